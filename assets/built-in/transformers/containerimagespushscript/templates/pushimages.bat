@@ -52,13 +52,37 @@ GOTO REGISTRY
     GOTO SKIP
 
 :MAIN
+{{- if .LoginCommandWindows }}
+:: login into the registry using the command line tool of its provider
+{{ .LoginCommandWindows }}
+{{- else }}
 :: Uncomment the below line if you want to enable login before pushing
 :: %CONTAINER_RUNTIME% login %REGISTRY_URL%
+{{- end }}
+{{- if .PreHookWindows }}
+
+:: pre hook
+{{ .PreHookWindows }}
+{{- end }}
+{{- if .SignImages }}
+:: the images are signed with cosign after they are pushed{{ if .SigningKeyRef }}, the password of the key is read from COSIGN_PASSWORD{{ end }}
+{{- end }}
 {{- range $image := .Images }}
 
 echo "pushing image {{ $image }}"
+{{- with index $.CreateRepositoryCommandsWindows $image }}
+{{ . }}
+{{- end }}
 %CONTAINER_RUNTIME% tag {{ $image }} %REGISTRY_URL%/%REGISTRY_NAMESPACE%/{{ $image }}
 %CONTAINER_RUNTIME% push %REGISTRY_URL%/%REGISTRY_NAMESPACE%/{{ $image }}
+{{- if $.SignImages }}
+cosign sign --yes{{ if $.SigningKeyRef }} --key {{ $.SigningKeyRef }}{{ end }} %REGISTRY_URL%/%REGISTRY_NAMESPACE%/{{ $image }}
+{{- end }}
+{{- end }}
+{{- if .PostHookWindows }}
+
+:: post hook
+{{ .PostHookWindows }}
 {{- end }}
 
 echo "done"
@@ -33,13 +33,37 @@ if [ "${CONTAINER_RUNTIME}" != "docker" ] && [ "${CONTAINER_RUNTIME}" != "podman
    echo 'Unsupported container runtime passed as an argument for pushing the images: '"${CONTAINER_RUNTIME}"
    exit 1
 fi
+{{- if .LoginCommand }}
+# login into the registry using the command line tool of its provider
+{{ .LoginCommand }}
+{{- else }}
 # Uncomment the below line if you want to enable login before pushing
 # ${CONTAINER_RUNTIME} login ${REGISTRY_URL}
+{{- end }}
+{{- if .SignImages }}
+# the images are signed with cosign after they are pushed{{ if .SigningKeyRef }}, the password of the key is read from COSIGN_PASSWORD{{ end }}
+{{- end }}
+{{- if .PreHook }}
+
+# pre hook
+{{ .PreHook }}
+{{- end }}
 {{- range $image := .Images }}
 
 echo 'pushing image {{ $image }}'
+{{- with index $.CreateRepositoryCommands $image }}
+{{ . }}
+{{- end }}
 ${CONTAINER_RUNTIME} tag {{ $image }} ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $image }}
 ${CONTAINER_RUNTIME} push ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $image }}
+{{- if $.SignImages }}
+cosign sign --yes{{ if $.SigningKeyRef }} --key {{ $.SigningKeyRef }}{{ end }} ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $image }}
+{{- end }}
+{{- end }}
+{{- if .PostHook }}
+
+# post hook
+{{ .PostHook }}
 {{- end }}
 
 echo 'done'
@@ -27,7 +27,7 @@ if not %basename% == "scripts" (
 )
 
 REM go to the parent directory so that all the relative paths will be correct
-cd {{ .RelParentOfSourceDir }}
+cd {{ .RelParentOfSourceDirWindows }}
 
 IF "%3"=="" GOTO DEFAULT_PLATFORMS
 SET PLATFORMS=%3%
@@ -50,14 +50,38 @@ GOTO REGISTRY
 	GOTO MAIN
 
 :MAIN
+{{- if .LoginCommandWindows }}
+:: login into the registry using the command line tool of its provider
+{{ .LoginCommandWindows }}
+{{- else }}
 :: Uncomment the below line if you want to enable login before pushing
 :: docker login %REGISTRY_URL%
+{{- end }}
+{{- if .PreHookWindows }}
+
+:: pre hook
+{{ .PreHookWindows }}
+{{- end }}
+{{- if .SignImages }}
+:: the images are signed with cosign after they are pushed{{ if .SigningKeyRef }}, the password of the key is read from COSIGN_PASSWORD{{ end }}
+{{- end }}
 {{- range $dockerfile := .DockerfilesConfig }}
 
 echo "building and pushing image {{ $dockerfile.ImageName }}"
+{{- with index $.CreateRepositoryCommandsWindows $dockerfile.ImageName }}
+{{ . }}
+{{- end }}
 pushd {{ $dockerfile.ContextWindows }}
-docker buildx build --platform ${PLATFORMS} -f {{ $dockerfile.DockerfileName }} --push --tag ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $dockerfile.ImageName }} .
+docker buildx build --platform ${PLATFORMS} -f {{ $dockerfile.DockerfileNameWindows }}{{ range $buildArg := $dockerfile.BuildArgs }} --build-arg "{{ $buildArg }}"{{ end }}{{ if $dockerfile.Target }} --target {{ $dockerfile.Target }}{{ end }} --push --tag ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $dockerfile.ImageName }} .
+{{- if $.SignImages }}
+cosign sign --yes{{ if $.SigningKeyRef }} --key {{ $.SigningKeyRef }}{{ end }} %REGISTRY_URL%/%REGISTRY_NAMESPACE%/{{ $dockerfile.ImageName }}
+{{- end }}
 popd
 {{- end }}
+{{- if .PostHookWindows }}
+
+:: post hook
+{{ .PostHookWindows }}
+{{- end }}
 
 echo "done"
@@ -24,7 +24,7 @@ if [[ "$(basename "$PWD")" != 'scripts' ]] ; then
   exit 1
 fi
 
-cd {{ .RelParentOfSourceDir }} # go to the parent directory so that all the relative paths will be correct
+cd {{ .RelParentOfSourceDirUnix }} # go to the parent directory so that all the relative paths will be correct
 
 REGISTRY_URL={{ .RegistryURL }}
 REGISTRY_NAMESPACE={{ .RegistryNamespace }}
@@ -36,14 +36,38 @@ fi
 if [ "$#" -eq 3 ]; then
   PLATFORMS=$3
 fi
+{{- if .LoginCommand }}
+# login into the registry using the command line tool of its provider
+{{ .LoginCommand }}
+{{- else }}
 # Uncomment the below line if you want to enable login before pushing
 # docker login ${REGISTRY_URL}
+{{- end }}
+{{- if .SignImages }}
+# the images are signed with cosign after they are pushed{{ if .SigningKeyRef }}, the password of the key is read from COSIGN_PASSWORD{{ end }}
+{{- end }}
+{{- if .PreHook }}
+
+# pre hook
+{{ .PreHook }}
+{{- end }}
 {{- range $dockerfile := .DockerfilesConfig }}
 
 echo 'building and pushing image {{ $dockerfile.ImageName }}'
+{{- with index $.CreateRepositoryCommands $dockerfile.ImageName }}
+{{ . }}
+{{- end }}
 cd {{ $dockerfile.ContextUnix }}
-docker buildx build --platform ${PLATFORMS} -f {{ $dockerfile.DockerfileName }}  --push --tag ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $dockerfile.ImageName }} .
+docker buildx build --platform ${PLATFORMS} -f {{ $dockerfile.DockerfileNameUnix }}{{ range $buildArg := $dockerfile.BuildArgs }} --build-arg '{{ $buildArg }}'{{ end }}{{ if $dockerfile.Target }} --target {{ $dockerfile.Target }}{{ end }}  --push --tag ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $dockerfile.ImageName }} .
+{{- if $.SignImages }}
+cosign sign --yes{{ if $.SigningKeyRef }} --key {{ $.SigningKeyRef }}{{ end }} ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $dockerfile.ImageName }}
+{{- end }}
 cd -
 {{- end }}
+{{- if .PostHook }}
+
+# post hook
+{{ .PostHook }}
+{{- end }}
 
 echo 'done'
@@ -46,16 +46,28 @@ GOTO DOCKER_CONTAINER_RUNTIME
     GOTO SKIP
 
 :MAIN
+REM the Dockerfiles use cache mounts which need BuildKit
+SET DOCKER_BUILDKIT=1
 REM go to the parent directory so that all the relative paths will be correct
-cd {{ .RelParentOfSourceDir }}
+cd {{ .RelParentOfSourceDirWindows }}
+{{- if .PreHookWindows }}
+
+REM pre hook
+{{ .PreHookWindows }}
+{{- end }}
 
 {{- range $dockerfile := .DockerfilesConfig }}
 
 echo "building image {{ $dockerfile.ImageName }}"
 pushd {{ $dockerfile.ContextWindows }}
-%CONTAINER_RUNTIME% build -f {{ $dockerfile.DockerfileName }} -t {{ $dockerfile.ImageName }} .
+%CONTAINER_RUNTIME% build -f {{ $dockerfile.DockerfileNameWindows }}{{ range $buildArg := $dockerfile.BuildArgs }} --build-arg "{{ $buildArg }}"{{ end }}{{ if $dockerfile.Target }} --target {{ $dockerfile.Target }}{{ end }} -t {{ $dockerfile.ImageName }} .
 popd
 {{- end }}
+{{- if .PostHookWindows }}
+
+REM post hook
+{{ .PostHookWindows }}
+{{- end }}
 
 echo "done"
 
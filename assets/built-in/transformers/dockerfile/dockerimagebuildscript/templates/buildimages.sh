@@ -30,14 +30,26 @@ if [ "${CONTAINER_RUNTIME}" != "docker" ] && [ "${CONTAINER_RUNTIME}" != "podman
    echo 'Unsupported container runtime passed as an argument for building the images: '"${CONTAINER_RUNTIME}"
    exit 1
 fi
-cd {{ .RelParentOfSourceDir }} # go to the parent directory so that all the relative paths will be correct
+# the Dockerfiles use cache mounts which need BuildKit
+export DOCKER_BUILDKIT=1
+cd {{ .RelParentOfSourceDirUnix }} # go to the parent directory so that all the relative paths will be correct
+{{- if .PreHook }}
+
+# pre hook
+{{ .PreHook }}
+{{- end }}
 
 {{- range $dockerfile := .DockerfilesConfig }}
 
 echo 'building image {{ $dockerfile.ImageName }}'
 cd {{ $dockerfile.ContextUnix }}
-${CONTAINER_RUNTIME} build -f {{ $dockerfile.DockerfileName }} -t {{ $dockerfile.ImageName }} .
+${CONTAINER_RUNTIME} build -f {{ $dockerfile.DockerfileNameUnix }}{{ range $buildArg := $dockerfile.BuildArgs }} --build-arg '{{ $buildArg }}'{{ end }}{{ if $dockerfile.Target }} --target {{ $dockerfile.Target }}{{ end }} -t {{ $dockerfile.ImageName }} .
 cd -
 {{- end }}
+{{- if .PostHook }}
+
+# post hook
+{{ .PostHook }}
+{{- end }}
 
 echo 'done'
@@ -0,0 +1,45 @@
+#!/usr/bin/env bash
+#   Copyright IBM Corporation 2021
+#
+#   Licensed under the Apache License, Version 2.0 (the "License");
+#   you may not use this file except in compliance with the License.
+#   You may obtain a copy of the License at
+#
+#        http://www.apache.org/licenses/LICENSE-2.0
+#
+#   Unless required by applicable law or agreed to in writing, software
+#   distributed under the License is distributed on an "AS IS" BASIS,
+#   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#   See the License for the specific language governing permissions and
+#   limitations under the License.
+
+# Locks the images of the kubernetes yamls to their digests using kbld and pushes the imgpkg bundle.
+# Push the images using pushimages.sh before running this script.
+# Invoke as ./push-bundle.sh <bundle_image> <version>
+# Examples:
+# 1) ./push-bundle.sh
+# 2) ./push-bundle.sh {{ .BundleImage }} {{ .Version }}
+
+if [[ "$(basename "$PWD")" != 'carvel' ]] ; then
+  echo 'please run this script from the "carvel" directory'
+  exit 1
+fi
+BUNDLE_IMAGE={{ .BundleImage }}
+VERSION={{ .Version }}
+if [ "$#" -gt 0 ]; then
+  BUNDLE_IMAGE=$1
+fi
+if [ "$#" -gt 1 ]; then
+  VERSION=$2
+fi
+for tool in kbld imgpkg; do
+  if ! command -v "${tool}" >/dev/null 2>&1; then
+    echo "${tool} is required to push the bundle. Install it from https://carvel.dev"
+    exit 1
+  fi
+done
+
+mkdir -p bundle/.imgpkg
+kbld -f bundle/config --imgpkg-lock-output bundle/.imgpkg/images.yml >/dev/null || exit 1
+imgpkg push -b "${BUNDLE_IMAGE}:${VERSION}" -f bundle || exit 1
+echo "Pushed the bundle ${BUNDLE_IMAGE}:${VERSION}. Apply packagemetadata.yaml, package.yaml and packageinstall.yaml, or app.yaml, to deploy it using kapp-controller."
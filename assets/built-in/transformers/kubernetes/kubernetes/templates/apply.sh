@@ -0,0 +1,44 @@
+#!/usr/bin/env bash
+#   Copyright IBM Corporation 2021
+#
+#   Licensed under the Apache License, Version 2.0 (the "License");
+#   you may not use this file except in compliance with the License.
+#   You may obtain a copy of the License at
+#
+#        http://www.apache.org/licenses/LICENSE-2.0
+#
+#   Unless required by applicable law or agreed to in writing, software
+#   distributed under the License is distributed on an "AS IS" BASIS,
+#   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#   See the License for the specific language governing permissions and
+#   limitations under the License.
+
+
+# Applies the directories of the kinds in the order listed in {{ .ApplyOrderFile }}, so that the resources exist before the resources which use them.
+{{- if .ClusterScoped }}
+# These resources do not belong to a namespace, applying them needs the permissions of a cluster administrator.
+{{- end }}
+# The arguments are passed to kubectl apply.
+# Invoke as ./apply.sh <kubectl apply args>
+# Examples:
+# 1) ./apply.sh
+{{- if .ClusterScoped }}
+# 2) ./apply.sh --dry-run=server
+{{- else }}
+# 2) ./apply.sh --namespace myproject
+# 3) ./apply.sh --dry-run=server
+{{- end }}
+
+set -e
+cd "$(dirname "$0")" # go to the directory of the yamls so that all the relative paths will be correct
+
+while IFS= read -r dir || [ -n "$dir" ]; do
+  if [ -z "$dir" ] || [[ "$dir" == \#* ]]; then
+    continue
+  fi
+  echo "applying the yamls in ${dir}"
+  kubectl apply -f "${dir}" "$@"
+  if [ "${dir}" == 'customresourcedefinition' ]; then
+    kubectl wait --for condition=established --timeout=60s -f "${dir}"
+  fi
+done < {{ .ApplyOrderFile }}
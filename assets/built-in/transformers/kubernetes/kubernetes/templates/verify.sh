@@ -0,0 +1,106 @@
+#!/usr/bin/env bash
+#   Copyright IBM Corporation 2021
+#
+#   Licensed under the Apache License, Version 2.0 (the "License");
+#   you may not use this file except in compliance with the License.
+#   You may obtain a copy of the License at
+#
+#        http://www.apache.org/licenses/LICENSE-2.0
+#
+#   Unless required by applicable law or agreed to in writing, software
+#   distributed under the License is distributed on an "AS IS" BASIS,
+#   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#   See the License for the specific language governing permissions and
+#   limitations under the License.
+
+
+# Waits for the rollouts of the workloads and runs the smoke test Jobs, which check that the exposed services respond.
+# Run it after applying the yamls. It exits with a non zero code when any of the smoke tests fail.
+# The arguments are passed to kubectl.
+# Set the TIMEOUT environment variable to change how long to wait for each rollout and each smoke test, in seconds or as a duration like 10m or 1h30m. Default is 300s.
+# Invoke as ./verify.sh <kubectl args>
+# Examples:
+# 1) ./verify.sh
+# 2) ./verify.sh --namespace myproject
+# 3) TIMEOUT=600s ./verify.sh --context mycluster
+
+set -e
+cd "$(dirname "$0")" # go to the directory of the smoke tests so that all the relative paths will be correct
+
+# to_seconds converts the durations accepted by kubectl, like 300, 300s, 10m or 1h30m, to seconds
+to_seconds() {
+  local duration="$1"
+  local total=0
+  local number
+  while [ -n "${duration}" ]; do
+    number="${duration%%[!0-9]*}"
+    if [ -z "${number}" ]; then
+      echo "invalid duration $1 , use seconds or a duration like 10m or 1h30m" >&2
+      return 1
+    fi
+    duration="${duration#"${number}"}"
+    case "${duration}" in
+      "") total=$((total + number)) ;;
+      h*) total=$((total + number * 3600)); duration="${duration#h}" ;;
+      ms*) duration="${duration#ms}" ;;
+      m*) total=$((total + number * 60)); duration="${duration#m}" ;;
+      s*) total=$((total + number)); duration="${duration#s}" ;;
+      *)
+        echo "invalid duration $1 , use seconds or a duration like 10m or 1h30m" >&2
+        return 1
+        ;;
+    esac
+  done
+  echo "${total}"
+}
+
+TIMEOUT="${TIMEOUT:-300s}"
+TIMEOUT_SECONDS="$(to_seconds "${TIMEOUT}")"
+if [[ "${TIMEOUT}" =~ ^[0-9]+$ ]]; then
+  # kubectl needs the unit
+  TIMEOUT="${TIMEOUT}s"
+fi
+{{- range .Workloads }}
+
+echo "waiting for the rollout of {{ . }}"
+kubectl rollout status '{{ . }}' --timeout="${TIMEOUT}" "$@"
+{{- end }}
+
+# wait_for_job waits until the Job succeeds or fails
+wait_for_job() {
+  local job="$1"
+  shift
+  local waited=0
+  while [ "${waited}" -lt "${TIMEOUT_SECONDS}" ]; do
+    if [ "$(kubectl get job "${job}" -o jsonpath='{.status.succeeded}' "$@")" == "1" ]; then
+      return 0
+    fi
+    if [ "$(kubectl get job "${job}" -o jsonpath='{.status.conditions[?(@.type=="Failed")].status}' "$@")" == "True" ]; then
+      return 1
+    fi
+    sleep 5
+    waited=$((waited + 5))
+  done
+  echo "timed out waiting for the smoke test ${job}"
+  return 1
+}
+
+FAILED=()
+{{- range .Jobs }}
+
+echo "running the smoke test {{ .Name }}"
+kubectl delete job '{{ .Name }}' --ignore-not-found "$@"
+kubectl apply -f '{{ .File }}' "$@"
+if wait_for_job '{{ .Name }}' "$@"; then
+  echo "the smoke test {{ .Name }} passed"
+else
+  kubectl logs 'job/{{ .Name }}' "$@" || true
+  FAILED+=('{{ .Name }}')
+fi
+{{- end }}
+
+if [ "${#FAILED[@]}" -ne 0 ]; then
+  echo "the smoke tests failed: ${FAILED[*]}"
+  exit 1
+fi
+echo "all the smoke tests passed"
@@ -0,0 +1,91 @@
+#!/usr/bin/env bash
+#   Copyright IBM Corporation 2021
+#
+#   Licensed under the Apache License, Version 2.0 (the "License");
+#   you may not use this file except in compliance with the License.
+#   You may obtain a copy of the License at
+#
+#        http://www.apache.org/licenses/LICENSE-2.0
+#
+#   Unless required by applicable law or agreed to in writing, software
+#   distributed under the License is distributed on an "AS IS" BASIS,
+#   WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+#   See the License for the specific language governing permissions and
+#   limitations under the License.
+
+# Creates a local cluster, loads the images built using buildimages.sh, deploys the yamls and forwards the service ports.
+# Invoke as ./deploy-local.sh <cluster_type> <cluster_name>
+# Examples:
+# 1) ./deploy-local.sh
+# 2) ./deploy-local.sh minikube
+# 3) ./deploy-local.sh kind {{ .ClusterName }}
+
+if [[ "$(basename "$PWD")" != 'scripts' ]] ; then
+  echo 'please run this script from the "scripts" directory'
+  exit 1
+fi
+CLUSTER_TYPE=kind
+CLUSTER_NAME={{ .ClusterName }}
+REGISTRY_URL={{ .RegistryURL }}
+REGISTRY_NAMESPACE={{ .RegistryNamespace }}
+DEPLOY_DIR={{ .DeployPath }}
+if [ "$#" -gt 0 ]; then
+  CLUSTER_TYPE=$1
+fi
+if [ "$#" -gt 1 ]; then
+  CLUSTER_NAME=$2
+fi
+if [ "${CLUSTER_TYPE}" != "kind" ] && [ "${CLUSTER_TYPE}" != "minikube" ]; then
+  echo 'Unsupported cluster type passed as an argument for creating the local cluster: '"${CLUSTER_TYPE}"
+  exit 1
+fi
+set -e
+cd .. # go to the parent directory so that all the relative paths will be correct
+
+echo "creating the ${CLUSTER_TYPE} cluster ${CLUSTER_NAME}"
+if [ "${CLUSTER_TYPE}" == "kind" ]; then
+  if ! kind get clusters | grep -qx "${CLUSTER_NAME}" ; then
+    kind create cluster --name "${CLUSTER_NAME}"
+  fi
+  kubectl config use-context "kind-${CLUSTER_NAME}"
+else
+  minikube start -p "${CLUSTER_NAME}"
+  kubectl config use-context "${CLUSTER_NAME}"
+fi
+{{- range $image := .Images }}
+
+echo 'loading image {{ $image }}'
+docker tag {{ $image }} ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $image }}
+if [ "${CLUSTER_TYPE}" == "kind" ]; then
+  kind load docker-image ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $image }} --name "${CLUSTER_NAME}"
+else
+  minikube -p "${CLUSTER_NAME}" image load ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/{{ $image }}
+fi
+{{- end }}
+
+# apply the resources that the workloads depend on before the workloads
+# the images are loaded into the cluster, so they should not be pulled from the registry
+apply_kind() {
+  find "${DEPLOY_DIR}" -type f \( -name '*.yaml' -o -name '*.yml' \) | sort | while IFS= read -r f ; do
+    if [ -z "$1" ] || grep -qE "^kind: ($1)\s*$" "$f" ; then
+      sed 's/imagePullPolicy: Always/imagePullPolicy: IfNotPresent/' "$f" | kubectl apply -f -
+    fi
+  done
+}
+echo "deploying the yamls in ${DEPLOY_DIR}"
+apply_kind 'Namespace|CustomResourceDefinition'
+apply_kind 'ServiceAccount|Role|ClusterRole|RoleBinding|ClusterRoleBinding|ConfigMap|Secret|PersistentVolumeClaim'
+apply_kind 'Service'
+apply_kind ''
+kubectl wait --for=condition=available deployment --all --timeout=300s || echo 'some of the deployments are not available yet'
+{{- if .PortForwards }}
+
+echo 'forwarding the service ports'
+{{- range $portForward := .PortForwards }}
+kubectl port-forward service/{{ $portForward.ServiceName }} {{ $portForward.LocalPort }}:{{ $portForward.ServicePort }} > /dev/null &
+echo 'service {{ $portForward.ServiceName }} port {{ $portForward.ServicePort }} is available at http://localhost:{{ $portForward.LocalPort }}'
+{{- end }}
+echo 'press Ctrl+C to stop forwarding the ports'
+trap 'kill $(jobs -p) 2> /dev/null' EXIT
+wait
+{{- end }}
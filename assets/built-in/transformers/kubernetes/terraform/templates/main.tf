@@ -0,0 +1,69 @@
+locals {
+  image_repositories = [{{ range $i, $repository := .Repositories }}{{ if $i }}, {{ end }}"{{ $repository }}"{{ end }}]
+  ingress_hosts      = [{{ range $i, $host := .IngressHosts }}{{ if $i }}, {{ end }}"{{ $host }}"{{ end }}]
+}
+
+resource "kubernetes_namespace" "application" {
+  metadata {
+    name = var.namespace
+  }
+}
+{{- if eq .RegistryProvider "aws" }}
+
+resource "aws_ecr_repository" "images" {
+  for_each             = toset(local.image_repositories)
+  name                 = each.value
+  image_tag_mutability = "MUTABLE"
+
+  image_scanning_configuration {
+    scan_on_push = true
+  }
+}
+{{- else if eq .RegistryProvider "google" }}
+
+resource "google_artifact_registry_repository" "images" {
+  location      = "{{ .RegistryRegion }}"
+  repository_id = "{{ .RegistryName }}"
+  format        = "DOCKER"
+}
+{{- else if eq .RegistryProvider "azurerm" }}
+
+# The repositories are created when the images are pushed to the registry
+resource "azurerm_container_registry" "images" {
+  name                = "{{ .RegistryName }}"
+  resource_group_name = var.azure_resource_group
+  location            = var.azure_location
+  sku                 = "Standard"
+}
+{{- end }}
+{{- if eq .DNSProvider "Route53" }}
+
+resource "aws_route53_record" "ingress" {
+  for_each = toset(local.ingress_hosts)
+  zone_id  = var.dns_zone
+  name     = each.value
+  type     = "CNAME"
+  ttl      = var.dns_ttl
+  records  = [var.ingress_address]
+}
+{{- else if eq .DNSProvider "Azure DNS" }}
+
+resource "azurerm_dns_a_record" "ingress" {
+  for_each            = toset(local.ingress_hosts)
+  zone_name           = var.dns_zone
+  resource_group_name = var.azure_resource_group
+  name                = var.dns_zone_domain == "" ? each.value : trimsuffix(each.value, ".${var.dns_zone_domain}")
+  ttl                 = var.dns_ttl
+  records             = [var.ingress_address]
+}
+{{- else if eq .DNSProvider "Cloud DNS" }}
+
+resource "google_dns_record_set" "ingress" {
+  for_each     = toset(local.ingress_hosts)
+  managed_zone = var.dns_zone
+  name         = "${each.value}."
+  type         = "A"
+  ttl          = var.dns_ttl
+  rrdatas      = [var.ingress_address]
+}
+{{- end }}
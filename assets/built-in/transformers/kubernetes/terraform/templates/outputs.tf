@@ -0,0 +1,28 @@
+output "namespace" {
+  description = "Namespace the application is deployed to"
+  value       = kubernetes_namespace.application.metadata[0].name
+}
+{{- if eq .RegistryProvider "aws" }}
+
+output "image_repository_urls" {
+  description = "URLs of the image repositories"
+  value       = { for name, repository in aws_ecr_repository.images : name => repository.repository_url }
+}
+{{- else if eq .RegistryProvider "google" }}
+
+output "image_repository_id" {
+  description = "ID of the image repository"
+  value       = google_artifact_registry_repository.images.id
+}
+{{- else if eq .RegistryProvider "azurerm" }}
+
+output "image_registry_login_server" {
+  description = "Login server of the container registry"
+  value       = azurerm_container_registry.images.login_server
+}
+{{- end }}
+
+output "ingress_hosts" {
+  description = "Hosts of the ingress"
+  value       = local.ingress_hosts
+}
@@ -0,0 +1,73 @@
+variable "kubeconfig_path" {
+  description = "Path to the kubeconfig file of the cluster the application is deployed to"
+  type        = string
+  default     = "~/.kube/config"
+}
+
+variable "kubeconfig_context" {
+  description = "Context of the kubeconfig file to use. Leave empty to use the current context"
+  type        = string
+  default     = null
+}
+
+variable "namespace" {
+  description = "Namespace the application is deployed to"
+  type        = string
+  default     = "{{ .Namespace }}"
+}
+{{- if or (eq .RegistryProvider "aws") (eq .DNSProvider "Route53") }}
+
+variable "aws_region" {
+  description = "AWS region of the image repositories and the DNS zone"
+  type        = string
+  default     = "{{ if .RegistryRegion }}{{ .RegistryRegion }}{{ else }}us-east-1{{ end }}"
+}
+{{- end }}
+{{- if or (eq .RegistryProvider "google") (eq .DNSProvider "Cloud DNS") }}
+
+variable "google_project" {
+  description = "Google Cloud project of the image repository and the DNS zone"
+  type        = string
+}
+{{- end }}
+{{- if or (eq .RegistryProvider "azurerm") (eq .DNSProvider "Azure DNS") }}
+
+variable "azure_resource_group" {
+  description = "Azure resource group of the container registry and the DNS zone"
+  type        = string
+}
+{{- end }}
+{{- if eq .RegistryProvider "azurerm" }}
+
+variable "azure_location" {
+  description = "Azure location of the container registry"
+  type        = string
+  default     = "eastus"
+}
+{{- end }}
+{{- if .DNSProvider }}
+
+variable "dns_zone" {
+  description = "{{ if eq .DNSProvider "Route53" }}ID of the Route53 hosted zone{{ else if eq .DNSProvider "Azure DNS" }}Name of the Azure DNS zone{{ else }}Name of the Cloud DNS managed zone{{ end }} of the ingress hosts"
+  type        = string
+}
+{{- if eq .DNSProvider "Azure DNS" }}
+
+variable "dns_zone_domain" {
+  description = "Domain of the DNS zone, the record names are relative to it"
+  type        = string
+  default     = ""
+}
+{{- end }}
+
+variable "ingress_address" {
+  description = "{{ if eq .DNSProvider "Route53" }}Hostname{{ else }}IP address{{ end }} of the load balancer of the ingress controller"
+  type        = string
+}
+
+variable "dns_ttl" {
+  description = "TTL of the DNS records in seconds"
+  type        = number
+  default     = 300
+}
+{{- end }}
@@ -0,0 +1,50 @@
+terraform {
+  required_version = ">= 1.0"
+  required_providers {
+    kubernetes = {
+      source  = "hashicorp/kubernetes"
+      version = ">= 2.0"
+    }
+{{- if or (eq .RegistryProvider "aws") (eq .DNSProvider "Route53") }}
+    aws = {
+      source  = "hashicorp/aws"
+      version = ">= 4.0"
+    }
+{{- end }}
+{{- if or (eq .RegistryProvider "google") (eq .DNSProvider "Cloud DNS") }}
+    google = {
+      source  = "hashicorp/google"
+      version = ">= 4.0"
+    }
+{{- end }}
+{{- if or (eq .RegistryProvider "azurerm") (eq .DNSProvider "Azure DNS") }}
+    azurerm = {
+      source  = "hashicorp/azurerm"
+      version = ">= 3.0"
+    }
+{{- end }}
+  }
+}
+
+provider "kubernetes" {
+  config_path    = var.kubeconfig_path
+  config_context = var.kubeconfig_context
+}
+{{- if or (eq .RegistryProvider "aws") (eq .DNSProvider "Route53") }}
+
+provider "aws" {
+  region = var.aws_region
+}
+{{- end }}
+{{- if or (eq .RegistryProvider "google") (eq .DNSProvider "Cloud DNS") }}
+
+provider "google" {
+  project = var.google_project
+}
+{{- end }}
+{{- if or (eq .RegistryProvider "azurerm") (eq .DNSProvider "Azure DNS") }}
+
+provider "azurerm" {
+  features {}
+}
+{{- end }}
@@ -17,10 +17,12 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/lib"
 	"github.com/konveyor/move2kube/types"
 	"github.com/sirupsen/logrus"
@@ -32,9 +34,14 @@ type collectFlags struct {
 	annotations string
 	outpath     string
 	srcpath     string
+	kubeconfig  string
+	kubecontext string
+	prometheus  string
+	namespaces  []string
+	helmSecrets bool
 }
 
-func collectHandler(flags collectFlags) {
+func collectHandler(ctx context.Context, flags collectFlags) {
 	var err error
 	annotations := flags.annotations
 	outpath := flags.outpath
@@ -59,11 +66,16 @@ func collectHandler(flags collectFlags) {
 			logrus.Fatalf("Source path is a file, expected '%s' to be a directory.", srcpath)
 		}
 	}
+	common.KubeConfigPath = flags.kubeconfig
+	common.KubeContext = flags.kubecontext
+	common.PrometheusURL = flags.prometheus
+	common.CollectNamespaces = flags.namespaces
+	common.CollectHelmReleaseSecrets = flags.helmSecrets
 	outpath = filepath.Join(filepath.Clean(outpath), types.AppNameShort+"_collect")
 	if annotations == "" {
-		lib.Collect(srcpath, outpath, []string{})
+		lib.Collect(ctx, srcpath, outpath, []string{})
 	} else {
-		lib.Collect(srcpath, outpath, strings.Split(annotations, ","))
+		lib.Collect(ctx, srcpath, outpath, strings.Split(annotations, ","))
 	}
 	logrus.Infof("Collect Output in [%s]. Copy this directory into the source directory to be used for planning.", outpath)
 }
@@ -77,12 +89,17 @@ func GetCollectCommand() *cobra.Command {
 		Use:   "collect",
 		Short: "Collect and process metadata from multiple sources.",
 		Long:  "Collect metadata from multiple sources (cluster, image repo etc.), filter and summarize it into a yaml.",
-		Run:   func(*cobra.Command, []string) { collectHandler(flags) },
+		Run:   func(cmd *cobra.Command, _ []string) { collectHandler(cmd.Context(), flags) },
 	}
 
 	collectCmd.Flags().StringVarP(&flags.annotations, "annotations", "a", "", "Specify annotations to select collector subset.")
 	collectCmd.Flags().StringVarP(&flags.outpath, outputFlag, "o", ".", "Specify output directory for collect.")
 	collectCmd.Flags().StringVarP(&flags.srcpath, sourceFlag, "s", "", "Specify source directory for the artifacts to be considered while collecting.")
+	collectCmd.Flags().StringVar(&flags.kubeconfig, "kubeconfig", "", "Specify the kubeconfig file to be used to connect to the cluster.")
+	collectCmd.Flags().StringVar(&flags.kubecontext, "context", "", "Specify the kubeconfig context to be used to connect to the cluster.")
+	collectCmd.Flags().StringVar(&flags.prometheus, "prometheus-url", "", "Specify the url of the Prometheus server to get the usage metrics of the workloads from. The metrics-server of the cluster is used if not specified.")
+	collectCmd.Flags().StringSliceVar(&flags.namespaces, "namespaces", []string{}, "Specify the namespaces to collect the workloads and the helm releases from. All the non system namespaces are collected if not specified.")
+	collectCmd.Flags().BoolVar(&flags.helmSecrets, "helm-release-secrets", false, "Collect the helm releases stored as secrets. The secrets contain the values of the charts, which may include passwords.")
 
 	return collectCmd
 }
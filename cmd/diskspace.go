@@ -0,0 +1,36 @@
+//go:build !windows
+// +build !windows
+
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"syscall"
+)
+
+// maxOutputPathLength is the maximum length of the paths the operating system accepts
+const maxOutputPathLength = 4096
+
+// getFreeDiskSpace returns the number of bytes available to the user on the file system containing the path
+func getFreeDiskSpace(path string) (uint64, error) {
+	stat := syscall.Statfs_t{}
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -0,0 +1,40 @@
+//go:build windows
+// +build windows
+
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// maxOutputPathLength is the maximum length of the paths, unless the long paths are enabled on the machine
+const maxOutputPathLength = 260
+
+// getFreeDiskSpace returns the number of bytes available to the user on the volume containing the path
+func getFreeDiskSpace(path string) (uint64, error) {
+	pathPtr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+	freeBytes := uint64(0)
+	if err := windows.GetDiskFreeSpaceEx(pathPtr, &freeBytes, nil, nil); err != nil {
+		return 0, err
+	}
+	return freeBytes, nil
+}
@@ -0,0 +1,126 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// failureCategory is the kind of failure the command stopped with
+type failureCategory string
+
+const (
+	// genericFailure is used for the failures which do not fall in any of the other categories
+	genericFailure failureCategory = "error"
+	// validationFailure is used when the flags or the paths given to the command are invalid
+	validationFailure failureCategory = "validation"
+	// planFailure is used when the plan could not be created or read
+	planFailure failureCategory = "plan"
+	// qaFailure is used when some of the questions had no answer and no default
+	qaFailure failureCategory = "qa"
+	// conversionFailure is used when some of the artifacts or objects could not be transformed
+	conversionFailure failureCategory = "conversion"
+	// writeFailure is used when some of the output could not be written
+	writeFailure failureCategory = "write"
+)
+
+// exitCodes are the exit codes of the categories of failures, so that the scripts running the command can tell them apart
+var exitCodes = map[failureCategory]int{
+	genericFailure:    1,
+	validationFailure: 2,
+	planFailure:       3,
+	qaFailure:         4,
+	conversionFailure: 5,
+	writeFailure:      6,
+}
+
+// FailureSummary is printed as JSON to stderr when the command exits
+type FailureSummary struct {
+	Status   string          `json:"status"`
+	ExitCode int             `json:"exitCode"`
+	Category failureCategory `json:"category,omitempty"`
+	Message  string          `json:"message,omitempty"`
+	Details  []string        `json:"details,omitempty"`
+}
+
+var (
+	pendingFailure      *FailureSummary
+	pendingFailureMutex sync.Mutex
+)
+
+// failureSummaryHook records the message of a fatal log which was not logged through fatalf, so that it is included in the summary
+type failureSummaryHook struct{}
+
+// Fire records the message of the fatal log
+func (hook *failureSummaryHook) Fire(entry *logrus.Entry) error {
+	pendingFailureMutex.Lock()
+	defer pendingFailureMutex.Unlock()
+	if pendingFailure == nil {
+		pendingFailure = &FailureSummary{Category: genericFailure, Message: entry.Message}
+	}
+	return nil
+}
+
+// Levels returns the levels on which the hook gets called
+func (hook *failureSummaryHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.FatalLevel}
+}
+
+// setupFailureSummary makes the command print the failure summary and exit with the code of the category of the failure
+func setupFailureSummary() {
+	logrus.AddHook(&failureSummaryHook{})
+	logrus.StandardLogger().ExitFunc = func(int) {
+		pendingFailureMutex.Lock()
+		summary := FailureSummary{Category: genericFailure}
+		if pendingFailure != nil {
+			summary = *pendingFailure
+		}
+		pendingFailureMutex.Unlock()
+		summary.Status = "failed"
+		summary.ExitCode = exitCodes[summary.Category]
+		printFailureSummary(summary)
+		os.Exit(summary.ExitCode)
+	}
+}
+
+// fatalf logs the error and exits with the exit code of the category
+func fatalf(category failureCategory, details []string, format string, args ...interface{}) {
+	pendingFailureMutex.Lock()
+	pendingFailure = &FailureSummary{Category: category, Message: fmt.Sprintf(format, args...), Details: details}
+	pendingFailureMutex.Unlock()
+	logrus.Fatalf(format, args...)
+}
+
+// printSuccessSummary prints the summary of a planning or a transformation which did not fail
+func printSuccessSummary() {
+	printFailureSummary(FailureSummary{Status: "succeeded"})
+}
+
+// printFailureSummary prints the summary as a single line of JSON to stderr
+func printFailureSummary(summary FailureSummary) {
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		logrus.Errorf("failed to marshal the failure summary to json. Error: %q", err)
+		return
+	}
+	fmt.Fprintln(os.Stderr, string(summaryJSON))
+}
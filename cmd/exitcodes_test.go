@@ -0,0 +1,131 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/assets"
+	"github.com/konveyor/move2kube/common"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// exitTestArgsEnv contains the arguments of the command the test binary runs in the subprocess, separated by new lines
+const exitTestArgsEnv = "M2K_TEST_EXIT_CODE_ARGS"
+
+// runExitTestSubprocess runs the command in the subprocess, since the command exits the process when it fails
+func runExitTestSubprocess(args []string) {
+	var command *cobra.Command
+	switch args[0] {
+	case "plan":
+		command = GetPlanCommand()
+	case "transform":
+		command = GetTransformCommand()
+	default:
+		setupFailureSummary()
+		fatalf(failureCategory(args[0]), args[1:], "failed with the category %s", args[0])
+	}
+	assetsFilePermissions := map[string]int{}
+	if err := yaml.Unmarshal([]byte(assets.AssetFilePermissions), &assetsFilePermissions); err != nil {
+		os.Exit(101)
+	}
+	assetsPath, tempPath, err := common.CreateAssetsData(assets.AssetsDir, assetsFilePermissions)
+	if err != nil {
+		os.Exit(101)
+	}
+	common.TempPath = tempPath
+	common.AssetsPath = assetsPath
+	command.SetArgs(args[1:])
+	if err := command.ExecuteContext(context.Background()); err != nil {
+		os.Exit(100)
+	}
+	os.Exit(0)
+}
+
+func TestExitCodes(t *testing.T) {
+	if args := os.Getenv(exitTestArgsEnv); args != "" {
+		runExitTestSubprocess(strings.Split(args, "\n"))
+		return
+	}
+	tempDir := t.TempDir()
+	sourceFile := filepath.Join(tempDir, "source.txt")
+	if err := os.WriteFile(sourceFile, []byte("not a directory"), 0644); err != nil {
+		t.Fatalf("failed to create the source file. Error: %q", err)
+	}
+	emptySourceDir := filepath.Join(tempDir, "empty")
+	if err := os.Mkdir(emptySourceDir, 0755); err != nil {
+		t.Fatalf("failed to create the source directory. Error: %q", err)
+	}
+	unwritablePlanDir := filepath.Join(tempDir, "unwritable")
+	if err := os.MkdirAll(filepath.Join(unwritablePlanDir, common.DefaultPlanFile), 0755); err != nil {
+		t.Fatalf("failed to create a directory in place of the plan file. Error: %q", err)
+	}
+
+	testCases := []struct {
+		name     string
+		args     []string
+		category failureCategory
+		details  []string
+	}{
+		{name: "plan with a file as the source", args: []string{"plan", "-s", sourceFile, "-p", filepath.Join(tempDir, "plan1.yaml")}, category: validationFailure},
+		{name: "plan with no services", args: []string{"plan", "-s", emptySourceDir, "-p", filepath.Join(tempDir, "plan2.yaml"), "--fail-on-empty-plan"}, category: planFailure},
+		{name: "plan written over a directory", args: []string{"plan", "-s", emptySourceDir, "-p", unwritablePlanDir}, category: writeFailure},
+		{name: "transform with a missing plan", args: []string{"transform", "--qa-skip", "-p", filepath.Join(tempDir, "missing", "plan.yaml"), "-o", tempDir}, category: planFailure},
+		{name: "transform with an unsupported flag value", args: []string{"transform", "--qa-skip", "--archive", "rar", "-o", tempDir}, category: validationFailure},
+		{name: "unanswered questions", args: []string{string(qaFailure), "question1", "question2"}, category: qaFailure, details: []string{"question1", "question2"}},
+		{name: "conversion failures", args: []string{string(conversionFailure)}, category: conversionFailure},
+		{name: "generic failures", args: []string{string(genericFailure)}, category: genericFailure},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			command := exec.Command(os.Args[0], "-test.run=^TestExitCodes$")
+			command.Dir = tempDir
+			command.Env = append(os.Environ(), exitTestArgsEnv+"="+strings.Join(testCase.args, "\n"))
+			stderr := bytes.Buffer{}
+			command.Stderr = &stderr
+			err := command.Run()
+			exitErr := &exec.ExitError{}
+			if !errors.As(err, &exitErr) {
+				t.Fatalf("expected the command to exit with a failure, got the error %v . Stderr:\n%s", err, stderr.String())
+			}
+			wantExitCode := exitCodes[testCase.category]
+			if exitErr.ExitCode() != wantExitCode {
+				t.Fatalf("got the exit code %d , want %d for the category %s . Stderr:\n%s", exitErr.ExitCode(), wantExitCode, testCase.category, stderr.String())
+			}
+			lines := strings.Split(strings.TrimSpace(stderr.String()), "\n")
+			summary := FailureSummary{}
+			if err := json.Unmarshal([]byte(lines[len(lines)-1]), &summary); err != nil {
+				t.Fatalf("expected the last line of stderr to be the failure summary. Error: %q . Stderr:\n%s", err, stderr.String())
+			}
+			if summary.Status != "failed" || summary.ExitCode != wantExitCode || summary.Category != testCase.category || summary.Message == "" {
+				t.Fatalf("got the failure summary %+v , want the category %s and the exit code %d", summary, testCase.category, wantExitCode)
+			}
+			if strings.Join(summary.Details, ",") != strings.Join(testCase.details, ",") {
+				t.Fatalf("got the details %+v , want %+v", summary.Details, testCase.details)
+			}
+		})
+	}
+}
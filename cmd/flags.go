@@ -29,24 +29,87 @@ const (
 	ignoreEnvFlag = "ignore-env"
 	// qaSkipFlag is the name of the flag that lets you skip all the question answers
 	qaSkipFlag = "qa-skip"
+	// qaStrictFlag is the name of the flag that makes the QA use defaults and fail if any question has no default
+	qaStrictFlag = "qa-strict"
 	// qaPersistPasswords is the name of the flag that lets choose to persist passwords
 	qaPersistPasswords = "qa-persist-passwords"
 	// configOutFlag is the name of the flag that will point the location to output the config file
 	configOutFlag = "config-out"
 	// qaCacheOutFlag is the name of the flag that will point the location to output the cache file
 	qaCacheOutFlag = "qa-cache-out"
+	// qaAnswersFlag is the name of the flag that contains list of recorded answer files to replay
+	qaAnswersFlag = "qa-answers"
+	// qaAnswerSourceFlag is the name of the flag that contains list of sources the answers are resolved from before asking the questions
+	qaAnswerSourceFlag = "qa-answer-source"
 	// configFlag is the name of the flag that contains list of config files
 	configFlag = "config"
 	// setConfigFlag is the name of the flag that contains list of key-value configs
 	setConfigFlag = "set-config"
+	// setFlag is the name of the flag that contains list of <question id>=<answer> pairs
+	setFlag = "set"
 	// preSetFlag is the name of the flag that contains list of preset configurations to use
 	preSetFlag = "preset"
+	// qaProfileFlag is the name of the flag that selects how many of the questions are asked
+	qaProfileFlag = "profile"
+	// dryRunFlag is the name of the flag that lists the files that would be written without writing them
+	dryRunFlag = "dry-run"
+	// scriptLineEndingsFlag is the name of the flag that controls the line endings of the generated scripts
+	scriptLineEndingsFlag = "script-line-endings"
+	// archiveFlag is the name of the flag that packages the output directory into an archive of the given format
+	archiveFlag = "archive"
+	// strictFlag is the name of the flag that fails the transformation when any of the generated objects are ignored
+	strictFlag = "strict"
+	// watchFlag is the name of the flag that transforms again when the source or the customizations change
+	watchFlag = "watch"
+	// reviewFlag is the name of the flag that lets you review and exclude the generated resources before they are written
+	reviewFlag = "review"
+	// signProvenanceFlag is the name of the flag that contains the cosign key used to sign the provenance of the output
+	signProvenanceFlag = "sign-provenance"
+	// serviceGroupFlag is the name of the flag that splits the output into groups of services, generated into separate directories
+	serviceGroupFlag = "service-group"
+	// outputBucketFlag is the name of the flag that contains the url of the object storage bucket the output is uploaded to
+	outputBucketFlag = "output-bucket"
+	// serverHostFlag is the name of the flag that contains the host the transformation server listens on
+	serverHostFlag = "host"
+	// serverPortFlag is the name of the flag that contains the port the transformation server listens on
+	serverPortFlag = "port"
+	// workspaceFlag is the name of the flag that contains the directory where the transformation server stores the transformations
+	workspaceFlag = "workspace"
+	// maxParallelFlag is the name of the flag that limits the number of transformations the server runs at the same time
+	maxParallelFlag = "max-parallel"
+	// maxQueuedFlag is the name of the flag that limits the number of transformations waiting to be started by the server
+	maxQueuedFlag = "max-queued"
+	// transformationTimeoutFlag is the name of the flag that limits the time each transformation run by the server can take
+	transformationTimeoutFlag = "timeout"
+	// cpusFlag is the name of the flag that limits the number of CPUs used by each transformation run by the server
+	cpusFlag = "cpus"
+	// memoryFlag is the name of the flag that contains the soft memory limit of each transformation run by the server
+	memoryFlag = "memory"
+	// maxUploadSizeFlag is the name of the flag that limits the size of the requests uploading the sources to the server
+	maxUploadSizeFlag = "max-upload-size"
+	// finishedTTLFlag is the name of the flag that contains how long the server keeps the finished transformations
+	finishedTTLFlag = "finished-ttl"
 	// overwriteFlag is the name of the flag that lets you overwrite the output directory if it exists
 	overwriteFlag = "overwrite"
+	// customTemplatesFlag is the path to the directory containing templates that override the built-in templates
+	customTemplatesFlag = "custom-templates"
+	// templateValuesFlag is the name of the flag that contains the list of value files the templates can use
+	templateValuesFlag = "template-values"
+	// versionedFlag is the name of the flag that writes each run into its own sub directory of the output directory
+	versionedFlag = "versioned"
+	// keepRunsFlag is the name of the flag that contains the number of the versioned runs to keep
+	keepRunsFlag = "keep-runs"
+	// regenerateFlag is the name of the flag that contains the transformer whose output is regenerated from the saved IR
+	regenerateFlag = "regenerate"
+	// regenerateIRFlag is the name of the flag that contains the IR files the output is regenerated from
+	regenerateIRFlag = "regenerate-ir"
 	// customizationsFlag is the path to customizations directory
 	customizationsFlag      = "customizations"
 	qadisablecliFlag        = "qa-disable-cli"
 	qaportFlag              = "qa-port"
+	qaHostFlag              = "qa-host"
+	qaListenerFdFlag        = "qa-listener-fd"
+	qagrpcFlag              = "qa-grpc"
 	planProgressPortFlag    = "plan-progress-port"
 	transformerSelectorFlag = "transformer-selector"
 )
@@ -54,18 +117,33 @@ const (
 type qaflags struct {
 	qadisablecli bool
 	qaport       int
+	qagrpc       bool
+	// qaHost is the host the QA REST/GRPC service listens on
+	qaHost string
+	// qaListenerFd is the file descriptor of the listener the QA REST/GRPC service serves on, passed by the server running the transformation
+	qaListenerFd int
 	// configOut contains the location to output the config
 	configOut string
 	// qaCacheOut contains the location to output the cache
 	qaCacheOut string
+	// qaAnswers contains a list of answer files recorded by previous runs, which are replayed in this run
+	qaAnswers []string
+	// answerSources contains a list of sources, like the environment variables or vault, which answer the questions using their IDs
+	answerSources []string
 	// configs contains a list of config files
 	configs []string
 	// Configs contains a list of key-value configs
 	setconfigs []string
+	// sets contains a list of question id and answer pairs
+	sets []string
 	// qaskip lets you skip all the question answers
 	qaskip bool
+	// qastrict uses the default answers and fails at the end if any question had no default
+	qastrict bool
 	// preSets contains a list of preset configurations
 	preSets []string
+	// profile selects the questions which are asked, the others are answered with their defaults
+	profile string
 	// persistPasswords sets whether to persist the password or not
 	persistPasswords bool
 }
@@ -0,0 +1,77 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"sigs.k8s.io/yaml"
+)
+
+type irDiffFlags struct {
+	format string
+}
+
+func irDiffHandler(flags irDiffFlags, args []string) {
+	oldIRPath := filepath.Clean(args[0])
+	newIRPath := filepath.Clean(args[1])
+	oldIR, err := irtypes.ReadIRFile(oldIRPath)
+	if err != nil {
+		logrus.Fatalf("failed to read the old IR. Error: %q", err)
+	}
+	newIR, err := irtypes.ReadIRFile(newIRPath)
+	if err != nil {
+		logrus.Fatalf("failed to read the new IR. Error: %q", err)
+	}
+	changes := irtypes.DiffIRs(oldIR.IR, newIR.IR)
+	var data []byte
+	switch flags.format {
+	case "json":
+		data, err = json.MarshalIndent(changes, "", "  ")
+	case "yaml":
+		data, err = yaml.Marshal(changes)
+	default:
+		logrus.Fatalf("unsupported output format %s . Supported formats are json and yaml", flags.format)
+	}
+	if err != nil {
+		logrus.Fatalf("failed to marshal the IR diff to %s . Error: %q", flags.format, err)
+	}
+	fmt.Println(string(data))
+}
+
+// GetIRDiffCommand returns a command to show the differences between two exported IRs
+func GetIRDiffCommand() *cobra.Command {
+	viper.AutomaticEnv()
+	flags := irDiffFlags{}
+	irDiffCmd := &cobra.Command{
+		Use:   "irdiff path/to/old/ir.yaml path/to/new/ir.yaml",
+		Short: "Show the differences between two exported IRs.",
+		Long: `Show the differences between two IR files exported by the transform command.
+	The services, containers, ports, environment variables and storages of the two IRs are compared.
+	This helps understand why the regenerated manifests changed after the source was changed.`,
+		Args: cobra.ExactArgs(2),
+		Run:  func(_ *cobra.Command, args []string) { irDiffHandler(flags, args) },
+	}
+	irDiffCmd.Flags().StringVarP(&flags.format, "format", "f", "yaml", "Output format of the diff. Supported formats are json and yaml.")
+	return irDiffCmd
+}
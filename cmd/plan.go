@@ -40,12 +40,17 @@ type planFlags struct {
 	name                  string
 	customizationsPath    string
 	transformerSelector   string
+	verifyKey             string
+	verifyIdentity        string
+	verifyOIDCIssuer      string
 	disableLocalExecution bool
 	failOnEmptyPlan       bool
 	//Configs contains a list of config files
 	configs []string
 	//Configs contains a list of key-value configs
 	setconfigs []string
+	//sets contains a list of question id and answer pairs
+	sets []string
 	//PreSets contains a list of preset configurations
 	preSets []string
 }
@@ -54,6 +59,7 @@ func planHandler(cmd *cobra.Command, flags planFlags) {
 	ctx, cancel := context.WithCancel(cmd.Context())
 	logrus.AddHook(common.NewCleanupHook(cancel))
 	logrus.AddHook(common.NewCleanupHook(lib.Destroy))
+	setupFailureSummary()
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
 	go func() {
 		<-ctx.Done()
@@ -76,7 +82,7 @@ func planHandler(cmd *cobra.Command, flags planFlags) {
 			// make all path(s) absolute
 			flags.customizationsPath, err = filepath.Abs(flags.customizationsPath)
 			if err != nil {
-				logrus.Fatalf("Failed to make the customizations directory path %q absolute. Error: %q", flags.customizationsPath, err)
+				fatalf(validationFailure, nil, "Failed to make the customizations directory path %q absolute. Error: %q", flags.customizationsPath, err)
 			}
 		}
 	}
@@ -90,7 +96,7 @@ func planHandler(cmd *cobra.Command, flags planFlags) {
 	// make all path(s) absolute
 	for i, c := range flags.configs {
 		if c, err := filepath.Abs(c); err != nil {
-			logrus.Fatalf("failed to make the config file path %s absolute. Error: %q", c, err)
+			fatalf(validationFailure, nil, "failed to make the config file path %s absolute. Error: %q", c, err)
 		}
 		flags.configs[i] = c
 	}
@@ -98,24 +104,27 @@ func planHandler(cmd *cobra.Command, flags planFlags) {
 	customizationsPath := flags.customizationsPath
 	// Global settings
 	common.DisableLocalExecution = flags.disableLocalExecution
+	common.SignatureVerificationKey = flags.verifyKey
+	common.SignatureVerificationIdentity = flags.verifyIdentity
+	common.SignatureVerificationOIDCIssuer = flags.verifyOIDCIssuer
 	// Global settings
 
 	planfile, err = filepath.Abs(planfile)
 	if err != nil {
-		logrus.Fatalf("Failed to make the plan file path %q absolute. Error: %q", planfile, err)
+		fatalf(validationFailure, nil, "Failed to make the plan file path %q absolute. Error: %q", planfile, err)
 	}
 	var fi fs.FileInfo
 	if srcpath != "" {
 		srcpath, err = filepath.Abs(srcpath)
 		if err != nil {
-			logrus.Fatalf("Failed to make the source directory path %q absolute. Error: %q", srcpath, err)
+			fatalf(validationFailure, nil, "Failed to make the source directory path %q absolute. Error: %q", srcpath, err)
 		}
 		fi, err = os.Stat(srcpath)
 		if err != nil {
-			logrus.Fatalf("Unable to access source directory : %s", err)
+			fatalf(validationFailure, nil, "Unable to access source directory : %s", err)
 		}
 		if !fi.IsDir() {
-			logrus.Fatalf("Input is a file, expected directory: %s", srcpath)
+			fatalf(validationFailure, nil, "Input is a file, expected directory: %s", srcpath)
 		}
 	}
 	fi, err = os.Stat(planfile)
@@ -126,30 +135,31 @@ func planHandler(cmd *cobra.Command, flags planFlags) {
 			planfile = filepath.Join(planfile, common.DefaultPlanFile)
 		}
 	} else if err != nil {
-		logrus.Fatalf("Error while accessing plan file path %s : %s ", planfile, err)
+		fatalf(validationFailure, nil, "Error while accessing plan file path %s : %s ", planfile, err)
 	} else if fi.IsDir() {
 		planfile = filepath.Join(planfile, common.DefaultPlanFile)
 	}
-	qaengine.StartEngine(true, 0, true)
-	qaengine.SetupConfigFile("", flags.setconfigs, flags.configs, flags.preSets, false)
+	qaengine.StartEngine(true, false, 0, true, false)
+	qaengine.SetupConfigFile("", append(flags.setconfigs, getConfigStringsFromSets(flags.sets)...), addProjectConfigFile(srcpath, flags.configs), flags.preSets, false)
 	if flags.progressServerPort != 0 {
 		startPlanProgressServer(flags.progressServerPort)
 	}
 	p, err := lib.CreatePlan(ctx, srcpath, "", customizationsPath, flags.transformerSelector, name)
 	if err != nil {
-		logrus.Fatalf("failed to create the plan. Error: %q", err)
+		fatalf(planFailure, nil, "failed to create the plan. Error: %q", err)
 	}
 	if err = plantypes.WritePlan(planfile, p); err != nil {
-		logrus.Fatalf("failed to write the plan to file at path %s . Error: %q", planfile, err)
+		fatalf(writeFailure, nil, "failed to write the plan to file at path %s . Error: %q", planfile, err)
 	}
 	logrus.Debugf("Plan : %+v", p)
 	logrus.Infof("Plan can be found at [%s].", planfile)
 	if len(p.Spec.Services) == 0 && len(p.Spec.InvokedByDefaultTransformers) == 0 {
 		if flags.failOnEmptyPlan {
-			logrus.Fatalf("Did not detect any services in the directory %s . Also we didn't find any default transformers to run.", srcpath)
+			fatalf(planFailure, nil, "Did not detect any services in the directory %s . Also we didn't find any default transformers to run.", srcpath)
 		}
 		logrus.Warnf("Did not detect any services in the directory %s . Also we didn't find any default transformers to run.", srcpath)
 	}
+	printSuccessSummary()
 }
 
 // GetPlanCommand returns a command to do the planning
@@ -172,14 +182,18 @@ func GetPlanCommand() *cobra.Command {
 	planCmd.Flags().StringVarP(&flags.srcpath, sourceFlag, "s", "", "Specify source directory.")
 	planCmd.Flags().StringVarP(&flags.planfile, planFlag, "p", common.DefaultPlanFile, "Specify a file path to save plan to.")
 	planCmd.Flags().StringVarP(&flags.name, nameFlag, "n", common.DefaultProjectName, "Specify the project name.")
-	planCmd.Flags().StringVarP(&flags.customizationsPath, customizationsFlag, "c", "", "Specify directory where customizations are stored. By default we look for "+common.DefaultCustomizationDir)
-	planCmd.Flags().StringSliceVarP(&flags.configs, configFlag, "f", []string{}, "Specify config file locations. By default we look for "+common.DefaultConfigFilePath)
+	planCmd.Flags().StringVarP(&flags.customizationsPath, customizationsFlag, "c", "", "Specify directory where customizations are stored, a git url with an optional ref (example: https://github.com/myorg/customizations.git#v1) or an OCI artifact (example: oci://quay.io/myorg/customizations:v1). The remote customizations are cached. By default we look for "+common.DefaultCustomizationDir)
+	planCmd.Flags().StringSliceVarP(&flags.configs, configFlag, "f", []string{}, "Specify config file locations. By default we look for "+common.DefaultConfigFilePath+". A "+common.ProjectConfigFile+" file in the root of the source directory is also used, with a lower priority.")
 	planCmd.Flags().StringVarP(&flags.transformerSelector, transformerSelectorFlag, "t", "", "Specify the transformer selector.")
 	planCmd.Flags().StringSliceVar(&flags.preSets, preSetFlag, []string{}, "Specify preset config to use.")
 	planCmd.Flags().StringArrayVar(&flags.setconfigs, setConfigFlag, []string{}, "Specify config key-value pairs.")
+	planCmd.Flags().StringArrayVar(&flags.sets, setFlag, []string{}, "Answer a question using its id. Format: <question id>=<answer> . Example: --set 'move2kube.target.\"default\".clustertype=Kubernetes'")
 	planCmd.Flags().IntVar(&flags.progressServerPort, planProgressPortFlag, 0, "Port for the plan progress server. If not provided, the server won't be started.")
 	planCmd.Flags().BoolVar(&flags.disableLocalExecution, common.DisableLocalExecutionFlag, false, "Allow files to be executed locally.")
 	planCmd.Flags().BoolVar(&flags.failOnEmptyPlan, common.FailOnEmptyPlan, false, "If true, planning will exit with a failure exit code if no services are detected (and no default transformers are found).")
+	planCmd.Flags().StringVar(&flags.verifyKey, common.VerifyKeyFlag, "", "Specify the cosign public key, or KMS url, to verify the signatures of the remote customizations and templates.")
+	planCmd.Flags().StringVar(&flags.verifyIdentity, common.VerifyIdentityFlag, "", "Specify the certificate identity for the keyless verification of the signatures of the remote customizations and templates. Example: https://github.com/myorg/customizations/.github/workflows/sign.yaml@refs/heads/main")
+	planCmd.Flags().StringVar(&flags.verifyOIDCIssuer, common.VerifyOIDCIssuerFlag, "", "Specify the OIDC issuer of the certificate identity for the keyless verification. Example: https://token.actions.githubusercontent.com")
 
 	must(planCmd.Flags().MarkHidden(planProgressPortFlag))
 
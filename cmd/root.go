@@ -29,6 +29,7 @@ import (
 func GetRootCmd() *cobra.Command {
 	loglevel := logrus.InfoLevel.String()
 	logFile := ""
+	logFormat := common.TextLogFormat
 
 	// RootCmd root level flags and commands
 	rootCmd := &cobra.Command{
@@ -47,6 +48,9 @@ func GetRootCmd() *cobra.Command {
 				logl = logrus.InfoLevel
 			}
 			logrus.SetLevel(logl)
+			if err := common.SetLogFormat(logFormat); err != nil {
+				logrus.Errorf("%s . Using the %s log format instead.", err, common.TextLogFormat)
+			}
 			if logFile != "" {
 				f, err := os.OpenFile(logFile, os.O_WRONLY|os.O_CREATE|os.O_APPEND, common.DefaultFilePermission)
 				if err != nil {
@@ -60,6 +64,7 @@ func GetRootCmd() *cobra.Command {
 
 	rootCmd.PersistentFlags().StringVar(&loglevel, "log-level", logrus.InfoLevel.String(), "Set logging levels.")
 	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "File to store the logs in. By default it only prints to console.")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", common.TextLogFormat, "Set the log format. Supported formats are "+common.TextLogFormat+" and "+common.JSONLogFormat+".")
 
 	rootCmd.AddCommand(GetVersionCommand())
 	rootCmd.AddCommand(GetCollectCommand())
@@ -67,5 +72,7 @@ func GetRootCmd() *cobra.Command {
 	rootCmd.AddCommand(GetTransformCommand())
 	rootCmd.AddCommand(GetGenerateDocsCommand())
 	rootCmd.AddCommand(GetGraphCommand())
+	rootCmd.AddCommand(GetIRDiffCommand())
+	rootCmd.AddCommand(GetServeCommand())
 	return rootCmd
 }
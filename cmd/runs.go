@@ -0,0 +1,183 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/info"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// runMetadataFile is written into the directory of each run, it also marks the directories which are runs
+	runMetadataFile = "m2k-run.json"
+	// latestRunLink points to the directory of the latest successful run
+	latestRunLink = "latest"
+	// runIDTimeFormat keeps the run IDs sorted in the order of the runs
+	runIDTimeFormat = "20060102-150405"
+)
+
+const (
+	runningRunStatus   = "running"
+	succeededRunStatus = "succeeded"
+	failedRunStatus    = "failed"
+)
+
+// RunMetadata describes a run of the transformation written into its own directory
+type RunMetadata struct {
+	ID                string    `json:"id"`
+	Status            string    `json:"status"`
+	StartTime         time.Time `json:"startTime"`
+	EndTime           time.Time `json:"endTime,omitempty"`
+	Version           string    `json:"version"`
+	Args              []string  `json:"args"`
+	SourceDir         string    `json:"sourceDir,omitempty"`
+	PlanFile          string    `json:"planFile,omitempty"`
+	CustomizationsDir string    `json:"customizationsDir,omitempty"`
+	QAAnswersSHA256   string    `json:"qaAnswersSHA256,omitempty"`
+}
+
+// newRunID returns a unique ID for the run, starting with the time of the run
+func newRunID(now time.Time) string {
+	suffix := make([]byte, 3)
+	if _, err := rand.Read(suffix); err != nil {
+		logrus.Debugf("failed to generate a random suffix for the run ID. Error: %q", err)
+		return now.UTC().Format(runIDTimeFormat)
+	}
+	return now.UTC().Format(runIDTimeFormat) + "-" + hex.EncodeToString(suffix)
+}
+
+// writeRunMetadata writes the metadata file into the directory of the run
+func writeRunMetadata(runOutpath string, metadata RunMetadata) {
+	data, err := json.MarshalIndent(metadata, "", "  ")
+	if err != nil {
+		logrus.Errorf("failed to marshal the metadata of the run %s to json. Error: %q", metadata.ID, err)
+		return
+	}
+	if err := os.MkdirAll(runOutpath, common.DefaultDirectoryPermission); err != nil {
+		logrus.Errorf("failed to create the directory of the run at path %s . Error: %q", runOutpath, err)
+		return
+	}
+	if err := os.WriteFile(filepath.Join(runOutpath, runMetadataFile), data, common.DefaultFilePermission); err != nil {
+		logrus.Errorf("failed to write the metadata of the run %s . Error: %q", metadata.ID, err)
+	}
+}
+
+// updateLatestRunLink points the latest link in the project directory to the run, the link is replaced atomically
+func updateLatestRunLink(projectOutpath, runID string) error {
+	linkPath := filepath.Join(projectOutpath, latestRunLink)
+	tempLinkPath := linkPath + "." + runID
+	// the link is relative, so that the project directory can be moved
+	if err := os.Symlink(runID, tempLinkPath); err != nil {
+		return fmt.Errorf("failed to create the symbolic link at path %s . Error: %w", tempLinkPath, err)
+	}
+	if err := os.Rename(tempLinkPath, linkPath); err != nil {
+		os.Remove(tempLinkPath)
+		return fmt.Errorf("failed to replace the symbolic link at path %s . Error: %w", linkPath, err)
+	}
+	return nil
+}
+
+// getRunIDs returns the IDs of the runs in the project directory, from the oldest to the newest
+func getRunIDs(projectOutpath string) ([]string, error) {
+	entries, err := os.ReadDir(projectOutpath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the directory %s . Error: %w", projectOutpath, err)
+	}
+	runIDs := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		// the directories written by the user are left alone
+		if _, err := os.Stat(filepath.Join(projectOutpath, entry.Name(), runMetadataFile)); err != nil {
+			continue
+		}
+		runIDs = append(runIDs, entry.Name())
+	}
+	sort.Strings(runIDs)
+	return runIDs, nil
+}
+
+// pruneRuns deletes the oldest runs in the project directory, keeping the given number of runs and the latest run
+func pruneRuns(projectOutpath string, keep int, latestRunID string) {
+	if keep <= 0 {
+		return
+	}
+	runIDs, err := getRunIDs(projectOutpath)
+	if err != nil {
+		logrus.Errorf("failed to find the runs to delete. Error: %q", err)
+		return
+	}
+	if len(runIDs) <= keep {
+		return
+	}
+	for _, runID := range runIDs[:len(runIDs)-keep] {
+		if runID == latestRunID {
+			continue
+		}
+		logrus.Infof("Deleting the run %s , since only the latest %d runs are kept.", runID, keep)
+		if err := os.RemoveAll(filepath.Join(projectOutpath, runID)); err != nil {
+			logrus.Errorf("failed to delete the run %s . Error: %q", runID, err)
+		}
+	}
+}
+
+// startRun returns the output directory of a new run in the project directory. The run is marked as failed if the transformation exits with an error.
+func startRun(projectOutpath string, flags transformFlags) (string, *RunMetadata) {
+	now := time.Now()
+	metadata := &RunMetadata{
+		ID:                newRunID(now),
+		Status:            runningRunStatus,
+		StartTime:         now,
+		Version:           info.GetVersion(),
+		Args:              os.Args[1:],
+		SourceDir:         flags.srcpath,
+		CustomizationsDir: flags.customizationsPath,
+	}
+	if _, err := os.Stat(flags.planfile); err == nil {
+		metadata.PlanFile = flags.planfile
+	}
+	runOutpath := filepath.Join(projectOutpath, metadata.ID)
+	logrus.AddHook(common.NewCleanupHook(func() { finishRun(runOutpath, metadata, failedRunStatus) }))
+	return runOutpath, metadata
+}
+
+// finishRun records the outcome of the run in its metadata file
+func finishRun(runOutpath string, metadata *RunMetadata, status string) {
+	if metadata.Status != runningRunStatus {
+		return
+	}
+	if _, err := os.Stat(runOutpath); err != nil {
+		// nothing was written for the run
+		return
+	}
+	metadata.Status = status
+	metadata.EndTime = time.Now()
+	metadata.QAAnswersSHA256 = qaengine.GetAnswersDigest()
+	writeRunMetadata(runOutpath, *metadata)
+}
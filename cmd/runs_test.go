@@ -0,0 +1,147 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// readRunMetadata reads the metadata file in the directory of the run
+func readRunMetadata(t *testing.T, runOutpath string) RunMetadata {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(runOutpath, runMetadataFile))
+	if err != nil {
+		t.Fatalf("failed to read the metadata of the run at path %s . Error: %q", runOutpath, err)
+	}
+	metadata := RunMetadata{}
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		t.Fatalf("failed to unmarshal the metadata of the run at path %s . Error: %q", runOutpath, err)
+	}
+	return metadata
+}
+
+func TestNewRunID(t *testing.T) {
+	now := time.Date(2026, 3, 4, 5, 6, 7, 0, time.FixedZone("IST", 19800))
+	runID := newRunID(now)
+	if !regexp.MustCompile(`^20260303-233607-[0-9a-f]{6}$`).MatchString(runID) {
+		t.Fatalf("expected the run ID to start with the time of the run in UTC, got %s", runID)
+	}
+	if otherRunID := newRunID(now); otherRunID == runID {
+		t.Fatalf("expected the runs started at the same time to have different IDs, got %s twice", runID)
+	}
+	if laterRunID := newRunID(now.Add(time.Second)); laterRunID <= runID {
+		t.Fatalf("expected the ID %s of the later run to sort after %s", laterRunID, runID)
+	}
+}
+
+func TestFinishRun(t *testing.T) {
+	projectOutpath := t.TempDir()
+	metadata := &RunMetadata{ID: "20260101-000000-aaaaaa", Status: runningRunStatus, StartTime: time.Now(), Args: []string{"transform"}}
+	runOutpath := filepath.Join(projectOutpath, metadata.ID)
+
+	finishRun(runOutpath, metadata, failedRunStatus)
+	if _, err := os.Stat(runOutpath); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing to be written for a run which did not write its output")
+	}
+
+	writeRunMetadata(runOutpath, *metadata)
+	if got := readRunMetadata(t, runOutpath); got.Status != runningRunStatus || !cmp.Equal(got.Args, metadata.Args) {
+		t.Fatalf("expected the metadata of the running run, got %+v", got)
+	}
+	finishRun(runOutpath, metadata, succeededRunStatus)
+	got := readRunMetadata(t, runOutpath)
+	if got.Status != succeededRunStatus || got.EndTime.IsZero() || got.QAAnswersSHA256 == "" {
+		t.Fatalf("expected the run to be recorded as succeeded with its end time and the digest of the answers, got %+v", got)
+	}
+	// the failure hook runs after the run succeeded when a later step fails, which should not change the outcome
+	finishRun(runOutpath, metadata, failedRunStatus)
+	if got := readRunMetadata(t, runOutpath); got.Status != succeededRunStatus {
+		t.Fatalf("expected the status of a finished run to be kept, got %s", got.Status)
+	}
+}
+
+func TestUpdateLatestRunLink(t *testing.T) {
+	projectOutpath := t.TempDir()
+	for _, runID := range []string{"20260101-000000-aaaaaa", "20260102-000000-bbbbbb"} {
+		if err := os.Mkdir(filepath.Join(projectOutpath, runID), 0755); err != nil {
+			t.Fatalf("failed to create the directory of the run. Error: %q", err)
+		}
+		if err := updateLatestRunLink(projectOutpath, runID); err != nil {
+			t.Fatalf("failed to update the latest link to the run %s . Error: %q", runID, err)
+		}
+		target, err := os.Readlink(filepath.Join(projectOutpath, latestRunLink))
+		if err != nil {
+			t.Fatalf("failed to read the latest link. Error: %q", err)
+		}
+		if target != runID {
+			t.Fatalf("expected the latest link to point to %s , got %s", runID, target)
+		}
+	}
+	entries, err := os.ReadDir(projectOutpath)
+	if err != nil {
+		t.Fatalf("failed to read the project directory. Error: %q", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("expected the temporary links to be renamed, got the entries %+v", entries)
+	}
+}
+
+func TestPruneRuns(t *testing.T) {
+	projectOutpath := t.TempDir()
+	runIDs := []string{"20260101-000000-aaaaaa", "20260102-000000-bbbbbb", "20260103-000000-cccccc", "20260104-000000-dddddd"}
+	for _, runID := range runIDs {
+		writeRunMetadata(filepath.Join(projectOutpath, runID), RunMetadata{ID: runID, Status: succeededRunStatus})
+	}
+	// the directories without the metadata file are not runs
+	if err := os.Mkdir(filepath.Join(projectOutpath, "notes"), 0755); err != nil {
+		t.Fatalf("failed to create the directory. Error: %q", err)
+	}
+	if err := updateLatestRunLink(projectOutpath, runIDs[3]); err != nil {
+		t.Fatalf("failed to update the latest link. Error: %q", err)
+	}
+	got, err := getRunIDs(projectOutpath)
+	if err != nil {
+		t.Fatalf("failed to get the runs. Error: %q", err)
+	}
+	if diff := cmp.Diff(runIDs, got); diff != "" {
+		t.Fatalf("got the wrong runs. Diff (-want +got):\n%s", diff)
+	}
+
+	pruneRuns(projectOutpath, 0, runIDs[3])
+	if got, _ := getRunIDs(projectOutpath); len(got) != len(runIDs) {
+		t.Fatalf("expected all the runs to be kept by default, got %+v", got)
+	}
+	// the latest run is kept even if it is not one of the newest runs
+	pruneRuns(projectOutpath, 2, runIDs[0])
+	got, err = getRunIDs(projectOutpath)
+	if err != nil {
+		t.Fatalf("failed to get the runs. Error: %q", err)
+	}
+	if diff := cmp.Diff([]string{runIDs[0], runIDs[2], runIDs[3]}, got); diff != "" {
+		t.Fatalf("got the wrong runs after pruning. Diff (-want +got):\n%s", diff)
+	}
+	if _, err := os.Stat(filepath.Join(projectOutpath, "notes")); err != nil {
+		t.Fatalf("expected the directories which are not runs to be kept. Error: %q", err)
+	}
+}
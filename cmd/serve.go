@@ -0,0 +1,96 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/konveyor/move2kube/server"
+	"github.com/konveyor/move2kube/types"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type serveFlags struct {
+	host          string
+	port          int
+	workspacePath string
+	maxParallel   int
+	maxQueued     int
+	timeout       time.Duration
+	cpus          int
+	memory        string
+	maxUploadSize int64
+	finishedTTL   time.Duration
+}
+
+func serveHandler(flags serveFlags) {
+	s, err := server.NewServer(server.Config{
+		Address:                    fmt.Sprintf("%s:%d", flags.host, flags.port),
+		WorkspacePath:              flags.workspacePath,
+		MaxParallelTransformations: flags.maxParallel,
+		MaxQueuedTransformations:   flags.maxQueued,
+		MaxUploadSize:              flags.maxUploadSize,
+		FinishedTransformationTTL:  flags.finishedTTL,
+		Limits:                     server.Limits{Timeout: flags.timeout, CPUs: flags.cpus, Memory: flags.memory},
+	})
+	if err != nil {
+		logrus.Fatalf("failed to create the transformation server. Error: %q", err)
+	}
+	signalCh := make(chan os.Signal, 1)
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signalCh
+		logrus.Info("Stopping the running transformations")
+		s.Stop()
+		os.Exit(0)
+	}()
+	logrus.Fatalf("transformation server stopped. Error: %q", s.Start())
+}
+
+// GetServeCommand returns a command to run the transformations as a service
+func GetServeCommand() *cobra.Command {
+	viper.AutomaticEnv()
+	flags := serveFlags{}
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run the transformations as a service using a REST API.",
+		Long: `Run the transformations as a service using a REST API.
+	Upload the source archive to ` + server.APIPrefix + `/transformations as a multipart form, answer the questions using
+	` + server.APIPrefix + `/transformations/{id}/problems, poll ` + server.APIPrefix + `/transformations/{id} for the status and download the
+	output archive from ` + server.APIPrefix + `/transformations/{id}/output . Each transformation runs in a separate ` + types.AppName + ` process.`,
+		Args: cobra.NoArgs,
+		Run:  func(_ *cobra.Command, __ []string) { serveHandler(flags) },
+	}
+	serveCmd.Flags().StringVar(&flags.host, serverHostFlag, "127.0.0.1", "Host to start the server on. Use 0.0.0.0 to accept the requests from other machines.")
+	serveCmd.Flags().IntVarP(&flags.port, serverPortFlag, "p", 8080, "Port to start the server on.")
+	serveCmd.Flags().StringVar(&flags.workspacePath, workspaceFlag, filepath.Join(os.TempDir(), types.AppNameShort+"-server"), "Directory where the sources, logs and outputs of the transformations are stored.")
+	serveCmd.Flags().IntVar(&flags.maxParallel, maxParallelFlag, 4, "Maximum number of transformations which run at the same time. The other transformations are queued. Zero means no limit.")
+	serveCmd.Flags().IntVar(&flags.maxQueued, maxQueuedFlag, 100, "Maximum number of transformations waiting to start. The requests are rejected once it is reached. Zero means no limit.")
+	serveCmd.Flags().DurationVar(&flags.timeout, transformationTimeoutFlag, 0, "Stop the transformations which take longer than this. Example: 30m . Zero means no limit.")
+	serveCmd.Flags().IntVar(&flags.cpus, cpusFlag, 0, "Maximum number of CPUs each transformation uses at the same time. Zero means no limit.")
+	serveCmd.Flags().StringVar(&flags.memory, memoryFlag, "", "Soft memory limit of each transformation. Example: 2GiB . By default there is no limit.")
+	serveCmd.Flags().Int64Var(&flags.maxUploadSize, maxUploadSizeFlag, server.DefaultMaxUploadSize, "Maximum size in bytes of the requests uploading the sources and the config. The larger requests are rejected.")
+	serveCmd.Flags().DurationVar(&flags.finishedTTL, finishedTTLFlag, server.DefaultFinishedTransformationTTL, "Remove the finished transformations and their files after this duration. Zero keeps them until they are deleted using the API.")
+	return serveCmd
+}
@@ -18,12 +18,17 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"os"
 	"os/signal"
 	"path/filepath"
+	"strings"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/filesystem"
 	"github.com/konveyor/move2kube/lib"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer"
 	"github.com/konveyor/move2kube/types/plan"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -46,15 +51,50 @@ type transformFlags struct {
 	name string
 	// overwrite lets you overwrite the output directory if it exists
 	overwrite bool
+	// versioned writes each run into a new sub directory of the project output directory
+	versioned bool
+	// keepRuns is the number of versioned runs to keep, all the runs are kept when it is zero
+	keepRuns int
+	// regenerate is the name of the only transformer run on the saved IR
+	regenerate string
+	// regenerateIRs contains the IR files the output is regenerated from, the IR exported into the output directory is used by default
+	regenerateIRs []string
+	// dryRun lists the files that would be written to the output directory without writing them
+	dryRun bool
+	// scriptLineEndings controls the line endings of the generated scripts
+	scriptLineEndings string
+	// outputBucket is the url of the object storage bucket the output is uploaded to, instead of keeping it in the output directory
+	outputBucket string
+	// archiveFormat packages the output directory into a single archive of this format
+	archiveFormat string
+	// strict fails the transformation when any of the generated objects are ignored
+	strict bool
+	// watch transforms again when the source or the customizations change
+	watch bool
+	// review lets the user exclude the generated resources before they are written
+	review bool
+	// signProvenanceKey is the cosign key used to sign the provenance of the output
+	signProvenanceKey string
+	// serviceGroups split the output into groups of services of the form <group>=<pattern>,<pattern>
+	serviceGroups []string
+	// customTemplatesPath contains the path to the directory with templates that override the built-in templates
+	customTemplatesPath string
+	// templateValues contains the list of value files the templates can use
+	templateValues []string
 	// CustomizationsPaths contains the path to the customizations directory
 	customizationsPath  string
 	transformerSelector string
+	// verifyKey, verifyIdentity and verifyOIDCIssuer are used to verify the signatures of the remote customizations and templates
+	verifyKey        string
+	verifyIdentity   string
+	verifyOIDCIssuer string
 }
 
 func transformHandler(cmd *cobra.Command, flags transformFlags) {
 	ctx, cancel := context.WithCancel(cmd.Context())
 	logrus.AddHook(common.NewCleanupHook(cancel))
 	logrus.AddHook(common.NewCleanupHook(lib.Destroy))
+	setupFailureSummary()
 	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
 	go func() {
 		<-ctx.Done()
@@ -66,15 +106,15 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 
 	var err error
 	if flags.planfile, err = filepath.Abs(flags.planfile); err != nil {
-		logrus.Fatalf("Failed to make the plan file path %q absolute. Error: %q", flags.planfile, err)
+		fatalf(validationFailure, nil, "Failed to make the plan file path %q absolute. Error: %q", flags.planfile, err)
 	}
 	if flags.srcpath != "" {
 		if flags.srcpath, err = filepath.Abs(flags.srcpath); err != nil {
-			logrus.Fatalf("Failed to make the source directory path %q absolute. Error: %q", flags.srcpath, err)
+			fatalf(validationFailure, nil, "Failed to make the source directory path %q absolute. Error: %q", flags.srcpath, err)
 		}
 	}
 	if flags.outpath, err = filepath.Abs(flags.outpath); err != nil {
-		logrus.Fatalf("Failed to make the output directory path %q absolute. Error: %q", flags.outpath, err)
+		fatalf(validationFailure, nil, "Failed to make the output directory path %q absolute. Error: %q", flags.outpath, err)
 	}
 	// Check if the default customization folder exists in the working directory.
 	// If not, skip the customization option
@@ -84,7 +124,7 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 			// make all path(s) absolute
 			flags.customizationsPath, err = filepath.Abs(flags.customizationsPath)
 			if err != nil {
-				logrus.Fatalf("Failed to make the customizations directory path %q absolute. Error: %q", flags.customizationsPath, err)
+				fatalf(validationFailure, nil, "Failed to make the customizations directory path %q absolute. Error: %q", flags.customizationsPath, err)
 			}
 		}
 	}
@@ -98,15 +138,80 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 	// make all path(s) absolute
 	for i, c := range flags.configs {
 		if c, err := filepath.Abs(c); err != nil {
-			logrus.Fatalf("failed to make the config file path %s absolute. Error: %q", c, err)
+			fatalf(validationFailure, nil, "failed to make the config file path %s absolute. Error: %q", c, err)
 		}
 		flags.configs[i] = c
 	}
+	flags.setconfigs = append(flags.setconfigs, getConfigStringsFromSets(flags.sets)...)
+	for i, a := range flags.qaAnswers {
+		a, err := filepath.Abs(a)
+		if err != nil {
+			fatalf(validationFailure, nil, "failed to make the answers file path %s absolute. Error: %q", a, err)
+		}
+		if _, err := os.Stat(a); err != nil {
+			fatalf(validationFailure, nil, "failed to find the answers file at path %s . Error: %q", a, err)
+		}
+		flags.qaAnswers[i] = a
+	}
 
 	// Global settings
 	common.IgnoreEnvironment = flags.ignoreEnv
 	common.DisableLocalExecution = flags.disableLocalExecution
+	common.DryRun = flags.dryRun
+	common.SignatureVerificationKey = flags.verifyKey
+	common.SignatureVerificationIdentity = flags.verifyIdentity
+	common.SignatureVerificationOIDCIssuer = flags.verifyOIDCIssuer
+	common.ProvenanceSigningKey = flags.signProvenanceKey
+	common.ReviewResources = flags.review
+	common.ServiceGroups = flags.serviceGroups
+	switch flags.scriptLineEndings {
+	case common.NativeScriptLineEndings, common.LFScriptLineEndings, common.CRLFScriptLineEndings:
+		common.ScriptLineEndings = flags.scriptLineEndings
+	default:
+		fatalf(validationFailure, nil, "Unsupported value %s for the flag --%s . Supported values are %s, %s and %s", flags.scriptLineEndings, scriptLineEndingsFlag, common.NativeScriptLineEndings, common.LFScriptLineEndings, common.CRLFScriptLineEndings)
+	}
+	if flags.profile != "" && !common.IsPresent(qaengine.Profiles, flags.profile) {
+		fatalf(validationFailure, nil, "Unsupported value %s for the flag --%s . Supported values are %s", flags.profile, qaProfileFlag, strings.Join(qaengine.Profiles, ", "))
+	}
+	if flags.archiveFormat != "" && !common.IsPresent(lib.ArchiveFormats, flags.archiveFormat) {
+		fatalf(validationFailure, nil, "Unsupported value %s for the flag --%s . Supported values are %s", flags.archiveFormat, archiveFlag, strings.Join(lib.ArchiveFormats, ", "))
+	}
+	if flags.watch && (flags.dryRun || flags.archiveFormat != "") {
+		fatalf(validationFailure, nil, "The flag --%s can not be used along with the flags --%s and --%s", watchFlag, dryRunFlag, archiveFlag)
+	}
+	if flags.versioned && (flags.watch || flags.dryRun || flags.outputBucket != "") {
+		fatalf(validationFailure, nil, "The flag --%s can not be used along with the flags --%s, --%s and --%s", versionedFlag, watchFlag, dryRunFlag, outputBucketFlag)
+	}
+	if flags.regenerate != "" && (flags.watch || flags.dryRun || flags.versioned || flags.outputBucket != "" || flags.archiveFormat != "" || len(flags.serviceGroups) != 0) {
+		fatalf(validationFailure, nil, "The flag --%s can not be used along with the flags --%s, --%s, --%s, --%s, --%s and --%s", regenerateFlag, watchFlag, dryRunFlag, versionedFlag, outputBucketFlag, archiveFlag, serviceGroupFlag)
+	}
+	if len(flags.regenerateIRs) != 0 && flags.regenerate == "" {
+		fatalf(validationFailure, nil, "The flag --%s can only be used along with the flag --%s", regenerateIRFlag, regenerateFlag)
+	}
+	if flags.keepRuns < 0 || (flags.keepRuns != 0 && !flags.versioned) {
+		fatalf(validationFailure, nil, "The flag --%s needs a positive number of runs and the flag --%s", keepRunsFlag, versionedFlag)
+	}
+	if flags.outputBucket != "" {
+		if !filesystem.IsBucketURL(flags.outputBucket) {
+			fatalf(validationFailure, nil, "Unsupported value %s for the flag --%s . Supported urls start with %s://", flags.outputBucket, outputBucketFlag, strings.Join(filesystem.BucketSchemes, ":// , "))
+		}
+		if flags.watch || flags.dryRun || flags.archiveFormat != "" {
+			fatalf(validationFailure, nil, "The flag --%s can not be used along with the flags --%s, --%s and --%s", outputBucketFlag, watchFlag, dryRunFlag, archiveFlag)
+		}
+	}
 	// Global settings
+	if flags.dryRun {
+		flags.configOut = ""
+		flags.qaCacheOut = ""
+	}
+	dryRunOutpath := ""
+	var run *RunMetadata
+	if err := lib.ApplyCustomTemplates(flags.customTemplatesPath); err != nil {
+		logrus.Fatalf("failed to apply the custom templates. Error: %q", err)
+	}
+	if err := filesystem.LoadTemplateValues(flags.templateValues); err != nil {
+		fatalf(validationFailure, nil, "failed to load the values of the templates. Error: %q", err)
+	}
 
 	// Parameter cleaning and curate plan
 	transformationPlan := plan.Plan{}
@@ -119,30 +224,55 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 	if err != nil {
 		logrus.Debugf("No plan file found.")
 		if cmd.Flags().Changed(planFlag) {
-			logrus.Fatalf("Error while accessing plan file at path %s Error: %q", flags.planfile, err)
+			fatalf(planFailure, nil, "Error while accessing plan file at path %s Error: %q", flags.planfile, err)
 		}
+		if flags.regenerate != "" {
+			fatalf(planFailure, nil, "The flag --%s needs the plan file of the previous transformation. Error while accessing plan file at path %s Error: %q", regenerateFlag, flags.planfile, err)
+		}
+
+		flags.configs = addProjectConfigFile(flags.srcpath, flags.configs)
+		startQA(flags.qaflags)
 
 		// Global settings
 		flags.outpath = filepath.Join(flags.outpath, flags.name)
-		checkOutputPath(flags.outpath, flags.overwrite)
+		if flags.versioned {
+			flags.outpath, run = startRun(flags.outpath, flags)
+		}
+		if !flags.dryRun {
+			checkOutputPath(flags.outpath, flags.overwrite)
+			flags.outpath = getWritableOutputPath(flags.outpath, flags.overwrite)
+			if flags.archiveFormat != "" {
+				checkArchivePath(flags.outpath+"."+flags.archiveFormat, flags.overwrite)
+			}
+		}
 		if flags.srcpath != "" {
 			checkSourcePath(flags.srcpath)
 			if flags.srcpath == flags.outpath || common.IsParent(flags.outpath, flags.srcpath) || common.IsParent(flags.srcpath, flags.outpath) {
-				logrus.Fatalf("The source path %s and output path %s overlap.", flags.srcpath, flags.outpath)
+				fatalf(validationFailure, nil, "The source path %s and output path %s overlap.", flags.srcpath, flags.outpath)
 			}
 		}
+		if flags.dryRun {
+			dryRunOutpath, flags.outpath = flags.outpath, getDryRunOutputPath(flags.outpath)
+		}
 		if err := os.MkdirAll(flags.outpath, common.DefaultDirectoryPermission); err != nil {
-			logrus.Fatalf("Failed to create the output directory at path %s Error: %q", flags.outpath, err)
+			fatalf(writeFailure, nil, "Failed to create the output directory at path %s Error: %q", flags.outpath, err)
+		}
+		if run != nil {
+			writeRunMetadata(flags.outpath, *run)
+		}
+		if flags.dryRun {
+			if err := transformer.SeedHandAuthoredFiles(dryRunOutpath, flags.outpath); err != nil {
+				fatalf(writeFailure, nil, "failed to copy the hand authored files from the output directory %s . Error: %q", dryRunOutpath, err)
+			}
 		}
-		startQA(flags.qaflags)
 		logrus.Debugf("Creating a new plan.")
 		transformationPlan, err = lib.CreatePlan(ctx, flags.srcpath, flags.outpath, flags.customizationsPath, flags.transformerSelector, flags.name)
 		if err != nil {
-			logrus.Fatalf("failed to create the plan. Error: %q", err)
+			fatalf(planFailure, nil, "failed to create the plan. Error: %q", err)
 		}
 		if len(transformationPlan.Spec.Services) == 0 && len(transformationPlan.Spec.InvokedByDefaultTransformers) == 0 {
 			logrus.Debugf("Plan : %+v", transformationPlan)
-			logrus.Fatalf("failed to find any services or default transformers. Aborting.")
+			fatalf(planFailure, nil, "failed to find any services or default transformers. Aborting.")
 		}
 	} else {
 		preExistingPlan = true
@@ -153,11 +283,11 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 			logrus.Warnf("Using the detected plan with specified source. If you did not want to use the plan file at %s, delete it and rerun the command.", flags.planfile)
 		}
 		if transformationPlan, err = plan.ReadPlan(flags.planfile, sourceDir); err != nil {
-			logrus.Fatalf("Unable to read the plan at path %s Error: %q", flags.planfile, err)
+			fatalf(planFailure, nil, "Unable to read the plan at path %s Error: %q", flags.planfile, err)
 		}
 		if len(transformationPlan.Spec.Services) == 0 && len(transformationPlan.Spec.InvokedByDefaultTransformers) == 0 {
 			logrus.Debugf("Plan : %+v", transformationPlan)
-			logrus.Fatalf("Failed to find any services or default transformers. Aborting.")
+			fatalf(planFailure, nil, "Failed to find any services or default transformers. Aborting.")
 		}
 		if cmd.Flags().Changed(nameFlag) {
 			transformationPlan.Name = flags.name
@@ -168,6 +298,8 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 				logrus.Warnf("Using the detected plan with specified customization. This might result in undesired results if the customization is different from what was given to plan. If you did not want to use the plan file at %s, delete it and rerun the command.", flags.planfile)
 			}
 		}
+		flags.configs = addProjectConfigFile(transformationPlan.Spec.SourceDir, flags.configs)
+		startQA(flags.qaflags)
 
 		// Global settings
 		if transformationPlan.Spec.SourceDir != "" {
@@ -175,65 +307,162 @@ func transformHandler(cmd *cobra.Command, flags transformFlags) {
 		}
 		lib.CheckAndCopyCustomizations(transformationPlan.Spec.CustomizationsDir)
 		flags.outpath = filepath.Join(flags.outpath, transformationPlan.Name)
-		checkOutputPath(flags.outpath, flags.overwrite)
+		if flags.versioned {
+			flags.outpath, run = startRun(flags.outpath, flags)
+		}
+		if flags.regenerate != "" {
+			// the output of the previous transformation is updated in place
+			if fi, err := os.Stat(flags.outpath); err != nil || !fi.IsDir() {
+				fatalf(validationFailure, nil, "The flag --%s needs the output directory of the previous transformation at path %s", regenerateFlag, flags.outpath)
+			}
+		} else if !flags.dryRun {
+			checkOutputPath(flags.outpath, flags.overwrite)
+			flags.outpath = getWritableOutputPath(flags.outpath, flags.overwrite)
+		}
 		if transformationPlan.Spec.SourceDir != "" && (transformationPlan.Spec.SourceDir == flags.outpath || common.IsParent(flags.outpath, transformationPlan.Spec.SourceDir) || common.IsParent(transformationPlan.Spec.SourceDir, flags.outpath)) {
-			logrus.Fatalf("The source path %s and output path %s overlap.", transformationPlan.Spec.SourceDir, flags.outpath)
+			fatalf(validationFailure, nil, "The source path %s and output path %s overlap.", transformationPlan.Spec.SourceDir, flags.outpath)
+		}
+		if flags.dryRun {
+			dryRunOutpath, flags.outpath = flags.outpath, getDryRunOutputPath(flags.outpath)
 		}
 		if err := os.MkdirAll(flags.outpath, common.DefaultDirectoryPermission); err != nil {
-			logrus.Fatalf("Failed to create the output directory at path %s Error: %q", flags.outpath, err)
+			fatalf(writeFailure, nil, "Failed to create the output directory at path %s Error: %q", flags.outpath, err)
 		}
-		startQA(flags.qaflags)
+		if run != nil {
+			writeRunMetadata(flags.outpath, *run)
+		}
+		if flags.dryRun {
+			if err := transformer.SeedHandAuthoredFiles(dryRunOutpath, flags.outpath); err != nil {
+				fatalf(writeFailure, nil, "failed to copy the hand authored files from the output directory %s . Error: %q", dryRunOutpath, err)
+			}
+		}
+	}
+	if flags.outputBucket != "" {
+		// the project directory is uploaded under the prefix of the bucket url
+		bucketFs, err := filesystem.NewBucketFs(flags.outputBucket, filepath.Dir(flags.outpath))
+		if err != nil {
+			fatalf(writeFailure, nil, "failed to set up the upload of the output to the bucket %s . Error: %q", flags.outputBucket, err)
+		}
+		lib.SetOutputFilesystem(bucketFs)
+	}
+	var failedErr *transformer.TransformationFailedError
+	var transformErr error
+	if flags.regenerate != "" {
+		transformErr = lib.Regenerate(ctx, transformationPlan, flags.outpath, flags.transformerSelector, flags.regenerate, flags.regenerateIRs)
+	} else {
+		transformErr = lib.Transform(ctx, transformationPlan, preExistingPlan, flags.outpath, flags.transformerSelector)
 	}
-	if err := lib.Transform(ctx, transformationPlan, preExistingPlan, flags.outpath, flags.transformerSelector); err != nil {
-		logrus.Fatalf("failed to transform. Error: %q", err)
+	checkWriteFailures(flags.outpath)
+	if transformErr != nil {
+		if !errors.As(transformErr, &failedErr) {
+			fatalf(conversionFailure, nil, "failed to transform. Error: %q", transformErr)
+		}
+	}
+	if flags.qastrict {
+		checkUnansweredQuestions()
 	}
-	logrus.Infof("Transformed target artifacts can be found at [%s].", flags.outpath)
+	if flags.dryRun {
+		printDryRunChanges(flags.outpath, dryRunOutpath)
+		logrus.Infof("Dry run finished. Nothing was written to [%s].", dryRunOutpath)
+	} else if flags.archiveFormat != "" {
+		archivePath, err := lib.ArchiveOutput(flags.outpath, flags.archiveFormat)
+		if err != nil {
+			fatalf(writeFailure, nil, "failed to archive the output directory %s . Error: %q", flags.outpath, err)
+		}
+		logrus.Infof("Transformed target artifacts can be found in the archive [%s].", archivePath)
+	} else if flags.outputBucket != "" {
+		logrus.Infof("Transformed target artifacts were uploaded to the bucket [%s].", flags.outputBucket)
+	} else {
+		logrus.Infof("Transformed target artifacts can be found at [%s].", flags.outpath)
+	}
+	if flags.strict && !flags.watch {
+		if count := transformer.GetIgnoredObjectsCount(); count != 0 {
+			fatalf(conversionFailure, nil, "%d of the generated objects were ignored. The details can be found in the %s file in the output directory.", count, transformer.ConversionsReportFile)
+		}
+	}
+	if failedErr != nil && !flags.watch {
+		fatalf(conversionFailure, nil, "failed to transform some of the artifacts. Error: %q", failedErr)
+	}
+	if flags.watch {
+		if err := lib.Watch(ctx, transformationPlan, preExistingPlan, flags.outpath, flags.transformerSelector); err != nil {
+			logrus.Fatalf("failed to watch for changes. Error: %q", err)
+		}
+	}
+	if run != nil {
+		finishRun(flags.outpath, run, succeededRunStatus)
+		// the output directory may have been changed to an alternative directory, which holds the runs from then on
+		projectOutpath := filepath.Dir(flags.outpath)
+		if err := updateLatestRunLink(projectOutpath, run.ID); err != nil {
+			logrus.Warnf("failed to point the %s link to the run %s . Error: %q", latestRunLink, run.ID, err)
+		}
+		pruneRuns(projectOutpath, flags.keepRuns, run.ID)
+	}
+	printSuccessSummary()
 }
 
 // GetTransformCommand returns a command to do the transformation
 func GetTransformCommand() *cobra.Command {
-	must := func(err error) {
-		if err != nil {
-			panic(err)
-		}
-	}
 	viper.AutomaticEnv()
 
 	flags := transformFlags{}
 	transformCmd := &cobra.Command{
 		Use:        "transform",
 		Short:      "Transform using move2kube plan",
-		Long:       "Transform artifacts using move2kube plan",
+		Long:       "Transform artifacts using move2kube plan.\nA JSON summary of the outcome is printed to stderr at the end. The exit codes are 1 for other errors, 2 for invalid flags or paths, 3 for plan errors, 4 for unanswered questions, 5 for conversion failures and 6 for write failures.",
 		Run:        func(cmd *cobra.Command, _ []string) { transformHandler(cmd, flags) },
 		SuggestFor: []string{"translate"},
 	}
 
 	// Basic options
 	transformCmd.Flags().StringVarP(&flags.planfile, planFlag, "p", common.DefaultPlanFile, "Specify a plan file to execute.")
+	transformCmd.Flags().StringVar(&flags.archiveFormat, archiveFlag, "", "Package the output directory into a single archive along with the manifest of the files, to move it across network boundaries. Supported values: "+strings.Join(lib.ArchiveFormats, ", "))
+	transformCmd.Flags().StringVar(&flags.outputBucket, outputBucketFlag, "", "Upload the output to an object storage bucket instead of keeping it in the output directory, so that no shared disk is needed to deliver it. The credentials are read from the environment. Examples: s3://my-bucket/move2kube, gs://my-bucket/move2kube, azblob://my-container/move2kube")
 	transformCmd.Flags().BoolVar(&flags.overwrite, overwriteFlag, false, "Overwrite the output directory if it exists. By default we don't overwrite.")
+	transformCmd.Flags().BoolVar(&flags.versioned, versionedFlag, false, "Write each run into a new sub directory of the project output directory, named after the time of the run, along with the "+runMetadataFile+" metadata file. The '"+latestRunLink+"' link points to the latest successful run, so that the runs do not overwrite each other and can be compared, for example using the irdiff command on the exported IRs.")
+	transformCmd.Flags().StringVar(&flags.regenerate, regenerateFlag, "", "Run only this transformer on the saved IR and rewrite only its output in the output directory of the previous transformation, instead of transforming all the services again. Needs the plan file. Example: --regenerate Tekton")
+	transformCmd.Flags().StringSliceVar(&flags.regenerateIRs, regenerateIRFlag, []string{}, "Specify the IR files to regenerate the output from. By default the IR files exported into the output directory by the IRExporter are used.")
+	transformCmd.Flags().IntVar(&flags.keepRuns, keepRunsFlag, 0, "Delete the oldest runs, keeping only this number of the latest runs, when the --"+versionedFlag+" flag is used. All the runs are kept by default.")
+	transformCmd.Flags().StringVar(&flags.scriptLineEndings, scriptLineEndingsFlag, common.NativeScriptLineEndings, "Line endings of the generated scripts. native uses LF for .sh files and CRLF for .bat files. Supported values: native, lf, crlf")
+	transformCmd.Flags().BoolVar(&flags.dryRun, dryRunFlag, false, "Run the transformation and list the files that would be created, modified or deleted in the output directory, without writing them. The config and cache files are also not written.")
 	transformCmd.Flags().StringVarP(&flags.srcpath, sourceFlag, "s", "", "Specify source directory to transform. If you already have a m2k.plan then this will override the sourceDir value specified in that plan.")
 	transformCmd.Flags().StringVarP(&flags.outpath, outputFlag, "o", ".", "Path for output. Default will be directory with the project name.")
 	transformCmd.Flags().StringVarP(&flags.name, nameFlag, "n", common.DefaultProjectName, "Specify the project name.")
 	transformCmd.Flags().StringVar(&flags.configOut, configOutFlag, ".", "Specify config file output location.")
-	transformCmd.Flags().StringVar(&flags.qaCacheOut, qaCacheOutFlag, ".", "Specify cache file output location.")
-	transformCmd.Flags().StringSliceVarP(&flags.configs, configFlag, "f", []string{}, "Specify config file locations. By default we look for "+common.DefaultConfigFilePath)
+	transformCmd.Flags().StringVar(&flags.qaCacheOut, qaCacheOutFlag, ".", "Specify cache file output location. The cache file records all the answers given during the run.")
+	transformCmd.Flags().StringSliceVar(&flags.qaAnswers, qaAnswersFlag, []string{}, "Specify answer files (cache files recorded by previous runs) to replay. Later files override earlier ones. The config files and the --"+setConfigFlag+" and --"+setFlag+" flags take precedence over the replayed answers.")
+	transformCmd.Flags().StringArrayVar(&flags.answerSources, qaAnswerSourceFlag, []string{}, "Resolve the answers from these sources using the question IDs, before the config and cache files and before asking the questions. The answers from the sources are not persisted in the config and cache files. Supported sources: '"+qaengine.EnvAnswerSource+"' reads the environment variables named "+qaengine.DefaultEnvAnswerPrefix+"<question id in upper case, with _ instead of the other characters>, '"+qaengine.EnvAnswerSource+":<prefix>' uses another prefix, '"+qaengine.VaultAnswerSource+":<secret path>' reads the keys of a Vault secret, named either after the question IDs or like the environment variables without the prefix, using the VAULT_ADDR, VAULT_TOKEN and VAULT_NAMESPACE environment variables. Earlier sources take precedence. Example: --qa-answer-source env --qa-answer-source vault:secret/data/move2kube")
+	transformCmd.Flags().StringSliceVarP(&flags.configs, configFlag, "f", []string{}, "Specify config file locations. By default we look for "+common.DefaultConfigFilePath+". A "+common.ProjectConfigFile+" file in the root of the source directory is also used, with a lower priority.")
 	transformCmd.Flags().StringSliceVar(&flags.preSets, preSetFlag, []string{}, "Specify preset config to use.")
+	transformCmd.Flags().StringVar(&flags.profile, qaProfileFlag, "", "Specify the profile deciding which questions are asked: "+strings.Join(qaengine.Profiles, ", ")+". The minimal profile only asks the essential questions, the advanced profile leaves out the security and scaling questions which the hardened profile asks. All the questions are asked by default.")
 	transformCmd.Flags().BoolVar(&flags.persistPasswords, qaPersistPasswords, false, "Store passwords in the config and cache. By default passwords are not persisted.")
 	transformCmd.Flags().StringArrayVar(&flags.setconfigs, setConfigFlag, []string{}, "Specify config key-value pairs.")
-	transformCmd.Flags().StringVarP(&flags.customizationsPath, customizationsFlag, "c", "", "Specify directory where customizations are stored. By default we look for "+common.DefaultCustomizationDir)
+	transformCmd.Flags().StringArrayVar(&flags.sets, setFlag, []string{}, "Answer a question using its id. Format: <question id>=<answer> . Example: --set 'move2kube.target.\"default\".clustertype=Kubernetes'")
+	transformCmd.Flags().StringVar(&flags.customTemplatesPath, customTemplatesFlag, "", "Specify a directory, git url or OCI artifact with templates that override the built-in templates having the same name. Example: buildimages.sh")
+	transformCmd.Flags().StringSliceVar(&flags.templateValues, templateValuesFlag, []string{}, "Specify yaml files with values the templates can use through the values, value and required functions, along with the sprig functions. Later files override earlier ones. Example: {{ value \"registry.url\" | default \"quay.io\" | quote }}")
+	transformCmd.Flags().StringVarP(&flags.customizationsPath, customizationsFlag, "c", "", "Specify directory where customizations are stored, a git url with an optional ref (example: https://github.com/myorg/customizations.git#v1) or an OCI artifact (example: oci://quay.io/myorg/customizations:v1). The remote customizations are cached. By default we look for "+common.DefaultCustomizationDir)
 	transformCmd.Flags().StringVarP(&flags.transformerSelector, transformerSelectorFlag, "t", "", "Specify the transformer selector.")
+	transformCmd.Flags().StringVar(&flags.verifyKey, common.VerifyKeyFlag, "", "Specify the cosign public key, or KMS url, to verify the signatures of the remote customizations and templates.")
+	transformCmd.Flags().StringVar(&flags.verifyIdentity, common.VerifyIdentityFlag, "", "Specify the certificate identity for the keyless verification of the signatures of the remote customizations and templates. Example: https://github.com/myorg/customizations/.github/workflows/sign.yaml@refs/heads/main")
+	transformCmd.Flags().BoolVar(&flags.strict, strictFlag, false, "Fail with a non zero exit code when any of the generated objects are dropped or written in a version the target cluster does not support. The objects are listed in the "+transformer.ConversionsReportFile+" file in the output directory.")
+	transformCmd.Flags().BoolVar(&flags.watch, watchFlag, false, "Keep watching the source and the customizations directories for changes and transform again, keeping the output directory in sync. The plan is created again only when the files outside of the planned services change.")
+	transformCmd.Flags().BoolVar(&flags.review, reviewFlag, false, "Review the resources generated by each transformer and exclude the unwanted services, kinds and pipelines before they are written to the output.")
+	transformCmd.Flags().StringArrayVar(&flags.serviceGroups, serviceGroupFlag, []string{}, "Generate the output of a group of services separately, in a directory named after the group, so that each group can be reviewed and applied independently. Format: <group>=<pattern>[,<pattern>...] . The patterns are globs of the service names, or of the transformer names when prefixed with 'transformer:'. The services in no group are generated in the '"+lib.UngroupedServiceGroup+"' directory. Example: --service-group 'payments=pay-*,billing'")
+	transformCmd.Flags().StringVar(&flags.signProvenanceKey, signProvenanceFlag, "", "Sign the provenance file "+transformer.ProvenanceFile+" in the output using cosign. Specify the cosign private key or KMS url, or "+lib.KeylessSigning+" for the keyless signing.")
+	transformCmd.Flags().StringVar(&flags.verifyOIDCIssuer, common.VerifyOIDCIssuerFlag, "", "Specify the OIDC issuer of the certificate identity for the keyless verification. Example: https://token.actions.githubusercontent.com")
 	transformCmd.Flags().BoolVar(&flags.qaskip, qaSkipFlag, false, "Enable/disable the default answers to questions posed in QA Cli sub-system. If disabled, you will have to answer the questions posed by QA during interaction.")
+	transformCmd.Flags().BoolVar(&flags.qastrict, qaStrictFlag, false, "Use the default answers for all questions and fail at the end with a JSON list of the questions that had no default. Useful for CI pipelines.")
 
 	// Advanced options
 	transformCmd.Flags().BoolVar(&flags.ignoreEnv, ignoreEnvFlag, false, "Ignore data from local machine.")
 	transformCmd.Flags().BoolVar(&flags.disableLocalExecution, common.DisableLocalExecutionFlag, false, "Allow files to be executed locally.")
-
-	// Hidden options
 	transformCmd.Flags().BoolVar(&flags.qadisablecli, qadisablecliFlag, false, "Enable/disable the QA Cli sub-system. Without this system, you will have to use the REST API to interact.")
-	transformCmd.Flags().IntVar(&flags.qaport, qaportFlag, 0, "Port for the QA service. By default it chooses a random free port.")
-
-	must(transformCmd.Flags().MarkHidden(qadisablecliFlag))
-	must(transformCmd.Flags().MarkHidden(qaportFlag))
+	transformCmd.Flags().IntVar(&flags.qaport, qaportFlag, 0, "Port for the QA REST/GRPC service. By default it chooses a random free port.")
+	transformCmd.Flags().BoolVar(&flags.qagrpc, qagrpcFlag, false, "Use a GRPC bidirectional stream instead of the REST API when the QA Cli sub-system is disabled. The port is set using --"+qaportFlag+".")
+	transformCmd.Flags().StringVar(&flags.qaHost, qaHostFlag, "", "Host the QA REST/GRPC service listens on. Example: 127.0.0.1 to only accept the connections from the same machine. By default it listens on all the interfaces.")
+	transformCmd.Flags().IntVar(&flags.qaListenerFd, qaListenerFdFlag, 0, "File descriptor of the listener the QA REST/GRPC service serves on, instead of listening on --"+qaportFlag+".")
+	if err := transformCmd.Flags().MarkHidden(qaListenerFdFlag); err != nil {
+		panic(err)
+	}
 
 	return transformCmd
 }
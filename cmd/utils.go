@@ -17,15 +17,23 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/gorilla/mux"
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
 )
@@ -34,20 +42,37 @@ import (
 func checkSourcePath(srcpath string) {
 	fi, err := os.Stat(srcpath)
 	if os.IsNotExist(err) {
-		logrus.Fatalf("The given source directory %s does not exist. Error: %q", srcpath, err)
+		fatalf(validationFailure, nil, "The given source directory %s does not exist. Error: %q", srcpath, err)
 	}
 	if err != nil {
-		logrus.Fatalf("Error while accessing the given source directory %s Error: %q", srcpath, err)
+		fatalf(validationFailure, nil, "Error while accessing the given source directory %s Error: %q", srcpath, err)
 	}
 	if !fi.IsDir() {
-		logrus.Fatalf("The given source path %s is a file. Expected a directory. Exiting.", srcpath)
+		fatalf(validationFailure, nil, "The given source path %s is a file. Expected a directory. Exiting.", srcpath)
 	}
 	pwd, err := os.Getwd()
 	if err != nil {
-		logrus.Fatalf("Failed to get the current working directory. Error: %q", err)
+		fatalf(validationFailure, nil, "Failed to get the current working directory. Error: %q", err)
 	}
 	if common.IsParent(pwd, srcpath) {
-		logrus.Fatalf("The given source directory %s is a parent of the current working directory.", srcpath)
+		fatalf(validationFailure, nil, "The given source directory %s is a parent of the current working directory.", srcpath)
+	}
+}
+
+// checkArchivePath checks if the archive of the output is already in use.
+func checkArchivePath(archivePath string, overwrite bool) {
+	fi, err := os.Stat(archivePath)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		fatalf(validationFailure, nil, "Error while accessing the archive at path '%s' Error: %q . Exiting", archivePath, err)
+	}
+	if fi.IsDir() {
+		fatalf(validationFailure, nil, "The archive path '%s' is a directory. Exiting", archivePath)
+	}
+	if !overwrite {
+		fatalf(validationFailure, nil, "The archive '%s' already exists. Please either remove it or specify the '--%s' flag to overwrite it. Exiting.", archivePath, overwriteFlag)
 	}
 }
 
@@ -59,10 +84,10 @@ func checkOutputPath(outpath string, overwrite bool) {
 		return
 	}
 	if err != nil {
-		logrus.Fatalf("Error while accessing output directory at path '%s' Error: %q . Exiting", outpath, err)
+		fatalf(validationFailure, nil, "Error while accessing output directory at path '%s' Error: %q . Exiting", outpath, err)
 	}
 	if !overwrite {
-		logrus.Fatalf(`The output directory '%s' already exists.
+		fatalf(validationFailure, nil, `The output directory '%s' already exists.
 Please either:
 - remove the output directory
 - specify the '--%s' flag to overwrite the output directory
@@ -70,20 +95,247 @@ Please either:
 Exiting.`, outpath, overwriteFlag, outputFlag)
 	}
 	if !fi.IsDir() {
-		logrus.Fatalf("Output path '%s' is a file. Expected a directory. Exiting", outpath)
+		fatalf(validationFailure, nil, "Output path '%s' is a file. Expected a directory. Exiting", outpath)
 	}
 	pwd, err := os.Getwd()
 	if err != nil {
-		logrus.Fatalf("Failed to get the current working directory. Error: %q", err)
+		fatalf(validationFailure, nil, "Failed to get the current working directory. Error: %q", err)
 	}
 	if common.IsParent(pwd, outpath) {
-		logrus.Fatalf("The given output directory '%s' is a parent of the current working directory.", outpath)
+		fatalf(validationFailure, nil, "The given output directory '%s' is a parent of the current working directory.", outpath)
 	}
 	logrus.Infof("Output directory '%s' exists. The contents might get overwritten.", outpath)
 }
 
+const (
+	// minOutputDiskSpace is the number of free bytes below which the output directory is considered unwritable
+	minOutputDiskSpace = 10 * 1024 * 1024
+	// outputPathLengthMargin is the length reserved for the paths of the generated files inside the output directory
+	outputPathLengthMargin = 128
+)
+
+// checkOutputPathWritable checks that the output directory can be created and written to, before anything is written to it
+func checkOutputPathWritable(outpath string) error {
+	absOutpath, err := filepath.Abs(outpath)
+	if err != nil {
+		return fmt.Errorf("failed to make the output path %s absolute. Error: %w", outpath, err)
+	}
+	if len(absOutpath)+outputPathLengthMargin > maxOutputPathLength {
+		return fmt.Errorf("the output path %s is %d characters long, which leaves too little room for the paths of the generated files within the limit of %d characters", absOutpath, len(absOutpath), maxOutputPathLength)
+	}
+	// the nearest existing directory is probed, so that nothing is created if the output directory cannot be written to
+	existingDir := absOutpath
+	for {
+		fi, err := os.Stat(existingDir)
+		if err == nil {
+			if !fi.IsDir() {
+				return fmt.Errorf("the path %s is a file. Expected a directory", existingDir)
+			}
+			break
+		}
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("failed to access the path %s . Error: %w", existingDir, err)
+		}
+		parentDir := filepath.Dir(existingDir)
+		if parentDir == existingDir {
+			return fmt.Errorf("none of the parent directories of the output path %s exist", absOutpath)
+		}
+		existingDir = parentDir
+	}
+	probeFile, err := os.CreateTemp(existingDir, ".m2kwritecheck")
+	if err != nil {
+		return fmt.Errorf("the directory %s is not writable. Error: %w", existingDir, err)
+	}
+	probeFile.Close()
+	if err := os.Remove(probeFile.Name()); err != nil {
+		logrus.Debugf("failed to remove the file %s used to check that the output directory is writable. Error: %q", probeFile.Name(), err)
+	}
+	freeDiskSpace, err := getFreeDiskSpace(existingDir)
+	if err != nil {
+		logrus.Debugf("failed to get the free disk space of the directory %s . Error: %q", existingDir, err)
+		return nil
+	}
+	if freeDiskSpace < minOutputDiskSpace {
+		return fmt.Errorf("only %d bytes are free on the disk containing the directory %s", freeDiskSpace, existingDir)
+	}
+	return nil
+}
+
+// getWritableOutputPath returns the output path, or the alternative one given by the user if the output path cannot be written to.
+// It exits, before anything is written, if no writable alternative is given.
+func getWritableOutputPath(outpath string, overwrite bool) string {
+	triedOutpaths := map[string]bool{}
+	for {
+		err := checkOutputPathWritable(outpath)
+		if err == nil {
+			return outpath
+		}
+		triedOutpaths[outpath] = true
+		logrus.Errorf("The output directory %s cannot be written to. Error: %q", outpath, err)
+		alternativeDir := qaengine.FetchStringAnswer(
+			common.ConfigAlternativeOutputPathKey,
+			"Enter an alternative directory to write the output to :",
+			[]string{fmt.Sprintf("The output is written to the %s sub directory. Leave it empty to exit.", filepath.Base(outpath))},
+			"",
+			nil,
+		)
+		if alternativeDir == "" {
+			fatalf(writeFailure, nil, "Nothing was written, since the output directory %s cannot be written to. Use the '--%s' flag to specify a different output directory. Exiting.", outpath, outputFlag)
+		}
+		alternativeOutpath, err := filepath.Abs(filepath.Join(alternativeDir, filepath.Base(outpath)))
+		if err != nil {
+			fatalf(writeFailure, nil, "failed to make the output path %s absolute. Error: %q", alternativeDir, err)
+		}
+		if triedOutpaths[alternativeOutpath] {
+			fatalf(writeFailure, nil, "Nothing was written, since the alternative output directory %s cannot be written to either. Exiting.", alternativeOutpath)
+		}
+		checkOutputPath(alternativeOutpath, overwrite)
+		logrus.Infof("The output will be written to the directory %s", alternativeOutpath)
+		outpath = alternativeOutpath
+	}
+}
+
+// checkWriteFailures exits with a summary of what was and was not written, if some of the output could not be written to
+func checkWriteFailures(outpath string) {
+	writeFailures := transformer.GetWriteFailures()
+	if len(writeFailures) == 0 {
+		return
+	}
+	writtenFilesCount := 0
+	filepath.WalkDir(outpath, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			writtenFilesCount++
+		}
+		return nil
+	})
+	failedPaths := []string{}
+	for failedPath := range writeFailures {
+		failedPaths = append(failedPaths, failedPath)
+	}
+	sort.Strings(failedPaths)
+	summary := ""
+	for _, failedPath := range failedPaths {
+		summary += fmt.Sprintf("\n- %s : %s", failedPath, writeFailures[failedPath])
+	}
+	fatalf(writeFailure, failedPaths, "The output is incomplete. %d files were written to the output directory %s , but the following %d paths could not be written to:%s", writtenFilesCount, outpath, len(failedPaths), summary)
+}
+
+// getDryRunOutputPath returns the temporary directory that is used instead of the output directory during a dry run
+func getDryRunOutputPath(outpath string) string {
+	return filepath.Join(common.TempPath, "dry-run", filepath.Base(outpath))
+}
+
+// printDryRunChanges prints the files and directories that would be created, modified or deleted by the dry run
+func printDryRunChanges(dryRunOutpath, outpath string) {
+	changes, err := getDryRunChanges(dryRunOutpath, outpath)
+	if err != nil {
+		logrus.Fatalf("%s", err)
+	}
+	for _, change := range changes {
+		fmt.Println(change)
+	}
+}
+
+// getDryRunChanges compares the files generated during a dry run with the existing output directory
+// and returns the files and directories that would be created, modified or deleted.
+func getDryRunChanges(dryRunOutpath, outpath string) ([]string, error) {
+	changes := []string{}
+	err := filepath.WalkDir(dryRunOutpath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dryRunOutpath, path)
+		if err != nil {
+			return err
+		}
+		destPath := filepath.Join(outpath, relPath)
+		destInfo, err := os.Stat(destPath)
+		if d.IsDir() {
+			if err != nil {
+				changes = append(changes, "create "+destPath+string(os.PathSeparator))
+			}
+			return nil
+		}
+		if err != nil {
+			changes = append(changes, "create "+destPath)
+			return nil
+		}
+		if destInfo.IsDir() {
+			changes = append(changes, "modify "+destPath)
+			return nil
+		}
+		newData, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		oldData, err := os.ReadFile(destPath)
+		if err != nil || !bytes.Equal(newData, oldData) {
+			changes = append(changes, "modify "+destPath)
+		}
+		return nil
+	})
+	if err != nil {
+		return changes, fmt.Errorf("failed to compare the dry run output %s with the output directory %s . Error: %w", dryRunOutpath, outpath, err)
+	}
+	// the output directory is cleared before the transformation output is written to it
+	if _, err := os.Stat(outpath); err == nil {
+		err := filepath.WalkDir(outpath, func(path string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+			relPath, err := filepath.Rel(outpath, path)
+			if err != nil {
+				return err
+			}
+			if _, err := os.Stat(filepath.Join(dryRunOutpath, relPath)); os.IsNotExist(err) {
+				changes = append(changes, "delete "+path)
+			}
+			return nil
+		})
+		if err != nil {
+			return changes, fmt.Errorf("failed to look for the files that would be deleted from the output directory %s . Error: %w", outpath, err)
+		}
+	}
+	return changes, nil
+}
+
+// addProjectConfigFile adds the project config file from the root of the source directory, if it exists.
+// It has the lowest priority among the config files, so that the config files given as flags can override it.
+func addProjectConfigFile(srcpath string, configs []string) []string {
+	if srcpath == "" {
+		return configs
+	}
+	projectConfigPath := filepath.Join(srcpath, common.ProjectConfigFile)
+	if fi, err := os.Stat(projectConfigPath); err != nil || fi.IsDir() {
+		return configs
+	}
+	if common.IsPresent(configs, projectConfigPath) {
+		return configs
+	}
+	logrus.Infof("Using the project config file at path %s", projectConfigPath)
+	return append([]string{projectConfigPath}, configs...)
+}
+
 func startQA(flags qaflags) {
-	qaengine.StartEngine(flags.qaskip, flags.qaport, flags.qadisablecli)
+	if err := qaengine.SetProfile(flags.profile); err != nil {
+		fatalf(validationFailure, nil, "Invalid value for the flag --%s . Error: %q", qaProfileFlag, err)
+	}
+	qaengine.SetListenHost(flags.qaHost)
+	if flags.qaListenerFd > 0 {
+		listenerFile := os.NewFile(uintptr(flags.qaListenerFd), qaListenerFdFlag)
+		listener, err := net.FileListener(listenerFile)
+		if err != nil {
+			fatalf(validationFailure, nil, "Invalid value for the flag --%s . Error: %q", qaListenerFdFlag, err)
+		}
+		// the listener has its own copy of the file descriptor
+		listenerFile.Close()
+		qaengine.SetListener(listener)
+	}
+	qaengine.StartEngine(flags.qaskip, flags.qastrict, flags.qaport, flags.qadisablecli, flags.qagrpc)
+	// config files and strings take precedence over the replayed answers
+	if len(flags.qaAnswers) != 0 {
+		qaengine.AddCaches(flags.qaAnswers...)
+	}
 	if flags.configOut == "" {
 		qaengine.SetupConfigFile("", flags.setconfigs, flags.configs, flags.preSets, flags.persistPasswords)
 	} else {
@@ -120,19 +372,66 @@ func startQA(flags qaflags) {
 			qaengine.SetupWriteCacheFile(filepath.Join(flags.qaCacheOut, common.QACacheFile), flags.persistPasswords)
 		}
 	}
+	// the answer sources take precedence over the config and cache files, since they are given explicitly for this run
+	if err := qaengine.AddAnswerSources(flags.answerSources...); err != nil {
+		fatalf(validationFailure, nil, "Invalid value for the flag --%s . Error: %q", qaAnswerSourceFlag, err)
+	}
 	if err := qaengine.WriteStoresToDisk(); err != nil {
 		logrus.Warnf("Failed to write the stores to disk. Error: %q", err)
 	}
 }
 
+// getConfigStringsFromSets converts the <question id>=<answer> pairs into config strings
+func getConfigStringsFromSets(sets []string) []string {
+	configStrings := []string{}
+	for _, set := range sets {
+		configString, err := qatypes.GetConfigStringFromKeyValue(set)
+		if err != nil {
+			fatalf(validationFailure, nil, "failed to parse the value '%s' of the --%s flag. Error: %q", set, setFlag, err)
+		}
+		configStrings = append(configStrings, configString)
+	}
+	return configStrings
+}
+
+// checkUnansweredQuestions prints the questions that had no default answer as JSON and exits with a non zero status
+func checkUnansweredQuestions() {
+	problems := qaengine.GetUnansweredProblems()
+	if len(problems) == 0 {
+		return
+	}
+	for i := range problems {
+		problems[i].Answer = nil
+	}
+	problemsJSON, err := json.MarshalIndent(problems, "", "  ")
+	if err != nil {
+		logrus.Fatalf("failed to marshal the unanswered questions to json. Error: %q", err)
+	}
+	fmt.Println(string(problemsJSON))
+	questionIDs := []string{}
+	for _, problem := range problems {
+		questionIDs = append(questionIDs, problem.ID)
+	}
+	fatalf(qaFailure, questionIDs, "%d question(s) had no default answer. Provide the answers using a config file and run again.", len(problems))
+}
+
 func startPlanProgressServer(port int) {
 	logrus.Trace("startPlanProgressServer start")
 	var server http.Server
+	var lastEventMutex sync.Mutex
+	lastEvent := common.ProgressEvent{}
+	common.AddProgressListener(func(e common.ProgressEvent) {
+		lastEventMutex.Lock()
+		defer lastEventMutex.Unlock()
+		lastEvent = e
+	})
 	r := mux.NewRouter()
 	r.HandleFunc("/progress", func(w http.ResponseWriter, r *http.Request) {
+		lastEventMutex.Lock()
+		defer lastEventMutex.Unlock()
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(map[string]interface{}{"files": common.PlanProgressNumDirectories, "transformers": common.PlanProgressNumBaseDetectTransformers})
+		json.NewEncoder(w).Encode(map[string]interface{}{"files": common.PlanProgressNumDirectories, "transformers": common.PlanProgressNumBaseDetectTransformers, "lastEvent": lastEvent})
 	}).Methods("GET")
 	server.Handler = r
 	server.Addr = ":" + cast.ToString(port)
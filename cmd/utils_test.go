@@ -0,0 +1,161 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+)
+
+func TestStartQAReplaysTheAnswers(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	answersFile := filepath.Join(t.TempDir(), "answers.yaml")
+	cache := qatypes.NewCache(answersFile, false)
+	for _, id := range []string{"move2kube.test.replayed", "move2kube.test.overridden"} {
+		problem, err := qatypes.NewInputProblem(id, "Enter the value of "+id, nil, "", nil)
+		if err != nil {
+			t.Fatalf("failed to create the problem. Error: %q", err)
+		}
+		problem.Answer = "recorded"
+		if err := cache.AddSolution(problem); err != nil {
+			t.Fatalf("failed to record the answer. Error: %q", err)
+		}
+	}
+
+	startQA(qaflags{qaskip: true, qaAnswers: []string{answersFile}, setconfigs: []string{`move2kube.test.overridden="set"`}})
+
+	for id, want := range map[string]string{
+		"move2kube.test.replayed":   "recorded",
+		"move2kube.test.overridden": "set",
+		"move2kube.test.unanswered": "default",
+	} {
+		if got := qaengine.FetchStringAnswer(id, "Enter the value of "+id, nil, "default", nil); got != want {
+			t.Errorf("got the answer %q for the question %s , want %q", got, id, want)
+		}
+	}
+}
+
+func TestAddProjectConfigFile(t *testing.T) {
+	srcDir := t.TempDir()
+	projectConfigPath := filepath.Join(srcDir, common.ProjectConfigFile)
+	explicitConfigPath := filepath.Join(t.TempDir(), "explicit.yaml")
+
+	if got := addProjectConfigFile(srcDir, []string{explicitConfigPath}); !cmp.Equal(got, []string{explicitConfigPath}) {
+		t.Fatalf("expected the configs to be unchanged without a project config file. Actual: %v", got)
+	}
+	if err := os.Mkdir(projectConfigPath, common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the directory %s . Error: %q", projectConfigPath, err)
+	}
+	if got := addProjectConfigFile(srcDir, nil); len(got) != 0 {
+		t.Fatalf("expected a directory to be ignored. Actual: %v", got)
+	}
+	if err := os.Remove(projectConfigPath); err != nil {
+		t.Fatalf("failed to remove the directory %s . Error: %q", projectConfigPath, err)
+	}
+	if err := os.WriteFile(projectConfigPath, []byte("move2kube:\n  test:\n    project: project\n    overridden: project\n"), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the project config file. Error: %q", err)
+	}
+	if err := os.WriteFile(explicitConfigPath, []byte("move2kube:\n  test:\n    overridden: explicit\n"), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the explicit config file. Error: %q", err)
+	}
+	if got := addProjectConfigFile("", []string{explicitConfigPath}); !cmp.Equal(got, []string{explicitConfigPath}) {
+		t.Fatalf("expected the configs to be unchanged without a source directory. Actual: %v", got)
+	}
+	configs := addProjectConfigFile(srcDir, []string{explicitConfigPath})
+	if want := []string{projectConfigPath, explicitConfigPath}; !cmp.Equal(configs, want) {
+		t.Fatalf("expected the project config file to come first. Differences: %s", cmp.Diff(want, configs))
+	}
+	if got := addProjectConfigFile(srcDir, configs); !cmp.Equal(got, configs) {
+		t.Fatalf("expected the project config file not to be added twice. Actual: %v", got)
+	}
+
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", nil, configs, nil, false)
+	for id, want := range map[string]string{
+		"move2kube.test.project":    "project",
+		"move2kube.test.overridden": "explicit",
+	} {
+		if got := qaengine.FetchStringAnswer(id, "Enter the project value of "+id, nil, "default", nil); got != want {
+			t.Errorf("got the answer %q for the question %s , want %q", got, id, want)
+		}
+	}
+}
+
+func TestGetDryRunChanges(t *testing.T) {
+	writeFiles := func(t *testing.T, dir string, files map[string]string) {
+		t.Helper()
+		for path, contents := range files {
+			path = filepath.Join(dir, path)
+			if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+				t.Fatalf("failed to create the directory of %s . Error: %q", path, err)
+			}
+			if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+				t.Fatalf("failed to write the file %s . Error: %q", path, err)
+			}
+		}
+	}
+	dryRunOutpath := filepath.Join(t.TempDir(), "myproject")
+	writeFiles(t, dryRunOutpath, map[string]string{
+		"Readme.md": "# myproject\n",
+		filepath.Join("deploy", "yamls", "api-deployment.yaml"): "replicas: 2\n",
+		filepath.Join("deploy", "yamls", "api-service.yaml"):    "kind: Service\n",
+		filepath.Join("scripts", "deploy.sh"):                   "kubectl apply -f deploy/yamls\n",
+	})
+	outpath := filepath.Join(t.TempDir(), "myproject")
+	writeFiles(t, outpath, map[string]string{
+		"Readme.md": "# myproject\n",
+		filepath.Join("deploy", "yamls", "api-deployment.yaml"): "replicas: 1\n",
+		filepath.Join("deploy", "yamls", "old-ingress.yaml"):    "kind: Ingress\n",
+	})
+	changes, err := getDryRunChanges(dryRunOutpath, outpath)
+	if err != nil {
+		t.Fatalf("failed to get the changes of the dry run. Error: %q", err)
+	}
+	want := []string{
+		"modify " + filepath.Join(outpath, "deploy", "yamls", "api-deployment.yaml"),
+		"create " + filepath.Join(outpath, "deploy", "yamls", "api-service.yaml"),
+		"create " + filepath.Join(outpath, "scripts") + string(os.PathSeparator),
+		"create " + filepath.Join(outpath, "scripts", "deploy.sh"),
+		"delete " + filepath.Join(outpath, "deploy", "yamls", "old-ingress.yaml"),
+	}
+	if !cmp.Equal(changes, want) {
+		t.Fatalf("the changes of the dry run are different. Difference:\n%s", cmp.Diff(want, changes))
+	}
+	missingOutpath := filepath.Join(t.TempDir(), "missing")
+	changes, err = getDryRunChanges(dryRunOutpath, missingOutpath)
+	if err != nil {
+		t.Fatalf("failed to get the changes of the dry run. Error: %q", err)
+	}
+	if len(changes) != 8 || changes[0] != "create "+missingOutpath+string(os.PathSeparator) {
+		t.Fatalf("expected the output directory and all its files and directories to be created, got the changes %+v", changes)
+	}
+	for _, change := range changes {
+		if !strings.HasPrefix(change, "create ") {
+			t.Fatalf("expected only creations when the output directory does not exist, got the changes %+v", changes)
+		}
+	}
+}
@@ -17,10 +17,12 @@
 package collector
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
 
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
 	"github.com/konveyor/move2kube/common"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 
@@ -38,7 +40,7 @@ func (c *CfAppsCollector) GetAnnotations() []string {
 }
 
 //Collect gets the cf app metadata by querying the cf app. Assumes that the authentication with cluster is already done.
-func (c *CfAppsCollector) Collect(inputPath string, outputPath string) error {
+func (c *CfAppsCollector) Collect(ctx context.Context, inputPath string, outputPath string) error {
 	client, err := getCfClient()
 	if err != nil {
 		logrus.Errorf("Unable to connect to cf client : %s", err)
@@ -69,6 +71,7 @@ func (c *CfAppsCollector) Collect(inputPath string, outputPath string) error {
 		} else {
 			cfapp.Environment = appEnv
 		}
+		cfapp.Routes = getCfAppRoutes(client, app)
 		cfinstanceapps.Spec.CfApps = append(cfinstanceapps.Spec.CfApps, cfapp)
 	}
 	cfinstanceapps = collecttypes.FormatMapsWithInterface(cfinstanceapps)
@@ -84,3 +87,34 @@ func (c *CfAppsCollector) Collect(inputPath string, outputPath string) error {
 
 	return nil
 }
+
+// getCfAppRoutes returns the routes of the app along with the route services bound to them, which are not part of the app manifests
+func getCfAppRoutes(client *cfclient.Client, app cfclient.App) []collecttypes.CfRoute {
+	routes, err := client.GetAppRoutes(app.Guid)
+	if err != nil {
+		logrus.Errorf("Unable to get the routes of the app %s : %s", app.Name, err)
+		return nil
+	}
+	cfRoutes := []collecttypes.CfRoute{}
+	for _, route := range routes {
+		cfRoute := collecttypes.CfRoute{Route: route}
+		if domain, err := route.Domain(); err != nil {
+			logrus.Debugf("Unable to get the domain of the route %s of the app %s : %s", route.Guid, app.Name, err)
+		} else {
+			cfRoute.Domain = domain.Name
+		}
+		if route.ServiceInstanceGuid != "" {
+			cfRoute.RouteService = route.ServiceInstanceGuid
+			if serviceInstance, err := client.GetServiceInstanceByGuid(route.ServiceInstanceGuid); err == nil {
+				cfRoute.RouteService = serviceInstance.Name
+			} else if serviceInstance, err := client.GetUserProvidedServiceInstanceByGuid(route.ServiceInstanceGuid); err == nil {
+				cfRoute.RouteService = serviceInstance.Name
+				cfRoute.RouteServiceURL = serviceInstance.RouteServiceUrl
+			} else {
+				logrus.Debugf("Unable to get the route service %s of the app %s : %s", route.ServiceInstanceGuid, app.Name, err)
+			}
+		}
+		cfRoutes = append(cfRoutes, cfRoute)
+	}
+	return cfRoutes
+}
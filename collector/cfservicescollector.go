@@ -17,6 +17,7 @@
 package collector
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -38,7 +39,7 @@ func (c *CfServicesCollector) GetAnnotations() []string {
 }
 
 //Collect gets the cf service metadata by querying the cf app. Assumes that the authentication with cluster is already done.
-func (c *CfServicesCollector) Collect(inputPath string, outputPath string) error {
+func (c *CfServicesCollector) Collect(ctx context.Context, inputPath string, outputPath string) error {
 	client, err := getCfClient()
 	if err != nil {
 		logrus.Errorf("Unable to connect to cf client : %s", err)
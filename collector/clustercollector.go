@@ -17,6 +17,7 @@
 package collector
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -50,7 +51,7 @@ func (c ClusterCollector) GetAnnotations() []string {
 }
 
 //Collect gets the cluster metadata by querying the cluster. Assumes that the authentication with cluster is already done.
-func (c *ClusterCollector) Collect(inputPath string, outputPath string) error {
+func (c *ClusterCollector) Collect(ctx context.Context, inputPath string, outputPath string) error {
 	//Creating the output sub-directory if it does not exist
 	outputPath = filepath.Join(outputPath, "clusters")
 	err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission)
@@ -117,14 +118,29 @@ func (c *ClusterCollector) getClusterCommand() string {
 }
 
 func (c *ClusterCollector) getClusterContextName() (string, error) {
-	cmd := exec.Command(c.getClusterCommand(), "config", "current-context")
+	if common.KubeContext != "" {
+		return common.KubeContext, nil
+	}
+	cmd := c.getClusterCommandWithArgs("config", "current-context")
 	name, err := cmd.Output()
 	return strings.TrimSpace(string(name)), err
 }
 
+// getClusterCommandWithArgs returns the kubectl or oc command, using the kubeconfig and the context given to collect
+func (c *ClusterCollector) getClusterCommandWithArgs(args ...string) *exec.Cmd {
+	kubeConfigArgs := []string{}
+	if common.KubeConfigPath != "" {
+		kubeConfigArgs = append(kubeConfigArgs, "--kubeconfig", common.KubeConfigPath)
+	}
+	if common.KubeContext != "" {
+		kubeConfigArgs = append(kubeConfigArgs, "--context", common.KubeContext)
+	}
+	return exec.Command(c.getClusterCommand(), append(kubeConfigArgs, args...)...)
+}
+
 func (c *ClusterCollector) getStorageClasses() ([]string, error) {
 	ccmd := c.getClusterCommand()
-	cmd := exec.Command(ccmd, "get", "sc", "-o", "yaml")
+	cmd := c.getClusterCommandWithArgs("get", "sc", "-o", "yaml")
 	yamlOutput, err := cmd.CombinedOutput()
 	if err != nil {
 		errDesc := c.interpretError(string(yamlOutput))
@@ -177,7 +193,10 @@ func (c ClusterCollector) getGlobalGroupOrder() []string {
 
 func (c *ClusterCollector) getAPI() (*cgdiscovery.DiscoveryClient, error) {
 	rules := cgclientcmd.NewDefaultClientConfigLoadingRules()
-	cfg, err := cgclientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &cgclientcmd.ConfigOverrides{}).ClientConfig()
+	if common.KubeConfigPath != "" {
+		rules.ExplicitPath = common.KubeConfigPath
+	}
+	cfg, err := cgclientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &cgclientcmd.ConfigOverrides{CurrentContext: common.KubeContext}).ClientConfig()
 	if err != nil {
 		logrus.Warnf("Failed to get the default config for the cluster API client. Error: %q", err)
 		return nil, err
@@ -304,7 +323,10 @@ func (c *ClusterCollector) collectUsingAPI() (map[string][]string, error) {
 		logrus.Warnf("Failed to api handle for cluster")
 		return nil, err
 	}
+	return c.collectUsingDiscoveryClient(api)
+}
 
+func (c *ClusterCollector) collectUsingDiscoveryClient(api *cgdiscovery.DiscoveryClient) (map[string][]string, error) {
 	gvList, err := c.getPreferredResourceUsingAPI(api)
 	errStr := "Failed to retrieve preferred group information from cluster"
 	if err != nil {
@@ -489,7 +511,7 @@ func (c *ClusterCollector) clusterByGroupsAndSortVersions(gvList []string) []str
 }
 
 func (c *ClusterCollector) collectUsingCLI() (map[string][]string, error) {
-	cmd := exec.Command("bash", "-c", c.getClusterCommand()+" api-resources -o name")
+	cmd := c.getClusterCommandWithArgs("api-resources", "-o", "name")
 	output, err := cmd.Output()
 	if err != nil {
 		logrus.Errorf("Error while running kubectl api-resources: %s", err)
@@ -577,7 +599,7 @@ func (c *ClusterCollector) getPreferredGVUsingCLI(kind string, availableGroupLis
 }
 
 func (c *ClusterCollector) isSupportedGV(kind string, gvStr string) (bool, error) {
-	cmd := exec.Command("bash", "-c", c.getClusterCommand()+" explain "+kind+" --api-version="+gvStr+" --recursive")
+	cmd := c.getClusterCommandWithArgs("explain", kind, "--api-version="+gvStr, "--recursive")
 	output, err := cmd.Output()
 	if err != nil {
 		logrus.Debugf("Error while running %s for verifying [%s]\n", c.getClusterCommand(), gvStr)
@@ -598,7 +620,7 @@ func (c *ClusterCollector) isSupportedGV(kind string, gvStr string) (bool, error
 }
 
 func (c *ClusterCollector) getGVKUsingNameCLI(name string) (string, string, error) {
-	cmd := exec.Command("bash", "-c", c.getClusterCommand()+" explain "+name)
+	cmd := c.getClusterCommandWithArgs("explain", name)
 	output, err := cmd.Output()
 	if err != nil {
 		//logrus.Errorf("Error while running kubectl: %s\n", err)
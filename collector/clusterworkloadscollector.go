@@ -0,0 +1,350 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collector
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	workloadsDir                = "workloads"
+	workloadsMetadataFileName   = "workloadsmetadata.yaml"
+	helmReleaseLabelSelector    = "owner=helm,status=deployed"
+	helmReleaseDataKey          = "release"
+	helmManagedByLabelKey       = "app.kubernetes.io/managed-by"
+	helmManagedByLabelValue     = "Helm"
+	helmReleaseNameAnnotation   = "meta.helm.sh/release-name"
+	kubeRootCACertConfigMapName = "kube-root-ca.crt"
+)
+
+var (
+	gzipMagicHeader        = []byte{0x1f, 0x8b, 0x08}
+	yamlDocumentSeparator  = regexp.MustCompile(`(?m)^---\s*$`)
+	systemNamespaceRegexps = []*regexp.Regexp{regexp.MustCompile(`^kube-.*$`), regexp.MustCompile(`^openshift.*$`)}
+	collectedWorkloadsGVRs = []schema.GroupVersionResource{
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+		{Group: "apps", Version: "v1", Resource: "statefulsets"},
+		{Group: "apps", Version: "v1", Resource: "daemonsets"},
+		{Group: "batch", Version: "v1", Resource: "jobs"},
+		{Group: "batch", Version: "v1", Resource: "cronjobs"},
+		{Group: "", Version: "v1", Resource: "services"},
+		{Group: "", Version: "v1", Resource: "configmaps"},
+		{Group: "", Version: "v1", Resource: "persistentvolumeclaims"},
+		{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+	}
+	runtimeMetadataFields = []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "selfLink", "ownerReferences"}
+)
+
+// ClusterWorkloadsCollector Implements Collector interface.
+// It collects the deployed helm releases and the workloads not managed by helm from the cluster,
+// so that they can be re-platformed to a different cluster.
+type ClusterWorkloadsCollector struct {
+}
+
+// helmRelease stores the fields of a helm release that are used by the collector
+type helmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Manifest  string `json:"manifest"`
+	Chart     struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+// GetAnnotations returns annotations on which this collector should be invoked
+func (c ClusterWorkloadsCollector) GetAnnotations() []string {
+	return []string{"workloads", "helm"}
+}
+
+// Collect gets the helm releases and the workloads from the cluster using the kubeconfig
+func (c *ClusterWorkloadsCollector) Collect(ctx context.Context, inputPath string, outputPath string) error {
+	outputPath = filepath.Join(outputPath, workloadsDir)
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		logrus.Errorf("Unable to create output directory at path %q Error: %q", outputPath, err)
+		return err
+	}
+	clusterName, cfg, err := getKubeRestConfig()
+	if err != nil {
+		logrus.Warnf("Unable to load the kubeconfig. Error: %q", err)
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		logrus.Warnf("Failed to create the client for the cluster. Error: %q", err)
+		return err
+	}
+	dynamicClient, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		logrus.Warnf("Failed to create the dynamic client for the cluster. Error: %q", err)
+		return err
+	}
+	namespaces, err := getWorkloadsNamespaces(ctx, clientset)
+	if err != nil {
+		logrus.Warnf("Failed to list the namespaces in the cluster. Error: %q", err)
+		return err
+	}
+	for _, release := range c.getHelmReleases(ctx, clientset, namespaces) {
+		if err := c.writeHelmRelease(release, clusterName, outputPath); err != nil {
+			logrus.Errorf("Failed to write the helm release %s in namespace %s . Error: %q", release.Name, release.Namespace, err)
+		}
+	}
+	for _, namespace := range namespaces {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := c.writeNamespaceWorkloads(ctx, dynamicClient, namespace, clusterName, outputPath); err != nil {
+			logrus.Errorf("Failed to collect the workloads in namespace %s . Error: %q", namespace, err)
+		}
+	}
+	return nil
+}
+
+// getWorkloadsNamespaces returns the namespaces selected by the user, or else the non system namespaces of the cluster, in sorted order
+func getWorkloadsNamespaces(ctx context.Context, clientset kubernetes.Interface) ([]string, error) {
+	namespaces := []string{}
+	if len(common.CollectNamespaces) != 0 {
+		namespaces = append(namespaces, common.CollectNamespaces...)
+	} else {
+		nsList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		for _, ns := range nsList.Items {
+			if isSystemNamespace(ns.Name) {
+				logrus.Debugf("Skipping the system namespace %s", ns.Name)
+				continue
+			}
+			namespaces = append(namespaces, ns.Name)
+		}
+	}
+	sort.Strings(namespaces)
+	return common.UniqueStrings(namespaces), nil
+}
+
+// getHelmReleases returns the deployed helm releases stored as configmaps in the namespaces.
+// The releases stored as secrets contain the values of the charts, which often have passwords,
+// so they are read only if the user opts in.
+func (c *ClusterWorkloadsCollector) getHelmReleases(ctx context.Context, clientset kubernetes.Interface, namespaces []string) []helmRelease {
+	releases := []helmRelease{}
+	if !common.CollectHelmReleaseSecrets {
+		logrus.Infof("Skipping the helm releases stored as secrets. Use --helm-release-secrets to collect them.")
+	}
+	for _, namespace := range namespaces {
+		if common.CollectHelmReleaseSecrets {
+			secrets, err := clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{LabelSelector: helmReleaseLabelSelector})
+			if err != nil {
+				logrus.Warnf("Failed to list the helm releases stored as secrets in namespace %s . Error: %q", namespace, err)
+			} else {
+				for _, secret := range secrets.Items {
+					release, err := decodeHelmRelease(string(secret.Data[helmReleaseDataKey]))
+					if err != nil {
+						logrus.Warnf("Failed to decode the helm release stored in secret %s/%s . Error: %q", secret.Namespace, secret.Name, err)
+						continue
+					}
+					releases = append(releases, release)
+				}
+			}
+		}
+		configMaps, err := clientset.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{LabelSelector: helmReleaseLabelSelector})
+		if err != nil {
+			logrus.Warnf("Failed to list the helm releases stored as configmaps in namespace %s . Error: %q", namespace, err)
+			continue
+		}
+		for _, cm := range configMaps.Items {
+			release, err := decodeHelmRelease(cm.Data[helmReleaseDataKey])
+			if err != nil {
+				logrus.Warnf("Failed to decode the helm release stored in configmap %s/%s . Error: %q", cm.Namespace, cm.Name, err)
+				continue
+			}
+			releases = append(releases, release)
+		}
+	}
+	return releases
+}
+
+// decodeHelmRelease decodes the base64 encoded and optionally gzipped helm release
+func decodeHelmRelease(data string) (helmRelease, error) {
+	release := helmRelease{}
+	b, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return release, err
+	}
+	if bytes.HasPrefix(b, gzipMagicHeader) {
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return release, err
+		}
+		defer r.Close()
+		if b, err = io.ReadAll(r); err != nil {
+			return release, err
+		}
+	}
+	if err := json.Unmarshal(b, &release); err != nil {
+		return release, err
+	}
+	return release, nil
+}
+
+func (c *ClusterWorkloadsCollector) writeHelmRelease(release helmRelease, clusterName, outputPath string) error {
+	name := release.Namespace + "-" + release.Name
+	releaseDir := filepath.Join(outputPath, common.NormalizeForFilename(name))
+	if err := os.MkdirAll(releaseDir, common.DefaultDirectoryPermission); err != nil {
+		return err
+	}
+	wm := collecttypes.NewWorkloadsMetadata(name)
+	wm.Spec = collecttypes.WorkloadsMetadataSpec{
+		Source:       collecttypes.HelmReleaseWorkloadsSourceType,
+		Cluster:      clusterName,
+		Namespace:    release.Namespace,
+		ReleaseName:  release.Name,
+		Revision:     release.Version,
+		ChartName:    release.Chart.Metadata.Name,
+		ChartVersion: release.Chart.Metadata.Version,
+		AppVersion:   release.Chart.Metadata.AppVersion,
+		Manifests:    []string{},
+	}
+	for _, doc := range yamlDocumentSeparator.Split(release.Manifest, -1) {
+		obj := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil || len(obj) == 0 {
+			continue
+		}
+		u := unstructured.Unstructured{Object: obj}
+		if u.GetKind() == "" || u.GetName() == "" {
+			continue
+		}
+		fileName := common.NormalizeForFilename(strings.ToLower(u.GetKind())+"-"+u.GetName()) + ".yaml"
+		if err := os.WriteFile(filepath.Join(releaseDir, fileName), []byte(strings.TrimSpace(doc)+"\n"), common.DefaultFilePermission); err != nil {
+			logrus.Errorf("Failed to write the manifest %s of the helm release %s . Error: %q", fileName, release.Name, err)
+			continue
+		}
+		wm.Spec.Manifests = append(wm.Spec.Manifests, fileName)
+	}
+	return common.WriteYaml(filepath.Join(releaseDir, workloadsMetadataFileName), wm)
+}
+
+// writeNamespaceWorkloads writes the workloads in the namespace that are not managed by helm
+func (c *ClusterWorkloadsCollector) writeNamespaceWorkloads(ctx context.Context, client dynamic.Interface, namespace, clusterName, outputPath string) error {
+	wm := collecttypes.NewWorkloadsMetadata(namespace)
+	wm.Spec = collecttypes.WorkloadsMetadataSpec{
+		Source:    collecttypes.ClusterWorkloadsSourceType,
+		Cluster:   clusterName,
+		Namespace: namespace,
+		Manifests: []string{},
+	}
+	nsDir := filepath.Join(outputPath, common.NormalizeForFilename(namespace))
+	for _, gvr := range collectedWorkloadsGVRs {
+		list, err := client.Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			logrus.Debugf("Failed to list %s in namespace %s . Error: %q", gvr.String(), namespace, err)
+			continue
+		}
+		for _, item := range list.Items {
+			if !isUserWorkload(item) {
+				continue
+			}
+			cleanRuntimeFields(&item)
+			if err := os.MkdirAll(nsDir, common.DefaultDirectoryPermission); err != nil {
+				return err
+			}
+			fileName := common.NormalizeForFilename(strings.ToLower(item.GetKind())+"-"+item.GetName()) + ".yaml"
+			if err := common.WriteYaml(filepath.Join(nsDir, fileName), item.Object); err != nil {
+				logrus.Errorf("Failed to write the %s %s in namespace %s . Error: %q", item.GetKind(), item.GetName(), namespace, err)
+				continue
+			}
+			wm.Spec.Manifests = append(wm.Spec.Manifests, fileName)
+		}
+	}
+	if len(wm.Spec.Manifests) == 0 {
+		logrus.Debugf("No workloads found in namespace %s", namespace)
+		return nil
+	}
+	return common.WriteYaml(filepath.Join(nsDir, workloadsMetadataFileName), wm)
+}
+
+func isSystemNamespace(namespace string) bool {
+	for _, re := range systemNamespaceRegexps {
+		if re.MatchString(namespace) {
+			return true
+		}
+	}
+	return false
+}
+
+// isUserWorkload returns false for objects that are managed by helm, owned by other objects or created by the cluster
+func isUserWorkload(obj unstructured.Unstructured) bool {
+	if obj.GetLabels()[helmManagedByLabelKey] == helmManagedByLabelValue || obj.GetAnnotations()[helmReleaseNameAnnotation] != "" {
+		return false
+	}
+	if len(obj.GetOwnerReferences()) > 0 {
+		return false
+	}
+	switch obj.GetKind() {
+	case "Service":
+		return !(obj.GetNamespace() == "default" && obj.GetName() == "kubernetes")
+	case "ConfigMap":
+		return obj.GetName() != kubeRootCACertConfigMapName
+	}
+	return true
+}
+
+// cleanRuntimeFields removes the fields set by the cluster that should not be part of the migrated workload
+func cleanRuntimeFields(obj *unstructured.Unstructured) {
+	unstructured.RemoveNestedField(obj.Object, "status")
+	for _, field := range runtimeMetadataFields {
+		unstructured.RemoveNestedField(obj.Object, "metadata", field)
+	}
+	annotations := obj.GetAnnotations()
+	delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+	delete(annotations, "deployment.kubernetes.io/revision")
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(obj.Object, "metadata", "annotations")
+	} else {
+		obj.SetAnnotations(annotations)
+	}
+	if obj.GetKind() == "Service" {
+		unstructured.RemoveNestedField(obj.Object, "spec", "clusterIP")
+		unstructured.RemoveNestedField(obj.Object, "spec", "clusterIPs")
+	}
+	if obj.GetKind() == "PersistentVolumeClaim" {
+		unstructured.RemoveNestedField(obj.Object, "spec", "volumeName")
+	}
+}
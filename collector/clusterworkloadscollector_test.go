@@ -0,0 +1,188 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collector
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func setCollectOptions(t *testing.T, namespaces []string, helmReleaseSecrets bool) {
+	t.Helper()
+	oldNamespaces, oldHelmReleaseSecrets := common.CollectNamespaces, common.CollectHelmReleaseSecrets
+	t.Cleanup(func() { common.CollectNamespaces, common.CollectHelmReleaseSecrets = oldNamespaces, oldHelmReleaseSecrets })
+	common.CollectNamespaces, common.CollectHelmReleaseSecrets = namespaces, helmReleaseSecrets
+}
+
+func newHelmReleaseObjects(namespace, name string) []runtime.Object {
+	data := base64.StdEncoding.EncodeToString([]byte(`{"name": "` + name + `", "namespace": "` + namespace + `", "version": 1}`))
+	meta := metav1.ObjectMeta{Name: "sh.helm.release.v1." + name + ".v1", Namespace: namespace, Labels: map[string]string{"owner": "helm", "status": "deployed"}}
+	return []runtime.Object{
+		&corev1.Secret{ObjectMeta: meta, Data: map[string][]byte{helmReleaseDataKey: []byte(data)}},
+		&corev1.ConfigMap{ObjectMeta: meta, Data: map[string]string{helmReleaseDataKey: data}},
+	}
+}
+
+func newWorkloadsTestClientset() *fake.Clientset {
+	objs := []runtime.Object{}
+	for _, namespace := range []string{"team-b", "team-a", "kube-system"} {
+		objs = append(objs, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})
+		objs = append(objs, newHelmReleaseObjects(namespace, namespace+"-app")...)
+	}
+	return fake.NewSimpleClientset(objs...)
+}
+
+func TestGetWorkloadsNamespaces(t *testing.T) {
+	testCases := []struct {
+		name       string
+		namespaces []string
+		want       []string
+	}{
+		{name: "all the non system namespaces in sorted order", want: []string{"team-a", "team-b"}},
+		{name: "the selected namespaces", namespaces: []string{"team-b", "kube-system", "team-b"}, want: []string{"kube-system", "team-b"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			setCollectOptions(t, testCase.namespaces, false)
+			got, err := getWorkloadsNamespaces(context.Background(), newWorkloadsTestClientset())
+			if err != nil {
+				t.Fatalf("failed to get the namespaces. Error: %q", err)
+			}
+			if !cmp.Equal(got, testCase.want) {
+				t.Fatalf("the namespaces are different. Difference:\n%s", cmp.Diff(testCase.want, got))
+			}
+		})
+	}
+}
+
+func TestGetHelmReleases(t *testing.T) {
+	testCases := []struct {
+		name               string
+		helmReleaseSecrets bool
+		want               []string
+	}{
+		{name: "the secrets are not read by default", want: []string{"team-a/team-a-app"}},
+		{name: "the secrets are read when opted in", helmReleaseSecrets: true, want: []string{"team-a/team-a-app", "team-a/team-a-app"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			setCollectOptions(t, []string{"team-a"}, testCase.helmReleaseSecrets)
+			clientset := newWorkloadsTestClientset()
+			got := []string{}
+			for _, release := range (&ClusterWorkloadsCollector{}).getHelmReleases(context.Background(), clientset, []string{"team-a"}) {
+				got = append(got, release.Namespace+"/"+release.Name)
+			}
+			sort.Strings(got)
+			if !cmp.Equal(got, testCase.want) {
+				t.Fatalf("the helm releases are different. Difference:\n%s", cmp.Diff(testCase.want, got))
+			}
+			for _, action := range clientset.Actions() {
+				if action.GetNamespace() != "team-a" {
+					t.Fatalf("expected only the selected namespace to be read, got the action %+v", action)
+				}
+				if action.GetResource().Resource == "secrets" && !testCase.helmReleaseSecrets {
+					t.Fatalf("expected the secrets not to be read without opting in, got the action %+v", action)
+				}
+			}
+		})
+	}
+}
+
+func TestClusterWorkloadsCollectorAnnotations(t *testing.T) {
+	annotations := ClusterWorkloadsCollector{}.GetAnnotations()
+	if common.IsPresent(annotations, "k8s") {
+		t.Fatalf("expected the collector not to run for the generic k8s annotation, got the annotations %+v", annotations)
+	}
+	if !common.IsPresent(annotations, "workloads") {
+		t.Fatalf("expected the collector to run for the workloads annotation, got the annotations %+v", annotations)
+	}
+}
+
+func TestCollectStopsWhenTheContextIsCancelled(t *testing.T) {
+	setKubeConfig(t, "")
+	setCollectOptions(t, nil, false)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := (&ClusterWorkloadsCollector{}).Collect(ctx, "", t.TempDir())
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected the collection to stop with the cancelled context, got the error %v", err)
+	}
+}
+
+func TestWriteNamespaceWorkloads(t *testing.T) {
+	newObject := func(apiVersion, kind, name string, labels map[string]interface{}) *unstructured.Unstructured {
+		metadata := map[string]interface{}{"name": name, "namespace": "team-a", "uid": "1234", "resourceVersion": "5"}
+		if labels != nil {
+			metadata["labels"] = labels
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   metadata,
+			"spec":       map[string]interface{}{"replicas": int64(1)},
+			"status":     map[string]interface{}{"readyReplicas": int64(1)},
+		}}
+	}
+	listKinds := map[schema.GroupVersionResource]string{}
+	for _, gvr := range collectedWorkloadsGVRs {
+		listKinds[gvr] = gvr.Resource + "List"
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds,
+		newObject("apps/v1", "Deployment", "web", nil),
+		newObject("apps/v1", "Deployment", "chart-web", map[string]interface{}{helmManagedByLabelKey: helmManagedByLabelValue}),
+		newObject("v1", "ConfigMap", kubeRootCACertConfigMapName, nil),
+	)
+	outputPath := t.TempDir()
+	nsDir := filepath.Join(outputPath, common.NormalizeForFilename("team-a"))
+	deploymentFileName := common.NormalizeForFilename("deployment-web") + ".yaml"
+	if err := (&ClusterWorkloadsCollector{}).writeNamespaceWorkloads(context.Background(), client, "team-a", "dev", outputPath); err != nil {
+		t.Fatalf("failed to write the workloads. Error: %q", err)
+	}
+	wm := collecttypes.WorkloadsMetadata{}
+	if err := common.ReadMove2KubeYaml(filepath.Join(nsDir, workloadsMetadataFileName), &wm); err != nil {
+		t.Fatalf("failed to read the workloads metadata. Error: %q", err)
+	}
+	if want := []string{deploymentFileName}; !cmp.Equal(wm.Spec.Manifests, want) {
+		t.Fatalf("the manifests are different. Difference:\n%s", cmp.Diff(want, wm.Spec.Manifests))
+	}
+	data, err := os.ReadFile(filepath.Join(nsDir, deploymentFileName))
+	if err != nil {
+		t.Fatalf("failed to read the manifest. Error: %q", err)
+	}
+	for _, field := range []string{"status", "uid", "resourceVersion"} {
+		if strings.Contains(string(data), field) {
+			t.Fatalf("expected the field %s set by the cluster to be removed, got the manifest:\n%s", field, data)
+		}
+	}
+}
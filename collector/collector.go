@@ -16,14 +16,16 @@
 
 package collector
 
+import "context"
+
 //Collector defines interface for collecting data from data sources
 type Collector interface {
-	Collect(inputDirectory string, outputPath string) error
+	Collect(ctx context.Context, inputDirectory string, outputPath string) error
 	GetAnnotations() []string
 }
 
 // GetCollectors returns different collectors
 func GetCollectors() ([]Collector, error) {
-	collectors := []Collector{new(ClusterCollector), new(ImagesCollector), new(CfAppsCollector), new(CfServicesCollector)}
+	collectors := []Collector{new(ClusterCollector), new(KubeConfigClusterCollector), new(ClusterWorkloadsCollector), new(ImagesCollector), new(CfAppsCollector), new(CfServicesCollector), new(UsageMetricsCollector)}
 	return collectors, nil
 }
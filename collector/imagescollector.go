@@ -17,6 +17,7 @@
 package collector
 
 import (
+	"context"
 	"encoding/json"
 	"os"
 	"os/exec"
@@ -42,7 +43,7 @@ func (c ImagesCollector) GetAnnotations() []string {
 }
 
 //Collect gets the image metadata using docker inspect
-func (c *ImagesCollector) Collect(inputDirectory string, outputPath string) error {
+func (c *ImagesCollector) Collect(ctx context.Context, inputDirectory string, outputPath string) error {
 	//Creating the output sub-directory if it does not exist
 	outputPath = filepath.Join(outputPath, "images")
 	err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission)
@@ -0,0 +1,222 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cgdiscovery "k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	cgclientcmd "k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	defaultStorageClassAnnotation     = "storageclass.kubernetes.io/is-default-class"
+	betaDefaultStorageClassAnnotation = "storageclass.beta.kubernetes.io/is-default-class"
+	defaultIngressClassAnnotation     = "ingressclass.kubernetes.io/is-default-class"
+	// kubeConfigClusterNameSuffix is added to the name of the context for the cluster metadata and its file
+	kubeConfigClusterNameSuffix = "-kubeconfig"
+)
+
+var crdGVR = schema.GroupVersionResource{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}
+
+// KubeConfigClusterCollector Implements Collector interface.
+// It connects to the cluster directly using the kubeconfig and does not require kubectl or oc.
+type KubeConfigClusterCollector struct {
+}
+
+// GetAnnotations returns annotations on which this collector should be invoked
+func (c KubeConfigClusterCollector) GetAnnotations() []string {
+	return []string{"kubeconfig"}
+}
+
+// Collect gets the cluster metadata by querying the cluster API server using the kubeconfig
+func (c *KubeConfigClusterCollector) Collect(ctx context.Context, inputPath string, outputPath string) error {
+	outputPath = filepath.Join(outputPath, "clusters")
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		logrus.Errorf("Unable to create output directory at path %q Error: %q", outputPath, err)
+		return err
+	}
+	name, cfg, err := getKubeRestConfig()
+	if err != nil {
+		logrus.Warnf("Unable to load the kubeconfig. Error: %q", err)
+		return err
+	}
+	// the name is different from the one of the ClusterCollector, since both can collect the same context
+	clusterMd := collecttypes.NewClusterMetadata(name + kubeConfigClusterNameSuffix)
+	clusterMd.Spec.Host = cfg.Host
+	api, err := cgdiscovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		logrus.Warnf("Failed to create the discovery client for the cluster. Error: %q", err)
+		return err
+	}
+	if version, err := api.ServerVersion(); err != nil {
+		logrus.Warnf("Failed to get the server version of the cluster. Error: %q", err)
+	} else {
+		clusterMd.Spec.ServerVersion = version.GitVersion
+	}
+	cc := ClusterCollector{}
+	clusterMd.Spec.APIKindVersionMap, err = cc.collectUsingDiscoveryClient(api)
+	if err != nil {
+		logrus.Warnf("Failed to collect the supported kinds using the discovery API. Error: %q", err)
+		return err
+	}
+	cc.groupOrderPolicy(&clusterMd.Spec.APIKindVersionMap)
+	clusterMd.Spec.StorageClasses, clusterMd.Spec.DefaultStorageClass, err = c.getStorageClasses(ctx, cfg)
+	if err != nil {
+		logrus.Warnf("Failed to get the storage classes from the cluster. Error: %q", err)
+		clusterMd.Spec.StorageClasses = []string{}
+	}
+	if clusterMd.Spec.IngressClasses, clusterMd.Spec.IngressController, err = c.getIngressClasses(ctx, cfg); err != nil {
+		logrus.Warnf("Failed to get the ingress classes from the cluster. Error: %q", err)
+	}
+	if clusterMd.Spec.CustomResourceDefinitions, err = c.getCustomResourceDefinitions(ctx, cfg); err != nil {
+		logrus.Warnf("Failed to get the custom resource definitions from the cluster. Error: %q", err)
+	}
+	outputPath = filepath.Join(outputPath, common.NormalizeForFilename(clusterMd.Name)+".yaml")
+	return common.WriteYaml(outputPath, clusterMd)
+}
+
+// getKubeRestConfig returns the context name and the rest config for the cluster using the kubeconfig
+func getKubeRestConfig() (string, *rest.Config, error) {
+	rules := cgclientcmd.NewDefaultClientConfigLoadingRules()
+	if common.KubeConfigPath != "" {
+		rules.ExplicitPath = common.KubeConfigPath
+	}
+	overrides := &cgclientcmd.ConfigOverrides{CurrentContext: common.KubeContext}
+	clientConfig := cgclientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+	rawConfig, err := clientConfig.RawConfig()
+	if err != nil {
+		return "", nil, err
+	}
+	name := rawConfig.CurrentContext
+	if common.KubeContext != "" {
+		name = common.KubeContext
+	}
+	if name == "" {
+		return "", nil, fmt.Errorf("no context found in the kubeconfig")
+	}
+	cfg, err := clientConfig.ClientConfig()
+	if err != nil {
+		return "", nil, err
+	}
+	return name, cfg, nil
+}
+
+// getStorageClasses returns the storage classes in the cluster with the default storage class first
+func (c *KubeConfigClusterCollector) getStorageClasses(ctx context.Context, cfg *rest.Config) ([]string, string, error) {
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	scList, err := clientset.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	storageClasses := []string{}
+	defaultStorageClass := ""
+	for _, sc := range scList.Items {
+		if sc.Annotations[defaultStorageClassAnnotation] == "true" || sc.Annotations[betaDefaultStorageClassAnnotation] == "true" {
+			defaultStorageClass = sc.Name
+			continue
+		}
+		storageClasses = append(storageClasses, sc.Name)
+	}
+	if defaultStorageClass != "" {
+		storageClasses = append([]string{defaultStorageClass}, storageClasses...)
+	}
+	return storageClasses, defaultStorageClass, nil
+}
+
+// getIngressClasses returns the ingress classes in the cluster with the default ingress class first, along with the default one
+func (c *KubeConfigClusterCollector) getIngressClasses(ctx context.Context, cfg *rest.Config) ([]string, string, error) {
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, "", err
+	}
+	icList, err := clientset.NetworkingV1().IngressClasses().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, "", err
+	}
+	ingressClasses := []string{}
+	defaultIngressClass := ""
+	for _, ic := range icList.Items {
+		if ic.Annotations[defaultIngressClassAnnotation] == "true" {
+			defaultIngressClass = ic.Name
+			continue
+		}
+		ingressClasses = append(ingressClasses, ic.Name)
+	}
+	if defaultIngressClass != "" {
+		ingressClasses = append([]string{defaultIngressClass}, ingressClasses...)
+	}
+	return ingressClasses, defaultIngressClass, nil
+}
+
+func (c *KubeConfigClusterCollector) getCustomResourceDefinitions(ctx context.Context, cfg *rest.Config) ([]collecttypes.CustomResourceDefinition, error) {
+	client, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	crdList, err := client.Resource(crdGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	crds := []collecttypes.CustomResourceDefinition{}
+	for _, item := range crdList.Items {
+		crd := collecttypes.CustomResourceDefinition{Name: item.GetName()}
+		crd.Group, _, _ = unstructured.NestedString(item.Object, "spec", "group")
+		crd.Kind, _, _ = unstructured.NestedString(item.Object, "spec", "names", "kind")
+		crd.Scope, _, _ = unstructured.NestedString(item.Object, "spec", "scope")
+		versions, _, _ := unstructured.NestedSlice(item.Object, "spec", "versions")
+		for _, version := range versions {
+			versionMap, ok := version.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if served, ok := versionMap["served"].(bool); ok && !served {
+				continue
+			}
+			versionName, ok := versionMap["name"].(string)
+			if !ok {
+				continue
+			}
+			crd.Versions = append(crd.Versions, versionName)
+			if schema, ok, _ := unstructured.NestedMap(versionMap, "schema", "openAPIV3Schema"); ok {
+				if crd.Schemas == nil {
+					crd.Schemas = map[string]interface{}{}
+				}
+				crd.Schemas[versionName] = schema
+			}
+		}
+		crds = append(crds, crd)
+	}
+	sort.Slice(crds, func(i, j int) bool { return crds[i].Name < crds[j].Name })
+	return crds, nil
+}
@@ -0,0 +1,96 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+)
+
+const testKubeConfig = `apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster: {server: "https://dev.example.com:6443"}
+- name: prod
+  cluster: {server: "https://prod.example.com:6443"}
+contexts:
+- name: dev
+  context: {cluster: dev, user: dev}
+- name: prod
+  context: {cluster: prod, user: prod}
+users:
+- name: dev
+  user: {token: dev-token}
+- name: prod
+  user: {token: prod-token}
+`
+
+func setKubeConfig(t *testing.T, kubeContext string) string {
+	t.Helper()
+	kubeConfigPath := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(kubeConfigPath, []byte(testKubeConfig), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the kubeconfig. Error: %q", err)
+	}
+	oldKubeConfigPath, oldKubeContext := common.KubeConfigPath, common.KubeContext
+	t.Cleanup(func() { common.KubeConfigPath, common.KubeContext = oldKubeConfigPath, oldKubeContext })
+	common.KubeConfigPath, common.KubeContext = kubeConfigPath, kubeContext
+	return kubeConfigPath
+}
+
+func TestGetKubeRestConfig(t *testing.T) {
+	testCases := []struct {
+		kubeContext string
+		wantName    string
+		wantHost    string
+	}{
+		{kubeContext: "", wantName: "dev", wantHost: "https://dev.example.com:6443"},
+		{kubeContext: "prod", wantName: "prod", wantHost: "https://prod.example.com:6443"},
+	}
+	for _, testCase := range testCases {
+		setKubeConfig(t, testCase.kubeContext)
+		name, cfg, err := getKubeRestConfig()
+		if err != nil {
+			t.Fatalf("failed to load the kubeconfig for the context %q . Error: %q", testCase.kubeContext, err)
+		}
+		if name != testCase.wantName || cfg.Host != testCase.wantHost {
+			t.Fatalf("got the context %s with the host %s , want %s with %s", name, cfg.Host, testCase.wantName, testCase.wantHost)
+		}
+	}
+}
+
+func TestClusterCollectorUsesTheKubeConfig(t *testing.T) {
+	kubeConfigPath := setKubeConfig(t, "prod")
+	c := &ClusterCollector{clusterCmd: "kubectl"}
+	want := []string{"kubectl", "--kubeconfig", kubeConfigPath, "--context", "prod", "get", "sc", "-o", "yaml"}
+	if got := c.getClusterCommandWithArgs("get", "sc", "-o", "yaml").Args; !cmp.Equal(got, want) {
+		t.Fatalf("the command is different. Difference:\n%s", cmp.Diff(want, got))
+	}
+	if name, err := c.getClusterContextName(); err != nil || name != "prod" {
+		t.Fatalf("got the context %q with the error %v , want prod", name, err)
+	}
+	for _, annotation := range (KubeConfigClusterCollector{}).GetAnnotations() {
+		if common.IsPresent(ClusterCollector{}.GetAnnotations(), annotation) {
+			t.Fatalf("expected the cluster collectors to have different annotations, both have %s", annotation)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	usageMetricsDir      = "usagemetrics"
+	usageMetricsFileName = "usagemetrics.yaml"
+	// usageMetricsWindow is the duration over which Prometheus computes the percentiles of the usage
+	usageMetricsWindow    = "7d"
+	prometheusQueryPath   = "/api/v1/query"
+	prometheusCPUQuery    = `quantile_over_time(0.95, sum by (namespace, pod, container) (rate(container_cpu_usage_seconds_total{container!="",container!="POD"}[5m]))[` + usageMetricsWindow + `:5m])`
+	prometheusMemoryQuery = `quantile_over_time(0.95, sum by (namespace, pod, container) (container_memory_working_set_bytes{container!="",container!="POD"})[` + usageMetricsWindow + `:5m])`
+)
+
+var podMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+
+// UsageMetricsCollector Implements Collector interface.
+// It collects the 95th percentile of the cpu and memory usage of the workloads from Prometheus, or the current usage from the metrics-server,
+// which are used as the defaults of the requests and limits of the migrated workloads.
+type UsageMetricsCollector struct {
+}
+
+// workloadRef identifies the workload which owns a pod
+type workloadRef struct {
+	namespace string
+	kind      string
+	name      string
+}
+
+// containerUsage stores the usage of a container in millicores and bytes
+type containerUsage struct {
+	milliCPU    int64
+	memoryBytes int64
+}
+
+// prometheusQueryResponse stores the fields of the response of the Prometheus instant query api that are used by the collector
+type prometheusQueryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// GetAnnotations returns annotations on which this collector should be invoked
+func (c UsageMetricsCollector) GetAnnotations() []string {
+	return []string{"k8s", "metrics", "usage"}
+}
+
+// Collect gets the usage of the workloads in the cluster using the kubeconfig
+func (c *UsageMetricsCollector) Collect(ctx context.Context, inputPath string, outputPath string) error {
+	clusterName, cfg, err := getKubeRestConfig()
+	if err != nil {
+		logrus.Warnf("Unable to load the kubeconfig. Error: %q", err)
+		return err
+	}
+	clientset, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		logrus.Warnf("Failed to create the client for the cluster. Error: %q", err)
+		return err
+	}
+	podWorkloads, err := c.getPodWorkloads(ctx, clientset)
+	if err != nil {
+		logrus.Warnf("Failed to get the workloads of the pods in the cluster. Error: %q", err)
+		return err
+	}
+	usageMetrics := collecttypes.NewUsageMetrics(common.NormalizeForFilename(clusterName))
+	usageMetrics.Spec.Cluster = clusterName
+	usages := map[workloadRef]map[string]containerUsage{}
+	if common.PrometheusURL != "" {
+		usageMetrics.Spec.Source = collecttypes.PrometheusUsageMetricsSourceType
+		usageMetrics.Spec.Window = usageMetricsWindow
+		err = c.addPrometheusUsages(usages, podWorkloads)
+	} else {
+		usageMetrics.Spec.Source = collecttypes.MetricsServerUsageMetricsSourceType
+		dynamicClient, derr := dynamic.NewForConfig(cfg)
+		if derr != nil {
+			logrus.Warnf("Failed to create the dynamic client for the cluster. Error: %q", derr)
+			return derr
+		}
+		logrus.Infof("Using the current usage from the metrics-server. Specify the Prometheus url to use the 95th percentile of the usage over %s instead.", usageMetricsWindow)
+		err = c.addMetricsServerUsages(ctx, usages, podWorkloads, dynamicClient)
+	}
+	if err != nil {
+		logrus.Warnf("Failed to collect the usage metrics from %s . Error: %q", usageMetrics.Spec.Source, err)
+		return err
+	}
+	usageMetrics.Spec.Workloads = getWorkloadUsages(usages)
+	if len(usageMetrics.Spec.Workloads) == 0 {
+		logrus.Debugf("No usage metrics found for the workloads in the cluster %s", clusterName)
+		return nil
+	}
+	outputPath = filepath.Join(outputPath, usageMetricsDir)
+	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
+		logrus.Errorf("Unable to create output directory at path %q Error: %q", outputPath, err)
+		return err
+	}
+	return common.WriteYaml(filepath.Join(outputPath, usageMetricsFileName), usageMetrics)
+}
+
+// getPodWorkloads returns the workloads owning the pods in the user namespaces, keyed by namespace/pod
+func (c *UsageMetricsCollector) getPodWorkloads(ctx context.Context, clientset kubernetes.Interface) (map[string]workloadRef, error) {
+	podWorkloads := map[string]workloadRef{}
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return podWorkloads, err
+	}
+	replicaSetOwners := map[string]workloadRef{}
+	for _, pod := range pods.Items {
+		if isSystemNamespace(pod.Namespace) {
+			continue
+		}
+		ref := workloadRef{namespace: pod.Namespace, kind: "Pod", name: pod.Name}
+		for _, owner := range pod.OwnerReferences {
+			if owner.Controller == nil || !*owner.Controller {
+				continue
+			}
+			ref = workloadRef{namespace: pod.Namespace, kind: owner.Kind, name: owner.Name}
+			if owner.Kind != "ReplicaSet" {
+				break
+			}
+			rsKey := pod.Namespace + "/" + owner.Name
+			if rsOwner, ok := replicaSetOwners[rsKey]; ok {
+				ref = rsOwner
+				break
+			}
+			rs, err := clientset.AppsV1().ReplicaSets(pod.Namespace).Get(ctx, owner.Name, metav1.GetOptions{})
+			if err != nil {
+				logrus.Debugf("Failed to get the replicaset %s . Error: %q", rsKey, err)
+				break
+			}
+			for _, rsOwnerRef := range rs.OwnerReferences {
+				if rsOwnerRef.Controller != nil && *rsOwnerRef.Controller {
+					ref = workloadRef{namespace: pod.Namespace, kind: rsOwnerRef.Kind, name: rsOwnerRef.Name}
+				}
+			}
+			replicaSetOwners[rsKey] = ref
+			break
+		}
+		podWorkloads[pod.Namespace+"/"+pod.Name] = ref
+	}
+	return podWorkloads, nil
+}
+
+// addPrometheusUsages adds the 95th percentile of the usage of the containers computed by Prometheus
+func (c *UsageMetricsCollector) addPrometheusUsages(usages map[workloadRef]map[string]containerUsage, podWorkloads map[string]workloadRef) error {
+	cpuResponse, err := queryPrometheus(common.PrometheusURL, prometheusCPUQuery)
+	if err != nil {
+		return err
+	}
+	memoryResponse, err := queryPrometheus(common.PrometheusURL, prometheusMemoryQuery)
+	if err != nil {
+		return err
+	}
+	for i, response := range []prometheusQueryResponse{cpuResponse, memoryResponse} {
+		for _, result := range response.Data.Result {
+			ref, ok := podWorkloads[result.Metric["namespace"]+"/"+result.Metric["pod"]]
+			if !ok || len(result.Value) != 2 {
+				continue
+			}
+			valueStr, ok := result.Value[1].(string)
+			if !ok {
+				continue
+			}
+			value, err := strconv.ParseFloat(valueStr, 64)
+			if err != nil || math.IsNaN(value) {
+				continue
+			}
+			usage := containerUsage{}
+			if i == 0 {
+				usage.milliCPU = int64(math.Ceil(value * 1000))
+			} else {
+				usage.memoryBytes = int64(math.Ceil(value))
+			}
+			addContainerUsage(usages, ref, result.Metric["container"], usage)
+		}
+	}
+	return nil
+}
+
+// queryPrometheus runs the instant query on the Prometheus server
+func queryPrometheus(prometheusURL, query string) (prometheusQueryResponse, error) {
+	response := prometheusQueryResponse{}
+	queryURL := strings.TrimSuffix(prometheusURL, "/") + prometheusQueryPath + "?" + url.Values{"query": []string{query}}.Encode()
+	client := http.Client{Timeout: 2 * time.Minute}
+	resp, err := client.Get(queryURL)
+	if err != nil {
+		return response, fmt.Errorf("failed to query the Prometheus server at %s . Error: %w", prometheusURL, err)
+	}
+	defer resp.Body.Close()
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return response, fmt.Errorf("failed to decode the response of the Prometheus server with the status %s . Error: %w", resp.Status, err)
+	}
+	if response.Status != "success" {
+		return response, fmt.Errorf("the Prometheus query failed with the status %s . Error: %s", resp.Status, response.Error)
+	}
+	return response, nil
+}
+
+// addMetricsServerUsages adds the current usage of the containers reported by the metrics-server
+func (c *UsageMetricsCollector) addMetricsServerUsages(ctx context.Context, usages map[workloadRef]map[string]containerUsage, podWorkloads map[string]workloadRef, client dynamic.Interface) error {
+	list, err := client.Resource(podMetricsGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, item := range list.Items {
+		ref, ok := podWorkloads[item.GetNamespace()+"/"+item.GetName()]
+		if !ok {
+			continue
+		}
+		containers, ok := item.Object["containers"].([]interface{})
+		if !ok {
+			continue
+		}
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := container["name"].(string)
+			usageMap, _ := container["usage"].(map[string]interface{})
+			usage := containerUsage{}
+			if cpu, ok := usageMap["cpu"].(string); ok {
+				if q, err := resource.ParseQuantity(cpu); err == nil {
+					usage.milliCPU = q.MilliValue()
+				}
+			}
+			if memory, ok := usageMap["memory"].(string); ok {
+				if q, err := resource.ParseQuantity(memory); err == nil {
+					usage.memoryBytes = q.Value()
+				}
+			}
+			addContainerUsage(usages, ref, name, usage)
+		}
+	}
+	return nil
+}
+
+// addContainerUsage keeps the maximum usage of the container over the pods of the workload
+func addContainerUsage(usages map[workloadRef]map[string]containerUsage, ref workloadRef, containerName string, usage containerUsage) {
+	if containerName == "" {
+		return
+	}
+	if _, ok := usages[ref]; !ok {
+		usages[ref] = map[string]containerUsage{}
+	}
+	existing := usages[ref][containerName]
+	if usage.milliCPU > existing.milliCPU {
+		existing.milliCPU = usage.milliCPU
+	}
+	if usage.memoryBytes > existing.memoryBytes {
+		existing.memoryBytes = usage.memoryBytes
+	}
+	usages[ref][containerName] = existing
+}
+
+// getWorkloadUsages returns the sorted usages of the workloads, with the memory rounded up to mebibytes
+func getWorkloadUsages(usages map[workloadRef]map[string]containerUsage) []collecttypes.WorkloadUsage {
+	workloadUsages := []collecttypes.WorkloadUsage{}
+	for ref, containers := range usages {
+		workloadUsage := collecttypes.WorkloadUsage{Name: ref.name, Namespace: ref.namespace, Kind: ref.kind, Containers: []collecttypes.ContainerUsage{}}
+		for name, usage := range containers {
+			cu := collecttypes.ContainerUsage{Name: name}
+			if usage.milliCPU > 0 {
+				cu.CPU = resource.NewMilliQuantity(usage.milliCPU, resource.DecimalSI).String()
+			}
+			if usage.memoryBytes > 0 {
+				cu.Memory = fmt.Sprintf("%dMi", int64(math.Ceil(float64(usage.memoryBytes)/(1024*1024))))
+			}
+			workloadUsage.Containers = append(workloadUsage.Containers, cu)
+		}
+		sort.Slice(workloadUsage.Containers, func(i, j int) bool { return workloadUsage.Containers[i].Name < workloadUsage.Containers[j].Name })
+		workloadUsages = append(workloadUsages, workloadUsage)
+	}
+	sort.Slice(workloadUsages, func(i, j int) bool {
+		if workloadUsages[i].Namespace != workloadUsages[j].Namespace {
+			return workloadUsages[i].Namespace < workloadUsages[j].Namespace
+		}
+		return workloadUsages[i].Name < workloadUsages[j].Name
+	})
+	return workloadUsages
+}
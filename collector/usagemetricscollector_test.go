@@ -0,0 +1,146 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collector
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newUsageMetricsTestPodWorkloads(t *testing.T) map[string]workloadRef {
+	t.Helper()
+	controller := true
+	ownedBy := func(kind, name string) []metav1.OwnerReference {
+		return []metav1.OwnerReference{{Kind: kind, Name: name, Controller: &controller}}
+	}
+	clientset := fake.NewSimpleClientset(
+		&appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Name: "api-5d8f", Namespace: "shop", OwnerReferences: ownedBy("Deployment", "api")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-5d8f-a", Namespace: "shop", OwnerReferences: ownedBy("ReplicaSet", "api-5d8f")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "api-5d8f-b", Namespace: "shop", OwnerReferences: ownedBy("ReplicaSet", "api-5d8f")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db-0", Namespace: "shop", OwnerReferences: ownedBy("StatefulSet", "db")}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "debug", Namespace: "shop"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "coredns-1", Namespace: "kube-system", OwnerReferences: ownedBy("ReplicaSet", "coredns")}},
+	)
+	podWorkloads, err := (&UsageMetricsCollector{}).getPodWorkloads(context.Background(), clientset)
+	if err != nil {
+		t.Fatalf("failed to get the workloads of the pods. Error: %q", err)
+	}
+	return podWorkloads
+}
+
+func TestGetPodWorkloads(t *testing.T) {
+	want := map[string]workloadRef{
+		"shop/api-5d8f-a": {namespace: "shop", kind: "Deployment", name: "api"},
+		"shop/api-5d8f-b": {namespace: "shop", kind: "Deployment", name: "api"},
+		"shop/db-0":       {namespace: "shop", kind: "StatefulSet", name: "db"},
+		"shop/debug":      {namespace: "shop", kind: "Pod", name: "debug"},
+	}
+	if got := newUsageMetricsTestPodWorkloads(t); !cmp.Equal(got, want, cmp.AllowUnexported(workloadRef{})) {
+		t.Fatalf("the workloads of the pods differ. Differences:\n%s", cmp.Diff(want, got, cmp.AllowUnexported(workloadRef{})))
+	}
+}
+
+func TestAddPrometheusUsages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != prometheusQueryPath {
+			http.NotFound(w, r)
+			return
+		}
+		switch r.URL.Query().Get("query") {
+		case prometheusCPUQuery:
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"result": [
+				{"metric": {"namespace": "shop", "pod": "api-5d8f-a", "container": "api"}, "value": [1700000000, "0.1204"]},
+				{"metric": {"namespace": "shop", "pod": "api-5d8f-b", "container": "api"}, "value": [1700000000, "0.25"]},
+				{"metric": {"namespace": "shop", "pod": "db-0", "container": "db"}, "value": [1700000000, "NaN"]},
+				{"metric": {"namespace": "kube-system", "pod": "coredns-1", "container": "coredns"}, "value": [1700000000, "0.01"]}
+			]}}`))
+		case prometheusMemoryQuery:
+			_, _ = w.Write([]byte(`{"status": "success", "data": {"result": [
+				{"metric": {"namespace": "shop", "pod": "api-5d8f-a", "container": "api"}, "value": [1700000000, "209715200"]},
+				{"metric": {"namespace": "shop", "pod": "db-0", "container": "db"}, "value": [1700000000, "1073741825"]}
+			]}}`))
+		default:
+			_, _ = w.Write([]byte(`{"status": "error", "error": "unexpected query"}`))
+		}
+	}))
+	defer server.Close()
+	oldPrometheusURL := common.PrometheusURL
+	defer func() { common.PrometheusURL = oldPrometheusURL }()
+	common.PrometheusURL = server.URL + "/"
+
+	usages := map[workloadRef]map[string]containerUsage{}
+	if err := (&UsageMetricsCollector{}).addPrometheusUsages(usages, newUsageMetricsTestPodWorkloads(t)); err != nil {
+		t.Fatalf("failed to add the usages from Prometheus. Error: %q", err)
+	}
+	want := []collecttypes.WorkloadUsage{
+		{Name: "api", Namespace: "shop", Kind: "Deployment", Containers: []collecttypes.ContainerUsage{{Name: "api", CPU: "250m", Memory: "200Mi"}}},
+		{Name: "db", Namespace: "shop", Kind: "StatefulSet", Containers: []collecttypes.ContainerUsage{{Name: "db", Memory: "1025Mi"}}},
+	}
+	if got := getWorkloadUsages(usages); !cmp.Equal(got, want) {
+		t.Fatalf("the usages differ. Differences:\n%s", cmp.Diff(want, got))
+	}
+
+	if _, err := queryPrometheus(server.URL, "up"); err == nil {
+		t.Fatalf("expected an error for the failed query")
+	}
+}
+
+func TestAddMetricsServerUsages(t *testing.T) {
+	newPodMetrics := func(name string, containers ...interface{}) runtime.Object {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "metrics.k8s.io/v1beta1",
+			"kind":       "PodMetrics",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "shop"},
+			"containers": containers,
+		}}
+	}
+	client := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{podMetricsGVR: "PodMetricsList"})
+	// the kind PodMetrics does not map to the resource pods, so the objects are added with the resource
+	for _, podMetrics := range []runtime.Object{
+		newPodMetrics("api-5d8f-a", map[string]interface{}{"name": "api", "usage": map[string]interface{}{"cpu": "12345678n", "memory": "102400Ki"}}),
+		newPodMetrics("api-5d8f-b", map[string]interface{}{"name": "api", "usage": map[string]interface{}{"cpu": "5m", "memory": "300Mi"}}),
+		newPodMetrics("unknown", map[string]interface{}{"name": "unknown", "usage": map[string]interface{}{"cpu": "1", "memory": "1Gi"}}),
+	} {
+		if err := client.Tracker().Create(podMetricsGVR, podMetrics, "shop"); err != nil {
+			t.Fatalf("failed to add the pod metrics. Error: %q", err)
+		}
+	}
+	usages := map[workloadRef]map[string]containerUsage{}
+	if err := (&UsageMetricsCollector{}).addMetricsServerUsages(context.Background(), usages, newUsageMetricsTestPodWorkloads(t), client); err != nil {
+		t.Fatalf("failed to add the usages from the metrics-server. Error: %q", err)
+	}
+	want := []collecttypes.WorkloadUsage{
+		{Name: "api", Namespace: "shop", Kind: "Deployment", Containers: []collecttypes.ContainerUsage{{Name: "api", CPU: "13m", Memory: "300Mi"}}},
+	}
+	if got := getWorkloadUsages(usages); !cmp.Equal(got, want) {
+		t.Fatalf("the usages differ. Differences:\n%s", cmp.Diff(want, got))
+	}
+}
@@ -29,6 +29,12 @@ const (
 	DisableLocalExecutionFlag = "disable-local-execution"
 	// FailOnEmptyPlan is the name of the flag that lets the user fail when the plan is empty (zero services, zero default transformers).
 	FailOnEmptyPlan = "fail-on-empty-plan"
+	// VerifyKeyFlag is the name of the flag that contains the cosign public key used to verify the remote customizations
+	VerifyKeyFlag = "verify-key"
+	// VerifyIdentityFlag is the name of the flag that contains the certificate identity used for the keyless verification of the remote customizations
+	VerifyIdentityFlag = "verify-identity"
+	// VerifyOIDCIssuerFlag is the name of the flag that contains the OIDC issuer used for the keyless verification of the remote customizations
+	VerifyOIDCIssuerFlag = "verify-oidc-issuer"
 )
 
 const (
@@ -46,22 +52,49 @@ const (
 	QACacheFile = types.AppNameShort + "qacache.yaml"
 	// ConfigFile defines the location of the config file
 	ConfigFile = types.AppNameShort + "config.yaml"
+	// ProjectConfigFile is the name of the config file that can be checked into the root of the source directory
+	ProjectConfigFile = types.AppName + ".yaml"
 	// IgnoreFilename is the name of the file containing the ignore rules and exceptions
 	IgnoreFilename = "." + types.AppNameShort + "ignore"
+	// CopyIgnoreFilename is the name of the file containing the patterns of the paths which are not copied into the source directory of the output
+	CopyIgnoreFilename = "." + types.AppNameShort + "copyignore"
 	// WindowsAnnotation tag is used tag a service to run on windows nodes
 	WindowsAnnotation = types.GroupName + "/containertype.windows"
 	// AnnotationLabelValue represents the value when an annotation is valid
 	AnnotationLabelValue = "true"
 	// DefaultServicePort is the default port that will be added to a service.
 	DefaultServicePort int32 = 8080
+	// DefaultContainerCPURequest is the cpu request of the containers that don't specify one
+	DefaultContainerCPURequest = "100m"
+	// DefaultContainerMemoryRequest is the memory request of the containers that don't specify one
+	DefaultContainerMemoryRequest = "128Mi"
+	// DefaultContainerCPULimit is the cpu limit of the containers that don't specify one
+	DefaultContainerCPULimit = "500m"
+	// DefaultContainerMemoryLimit is the memory limit of the containers that don't specify one
+	DefaultContainerMemoryLimit = "512Mi"
 	// TODOAnnotation is used to annotate with TODO tasks
 	TODOAnnotation = types.GroupName + "/todo."
+	// SkipFixersAnnotation lists the names of the fixers, separated by commas, which are not run on the annotated resource. "*" skips all the fixers.
+	SkipFixersAnnotation = types.GroupName + "/skip-fixers"
+	// SkipConversionAnnotation keeps the annotated resource in its apiVersion when set to "true", or converts it to the apiVersion it is set to,
+	// instead of converting it to the versions supported by the target cluster
+	SkipConversionAnnotation = types.GroupName + "/skip-conversion"
+	// OwnershipAnnotation marks the resources generated by move2kube. The resources without it are not modified when transforming into an existing output directory.
+	OwnershipAnnotation = types.GroupName + "/generated-by"
 	// DefaultBuildContainerName stores default build container name
 	DefaultBuildContainerName = "builder"
 	// ShExt is the extension of sh file
 	ShExt = ".sh"
 	// BatExt is the extension of bat file
 	BatExt = ".bat"
+	// NativeScriptLineEndings uses LF for shell scripts and CRLF for batch files
+	NativeScriptLineEndings = "native"
+	// LFScriptLineEndings uses LF for all the generated scripts
+	LFScriptLineEndings = "lf"
+	// CRLFScriptLineEndings uses CRLF for all the generated scripts
+	CRLFScriptLineEndings = "crlf"
+	// InterruptionTolerantPriorityClassName is the name of the low priority class of the workloads which tolerate their nodes being reclaimed
+	InterruptionTolerantPriorityClassName = "interruption-tolerant"
 )
 
 const (
@@ -85,6 +118,14 @@ const (
 	ConfigStoragesKey = BaseKey + d + "storages"
 	//ConfigMinReplicasKey represents Ingress host Key
 	ConfigMinReplicasKey = BaseKey + d + "minreplicas"
+	//ConfigLoadBalancerCloudKey represents the cloud whose annotations are set on the LoadBalancer services Key
+	ConfigLoadBalancerCloudKey = BaseKey + d + "loadbalancer" + d + "cloud"
+	//ConfigServiceMeshKey represents the service mesh installed in the target cluster Key
+	ConfigServiceMeshKey = BaseKey + d + "servicemesh"
+	//ConfigTimeZoneKey represents the time zone of the containers which used the time zone of the host Key
+	ConfigTimeZoneKey = BaseKey + d + "timezone"
+	//ConfigBaseImageFamilyKey represents the preferred family of the base images used in the generated Dockerfiles Key
+	ConfigBaseImageFamilyKey = BaseKey + d + "baseimagefamily"
 	//ConfigPortsForServiceKeySegment represents the ports used for service
 	ConfigPortsForServiceKeySegment = "ports"
 	//ConfigPortForServiceKeySegment represents the port used for service
@@ -99,8 +140,224 @@ const (
 	ConfigPublishProfileForServiceKeySegment = "publishprofile"
 	//ConfigContainerizationOptionServiceKeySegment represents containerization option to use
 	ConfigContainerizationOptionServiceKeySegment = "containerizationoption"
+	//ConfigServiceBindingsForServiceKeySegment represents how the bound Cloud Foundry services are made available to the service
+	ConfigServiceBindingsForServiceKeySegment = "servicebindings"
 	//ConfigApacheConfFileForServiceKeySegment represents the conf file used for service
 	ConfigApacheConfFileForServiceKeySegment = "apacheconfig"
+	//ConfigExportIRKey represents the export IR option Key
+	ConfigExportIRKey = BaseKey + d + "exportir"
+	//ConfigScriptsKey represents the generated scripts Key
+	ConfigScriptsKey = BaseKey + d + "scripts"
+	//ConfigScriptsHooksEnabledKey represents the option to add hooks to the generated scripts Key
+	ConfigScriptsHooksEnabledKey = ConfigScriptsKey + d + "hooks" + d + "enabled"
+	//ConfigScriptPreHookKeySuffix represents the snippet run before the main loop of a generated script Key
+	ConfigScriptPreHookKeySuffix = "prehook"
+	//ConfigScriptPostHookKeySuffix represents the snippet run after the main loop of a generated script Key
+	ConfigScriptPostHookKeySuffix = "posthook"
+	//ConfigScriptWindowsHookKeySegment represents the hooks of the batch script generated for Windows Key
+	ConfigScriptWindowsHookKeySegment = "windows"
+	//ConfigImageSigningKey represents the signing of the pushed images Key
+	ConfigImageSigningKey = BaseKey + d + "imagesigning"
+	//ConfigImageSigningTypeKey represents how the pushed images are signed with cosign Key
+	ConfigImageSigningTypeKey = ConfigImageSigningKey + d + "type"
+	//ConfigImageSigningKeyRefKey represents the reference to the cosign key the images are signed with Key
+	ConfigImageSigningKeyRefKey = ConfigImageSigningKey + d + "keyref"
+	//ConfigDevConfigKey represents the inner loop development configuration Key
+	ConfigDevConfigKey = BaseKey + d + "devconfig"
+	//ConfigDevConfigToolKey represents the tool for which the inner loop development configuration is generated Key
+	ConfigDevConfigToolKey = ConfigDevConfigKey + d + "tool"
+	//ConfigDockerfileLintFixKey represents the option to rewrite the Dockerfiles in the source directory to fix the issues found in them Key
+	ConfigDockerfileLintFixKey = BaseKey + d + "dockerfilelint" + d + "fix"
+	//ConfigStarlarkKey represents the limits of the Starlark transformers Key
+	ConfigStarlarkKey = BaseKey + d + "starlark"
+	//ConfigStarlarkTimeoutKey represents the maximum duration of each call of a Starlark script Key
+	ConfigStarlarkTimeoutKey = ConfigStarlarkKey + d + "timeout"
+	//ConfigStarlarkMaxStepsKey represents the maximum number of computation steps of each call of a Starlark script Key
+	ConfigStarlarkMaxStepsKey = ConfigStarlarkKey + d + "maxsteps"
+	//ConfigStarlarkMaxHeapKey represents the maximum size of the heap in MB while a Starlark script runs Key
+	ConfigStarlarkMaxHeapKey = ConfigStarlarkKey + d + "maxheapmb"
+	//ConfigStarlarkTrustCustomizationsKey represents the option to let the customized Starlark transformers write files and access the network Key
+	ConfigStarlarkTrustCustomizationsKey = ConfigStarlarkKey + d + "trustcustomizations"
+	//ConfigSOPSKey represents the SOPS encryption of the generated Secret manifests Key
+	ConfigSOPSKey = BaseKey + d + "sops"
+	//ConfigSOPSEnabledKey represents the option to encrypt the generated Secret manifests using SOPS Key
+	ConfigSOPSEnabledKey = ConfigSOPSKey + d + "enabled"
+	//ConfigSOPSKeyTypeKey represents the type of the keys used by SOPS Key
+	ConfigSOPSKeyTypeKey = ConfigSOPSKey + d + "keytype"
+	//ConfigSOPSRecipientsKey represents the recipients for which SOPS encrypts the Secret manifests Key
+	ConfigSOPSRecipientsKey = ConfigSOPSKey + d + "recipients"
+	//ConfigHelmDependencyChartsKey represents the backing services which are deployed using dependency charts of the generated Helm chart Key
+	ConfigHelmDependencyChartsKey = BaseKey + d + "helm" + d + "dependencycharts"
+	//ConfigDependencyWaitKey represents the option to add init containers which wait for the dependencies of the services to be reachable Key
+	ConfigDependencyWaitKey = BaseKey + d + "dependencywait"
+	//ConfigConfigRolloutKey represents the way config map and secret changes trigger rollouts of the workloads Key
+	ConfigConfigRolloutKey = BaseKey + d + "configrollout"
+	//ConfigProgressiveDeliveryKey represents progressive delivery of the workloads Key
+	ConfigProgressiveDeliveryKey = BaseKey + d + "progressivedelivery"
+	//ConfigProgressiveDeliveryStrategyKey represents the strategy used to roll out new versions of the workloads Key
+	ConfigProgressiveDeliveryStrategyKey = ConfigProgressiveDeliveryKey + d + "strategy"
+	//ConfigProgressiveDeliveryPrometheusKey represents the address of the Prometheus server used to analyse new versions Key
+	ConfigProgressiveDeliveryPrometheusKey = ConfigProgressiveDeliveryKey + d + "prometheus"
+	//ConfigProgressiveDeliverySuccessRateKey represents the minimum success rate of the requests to new versions Key
+	ConfigProgressiveDeliverySuccessRateKey = ConfigProgressiveDeliveryKey + d + "successrate"
+	//ConfigProgressiveDeliveryRequestDurationKey represents the maximum request duration of new versions Key
+	ConfigProgressiveDeliveryRequestDurationKey = ConfigProgressiveDeliveryKey + d + "requestduration"
+	//ConfigReplicasKeySuffix represents the number of replicas of a service Key
+	ConfigReplicasKeySuffix = "replicas"
+	//ConfigRolloutKeySegment represents how the pods of a service are replaced when it is updated Key segment
+	ConfigRolloutKeySegment = "rollout"
+	//ConfigRolloutStrategyKeySuffix represents the rollout strategy of a service Key
+	ConfigRolloutStrategyKeySuffix = ConfigRolloutKeySegment + d + "strategy"
+	//ConfigRolloutMaxSurgeKeySuffix represents the number of pods created above the replicas during a rolling update Key
+	ConfigRolloutMaxSurgeKeySuffix = ConfigRolloutKeySegment + d + "maxsurge"
+	//ConfigRolloutMaxUnavailableKeySuffix represents the number of pods which can be unavailable during a rolling update Key
+	ConfigRolloutMaxUnavailableKeySuffix = ConfigRolloutKeySegment + d + "maxunavailable"
+	//ConfigRolloutRevisionHistoryLimitKeySuffix represents the number of old revisions kept to roll back a service Key
+	ConfigRolloutRevisionHistoryLimitKeySuffix = ConfigRolloutKeySegment + d + "revisionhistorylimit"
+	//ConfigFSGroupKeySuffix represents the group which owns the volumes mounted by a service Key
+	ConfigFSGroupKeySuffix = "fsgroup"
+	//ConfigHealthProbeKeySegment represents the probes scaffolded for a service without probes Key segment
+	ConfigHealthProbeKeySegment = "healthprobe"
+	//ConfigHealthProbeTypeKeySuffix represents the kind of probe scaffolded for a service Key
+	ConfigHealthProbeTypeKeySuffix = ConfigHealthProbeKeySegment + d + "type"
+	//ConfigHealthProbeCommandKeySuffix represents the command of the exec probe scaffolded for a service Key
+	ConfigHealthProbeCommandKeySuffix = ConfigHealthProbeKeySegment + d + "command"
+	//ConfigAutoscalingKeySegment represents the autoscaling of a service Key segment
+	ConfigAutoscalingKeySegment = "autoscaling"
+	//ConfigAutoscalingServicesKey represents the services which are horizontally autoscaled Key
+	ConfigAutoscalingServicesKey = BaseKey + d + ConfigAutoscalingKeySegment + d + "services"
+	//ConfigAutoscalingMaxReplicasKeySuffix represents the maximum number of replicas of an autoscaled service Key
+	ConfigAutoscalingMaxReplicasKeySuffix = ConfigAutoscalingKeySegment + d + "maxreplicas"
+	//ConfigAutoscalingMetricsKeySuffix represents the metrics an autoscaled service is scaled on Key
+	ConfigAutoscalingMetricsKeySuffix = ConfigAutoscalingKeySegment + d + "metrics"
+	//ConfigAutoscalingCPUUtilizationKeySuffix represents the target CPU utilization of an autoscaled service Key
+	ConfigAutoscalingCPUUtilizationKeySuffix = ConfigAutoscalingKeySegment + d + "cpuutilization"
+	//ConfigAutoscalingRequestsMetricKeySuffix represents the name of the requests per second metric of an autoscaled service Key
+	ConfigAutoscalingRequestsMetricKeySuffix = ConfigAutoscalingKeySegment + d + "requestspersecond" + d + "metric"
+	//ConfigAutoscalingRequestsTargetKeySuffix represents the target requests per second per pod of an autoscaled service Key
+	ConfigAutoscalingRequestsTargetKeySuffix = ConfigAutoscalingKeySegment + d + "requestspersecond" + d + "target"
+	//ConfigAutoscalingQueueMetricKeySuffix represents the name of the external queue depth metric of an autoscaled service Key
+	ConfigAutoscalingQueueMetricKeySuffix = ConfigAutoscalingKeySegment + d + "queuedepth" + d + "metric"
+	//ConfigAutoscalingQueueTargetKeySuffix represents the target queue depth per pod of an autoscaled service Key
+	ConfigAutoscalingQueueTargetKeySuffix = ConfigAutoscalingKeySegment + d + "queuedepth" + d + "target"
+	//ConfigGrafanaDashboardKey represents the generation of a Grafana dashboard for the application Key
+	ConfigGrafanaDashboardKey = BaseKey + d + "grafanadashboard"
+	//ConfigSmokeTestsKey represents the generation of the smoke test Jobs of the exposed services Key
+	ConfigSmokeTestsKey = BaseKey + d + "smoketests"
+	//ConfigFluentBitKey represents the log shipping using Fluent Bit Key
+	ConfigFluentBitKey = BaseKey + d + "fluentbit"
+	//ConfigFluentBitModeKey represents the way the logs are collected Key
+	ConfigFluentBitModeKey = ConfigFluentBitKey + d + "mode"
+	//ConfigFluentBitNamespaceKey represents the namespace of the Fluent Bit DaemonSet Key
+	ConfigFluentBitNamespaceKey = ConfigFluentBitKey + d + "namespace"
+	//ConfigFluentBitOutputKey represents the destination of the logs Key
+	ConfigFluentBitOutputKey = ConfigFluentBitKey + d + "output"
+	//ConfigFluentBitOutputHostKey represents the host of the destination of the logs Key
+	ConfigFluentBitOutputHostKey = ConfigFluentBitKey + d + "host"
+	//ConfigFluentBitOutputPortKey represents the port of the destination of the logs Key
+	ConfigFluentBitOutputPortKey = ConfigFluentBitKey + d + "port"
+	//ConfigReviewResourcesKey represents the resources generated by a transformer which are written to the output Key
+	ConfigReviewResourcesKey = BaseKey + d + "review" + d + "%s" + d + "resources"
+	//ConfigSpotSchedulingKey represents the scheduling of interruption tolerant workloads on spot and preemptible nodes Key
+	ConfigSpotSchedulingKey = BaseKey + d + "spotscheduling"
+	//ConfigSpotSchedulingServicesKey represents the services which tolerate their nodes being reclaimed Key
+	ConfigSpotSchedulingServicesKey = ConfigSpotSchedulingKey + d + "services"
+	//ConfigSpotSchedulingNodePoolKey represents the kind of spot nodes in the target cluster Key
+	ConfigSpotSchedulingNodePoolKey = ConfigSpotSchedulingKey + d + "nodepool"
+	//ConfigSpotSchedulingNodeSelectorKey represents the label of the spot nodes Key
+	ConfigSpotSchedulingNodeSelectorKey = ConfigSpotSchedulingKey + d + "nodeselector"
+	//ConfigSpotSchedulingTaintKey represents the taint of the spot nodes Key
+	ConfigSpotSchedulingTaintKey = ConfigSpotSchedulingKey + d + "taint"
+	//ConfigKnativeServicesKey represents the services deployed as Knative Services instead of Deployments Key
+	ConfigKnativeServicesKey = BaseKey + d + "knative" + d + "services"
+	//ConfigTektonCacheKey represents the cache of the image builds of the Tekton pipelines Key
+	ConfigTektonCacheKey = BaseKey + d + "tekton" + d + "cache"
+	//ConfigTektonCacheTypeKey represents where the layers of the image builds are cached Key
+	ConfigTektonCacheTypeKey = ConfigTektonCacheKey + d + "type"
+	//ConfigTektonCacheRepoKey represents the repository of the image registry the layers are cached in Key
+	ConfigTektonCacheRepoKey = ConfigTektonCacheKey + d + "repo"
+	//ConfigTektonCacheSizeKey represents the size of the persistent volume claim the layers are cached in Key
+	ConfigTektonCacheSizeKey = ConfigTektonCacheKey + d + "size"
+	//ConfigTektonArtifactBucketKey represents the bucket the workspaces of the pipeline runs are uploaded to Key
+	ConfigTektonArtifactBucketKey = BaseKey + d + "tekton" + d + "artifactbucket"
+	//ConfigManifestsLayoutKey represents the layout of the generated kubernetes yamls Key
+	ConfigManifestsLayoutKey = BaseKey + d + "manifests" + d + "layout"
+	//ConfigSessionAffinityServicesKey represents the services which rely on sticky sessions Key
+	ConfigSessionAffinityServicesKey = BaseKey + d + "sessionaffinity" + d + "services"
+	//ConfigNamespaceKey represents the namespace the application is deployed to Key
+	ConfigNamespaceKey = BaseKey + d + "namespace"
+	//ConfigNamespaceCreateKey represents the generation of the namespace of the application Key
+	ConfigNamespaceCreateKey = ConfigNamespaceKey + d + "create"
+	//ConfigNamespaceNameKey represents the name of the namespace of the application Key
+	ConfigNamespaceNameKey = ConfigNamespaceKey + d + "name"
+	//ConfigNamespaceResourceQuotaKey represents the generation of a resource quota for the namespace Key
+	ConfigNamespaceResourceQuotaKey = ConfigNamespaceKey + d + "resourcequota"
+	//ConfigNamespaceDefaultDenyKey represents the generation of default deny network policies for the namespace Key
+	ConfigNamespaceDefaultDenyKey = ConfigNamespaceKey + d + "defaultdeny"
+	//ConfigServiceAccountsKey represents the creation of a service account for each service Key
+	ConfigServiceAccountsKey = BaseKey + d + "serviceaccounts"
+	//ConfigImagePullPolicyKey represents the pull policy of the container images Key
+	ConfigImagePullPolicyKey = BaseKey + d + "imagepullpolicy"
+	//ConfigImageDigestsKey represents the pinning of the container images to their digests Key
+	ConfigImageDigestsKey = BaseKey + d + "imagedigests"
+	//ConfigKustomizeComponentsKey represents the optional kustomize components each environment opts into Key
+	ConfigKustomizeComponentsKey = BaseKey + d + "kustomize" + d + "components"
+	//ConfigCarvelKey represents the Carvel packaging of the kubernetes yamls Key
+	ConfigCarvelKey = BaseKey + d + "carvel"
+	//ConfigCarvelPackageNameKey represents the fully qualified name of the Carvel package Key
+	ConfigCarvelPackageNameKey = ConfigCarvelKey + d + "packagename"
+	//ConfigCarvelPackageVersionKey represents the version of the Carvel package Key
+	ConfigCarvelPackageVersionKey = ConfigCarvelKey + d + "version"
+	//ConfigCarvelServiceAccountKey represents the service account used by kapp-controller to deploy the application Key
+	ConfigCarvelServiceAccountKey = ConfigCarvelKey + d + "serviceaccount"
+	//ConfigCrossplaneKey represents the Crossplane claims of the cloud resources used by the services Key
+	ConfigCrossplaneKey = BaseKey + d + "crossplane"
+	//ConfigCrossplaneClaimsKey represents the option to generate Crossplane claims for the detected cloud resources Key
+	ConfigCrossplaneClaimsKey = ConfigCrossplaneKey + d + "claims"
+	//ConfigCrossplaneAPIGroupKey represents the API group of the composite resources defined by the platform Key
+	ConfigCrossplaneAPIGroupKey = ConfigCrossplaneKey + d + "apigroup"
+	//ConfigTerraformKey represents the Terraform module of the infrastructure the application depends on Key
+	ConfigTerraformKey = BaseKey + d + "terraform"
+	//ConfigTerraformDNSProviderKey represents the provider of the DNS zone of the ingress hosts Key
+	ConfigTerraformDNSProviderKey = ConfigTerraformKey + d + "dnsprovider"
+	//ConfigCdk8sLanguageKey represents the language of the generated cdk8s code Key
+	ConfigCdk8sLanguageKey = BaseKey + d + "cdk8s" + d + "language"
+	//ConfigGRPCPortsKey represents the ports of the services serving gRPC Key
+	ConfigGRPCPortsKey = BaseKey + d + "grpc" + d + "ports"
+	//ConfigStatefulSetServicesKey represents the services deployed as StatefulSets Key
+	ConfigStatefulSetServicesKey = BaseKey + d + "statefulset" + d + "services"
+	//ConfigExternalServicesKey represents the dependencies outside the cluster Key
+	ConfigExternalServicesKey = BaseKey + d + "externalservices"
+	//ConfigServiceDiscoveryAliasesKey represents the host names and IP addresses of the services which are replaced with the names of their k8s services Key
+	ConfigServiceDiscoveryAliasesKey = BaseKey + d + "servicediscovery" + d + "aliases"
+	//ConfigExternalServicesHostsKey represents the host names of the dependencies which are not migrated Key
+	ConfigExternalServicesHostsKey = ConfigExternalServicesKey + d + "hosts"
+	//ConfigExternalServicesRewriteEnvKey represents replacing the host names of the dependencies in the environment variables Key
+	ConfigExternalServicesRewriteEnvKey = ConfigExternalServicesKey + d + "rewriteenv"
+	//ConfigExternalServiceNameKeySuffix represents the name of the ExternalName service of a dependency Key
+	ConfigExternalServiceNameKeySuffix = "name"
+	//ConfigContainerResourcesKeySegment represents the requests and limits of a container of a service Key segment
+	ConfigContainerResourcesKeySegment = "resources"
+	//ConfigImageBuildKey represents the build args and the target stage used to build the images Key
+	ConfigImageBuildKey = BaseKey + d + "imagebuild"
+	//ConfigImageBuildArgsKeySegment represents the build args of an image Key segment
+	ConfigImageBuildArgsKeySegment = "buildargs"
+	//ConfigImageBuildTargetKeySegment represents the target stage of an image Key segment
+	ConfigImageBuildTargetKeySegment = "target"
+	//ConfigBuildProxyKey represents the HTTP proxies the image builds go through Key
+	ConfigBuildProxyKey = BaseKey + d + "buildproxy"
+	//ConfigBuildProxyEnabledKey represents the option to pass the HTTP proxies to the image builds Key
+	ConfigBuildProxyEnabledKey = ConfigBuildProxyKey + d + "enabled"
+	//ConfigBaseImageMirrorsKey represents the mirrors of the registries the base images are pulled from Key
+	ConfigBaseImageMirrorsKey = BaseKey + d + "baseimagemirrors"
+	//ConfigAlternativeOutputPathKey represents the directory the output is written to when the given output directory cannot be written to Key
+	ConfigAlternativeOutputPathKey = BaseKey + d + "output" + d + "alternativepath"
+	//ConfigSourceExcludesKey represents the patterns of the paths which are not copied into the source directory of the output Key
+	ConfigSourceExcludesKey = BaseKey + d + "source" + d + "excludes"
+	//ConfigSourceSymlinksKey represents how the symbolic links are copied into the source directory of the output Key
+	ConfigSourceSymlinksKey = BaseKey + d + "source" + d + "symlinks"
+	//ConfigSourceCacheKey represents the option to keep the sources in the cache directory for the next transformation Key
+	ConfigSourceCacheKey = BaseKey + d + "source" + d + "cache"
 	//ConfigSpawnContainersKey represents spwan containers option Key
 	ConfigSpawnContainersKey = BaseKey + d + "spawncontainers"
 	//ConfigTransformersKey represents transformers Key
@@ -127,10 +384,28 @@ const (
 	ConfigRepoKeyPathsKey = ConfigRepoKeysKey + d + "paths"
 	//ConfigTransformerTypesKey represents Transformers type Key
 	ConfigTransformerTypesKey = ConfigTransformersKey + d + "types"
+	//ConfigFixersKey represents fixers Key
+	ConfigFixersKey = BaseKey + d + "fixers"
+	//ConfigFixersEnabledKey represents the ordered list of enabled fixers Key
+	ConfigFixersEnabledKey = ConfigFixersKey + d + "enabled"
+	//ConfigFixersResourcesKey represents the default container resources Key
+	ConfigFixersResourcesKey = ConfigFixersKey + d + "resources"
 	//IngressKey represents ingress keyword
 	IngressKey = "ingress"
 	// ConfigIngressClassNameKeySuffix represents the ingress class name
 	ConfigIngressClassNameKeySuffix = IngressKey + d + "ingressclassname"
+	// ConfigIngressControllerKeySuffix represents the ingress controller preset
+	ConfigIngressControllerKeySuffix = IngressKey + d + "controller"
+	// ConfigIngressUseRouteKeySuffix represents the choice between Route and Ingress on OpenShift clusters
+	ConfigIngressUseRouteKeySuffix = IngressKey + d + "useroute"
+	// RouteKey represents route keyword
+	RouteKey = "route"
+	// ConfigRouteTLSTerminationKeySuffix represents the TLS termination type of routes
+	ConfigRouteTLSTerminationKeySuffix = RouteKey + d + "tlstermination"
+	// ConfigRouteDestinationCACertKeySuffix represents the destination CA certificate of reencrypt routes
+	ConfigRouteDestinationCACertKeySuffix = RouteKey + d + "destinationcacert"
+	// ConfigWorkloadKindKeySuffix represents the choice between Deployment and DeploymentConfig on OpenShift clusters
+	ConfigWorkloadKindKeySuffix = "workloadkind"
 	//ConfigIngressHostKeySuffix represents Ingress host Key
 	ConfigIngressHostKeySuffix = IngressKey + d + "host"
 	//ConfigIngressTLSKeySuffix represents ingress tls Key
@@ -141,6 +416,8 @@ const (
 	ConfigImageRegistryKey = ConfigTargetKey + d + "imageregistry"
 	//ConfigTargetExistingVersionUpdate represents key which how to update versions
 	ConfigTargetExistingVersionUpdate = ConfigTargetKey + d + "existingversionupdate"
+	//ConfigTargetCustomResourceValidationKey represents the key of how the custom resources are validated against the CRDs of the target cluster
+	ConfigTargetCustomResourceValidationKey = ConfigTargetKey + d + "customresourcevalidation"
 	//ConfigImageRegistryURLKey represents image registry url Key
 	ConfigImageRegistryURLKey = ConfigImageRegistryKey + d + "url"
 	//ConfigImageRegistryNamespaceKey represents image registry namespace Key
@@ -157,6 +434,10 @@ const (
 	ConfigStoragesPVCForHostPathKey = ConfigStoragesKey + d + "pvcforhostpath"
 	//ConfigStoragesPerClaimStorageClassKey represents key for having different storage class for claim
 	ConfigStoragesPerClaimStorageClassKey = ConfigStoragesKey + d + "perclaimstorageclass"
+	//ConfigStoragesSharingKey represents how a volume claim shared by several services is translated Key
+	ConfigStoragesSharingKey = ConfigStoragesKey + d + "%s" + d + "sharing"
+	//ConfigStoragesStorageClassKey represents the storage class of a volume claim Key
+	ConfigStoragesStorageClassKey = ConfigStoragesKey + d + "%s" + d + "storageclass"
 	//ConfigServicesNamesKey is true if a detected service is enabled for transformation
 	ConfigServicesNamesKey = ConfigServicesKey + d + Special + d + "enable"
 	//ConfigContainerizationTypesKey represents source type Key
@@ -206,6 +487,32 @@ var (
 	IgnoreEnvironment = false
 	// DisableLocalExecution indicates whether to allow execution of local executables
 	DisableLocalExecution = false
+	// DryRun indicates that files should not be written outside the temporary directory
+	DryRun = false
+	// ScriptLineEndings controls the line endings of the generated scripts
+	ScriptLineEndings = NativeScriptLineEndings
+	// KubeConfigPath stores the path to the kubeconfig file used to connect to the cluster during collect
+	KubeConfigPath = ""
+	// KubeContext stores the kubeconfig context used to connect to the cluster during collect
+	KubeContext = ""
+	// PrometheusURL stores the url of the Prometheus server queried for the usage metrics of the workloads during collect
+	PrometheusURL = ""
+	// CollectNamespaces stores the namespaces whose workloads and helm releases are collected. All the non system namespaces are collected if it is empty
+	CollectNamespaces = []string{}
+	// CollectHelmReleaseSecrets indicates whether to read the helm releases stored as secrets, which contain the values of the charts, during collect
+	CollectHelmReleaseSecrets = false
+	// SignatureVerificationKey stores the cosign public key, or the KMS url, used to verify the signatures of the remote customizations
+	SignatureVerificationKey = ""
+	// SignatureVerificationIdentity stores the certificate identity used for the keyless verification of the remote customizations
+	SignatureVerificationIdentity = ""
+	// SignatureVerificationOIDCIssuer stores the OIDC issuer of the certificate identity
+	SignatureVerificationOIDCIssuer = ""
+	// ReviewResources indicates whether to ask which of the generated resources should be written to the output
+	ReviewResources = false
+	// ProvenanceSigningKey stores the cosign private key, or the KMS url, used to sign the provenance of the output. Use "keyless" for the keyless signing.
+	ProvenanceSigningKey = ""
+	// ServiceGroups split the output into groups of services generated separately. Each group is of the form <group>=<pattern>[,<pattern>...]
+	ServiceGroups = []string{}
 	// DefaultIgnoreDirRegexps specifies directory name regexes that would be ignored
 	DefaultIgnoreDirRegexps = []*regexp.Regexp{regexp.MustCompile("^[.].*")}
 	// disallowedDNSCharactersRegex provides pattern for characters not allowed in a DNS Name
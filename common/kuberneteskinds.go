@@ -23,4 +23,12 @@ const (
 	DeploymentKind = "Deployment"
 	// IngressKind defines Ingress Kind
 	IngressKind = "Ingress"
+	// PodKind defines Pod Kind
+	PodKind = "Pod"
+	// JobKind defines Job Kind
+	JobKind = "Job"
+	// DaemonSetKind defines DaemonSet Kind
+	DaemonSetKind = "DaemonSet"
+	// StatefulSetKind defines StatefulSet Kind
+	StatefulSetKind = "StatefulSet"
 )
@@ -18,10 +18,32 @@ package common
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// TextLogFormat is the default human readable log format
+	TextLogFormat = "text"
+	// JSONLogFormat prints each log entry as a json object
+	JSONLogFormat = "json"
+)
+
+// Stable field names used in the structured logs
+const (
+	// LogFieldStage is the stage of the run. Example: Plan, Transform
+	LogFieldStage = "stage"
+	// LogFieldTransformer is the name of the transformer
+	LogFieldTransformer = "transformer"
+	// LogFieldService is the name of the service
+	LogFieldService = "service"
+	// LogFieldFile is the path of a file or directory
+	LogFieldFile = "file"
+	// LogFieldEvent is the type of the progress event
+	LogFieldEvent = "event"
+)
+
 // CleanupHook calls the cleanup functions on fatal and panic errors
 type CleanupHook struct {
 	ctxContextFn func()
@@ -45,3 +67,55 @@ func (hook *CleanupHook) Levels() []logrus.Level {
 		logrus.FatalLevel,
 	}
 }
+
+// removeLogProgressListener removes the listener logging the progress events in json format
+var removeLogProgressListener func()
+
+// SetLogFormat sets the format of the logs. In json format the progress events are also logged with structured fields.
+func SetLogFormat(format string) error {
+	switch format {
+	case TextLogFormat:
+		logrus.SetFormatter(&logrus.TextFormatter{})
+		if removeLogProgressListener != nil {
+			removeLogProgressListener()
+			removeLogProgressListener = nil
+		}
+	case JSONLogFormat:
+		logrus.SetFormatter(&logrus.JSONFormatter{
+			FieldMap: logrus.FieldMap{
+				logrus.FieldKeyTime:  "time",
+				logrus.FieldKeyLevel: "level",
+				logrus.FieldKeyMsg:   "msg",
+			},
+		})
+		if removeLogProgressListener == nil {
+			removeLogProgressListener = AddProgressListener(logProgressEvent)
+		}
+	default:
+		return fmt.Errorf("the log format '%s' is not supported. Supported formats are %s and %s", format, TextLogFormat, JSONLogFormat)
+	}
+	return nil
+}
+
+func logProgressEvent(e ProgressEvent) {
+	fields := logrus.Fields{LogFieldStage: e.Stage, LogFieldEvent: e.Type}
+	switch e.Type {
+	case TransformerFinishedProgressEvent:
+		fields[LogFieldTransformer] = e.Name
+	case ServiceTransformedProgressEvent:
+		fields[LogFieldService] = e.Name
+	case FilesWrittenProgressEvent:
+		fields[LogFieldFile] = e.Name
+	}
+	if e.Total > 0 {
+		fields["current"] = e.Current
+		fields["total"] = e.Total
+	} else if e.Current > 0 {
+		fields["current"] = e.Current
+	}
+	msg := e.Message
+	if msg == "" {
+		msg = fmt.Sprintf("%s %s", e.Stage, e.Type)
+	}
+	logrus.WithFields(fields).Info(msg)
+}
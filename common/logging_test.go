@@ -0,0 +1,79 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+)
+
+func TestSetLogFormat(t *testing.T) {
+	out := &bytes.Buffer{}
+	stdOut := logrus.StandardLogger().Out
+	logrus.SetOutput(out)
+	t.Cleanup(func() {
+		if err := common.SetLogFormat(common.TextLogFormat); err != nil {
+			t.Fatalf("failed to reset the log format. Error: %q", err)
+		}
+		logrus.SetOutput(stdOut)
+	})
+	if err := common.SetLogFormat("xml"); err == nil {
+		t.Fatalf("expected an error for an unsupported log format")
+	}
+	for i := 0; i < 2; i++ {
+		if err := common.SetLogFormat(common.JSONLogFormat); err != nil {
+			t.Fatalf("failed to set the json log format. Error: %q", err)
+		}
+	}
+	common.ReportProgress(common.ProgressEvent{Stage: common.TransformProgressStage, Type: common.ServiceTransformedProgressEvent, Name: "api", Current: 1, Total: 2})
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected the progress event to be logged once, got the logs:\n%s", out.String())
+	}
+	entry := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("failed to parse the json log entry %s . Error: %q", lines[0], err)
+	}
+	delete(entry, "time")
+	want := map[string]interface{}{
+		"level":                "info",
+		"msg":                  string(common.TransformProgressStage) + " " + string(common.ServiceTransformedProgressEvent),
+		common.LogFieldStage:   string(common.TransformProgressStage),
+		common.LogFieldEvent:   string(common.ServiceTransformedProgressEvent),
+		common.LogFieldService: "api",
+		"current":              float64(1),
+		"total":                float64(2),
+	}
+	if !cmp.Equal(entry, want) {
+		t.Fatalf("the log entry is different. Difference:\n%s", cmp.Diff(want, entry))
+	}
+
+	out.Reset()
+	if err := common.SetLogFormat(common.TextLogFormat); err != nil {
+		t.Fatalf("failed to set the text log format. Error: %q", err)
+	}
+	common.ReportProgress(common.ProgressEvent{Stage: common.TransformProgressStage, Type: common.ServiceTransformedProgressEvent, Name: "api"})
+	if out.Len() != 0 {
+		t.Fatalf("expected the progress events not to be logged in the text log format, got the logs:\n%s", out.String())
+	}
+}
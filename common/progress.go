@@ -0,0 +1,138 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"sync"
+)
+
+// ProgressStage is a stage of the run
+type ProgressStage string
+
+// ProgressEventType is the type of a progress event
+type ProgressEventType string
+
+const (
+	// PlanProgressStage is the stage where the source directory is analyzed to create the plan
+	PlanProgressStage ProgressStage = "Plan"
+	// TransformProgressStage is the stage where the services in the plan are transformed
+	TransformProgressStage ProgressStage = "Transform"
+)
+
+const (
+	// StageStartedProgressEvent is sent when a stage starts
+	StageStartedProgressEvent ProgressEventType = "StageStarted"
+	// StageFinishedProgressEvent is sent when a stage finishes
+	StageFinishedProgressEvent ProgressEventType = "StageFinished"
+	// TransformerFinishedProgressEvent is sent when a transformer finishes processing during planning or transformation
+	TransformerFinishedProgressEvent ProgressEventType = "TransformerFinished"
+	// ServiceTransformedProgressEvent is sent when the artifacts of a service in the plan have been processed
+	ServiceTransformedProgressEvent ProgressEventType = "ServiceTransformed"
+	// FilesWrittenProgressEvent is sent when files are written to the output directory
+	FilesWrittenProgressEvent ProgressEventType = "FilesWritten"
+)
+
+// ProgressEvent is a structured progress update. Current and Total are set when the number of items is known.
+type ProgressEvent struct {
+	Stage   ProgressStage     `yaml:"stage" json:"stage"`
+	Type    ProgressEventType `yaml:"type" json:"type"`
+	Name    string            `yaml:"name,omitempty" json:"name,omitempty"`
+	Current int               `yaml:"current,omitempty" json:"current,omitempty"`
+	Total   int               `yaml:"total,omitempty" json:"total,omitempty"`
+	Message string            `yaml:"message,omitempty" json:"message,omitempty"`
+}
+
+// Percentage returns the percentage of completion of the items in the event. It returns -1 if the total is not known.
+func (e ProgressEvent) Percentage() int {
+	if e.Total <= 0 {
+		return -1
+	}
+	return e.Current * 100 / e.Total
+}
+
+type progressListener struct {
+	id       int
+	listener func(ProgressEvent)
+}
+
+var (
+	progressMutex          sync.RWMutex
+	progressListeners      []progressListener
+	nextProgressListenerID int
+)
+
+// AddProgressListener registers a callback that is called synchronously for every progress event.
+// The callback should return quickly since it blocks the run. It can add and remove the listeners, including itself.
+// The returned function removes the listener, it should be called when the run ends.
+// An event being reported while the listener is removed could still reach it.
+func AddProgressListener(listener func(ProgressEvent)) (remove func()) {
+	progressMutex.Lock()
+	defer progressMutex.Unlock()
+	nextProgressListenerID++
+	id := nextProgressListenerID
+	progressListeners = append(progressListeners, progressListener{id: id, listener: listener})
+	return func() {
+		progressMutex.Lock()
+		defer progressMutex.Unlock()
+		for i, l := range progressListeners {
+			if l.id == id {
+				progressListeners = append(progressListeners[:i], progressListeners[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// NewProgressChannel returns a channel that receives the progress events.
+// Events are dropped when the channel buffer is full, so that a slow reader does not block the run.
+// The returned function removes the listener sending the events to the channel and closes the channel, it should be called when the run ends.
+func NewProgressChannel(bufferSize int) (<-chan ProgressEvent, func()) {
+	events := make(chan ProgressEvent, bufferSize)
+	eventsMutex := sync.Mutex{}
+	closed := false
+	removeListener := AddProgressListener(func(e ProgressEvent) {
+		eventsMutex.Lock()
+		defer eventsMutex.Unlock()
+		if closed {
+			return
+		}
+		select {
+		case events <- e:
+		default:
+		}
+	})
+	return events, func() {
+		removeListener()
+		eventsMutex.Lock()
+		defer eventsMutex.Unlock()
+		if !closed {
+			closed = true
+			close(events)
+		}
+	}
+}
+
+// ReportProgress sends the progress event to all the listeners.
+// The listeners are called without holding the lock, so that they can add and remove the listeners.
+func ReportProgress(event ProgressEvent) {
+	progressMutex.RLock()
+	listeners := append([]progressListener{}, progressListeners...)
+	progressMutex.RUnlock()
+	for _, l := range listeners {
+		l.listener(event)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+)
+
+func TestProgressListeners(t *testing.T) {
+	first, second := 0, 0
+	removeFirst := common.AddProgressListener(func(common.ProgressEvent) { first++ })
+	removeSecond := common.AddProgressListener(func(common.ProgressEvent) { second++ })
+	defer removeSecond()
+	events, removeChannel := common.NewProgressChannel(1)
+
+	common.ReportProgress(common.ProgressEvent{Stage: common.TransformProgressStage})
+	removeFirst()
+	removeFirst()
+	removeChannel()
+	common.ReportProgress(common.ProgressEvent{Stage: common.TransformProgressStage})
+
+	if first != 1 || second != 2 {
+		t.Fatalf("expected the removed listener to get only the event sent before it was removed, got %d and %d events", first, second)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected the channel to get only the event sent before it was removed, got %d events", len(events))
+	}
+	<-events
+	if _, ok := <-events; ok {
+		t.Fatalf("expected the channel to be closed after it was removed")
+	}
+	removeChannel()
+}
+
+func TestProgressListenersCanRemoveThemselves(t *testing.T) {
+	calls := 0
+	var remove func()
+	remove = common.AddProgressListener(func(common.ProgressEvent) {
+		calls++
+		remove()
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		common.ReportProgress(common.ProgressEvent{Stage: common.PlanProgressStage})
+		common.ReportProgress(common.ProgressEvent{Stage: common.PlanProgressStage})
+	}()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatalf("reporting the progress deadlocked when a listener removed itself")
+	}
+	if calls != 1 {
+		t.Fatalf("expected the listener to get only the event it removed itself on, got %d events", calls)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// RedactedValue replaces the secret values in the logs and reports
+	RedactedValue = "******"
+	// minSecretLength is the length below which values are not redacted, since masking them would garble unrelated text
+	minSecretLength = 4
+)
+
+var (
+	secretsMutex sync.RWMutex
+	// secretValues is sorted by decreasing length so that a secret containing another secret is masked fully
+	secretValues []string
+	// secretNameRegex matches the names of environment variables and keys which usually hold credentials
+	secretNameRegex = regexp.MustCompile(`(?i)(passw(or)?d|secret|token|api_?key|private_?key|credential)`)
+)
+
+func init() {
+	logrus.AddHook(&RedactHook{})
+}
+
+// IsSecretName returns true if the name of the environment variable or key suggests that its value is a credential
+func IsSecretName(name string) bool {
+	return secretNameRegex.MatchString(name)
+}
+
+// AddSecretValues registers values which will be masked in the logs and reports
+func AddSecretValues(values ...string) {
+	secretsMutex.Lock()
+	defer secretsMutex.Unlock()
+	added := false
+	for _, value := range values {
+		if len(value) < minSecretLength || IsPresent(secretValues, value) {
+			continue
+		}
+		secretValues = append(secretValues, value)
+		added = true
+	}
+	if added {
+		sort.SliceStable(secretValues, func(i, j int) bool { return len(secretValues[i]) > len(secretValues[j]) })
+	}
+}
+
+// RedactSecrets masks the registered secret values in the string
+func RedactSecrets(s string) string {
+	secretsMutex.RLock()
+	defer secretsMutex.RUnlock()
+	for _, value := range secretValues {
+		s = strings.ReplaceAll(s, value, RedactedValue)
+	}
+	return s
+}
+
+// RedactHook masks the secret values in the log messages and fields before they are written or collected by other hooks.
+// It is added when the package is initialized, so it fires before the hooks added later.
+type RedactHook struct{}
+
+// Fire masks the secret values in the log entry
+func (*RedactHook) Fire(entry *logrus.Entry) error {
+	entry.Message = RedactSecrets(entry.Message)
+	for k, v := range entry.Data {
+		if s, ok := v.(string); ok {
+			entry.Data[k] = RedactSecrets(s)
+			continue
+		}
+		if s := fmt.Sprint(v); RedactSecrets(s) != s {
+			entry.Data[k] = RedactSecrets(s)
+		}
+	}
+	return nil
+}
+
+// Levels returns the levels on which the redact hook gets called
+func (*RedactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
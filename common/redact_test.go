@@ -0,0 +1,51 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+)
+
+func TestRedactSecrets(t *testing.T) {
+	common.AddSecretValues("hunter2-password", "hunter2", "abc")
+	t.Run("secrets are masked", func(t *testing.T) {
+		want := "login with " + common.RedactedValue + " and " + common.RedactedValue
+		if actual := common.RedactSecrets("login with hunter2-password and hunter2"); actual != want {
+			t.Fatalf("the secrets were not masked. Expected: %s Actual: %s", want, actual)
+		}
+	})
+	t.Run("short values are not masked", func(t *testing.T) {
+		if actual := common.RedactSecrets("abc"); actual != "abc" {
+			t.Fatalf("the short value should not be masked. Actual: %s", actual)
+		}
+	})
+	t.Run("secrets are masked in the logs", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		oldOut := logrus.StandardLogger().Out
+		logrus.SetOutput(out)
+		defer logrus.SetOutput(oldOut)
+		logrus.WithField("password", "hunter2").Warnf("the password is %s", "hunter2")
+		if strings.Contains(out.String(), "hunter2") {
+			t.Fatalf("the secret was not masked in the log. Actual: %s", out.String())
+		}
+	})
+}
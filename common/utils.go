@@ -35,6 +35,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
@@ -163,6 +164,161 @@ func GetFilesByExt(inputPath string, exts []string) ([]string, error) {
 	return files, nil
 }
 
+// serverSideSessionRegex matches the uses of the server side sessions, which are lost when the requests of a client reach another instance
+var serverSideSessionRegex = regexp.MustCompile(`JSESSIONID|HttpSession|PHPSESSID|session_start\(|express-session|ASP\.NET_SessionId|(?i:sticky[-_ ]?sessions?)`)
+
+// UsesServerSideSessions returns true if the source files in the directory use server side sessions, like the apps relying on sticky sessions do
+func UsesServerSideSessions(inputPath string) bool {
+	files, err := GetFilesByExt(inputPath, []string{".java", ".jsp", ".xml", ".properties", ".js", ".ts", ".php", ".py", ".rb", ".cs", ".config", ".yml", ".yaml"})
+	if err != nil {
+		logrus.Debugf("failed to look for the uses of the sessions in the directory %s . Error: %q", inputPath, err)
+		return false
+	}
+	for _, file := range files {
+		if strings.Contains(file, string(os.PathSeparator)+"node_modules"+string(os.PathSeparator)) {
+			continue
+		}
+		if info, err := os.Stat(file); err != nil || info.Size() > 1024*1024 {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		if serverSideSessionRegex.Match(data) {
+			logrus.Debugf("found the use of the server side sessions in the file %s", file)
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	// dockerfileArgRegex matches the build args declared in a Dockerfile
+	dockerfileArgRegex = regexp.MustCompile(`(?im)^\s*ARG\s+([A-Za-z_][A-Za-z0-9_]*)`)
+	// dockerfileStageRegex matches the named stages of a multi-stage Dockerfile
+	dockerfileStageRegex = regexp.MustCompile(`(?im)^\s*FROM\s+(?:--\S+\s+)*\S+\s+AS\s+(\S+)\s*$`)
+	// dockerfileFromRegex matches the images the stages of a Dockerfile are built from
+	dockerfileFromRegex = regexp.MustCompile(`(?im)^\s*FROM\s+(?:--\S+\s+)*(\S+)(?:\s+AS\s+(\S+))?\s*$`)
+)
+
+// GetDockerfileArgsAndStages returns the names of the build args declared in the Dockerfile and the names of its stages
+func GetDockerfileArgsAndStages(dockerfilePath string) (buildArgs []string, stages []string) {
+	data, err := os.ReadFile(dockerfilePath)
+	if err != nil {
+		logrus.Debugf("failed to read the Dockerfile at path %s . Error: %q", dockerfilePath, err)
+		return nil, nil
+	}
+	for _, match := range dockerfileArgRegex.FindAllSubmatch(data, -1) {
+		buildArgs = AppendIfNotPresent(buildArgs, string(match[1]))
+	}
+	for _, match := range dockerfileStageRegex.FindAllSubmatch(data, -1) {
+		stages = AppendIfNotPresent(stages, string(match[1]))
+	}
+	return buildArgs, stages
+}
+
+// GetDockerfileBaseImages returns the images the Dockerfile is built from, leaving out its own stages and scratch
+func GetDockerfileBaseImages(dockerfilePath string) []string {
+	data, err := os.ReadFile(dockerfilePath)
+	if err != nil {
+		logrus.Debugf("failed to read the Dockerfile at path %s . Error: %q", dockerfilePath, err)
+		return nil
+	}
+	baseImages := []string{}
+	stages := map[string]bool{"scratch": true}
+	for _, match := range dockerfileFromRegex.FindAllSubmatch(data, -1) {
+		if image := string(match[1]); !stages[strings.ToLower(image)] {
+			baseImages = AppendIfNotPresent(baseImages, image)
+		}
+		if len(match[2]) != 0 {
+			stages[strings.ToLower(string(match[2]))] = true
+		}
+	}
+	return baseImages
+}
+
+// getImageRepository returns the image without its tag and digest
+func getImageRepository(image string) string {
+	if i := strings.Index(image, "@"); i != -1 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i != -1 && !strings.Contains(image[i:], "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// GetImageBuildOrder returns the images sorted so that the images used as the base of other images are built before them,
+// along with the images each image has to be built after. The images which do not depend on each other are sorted by name.
+func GetImageBuildOrder(baseImages map[string][]string) (order []string, dependencies map[string][]string) {
+	imageNames := []string{}
+	for imageName := range baseImages {
+		imageNames = append(imageNames, imageName)
+	}
+	sort.Strings(imageNames)
+	dependencies = map[string][]string{}
+	for _, imageName := range imageNames {
+		for _, baseImage := range baseImages[imageName] {
+			baseRepository := getImageRepository(baseImage)
+			for _, builtImageName := range imageNames {
+				builtRepository := getImageRepository(builtImageName)
+				if builtImageName != imageName && (baseRepository == builtRepository || strings.HasSuffix(baseRepository, "/"+builtRepository)) {
+					dependencies[imageName] = AppendIfNotPresent(dependencies[imageName], builtImageName)
+				}
+			}
+		}
+	}
+	built := map[string]bool{}
+	for len(order) < len(imageNames) {
+		progressed := false
+		for _, imageName := range imageNames {
+			if built[imageName] {
+				continue
+			}
+			ready := true
+			for _, dependency := range dependencies[imageName] {
+				ready = ready && built[dependency]
+			}
+			if ready {
+				order = append(order, imageName)
+				built[imageName] = true
+				progressed = true
+				break
+			}
+		}
+		if progressed {
+			continue
+		}
+		for _, imageName := range imageNames {
+			if !built[imageName] {
+				logrus.Warnf("The image %s and its base images %s are built from each other. Building %s first", imageName, strings.Join(dependencies[imageName], ", "), imageName)
+				order = append(order, imageName)
+				built[imageName] = true
+				break
+			}
+		}
+	}
+	return order, dependencies
+}
+
+// MirrorImage returns the image pulled from the mirror of its registry. The image is returned as is if its registry has no mirror.
+// The images without a registry are pulled from docker.io , like docker does.
+func MirrorImage(image string, mirrors map[string]string) string {
+	registry, repository := "docker.io", image
+	if parts := strings.SplitN(image, "/", 2); len(parts) == 2 && (strings.ContainsAny(parts[0], ".:") || parts[0] == "localhost") {
+		registry, repository = parts[0], parts[1]
+	}
+	mirror, ok := mirrors[registry]
+	if !ok {
+		return image
+	}
+	if registry == "docker.io" && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return strings.TrimSuffix(mirror, "/") + "/" + repository
+}
+
 // GetFilesByExtInCurrDir returns the files present in current directory which have one of the specified extensions
 func GetFilesByExtInCurrDir(dir string, exts []string) ([]string, error) {
 	var files []string
@@ -1007,7 +1163,7 @@ func CopyFile(dst, src string) error {
 	srcfilesize := srcfileinfo.Size()
 	dstfile, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, srcfileinfo.Mode())
 	if err != nil {
-		return fmt.Errorf("failed to create the destination file at path %q Error: %q", dst, err)
+		return fmt.Errorf("failed to create the destination file at path %q Error: %w", dst, err)
 	}
 	defer dstfile.Close()
 	written, err := io.Copy(dstfile, srcfile)
@@ -1015,7 +1171,7 @@ func CopyFile(dst, src string) error {
 		return fmt.Errorf("failed to copy all the bytes from source %q to destination %q. %d out of %d bytes written. Error: %v", src, dst, written, srcfilesize, err)
 	}
 	if err != nil {
-		return fmt.Errorf("failed to copy from source %q to destination %q. Error: %q", src, dst, err)
+		return fmt.Errorf("failed to copy from source %q to destination %q. Error: %w", src, dst, err)
 	}
 	return dstfile.Close()
 }
@@ -1148,24 +1304,33 @@ func IsSameRuntimeObject(obj1, obj2 runtime.Object) bool {
 
 // MarshalObjToYaml marshals an object to yaml
 func MarshalObjToYaml(obj runtime.Object) ([]byte, error) {
-	objJSONBytes, err := json.Marshal(obj)
-	if err != nil {
-		logrus.Errorf("Error while marshalling object %+v to json. Error: %q", obj, err)
+	var b bytes.Buffer
+	if err := WriteObjToYaml(&b, obj); err != nil {
 		return nil, err
 	}
-	var jsonObj interface{}
-	if err := yaml.Unmarshal(objJSONBytes, &jsonObj); err != nil {
-		logrus.Errorf("Unable to unmarshal the json as yaml:\n%s\nError: %q", objJSONBytes, err)
-		return nil, err
+	return b.Bytes(), nil
+}
+
+// WriteObjToYaml encodes an object as yaml directly into the writer.
+// The object is converted to its unstructured content, instead of being marshalled to json and parsed again, so that only one copy of it is kept in memory.
+func WriteObjToYaml(w io.Writer, obj runtime.Object) error {
+	var content map[string]interface{}
+	if u, ok := obj.(runtime.Unstructured); ok {
+		content = u.UnstructuredContent()
+	} else {
+		var err error
+		if content, err = runtime.DefaultUnstructuredConverter.ToUnstructured(obj); err != nil {
+			logrus.Errorf("Error while converting the object %+v to unstructured. Error: %q", obj, err)
+			return err
+		}
 	}
-	var b bytes.Buffer
-	encoder := yaml.NewEncoder(&b)
+	encoder := yaml.NewEncoder(w)
 	encoder.SetIndent(2)
-	if err := encoder.Encode(jsonObj); err != nil {
-		logrus.Errorf("Error while encoding the json object:\n%s\nError: %q", jsonObj, err)
-		return nil, err
+	if err := encoder.Encode(content); err != nil {
+		logrus.Errorf("Error while encoding the object:\n%+v\nError: %q", content, err)
+		return err
 	}
-	return b.Bytes(), nil
+	return encoder.Close()
 }
 
 // ConvertInterfaceToSliceOfStrings converts an interface{} to a []string type.
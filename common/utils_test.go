@@ -18,6 +18,7 @@ package common_test
 
 import (
 	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
@@ -26,6 +27,9 @@ import (
 	"github.com/konveyor/move2kube/common"
 	"golang.org/x/text/encoding/unicode"
 	"golang.org/x/text/transform"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
 const (
@@ -193,3 +197,205 @@ func TestConvertUtf8AndUtf16ToUtf8(t *testing.T) {
 		}
 	})
 }
+
+func TestUsesServerSideSessions(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		want  bool
+	}{
+		{name: "java session", files: map[string]string{"src/Cart.java": `HttpSession session = request.getSession();`}, want: true},
+		{name: "php session", files: map[string]string{"index.php": `<?php session_start(); ?>`}, want: true},
+		{name: "sticky sessions in the config", files: map[string]string{"config/app.yml": "sticky-sessions: true"}, want: true},
+		{name: "no sessions", files: map[string]string{"app.js": `app.get("/", (req, res) => res.send("hello"))`}, want: false},
+		{name: "the sessions of the dependencies are ignored", files: map[string]string{"node_modules/express-session/index.js": `require("express-session")`}, want: false},
+		{name: "the other files are ignored", files: map[string]string{"README.md": "The JSESSIONID cookie is not used"}, want: false},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			inputPath := t.TempDir()
+			for relPath, content := range testCase.files {
+				path := filepath.Join(inputPath, relPath)
+				if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+					t.Fatalf("failed to create the directory for the file %s . Error: %q", relPath, err)
+				}
+				if err := os.WriteFile(path, []byte(content), common.DefaultFilePermission); err != nil {
+					t.Fatalf("failed to write the file %s . Error: %q", relPath, err)
+				}
+			}
+			if got := common.UsesServerSideSessions(inputPath); got != testCase.want {
+				t.Errorf("UsesServerSideSessions(%q) = %t, want %t", inputPath, got, testCase.want)
+			}
+		})
+	}
+}
+
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, errors.New("the disk is full")
+}
+
+func TestWriteObjToYaml(t *testing.T) {
+	configMap := &corev1.ConfigMap{
+		TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{"app": "web"}},
+		Data:       map[string]string{"PORT": "8080"},
+	}
+	want := `apiVersion: v1
+data:
+  PORT: "8080"
+kind: ConfigMap
+metadata:
+  creationTimestamp: null
+  labels:
+    app: web
+  name: web
+`
+	t.Run("typed object", func(t *testing.T) {
+		var b bytes.Buffer
+		if err := common.WriteObjToYaml(&b, configMap); err != nil {
+			t.Fatalf("failed to write the object as yaml. Error: %q", err)
+		}
+		if diff := cmp.Diff(want, b.String()); diff != "" {
+			t.Fatalf("unexpected yaml. Diff (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("unstructured object", func(t *testing.T) {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "web", "labels": map[string]interface{}{"app": "web"}, "creationTimestamp": nil},
+			"data":       map[string]interface{}{"PORT": "8080"},
+		}}
+		yamlBytes, err := common.MarshalObjToYaml(obj)
+		if err != nil {
+			t.Fatalf("failed to marshal the object to yaml. Error: %q", err)
+		}
+		if diff := cmp.Diff(want, string(yamlBytes)); diff != "" {
+			t.Fatalf("expected the same yaml as the typed object. Diff (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("the error of the writer is returned", func(t *testing.T) {
+		if err := common.WriteObjToYaml(failingWriter{}, configMap); err == nil {
+			t.Fatalf("expected the error of the writer to be returned")
+		}
+	})
+}
+
+func TestGetDockerfileArgsAndStages(t *testing.T) {
+	dockerfilePath := filepath.Join(t.TempDir(), "Dockerfile")
+	dockerfile := `ARG GO_VERSION=1.18
+FROM --platform=$BUILDPLATFORM golang:${GO_VERSION} AS builder
+ARG VERSION
+arg GO_VERSION
+RUN go build -ldflags "-X main.version=${VERSION}" -o /app .
+
+FROM gcr.io/distroless/static as runtime
+COPY --from=builder /app /app
+`
+	if err := os.WriteFile(dockerfilePath, []byte(dockerfile), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the Dockerfile. Error: %q", err)
+	}
+	buildArgs, stages := common.GetDockerfileArgsAndStages(dockerfilePath)
+	if want := []string{"GO_VERSION", "VERSION"}; !cmp.Equal(buildArgs, want) {
+		t.Fatalf("unexpected build args. Differences:\n%s", cmp.Diff(want, buildArgs))
+	}
+	if want := []string{"builder", "runtime"}; !cmp.Equal(stages, want) {
+		t.Fatalf("unexpected stages. Differences:\n%s", cmp.Diff(want, stages))
+	}
+	if buildArgs, stages := common.GetDockerfileArgsAndStages(filepath.Join(t.TempDir(), "Dockerfile")); buildArgs != nil || stages != nil {
+		t.Fatalf("expected no build args and stages for a missing Dockerfile. Actual: %+v %+v", buildArgs, stages)
+	}
+}
+
+func TestMirrorImage(t *testing.T) {
+	mirrors := map[string]string{
+		"docker.io":      "mirror.example.com/dockerhub/",
+		"quay.io":        "mirror.example.com/quay",
+		"localhost:5000": "mirror.example.com/local",
+	}
+	testCases := []struct {
+		image string
+		want  string
+	}{
+		{image: "golang:1.18", want: "mirror.example.com/dockerhub/library/golang:1.18"},
+		{image: "bitnami/redis", want: "mirror.example.com/dockerhub/bitnami/redis"},
+		{image: "docker.io/library/alpine", want: "mirror.example.com/dockerhub/library/alpine"},
+		{image: "quay.io/konveyor/move2kube:latest", want: "mirror.example.com/quay/konveyor/move2kube:latest"},
+		{image: "localhost:5000/app", want: "mirror.example.com/local/app"},
+		{image: "registry.access.redhat.com/ubi8/ubi", want: "registry.access.redhat.com/ubi8/ubi"},
+	}
+	for _, testCase := range testCases {
+		if got := common.MirrorImage(testCase.image, mirrors); got != testCase.want {
+			t.Errorf("MirrorImage(%q) = %q, want %q", testCase.image, got, testCase.want)
+		}
+	}
+}
+
+func TestGetDockerfileBaseImages(t *testing.T) {
+	dockerfilePath := filepath.Join(t.TempDir(), "Dockerfile")
+	dockerfile := `FROM --platform=$BUILDPLATFORM quay.io/myteam/base:1.0 AS builder
+FROM builder AS test
+from scratch
+FROM registry.access.redhat.com/ubi8/ubi-minimal
+FROM quay.io/myteam/base:1.0
+`
+	if err := os.WriteFile(dockerfilePath, []byte(dockerfile), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the Dockerfile. Error: %q", err)
+	}
+	want := []string{"quay.io/myteam/base:1.0", "registry.access.redhat.com/ubi8/ubi-minimal"}
+	if got := common.GetDockerfileBaseImages(dockerfilePath); !cmp.Equal(got, want) {
+		t.Fatalf("unexpected base images. Differences:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestGetImageBuildOrder(t *testing.T) {
+	testCases := []struct {
+		name             string
+		baseImages       map[string][]string
+		wantOrder        []string
+		wantDependencies map[string][]string
+	}{
+		{
+			name:             "the images which do not depend on each other are sorted by name",
+			baseImages:       map[string][]string{"web": {"node:18"}, "api": {"golang:1.18"}},
+			wantOrder:        []string{"api", "web"},
+			wantDependencies: map[string][]string{},
+		},
+		{
+			name: "the base images are built first",
+			baseImages: map[string][]string{
+				"app-api":        {"quay.io/myteam/app-base:latest"},
+				"app-base":       {"myteam-runtime"},
+				"app-worker":     {"app-base@sha256:0123456789abcdef"},
+				"myteam-runtime": {"registry.access.redhat.com/ubi8/ubi"},
+			},
+			wantOrder: []string{"myteam-runtime", "app-base", "app-api", "app-worker"},
+			wantDependencies: map[string][]string{
+				"app-api":    {"app-base"},
+				"app-base":   {"myteam-runtime"},
+				"app-worker": {"app-base"},
+			},
+		},
+		{
+			name:             "the images built from each other",
+			baseImages:       map[string][]string{"a": {"b"}, "b": {"a"}, "c": nil},
+			wantOrder:        []string{"c", "a", "b"},
+			wantDependencies: map[string][]string{"a": {"b"}, "b": {"a"}},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			order, dependencies := common.GetImageBuildOrder(testCase.baseImages)
+			if diff := cmp.Diff(testCase.wantOrder, order); diff != "" {
+				t.Fatalf("unexpected build order. Diff (-want +got):\n%s", diff)
+			}
+			if diff := cmp.Diff(testCase.wantDependencies, dependencies); diff != "" {
+				t.Fatalf("unexpected dependencies. Diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,60 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common
+
+import (
+	"runtime"
+	"sync"
+)
+
+// FileWriteWorkers is the number of goroutines used to write and copy the files in parallel
+var FileWriteWorkers = runtime.NumCPU() * 2
+
+// WorkerPool runs the submitted tasks on a bounded number of goroutines
+type WorkerPool struct {
+	tasks chan func()
+	wg    sync.WaitGroup
+}
+
+// NewWorkerPool starts a worker pool with the given number of goroutines
+func NewWorkerPool(workers int) *WorkerPool {
+	if workers < 1 {
+		workers = 1
+	}
+	pool := &WorkerPool{tasks: make(chan func(), workers)}
+	for i := 0; i < workers; i++ {
+		go func() {
+			for task := range pool.tasks {
+				task()
+				pool.wg.Done()
+			}
+		}()
+	}
+	return pool
+}
+
+// Submit queues the task, blocking while all the goroutines are busy
+func (p *WorkerPool) Submit(task func()) {
+	p.wg.Add(1)
+	p.tasks <- task
+}
+
+// Wait waits for the submitted tasks to finish and stops the goroutines. The pool can not be used after this.
+func (p *WorkerPool) Wait() {
+	p.wg.Wait()
+	close(p.tasks)
+}
@@ -0,0 +1,63 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package common_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+)
+
+func TestWorkerPool(t *testing.T) {
+	const workers = 3
+	pool := common.NewWorkerPool(workers)
+	var running, maxRunning, done int32
+	var mutex sync.Mutex
+	for i := 0; i < 20; i++ {
+		pool.Submit(func() {
+			current := atomic.AddInt32(&running, 1)
+			mutex.Lock()
+			if current > maxRunning {
+				maxRunning = current
+			}
+			mutex.Unlock()
+			time.Sleep(time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			atomic.AddInt32(&done, 1)
+		})
+	}
+	pool.Wait()
+	if done != 20 {
+		t.Fatalf("expected all the 20 tasks to be done after waiting, got %d", done)
+	}
+	if maxRunning > workers {
+		t.Fatalf("expected at most %d tasks to run at the same time, got %d", workers, maxRunning)
+	}
+}
+
+func TestWorkerPoolWithoutWorkers(t *testing.T) {
+	pool := common.NewWorkerPool(0)
+	done := false
+	pool.Submit(func() { done = true })
+	pool.Wait()
+	if !done {
+		t.Fatalf("expected the task to run on a single goroutine when no workers are requested")
+	}
+}
@@ -17,6 +17,7 @@
 package container
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -38,8 +39,8 @@ var (
 
 // ContainerEngine defines interface to manage containers
 type ContainerEngine interface {
-	// RunCmdInContainer runs a container
-	RunCmdInContainer(image string, cmd environmenttypes.Command, workingdir string, env []string) (stdout, stderr string, exitcode int, err error)
+	// RunCmdInContainer runs a command in a container and stops waiting for it when the context is cancelled
+	RunCmdInContainer(ctx context.Context, image string, cmd environmenttypes.Command, workingdir string, env []string) (stdout, stderr string, exitcode int, err error)
 	// InspectImage gets Inspect output for a container
 	InspectImage(image string) (dockertypes.ImageInspect, error)
 	// TODO: Change paths from map to array
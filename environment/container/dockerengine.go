@@ -101,7 +101,7 @@ func (e *dockerEngine) pullImage(image string) error {
 }
 
 // RunCmdInContainer executes a container
-func (e *dockerEngine) RunCmdInContainer(containerID string, cmd environmenttypes.Command, workingdir string, env []string) (stdout, stderr string, exitCode int, err error) {
+func (e *dockerEngine) RunCmdInContainer(ctx context.Context, containerID string, cmd environmenttypes.Command, workingdir string, env []string) (stdout, stderr string, exitCode int, err error) {
 	execConfig := types.ExecConfig{
 		AttachStdout: true,
 		AttachStderr: true,
@@ -109,15 +109,25 @@ func (e *dockerEngine) RunCmdInContainer(containerID string, cmd environmenttype
 		WorkingDir:   workingdir,
 		Env:          env,
 	}
-	cresp, err := e.cli.ContainerExecCreate(e.ctx, containerID, execConfig)
+	cresp, err := e.cli.ContainerExecCreate(ctx, containerID, execConfig)
 	if err != nil {
 		return "", "", -1, fmt.Errorf("failed to execute a process in the container. Error: %w", err)
 	}
-	aresp, err := e.cli.ContainerExecAttach(e.ctx, cresp.ID, types.ExecStartCheck{})
+	aresp, err := e.cli.ContainerExecAttach(ctx, cresp.ID, types.ExecStartCheck{})
 	if err != nil {
 		return "", "", -1, fmt.Errorf("failed to execute a process in the container and attach to it. Error: %w", err)
 	}
 	defer aresp.Close()
+	// closing the connection unblocks the reads below when the context is cancelled
+	attachDone := make(chan struct{})
+	defer close(attachDone)
+	go func() {
+		select {
+		case <-ctx.Done():
+			aresp.Close()
+		case <-attachDone:
+		}
+	}()
 
 	var outBuf, errBuf bytes.Buffer
 	outputDone := make(chan error)
@@ -146,13 +156,16 @@ func (e *dockerEngine) RunCmdInContainer(containerID string, cmd environmenttype
 		}
 		break
 
-	case <-e.ctx.Done():
-		return "", "", 0, e.ctx.Err()
+	case <-ctx.Done():
+		return "", "", 0, ctx.Err()
+	}
+	if err := ctx.Err(); err != nil {
+		return "", "", 0, err
 	}
 
 	stdoutbytes := outBuf.Bytes()
 	stderrbytes := errBuf.Bytes()
-	res, err := e.cli.ContainerExecInspect(e.ctx, cresp.ID)
+	res, err := e.cli.ContainerExecInspect(ctx, cresp.ID)
 	if err != nil {
 		return
 	}
@@ -18,6 +18,7 @@ package environment
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io/fs"
 	"net"
@@ -79,7 +80,7 @@ type EnvironmentInstance interface {
 	Stat(name string) (fs.FileInfo, error)
 	Download(envpath string) (outpath string, err error)
 	Upload(outpath string) (envpath string, err error)
-	Exec(cmd environmenttypes.Command, envList []string) (stdout string, stderr string, exitcode int, err error)
+	Exec(ctx context.Context, cmd environmenttypes.Command, envList []string) (stdout string, stderr string, exitcode int, err error)
 	Destroy() error
 
 	GetSource() string
@@ -162,12 +163,12 @@ func (e *Environment) Reset() error {
 	return e.Env.Reset()
 }
 
-// Exec executes an executable within the environment
-func (e *Environment) Exec(cmd environmenttypes.Command, envList []string) (stdout string, stderr string, exitcode int, err error) {
+// Exec executes an executable within the environment and stops it when the context is cancelled
+func (e *Environment) Exec(ctx context.Context, cmd environmenttypes.Command, envList []string) (stdout string, stderr string, exitcode int, err error) {
 	if !e.active {
 		return "", "", 0, ErrEnvironmentNotActive
 	}
-	return e.Env.Exec(cmd, envList)
+	return e.Env.Exec(ctx, cmd, envList)
 }
 
 // Destroy destroys all artifacts specific to the environment
@@ -18,6 +18,7 @@ package environment
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -90,15 +91,15 @@ func (e *Local) Stat(name string) (fs.FileInfo, error) {
 	return os.Stat(name)
 }
 
-// Exec executes an executable within the environment
-func (e *Local) Exec(cmd environmenttypes.Command, envList []string) (stdout string, stderr string, exitcode int, err error) {
+// Exec executes an executable within the environment and kills it when the context is cancelled
+func (e *Local) Exec(ctx context.Context, cmd environmenttypes.Command, envList []string) (stdout string, stderr string, exitcode int, err error) {
 	if common.DisableLocalExecution {
 		return "", "", 0, fmt.Errorf("local execution prevented by %s flag", common.DisableLocalExecutionFlag)
 	}
 	var outb, errb bytes.Buffer
 	var execcmd *exec.Cmd
 	if len(cmd) > 0 {
-		execcmd = exec.Command(cmd[0], cmd[1:]...)
+		execcmd = exec.CommandContext(ctx, cmd[0], cmd[1:]...)
 	} else {
 		return "", "", 0, fmt.Errorf("no command found to execute")
 	}
@@ -108,6 +109,9 @@ func (e *Local) Exec(cmd environmenttypes.Command, envList []string) (stdout str
 	execcmd.Env = e.getEnv()
 	execcmd.Env = append(execcmd.Env, envList...)
 	if err := execcmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return outb.String(), errb.String(), -1, fmt.Errorf("the command was stopped. Error: %w", ctxErr)
+		}
 		var ee *exec.ExitError
 		var pe *os.PathError
 		if errors.As(err, &ee) {
@@ -17,6 +17,7 @@
 package environment
 
 import (
+	"context"
 	"fmt"
 	"io/fs"
 	"net"
@@ -137,7 +138,7 @@ func (e *PeerContainer) Stat(name string) (fs.FileInfo, error) {
 }
 
 // Exec executes a command in the container
-func (e *PeerContainer) Exec(cmd environmenttypes.Command, envList []string) (stdout string, stderr string, exitcode int, err error) {
+func (e *PeerContainer) Exec(ctx context.Context, cmd environmenttypes.Command, envList []string) (stdout string, stderr string, exitcode int, err error) {
 	cengine, err := container.GetContainerEngine(false)
 	if err != nil {
 		return "", "", 0, fmt.Errorf("failed to get the container engine. Error: %w", err)
@@ -149,7 +150,7 @@ func (e *PeerContainer) Exec(cmd environmenttypes.Command, envList []string) (st
 		envs = append(envs, GRPCEnvName+"="+hostname+":"+port)
 	}
 	envs = append(envs, envList...)
-	return cengine.RunCmdInContainer(e.ContainerInfo.ID, cmd, e.ContainerInfo.WorkingDir, envs)
+	return cengine.RunCmdInContainer(ctx, e.ContainerInfo.ID, cmd, e.ContainerInfo.WorkingDir, envs)
 }
 
 // Destroy destroys the container instance
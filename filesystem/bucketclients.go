@@ -0,0 +1,733 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package filesystem
+
+import (
+	"crypto"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/ini.v1"
+)
+
+const (
+	defaultAWSRegion        = "us-east-1"
+	defaultAWSProfile       = "default"
+	stsAPIVersion           = "2011-06-15"
+	azureBlobAPIVersion     = "2020-10-02"
+	azureStorageScope       = "https://storage.azure.com/.default"
+	defaultAzureAuthority   = "https://login.microsoftonline.com/"
+	azureClientAssertionJWT = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+	defaultGCEMetadataHost  = "metadata.google.internal"
+	gcsMetadataTokenPath    = "/computeMetadata/v1/instance/service-accounts/default/token"
+	defaultGoogleTokenURI   = "https://oauth2.googleapis.com/token"
+	gcsReadWriteScope       = "https://www.googleapis.com/auth/devstorage.read_write"
+	googleJWTBearerGrant    = "urn:ietf:params:oauth:grant-type:jwt-bearer"
+	bucketClientTimeout     = 5 * time.Minute
+	// credentialsExpiryWindow is how long before they expire the temporary credentials are refreshed
+	credentialsExpiryWindow = time.Minute
+)
+
+var bucketHTTPClient = &http.Client{Timeout: bucketClientTimeout}
+
+// doBucketRequest sends the request and returns an error if the bucket did not accept it
+func doBucketRequest(req *http.Request) error {
+	resp, err := bucketHTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("the request %s %s failed with the status %s : %s", req.Method, req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// newUploadRequest returns a request which streams the size bytes read from the contents as its body.
+// The contents are not closed by the request, and are read again from the start when the request is redirected.
+func newUploadRequest(method, objectURL string, contents io.ReadSeeker, size int64) (*http.Request, error) {
+	req, err := http.NewRequest(method, objectURL, io.NopCloser(contents))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	req.GetBody = func() (io.ReadCloser, error) {
+		if _, err := contents.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(contents), nil
+	}
+	if size == 0 {
+		req.Body = http.NoBody
+	}
+	return req, nil
+}
+
+// getFirstEnv returns the value of the first environment variable which is set
+func getFirstEnv(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// escapeObjectKey escapes each of the segments of the key, leaving only the unreserved characters as is
+func escapeObjectKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = escapeURIComponent(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// escapeURIComponent escapes all the characters except the unreserved ones, as the signatures of AWS require
+func escapeURIComponent(component string) string {
+	escaped := strings.Builder{}
+	for _, c := range []byte(component) {
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '.' || c == '_' || c == '~' {
+			escaped.WriteByte(c)
+		} else {
+			escaped.WriteString(fmt.Sprintf("%%%02X", c))
+		}
+	}
+	return escaped.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// awsCredentials are the credentials used to sign the requests to AWS
+type awsCredentials struct {
+	accessKey    string
+	secretKey    string
+	sessionToken string
+	// expiry is the time the temporary credentials expire, it is zero for the credentials which do not expire
+	expiry time.Time
+}
+
+// s3BucketClient uploads the objects using the S3 REST API, signing the requests with AWS Signature Version 4.
+// The credentials are looked up like the AWS SDKs do, in the environment variables, then the web identity token
+// of the IAM roles for service accounts (IRSA), then the profile in the shared credentials file.
+type s3BucketClient struct {
+	bucket      string
+	region      string
+	endpoint    string
+	credentials awsCredentials
+	// roleARN and webIdentityTokenFile are set when the credentials are fetched from STS using the web identity token
+	roleARN              string
+	webIdentityTokenFile string
+	roleSessionName      string
+	stsEndpoint          string
+	mutex                sync.Mutex
+}
+
+func newS3BucketClient(bucket string) (*s3BucketClient, error) {
+	profile := getFirstEnv("AWS_PROFILE", "AWS_DEFAULT_PROFILE")
+	if profile == "" {
+		profile = defaultAWSProfile
+	}
+	c := &s3BucketClient{
+		bucket:   bucket,
+		region:   getFirstEnv("AWS_REGION", "AWS_DEFAULT_REGION"),
+		endpoint: strings.TrimSuffix(getFirstEnv("AWS_ENDPOINT_URL_S3", "AWS_ENDPOINT_URL"), "/"),
+	}
+	if c.region == "" {
+		c.region = getAWSProfileRegion(profile)
+	}
+	if c.region == "" {
+		c.region = defaultAWSRegion
+	}
+	if accessKey, secretKey := os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"); accessKey != "" && secretKey != "" {
+		c.credentials = awsCredentials{accessKey: accessKey, secretKey: secretKey, sessionToken: os.Getenv("AWS_SESSION_TOKEN")}
+		return c, nil
+	}
+	if tokenFile, roleARN := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE"), os.Getenv("AWS_ROLE_ARN"); tokenFile != "" && roleARN != "" {
+		c.webIdentityTokenFile = tokenFile
+		c.roleARN = roleARN
+		c.roleSessionName = os.Getenv("AWS_ROLE_SESSION_NAME")
+		if c.roleSessionName == "" {
+			c.roleSessionName = fmt.Sprintf("move2kube-%d", time.Now().Unix())
+		}
+		c.stsEndpoint = strings.TrimSuffix(getFirstEnv("AWS_ENDPOINT_URL_STS", "AWS_ENDPOINT_URL"), "/")
+		if c.stsEndpoint == "" {
+			c.stsEndpoint = "https://sts." + c.region + ".amazonaws.com"
+		}
+		return c, nil
+	}
+	credentials, err := getAWSProfileCredentials(profile)
+	if err != nil {
+		return nil, fmt.Errorf("set the environment variables AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, AWS_WEB_IDENTITY_TOKEN_FILE and AWS_ROLE_ARN, or the profile in the shared credentials file to upload to the bucket %s . Error: %w", bucket, err)
+	}
+	c.credentials = credentials
+	return c, nil
+}
+
+// getAWSSharedFilePath returns the path of the shared credentials or config file, like ~/.aws/credentials
+func getAWSSharedFilePath(envName, name string) string {
+	if path := os.Getenv(envName); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".aws", name)
+}
+
+// getAWSProfileCredentials returns the credentials of the profile in the shared credentials file
+func getAWSProfileCredentials(profile string) (awsCredentials, error) {
+	credentialsPath := getAWSSharedFilePath("AWS_SHARED_CREDENTIALS_FILE", "credentials")
+	credentialsFile, err := ini.Load(credentialsPath)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to load the shared credentials file %s . Error: %w", credentialsPath, err)
+	}
+	section, err := credentialsFile.GetSection(profile)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("the profile %s is not in the shared credentials file %s . Error: %w", profile, credentialsPath, err)
+	}
+	credentials := awsCredentials{
+		accessKey:    section.Key("aws_access_key_id").String(),
+		secretKey:    section.Key("aws_secret_access_key").String(),
+		sessionToken: section.Key("aws_session_token").String(),
+	}
+	if credentials.accessKey == "" || credentials.secretKey == "" {
+		return awsCredentials{}, fmt.Errorf("the profile %s in the shared credentials file %s does not have the aws_access_key_id and aws_secret_access_key", profile, credentialsPath)
+	}
+	return credentials, nil
+}
+
+// getAWSProfileRegion returns the region of the profile in the shared config file, or an empty string if it is not set
+func getAWSProfileRegion(profile string) string {
+	configFile, err := ini.Load(getAWSSharedFilePath("AWS_CONFIG_FILE", "config"))
+	if err != nil {
+		return ""
+	}
+	sectionName := "profile " + profile
+	if profile == defaultAWSProfile {
+		sectionName = defaultAWSProfile
+	}
+	section, err := configFile.GetSection(sectionName)
+	if err != nil {
+		return ""
+	}
+	return section.Key("region").String()
+}
+
+// getCredentials returns the credentials, fetching new ones from STS when the temporary credentials expire
+func (c *s3BucketClient) getCredentials() (awsCredentials, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.roleARN == "" || (c.credentials.accessKey != "" && time.Now().Before(c.credentials.expiry.Add(-credentialsExpiryWindow))) {
+		return c.credentials, nil
+	}
+	credentials, err := c.assumeRoleWithWebIdentity()
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to assume the role %s using the web identity token in %s . Error: %w", c.roleARN, c.webIdentityTokenFile, err)
+	}
+	c.credentials = credentials
+	return c.credentials, nil
+}
+
+// assumeRoleWithWebIdentity exchanges the web identity token for temporary credentials of the role.
+// The request to STS is not signed, since the token authenticates it.
+func (c *s3BucketClient) assumeRoleWithWebIdentity() (awsCredentials, error) {
+	token, err := os.ReadFile(c.webIdentityTokenFile)
+	if err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to read the web identity token. Error: %w", err)
+	}
+	query := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {stsAPIVersion},
+		"RoleArn":          {c.roleARN},
+		"RoleSessionName":  {c.roleSessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequest(http.MethodPost, c.stsEndpoint+"/", strings.NewReader(query.Encode()))
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := bucketHTTPClient.Do(req)
+	if err != nil {
+		return awsCredentials{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return awsCredentials{}, fmt.Errorf("STS returned the status %s : %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	result := struct {
+		Credentials struct {
+			AccessKeyID     string    `xml:"AccessKeyId"`
+			SecretAccessKey string    `xml:"SecretAccessKey"`
+			SessionToken    string    `xml:"SessionToken"`
+			Expiration      time.Time `xml:"Expiration"`
+		} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+	}{}
+	if err := xml.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return awsCredentials{}, fmt.Errorf("failed to parse the response of STS. Error: %w", err)
+	}
+	if result.Credentials.AccessKeyID == "" {
+		return awsCredentials{}, fmt.Errorf("the response of STS does not have the credentials")
+	}
+	return awsCredentials{
+		accessKey:    result.Credentials.AccessKeyID,
+		secretKey:    result.Credentials.SecretAccessKey,
+		sessionToken: result.Credentials.SessionToken,
+		expiry:       result.Credentials.Expiration,
+	}, nil
+}
+
+func (c *s3BucketClient) putObject(key string, contents io.ReadSeeker, size int64) error {
+	credentials, err := c.getCredentials()
+	if err != nil {
+		return err
+	}
+	// The custom endpoints of the S3 compatible storage use the path style urls, AWS uses the virtual hosted style urls.
+	objectURL := "https://" + c.bucket + ".s3." + c.region + ".amazonaws.com/" + escapeObjectKey(key)
+	if c.endpoint != "" {
+		objectURL = c.endpoint + "/" + c.bucket + "/" + escapeObjectKey(key)
+	}
+	// The payload is hashed by reading it once before it is streamed in the body of the request
+	hash := sha256.New()
+	if _, err := io.Copy(hash, contents); err != nil {
+		return fmt.Errorf("failed to hash the contents of the object. Error: %w", err)
+	}
+	if _, err := contents.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	req, err := newUploadRequest(http.MethodPut, objectURL, contents, size)
+	if err != nil {
+		return err
+	}
+	payloadHash := hex.EncodeToString(hash.Sum(nil))
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	signAWSV4(req, credentials, c.region, "s3", payloadHash, time.Now().UTC())
+	return doBucketRequest(req)
+}
+
+// signAWSV4 adds the AWS Signature Version 4 of the request to its headers, signing all the headers of the request
+func signAWSV4(req *http.Request, credentials awsCredentials, region, service, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("x-amz-date", amzDate)
+	if credentials.sessionToken != "" {
+		req.Header.Set("x-amz-security-token", credentials.sessionToken)
+	}
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		trimmed := []string{}
+		for _, value := range values {
+			trimmed = append(trimmed, strings.Join(strings.Fields(value), " "))
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	headerNames := []string{}
+	for name := range headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+	canonicalHeaders := ""
+	for _, name := range headerNames {
+		canonicalHeaders += name + ":" + headers[name] + "\n"
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+	queryParams := []string{}
+	for name, values := range req.URL.Query() {
+		for _, value := range values {
+			queryParams = append(queryParams, escapeURIComponent(name)+"="+escapeURIComponent(value))
+		}
+	}
+	sort.Strings(queryParams)
+	canonicalRequest := strings.Join([]string{req.Method, req.URL.EscapedPath(), strings.Join(queryParams, "&"), canonicalHeaders, signedHeaders, payloadHash}, "\n")
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+	signingKey := hmacSHA256(hmacSHA256(hmacSHA256(hmacSHA256([]byte("AWS4"+credentials.secretKey), date), region), service), "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", credentials.accessKey, scope, signedHeaders, signature))
+}
+
+// gcsBucketClient uploads the objects using the Cloud Storage JSON API with an OAuth access token.
+// Without a token in the environment, the token is fetched for the service account key in GOOGLE_APPLICATION_CREDENTIALS,
+// or else for the service account of the instance from the metadata server, which also provides the tokens of the workload identity on GKE.
+type gcsBucketClient struct {
+	bucket      string
+	token       string
+	tokenExpiry time.Time
+	// serviceAccount is set when the access token is fetched using the key of the service account
+	serviceAccount *gcsServiceAccount
+	// metadataTokenURL is set when the access token is fetched from the metadata server of the instance
+	metadataTokenURL string
+	mutex            sync.Mutex
+}
+
+// gcsServiceAccount is the key of the service account, which signs the JWT exchanged for an access token
+type gcsServiceAccount struct {
+	clientEmail string
+	tokenURI    string
+	privateKey  *rsa.PrivateKey
+}
+
+func newGCSBucketClient(bucket string) (*gcsBucketClient, error) {
+	c := &gcsBucketClient{bucket: bucket, token: os.Getenv("GOOGLE_OAUTH_ACCESS_TOKEN")}
+	if c.token != "" {
+		return c, nil
+	}
+	if credentialsFile := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); credentialsFile != "" {
+		serviceAccount, err := readGCSServiceAccount(credentialsFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the credentials in the file %s set in GOOGLE_APPLICATION_CREDENTIALS . Error: %w", credentialsFile, err)
+		}
+		c.serviceAccount = serviceAccount
+		return c, nil
+	}
+	metadataHost := os.Getenv("GCE_METADATA_HOST")
+	if metadataHost == "" {
+		metadataHost = defaultGCEMetadataHost
+	}
+	c.metadataTokenURL = "http://" + metadataHost + gcsMetadataTokenPath
+	return c, nil
+}
+
+// readGCSServiceAccount reads the key of the service account in the JSON credentials file.
+// Only the service account keys are supported, not the authorized user or the external account credentials of gcloud.
+func readGCSServiceAccount(credentialsFile string) (*gcsServiceAccount, error) {
+	data, err := os.ReadFile(credentialsFile)
+	if err != nil {
+		return nil, err
+	}
+	credentials := struct {
+		Type        string `json:"type"`
+		ClientEmail string `json:"client_email"`
+		PrivateKey  string `json:"private_key"`
+		TokenURI    string `json:"token_uri"`
+	}{}
+	if err := json.Unmarshal(data, &credentials); err != nil {
+		return nil, fmt.Errorf("failed to parse the credentials. Error: %w", err)
+	}
+	if credentials.Type != "service_account" {
+		return nil, fmt.Errorf("the credentials of type %q are not supported, only the service account keys of type \"service_account\" are supported", credentials.Type)
+	}
+	if credentials.ClientEmail == "" {
+		return nil, fmt.Errorf("the service account key does not have the client_email")
+	}
+	block, _ := pem.Decode([]byte(credentials.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("the private_key of the service account %s is not PEM encoded", credentials.ClientEmail)
+	}
+	var privateKey *rsa.PrivateKey
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("the private_key of the service account %s is not an RSA key", credentials.ClientEmail)
+		}
+		privateKey = rsaKey
+	} else if privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes); err != nil {
+		return nil, fmt.Errorf("failed to parse the private_key of the service account %s . Error: %w", credentials.ClientEmail, err)
+	}
+	if credentials.TokenURI == "" {
+		credentials.TokenURI = defaultGoogleTokenURI
+	}
+	return &gcsServiceAccount{clientEmail: credentials.ClientEmail, tokenURI: credentials.TokenURI, privateKey: privateKey}, nil
+}
+
+// getAssertion returns the JWT signed with the key of the service account, which is exchanged for an access token
+func (s *gcsServiceAccount) getAssertion(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   s.clientEmail,
+		"scope": gcsReadWriteScope,
+		"aud":   s.tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// getToken returns the access token, fetching a new one for the service account or from the metadata server when it expires
+func (c *gcsBucketClient) getToken() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if (c.serviceAccount == nil && c.metadataTokenURL == "") || (c.token != "" && time.Now().Before(c.tokenExpiry)) {
+		return c.token, nil
+	}
+	var req *http.Request
+	source := "the metadata server"
+	if c.serviceAccount != nil {
+		source = "the service account " + c.serviceAccount.clientEmail
+		assertion, err := c.serviceAccount.getAssertion(time.Now())
+		if err != nil {
+			return "", fmt.Errorf("failed to sign the JWT of %s . Error: %w", source, err)
+		}
+		form := url.Values{"grant_type": {googleJWTBearerGrant}, "assertion": {assertion}}
+		req, err = http.NewRequest(http.MethodPost, c.serviceAccount.tokenURI, strings.NewReader(form.Encode()))
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	} else {
+		var err error
+		req, err = http.NewRequest(http.MethodGet, c.metadataTokenURL, nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+	}
+	resp, err := bucketHTTPClient.Do(req)
+	if err != nil {
+		if c.serviceAccount == nil {
+			return "", fmt.Errorf("the environment variables GOOGLE_OAUTH_ACCESS_TOKEN and GOOGLE_APPLICATION_CREDENTIALS are not set and the access token could not be fetched from the metadata server. Error: %w", err)
+		}
+		return "", fmt.Errorf("failed to fetch the access token of %s . Error: %w", source, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("failed to fetch the access token of %s . Status: %s : %s", source, resp.Status, strings.TrimSpace(string(body)))
+	}
+	token := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to parse the access token of %s . Error: %w", source, err)
+	}
+	c.token = token.AccessToken
+	c.tokenExpiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - credentialsExpiryWindow)
+	return c.token, nil
+}
+
+func (c *gcsBucketClient) putObject(key string, contents io.ReadSeeker, size int64) error {
+	token, err := c.getToken()
+	if err != nil {
+		return err
+	}
+	objectURL := "https://storage.googleapis.com/upload/storage/v1/b/" + url.PathEscape(c.bucket) + "/o?uploadType=media&name=" + url.QueryEscape(key)
+	req, err := newUploadRequest(http.MethodPost, objectURL, contents, size)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	return doBucketRequest(req)
+}
+
+// azureBlobBucketClient uploads the objects as block blobs using the Blob Storage REST API,
+// authorizing the requests with the shared key of the storage account, a SAS token or the Azure AD token of the workload identity
+type azureBlobBucketClient struct {
+	container  string
+	account    string
+	endpoint   string
+	accountKey []byte
+	sasToken   string
+	// the workload identity exchanges the federated token in the file for an Azure AD token
+	clientID           string
+	tenantID           string
+	federatedTokenFile string
+	authorityHost      string
+	token              string
+	tokenExpiry        time.Time
+	mutex              sync.Mutex
+}
+
+func newAzureBlobBucketClient(container string) (*azureBlobBucketClient, error) {
+	c := &azureBlobBucketClient{
+		container: container,
+		account:   os.Getenv("AZURE_STORAGE_ACCOUNT"),
+		endpoint:  strings.TrimSuffix(os.Getenv("AZURE_STORAGE_ENDPOINT"), "/"),
+		sasToken:  strings.TrimPrefix(os.Getenv("AZURE_STORAGE_SAS_TOKEN"), "?"),
+	}
+	if c.account == "" {
+		return nil, fmt.Errorf("the environment variable AZURE_STORAGE_ACCOUNT must be set to upload to the container %s", container)
+	}
+	if c.endpoint == "" {
+		c.endpoint = "https://" + c.account + ".blob.core.windows.net"
+	}
+	if accountKey := os.Getenv("AZURE_STORAGE_KEY"); accountKey != "" {
+		key, err := base64.StdEncoding.DecodeString(accountKey)
+		if err != nil {
+			return nil, fmt.Errorf("the environment variable AZURE_STORAGE_KEY is not base64 encoded. Error: %w", err)
+		}
+		c.accountKey = key
+		return c, nil
+	}
+	if c.sasToken != "" {
+		return c, nil
+	}
+	c.clientID, c.tenantID, c.federatedTokenFile = os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_FEDERATED_TOKEN_FILE")
+	if c.clientID == "" || c.tenantID == "" || c.federatedTokenFile == "" {
+		return nil, fmt.Errorf("set the environment variable AZURE_STORAGE_KEY or AZURE_STORAGE_SAS_TOKEN, or use the workload identity with AZURE_CLIENT_ID, AZURE_TENANT_ID and AZURE_FEDERATED_TOKEN_FILE to upload to the container %s", container)
+	}
+	c.authorityHost = os.Getenv("AZURE_AUTHORITY_HOST")
+	if c.authorityHost == "" {
+		c.authorityHost = defaultAzureAuthority
+	}
+	if !strings.HasSuffix(c.authorityHost, "/") {
+		c.authorityHost += "/"
+	}
+	return c, nil
+}
+
+// getToken returns the Azure AD token of the workload identity, fetching a new one when it expires
+func (c *azureBlobBucketClient) getToken() (string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.token != "" && time.Now().Before(c.tokenExpiry) {
+		return c.token, nil
+	}
+	federatedToken, err := os.ReadFile(c.federatedTokenFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the federated token of the workload identity. Error: %w", err)
+	}
+	form := url.Values{
+		"client_id":             {c.clientID},
+		"scope":                 {azureStorageScope},
+		"grant_type":            {"client_credentials"},
+		"client_assertion_type": {azureClientAssertionJWT},
+		"client_assertion":      {strings.TrimSpace(string(federatedToken))},
+	}
+	resp, err := bucketHTTPClient.PostForm(c.authorityHost+url.PathEscape(c.tenantID)+"/oauth2/v2.0/token", form)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch the Azure AD token of the workload identity. Error: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return "", fmt.Errorf("failed to fetch the Azure AD token of the workload identity. Status: %s : %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	token := struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("failed to parse the Azure AD token of the workload identity. Error: %w", err)
+	}
+	c.token = token.AccessToken
+	c.tokenExpiry = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - credentialsExpiryWindow)
+	return c.token, nil
+}
+
+func (c *azureBlobBucketClient) putObject(key string, contents io.ReadSeeker, size int64) error {
+	objectURL := c.endpoint + "/" + c.container + "/" + escapeObjectKey(key)
+	if c.accountKey == nil && c.sasToken != "" {
+		objectURL += "?" + c.sasToken
+	}
+	req, err := newUploadRequest(http.MethodPut, objectURL, contents, size)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("x-ms-blob-type", "BlockBlob")
+	req.Header.Set("x-ms-date", time.Now().UTC().Format(http.TimeFormat))
+	req.Header.Set("x-ms-version", azureBlobAPIVersion)
+	switch {
+	case c.accountKey != nil:
+		signature := base64.StdEncoding.EncodeToString(hmacSHA256(c.accountKey, getAzureSharedKeyStringToSign(req, c.account, size)))
+		req.Header.Set("Authorization", "SharedKey "+c.account+":"+signature)
+	case c.sasToken == "":
+		token, err := c.getToken()
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return doBucketRequest(req)
+}
+
+// getAzureSharedKeyStringToSign returns the string signed with the shared key of the storage account
+func getAzureSharedKeyStringToSign(req *http.Request, account string, contentLength int64) string {
+	msHeaders := []string{}
+	for name := range req.Header {
+		if lowerName := strings.ToLower(name); strings.HasPrefix(lowerName, "x-ms-") {
+			msHeaders = append(msHeaders, lowerName+":"+strings.TrimSpace(req.Header.Get(name)))
+		}
+	}
+	sort.Strings(msHeaders)
+	contentLengthStr := ""
+	if contentLength != 0 {
+		contentLengthStr = fmt.Sprint(contentLength)
+	}
+	canonicalizedResource := "/" + account + req.URL.EscapedPath()
+	query := req.URL.Query()
+	queryNames := []string{}
+	for name := range query {
+		queryNames = append(queryNames, name)
+	}
+	sort.Strings(queryNames)
+	for _, name := range queryNames {
+		values := append([]string{}, query[name]...)
+		sort.Strings(values)
+		canonicalizedResource += "\n" + strings.ToLower(name) + ":" + strings.Join(values, ",")
+	}
+	// VERB, Content-Encoding, Content-Language, Content-Length, Content-MD5, Content-Type, Date,
+	// If-Modified-Since, If-Match, If-None-Match, If-Unmodified-Since, Range, headers and resource
+	return strings.Join([]string{
+		req.Method,
+		req.Header.Get("Content-Encoding"),
+		req.Header.Get("Content-Language"),
+		contentLengthStr,
+		req.Header.Get("Content-MD5"),
+		req.Header.Get("Content-Type"),
+		req.Header.Get("Date"),
+		req.Header.Get("If-Modified-Since"),
+		req.Header.Get("If-Match"),
+		req.Header.Get("If-None-Match"),
+		req.Header.Get("If-Unmodified-Since"),
+		req.Header.Get("Range"),
+	}, "\n") + "\n" + strings.Join(msHeaders, "\n") + "\n" + canonicalizedResource
+}
@@ -0,0 +1,289 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package filesystem
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const (
+	// S3BucketScheme is the scheme of the urls of the Amazon S3 buckets and the S3 compatible buckets. Example: s3://my-bucket/builds
+	S3BucketScheme = "s3"
+	// GCSBucketScheme is the scheme of the urls of the Google Cloud Storage buckets. Example: gs://my-bucket/builds
+	GCSBucketScheme = "gs"
+	// AzureBlobBucketScheme is the scheme of the urls of the Azure Blob Storage containers. Example: azblob://my-container/builds
+	AzureBlobBucketScheme = "azblob"
+)
+
+// BucketSchemes are the schemes of the supported object storage buckets
+var BucketSchemes = []string{S3BucketScheme, GCSBucketScheme, AzureBlobBucketScheme}
+
+// errBucketUnsupported is returned for the operations which the objects in a bucket do not support
+var errBucketUnsupported = fmt.Errorf("the operation is not supported by the bucket filesystem, which can only be written to")
+
+// bucketClient uploads objects to an object storage bucket
+type bucketClient interface {
+	// putObject streams the size bytes read from the contents to the object with the key
+	putObject(key string, contents io.ReadSeeker, size int64) error
+}
+
+// BucketFs is a write only afero.Fs which uploads the files to an object storage bucket.
+// The paths under the root directory are stored as objects with the same relative path under the prefix of the bucket url.
+// The buckets have no directories, so creating and removing the directories does nothing.
+type BucketFs struct {
+	bucketURL string
+	root      string
+	prefix    string
+	client    bucketClient
+}
+
+// NewBucketFs returns a filesystem which uploads the files under the root directory to the bucket.
+// The credentials are read from the environment:
+//   - s3://bucket/prefix uses AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN, or the IAM role for service accounts using AWS_WEB_IDENTITY_TOKEN_FILE and AWS_ROLE_ARN,
+//     or the AWS_PROFILE in the shared credentials file, along with AWS_REGION and AWS_ENDPOINT_URL for the S3 compatible storage
+//   - gs://bucket/prefix uses GOOGLE_OAUTH_ACCESS_TOKEN, or the service account key in GOOGLE_APPLICATION_CREDENTIALS,
+//     or the service account of the instance or the workload identity when running on Google Cloud
+//   - azblob://container/prefix uses AZURE_STORAGE_ACCOUNT along with AZURE_STORAGE_KEY, AZURE_STORAGE_SAS_TOKEN or the workload identity
+//     using AZURE_CLIENT_ID, AZURE_TENANT_ID and AZURE_FEDERATED_TOKEN_FILE, and AZURE_STORAGE_ENDPOINT for the emulators
+func NewBucketFs(bucketURL, root string) (*BucketFs, error) {
+	u, err := url.Parse(bucketURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the bucket url %s . Error: %w", bucketURL, err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("the bucket url %s does not have the name of the bucket", bucketURL)
+	}
+	var client bucketClient
+	switch u.Scheme {
+	case S3BucketScheme:
+		client, err = newS3BucketClient(u.Host)
+	case GCSBucketScheme:
+		client, err = newGCSBucketClient(u.Host)
+	case AzureBlobBucketScheme:
+		client, err = newAzureBlobBucketClient(u.Host)
+	default:
+		return nil, fmt.Errorf("the scheme of the bucket url %s is not supported. Supported schemes are %+v", bucketURL, BucketSchemes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the client for the bucket %s . Error: %w", bucketURL, err)
+	}
+	return &BucketFs{bucketURL: bucketURL, root: root, prefix: strings.Trim(u.Path, "/"), client: client}, nil
+}
+
+// IsBucketURL returns true if the url refers to one of the supported object storage buckets
+func IsBucketURL(bucketURL string) bool {
+	for _, scheme := range BucketSchemes {
+		if strings.HasPrefix(bucketURL, scheme+"://") {
+			return true
+		}
+	}
+	return false
+}
+
+// getKey returns the key of the object stored at the path
+func (b *BucketFs) getKey(name string) (string, error) {
+	relPath, err := filepath.Rel(b.root, name)
+	if err != nil || relPath == ".." || strings.HasPrefix(relPath, ".."+string(os.PathSeparator)) {
+		return "", fmt.Errorf("the path %s is outside the directory %s which is uploaded to the bucket %s", name, b.root, b.bucketURL)
+	}
+	return path.Join(b.prefix, filepath.ToSlash(relPath)), nil
+}
+
+// Create creates the object at the path. The object is uploaded when the file is closed.
+func (b *BucketFs) Create(name string) (afero.File, error) {
+	return b.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+}
+
+// Mkdir does nothing, since the buckets have no directories
+func (b *BucketFs) Mkdir(name string, perm os.FileMode) error {
+	return nil
+}
+
+// MkdirAll does nothing, since the buckets have no directories
+func (b *BucketFs) MkdirAll(path string, perm os.FileMode) error {
+	return nil
+}
+
+// Open is not supported, since the filesystem is write only
+func (b *BucketFs) Open(name string) (afero.File, error) {
+	return nil, &os.PathError{Op: "open", Path: name, Err: errBucketUnsupported}
+}
+
+// OpenFile opens the object at the path for writing. The object is uploaded when the file is closed.
+func (b *BucketFs) OpenFile(name string, flag int, perm os.FileMode) (afero.File, error) {
+	if flag&(os.O_WRONLY|os.O_RDWR) == 0 {
+		return nil, &os.PathError{Op: "open", Path: name, Err: errBucketUnsupported}
+	}
+	key, err := b.getKey(name)
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	contents, err := os.CreateTemp("", "move2kube-bucket-*")
+	if err != nil {
+		return nil, &os.PathError{Op: "open", Path: name, Err: err}
+	}
+	return &bucketFile{fs: b, name: name, key: key, contents: contents}, nil
+}
+
+// Remove does nothing. The existing objects are overwritten by the objects with the same key.
+func (b *BucketFs) Remove(name string) error {
+	return nil
+}
+
+// RemoveAll does nothing. The existing objects are overwritten by the objects with the same key,
+// the objects which are not written again are left as is.
+func (b *BucketFs) RemoveAll(path string) error {
+	logrus.Debugf("The objects under %s in the bucket %s are overwritten but not removed", path, b.bucketURL)
+	return nil
+}
+
+// Rename is not supported, since the objects can not be moved
+func (b *BucketFs) Rename(oldname, newname string) error {
+	return &os.LinkError{Op: "rename", Old: oldname, New: newname, Err: errBucketUnsupported}
+}
+
+// Stat is not supported, since the filesystem is write only
+func (b *BucketFs) Stat(name string) (os.FileInfo, error) {
+	return nil, &os.PathError{Op: "stat", Path: name, Err: errBucketUnsupported}
+}
+
+// Name returns the name of the filesystem
+func (b *BucketFs) Name() string {
+	return "BucketFs(" + b.bucketURL + ")"
+}
+
+// Chmod does nothing, since the objects have no permissions
+func (b *BucketFs) Chmod(name string, mode os.FileMode) error {
+	return nil
+}
+
+// Chown does nothing, since the objects have no owners
+func (b *BucketFs) Chown(name string, uid, gid int) error {
+	return nil
+}
+
+// Chtimes does nothing, since the modification time of the objects is set by the bucket
+func (b *BucketFs) Chtimes(name string, atime time.Time, mtime time.Time) error {
+	return nil
+}
+
+// bucketFile spools the contents written to it to a temporary file,
+// which is streamed to the bucket and removed when the file is closed
+type bucketFile struct {
+	fs       *BucketFs
+	name     string
+	key      string
+	contents *os.File
+	closed   bool
+}
+
+func (f *bucketFile) Close() error {
+	if f.closed {
+		return nil
+	}
+	f.closed = true
+	defer os.Remove(f.contents.Name())
+	defer f.contents.Close()
+	size, err := f.contents.Seek(0, io.SeekEnd)
+	if err != nil {
+		return fmt.Errorf("failed to read the contents of the file %s . Error: %w", f.name, err)
+	}
+	if _, err := f.contents.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read the contents of the file %s . Error: %w", f.name, err)
+	}
+	if err := f.fs.client.putObject(f.key, f.contents, size); err != nil {
+		return fmt.Errorf("failed to upload the file %s to the bucket %s . Error: %w", f.name, f.fs.bucketURL, err)
+	}
+	logrus.Debugf("uploaded the file %s to the bucket %s as %s", f.name, f.fs.bucketURL, f.key)
+	return nil
+}
+
+func (f *bucketFile) Write(p []byte) (int, error) {
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	return f.contents.Write(p)
+}
+
+func (f *bucketFile) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+// Truncate discards the contents after the size, the following writes are appended to the remaining contents
+func (f *bucketFile) Truncate(size int64) error {
+	if f.closed {
+		return os.ErrClosed
+	}
+	current, err := f.contents.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if size > current {
+		return errBucketUnsupported
+	}
+	if err := f.contents.Truncate(size); err != nil {
+		return err
+	}
+	_, err = f.contents.Seek(size, io.SeekStart)
+	return err
+}
+
+func (f *bucketFile) Name() string {
+	return f.name
+}
+
+func (f *bucketFile) Sync() error {
+	return nil
+}
+
+func (f *bucketFile) Read(p []byte) (int, error) {
+	return 0, errBucketUnsupported
+}
+
+func (f *bucketFile) ReadAt(p []byte, off int64) (int, error) {
+	return 0, errBucketUnsupported
+}
+
+func (f *bucketFile) Seek(offset int64, whence int) (int64, error) {
+	return 0, errBucketUnsupported
+}
+
+func (f *bucketFile) WriteAt(p []byte, off int64) (int, error) {
+	return 0, errBucketUnsupported
+}
+
+func (f *bucketFile) Readdir(count int) ([]os.FileInfo, error) {
+	return nil, errBucketUnsupported
+}
+
+func (f *bucketFile) Readdirnames(n int) ([]string, error) {
+	return nil, errBucketUnsupported
+}
+
+func (f *bucketFile) Stat() (os.FileInfo, error) {
+	return nil, errBucketUnsupported
+}
@@ -0,0 +1,76 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package filesystem
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// recordingBucketClient records the objects uploaded to it
+type recordingBucketClient struct {
+	objects map[string]string
+}
+
+func (c *recordingBucketClient) putObject(key string, contents io.ReadSeeker, size int64) error {
+	data, err := io.ReadAll(contents)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) != size {
+		return io.ErrUnexpectedEOF
+	}
+	c.objects[key] = string(data)
+	return nil
+}
+
+func TestBucketFileUpload(t *testing.T) {
+	root := t.TempDir()
+	client := &recordingBucketClient{objects: map[string]string{}}
+	fs := &BucketFs{bucketURL: "s3://my-bucket/builds", root: root, prefix: "builds", client: client}
+	f, err := fs.Create(filepath.Join(root, "deploy", "app.yaml"))
+	if err != nil {
+		t.Fatalf("failed to create the file. Error: %q", err)
+	}
+	if _, err := f.WriteString("kind: Deployment\nreplicas: 2\n"); err != nil {
+		t.Fatalf("failed to write the file. Error: %q", err)
+	}
+	if err := f.Truncate(17); err != nil {
+		t.Fatalf("failed to truncate the file. Error: %q", err)
+	}
+	if _, err := f.WriteString("replicas: 3\n"); err != nil {
+		t.Fatalf("failed to write the file. Error: %q", err)
+	}
+	if err := f.Truncate(100); err == nil {
+		t.Fatalf("expected an error when the file is extended")
+	}
+	spool := f.(*bucketFile).contents.Name()
+	if err := f.Close(); err != nil {
+		t.Fatalf("failed to close the file. Error: %q", err)
+	}
+	if got, want := client.objects["builds/deploy/app.yaml"], "kind: Deployment\nreplicas: 3\n"; got != want {
+		t.Fatalf("got the object %q , want %q", got, want)
+	}
+	if _, err := os.Stat(spool); !os.IsNotExist(err) {
+		t.Fatalf("expected the temporary file %s to be removed after the upload. Error: %v", spool, err)
+	}
+	if _, err := f.Write([]byte("more")); err != os.ErrClosed {
+		t.Fatalf("got the error %v when writing to the closed file, want %v", err, os.ErrClosed)
+	}
+}
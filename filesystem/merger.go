@@ -30,8 +30,25 @@ func Merge(source, destination string, warnOnOverwrite bool) error {
 		deletionCallBack:    mergeDeletionCallBack,
 		mismatchCallBack:    mergeDeletionCallBack,
 		config:              warnOnOverwrite,
+		parallel:            true,
 	}
-	return newProcessor(options).process(source, destination)
+	return newProcessor(options).run(source, destination)
+}
+
+// MergeExcluding copies and merges data into destination directory, leaving out the paths for which excluded returns true
+// and copying the symbolic links according to the policy
+func MergeExcluding(source, destination string, warnOnOverwrite bool, excluded func(sourcePath string, isDir bool) bool, symlinks SymlinkPolicy) error {
+	options := options{
+		processFileCallBack: mergeProcessFileCallBack,
+		additionCallBack:    mergeAdditionCallBack,
+		deletionCallBack:    mergeDeletionCallBack,
+		mismatchCallBack:    mergeDeletionCallBack,
+		config:              warnOnOverwrite,
+		skip:                excluded,
+		symlinks:            symlinks,
+		parallel:            true,
+	}
+	return newProcessor(options).run(source, destination)
 }
 
 func mergeProcessFileCallBack(sourceFilePath, destinationFilePath string, config interface{}) error {
@@ -42,7 +59,7 @@ func mergeProcessFileCallBack(sourceFilePath, destinationFilePath string, config
 	}
 	di, err := os.Stat(destinationFilePath)
 	if err == nil {
-		if !(si.Mode().IsRegular() != di.Mode().IsRegular() || si.Size() != di.Size() || si.ModTime() != di.ModTime()) {
+		if !(si.Mode() != di.Mode() || si.Size() != di.Size() || si.ModTime() != di.ModTime()) {
 			return nil
 		}
 		if config.(bool) {
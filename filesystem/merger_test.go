@@ -0,0 +1,75 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeCopiesTheFilesInParallel(t *testing.T) {
+	source := t.TempDir()
+	destination := t.TempDir()
+	want := map[string]string{}
+	for i := 0; i < 50; i++ {
+		path := filepath.Join(fmt.Sprintf("dir%d", i%5), fmt.Sprintf("sub%d", i%2), fmt.Sprintf("file%d.txt", i))
+		want[path] = fmt.Sprintf("contents %d", i)
+	}
+	want["top.txt"] = "top"
+	for path, contents := range want {
+		path = filepath.Join(source, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create the directory %s . Error: %q", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(destination, "existing.txt"), []byte("existing"), 0644); err != nil {
+		t.Fatalf("failed to write the existing file. Error: %q", err)
+	}
+	want["existing.txt"] = "existing"
+	if err := Merge(source, destination, false); err != nil {
+		t.Fatalf("failed to merge. Error: %q", err)
+	}
+	count := 0
+	if err := filepath.Walk(destination, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		count++
+		relPath, err := filepath.Rel(destination, path)
+		if err != nil {
+			return err
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if string(contents) != want[relPath] {
+			t.Errorf("got the contents %q for the file %s , want %q", contents, relPath, want[relPath])
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to walk the destination. Error: %q", err)
+	}
+	if count != len(want) {
+		t.Fatalf("expected %d files after the merge, got %d", len(want), count)
+	}
+}
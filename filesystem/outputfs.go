@@ -0,0 +1,145 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package filesystem
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+)
+
+const copyMarkerPattern = ".m2k-copy-marker-*"
+
+// CopyToFs copies the source directory on the local filesystem to the destination directory in the given filesystem.
+// The destination directory is removed first, so that it contains only the files in the source directory.
+// It fails when the filesystem is backed by the local filesystem and the destination overlaps the source,
+// like afero.NewBasePathFs(afero.NewOsFs(), "/") with the same path, since removing the destination would remove the source.
+func CopyToFs(source string, destFs afero.Fs, destination string) error {
+	overlaps, err := overlapsOnTheLocalFs(source, destFs, destination)
+	if err != nil {
+		return fmt.Errorf("failed to check if the destination directory %s overlaps the source directory %s . Error: %w", destination, source, err)
+	}
+	if overlaps {
+		return fmt.Errorf("the destination directory %s in the filesystem %s overlaps the source directory %s on the local filesystem", destination, destFs.Name(), source)
+	}
+	if err := destFs.RemoveAll(destination); err != nil {
+		return fmt.Errorf("failed to remove the destination directory %s . Error: %w", destination, err)
+	}
+	return filepath.WalkDir(source, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(source, path)
+		if err != nil {
+			return fmt.Errorf("failed to make the path %s relative to the directory %s . Error: %w", path, source, err)
+		}
+		destPath := filepath.Join(destination, relPath)
+		info, err := d.Info()
+		if err != nil {
+			return fmt.Errorf("failed to stat the path %s . Error: %w", path, err)
+		}
+		switch {
+		case d.IsDir():
+			if err := destFs.MkdirAll(destPath, info.Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to create the directory %s . Error: %w", destPath, err)
+			}
+		case d.Type()&os.ModeSymlink != 0:
+			linker, ok := destFs.(afero.Linker)
+			if !ok {
+				logrus.Warnf("skipping the symbolic link %s since the filesystem %s does not support symbolic links", path, destFs.Name())
+				return nil
+			}
+			link, err := os.Readlink(path)
+			if err != nil {
+				return fmt.Errorf("failed to read the symbolic link %s . Error: %w", path, err)
+			}
+			if err := linker.SymlinkIfPossible(link, destPath); err != nil {
+				return fmt.Errorf("failed to create the symbolic link %s . Error: %w", destPath, err)
+			}
+		default:
+			if err := copyFileToFs(path, destFs, destPath, info.Mode().Perm()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// overlapsOnTheLocalFs returns true if the destination directory in the filesystem is the source directory on the local filesystem,
+// or one of its parents or sub directories. Since the filesystem could be any wrapper of the local filesystem,
+// it is detected by looking for a marker file created on one side from the other side.
+func overlapsOnTheLocalFs(source string, destFs afero.Fs, destination string) (bool, error) {
+	// the destination is the source or one of its sub directories
+	sourceMarker, err := os.CreateTemp(source, copyMarkerPattern)
+	if err != nil {
+		return false, fmt.Errorf("failed to create a marker file in the directory %s . Error: %w", source, err)
+	}
+	sourceMarker.Close()
+	defer os.Remove(sourceMarker.Name())
+	for dir := destination; ; dir = filepath.Dir(dir) {
+		if _, err := destFs.Stat(filepath.Join(dir, filepath.Base(sourceMarker.Name()))); err == nil {
+			return true, nil
+		}
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	// the destination is one of the parents of the source
+	if fi, err := destFs.Stat(destination); err != nil || !fi.IsDir() {
+		return false, nil
+	}
+	destMarker, err := afero.TempFile(destFs, destination, copyMarkerPattern)
+	if err != nil {
+		return false, fmt.Errorf("failed to create a marker file in the directory %s . Error: %w", destination, err)
+	}
+	destMarker.Close()
+	defer destFs.Remove(destMarker.Name())
+	for dir := source; ; dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, filepath.Base(destMarker.Name()))); err == nil {
+			return true, nil
+		}
+		if dir == filepath.Dir(dir) {
+			break
+		}
+	}
+	return false, nil
+}
+
+func copyFileToFs(source string, destFs afero.Fs, destination string, perm os.FileMode) error {
+	src, err := os.Open(source)
+	if err != nil {
+		return fmt.Errorf("failed to open the file %s . Error: %w", source, err)
+	}
+	defer src.Close()
+	dest, err := destFs.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create the file %s . Error: %w", destination, err)
+	}
+	if _, err := io.Copy(dest, src); err != nil {
+		dest.Close()
+		return fmt.Errorf("failed to copy the file %s to %s . Error: %w", source, destination, err)
+	}
+	// the filesystems like the buckets write the file only when it is closed
+	if err := dest.Close(); err != nil {
+		return fmt.Errorf("failed to write the file %s . Error: %w", destination, err)
+	}
+	return nil
+}
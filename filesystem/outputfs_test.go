@@ -0,0 +1,108 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/afero"
+)
+
+func TestCopyToFs(t *testing.T) {
+	source := t.TempDir()
+	for path, contents := range map[string]string{"deploy/yamls/web-deployment.yaml": "kind: Deployment", "Readme.md": "readme"} {
+		path = filepath.Join(source, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create the directory. Error: %q", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write the file. Error: %q", err)
+		}
+	}
+	if runtime.GOOS != "windows" {
+		if err := os.Symlink("Readme.md", filepath.Join(source, "README")); err != nil {
+			t.Fatalf("failed to create the symbolic link. Error: %q", err)
+		}
+	}
+	memFs := afero.NewMemMapFs()
+	destination := filepath.Join(string(filepath.Separator), "output", "myproject")
+	stalePath := filepath.Join(destination, "stale.yaml")
+	if err := afero.WriteFile(memFs, stalePath, []byte("kind: Stale"), 0644); err != nil {
+		t.Fatalf("failed to write the stale file. Error: %q", err)
+	}
+
+	if err := CopyToFs(source, memFs, destination); err != nil {
+		t.Fatalf("failed to copy to the in-memory filesystem. Error: %q", err)
+	}
+	contents, err := afero.ReadFile(memFs, filepath.Join(destination, "deploy", "yamls", "web-deployment.yaml"))
+	if err != nil || string(contents) != "kind: Deployment" {
+		t.Fatalf("got the contents %q , want the contents of the source file. Error: %v", string(contents), err)
+	}
+	if exists, _ := afero.Exists(memFs, stalePath); exists {
+		t.Fatalf("expected the files which are not in the source directory to be removed from the destination")
+	}
+	// the in-memory filesystem does not support the symbolic links, so they are skipped
+	if exists, _ := afero.Exists(memFs, filepath.Join(destination, "README")); exists {
+		t.Fatalf("expected the symbolic link to be skipped")
+	}
+}
+
+func TestCopyToFsRefusesOverlappingLocalDirectories(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(root, "output", "myproject")
+	if err := os.MkdirAll(source, 0755); err != nil {
+		t.Fatalf("failed to create the source directory. Error: %q", err)
+	}
+	sourceFile := filepath.Join(source, "Readme.md")
+	if err := os.WriteFile(sourceFile, []byte("readme"), 0644); err != nil {
+		t.Fatalf("failed to write the file. Error: %q", err)
+	}
+	osBackedFs := afero.NewBasePathFs(afero.NewOsFs(), string(filepath.Separator))
+	testCases := []struct {
+		name        string
+		destination string
+		wantErr     bool
+	}{
+		{name: "same directory", destination: source, wantErr: true},
+		{name: "parent directory", destination: filepath.Dir(source), wantErr: true},
+		{name: "sub directory", destination: filepath.Join(source, "copy"), wantErr: true},
+		{name: "separate directory", destination: filepath.Join(root, "copy")},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := CopyToFs(source, osBackedFs, testCase.destination)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("got the error %v , want an error: %t", err, testCase.wantErr)
+			}
+			if contents, err := os.ReadFile(sourceFile); err != nil || string(contents) != "readme" {
+				t.Fatalf("expected the source to be left as it was, got the contents %q . Error: %v", string(contents), err)
+			}
+			if !testCase.wantErr {
+				if contents, err := os.ReadFile(filepath.Join(testCase.destination, "Readme.md")); err != nil || string(contents) != "readme" {
+					t.Fatalf("expected the file to be copied, got the contents %q . Error: %v", string(contents), err)
+				}
+			}
+			markers, _ := filepath.Glob(filepath.Join(root, "output", "*", copyMarkerPattern))
+			if rootMarkers, _ := filepath.Glob(filepath.Join(root, "output", copyMarkerPattern)); len(markers)+len(rootMarkers) != 0 {
+				t.Fatalf("expected the marker files to be removed, found %+v %+v", markers, rootMarkers)
+			}
+		})
+	}
+}
@@ -20,12 +20,34 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync"
 
+	"github.com/konveyor/move2kube/common"
 	"github.com/sirupsen/logrus"
 )
 
+// SymlinkPolicy is how the symbolic links are copied
+type SymlinkPolicy string
+
+const (
+	// PreserveSymlinks copies the symbolic links which point inside the copied directory as links,
+	// and the files the other ones point to, since they would be broken in the copy
+	PreserveSymlinks SymlinkPolicy = "preserve"
+	// FollowSymlinks copies the files the symbolic links point to
+	FollowSymlinks SymlinkPolicy = "follow"
+	// SkipSymlinks leaves out the symbolic links
+	SkipSymlinks SymlinkPolicy = "skip"
+)
+
 type processor struct {
 	options options
+	pool    *common.WorkerPool
+	// root is the directory being processed, which the preserved symbolic links have to point inside of
+	root string
+	// errs stores the errors of the paths which could not be processed, the other paths are still processed
+	errs      []error
+	errsMutex sync.Mutex
 }
 
 type options struct {
@@ -34,6 +56,12 @@ type options struct {
 	deletionCallBack    func(sourcePath, destinationPath string, config interface{}) (err error)
 	mismatchCallBack    func(sourcePath, destinationPath string, config interface{}) (err error)
 	config              interface{}
+	// skip returns true for the source paths which are not processed, along with their contents
+	skip func(sourcePath string, isDir bool) bool
+	// symlinks is how the symbolic links are copied. The links are preserved by default.
+	symlinks SymlinkPolicy
+	// parallel processes the files of the directories using a bounded worker pool. The callbacks have to be safe for concurrent use.
+	parallel bool
 }
 
 func newProcessor(options options) *processor {
@@ -42,8 +70,50 @@ func newProcessor(options options) *processor {
 	}
 }
 
+// run processes the source into the destination and waits for the files being processed in parallel.
+// The errors of the paths in the directories which could not be processed are returned along with the error of the source path.
+func (p *processor) run(source, destination string) error {
+	p.root = source
+	p.errs = nil
+	if p.options.parallel {
+		p.pool = common.NewWorkerPool(common.FileWriteWorkers)
+	}
+	if err := p.process(source, destination); err != nil {
+		p.addError(err)
+	}
+	if p.pool != nil {
+		p.pool.Wait()
+		p.pool = nil
+	}
+	return p.getError()
+}
+
+// addError records the error of a path which could not be processed
+func (p *processor) addError(err error) {
+	p.errsMutex.Lock()
+	defer p.errsMutex.Unlock()
+	p.errs = append(p.errs, err)
+}
+
+// getError returns the first error that was recorded, wrapped along with the messages of the other errors
+func (p *processor) getError() error {
+	p.errsMutex.Lock()
+	defer p.errsMutex.Unlock()
+	if len(p.errs) == 0 {
+		return nil
+	}
+	if len(p.errs) == 1 {
+		return p.errs[0]
+	}
+	others := []string{}
+	for _, err := range p.errs[1:] {
+		others = append(others, err.Error())
+	}
+	return fmt.Errorf("%w\nfailed to process %d other paths. Errors:\n%s", p.errs[0], len(others), strings.Join(others, "\n"))
+}
+
 func (p *processor) process(source, destination string) error {
-	si, err := os.Stat(source)
+	si, err := os.Lstat(source)
 	if err != nil {
 		return fmt.Errorf("failed to stat the source path '%s' . Error: %w", source, err)
 	}
@@ -90,14 +160,23 @@ func (p *processor) processDirectory(source, destination string) error {
 		return err
 	}
 
-	di, err := os.Stat(destination)
+	di, err := os.Lstat(destination)
+	if err == nil && di.Mode()&os.ModeSymlink != 0 {
+		// the directory was a symbolic link in the previous copy, the link is replaced so that the files are not written where it points to
+		if err := os.Remove(destination); err != nil {
+			return fmt.Errorf("failed to remove the symbolic link %s . Error: %w", destination, err)
+		}
+		di, err = os.Lstat(destination)
+	}
 	if err != nil {
 		if err := p.options.deletionCallBack(source, destination, p.options.config); err != nil {
 			logrus.Errorf("Error during deletion callback for %s, %s", source, destination)
+			p.addError(fmt.Errorf("failed to create the destination directory %s for the source directory %s . Error: %w", destination, source, err))
 		}
 	} else if !di.IsDir() {
 		if err := p.options.mismatchCallBack(source, destination, p.options.config); err != nil {
 			logrus.Errorf("Error during mismatch callback for %s, %s", source, destination)
+			p.addError(fmt.Errorf("failed to replace the destination path %s with the source directory %s . Error: %w", destination, source, err))
 		}
 	} else {
 		destEntries, err := os.ReadDir(destination)
@@ -113,9 +192,24 @@ func (p *processor) processDirectory(source, destination string) error {
 		eN := entry.Name()
 		sourcePath := filepath.Join(source, eN)
 		destPath := filepath.Join(destination, eN)
+		// the excluded paths are left in the destination entries, so that they are removed when replicating
+		if p.options.skip != nil && p.options.skip(sourcePath, entry.IsDir()) {
+			logrus.Debugf("Skipping the excluded path %s", sourcePath)
+			continue
+		}
 		delete(destEntryNames, eN)
+		if p.pool != nil && entry.Type().IsRegular() {
+			p.pool.Submit(func() {
+				if err := p.processFile(sourcePath, destPath); err != nil {
+					logrus.Errorf("Error during processing : %s", err)
+					p.addError(err)
+				}
+			})
+			continue
+		}
 		if err := p.process(sourcePath, destPath); err != nil {
 			logrus.Errorf("Error during processing : %s", err)
+			p.addError(err)
 		}
 	}
 	for deN := range destEntryNames {
@@ -123,6 +217,7 @@ func (p *processor) processDirectory(source, destination string) error {
 			err := p.options.additionCallBack(filepath.Join(source, deN), filepath.Join(destination, deN), p.options.config)
 			if err != nil {
 				logrus.Errorf("Error during addition callback for %s", destination)
+				p.addError(fmt.Errorf("failed to process the destination path %s which is not in the source directory %s . Error: %w", filepath.Join(destination, deN), source, err))
 			}
 		}
 	}
@@ -134,5 +229,62 @@ func (p *processor) processSymLink(source, destination string) error {
 	if err != nil {
 		return err
 	}
+	switch p.options.symlinks {
+	case SkipSymlinks:
+		logrus.Debugf("Skipping the symbolic link %s", source)
+		return nil
+	case FollowSymlinks:
+		return p.followSymLink(source, destination)
+	}
+	if !p.isInsideRoot(source, link) {
+		logrus.Warnf("The symbolic link %s points to %s outside of the directory %s . Copying the path it points to instead.", source, link, p.root)
+		return p.followSymLink(source, destination)
+	}
+	if di, err := os.Lstat(destination); err == nil {
+		if di.Mode()&os.ModeSymlink != 0 {
+			if existingLink, err := os.Readlink(destination); err == nil && existingLink == link {
+				return nil
+			}
+		}
+		if err := os.RemoveAll(destination); err != nil {
+			return fmt.Errorf("failed to remove the path %s to replace it with a symbolic link. Error: %w", destination, err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(destination), common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the directory %s . Error: %w", filepath.Dir(destination), err)
+	}
 	return os.Symlink(link, destination)
 }
+
+// followSymLink processes the path the symbolic link points to, as if it was at the path of the link
+func (p *processor) followSymLink(source, destination string) error {
+	si, err := os.Stat(source)
+	if err != nil {
+		return fmt.Errorf("failed to stat the path the symbolic link %s points to. Error: %w", source, err)
+	}
+	if !si.IsDir() {
+		return p.processFile(source, destination)
+	}
+	realPath, err := filepath.EvalSymlinks(source)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the symbolic link %s . Error: %w", source, err)
+	}
+	realParentPath, err := filepath.EvalSymlinks(filepath.Dir(source))
+	if err != nil {
+		return fmt.Errorf("failed to resolve the directory %s . Error: %w", filepath.Dir(source), err)
+	}
+	if realParentPath == realPath || strings.HasPrefix(realParentPath, realPath+string(os.PathSeparator)) {
+		logrus.Warnf("Skipping the symbolic link %s since it points to the directory %s which contains it", source, realPath)
+		return nil
+	}
+	return p.processDirectory(source, destination)
+}
+
+// isInsideRoot returns true if the symbolic link points inside the directory being processed, so that it is not broken in the copy
+func (p *processor) isInsideRoot(source, link string) bool {
+	if filepath.IsAbs(link) {
+		return false
+	}
+	relPath, err := filepath.Rel(p.root, filepath.Join(filepath.Dir(source), link))
+	return err == nil && relPath != ".." && !strings.HasPrefix(relPath, ".."+string(os.PathSeparator))
+}
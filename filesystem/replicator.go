@@ -29,8 +29,9 @@ func Replicate(source, destination string) error {
 		additionCallBack:    replicateAdditionCallBack,
 		deletionCallBack:    replicateDeletionCallBack,
 		mismatchCallBack:    replicateDeletionCallBack,
+		parallel:            true,
 	}
-	return newProcessor(options).process(source, destination)
+	return newProcessor(options).run(source, destination)
 }
 
 func replicateProcessFileCallBack(sourceFilePath, destinationFilePath string, config interface{}) error {
@@ -41,7 +42,7 @@ func replicateProcessFileCallBack(sourceFilePath, destinationFilePath string, co
 	}
 	di, err := os.Stat(destinationFilePath)
 	if err == nil {
-		if !(si.Mode().IsRegular() != di.Mode().IsRegular() || si.Size() != di.Size() || si.ModTime() != di.ModTime()) {
+		if !(si.Mode() != di.Mode() || si.Size() != di.Size() || si.ModTime() != di.ModTime()) {
 			return nil
 		}
 	}
@@ -0,0 +1,50 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package filesystem
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+)
+
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// normalizeScript sets the line endings of generated scripts as per common.ScriptLineEndings.
+// For shell scripts, the byte order mark and the blank lines before the shebang are removed, so that the shebang is the first line.
+// Other files are returned unchanged.
+func normalizeScript(path string, data []byte) []byte {
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext != common.ShExt && ext != common.BatExt {
+		return data
+	}
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if ext == common.ShExt {
+		trimmed := bytes.TrimLeft(bytes.TrimPrefix(data, utf8BOM), "\n")
+		if bytes.HasPrefix(trimmed, []byte("#!")) {
+			data = trimmed
+		}
+	}
+	useCRLF := common.ScriptLineEndings == common.CRLFScriptLineEndings ||
+		(common.ScriptLineEndings != common.LFScriptLineEndings && ext == common.BatExt)
+	if useCRLF {
+		data = bytes.ReplaceAll(data, []byte("\n"), []byte("\r\n"))
+	}
+	return data
+}
@@ -0,0 +1,65 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package filesystem
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+)
+
+func TestNormalizeScript(t *testing.T) {
+	script := "\xEF\xBB\xBF\n\r\n#!/usr/bin/env bash\r\necho hello\n"
+	testCases := []struct {
+		lineEndings string
+		path        string
+		want        string
+	}{
+		{lineEndings: common.NativeScriptLineEndings, path: "build.sh", want: "#!/usr/bin/env bash\necho hello\n"},
+		{lineEndings: common.NativeScriptLineEndings, path: "build.BAT", want: "\xEF\xBB\xBF\r\n\r\n#!/usr/bin/env bash\r\necho hello\r\n"},
+		{lineEndings: common.LFScriptLineEndings, path: "build.bat", want: "\xEF\xBB\xBF\n\n#!/usr/bin/env bash\necho hello\n"},
+		{lineEndings: common.CRLFScriptLineEndings, path: "build.sh", want: "#!/usr/bin/env bash\r\necho hello\r\n"},
+		{lineEndings: common.CRLFScriptLineEndings, path: "Readme.md", want: script},
+	}
+	defer func() { common.ScriptLineEndings = common.NativeScriptLineEndings }()
+	for _, testCase := range testCases {
+		common.ScriptLineEndings = testCase.lineEndings
+		if got := string(normalizeScript(testCase.path, []byte(script))); got != testCase.want {
+			t.Errorf("normalizeScript(%q) with the %s line endings = %q, want %q", testCase.path, testCase.lineEndings, got, testCase.want)
+		}
+	}
+	common.ScriptLineEndings = common.NativeScriptLineEndings
+	if got := string(normalizeScript("notes.sh", []byte("\n\necho no shebang\n"))); got != "\n\necho no shebang\n" {
+		t.Errorf("expected the blank lines to be kept in a shell script without a shebang, got %q", got)
+	}
+}
+
+func TestWriteTemplateToFileNormalizesTheScripts(t *testing.T) {
+	writePath := filepath.Join(t.TempDir(), "deploy.bat")
+	if err := writeTemplateToFile("echo {{ .Name }}\n", map[string]string{"Name": "api"}, writePath, common.DefaultFilePermission, "", ""); err != nil {
+		t.Fatalf("failed to write the template. Error: %q", err)
+	}
+	data, err := os.ReadFile(writePath)
+	if err != nil {
+		t.Fatalf("failed to read the file %s . Error: %q", writePath, err)
+	}
+	if string(data) != "echo api\r\n" {
+		t.Fatalf("expected the batch file to have CRLF line endings, got %q", string(data))
+	}
+}
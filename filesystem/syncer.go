@@ -0,0 +1,89 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package filesystem
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Sync replicates the source directory into destination, leaving out the paths for which excluded returns true.
+// The files whose contents have not changed are not copied again and the paths which are not in the source are removed.
+func Sync(source, destination string, excluded func(sourcePath string, isDir bool) bool, symlinks SymlinkPolicy) error {
+	options := options{
+		processFileCallBack: syncProcessFileCallBack,
+		additionCallBack:    replicateAdditionCallBack,
+		deletionCallBack:    replicateDeletionCallBack,
+		mismatchCallBack:    replicateDeletionCallBack,
+		skip:                excluded,
+		symlinks:            symlinks,
+		parallel:            true,
+	}
+	return newProcessor(options).run(source, destination)
+}
+
+func syncProcessFileCallBack(sourceFilePath, destinationFilePath string, config interface{}) error {
+	si, err := os.Stat(sourceFilePath)
+	if err != nil {
+		logrus.Errorf("Unable to stat file %s : %s", sourceFilePath, err)
+		return err
+	}
+	di, err := os.Lstat(destinationFilePath)
+	if err == nil && di.Mode().IsRegular() && si.Size() == di.Size() && haveSameChecksum(sourceFilePath, destinationFilePath) {
+		if si.Mode().Perm() == di.Mode().Perm() {
+			return nil
+		}
+		if err := os.Chmod(destinationFilePath, si.Mode().Perm()); err != nil {
+			logrus.Errorf("Unable to copy permissions in file %s : %s", destinationFilePath, err)
+			return err
+		}
+		return nil
+	}
+	return copyFile(destinationFilePath, sourceFilePath, si.ModTime())
+}
+
+// haveSameChecksum returns true if both the files could be read and have the same contents
+func haveSameChecksum(filePath1, filePath2 string) bool {
+	checksum1, err := getFileChecksum(filePath1)
+	if err != nil {
+		logrus.Debugf("failed to get the checksum of the file %s . Error: %q", filePath1, err)
+		return false
+	}
+	checksum2, err := getFileChecksum(filePath2)
+	if err != nil {
+		logrus.Debugf("failed to get the checksum of the file %s . Error: %q", filePath2, err)
+		return false
+	}
+	return checksum1 == checksum2
+}
+
+func getFileChecksum(filePath string) (string, error) {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open the file at path %s . Error: %w", filePath, err)
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", fmt.Errorf("failed to read the file at path %s . Error: %w", filePath, err)
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
@@ -166,8 +166,7 @@ func writeTemplateToFile(tpl string, config interface{}, writepath string,
 		"archTarGZipStr": common.CreateTarArchiveGZipStringWrapper,
 		"archTarStr":     common.CreateTarArchiveNoCompressionStringWrapper,
 	}
-	template.Must(packageTemplate.Delims(openingDelimiter, closingDelimiter).Funcs(sprig.TxtFuncMap()).Funcs(methodMap).Parse(tpl))
-	if err != nil {
+	if _, err = packageTemplate.Delims(openingDelimiter, closingDelimiter).Funcs(sprig.TxtFuncMap()).Funcs(getTemplateValueFuncs()).Funcs(methodMap).Parse(tpl); err != nil {
 		logrus.Errorf("Unable to parse the template : %s", err)
 		return err
 	}
@@ -175,7 +174,7 @@ func writeTemplateToFile(tpl string, config interface{}, writepath string,
 	if err != nil {
 		return fmt.Errorf("unable to transform template to string using the data. Error: %q . Data: %+v Template: %q", err, config, tpl)
 	}
-	err = os.WriteFile(writepath, tplbuffer.Bytes(), filemode)
+	err = os.WriteFile(writepath, normalizeScript(writepath, tplbuffer.Bytes()), filemode)
 	if err != nil {
 		logrus.Warnf("Error writing file at %s : %s", writepath, err)
 		return err
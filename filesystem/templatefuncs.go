@@ -0,0 +1,102 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package filesystem
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// templateValues are the values read from the value files given by the user, which all the templates can use
+var templateValues = map[string]interface{}{}
+
+// LoadTemplateValues reads the value files, the values in the later files override the ones in the earlier files
+func LoadTemplateValues(paths []string) error {
+	values := map[string]interface{}{}
+	for _, path := range paths {
+		fileValues := map[string]interface{}{}
+		if err := common.ReadYaml(path, &fileValues); err != nil {
+			return fmt.Errorf("failed to read the template values file at path %s . Error: %w", path, err)
+		}
+		values = mergeTemplateValues(values, fileValues)
+		logrus.Debugf("Loaded the template values from the file %s", path)
+	}
+	templateValues = values
+	return nil
+}
+
+// mergeTemplateValues merges the nested maps of the values, the other values win on conflicts
+func mergeTemplateValues(values, otherValues map[string]interface{}) map[string]interface{} {
+	for key, otherValue := range otherValues {
+		if otherMap, ok := otherValue.(map[string]interface{}); ok {
+			if valueMap, ok := values[key].(map[string]interface{}); ok {
+				values[key] = mergeTemplateValues(valueMap, otherMap)
+				continue
+			}
+		}
+		values[key] = otherValue
+	}
+	return values
+}
+
+// getTemplateValue returns the value at the path of dot separated keys, nil if it is not set
+func getTemplateValue(path string) interface{} {
+	var value interface{} = templateValues
+	for _, key := range strings.Split(path, ".") {
+		valueMap, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if value, ok = valueMap[key]; !ok {
+			return nil
+		}
+	}
+	return value
+}
+
+// requiredTemplateValue fails the rendering of the template with the message if the value is empty
+func requiredTemplateValue(message string, value interface{}) (interface{}, error) {
+	if value == nil || value == "" {
+		return nil, errors.New(message)
+	}
+	return value, nil
+}
+
+// toYamlTemplateValue returns the value marshalled to yaml, without the trailing new line so that it can be indented
+func toYamlTemplateValue(value interface{}) (string, error) {
+	yamlBytes, err := yaml.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the value to yaml. Error: %w", err)
+	}
+	return strings.TrimSuffix(string(yamlBytes), "\n"), nil
+}
+
+// getTemplateValueFuncs returns the functions which give the templates access to the values files, in the style of helm
+func getTemplateValueFuncs() template.FuncMap {
+	return template.FuncMap{
+		"values":   func() map[string]interface{} { return templateValues },
+		"value":    getTemplateValue,
+		"required": requiredTemplateValue,
+		"toYaml":   toYamlTemplateValue,
+	}
+}
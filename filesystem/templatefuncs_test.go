@@ -0,0 +1,109 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package filesystem
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func loadTemplateValuesForTest(t *testing.T, contents ...string) {
+	t.Helper()
+	dir := t.TempDir()
+	paths := []string{}
+	for i, content := range contents {
+		path := filepath.Join(dir, fmt.Sprintf("values%d.yaml", i))
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write the values file at path %s . Error: %q", path, err)
+		}
+		paths = append(paths, path)
+	}
+	t.Cleanup(func() { templateValues = map[string]interface{}{} })
+	if err := LoadTemplateValues(paths); err != nil {
+		t.Fatalf("failed to load the template values. Error: %q", err)
+	}
+}
+
+func TestLoadTemplateValues(t *testing.T) {
+	loadTemplateValuesForTest(t,
+		"registry:\n  url: quay.io\n  namespace: first\nreplicas: 1\n",
+		"registry:\n  namespace: second\nreplicas: 3\nlabels: [a, b]\n",
+	)
+	want := map[string]interface{}{
+		"registry": map[string]interface{}{"url": "quay.io", "namespace": "second"},
+		"replicas": 3,
+		"labels":   []interface{}{"a", "b"},
+	}
+	if diff := cmp.Diff(want, templateValues); diff != "" {
+		t.Fatalf("the later values files were not merged correctly. Diff (-want +got):\n%s", diff)
+	}
+	if err := LoadTemplateValues([]string{filepath.Join(t.TempDir(), "missing.yaml")}); err == nil {
+		t.Fatalf("expected an error for a missing values file")
+	}
+}
+
+func TestWriteTemplateToFileWithValues(t *testing.T) {
+	loadTemplateValuesForTest(t, "registry:\n  url: us.icr.io\nlabels:\n  app: web\n  tier: front\n")
+	testCases := []struct {
+		tpl  string
+		want string
+	}{
+		{tpl: `{{ value "registry.url" | quote }}`, want: `"us.icr.io"`},
+		{tpl: `{{ value "registry.namespace" | default "myns" }}`, want: `myns`},
+		{tpl: `{{ value "registry.url.host" | default "none" }}`, want: `none`},
+		{tpl: `{{ (values).registry.url }}`, want: `us.icr.io`},
+		{tpl: `{{ required "the url is required" (value "registry.url") }}`, want: `us.icr.io`},
+		{tpl: "labels:\n{{ value \"labels\" | toYaml | indent 2 }}", want: "labels:\n  app: web\n  tier: front"},
+		{tpl: `{{ .Name | upper }}`, want: `SVC1`},
+	}
+	dir := t.TempDir()
+	for _, tc := range testCases {
+		path := filepath.Join(dir, "out.txt")
+		if err := writeTemplateToFile(tc.tpl, map[string]string{"Name": "svc1"}, path, 0644, "", ""); err != nil {
+			t.Errorf("writeTemplateToFile(%q) failed. Error: %q", tc.tpl, err)
+			continue
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read the file at path %s . Error: %q", path, err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("writeTemplateToFile(%q) = %q, want %q", tc.tpl, got, tc.want)
+		}
+	}
+}
+
+func TestWriteTemplateToFileErrors(t *testing.T) {
+	loadTemplateValuesForTest(t, "registry:\n  url: \"\"\n")
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+	err := writeTemplateToFile(`{{ required "the registry url is required" (value "registry.url") }}`, nil, path, 0644, "", "")
+	if err == nil || !strings.Contains(err.Error(), "the registry url is required") {
+		t.Errorf("expected the rendering to fail with the message of required, got %v", err)
+	}
+	if err := writeTemplateToFile(`{{ value "registry.url" `, nil, path, 0644, "", ""); err == nil {
+		t.Errorf("expected an error for a template which does not parse")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected no file to be written for the failed templates")
+	}
+}
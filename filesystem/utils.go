@@ -25,8 +25,15 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// Copies file and sets mod time
+// Copies file and sets mod time and permissions
 func copyFile(df, sf string, modTime time.Time) error {
+	if di, err := os.Lstat(df); err == nil && di.Mode()&os.ModeSymlink != 0 {
+		// the file was a symbolic link in the previous copy, the link is replaced so that the file it points to is not overwritten
+		if err := os.Remove(df); err != nil {
+			logrus.Errorf("Unable to remove the symbolic link %s : %s", df, err)
+			return err
+		}
+	}
 	err := os.MkdirAll(filepath.Dir(df), common.DefaultDirectoryPermission)
 	if err != nil {
 		logrus.Errorf("Unable to make dir for %s : %s", filepath.Dir(df), err)
@@ -37,6 +44,17 @@ func copyFile(df, sf string, modTime time.Time) error {
 		logrus.Errorf("Unable to copy file %s to %s : %s", sf, df, err)
 		return err
 	}
+	// the permissions of an existing file are not changed when it is overwritten and the new ones are masked by the umask
+	si, err := os.Stat(sf)
+	if err != nil {
+		logrus.Errorf("Unable to stat file %s : %s", sf, err)
+		return err
+	}
+	err = os.Chmod(df, si.Mode().Perm())
+	if err != nil {
+		logrus.Errorf("Unable to copy permissions in file %s : %s", df, err)
+		return err
+	}
 	err = os.Chtimes(df, modTime, modTime)
 	if err != nil {
 		logrus.Errorf("Unable to change timestamp for file %s : %s", df, err)
@@ -0,0 +1,199 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// TarGzArchiveFormat packages the output as a gzip compressed tar archive
+	TarGzArchiveFormat = "tar.gz"
+	// ZipArchiveFormat packages the output as a zip archive
+	ZipArchiveFormat = "zip"
+)
+
+// ArchiveFormats are the supported formats of the packaged output
+var ArchiveFormats = []string{TarGzArchiveFormat, ZipArchiveFormat}
+
+// ArchiveOutput packages the output directory into a single archive next to it and removes the directory.
+// The files are stored under the name of the output directory, along with the output manifest listing their checksums.
+func ArchiveOutput(outputPath, format string) (string, error) {
+	if !common.IsPresent(ArchiveFormats, format) {
+		return "", fmt.Errorf("unsupported archive format '%s' . Supported formats are %+v", format, ArchiveFormats)
+	}
+	archivePath := outputPath + "." + format
+	archiveFile, err := os.OpenFile(archivePath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, common.DefaultFilePermission)
+	if err != nil {
+		return "", fmt.Errorf("failed to create the archive at path '%s' . Error: %w", archivePath, err)
+	}
+	defer archiveFile.Close()
+	switch format {
+	case TarGzArchiveFormat:
+		reader := common.ReadFilesAsTar(outputPath, filepath.Base(outputPath), common.GZipCompression)
+		if _, err := io.Copy(archiveFile, reader); err != nil {
+			reader.Close()
+			return "", fmt.Errorf("failed to write the archive at path '%s' . Error: %w", archivePath, err)
+		}
+		if err := reader.Close(); err != nil {
+			return "", fmt.Errorf("failed to archive the output directory '%s' . Error: %w", outputPath, err)
+		}
+	case ZipArchiveFormat:
+		if err := writeZipArchive(archiveFile, outputPath); err != nil {
+			return "", fmt.Errorf("failed to archive the output directory '%s' . Error: %w", outputPath, err)
+		}
+	}
+	if err := archiveFile.Close(); err != nil {
+		return "", fmt.Errorf("failed to close the archive at path '%s' . Error: %w", archivePath, err)
+	}
+	if err := os.RemoveAll(outputPath); err != nil {
+		logrus.Warnf("Failed to remove the output directory %s after archiving it. Error: %q", outputPath, err)
+	}
+	return archivePath, nil
+}
+
+// writeZipArchive writes the files in the directory to the zip archive, under the name of the directory
+func writeZipArchive(w io.Writer, dir string) error {
+	zw := zip.NewWriter(w)
+	baseDir := filepath.Dir(dir)
+	err := filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !fi.IsDir() && !fi.Mode().IsRegular() {
+			logrus.Debugf("Skipping the file %s which is not a regular file", path)
+			return nil
+		}
+		relPath, err := filepath.Rel(baseDir, path)
+		if err != nil {
+			return err
+		}
+		header, err := zip.FileInfoHeader(fi)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(relPath)
+		if fi.IsDir() {
+			header.Name += "/"
+			_, err := zw.CreateHeader(header)
+			return err
+		}
+		header.Method = zip.Deflate
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(fw, f)
+		return err
+	})
+	if err != nil {
+		zw.Close()
+		return err
+	}
+	return zw.Close()
+}
+
+// ExtractArchive extracts the tar.gz or zip archive into the directory.
+// It is the reverse of ArchiveOutput and is used to receive the sources uploaded as a single archive.
+func ExtractArchive(archivePath, format, dir string) error {
+	switch format {
+	case TarGzArchiveFormat:
+		archiveFile, err := os.Open(archivePath)
+		if err != nil {
+			return fmt.Errorf("failed to open the archive at path '%s' . Error: %w", archivePath, err)
+		}
+		defer archiveFile.Close()
+		gzipReader, err := gzip.NewReader(archiveFile)
+		if err != nil {
+			return fmt.Errorf("failed to read the gzip archive at path '%s' . Error: %w", archivePath, err)
+		}
+		defer gzipReader.Close()
+		if err := extractTar(gzipReader, dir); err != nil {
+			return fmt.Errorf("failed to extract the archive at path '%s' . Error: %w", archivePath, err)
+		}
+	case ZipArchiveFormat:
+		if err := extractZip(archivePath, dir); err != nil {
+			return fmt.Errorf("failed to extract the archive at path '%s' . Error: %w", archivePath, err)
+		}
+	default:
+		return fmt.Errorf("unsupported archive format '%s' . Supported formats are %+v", format, ArchiveFormats)
+	}
+	return nil
+}
+
+// extractZip extracts the regular files and the directories of the zip archive into the directory
+func extractZip(archivePath, dir string) error {
+	zipReader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer zipReader.Close()
+	for _, zipFile := range zipReader.File {
+		path := filepath.Join(dir, filepath.FromSlash(zipFile.Name))
+		if !common.IsParent(path, dir) {
+			logrus.Warnf("Skipping the file %s outside the archive", zipFile.Name)
+			continue
+		}
+		if zipFile.FileInfo().IsDir() {
+			if err := os.MkdirAll(path, common.DefaultDirectoryPermission); err != nil {
+				return err
+			}
+			continue
+		}
+		if !zipFile.Mode().IsRegular() {
+			logrus.Debugf("Skipping the file %s which is not a regular file", zipFile.Name)
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			return err
+		}
+		if err := extractZipFile(zipFile, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func extractZipFile(zipFile *zip.File, path string) error {
+	src, err := zipFile.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dest, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, zipFile.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dest, src); err != nil {
+		dest.Close()
+		return err
+	}
+	return dest.Close()
+}
@@ -0,0 +1,116 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib_test
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/konveyor/move2kube/lib"
+)
+
+func createOutputDir(t *testing.T) string {
+	t.Helper()
+	outputPath := filepath.Join(t.TempDir(), "myproject")
+	for path, contents := range map[string]string{
+		"deploy/yamls/web-deployment.yaml": "kind: Deployment",
+		"m2k-manifest.json":                "{}",
+	} {
+		path = filepath.Join(outputPath, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create the directory. Error: %q", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write the file. Error: %q", err)
+		}
+	}
+	return outputPath
+}
+
+func TestArchiveOutput(t *testing.T) {
+	want := []string{"myproject/deploy/yamls/web-deployment.yaml", "myproject/m2k-manifest.json"}
+	t.Run("tar.gz archive", func(t *testing.T) {
+		outputPath := createOutputDir(t)
+		archivePath, err := lib.ArchiveOutput(outputPath, lib.TarGzArchiveFormat)
+		if err != nil {
+			t.Fatalf("failed to archive the output. Error: %q", err)
+		}
+		f, err := os.Open(archivePath)
+		if err != nil {
+			t.Fatalf("failed to open the archive. Error: %q", err)
+		}
+		defer f.Close()
+		gr, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("failed to read the gzip archive. Error: %q", err)
+		}
+		files := []string{}
+		tr := tar.NewReader(gr)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatalf("failed to read the tar archive. Error: %q", err)
+			}
+			if header.Typeflag == tar.TypeReg {
+				files = append(files, header.Name)
+			}
+		}
+		sort.Strings(files)
+		if len(files) != len(want) || files[0] != want[0] || files[1] != want[1] {
+			t.Fatalf("the archive has the wrong files. Expected: %+v Actual: %+v", want, files)
+		}
+		if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+			t.Fatalf("the output directory %s should be removed after archiving", outputPath)
+		}
+	})
+	t.Run("zip archive", func(t *testing.T) {
+		outputPath := createOutputDir(t)
+		archivePath, err := lib.ArchiveOutput(outputPath, lib.ZipArchiveFormat)
+		if err != nil {
+			t.Fatalf("failed to archive the output. Error: %q", err)
+		}
+		zr, err := zip.OpenReader(archivePath)
+		if err != nil {
+			t.Fatalf("failed to read the zip archive. Error: %q", err)
+		}
+		defer zr.Close()
+		files := []string{}
+		for _, f := range zr.File {
+			if !f.FileInfo().IsDir() {
+				files = append(files, f.Name)
+			}
+		}
+		sort.Strings(files)
+		if len(files) != len(want) || files[0] != want[0] || files[1] != want[1] {
+			t.Fatalf("the archive has the wrong files. Expected: %+v Actual: %+v", want, files)
+		}
+	})
+	t.Run("unsupported format", func(t *testing.T) {
+		if _, err := lib.ArchiveOutput(createOutputDir(t), "rar"); err == nil {
+			t.Fatalf("expected an error for the unsupported archive format")
+		}
+	})
+}
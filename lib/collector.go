@@ -17,6 +17,7 @@
 package lib
 
 import (
+	"context"
 	"os"
 	"strings"
 
@@ -26,7 +27,7 @@ import (
 )
 
 //Collect gets the metadata from multiple sources, filters it and dumps it into files within source directory
-func Collect(inputPath string, outputPath string, annotations []string) {
+func Collect(ctx context.Context, inputPath string, outputPath string, annotations []string) {
 	collectors, err := collector.GetCollectors()
 	if err != nil {
 		logrus.Fatalf("Failed to get the collectors. Error: %q", err)
@@ -42,7 +43,7 @@ func Collect(inputPath string, outputPath string, annotations []string) {
 			}
 		}
 		logrus.Infof("[%T] Begin collection", collector)
-		if err = collector.Collect(inputPath, outputPath); err != nil {
+		if err = collector.Collect(ctx, inputPath, outputPath); err != nil {
 			logrus.Warnf("[%T] failed. Error: %q", collector, err)
 			continue
 		}
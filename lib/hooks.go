@@ -0,0 +1,31 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
+)
+
+// RegisterPreTransformHook registers a callback that can mutate the IR before it is converted to kubernetes objects
+func RegisterPreTransformHook(name string, hook apiresource.PreTransformHook) {
+	apiresource.RegisterPreTransformHook(name, hook)
+}
+
+// RegisterPostTransformHook registers a callback that can inspect and modify the kubernetes objects created from the IR
+func RegisterPostTransformHook(name string, hook apiresource.PostTransformHook) {
+	apiresource.RegisterPostTransformHook(name, hook)
+}
@@ -0,0 +1,53 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/konveyor/move2kube/filesystem"
+	"github.com/spf13/afero"
+)
+
+// outputFs is the filesystem the output is written to. When nil, the output stays in the output directory on the local filesystem.
+var outputFs afero.Fs
+
+// SetOutputFilesystem sets the filesystem the finished output is copied to.
+// It is not a filesystem for the transformers: they still write to the output directory on the local disk, which needs space for the output.
+// After the transformation the output directory is copied to the same path in the given filesystem and then removed from the local disk.
+// This can be used to capture the output in memory using afero.NewMemMapFs() or to upload it to other storage.
+// The copy fails for the filesystems backed by the local disk in which the path overlaps the output directory, since it would remove the output.
+func SetOutputFilesystem(fs afero.Fs) {
+	outputFs = fs
+}
+
+func writeOutputToFilesystem(outputPath string) error {
+	if outputFs == nil {
+		return nil
+	}
+	if _, ok := outputFs.(*afero.OsFs); ok {
+		return nil
+	}
+	if err := filesystem.CopyToFs(outputPath, outputFs, outputPath); err != nil {
+		return fmt.Errorf("failed to copy the output to the filesystem %s . Error: %w", outputFs.Name(), err)
+	}
+	if err := os.RemoveAll(outputPath); err != nil {
+		return fmt.Errorf("failed to remove the working directory %s . Error: %w", outputPath, err)
+	}
+	return nil
+}
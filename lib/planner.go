@@ -69,12 +69,14 @@ func CreatePlan(ctx context.Context, inputPath, outputPath string, customization
 	logrus.Info("Configuration loading done")
 
 	logrus.Info("Start planning")
+	common.ReportProgress(common.ProgressEvent{Stage: common.PlanProgressStage, Type: common.StageStartedProgressEvent})
 	if inputPath != "" {
-		plan.Spec.Services, err = transformer.GetServices(plan.Name, inputPath)
+		plan.Spec.Services, err = transformer.GetServices(ctx, plan.Name, inputPath)
 		if err != nil {
 			return plan, fmt.Errorf("failed to get services from the input directory '%s' . Error: %w", inputPath, err)
 		}
 	}
 	logrus.Infof("Planning done. Number of services identified: %d", len(plan.Spec.Services))
+	common.ReportProgress(common.ProgressEvent{Stage: common.PlanProgressStage, Type: common.StageFinishedProgressEvent, Message: fmt.Sprintf("identified %d services", len(plan.Spec.Services))})
 	return plan, nil
 }
@@ -0,0 +1,74 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Regenerate runs only the transformer on the saved IR, rewriting its output in the output directory of a previous transformation.
+// When no IR files are given, the IR files exported into the output directory are used.
+func Regenerate(ctx context.Context, plan plantypes.Plan, outputPath string, transformerSelector string, transformerName string, irFilePaths []string) error {
+	logrus.Infof("Starting the regeneration of the output of the transformer %s", transformerName)
+	common.ProjectName = plan.Name
+	qaengine.ResetAnswers()
+	if len(irFilePaths) == 0 {
+		var err error
+		if irFilePaths, err = transformer.GetExportedIRFilePaths(outputPath); err != nil {
+			return err
+		}
+		if len(irFilePaths) == 0 {
+			return fmt.Errorf("no IR files were found in the output directory %s . Export the IR during the transformation or specify the IR files", outputPath)
+		}
+	}
+	ir := irtypes.NewIR()
+	ir.Name = plan.Name
+	for _, irFilePath := range irFilePaths {
+		irFile, err := irtypes.ReadIRFile(irFilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read the IR file at path %s . Error: %w", irFilePath, err)
+		}
+		ir.Merge(irFile.IR)
+	}
+	transformerSelectorObj, err := common.ConvertStringSelectorsToSelectors(transformerSelector)
+	if err != nil {
+		return fmt.Errorf("failed to parse the transformer selector string. Error: %w", err)
+	}
+	selectorsInPlan, err := metav1.LabelSelectorAsSelector(&plan.Spec.TransformerSelector)
+	if err != nil {
+		return fmt.Errorf("failed to convert label selector to selector. Error: %w", err)
+	}
+	requirements, _ := selectorsInPlan.Requirements()
+	transformerSelectorObj = transformerSelectorObj.Add(requirements...)
+	if _, err := transformer.InitTransformers(plan.Spec.Transformers, transformerSelectorObj, plan.Spec.SourceDir, outputPath, plan.Name, true, true); err != nil {
+		return fmt.Errorf("failed to initialize the transformers. Error: %w", err)
+	}
+	if err := transformer.Regenerate(ctx, transformerName, ir, plan.Spec.SourceDir, outputPath); err != nil {
+		return err
+	}
+	logrus.Infof("Regeneration done")
+	return nil
+}
@@ -0,0 +1,282 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"archive/tar"
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	gitfilesystem "github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ociCustomizationsPrefix is the prefix of the customizations stored as OCI artifacts. Example: oci://quay.io/myorg/customizations:v1
+	ociCustomizationsPrefix = "oci://"
+	// gitCustomizationsPrefix forces the url to be treated as a git repository. Example: git+https://example.com/myorg/customizations
+	gitCustomizationsPrefix = "git+"
+	// gitRefSeparator separates the branch, tag or commit from the git url. Example: https://github.com/myorg/customizations.git#v1.2.0
+	gitRefSeparator = "#"
+	// ociDigestFile stores the digest of the pulled artifact in its cache directory
+	ociDigestFile         = ".m2kdigest"
+	remoteFetchTimeout    = 5 * time.Minute
+	customizationsCacheID = "customizations"
+	// minTarFilePermission is added to the permissions of the extracted files, since the artifacts can be pushed without the read permissions
+	minTarFilePermission os.FileMode = 0644
+)
+
+// IsRemoteCustomizations returns true if the customizations path is a git url or an OCI artifact reference
+func IsRemoteCustomizations(customizationsPath string) bool {
+	return strings.HasPrefix(customizationsPath, ociCustomizationsPrefix) || isGitCustomizations(customizationsPath)
+}
+
+// FetchRemoteCustomizations fetches the customizations in a git repository or an OCI artifact into the cache and returns the cached directory.
+// The local paths are returned unchanged. When the remote is unreachable the previously cached copy is used.
+// The signatures are verified using cosign, when a key or a keyless identity is given, since the Starlark scripts run with the privileges of the user.
+func FetchRemoteCustomizations(customizationsPath string) (string, error) {
+	if !IsRemoteCustomizations(customizationsPath) {
+		return customizationsPath, nil
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		cacheDir = os.TempDir()
+	}
+	cacheDir = filepath.Join(cacheDir, types.AppName, customizationsCacheID)
+	if err := os.MkdirAll(cacheDir, common.DefaultDirectoryPermission); err != nil {
+		return "", fmt.Errorf("failed to create the customizations cache directory at path '%s' . Error: %w", cacheDir, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), remoteFetchTimeout)
+	defer cancel()
+	if strings.HasPrefix(customizationsPath, ociCustomizationsPrefix) {
+		return fetchOCICustomizations(ctx, strings.TrimPrefix(customizationsPath, ociCustomizationsPrefix), cacheDir)
+	}
+	return fetchGitCustomizations(ctx, customizationsPath, cacheDir)
+}
+
+// isGitCustomizations returns true if the path looks like the url of a git repository
+func isGitCustomizations(customizationsPath string) bool {
+	if strings.HasPrefix(customizationsPath, gitCustomizationsPrefix) {
+		return true
+	}
+	repoURL := strings.Split(customizationsPath, gitRefSeparator)[0]
+	if strings.HasPrefix(repoURL, "git@") || strings.HasPrefix(repoURL, "ssh://") || strings.HasPrefix(repoURL, "git://") {
+		return true
+	}
+	return (strings.HasPrefix(repoURL, "https://") || strings.HasPrefix(repoURL, "http://")) && (strings.HasSuffix(repoURL, ".git") || strings.Contains(customizationsPath, gitRefSeparator))
+}
+
+// fetchGitCustomizations clones the repository into the cache, or fetches the new commits into the cached clone, and checks out the ref
+func fetchGitCustomizations(ctx context.Context, customizationsPath, cacheDir string) (string, error) {
+	repoURL, ref := strings.TrimPrefix(customizationsPath, gitCustomizationsPrefix), ""
+	if i := strings.LastIndex(repoURL, gitRefSeparator); i != -1 {
+		repoURL, ref = repoURL[:i], repoURL[i+len(gitRefSeparator):]
+	}
+	// The git directory is kept outside the worktree, so that it is not copied along with the customizations
+	repoCacheDir := filepath.Join(cacheDir, "git", getCacheKey(repoURL))
+	gitDir, repoDir := filepath.Join(repoCacheDir, git.GitDirName), filepath.Join(repoCacheDir, "worktree")
+	storage := gitfilesystem.NewStorage(osfs.New(gitDir), cache.NewObjectLRUDefault())
+	repo, err := git.Open(storage, osfs.New(repoDir))
+	if err != nil {
+		logrus.Infof("Cloning the customizations from the git repository %s", repoURL)
+		if err := os.RemoveAll(repoCacheDir); err != nil {
+			return "", fmt.Errorf("failed to remove the stale cache directory '%s' . Error: %w", repoCacheDir, err)
+		}
+		storage = gitfilesystem.NewStorage(osfs.New(gitDir), cache.NewObjectLRUDefault())
+		if repo, err = git.CloneContext(ctx, storage, osfs.New(repoDir), &git.CloneOptions{URL: repoURL}); err != nil {
+			os.RemoveAll(repoCacheDir)
+			return "", fmt.Errorf("failed to clone the customizations from the git repository '%s' . Error: %w", repoURL, err)
+		}
+	} else if err := repo.FetchContext(ctx, &git.FetchOptions{Tags: git.AllTags, Force: true}); err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		logrus.Warnf("Failed to fetch the latest customizations from the git repository %s . Using the cached copy at %s . Error: %q", repoURL, repoDir, err)
+	}
+	hash, err := resolveGitRef(ctx, repo, ref)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve the ref '%s' of the git repository '%s' . Error: %w", ref, repoURL, err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the worktree of the cached git repository '%s' . Error: %w", repoDir, err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Hash: hash, Force: true}); err != nil {
+		return "", fmt.Errorf("failed to checkout the commit %s of the git repository '%s' . Error: %w", hash, repoURL, err)
+	}
+	// The .git file pointing to the git directory is not needed, since the storage is opened explicitly
+	if fi, err := os.Lstat(filepath.Join(repoDir, git.GitDirName)); err == nil && !fi.IsDir() {
+		os.Remove(filepath.Join(repoDir, git.GitDirName))
+	}
+	logrus.Debugf("Using the commit %s of the customizations from the git repository %s", hash, repoURL)
+	if err := verifyGitCustomizations(ctx, repoURL, repoDir); err != nil {
+		return "", err
+	}
+	return repoDir, nil
+}
+
+// resolveGitRef resolves the branch, tag or commit to a commit. The remote branches are preferred, since the cached local branches are not updated.
+// Without a ref, the default branch of the remote is used. It is recorded, so that the cached copy can be used when the remote is unreachable.
+func resolveGitRef(ctx context.Context, repo *git.Repository, ref string) (plumbing.Hash, error) {
+	if ref == "" {
+		remoteHEADName := plumbing.NewRemoteHEADReferenceName(git.DefaultRemoteName)
+		if branch, err := getRemoteDefaultBranch(ctx, repo); err != nil {
+			logrus.Debugf("failed to get the default branch of the remote. Using the default branch found earlier. Error: %q", err)
+		} else if err := repo.Storer.SetReference(plumbing.NewSymbolicReference(remoteHEADName, plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch.Short()))); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to record the default branch %s of the remote. Error: %w", branch.Short(), err)
+		}
+		head, err := repo.Reference(remoteHEADName, true)
+		if err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("failed to find the default branch of the remote. Error: %w", err)
+		}
+		return head.Hash(), nil
+	}
+	for _, revision := range []string{git.DefaultRemoteName + "/" + ref, "refs/tags/" + ref, ref} {
+		if hash, err := repo.ResolveRevision(plumbing.Revision(revision)); err == nil {
+			return *hash, nil
+		}
+	}
+	return plumbing.ZeroHash, fmt.Errorf("no branch, tag or commit named '%s' was found", ref)
+}
+
+// getRemoteDefaultBranch returns the branch the HEAD of the remote points to
+func getRemoteDefaultBranch(ctx context.Context, repo *git.Repository) (plumbing.ReferenceName, error) {
+	remote, err := repo.Remote(git.DefaultRemoteName)
+	if err != nil {
+		return "", err
+	}
+	refs, err := remote.ListContext(ctx, &git.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	for _, ref := range refs {
+		if ref.Name() == plumbing.HEAD && ref.Type() == plumbing.SymbolicReference && ref.Target().IsBranch() {
+			return ref.Target(), nil
+		}
+	}
+	return "", fmt.Errorf("the HEAD of the remote does not point to a branch")
+}
+
+// fetchOCICustomizations extracts the layers of the OCI artifact into the cache. The artifact is pulled again only when its digest changes.
+func fetchOCICustomizations(ctx context.Context, reference, cacheDir string) (string, error) {
+	ref, err := name.ParseReference(reference)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the OCI reference '%s' . Error: %w", reference, err)
+	}
+	artifactDir := filepath.Join(cacheDir, "oci", getCacheKey(ref.Name()))
+	cachedDigest, _ := os.ReadFile(filepath.Join(artifactDir, ociDigestFile))
+	options := []remote.Option{remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithContext(ctx)}
+	desc, err := remote.Head(ref, options...)
+	if err != nil {
+		if len(cachedDigest) != 0 {
+			if err := verifyCachedOCICustomizations(artifactDir); err != nil {
+				return "", fmt.Errorf("failed to get the digest of the OCI artifact '%s' to verify its signature. Error: %w", reference, err)
+			}
+			logrus.Warnf("Failed to check for the latest customizations in the OCI artifact %s . Using the cached copy at %s . Error: %q", reference, artifactDir, err)
+			return artifactDir, nil
+		}
+		return "", fmt.Errorf("failed to get the digest of the OCI artifact '%s' . Error: %w", reference, err)
+	}
+	if err := verifyOCICustomizations(ctx, ref.Context().Name(), desc.Digest.String()); err != nil {
+		return "", err
+	}
+	if string(cachedDigest) == desc.Digest.String() {
+		markOCICustomizationsVerified(artifactDir)
+		logrus.Debugf("Using the cached customizations of the OCI artifact %s with the digest %s", reference, desc.Digest)
+		return artifactDir, nil
+	}
+	logrus.Infof("Pulling the customizations from the OCI artifact %s", reference)
+	// The verified digest is pulled, so that the tag can not be moved to another artifact in between
+	img, err := remote.Image(ref.Context().Digest(desc.Digest.String()), options...)
+	if err != nil {
+		return "", fmt.Errorf("failed to pull the OCI artifact '%s' . Error: %w", reference, err)
+	}
+	if err := os.RemoveAll(artifactDir); err != nil {
+		return "", fmt.Errorf("failed to remove the stale cache directory '%s' . Error: %w", artifactDir, err)
+	}
+	if err := os.MkdirAll(artifactDir, common.DefaultDirectoryPermission); err != nil {
+		return "", fmt.Errorf("failed to create the cache directory '%s' . Error: %w", artifactDir, err)
+	}
+	reader := mutate.Extract(img)
+	defer reader.Close()
+	if err := extractTar(reader, artifactDir); err != nil {
+		os.RemoveAll(artifactDir)
+		return "", fmt.Errorf("failed to extract the OCI artifact '%s' . Error: %w", reference, err)
+	}
+	if err := os.WriteFile(filepath.Join(artifactDir, ociDigestFile), []byte(desc.Digest.String()), common.DefaultFilePermission); err != nil {
+		logrus.Warnf("Failed to cache the digest of the OCI artifact %s . Error: %q", reference, err)
+	}
+	markOCICustomizationsVerified(artifactDir)
+	return artifactDir, nil
+}
+
+// extractTar extracts the regular files and the directories of the tar stream into the directory
+func extractTar(reader io.Reader, dir string) error {
+	tarReader := tar.NewReader(reader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		path := filepath.Join(dir, filepath.FromSlash(header.Name))
+		if !common.IsParent(path, dir) {
+			logrus.Warnf("Skipping the file %s outside the artifact", header.Name)
+			continue
+		}
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(path, common.DefaultDirectoryPermission); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+				return err
+			}
+			file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, os.FileMode(header.Mode).Perm()|minTarFilePermission)
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(file, tarReader); err != nil {
+				file.Close()
+				return err
+			}
+			file.Close()
+		}
+	}
+}
+
+// getCacheKey returns the name of the cache directory of the remote customizations
+func getCacheKey(remote string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(remote)))[:16]
+}
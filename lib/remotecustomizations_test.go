@@ -0,0 +1,121 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestFetchGitCustomizationsFollowsTheDefaultBranch(t *testing.T) {
+	upstreamDir := t.TempDir()
+	upstream, err := git.PlainInit(upstreamDir, false)
+	if err != nil {
+		t.Fatalf("failed to create the upstream repository. Error: %q", err)
+	}
+	worktree, err := upstream.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get the worktree of the upstream repository. Error: %q", err)
+	}
+	commit := func(contents string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(upstreamDir, "transformer.yaml"), []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write the file. Error: %q", err)
+		}
+		if _, err := worktree.Add("transformer.yaml"); err != nil {
+			t.Fatalf("failed to add the file. Error: %q", err)
+		}
+		signature := &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()}
+		if _, err := worktree.Commit(contents, &git.CommitOptions{Author: signature}); err != nil {
+			t.Fatalf("failed to commit. Error: %q", err)
+		}
+	}
+	commit("version: 1")
+	// a branch which is not the default branch should not be checked out
+	head, err := upstream.Head()
+	if err != nil {
+		t.Fatalf("failed to get the HEAD of the upstream repository. Error: %q", err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("dev"), Create: true}); err != nil {
+		t.Fatalf("failed to create the dev branch. Error: %q", err)
+	}
+	commit("version: dev")
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: head.Name()}); err != nil {
+		t.Fatalf("failed to checkout the default branch. Error: %q", err)
+	}
+
+	cacheDir := t.TempDir()
+	fetch := func(want string) {
+		t.Helper()
+		repoDir, err := fetchGitCustomizations(context.Background(), "git+"+upstreamDir, cacheDir)
+		if err != nil {
+			t.Fatalf("failed to fetch the customizations. Error: %q", err)
+		}
+		data, err := os.ReadFile(filepath.Join(repoDir, "transformer.yaml"))
+		if err != nil || string(data) != want {
+			t.Fatalf("got the contents %q , want %q . Error: %v", string(data), want, err)
+		}
+	}
+	fetch("version: 1")
+	commit("version: 2")
+	fetch("version: 2")
+}
+
+func TestExtractTar(t *testing.T) {
+	buffer := bytes.Buffer{}
+	tarWriter := tar.NewWriter(&buffer)
+	for _, file := range []struct {
+		name string
+		mode int64
+	}{{name: "transformers/transformer.yaml", mode: 0600}, {name: "transformers/run.sh", mode: 0700}, {name: "../outside.yaml", mode: 0644}} {
+		contents := []byte("contents")
+		if err := tarWriter.WriteHeader(&tar.Header{Name: file.name, Mode: file.mode, Size: int64(len(contents)), Typeflag: tar.TypeReg}); err != nil {
+			t.Fatalf("failed to write the tar header. Error: %q", err)
+		}
+		if _, err := tarWriter.Write(contents); err != nil {
+			t.Fatalf("failed to write the tar contents. Error: %q", err)
+		}
+	}
+	if err := tarWriter.Close(); err != nil {
+		t.Fatalf("failed to close the tar writer. Error: %q", err)
+	}
+	dir := filepath.Join(t.TempDir(), "artifact")
+	if err := extractTar(&buffer, dir); err != nil {
+		t.Fatalf("failed to extract the tar. Error: %q", err)
+	}
+	for name, want := range map[string]os.FileMode{"transformers/transformer.yaml": 0644, "transformers/run.sh": 0744} {
+		fi, err := os.Stat(filepath.Join(dir, filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatalf("expected the file %s to be extracted. Error: %q", name, err)
+		}
+		if fi.Mode().Perm() != want {
+			t.Fatalf("got the permissions %s for the file %s , want %s", fi.Mode().Perm(), name, want)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(dir), "outside.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("expected the file outside the artifact to be skipped")
+	}
+}
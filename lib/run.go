@@ -0,0 +1,170 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/konveyor/move2kube/assets"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// Options configures a run of the transformation pipeline
+type Options struct {
+	// SourcePath is the directory containing the source artifacts. It can also contain IR files exported by the IRExporter.
+	SourcePath string
+	// PlanPath is the path of an existing plan file. When empty, a new plan is created from the source directory.
+	PlanPath string
+	// OutputPath is the directory where the output is written. It must not overlap with the source directory.
+	OutputPath string
+	// OutputFilesystem receives a copy of the finished output when set. See SetOutputFilesystem.
+	OutputFilesystem afero.Fs
+	// CustomizationsPath is the directory containing the custom transformers
+	CustomizationsPath string
+	// CustomTemplatesPath is the directory containing templates that override the built-in templates. See ApplyCustomTemplates.
+	CustomTemplatesPath string
+	// TransformerSelector is a label selector that chooses the transformers to enable. Example: move2kube.konveyor.io/built-in=true
+	TransformerSelector string
+	// ProjectName is the name of the project. Defaults to common.DefaultProjectName
+	ProjectName string
+	// ConfigFiles are the config files used to answer the questions. Later files override earlier ones.
+	ConfigFiles []string
+	// Configs are key=value config strings that override the config files
+	Configs []string
+	// Presets are the names of the built-in presets to apply
+	Presets []string
+	// QAEngine answers the questions not answered by the configs. When nil, the default answers are used.
+	QAEngine qaengine.Engine
+	// PlanTimeout limits the time taken for planning. Zero means no limit.
+	PlanTimeout time.Duration
+	// TransformTimeout limits the time taken for the transformation. Zero means no limit.
+	TransformTimeout time.Duration
+}
+
+// Run plans and transforms using the given options.
+// The QA engines, stores and transformers of a previous run are removed, so Run can be called again in the same process,
+// but not concurrently since they are global.
+func Run(ctx context.Context, opts Options) error {
+	if opts.SourcePath == "" && opts.PlanPath == "" {
+		return fmt.Errorf("either the source path or the plan path must be specified")
+	}
+	if opts.OutputPath == "" {
+		return fmt.Errorf("the output path must be specified")
+	}
+	if opts.ProjectName == "" {
+		opts.ProjectName = common.DefaultProjectName
+	}
+	var err error
+	if opts.SourcePath != "" {
+		if opts.SourcePath, err = filepath.Abs(opts.SourcePath); err != nil {
+			return fmt.Errorf("failed to make the source path %s absolute. Error: %w", opts.SourcePath, err)
+		}
+	}
+	if opts.OutputPath, err = filepath.Abs(opts.OutputPath); err != nil {
+		return fmt.Errorf("failed to make the output path %s absolute. Error: %w", opts.OutputPath, err)
+	}
+	if _, err := os.Stat(common.AssetsPath); err != nil {
+		tempPath, err := setupAssets()
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(tempPath)
+	}
+	qaengine.Reset()
+	transformer.Reset()
+	defer Destroy()
+	if err := ApplyCustomTemplates(opts.CustomTemplatesPath); err != nil {
+		return fmt.Errorf("failed to apply the custom templates. Error: %w", err)
+	}
+
+	qaEngine := opts.QAEngine
+	if qaEngine == nil {
+		qaEngine = qaengine.NewDefaultEngine()
+	}
+	qaengine.AddEngine(qaEngine)
+	qaengine.SetupConfigFile("", opts.Configs, opts.ConfigFiles, opts.Presets, false)
+
+	plan := plantypes.Plan{}
+	preExistingPlan := opts.PlanPath != ""
+	if preExistingPlan {
+		if plan, err = plantypes.ReadPlan(opts.PlanPath, opts.SourcePath); err != nil {
+			return fmt.Errorf("failed to read the plan at path %s . Error: %w", opts.PlanPath, err)
+		}
+		if err := CheckAndCopyCustomizations(plan.Spec.CustomizationsDir); err != nil {
+			return fmt.Errorf("failed to check and copy the customizations. Error: %w", err)
+		}
+	}
+	if plan.Spec.SourceDir != "" {
+		opts.SourcePath = plan.Spec.SourceDir
+	}
+	if opts.SourcePath != "" && (opts.SourcePath == opts.OutputPath || common.IsParent(opts.OutputPath, opts.SourcePath) || common.IsParent(opts.SourcePath, opts.OutputPath)) {
+		return fmt.Errorf("the source path %s and output path %s overlap", opts.SourcePath, opts.OutputPath)
+	}
+	if err := os.MkdirAll(opts.OutputPath, common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the output directory at path %s . Error: %w", opts.OutputPath, err)
+	}
+	if !preExistingPlan {
+		planCtx, cancel := withOptionalTimeout(ctx, opts.PlanTimeout)
+		defer cancel()
+		if plan, err = CreatePlan(planCtx, opts.SourcePath, opts.OutputPath, opts.CustomizationsPath, opts.TransformerSelector, opts.ProjectName); err != nil {
+			return fmt.Errorf("failed to create the plan. Error: %w", err)
+		}
+	}
+	if len(plan.Spec.Services) == 0 && len(plan.Spec.InvokedByDefaultTransformers) == 0 {
+		return fmt.Errorf("failed to find any services or default transformers")
+	}
+
+	previousOutputFs := outputFs
+	outputFs = opts.OutputFilesystem
+	defer func() { outputFs = previousOutputFs }()
+	transformCtx, cancel := withOptionalTimeout(ctx, opts.TransformTimeout)
+	defer cancel()
+	return Transform(transformCtx, plan, preExistingPlan, opts.OutputPath, opts.TransformerSelector)
+}
+
+func withOptionalTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// setupAssets extracts the built-in assets and returns the temporary directory containing them
+func setupAssets() (string, error) {
+	assetsFilePermissions := map[string]int{}
+	if err := yaml.Unmarshal([]byte(assets.AssetFilePermissions), &assetsFilePermissions); err != nil {
+		return "", fmt.Errorf("failed to unmarshal the file permissions of the assets. Error: %w", err)
+	}
+	assetsPath, tempPath, err := common.CreateAssetsData(assets.AssetsDir, assetsFilePermissions)
+	if err != nil {
+		return "", fmt.Errorf("failed to create the assets directory. Error: %w", err)
+	}
+	logrus.Debugf("extracted the assets to %s", assetsPath)
+	common.TempPath = tempPath
+	common.AssetsPath = assetsPath
+	return tempPath, nil
+}
@@ -0,0 +1,101 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/lib"
+	"github.com/spf13/afero"
+)
+
+// chdirToTempDir changes the working directory to a temporary directory for the test,
+// since the transformation writes the graph to the working directory
+func chdirToTempDir(t *testing.T) {
+	t.Helper()
+	oldWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("failed to get the working directory. Error: %q", err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatalf("failed to change the working directory. Error: %q", err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(oldWd); err != nil {
+			t.Errorf("failed to restore the working directory. Error: %q", err)
+		}
+	})
+}
+
+func TestRunTwice(t *testing.T) {
+	chdirToTempDir(t)
+	sourcePath := filepath.Join(t.TempDir(), "src")
+	if err := os.MkdirAll(filepath.Join(sourcePath, "web"), 0755); err != nil {
+		t.Fatalf("failed to create the source directory. Error: %q", err)
+	}
+	dockerfile := "FROM nginx:1.25\nEXPOSE 8080\n"
+	if err := os.WriteFile(filepath.Join(sourcePath, "web", "Dockerfile"), []byte(dockerfile), 0644); err != nil {
+		t.Fatalf("failed to write the Dockerfile. Error: %q", err)
+	}
+	for _, projectName := range []string{"first", "second"} {
+		outputPath := filepath.Join(t.TempDir(), projectName)
+		opts := lib.Options{
+			SourcePath:  sourcePath,
+			OutputPath:  outputPath,
+			ProjectName: projectName,
+		}
+		if err := lib.Run(context.Background(), opts); err != nil {
+			t.Fatalf("the run for the project %s failed. Error: %q", projectName, err)
+		}
+		deployments, err := filepath.Glob(filepath.Join(outputPath, "deploy", "yamls", "*-deployment.yaml"))
+		if err != nil || len(deployments) == 0 {
+			t.Fatalf("expected the run for the project %s to generate the Deployments, found %+v . Error: %v", projectName, deployments, err)
+		}
+	}
+}
+
+func TestRunIntoMemoryFilesystem(t *testing.T) {
+	chdirToTempDir(t)
+	sourcePath := filepath.Join(t.TempDir(), "src")
+	if err := os.MkdirAll(filepath.Join(sourcePath, "web"), 0755); err != nil {
+		t.Fatalf("failed to create the source directory. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourcePath, "web", "Dockerfile"), []byte("FROM nginx:1.25\nEXPOSE 8080\n"), 0644); err != nil {
+		t.Fatalf("failed to write the Dockerfile. Error: %q", err)
+	}
+	outputPath := filepath.Join(t.TempDir(), "inmemory")
+	memFs := afero.NewMemMapFs()
+	opts := lib.Options{
+		SourcePath:       sourcePath,
+		OutputPath:       outputPath,
+		ProjectName:      "inmemory",
+		OutputFilesystem: memFs,
+	}
+	if err := lib.Run(context.Background(), opts); err != nil {
+		t.Fatalf("the run failed. Error: %q", err)
+	}
+	deployments, err := afero.Glob(memFs, filepath.Join(outputPath, "deploy", "yamls", "*-deployment.yaml"))
+	if err != nil || len(deployments) == 0 {
+		t.Fatalf("expected the Deployments to be written to the in-memory filesystem, found %+v . Error: %v", deployments, err)
+	}
+	if _, err := os.Stat(outputPath); !os.IsNotExist(err) {
+		t.Fatalf("expected the working directory %s on the local filesystem to be removed", outputPath)
+	}
+}
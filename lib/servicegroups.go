@@ -0,0 +1,136 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/transformer"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// UngroupedServiceGroup is the group of the services which do not match any of the service groups
+	UngroupedServiceGroup = "ungrouped"
+	// transformerPatternPrefix marks the patterns matching the names of the transformers instead of the services
+	transformerPatternPrefix = "transformer:"
+)
+
+// serviceGroup is a group of services whose output is generated separately
+type serviceGroup struct {
+	name     string
+	patterns []string
+	options  []plantypes.PlanArtifact
+}
+
+// parseServiceGroups parses the service groups of the form <group>=<pattern>[,<pattern>...]
+func parseServiceGroups(values []string) ([]serviceGroup, error) {
+	groups := []serviceGroup{}
+	names := map[string]bool{}
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		name := strings.TrimSpace(parts[0])
+		if len(parts) != 2 || name == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("the service group '%s' is not of the form <group>=<pattern>[,<pattern>...]", value)
+		}
+		if common.MakeStringDNSLabelNameCompliant(name) != name {
+			return nil, fmt.Errorf("the name of the service group '%s' is not a valid DNS label", name)
+		}
+		if names[name] || name == UngroupedServiceGroup {
+			return nil, fmt.Errorf("the service group '%s' is specified more than once", name)
+		}
+		names[name] = true
+		group := serviceGroup{name: name}
+		for _, pattern := range strings.Split(parts[1], ",") {
+			pattern = strings.TrimSpace(pattern)
+			if _, err := filepath.Match(strings.TrimPrefix(pattern, transformerPatternPrefix), ""); err != nil {
+				return nil, fmt.Errorf("the pattern '%s' of the service group '%s' is invalid. Error: %w", pattern, name, err)
+			}
+			group.patterns = append(group.patterns, pattern)
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+// matches returns true if the pattern of the group matches the name of the service or of its transformer
+func (group serviceGroup) matches(option plantypes.PlanArtifact) bool {
+	for _, pattern := range group.patterns {
+		name := option.ServiceName
+		if strings.HasPrefix(pattern, transformerPatternPrefix) {
+			pattern, name = strings.TrimPrefix(pattern, transformerPatternPrefix), option.TransformerName
+		}
+		if ok, _ := filepath.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// groupTransformationOptions assigns each service to the first group matching it, and the rest to the ungrouped group
+func groupTransformationOptions(groups []serviceGroup, options []plantypes.PlanArtifact) []serviceGroup {
+	groups = append(groups, serviceGroup{name: UngroupedServiceGroup})
+	for _, option := range options {
+		for i := range groups {
+			if i == len(groups)-1 || groups[i].matches(option) {
+				groups[i].options = append(groups[i].options, option)
+				break
+			}
+		}
+	}
+	return groups
+}
+
+// transformServiceGroups transforms each group of services into the directory of the group in the output directory.
+// The output of each group is generated for a project named after the group, so that the resources shared by the services, like the ingress, do not collide.
+func transformServiceGroups(ctx context.Context, groups []serviceGroup, projectName, sourceDir, outputPath string) (*transformer.TransformationFailedError, error) {
+	defer transformer.SetProject(projectName, outputPath)
+	failures := []transformer.ReportFailure{}
+	for _, group := range groups {
+		if len(group.options) == 0 {
+			logrus.Debugf("Skipping the service group %s, since none of the services are in it", group.name)
+			continue
+		}
+		serviceNames := []string{}
+		for _, option := range group.options {
+			serviceNames = append(serviceNames, option.ServiceName)
+		}
+		logrus.Infof("Transforming the service group %s with the services %s", group.name, strings.Join(serviceNames, ", "))
+		groupOutputPath := filepath.Join(outputPath, group.name)
+		if err := os.MkdirAll(groupOutputPath, common.DefaultDirectoryPermission); err != nil {
+			return nil, fmt.Errorf("failed to create the output directory '%s' of the service group %s . Error: %w", groupOutputPath, group.name, err)
+		}
+		transformer.SetProject(common.MakeStringK8sServiceNameCompliant(projectName+"-"+group.name), groupOutputPath)
+		failedErr, err := transformServices(ctx, group.options, sourceDir, groupOutputPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to transform the service group %s . Error: %w", group.name, err)
+		}
+		if failedErr != nil {
+			failures = append(failures, failedErr.Failures...)
+		}
+	}
+	if len(failures) != 0 {
+		return &transformer.TransformationFailedError{Failures: failures}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,100 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+)
+
+func TestParseServiceGroups(t *testing.T) {
+	testCases := []struct {
+		name    string
+		values  []string
+		want    []serviceGroup
+		wantErr bool
+	}{
+		{name: "no groups", values: nil, want: []serviceGroup{}},
+		{name: "the patterns of the groups", values: []string{"payments=pay-*, billing", "frontend=transformer:Nodejs*"}, want: []serviceGroup{
+			{name: "payments", patterns: []string{"pay-*", "billing"}},
+			{name: "frontend", patterns: []string{"transformer:Nodejs*"}},
+		}},
+		{name: "without the patterns", values: []string{"payments="}, wantErr: true},
+		{name: "without the equals sign", values: []string{"payments"}, wantErr: true},
+		{name: "the name is not a dns label", values: []string{"Pay_ments=pay-*"}, wantErr: true},
+		{name: "the same group twice", values: []string{"payments=pay-*", "payments=billing"}, wantErr: true},
+		{name: "the ungrouped group", values: []string{UngroupedServiceGroup + "=pay-*"}, wantErr: true},
+		{name: "an invalid pattern", values: []string{"payments=pay-[*"}, wantErr: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := parseServiceGroups(testCase.values)
+			if testCase.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error for the service groups %q . Actual: %+v", testCase.values, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to parse the service groups %q . Error: %q", testCase.values, err)
+			}
+			if diff := cmp.Diff(testCase.want, got, cmp.AllowUnexported(serviceGroup{})); diff != "" {
+				t.Fatalf("unexpected service groups. Diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestGroupTransformationOptions(t *testing.T) {
+	groups, err := parseServiceGroups([]string{"payments=pay-*,billing", "frontend=transformer:Nodejs*", "all=*"})
+	if err != nil {
+		t.Fatalf("failed to parse the service groups. Error: %q", err)
+	}
+	options := []plantypes.PlanArtifact{
+		{ServiceName: "pay-api", TransformerName: "Golang-Dockerfile"},
+		{ServiceName: "billing", TransformerName: "Nodejs-Dockerfile"},
+		{ServiceName: "web", TransformerName: "Nodejs-Dockerfile"},
+		{ServiceName: "worker", TransformerName: "Python-Dockerfile"},
+	}
+	// every service is in the first group matching it, so the last group takes the rest
+	groups = groupTransformationOptions(groups, options)
+	got := map[string][]string{}
+	for _, group := range groups {
+		serviceNames := []string{}
+		for _, option := range group.options {
+			serviceNames = append(serviceNames, option.ServiceName)
+		}
+		got[group.name] = serviceNames
+	}
+	want := map[string][]string{
+		"payments":            {"pay-api", "billing"},
+		"frontend":            {"web"},
+		"all":                 {"worker"},
+		UngroupedServiceGroup: {},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected services in the groups. Diff (-want +got):\n%s", diff)
+	}
+
+	groups, _ = parseServiceGroups([]string{"payments=pay-*"})
+	groups = groupTransformationOptions(groups, options)
+	if len(groups) != 2 || groups[1].name != UngroupedServiceGroup || len(groups[1].options) != 3 {
+		t.Fatalf("expected the services which are in no group to be ungrouped. Actual: %+v", groups)
+	}
+}
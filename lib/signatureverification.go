@@ -0,0 +1,269 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	cosignCmd = "cosign"
+	// checksumsFile lists the sha256 checksums of all the files in a git repository of customizations, in the format of sha256sum.
+	// The file is signed with cosign sign-blob, since the git commits can not be verified by cosign.
+	checksumsFile = "m2k-customizations.sha256"
+	// checksumsSignatureSuffix is the suffix of the signature created using a key. Example: cosign sign-blob --key cosign.key --output-signature m2k-customizations.sha256.sig m2k-customizations.sha256
+	checksumsSignatureSuffix = ".sig"
+	// checksumsBundleSuffix is the suffix of the bundle created using keyless signing. Example: cosign sign-blob --bundle m2k-customizations.sha256.bundle m2k-customizations.sha256
+	checksumsBundleSuffix = ".bundle"
+	// KeylessSigning is the value of the signing key which uses the keyless signing of cosign, with the identity from the OIDC provider
+	KeylessSigning = "keyless"
+	// verifiedByFile stores the verifier of the cached OCI artifact, so that it can be used when the registry is unreachable
+	verifiedByFile = ".m2kverifiedby"
+)
+
+// sha256Regex matches the sha256 checksums in hex
+var sha256Regex = regexp.MustCompile(`^[0-9a-fA-F]{64}$`)
+
+// isSignatureVerificationEnabled returns true if a key or a keyless identity was given to verify the remote customizations
+func isSignatureVerificationEnabled() bool {
+	return common.SignatureVerificationKey != "" || common.SignatureVerificationIdentity != ""
+}
+
+// getVerifier returns a description of the key or the keyless identity used for the verification
+func getVerifier() string {
+	if common.SignatureVerificationKey != "" {
+		return "key=" + common.SignatureVerificationKey
+	}
+	return "identity=" + common.SignatureVerificationIdentity + ",issuer=" + common.SignatureVerificationOIDCIssuer
+}
+
+// getCosignVerificationArgs returns the arguments of cosign for the key or the keyless verification
+func getCosignVerificationArgs() ([]string, error) {
+	if common.SignatureVerificationKey != "" {
+		return []string{"--key", common.SignatureVerificationKey}, nil
+	}
+	if common.SignatureVerificationOIDCIssuer == "" {
+		return nil, fmt.Errorf("the OIDC issuer is required along with the identity for the keyless verification")
+	}
+	return []string{"--certificate-identity", common.SignatureVerificationIdentity, "--certificate-oidc-issuer", common.SignatureVerificationOIDCIssuer}, nil
+}
+
+// runCosign runs cosign with the arguments and returns its output in the error when the command fails
+func runCosign(ctx context.Context, args ...string) error {
+	if _, err := exec.LookPath(cosignCmd); err != nil {
+		return fmt.Errorf("cosign is required to sign and verify the signatures. Error: %w", err)
+	}
+	logrus.Debugf("Running %s %s", cosignCmd, strings.Join(args, " "))
+	output, err := exec.CommandContext(ctx, cosignCmd, args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s . Error: %w", strings.TrimSpace(string(output)), err)
+	}
+	return nil
+}
+
+// verifyOCICustomizations verifies the cosign signature of the OCI artifact, pinned to the digest which is pulled
+func verifyOCICustomizations(ctx context.Context, repository, digest string) error {
+	if !isSignatureVerificationEnabled() {
+		logrus.Warnf("The signature of the customizations in the OCI artifact %s@%s is not verified. Use the --%s or the --%s flags to verify it.", repository, digest, common.VerifyKeyFlag, common.VerifyIdentityFlag)
+		return nil
+	}
+	args, err := getCosignVerificationArgs()
+	if err != nil {
+		return err
+	}
+	if err := runCosign(ctx, append(append([]string{"verify"}, args...), repository+"@"+digest)...); err != nil {
+		return fmt.Errorf("failed to verify the signature of the OCI artifact '%s@%s' . Error: %w", repository, digest, err)
+	}
+	logrus.Infof("Verified the signature of the customizations in the OCI artifact %s@%s", repository, digest)
+	return nil
+}
+
+// verifyCachedOCICustomizations checks that the cached OCI artifact was verified by the same key or identity, when the registry is unreachable
+func verifyCachedOCICustomizations(artifactDir string) error {
+	if !isSignatureVerificationEnabled() {
+		return nil
+	}
+	verifiedBy, err := os.ReadFile(filepath.Join(artifactDir, verifiedByFile))
+	if err != nil || string(verifiedBy) != getVerifier() {
+		return fmt.Errorf("the cached customizations at '%s' were not verified with %s", artifactDir, getVerifier())
+	}
+	return nil
+}
+
+// markOCICustomizationsVerified records the verifier of the cached OCI artifact
+func markOCICustomizationsVerified(artifactDir string) {
+	if !isSignatureVerificationEnabled() {
+		os.Remove(filepath.Join(artifactDir, verifiedByFile))
+		return
+	}
+	if err := os.WriteFile(filepath.Join(artifactDir, verifiedByFile), []byte(getVerifier()), common.DefaultFilePermission); err != nil {
+		logrus.Warnf("Failed to record the verification of the cached customizations at %s . Error: %q", artifactDir, err)
+	}
+}
+
+// verifyGitCustomizations verifies the signature of the checksums file of the git repository and the checksums of all the files in the worktree
+func verifyGitCustomizations(ctx context.Context, repoURL, repoDir string) error {
+	if !isSignatureVerificationEnabled() {
+		logrus.Warnf("The signature of the customizations in the git repository %s is not verified. Use the --%s or the --%s flags to verify it.", repoURL, common.VerifyKeyFlag, common.VerifyIdentityFlag)
+		return nil
+	}
+	args, err := getCosignVerificationArgs()
+	if err != nil {
+		return err
+	}
+	checksumsPath := filepath.Join(repoDir, checksumsFile)
+	if bundlePath := checksumsPath + checksumsBundleSuffix; fileExists(bundlePath) {
+		args = append(args, "--bundle", bundlePath)
+	} else if signaturePath := checksumsPath + checksumsSignatureSuffix; fileExists(signaturePath) {
+		args = append(args, "--signature", signaturePath)
+	} else {
+		return fmt.Errorf("the git repository '%s' has no signature %s%s or bundle %s%s of the checksums file", repoURL, checksumsFile, checksumsSignatureSuffix, checksumsFile, checksumsBundleSuffix)
+	}
+	if err := runCosign(ctx, append(append([]string{"verify-blob"}, args...), checksumsPath)...); err != nil {
+		return fmt.Errorf("failed to verify the signature of the checksums file of the git repository '%s' . Error: %w", repoURL, err)
+	}
+	if err := verifyChecksums(repoDir, checksumsPath); err != nil {
+		return fmt.Errorf("failed to verify the files of the git repository '%s' . Error: %w", repoURL, err)
+	}
+	logrus.Infof("Verified the signature of the customizations in the git repository %s", repoURL)
+	return nil
+}
+
+// fileExists returns true if the path is an existing file
+func fileExists(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && !fi.IsDir()
+}
+
+// verifyChecksums checks that every file in the directory is listed in the checksums file with the same checksum
+func verifyChecksums(dir, checksumsPath string) error {
+	checksums, err := readChecksums(checksumsPath)
+	if err != nil {
+		return fmt.Errorf("failed to read the checksums file '%s' . Error: %w", checksumsPath, err)
+	}
+	verified := map[string]bool{}
+	if err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if relPath == checksumsFile || relPath == checksumsFile+checksumsSignatureSuffix || relPath == checksumsFile+checksumsBundleSuffix {
+			return nil
+		}
+		expected, ok := checksums[relPath]
+		if !ok {
+			return fmt.Errorf("the file '%s' is not listed in the checksums file", relPath)
+		}
+		actual, err := getFileChecksum(path)
+		if err != nil {
+			return fmt.Errorf("failed to get the checksum of the file '%s' . Error: %w", relPath, err)
+		}
+		if actual != expected {
+			return fmt.Errorf("the checksum of the file '%s' does not match the checksums file", relPath)
+		}
+		verified[relPath] = true
+		return nil
+	}); err != nil {
+		return err
+	}
+	missing := []string{}
+	for relPath := range checksums {
+		if !verified[relPath] {
+			missing = append(missing, relPath)
+		}
+	}
+	if len(missing) != 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("the files %s listed in the checksums file are missing", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// readChecksums reads the checksums file created by sha256sum, keyed by the relative path of the files.
+// Each line has the checksum, a space, a space or a * for the binary mode, and the path, which can contain spaces.
+func readChecksums(checksumsPath string) (map[string]string, error) {
+	file, err := os.Open(checksumsPath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	checksums := map[string]string{}
+	scanner := bufio.NewScanner(file)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		checksum, path, ok := strings.Cut(line, " ")
+		if !ok || len(path) < 2 || (path[0] != ' ' && path[0] != '*') || !sha256Regex.MatchString(checksum) {
+			return nil, fmt.Errorf("the line %d of the checksums file is not a sha256 checksum followed by a path", lineNumber)
+		}
+		path = strings.TrimPrefix(path[1:], "./")
+		checksums[path] = strings.ToLower(checksum)
+	}
+	return checksums, scanner.Err()
+}
+
+// getFileChecksum returns the sha256 checksum of the file in hex
+func getFileChecksum(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
+
+// signProvenance signs the provenance file with cosign sign-blob. The signature, or the bundle for the keyless signing, is written next to it.
+func signProvenance(ctx context.Context, provenancePath string) error {
+	args := []string{"sign-blob", "--yes"}
+	if common.ProvenanceSigningKey == KeylessSigning {
+		args = append(args, "--bundle", provenancePath+checksumsBundleSuffix)
+	} else {
+		args = append(args, "--key", common.ProvenanceSigningKey, "--output-signature", provenancePath+checksumsSignatureSuffix)
+	}
+	if err := runCosign(ctx, append(args, provenancePath)...); err != nil {
+		return fmt.Errorf("failed to sign the provenance file '%s' . Error: %w", provenancePath, err)
+	}
+	logrus.Infof("Signed the provenance file %s", provenancePath)
+	return nil
+}
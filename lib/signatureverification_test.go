@@ -0,0 +1,184 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+)
+
+func TestReadChecksums(t *testing.T) {
+	hash := strings.Repeat("ab", 32)
+	testCases := []struct {
+		name     string
+		contents string
+		want     map[string]string
+		wantErr  bool
+	}{
+		{
+			name:     "text and binary modes",
+			contents: hash + "  ./transformers/a.yaml\n" + strings.ToUpper(hash) + " *b.star\n\n",
+			want:     map[string]string{"transformers/a.yaml": hash, "b.star": hash},
+		},
+		{
+			name:     "paths with spaces and windows line endings",
+			contents: hash + "  templates/my file.yaml\r\n",
+			want:     map[string]string{"templates/my file.yaml": hash},
+		},
+		{name: "missing path", contents: hash + "\n", wantErr: true},
+		{name: "single space", contents: hash + " a.yaml\n", wantErr: true},
+		{name: "invalid checksum", contents: "abc  a.yaml\n", wantErr: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			checksumsPath := filepath.Join(t.TempDir(), checksumsFile)
+			if err := os.WriteFile(checksumsPath, []byte(testCase.contents), 0644); err != nil {
+				t.Fatalf("failed to write the checksums file. Error: %q", err)
+			}
+			got, err := readChecksums(checksumsPath)
+			if (err != nil) != testCase.wantErr {
+				t.Fatalf("got the error %v , want an error: %t", err, testCase.wantErr)
+			}
+			if testCase.wantErr {
+				return
+			}
+			if fmt.Sprint(got) != fmt.Sprint(testCase.want) {
+				t.Fatalf("got the checksums %+v , want %+v", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestVerifyChecksums(t *testing.T) {
+	files := map[string]string{
+		"transformers/a.yaml": "kind: Transformer",
+		"b.star":              "def transform(): pass",
+	}
+	checksum := func(contents string) string {
+		return fmt.Sprintf("%x", sha256.Sum256([]byte(contents)))
+	}
+	setup := func(t *testing.T, contents map[string]string, checksums map[string]string) string {
+		dir := t.TempDir()
+		for path, data := range contents {
+			path = filepath.Join(dir, filepath.FromSlash(path))
+			if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+				t.Fatalf("failed to create the directory. Error: %q", err)
+			}
+			if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+				t.Fatalf("failed to write the file. Error: %q", err)
+			}
+		}
+		lines := ""
+		for path, sum := range checksums {
+			lines += sum + "  " + path + "\n"
+		}
+		if err := os.WriteFile(filepath.Join(dir, checksumsFile), []byte(lines), 0644); err != nil {
+			t.Fatalf("failed to write the checksums file. Error: %q", err)
+		}
+		return dir
+	}
+	allChecksums := map[string]string{}
+	for path, data := range files {
+		allChecksums[path] = checksum(data)
+	}
+
+	testCases := []struct {
+		name      string
+		contents  map[string]string
+		checksums map[string]string
+		wantErr   string
+	}{
+		{name: "all the files match", contents: files, checksums: allChecksums},
+		{
+			name:      "modified file",
+			contents:  map[string]string{"transformers/a.yaml": "kind: Transformer", "b.star": "def transform(): fail"},
+			checksums: allChecksums,
+			wantErr:   "does not match",
+		},
+		{
+			name:      "file which is not listed",
+			contents:  map[string]string{"transformers/a.yaml": "kind: Transformer", "b.star": "def transform(): pass", "c.star": ""},
+			checksums: allChecksums,
+			wantErr:   "not listed",
+		},
+		{
+			name:      "listed file which was removed",
+			contents:  map[string]string{"transformers/a.yaml": "kind: Transformer"},
+			checksums: allChecksums,
+			wantErr:   "b.star",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			dir := setup(t, testCase.contents, testCase.checksums)
+			err := verifyChecksums(dir, filepath.Join(dir, checksumsFile))
+			if testCase.wantErr == "" {
+				if err != nil {
+					t.Fatalf("failed to verify the checksums. Error: %q", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), testCase.wantErr) {
+				t.Fatalf("expected an error containing %q, got %v", testCase.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSignProvenance(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake cosign is a shell script")
+	}
+	binDir := t.TempDir()
+	argsPath := filepath.Join(t.TempDir(), "args")
+	fakeCosign := "#!/bin/sh\necho \"$@\" > " + argsPath + "\n"
+	if err := os.WriteFile(filepath.Join(binDir, cosignCmd), []byte(fakeCosign), 0755); err != nil {
+		t.Fatalf("failed to write the fake cosign. Error: %q", err)
+	}
+	t.Setenv("PATH", binDir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	signingKey := common.ProvenanceSigningKey
+	t.Cleanup(func() { common.ProvenanceSigningKey = signingKey })
+	provenancePath := filepath.Join(t.TempDir(), "m2k-provenance.json")
+	testCases := []struct {
+		signingKey string
+		want       string
+	}{
+		{signingKey: "cosign.key", want: "sign-blob --yes --key cosign.key --output-signature " + provenancePath + ".sig " + provenancePath + "\n"},
+		{signingKey: KeylessSigning, want: "sign-blob --yes --bundle " + provenancePath + ".bundle " + provenancePath + "\n"},
+	}
+	for _, testCase := range testCases {
+		common.ProvenanceSigningKey = testCase.signingKey
+		if err := signProvenance(context.Background(), provenancePath); err != nil {
+			t.Fatalf("failed to sign the provenance. Error: %q", err)
+		}
+		args, err := os.ReadFile(argsPath)
+		if err != nil {
+			t.Fatalf("failed to read the arguments of cosign. Error: %q", err)
+		}
+		if string(args) != testCase.want {
+			t.Errorf("cosign was run with %q for the signing key %s , want %q", args, testCase.signingKey, testCase.want)
+		}
+	}
+}
@@ -18,7 +18,9 @@ package lib
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"path/filepath"
 	"sort"
 
 	"github.com/konveyor/move2kube/common"
@@ -34,6 +36,8 @@ func Transform(ctx context.Context, plan plantypes.Plan, preExistingPlan bool, o
 	logrus.Infof("Starting transformation")
 
 	common.ProjectName = plan.Name
+	transformer.StartTransformationReport()
+	defer transformer.StopTransformationReport()
 	logrus.Debugf("common.TempPath: '%s'", common.TempPath)
 
 	transformerSelectorObj, err := common.ConvertStringSelectorsToSelectors(transformerSelector)
@@ -47,10 +51,16 @@ func Transform(ctx context.Context, plan plantypes.Plan, preExistingPlan bool, o
 	requirements, _ := selectorsInPlan.Requirements()
 	transformerSelectorObj = transformerSelectorObj.Add(requirements...)
 
+	serviceGroups, err := parseServiceGroups(common.ServiceGroups)
+	if err != nil {
+		return fmt.Errorf("failed to parse the service groups. Error: %w", err)
+	}
 	if _, err := transformer.InitTransformers(plan.Spec.Transformers, transformerSelectorObj, plan.Spec.SourceDir, outputPath, plan.Name, true, preExistingPlan); err != nil {
 		return fmt.Errorf("failed to initialize the transformers. Error: %w", err)
 	}
 
+	// the services in the plan could have been renamed by the user
+	plan.Spec.Services = transformer.SanitizeServiceNames(plan.Spec.Services)
 	// select only the services the user is interested in
 	serviceNames := []string{}
 	for serviceName := range plan.Spec.Services {
@@ -58,6 +68,11 @@ func Transform(ctx context.Context, plan plantypes.Plan, preExistingPlan bool, o
 	}
 	sort.Strings(serviceNames)
 	selectedServiceNames := qaengine.FetchMultiSelectAnswer(common.ConfigServicesNamesKey, "Select all services that are needed:", []string{"The services unselected here will be ignored."}, serviceNames, serviceNames, nil)
+	for _, serviceName := range serviceNames {
+		if !common.IsPresent(selectedServiceNames, serviceName) {
+			transformer.AddSkippedToReport(fmt.Sprintf("The service '%s' was not selected.", serviceName))
+		}
+	}
 
 	// select the first valid transformation option for each selected service
 	selectedTransformationOptions := []plantypes.PlanArtifact{}
@@ -80,18 +95,51 @@ func Transform(ctx context.Context, plan plantypes.Plan, preExistingPlan bool, o
 		}
 		if !found {
 			logrus.Warnf("No valid transformers were found for the service '%s'. Skipping.", selectedServiceName)
+			transformer.AddSkippedToReport(fmt.Sprintf("No valid transformers were found for the service '%s'.", selectedServiceName))
 		}
 	}
 
 	// transform the selected services using the selected transformation options
-	if err := transformer.Transform(selectedTransformationOptions, plan.Spec.SourceDir, outputPath); err != nil {
-		return fmt.Errorf("failed to transform using the plan. Error: %w", err)
+	common.ReportProgress(common.ProgressEvent{Stage: common.TransformProgressStage, Type: common.StageStartedProgressEvent, Total: len(selectedTransformationOptions)})
+	var failedErr *transformer.TransformationFailedError
+	if len(serviceGroups) == 0 {
+		failedErr, err = transformServices(ctx, selectedTransformationOptions, plan.Spec.SourceDir, outputPath)
+	} else {
+		failedErr, err = transformServiceGroups(ctx, groupTransformationOptions(serviceGroups, selectedTransformationOptions), plan.Name, plan.Spec.SourceDir, outputPath)
+	}
+	if err != nil {
+		return err
+	}
+	if err := writeOutputToFilesystem(outputPath); err != nil {
+		return err
+	}
+	common.ReportProgress(common.ProgressEvent{Stage: common.TransformProgressStage, Type: common.StageFinishedProgressEvent})
+	if failedErr != nil {
+		return failedErr
 	}
-
-	logrus.Infof("Transformation done")
 	return nil
 }
 
+// transformServices transforms the services using the selected transformation options and writes the output.
+// When only some of the transformations failed, the output is still written and the failures are returned.
+func transformServices(ctx context.Context, selectedTransformationOptions []plantypes.PlanArtifact, sourceDir, outputPath string) (*transformer.TransformationFailedError, error) {
+	var failedErr *transformer.TransformationFailedError
+	if err := transformer.Transform(ctx, selectedTransformationOptions, sourceDir, outputPath); err != nil {
+		if !errors.As(err, &failedErr) {
+			return nil, fmt.Errorf("failed to transform using the plan. Error: %w", err)
+		}
+		logrus.Errorf("Transformation finished with failures. The details can be found in the %s file in the output directory.", transformer.TransformationReportMarkdownFile)
+	} else {
+		logrus.Infof("Transformation done")
+	}
+	if common.ProvenanceSigningKey != "" {
+		if err := signProvenance(ctx, filepath.Join(outputPath, transformer.ProvenanceFile)); err != nil {
+			return nil, fmt.Errorf("failed to sign the provenance. Error: %w", err)
+		}
+	}
+	return failedErr, nil
+}
+
 // Destroy destroys the tranformers
 func Destroy() {
 	logrus.Debugf("Cleaning up!")
@@ -20,17 +20,23 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/filesystem"
+	"github.com/sirupsen/logrus"
 )
 
-// CheckAndCopyCustomizations checks if the customizations path is an existing directory and copies to assets
+// CheckAndCopyCustomizations checks if the customizations path is an existing directory, or fetches the git repository or OCI artifact, and copies to assets
 func CheckAndCopyCustomizations(customizationsPath string) error {
 	if customizationsPath == "" {
 		return nil
 	}
-	customizationsPath, err := filepath.Abs(customizationsPath)
+	customizationsPath, err := FetchRemoteCustomizations(customizationsPath)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the remote customizations. Error: %w", err)
+	}
+	customizationsPath, err = filepath.Abs(customizationsPath)
 	if err != nil {
 		return fmt.Errorf("failed to make the customizations directory path '%s' absolute. Error: %w", customizationsPath, err)
 	}
@@ -77,3 +83,84 @@ func CopyCustomizationsAssetsData(customizationsPath string) (err error) {
 	}
 	return nil
 }
+
+// ApplyCustomTemplates overrides the templates of the built-in transformers with the files in the custom templates directory.
+// A file overrides the built-in template with the same name. When multiple built-in templates have the same name,
+// the file has to be placed at the same path relative to the built-in transformers directory.
+// Example: dockerfilegenerator/nodejs/templates/Dockerfile
+func ApplyCustomTemplates(customTemplatesPath string) error {
+	if customTemplatesPath == "" {
+		return nil
+	}
+	customTemplatesPath, err := FetchRemoteCustomizations(customTemplatesPath)
+	if err != nil {
+		return fmt.Errorf("failed to fetch the remote custom templates. Error: %w", err)
+	}
+	customTemplatesPath, err = filepath.Abs(customTemplatesPath)
+	if err != nil {
+		return fmt.Errorf("failed to make the custom templates directory path '%s' absolute. Error: %w", customTemplatesPath, err)
+	}
+	if fi, err := os.Stat(customTemplatesPath); err != nil {
+		return fmt.Errorf("failed to stat the custom templates directory '%s' . Error: %w", customTemplatesPath, err)
+	} else if !fi.IsDir() {
+		return fmt.Errorf("the given custom templates path '%s' is a file. Expected a directory", customTemplatesPath)
+	}
+	builtInTransformersPath := filepath.Join(common.AssetsPath, "built-in", "transformers")
+	templatesByName := map[string][]string{}
+	err = filepath.WalkDir(builtInTransformersPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !common.IsPresent(strings.Split(filepath.ToSlash(path), "/"), "templates") {
+			return nil
+		}
+		templatesByName[d.Name()] = append(templatesByName[d.Name()], path)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to look for the templates in the directory '%s' . Error: %w", builtInTransformersPath, err)
+	}
+	return filepath.WalkDir(customTemplatesPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		relPath, err := filepath.Rel(customTemplatesPath, path)
+		if err != nil {
+			return fmt.Errorf("failed to make the path '%s' relative to the custom templates directory '%s' . Error: %w", path, customTemplatesPath, err)
+		}
+		destPath := filepath.Join(builtInTransformersPath, relPath)
+		if _, err := os.Stat(destPath); err != nil {
+			candidates := templatesByName[d.Name()]
+			if len(candidates) == 0 {
+				logrus.Warnf("the custom template '%s' does not match any built-in template. Ignoring it.", relPath)
+				return nil
+			}
+			if len(candidates) > 1 {
+				relCandidates := []string{}
+				for _, candidate := range candidates {
+					relCandidate, _ := filepath.Rel(builtInTransformersPath, candidate)
+					relCandidates = append(relCandidates, relCandidate)
+				}
+				return fmt.Errorf("the custom template '%s' matches multiple built-in templates %+v . Place it at one of these paths in the custom templates directory", relPath, relCandidates)
+			}
+			destPath = candidates[0]
+		}
+		// keep the permissions of the built-in template, so that the generated scripts stay executable
+		di, err := os.Stat(destPath)
+		if err != nil {
+			return fmt.Errorf("failed to stat the built-in template '%s' . Error: %w", destPath, err)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read the custom template '%s' . Error: %w", path, err)
+		}
+		if err := os.WriteFile(destPath, data, di.Mode()); err != nil {
+			return fmt.Errorf("failed to copy the custom template '%s' to '%s' . Error: %w", path, destPath, err)
+		}
+		logrus.Debugf("overrode the built-in template '%s' with the custom template '%s'", destPath, path)
+		return nil
+	})
+}
@@ -0,0 +1,109 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+)
+
+func TestApplyCustomTemplates(t *testing.T) {
+	writeFile := func(t *testing.T, path, contents string, mode os.FileMode) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory of %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), mode); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	readFile := func(t *testing.T, path string) string {
+		t.Helper()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("failed to read the file %s . Error: %q", path, err)
+		}
+		return string(data)
+	}
+	setup := func(t *testing.T) (string, string) {
+		t.Helper()
+		assetsPath := common.AssetsPath
+		common.AssetsPath = t.TempDir()
+		t.Cleanup(func() { common.AssetsPath = assetsPath })
+		builtInTransformersPath := filepath.Join(common.AssetsPath, "built-in", "transformers")
+		writeFile(t, filepath.Join(builtInTransformersPath, "dockerfile", "dockerimagebuildscript", "templates", "buildimages.sh"), "built-in build script", 0755)
+		writeFile(t, filepath.Join(builtInTransformersPath, "dockerfile", "dockerimagebuildscript", "transformer.yaml"), "built-in transformer", common.DefaultFilePermission)
+		for _, language := range []string{"nodejs", "python"} {
+			writeFile(t, filepath.Join(builtInTransformersPath, "dockerfilegenerator", language, "templates", "Dockerfile"), "built-in "+language, common.DefaultFilePermission)
+		}
+		return builtInTransformersPath, t.TempDir()
+	}
+
+	t.Run("no custom templates", func(t *testing.T) {
+		if err := ApplyCustomTemplates(""); err != nil {
+			t.Fatalf("expected no error without a custom templates directory. Error: %q", err)
+		}
+	})
+	t.Run("the templates are overridden by name and by relative path", func(t *testing.T) {
+		builtInTransformersPath, customTemplatesPath := setup(t)
+		writeFile(t, filepath.Join(customTemplatesPath, "buildimages.sh"), "custom build script", common.DefaultFilePermission)
+		writeFile(t, filepath.Join(customTemplatesPath, "dockerfilegenerator", "python", "templates", "Dockerfile"), "custom python", common.DefaultFilePermission)
+		writeFile(t, filepath.Join(customTemplatesPath, "unknown.txt"), "ignored", common.DefaultFilePermission)
+		writeFile(t, filepath.Join(customTemplatesPath, "transformer.yaml"), "not a template", common.DefaultFilePermission)
+		if err := ApplyCustomTemplates(customTemplatesPath); err != nil {
+			t.Fatalf("failed to apply the custom templates. Error: %q", err)
+		}
+		buildScriptPath := filepath.Join(builtInTransformersPath, "dockerfile", "dockerimagebuildscript", "templates", "buildimages.sh")
+		if got := readFile(t, buildScriptPath); got != "custom build script" {
+			t.Fatalf("expected the build script to be overridden, got %q", got)
+		}
+		if fi, err := os.Stat(buildScriptPath); err != nil || fi.Mode().Perm() != 0755 {
+			t.Fatalf("expected the overridden build script to stay executable. Actual: %+v %v", fi, err)
+		}
+		for language, want := range map[string]string{"nodejs": "built-in nodejs", "python": "custom python"} {
+			if got := readFile(t, filepath.Join(builtInTransformersPath, "dockerfilegenerator", language, "templates", "Dockerfile")); got != want {
+				t.Fatalf("got the %s Dockerfile %q , want %q", language, got, want)
+			}
+		}
+		if got := readFile(t, filepath.Join(builtInTransformersPath, "dockerfile", "dockerimagebuildscript", "transformer.yaml")); got != "built-in transformer" {
+			t.Fatalf("expected only the templates to be overridden, got the transformer %q", got)
+		}
+		if _, err := os.Stat(filepath.Join(builtInTransformersPath, "unknown.txt")); !os.IsNotExist(err) {
+			t.Fatalf("expected the unknown custom template to be ignored. Error: %v", err)
+		}
+	})
+	t.Run("a name shared by multiple templates", func(t *testing.T) {
+		_, customTemplatesPath := setup(t)
+		writeFile(t, filepath.Join(customTemplatesPath, "Dockerfile"), "custom", common.DefaultFilePermission)
+		err := ApplyCustomTemplates(customTemplatesPath)
+		if err == nil || !strings.Contains(err.Error(), "matches multiple built-in templates") {
+			t.Fatalf("expected an error for the ambiguous custom template. Error: %v", err)
+		}
+	})
+	t.Run("a file instead of a directory", func(t *testing.T) {
+		_, customTemplatesPath := setup(t)
+		path := filepath.Join(customTemplatesPath, "buildimages.sh")
+		writeFile(t, path, "custom", common.DefaultFilePermission)
+		if err := ApplyCustomTemplates(path); err == nil {
+			t.Fatalf("expected an error when the custom templates path is a file")
+		}
+	})
+}
@@ -0,0 +1,250 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/filesystem"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	"github.com/sirupsen/logrus"
+)
+
+// watchDebounceInterval is the time to wait after the last change before transforming again, so that a burst of changes is transformed once
+const watchDebounceInterval = time.Second
+
+// watchChanges stores the changes seen since the last transformation
+type watchChanges struct {
+	paths                 []string
+	customizationsChanged bool
+	// replan is true when files were added or removed outside of the services in the plan, since they could be new services,
+	// or when the files which the services were detected from, like the compose files, changed
+	replan bool
+}
+
+// Watch watches the source directory and the customizations directory and transforms again when they change.
+// The plan is reused unless new files appear outside of the planned services or the customizations change.
+// The output is generated in a temporary directory and then replicated into the output directory, so that it is never partially written.
+func Watch(ctx context.Context, plan plantypes.Plan, preExistingPlan bool, outputPath string, transformerSelector string) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create the file watcher. Error: %w", err)
+	}
+	defer watcher.Close()
+	customizationsDir := ""
+	if plan.Spec.CustomizationsDir != "" && !IsRemoteCustomizations(plan.Spec.CustomizationsDir) {
+		if customizationsDir, err = filepath.Abs(plan.Spec.CustomizationsDir); err != nil {
+			return fmt.Errorf("failed to make the customizations directory path '%s' absolute. Error: %w", plan.Spec.CustomizationsDir, err)
+		}
+	}
+	watchedDirs := []string{}
+	for _, dir := range []string{plan.Spec.SourceDir, customizationsDir} {
+		if dir == "" {
+			continue
+		}
+		if err := addWatches(watcher, dir); err != nil {
+			return fmt.Errorf("failed to watch the directory '%s' . Error: %w", dir, err)
+		}
+		watchedDirs = append(watchedDirs, dir)
+	}
+	if len(watchedDirs) == 0 {
+		return fmt.Errorf("there is no source or customizations directory to watch")
+	}
+	logrus.Infof("Watching %v for changes. Press Ctrl+C to stop.", watchedDirs)
+	changes := watchChanges{}
+	debounce := time.NewTimer(watchDebounceInterval)
+	debounce.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			logrus.Warnf("Error while watching for changes. Error: %q", err)
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if isIgnoredWatchPath(event.Name, watchedDirs) {
+				continue
+			}
+			logrus.Debugf("Detected the change %s", event)
+			if event.Op&fsnotify.Create != 0 {
+				if fi, err := os.Stat(event.Name); err == nil && fi.IsDir() {
+					if err := addWatches(watcher, event.Name); err != nil {
+						logrus.Warnf("Failed to watch the new directory %s . Error: %q", event.Name, err)
+					}
+				}
+			}
+			changes.paths = common.AppendIfNotPresent(changes.paths, event.Name)
+			if customizationsDir != "" && common.IsParent(event.Name, customizationsDir) {
+				changes.customizationsChanged = true
+			} else if event.Op&(fsnotify.Create|fsnotify.Remove|fsnotify.Rename) != 0 && len(getAffectedServices(plan, []string{event.Name})) == 0 {
+				changes.replan = true
+			} else if isPlannedFile(plan, event.Name) {
+				changes.replan = true
+			}
+			debounce.Reset(watchDebounceInterval)
+		case <-debounce.C:
+			if affectedServices := getAffectedServices(plan, changes.paths); len(affectedServices) != 0 {
+				logrus.Infof("Detected changes in the services %v", affectedServices)
+			}
+			if changes.customizationsChanged {
+				logrus.Infof("Detected changes in the customizations. Copying them again.")
+			}
+			if changes.replan || changes.customizationsChanged {
+				logrus.Infof("Planning again, since the files defining the services or the customizations changed.")
+				newPlan, err := replan(ctx, plan, outputPath, transformerSelector)
+				if err != nil {
+					logrus.Errorf("failed to create the plan. Using the previous plan. Error: %q", err)
+				} else {
+					plan = newPlan
+					preExistingPlan = false
+				}
+			}
+			changes = watchChanges{}
+			if err := transformIntoOutput(ctx, plan, preExistingPlan, outputPath, transformerSelector); err != nil {
+				logrus.Errorf("failed to transform the changes. Error: %q", err)
+				continue
+			}
+			logrus.Infof("The output at [%s] is in sync with the changes. Watching for more changes.", outputPath)
+		}
+	}
+}
+
+// replan plans again after destroying and resetting the transformers, so that the changed and the new customized transformers
+// and their scripts are loaded again, instead of the ones initialized by the previous plan. The customizations are copied again by CreatePlan.
+func replan(ctx context.Context, plan plantypes.Plan, outputPath string, transformerSelector string) (plantypes.Plan, error) {
+	transformer.Destroy()
+	transformer.Reset()
+	qaengine.ResetAnswers()
+	return CreatePlan(ctx, plan.Spec.SourceDir, outputPath, plan.Spec.CustomizationsDir, transformerSelector, plan.Name)
+}
+
+// transformIntoOutput transforms into a temporary directory and replicates the result into the output directory
+func transformIntoOutput(ctx context.Context, plan plantypes.Plan, preExistingPlan bool, outputPath string, transformerSelector string) error {
+	tempOutputPath := filepath.Join(common.TempPath, "watch", filepath.Base(outputPath))
+	if err := os.RemoveAll(tempOutputPath); err != nil {
+		return fmt.Errorf("failed to remove the previous temporary output directory '%s' . Error: %w", tempOutputPath, err)
+	}
+	if err := os.MkdirAll(tempOutputPath, common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the temporary output directory '%s' . Error: %w", tempOutputPath, err)
+	}
+	if err := transformer.SeedHandAuthoredFiles(outputPath, tempOutputPath); err != nil {
+		return fmt.Errorf("failed to copy the hand authored files from the output directory '%s' . Error: %w", outputPath, err)
+	}
+	var failedErr *transformer.TransformationFailedError
+	if err := Transform(ctx, plan, preExistingPlan, tempOutputPath, transformerSelector); err != nil {
+		if !errors.As(err, &failedErr) {
+			return err
+		}
+		logrus.Errorf("failed to transform some of the artifacts. Error: %q", failedErr)
+	}
+	if err := filesystem.Replicate(tempOutputPath, outputPath); err != nil {
+		return fmt.Errorf("failed to replicate the output from '%s' to '%s' . Error: %w", tempOutputPath, outputPath, err)
+	}
+	return nil
+}
+
+// addWatches watches the directory and all its sub directories, skipping the ignored directories like .git
+func addWatches(watcher *fsnotify.Watcher, dir string) error {
+	return filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir {
+			for _, dirRegExp := range common.DefaultIgnoreDirRegexps {
+				if dirRegExp.MatchString(d.Name()) {
+					return filepath.SkipDir
+				}
+			}
+		}
+		return watcher.Add(path)
+	})
+}
+
+// isIgnoredWatchPath returns true if the path is in an ignored directory, like .git, of the watched directory containing it
+func isIgnoredWatchPath(path string, watchedDirs []string) bool {
+	for _, dir := range watchedDirs {
+		if !common.IsParent(path, dir) {
+			continue
+		}
+		for p := path; p != dir && p != filepath.Dir(p); p = filepath.Dir(p) {
+			for _, dirRegExp := range common.DefaultIgnoreDirRegexps {
+				if dirRegExp.MatchString(filepath.Base(p)) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return false
+}
+
+// getAffectedServices returns the services in the plan having any of the paths
+func getAffectedServices(plan plantypes.Plan, paths []string) []string {
+	affectedServices := []string{}
+	for serviceName, options := range plan.Spec.Services {
+	optionsLoop:
+		for _, option := range options {
+			for _, servicePaths := range option.Paths {
+				for _, servicePath := range servicePaths {
+					for _, path := range paths {
+						if common.IsParent(path, servicePath) {
+							affectedServices = append(affectedServices, serviceName)
+							break optionsLoop
+						}
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(affectedServices)
+	return affectedServices
+}
+
+// isPlannedFile returns true if the path is a file, not a directory, which the services in the plan were detected from
+func isPlannedFile(plan plantypes.Plan, path string) bool {
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return false
+	}
+	for _, options := range plan.Spec.Services {
+		for _, option := range options {
+			for _, servicePaths := range option.Paths {
+				if common.IsPresent(servicePaths, path) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package lib
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer"
+)
+
+const watchTestTransformerYaml = `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: Transformer
+metadata:
+  name: WatchTestDetector
+spec:
+  class: "Starlark"
+  directoryDetect:
+    levels: 1
+  config:
+    starFile: "detect.star"
+`
+
+// getWatchTestStarScript returns a script detecting the service with the name in the source directory
+func getWatchTestStarScript(serviceName string) string {
+	return `def directory_detect(dir):
+    return {"` + serviceName + `": [{"paths": {"ServiceDirPath": [dir]}}]}
+
+def transform(new_artifacts, old_artifacts):
+    return {}
+`
+}
+
+func TestReplanReloadsTheCustomizations(t *testing.T) {
+	tempPath, err := setupAssets()
+	if err != nil {
+		t.Fatalf("failed to set up the assets. Error: %q", err)
+	}
+	defer os.RemoveAll(tempPath)
+	qaengine.Reset()
+	defer qaengine.Reset()
+	transformer.Reset()
+	defer transformer.Reset()
+	defer transformer.Destroy()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{common.TransformerSelectorKey + `="move2kube.konveyor.io/built-in notin (true)"`}, nil, nil, false)
+
+	sourcePath := t.TempDir()
+	customizationsPath := filepath.Join(t.TempDir(), "customizations")
+	transformerPath := filepath.Join(customizationsPath, "watchtest")
+	if err := os.MkdirAll(transformerPath, 0755); err != nil {
+		t.Fatalf("failed to create the customizations directory. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(transformerPath, "transformer.yaml"), []byte(watchTestTransformerYaml), 0644); err != nil {
+		t.Fatalf("failed to write the transformer yaml. Error: %q", err)
+	}
+	writeScript := func(serviceName string) {
+		if err := os.WriteFile(filepath.Join(transformerPath, "detect.star"), []byte(getWatchTestStarScript(serviceName)), 0644); err != nil {
+			t.Fatalf("failed to write the starlark script. Error: %q", err)
+		}
+	}
+	writeScript("first")
+	plan, err := CreatePlan(context.Background(), sourcePath, t.TempDir(), customizationsPath, "", "watchtest")
+	if err != nil {
+		t.Fatalf("failed to create the plan. Error: %q", err)
+	}
+	if _, ok := plan.Spec.Services["first"]; !ok {
+		t.Fatalf("expected the plan to have the service detected by the customization, got %+v", plan.Spec.Services)
+	}
+
+	writeScript("second")
+	newPlan, err := replan(context.Background(), plan, t.TempDir(), "")
+	if err != nil {
+		t.Fatalf("failed to plan again. Error: %q", err)
+	}
+	if _, ok := newPlan.Spec.Services["second"]; !ok || len(newPlan.Spec.Services) != 1 {
+		t.Fatalf("expected the plan to have only the service detected by the edited customization, got %+v", newPlan.Spec.Services)
+	}
+}
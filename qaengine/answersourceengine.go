@@ -0,0 +1,278 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// EnvAnswerSource resolves the answers from the environment variables
+	EnvAnswerSource = "env"
+	// VaultAnswerSource resolves the answers from a secret in HashiCorp Vault
+	VaultAnswerSource = "vault"
+	// DefaultEnvAnswerPrefix is the prefix of the environment variables containing the answers
+	DefaultEnvAnswerPrefix = "M2K_ANSWER_"
+
+	vaultRequestTimeout = 30 * time.Second
+)
+
+var (
+	nonAlphanumericRegex = regexp.MustCompile(`[^A-Za-z0-9]+`)
+)
+
+// AnswerSource resolves the answers of the questions using their IDs
+type AnswerSource interface {
+	// Load reads the answers, it is called once before any of the answers are looked up
+	Load() error
+	// Lookup returns the answer of the question as a string and whether the source has an answer for it
+	Lookup(id string) (string, bool)
+}
+
+// AnswerSourceEngine answers the questions from an answer source, like the environment variables or a secrets backend.
+// The answers are not persisted in the config and cache files, so that the secrets do not end up on the disk.
+type AnswerSourceEngine struct {
+	name   string
+	source AnswerSource
+	// invalidAnswers are the questions whose invalid answers were already reported, since the questions can be asked many times
+	invalidAnswers map[string]bool
+}
+
+// NewAnswerSourceEngine creates an engine from the answer source specification.
+// Supported specifications: env, env:<prefix> and vault:<secret path>
+func NewAnswerSourceEngine(spec string) (*AnswerSourceEngine, error) {
+	kind, arg, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	switch kind {
+	case EnvAnswerSource:
+		if arg == "" {
+			arg = DefaultEnvAnswerPrefix
+		}
+		return &AnswerSourceEngine{name: spec, source: &envAnswerSource{prefix: arg}, invalidAnswers: map[string]bool{}}, nil
+	case VaultAnswerSource:
+		if arg == "" {
+			return nil, fmt.Errorf("the path of the secret is missing in the answer source %s . Example: vault:secret/data/move2kube", spec)
+		}
+		return &AnswerSourceEngine{name: spec, source: &vaultAnswerSource{path: strings.Trim(arg, "/")}, invalidAnswers: map[string]bool{}}, nil
+	}
+	return nil, fmt.Errorf("the answer source %s is not supported. Supported answer sources: %s, %s:<prefix>, %s:<secret path>", spec, EnvAnswerSource, EnvAnswerSource, VaultAnswerSource)
+}
+
+// AddAnswerSources adds the engines of the answer sources, with a higher priority than the config and cache files.
+// Earlier sources take precedence over later sources.
+func AddAnswerSources(specs ...string) error {
+	sourceEngines := []*AnswerSourceEngine{}
+	for _, spec := range specs {
+		e, err := NewAnswerSourceEngine(spec)
+		if err != nil {
+			return err
+		}
+		sourceEngines = append(sourceEngines, e)
+	}
+	for i := len(sourceEngines) - 1; i >= 0; i-- {
+		if err := AddEngineHighestPriority(sourceEngines[i]); err != nil {
+			return fmt.Errorf("failed to add the answer source %s . Error: %w", sourceEngines[i].name, err)
+		}
+	}
+	return nil
+}
+
+// GetAnswerSourceKey returns the key of the question in the answer sources which can not contain the dots and quotes of the question IDs.
+// Example: move2kube.target.imageregistry."quay.io".password becomes MOVE2KUBE_TARGET_IMAGEREGISTRY_QUAY_IO_PASSWORD
+func GetAnswerSourceKey(id string) string {
+	return strings.Trim(strings.ToUpper(nonAlphanumericRegex.ReplaceAllString(id, "_")), "_")
+}
+
+// String returns the specification of the answer source, so that the loaded answers are never printed
+func (e *AnswerSourceEngine) String() string {
+	return e.name
+}
+
+// StartEngine loads the answers from the source
+func (e *AnswerSourceEngine) StartEngine() error {
+	return e.source.Load()
+}
+
+// IsInteractiveEngine returns true if the engine interacts with the user
+func (*AnswerSourceEngine) IsInteractiveEngine() bool {
+	return false
+}
+
+// FetchAnswer fetches the answer from the source, converting it to the type of the question
+func (e *AnswerSourceEngine) FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+	value, ok := e.source.Lookup(prob.ID)
+	if !ok {
+		return prob, fmt.Errorf("the answer source %s has no answer for the question %s", e.name, prob.ID)
+	}
+	problem, err := setAnswerFromString(prob, value)
+	if err != nil {
+		err = fmt.Errorf("the answer of the question %s from the answer source %s is invalid. Error: %w", prob.ID, e.name, err)
+		if e.invalidAnswers[prob.ID] {
+			return prob, err
+		}
+		e.invalidAnswers[prob.ID] = true
+		// the invalid answers are reported, instead of being silently ignored like the answers the source does not have
+		return prob, &qatypes.ValidationError{Reason: err.Error()}
+	}
+	prob = problem
+	logrus.Debugf("Answered the question %s using the answer source %s", prob.ID, e.name)
+	return prob, nil
+}
+
+// setAnswerFromString converts the answer to the type of the question and sets it
+func setAnswerFromString(prob qatypes.Problem, value string) (qatypes.Problem, error) {
+	var answer interface{} = value
+	switch prob.Type {
+	case qatypes.ConfirmSolutionFormType:
+		boolAnswer, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return prob, fmt.Errorf("the answer '%s' is not a boolean", value)
+		}
+		answer = boolAnswer
+	case qatypes.MultiSelectSolutionFormType:
+		answer = parseListAnswer(value)
+	}
+	if err := prob.SetAnswer(answer, true); err != nil {
+		return prob, err
+	}
+	return prob, nil
+}
+
+// parseListAnswer parses the answer of a multi select question, given either as a json array or as comma separated values
+func parseListAnswer(value string) []string {
+	list := []string{}
+	if err := json.Unmarshal([]byte(value), &list); err == nil {
+		return list
+	}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// envAnswerSource looks up the answers in the environment variables named after the prefix and the key of the question
+type envAnswerSource struct {
+	prefix string
+}
+
+func (*envAnswerSource) Load() error {
+	return nil
+}
+
+func (s *envAnswerSource) Lookup(id string) (string, bool) {
+	return os.LookupEnv(s.prefix + GetAnswerSourceKey(id))
+}
+
+// vaultAnswerSource reads the answers from a secret in HashiCorp Vault, using the same environment variables as the vault CLI.
+// The keys of the secret are either the question IDs or their answer source keys.
+type vaultAnswerSource struct {
+	path    string
+	answers map[string]string
+}
+
+func (s *vaultAnswerSource) Load() error {
+	address := strings.TrimRight(os.Getenv("VAULT_ADDR"), "/")
+	if address == "" {
+		return fmt.Errorf("the VAULT_ADDR environment variable is not set")
+	}
+	token := os.Getenv("VAULT_TOKEN")
+	if token == "" {
+		homeDir, err := os.UserHomeDir()
+		if err == nil {
+			if data, err := os.ReadFile(filepath.Join(homeDir, ".vault-token")); err == nil {
+				token = strings.TrimSpace(string(data))
+			}
+		}
+		if token == "" {
+			return fmt.Errorf("the VAULT_TOKEN environment variable is not set and the token helper file ~/.vault-token was not found. Login using vault login")
+		}
+	}
+	secretURL := address + "/v1/" + s.path
+	req, err := http.NewRequest(http.MethodGet, secretURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create the request for the secret at %s . Error: %w", secretURL, err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if namespace := os.Getenv("VAULT_NAMESPACE"); namespace != "" {
+		req.Header.Set("X-Vault-Namespace", namespace)
+	}
+	client := &http.Client{Timeout: vaultRequestTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to read the secret at %s . Error: %w", secretURL, err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read the response for the secret at %s . Error: %w", secretURL, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to read the secret at %s . Status: %s", secretURL, resp.Status)
+	}
+	secret := struct {
+		Data map[string]interface{} `json:"data"`
+	}{}
+	if err := json.Unmarshal(body, &secret); err != nil {
+		return fmt.Errorf("failed to unmarshal the secret at %s . Error: %w", secretURL, err)
+	}
+	data := secret.Data
+	// the KV version 2 secrets engine nests the key value pairs along with the metadata of the secret
+	if nestedData, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = nestedData
+		}
+	}
+	s.answers = map[string]string{}
+	for key, value := range data {
+		switch value := value.(type) {
+		case string:
+			s.answers[key] = value
+		case nil:
+			continue
+		default:
+			valueBytes, err := json.Marshal(value)
+			if err != nil {
+				logrus.Warnf("Ignoring the key %s of the secret at %s . Error: %q", key, secretURL, err)
+				continue
+			}
+			s.answers[key] = string(valueBytes)
+		}
+	}
+	logrus.Infof("Loaded %d answers from the secret at %s", len(s.answers), secretURL)
+	return nil
+}
+
+func (s *vaultAnswerSource) Lookup(id string) (string, bool) {
+	if value, ok := s.answers[id]; ok {
+		return value, true
+	}
+	value, ok := s.answers[GetAnswerSourceKey(id)]
+	return value, ok
+}
@@ -0,0 +1,239 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+)
+
+// recordingStore records the solutions added to it, to check which answers would be persisted
+type recordingStore struct {
+	solutions []qatypes.Problem
+}
+
+func (*recordingStore) Load() error { return nil }
+func (*recordingStore) GetSolution(p qatypes.Problem) (qatypes.Problem, error) {
+	return p, &qatypes.ValidationError{Reason: "not found"}
+}
+func (*recordingStore) Write() error { return nil }
+func (s *recordingStore) AddSolution(p qatypes.Problem) error {
+	s.solutions = append(s.solutions, p)
+	return nil
+}
+
+func TestGetAnswerSourceKey(t *testing.T) {
+	testCases := []struct {
+		id   string
+		want string
+	}{
+		{id: "move2kube.minreplicas", want: "MOVE2KUBE_MINREPLICAS"},
+		{id: `move2kube.target.imageregistry."quay.io".password`, want: "MOVE2KUBE_TARGET_IMAGEREGISTRY_QUAY_IO_PASSWORD"},
+		{id: `move2kube.services."my-svc".ports`, want: "MOVE2KUBE_SERVICES_MY_SVC_PORTS"},
+		{id: "move2kube..trailing.", want: "MOVE2KUBE_TRAILING"},
+	}
+	for _, testCase := range testCases {
+		if got := GetAnswerSourceKey(testCase.id); got != testCase.want {
+			t.Errorf("GetAnswerSourceKey(%q) = %q, want %q", testCase.id, got, testCase.want)
+		}
+	}
+}
+
+func TestNewAnswerSourceEngine(t *testing.T) {
+	testCases := []struct {
+		spec    string
+		wantErr bool
+	}{
+		{spec: "env"},
+		{spec: "env:MY_PREFIX_"},
+		{spec: "vault:secret/data/move2kube"},
+		{spec: "vault:", wantErr: true},
+		{spec: "file:answers.txt", wantErr: true},
+	}
+	for _, testCase := range testCases {
+		_, err := NewAnswerSourceEngine(testCase.spec)
+		if (err != nil) != testCase.wantErr {
+			t.Errorf("NewAnswerSourceEngine(%q) returned the error %v, want an error: %v", testCase.spec, err, testCase.wantErr)
+		}
+	}
+}
+
+func TestEnvAnswerSource(t *testing.T) {
+	t.Setenv("M2K_ANSWER_MOVE2KUBE_TEST_INPUT", "from-env")
+	t.Setenv("M2K_ANSWER_MOVE2KUBE_TEST_CONFIRM", "maybe")
+	t.Setenv("M2K_ANSWER_MOVE2KUBE_TEST_SELECT", "Option B")
+	t.Setenv("M2K_ANSWER_MOVE2KUBE_TEST_MULTISELECT", "a, c")
+	t.Setenv("M2K_ANSWER_MOVE2KUBE_TEST_JSONMULTISELECT", `["b","c"]`)
+	t.Setenv("CUSTOM_MOVE2KUBE_TEST_INPUT", "from-custom-prefix")
+
+	setup := func(t *testing.T, specs ...string) {
+		engines = []Engine{}
+		AddEngine(NewDefaultEngine())
+		if err := AddAnswerSources(specs...); err != nil {
+			t.Fatalf("failed to add the answer sources %+v . Error: %q", specs, err)
+		}
+	}
+
+	testCases := []struct {
+		name  string
+		specs []string
+		fetch func() interface{}
+		want  interface{}
+	}{
+		{
+			name:  "input answered from the default prefix",
+			specs: []string{"env"},
+			fetch: func() interface{} { return FetchStringAnswer("move2kube.test.input", "Input :", nil, "default", nil) },
+			want:  "from-env",
+		},
+		{
+			name:  "earlier sources take precedence",
+			specs: []string{"env:CUSTOM_", "env"},
+			fetch: func() interface{} { return FetchStringAnswer("move2kube.test.input", "Input :", nil, "default", nil) },
+			want:  "from-custom-prefix",
+		},
+		{
+			name:  "invalid boolean falls back to the next engine",
+			specs: []string{"env"},
+			fetch: func() interface{} { return FetchBoolAnswer("move2kube.test.confirm", "Confirm :", nil, true, nil) },
+			want:  true,
+		},
+		{
+			name:  "select answered with a valid option",
+			specs: []string{"env"},
+			fetch: func() interface{} {
+				return FetchSelectAnswer("move2kube.test.select", "Select :", nil, "Option A", []string{"Option A", "Option B"}, nil)
+			},
+			want: "Option B",
+		},
+		{
+			name:  "select answer outside the options falls back to the next engine",
+			specs: []string{"env"},
+			fetch: func() interface{} {
+				return FetchSelectAnswer("move2kube.test.select", "Select :", nil, "Option A", []string{"Option A", "Option C"}, nil)
+			},
+			want: "Option A",
+		},
+		{
+			name:  "comma separated multiselect",
+			specs: []string{"env"},
+			fetch: func() interface{} {
+				return strings.Join(FetchMultiSelectAnswer("move2kube.test.multiselect", "Multiselect :", nil, []string{"b"}, []string{"a", "b", "c"}, nil), ",")
+			},
+			want: "a,c",
+		},
+		{
+			name:  "json multiselect",
+			specs: []string{"env"},
+			fetch: func() interface{} {
+				return strings.Join(FetchMultiSelectAnswer("move2kube.test.jsonmultiselect", "Multiselect :", nil, []string{"a"}, []string{"a", "b", "c"}, nil), ",")
+			},
+			want: "b,c",
+		},
+		{
+			name:  "missing answer falls back to the next engine",
+			specs: []string{"env"},
+			fetch: func() interface{} { return FetchStringAnswer("move2kube.test.missing", "Input :", nil, "default", nil) },
+			want:  "default",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			setup(t, testCase.specs...)
+			if got := testCase.fetch(); got != testCase.want {
+				t.Fatalf("got the answer %+v , want %+v", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestAnswerSourcePasswordIsNotPersisted(t *testing.T) {
+	password := "s3cr3t-registry-password"
+	t.Setenv("M2K_ANSWER_MOVE2KUBE_TEST_PASSWORD", password)
+	engines = []Engine{}
+	store := &recordingStore{}
+	stores = []qatypes.Store{store}
+	defer func() { stores = []qatypes.Store{} }()
+	AddEngine(NewDefaultEngine())
+	if err := AddAnswerSources("env"); err != nil {
+		t.Fatalf("failed to add the env answer source. Error: %q", err)
+	}
+	if got := FetchPasswordAnswer("move2kube.test.password", "Password :", nil, nil); got != password {
+		t.Fatalf("got the password %q , want %q", got, password)
+	}
+	if len(store.solutions) != 0 {
+		t.Fatalf("expected the answers from the answer sources not to be persisted, got %+v", store.solutions)
+	}
+	if got := GetAnswers()["move2kube.test.password"]; got != common.RedactedValue {
+		t.Fatalf("expected the password to be redacted in the recorded answers, got %+v", got)
+	}
+	if redacted := common.RedactSecrets("login with " + password); strings.Contains(redacted, password) {
+		t.Fatalf("expected the password to be redacted in the logs, got %q", redacted)
+	}
+	if got := FetchStringAnswer("move2kube.test.notfromsource", "Input :", nil, "default", nil); got != "default" {
+		t.Fatalf("got the answer %q , want the default", got)
+	}
+	if len(store.solutions) != 1 || store.solutions[0].ID != "move2kube.test.notfromsource" {
+		t.Fatalf("expected only the answer from the other engines to be persisted, got %+v", store.solutions)
+	}
+}
+
+func TestVaultAnswerSource(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "test-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/v1/secret/data/move2kube" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// the KV version 2 secrets engine nests the key value pairs
+		w.Write([]byte(`{"data":{"data":{"MOVE2KUBE_TEST_INPUT":"from-vault","move2kube.test.replicas":3},"metadata":{"version":1}}}`))
+	}))
+	defer server.Close()
+	t.Setenv("VAULT_ADDR", server.URL)
+
+	t.Run("answers from the secret", func(t *testing.T) {
+		t.Setenv("VAULT_TOKEN", "test-token")
+		engines = []Engine{}
+		AddEngine(NewDefaultEngine())
+		if err := AddAnswerSources("vault:secret/data/move2kube"); err != nil {
+			t.Fatalf("failed to add the vault answer source. Error: %q", err)
+		}
+		if got := FetchStringAnswer("move2kube.test.input", "Input :", nil, "default", nil); got != "from-vault" {
+			t.Fatalf("got the answer %q , want the answer from the secret", got)
+		}
+		if got := FetchStringAnswer("move2kube.test.replicas", "Replicas :", nil, "1", nil); got != "3" {
+			t.Fatalf("got the answer %q , want the number in the secret", got)
+		}
+	})
+
+	t.Run("invalid token", func(t *testing.T) {
+		t.Setenv("VAULT_TOKEN", "wrong-token")
+		t.Setenv("HOME", t.TempDir())
+		engines = []Engine{}
+		if err := AddAnswerSources("vault:secret/data/move2kube"); err == nil {
+			t.Fatalf("expected an error for the invalid token")
+		}
+	})
+}
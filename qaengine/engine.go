@@ -17,12 +17,20 @@
 package qaengine
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"net"
 	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/deepcopy"
 	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/phayes/freeport"
 	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
 )
 
 // Engine defines interface for qa engines
@@ -36,15 +44,59 @@ var (
 	engines       []Engine
 	stores        []qatypes.Store
 	defaultEngine = NewDefaultEngine()
+	strictEngine  *StrictEngine
+	// answers stores the answer of each question, used to record the digest of the answers in the provenance
+	answers      = map[string]interface{}{}
+	answersMutex sync.Mutex
+	// listenHost is the host the REST and GRPC engines listen on, all the interfaces when it is empty
+	listenHost string
+	// inheritedListener is the listener passed by the parent process, which the REST or GRPC engine serves on instead of listening on a port
+	inheritedListener net.Listener
 )
 
+// SetListenHost sets the host the REST and GRPC engines listen on, like 127.0.0.1 to only accept the connections from the same machine
+func SetListenHost(host string) {
+	listenHost = host
+}
+
+// SetListener makes the REST or GRPC engine serve on the listener, which the parent process already bound to a free port,
+// so that no other process can take the port between it being found and the engine listening on it
+func SetListener(listener net.Listener) {
+	inheritedListener = listener
+}
+
+// listen returns the listener passed by the parent process, or listens on the port of the listen host
+func listen(port int) (net.Listener, error) {
+	if inheritedListener != nil {
+		listener := inheritedListener
+		inheritedListener = nil
+		return listener, nil
+	}
+	if port == 0 {
+		var err error
+		if port, err = freeport.GetFreePort(); err != nil {
+			return nil, fmt.Errorf("unable to find a free port : %s", err)
+		}
+	}
+	listener, err := net.Listen("tcp", net.JoinHostPort(listenHost, cast.ToString(port)))
+	if err != nil {
+		return nil, fmt.Errorf("unable to listen on port %d : %s", port, err)
+	}
+	return listener, nil
+}
+
 // StartEngine starts the QA Engines
-func StartEngine(qaskip bool, qaport int, qadisablecli bool) {
+func StartEngine(qaskip bool, qastrict bool, qaport int, qadisablecli bool, qagrpc bool) {
 	var e Engine
-	if qaskip {
+	if qastrict {
+		strictEngine = NewStrictEngine()
+		e = strictEngine
+	} else if qaskip {
 		e = NewDefaultEngine()
 	} else if !qadisablecli {
 		e = NewCliEngine()
+	} else if qagrpc {
+		e = NewGRPCEngine(qaport)
 	} else {
 		e = NewHTTPRESTEngine(qaport)
 	}
@@ -113,19 +165,29 @@ func SetupConfigFile(writeConfigFile string, configStrings, configFiles, presets
 func FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 	logrus.Trace("FetchAnswer start")
 	defer logrus.Trace("FetchAnswer end")
+	addPasswordToRedact(prob)
 	logrus.Debugf("Fetching answer for the problem: %#v", prob)
 	if prob.Answer != nil {
 		logrus.Debugf("Problem already solved.")
 		return prob, nil
 	}
 	var err error
+	fromAnswerSource := false
 	logrus.Debug("looping through the engines to try and fetch the answer")
 	for _, engine := range engines {
 		logrus.Debugf("engine '%T'", engine)
 		if prob.Desc == "" && engine.IsInteractiveEngine() {
 			return defaultEngine.FetchAnswer(prob)
 		}
+		if engine.IsInteractiveEngine() && !isAskedInProfile(prob.ID) {
+			logrus.Debugf("the question %s is not asked in the %s profile, using the default answer", prob.ID, profile)
+			if defaultProb, defaultErr := defaultEngine.FetchAnswer(prob); defaultErr == nil && defaultProb.Answer != nil {
+				prob, err = defaultProb, nil
+				break
+			}
+		}
 		prob, err = engine.FetchAnswer(prob)
+		addPasswordToRedact(prob)
 		if err != nil {
 			if _, ok := err.(*qatypes.ValidationError); ok {
 				logrus.Errorf("failed to fetch the answer using the engine '%T' . Error: %q", engine, err)
@@ -135,6 +197,7 @@ func FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 			continue
 		}
 		if prob.Answer != nil {
+			_, fromAnswerSource = engine.(*AnswerSourceEngine)
 			prob = changeSelectToInputForOther(prob)
 			break
 		}
@@ -152,6 +215,7 @@ func FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 		}
 		for err != nil || prob.Answer == nil {
 			prob, err = lastEngine.FetchAnswer(prob)
+			addPasswordToRedact(prob)
 			if err != nil {
 				logrus.Errorf("failed to fetch the answer for the problem: '%s' , trying again. Error: %q", prob.Desc, err)
 				continue
@@ -161,12 +225,83 @@ func FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
 			}
 		}
 	}
+	if strictEngine != nil && strictEngine.isUnanswered(prob) {
+		// placeholder answers should not be persisted
+		return prob, err
+	}
+	answersMutex.Lock()
+	if prob.Type == qatypes.PasswordSolutionFormType {
+		answers[prob.ID] = common.RedactedValue
+	} else {
+		answers[prob.ID] = prob.Answer
+	}
+	answersMutex.Unlock()
+	if fromAnswerSource {
+		// the answers are resolved again from the answer sources in the next run
+		return prob, err
+	}
 	for _, store := range stores {
 		store.AddSolution(prob)
 	}
 	return prob, err
 }
 
+// GetAnswersDigest returns the sha256 digest of the answers given during the run. The password answers are excluded.
+func GetAnswersDigest() string {
+	answersMutex.Lock()
+	data, err := json.Marshal(answers)
+	answersMutex.Unlock()
+	if err != nil {
+		logrus.Errorf("failed to marshal the answers to json. Error: %q", err)
+		return ""
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(data))
+}
+
+// GetAnswers returns a copy of the answers given so far during the run. The password answers are redacted.
+func GetAnswers() map[string]interface{} {
+	answersMutex.Lock()
+	defer answersMutex.Unlock()
+	answersCopy := make(map[string]interface{}, len(answers))
+	for id, answer := range answers {
+		answersCopy[id] = answer
+	}
+	return answersCopy
+}
+
+// Reset removes the engines and the stores set up for a previous run, along with its answers
+func Reset() {
+	engines = []Engine{}
+	stores = []qatypes.Store{}
+	strictEngine = nil
+	ResetAnswers()
+}
+
+// ResetAnswers clears the answers recorded during a previous run
+func ResetAnswers() {
+	answersMutex.Lock()
+	defer answersMutex.Unlock()
+	answers = map[string]interface{}{}
+}
+
+// addPasswordToRedact masks the answers of the password questions in the logs and reports
+func addPasswordToRedact(prob qatypes.Problem) {
+	if prob.Type != qatypes.PasswordSolutionFormType {
+		return
+	}
+	if answer, ok := prob.Answer.(string); ok {
+		common.AddSecretValues(answer)
+	}
+}
+
+// GetUnansweredProblems returns the questions that had no default answer when running in strict mode
+func GetUnansweredProblems() []qatypes.Problem {
+	if strictEngine == nil {
+		return nil
+	}
+	return strictEngine.GetUnansweredProblems()
+}
+
 // WriteStoresToDisk forces all the stores to write their contents out to disk
 func WriteStoresToDisk() error {
 	var err error
@@ -195,6 +330,43 @@ func changeSelectToInputForOther(prob qatypes.Problem) qatypes.Problem {
 	return prob
 }
 
+// resolveOtherAnswer asks for the values of the "Other" option of a multi select problem as a multiline input
+// using the given function and replaces the "Other" option in the answer with those values
+func resolveOtherAnswer(prob qatypes.Problem, ask func(qatypes.Problem) qatypes.Problem) (qatypes.Problem, error) {
+	if prob.Type != qatypes.MultiSelectSolutionFormType {
+		return prob, nil
+	}
+	otherAnsPresent := false
+	ans, err := common.ConvertInterfaceToSliceOfStrings(prob.Answer)
+	if err != nil {
+		return prob, fmt.Errorf("failed to convert the answer from an interface to a slice of strings. Error: %w", err)
+	}
+	newAns := []string{}
+	for _, a := range ans {
+		if a == qatypes.OtherAnswer {
+			otherAnsPresent = true
+		} else {
+			newAns = append(newAns, a)
+		}
+	}
+	if otherAnsPresent {
+		multilineAns := ""
+		multilineProb := deepcopy.DeepCopy(prob).(qatypes.Problem)
+		multilineProb.Type = qatypes.MultilineInputSolutionFormType
+		multilineProb.Default = ""
+		multilineProb = ask(multilineProb)
+		multilineAns = multilineProb.Answer.(string)
+		for _, lineAns := range strings.Split(multilineAns, "\n") {
+			lineAns = strings.TrimSpace(lineAns)
+			if lineAns != "" {
+				newAns = common.AppendIfNotPresent(newAns, lineAns)
+			}
+		}
+	}
+	prob.Answer = newAns
+	return prob, nil
+}
+
 // Convenience functions
 
 // FetchStringAnswer asks a input type question and gets a string as the answer
@@ -17,6 +17,9 @@
 package qaengine
 
 import (
+	"fmt"
+	"net"
+	"sync"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -46,3 +49,60 @@ func TestEngine(t *testing.T) {
 	})
 
 }
+
+func TestAnswers(t *testing.T) {
+	engines = []Engine{}
+	AddEngine(NewDefaultEngine())
+	ResetAnswers()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			FetchStringAnswer(fmt.Sprintf("move2kube.test.concurrent%d", i), "Input :", nil, "default", nil)
+			GetAnswersDigest()
+		}(i)
+	}
+	wg.Wait()
+	if got := len(GetAnswers()); got != 20 {
+		t.Fatalf("expected the answers of the 20 questions to be recorded, got %d", got)
+	}
+
+	digest := GetAnswersDigest()
+	ResetAnswers()
+	if got := GetAnswers(); len(got) != 0 {
+		t.Fatalf("expected the answers of the previous run to be cleared, got %+v", got)
+	}
+	if GetAnswersDigest() == digest {
+		t.Fatalf("expected the digest to change after the answers were cleared")
+	}
+}
+
+func TestListen(t *testing.T) {
+	t.Cleanup(func() { SetListenHost("") })
+	SetListenHost("127.0.0.1")
+	listener, err := listen(0)
+	if err != nil {
+		t.Fatalf("failed to listen on a free port. Error: %q", err)
+	}
+	defer listener.Close()
+	if addr := listener.Addr().(*net.TCPAddr); !addr.IP.IsLoopback() || addr.Port == 0 {
+		t.Fatalf("expected a free port of the listen host 127.0.0.1 , got %s", addr)
+	}
+
+	inherited, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen on a free port. Error: %q", err)
+	}
+	defer inherited.Close()
+	SetListener(inherited)
+	// the port is ignored when the listener was passed by the parent process
+	if got, err := listen(1); err != nil || got != inherited {
+		t.Fatalf("expected the listener passed by the parent process, got %v . Error: %v", got, err)
+	}
+	// the listener is only used by the first engine which listens
+	if _, err := listen(inherited.Addr().(*net.TCPAddr).Port); err == nil {
+		t.Fatalf("expected an error listening on the port which is already taken")
+	}
+}
@@ -0,0 +1,168 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"sync"
+
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	qagrpc "github.com/konveyor/move2kube/types/qaengine/qagrpc"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// GRPCEngine handles qa using a GRPC bidirectional stream
+type GRPCEngine struct {
+	qagrpc.UnimplementedQAStreamServer
+	port int
+	// streamMutex guards streaming. Only one client at a time answers the questions,
+	// so the current problem is only used by the stream of that client.
+	streamMutex    sync.Mutex
+	streaming      bool
+	currentProblem qatypes.Problem
+	problemChan    chan qatypes.Problem
+	answerChan     chan qatypes.Problem
+}
+
+// NewGRPCEngine creates a new instance of GRPC engine
+func NewGRPCEngine(qaport int) Engine {
+	return &GRPCEngine{
+		port:           qaport,
+		currentProblem: qatypes.Problem{ID: "", Answer: ""},
+		problemChan:    make(chan qatypes.Problem),
+		answerChan:     make(chan qatypes.Problem),
+	}
+}
+
+// StartEngine starts the QA Engine
+func (g *GRPCEngine) StartEngine() error {
+	listener, err := listen(g.port)
+	if err != nil {
+		return err
+	}
+	g.port = listener.Addr().(*net.TCPAddr).Port
+	qaportstr := cast.ToString(g.port)
+	s := grpc.NewServer()
+	qagrpc.RegisterQAStreamServer(s, g)
+	reflection.Register(s)
+	go func(listener net.Listener) {
+		err := s.Serve(listener)
+		if err != nil {
+			logrus.Fatalf("Unable to start qa server : %s", err)
+		}
+	}(listener)
+	logrus.Info("Started QA GRPC engine on: localhost:" + qaportstr)
+	return nil
+}
+
+// IsInteractiveEngine returns true if the engine interacts with the user
+func (*GRPCEngine) IsInteractiveEngine() bool {
+	return true
+}
+
+// FetchAnswer fetches the answer using the GRPC stream
+func (g *GRPCEngine) FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+	logrus.Trace("GRPCEngine.FetchAnswer start")
+	defer logrus.Trace("GRPCEngine.FetchAnswer end")
+	if err := ValidateProblem(prob); err != nil {
+		return prob, fmt.Errorf("the QA problem object is invalid. Error: %w", err)
+	}
+	if prob.Answer != nil {
+		return prob, nil
+	}
+	if _, err := qatypes.NewGRPCProblem(prob); err != nil {
+		return prob, fmt.Errorf("failed to convert the problem to a GRPC problem. Error: %w", err)
+	}
+	logrus.Debugf("Passing problem to GRPC QA Engine ID: '%s' desc: '%s'", prob.ID, prob.Desc)
+	g.problemChan <- prob
+	prob = <-g.answerChan
+	logrus.Debugf("received a solution from the problem channel: %+v", prob)
+	if prob.Answer == nil {
+		return prob, fmt.Errorf("failed to resolve the QA problem: %+v", prob)
+	}
+	return resolveOtherAnswer(prob, func(p qatypes.Problem) qatypes.Problem {
+		g.problemChan <- p
+		return <-g.answerChan
+	})
+}
+
+// Stream sends the questions to the client and receives the solutions.
+// If the client disconnects, the unanswered question is sent again to the next client.
+// The clients connecting while another client is answering are rejected.
+func (g *GRPCEngine) Stream(stream qagrpc.QAStream_StreamServer) error {
+	g.streamMutex.Lock()
+	if g.streaming {
+		g.streamMutex.Unlock()
+		return status.Error(codes.FailedPrecondition, "another client is already answering the questions")
+	}
+	g.streaming = true
+	g.streamMutex.Unlock()
+	defer func() {
+		g.streamMutex.Lock()
+		g.streaming = false
+		g.streamMutex.Unlock()
+	}()
+	validationError := ""
+	for {
+		if g.currentProblem.Answer != nil || g.currentProblem.ID == "" {
+			select {
+			case g.currentProblem = <-g.problemChan:
+			case <-stream.Context().Done():
+				return stream.Context().Err()
+			}
+		}
+		gprob, err := qatypes.NewGRPCProblem(g.currentProblem)
+		if err != nil {
+			return fmt.Errorf("failed to convert the problem %+v to a GRPC problem. Error: %w", g.currentProblem, err)
+		}
+		logrus.Debugf("QA Engine serves problem id: '%s' desc: '%s'", g.currentProblem.ID, g.currentProblem.Desc)
+		if err := stream.Send(&qagrpc.Question{Problem: gprob, ValidationError: validationError}); err != nil {
+			return fmt.Errorf("failed to send the question. Error: %w", err)
+		}
+		solution, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to receive the solution. Error: %w", err)
+		}
+		logrus.Debugf("QA Engine received the solution: %+v", solution)
+		if solution.Id != g.currentProblem.ID {
+			validationError = fmt.Sprintf("the solution's problem ID doesn't match the current problem. Expected: '%s' Actual '%s'", g.currentProblem.ID, solution.Id)
+			logrus.Error(validationError)
+			continue
+		}
+		ans, err := qatypes.ArrayToInterface(solution.Answer, g.currentProblem.Type)
+		if err == nil {
+			err = g.currentProblem.SetAnswer(ans, true)
+		}
+		if err != nil {
+			validationError = fmt.Sprintf("failed to set the given solution as the answer. Error: %s", err)
+			logrus.Error(validationError)
+			continue
+		}
+		validationError = ""
+		g.answerChan <- g.currentProblem
+	}
+}
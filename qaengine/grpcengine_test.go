@@ -0,0 +1,104 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	qagrpc "github.com/konveyor/move2kube/types/qaengine/qagrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// fakeQAStream answers every question it is sent with the answer, until its context is cancelled
+type fakeQAStream struct {
+	grpc.ServerStream
+	ctx       context.Context
+	answer    string
+	questions chan *qagrpc.Question
+}
+
+func (s *fakeQAStream) Context() context.Context { return s.ctx }
+func (s *fakeQAStream) Send(q *qagrpc.Question) error {
+	s.questions <- q
+	return nil
+}
+func (s *fakeQAStream) Recv() (*qagrpc.Solution, error) {
+	select {
+	case q := <-s.questions:
+		return &qagrpc.Solution{Id: q.Problem.Id, Answer: []string{s.answer}}, nil
+	case <-s.ctx.Done():
+		return nil, s.ctx.Err()
+	}
+}
+
+func TestGRPCEngineReturnsTheConversionError(t *testing.T) {
+	g := NewGRPCEngine(0)
+	prob := qatypes.Problem{ID: "move2kube.test.password", Type: qatypes.PasswordSolutionFormType, Desc: "Password :", Default: 1234}
+	errs := make(chan error)
+	go func() {
+		_, err := g.FetchAnswer(prob)
+		errs <- err
+	}()
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Fatalf("expected an error for the problem which can not be sent to the clients")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected the error to be returned instead of waiting for a client")
+	}
+}
+
+func TestGRPCEngineRejectsConcurrentStreams(t *testing.T) {
+	g := NewGRPCEngine(0).(*GRPCEngine)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	errs := make(chan error, 2)
+	wg := sync.WaitGroup{}
+	for _, answer := range []string{"first", "second"} {
+		wg.Add(1)
+		go func(answer string) {
+			defer wg.Done()
+			errs <- g.Stream(&fakeQAStream{ctx: ctx, answer: answer, questions: make(chan *qagrpc.Question, 1)})
+		}(answer)
+	}
+	select {
+	case err := <-errs:
+		if status.Code(err) != codes.FailedPrecondition {
+			t.Fatalf("expected the second stream to be rejected, got the error %v", err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("expected one of the streams to be rejected")
+	}
+	for i := 0; i < 3; i++ {
+		prob, err := g.FetchAnswer(qatypes.Problem{ID: "move2kube.test.input", Type: qatypes.InputSolutionFormType, Desc: "Input :"})
+		if err != nil {
+			t.Fatalf("failed to fetch the answer. Error: %q", err)
+		}
+		if prob.Answer != "first" && prob.Answer != "second" {
+			t.Fatalf("got the answer %+v , want the answer of the client", prob.Answer)
+		}
+	}
+	cancel()
+	wg.Wait()
+}
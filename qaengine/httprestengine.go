@@ -21,13 +21,10 @@ import (
 	"fmt"
 	"net"
 	"net/http"
-	"strings"
+	"sync"
 
 	"github.com/gorilla/mux"
-	"github.com/konveyor/move2kube/common"
-	"github.com/konveyor/move2kube/common/deepcopy"
 	qatypes "github.com/konveyor/move2kube/types/qaengine"
-	"github.com/phayes/freeport"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
 )
@@ -38,45 +35,42 @@ type HTTPRESTEngine struct {
 	currentProblem qatypes.Problem
 	problemChan    chan qatypes.Problem
 	answerChan     chan qatypes.Problem
+	// mutex guards the current problem, which the handlers of the concurrent requests read and answer
+	mutex sync.Mutex
+	// problemSet is signalled when the next problem becomes the current problem
+	problemSet *sync.Cond
 }
 
 const (
 	problemsURLPrefix        = "/problems"
 	currentProblemURLPrefix  = problemsURLPrefix + "/current"
 	currentSolutionURLPrefix = currentProblemURLPrefix + "/solution"
+	// problemSolutionURLPrefix matches the problem IDs containing slashes too, like the IDs of the questions about paths
+	problemSolutionURLPrefix = problemsURLPrefix + "/{id:.+}/solution"
 )
 
 // NewHTTPRESTEngine creates a new instance of Http REST engine
 func NewHTTPRESTEngine(qaport int) Engine {
-	return &HTTPRESTEngine{
+	h := &HTTPRESTEngine{
 		port:           qaport,
 		currentProblem: qatypes.Problem{ID: "", Answer: ""},
 		problemChan:    make(chan qatypes.Problem),
 		answerChan:     make(chan qatypes.Problem),
 	}
+	h.problemSet = sync.NewCond(&h.mutex)
+	return h
 }
 
 // StartEngine starts the QA Engine
 func (h *HTTPRESTEngine) StartEngine() error {
-	if h.port == 0 {
-		var err error
-		h.port, err = freeport.GetFreePort()
-		if err != nil {
-			return fmt.Errorf("unable to find a free port : %s", err)
-		}
-	}
-	// Create the REST router.
-	r := mux.NewRouter()
-	r.HandleFunc(currentProblemURLPrefix, h.getQuestionHandler).Methods("GET")
-	r.HandleFunc(currentSolutionURLPrefix, h.postSolutionHandler).Methods("POST")
-
-	http.Handle("/", r)
-	qaportstr := cast.ToString(h.port)
-
-	listener, err := net.Listen("tcp", ":"+qaportstr)
+	listener, err := listen(h.port)
 	if err != nil {
-		return fmt.Errorf("unable to listen on port %d : %s", h.port, err)
+		return err
 	}
+	h.port = listener.Addr().(*net.TCPAddr).Port
+	http.Handle("/", h.newRouter())
+	go h.receiveProblems()
+	qaportstr := cast.ToString(h.port)
 	go func(listener net.Listener) {
 		err := http.Serve(listener, nil)
 		if err != nil {
@@ -87,6 +81,31 @@ func (h *HTTPRESTEngine) StartEngine() error {
 	return nil
 }
 
+// newRouter creates the REST router
+func (h *HTTPRESTEngine) newRouter() *mux.Router {
+	r := mux.NewRouter()
+	r.HandleFunc(currentProblemURLPrefix, h.getQuestionHandler).Methods("GET")
+	r.HandleFunc(currentSolutionURLPrefix, h.postSolutionHandler).Methods("POST")
+	r.HandleFunc(problemsURLPrefix, h.listQuestionsHandler).Methods("GET")
+	r.HandleFunc(problemSolutionURLPrefix, h.postSolutionHandler).Methods("POST")
+	return r
+}
+
+// receiveProblems makes each problem sent by FetchAnswer the current problem, until it is answered
+func (h *HTTPRESTEngine) receiveProblems() {
+	for prob := range h.problemChan {
+		h.mutex.Lock()
+		h.currentProblem = prob
+		h.problemSet.Broadcast()
+		h.mutex.Unlock()
+	}
+}
+
+// isPending returns true if the current problem has not been answered yet. The mutex has to be held.
+func (h *HTTPRESTEngine) isPending() bool {
+	return h.currentProblem.Answer == nil && h.currentProblem.ID != ""
+}
+
 // IsInteractiveEngine returns true if the engine interacts with the user
 func (*HTTPRESTEngine) IsInteractiveEngine() bool {
 	return true
@@ -110,39 +129,10 @@ func (h *HTTPRESTEngine) FetchAnswer(prob qatypes.Problem) (qatypes.Problem, err
 	if prob.Answer == nil {
 		return prob, fmt.Errorf("failed to resolve the QA problem: %+v", prob)
 	}
-	if prob.Type != qatypes.MultiSelectSolutionFormType {
-		return prob, nil
-	}
-	otherAnsPresent := false
-	ans, err := common.ConvertInterfaceToSliceOfStrings(prob.Answer)
-	if err != nil {
-		return prob, fmt.Errorf("failed to convert the answer from an interface to a slice of strings. Error: %w", err)
-	}
-	newAns := []string{}
-	for _, a := range ans {
-		if a == qatypes.OtherAnswer {
-			otherAnsPresent = true
-		} else {
-			newAns = append(newAns, a)
-		}
-	}
-	if otherAnsPresent {
-		multilineAns := ""
-		multilineProb := deepcopy.DeepCopy(prob).(qatypes.Problem)
-		multilineProb.Type = qatypes.MultilineInputSolutionFormType
-		multilineProb.Default = ""
-		h.problemChan <- multilineProb
-		multilineProb = <-h.answerChan
-		multilineAns = multilineProb.Answer.(string)
-		for _, lineAns := range strings.Split(multilineAns, "\n") {
-			lineAns = strings.TrimSpace(lineAns)
-			if lineAns != "" {
-				newAns = common.AppendIfNotPresent(newAns, lineAns)
-			}
-		}
-	}
-	prob.Answer = newAns
-	return prob, nil
+	return resolveOtherAnswer(prob, func(p qatypes.Problem) qatypes.Problem {
+		h.problemChan <- p
+		return <-h.answerChan
+	})
 }
 
 // getQuestionHandler blocks until it gets a question and returns it as json.
@@ -150,22 +140,44 @@ func (h *HTTPRESTEngine) getQuestionHandler(w http.ResponseWriter, r *http.Reque
 	logrus.Trace("problemHandler start")
 	defer logrus.Trace("problemHandler end")
 	logrus.Debug("Looking for a problem fron HTTP REST service")
-	// if currently problem is resolved
-	if h.currentProblem.Answer != nil || h.currentProblem.ID == "" {
-		// Pick the next problem off the channel
-		h.currentProblem = <-h.problemChan
+	h.mutex.Lock()
+	// if currently problem is resolved, wait for the next problem
+	for !h.isPending() {
+		h.problemSet.Wait()
 	}
-	logrus.Debugf("QA Engine serves problem id: '%s' desc: '%s'", h.currentProblem.ID, h.currentProblem.Desc)
+	currentProblem := h.currentProblem
+	h.mutex.Unlock()
+	logrus.Debugf("QA Engine serves problem id: '%s' desc: '%s'", currentProblem.ID, currentProblem.Desc)
 	// Send the problem to the request.
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	if err := json.NewEncoder(w).Encode(h.currentProblem); err != nil {
+	if err := json.NewEncoder(w).Encode(currentProblem); err != nil {
 		logrus.Errorf("failed to encode the current problem as json and send the response. Error: %q", err)
 		return
 	}
 }
 
+// listQuestionsHandler returns the pending questions as a json list without blocking.
+// The list is empty when the transformation is not waiting for an answer.
+func (h *HTTPRESTEngine) listQuestionsHandler(w http.ResponseWriter, r *http.Request) {
+	logrus.Trace("listQuestionsHandler start")
+	defer logrus.Trace("listQuestionsHandler end")
+	problems := []qatypes.Problem{}
+	h.mutex.Lock()
+	if h.isPending() {
+		problems = append(problems, h.currentProblem)
+	}
+	h.mutex.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(problems); err != nil {
+		logrus.Errorf("failed to encode the pending problems as json and send the response. Error: %q", err)
+		return
+	}
+}
+
 // postSolutionHandler accepts solution for the current question.
+// The problem ID is taken from the URL if present, otherwise from the request body.
 func (h *HTTPRESTEngine) postSolutionHandler(w http.ResponseWriter, r *http.Request) {
 	logrus.Trace("solutionHandler start")
 	defer logrus.Trace("solutionHandler end")
@@ -179,6 +191,17 @@ func (h *HTTPRESTEngine) postSolutionHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 	logrus.Debugf("QA Engine received the solution: %+v", prob)
+	if id, ok := mux.Vars(r)["id"]; ok {
+		prob.ID = id
+	}
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if !h.isPending() {
+		err := fmt.Errorf("there is no problem waiting for a solution")
+		http.Error(w, err.Error(), http.StatusNotAcceptable)
+		logrus.Error(err.Error())
+		return
+	}
 	if h.currentProblem.ID != prob.ID {
 		err := fmt.Errorf("the solution's problem ID doesn't match the current problem. Expected: '%s' Actual '%s'", h.currentProblem.ID, prob.ID)
 		http.Error(w, err.Error(), http.StatusNotAcceptable)
@@ -193,5 +216,6 @@ func (h *HTTPRESTEngine) postSolutionHandler(w http.ResponseWriter, r *http.Requ
 	}
 	logrus.Debugf("QA Engine set the given solution as the answer: %+v", h.currentProblem)
 	w.WriteHeader(http.StatusNoContent)
-	go func() { h.answerChan <- h.currentProblem }()
+	answeredProblem := h.currentProblem
+	go func() { h.answerChan <- answeredProblem }()
 }
@@ -0,0 +1,118 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+)
+
+func TestHTTPRESTEngine(t *testing.T) {
+	h := NewHTTPRESTEngine(0).(*HTTPRESTEngine)
+	server := httptest.NewServer(h.newRouter())
+	defer server.Close()
+	go h.receiveProblems()
+
+	listProblems := func(t *testing.T) []qatypes.Problem {
+		resp, err := http.Get(server.URL + problemsURLPrefix)
+		if err != nil {
+			t.Fatalf("failed to list the problems. Error: %q", err)
+		}
+		defer resp.Body.Close()
+		problems := []qatypes.Problem{}
+		if err := json.NewDecoder(resp.Body).Decode(&problems); err != nil {
+			t.Fatalf("failed to decode the problems. Error: %q", err)
+		}
+		return problems
+	}
+	postSolution := func(t *testing.T, id, answer string) int {
+		resp, err := http.Post(server.URL+problemsURLPrefix+"/"+url.PathEscape(id)+"/solution", "application/json", strings.NewReader(`{"answer":"`+answer+`"}`))
+		if err != nil {
+			t.Fatalf("failed to post the solution. Error: %q", err)
+		}
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	if problems := listProblems(t); len(problems) != 0 {
+		t.Fatalf("expected no pending problems, got %+v", problems)
+	}
+	if status := postSolution(t, "move2kube.test", "answer"); status != http.StatusNotAcceptable {
+		t.Fatalf("expected the solution to be rejected when no problem is pending, got the status %d", status)
+	}
+
+	id := `move2kube.services."api".dockerfile/path`
+	prob, err := qatypes.NewInputProblem(id, "Path :", nil, "", nil)
+	if err != nil {
+		t.Fatalf("failed to create the problem. Error: %q", err)
+	}
+	answered := make(chan qatypes.Problem)
+	go func() {
+		ans, err := h.FetchAnswer(prob)
+		if err != nil {
+			t.Errorf("failed to fetch the answer. Error: %q", err)
+		}
+		answered <- ans
+	}()
+
+	var problems []qatypes.Problem
+	for i := 0; i < 100 && len(problems) == 0; i++ {
+		// the problems are listed concurrently, while the problem is being set
+		var wg sync.WaitGroup
+		for j := 0; j < 5; j++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				listProblems(t)
+			}()
+		}
+		wg.Wait()
+		problems = listProblems(t)
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(problems) != 1 || problems[0].ID != id {
+		t.Fatalf("expected the problem %s to be pending, got %+v", id, problems)
+	}
+	if status := postSolution(t, "move2kube.other", "answer"); status != http.StatusNotAcceptable {
+		t.Fatalf("expected the solution of another problem to be rejected, got the status %d", status)
+	}
+	if status := postSolution(t, id, "Dockerfile.prod"); status != http.StatusNoContent {
+		t.Fatalf("expected the solution to be accepted, got the status %d", status)
+	}
+	if status := postSolution(t, id, "Dockerfile.dev"); status != http.StatusNotAcceptable {
+		t.Fatalf("expected the second solution of the answered problem to be rejected, got the status %d", status)
+	}
+	select {
+	case ans := <-answered:
+		if ans.Answer != "Dockerfile.prod" {
+			t.Fatalf("got the answer %+v , want the posted solution", ans.Answer)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatalf("timed out waiting for the answer")
+	}
+	if problems := listProblems(t); len(problems) != 0 {
+		t.Fatalf("expected no pending problems after the answer, got %+v", problems)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+)
+
+// Profile decides which of the questions are asked, the others are answered with their defaults
+type Profile string
+
+const (
+	// MinimalProfile only asks the essential questions
+	MinimalProfile Profile = "minimal"
+	// AdvancedProfile asks all the questions, except for the security and the scaling ones
+	AdvancedProfile Profile = "advanced"
+	// HardenedProfile asks all the questions, including the security and the scaling ones
+	HardenedProfile Profile = "hardened"
+	// profileKeyWildcard matches any segment of a question id in the profile patterns
+	profileKeyWildcard = "*"
+)
+
+// Profiles are the supported profiles
+var Profiles = []string{string(MinimalProfile), string(AdvancedProfile), string(HardenedProfile)}
+
+var (
+	// profile is empty when no profile is selected, in which case all the questions are asked
+	profile Profile
+	// essentialQuestions are the questions asked in all the profiles
+	essentialQuestions = getProfilePatterns(
+		common.TransformerSelectorKey,
+		common.ConfigTransformerTypesKey,
+		common.ConfigServicesNamesKey,
+		common.ConfigServicesExposeKey,
+		common.ConfigContainerizationKeySegment,
+		common.ConfigServicesKey+common.Delim+profileKeyWildcard+common.Delim+common.ConfigContainerizationOptionServiceKeySegment,
+		common.ConfigServicesKey+common.Delim+profileKeyWildcard+common.Delim+common.ConfigPortsForServiceKeySegment,
+		common.ConfigServicesKey+common.Delim+profileKeyWildcard+common.Delim+common.ConfigPortForServiceKeySegment,
+		common.ConfigTargetKey,
+	)
+	// hardenedQuestions are the security and the scaling questions, which are only asked in the hardened profile
+	hardenedQuestions = getProfilePatterns(
+		common.ConfigImageSigningKey,
+		common.ConfigSOPSKey,
+		common.ConfigNamespaceDefaultDenyKey,
+		common.ConfigNamespaceResourceQuotaKey,
+		common.ConfigServiceAccountsKey,
+		common.ConfigImageDigestsKey,
+		common.ConfigImagePullPolicyKey,
+		common.ConfigMinReplicasKey,
+		common.ConfigAutoscalingServicesKey,
+		common.ConfigServicesKey+common.Delim+profileKeyWildcard+common.Delim+common.ConfigAutoscalingKeySegment,
+		common.ConfigServicesKey+common.Delim+profileKeyWildcard+common.Delim+"containers"+common.Delim+profileKeyWildcard+common.Delim+common.ConfigContainerResourcesKeySegment,
+		common.ConfigSpotSchedulingKey,
+		common.ConfigProgressiveDeliveryKey,
+	)
+)
+
+// SetProfile selects the profile deciding which questions are asked. An empty profile asks all the questions.
+func SetProfile(p string) error {
+	if p != "" && !common.IsPresent(Profiles, p) {
+		return fmt.Errorf("the profile %s is not supported. Supported profiles are %s", p, strings.Join(Profiles, ", "))
+	}
+	profile = Profile(p)
+	return nil
+}
+
+// getProfilePatterns returns the regular expressions matching the question ids starting with the keys
+func getProfilePatterns(keys ...string) []*regexp.Regexp {
+	patterns := []*regexp.Regexp{}
+	for _, key := range keys {
+		pattern := strings.ReplaceAll(regexp.QuoteMeta(key), regexp.QuoteMeta(profileKeyWildcard), `(?:"[^"]*"|[^.]*)`)
+		patterns = append(patterns, regexp.MustCompile(`^`+pattern+`(?:\..*)?$`))
+	}
+	return patterns
+}
+
+// matchesProfilePatterns returns true if the question id matches one of the patterns
+func matchesProfilePatterns(id string, patterns []*regexp.Regexp) bool {
+	for _, pattern := range patterns {
+		if pattern.MatchString(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// isAskedInProfile returns true if the question is asked in the selected profile
+func isAskedInProfile(id string) bool {
+	switch profile {
+	case MinimalProfile:
+		return matchesProfilePatterns(id, essentialQuestions)
+	case AdvancedProfile:
+		return !matchesProfilePatterns(id, hardenedQuestions) || matchesProfilePatterns(id, essentialQuestions)
+	}
+	return true
+}
@@ -0,0 +1,133 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+)
+
+// recordingEngine is an interactive engine which records the questions it is asked
+type recordingEngine struct {
+	asked []string
+}
+
+func (*recordingEngine) StartEngine() error {
+	return nil
+}
+
+func (*recordingEngine) IsInteractiveEngine() bool {
+	return true
+}
+
+func (e *recordingEngine) FetchAnswer(prob qatypes.Problem) (qatypes.Problem, error) {
+	e.asked = append(e.asked, prob.ID)
+	prob.Answer = "asked"
+	return prob, nil
+}
+
+func setProfileForTest(t *testing.T, p string) {
+	t.Helper()
+	if err := SetProfile(p); err != nil {
+		t.Fatalf("failed to set the profile %s . Error: %q", p, err)
+	}
+	t.Cleanup(func() { profile = "" })
+}
+
+func TestSetProfile(t *testing.T) {
+	t.Cleanup(func() { profile = "" })
+	for _, p := range append([]string{""}, Profiles...) {
+		if err := SetProfile(p); err != nil {
+			t.Errorf("SetProfile(%q) failed. Error: %q", p, err)
+		}
+		if profile != Profile(p) {
+			t.Errorf("SetProfile(%q) selected the profile %q", p, profile)
+		}
+	}
+	if err := SetProfile("paranoid"); err == nil {
+		t.Errorf("expected an error for an unsupported profile")
+	}
+	if profile != HardenedProfile {
+		t.Errorf("expected an unsupported profile to keep the previous profile, got %q", profile)
+	}
+}
+
+func TestIsAskedInProfile(t *testing.T) {
+	svcKey := common.ConfigServicesKey + common.Delim + `"svc1"`
+	testcases := []struct {
+		id       string
+		minimal  bool
+		advanced bool
+	}{
+		{id: common.ConfigServicesNamesKey, minimal: true, advanced: true},
+		{id: common.ConfigServicesExposeKey, minimal: true, advanced: true},
+		{id: common.ConfigTargetKey + common.Delim + "clustertype", minimal: true, advanced: true},
+		{id: svcKey + common.Delim + common.ConfigContainerizationOptionServiceKeySegment, minimal: true, advanced: true},
+		{id: svcKey + common.Delim + common.ConfigPortsForServiceKeySegment, minimal: true, advanced: true},
+		{id: common.ConfigServicesKey + common.Delim + "svc2" + common.Delim + common.ConfigPortsForServiceKeySegment, minimal: true, advanced: true},
+		{id: common.BaseKey + common.Delim + "ingress" + common.Delim + "host", minimal: false, advanced: true},
+		{id: svcKey + common.Delim + "wartransformer", minimal: false, advanced: true},
+		{id: common.ConfigImageSigningKey, minimal: false, advanced: false},
+		{id: common.ConfigImageSigningKeyRefKey, minimal: false, advanced: false},
+		{id: common.ConfigMinReplicasKey, minimal: false, advanced: false},
+		{id: svcKey + common.Delim + common.ConfigAutoscalingKeySegment + common.Delim + "maxreplicas", minimal: false, advanced: false},
+		{id: svcKey + common.Delim + "containers" + common.Delim + `"app"` + common.Delim + common.ConfigContainerResourcesKeySegment, minimal: false, advanced: false},
+		{id: common.ConfigProgressiveDeliveryKey, minimal: false, advanced: false},
+		// a key only sharing the prefix of a pattern is not matched by it
+		{id: common.ConfigMinReplicasKey + "extra", minimal: false, advanced: true},
+	}
+	for _, p := range []Profile{"", MinimalProfile, AdvancedProfile, HardenedProfile} {
+		setProfileForTest(t, string(p))
+		for _, tc := range testcases {
+			want := true
+			switch p {
+			case MinimalProfile:
+				want = tc.minimal
+			case AdvancedProfile:
+				want = tc.advanced
+			}
+			if got := isAskedInProfile(tc.id); got != want {
+				t.Errorf("isAskedInProfile(%q) in the profile %q = %t, want %t", tc.id, p, got, want)
+			}
+		}
+	}
+}
+
+func TestFetchAnswerInProfile(t *testing.T) {
+	Reset()
+	t.Cleanup(Reset)
+	e := &recordingEngine{}
+	AddEngine(e)
+	setProfileForTest(t, string(MinimalProfile))
+
+	essentialID := common.ConfigServicesExposeKey
+	if got := FetchStringAnswer(essentialID, "Expose :", nil, "default", nil); got != "asked" {
+		t.Errorf("FetchStringAnswer(%q) = %q, want %q", essentialID, got, "asked")
+	}
+	otherID := common.BaseKey + common.Delim + "ingress" + common.Delim + "host"
+	if got := FetchStringAnswer(otherID, "Host :", nil, "default", nil); got != "default" {
+		t.Errorf("FetchStringAnswer(%q) = %q, want %q", otherID, got, "default")
+	}
+	if len(e.asked) != 1 || e.asked[0] != essentialID {
+		t.Errorf("expected only the question %s to be asked, got %+v", essentialID, e.asked)
+	}
+	if got := GetAnswers()[otherID]; got != "default" {
+		t.Errorf("expected the default answer of %s to be recorded, got %+v", otherID, got)
+	}
+}
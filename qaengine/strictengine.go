@@ -0,0 +1,92 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"fmt"
+
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/sirupsen/logrus"
+)
+
+// StrictEngine returns default values for all questions.
+// Questions without a default are recorded and answered with an empty value so that the run can list all of them at the end.
+type StrictEngine struct {
+	unanswered []qatypes.Problem
+}
+
+// NewStrictEngine creates a new instance of strict engine
+func NewStrictEngine() *StrictEngine {
+	return new(StrictEngine)
+}
+
+// StartEngine starts the strict qa engine
+func (*StrictEngine) StartEngine() error {
+	return nil
+}
+
+// IsInteractiveEngine returns true if the engine interacts with the user
+func (*StrictEngine) IsInteractiveEngine() bool {
+	return false
+}
+
+// FetchAnswer fetches the default answers
+func (se *StrictEngine) FetchAnswer(problem qatypes.Problem) (qatypes.Problem, error) {
+	if problem.Default != nil {
+		if err := problem.SetAnswer(problem.Default, true); err != nil {
+			return problem, fmt.Errorf("failed to set the given solution as the answer. Error: %w", err)
+		}
+		return problem, nil
+	}
+	logrus.Errorf("the question '%s' with the id '%s' has no default answer", problem.Desc, problem.ID)
+	se.unanswered = append(se.unanswered, problem)
+	if err := problem.SetAnswer(getEmptyAnswer(problem), false); err != nil {
+		return problem, fmt.Errorf("failed to set an empty answer for the question. Error: %w", err)
+	}
+	return problem, nil
+}
+
+// GetUnansweredProblems returns the questions that had no default answer
+func (se *StrictEngine) GetUnansweredProblems() []qatypes.Problem {
+	return se.unanswered
+}
+
+func (se *StrictEngine) isUnanswered(problem qatypes.Problem) bool {
+	for _, p := range se.unanswered {
+		if p.ID == problem.ID {
+			return true
+		}
+	}
+	return false
+}
+
+// getEmptyAnswer returns a placeholder answer of the correct type for the problem
+func getEmptyAnswer(problem qatypes.Problem) interface{} {
+	switch problem.Type {
+	case qatypes.ConfirmSolutionFormType:
+		return false
+	case qatypes.MultiSelectSolutionFormType:
+		return []string{}
+	case qatypes.SelectSolutionFormType:
+		if len(problem.Options) != 0 {
+			return problem.Options[0]
+		}
+		return ""
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,117 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+)
+
+func TestStrictEngine(t *testing.T) {
+	t.Run("questions with a default are answered with the default", func(t *testing.T) {
+		Reset()
+		defer Reset()
+		StartEngine(false, true, 0, false, false)
+		key := common.JoinQASubKeys(common.BaseKey, "strict", "withdefault")
+		if answer := FetchStringAnswer(key, "Strict question with a default", nil, "quay.io", nil); answer != "quay.io" {
+			t.Fatalf("expected the default answer 'quay.io'. Actual: '%s'", answer)
+		}
+		if problems := GetUnansweredProblems(); len(problems) != 0 {
+			t.Fatalf("expected no unanswered questions. Actual: %+v", problems)
+		}
+		if answers := GetAnswers(); answers[key] != "quay.io" {
+			t.Fatalf("expected the answer to be recorded. Actual answers: %+v", answers)
+		}
+	})
+
+	t.Run("questions without a default are recorded and get an empty answer", func(t *testing.T) {
+		Reset()
+		defer Reset()
+		StartEngine(false, true, 0, false, false)
+		passwordKey := common.JoinQASubKeys(common.BaseKey, "strict", "password")
+		if answer := FetchPasswordAnswer(passwordKey, "Strict password question", nil, nil); answer != "" {
+			t.Fatalf("expected an empty answer for the password. Actual: '%s'", answer)
+		}
+		confirmKey := common.JoinQASubKeys(common.BaseKey, "strict", "confirm")
+		confirmProblem := qatypes.Problem{ID: confirmKey, Type: qatypes.ConfirmSolutionFormType, Desc: "Strict confirm question"}
+		confirmProblem, err := FetchAnswer(confirmProblem)
+		if err != nil {
+			t.Fatalf("failed to fetch the answer for the confirm question. Error: %q", err)
+		}
+		if confirmProblem.Answer != false {
+			t.Fatalf("expected false as the answer for the confirm question. Actual: %+v", confirmProblem.Answer)
+		}
+		selectKey := common.JoinQASubKeys(common.BaseKey, "strict", "select")
+		selectProblem := qatypes.Problem{ID: selectKey, Type: qatypes.SelectSolutionFormType, Desc: "Strict select question", Options: []string{"Option A", "Option B"}}
+		selectProblem, err = FetchAnswer(selectProblem)
+		if err != nil {
+			t.Fatalf("failed to fetch the answer for the select question. Error: %q", err)
+		}
+		if selectProblem.Answer != "Option A" {
+			t.Fatalf("expected the first option as the answer for the select question. Actual: %+v", selectProblem.Answer)
+		}
+		problemIDs := []string{}
+		for _, problem := range GetUnansweredProblems() {
+			problemIDs = append(problemIDs, problem.ID)
+		}
+		if want := []string{passwordKey, confirmKey, selectKey}; !cmp.Equal(problemIDs, want) {
+			t.Fatalf("unexpected unanswered questions. Differences: %s", cmp.Diff(want, problemIDs))
+		}
+		answers := GetAnswers()
+		for _, id := range problemIDs {
+			if _, ok := answers[id]; ok {
+				t.Fatalf("expected the placeholder answer of '%s' not to be recorded. Actual answers: %+v", id, answers)
+			}
+		}
+	})
+
+	t.Run("questions answered by the config are not recorded as unanswered", func(t *testing.T) {
+		Reset()
+		defer Reset()
+		StartEngine(false, true, 0, false, false)
+		key := common.JoinQASubKeys(common.BaseKey, "strict", "configured")
+		SetupConfigFile("", []string{key + `="configured"`}, nil, nil, false)
+		problem := qatypes.Problem{ID: key, Type: qatypes.InputSolutionFormType, Desc: "Strict configured question"}
+		problem, err := FetchAnswer(problem)
+		if err != nil {
+			t.Fatalf("failed to fetch the answer for the configured question. Error: %q", err)
+		}
+		if problem.Answer != "configured" {
+			t.Fatalf("expected the answer from the config. Actual: %+v", problem.Answer)
+		}
+		if problems := GetUnansweredProblems(); len(problems) != 0 {
+			t.Fatalf("expected no unanswered questions. Actual: %+v", problems)
+		}
+	})
+
+	t.Run("reset clears the unanswered questions", func(t *testing.T) {
+		Reset()
+		defer Reset()
+		StartEngine(false, true, 0, false, false)
+		FetchPasswordAnswer(common.JoinQASubKeys(common.BaseKey, "strict", "reset"), "Strict password question before the reset", nil, nil)
+		if problems := GetUnansweredProblems(); len(problems) != 1 {
+			t.Fatalf("expected 1 unanswered question. Actual: %+v", problems)
+		}
+		Reset()
+		if problems := GetUnansweredProblems(); len(problems) != 0 {
+			t.Fatalf("expected no unanswered questions after the reset. Actual: %+v", problems)
+		}
+	})
+}
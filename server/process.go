@@ -0,0 +1,63 @@
+//go:build !windows
+// +build !windows
+
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup runs the command in its own process group, so that the processes it starts, like the containers and
+// the scripts of the transformers, are stopped along with it
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// killProcessGroup stops the command and all the processes it started
+func killProcessGroup(cmd *exec.Cmd) error {
+	// the process group of the command has the same ID as the command, since it leads the group
+	if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil && err != syscall.ESRCH {
+		return err
+	}
+	return nil
+}
+
+// passQAListener listens on a free port of the loopback interface and passes the listener to the command,
+// so that no other process can take the port before the QA engine of the transformation serves on it.
+// It returns the port and the file of the listener, which has to be closed once the command has started.
+func passQAListener(cmd *exec.Cmd) ([]string, int, *os.File, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to listen on a free port. Error: %w", err)
+	}
+	// the file is a duplicate of the file descriptor of the listener, so the listener can be closed
+	defer listener.Close()
+	listenerFile, err := listener.(*net.TCPListener).File()
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to get the file of the listener. Error: %w", err)
+	}
+	cmd.ExtraFiles = append(cmd.ExtraFiles, listenerFile)
+	// the extra files of the command start after the standard input, output and error
+	fd := 2 + len(cmd.ExtraFiles)
+	return []string{"--qa-listener-fd", fmt.Sprint(fd)}, listener.Addr().(*net.TCPAddr).Port, listenerFile, nil
+}
@@ -0,0 +1,117 @@
+//go:build !windows
+// +build !windows
+
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package server
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cast"
+)
+
+// isProcessRunning returns true if the process exists and is not a zombie waiting to be reaped
+func isProcessRunning(pid int) bool {
+	stat, err := os.ReadFile(filepath.Join("/proc", cast.ToString(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(stat[strings.LastIndex(string(stat), ")")+1:]))
+	return len(fields) != 0 && fields[0] != "Z"
+}
+
+func TestKillProcessGroup(t *testing.T) {
+	if _, err := os.Stat("/proc/self/stat"); err != nil {
+		t.Skip("the processes are found using the proc filesystem")
+	}
+	pidPath := filepath.Join(t.TempDir(), "pid")
+	// the shell starts a child process which keeps running if only the shell is stopped
+	cmd := exec.Command("sh", "-c", "sleep 60 & echo $! > "+pidPath+"; wait")
+	setProcessGroup(cmd)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("failed to start the command. Error: %q", err)
+	}
+	var childPid int
+	for deadline := time.Now().Add(5 * time.Second); childPid == 0; time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("the command did not start its child process")
+		}
+		if pid, err := os.ReadFile(pidPath); err == nil {
+			childPid = cast.ToInt(strings.TrimSpace(string(pid)))
+		}
+	}
+	if err := killProcessGroup(cmd); err != nil {
+		t.Fatalf("failed to stop the process group. Error: %q", err)
+	}
+	if err := cmd.Wait(); err == nil {
+		t.Fatalf("expected the command to be killed")
+	}
+	for deadline := time.Now().Add(5 * time.Second); isProcessRunning(childPid); time.Sleep(10 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("expected the child process %d to be stopped along with the command", childPid)
+		}
+	}
+	// stopping a process group which already exited is not an error
+	if err := killProcessGroup(cmd); err != nil {
+		t.Fatalf("failed to stop the process group which already exited. Error: %q", err)
+	}
+}
+
+func TestPassQAListener(t *testing.T) {
+	cmd := exec.Command("move2kube")
+	args, port, listenerFile, err := passQAListener(cmd)
+	if err != nil {
+		t.Fatalf("failed to pass the listener. Error: %q", err)
+	}
+	defer listenerFile.Close()
+	if want := []string{"--qa-listener-fd", "3"}; strings.Join(args, " ") != strings.Join(want, " ") {
+		t.Fatalf("got the args %+v , want %+v", args, want)
+	}
+	if len(cmd.ExtraFiles) != 1 || cmd.ExtraFiles[0] != listenerFile {
+		t.Fatalf("expected the listener to be passed as the first extra file, got %+v", cmd.ExtraFiles)
+	}
+	// the port stays bound, so no other process can take it
+	if otherListener, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", cast.ToString(port))); err == nil {
+		otherListener.Close()
+		t.Fatalf("expected the port %d to be taken by the passed listener", port)
+	}
+	listener, err := net.FileListener(listenerFile)
+	if err != nil {
+		t.Fatalf("failed to get the listener from the file. Error: %q", err)
+	}
+	defer listener.Close()
+	if addr := listener.Addr().(*net.TCPAddr); !addr.IP.IsLoopback() || addr.Port != port {
+		t.Fatalf("expected the listener to be bound to the port %d of the loopback interface, got %s", port, addr)
+	}
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			conn.Close()
+		}
+	}()
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", cast.ToString(port)), time.Second)
+	if err != nil {
+		t.Fatalf("failed to connect to the passed listener. Error: %q", err)
+	}
+	conn.Close()
+}
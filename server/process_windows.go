@@ -0,0 +1,47 @@
+//go:build windows
+// +build windows
+
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package server
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/phayes/freeport"
+)
+
+// setProcessGroup does nothing, since the processes started by the command are not stopped along with it on Windows
+func setProcessGroup(cmd *exec.Cmd) {
+}
+
+// killProcessGroup stops the command
+func killProcessGroup(cmd *exec.Cmd) error {
+	return cmd.Process.Kill()
+}
+
+// passQAListener finds a free port for the QA engine of the transformation. The listeners can not be passed
+// to the child processes on Windows, so the port could be taken by another process before the QA engine listens on it.
+func passQAListener(cmd *exec.Cmd) ([]string, int, *os.File, error) {
+	port, err := freeport.GetFreePort()
+	if err != nil {
+		return nil, 0, nil, fmt.Errorf("failed to find a free port. Error: %w", err)
+	}
+	return []string{"--qa-port", fmt.Sprint(port)}, port, nil, nil
+}
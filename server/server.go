@@ -0,0 +1,409 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/lib"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// APIPrefix is the prefix of the paths of the REST API
+	APIPrefix = "/api/v1"
+	// DefaultMaxUploadSize is the default maximum size of the requests uploading the sources
+	DefaultMaxUploadSize = 1 << 30
+	// DefaultFinishedTransformationTTL is the default duration for which the finished transformations are kept
+	DefaultFinishedTransformationTTL = 24 * time.Hour
+	// maxMemoryUploadSize is the size of the uploaded files kept in memory, the larger files are stored in temporary files
+	maxMemoryUploadSize = 32 << 20
+	// maxSolutionSize is the maximum size of the answers of the questions
+	maxSolutionSize = 1 << 20
+	// cleanupInterval is the interval at which the expired transformations are removed
+	cleanupInterval    = time.Minute
+	sourceFormField    = "source"
+	configFormField    = "config"
+	nameFormField      = "name"
+	formatFormField    = "format"
+	setConfigFormField = "setconfig"
+	qaSkipFormField    = "qaskip"
+)
+
+// Config configures the transformation server
+type Config struct {
+	// Address is the host and port the server listens on. Example: 127.0.0.1:8080
+	Address string
+	// WorkspacePath is the directory where the sources, logs and outputs of the transformations are stored
+	WorkspacePath string
+	// MaxParallelTransformations limits the number of transformations which run at the same time. Zero means no limit.
+	// The transformations requested after the limit is reached are queued and started in the order they were requested.
+	MaxParallelTransformations int
+	// MaxQueuedTransformations limits the number of transformations waiting to start. Zero means no limit.
+	MaxQueuedTransformations int
+	// MaxUploadSize limits the size in bytes of the requests uploading the sources and the config. Zero means no limit.
+	MaxUploadSize int64
+	// FinishedTransformationTTL is the duration after which the finished transformations and their files are removed. Zero means they are kept until deleted.
+	FinishedTransformationTTL time.Duration
+	// Limits are applied to each of the transformations
+	Limits Limits
+	// Executable is the move2kube binary which runs the transformations. Defaults to the current executable.
+	Executable string
+}
+
+// Server runs the transformations requested using the REST API.
+// Each transformation runs in a separate move2kube process, since the QA engines and the transformers are global to a process.
+type Server struct {
+	config          Config
+	mutex           sync.Mutex
+	transformations map[string]*transformation
+	// queue has the transformations waiting to start, in the order they were requested
+	queue  []*transformation
+	stopCh chan struct{}
+}
+
+// NewServer returns a transformation server using the config
+func NewServer(config Config) (*Server, error) {
+	if config.Executable == "" {
+		executable, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("failed to find the path of the move2kube executable. Error: %w", err)
+		}
+		config.Executable = executable
+	}
+	workspacePath, err := filepath.Abs(config.WorkspacePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to make the workspace path %s absolute. Error: %w", config.WorkspacePath, err)
+	}
+	config.WorkspacePath = workspacePath
+	if err := os.MkdirAll(config.WorkspacePath, common.DefaultDirectoryPermission); err != nil {
+		return nil, fmt.Errorf("failed to create the workspace directory %s . Error: %w", config.WorkspacePath, err)
+	}
+	return &Server{config: config, transformations: map[string]*transformation{}, stopCh: make(chan struct{})}, nil
+}
+
+// Handler returns the handler of the REST API
+func (s *Server) Handler() http.Handler {
+	router := mux.NewRouter()
+	api := router.PathPrefix(APIPrefix).Subrouter()
+	api.HandleFunc("/transformations", s.listTransformationsHandler).Methods("GET")
+	api.HandleFunc("/transformations", s.createTransformationHandler).Methods("POST")
+	api.HandleFunc("/transformations/{id}", s.getTransformationHandler).Methods("GET")
+	api.HandleFunc("/transformations/{id}", s.deleteTransformationHandler).Methods("DELETE")
+	api.HandleFunc("/transformations/{id}/problems", s.listProblemsHandler).Methods("GET")
+	api.HandleFunc("/transformations/{id}/problems/{problemid}/solution", s.postSolutionHandler).Methods("POST")
+	api.HandleFunc("/transformations/{id}/logs", s.getLogsHandler).Methods("GET")
+	api.HandleFunc("/transformations/{id}/output", s.getOutputHandler).Methods("GET")
+	return router
+}
+
+// Start starts the server and blocks until it stops
+func (s *Server) Start() error {
+	server := &http.Server{
+		Handler:           s.Handler(),
+		Addr:              s.config.Address,
+		ReadHeaderTimeout: 15 * time.Second,
+	}
+	if s.config.FinishedTransformationTTL > 0 {
+		go s.removeExpiredTransformationsPeriodically()
+	}
+	logrus.Infof("Listening on http://%s%s/transformations", s.config.Address, APIPrefix)
+	return server.ListenAndServe()
+}
+
+// removeExpiredTransformationsPeriodically removes the expired transformations until the server is stopped
+func (s *Server) removeExpiredTransformationsPeriodically() {
+	ticker := time.NewTicker(cleanupInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case now := <-ticker.C:
+			s.removeExpiredTransformations(now)
+		}
+	}
+}
+
+// removeExpiredTransformations removes the transformations which finished before the TTL and their files
+func (s *Server) removeExpiredTransformations(now time.Time) {
+	s.mutex.Lock()
+	expired := []*transformation{}
+	for _, t := range s.transformations {
+		if finishedAt := t.getFinishedAt(); finishedAt != nil && now.Sub(*finishedAt) >= s.config.FinishedTransformationTTL {
+			expired = append(expired, t)
+		}
+	}
+	s.mutex.Unlock()
+	for _, t := range expired {
+		s.removeTransformation(t)
+		logrus.Infof("Removed the transformation %s since it finished more than %s ago", t.ID, s.config.FinishedTransformationTTL)
+	}
+}
+
+// Stop cancels the running transformations
+func (s *Server) Stop() {
+	s.mutex.Lock()
+	select {
+	case <-s.stopCh:
+	default:
+		close(s.stopCh)
+	}
+	s.queue = nil
+	transformations := []*transformation{}
+	for _, t := range s.transformations {
+		transformations = append(transformations, t)
+	}
+	s.mutex.Unlock()
+	for _, t := range transformations {
+		t.cancel()
+	}
+}
+
+func (s *Server) getTransformation(w http.ResponseWriter, r *http.Request) *transformation {
+	id := mux.Vars(r)["id"]
+	s.mutex.Lock()
+	t, ok := s.transformations[id]
+	s.mutex.Unlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("the transformation %s does not exist", id), http.StatusNotFound)
+		return nil
+	}
+	return t
+}
+
+func (s *Server) listTransformationsHandler(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	transformations := []Transformation{}
+	for _, t := range s.transformations {
+		transformations = append(transformations, t.getStatus(false))
+	}
+	s.mutex.Unlock()
+	sort.Slice(transformations, func(i, j int) bool { return transformations[i].CreatedAt.Before(transformations[j].CreatedAt) })
+	writeJSON(w, http.StatusOK, transformations)
+}
+
+// createTransformationHandler starts a transformation of the uploaded source archive.
+// The multipart form has the source archive, along with the optional config file, project name, archive format of the output,
+// config key-value pairs and whether the default answers should be used instead of asking the questions over the API.
+func (s *Server) createTransformationHandler(w http.ResponseWriter, r *http.Request) {
+	if s.config.MaxUploadSize > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, s.config.MaxUploadSize)
+	}
+	if err := r.ParseMultipartForm(maxMemoryUploadSize); err != nil {
+		if isRequestTooLarge(err) {
+			http.Error(w, fmt.Sprintf("the upload is larger than the limit of %d bytes", s.config.MaxUploadSize), http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, fmt.Sprintf("failed to parse the multipart form. Error: %q", err), http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+	opts := transformationOptions{
+		name:       r.FormValue(nameFormField),
+		format:     r.FormValue(formatFormField),
+		setConfigs: r.MultipartForm.Value[setConfigFormField],
+		qaSkip:     r.FormValue(qaSkipFormField) == "true",
+	}
+	if opts.name == "" {
+		opts.name = common.DefaultProjectName
+	}
+	opts.name = common.MakeFileNameCompliant(opts.name)
+	if opts.format == "" {
+		opts.format = lib.ZipArchiveFormat
+	}
+	if !common.IsPresent(lib.ArchiveFormats, opts.format) {
+		http.Error(w, fmt.Sprintf("unsupported archive format %s . Supported formats are %s", opts.format, strings.Join(lib.ArchiveFormats, ", ")), http.StatusBadRequest)
+		return
+	}
+	source, sourceHeader, err := r.FormFile(sourceFormField)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("the source archive must be uploaded in the %s field. Error: %q", sourceFormField, err), http.StatusBadRequest)
+		return
+	}
+	defer source.Close()
+	opts.sourceFormat = lib.ZipArchiveFormat
+	if strings.HasSuffix(sourceHeader.Filename, ".tar.gz") || strings.HasSuffix(sourceHeader.Filename, ".tgz") {
+		opts.sourceFormat = lib.TarGzArchiveFormat
+	}
+	s.mutex.Lock()
+	if limit := s.config.MaxQueuedTransformations; limit > 0 && len(s.queue) >= limit {
+		s.mutex.Unlock()
+		http.Error(w, fmt.Sprintf("%d transformations are already waiting to start. Try again later.", len(s.queue)), http.StatusTooManyRequests)
+		return
+	}
+	id := newTransformationID()
+	for s.transformations[id] != nil {
+		id = newTransformationID()
+	}
+	t := newTransformation(id, filepath.Join(s.config.WorkspacePath, id), opts)
+	s.transformations[id] = t
+	s.mutex.Unlock()
+	if err := t.setup(source, configFile(r)); err != nil {
+		s.removeTransformation(t)
+		logrus.Errorf("failed to set up the transformation %s . Error: %q", id, err)
+		http.Error(w, fmt.Sprintf("failed to set up the transformation. Error: %q", err), http.StatusBadRequest)
+		return
+	}
+	s.mutex.Lock()
+	s.queue = append(s.queue, t)
+	s.mutex.Unlock()
+	logrus.Infof("Queued the transformation %s of the project %s", id, opts.name)
+	s.startQueuedTransformations()
+	w.Header().Set("Location", APIPrefix+"/transformations/"+id)
+	writeJSON(w, http.StatusAccepted, t.getStatus(false))
+}
+
+// isRequestTooLarge returns true if reading the request failed since it is larger than the limit of http.MaxBytesReader
+func isRequestTooLarge(err error) bool {
+	return strings.Contains(err.Error(), "request body too large")
+}
+
+// configFile returns the uploaded config file, or nil if there is none
+func configFile(r *http.Request) io.ReadCloser {
+	config, _, err := r.FormFile(configFormField)
+	if err != nil {
+		return nil
+	}
+	return config
+}
+
+// startQueuedTransformations starts the queued transformations until the limit of the parallel transformations is reached
+func (s *Server) startQueuedTransformations() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	running := 0
+	for _, t := range s.transformations {
+		if t.isRunning() {
+			running++
+		}
+	}
+	for len(s.queue) != 0 && (s.config.MaxParallelTransformations <= 0 || running < s.config.MaxParallelTransformations) {
+		t := s.queue[0]
+		s.queue = s.queue[1:]
+		if err := t.start(s.config.Executable, s.config.Limits, s.startQueuedTransformations); err != nil {
+			logrus.Errorf("failed to start the transformation %s . Error: %q", t.ID, err)
+			t.fail(fmt.Sprintf("failed to start the transformation. Error: %q", err))
+			continue
+		}
+		logrus.Infof("Started the transformation %s of the project %s", t.ID, t.Name)
+		running++
+	}
+}
+
+func (s *Server) removeTransformation(t *transformation) {
+	s.mutex.Lock()
+	delete(s.transformations, t.ID)
+	for i, queued := range s.queue {
+		if queued == t {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			break
+		}
+	}
+	s.mutex.Unlock()
+	t.cancel()
+	if err := os.RemoveAll(t.dir); err != nil {
+		logrus.Errorf("failed to remove the directory %s of the transformation %s . Error: %q", t.dir, t.ID, err)
+	}
+}
+
+func (s *Server) getTransformationHandler(w http.ResponseWriter, r *http.Request) {
+	if t := s.getTransformation(w, r); t != nil {
+		writeJSON(w, http.StatusOK, t.getStatus(true))
+	}
+}
+
+// deleteTransformationHandler cancels the transformation if it is running and removes its files
+func (s *Server) deleteTransformationHandler(w http.ResponseWriter, r *http.Request) {
+	if t := s.getTransformation(w, r); t != nil {
+		s.removeTransformation(t)
+		logrus.Infof("Removed the transformation %s", t.ID)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func (s *Server) listProblemsHandler(w http.ResponseWriter, r *http.Request) {
+	t := s.getTransformation(w, r)
+	if t == nil {
+		return
+	}
+	problems, err := t.getProblems()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	writeJSON(w, http.StatusOK, problems)
+}
+
+func (s *Server) postSolutionHandler(w http.ResponseWriter, r *http.Request) {
+	t := s.getTransformation(w, r)
+	if t == nil {
+		return
+	}
+	status, body, err := t.postSolution(mux.Vars(r)["problemid"], http.MaxBytesReader(w, r.Body, maxSolutionSize))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	if status == http.StatusNoContent {
+		w.WriteHeader(status)
+		return
+	}
+	http.Error(w, strings.TrimSpace(string(body)), status)
+}
+
+func (s *Server) getLogsHandler(w http.ResponseWriter, r *http.Request) {
+	if t := s.getTransformation(w, r); t != nil {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		http.ServeFile(w, r, t.logPath())
+	}
+}
+
+// getOutputHandler downloads the archive of the output once the transformation succeeds
+func (s *Server) getOutputHandler(w http.ResponseWriter, r *http.Request) {
+	t := s.getTransformation(w, r)
+	if t == nil {
+		return
+	}
+	status := t.getStatus(false)
+	if status.Status != SucceededTransformationStatus {
+		http.Error(w, fmt.Sprintf("the output is not available since the status of the transformation is %s", status.Status), http.StatusConflict)
+		return
+	}
+	archivePath := t.archivePath()
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filepath.Base(archivePath)))
+	http.ServeFile(w, r, archivePath)
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		logrus.Errorf("failed to encode the response as json. Error: %q", err)
+	}
+}
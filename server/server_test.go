@@ -0,0 +1,315 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package server
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// transformScript is a fake move2kube executable which writes the output archive
+const transformScript = `#!/bin/sh
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --output) output="$2"; shift;;
+    --name) name="$2"; shift;;
+    --archive) format="$2"; shift;;
+  esac
+  shift
+done
+mkdir -p "$output"
+echo transformed > "$output/$name.$format"
+`
+
+// slowTransformScript is a fake move2kube executable which runs until it is stopped
+const slowTransformScript = "#!/bin/sh\nexec sleep 60\n"
+
+func newTestServer(t *testing.T, script string, config Config) (*Server, *httptest.Server) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake move2kube executable is a shell script")
+	}
+	config.Executable = filepath.Join(t.TempDir(), "move2kube")
+	if err := os.WriteFile(config.Executable, []byte(script), 0755); err != nil {
+		t.Fatalf("failed to write the fake executable. Error: %q", err)
+	}
+	config.WorkspacePath = t.TempDir()
+	s, err := NewServer(config)
+	if err != nil {
+		t.Fatalf("failed to create the server. Error: %q", err)
+	}
+	httpServer := httptest.NewServer(s.Handler())
+	t.Cleanup(func() {
+		s.Stop()
+		httpServer.Close()
+	})
+	return s, httpServer
+}
+
+// submit uploads a zip archive of the source with a file of the size and returns the response
+func submit(t *testing.T, httpServer *httptest.Server, fileSize int) *http.Response {
+	t.Helper()
+	sourceArchive := &bytes.Buffer{}
+	zipWriter := zip.NewWriter(sourceArchive)
+	file, err := zipWriter.Create("app/main.go")
+	if err != nil {
+		t.Fatalf("failed to add the file to the source archive. Error: %q", err)
+	}
+	// random contents, so that the size of the upload is not reduced by the compression
+	contents := make([]byte, fileSize)
+	if _, err := rand.Read(contents); err != nil {
+		t.Fatalf("failed to generate the contents of the file. Error: %q", err)
+	}
+	if _, err := file.Write(contents); err != nil {
+		t.Fatalf("failed to write the file to the source archive. Error: %q", err)
+	}
+	if err := zipWriter.Close(); err != nil {
+		t.Fatalf("failed to close the source archive. Error: %q", err)
+	}
+	body := &bytes.Buffer{}
+	formWriter := multipart.NewWriter(body)
+	sourceField, err := formWriter.CreateFormFile(sourceFormField, "source.zip")
+	if err != nil {
+		t.Fatalf("failed to create the source field. Error: %q", err)
+	}
+	if _, err := io.Copy(sourceField, sourceArchive); err != nil {
+		t.Fatalf("failed to write the source field. Error: %q", err)
+	}
+	if err := formWriter.WriteField(nameFormField, "myproject"); err != nil {
+		t.Fatalf("failed to write the name field. Error: %q", err)
+	}
+	if err := formWriter.WriteField(qaSkipFormField, "true"); err != nil {
+		t.Fatalf("failed to write the qaskip field. Error: %q", err)
+	}
+	if err := formWriter.Close(); err != nil {
+		t.Fatalf("failed to close the form. Error: %q", err)
+	}
+	resp, err := http.Post(httpServer.URL+APIPrefix+"/transformations", formWriter.FormDataContentType(), body)
+	if err != nil {
+		t.Fatalf("failed to submit the transformation. Error: %q", err)
+	}
+	return resp
+}
+
+func decodeTransformation(t *testing.T, resp *http.Response) Transformation {
+	t.Helper()
+	defer resp.Body.Close()
+	status := Transformation{}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("failed to decode the transformation. Error: %q", err)
+	}
+	return status
+}
+
+func waitForStatus(t *testing.T, httpServer *httptest.Server, id string, want TransformationStatus) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for {
+		resp, err := http.Get(httpServer.URL + APIPrefix + "/transformations/" + id)
+		if err != nil {
+			t.Fatalf("failed to get the status of the transformation. Error: %q", err)
+		}
+		status := decodeTransformation(t, resp)
+		if status.Status == want {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("the status of the transformation is %s , want %s . Error: %s", status.Status, want, status.Error)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestServerTransformation(t *testing.T) {
+	s, httpServer := newTestServer(t, transformScript, Config{})
+	resp := submit(t, httpServer, 16)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("got the status %s , want %d", resp.Status, http.StatusAccepted)
+	}
+	location := resp.Header.Get("Location")
+	status := decodeTransformation(t, resp)
+	if location != APIPrefix+"/transformations/"+status.ID {
+		t.Fatalf("got the location %s for the transformation %s", location, status.ID)
+	}
+	waitForStatus(t, httpServer, status.ID, SucceededTransformationStatus)
+
+	resp, err := http.Get(httpServer.URL + location + "/output")
+	if err != nil {
+		t.Fatalf("failed to download the output. Error: %q", err)
+	}
+	output, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil || resp.StatusCode != http.StatusOK || string(output) != "transformed\n" {
+		t.Fatalf("got the output %q with the status %s , want the archive written by the transformation. Error: %v", string(output), resp.Status, err)
+	}
+
+	if resp, err := http.Get(httpServer.URL + APIPrefix + "/transformations/unknown"); err != nil || resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected the unknown transformation not to be found, got %+v . Error: %v", resp, err)
+	}
+
+	req, err := http.NewRequest(http.MethodDelete, httpServer.URL+location, nil)
+	if err != nil {
+		t.Fatalf("failed to create the delete request. Error: %q", err)
+	}
+	if resp, err := http.DefaultClient.Do(req); err != nil || resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("failed to delete the transformation, got %+v . Error: %v", resp, err)
+	}
+	if _, err := os.Stat(filepath.Join(s.config.WorkspacePath, status.ID)); !os.IsNotExist(err) {
+		t.Fatalf("expected the files of the deleted transformation to be removed")
+	}
+}
+
+func TestServerRejectsLargeUploads(t *testing.T) {
+	s, httpServer := newTestServer(t, transformScript, Config{MaxUploadSize: 1024})
+	resp := submit(t, httpServer, 4096)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("got the status %s , want %d", resp.Status, http.StatusRequestEntityTooLarge)
+	}
+	if len(s.transformations) != 0 {
+		t.Fatalf("expected no transformations to be created, got %d", len(s.transformations))
+	}
+}
+
+func TestServerQueueOverflow(t *testing.T) {
+	_, httpServer := newTestServer(t, slowTransformScript, Config{MaxParallelTransformations: 1, MaxQueuedTransformations: 1})
+	running := decodeTransformation(t, submit(t, httpServer, 16))
+	waitForStatus(t, httpServer, running.ID, RunningTransformationStatus)
+	queued := decodeTransformation(t, submit(t, httpServer, 16))
+	if queued.Status != QueuedTransformationStatus {
+		t.Fatalf("got the status %s , want the transformation to be queued", queued.Status)
+	}
+	resp := submit(t, httpServer, 16)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTooManyRequests || !strings.Contains(string(body), "waiting to start") {
+		t.Fatalf("got the status %s and the body %q , want the request to be rejected once the queue is full", resp.Status, string(body))
+	}
+}
+
+func TestRemoveExpiredTransformations(t *testing.T) {
+	ttl := time.Hour
+	s, httpServer := newTestServer(t, transformScript, Config{FinishedTransformationTTL: ttl})
+	status := decodeTransformation(t, submit(t, httpServer, 16))
+	waitForStatus(t, httpServer, status.ID, SucceededTransformationStatus)
+	dir := filepath.Join(s.config.WorkspacePath, status.ID)
+
+	s.removeExpiredTransformations(time.Now())
+	if _, ok := s.transformations[status.ID]; !ok {
+		t.Fatalf("expected the transformation to be kept until the TTL")
+	}
+	s.removeExpiredTransformations(time.Now().Add(ttl))
+	if _, ok := s.transformations[status.ID]; ok {
+		t.Fatalf("expected the transformation to be removed after the TTL")
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("expected the files of the expired transformation to be removed")
+	}
+}
+
+// envTransformScript is a fake move2kube executable which writes its temporary directory and limits to the output archive
+const envTransformScript = `#!/bin/sh
+while [ $# -gt 0 ]; do
+  case "$1" in
+    --output) output="$2"; shift;;
+    --name) name="$2"; shift;;
+    --archive) format="$2"; shift;;
+  esac
+  shift
+done
+mkdir -p "$output"
+test -d "$TMPDIR" && echo "$TMPDIR $GOMAXPROCS $GOMEMLIMIT" > "$output/$name.$format"
+`
+
+func TestServerTransformationLimits(t *testing.T) {
+	s, httpServer := newTestServer(t, envTransformScript, Config{Limits: Limits{CPUs: 2, Memory: "1GiB"}})
+	status := decodeTransformation(t, submit(t, httpServer, 16))
+	waitForStatus(t, httpServer, status.ID, SucceededTransformationStatus)
+	resp, err := http.Get(httpServer.URL + APIPrefix + "/transformations/" + status.ID + "/output")
+	if err != nil {
+		t.Fatalf("failed to download the output. Error: %q", err)
+	}
+	output, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	tempPath := filepath.Join(s.config.WorkspacePath, status.ID, tempDir)
+	if want := tempPath + " 2 1GiB\n"; err != nil || string(output) != want {
+		t.Fatalf("got the output %q , want %q . Error: %v", string(output), want, err)
+	}
+	if _, err := os.Stat(tempPath); !os.IsNotExist(err) {
+		t.Fatalf("expected the temporary directory of the transformation to be removed once it finished")
+	}
+}
+
+func TestServerTransformationTimeout(t *testing.T) {
+	_, httpServer := newTestServer(t, slowTransformScript, Config{Limits: Limits{Timeout: 100 * time.Millisecond}})
+	status := decodeTransformation(t, submit(t, httpServer, 16))
+	waitForStatus(t, httpServer, status.ID, FailedTransformationStatus)
+	resp, err := http.Get(httpServer.URL + APIPrefix + "/transformations/" + status.ID)
+	if err != nil {
+		t.Fatalf("failed to get the status of the transformation. Error: %q", err)
+	}
+	if status := decodeTransformation(t, resp); !strings.Contains(status.Error, "took longer than 100ms") {
+		t.Fatalf("expected the transformation to be stopped by the timeout. Error: %s", status.Error)
+	}
+}
+
+func TestServerStartsTheQueuedTransformations(t *testing.T) {
+	_, httpServer := newTestServer(t, slowTransformScript, Config{MaxParallelTransformations: 1})
+	first := decodeTransformation(t, submit(t, httpServer, 16))
+	second := decodeTransformation(t, submit(t, httpServer, 16))
+	third := decodeTransformation(t, submit(t, httpServer, 16))
+	waitForStatus(t, httpServer, first.ID, RunningTransformationStatus)
+	cancel := func(id string) {
+		t.Helper()
+		req, err := http.NewRequest(http.MethodDelete, httpServer.URL+APIPrefix+"/transformations/"+id, nil)
+		if err != nil {
+			t.Fatalf("failed to create the delete request. Error: %q", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("failed to delete the transformation. Error: %q", err)
+		}
+		resp.Body.Close()
+	}
+
+	cancel(second.ID)
+	getStatus := func(id string) TransformationStatus {
+		t.Helper()
+		resp, err := http.Get(httpServer.URL + APIPrefix + "/transformations/" + id)
+		if err != nil {
+			t.Fatalf("failed to get the status of the transformation. Error: %q", err)
+		}
+		return decodeTransformation(t, resp).Status
+	}
+	if status := getStatus(third.ID); status != QueuedTransformationStatus {
+		t.Fatalf("got the status %s , want the transformation to wait for the running one", status)
+	}
+	cancel(first.ID)
+	waitForStatus(t, httpServer, third.ID, RunningTransformationStatus)
+}
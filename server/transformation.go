@@ -0,0 +1,417 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/lib"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/sirupsen/logrus"
+)
+
+// TransformationStatus is the status of a transformation run by the server
+type TransformationStatus string
+
+const (
+	// QueuedTransformationStatus is the status of the transformations waiting for the other transformations to finish
+	QueuedTransformationStatus TransformationStatus = "queued"
+	// RunningTransformationStatus is the status of the transformations which are planning or transforming
+	RunningTransformationStatus TransformationStatus = "running"
+	// WaitingForAnswerTransformationStatus is the status of the transformations waiting for the answer of a question
+	WaitingForAnswerTransformationStatus TransformationStatus = "waiting-for-answer"
+	// SucceededTransformationStatus is the status of the transformations whose output can be downloaded
+	SucceededTransformationStatus TransformationStatus = "succeeded"
+	// FailedTransformationStatus is the status of the transformations which failed, the reason can be found in the logs
+	FailedTransformationStatus TransformationStatus = "failed"
+	// CancelledTransformationStatus is the status of the transformations which were cancelled
+	CancelledTransformationStatus TransformationStatus = "cancelled"
+)
+
+const (
+	sourceDir       = "source"
+	outputDir       = "output"
+	logFile         = "transform.log"
+	tempDir         = "tmp"
+	configFilename  = "m2kconfig.yaml"
+	qaClientTimeout = 10 * time.Second
+)
+
+// Transformation is the status of a transformation returned by the REST API
+type Transformation struct {
+	ID              string               `json:"id"`
+	Name            string               `json:"name"`
+	Status          TransformationStatus `json:"status"`
+	Error           string               `json:"error,omitempty"`
+	CreatedAt       time.Time            `json:"createdAt"`
+	FinishedAt      *time.Time           `json:"finishedAt,omitempty"`
+	PendingProblems []qatypes.Problem    `json:"pendingProblems,omitempty"`
+}
+
+// Limits are the resources each transformation can use
+type Limits struct {
+	// Timeout stops the transformations which take longer. Zero means no limit.
+	Timeout time.Duration
+	// CPUs limits the number of CPUs used at the same time by a transformation, using GOMAXPROCS. Zero means no limit.
+	CPUs int
+	// Memory is the soft memory limit of a transformation, using GOMEMLIMIT. Example: 2GiB . Empty means no limit.
+	Memory string
+}
+
+// transformationOptions are the options given when the transformation was requested
+type transformationOptions struct {
+	name         string
+	format       string
+	sourceFormat string
+	setConfigs   []string
+	qaSkip       bool
+}
+
+// transformation is a transformation running in a separate move2kube process, which asks its questions using the REST QA engine
+type transformation struct {
+	Transformation
+	dir        string
+	opts       transformationOptions
+	qaPort     int
+	cmd        *exec.Cmd
+	hasConfig  bool
+	mutex      sync.Mutex
+	finishedCh chan struct{}
+}
+
+var qaClient = &http.Client{Timeout: qaClientTimeout}
+
+func newTransformationID() string {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return common.GetRandomString()
+	}
+	return hex.EncodeToString(id)
+}
+
+func newTransformation(id, dir string, opts transformationOptions) *transformation {
+	return &transformation{
+		Transformation: Transformation{ID: id, Name: opts.name, Status: QueuedTransformationStatus, CreatedAt: time.Now()},
+		dir:            dir,
+		opts:           opts,
+		finishedCh:     make(chan struct{}),
+	}
+}
+
+func (t *transformation) logPath() string {
+	return filepath.Join(t.dir, logFile)
+}
+
+func (t *transformation) archivePath() string {
+	return filepath.Join(t.dir, outputDir, t.opts.name+"."+t.opts.format)
+}
+
+// setup extracts the uploaded source archive and stores the uploaded config file in the directory of the transformation
+func (t *transformation) setup(source io.Reader, config io.ReadCloser) error {
+	if err := os.MkdirAll(t.dir, common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the directory %s . Error: %w", t.dir, err)
+	}
+	archivePath := filepath.Join(t.dir, sourceDir+"."+t.opts.sourceFormat)
+	if err := writeUploadedFile(archivePath, source); err != nil {
+		return err
+	}
+	defer os.Remove(archivePath)
+	if err := lib.ExtractArchive(archivePath, t.opts.sourceFormat, filepath.Join(t.dir, sourceDir)); err != nil {
+		return fmt.Errorf("failed to extract the uploaded source archive. Error: %w", err)
+	}
+	if config != nil {
+		defer config.Close()
+		if err := writeUploadedFile(filepath.Join(t.dir, configFilename), config); err != nil {
+			return err
+		}
+		t.hasConfig = true
+	}
+	return nil
+}
+
+func writeUploadedFile(path string, contents io.Reader) error {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, common.DefaultFilePermission)
+	if err != nil {
+		return fmt.Errorf("failed to create the file %s . Error: %w", path, err)
+	}
+	if _, err := io.Copy(file, contents); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write the uploaded file to %s . Error: %w", path, err)
+	}
+	return file.Close()
+}
+
+// start runs the transform command in the directory of the transformation, writing its logs to the log file.
+// Each transformation has its own temporary directory, so that the transformations running at the same time do not share any files.
+// The onFinish function is called once the command exits.
+func (t *transformation) start(executable string, limits Limits, onFinish func()) error {
+	args := []string{
+		"transform",
+		"--source", filepath.Join(t.dir, sourceDir),
+		"--output", filepath.Join(t.dir, outputDir),
+		"--name", t.opts.name,
+		"--archive", t.opts.format,
+		"--config-out", t.dir,
+		"--qa-cache-out", t.dir,
+	}
+	if t.hasConfig {
+		args = append(args, "--config", filepath.Join(t.dir, configFilename))
+	}
+	for _, setConfig := range t.opts.setConfigs {
+		args = append(args, "--set-config", setConfig)
+	}
+	cmd := exec.Command(executable)
+	// the processes started by the transformation are stopped along with it
+	setProcessGroup(cmd)
+	var listenerFile *os.File
+	if t.opts.qaSkip {
+		args = append(args, "--qa-skip")
+	} else {
+		qaArgs, port, file, err := passQAListener(cmd)
+		if err != nil {
+			return fmt.Errorf("failed to set up the QA engine of the transformation. Error: %w", err)
+		}
+		listenerFile = file
+		t.mutex.Lock()
+		t.qaPort = port
+		t.mutex.Unlock()
+		// the QA engine only accepts the connections of the server, which passes the answers on
+		args = append(args, "--qa-disable-cli", "--qa-host", "127.0.0.1")
+		args = append(args, qaArgs...)
+	}
+	cmd.Args = append(cmd.Args, args...)
+	// the QA engine of the transformation has its own copy of the listener once it has started
+	closeListenerFile := func() {
+		if listenerFile != nil {
+			listenerFile.Close()
+		}
+	}
+	logs, err := os.OpenFile(t.logPath(), os.O_CREATE|os.O_TRUNC|os.O_WRONLY, common.DefaultFilePermission)
+	if err != nil {
+		closeListenerFile()
+		return fmt.Errorf("failed to create the log file of the transformation. Error: %w", err)
+	}
+	tempPath := filepath.Join(t.dir, tempDir)
+	if err := os.MkdirAll(tempPath, common.DefaultDirectoryPermission); err != nil {
+		closeListenerFile()
+		logs.Close()
+		return fmt.Errorf("failed to create the temporary directory of the transformation. Error: %w", err)
+	}
+	ctx, cancelTimeout := context.WithCancel(context.Background())
+	if limits.Timeout > 0 {
+		ctx, cancelTimeout = context.WithTimeout(context.Background(), limits.Timeout)
+	}
+	// The plan and the config files in the current directory are picked up by the transform command, so an empty directory is used.
+	cmd.Dir = t.dir
+	cmd.Env = append(os.Environ(), "TMPDIR="+tempPath, "TMP="+tempPath, "TEMP="+tempPath)
+	if limits.CPUs > 0 {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("GOMAXPROCS=%d", limits.CPUs))
+	}
+	if limits.Memory != "" {
+		cmd.Env = append(cmd.Env, "GOMEMLIMIT="+limits.Memory)
+	}
+	cmd.Stdout = logs
+	cmd.Stderr = logs
+	err = cmd.Start()
+	closeListenerFile()
+	if err != nil {
+		cancelTimeout()
+		logs.Close()
+		return fmt.Errorf("failed to run the transform command. Error: %w", err)
+	}
+	t.mutex.Lock()
+	t.cmd = cmd
+	t.Status = RunningTransformationStatus
+	t.mutex.Unlock()
+	go func() {
+		<-ctx.Done()
+		if ctx.Err() == context.DeadlineExceeded {
+			if err := killProcessGroup(cmd); err != nil {
+				logrus.Errorf("failed to stop the transformation %s which took too long. Error: %q", t.ID, err)
+			}
+		}
+	}()
+	go func() {
+		err := cmd.Wait()
+		timedOut := ctx.Err() == context.DeadlineExceeded
+		cancelTimeout()
+		logs.Close()
+		if err := os.RemoveAll(tempPath); err != nil {
+			logrus.Warnf("failed to remove the temporary directory %s of the transformation %s . Error: %q", tempPath, t.ID, err)
+		}
+		t.mutex.Lock()
+		finishedAt := time.Now()
+		t.FinishedAt = &finishedAt
+		switch {
+		case t.Status == CancelledTransformationStatus:
+		case timedOut:
+			t.Status = FailedTransformationStatus
+			t.Error = fmt.Sprintf("the transformation was stopped since it took longer than %s", limits.Timeout)
+		case err != nil:
+			t.Status = FailedTransformationStatus
+			t.Error = fmt.Sprintf("the transform command failed, the details can be found in the logs. Error: %q", err)
+		default:
+			t.Status = SucceededTransformationStatus
+		}
+		logrus.Infof("The transformation %s is %s", t.ID, t.Status)
+		close(t.finishedCh)
+		t.mutex.Unlock()
+		onFinish()
+	}()
+	return nil
+}
+
+// fail marks the transformation which could not be started as failed
+func (t *transformation) fail(reason string) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	finishedAt := time.Now()
+	t.FinishedAt = &finishedAt
+	t.Status = FailedTransformationStatus
+	t.Error = reason
+	close(t.finishedCh)
+}
+
+// isRunning returns true if the transformation was started and has not finished yet
+func (t *transformation) isRunning() bool {
+	t.mutex.Lock()
+	started := t.cmd != nil
+	t.mutex.Unlock()
+	return started && !t.isFinished()
+}
+
+func (t *transformation) isFinished() bool {
+	select {
+	case <-t.finishedCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// cancel stops the transformation if it is still running
+func (t *transformation) cancel() {
+	t.mutex.Lock()
+	if t.isFinished() {
+		t.mutex.Unlock()
+		return
+	}
+	if t.cmd == nil {
+		// the transformation is still queued
+		finishedAt := time.Now()
+		t.FinishedAt = &finishedAt
+		t.Status = CancelledTransformationStatus
+		close(t.finishedCh)
+		t.mutex.Unlock()
+		return
+	}
+	t.Status = CancelledTransformationStatus
+	if err := killProcessGroup(t.cmd); err != nil {
+		logrus.Errorf("failed to stop the transformation %s . Error: %q", t.ID, err)
+	}
+	t.mutex.Unlock()
+	<-t.finishedCh
+}
+
+// getStatus returns the status of the transformation. The pending questions are fetched from the transformation if asked for.
+func (t *transformation) getStatus(withProblems bool) Transformation {
+	t.mutex.Lock()
+	status := t.Transformation
+	qaPort := t.qaPort
+	t.mutex.Unlock()
+	if status.Status != RunningTransformationStatus || qaPort == 0 {
+		return status
+	}
+	problems, err := t.getProblems()
+	if err != nil {
+		logrus.Debugf("failed to get the pending questions of the transformation %s . Error: %q", t.ID, err)
+		return status
+	}
+	if len(problems) != 0 {
+		status.Status = WaitingForAnswerTransformationStatus
+		if withProblems {
+			status.PendingProblems = problems
+		}
+	}
+	return status
+}
+
+// getFinishedAt returns the time the transformation finished, or nil if it has not finished yet
+func (t *transformation) getFinishedAt() *time.Time {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.FinishedAt
+}
+
+// getQAPort returns the port of the REST QA engine of the transformation, or zero if the default answers are used
+func (t *transformation) getQAPort() int {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return t.qaPort
+}
+
+// getProblems returns the questions the transformation is waiting for
+func (t *transformation) getProblems() ([]qatypes.Problem, error) {
+	qaPort := t.getQAPort()
+	if qaPort == 0 || t.isFinished() {
+		return []qatypes.Problem{}, nil
+	}
+	resp, err := qaClient.Get(fmt.Sprintf("http://127.0.0.1:%d/problems", qaPort))
+	if err != nil {
+		return nil, fmt.Errorf("the QA engine of the transformation is not available yet. Error: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("the QA engine of the transformation returned the status %s", resp.Status)
+	}
+	problems := []qatypes.Problem{}
+	if err := json.NewDecoder(resp.Body).Decode(&problems); err != nil {
+		return nil, fmt.Errorf("failed to decode the questions of the transformation. Error: %w", err)
+	}
+	return problems, nil
+}
+
+// postSolution passes the answer of a question to the transformation and returns the status and the body of its response
+func (t *transformation) postSolution(problemID string, solution io.Reader) (int, []byte, error) {
+	qaPort := t.getQAPort()
+	if qaPort == 0 || t.isFinished() {
+		return http.StatusConflict, []byte("the transformation is not waiting for any answers"), nil
+	}
+	resp, err := qaClient.Post(fmt.Sprintf("http://127.0.0.1:%d/problems/%s/solution", qaPort, url.PathEscape(problemID)), "application/json", solution)
+	if err != nil {
+		return 0, nil, fmt.Errorf("the QA engine of the transformation is not available. Error: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read the response of the QA engine of the transformation. Error: %w", err)
+	}
+	return resp.StatusCode, body, nil
+}
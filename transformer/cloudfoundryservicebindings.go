@@ -0,0 +1,216 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// serviceBindingRootEnvName is read by the binding libraries, like Spring Cloud Bindings and Quarkus, to find the bindings
+	serviceBindingRootEnvName = "SERVICE_BINDING_ROOT"
+	defaultServiceBindingRoot = "/bindings"
+	serviceBindingTypeKey     = "type"
+	serviceBindingProviderKey = "provider"
+	// projectedServiceBindings mounts the binding secrets in the pods, which needs no controller in the cluster
+	projectedServiceBindings = "projected"
+	// crServiceBindings creates ServiceBinding resources, which a servicebinding.io controller projects into the workloads
+	crServiceBindings = "servicebinding"
+	noServiceBindings = "none"
+)
+
+var (
+	// serviceBindingTypes maps the keywords in the labels and tags of the service offerings to the well known binding types
+	serviceBindingTypes = []struct {
+		keywords    []string
+		bindingType string
+	}{
+		{keywords: []string{"postgres", "elephantsql"}, bindingType: "postgresql"},
+		{keywords: []string{"mysql", "cleardb", "mariadb"}, bindingType: "mysql"},
+		{keywords: []string{"mssql", "sqlserver"}, bindingType: "sqlserver"},
+		{keywords: []string{"oracle"}, bindingType: "oracle"},
+		{keywords: []string{"db2"}, bindingType: "db2"},
+		{keywords: []string{"mongo"}, bindingType: "mongodb"},
+		{keywords: []string{"redis"}, bindingType: "redis"},
+		{keywords: []string{"rabbit", "amqp"}, bindingType: "rabbitmq"},
+		{keywords: []string{"kafka"}, bindingType: "kafka"},
+		{keywords: []string{"elastic"}, bindingType: "elasticsearch"},
+		{keywords: []string{"cassandra"}, bindingType: "cassandra"},
+		{keywords: []string{"config-server", "p-config-server", "p.config-server"}, bindingType: "config"},
+		{keywords: []string{"service-registry", "eureka"}, bindingType: "eureka"},
+		{keywords: []string{"vault"}, bindingType: "vault"},
+	}
+	// serviceBindingKeyAliases are the keys the binding libraries expect, for the keys the Cloud Foundry service brokers commonly use
+	serviceBindingKeyAliases = map[string]string{"hostname": "host", "user": "username", "name": "database"}
+	// sqlServiceBindingTypes are the types whose name credential is the name of the database
+	sqlServiceBindingTypes = []string{"postgresql", "mysql", "sqlserver", "oracle", "db2"}
+	secretKeyRegex         = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+)
+
+// getVcapServiceBindings returns the bindings of the service instances in VCAP_SERVICES, along with the secrets holding their credentials
+func getVcapServiceBindings(vcapServices string, serviceName string) ([]irtypes.ServiceBinding, []irtypes.Storage) {
+	serviceInstanceMap := map[string][]artifacts.VCAPService{}
+	if err := json.Unmarshal([]byte(vcapServices), &serviceInstanceMap); err != nil {
+		logrus.Debugf("Unable to parse the %s environment variable for the service bindings. Error: %q", common.VcapServiceEnvName, err)
+		return nil, nil
+	}
+	offerings := []string{}
+	for offering := range serviceInstanceMap {
+		offerings = append(offerings, offering)
+	}
+	sort.Strings(offerings)
+	bindings := []irtypes.ServiceBinding{}
+	storages := []irtypes.Storage{}
+	for _, offering := range offerings {
+		for _, serviceInstance := range serviceInstanceMap[offering] {
+			if serviceInstance.ServiceName == "" {
+				continue
+			}
+			provider := serviceInstance.Label
+			if provider == "" {
+				provider = offering
+			}
+			binding := irtypes.ServiceBinding{
+				Name:       common.MakeStringK8sServiceNameCompliant(serviceInstance.ServiceName),
+				SecretName: common.MakeStringK8sServiceNameCompliant(serviceName + "-" + serviceInstance.ServiceName + "-binding"),
+				Type:       getServiceBindingType(provider, serviceInstance.Tags),
+				Provider:   provider,
+			}
+			content := map[string][]byte{}
+			for key, value := range serviceInstance.ServiceCredentials {
+				if !secretKeyRegex.MatchString(key) {
+					logrus.Debugf("Ignoring the credential %s of the service instance %s, since it is not a valid key of a secret", key, serviceInstance.ServiceName)
+					continue
+				}
+				content[key] = getServiceBindingValue(value)
+			}
+			for key, alias := range serviceBindingKeyAliases {
+				if alias == "database" && !common.IsPresent(sqlServiceBindingTypes, binding.Type) {
+					continue
+				}
+				if _, ok := content[alias]; !ok && content[key] != nil {
+					content[alias] = content[key]
+				}
+			}
+			content[serviceBindingTypeKey] = []byte(binding.Type)
+			content[serviceBindingProviderKey] = []byte(binding.Provider)
+			bindings = append(bindings, binding)
+			storages = append(storages, irtypes.Storage{Name: binding.SecretName, StorageType: irtypes.SecretKind, SecretType: core.SecretTypeOpaque, Content: content})
+		}
+	}
+	return bindings, storages
+}
+
+// getServiceBindingType returns the binding type of the service offering, the label itself when it is not a well known type
+func getServiceBindingType(label string, tags []string) string {
+	names := strings.ToLower(strings.Join(append([]string{label}, tags...), " "))
+	for _, serviceBindingType := range serviceBindingTypes {
+		for _, keyword := range serviceBindingType.keywords {
+			if strings.Contains(names, keyword) {
+				return serviceBindingType.bindingType
+			}
+		}
+	}
+	return strings.ToLower(label)
+}
+
+// getServiceBindingValue returns the value of a credential as the content of a file, the nested values are written as json
+func getServiceBindingValue(value interface{}) []byte {
+	switch v := value.(type) {
+	case string:
+		return []byte(v)
+	case float64, bool:
+		return []byte(fmt.Sprintf("%v", v))
+	case nil:
+		return []byte{}
+	}
+	valueJSON, err := json.Marshal(value)
+	if err != nil {
+		logrus.Debugf("Unable to marshal the credential %+v to json. Error: %q", value, err)
+		return []byte(fmt.Sprintf("%v", value))
+	}
+	return valueJSON
+}
+
+// addServiceBindings makes the bound Cloud Foundry services available to the service in the layout of the servicebinding.io specification
+// The apps collected from the cluster have VCAP_SERVICES as a json object, instead of a string.
+func addServiceBindings(irService *irtypes.Service, container *core.Container, vcapServices interface{}) []irtypes.Storage {
+	vcapServicesStr, ok := vcapServices.(string)
+	if !ok {
+		vcapServicesJSON, err := json.Marshal(vcapServices)
+		if err != nil {
+			logrus.Debugf("Unable to marshal the %s environment variable to json. Error: %q", common.VcapServiceEnvName, err)
+			return nil
+		}
+		vcapServicesStr = string(vcapServicesJSON)
+	}
+	bindings, storages := getVcapServiceBindings(vcapServicesStr, irService.Name)
+	if len(bindings) == 0 {
+		return nil
+	}
+	names := []string{}
+	for _, binding := range bindings {
+		names = append(names, fmt.Sprintf("%s (%s)", binding.Name, binding.Type))
+	}
+	quesKey := common.JoinQASubKeys(common.ConfigServicesKey, `"`+irService.Name+`"`, common.ConfigServiceBindingsForServiceKeySegment)
+	bindingsType := qaengine.FetchSelectAnswer(
+		quesKey,
+		fmt.Sprintf("Select how the services bound to the Cloud Foundry app %s should be made available to it :", irService.Name),
+		[]string{
+			fmt.Sprintf("The bound services are %s", strings.Join(names, ", ")),
+			fmt.Sprintf("%s : the binding secrets are mounted under %s, where Spring Cloud Bindings and Quarkus find them", projectedServiceBindings, defaultServiceBindingRoot),
+			fmt.Sprintf("%s : ServiceBinding resources are created, which need a servicebinding.io controller in the cluster", crServiceBindings),
+			fmt.Sprintf("%s : only the %s environment variable is kept", noServiceBindings, common.VcapServiceEnvName),
+		},
+		projectedServiceBindings,
+		[]string{projectedServiceBindings, crServiceBindings, noServiceBindings},
+		nil,
+	)
+	switch bindingsType {
+	case projectedServiceBindings:
+		for _, binding := range bindings {
+			irService.Volumes = append(irService.Volumes, core.Volume{
+				Name:         binding.SecretName,
+				VolumeSource: core.VolumeSource{Secret: &core.SecretVolumeSource{SecretName: binding.SecretName}},
+			})
+			container.VolumeMounts = append(container.VolumeMounts, core.VolumeMount{
+				Name:      binding.SecretName,
+				MountPath: path.Join(defaultServiceBindingRoot, binding.Name),
+				ReadOnly:  true,
+			})
+		}
+		container.Env = append(container.Env, core.EnvVar{Name: serviceBindingRootEnvName, Value: defaultServiceBindingRoot})
+	case crServiceBindings:
+		irService.ServiceBindings = append(irService.ServiceBindings, bindings...)
+		logrus.Infof("The services bound to the app %s are projected by ServiceBinding resources. A servicebinding.io controller needs to be installed in the cluster.", irService.Name)
+	default:
+		return nil
+	}
+	return storages
+}
@@ -0,0 +1,179 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const testVcapServices = `{
+	"elephantsql": [{
+		"name": "orders db",
+		"label": "elephantsql",
+		"tags": ["relational"],
+		"credentials": {"hostname": "db.example.com", "port": 5432, "user": "admin", "name": "orders", "ssl": true, "uri": "postgres://db.example.com/orders", "bad key": "x"}
+	}],
+	"user-provided": [{
+		"name": "cache",
+		"label": "user-provided",
+		"tags": ["Redis"],
+		"credentials": {"host": "cache.example.com", "name": "sessions", "options": {"tls": true}}
+	}, {"name": ""}]
+}`
+
+func setupServiceBindingsQA(t *testing.T, configs ...string) {
+	t.Helper()
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", configs, nil, nil, false)
+}
+
+func TestGetServiceBindingType(t *testing.T) {
+	testCases := []struct {
+		label string
+		tags  []string
+		want  string
+	}{
+		{label: "elephantsql", want: "postgresql"},
+		{label: "p.mysql", want: "mysql"},
+		{label: "user-provided", tags: []string{"MongoDB"}, want: "mongodb"},
+		{label: "p-config-server", want: "config"},
+		{label: "Custom-Broker", want: "custom-broker"},
+	}
+	for _, tc := range testCases {
+		if got := getServiceBindingType(tc.label, tc.tags); got != tc.want {
+			t.Errorf("getServiceBindingType(%q, %q) = %q, want %q", tc.label, tc.tags, got, tc.want)
+		}
+	}
+}
+
+func TestGetVcapServiceBindings(t *testing.T) {
+	bindings, storages := getVcapServiceBindings(testVcapServices, "app1")
+	wantBindings := []irtypes.ServiceBinding{
+		{Name: "orders-db", SecretName: "app1-orders-db-binding", Type: "postgresql", Provider: "elephantsql"},
+		{Name: "cache", SecretName: "app1-cache-binding", Type: "redis", Provider: "user-provided"},
+	}
+	if diff := cmp.Diff(wantBindings, bindings); diff != "" {
+		t.Fatalf("the bindings were not created correctly. Diff (-want +got):\n%s", diff)
+	}
+	wantContents := []map[string][]byte{{
+		"hostname": []byte("db.example.com"),
+		"host":     []byte("db.example.com"),
+		"port":     []byte("5432"),
+		"user":     []byte("admin"),
+		"username": []byte("admin"),
+		"name":     []byte("orders"),
+		"database": []byte("orders"),
+		"ssl":      []byte("true"),
+		"uri":      []byte("postgres://db.example.com/orders"),
+		"type":     []byte("postgresql"),
+		"provider": []byte("elephantsql"),
+	}, {
+		// the name of a non sql service is not the name of a database
+		"host":     []byte("cache.example.com"),
+		"name":     []byte("sessions"),
+		"options":  []byte(`{"tls":true}`),
+		"type":     []byte("redis"),
+		"provider": []byte("user-provided"),
+	}}
+	if len(storages) != len(wantContents) {
+		t.Fatalf("expected %d secrets, got %+v", len(wantContents), storages)
+	}
+	for i, storage := range storages {
+		if storage.Name != wantBindings[i].SecretName || storage.StorageType != irtypes.SecretKind || storage.SecretType != core.SecretTypeOpaque {
+			t.Errorf("expected the opaque secret %s , got %+v", wantBindings[i].SecretName, storage)
+		}
+		if diff := cmp.Diff(wantContents[i], storage.Content); diff != "" {
+			t.Errorf("the contents of the secret %s are not correct. Diff (-want +got):\n%s", storage.Name, diff)
+		}
+	}
+	if bindings, storages := getVcapServiceBindings("not json", "app1"); len(bindings) != 0 || len(storages) != 0 {
+		t.Errorf("expected no bindings for an invalid %s , got %+v and %+v", common.VcapServiceEnvName, bindings, storages)
+	}
+}
+
+func TestAddServiceBindings(t *testing.T) {
+	newService := func() (irtypes.Service, core.Container) {
+		return irtypes.NewServiceWithName("app1"), core.Container{Name: "app1"}
+	}
+	quesKey := common.JoinQASubKeys(common.ConfigServicesKey, `"app1"`, common.ConfigServiceBindingsForServiceKeySegment)
+
+	t.Run("the binding secrets are projected into the pods by default", func(t *testing.T) {
+		setupServiceBindingsQA(t)
+		service, container := newService()
+		storages := addServiceBindings(&service, &container, testVcapServices)
+		if len(storages) != 2 {
+			t.Fatalf("expected the secrets of the 2 bindings, got %+v", storages)
+		}
+		wantVolumes := []core.Volume{
+			{Name: "app1-orders-db-binding", VolumeSource: core.VolumeSource{Secret: &core.SecretVolumeSource{SecretName: "app1-orders-db-binding"}}},
+			{Name: "app1-cache-binding", VolumeSource: core.VolumeSource{Secret: &core.SecretVolumeSource{SecretName: "app1-cache-binding"}}},
+		}
+		if diff := cmp.Diff(wantVolumes, service.Volumes); diff != "" {
+			t.Errorf("the volumes of the bindings are not correct. Diff (-want +got):\n%s", diff)
+		}
+		wantMounts := []core.VolumeMount{
+			{Name: "app1-orders-db-binding", MountPath: "/bindings/orders-db", ReadOnly: true},
+			{Name: "app1-cache-binding", MountPath: "/bindings/cache", ReadOnly: true},
+		}
+		if diff := cmp.Diff(wantMounts, container.VolumeMounts); diff != "" {
+			t.Errorf("the bindings are not mounted correctly. Diff (-want +got):\n%s", diff)
+		}
+		if diff := cmp.Diff([]core.EnvVar{{Name: serviceBindingRootEnvName, Value: defaultServiceBindingRoot}}, container.Env); diff != "" {
+			t.Errorf("the binding root is not set correctly. Diff (-want +got):\n%s", diff)
+		}
+		if len(service.ServiceBindings) != 0 {
+			t.Errorf("expected no ServiceBinding resources, got %+v", service.ServiceBindings)
+		}
+	})
+
+	t.Run("the ServiceBinding resources project the bindings", func(t *testing.T) {
+		setupServiceBindingsQA(t, quesKey+`="`+crServiceBindings+`"`)
+		service, container := newService()
+		// the apps collected from the cluster have VCAP_SERVICES as a json object
+		vcapServices := map[string]interface{}{"p.redis": []interface{}{map[string]interface{}{"name": "cache"}}}
+		storages := addServiceBindings(&service, &container, vcapServices)
+		if len(storages) != 1 || storages[0].Name != "app1-cache-binding" {
+			t.Fatalf("expected the secret of the cache binding, got %+v", storages)
+		}
+		want := []irtypes.ServiceBinding{{Name: "cache", SecretName: "app1-cache-binding", Type: "redis", Provider: "p.redis"}}
+		if diff := cmp.Diff(want, service.ServiceBindings); diff != "" {
+			t.Errorf("the ServiceBinding resources are not correct. Diff (-want +got):\n%s", diff)
+		}
+		if len(service.Volumes) != 0 || len(container.VolumeMounts) != 0 || len(container.Env) != 0 {
+			t.Errorf("expected the bindings to not be mounted, got the volumes %+v , the mounts %+v and the env %+v", service.Volumes, container.VolumeMounts, container.Env)
+		}
+	})
+
+	t.Run("only VCAP_SERVICES is kept", func(t *testing.T) {
+		setupServiceBindingsQA(t, quesKey+`="`+noServiceBindings+`"`)
+		service, container := newService()
+		if storages := addServiceBindings(&service, &container, testVcapServices); len(storages) != 0 {
+			t.Errorf("expected no secrets, got %+v", storages)
+		}
+		if len(service.Volumes) != 0 || len(service.ServiceBindings) != 0 || len(container.Env) != 0 {
+			t.Errorf("expected the service to be unchanged, got %+v", service)
+		}
+	})
+}
@@ -19,6 +19,7 @@ package transformer
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -43,21 +44,74 @@ import (
 const (
 	// ResourceRequestKey is the config key for resource requests
 	ResourceRequestKey = "ResourceRequest"
+
+	// Cloud Foundry enforces the memory and the disk quotas as hard limits and reserves the memory quota on the cells
+	defaultCfMemoryRequestRatio           = 1.0
+	defaultCfMemoryLimitRatio             = 1.0
+	defaultCfCPURequestPerGi              = "250m"
+	defaultCfEphemeralStorageRequestRatio = 0.5
+	defaultCfEphemeralStorageLimitRatio   = 1.0
+
+	// Cloud Foundry stops routing to an instance before stopping it and then gives it 10 seconds to exit after SIGTERM
+	defaultCfPreStopDelaySeconds        = 5
+	defaultCfShutdownGracePeriodSeconds = 10
 )
 
+// cfStackEnv are the locale and the time zone set in the containers of the Cloud Foundry stacks, which the images often do not set
+var cfStackEnv = map[string]string{"LANG": "en_US.UTF-8", "TZ": "UTC"}
+
 // variableLiteralPattern to identify variable literals in environment names
 var variableLiteralPattern = regexp.MustCompile(`[-.+~\x60!@#$%^&*(){}\[\]:;"',?<>/]`)
 
 // CloudFoundry implements Transformer interface
 type CloudFoundry struct {
-	Config transformertypes.Transformer
-	Env    *environment.Environment
+	Config   transformertypes.Transformer
+	Env      *environment.Environment
+	CfConfig *CloudFoundryYamlConfig
+}
+
+// CloudFoundryYamlConfig stores the transformer specific configuration
+type CloudFoundryYamlConfig struct {
+	ResourceMapping CfResourceMapping `yaml:"resourceMapping"`
+}
+
+// CfResourceMapping is the formula mapping the memory and disk quotas of the apps to the resources of the containers.
+// The memory and the ephemeral storage are the quotas multiplied by the ratios.
+// The cpu is proportional to the memory quota, like the cpu shares of the Cloud Foundry containers.
+type CfResourceMapping struct {
+	MemoryRequestRatio           float64 `yaml:"memoryRequestRatio"`
+	MemoryLimitRatio             float64 `yaml:"memoryLimitRatio"`
+	CPURequestPerGi              string  `yaml:"cpuRequestPerGi"`
+	CPULimitPerGi                string  `yaml:"cpuLimitPerGi"`
+	EphemeralStorageRequestRatio float64 `yaml:"ephemeralStorageRequestRatio"`
+	EphemeralStorageLimitRatio   float64 `yaml:"ephemeralStorageLimitRatio"`
 }
 
 // Init Initializes the transformer
 func (t *CloudFoundry) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
 	t.Config = tc
 	t.Env = env
+	t.CfConfig = &CloudFoundryYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.CfConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.CfConfig, err)
+		return err
+	}
+	mapping := &t.CfConfig.ResourceMapping
+	if mapping.MemoryRequestRatio <= 0 {
+		mapping.MemoryRequestRatio = defaultCfMemoryRequestRatio
+	}
+	if mapping.MemoryLimitRatio <= 0 {
+		mapping.MemoryLimitRatio = defaultCfMemoryLimitRatio
+	}
+	if mapping.CPURequestPerGi == "" {
+		mapping.CPURequestPerGi = defaultCfCPURequestPerGi
+	}
+	if mapping.EphemeralStorageRequestRatio <= 0 {
+		mapping.EphemeralStorageRequestRatio = defaultCfEphemeralStorageRequestRatio
+	}
+	if mapping.EphemeralStorageLimitRatio <= 0 {
+		mapping.EphemeralStorageLimitRatio = defaultCfEphemeralStorageLimitRatio
+	}
 	return nil
 }
 
@@ -186,10 +240,8 @@ func (t *CloudFoundry) Transform(newArtifacts []transformertypes.Artifact, alrea
 			logrus.Debugf("Using cf manifest file at path %s to transform service %s", path, cfConfig.ServiceName)
 			application := applications[0]
 			irService := irtypes.Service{Name: serviceConfig.ServiceName}
-			rList := core.ResourceList{"memory": resource.MustParse(fmt.Sprintf("%dM", cfinstanceapp.Application.Memory)),
-				"ephemeral-storage": resource.MustParse(fmt.Sprintf("%dM", cfinstanceapp.Application.DiskQuota))}
 			serviceContainer := core.Container{Name: serviceConfig.ServiceName,
-				Resources: core.ResourceRequirements{Requests: rList}}
+				Resources: getCfAppResources(cfinstanceapp, application, t.CfConfig.ResourceMapping)}
 			serviceContainer.Image = cfConfig.ImageName
 			if serviceContainer.Image == "" {
 				serviceContainer.Image = serviceConfig.ServiceName
@@ -206,6 +258,9 @@ func (t *CloudFoundry) Transform(newArtifacts []transformertypes.Artifact, alrea
 			ir.Storages = append(ir.Storages, irtypes.Storage{Name: secretName,
 				StorageType: irtypes.SecretKind,
 				Content:     vcapEnvMap})
+			if vcapServices, ok := cfinstanceapp.Environment.SystemEnv[common.VcapServiceEnvName]; ok {
+				ir.Storages = append(ir.Storages, addServiceBindings(&irService, &serviceContainer, vcapServices)...)
+			}
 			for _, port := range cfinstanceapp.Application.Ports {
 				// Add the port to the k8s pod.
 				serviceContainer.Ports = append(serviceContainer.Ports, core.ContainerPort{ContainerPort: int32(port)})
@@ -214,7 +269,20 @@ func (t *CloudFoundry) Transform(newArtifacts []transformertypes.Artifact, alrea
 				servicePort := podPort
 				irService.AddPortForwarding(servicePort, podPort, "")
 			}
+			// the bound service instances are waited for if they are deployed as services
+			for _, boundService := range application.Services {
+				irService.DependsOn = common.AppendIfNotPresent(irService.DependsOn, common.MakeStringK8sServiceNameCompliant(boundService))
+			}
 			irService.Containers = []core.Container{serviceContainer}
+			// the cf router keeps the clients on the same instance when the app sets the JSESSIONID cookie
+			if serviceDirs := a.Paths[artifacts.ServiceDirPathType]; len(serviceDirs) != 0 {
+				irService.SessionAffinity = common.UsesServerSideSessions(serviceDirs[0])
+			}
+			// the endpoint is removed asynchronously, so the pods keep serving the in flight requests for a while like the cf instances do
+			irService.PreStopDelaySeconds = defaultCfPreStopDelaySeconds
+			terminationGracePeriodSeconds := int64(defaultCfPreStopDelaySeconds + defaultCfShutdownGracePeriodSeconds)
+			irService.TerminationGracePeriodSeconds = &terminationGracePeriodSeconds
+			irService.Annotations = getCfUnmappedTODOs(serviceConfig.ServiceName, cfinstanceapp, application)
 			ir.Services[serviceConfig.ServiceName] = irService
 		}
 		if len(containerizationOptionsConfig) != 0 {
@@ -268,6 +336,10 @@ func (t *CloudFoundry) prioritizeAndAddEnvironmentVariables(cfApp collecttypes.C
 	manifestEnvMap map[string]string, secretName string, serviceName string) ([]core.EnvVar, map[string][]byte) {
 	vcapEnvMap := map[string][]byte{}
 	envOrderMap := map[string]core.EnvVar{}
+	// Stack
+	for varname, value := range cfStackEnv {
+		envOrderMap[varname] = core.EnvVar{Name: varname, Value: value}
+	}
 	// Manifest
 	for varname, value := range manifestEnvMap {
 		envOrderMap[varname] = core.EnvVar{Name: varname, Value: value}
@@ -280,6 +352,12 @@ func (t *CloudFoundry) prioritizeAndAddEnvironmentVariables(cfApp collecttypes.C
 	}
 	for varname, value := range cfApp.Environment.SystemEnv {
 		valueStr := fmt.Sprintf("%s", value)
+		if _, ok := value.(string); !ok && varname == common.VcapServiceEnvName {
+			// the apps collected from the cluster have VCAP_SERVICES as a json object
+			if valueJSON, err := json.Marshal(value); err == nil {
+				valueStr = string(valueJSON)
+			}
+		}
 		if varname == common.VcapServiceEnvName && valueStr != "" {
 			flattenedEnvList := flattenVcapServiceVariables(valueStr, serviceName)
 			for _, env := range flattenedEnvList {
@@ -451,3 +529,68 @@ func getCfAppInstance(path string, appname string) (collecttypes.CfApp, error) {
 	}
 	return collecttypes.CfApp{}, fmt.Errorf("failed to find the app %s in the cf apps file at path %s", appname, path)
 }
+
+// getCfAppResources returns the container resources using the memory and disk quota of the running app instance if available, else the manifest.
+// The quotas are in mebibytes, which Cloud Foundry writes as M.
+func getCfAppResources(cfinstanceapp collecttypes.CfApp, application manifest.Application, mapping CfResourceMapping) core.ResourceRequirements {
+	memory := uint64(cfinstanceapp.Application.Memory)
+	if memory == 0 && application.Memory.IsSet {
+		memory = application.Memory.Value
+	}
+	diskQuota := uint64(cfinstanceapp.Application.DiskQuota)
+	if diskQuota == 0 && application.DiskQuota.IsSet {
+		diskQuota = application.DiskQuota.Value
+	}
+	requests := core.ResourceList{}
+	limits := core.ResourceList{}
+	if memory != 0 {
+		requests[core.ResourceMemory] = getCfQuantityInMi(memory, mapping.MemoryRequestRatio)
+		limits[core.ResourceMemory] = getCfQuantityInMi(memory, mapping.MemoryLimitRatio)
+		if cpu, ok := getCfCPUQuantity(memory, mapping.CPURequestPerGi); ok {
+			requests[core.ResourceCPU] = cpu
+		}
+		if cpu, ok := getCfCPUQuantity(memory, mapping.CPULimitPerGi); ok {
+			limits[core.ResourceCPU] = cpu
+		}
+	}
+	if diskQuota != 0 {
+		requests[core.ResourceEphemeralStorage] = getCfQuantityInMi(diskQuota, mapping.EphemeralStorageRequestRatio)
+		limits[core.ResourceEphemeralStorage] = getCfQuantityInMi(diskQuota, mapping.EphemeralStorageLimitRatio)
+	}
+	for name, limit := range limits {
+		if request, ok := requests[name]; ok && request.Cmp(limit) > 0 {
+			logrus.Warnf("The %s request %s of the app %s is more than the limit %s . Using the request as the limit.", name, request.String(), application.Name, limit.String())
+			limits[name] = request
+		}
+	}
+	resources := core.ResourceRequirements{}
+	if len(requests) != 0 {
+		resources.Requests = requests
+	}
+	if len(limits) != 0 {
+		resources.Limits = limits
+	}
+	return resources
+}
+
+// getCfQuantityInMi returns the quota in mebibytes multiplied by the ratio
+func getCfQuantityInMi(quotaInMi uint64, ratio float64) resource.Quantity {
+	return resource.MustParse(fmt.Sprintf("%dMi", int64(math.Round(float64(quotaInMi)*ratio))))
+}
+
+// getCfCPUQuantity returns the cpu proportional to the memory quota, using the cpu per gibibyte of memory
+func getCfCPUQuantity(memoryInMi uint64, cpuPerGi string) (resource.Quantity, bool) {
+	if cpuPerGi == "" {
+		return resource.Quantity{}, false
+	}
+	perGi, err := resource.ParseQuantity(cpuPerGi)
+	if err != nil {
+		logrus.Errorf("failed to parse the cpu per Gi of memory '%s' of the resource mapping. Error: %q", cpuPerGi, err)
+		return resource.Quantity{}, false
+	}
+	milliCPU := int64(math.Round(float64(perGi.MilliValue()) * float64(memoryInMi) / 1024))
+	if milliCPU < 1 {
+		milliCPU = 1
+	}
+	return *resource.NewMilliQuantity(milliCPU, resource.DecimalSI), true
+}
@@ -0,0 +1,119 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"testing"
+
+	"code.cloudfoundry.org/cli/types"
+	"code.cloudfoundry.org/cli/util/manifest"
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	"github.com/google/go-cmp/cmp"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestGetCfAppResources(t *testing.T) {
+	newMapping := func(t *testing.T, config interface{}) CfResourceMapping {
+		t.Helper()
+		cf := &CloudFoundry{}
+		tc := transformertypes.Transformer{}
+		tc.Spec.Config = config
+		if err := cf.Init(tc, nil); err != nil {
+			t.Fatalf("failed to initialize the transformer. Error: %q", err)
+		}
+		return cf.CfConfig.ResourceMapping
+	}
+	manifestApp := manifest.Application{
+		Name:      "web",
+		Memory:    types.NullByteSizeInMb{IsSet: true, Value: 1024},
+		DiskQuota: types.NullByteSizeInMb{IsSet: true, Value: 2048},
+	}
+	assertQuantities := func(t *testing.T, kind string, actual core.ResourceList, want map[core.ResourceName]string) {
+		t.Helper()
+		if len(actual) != len(want) {
+			t.Fatalf("expected the %s %v , got %v", kind, want, actual)
+		}
+		for name, quantity := range want {
+			if got, ok := actual[name]; !ok || got.Cmp(resource.MustParse(quantity)) != 0 {
+				t.Fatalf("expected the %s %s of %s , got %v", name, kind, quantity, actual)
+			}
+		}
+	}
+
+	t.Run("the default mapping of the manifest quotas", func(t *testing.T) {
+		resources := getCfAppResources(collecttypes.CfApp{}, manifestApp, newMapping(t, nil))
+		assertQuantities(t, "requests", resources.Requests, map[core.ResourceName]string{core.ResourceMemory: "1Gi", core.ResourceCPU: "250m", core.ResourceEphemeralStorage: "1Gi"})
+		assertQuantities(t, "limits", resources.Limits, map[core.ResourceName]string{core.ResourceMemory: "1Gi", core.ResourceEphemeralStorage: "2Gi"})
+	})
+
+	t.Run("the quotas of the running app take precedence", func(t *testing.T) {
+		app := collecttypes.CfApp{Application: cfclient.App{Memory: 512, DiskQuota: 1024}}
+		resources := getCfAppResources(app, manifestApp, newMapping(t, nil))
+		assertQuantities(t, "requests", resources.Requests, map[core.ResourceName]string{core.ResourceMemory: "512Mi", core.ResourceCPU: "125m", core.ResourceEphemeralStorage: "512Mi"})
+		assertQuantities(t, "limits", resources.Limits, map[core.ResourceName]string{core.ResourceMemory: "512Mi", core.ResourceEphemeralStorage: "1Gi"})
+	})
+
+	t.Run("the configured mapping", func(t *testing.T) {
+		mapping := newMapping(t, map[string]interface{}{"resourceMapping": map[string]interface{}{
+			"memoryRequestRatio":         0.5,
+			"memoryLimitRatio":           1.5,
+			"cpuRequestPerGi":            "500m",
+			"cpuLimitPerGi":              "2",
+			"ephemeralStorageLimitRatio": 0.25,
+		}})
+		resources := getCfAppResources(collecttypes.CfApp{}, manifestApp, mapping)
+		assertQuantities(t, "requests", resources.Requests, map[core.ResourceName]string{core.ResourceMemory: "512Mi", core.ResourceCPU: "500m", core.ResourceEphemeralStorage: "1Gi"})
+		// the ephemeral storage limit below the request is raised to the request
+		assertQuantities(t, "limits", resources.Limits, map[core.ResourceName]string{core.ResourceMemory: "1536Mi", core.ResourceCPU: "2", core.ResourceEphemeralStorage: "1Gi"})
+	})
+
+	t.Run("no quotas", func(t *testing.T) {
+		resources := getCfAppResources(collecttypes.CfApp{}, manifest.Application{Name: "web"}, newMapping(t, nil))
+		if resources.Requests != nil || resources.Limits != nil {
+			t.Fatalf("expected no resources without the quotas. Actual: %+v", resources)
+		}
+	})
+}
+
+func TestGetCfCPUQuantity(t *testing.T) {
+	if cpu, ok := getCfCPUQuantity(2, "250m"); !ok || cpu.MilliValue() != 1 {
+		t.Fatalf("expected at least a millicpu for the small quotas. Actual: %s", cpu.String())
+	}
+	if _, ok := getCfCPUQuantity(1024, "a lot"); ok {
+		t.Fatalf("expected no cpu for an invalid cpu per Gi")
+	}
+	if _, ok := getCfCPUQuantity(1024, ""); ok {
+		t.Fatalf("expected no cpu when the cpu per Gi is empty")
+	}
+}
+
+func TestPrioritizeAndAddEnvironmentVariablesSetsTheStackEnv(t *testing.T) {
+	cfApp := collecttypes.CfApp{}
+	cfApp.Environment.Environment = map[string]interface{}{"TZ": "Europe/Paris"}
+	envList, _ := (&CloudFoundry{}).prioritizeAndAddEnvironmentVariables(cfApp, map[string]string{"PORT": "8080"}, "web-vcap", "web")
+	got := map[string]string{}
+	for _, env := range envList {
+		got[env.Name] = env.Value
+	}
+	want := map[string]string{"LANG": "en_US.UTF-8", "TZ": "Europe/Paris", "PORT": "8080"}
+	if !cmp.Equal(got, want) {
+		t.Fatalf("the environment variables are different. Difference:\n%s", cmp.Diff(want, got))
+	}
+}
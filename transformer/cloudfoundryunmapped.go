@@ -0,0 +1,145 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"code.cloudfoundry.org/cli/util/manifest"
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// cfTODOCategoryPrefix is the prefix of the TODO categories of the Cloud Foundry constructs which could not be mapped
+	cfTODOCategoryPrefix = "cf."
+)
+
+// getCfUnmappedTODOs returns the TODO annotations of the routes, the route services and the syslog drains of the app,
+// which have no equivalent in the generated resources, so that they are reported instead of being dropped
+func getCfUnmappedTODOs(serviceName string, cfinstanceapp collecttypes.CfApp, application manifest.Application) map[string]string {
+	todos := map[string]string{}
+	addTODO := func(category, message string) {
+		logrus.Warn(message)
+		todos[common.TODOAnnotation+cfTODOCategoryPrefix+category] = message
+	}
+	if routes := getCfRoutes(cfinstanceapp, application); len(routes) != 0 {
+		addTODO("routes", fmt.Sprintf("The Cloud Foundry routes %s of the app %s were not mapped. Expose the service on the same hosts and paths with the Ingress and point the DNS records of the hosts to the cluster.", strings.Join(routes, ", "), serviceName))
+	}
+	routeServices := map[string][]string{}
+	routeServiceNames := []string{}
+	for _, route := range cfinstanceapp.Routes {
+		if route.RouteService == "" {
+			continue
+		}
+		name := route.RouteService
+		if route.RouteServiceURL != "" {
+			name = fmt.Sprintf("%s (%s)", route.RouteService, route.RouteServiceURL)
+		}
+		if _, ok := routeServices[name]; !ok {
+			routeServiceNames = append(routeServiceNames, name)
+		}
+		routeServices[name] = append(routeServices[name], getCfRouteURL(route))
+	}
+	sort.Strings(routeServiceNames)
+	if len(routeServiceNames) != 0 {
+		services := []string{}
+		for _, name := range routeServiceNames {
+			services = append(services, fmt.Sprintf("%s for the routes %s", name, strings.Join(routeServices[name], ", ")))
+		}
+		addTODO("routeservice", fmt.Sprintf("The route services %s process the requests before they reach the app %s and were not mapped. Put an equivalent proxy in front of the service, like an authentication or a rate limiting policy of the ingress controller, or a sidecar.", strings.Join(services, "; "), serviceName))
+	}
+	if drains := getCfSyslogDrains(cfinstanceapp); len(drains) != 0 {
+		// the urls of the drains are left out, since they often contain tokens
+		addTODO("syslogdrain", fmt.Sprintf("The logs of the app %s are drained by the services %s, which were not mapped. Forward the logs of the pods with a log collector running on the nodes, like Fluent Bit.", serviceName, strings.Join(drains, ", ")))
+	}
+	if len(todos) == 0 {
+		return nil
+	}
+	return todos
+}
+
+// getCfRoutes returns the routes of the running app, or else the routes in the manifest
+func getCfRoutes(cfinstanceapp collecttypes.CfApp, application manifest.Application) []string {
+	routes := []string{}
+	if len(cfinstanceapp.Routes) != 0 {
+		for _, route := range cfinstanceapp.Routes {
+			routes = append(routes, getCfRouteURL(route))
+		}
+		return routes
+	}
+	if application.NoRoute {
+		return routes
+	}
+	routes = append(routes, application.Routes...)
+	if len(routes) == 0 && application.Hostname != "" && application.Domain != "" {
+		routes = append(routes, application.Hostname+"."+application.Domain+application.RoutePath)
+	}
+	return routes
+}
+
+// getCfRouteURL returns the route as the cf cli prints it, like host.domain/path or domain:port
+func getCfRouteURL(route collecttypes.CfRoute) string {
+	url := route.Domain
+	if url == "" {
+		url = route.Route.DomainGuid
+	}
+	if route.Route.Host != "" {
+		url = route.Route.Host + "." + url
+	}
+	if route.Route.Port != 0 {
+		url = fmt.Sprintf("%s:%d", url, route.Route.Port)
+	}
+	return url + route.Route.Path
+}
+
+// getCfSyslogDrains returns the names of the bound services which drain the logs of the app
+func getCfSyslogDrains(cfinstanceapp collecttypes.CfApp) []string {
+	vcapServices, ok := cfinstanceapp.Environment.SystemEnv[common.VcapServiceEnvName]
+	if !ok {
+		return nil
+	}
+	vcapServicesStr, ok := vcapServices.(string)
+	if !ok {
+		vcapServicesJSON, err := json.Marshal(vcapServices)
+		if err != nil {
+			logrus.Debugf("Unable to marshal the %s environment variable to json. Error: %q", common.VcapServiceEnvName, err)
+			return nil
+		}
+		vcapServicesStr = string(vcapServicesJSON)
+	}
+	serviceInstanceMap := map[string][]artifacts.VCAPService{}
+	if err := json.Unmarshal([]byte(vcapServicesStr), &serviceInstanceMap); err != nil {
+		logrus.Debugf("Unable to parse the %s environment variable for the syslog drains. Error: %q", common.VcapServiceEnvName, err)
+		return nil
+	}
+	drains := []string{}
+	for _, serviceInstances := range serviceInstanceMap {
+		for _, serviceInstance := range serviceInstances {
+			if serviceInstance.SyslogDrainURL != "" {
+				drains = append(drains, serviceInstance.ServiceName)
+			}
+		}
+	}
+	sort.Strings(drains)
+	return drains
+}
@@ -0,0 +1,143 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"testing"
+
+	"code.cloudfoundry.org/cli/util/manifest"
+	cfclient "github.com/cloudfoundry-community/go-cfclient"
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+)
+
+func TestGetCfRoutes(t *testing.T) {
+	testCases := []struct {
+		name        string
+		app         collecttypes.CfApp
+		application manifest.Application
+		want        []string
+	}{
+		{
+			name: "routes of the running app take precedence",
+			app: collecttypes.CfApp{Routes: []collecttypes.CfRoute{
+				{Route: cfclient.Route{Host: "web", Path: "/api"}, Domain: "apps.example.com"},
+				{Route: cfclient.Route{Port: 1024, DomainGuid: "tcp-domain-guid"}},
+			}},
+			application: manifest.Application{Routes: []string{"ignored.example.com"}},
+			want:        []string{"web.apps.example.com/api", "tcp-domain-guid:1024"},
+		},
+		{
+			name:        "routes in the manifest",
+			application: manifest.Application{Routes: []string{"web.example.com", "example.com/web"}},
+			want:        []string{"web.example.com", "example.com/web"},
+		},
+		{
+			name:        "deprecated host and domain in the manifest",
+			application: manifest.Application{Hostname: "web", Domain: "example.com", RoutePath: "/app"},
+			want:        []string{"web.example.com/app"},
+		},
+		{
+			name:        "app without routes",
+			application: manifest.Application{NoRoute: true, Routes: []string{"web.example.com"}},
+			want:        []string{},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := getCfRoutes(testCase.app, testCase.application); !cmp.Equal(got, testCase.want) {
+				t.Fatalf("the routes are different. Difference:\n%s", cmp.Diff(testCase.want, got))
+			}
+		})
+	}
+}
+
+func TestGetCfSyslogDrains(t *testing.T) {
+	vcapServices := `{"user-provided":[{"name":"papertrail","syslog_drain_url":"syslog-tls://token@logs.example.com:1234"},{"name":"config"}],"logdna":[{"name":"logdna","syslog_drain_url":"syslog://logs.example.com"}]}`
+	testCases := []struct {
+		name         string
+		vcapServices interface{}
+		want         []string
+	}{
+		{name: "string", vcapServices: vcapServices, want: []string{"logdna", "papertrail"}},
+		{
+			name: "object",
+			vcapServices: map[string]interface{}{
+				"user-provided": []interface{}{
+					map[string]interface{}{"name": "papertrail", "syslog_drain_url": "syslog-tls://token@logs.example.com:1234"},
+					map[string]interface{}{"name": "config"},
+				},
+			},
+			want: []string{"papertrail"},
+		},
+		{name: "no drains", vcapServices: `{"user-provided":[{"name":"config"}]}`, want: []string{}},
+		{name: "invalid json", vcapServices: `{"user-provided":`},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			app := collecttypes.CfApp{Environment: cfclient.AppEnv{SystemEnv: map[string]interface{}{common.VcapServiceEnvName: testCase.vcapServices}}}
+			if got := getCfSyslogDrains(app); !cmp.Equal(got, testCase.want) {
+				t.Fatalf("the drains are different. Difference:\n%s", cmp.Diff(testCase.want, got))
+			}
+		})
+	}
+	if got := getCfSyslogDrains(collecttypes.CfApp{}); got != nil {
+		t.Fatalf("expected no drains without the %s environment variable, got %+v", common.VcapServiceEnvName, got)
+	}
+}
+
+func TestGetCfUnmappedTODOs(t *testing.T) {
+	todoKey := func(category string) string { return common.TODOAnnotation + cfTODOCategoryPrefix + category }
+	testCases := []struct {
+		name        string
+		app         collecttypes.CfApp
+		application manifest.Application
+		want        map[string]string
+	}{
+		{name: "nothing to report", application: manifest.Application{NoRoute: true}},
+		{
+			name: "routes, route services and syslog drains",
+			app: collecttypes.CfApp{
+				Routes: []collecttypes.CfRoute{
+					{Route: cfclient.Route{Host: "web"}, Domain: "example.com", RouteService: "auth", RouteServiceURL: "https://auth.example.com"},
+					{Route: cfclient.Route{Host: "admin"}, Domain: "example.com", RouteService: "auth", RouteServiceURL: "https://auth.example.com"},
+					{Route: cfclient.Route{Host: "api"}, Domain: "example.com", RouteService: "ratelimit"},
+				},
+				Environment: cfclient.AppEnv{SystemEnv: map[string]interface{}{
+					common.VcapServiceEnvName: `{"user-provided":[{"name":"papertrail","syslog_drain_url":"syslog-tls://token@logs.example.com:1234"}]}`,
+				}},
+			},
+			want: map[string]string{
+				todoKey("routes"): "The Cloud Foundry routes web.example.com, admin.example.com, api.example.com of the app web were not mapped. " +
+					"Expose the service on the same hosts and paths with the Ingress and point the DNS records of the hosts to the cluster.",
+				todoKey("routeservice"): "The route services auth (https://auth.example.com) for the routes web.example.com, admin.example.com; ratelimit for the routes api.example.com " +
+					"process the requests before they reach the app web and were not mapped. " +
+					"Put an equivalent proxy in front of the service, like an authentication or a rate limiting policy of the ingress controller, or a sidecar.",
+				todoKey("syslogdrain"): "The logs of the app web are drained by the services papertrail, which were not mapped. " +
+					"Forward the logs of the pods with a log collector running on the nodes, like Fluent Bit.",
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := getCfUnmappedTODOs("web", testCase.app, testCase.application); !cmp.Equal(got, testCase.want) {
+				t.Fatalf("the TODOs are different. Difference:\n%s", cmp.Diff(testCase.want, got))
+			}
+		})
+	}
+}
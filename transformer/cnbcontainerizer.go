@@ -17,6 +17,7 @@
 package transformer
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
 	"runtime"
@@ -86,9 +87,14 @@ func (t *CNBContainerizer) GetConfig() (transformertypes.Transformer, *environme
 
 // DirectoryDetect runs detect in each sub directory
 func (t *CNBContainerizer) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return t.DirectoryDetectWithContext(context.Background(), dir)
+}
+
+// DirectoryDetectWithContext runs detect in each sub directory and stops the detector when the context is cancelled
+func (t *CNBContainerizer) DirectoryDetectWithContext(ctx context.Context, dir string) (services map[string][]transformertypes.Artifact, err error) {
 	path := dir
 	cmd := environmenttypes.Command{"/cnb/lifecycle/detector", "-app", t.CNBEnv.Encode(path).(string)}
-	stdout, stderr, exitcode, err := t.CNBEnv.Exec(cmd, nil)
+	stdout, stderr, exitcode, err := t.CNBEnv.Exec(ctx, cmd, nil)
 	if err != nil {
 		logrus.Debugf("CNB detector failed. exit code: %d error: %q\nstdout: %s\nstderr: %s", exitcode, err, stdout, stderr)
 		return nil, fmt.Errorf("CNB detector failed with exitcode %d . Error: %q", exitcode, err)
@@ -104,6 +110,11 @@ func (t *CNBContainerizer) DirectoryDetect(dir string) (services map[string][]tr
 	return map[string][]transformertypes.Artifact{"": detectedServices}, nil
 }
 
+// TransformWithContext transforms the artifacts. It runs no commands, so there is nothing to stop when the context is cancelled.
+func (t *CNBContainerizer) TransformWithContext(ctx context.Context, newArtifacts []transformertypes.Artifact, oldArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	return t.Transform(newArtifacts, oldArtifacts)
+}
+
 // Transform transforms the artifacts
 func (t *CNBContainerizer) Transform(newArtifacts []transformertypes.Artifact, oldArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
 	createdArtifacts := []transformertypes.Artifact{}
@@ -191,7 +191,7 @@ func (t *ComposeAnalyser) Transform(newArtifacts []transformertypes.Artifact, al
 			if contextPath == "" && dockerfilePath != common.DefaultDockerfileName {
 				contextPath = filepath.Dir(dockerfilePath)
 			}
-			createdArtifacts = append(createdArtifacts, transformertypes.Artifact{
+			dockerfileArtifact := transformertypes.Artifact{
 				Name: name,
 				Type: artifacts.DockerfileArtifactType,
 				Paths: map[transformertypes.PathType][]string{artifacts.DockerfilePathType: {dockerfilePath},
@@ -202,7 +202,14 @@ func (t *ComposeAnalyser) Transform(newArtifacts []transformertypes.Artifact, al
 						ImageName: name,
 					},
 				},
-			})
+			}
+			if len(containerImage.Build.BuildArgs) != 0 || containerImage.Build.Target != "" {
+				dockerfileArtifact.Configs[artifacts.DockerfileBuildConfigType] = artifacts.DockerfileBuild{
+					BuildArgs: containerImage.Build.BuildArgs,
+					Target:    containerImage.Build.Target,
+				}
+			}
+			createdArtifacts = append(createdArtifacts, dockerfileArtifact)
 		}
 		createdArtifact := transformertypes.Artifact{
 			Name:    t.Env.GetProjectName(),
@@ -19,6 +19,8 @@ package compose
 import (
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	composetypes "github.com/docker/cli/cli/compose/types"
 	"github.com/konveyor/move2kube/common"
@@ -27,6 +29,7 @@ import (
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
 	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
 )
 
 const (
@@ -102,40 +105,13 @@ func (t *ComposeGenerator) Transform(newArtifacts []transformertypes.Artifact, a
 		}
 		logrus.Debugf("Starting Compose transform")
 		logrus.Debugf("Total services to be transformed : %d", len(ir.Services))
-		c := composeObj{
-			Version:  "3.5",
-			Services: map[string]composetypes.ServiceConfig{},
-		}
-		var exposedPort uint32 = 8080
-		for _, service := range ir.Services {
-			for _, container := range service.Containers {
-				ports := []composetypes.ServicePortConfig{}
-				for _, port := range container.Ports {
-					ports = append(ports, composetypes.ServicePortConfig{
-						Target:    uint32(port.ContainerPort),
-						Published: exposedPort,
-					})
-					exposedPort++
-				}
-				env := composetypes.MappingWithEquals{}
-				for _, e := range container.Env {
-					env[e.Name] = &e.Value
-				}
-				serviceConfig := composetypes.ServiceConfig{
-					ContainerName: container.Name,
-					Image:         container.Image,
-					Ports:         ports,
-					Environment:   env,
-				}
-				c.Services[service.Name] = serviceConfig
-			}
-		}
-		logrus.Debugf("Total transformed objects : %d", len(c.Services))
 		composePath := t.ComposeGeneratorConfig.OutputPath
 		absComposePath := filepath.Join(t.Env.TempPath, composePath)
 		if err := os.MkdirAll(absComposePath, common.DefaultDirectoryPermission); err != nil {
 			logrus.Errorf("Unable to create output directory %s : %s", common.TempPath, err)
 		}
+		c := getComposeObj(ir, absComposePath)
+		logrus.Debugf("Total transformed objects : %d", len(c.Services))
 		if err := common.WriteYaml(filepath.Join(absComposePath, "docker-compose.yaml"), c); err != nil {
 			logrus.Errorf("Unable to write docker compose file %s : %s", absComposePath, err)
 		}
@@ -147,3 +123,204 @@ func (t *ComposeGenerator) Transform(newArtifacts []transformertypes.Artifact, a
 	}
 	return pathMappings, nil, nil
 }
+
+// getComposeObj converts the services in the IR to compose services for running the application locally.
+// The contents of the config maps and secrets mounted as volumes are written to the compose output directory.
+func getComposeObj(ir irtypes.IR, composeDir string) composeObj {
+	c := composeObj{
+		Version:  "3.5",
+		Services: map[string]composetypes.ServiceConfig{},
+		Volumes:  map[string]composetypes.VolumeConfig{},
+	}
+	storages := map[string]irtypes.Storage{}
+	for _, storage := range ir.Storages {
+		storages[storage.Name] = storage
+	}
+	serviceNames := []string{}
+	for serviceName := range ir.Services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	usedPorts := map[uint32]bool{}
+	var nextFreePort uint32 = 8080
+	getPublishedPort := func(port uint32) uint32 {
+		if !usedPorts[port] {
+			usedPorts[port] = true
+			return port
+		}
+		for usedPorts[nextFreePort] {
+			nextFreePort++
+		}
+		usedPorts[nextFreePort] = true
+		return nextFreePort
+	}
+	// the compose services generated for the containers of each service, and the service of each compose service
+	composeServiceNames := map[string][]string{}
+	irServiceNames := map[string]string{}
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		volumes := map[string]core.Volume{}
+		for _, volume := range service.Volumes {
+			volumes[volume.Name] = volume
+		}
+		for _, container := range service.Containers {
+			composeServiceName := service.Name
+			if len(service.Containers) > 1 {
+				composeServiceName = service.Name + "-" + container.Name
+			}
+			composeServiceNames[serviceName] = append(composeServiceNames[serviceName], composeServiceName)
+			irServiceNames[composeServiceName] = serviceName
+			ports := []composetypes.ServicePortConfig{}
+			for _, port := range container.Ports {
+				published := uint32(port.ContainerPort)
+				for _, forwarding := range service.ServiceToPodPortForwardings {
+					if forwarding.PodPort.Number == port.ContainerPort && forwarding.ServicePort.Number != 0 {
+						published = uint32(forwarding.ServicePort.Number)
+						break
+					}
+				}
+				ports = append(ports, composetypes.ServicePortConfig{
+					Target:    uint32(port.ContainerPort),
+					Published: getPublishedPort(published),
+				})
+			}
+			env := composetypes.MappingWithEquals{}
+			for _, e := range container.Env {
+				value, ok := getEnvValue(e, storages)
+				if !ok {
+					logrus.Debugf("skipping the environment variable %s of the container %s since its value could not be resolved", e.Name, container.Name)
+					continue
+				}
+				env[e.Name] = &value
+			}
+			serviceConfig := composetypes.ServiceConfig{
+				ContainerName: container.Name,
+				Image:         container.Image,
+				Entrypoint:    container.Command,
+				Command:       container.Args,
+				WorkingDir:    container.WorkingDir,
+				Ports:         ports,
+				Environment:   env,
+			}
+			for _, volumeMount := range container.VolumeMounts {
+				volume, ok := volumes[volumeMount.Name]
+				if !ok {
+					continue
+				}
+				serviceVolume, ok := getServiceVolume(volume, volumeMount, storages, composeDir, &c)
+				if !ok {
+					logrus.Debugf("skipping the volume %s of the container %s since it is not supported in compose", volume.Name, container.Name)
+					continue
+				}
+				serviceConfig.Volumes = append(serviceConfig.Volumes, serviceVolume)
+			}
+			c.Services[composeServiceName] = serviceConfig
+		}
+	}
+	// a service depends on the services in its depends on list and the services whose names are used in its environment variables
+	for name, serviceConfig := range c.Services {
+		serviceName := irServiceNames[name]
+		dependencies := []string{}
+		for _, dependency := range ir.Services[serviceName].DependsOn {
+			if dependency != serviceName {
+				dependencies = common.AppendIfNotPresent(dependencies, dependency)
+			}
+		}
+		for _, otherServiceName := range serviceNames {
+			if otherServiceName == serviceName {
+				continue
+			}
+			for _, value := range serviceConfig.Environment {
+				if value != nil && referencesHost(*value, otherServiceName) {
+					dependencies = common.AppendIfNotPresent(dependencies, otherServiceName)
+					break
+				}
+			}
+		}
+		// a service with several containers is depended on through all of its compose services
+		for _, dependency := range dependencies {
+			for _, composeServiceName := range composeServiceNames[dependency] {
+				serviceConfig.DependsOn = common.AppendIfNotPresent(serviceConfig.DependsOn, composeServiceName)
+			}
+		}
+		sort.Strings(serviceConfig.DependsOn)
+		c.Services[name] = serviceConfig
+	}
+	return c
+}
+
+func getEnvValue(e core.EnvVar, storages map[string]irtypes.Storage) (string, bool) {
+	if e.ValueFrom == nil {
+		return e.Value, true
+	}
+	name, key := "", ""
+	switch {
+	case e.ValueFrom.ConfigMapKeyRef != nil:
+		name, key = e.ValueFrom.ConfigMapKeyRef.Name, e.ValueFrom.ConfigMapKeyRef.Key
+	case e.ValueFrom.SecretKeyRef != nil:
+		name, key = e.ValueFrom.SecretKeyRef.Name, e.ValueFrom.SecretKeyRef.Key
+	default:
+		return "", false
+	}
+	storage, ok := storages[name]
+	if !ok {
+		return "", false
+	}
+	value, ok := storage.Content[key]
+	return string(value), ok
+}
+
+func getServiceVolume(volume core.Volume, volumeMount core.VolumeMount, storages map[string]irtypes.Storage, composeDir string, c *composeObj) (composetypes.ServiceVolumeConfig, bool) {
+	serviceVolume := composetypes.ServiceVolumeConfig{Target: volumeMount.MountPath, ReadOnly: volumeMount.ReadOnly}
+	storageName := ""
+	switch {
+	case volume.PersistentVolumeClaim != nil:
+		serviceVolume.Type = "volume"
+		serviceVolume.Source = volume.PersistentVolumeClaim.ClaimName
+		c.Volumes[volume.PersistentVolumeClaim.ClaimName] = composetypes.VolumeConfig{}
+		return serviceVolume, true
+	case volume.HostPath != nil:
+		serviceVolume.Type = "bind"
+		serviceVolume.Source = volume.HostPath.Path
+		return serviceVolume, true
+	case volume.EmptyDir != nil:
+		serviceVolume.Type = "volume"
+		return serviceVolume, true
+	case volume.ConfigMap != nil:
+		storageName = volume.ConfigMap.Name
+	case volume.Secret != nil:
+		storageName = volume.Secret.SecretName
+	default:
+		return serviceVolume, false
+	}
+	storage, ok := storages[storageName]
+	if !ok {
+		return serviceVolume, false
+	}
+	storageDir := filepath.Join(composeDir, storageName)
+	if err := os.MkdirAll(storageDir, common.DefaultDirectoryPermission); err != nil {
+		logrus.Errorf("failed to create the directory %s . Error: %q", storageDir, err)
+		return serviceVolume, false
+	}
+	for key, value := range storage.Content {
+		if err := os.WriteFile(filepath.Join(storageDir, key), value, common.DefaultFilePermission); err != nil {
+			logrus.Errorf("failed to write the content of %s to the directory %s . Error: %q", storageName, storageDir, err)
+		}
+	}
+	serviceVolume.Type = "bind"
+	serviceVolume.Source = "./" + storageName
+	serviceVolume.ReadOnly = true
+	return serviceVolume, true
+}
+
+// referencesHost checks if the value uses the host name. Example: db, db:5432, postgres://db:5432/mydb
+func referencesHost(value, host string) bool {
+	for _, field := range strings.FieldsFunc(value, func(r rune) bool {
+		return r == '/' || r == ':' || r == '@' || r == ',' || r == ' ' || r == '?' || r == '='
+	}) {
+		if field == host {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,58 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestGetComposeObjDependsOn(t *testing.T) {
+	ir := irtypes.NewIR()
+	newService := func(name string, dependsOn []string, containers ...core.Container) {
+		service := irtypes.NewServiceWithName(name)
+		service.DependsOn = dependsOn
+		service.Containers = containers
+		ir.Services[name] = service
+	}
+	newService("api", nil, core.Container{Name: "api", Image: "api", Env: []core.EnvVar{{Name: "DB_URL", Value: "postgres://db:5432/app"}}})
+	newService("api-gateway", []string{"missing"}, core.Container{Name: "gateway", Image: "gateway", Env: []core.EnvVar{{Name: "UPSTREAM", Value: "http://api:8080"}}})
+	newService("db", []string{"db"},
+		core.Container{Name: "postgres", Image: "postgres"},
+		core.Container{Name: "exporter", Image: "exporter", Env: []core.EnvVar{{Name: "DATA_SOURCE", Value: "postgres://db:5432"}}},
+	)
+	newService("worker", []string{"db"}, core.Container{Name: "worker", Image: "worker", Env: []core.EnvVar{{Name: "API_PREFIX", Value: "api-"}}})
+
+	c := getComposeObj(ir, t.TempDir())
+	want := map[string][]string{
+		"api":         {"db-exporter", "db-postgres"},
+		"api-gateway": {"api"},
+		"db-postgres": nil,
+		"db-exporter": nil,
+		"worker":      {"db-exporter", "db-postgres"},
+	}
+	got := map[string][]string{}
+	for name, serviceConfig := range c.Services {
+		got[name] = serviceConfig.DependsOn
+	}
+	if !cmp.Equal(got, want) {
+		t.Fatalf("the dependencies of the compose services are different. Difference:\n%s", cmp.Diff(want, got))
+	}
+}
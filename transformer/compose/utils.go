@@ -20,10 +20,14 @@ import (
 	"fmt"
 	"hash/fnv"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/docker/cli/opts"
 	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
 	"github.com/sirupsen/logrus"
 	core "k8s.io/kubernetes/pkg/apis/core"
 )
@@ -34,8 +38,61 @@ const (
 	tmpFsPath             string = "tmpfs"
 	defaultSecretBasePath string = "/var/secrets"
 	envFile               string = "env_file"
+	timeZoneEnvName       string = "TZ"
+	zoneInfoPath          string = "/usr/share/zoneinfo/"
+	// composeTODOCategoryPrefix is the prefix of the TODO categories of the compose options which could not be mapped
+	composeTODOCategoryPrefix string = "compose."
 )
 
+// timeZoneFiles are the files which are bind mounted from the host so that the containers use the time zone of the host
+var timeZoneFiles = []string{"/etc/localtime", "/etc/timezone"}
+
+// unmappedOptionRecommendations are the manual steps replacing the compose options which have no equivalent in the generated resources
+var unmappedOptionRecommendations = map[string]string{
+	"network_mode":           "Kubernetes has no network modes. Set hostNetwork in the pod spec for the host mode, or run the containers sharing the network in the same pod.",
+	"extra_hosts":            "Add the hosts to the hostAliases of the pod spec.",
+	"dns":                    "Set the name servers in the dnsConfig of the pod spec, along with the dnsPolicy None.",
+	"dns_search":             "Set the search domains in the dnsConfig of the pod spec.",
+	"devices":                "Expose the devices on the nodes with a device plugin and request them in the resources of the container.",
+	"logging":                "The pods log to the stdout and the stderr of the containers. Forward the logs with a log collector running on the nodes, like Fluent Bit.",
+	"security_opt":           "Set the equivalent seccompProfile, appArmorProfile or seLinuxOptions in the securityContext of the container.",
+	"shm_size":               "Mount an emptyDir volume with the medium Memory and the size as its sizeLimit at /dev/shm.",
+	"ipc":                    "Run the containers sharing the IPC namespace in the same pod, or set hostIPC in the pod spec for the host mode.",
+	"pid":                    "Run the containers sharing the process namespace in the same pod with shareProcessNamespace, or set hostPID in the pod spec for the host mode.",
+	"uts":                    "Set hostNetwork in the pod spec to use the UTS namespace of the host.",
+	"user":                   "Set the numeric UID of the user in the runAsUser of the securityContext of the container.",
+	"ulimits":                "Kubernetes does not support setting the ulimits. Configure them in the container runtime of the nodes or raise them in the entrypoint of the image.",
+	"cgroup_parent":          "Kubernetes manages the cgroups of the pods. Set the resources of the containers instead.",
+	"stop_signal":            "The containers are stopped with SIGTERM. Handle SIGTERM in the service, or send the signal to it in a preStop hook.",
+	"userns_mode":            "Set hostUsers to false in the pod spec to run the pods in a user namespace, if the cluster supports it.",
+	"read_only":              "Set readOnlyRootFilesystem in the securityContext of the container and mount emptyDir volumes at the paths the service writes to.",
+	"init":                   "Use an init process, like tini, as the entrypoint of the image to reap the zombie processes.",
+	"mac_address":            "Kubernetes assigns the addresses of the pods. Remove the dependency of the service on its MAC address.",
+	"isolation":              "Select the isolation of the Windows containers with a RuntimeClass.",
+	"credential_spec":        "Set the gMSA credential spec in the windowsOptions of the securityContext of the container.",
+	"external_links":         "Point the service to the Kubernetes services of the linked containers, or create ExternalName services for them.",
+	"volumes_from":           "Run the containers sharing the volumes in the same pod, or mount the same PersistentVolumeClaims in both of the services.",
+	"oom_score_adj":          "Kubernetes sets the OOM score of the containers from their quality of service class. Set equal requests and limits to make the pods Guaranteed.",
+	"oom_kill_disable":       "Kubernetes does not support disabling the OOM killer. Set a memory limit which is large enough for the service.",
+	"deploy.placement":       "Schedule the pods with the nodeSelector, the affinity or the topologySpreadConstraints of the pod spec.",
+	"deploy.endpoint_mode":   "Use a headless service, with the clusterIP None, for the DNS round robin mode.",
+	"deploy.rollback_config": "Roll back the failed rollouts with kubectl rollout undo, or use a progressive delivery controller like Argo Rollouts.",
+}
+
+// safeSysctls are the namespaced sysctls which are allowed by default, the others have to be allowed in the kubelet of the nodes
+var safeSysctls = []string{
+	"kernel.shm_rmid_forced",
+	"net.ipv4.ip_local_port_range",
+	"net.ipv4.ip_unprivileged_port_start",
+	"net.ipv4.ip_local_reserved_ports",
+	"net.ipv4.tcp_syncookies",
+	"net.ipv4.ping_group_range",
+	"net.ipv4.tcp_keepalive_time",
+	"net.ipv4.tcp_fin_timeout",
+	"net.ipv4.tcp_keepalive_intvl",
+	"net.ipv4.tcp_keepalive_probes",
+}
+
 /*
 // IsV3 returns if the docker-compose yaml is version 3
 func IsV3(path string) (bool, error) {
@@ -58,6 +115,31 @@ func IsV3(path string) (bool, error) {
 }
 */
 
+// getDependsOn returns the names of the services used in depends_on and links. The links are of the form service or service:alias
+func getDependsOn(dependsOn []string, links []string) []string {
+	dependencies := []string{}
+	for _, dependency := range dependsOn {
+		dependencies = common.AppendIfNotPresent(dependencies, common.NormalizeForMetadataName(dependency))
+	}
+	for _, link := range links {
+		dependency := strings.SplitN(link, ":", 2)[0]
+		dependencies = common.AppendIfNotPresent(dependencies, common.NormalizeForMetadataName(dependency))
+	}
+	sort.Strings(dependencies)
+	return dependencies
+}
+
+// getServiceAliases returns the other host names and IP addresses the service is reached with, leaving out the name of its k8s service
+func getServiceAliases(serviceName string, aliases ...string) []string {
+	serviceAliases := []string{}
+	for _, alias := range aliases {
+		if alias = strings.TrimSpace(alias); alias != "" && alias != serviceName {
+			serviceAliases = common.AppendIfNotPresent(serviceAliases, alias)
+		}
+	}
+	return serviceAliases
+}
+
 func getEnvironmentVariables(envFile string) map[string]string {
 	result := map[string]string{}
 	if len(envFile) > 0 {
@@ -91,6 +173,24 @@ func getEnvironmentVariables(envFile string) map[string]string {
 	return result
 }
 
+// getBuildArgs returns the build args of the service. The args without a value take it from the environment, like in docker compose.
+func getBuildArgs(args map[string]*string) map[string]string {
+	buildArgs := map[string]string{}
+	for name, value := range args {
+		if value != nil {
+			buildArgs[name] = *value
+			continue
+		}
+		if common.IgnoreEnvironment {
+			continue
+		}
+		if envValue, ok := os.LookupEnv(name); ok {
+			buildArgs[name] = envValue
+		}
+	}
+	return buildArgs
+}
+
 func makeVolumesFromTmpFS(serviceName string, tfsList []string) ([]core.VolumeMount, []core.Volume) {
 	vmList := []core.VolumeMount{}
 	vList := []core.Volume{}
@@ -114,6 +214,158 @@ func makeVolumesFromTmpFS(serviceName string, tfsList []string) ([]core.VolumeMo
 	return vmList, vList
 }
 
+// credentialFileExtensions are the extensions of the certificates, keys and keystores which are mounted from secrets
+var credentialFileExtensions = []string{".pem", ".key", ".crt", ".cer", ".der", ".p12", ".pfx", ".jks", ".keystore", ".truststore", ".kdb"}
+
+// credentialFileNames are the names of the credential files which have no extension
+var credentialFileNames = []string{"id_rsa", "id_dsa", "id_ecdsa", "id_ed25519", "credentials", ".htpasswd", ".netrc", ".pgpass"}
+
+// isCredentialFile returns true if the name of the file is the name of a certificate, a key or a credential file
+func isCredentialFile(path string) bool {
+	name := strings.ToLower(filepath.Base(path))
+	return common.IsPresent(credentialFileExtensions, filepath.Ext(name)) || common.IsPresent(credentialFileNames, name)
+}
+
+// getCredentialFilesSecret returns the secret holding the credential files bind mounted from the host path,
+// along with the volume mounting them at the same path in the container.
+// The host path should either be a credential file or a directory containing only credential files.
+func getCredentialFilesSecret(filedir, hostPath, mountPath string, readOnly bool) (core.VolumeMount, core.Volume, irtypes.Storage, bool) {
+	if !filepath.IsAbs(hostPath) {
+		hostPath = filepath.Join(filedir, hostPath)
+	}
+	fileInfo, err := os.Stat(hostPath)
+	if err != nil {
+		logrus.Debugf("Could not read the host path [%s] mounted at [%s]. Error: %q", hostPath, mountPath, err)
+		return core.VolumeMount{}, core.Volume{}, irtypes.Storage{}, false
+	}
+	paths := []string{hostPath}
+	if fileInfo.IsDir() {
+		dirEntries, err := os.ReadDir(hostPath)
+		if err != nil || len(dirEntries) == 0 {
+			return core.VolumeMount{}, core.Volume{}, irtypes.Storage{}, false
+		}
+		paths = []string{}
+		for _, dirEntry := range dirEntries {
+			if !dirEntry.Type().IsRegular() {
+				return core.VolumeMount{}, core.Volume{}, irtypes.Storage{}, false
+			}
+			paths = append(paths, filepath.Join(hostPath, dirEntry.Name()))
+		}
+	} else if !fileInfo.Mode().IsRegular() {
+		return core.VolumeMount{}, core.Volume{}, irtypes.Storage{}, false
+	}
+	for _, path := range paths {
+		if !isCredentialFile(path) {
+			return core.VolumeMount{}, core.Volume{}, irtypes.Storage{}, false
+		}
+	}
+	content := map[string][]byte{}
+	items := []core.KeyToPath{}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			logrus.Warnf("Could not read the credential file [%s]. Error: %q", path, err)
+			return core.VolumeMount{}, core.Volume{}, irtypes.Storage{}, false
+		}
+		name := filepath.Base(path)
+		content[name] = data
+		// Keep the permissions of the files, since the private keys are often refused when they are readable by others
+		mode := int32(0644)
+		if pathInfo, err := os.Stat(path); err == nil {
+			mode = int32(pathInfo.Mode().Perm())
+		}
+		items = append(items, core.KeyToPath{Key: name, Path: name, Mode: &mode})
+	}
+	secretName := fmt.Sprintf("%s%d", common.VolumePrefix, getHash([]byte(hostPath)))
+	volumeMount := core.VolumeMount{Name: secretName, ReadOnly: readOnly, MountPath: mountPath}
+	if !fileInfo.IsDir() {
+		volumeMount.SubPath = filepath.Base(hostPath)
+	}
+	volume := core.Volume{
+		Name: secretName,
+		VolumeSource: core.VolumeSource{
+			Secret: &core.SecretVolumeSource{SecretName: secretName, Items: items},
+		},
+	}
+	storage := irtypes.Storage{Name: secretName, StorageType: irtypes.SecretKind, SecretType: core.SecretTypeOpaque, Content: content}
+	logrus.Infof("The credential files at [%s] are mounted at [%s] from the secret %s", hostPath, mountPath, secretName)
+	return volumeMount, volume, storage, true
+}
+
+// isTimeZoneMount returns true if the host path mounts the time zone of the host in the container
+func isTimeZoneMount(hostPath, mountPath string) bool {
+	hostPath = filepath.Clean(hostPath)
+	return common.IsPresent(timeZoneFiles, filepath.Clean(mountPath)) && (common.IsPresent(timeZoneFiles, hostPath) || strings.HasPrefix(hostPath, zoneInfoPath))
+}
+
+// setTimeZone sets the TZ environment variable of the container instead of mounting the time zone file of the host
+func setTimeZone(serviceName, hostPath string, container *core.Container) {
+	for _, env := range container.Env {
+		if env.Name == timeZoneEnvName {
+			return
+		}
+	}
+	timeZone := strings.TrimPrefix(filepath.Clean(hostPath), zoneInfoPath)
+	if timeZone == filepath.Clean(hostPath) {
+		timeZone = commonqa.TimeZone()
+	}
+	logrus.Infof("The service %s mounts the time zone of the host. Setting the %s environment variable to %s instead", serviceName, timeZoneEnvName, timeZone)
+	container.Env = append(container.Env, core.EnvVar{Name: timeZoneEnvName, Value: timeZone})
+}
+
+// getSysctls returns the sysctls of the pods, warning about the ones which have to be allowed in the kubelet of the nodes
+func getSysctls(serviceName string, sysctls map[string]string) []core.Sysctl {
+	podSysctls := []core.Sysctl{}
+	for name, value := range sysctls {
+		if !common.IsPresent(safeSysctls, name) {
+			logrus.Warnf("The sysctl %s of the service %s is unsafe. The pods will only be scheduled on the nodes whose kubelet allows it using --allowed-unsafe-sysctls", name, serviceName)
+		}
+		podSysctls = append(podSysctls, core.Sysctl{Name: name, Value: value})
+	}
+	sort.Slice(podSysctls, func(i, j int) bool { return podSysctls[i].Name < podSysctls[j].Name })
+	return podSysctls
+}
+
+// unmappedOptions are the TODOs of the compose options of a service which have no equivalent in the generated resources, keyed by the option
+type unmappedOptions map[string]string
+
+// add records the option which could not be mapped, so that it ends up as a TODO annotation on the resources of the service instead of being dropped
+func (u unmappedOptions) add(serviceName, option, value string) {
+	message := fmt.Sprintf("The compose option %s (%s) of the service %s was not mapped. %s", option, value, serviceName, unmappedOptionRecommendations[option])
+	logrus.Warn(message)
+	u[option] = message
+}
+
+// addUlimits records the ulimits of the service, since the pods use the limits of the container runtime of the nodes
+func (u unmappedOptions) addUlimits(serviceName string, ulimits map[string][2]int64) {
+	names := []string{}
+	for name := range ulimits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	values := []string{}
+	for _, name := range names {
+		values = append(values, fmt.Sprintf("%s soft %d hard %d", name, ulimits[name][0], ulimits[name][1]))
+	}
+	u.add(serviceName, "ulimits", strings.Join(values, ", "))
+}
+
+// getAnnotations returns a copy of the annotations along with the TODO annotations of the unmapped options, which are aggregated in the report
+func (u unmappedOptions) getAnnotations(annotations map[string]string) map[string]string {
+	if len(u) == 0 {
+		return annotations
+	}
+	// the annotations might be the labels of the compose service, which are used for the labels of the resources too
+	newAnnotations := map[string]string{}
+	for key, value := range annotations {
+		newAnnotations[key] = value
+	}
+	for option, message := range u {
+		newAnnotations[common.TODOAnnotation+composeTODOCategoryPrefix+option] = message
+	}
+	return newAnnotations
+}
+
 func isPath(substring string) bool {
 	return strings.Contains(substring, "/") || substring == "."
 }
@@ -123,3 +375,13 @@ func getHash(data []byte) uint64 {
 	hasher.Write(data)
 	return hasher.Sum64()
 }
+
+// getProtocol returns the transport protocol of a compose port, like udp in 53:53/udp
+func getProtocol(protocol string) core.Protocol {
+	for _, p := range []core.Protocol{core.ProtocolUDP, core.ProtocolSCTP} {
+		if strings.EqualFold(string(p), protocol) {
+			return p
+		}
+	}
+	return core.ProtocolTCP
+}
@@ -0,0 +1,205 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// getUnmappedOptionNames returns the sorted options of the TODO annotations of the compose options which were not mapped
+func getUnmappedOptionNames(annotations map[string]string) []string {
+	options := []string{}
+	for key := range annotations {
+		if option := strings.TrimPrefix(key, common.TODOAnnotation+composeTODOCategoryPrefix); option != key {
+			options = append(options, option)
+		}
+	}
+	sort.Strings(options)
+	return options
+}
+
+// writeComposeFile writes the compose file into a temporary directory and returns its path
+func writeComposeFile(t *testing.T, contents string) string {
+	t.Helper()
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	composeFilePath := filepath.Join(t.TempDir(), "docker-compose.yaml")
+	if err := os.WriteFile(composeFilePath, []byte(contents), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the compose file. Error: %q", err)
+	}
+	return composeFilePath
+}
+
+func TestUnmappedOptionsGetAnnotations(t *testing.T) {
+	labels := map[string]string{"app": "web"}
+	if got := (unmappedOptions{}).getAnnotations(labels); !cmp.Equal(got, labels) {
+		t.Fatalf("expected the annotations to be unchanged without unmapped options, got %+v", got)
+	}
+	unmapped := unmappedOptions{}
+	unmapped.add("web", "dns", "8.8.8.8")
+	unmapped.addUlimits("web", map[string][2]int64{"nproc": {512, 512}, "nofile": {1024, 2048}})
+	got := unmapped.getAnnotations(labels)
+	if len(labels) != 1 {
+		t.Fatalf("expected the labels of the service not to be modified, got %+v", labels)
+	}
+	want := map[string]string{
+		"app": "web",
+		common.TODOAnnotation + composeTODOCategoryPrefix + "dns":     "The compose option dns (8.8.8.8) of the service web was not mapped. " + unmappedOptionRecommendations["dns"],
+		common.TODOAnnotation + composeTODOCategoryPrefix + "ulimits": "The compose option ulimits (nofile soft 1024 hard 2048, nproc soft 512 hard 512) of the service web was not mapped. " + unmappedOptionRecommendations["ulimits"],
+	}
+	if !cmp.Equal(got, want) {
+		t.Fatalf("the annotations are different. Difference:\n%s", cmp.Diff(want, got))
+	}
+}
+
+// assertDNSPortProtocols checks the ports of a service publishing 53/udp and 53/tcp on the hosts and exposing 3868/sctp
+func assertDNSPortProtocols(t *testing.T, service irtypes.Service) {
+	t.Helper()
+	forwardings := []string{}
+	for _, forwarding := range service.ServiceToPodPortForwardings {
+		forwardings = append(forwardings, fmt.Sprintf("%d:%d/%s published on %d", forwarding.ServicePort.Number, forwarding.PodPort.Number, forwarding.GetProtocol(), forwarding.HostPort))
+	}
+	if want := []string{"53:53/UDP published on 53", "53:53/TCP published on 53", "3868:3868/SCTP published on 0"}; !cmp.Equal(forwardings, want) {
+		t.Fatalf("unexpected port forwardings. Differences: %s", cmp.Diff(want, forwardings))
+	}
+	if len(service.Containers) != 1 {
+		t.Fatalf("expected a single container. Actual: %+v", service.Containers)
+	}
+	containerPorts := []string{}
+	for _, port := range service.Containers[0].Ports {
+		containerPorts = append(containerPorts, fmt.Sprintf("%d/%s", port.ContainerPort, port.Protocol))
+	}
+	if want := []string{"53/UDP", "53/TCP", "3868/SCTP"}; !cmp.Equal(containerPorts, want) {
+		t.Fatalf("unexpected container ports. Differences: %s", cmp.Diff(want, containerPorts))
+	}
+}
+
+func TestGetCredentialFilesSecret(t *testing.T) {
+	filedir := t.TempDir()
+	for path, mode := range map[string]os.FileMode{"certs/tls.crt": 0644, "certs/tls.key": 0600, "keys/id_rsa": 0600, "mixed/app.conf": 0644, "mixed/ca.pem": 0644} {
+		path = filepath.Join(filedir, path)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory of %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte("contents of "+filepath.Base(path)), mode); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+
+	t.Run("a directory of credential files", func(t *testing.T) {
+		volumeMount, volume, storage, ok := getCredentialFilesSecret(filedir, "./certs", "/etc/tls", true)
+		if !ok {
+			t.Fatalf("expected the directory of the certificates to be mounted from a secret")
+		}
+		if volumeMount.MountPath != "/etc/tls" || volumeMount.SubPath != "" || !volumeMount.ReadOnly || volumeMount.Name != storage.Name {
+			t.Fatalf("unexpected volume mount. Actual: %+v", volumeMount)
+		}
+		if storage.StorageType != irtypes.SecretKind || string(storage.Content["tls.key"]) != "contents of tls.key" || len(storage.Content) != 2 {
+			t.Fatalf("expected a secret with the files of the directory. Actual: %+v", storage)
+		}
+		modes := map[string]int32{}
+		for _, item := range volume.Secret.Items {
+			modes[item.Key] = *item.Mode
+		}
+		if want := map[string]int32{"tls.crt": 0644, "tls.key": 0600}; !cmp.Equal(modes, want) {
+			t.Fatalf("expected the permissions of the files to be kept. Differences: %s", cmp.Diff(want, modes))
+		}
+	})
+
+	t.Run("a single credential file", func(t *testing.T) {
+		volumeMount, _, storage, ok := getCredentialFilesSecret(filedir, filepath.Join(filedir, "keys", "id_rsa"), "/root/.ssh/id_rsa", false)
+		if !ok {
+			t.Fatalf("expected the private key to be mounted from a secret")
+		}
+		if volumeMount.SubPath != "id_rsa" || volumeMount.MountPath != "/root/.ssh/id_rsa" || len(storage.Content) != 1 {
+			t.Fatalf("expected the file to be mounted at the same path. Actual: %+v", volumeMount)
+		}
+	})
+
+	for _, hostPath := range []string{"./mixed", "./mixed/app.conf", "./missing.pem"} {
+		if _, _, _, ok := getCredentialFilesSecret(filedir, hostPath, "/etc/app", false); ok {
+			t.Errorf("expected the host path %s not to be mounted from a secret", hostPath)
+		}
+	}
+}
+
+func TestIsTimeZoneMount(t *testing.T) {
+	testCases := map[[2]string]bool{
+		{"/etc/localtime", "/etc/localtime"}:                   true,
+		{"/etc/timezone", "/etc/timezone/"}:                    true,
+		{"/usr/share/zoneinfo/Europe/Paris", "/etc/localtime"}: true,
+		{"./localtime", "/etc/localtime"}:                      false,
+		{"/etc/localtime", "/app/localtime"}:                   false,
+	}
+	for paths, want := range testCases {
+		if got := isTimeZoneMount(paths[0], paths[1]); got != want {
+			t.Errorf("isTimeZoneMount(%q, %q) = %t, want %t", paths[0], paths[1], got, want)
+		}
+	}
+}
+
+func TestSetTimeZone(t *testing.T) {
+	writeComposeFile(t, "")
+	qaengine.SetupConfigFile("", []string{common.ConfigTimeZoneKey + `="Asia/Tokyo"`}, nil, nil, false)
+	container := core.Container{}
+	setTimeZone("web", "/usr/share/zoneinfo/Europe/Paris", &container)
+	setTimeZone("web", "/etc/localtime", &container)
+	if want := []core.EnvVar{{Name: "TZ", Value: "Europe/Paris"}}; !cmp.Equal(container.Env, want) {
+		t.Fatalf("expected the time zone of the zoneinfo file to be kept. Difference:\n%s", cmp.Diff(want, container.Env))
+	}
+	container = core.Container{}
+	setTimeZone("web", "/etc/localtime", &container)
+	if want := []core.EnvVar{{Name: "TZ", Value: "Asia/Tokyo"}}; !cmp.Equal(container.Env, want) {
+		t.Fatalf("expected the configured time zone. Difference:\n%s", cmp.Diff(want, container.Env))
+	}
+}
+
+func TestGetSysctls(t *testing.T) {
+	got := getSysctls("web", map[string]string{"net.ipv4.tcp_syncookies": "1", "net.core.somaxconn": "1024"})
+	want := []core.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}, {Name: "net.ipv4.tcp_syncookies", Value: "1"}}
+	if !cmp.Equal(got, want) {
+		t.Fatalf("the sysctls are different. Difference:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestGetBuildArgs(t *testing.T) {
+	t.Setenv("M2K_TEST_FROM_ENV", "from-env")
+	version := "1.2"
+	args := map[string]*string{"VERSION": &version, "M2K_TEST_FROM_ENV": nil, "M2K_TEST_NOT_SET": nil}
+	want := map[string]string{"VERSION": "1.2", "M2K_TEST_FROM_ENV": "from-env"}
+	if got := getBuildArgs(args); !cmp.Equal(got, want) {
+		t.Fatalf("unexpected build args. Differences:\n%s", cmp.Diff(want, got))
+	}
+	common.IgnoreEnvironment = true
+	defer func() { common.IgnoreEnvironment = false }()
+	want = map[string]string{"VERSION": "1.2"}
+	if got := getBuildArgs(args); !cmp.Equal(got, want) {
+		t.Fatalf("expected the environment to be ignored. Differences:\n%s", cmp.Diff(want, got))
+	}
+}
@@ -144,6 +144,7 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 		}
 		serviceConfig := irtypes.NewServiceWithName(common.NormalizeForMetadataName(name))
 		serviceConfig.Annotations = map[string]string(composeServiceConfig.Labels)
+		unmapped := c.getUnmappedOptions(name, composeServiceConfig)
 		if composeServiceConfig.Hostname != "" {
 			serviceConfig.Hostname = composeServiceConfig.Hostname
 		}
@@ -162,6 +163,7 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 			if ir.ContainerImages == nil {
 				ir.ContainerImages = map[string]irtypes.ContainerImage{}
 			}
+			serviceConfig.SessionAffinity = common.UsesServerSideSessions(filepath.Join(filedir, composeServiceConfig.Build.Context))
 			ir.ContainerImages[serviceContainer.Image] = irtypes.ContainerImage{
 				Build: irtypes.ContainerBuild{
 					ContainerBuildType: irtypes.DockerfileContainerBuildType,
@@ -169,9 +171,20 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 					Artifacts: map[irtypes.ContainerBuildArtifactTypeValue][]string{
 						irtypes.DockerfileContainerBuildArtifactTypeValue: {filepath.Join(filedir, composeServiceConfig.Build.Dockerfile)},
 					},
+					BuildArgs: getBuildArgs(composeServiceConfig.Build.Args),
 				},
 			}
 		}
+		aliases := []string{name, composeServiceConfig.ContainerName, composeServiceConfig.Hostname}
+		if composeServiceConfig.Networks != nil {
+			for _, network := range composeServiceConfig.Networks.Networks {
+				if network != nil {
+					aliases = append(aliases, network.Aliases...)
+					aliases = append(aliases, network.IPv4Address, network.IPv6Address)
+				}
+			}
+		}
+		serviceConfig.Aliases = getServiceAliases(serviceConfig.Name, aliases...)
 		if composeServiceConfig.ContainerName == "" {
 			composeServiceConfig.ContainerName = serviceConfig.Name
 		}
@@ -195,7 +208,7 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 		if composeServiceConfig.User != "" {
 			uid, err := cast.ToInt64E(composeServiceConfig.User)
 			if err != nil {
-				logrus.Warn("Ignoring user directive. User to be specified as a UID (numeric).")
+				unmapped.add(name, "user", composeServiceConfig.User)
 			} else {
 				securityContext.RunAsUser = &uid
 			}
@@ -218,6 +231,13 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 		if *securityContext != (core.SecurityContext{}) {
 			serviceContainer.SecurityContext = securityContext
 		}
+		if len(composeServiceConfig.Ulimits.Elements) > 0 {
+			ulimits := map[string][2]int64{}
+			for _, ulimit := range composeServiceConfig.Ulimits.Elements {
+				ulimits[ulimit.Name] = [2]int64{ulimit.Soft, ulimit.Hard}
+			}
+			unmapped.addUlimits(name, ulimits)
+		}
 		if !cmp.Equal(*podSecurityContext, core.PodSecurityContext{}) {
 			serviceConfig.SecurityContext = podSecurityContext
 		}
@@ -257,19 +277,31 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 			}
 		}
 
+		serviceConfig.DependsOn = getDependsOn(composeServiceConfig.DependsOn, composeServiceConfig.Links)
+
 		vml, vl := makeVolumesFromTmpFS(name, composeServiceConfig.Tmpfs)
 		for _, v := range vl {
 			serviceConfig.AddVolume(v)
 		}
 		serviceContainer.VolumeMounts = append(serviceContainer.VolumeMounts, vml...)
 
-		if composeServiceConfig.VolumesFrom != nil {
-			logrus.Warnf("Ignoring VolumeFrom in compose for service %s : %s", serviceName, composeServiceConfig.VolumesFrom)
+		if len(composeServiceConfig.VolumesFrom) != 0 {
+			unmapped.add(name, "volumes_from", strings.Join(composeServiceConfig.VolumesFrom, ", "))
 		}
 
 		if composeServiceConfig.Volumes != nil {
 			for _, vol := range composeServiceConfig.Volumes.Volumes {
 				if isPath(vol.Source) {
+					if isTimeZoneMount(vol.Source, vol.Destination) {
+						setTimeZone(name, vol.Source, &serviceContainer)
+						continue
+					}
+					if volumeMount, volume, storage, ok := getCredentialFilesSecret(filedir, vol.Source, vol.Destination, vol.AccessMode == modeReadOnly); ok {
+						serviceContainer.VolumeMounts = append(serviceContainer.VolumeMounts, volumeMount)
+						serviceConfig.AddVolume(volume)
+						ir.AddStorage(storage)
+						continue
+					}
 					hPath := vol.Source
 					if !filepath.IsAbs(vol.Source) {
 						hPath, err := filepath.Abs(vol.Source)
@@ -322,12 +354,77 @@ func (c *v1v2Loader) convertToIR(filedir string, composeObject *project.Project,
 		}
 
 		serviceConfig.Containers = []core.Container{serviceContainer}
+		serviceConfig.Annotations = unmapped.getAnnotations(serviceConfig.Annotations)
 		ir.Services[name] = serviceConfig
 	}
 
 	return ir, nil
 }
 
+// getUnmappedOptions returns the options of the service which have no equivalent in the generated resources
+func (c *v1v2Loader) getUnmappedOptions(name string, composeServiceConfig *config.ServiceConfig) unmappedOptions {
+	unmapped := unmappedOptions{}
+	if composeServiceConfig.NetworkMode != "" && composeServiceConfig.NetworkMode != "bridge" && composeServiceConfig.NetworkMode != "default" {
+		unmapped.add(name, "network_mode", composeServiceConfig.NetworkMode)
+	}
+	if len(composeServiceConfig.ExtraHosts) != 0 {
+		unmapped.add(name, "extra_hosts", strings.Join(composeServiceConfig.ExtraHosts, ", "))
+	}
+	if len(composeServiceConfig.DNS) != 0 {
+		unmapped.add(name, "dns", strings.Join(composeServiceConfig.DNS, ", "))
+	}
+	if len(composeServiceConfig.DNSSearch) != 0 {
+		unmapped.add(name, "dns_search", strings.Join(composeServiceConfig.DNSSearch, ", "))
+	}
+	if len(composeServiceConfig.Devices) != 0 {
+		unmapped.add(name, "devices", strings.Join(composeServiceConfig.Devices, ", "))
+	}
+	// the options of the logging drivers are left out, since they often contain tokens
+	if composeServiceConfig.Logging.Driver != "" && composeServiceConfig.Logging.Driver != "json-file" {
+		unmapped.add(name, "logging", "driver "+composeServiceConfig.Logging.Driver)
+	}
+	if len(composeServiceConfig.SecurityOpt) != 0 {
+		unmapped.add(name, "security_opt", strings.Join(composeServiceConfig.SecurityOpt, ", "))
+	}
+	if composeServiceConfig.ShmSize != 0 {
+		unmapped.add(name, "shm_size", fmt.Sprintf("%d bytes", composeServiceConfig.ShmSize))
+	}
+	if composeServiceConfig.Ipc != "" {
+		unmapped.add(name, "ipc", composeServiceConfig.Ipc)
+	}
+	if composeServiceConfig.Pid != "" {
+		unmapped.add(name, "pid", composeServiceConfig.Pid)
+	}
+	if composeServiceConfig.Uts != "" {
+		unmapped.add(name, "uts", composeServiceConfig.Uts)
+	}
+	if composeServiceConfig.CgroupParent != "" {
+		unmapped.add(name, "cgroup_parent", composeServiceConfig.CgroupParent)
+	}
+	if composeServiceConfig.StopSignal != "" && composeServiceConfig.StopSignal != "SIGTERM" {
+		unmapped.add(name, "stop_signal", composeServiceConfig.StopSignal)
+	}
+	if composeServiceConfig.ReadOnly {
+		unmapped.add(name, "read_only", "true")
+	}
+	if composeServiceConfig.MacAddress != "" {
+		unmapped.add(name, "mac_address", composeServiceConfig.MacAddress)
+	}
+	if composeServiceConfig.Isolation != "" && composeServiceConfig.Isolation != "default" {
+		unmapped.add(name, "isolation", composeServiceConfig.Isolation)
+	}
+	if len(composeServiceConfig.ExternalLinks) != 0 {
+		unmapped.add(name, "external_links", strings.Join(composeServiceConfig.ExternalLinks, ", "))
+	}
+	if composeServiceConfig.OomScoreAdj != 0 {
+		unmapped.add(name, "oom_score_adj", fmt.Sprintf("%d", composeServiceConfig.OomScoreAdj))
+	}
+	if composeServiceConfig.OomKillDisable {
+		unmapped.add(name, "oom_kill_disable", "true")
+	}
+	return unmapped
+}
+
 func (c *v1v2Loader) getEnvs(envars []string) []core.EnvVar {
 	envs := []core.EnvVar{}
 	for _, e := range envars {
@@ -350,15 +447,15 @@ func (c *v1v2Loader) getEnvs(envars []string) []core.EnvVar {
 
 func (c *v1v2Loader) getPorts(composePorts []string, expose []string) []core.ContainerPort {
 	ports := []core.ContainerPort{}
-	exist := map[int]bool{}
+	exist := map[string]bool{}
 	for _, port := range composePorts {
 		_, podPort, protocol, err := c.parseContainerPort(port)
 		if err != nil {
 			continue
 		}
-		if !exist[podPort] {
+		if key := fmt.Sprintf("%d/%s", podPort, protocol); !exist[key] {
 			ports = append(ports, core.ContainerPort{ContainerPort: int32(podPort), Protocol: protocol})
-			exist[podPort] = true
+			exist[key] = true
 		}
 	}
 	for _, port := range expose {
@@ -366,40 +463,45 @@ func (c *v1v2Loader) getPorts(composePorts []string, expose []string) []core.Con
 		if err != nil {
 			continue
 		}
-		if !exist[podPort] {
+		if key := fmt.Sprintf("%d/%s", podPort, protocol); !exist[key] {
 			ports = append(ports, core.ContainerPort{ContainerPort: int32(podPort), Protocol: protocol})
-			exist[podPort] = true
+			exist[key] = true
 		}
 	}
 	return ports
 }
 
 func (c *v1v2Loader) addPorts(composePorts []string, expose []string, service *irtypes.Service) {
-	exist := map[int]bool{}
+	exist := map[string]bool{}
 	for _, port := range composePorts {
-		servicePortNumber, podPortNumber, _, err := c.parseContainerPort(port)
+		servicePortNumber, podPortNumber, protocol, err := c.parseContainerPort(port)
 		if err != nil {
 			continue
 		}
-		if !exist[servicePortNumber] {
+		if key := fmt.Sprintf("%d/%s", servicePortNumber, protocol); !exist[key] {
 			// Forward the port on the k8s service to the k8s pod.
 			podPort := networking.ServiceBackendPort{Number: int32(podPortNumber)}
 			servicePort := networking.ServiceBackendPort{Number: int32(servicePortNumber)}
-			service.AddPortForwarding(servicePort, podPort, "")
-			exist[servicePortNumber] = true
+			if strings.Contains(strings.Split(port, "/")[0], ":") {
+				// "8000:8000" publishes the port on the hosts
+				service.AddPublishedPortForwarding(servicePort, podPort, protocol, int32(servicePortNumber))
+			} else {
+				service.AddPortForwardingWithProtocol(servicePort, podPort, "", protocol)
+			}
+			exist[key] = true
 		}
 	}
 	for _, port := range expose {
-		servicePortNumber, podPortNumber, _, err := c.parseContainerPort(port)
+		servicePortNumber, podPortNumber, protocol, err := c.parseContainerPort(port)
 		if err != nil {
 			continue
 		}
-		if !exist[servicePortNumber] {
+		if key := fmt.Sprintf("%d/%s", servicePortNumber, protocol); !exist[key] {
 			// Forward the port on the k8s service to the k8s pod.
 			podPort := networking.ServiceBackendPort{Number: int32(podPortNumber)}
 			servicePort := networking.ServiceBackendPort{Number: int32(servicePortNumber)}
-			service.AddPortForwarding(servicePort, podPort, "")
-			exist[servicePortNumber] = true
+			service.AddPortForwardingWithProtocol(servicePort, podPort, "", protocol)
+			exist[key] = true
 		}
 	}
 }
@@ -409,9 +511,7 @@ func (*v1v2Loader) parseContainerPort(value string) (servicePort int, podPort in
 	if strings.Contains(value, "/") {
 		parts := strings.Split(value, "/")
 		value = parts[0]
-		if strings.EqualFold(string(core.ProtocolUDP), parts[1]) {
-			protocol = core.ProtocolUDP
-		}
+		protocol = getProtocol(parts[1])
 	}
 	if !strings.Contains(value, ":") {
 		// "3000"
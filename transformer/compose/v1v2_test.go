@@ -0,0 +1,99 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestV1V2GetUnmappedOptions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		service string
+		want    []string
+	}{
+		{
+			name: "options with the default values",
+			service: `
+    network_mode: bridge
+    stop_signal: SIGTERM
+    logging:
+      driver: json-file`,
+			want: []string{},
+		},
+		{
+			name: "networking options",
+			service: `
+    network_mode: host
+    extra_hosts: ["db:10.0.0.1"]
+    dns: [8.8.8.8]
+    dns_search: [example.com]
+    external_links: [redis_1]`,
+			want: []string{"dns", "dns_search", "external_links", "extra_hosts", "network_mode"},
+		},
+		{
+			name: "runtime options",
+			service: `
+    pid: host
+    ipc: host
+    read_only: true
+    shm_size: 64m
+    oom_score_adj: 500
+    cgroup_parent: m2k
+    security_opt: ["label:disable"]
+    ulimits:
+      nofile: {soft: 1024, hard: 2048}
+    logging:
+      driver: syslog`,
+			want: []string{"cgroup_parent", "ipc", "logging", "oom_score_adj", "pid", "read_only", "security_opt", "shm_size", "ulimits"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			composeFilePath := writeComposeFile(t, "version: \"2\"\nservices:\n  web:\n    image: nginx"+testCase.service+"\n")
+			ir, err := (&v1v2Loader{}).ConvertToIR(composeFilePath, "web", false)
+			if err != nil {
+				t.Fatalf("failed to convert the compose file. Error: %q", err)
+			}
+			if got := getUnmappedOptionNames(ir.Services["web"].Annotations); !cmp.Equal(got, testCase.want) {
+				t.Fatalf("the unmapped options are different. Difference:\n%s", cmp.Diff(testCase.want, got))
+			}
+		})
+	}
+}
+
+func TestV1V2MapsTheProtocolsOfThePorts(t *testing.T) {
+	composeFilePath := writeComposeFile(t, "version: \"2\"\nservices:\n  dns:\n    image: coredns/coredns\n    ports: [\"53:53/udp\", \"53:53\"]\n    expose: [\"3868/sctp\"]\n")
+	ir, err := (&v1v2Loader{}).ConvertToIR(composeFilePath, "dns", false)
+	if err != nil {
+		t.Fatalf("failed to convert the compose file. Error: %q", err)
+	}
+	assertDNSPortProtocols(t, ir.Services["dns"])
+}
+
+func TestV1V2CollectsTheAliasesOfTheServices(t *testing.T) {
+	composeFilePath := writeComposeFile(t, "version: \"2\"\nservices:\n  db:\n    image: postgres\n    container_name: my-postgres\n    hostname: database\n    networks:\n      backend:\n        aliases: [pg, db]\n        ipv4_address: 172.16.238.10\nnetworks:\n  backend: {}\n")
+	ir, err := (&v1v2Loader{}).ConvertToIR(composeFilePath, "db", false)
+	if err != nil {
+		t.Fatalf("failed to convert the compose file. Error: %q", err)
+	}
+	if want, got := []string{"my-postgres", "database", "pg", "172.16.238.10"}, ir.Services["db"].Aliases; !cmp.Equal(got, want) {
+		t.Fatalf("the aliases differ. Differences:\n%s", cmp.Diff(want, got))
+	}
+}
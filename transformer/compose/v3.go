@@ -171,6 +171,7 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 			if ir.ContainerImages == nil {
 				ir.ContainerImages = map[string]irtypes.ContainerImage{}
 			}
+			serviceConfig.SessionAffinity = common.UsesServerSideSessions(filepath.Join(filedir, composeServiceConfig.Build.Context))
 			ir.ContainerImages[serviceContainer.Image] = irtypes.ContainerImage{
 				Build: irtypes.ContainerBuild{
 					ContainerBuildType: irtypes.DockerfileContainerBuildType,
@@ -178,6 +179,8 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 					Artifacts: map[irtypes.ContainerBuildArtifactTypeValue][]string{
 						irtypes.DockerfileContainerBuildArtifactTypeValue: {filepath.Join(filedir, composeServiceConfig.Build.Dockerfile)},
 					},
+					BuildArgs: getBuildArgs(composeServiceConfig.Build.Args),
+					Target:    composeServiceConfig.Build.Target,
 				},
 			}
 		}
@@ -185,35 +188,39 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 		serviceContainer.Command = composeServiceConfig.Entrypoint
 		serviceContainer.Args = composeServiceConfig.Command
 		serviceContainer.Stdin = composeServiceConfig.StdinOpen
+		aliases := []string{composeServiceConfig.Name, composeServiceConfig.ContainerName, composeServiceConfig.Hostname}
+		for _, network := range composeServiceConfig.Networks {
+			if network != nil {
+				aliases = append(aliases, network.Aliases...)
+				aliases = append(aliases, network.Ipv4Address, network.Ipv6Address)
+			}
+		}
+		serviceConfig.Aliases = getServiceAliases(serviceConfig.Name, aliases...)
 		if composeServiceConfig.ContainerName == "" {
 			composeServiceConfig.ContainerName = strings.ToLower(serviceConfig.Name)
 		}
 		serviceContainer.Name = common.NormalizeForMetadataName(composeServiceConfig.ContainerName)
 		serviceContainer.TTY = composeServiceConfig.Tty
 
-		if len(composeServiceConfig.Ports) == 0 {
+		// the ports are only published on the hosts when they are specified in the compose file
+		published := len(composeServiceConfig.Ports) != 0
+		if !published {
 			selectedPort := commonqa.GetPortForService(nil, `"`+serviceConfig.Name+`"`)
 			composeServiceConfig.Ports = []types.ServicePortConfig{{Protocol: "tcp", Target: uint32(selectedPort), Published: uint32(selectedPort)}}
 		}
 
 		serviceContainer.Ports = c.getPorts(composeServiceConfig.Ports, composeServiceConfig.Expose)
-		c.addPorts(composeServiceConfig.Ports, composeServiceConfig.Expose, &serviceConfig)
+		c.addPorts(composeServiceConfig.Ports, composeServiceConfig.Expose, published, &serviceConfig)
 
 		serviceConfig.Annotations = map[string]string(composeServiceConfig.Labels)
 		serviceConfig.Labels = common.MergeStringMaps(composeServiceConfig.Labels, composeServiceConfig.Deploy.Labels)
+		unmapped := c.getUnmappedOptions(name, composeServiceConfig)
 		if composeServiceConfig.Hostname != "" {
 			serviceConfig.Hostname = composeServiceConfig.Hostname
 		}
 		if composeServiceConfig.DomainName != "" {
 			serviceConfig.Subdomain = composeServiceConfig.DomainName
 		}
-		if composeServiceConfig.Pid != "" {
-			if composeServiceConfig.Pid == "host" {
-				serviceConfig.SecurityContext.HostPID = true
-			} else {
-				logrus.Warnf("Ignoring PID key for service \"%v\". Invalid value \"%v\".", name, composeServiceConfig.Pid)
-			}
-		}
 		securityContext := &core.SecurityContext{}
 		if composeServiceConfig.Privileged {
 			securityContext.Privileged = &composeServiceConfig.Privileged
@@ -221,7 +228,7 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 		if composeServiceConfig.User != "" {
 			uid, err := cast.ToInt64E(composeServiceConfig.User)
 			if err != nil {
-				logrus.Warn("Ignoring user directive. User to be specified as a UID (numeric).")
+				unmapped.add(name, "user", composeServiceConfig.User)
 			} else {
 				securityContext.RunAsUser = &uid
 			}
@@ -246,6 +253,30 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 			serviceContainer.SecurityContext = securityContext
 		}
 		podSecurityContext := &core.PodSecurityContext{}
+		if composeServiceConfig.Pid != "" {
+			if composeServiceConfig.Pid == "host" {
+				podSecurityContext.HostPID = true
+			} else {
+				unmapped.add(name, "pid", composeServiceConfig.Pid)
+			}
+		}
+		if len(composeServiceConfig.Sysctls) > 0 {
+			podSecurityContext.Sysctls = getSysctls(name, composeServiceConfig.Sysctls)
+		}
+		if len(composeServiceConfig.Ulimits) > 0 {
+			ulimits := map[string][2]int64{}
+			for ulimitName, ulimit := range composeServiceConfig.Ulimits {
+				if ulimit == nil {
+					continue
+				}
+				if ulimit.Single != 0 {
+					ulimits[ulimitName] = [2]int64{int64(ulimit.Single), int64(ulimit.Single)}
+				} else {
+					ulimits[ulimitName] = [2]int64{int64(ulimit.Soft), int64(ulimit.Hard)}
+				}
+			}
+			unmapped.addUlimits(name, ulimits)
+		}
 		if !cmp.Equal(*podSecurityContext, core.PodSecurityContext{}) {
 			serviceConfig.SecurityContext = podSecurityContext
 		}
@@ -256,6 +287,7 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 		if parseNetwork {
 			serviceConfig.Networks = c.getNetworks(composeServiceConfig, composeObject)
 		}
+		serviceConfig.DependsOn = getDependsOn(composeServiceConfig.DependsOn, composeServiceConfig.Links)
 		if (composeServiceConfig.Deploy.Resources != types.Resources{}) {
 			if composeServiceConfig.Deploy.Resources.Limits != nil {
 				resourceLimit := core.ResourceList{}
@@ -316,6 +348,14 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 		if composeServiceConfig.Deploy.Replicas != nil {
 			serviceConfig.Replicas = int(*composeServiceConfig.Deploy.Replicas)
 		}
+		// update_config:
+		if composeServiceConfig.Deploy.UpdateConfig != nil {
+			serviceConfig.RolloutStrategy = getRolloutStrategy(*composeServiceConfig.Deploy.UpdateConfig)
+		}
+		if composeServiceConfig.StopGracePeriod != nil {
+			stopGracePeriod := int64(time.Duration(*composeServiceConfig.StopGracePeriod).Seconds())
+			serviceConfig.TerminationGracePeriodSeconds = &stopGracePeriod
+		}
 		serviceContainer.Env = c.getEnvs(composeServiceConfig)
 
 		vml, vl := makeVolumesFromTmpFS(name, composeServiceConfig.Tmpfs)
@@ -403,6 +443,16 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 
 		for _, vol := range composeServiceConfig.Volumes {
 			if isPath(vol.Source) {
+				if isTimeZoneMount(vol.Source, vol.Target) {
+					setTimeZone(name, vol.Source, &serviceContainer)
+					continue
+				}
+				if volumeMount, volume, storage, ok := getCredentialFilesSecret(filedir, vol.Source, vol.Target, vol.ReadOnly); ok {
+					serviceContainer.VolumeMounts = append(serviceContainer.VolumeMounts, volumeMount)
+					serviceConfig.AddVolume(volume)
+					ir.AddStorage(storage)
+					continue
+				}
 				hPath := vol.Source
 				if !filepath.IsAbs(vol.Source) {
 					hPath, err := filepath.Abs(vol.Source)
@@ -452,14 +502,97 @@ func (c *v3Loader) convertToIR(filedir string, composeObject types.Config, servi
 		}
 
 		serviceConfig.Containers = []core.Container{serviceContainer}
+		serviceConfig.Annotations = unmapped.getAnnotations(serviceConfig.Annotations)
 		ir.Services[name] = serviceConfig
 	}
 
 	return ir, nil
 }
 
+// getUnmappedOptions returns the options of the service which have no equivalent in the generated resources
+func (c *v3Loader) getUnmappedOptions(name string, composeServiceConfig types.ServiceConfig) unmappedOptions {
+	unmapped := unmappedOptions{}
+	if composeServiceConfig.NetworkMode != "" && composeServiceConfig.NetworkMode != "bridge" && composeServiceConfig.NetworkMode != "default" {
+		unmapped.add(name, "network_mode", composeServiceConfig.NetworkMode)
+	}
+	if len(composeServiceConfig.ExtraHosts) != 0 {
+		unmapped.add(name, "extra_hosts", strings.Join(composeServiceConfig.ExtraHosts, ", "))
+	}
+	if len(composeServiceConfig.DNS) != 0 {
+		unmapped.add(name, "dns", strings.Join(composeServiceConfig.DNS, ", "))
+	}
+	if len(composeServiceConfig.DNSSearch) != 0 {
+		unmapped.add(name, "dns_search", strings.Join(composeServiceConfig.DNSSearch, ", "))
+	}
+	if len(composeServiceConfig.Devices) != 0 {
+		unmapped.add(name, "devices", strings.Join(composeServiceConfig.Devices, ", "))
+	}
+	// the options of the logging drivers are left out, since they often contain tokens
+	if composeServiceConfig.Logging != nil && composeServiceConfig.Logging.Driver != "" && composeServiceConfig.Logging.Driver != "json-file" {
+		unmapped.add(name, "logging", "driver "+composeServiceConfig.Logging.Driver)
+	}
+	if len(composeServiceConfig.SecurityOpt) != 0 {
+		unmapped.add(name, "security_opt", strings.Join(composeServiceConfig.SecurityOpt, ", "))
+	}
+	if composeServiceConfig.ShmSize != "" {
+		unmapped.add(name, "shm_size", composeServiceConfig.ShmSize)
+	}
+	if composeServiceConfig.Ipc != "" {
+		unmapped.add(name, "ipc", composeServiceConfig.Ipc)
+	}
+	if composeServiceConfig.CgroupParent != "" {
+		unmapped.add(name, "cgroup_parent", composeServiceConfig.CgroupParent)
+	}
+	if composeServiceConfig.StopSignal != "" && composeServiceConfig.StopSignal != "SIGTERM" {
+		unmapped.add(name, "stop_signal", composeServiceConfig.StopSignal)
+	}
+	if composeServiceConfig.UserNSMode != "" {
+		unmapped.add(name, "userns_mode", composeServiceConfig.UserNSMode)
+	}
+	if composeServiceConfig.ReadOnly {
+		unmapped.add(name, "read_only", "true")
+	}
+	if composeServiceConfig.Init != nil && *composeServiceConfig.Init {
+		unmapped.add(name, "init", "true")
+	}
+	if composeServiceConfig.MacAddress != "" {
+		unmapped.add(name, "mac_address", composeServiceConfig.MacAddress)
+	}
+	if composeServiceConfig.Isolation != "" && composeServiceConfig.Isolation != "default" {
+		unmapped.add(name, "isolation", composeServiceConfig.Isolation)
+	}
+	if composeServiceConfig.CredentialSpec != (types.CredentialSpecConfig{}) {
+		unmapped.add(name, "credential_spec", strings.TrimSpace(strings.Join([]string{composeServiceConfig.CredentialSpec.Config, composeServiceConfig.CredentialSpec.File, composeServiceConfig.CredentialSpec.Registry}, " ")))
+	}
+	if len(composeServiceConfig.ExternalLinks) != 0 {
+		unmapped.add(name, "external_links", strings.Join(composeServiceConfig.ExternalLinks, ", "))
+	}
+	placement := composeServiceConfig.Deploy.Placement
+	if len(placement.Constraints) != 0 || len(placement.Preferences) != 0 || placement.MaxReplicas != 0 {
+		values := append([]string{}, placement.Constraints...)
+		for _, preference := range placement.Preferences {
+			values = append(values, "spread "+preference.Spread)
+		}
+		if placement.MaxReplicas != 0 {
+			values = append(values, fmt.Sprintf("max_replicas_per_node %d", placement.MaxReplicas))
+		}
+		unmapped.add(name, "deploy.placement", strings.Join(values, ", "))
+	}
+	if composeServiceConfig.Deploy.EndpointMode == "dnsrr" {
+		unmapped.add(name, "deploy.endpoint_mode", composeServiceConfig.Deploy.EndpointMode)
+	}
+	if composeServiceConfig.Deploy.RollbackConfig != nil {
+		value := "configured"
+		if composeServiceConfig.Deploy.RollbackConfig.FailureAction != "" {
+			value = "failure_action " + composeServiceConfig.Deploy.RollbackConfig.FailureAction
+		}
+		unmapped.add(name, "deploy.rollback_config", value)
+	}
+	return unmapped
+}
+
 func (c *v3Loader) getSecretStorages(secrets map[string]types.SecretConfig) []irtypes.Storage {
-	storages := make([]irtypes.Storage, len(secrets))
+	storages := []irtypes.Storage{}
 	for secretName, secretObj := range secrets {
 		storage := irtypes.Storage{
 			Name:        secretName,
@@ -522,16 +655,13 @@ func (*v3Loader) getPorts(ports []types.ServicePortConfig, expose []string) []co
 	containerPorts := []core.ContainerPort{}
 	exist := map[string]bool{}
 	for _, port := range ports {
-		proto := core.ProtocolTCP
-		if strings.EqualFold(string(core.ProtocolUDP), port.Protocol) {
-			proto = core.ProtocolUDP
-		}
+		proto := getProtocol(port.Protocol)
 		// Add the port to the k8s pod.
 		containerPorts = append(containerPorts, core.ContainerPort{
 			ContainerPort: int32(port.Target),
 			Protocol:      proto,
 		})
-		exist[cast.ToString(port.Target)] = true
+		exist[cast.ToString(port.Target)+"/"+string(proto)] = true
 	}
 	for _, port := range expose {
 		portValue := port
@@ -539,9 +669,9 @@ func (*v3Loader) getPorts(ports []types.ServicePortConfig, expose []string) []co
 		if strings.Contains(portValue, "/") {
 			splits := strings.Split(port, "/")
 			portValue = splits[0]
-			protocol = core.Protocol(strings.ToUpper(splits[1]))
+			protocol = getProtocol(splits[1])
 		}
-		if exist[portValue] {
+		if exist[portValue+"/"+string(protocol)] {
 			continue
 		}
 		// Add the port to the k8s pod.
@@ -554,7 +684,7 @@ func (*v3Loader) getPorts(ports []types.ServicePortConfig, expose []string) []co
 	return containerPorts
 }
 
-func (*v3Loader) addPorts(ports []types.ServicePortConfig, expose []string, service *irtypes.Service) {
+func (*v3Loader) addPorts(ports []types.ServicePortConfig, expose []string, published bool, service *irtypes.Service) {
 	exist := map[string]bool{}
 	for _, port := range ports {
 		// Forward the port on the k8s service to the k8s pod.
@@ -564,16 +694,23 @@ func (*v3Loader) addPorts(ports []types.ServicePortConfig, expose []string, serv
 		servicePort := networking.ServiceBackendPort{
 			Number: int32(port.Published),
 		}
-		service.AddPortForwarding(servicePort, podPort, "")
-		exist[cast.ToString(port.Target)] = true
+		protocol := getProtocol(port.Protocol)
+		if published {
+			service.AddPublishedPortForwarding(servicePort, podPort, protocol, int32(port.Published))
+		} else {
+			service.AddPortForwardingWithProtocol(servicePort, podPort, "", protocol)
+		}
+		exist[cast.ToString(port.Target)+"/"+string(protocol)] = true
 	}
 	for _, port := range expose {
 		portValue := port
+		protocol := core.ProtocolTCP
 		if strings.Contains(portValue, "/") {
 			splits := strings.Split(port, "/")
 			portValue = splits[0]
+			protocol = getProtocol(splits[1])
 		}
-		if exist[portValue] {
+		if exist[portValue+"/"+string(protocol)] {
 			continue
 		}
 		// Forward the port on the k8s service to the k8s pod.
@@ -584,7 +721,7 @@ func (*v3Loader) addPorts(ports []types.ServicePortConfig, expose []string, serv
 		servicePort := networking.ServiceBackendPort{
 			Number: portNumber,
 		}
-		service.AddPortForwarding(servicePort, podPort, "")
+		service.AddPortForwardingWithProtocol(servicePort, podPort, "", protocol)
 	}
 }
 
@@ -678,3 +815,24 @@ func (c *v3Loader) getAllDirContentAsMap(directoryPath string) (map[string][]byt
 	logrus.Debugf("Read %d files into the data map", count)
 	return dataMap, nil
 }
+
+// getRolloutStrategy maps the update config of the swarm to the rolling update, which replaces parallelism tasks at a time
+func getRolloutStrategy(updateConfig types.UpdateConfig) irtypes.RolloutStrategy {
+	parallelism := "1"
+	if updateConfig.Parallelism != nil {
+		parallelism = cast.ToString(*updateConfig.Parallelism)
+		if *updateConfig.Parallelism == 0 {
+			// zero updates all the tasks at once
+			parallelism = "100%"
+		}
+	}
+	strategy := irtypes.RolloutStrategy{Type: irtypes.RollingUpdateRolloutStrategy}
+	if updateConfig.Order == "start-first" {
+		strategy.MaxSurge = parallelism
+		strategy.MaxUnavailable = "0"
+	} else {
+		strategy.MaxSurge = "0"
+		strategy.MaxUnavailable = parallelism
+	}
+	return strategy
+}
@@ -0,0 +1,232 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package compose
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestV3GetUnmappedOptions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		service string
+		want    []string
+	}{
+		{
+			name: "options with the default values",
+			service: `
+    network_mode: bridge
+    pid: host
+    stop_signal: SIGTERM
+    logging:
+      driver: json-file`,
+			want: []string{},
+		},
+		{
+			name: "networking options",
+			service: `
+    network_mode: host
+    extra_hosts: ["db:10.0.0.1"]
+    dns: [8.8.8.8]
+    dns_search: [example.com]
+    mac_address: 02:42:ac:11:65:43`,
+			want: []string{"dns", "dns_search", "extra_hosts", "mac_address", "network_mode"},
+		},
+		{
+			name: "runtime options",
+			service: `
+    pid: "service:other"
+    ipc: host
+    init: true
+    read_only: true
+    shm_size: 64m
+    stop_signal: SIGINT
+    ulimits:
+      nofile: {soft: 1024, hard: 2048}
+    logging:
+      driver: syslog
+      options: {syslog-address: "tcp://logs:514"}`,
+			want: []string{"init", "ipc", "logging", "pid", "read_only", "shm_size", "stop_signal", "ulimits"},
+		},
+		{
+			name: "swarm options",
+			service: `
+    deploy:
+      endpoint_mode: dnsrr
+      placement:
+        constraints: [node.role == worker]
+      rollback_config:
+        failure_action: pause`,
+			want: []string{"deploy.endpoint_mode", "deploy.placement", "deploy.rollback_config"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			composeFilePath := writeComposeFile(t, "version: \"3.8\"\nservices:\n  web:\n    image: nginx"+testCase.service+"\n")
+			ir, err := (&v3Loader{}).ConvertToIR(composeFilePath, "web", false)
+			if err != nil {
+				t.Fatalf("failed to convert the compose file. Error: %q", err)
+			}
+			if got := getUnmappedOptionNames(ir.Services["web"].Annotations); !cmp.Equal(got, testCase.want) {
+				t.Fatalf("the unmapped options are different. Difference:\n%s", cmp.Diff(testCase.want, got))
+			}
+		})
+	}
+}
+
+func TestV3MapsTheHostPIDMode(t *testing.T) {
+	composeFilePath := writeComposeFile(t, "version: \"3.8\"\nservices:\n  web:\n    image: nginx\n    pid: host\n")
+	ir, err := (&v3Loader{}).ConvertToIR(composeFilePath, "web", false)
+	if err != nil {
+		t.Fatalf("failed to convert the compose file. Error: %q", err)
+	}
+	if securityContext := ir.Services["web"].SecurityContext; securityContext == nil || !securityContext.HostPID {
+		t.Fatalf("expected the pods to use the host pid namespace, got the security context %+v", securityContext)
+	}
+}
+
+func TestV3GetUnmappedOptionsLeavesOutTheLoggingOptions(t *testing.T) {
+	composeFilePath := writeComposeFile(t, `version: "3.8"
+services:
+  web:
+    image: nginx
+    logging:
+      driver: splunk
+      options: {splunk-token: s3cr3t-token}
+`)
+	ir, err := (&v3Loader{}).ConvertToIR(composeFilePath, "web", false)
+	if err != nil {
+		t.Fatalf("failed to convert the compose file. Error: %q", err)
+	}
+	got := ir.Services["web"].Annotations[common.TODOAnnotation+composeTODOCategoryPrefix+"logging"]
+	if want := "The compose option logging (driver splunk) of the service web was not mapped. " + unmappedOptionRecommendations["logging"]; got != want {
+		t.Fatalf("got the TODO %q , want %q", got, want)
+	}
+}
+
+func TestV3MapsTheProtocolsOfThePorts(t *testing.T) {
+	composeFilePath := writeComposeFile(t, "version: \"3.8\"\nservices:\n  dns:\n    image: coredns/coredns\n    ports: [\"53:53/udp\", \"53:53\"]\n    expose: [\"3868/sctp\"]\n")
+	ir, err := (&v3Loader{}).ConvertToIR(composeFilePath, "dns", false)
+	if err != nil {
+		t.Fatalf("failed to convert the compose file. Error: %q", err)
+	}
+	assertDNSPortProtocols(t, ir.Services["dns"])
+}
+
+func TestV3CollectsTheAliasesOfTheServices(t *testing.T) {
+	composeFilePath := writeComposeFile(t, "version: \"3.8\"\nservices:\n  db:\n    image: postgres\n    container_name: my-postgres\n    hostname: database\n    networks:\n      backend:\n        aliases: [pg, db]\n        ipv4_address: 172.16.238.10\nnetworks:\n  backend: {}\n")
+	ir, err := (&v3Loader{}).ConvertToIR(composeFilePath, "db", false)
+	if err != nil {
+		t.Fatalf("failed to convert the compose file. Error: %q", err)
+	}
+	if want, got := []string{"my-postgres", "database", "pg", "172.16.238.10"}, ir.Services["db"].Aliases; !cmp.Equal(got, want) {
+		t.Fatalf("the aliases differ. Differences:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestV3MountsTheCredentialFilesFromSecrets(t *testing.T) {
+	composeFilePath := writeComposeFile(t, "version: \"3.8\"\nservices:\n  web:\n    image: nginx\n    volumes:\n      - ./server.pem:/etc/nginx/server.pem:ro\n      - ./html:/usr/share/nginx/html\n")
+	for name, contents := range map[string]string{"server.pem": "certificate", filepath.Join("html", "index.html"): "hello"} {
+		path := filepath.Join(filepath.Dir(composeFilePath), name)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory of %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	ir, err := (&v3Loader{}).ConvertToIR(composeFilePath, "web", false)
+	if err != nil {
+		t.Fatalf("failed to convert the compose file. Error: %q", err)
+	}
+	secrets := []irtypes.Storage{}
+	for _, storage := range ir.Storages {
+		if storage.StorageType == irtypes.SecretKind {
+			secrets = append(secrets, storage)
+		}
+	}
+	if len(secrets) != 1 || string(secrets[0].Content["server.pem"]) != "certificate" {
+		t.Fatalf("expected a secret with the certificate. Actual: %+v", ir.Storages)
+	}
+	web := ir.Services["web"]
+	mounts := map[string]string{}
+	for _, volumeMount := range web.Containers[0].VolumeMounts {
+		mounts[volumeMount.MountPath] = volumeMount.Name
+	}
+	if mounts["/etc/nginx/server.pem"] != secrets[0].Name {
+		t.Fatalf("expected the certificate to be mounted from the secret. Actual: %+v", web.Containers[0].VolumeMounts)
+	}
+	for _, volume := range web.Volumes {
+		if volume.Name == mounts["/usr/share/nginx/html"] && volume.Secret != nil {
+			t.Fatalf("expected the other files not to be mounted from a secret. Actual: %+v", volume)
+		}
+	}
+}
+
+func TestV3MapsTheTimeZoneAndTheSysctls(t *testing.T) {
+	composeFilePath := writeComposeFile(t, "version: \"3.8\"\nservices:\n  web:\n    image: nginx\n    sysctls:\n      net.core.somaxconn: 1024\n    volumes:\n      - /usr/share/zoneinfo/Europe/Paris:/etc/localtime:ro\n")
+	ir, err := (&v3Loader{}).ConvertToIR(composeFilePath, "web", false)
+	if err != nil {
+		t.Fatalf("failed to convert the compose file. Error: %q", err)
+	}
+	web := ir.Services["web"]
+	if want := []core.EnvVar{{Name: "TZ", Value: "Europe/Paris"}}; !cmp.Equal(web.Containers[0].Env, want) {
+		t.Fatalf("expected the time zone to be set in the environment. Difference:\n%s", cmp.Diff(want, web.Containers[0].Env))
+	}
+	if len(web.Containers[0].VolumeMounts) != 0 || len(web.Volumes) != 0 {
+		t.Fatalf("expected the time zone of the host not to be mounted. Actual: %+v %+v", web.Containers[0].VolumeMounts, web.Volumes)
+	}
+	if web.SecurityContext == nil || !cmp.Equal(web.SecurityContext.Sysctls, []core.Sysctl{{Name: "net.core.somaxconn", Value: "1024"}}) {
+		t.Fatalf("expected the sysctls in the security context of the pods. Actual: %+v", web.SecurityContext)
+	}
+}
+
+func TestV3MapsTheUpdateConfigToTheRolloutStrategy(t *testing.T) {
+	testCases := []struct {
+		updateConfig string
+		want         irtypes.RolloutStrategy
+	}{
+		{updateConfig: "{}", want: irtypes.RolloutStrategy{Type: irtypes.RollingUpdateRolloutStrategy, MaxSurge: "0", MaxUnavailable: "1"}},
+		{updateConfig: "{parallelism: 2, order: start-first}", want: irtypes.RolloutStrategy{Type: irtypes.RollingUpdateRolloutStrategy, MaxSurge: "2", MaxUnavailable: "0"}},
+		{updateConfig: "{parallelism: 0, order: stop-first}", want: irtypes.RolloutStrategy{Type: irtypes.RollingUpdateRolloutStrategy, MaxSurge: "0", MaxUnavailable: "100%"}},
+	}
+	for _, testCase := range testCases {
+		composeFilePath := writeComposeFile(t, "version: \"3.8\"\nservices:\n  web:\n    image: nginx\n    deploy:\n      update_config: "+testCase.updateConfig+"\n")
+		ir, err := (&v3Loader{}).ConvertToIR(composeFilePath, "web", false)
+		if err != nil {
+			t.Fatalf("failed to convert the compose file. Error: %q", err)
+		}
+		if got := ir.Services["web"].RolloutStrategy; !cmp.Equal(got, testCase.want) {
+			t.Errorf("the update config %s was mapped to %+v, want %+v", testCase.updateConfig, got, testCase.want)
+		}
+	}
+	composeFilePath := writeComposeFile(t, "version: \"3.8\"\nservices:\n  web:\n    image: nginx\n")
+	ir, err := (&v3Loader{}).ConvertToIR(composeFilePath, "web", false)
+	if err != nil {
+		t.Fatalf("failed to convert the compose file. Error: %q", err)
+	}
+	if got := ir.Services["web"].RolloutStrategy; got.Type != "" {
+		t.Errorf("expected no rollout strategy without an update config, got %+v", got)
+	}
+}
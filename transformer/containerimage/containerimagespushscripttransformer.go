@@ -49,6 +49,10 @@ type ImagePushTemplateConfig struct {
 	RegistryURL       string
 	RegistryNamespace string
 	Images            []string
+	SignImages        bool
+	SigningKeyRef     string
+	commonqa.RegistryScriptCommands
+	commonqa.ScriptHookSnippets
 }
 
 // Init Initializes the transformer
@@ -96,6 +100,9 @@ func (t *ContainerImagesPushScript) Transform(newArtifacts []transformertypes.Ar
 	}
 	ipt.RegistryURL = commonqa.ImageRegistry()
 	ipt.RegistryNamespace = commonqa.ImageRegistryNamespace()
+	ipt.ScriptHookSnippets = commonqa.GetScriptHooks(pushImagesFileName)
+	ipt.SignImages, ipt.SigningKeyRef = commonqa.ImageSigning()
+	ipt.RegistryScriptCommands = commonqa.GetRegistryScriptCommands(ipt.RegistryURL, ipt.Images, "${CONTAINER_RUNTIME}", "%CONTAINER_RUNTIME%")
 	pathMappings = append(pathMappings, transformertypes.PathMapping{
 		Type:           transformertypes.TemplatePathMappingType,
 		SrcPath:        filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir),
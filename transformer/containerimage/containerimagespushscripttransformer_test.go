@@ -0,0 +1,119 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package containerimage
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+)
+
+func TestPushImagesScriptHooks(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	hookFile := filepath.Join(t.TempDir(), "notify.bat")
+	if err := os.WriteFile(hookFile, []byte("curl -X POST https://hooks.example.com/pushed\n"), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the hook file. Error: %q", err)
+	}
+	qaengine.SetupConfigFile("", []string{
+		common.ConfigScriptsHooksEnabledKey + "=true",
+		common.JoinQASubKeys(common.ConfigScriptsKey, pushImagesFileName, common.ConfigScriptPreHookKeySuffix) + `="./gate.sh"`,
+		common.JoinQASubKeys(common.ConfigScriptsKey, pushImagesFileName, common.ConfigScriptWindowsHookKeySegment, common.ConfigScriptPreHookKeySuffix) + `="call gate.bat"`,
+		common.JoinQASubKeys(common.ConfigScriptsKey, pushImagesFileName, common.ConfigScriptWindowsHookKeySegment, common.ConfigScriptPostHookKeySuffix) + `="` + hookFile + `"`,
+	}, nil, nil, false)
+	ipt := ImagePushTemplateConfig{RegistryURL: "quay.io", RegistryNamespace: "myproject", Images: []string{"api"}}
+	ipt.ScriptHookSnippets = commonqa.GetScriptHooks(pushImagesFileName)
+	want := commonqa.ScriptHookSnippets{PreHook: "./gate.sh", PreHookWindows: "call gate.bat", PostHookWindows: "curl -X POST https://hooks.example.com/pushed"}
+	if ipt.ScriptHookSnippets != want {
+		t.Fatalf("got the hooks %+v , want %+v", ipt.ScriptHookSnippets, want)
+	}
+	templatesDir := filepath.Join("..", "..", "assets", "built-in", "transformers", "containerimagespushscript", "templates")
+	for script, hooks := range map[string][]string{"pushimages.sh": {want.PreHook}, "pushimages.bat": {want.PreHookWindows, want.PostHookWindows}} {
+		tpl, err := os.ReadFile(filepath.Join(templatesDir, script))
+		if err != nil {
+			t.Fatalf("failed to read the template %s . Error: %q", script, err)
+		}
+		out, err := common.GetStringFromTemplate(string(tpl), ipt)
+		if err != nil {
+			t.Fatalf("failed to fill the template %s . Error: %q", script, err)
+		}
+		pushIdx := strings.Index(out, "pushing image api")
+		for i, hook := range hooks {
+			hookIdx := strings.Index(out, hook)
+			if hookIdx == -1 {
+				t.Fatalf("expected the hook %q in the script %s :\n%s", hook, script, out)
+			}
+			if before := i == 0; before != (hookIdx < pushIdx) {
+				t.Fatalf("expected the hook %q to be in the right place in the script %s :\n%s", hook, script, out)
+			}
+		}
+	}
+}
+
+func TestPushImagesScriptSigning(t *testing.T) {
+	templatesDir := filepath.Join("..", "..", "assets", "built-in", "transformers", "containerimagespushscript", "templates")
+	testCases := []struct {
+		name          string
+		signImages    bool
+		signingKeyRef string
+		want          map[string]string
+	}{
+		{name: "not signed", want: map[string]string{"pushimages.sh": "", "pushimages.bat": ""}},
+		{name: "keyless", signImages: true, want: map[string]string{
+			"pushimages.sh":  "cosign sign --yes ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/api",
+			"pushimages.bat": "cosign sign --yes %REGISTRY_URL%/%REGISTRY_NAMESPACE%/api",
+		}},
+		{name: "with a key", signImages: true, signingKeyRef: "awskms:///alias/signing", want: map[string]string{
+			"pushimages.sh":  "cosign sign --yes --key awskms:///alias/signing ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/api",
+			"pushimages.bat": "cosign sign --yes --key awskms:///alias/signing %REGISTRY_URL%/%REGISTRY_NAMESPACE%/api",
+		}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ipt := ImagePushTemplateConfig{RegistryURL: "quay.io", RegistryNamespace: "myproject", Images: []string{"api"}, SignImages: testCase.signImages, SigningKeyRef: testCase.signingKeyRef}
+			for script, wantCommand := range testCase.want {
+				tpl, err := os.ReadFile(filepath.Join(templatesDir, script))
+				if err != nil {
+					t.Fatalf("failed to read the template %s . Error: %q", script, err)
+				}
+				out, err := common.GetStringFromTemplate(string(tpl), ipt)
+				if err != nil {
+					t.Fatalf("failed to fill the template %s . Error: %q", script, err)
+				}
+				if wantCommand == "" {
+					if strings.Contains(out, "cosign") {
+						t.Fatalf("expected the images not to be signed by the script %s :\n%s", script, out)
+					}
+					continue
+				}
+				signIdx := strings.Index(out, wantCommand)
+				if signIdx == -1 {
+					t.Fatalf("expected the command %q in the script %s :\n%s", wantCommand, script, out)
+				}
+				if pushIdx := strings.Index(out, " push "); pushIdx == -1 || pushIdx > signIdx {
+					t.Fatalf("expected the image to be signed after it is pushed by the script %s :\n%s", script, out)
+				}
+			}
+		})
+	}
+}
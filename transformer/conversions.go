@@ -0,0 +1,68 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
+	"github.com/konveyor/move2kube/types"
+)
+
+const (
+	// ConversionsReportFile is the name of the file listing the objects which were ignored during the transformation
+	ConversionsReportFile = types.AppNameShort + "-conversions.yaml"
+	// ConversionsReportKind defines the kind of the conversions report file
+	ConversionsReportKind types.Kind = "ConversionsReport"
+)
+
+// ConversionsReport stores the objects which were dropped or written in a version not supported by the target cluster
+type ConversionsReport struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty"`
+	Spec             ConversionsReportSpec `yaml:"spec,omitempty"`
+}
+
+// ConversionsReportSpec stores the ignored objects along with the reasons
+type ConversionsReportSpec struct {
+	IgnoredObjects []apiresource.ConversionReportEntry `yaml:"ignoredObjects"`
+}
+
+// GetIgnoredObjectsCount returns the number of objects ignored during the transformation
+func GetIgnoredObjectsCount() int {
+	return len(apiresource.GetIgnoredObjects())
+}
+
+// writeConversionsReport writes the objects ignored during the transformation. Nothing is written if no object was ignored.
+func writeConversionsReport(outputPath string) error {
+	ignoredObjects := apiresource.GetIgnoredObjects()
+	if len(ignoredObjects) == 0 {
+		return nil
+	}
+	report := ConversionsReport{
+		TypeMeta:   types.TypeMeta{Kind: string(ConversionsReportKind), APIVersion: types.SchemeGroupVersion.String()},
+		ObjectMeta: types.ObjectMeta{Name: common.ProjectName},
+		Spec:       ConversionsReportSpec{IgnoredObjects: ignoredObjects},
+	}
+	reportPath := filepath.Join(outputPath, ConversionsReportFile)
+	if err := common.WriteYaml(reportPath, report); err != nil {
+		return fmt.Errorf("failed to write the conversions report to the file at path %s . Error: %w", reportPath, err)
+	}
+	return nil
+}
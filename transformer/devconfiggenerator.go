@@ -0,0 +1,370 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	devSpaceDevTool = "DevSpace"
+	oktetoDevTool   = "Okteto"
+	noDevTool       = "None"
+
+	devSpaceFileName = "devspace.yaml"
+	oktetoFileName   = "okteto.yml"
+	// defaultDevContainerPath is used for syncing when the working directory of the container is not known
+	defaultDevContainerPath = "/app"
+)
+
+// DevConfigGenerator implements Transformer interface
+type DevConfigGenerator struct {
+	Config                   transformertypes.Transformer
+	Env                      *environment.Environment
+	DevConfigGeneratorConfig *DevConfigGeneratorYamlConfig
+}
+
+// DevConfigGeneratorYamlConfig contains all the configuration from the transformer YAML
+type DevConfigGeneratorYamlConfig struct {
+	DeployPath string `yaml:"deployPath"`
+}
+
+// devService stores the details of a service that are needed for inner loop development
+type devService struct {
+	Name string
+	// Workload is the name of the deployment of the service
+	Workload string
+	// Container is the name of the container using the image, when the pods of the deployment have several containers
+	Container     string
+	Image         string
+	Context       string
+	Dockerfile    string
+	ContainerPath string
+	Ports         []int32
+}
+
+type devSpaceConfig struct {
+	Version     string                   `yaml:"version"`
+	Images      map[string]devSpaceImage `yaml:"images,omitempty"`
+	Deployments []devSpaceDeployment     `yaml:"deployments"`
+	Dev         devSpaceDev              `yaml:"dev"`
+}
+
+type devSpaceImage struct {
+	Image      string `yaml:"image"`
+	Dockerfile string `yaml:"dockerfile"`
+	Context    string `yaml:"context"`
+}
+
+type devSpaceDeployment struct {
+	Name    string          `yaml:"name"`
+	Kubectl devSpaceKubectl `yaml:"kubectl"`
+}
+
+type devSpaceKubectl struct {
+	Manifests []string `yaml:"manifests"`
+}
+
+type devSpaceDev struct {
+	Ports []devSpacePort `yaml:"ports,omitempty"`
+	Sync  []devSpaceSync `yaml:"sync,omitempty"`
+}
+
+type devSpacePort struct {
+	ImageSelector string                `yaml:"imageSelector"`
+	Forward       []devSpacePortForward `yaml:"forward"`
+}
+
+type devSpacePortForward struct {
+	Port int32 `yaml:"port"`
+}
+
+type devSpaceSync struct {
+	ImageSelector string             `yaml:"imageSelector"`
+	LocalSubPath  string             `yaml:"localSubPath"`
+	ContainerPath string             `yaml:"containerPath"`
+	ExcludePaths  []string           `yaml:"excludePaths,omitempty"`
+	OnUpload      devSpaceSyncUpload `yaml:"onUpload"`
+}
+
+type devSpaceSyncUpload struct {
+	RestartContainer bool `yaml:"restartContainer"`
+}
+
+type oktetoConfig struct {
+	Name   string                 `yaml:"name"`
+	Build  map[string]oktetoBuild `yaml:"build,omitempty"`
+	Deploy []string               `yaml:"deploy"`
+	Dev    map[string]oktetoDev   `yaml:"dev,omitempty"`
+}
+
+type oktetoBuild struct {
+	Context    string `yaml:"context"`
+	Dockerfile string `yaml:"dockerfile"`
+}
+
+type oktetoDev struct {
+	// Container selects the container of the deployment, when the pods have several containers
+	Container string   `yaml:"container,omitempty"`
+	Image     string   `yaml:"image"`
+	Command   []string `yaml:"command"`
+	Sync      []string `yaml:"sync"`
+	Forward   []string `yaml:"forward,omitempty"`
+}
+
+// Init initializes the transformer
+func (t *DevConfigGenerator) Init(tc transformertypes.Transformer, env *environment.Environment) error {
+	t.Config = tc
+	t.Env = env
+	t.DevConfigGeneratorConfig = &DevConfigGeneratorYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.DevConfigGeneratorConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.DevConfigGeneratorConfig, err)
+		return err
+	}
+	if t.DevConfigGeneratorConfig.DeployPath == "" {
+		t.DevConfigGeneratorConfig.DeployPath = filepath.Join(common.DeployDir, "yamls")
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *DevConfigGenerator) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in each sub directory
+func (t *DevConfigGenerator) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform transforms the artifacts
+func (t *DevConfigGenerator) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	dockerfiles := map[string]devService{}
+	irs := []irtypes.IR{}
+	for _, a := range append(alreadySeenArtifacts, newArtifacts...) {
+		switch a.Type {
+		case artifacts.DockerfileArtifactType:
+			imageName := artifacts.ImageName{}
+			if err := a.GetConfig(artifacts.ImageNameConfigType, &imageName); err != nil {
+				logrus.Errorf("unable to load config for Transformer into %T . Error: %q", imageName, err)
+				continue
+			}
+			if imageName.ImageName == "" {
+				imageName.ImageName = common.MakeStringContainerImageNameCompliant(a.Name)
+			}
+			dockerfilePaths := a.Paths[artifacts.DockerfilePathType]
+			if _, ok := dockerfiles[imageName.ImageName]; ok || len(dockerfilePaths) == 0 {
+				continue
+			}
+			contextPath := filepath.Dir(dockerfilePaths[0])
+			if contextPaths := a.Paths[artifacts.DockerfileContextPathType]; len(contextPaths) > 0 {
+				contextPath = contextPaths[0]
+			}
+			context, err := t.getOutputRelPath(contextPath)
+			if err != nil {
+				logrus.Errorf("failed to get the sync path for the image %s . Error: %q", imageName.ImageName, err)
+				continue
+			}
+			dockerfile, err := t.getOutputRelPath(dockerfilePaths[0])
+			if err != nil {
+				logrus.Errorf("failed to get the Dockerfile path for the image %s . Error: %q", imageName.ImageName, err)
+				continue
+			}
+			dockerfiles[imageName.ImageName] = devService{Context: common.GetUnixPath(context), Dockerfile: common.GetUnixPath(dockerfile)}
+		case irtypes.IRArtifactType:
+			var ir irtypes.IR
+			if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+				logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+				continue
+			}
+			irs = append(irs, ir)
+		}
+	}
+	// the same service can be present in the IRs created by multiple transformers
+	devServices := []devService{}
+	seenDevServices := map[string]bool{}
+	for _, ir := range irs {
+		for _, devSvc := range t.getDevServices(ir, dockerfiles) {
+			if !seenDevServices[devSvc.Name] {
+				seenDevServices[devSvc.Name] = true
+				devServices = append(devServices, devSvc)
+			}
+		}
+	}
+	if len(devServices) == 0 {
+		return nil, nil, nil
+	}
+	tool := qaengine.FetchSelectAnswer(
+		common.ConfigDevConfigToolKey,
+		"Select the tool for which the inner loop development configuration should be generated : ",
+		[]string{"The configuration syncs the source code into the running containers for hot reload."},
+		noDevTool,
+		[]string{devSpaceDevTool, oktetoDevTool, noDevTool},
+		nil,
+	)
+	var config interface{}
+	fileName := ""
+	switch tool {
+	case devSpaceDevTool:
+		config, fileName = t.getDevSpaceConfig(devServices), devSpaceFileName
+	case oktetoDevTool:
+		config, fileName = t.getOktetoConfig(devServices), oktetoFileName
+	default:
+		return nil, nil, nil
+	}
+	tempDir := filepath.Join(t.Env.TempPath, "devconfig-"+common.GetRandomString())
+	if err := os.MkdirAll(tempDir, common.DefaultDirectoryPermission); err != nil {
+		return nil, nil, fmt.Errorf("failed to create the directory %s . Error: %w", tempDir, err)
+	}
+	if err := common.WriteYaml(filepath.Join(tempDir, fileName), config); err != nil {
+		return nil, nil, fmt.Errorf("failed to write the %s configuration to the file %s . Error: %w", tool, fileName, err)
+	}
+	return []transformertypes.PathMapping{{
+		Type:     transformertypes.DefaultPathMappingType,
+		SrcPath:  tempDir,
+		DestPath: ".",
+	}}, nil, nil
+}
+
+// getDevServices returns the containers which use the images built from the Dockerfiles
+func (t *DevConfigGenerator) getDevServices(ir irtypes.IR, dockerfiles map[string]devService) []devService {
+	registryURL := commonqa.ImageRegistry()
+	registryNamespace := commonqa.ImageRegistryNamespace()
+	serviceNames := []string{}
+	for serviceName := range ir.Services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	devServices := []devService{}
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		for _, container := range service.Containers {
+			devSvc, ok := dockerfiles[container.Image]
+			if !ok {
+				continue
+			}
+			devSvc.Name = common.MakeStringK8sServiceNameCompliant(service.Name)
+			devSvc.Workload = service.Name
+			if len(service.Containers) > 1 {
+				devSvc.Name = common.MakeStringK8sServiceNameCompliant(service.Name + "-" + container.Name)
+				devSvc.Container = container.Name
+			}
+			devSvc.Image = strings.Join([]string{registryURL, registryNamespace, container.Image}, "/")
+			devSvc.ContainerPath = container.WorkingDir
+			if devSvc.ContainerPath == "" {
+				devSvc.ContainerPath = defaultDevContainerPath
+			}
+			for _, port := range container.Ports {
+				devSvc.Ports = append(devSvc.Ports, port.ContainerPort)
+			}
+			if len(devSvc.Ports) == 0 {
+				devSvc.Ports = ir.ContainerImages[container.Image].ExposedPorts
+			}
+			devServices = append(devServices, devSvc)
+		}
+	}
+	return devServices
+}
+
+// getOutputRelPath returns the path of the source file relative to the output directory
+func (t *DevConfigGenerator) getOutputRelPath(path string) (string, error) {
+	if common.IsParent(path, t.Env.GetEnvironmentSource()) {
+		relPath, err := filepath.Rel(t.Env.GetEnvironmentSource(), path)
+		if err != nil {
+			return "", fmt.Errorf("failed to make the path %s relative to the source directory %s . Error: %w", path, t.Env.GetEnvironmentSource(), err)
+		}
+		return filepath.Join(common.DefaultSourceDir, relPath), nil
+	}
+	if common.IsParent(path, t.Env.GetEnvironmentOutput()) {
+		relPath, err := filepath.Rel(t.Env.GetEnvironmentOutput(), path)
+		if err != nil {
+			return "", fmt.Errorf("failed to make the path %s relative to the output directory %s . Error: %w", path, t.Env.GetEnvironmentOutput(), err)
+		}
+		return relPath, nil
+	}
+	return "", fmt.Errorf("the path %s is neither in the source directory nor in the output directory", path)
+}
+
+func (t *DevConfigGenerator) getDevSpaceConfig(devServices []devService) devSpaceConfig {
+	config := devSpaceConfig{
+		Version: "v1beta11",
+		Images:  map[string]devSpaceImage{},
+		Deployments: []devSpaceDeployment{{
+			Name:    common.MakeStringK8sServiceNameCompliant(t.Env.ProjectName),
+			Kubectl: devSpaceKubectl{Manifests: []string{common.GetUnixPath(t.DevConfigGeneratorConfig.DeployPath)}},
+		}},
+	}
+	for _, devSvc := range devServices {
+		config.Images[devSvc.Name] = devSpaceImage{Image: devSvc.Image, Dockerfile: devSvc.Dockerfile, Context: devSvc.Context}
+		if len(devSvc.Ports) > 0 {
+			port := devSpacePort{ImageSelector: devSvc.Image}
+			for _, p := range devSvc.Ports {
+				port.Forward = append(port.Forward, devSpacePortForward{Port: p})
+			}
+			config.Dev.Ports = append(config.Dev.Ports, port)
+		}
+		config.Dev.Sync = append(config.Dev.Sync, devSpaceSync{
+			ImageSelector: devSvc.Image,
+			LocalSubPath:  devSvc.Context,
+			ContainerPath: devSvc.ContainerPath,
+			ExcludePaths:  []string{".git/"},
+			OnUpload:      devSpaceSyncUpload{RestartContainer: true},
+		})
+	}
+	return config
+}
+
+func (t *DevConfigGenerator) getOktetoConfig(devServices []devService) oktetoConfig {
+	config := oktetoConfig{
+		Name:   common.MakeStringK8sServiceNameCompliant(t.Env.ProjectName),
+		Build:  map[string]oktetoBuild{},
+		Deploy: []string{"kubectl apply -f " + common.GetUnixPath(t.DevConfigGeneratorConfig.DeployPath)},
+		Dev:    map[string]oktetoDev{},
+	}
+	for _, devSvc := range devServices {
+		config.Build[devSvc.Name] = oktetoBuild{Context: devSvc.Context, Dockerfile: devSvc.Dockerfile}
+		// the development containers are named after the deployments they replace
+		if _, ok := config.Dev[devSvc.Workload]; ok {
+			logrus.Warnf("Okteto can develop only one container of the deployment %s . Skipping the container %s", devSvc.Workload, devSvc.Container)
+			continue
+		}
+		dev := oktetoDev{
+			Container: devSvc.Container,
+			Image:     "${OKTETO_BUILD_" + strings.ToUpper(strings.ReplaceAll(devSvc.Name, "-", "_")) + "_IMAGE}",
+			Command:   []string{"sh"},
+			Sync:      []string{devSvc.Context + ":" + devSvc.ContainerPath},
+		}
+		for _, p := range devSvc.Ports {
+			dev.Forward = append(dev.Forward, fmt.Sprintf("%d:%d", p, p))
+		}
+		config.Dev[devSvc.Workload] = dev
+	}
+	return config
+}
@@ -0,0 +1,71 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestGetOktetoConfig(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	ir := irtypes.NewIR()
+	api := irtypes.NewServiceWithName("api")
+	api.Containers = []core.Container{{Name: "api", Image: "api:latest", WorkingDir: "/srv", Ports: []core.ContainerPort{{ContainerPort: 8080}}}}
+	ir.Services["api"] = api
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{
+		{Name: "frontend", Image: "frontend:latest"},
+		{Name: "proxy", Image: "nginx:latest"},
+		{Name: "assets", Image: "assets:latest"},
+	}
+	ir.Services["web"] = web
+	dockerfiles := map[string]devService{
+		"api:latest":      {Context: "source/api", Dockerfile: "source/api/Dockerfile"},
+		"frontend:latest": {Context: "source/frontend", Dockerfile: "source/frontend/Dockerfile"},
+		"assets:latest":   {Context: "source/assets", Dockerfile: "source/assets/Dockerfile"},
+	}
+	d := &DevConfigGenerator{
+		Env:                      &environment.Environment{EnvInfo: environment.EnvInfo{ProjectName: "myproject"}},
+		DevConfigGeneratorConfig: &DevConfigGeneratorYamlConfig{DeployPath: "deploy/yamls"},
+	}
+	got := d.getOktetoConfig(d.getDevServices(ir, dockerfiles))
+	want := oktetoConfig{
+		Name: "myproject",
+		Build: map[string]oktetoBuild{
+			"api":          {Context: "source/api", Dockerfile: "source/api/Dockerfile"},
+			"web-frontend": {Context: "source/frontend", Dockerfile: "source/frontend/Dockerfile"},
+			"web-assets":   {Context: "source/assets", Dockerfile: "source/assets/Dockerfile"},
+		},
+		Deploy: []string{"kubectl apply -f deploy/yamls"},
+		Dev: map[string]oktetoDev{
+			"api": {Image: "${OKTETO_BUILD_API_IMAGE}", Command: []string{"sh"}, Sync: []string{"source/api:/srv"}, Forward: []string{"8080:8080"}},
+			// the deployment is named after the service, the first container with a Dockerfile is developed
+			"web": {Container: "frontend", Image: "${OKTETO_BUILD_WEB_FRONTEND_IMAGE}", Command: []string{"sh"}, Sync: []string{"source/frontend:/app"}},
+		},
+	}
+	if !cmp.Equal(got, want) {
+		t.Fatalf("the okteto configuration is different. Difference:\n%s", cmp.Diff(want, got))
+	}
+}
@@ -19,6 +19,9 @@ package dockerfile
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
@@ -48,18 +51,75 @@ type DockerfileImageBuildScriptConfig struct {
 
 // DockerfileImageBuildScriptTemplateConfig represents the data used to fill the build script generator template
 type DockerfileImageBuildScriptTemplateConfig struct {
-	RelParentOfSourceDir string
-	DockerfilesConfig    []DockerfileImageBuildConfig
-	RegistryURL          string
-	RegistryNamespace    string
+	RelParentOfSourceDir        string
+	RelParentOfSourceDirUnix    string
+	RelParentOfSourceDirWindows string
+	DockerfilesConfig           []DockerfileImageBuildConfig
+	RegistryURL                 string
+	RegistryNamespace           string
+	SignImages                  bool
+	SigningKeyRef               string
+	commonqa.RegistryScriptCommands
+	commonqa.ScriptHookSnippets
 }
 
 // DockerfileImageBuildConfig contains the Dockerfile image build config to be used in the ImageBuild script
 type DockerfileImageBuildConfig struct {
-	DockerfileName string
-	ImageName      string
-	ContextUnix    string
-	ContextWindows string
+	DockerfileName        string
+	DockerfileNameUnix    string
+	DockerfileNameWindows string
+	ImageName             string
+	ContextUnix           string
+	ContextWindows        string
+	BuildArgs             []string
+	Target                string
+}
+
+// ImageBuildDependency stores the images which are built before an image, since it is built from them
+type ImageBuildDependency struct {
+	Image      string   `yaml:"image" json:"image"`
+	BuiltAfter []string `yaml:"builtAfter" json:"builtAfter"`
+}
+
+var (
+	// imageBuildDependencies are in the order the images are built in
+	imageBuildDependencies      = []ImageBuildDependency{}
+	imageBuildDependenciesMutex sync.Mutex
+)
+
+// ResetImageBuildDependencies clears the build order of the images of a previous transformation
+func ResetImageBuildDependencies() {
+	imageBuildDependenciesMutex.Lock()
+	defer imageBuildDependenciesMutex.Unlock()
+	imageBuildDependencies = []ImageBuildDependency{}
+}
+
+// GetImageBuildDependencies returns the images in the order they are built in, along with the images each of them is built after
+func GetImageBuildDependencies() []ImageBuildDependency {
+	imageBuildDependenciesMutex.Lock()
+	defer imageBuildDependenciesMutex.Unlock()
+	return append([]ImageBuildDependency{}, imageBuildDependencies...)
+}
+
+// sortByBuildOrder sorts the images so that the base images are built before the images built from them
+func sortByBuildOrder(dockerfilesImageBuildConfig []DockerfileImageBuildConfig, baseImages map[string][]string) []DockerfileImageBuildConfig {
+	order, dependencies := common.GetImageBuildOrder(baseImages)
+	position := map[string]int{}
+	recordedDependencies := []ImageBuildDependency{}
+	for i, imageName := range order {
+		position[imageName] = i
+		recordedDependencies = append(recordedDependencies, ImageBuildDependency{Image: imageName, BuiltAfter: append([]string{}, dependencies[imageName]...)})
+		if len(dependencies[imageName]) != 0 {
+			logrus.Infof("The image %s is built after its base images %s", imageName, strings.Join(dependencies[imageName], ", "))
+		}
+	}
+	sort.SliceStable(dockerfilesImageBuildConfig, func(i, j int) bool {
+		return position[dockerfilesImageBuildConfig[i].ImageName] < position[dockerfilesImageBuildConfig[j].ImageName]
+	})
+	imageBuildDependenciesMutex.Lock()
+	defer imageBuildDependenciesMutex.Unlock()
+	imageBuildDependencies = recordedDependencies
+	return dockerfilesImageBuildConfig
 }
 
 // Init Initializes the transformer
@@ -93,6 +153,7 @@ func (t *DockerfileImageBuildScript) Transform(newArtifacts []transformertypes.A
 	dockerfilesImageBuildConfig := []DockerfileImageBuildConfig{}
 	createdArtifacts := []transformertypes.Artifact{}
 	processedImages := map[string]bool{}
+	baseImages := map[string][]string{}
 	for _, artifact := range append(alreadySeenArtifacts, newArtifacts...) {
 		if artifact.Type != artifacts.DockerfileArtifactType {
 			continue
@@ -111,7 +172,19 @@ func (t *DockerfileImageBuildScript) Transform(newArtifacts []transformertypes.A
 		processedImages[imageName.ImageName] = true
 		var dockerfileImageBuildConfig DockerfileImageBuildConfig
 		dockerfileImageBuildConfig.ImageName = imageName.ImageName
+		dockerfileBuild := artifacts.DockerfileBuild{}
+		if err := artifact.GetConfig(artifacts.DockerfileBuildConfigType, &dockerfileBuild); err != nil {
+			logrus.Debugf("unable to load config for Transformer into %T . Error: %q", dockerfileBuild, err)
+		}
 		for _, dockerfilePath := range artifact.Paths[artifacts.DockerfilePathType] {
+			declaredBuildArgs, stages := common.GetDockerfileArgsAndStages(dockerfilePath)
+			baseImages[imageName.ImageName] = append(baseImages[imageName.ImageName], common.GetDockerfileBaseImages(dockerfilePath)...)
+			dockerfileImageBuildConfig.BuildArgs = []string{}
+			for buildArg, value := range commonqa.ImageBuildArgs(imageName.ImageName, declaredBuildArgs, dockerfileBuild.BuildArgs) {
+				dockerfileImageBuildConfig.BuildArgs = append(dockerfileImageBuildConfig.BuildArgs, buildArg+"="+value)
+			}
+			sort.Strings(dockerfileImageBuildConfig.BuildArgs)
+			dockerfileImageBuildConfig.Target = commonqa.ImageBuildTarget(imageName.ImageName, stages, dockerfileBuild.Target)
 			dockerContextPath := filepath.Dir(dockerfilePath)
 			relDockerfilePath := filepath.Base(dockerfilePath)
 			if len(artifact.Paths[artifacts.DockerfileContextPathType]) > 0 {
@@ -124,6 +197,8 @@ func (t *DockerfileImageBuildScript) Transform(newArtifacts []transformertypes.A
 				}
 			}
 			dockerfileImageBuildConfig.DockerfileName = relDockerfilePath
+			dockerfileImageBuildConfig.DockerfileNameUnix = common.GetUnixPath(relDockerfilePath)
+			dockerfileImageBuildConfig.DockerfileNameWindows = common.GetWindowsPath(relDockerfilePath)
 			if common.IsParent(dockerfilePath, t.Env.GetEnvironmentSource()) {
 				relDockerContextPath, err := filepath.Rel(t.Env.GetEnvironmentSource(), filepath.Dir(dockerfilePath))
 				if err != nil {
@@ -161,6 +236,7 @@ func (t *DockerfileImageBuildScript) Transform(newArtifacts []transformertypes.A
 	if len(dockerfilesImageBuildConfig) == 0 {
 		return nil, nil, nil
 	}
+	dockerfilesImageBuildConfig = sortByBuildOrder(dockerfilesImageBuildConfig, baseImages)
 	relSourceDir, err := filepath.Rel(t.DockerfileImageBuildScriptConfig.OutputPath, common.DefaultSourceDir)
 	if err != nil {
 		return nil, nil, fmt.Errorf(
@@ -173,11 +249,20 @@ func (t *DockerfileImageBuildScript) Transform(newArtifacts []transformertypes.A
 	containerImageBuildShScriptPaths := []string{}
 	containerImageBuildBatScriptPaths := []string{}
 	templateData := DockerfileImageBuildScriptTemplateConfig{
-		RelParentOfSourceDir: filepath.Join(relSourceDir, ".."),
-		RegistryURL:          commonqa.ImageRegistry(),
-		RegistryNamespace:    commonqa.ImageRegistryNamespace(),
-		DockerfilesConfig:    dockerfilesImageBuildConfig,
+		RelParentOfSourceDir:        filepath.Join(relSourceDir, ".."),
+		RelParentOfSourceDirUnix:    common.GetUnixPath(filepath.Join(relSourceDir, "..")),
+		RelParentOfSourceDirWindows: common.GetWindowsPath(filepath.Join(relSourceDir, "..")),
+		RegistryURL:                 commonqa.ImageRegistry(),
+		RegistryNamespace:           commonqa.ImageRegistryNamespace(),
+		DockerfilesConfig:           dockerfilesImageBuildConfig,
+	}
+	templateData.ScriptHookSnippets = commonqa.GetScriptHooks(buildImagesFileName)
+	templateData.SignImages, templateData.SigningKeyRef = commonqa.ImageSigning()
+	imageNames := []string{}
+	for _, dockerfileConfig := range dockerfilesImageBuildConfig {
+		imageNames = append(imageNames, dockerfileConfig.ImageName)
 	}
+	templateData.RegistryScriptCommands = commonqa.GetRegistryScriptCommands(templateData.RegistryURL, imageNames, "docker", "docker")
 	pathMappings = append(pathMappings, transformertypes.PathMapping{
 		Type:           transformertypes.TemplatePathMappingType,
 		SrcPath:        filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir),
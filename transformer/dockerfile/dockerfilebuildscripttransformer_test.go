@@ -0,0 +1,49 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSortByBuildOrder(t *testing.T) {
+	ResetImageBuildDependencies()
+	t.Cleanup(ResetImageBuildDependencies)
+	configs := []DockerfileImageBuildConfig{
+		{ImageName: "api", DockerfileName: "Dockerfile"},
+		{ImageName: "base", DockerfileName: "Dockerfile.base"},
+		{ImageName: "web", DockerfileName: "Dockerfile"},
+	}
+	baseImages := map[string][]string{"api": {"base:latest"}, "base": {"alpine"}, "web": {"node:18"}}
+	got := []string{}
+	for _, config := range sortByBuildOrder(configs, baseImages) {
+		got = append(got, config.ImageName)
+	}
+	if want := []string{"base", "api", "web"}; !cmp.Equal(got, want) {
+		t.Fatalf("unexpected build order. Differences:\n%s", cmp.Diff(want, got))
+	}
+	want := []ImageBuildDependency{
+		{Image: "base", BuiltAfter: []string{}},
+		{Image: "api", BuiltAfter: []string{"base"}},
+		{Image: "web", BuiltAfter: []string{}},
+	}
+	if diff := cmp.Diff(want, GetImageBuildDependencies()); diff != "" {
+		t.Fatalf("unexpected recorded build order. Diff (-want +got):\n%s", diff)
+	}
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
 	"github.com/konveyor/move2kube/types/transformer/artifacts"
 	dockerparser "github.com/moby/buildkit/frontend/dockerfile/parser"
@@ -120,6 +121,19 @@ func (t *DockerfileDetector) Transform(newArtifacts []transformertypes.Artifact,
 				artifacts.ImageNameConfigType: sImageName,
 			},
 		}
+		lint := artifacts.DockerfileLint{Findings: []artifacts.DockerfileLintFinding{}}
+		for _, dockerfilePath := range newArtifact.Paths[artifacts.DockerfilePathType] {
+			findings, lintPathMappings, err := t.lintDockerfile(dockerfilePath)
+			if err != nil {
+				logrus.Errorf("failed to lint the Dockerfile at path %s . Error: %q", dockerfilePath, err)
+				continue
+			}
+			lint.Findings = append(lint.Findings, findings...)
+			pathMappings = append(pathMappings, lintPathMappings...)
+		}
+		if len(lint.Findings) != 0 {
+			p.Configs[artifacts.DockerfileLintConfigType] = lint
+		}
 		dfs := transformertypes.Artifact{
 			Name:  sConfig.ServiceName,
 			Type:  artifacts.DockerfileForServiceArtifactType,
@@ -134,6 +148,61 @@ func (t *DockerfileDetector) Transform(newArtifacts []transformertypes.Artifact,
 	return pathMappings, artifactsCreated, nil
 }
 
+// lintDockerfile finds the issues in a Dockerfile present in the source directory.
+// If the user chooses to fix them, the fixed Dockerfile replaces the copy in the output source directory.
+func (t *DockerfileDetector) lintDockerfile(dockerfilePath string) ([]artifacts.DockerfileLintFinding, []transformertypes.PathMapping, error) {
+	if !common.IsParent(dockerfilePath, t.Env.GetEnvironmentSource()) {
+		return nil, nil, nil
+	}
+	relDockerfilePath, err := filepath.Rel(t.Env.GetEnvironmentSource(), dockerfilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to make the path %s relative to the source directory %s . Error: %w", dockerfilePath, t.Env.GetEnvironmentSource(), err)
+	}
+	outputRelPath := filepath.Join(common.DefaultSourceDir, relDockerfilePath)
+	contents, err := os.ReadFile(dockerfilePath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read the Dockerfile. Error: %w", err)
+	}
+	issues, err := lintDockerfile(contents)
+	if err != nil {
+		return nil, nil, err
+	}
+	pathMappings := []transformertypes.PathMapping{}
+	fixable := false
+	for _, issue := range issues {
+		fixable = fixable || issue.fix != nil
+	}
+	if fixable && qaengine.FetchBoolAnswer(
+		common.ConfigDockerfileLintFixKey,
+		"Do you want to fix the issues found in the Dockerfiles present in the source directory?",
+		[]string{"The fixes add a non root USER to the final stage and remove the apt package lists after installing packages. The findings are listed in the transformation report."},
+		false,
+		nil,
+	) {
+		fixedPath := filepath.Join(t.Env.TempPath, "dockerfilelint-"+common.GetRandomString(), filepath.Base(dockerfilePath))
+		if err := os.MkdirAll(filepath.Dir(fixedPath), common.DefaultDirectoryPermission); err != nil {
+			return nil, nil, fmt.Errorf("failed to create the directory %s . Error: %w", filepath.Dir(fixedPath), err)
+		}
+		if err := os.WriteFile(fixedPath, fixDockerfile(contents, issues), common.DefaultFilePermission); err != nil {
+			return nil, nil, fmt.Errorf("failed to write the fixed Dockerfile to %s . Error: %w", fixedPath, err)
+		}
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:     transformertypes.DefaultPathMappingType,
+			SrcPath:  fixedPath,
+			DestPath: outputRelPath,
+		})
+	}
+	findings := []artifacts.DockerfileLintFinding{}
+	for _, issue := range issues {
+		issue.finding.File = filepath.ToSlash(outputRelPath)
+		findings = append(findings, issue.finding)
+	}
+	if len(findings) != 0 {
+		logrus.Infof("Found %d issues in the Dockerfile %s . They are listed in the transformation report.", len(findings), relDockerfilePath)
+	}
+	return findings, pathMappings, nil
+}
+
 func isDockerFile(path string) (isDockerfile bool, err error) {
 	f, err := os.Open(path)
 	if err != nil {
@@ -0,0 +1,157 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package dockerfile
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	dockerparser "github.com/moby/buildkit/frontend/dockerfile/parser"
+)
+
+const (
+	unpinnedBaseImageLintRule = "unpinned-base-image"
+	aptCacheLintRule          = "apt-cache"
+	missingUserLintRule       = "missing-user"
+	rootUserLintRule          = "root-user"
+	// nonRootUser is the user added to the Dockerfiles which run as root
+	nonRootUser         = "1001"
+	aptCacheCleanupCmd  = "rm -rf /var/lib/apt/lists/*"
+	aptListsDir         = "/var/lib/apt/lists"
+	dockerfileLineBreak = "\n"
+)
+
+var aptInstallRegex = regexp.MustCompile(`\bapt(-get)?\s+([^&;|]*\s+)?install\b`)
+
+// dockerfileLintIssue is an issue found in a Dockerfile along with the fix for it
+type dockerfileLintIssue struct {
+	finding artifacts.DockerfileLintFinding
+	// fix changes the lines of the Dockerfile to fix the issue. It is nil if the issue cannot be fixed automatically.
+	fix func(lines []string) []string
+}
+
+// lintDockerfile checks the Dockerfile for common issues like the ones reported by hadolint
+func lintDockerfile(contents []byte) ([]dockerfileLintIssue, error) {
+	res, err := dockerparser.Parse(bytes.NewReader(contents))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the Dockerfile. Error: %w", err)
+	}
+	issues := []dockerfileLintIssue{}
+	stageNames := map[string]bool{}
+	user, userLine, lastLine := "", 0, 0
+	for _, child := range res.AST.Children {
+		lastLine = child.EndLine
+		switch strings.ToLower(child.Value) {
+		case "from":
+			user, userLine = "", 0
+			if child.Next == nil {
+				continue
+			}
+			image := child.Next.Value
+			if !stageNames[strings.ToLower(image)] && isUnpinnedImage(image) {
+				issues = append(issues, dockerfileLintIssue{finding: artifacts.DockerfileLintFinding{
+					Line:    child.StartLine,
+					Rule:    unpinnedBaseImageLintRule,
+					Message: fmt.Sprintf("The base image %s is not pinned to a specific tag or digest.", image),
+				}})
+			}
+			if as := child.Next.Next; as != nil && strings.EqualFold(as.Value, "as") && as.Next != nil {
+				stageNames[strings.ToLower(as.Next.Value)] = true
+			}
+		case "user":
+			if child.Next != nil {
+				user, userLine = child.Next.Value, child.StartLine
+			}
+		case "run":
+			if child.Attributes["json"] || !aptInstallRegex.MatchString(child.Original) || strings.Contains(child.Original, aptListsDir) {
+				continue
+			}
+			endLine := child.EndLine
+			issues = append(issues, dockerfileLintIssue{
+				finding: artifacts.DockerfileLintFinding{
+					Line:    child.StartLine,
+					Rule:    aptCacheLintRule,
+					Message: fmt.Sprintf("The apt package lists are left behind in the image. Run '%s' after installing the packages.", aptCacheCleanupCmd),
+				},
+				fix: func(lines []string) []string {
+					if endLine < 1 || endLine > len(lines) {
+						return lines
+					}
+					lines[endLine-1] = strings.TrimRight(lines[endLine-1], " \t\r") + " && " + aptCacheCleanupCmd
+					return lines
+				},
+			})
+		}
+	}
+	addUser := func(lines []string) []string {
+		if len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+			return append(lines[:len(lines)-1], "USER "+nonRootUser, lines[len(lines)-1])
+		}
+		return append(lines, "USER "+nonRootUser)
+	}
+	if user == "" {
+		issues = append(issues, dockerfileLintIssue{
+			finding: artifacts.DockerfileLintFinding{
+				Line:    lastLine,
+				Rule:    missingUserLintRule,
+				Message: "The final stage does not set a USER, so the container may run as root.",
+			},
+			fix: addUser,
+		})
+	} else if isRootUser(user) {
+		issues = append(issues, dockerfileLintIssue{
+			finding: artifacts.DockerfileLintFinding{
+				Line:    userLine,
+				Rule:    rootUserLintRule,
+				Message: fmt.Sprintf("The final stage runs as the root user %s .", user),
+			},
+			fix: addUser,
+		})
+	}
+	return issues, nil
+}
+
+// fixDockerfile applies the fixes of the issues to the Dockerfile and marks the fixed issues
+func fixDockerfile(contents []byte, issues []dockerfileLintIssue) []byte {
+	lines := strings.Split(string(contents), dockerfileLineBreak)
+	for i, issue := range issues {
+		if issue.fix == nil {
+			continue
+		}
+		lines = issue.fix(lines)
+		issues[i].finding.Fixed = true
+	}
+	return []byte(strings.Join(lines, dockerfileLineBreak))
+}
+
+// isUnpinnedImage returns true if the image does not have a tag or digest, or uses the latest tag
+func isUnpinnedImage(image string) bool {
+	if strings.Contains(image, "$") || strings.EqualFold(image, "scratch") || strings.Contains(image, "@") {
+		return false
+	}
+	name := image[strings.LastIndex(image, "/")+1:]
+	idx := strings.LastIndex(name, ":")
+	return idx == -1 || name[idx+1:] == "latest"
+}
+
+func isRootUser(user string) bool {
+	user = strings.SplitN(user, ":", 2)[0]
+	return user == "root" || user == "0"
+}
@@ -0,0 +1,62 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package dockerfile
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func getLintRules(issues []dockerfileLintIssue) []string {
+	rules := []string{}
+	for _, issue := range issues {
+		rules = append(rules, issue.finding.Rule)
+	}
+	return rules
+}
+
+func TestLintDockerfile(t *testing.T) {
+	t.Run("Dockerfile without issues", func(t *testing.T) {
+		contents := "FROM golang:1.18 AS builder\nRUN go build -o /app .\nFROM builder\nUSER 1001\nCMD [\"/app\"]\n"
+		issues, err := lintDockerfile([]byte(contents))
+		if err != nil {
+			t.Fatalf("failed to lint the Dockerfile. Error: %q", err)
+		}
+		if len(issues) != 0 {
+			t.Fatalf("expected no issues. Actual: %+v", getLintRules(issues))
+		}
+	})
+	t.Run("Dockerfile with issues", func(t *testing.T) {
+		contents := "FROM ubuntu:latest\nRUN apt-get update && \\\n    apt-get install -y curl\nUSER root\nCMD [\"curl\"]\n"
+		issues, err := lintDockerfile([]byte(contents))
+		if err != nil {
+			t.Fatalf("failed to lint the Dockerfile. Error: %q", err)
+		}
+		wantRules := []string{unpinnedBaseImageLintRule, aptCacheLintRule, rootUserLintRule}
+		if !cmp.Equal(getLintRules(issues), wantRules) {
+			t.Fatalf("the issues did not match. Differences:\n%s", cmp.Diff(wantRules, getLintRules(issues)))
+		}
+		want := "FROM ubuntu:latest\nRUN apt-get update && \\\n    apt-get install -y curl && rm -rf /var/lib/apt/lists/*\nUSER root\nCMD [\"curl\"]\nUSER 1001\n"
+		if actual := string(fixDockerfile([]byte(contents), issues)); actual != want {
+			t.Fatalf("the fixed Dockerfile did not match. Differences:\n%s", cmp.Diff(want, actual))
+		}
+		if issues[0].finding.Fixed || !issues[1].finding.Fixed || !issues[2].finding.Fixed {
+			t.Fatalf("only the apt cache and root user issues should be fixed. Actual: %+v", issues)
+		}
+	})
+}
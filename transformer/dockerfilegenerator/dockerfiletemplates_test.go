@@ -0,0 +1,123 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package dockerfilegenerator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+)
+
+// renderDockerfileTemplate renders the Dockerfile template of the built-in transformer
+func renderDockerfileTemplate(t *testing.T, templatePath string, config interface{}) string {
+	t.Helper()
+	templatePath = filepath.Join(append([]string{"..", "..", "assets", "built-in", "transformers", "dockerfilegenerator"}, strings.Split(templatePath, "/")...)...)
+	tpl, err := os.ReadFile(templatePath)
+	if err != nil {
+		t.Fatalf("failed to read the template %s . Error: %q", templatePath, err)
+	}
+	dockerfile, err := common.GetStringFromTemplate(string(tpl), config)
+	if err != nil {
+		t.Fatalf("failed to render the template %s . Error: %q", templatePath, err)
+	}
+	return dockerfile
+}
+
+// getDockerfileStages returns the base images of the stages of the Dockerfile
+func getDockerfileStages(dockerfile string) []string {
+	stages := []string{}
+	for _, line := range strings.Split(dockerfile, "\n") {
+		if fields := strings.Fields(line); len(fields) > 1 && fields[0] == "FROM" {
+			stages = append(stages, fields[1])
+		}
+	}
+	return stages
+}
+
+func TestNodejsDockerfileIsMultiStage(t *testing.T) {
+	testCases := []struct {
+		packageManager string
+		cacheMount     string
+	}{
+		{packageManager: "npm", cacheMount: "RUN --mount=type=cache,target=/opt/app-root/src/.npm,uid=1001,gid=0 npm install\n"},
+		{packageManager: "yarn", cacheMount: "RUN --mount=type=cache,target=/opt/app-root/src/.cache/yarn,uid=1001,gid=0 yarn install\n"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.packageManager, func(t *testing.T) {
+			dockerfile := renderDockerfileTemplate(t, "nodejs/templates/Dockerfile", NodejsTemplateConfig{
+				Port:             8080,
+				Build:            true,
+				NodeMajorVersion: "16",
+				RunImage:         "registry.access.redhat.com/ubi8/nodejs-16-minimal",
+				PackageManager:   testCase.packageManager,
+			})
+			want := []string{"registry.access.redhat.com/ubi8/nodejs-16", "registry.access.redhat.com/ubi8/nodejs-16-minimal"}
+			if stages := getDockerfileStages(dockerfile); strings.Join(stages, " ") != strings.Join(want, " ") {
+				t.Fatalf("got the stages %v , want %v", stages, want)
+			}
+			for _, line := range []string{testCase.cacheMount, "RUN " + testCase.packageManager + " run build\n", "COPY --chown=1001:0 --from=builder /opt/app-root/src /opt/app-root/src\n"} {
+				if !strings.Contains(dockerfile, line) {
+					t.Fatalf("expected the line %q in the Dockerfile:\n%s", line, dockerfile)
+				}
+			}
+		})
+	}
+}
+
+func TestGetNodejsRunImage(t *testing.T) {
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	for nodeVersion, want := range map[string]string{
+		"v12.22.1": "registry.access.redhat.com/ubi8/nodejs-12",
+		"v14.0.0":  "registry.access.redhat.com/ubi8/nodejs-14-minimal",
+		"v16.13.0": "registry.access.redhat.com/ubi8/nodejs-16-minimal",
+	} {
+		if got := getNodejsRunImage(nodeVersion); got != want {
+			t.Errorf("getNodejsRunImage(%q) = %q, want %q", nodeVersion, got, want)
+		}
+	}
+}
+
+func TestDockerfilesUseCacheMounts(t *testing.T) {
+	testCases := []struct {
+		templatePath string
+		config       map[string]interface{}
+		cacheMount   string
+		stages       int
+	}{
+		{templatePath: "ruby/templates/Dockerfile", config: map[string]interface{}{"AppName": "app", "Port": 8080, "RunImage": "ruby:2.5-slim"}, cacheMount: "RUN --mount=type=cache,target=/usr/local/bundle/cache bundle install\n", stages: 2},
+		{templatePath: "python/templates/Dockerfile", config: map[string]interface{}{"AppName": "app", "Port": 8080, "RequirementsTxt": "requirements.txt"}, cacheMount: "RUN --mount=type=cache,target=/opt/app-root/src/.cache/pip,uid=1001,gid=0 pip install -r requirements.txt\n", stages: 1},
+		{templatePath: "rust/templates/Dockerfile", config: map[string]interface{}{"AppName": "app", "Port": 8080}, cacheMount: "RUN --mount=type=cache,target=/usr/local/cargo/registry \\\n    cargo build --release\n", stages: 2},
+		{templatePath: "golang/templates/Dockerfile", config: map[string]interface{}{"AppName": "app", "Ports": []int32{8080}, "GolangImageTag": "1.18"}, cacheMount: "--mount=type=cache,target=/opt/app-root/src/go/pkg/mod,uid=1001,gid=0", stages: 2},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.templatePath, func(t *testing.T) {
+			dockerfile := renderDockerfileTemplate(t, testCase.templatePath, testCase.config)
+			if !strings.Contains(dockerfile, testCase.cacheMount) {
+				t.Fatalf("expected the cache mount %q in the Dockerfile:\n%s", testCase.cacheMount, dockerfile)
+			}
+			if stages := getDockerfileStages(dockerfile); len(stages) != testCase.stages {
+				t.Fatalf("got the stages %v , want %d stages", stages, testCase.stages)
+			}
+		})
+	}
+}
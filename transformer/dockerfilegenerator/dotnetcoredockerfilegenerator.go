@@ -58,6 +58,7 @@ type DotNetCoreTemplateConfig struct {
 	PublishProfilePath    string
 	IncludeRunStage       bool
 	RunStageImageTag      string
+	RunImage              string
 	Ports                 []int32
 	EntryPointPath        string
 	CopyFrom              string
@@ -476,6 +477,7 @@ func (t *DotNetCoreDockerfileGenerator) TransformArtifact(newArtifact transforme
 			BuildContainerName:    imageToCopyFrom,
 			EntryPointPath:        childProject.OriginalName + ".dll",
 			RunStageImageTag:      targetFrameworkVersion,
+			RunImage:              getDotNetCoreRunImage(targetFrameworkVersion),
 			IncludeRunStage:       true,
 			CopyFrom:              common.GetUnixPath(copyFrom),
 			PublishProfilePath:    common.GetUnixPath(relSelectedProfilePath),
@@ -701,3 +703,10 @@ func modifyUrlsToListenOnAllAddresses(src string) (string, []int32, error) {
 	}
 	return strings.Join(newUrls, ";"), ports, nil
 }
+
+// getDotNetCoreRunImage returns the image used to run the .NET app.
+// Microsoft publishes Alpine variants of the ASP.NET runtime images.
+func getDotNetCoreRunImage(frameworkVersion string) string {
+	defaultRunImage := "mcr.microsoft.com/dotnet/aspnet:" + frameworkVersion
+	return commonqa.BaseImage(".NET", map[string]string{commonqa.AlpineBaseImageFamily: defaultRunImage + "-alpine"}, defaultRunImage)
+}
@@ -69,6 +69,17 @@ type GolangTemplateConfig struct {
 	Ports          []int32
 	AppName        string
 	GolangImageTag string
+	RunImage       string
+	StaticBinary   bool
+}
+
+const golangUBIRunImage = "registry.access.redhat.com/ubi8/ubi-minimal:8.3-201"
+
+// golangRunImages are the images used to run the Go apps for each base image family
+var golangRunImages = map[string]string{
+	commonqa.UBIBaseImageFamily:        golangUBIRunImage,
+	commonqa.AlpineBaseImageFamily:     "alpine:3.16",
+	commonqa.DistrolessBaseImageFamily: "gcr.io/distroless/static-debian11",
 }
 
 // GolangDockerfileYamlConfig represents the configuration of the Golang dockerfile
@@ -204,10 +215,14 @@ func (t *GolangDockerfileGenerator) Transform(newArtifacts []transformertypes.Ar
 			detectedPorts = append(detectedPorts, common.DefaultServicePort)
 		}
 		detectedPorts = commonqa.GetPortsForService(detectedPorts, `"`+a.Name+`"`)
+		runImage := commonqa.BaseImage("Go", golangRunImages, golangUBIRunImage)
 		golangConfig := GolangTemplateConfig{
 			AppName:        a.Name,
 			Ports:          detectedPorts,
 			GolangImageTag: golangImageTag,
+			RunImage:       runImage,
+			// the images without glibc need a statically linked binary
+			StaticBinary: runImage != golangUBIRunImage,
 		}
 
 		pathMappings = append(pathMappings, transformertypes.PathMapping{
@@ -52,6 +52,8 @@ type JarDockerfileTemplate struct {
 	DeploymentFilePath string
 	DeploymentFilename string
 	EnvVariables       map[string]string
+	RunImage           string
+	InstallJava        bool
 }
 
 // Init Initializes the transformer
@@ -181,13 +183,16 @@ func (t *JarAnalyser) Transform(newArtifacts []transformertypes.Artifact, alread
 		if buildContainerName == "" {
 			buildContainerName = common.DefaultBuildContainerName
 		}
+		runImage := getJavaRunImage(jarArtifactConfig.JavaVersion)
 		pathMappingTemplateConfig := JarDockerfileTemplate{
 			Port:               jarArtifactConfig.Port,
 			JavaPackageName:    javaPackage,
 			BuildContainerName: buildContainerName,
 			DeploymentFilePath: jarArtifactConfig.DeploymentFilePath,
 			DeploymentFilename: filepath.Base(jarArtifactConfig.DeploymentFilePath),
-			EnvVariables:       jarArtifactConfig.EnvVariables,
+			EnvVariables:       getJVMEnvVariables(serviceConfig.ServiceName, jarArtifactConfig.EnvVariables),
+			RunImage:           runImage,
+			InstallJava:        runImage == javaUBIRunImage,
 		}
 		// Fill the Dockerfile template using a pathmapping.
 		writeDockerfilePathMapping := transformertypes.PathMapping{
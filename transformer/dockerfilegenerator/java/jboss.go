@@ -52,6 +52,7 @@ type JbossDockerfileTemplate struct {
 	BuildContainerName string
 	Port               int32
 	EnvVariables       map[string]string
+	RunImage           string
 }
 
 // Init Initializes the transformer
@@ -124,7 +125,7 @@ func (t *Jboss) Transform(newArtifacts []transformertypes.Artifact, alreadySeenA
 		if err := os.WriteFile(dockerfileTemplatePath, []byte(template), common.DefaultFilePermission); err != nil {
 			logrus.Errorf("Could not write the generated Build Dockerfile template: %s", err)
 		}
-		templateData := JbossDockerfileTemplate{}
+		templateData := JbossDockerfileTemplate{RunImage: getAppServerRunImage("JBoss")}
 		warConfig := artifacts.WarArtifactConfig{}
 		if err := newArtifact.GetConfig(artifacts.WarConfigType, &warConfig); err == nil {
 			// WAR
@@ -136,7 +137,7 @@ func (t *Jboss) Transform(newArtifacts []transformertypes.Artifact, alreadySeenA
 			templateData.JavaPackageName = javaPackage
 			templateData.DeploymentFilePath = warConfig.DeploymentFilePath
 			templateData.Port = defaultJbossPort
-			templateData.EnvVariables = warConfig.EnvVariables
+			templateData.EnvVariables = getJVMEnvVariables(serviceConfig.ServiceName, warConfig.EnvVariables)
 			templateData.BuildContainerName = warConfig.BuildContainerName
 		} else {
 			// EAR
@@ -153,7 +154,7 @@ func (t *Jboss) Transform(newArtifacts []transformertypes.Artifact, alreadySeenA
 			templateData.JavaPackageName = javaPackage
 			templateData.DeploymentFilePath = earConfig.DeploymentFilePath
 			templateData.Port = defaultJbossPort
-			templateData.EnvVariables = earConfig.EnvVariables
+			templateData.EnvVariables = getJVMEnvVariables(serviceConfig.ServiceName, earConfig.EnvVariables)
 			templateData.BuildContainerName = earConfig.BuildContainerName
 		}
 		pathMappings = append(pathMappings, transformertypes.PathMapping{
@@ -54,6 +54,7 @@ type LibertyDockerfileTemplate struct {
 	BuildContainerName string
 	Port               int32
 	EnvVariables       map[string]string
+	RunImage           string
 }
 
 // JavaLibertyImageMapping stores the java version to liberty image version mappings
@@ -143,7 +144,7 @@ func (t *Liberty) Transform(newArtifacts []transformertypes.Artifact, alreadySee
 			logrus.Errorf("failed to write the liberty Dockerfile template to the temporary file at path %s . Error: %q", dockerfileTemplatePath, err)
 			continue
 		}
-		templateData := LibertyDockerfileTemplate{}
+		templateData := LibertyDockerfileTemplate{RunImage: getAppServerRunImage("Liberty")}
 		warConfig := artifacts.WarArtifactConfig{}
 		if err := newArtifact.GetConfig(artifacts.WarConfigType, &warConfig); err == nil {
 			// WAR
@@ -158,7 +159,7 @@ func (t *Liberty) Transform(newArtifacts []transformertypes.Artifact, alreadySee
 			templateData.JavaPackageName = javaPackage
 			templateData.JavaVersion = warConfig.JavaVersion
 			templateData.Port = defaultLibertyPort
-			templateData.EnvVariables = warConfig.EnvVariables
+			templateData.EnvVariables = getJVMEnvVariables(serviceConfig.ServiceName, warConfig.EnvVariables)
 			templateData.DeploymentFilePath = warConfig.DeploymentFilePath
 			templateData.BuildContainerName = warConfig.BuildContainerName
 		} else {
@@ -179,7 +180,7 @@ func (t *Liberty) Transform(newArtifacts []transformertypes.Artifact, alreadySee
 			templateData.JavaPackageName = javaPackage
 			templateData.JavaVersion = earConfig.JavaVersion
 			templateData.Port = defaultLibertyPort
-			templateData.EnvVariables = earConfig.EnvVariables
+			templateData.EnvVariables = getJVMEnvVariables(serviceConfig.ServiceName, earConfig.EnvVariables)
 			templateData.DeploymentFilePath = earConfig.DeploymentFilePath
 			templateData.BuildContainerName = earConfig.BuildContainerName
 		}
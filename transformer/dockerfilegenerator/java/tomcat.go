@@ -53,6 +53,7 @@ type TomcatDockerfileTemplate struct {
 	BuildContainerName string
 	Port               int32
 	EnvVariables       map[string]string
+	RunImage           string
 }
 
 // Init Initializes the transformer
@@ -151,8 +152,9 @@ func (t *Tomcat) Transform(newArtifacts []transformertypes.Artifact, alreadySeen
 			JavaVersion:        warConfig.JavaVersion,
 			DeploymentFilePath: warConfig.DeploymentFilePath,
 			Port:               tomcatDefaultPort,
-			EnvVariables:       warConfig.EnvVariables,
+			EnvVariables:       getJVMEnvVariables(serviceConfig.ServiceName, warConfig.EnvVariables),
 			BuildContainerName: warConfig.BuildContainerName,
+			RunImage:           getAppServerRunImage("Tomcat"),
 		}
 		pathMappings = append(pathMappings, transformertypes.PathMapping{
 			Type:           transformertypes.TemplatePathMappingType,
@@ -18,9 +18,12 @@ package java
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema/fixer"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
 	"github.com/sirupsen/logrus"
 )
 
@@ -39,8 +42,61 @@ const (
 	defaultAppPathInContainer = "/app"
 	defaultJavaVersion        = "17"
 	defaultJavaPackage        = "java-17-openjdk-devel"
+	javaToolOptionsEnvName    = "JAVA_TOOL_OPTIONS"
+	javaUBIRunImage           = "registry.access.redhat.com/ubi8/ubi-minimal:latest"
+	// jvmNonHeapMemoryMiB is the memory left for the metaspace, thread stacks and other non heap memory of the JVM
+	jvmNonHeapMemoryMiB = 256
+	minMaxRAMPercentage = 50
+	maxMaxRAMPercentage = 80
 )
 
+// getJVMEnvVariables adds the JAVA_TOOL_OPTIONS which size the heap relative to the memory limit of the container of the service.
+// The JVMs in the base images are container aware, so the percentage applies to the limit written into the manifests.
+func getJVMEnvVariables(serviceName string, envVariables map[string]string) map[string]string {
+	newEnvVariables := map[string]string{}
+	for k, v := range envVariables {
+		newEnvVariables[k] = v
+	}
+	if _, ok := newEnvVariables[javaToolOptionsEnvName]; ok {
+		return newEnvVariables
+	}
+	memoryLimit := fixer.GetContainerMemoryLimit(serviceName, serviceName)
+	newEnvVariables[javaToolOptionsEnvName] = fmt.Sprintf("-XX:MaxRAMPercentage=%d.0", getMaxRAMPercentage(memoryLimit.Value()))
+	return newEnvVariables
+}
+
+// getMaxRAMPercentage returns the percentage of the memory limit that can be used for the heap
+func getMaxRAMPercentage(memoryLimitBytes int64) int64 {
+	if memoryLimitBytes <= 0 {
+		return minMaxRAMPercentage
+	}
+	percentage := (memoryLimitBytes - jvmNonHeapMemoryMiB*1024*1024) * 100 / memoryLimitBytes
+	if percentage < minMaxRAMPercentage {
+		return minMaxRAMPercentage
+	}
+	if percentage > maxMaxRAMPercentage {
+		return maxMaxRAMPercentage
+	}
+	return percentage
+}
+
+// getJavaRunImage returns the image used to run the JAR. The Java package is installed in the UBI image.
+// The distroless Java images are not supported since their entrypoint runs java -jar with a fixed path.
+func getJavaRunImage(javaVersion string) string {
+	familyImages := map[string]string{commonqa.UBIBaseImageFamily: javaUBIRunImage}
+	switch majorVersion := strings.TrimPrefix(javaVersion, "1."); majorVersion {
+	case "8", "11", "17":
+		familyImages[commonqa.AlpineBaseImageFamily] = "eclipse-temurin:" + majorVersion + "-jre-alpine"
+	}
+	return commonqa.BaseImage("Java", familyImages, javaUBIRunImage)
+}
+
+// getAppServerRunImage returns the image used to run the application server.
+// The application servers are installed using the UBI package manager, so only UBI is supported.
+func getAppServerRunImage(appServerName string) string {
+	return commonqa.BaseImage(appServerName, map[string]string{commonqa.UBIBaseImageFamily: javaUBIRunImage}, javaUBIRunImage)
+}
+
 func getJavaPackage(mappingFile string, version string) (pkg string, err error) {
 	var javaPackageNamesMapping JavaPackageNamesMapping
 	if err := common.ReadMove2KubeYaml(mappingFile, &javaPackageNamesMapping); err != nil {
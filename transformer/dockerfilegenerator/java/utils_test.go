@@ -0,0 +1,103 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package java
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema/fixer"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+)
+
+func TestGetMaxRAMPercentage(t *testing.T) {
+	testCases := []struct {
+		memoryLimit int64
+		want        int64
+	}{
+		{memoryLimit: 0, want: minMaxRAMPercentage},
+		{memoryLimit: 256 * 1024 * 1024, want: minMaxRAMPercentage},
+		{memoryLimit: 768 * 1024 * 1024, want: 66},
+		{memoryLimit: 1024 * 1024 * 1024, want: 75},
+		{memoryLimit: 4 * 1024 * 1024 * 1024, want: maxMaxRAMPercentage},
+	}
+	for _, testCase := range testCases {
+		if got := getMaxRAMPercentage(testCase.memoryLimit); got != testCase.want {
+			t.Errorf("getMaxRAMPercentage(%d) = %d, want %d", testCase.memoryLimit, got, testCase.want)
+		}
+	}
+}
+
+func TestGetJVMEnvVariables(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	metricsDir := t.TempDir()
+	metrics := collecttypes.NewUsageMetrics("test")
+	metrics.Spec.Workloads = []collecttypes.WorkloadUsage{{Name: "observed", Containers: []collecttypes.ContainerUsage{{Name: "observed", Memory: "512Mi"}}}}
+	if err := common.WriteYaml(filepath.Join(metricsDir, "metrics.yaml"), metrics); err != nil {
+		t.Fatalf("failed to write the usage metrics. Error: %q", err)
+	}
+	fixer.LoadUsageMetrics(metricsDir)
+
+	testCases := []struct {
+		name         string
+		serviceName  string
+		envVariables map[string]string
+		want         string
+	}{
+		{name: "service with the default memory limit", serviceName: "plain", want: "-XX:MaxRAMPercentage=50.0"},
+		{name: "service with the limit from the observed usage", serviceName: "observed", want: "-XX:MaxRAMPercentage=66.0"},
+		{name: "options set by the user are kept", serviceName: "observed", envVariables: map[string]string{javaToolOptionsEnvName: "-Xmx1g"}, want: "-Xmx1g"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			got := getJVMEnvVariables(testCase.serviceName, testCase.envVariables)
+			if got[javaToolOptionsEnvName] != testCase.want {
+				t.Fatalf("got the %s %q , want %q", javaToolOptionsEnvName, got[javaToolOptionsEnvName], testCase.want)
+			}
+		})
+	}
+}
+
+func TestGetJavaRunImage(t *testing.T) {
+	testCases := []struct {
+		family      string
+		javaVersion string
+		want        string
+	}{
+		{family: "UBI", javaVersion: "17", want: javaUBIRunImage},
+		{family: "Alpine", javaVersion: "1.8", want: "eclipse-temurin:8-jre-alpine"},
+		{family: "Alpine", javaVersion: "11", want: "eclipse-temurin:11-jre-alpine"},
+		{family: "Alpine", javaVersion: "15", want: javaUBIRunImage},
+		{family: "Distroless", javaVersion: "17", want: javaUBIRunImage},
+	}
+	for _, testCase := range testCases {
+		qaengine.Reset()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{common.ConfigBaseImageFamilyKey + `="` + testCase.family + `"`}, nil, nil, false)
+		if got := getJavaRunImage(testCase.javaVersion); got != testCase.want {
+			t.Errorf("getJavaRunImage(%q) with the family %s = %q, want %q", testCase.javaVersion, testCase.family, got, testCase.want)
+		}
+		if got := getAppServerRunImage("Tomcat"); got != javaUBIRunImage {
+			t.Errorf("getAppServerRunImage(%q) with the family %s = %q, want %q", "Tomcat", testCase.family, got, javaUBIRunImage)
+		}
+	}
+	qaengine.Reset()
+}
@@ -66,6 +66,7 @@ type NodejsTemplateConfig struct {
 	NodeVersion           string
 	NodeImageTag          string
 	NodeMajorVersion      string
+	RunImage              string
 	NodeVersionProperties map[string]string
 	PackageManager        string
 }
@@ -111,6 +112,8 @@ const (
 	defaultPackageManager  = "npm"
 	imageTagKey            = "imageTag"
 	versionKey             = "version"
+	// minimalNodeImageMajorVersion is the oldest Node version with a ubi8 minimal image
+	minimalNodeImageMajorVersion = "v14"
 	// NodeVersionsMappingKind defines kind of NodeVersionMappingKind
 	NodeVersionsMappingKind types.Kind = "NodeVersionsMapping"
 )
@@ -261,6 +264,7 @@ func (t *NodejsDockerfileGenerator) Transform(newArtifacts []transformertypes.Ar
 			NodeVersion: nodeVersion,
 			// NodeImageTag:          getNodeImageTag(t.Spec.NodeVersions, nodeVersion), // To use this, change the base image in the Dockerfile template to- FROM node:{{ .NodeImageTag }}
 			NodeMajorVersion:      strings.TrimPrefix(semver.Major(nodeVersion), "v"),
+			RunImage:              getNodejsRunImage(nodeVersion),
 			NodeVersionProperties: props,
 			PackageManager:        packageManager,
 		}
@@ -347,3 +351,14 @@ func getNodeImageTag(nodeVersions []map[string]string, selectedNodeVersion strin
 	}
 	return nodeImageTag
 }
+
+// getNodejsRunImage returns the image used to run the Node.js app.
+// The node modules are installed using the UBI image, so only UBI is supported.
+func getNodejsRunImage(nodeVersion string) string {
+	majorVersion := semver.Major(nodeVersion)
+	ubiRunImage := "registry.access.redhat.com/ubi8/nodejs-" + strings.TrimPrefix(majorVersion, "v")
+	if semver.Compare(majorVersion, minimalNodeImageMajorVersion) >= 0 {
+		ubiRunImage += "-minimal"
+	}
+	return commonqa.BaseImage("Node.js", map[string]string{commonqa.UBIBaseImageFamily: ubiRunImage}, ubiRunImage)
+}
@@ -50,6 +50,14 @@ type PHPDockerfileGenerator struct {
 type PhpTemplateConfig struct {
 	ConfFile     string
 	ConfFilePort int32
+	RunImage     string
+}
+
+const phpUBIRunImage = "registry.access.redhat.com/ubi8/php-74:latest"
+
+// phpRunImages are the images used to run the PHP apps for each base image family
+var phpRunImages = map[string]string{
+	commonqa.UBIBaseImageFamily: phpUBIRunImage,
 }
 
 // Init Initializes the transformer
@@ -194,6 +202,7 @@ func (t *PHPDockerfileGenerator) Transform(newArtifacts []transformertypes.Artif
 		}
 		detectedPorts := ir.GetAllServicePorts()
 		var phpConfig PhpTemplateConfig
+		phpConfig.RunImage = commonqa.BaseImage("PHP", phpRunImages, phpUBIRunImage)
 		confFiles, err := detectConfFiles(a.Paths[artifacts.ServiceDirPathType][0])
 		if err != nil {
 			logrus.Debugf("Could not detect any conf files %s", err)
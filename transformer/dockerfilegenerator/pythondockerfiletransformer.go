@@ -46,6 +46,14 @@ type PythonTemplateConfig struct {
 	StartingScriptRelPath string
 	RequirementsTxt       string
 	IsDjango              bool
+	RunImage              string
+}
+
+const pythonUBIRunImage = "registry.access.redhat.com/ubi8/python-36"
+
+// pythonRunImages are the images used to run the Python apps for each base image family
+var pythonRunImages = map[string]string{
+	commonqa.UBIBaseImageFamily: pythonUBIRunImage,
 }
 
 // PythonConfig implements python config interface
@@ -226,6 +234,7 @@ func (t *PythonDockerfileGenerator) Transform(newArtifacts []transformertypes.Ar
 			pythonTemplateConfig.StartingScriptRelPath = getStartingPythonFileForService(newArtifact.Paths[PythonFilesPathType], serviceDir, newArtifact.Name)
 		}
 		pythonTemplateConfig.AppName = newArtifact.Name
+		pythonTemplateConfig.RunImage = commonqa.BaseImage("Python", pythonRunImages, pythonUBIRunImage)
 		var pythonConfig PythonConfig
 		err = newArtifact.GetConfig(PythonServiceConfigType, &pythonConfig)
 		if err != nil {
@@ -41,10 +41,17 @@ type RubyDockerfileGenerator struct {
 
 // RubyTemplateConfig implements Ruby config interface
 type RubyTemplateConfig struct {
-	Port    int32
-	AppName string
+	Port     int32
+	AppName  string
+	RunImage string
 }
 
+const rubyDefaultRunImage = "ruby:2.5-slim"
+
+// rubyRunImages are the images used to run the Ruby apps for each base image family.
+// The gems are installed using the ruby image, so only the images based on the same distribution are supported.
+var rubyRunImages = map[string]string{}
+
 // Init Initializes the transformer
 func (t *RubyDockerfileGenerator) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
 	t.Config = tc
@@ -122,6 +129,7 @@ func (t *RubyDockerfileGenerator) Transform(newArtifacts []transformertypes.Arti
 		}
 		rubyConfig.Port = commonqa.GetPortForService(detectedPorts, `"`+a.Name+`"`)
 		rubyConfig.AppName = a.Name
+		rubyConfig.RunImage = commonqa.BaseImage("Ruby", rubyRunImages, rubyDefaultRunImage)
 		if sImageName.ImageName == "" {
 			sImageName.ImageName = common.MakeStringContainerImageNameCompliant(sConfig.ServiceName)
 		}
@@ -48,6 +48,16 @@ type RustTemplateConfig struct {
 	AppName       string
 	RocketToml    string
 	RocketAddress string
+	RunImage      string
+}
+
+const rustUBIRunImage = "registry.access.redhat.com/ubi8/ubi-minimal:8.3-201"
+
+// rustRunImages are the images used to run the Rust apps for each base image family.
+// The binaries built by the rust image link against glibc, so Alpine is not supported.
+var rustRunImages = map[string]string{
+	commonqa.UBIBaseImageFamily:        rustUBIRunImage,
+	commonqa.DistrolessBaseImageFamily: "gcr.io/distroless/cc-debian11",
 }
 
 // CargoTomlConfig implements Cargo.toml config interface
@@ -139,6 +149,7 @@ func (t *RustDockerfileGenerator) Transform(newArtifacts []transformertypes.Arti
 		ports := ir.GetAllServicePorts()
 		var rustConfig RustTemplateConfig
 		rustConfig.AppName = a.Name
+		rustConfig.RunImage = commonqa.BaseImage("Rust", rustRunImages, rustUBIRunImage)
 		rocketTomlFilePath := filepath.Join(a.Paths[artifacts.ServiceDirPathType][0], rocketTomlFile)
 		if _, err := os.Stat(rocketTomlFilePath); err == nil {
 			rustConfig.RocketToml = rocketTomlFile
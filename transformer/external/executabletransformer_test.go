@@ -0,0 +1,71 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+func TestExecutableStopsWhenTheContextIsCancelled(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the transform command needs a posix shell")
+	}
+	oldTempPath := common.TempPath
+	common.TempPath = t.TempDir()
+	defer func() { common.TempPath = oldTempPath }()
+	tc := transformertypes.NewTransformer()
+	tc.Name = "test"
+	tc.Spec.Class = "Executable"
+	tc.Spec.Config = map[string]interface{}{
+		"platforms": []string{runtime.GOOS},
+		// exec keeps the shell from forking, so that killing it also closes the output
+		"transformCMD": []string{"sh", "-c", "exec sleep 60"},
+	}
+	env, err := environment.NewEnvironment(environment.EnvInfo{
+		Name:              tc.Name,
+		Source:            t.TempDir(),
+		Output:            t.TempDir(),
+		Context:           t.TempDir(),
+		EnvPlatformConfig: environmenttypes.EnvPlatformConfig{Platforms: []string{runtime.GOOS}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to create the environment. Error: %q", err)
+	}
+	executable := &Executable{}
+	if err := executable.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, _, err = executable.TransformWithContext(ctx, nil, nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the transform command to be stopped by the context, got the error %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Second {
+		t.Fatalf("expected the transform command to be killed soon after the context was cancelled, it ran for %s", elapsed)
+	}
+}
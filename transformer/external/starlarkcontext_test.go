@@ -0,0 +1,131 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	starutil "github.com/qri-io/starlib/util"
+	"go.starlark.net/starlark"
+)
+
+const contextStarFunctions = `
+def inspect():
+    cluster = context.cluster()
+    return {
+        "project": context.project(),
+        "source": context.source_dir(),
+        "services": [s["name"] + ":" + s["type"] for s in context.services()],
+        "ir": context.ir()["name"] if context.ir() else "",
+        "cluster": cluster["metadata"]["name"] if cluster else "",
+        "answers": context.answers(),
+        "resources": [r["resource"]["metadata"]["name"] for r in context.resources()],
+    }
+
+def modify():
+    context.answers()["move2kube.modified"] = "yes"
+`
+
+// callStarlarkFunction calls the function defined by the script of the transformer, with the artifacts being transformed
+func callStarlarkFunction(t *testing.T, starlarkTransformer *Starlark, fnName string, transformedArtifacts []transformertypes.Artifact) (interface{}, error) {
+	t.Helper()
+	starlarkTransformer.artifacts = transformedArtifacts
+	defer func() { starlarkTransformer.artifacts = nil }()
+	val, err := starlark.Call(starlarkTransformer.StarThread, starlarkTransformer.StarGlobals[fnName], nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return starutil.Unmarshal(val)
+}
+
+// getImageRegistryAnswer removes the answers from the context returned by the script and returns the answer of the image registry
+func getImageRegistryAnswer(got interface{}) interface{} {
+	context := got.(map[string]interface{})
+	answers := context["answers"].(map[string]interface{})
+	delete(context, "answers")
+	return answers[common.ConfigImageRegistryURLKey]
+}
+
+func TestStarlarkContextModule(t *testing.T) {
+	starlarkTransformer, err := initStarlark(t, contextStarFunctions, nil, false, common.ConfigImageRegistryURLKey+`="quay.io"`)
+	if err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	qaengine.FetchStringAnswer(common.ConfigImageRegistryURLKey, "Enter the registry :", nil, "docker.io", nil)
+
+	got, err := callStarlarkFunction(t, starlarkTransformer, "inspect", nil)
+	if err != nil {
+		t.Fatalf("failed to call the function. Error: %q", err)
+	}
+	want := map[string]interface{}{
+		"project":   starlarkTransformer.Env.ProjectName,
+		"source":    starlarkTransformer.Env.GetEnvironmentSource(),
+		"services":  []interface{}{},
+		"ir":        "",
+		"cluster":   "",
+		"resources": []interface{}{},
+	}
+	if answer := getImageRegistryAnswer(got); answer != "quay.io" {
+		t.Fatalf("expected the answer of the image registry in the answers, got %v", answer)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got the wrong context without artifacts. Diff (-want +got):\n%s", diff)
+	}
+
+	ir := irtypes.NewIR()
+	ir.Name = "shop"
+	transformedArtifacts := []transformertypes.Artifact{
+		{
+			Name: "shop",
+			Type: irtypes.IRArtifactType,
+			Configs: map[transformertypes.ConfigType]interface{}{
+				irtypes.IRConfigType:       ir,
+				kubernetes.ClusterMetadata: collecttypes.NewClusterMetadata("kind"),
+			},
+		},
+		{
+			Name:    "api",
+			Type:    artifacts.ServiceArtifactType,
+			Paths:   map[transformertypes.PathType][]string{artifacts.ServiceDirPathType: {"/src/api"}},
+			Configs: map[transformertypes.ConfigType]interface{}{artifacts.ServiceConfigType: artifacts.ServiceConfig{ServiceName: "api"}},
+		},
+	}
+	got, err = callStarlarkFunction(t, starlarkTransformer, "inspect", transformedArtifacts)
+	if err != nil {
+		t.Fatalf("failed to call the function. Error: %q", err)
+	}
+	want["services"] = []interface{}{"api:" + string(artifacts.ServiceArtifactType)}
+	want["ir"] = "shop"
+	want["cluster"] = "kind"
+	getImageRegistryAnswer(got)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("got the wrong context with the artifacts. Diff (-want +got):\n%s", diff)
+	}
+
+	if _, err := callStarlarkFunction(t, starlarkTransformer, "modify", nil); err == nil || !strings.Contains(err.Error(), "frozen") {
+		t.Fatalf("expected the context to be read only, got the error %v", err)
+	}
+}
@@ -0,0 +1,114 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+)
+
+const questionsStarFunctions = `
+def questions():
+    return [
+        {"id": "custom.greeting", "description": "Enter the greeting :", "default": "hello"},
+        {"id": "move2kube.custom.color", "type": "Select", "description": "Select the color :", "options": ["red", "blue"], "default": "red", "validation": "validate_color"},
+        {"id": "custom.features", "type": "MultiSelect", "description": "Select the features :", "options": ["a", "b", "c"], "default": ["a"]},
+    ]
+
+def validate_color(ans):
+    return "" if ans in ["red", "blue"] else "invalid color"
+
+def ask():
+    return {
+        "greeting": m2k.query({"id": "custom.greeting", "description": "Enter another greeting :", "default": "ignored"}),
+        "color": m2k.query({"id": "custom.color", "description": "Select the color :"}),
+        "features": m2k.query({"id": "custom.features", "description": "Select the features :"}),
+        "adhoc": m2k.query({"id": "custom.adhoc", "description": "Enter the value :", "default": "later"}),
+    }
+`
+
+// getCustomAnswers returns the answers of the questions of the script, leaving out the ones of the sandbox
+func getCustomAnswers() map[string]interface{} {
+	answers := map[string]interface{}{}
+	for id, answer := range qaengine.GetAnswers() {
+		if strings.HasPrefix(id, common.JoinQASubKeys(common.BaseKey, "custom")) {
+			answers[id] = answer
+		}
+	}
+	return answers
+}
+
+func TestStarlarkRegisteredQuestions(t *testing.T) {
+	starlarkTransformer, err := initStarlark(t, questionsStarFunctions, nil, false,
+		common.JoinQASubKeys(common.BaseKey, "custom", "greeting")+`="hi"`,
+		common.JoinQASubKeys(common.BaseKey, "custom", "features")+`=["b","c"]`,
+	)
+	if err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	// the registered questions are asked when the transformer is initialized, before the transformation
+	wantAnswers := map[string]interface{}{
+		"move2kube.custom.greeting": "hi",
+		"move2kube.custom.color":    "red",
+		"move2kube.custom.features": []string{"b", "c"},
+	}
+	if diff := cmp.Diff(wantAnswers, getCustomAnswers()); diff != "" {
+		t.Fatalf("the registered questions were not answered during the initialization. Diff (-want +got):\n%s", diff)
+	}
+	got, err := callStarlarkFunction(t, starlarkTransformer, "ask", nil)
+	if err != nil {
+		t.Fatalf("failed to call the function. Error: %q", err)
+	}
+	want := map[string]interface{}{
+		"greeting": "hi",
+		"color":    "red",
+		"features": []interface{}{"b", "c"},
+		"adhoc":    "later",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("the queries did not return the answers of the registered questions. Diff (-want +got):\n%s", diff)
+	}
+	wantAnswers["move2kube.custom.adhoc"] = "later"
+	if diff := cmp.Diff(wantAnswers, getCustomAnswers()); diff != "" {
+		t.Fatalf("expected only the question which was not registered to be asked again. Diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestStarlarkRegisteredQuestionsErrors(t *testing.T) {
+	testCases := []struct {
+		name   string
+		script string
+		want   string
+	}{
+		{name: "parameters", script: "def questions(x):\n    return []\n", want: "required number of paramters"},
+		{name: "not a list", script: "def questions():\n    return \"q\"\n", want: "should return a list of question objects"},
+		{name: "missing id", script: "def questions():\n    return [{\"description\": \"Enter :\"}]\n", want: "the key 'id' is missing"},
+		{name: "missing validation", script: "def questions():\n    return [{\"id\": \"custom.q\", \"validation\": \"missing\"}]\n", want: "validation function not found"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := initStarlark(t, testCase.script, nil, false)
+			if err == nil || !strings.Contains(err.Error(), testCase.want) {
+				t.Fatalf("expected an error containing %q , got %v", testCase.want, err)
+			}
+		})
+	}
+}
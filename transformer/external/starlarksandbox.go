@@ -0,0 +1,172 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"go.starlark.net/starlark"
+)
+
+const (
+	// defaultStarTimeout is the default maximum duration of each call of a Starlark script
+	defaultStarTimeout = 5 * time.Minute
+	// defaultStarMaxSteps is the default maximum number of computation steps of each call of a Starlark script
+	defaultStarMaxSteps uint64 = 100000000
+	// starHeapPollInterval is the interval at which the heap is checked against the memory limit while a Starlark script runs
+	starHeapPollInterval = 100 * time.Millisecond
+	// starContextLocalKey is the key of the thread local holding the context of the call, for the builtins which run more starlark code
+	starContextLocalKey = "move2kube.context"
+)
+
+// starSandbox limits the execution of the Starlark scripts, so that a buggy or malicious script can not hang or compromise the transformation.
+// The limits apply to each call of the script, like loading the file, transforming the artifacts or validating an answer.
+// They come from the config of the user and not from the yaml of the transformer, so that a script can not raise its own limits.
+type starSandbox struct {
+	maxSteps uint64
+	timeout  time.Duration
+	// maxHeapMB stops the script when the heap of the process grows above it. Zero means no limit.
+	// Starlark does not account the memory allocated by each script, so the heap of the whole process is checked.
+	maxHeapMB uint64
+	// allowFSWrite adds the functions which write files to the fs module
+	allowFSWrite bool
+	// allowNetwork adds the modules which can fetch urls, like xlsx
+	allowNetwork bool
+}
+
+// getStarSandbox returns the limits for the Starlark transformer at the path.
+// The customized transformers, including the remote ones, can not write files or access the network unless the user trusts them.
+func getStarSandbox(transformerYamlPath string) (starSandbox, error) {
+	sandbox := starSandbox{allowFSWrite: true, allowNetwork: true}
+	timeoutStr := qaengine.FetchStringAnswer(
+		common.ConfigStarlarkTimeoutKey,
+		"Enter the maximum duration of each call of the Starlark scripts :",
+		[]string{"Like 30s or 5m. The scripts which run longer are stopped."},
+		defaultStarTimeout.String(),
+		nil,
+	)
+	timeout, err := time.ParseDuration(timeoutStr)
+	if err != nil {
+		return sandbox, fmt.Errorf("failed to parse the Starlark timeout '%s' . Error: %w", timeoutStr, err)
+	}
+	if timeout <= 0 {
+		return sandbox, fmt.Errorf("the Starlark timeout '%s' should be positive", timeoutStr)
+	}
+	sandbox.timeout = timeout
+	maxStepsStr := qaengine.FetchStringAnswer(
+		common.ConfigStarlarkMaxStepsKey,
+		"Enter the maximum number of computation steps of each call of the Starlark scripts :",
+		[]string{"The scripts which take more steps are stopped."},
+		strconv.FormatUint(defaultStarMaxSteps, 10),
+		nil,
+	)
+	maxSteps, err := strconv.ParseUint(maxStepsStr, 10, 64)
+	if err != nil {
+		return sandbox, fmt.Errorf("failed to parse the maximum number of Starlark steps '%s' . Error: %w", maxStepsStr, err)
+	}
+	if maxSteps == 0 {
+		return sandbox, fmt.Errorf("the maximum number of Starlark steps should be positive")
+	}
+	sandbox.maxSteps = maxSteps
+	maxHeapStr := qaengine.FetchStringAnswer(
+		common.ConfigStarlarkMaxHeapKey,
+		"Enter the maximum size of the heap in MB while the Starlark scripts run :",
+		[]string{"The scripts are stopped when the memory used by move2kube grows above it. 0 means no limit."},
+		"0",
+		nil,
+	)
+	maxHeapMB, err := strconv.ParseUint(maxHeapStr, 10, 64)
+	if err != nil {
+		return sandbox, fmt.Errorf("failed to parse the maximum size of the heap '%s' . Error: %w", maxHeapStr, err)
+	}
+	sandbox.maxHeapMB = maxHeapMB
+	if common.IsParent(transformerYamlPath, filepath.Join(common.AssetsPath, "custom")) {
+		trusted := qaengine.FetchBoolAnswer(
+			common.ConfigStarlarkTrustCustomizationsKey,
+			"Do you want to let the customized Starlark transformers write files and access the network?",
+			[]string{"Otherwise they can only read files and return the path mappings of the files to write."},
+			false,
+			nil,
+		)
+		sandbox.allowFSWrite = trusted
+		sandbox.allowNetwork = trusted
+	}
+	return sandbox, nil
+}
+
+// runSandboxed runs the function on a new Starlark thread with the limits of the sandbox.
+// The thread is also cancelled when the context is cancelled, like when the planning or the transformation is stopped.
+func (t *Starlark) runSandboxed(ctx context.Context, run func(thread *starlark.Thread) error) error {
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("the starlark script was not run. Error: %w", err)
+	}
+	thread := &starlark.Thread{Name: t.Config.Name}
+	t.StarThread = thread
+	thread.SetLocal(starContextLocalKey, ctx)
+	thread.SetMaxExecutionSteps(t.sandbox.maxSteps)
+	timer := time.AfterFunc(t.sandbox.timeout, func() {
+		thread.Cancel(fmt.Sprintf("exceeded the time limit of %s", t.sandbox.timeout))
+	})
+	defer timer.Stop()
+	if ctx.Done() != nil || t.sandbox.maxHeapMB != 0 {
+		runDone := make(chan struct{})
+		defer close(runDone)
+		go t.watchSandboxed(ctx, thread, runDone)
+	}
+	return run(thread)
+}
+
+// watchSandboxed cancels the thread when the context is cancelled or the heap grows above the memory limit, until the run is done
+func (t *Starlark) watchSandboxed(ctx context.Context, thread *starlark.Thread, runDone <-chan struct{}) {
+	var heapPoll <-chan time.Time
+	if t.sandbox.maxHeapMB != 0 {
+		ticker := time.NewTicker(starHeapPollInterval)
+		defer ticker.Stop()
+		heapPoll = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			thread.Cancel(fmt.Sprintf("the call was stopped. Error: %q", ctx.Err()))
+			return
+		case <-heapPoll:
+			memStats := runtime.MemStats{}
+			runtime.ReadMemStats(&memStats)
+			if heapMB := memStats.HeapAlloc / (1024 * 1024); heapMB > t.sandbox.maxHeapMB {
+				thread.Cancel(fmt.Sprintf("exceeded the memory limit of %d MB. The heap is %d MB", t.sandbox.maxHeapMB, heapMB))
+				return
+			}
+		case <-runDone:
+			return
+		}
+	}
+}
+
+// getThreadContext returns the context of the call running on the thread
+func getThreadContext(thread *starlark.Thread) context.Context {
+	if ctx, ok := thread.Local(starContextLocalKey).(context.Context); ok {
+		return ctx
+	}
+	return context.Background()
+}
@@ -0,0 +1,188 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	environmenttypes "github.com/konveyor/move2kube/types/environment"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"go.starlark.net/starlark"
+)
+
+const loopingStarFunction = `
+def loop(ans=None):
+    x = 0
+    for i in range(1000000000):
+        x += i
+    return ""
+`
+
+// initStarlark initializes a Starlark transformer with the script, from the directory of the customizations if customized is true
+func initStarlark(t *testing.T, script string, starConfig map[string]interface{}, customized bool, configs ...string) (*Starlark, error) {
+	t.Helper()
+	oldTempPath, oldAssetsPath := common.TempPath, common.AssetsPath
+	common.TempPath = t.TempDir()
+	common.AssetsPath = filepath.Join(common.TempPath, common.AssetsDir)
+	t.Cleanup(func() { common.TempPath, common.AssetsPath = oldTempPath, oldAssetsPath })
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", configs, nil, nil, false)
+
+	contextPath := filepath.Join(common.AssetsPath, "built-in", "test")
+	if customized {
+		contextPath = filepath.Join(common.AssetsPath, "custom", "test")
+	}
+	if err := os.MkdirAll(contextPath, 0755); err != nil {
+		t.Fatalf("failed to create the context directory. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(contextPath, "test.star"), []byte(script+"\ndef transform(new_artifacts, old_artifacts):\n    return {}\n"), 0644); err != nil {
+		t.Fatalf("failed to write the starlark script. Error: %q", err)
+	}
+	if starConfig == nil {
+		starConfig = map[string]interface{}{}
+	}
+	starConfig["starFile"] = "test.star"
+	tc := transformertypes.NewTransformer()
+	tc.Name = "test"
+	tc.Spec.Class = "Starlark"
+	tc.Spec.Config = starConfig
+	tc.Spec.TransformerYamlPath = filepath.Join(contextPath, "transformer.yaml")
+	env, err := environment.NewEnvironment(environment.EnvInfo{
+		Name:              tc.Name,
+		Source:            t.TempDir(),
+		Output:            t.TempDir(),
+		Context:           contextPath,
+		EnvPlatformConfig: environmenttypes.EnvPlatformConfig{Platforms: []string{runtime.GOOS}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("failed to create the environment. Error: %q", err)
+	}
+	starlarkTransformer := &Starlark{}
+	return starlarkTransformer, starlarkTransformer.Init(tc, env)
+}
+
+func TestStarlarkSandboxLimits(t *testing.T) {
+	t.Run("the script can not raise the step limit in the transformer yaml", func(t *testing.T) {
+		_, err := initStarlark(t, loopingStarFunction+"loop()\n", map[string]interface{}{"sandbox": map[string]interface{}{"maxSteps": 0, "timeout": "1h"}}, false, common.ConfigStarlarkMaxStepsKey+`="10000"`)
+		if err == nil || !strings.Contains(err.Error(), "too many steps") {
+			t.Fatalf("expected the script to exceed the step limit, got the error %v", err)
+		}
+	})
+	t.Run("the script is stopped after the timeout", func(t *testing.T) {
+		_, err := initStarlark(t, loopingStarFunction+"loop()\n", nil, false, common.ConfigStarlarkTimeoutKey+`="50ms"`)
+		if err == nil || !strings.Contains(err.Error(), "exceeded the time limit") {
+			t.Fatalf("expected the script to exceed the time limit, got the error %v", err)
+		}
+	})
+	t.Run("the script is stopped when the heap grows above the memory limit", func(t *testing.T) {
+		script := "def grow():\n    chunks = []\n    for i in range(100000000):\n        chunks.append(\"x\" * 1000000)\n\ngrow()\n"
+		_, err := initStarlark(t, script, nil, false, common.ConfigStarlarkMaxHeapKey+`="64"`)
+		if err == nil || !strings.Contains(err.Error(), "exceeded the memory limit") {
+			t.Fatalf("expected the script to exceed the memory limit, got the error %v", err)
+		}
+	})
+	t.Run("the validators are limited", func(t *testing.T) {
+		starlarkTransformer, err := initStarlark(t, loopingStarFunction, nil, false, common.ConfigStarlarkMaxStepsKey+`="10000"`)
+		if err != nil {
+			t.Fatalf("failed to initialize the transformer. Error: %q", err)
+		}
+		prob, err := starlarkTransformer.getProblem(context.Background(), map[string]interface{}{"id": "test.validated"}, "loop")
+		if err != nil {
+			t.Fatalf("failed to get the problem. Error: %q", err)
+		}
+		if err := prob.Validator("answer"); err == nil || !strings.Contains(err.Error(), "too many steps") {
+			t.Fatalf("expected the validator to exceed the step limit, got the error %v", err)
+		}
+	})
+	t.Run("invalid limits", func(t *testing.T) {
+		if _, err := initStarlark(t, "", nil, false, common.ConfigStarlarkMaxStepsKey+`="0"`); err == nil {
+			t.Fatalf("expected an error for the step limit 0")
+		}
+		if _, err := initStarlark(t, "", nil, false, common.ConfigStarlarkTimeoutKey+`="forever"`); err == nil {
+			t.Fatalf("expected an error for the invalid timeout")
+		}
+		if _, err := initStarlark(t, "", nil, false, common.ConfigStarlarkMaxHeapKey+`="-1"`); err == nil {
+			t.Fatalf("expected an error for the invalid memory limit")
+		}
+	})
+}
+
+func TestStarlarkStopsWhenTheContextIsCancelled(t *testing.T) {
+	starlarkTransformer, err := initStarlark(t, loopingStarFunction+"def directory_detect(dir):\n    loop()\n    return {}\n", nil, false)
+	if err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if _, err := starlarkTransformer.DirectoryDetectWithContext(ctx, t.TempDir()); err == nil || !strings.Contains(err.Error(), "the call was stopped") {
+		t.Fatalf("expected the script to be stopped by the context, got the error %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > defaultStarTimeout/2 {
+		t.Fatalf("expected the script to be stopped soon after the context was cancelled, it ran for %s", elapsed)
+	}
+	if _, _, err := starlarkTransformer.TransformWithContext(ctx, nil, nil); err == nil {
+		t.Fatalf("expected the transform not to run with a cancelled context")
+	}
+}
+
+func TestStarlarkSandboxCustomizations(t *testing.T) {
+	testCases := []struct {
+		name        string
+		customized  bool
+		configs     []string
+		wantWrite   bool
+		wantNetwork bool
+	}{
+		{name: "built-in transformer", wantWrite: true, wantNetwork: true},
+		{name: "untrusted customization", customized: true},
+		{name: "trusted customization", customized: true, configs: []string{common.ConfigStarlarkTrustCustomizationsKey + "=true"}, wantWrite: true, wantNetwork: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			starlarkTransformer, err := initStarlark(t, `can_write = hasattr(fs, "`+fsWriteFnName+`")`, nil, testCase.customized, testCase.configs...)
+			if err != nil {
+				t.Fatalf("failed to initialize the transformer. Error: %q", err)
+			}
+			if got := starlarkTransformer.StarGlobals["can_write"] == starlark.True; got != testCase.wantWrite {
+				t.Fatalf("got fs.write available %t, want %t", got, testCase.wantWrite)
+			}
+			// the script fails to compile when the xlsx module is not defined
+			if _, err := initStarlark(t, "spreadsheets = xlsx", nil, testCase.customized, testCase.configs...); (err == nil) != testCase.wantNetwork {
+				t.Fatalf("got xlsx available %t, want %t. Error: %v", err == nil, testCase.wantNetwork, err)
+			}
+		})
+	}
+
+	t.Run("untrusted customization can not write files", func(t *testing.T) {
+		_, err := initStarlark(t, `fs.write(output_dir + "/written.txt", "data")`, nil, true)
+		if err == nil {
+			t.Fatalf("expected the untrusted customization to fail to write the file")
+		}
+	})
+}
@@ -0,0 +1,121 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+)
+
+// ResourceSelector selects the kubernetes resources a Starlark transformer is interested in.
+// The empty fields match every resource. The name can be a glob pattern like "web-*".
+type ResourceSelector struct {
+	APIVersion string            `yaml:"apiVersion,omitempty"`
+	Kind       string            `yaml:"kind,omitempty"`
+	Name       string            `yaml:"name,omitempty"`
+	Labels     map[string]string `yaml:"labels,omitempty"`
+}
+
+// SelectedResource is a kubernetes resource matching the selectors along with the file containing it
+type SelectedResource struct {
+	Path     string                 `yaml:"path"`
+	Resource map[string]interface{} `yaml:"resource"`
+}
+
+// matches returns true if the resource matches all the fields of the selector
+func (s ResourceSelector) matches(resource map[string]interface{}) bool {
+	if s.APIVersion != "" && cast.ToString(resource["apiVersion"]) != s.APIVersion {
+		return false
+	}
+	if s.Kind != "" && cast.ToString(resource["kind"]) != s.Kind {
+		return false
+	}
+	metadata := cast.ToStringMap(resource["metadata"])
+	if s.Name != "" {
+		if matched, err := filepath.Match(s.Name, cast.ToString(metadata["name"])); err != nil || !matched {
+			return false
+		}
+	}
+	labels := cast.ToStringMapString(metadata["labels"])
+	for key, value := range s.Labels {
+		if actual, ok := labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+	return true
+}
+
+// getSelectedResources returns the resources, in the yaml files in the paths of the artifacts, which match any of the selectors
+func getSelectedResources(selectors []ResourceSelector, artifacts []transformertypes.Artifact) []SelectedResource {
+	yamlPaths := []string{}
+	for _, a := range artifacts {
+		for _, paths := range a.Paths {
+			for _, path := range paths {
+				fi, err := os.Stat(path)
+				if err != nil {
+					continue
+				}
+				if !fi.IsDir() {
+					if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+						yamlPaths = common.AppendIfNotPresent(yamlPaths, path)
+					}
+					continue
+				}
+				files, err := common.GetFilesByExt(path, []string{".yaml", ".yml"})
+				if err != nil {
+					logrus.Debugf("failed to get the yaml files in the directory %s . Error: %q", path, err)
+					continue
+				}
+				yamlPaths = common.AppendIfNotPresent(yamlPaths, files...)
+			}
+		}
+	}
+	sort.Strings(yamlPaths)
+	selected := []SelectedResource{}
+	for _, yamlPath := range yamlPaths {
+		data, err := os.ReadFile(yamlPath)
+		if err != nil {
+			logrus.Debugf("failed to read the yaml file at path %s . Error: %q", yamlPath, err)
+			continue
+		}
+		docs, err := common.SplitYAML(data)
+		if err != nil {
+			logrus.Debugf("failed to split the yaml file at path %s into documents. Error: %q", yamlPath, err)
+			continue
+		}
+		for _, doc := range docs {
+			resource := map[string]interface{}{}
+			if err := yaml.Unmarshal(doc, &resource); err != nil || resource["kind"] == nil {
+				continue
+			}
+			for _, selector := range selectors {
+				if selector.matches(resource) {
+					selected = append(selected, SelectedResource{Path: yamlPath, Resource: resource})
+					break
+				}
+			}
+		}
+	}
+	return selected
+}
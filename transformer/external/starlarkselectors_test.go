@@ -0,0 +1,88 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package external
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+func TestStarlarkResourceSelectors(t *testing.T) {
+	yamlsDir := t.TempDir()
+	for fileName, contents := range map[string]string{
+		"web-deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web-frontend\n  labels:\n    tier: web\n",
+		"resources.yml":       "apiVersion: v1\nkind: Service\nmetadata:\n  name: web-frontend\n---\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: api\n  labels:\n    tier: backend\n",
+		"notes.txt":           "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: web-notes\n",
+	} {
+		if err := os.WriteFile(filepath.Join(yamlsDir, fileName), []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write the yaml %s . Error: %q", fileName, err)
+		}
+	}
+	newArtifacts := []transformertypes.Artifact{{Name: "yamls", Paths: map[transformertypes.PathType][]string{"KubernetesYamls": {yamlsDir}}}}
+	testCases := []struct {
+		name      string
+		selectors []ResourceSelector
+		want      []string
+	}{
+		{name: "kind", selectors: []ResourceSelector{{Kind: "Deployment"}}, want: []string{"api", "web-frontend"}},
+		{name: "api version and name pattern", selectors: []ResourceSelector{{APIVersion: "v1", Name: "web-*"}}, want: []string{"web-frontend"}},
+		{name: "labels", selectors: []ResourceSelector{{Labels: map[string]string{"tier": "web"}}}, want: []string{"web-frontend"}},
+		{name: "any of the selectors", selectors: []ResourceSelector{{Kind: "Service"}, {Labels: map[string]string{"tier": "backend"}}}, want: []string{"web-frontend", "api"}},
+		{name: "no match", selectors: []ResourceSelector{{Kind: "ConfigMap"}}, want: []string{}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			names := []string{}
+			for _, resource := range getSelectedResources(testCase.selectors, newArtifacts) {
+				names = append(names, resource.Resource["metadata"].(map[string]interface{})["name"].(string))
+			}
+			if diff := cmp.Diff(testCase.want, names); diff != "" {
+				t.Fatalf("got the wrong resources. Diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("the transformer is skipped without matching resources", func(t *testing.T) {
+		starlarkTransformer, err := initStarlark(t, "", map[string]interface{}{"resourceSelectors": []interface{}{map[string]interface{}{"kind": "ConfigMap"}}}, false)
+		if err != nil {
+			t.Fatalf("failed to initialize the transformer. Error: %q", err)
+		}
+		pathMappings, createdArtifacts, err := starlarkTransformer.Transform(newArtifacts, nil)
+		if err != nil || len(pathMappings) != 0 || len(createdArtifacts) != 0 {
+			t.Fatalf("expected the transformer to be skipped. Path mappings: %+v Artifacts: %+v Error: %v", pathMappings, createdArtifacts, err)
+		}
+	})
+
+	t.Run("the matching resources are available to the script", func(t *testing.T) {
+		starlarkTransformer, err := initStarlark(t, contextStarFunctions, nil, false)
+		if err != nil {
+			t.Fatalf("failed to initialize the transformer. Error: %q", err)
+		}
+		starlarkTransformer.resources = getSelectedResources([]ResourceSelector{{Kind: "Deployment"}}, newArtifacts)
+		got, err := callStarlarkFunction(t, starlarkTransformer, "inspect", newArtifacts)
+		if err != nil {
+			t.Fatalf("failed to call the function. Error: %q", err)
+		}
+		if diff := cmp.Diff([]interface{}{"api", "web-frontend"}, got.(map[string]interface{})["resources"]); diff != "" {
+			t.Fatalf("got the wrong resources. Diff (-want +got):\n%s", diff)
+		}
+	})
+}
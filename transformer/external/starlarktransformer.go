@@ -17,6 +17,7 @@
 package external
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io/fs"
@@ -30,9 +31,12 @@ import (
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
 	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes"
 	"github.com/konveyor/move2kube/types"
+	irtypes "github.com/konveyor/move2kube/types/ir"
 	qatypes "github.com/konveyor/move2kube/types/qaengine"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
 	"github.com/qri-io/starlib"
 	starutil "github.com/qri-io/starlib/util"
 	"github.com/sirupsen/logrus"
@@ -44,6 +48,9 @@ import (
 const (
 	directoryDetectFnName = "directory_detect"
 	transformFnName       = "transform"
+	questionsFnName       = "questions"
+	// questionValidationKey is the key in the question objects with the name of the validation function
+	questionValidationKey = "validation"
 
 	sourceDirVarName         = "source_dir"
 	contextDirVarName        = "context_dir"
@@ -75,6 +82,14 @@ const (
 	// archival functions
 	archTarGZipStrFnName = "arch_tar_gzip_str"
 	archTarStrFnName     = "arch_tar_str"
+	// context functions
+	ctxProjectFnName   = "project"
+	ctxSourceDirFnName = "source_dir"
+	ctxServicesFnName  = "services"
+	ctxClusterFnName   = "cluster"
+	ctxIRFnName        = "ir"
+	ctxAnswersFnName   = "answers"
+	ctxResourcesFnName = "resources"
 )
 
 // Starlark implements transformer interface and is used to write simple external transformers
@@ -87,11 +102,23 @@ type Starlark struct {
 
 	detectFn    *starlark.Function
 	transformFn *starlark.Function
+	questionsFn *starlark.Function
+	// registeredAnswers stores the answers of the questions returned by the questions function, by the id of the question
+	registeredAnswers map[string]interface{}
+	// artifacts stores the new and already seen artifacts of the current transform call, used by the context module
+	artifacts []transformertypes.Artifact
+	// resources stores the resources in the new artifacts matching the resource selectors
+	resources []SelectedResource
+	// sandbox stores the limits of the execution of the script
+	sandbox starSandbox
 }
 
 // StarYamlConfig defines yaml config for Starlark transformers
 type StarYamlConfig struct {
 	StarFile string `yaml:"starFile"`
+	// ResourceSelectors restrict the transformer to the artifacts containing matching kubernetes resources.
+	// The matching resources are available to the script through context.resources()
+	ResourceSelectors []ResourceSelector `yaml:"resourceSelectors,omitempty"`
 }
 
 // Init Initializes the transformer
@@ -103,13 +130,16 @@ func (t *Starlark) Init(tc transformertypes.Transformer, env *environment.Enviro
 	if err != nil {
 		return fmt.Errorf("failed to load config for Transformer %+v into %T . Error: %w", t.Config.Spec.Config, t.StarConfig, err)
 	}
+	if t.sandbox, err = getStarSandbox(tc.Spec.TransformerYamlPath); err != nil {
+		return fmt.Errorf("invalid sandbox config for the transformer %s . Error: %w", tc.Name, err)
+	}
 	t.StarThread = &starlark.Thread{Name: tc.Name}
 	t.setDefaultGlobals()
 	tcmapobj, err := common.GetMapInterfaceFromObj(tc)
 	if err != nil {
 		return fmt.Errorf("failed to convert transformer config to map[string]interface{}. Error: %w", err)
 	}
-	t.StarGlobals[env.ProjectName], err = starutil.Marshal(env.ProjectName)
+	t.StarGlobals[projectVarName], err = starutil.Marshal(env.ProjectName)
 	if err != nil {
 		return fmt.Errorf("failed to load transformer config. Error: %w", err)
 	}
@@ -142,7 +172,10 @@ func (t *Starlark) Init(tc transformertypes.Transformer, env *environment.Enviro
 		return fmt.Errorf("failed to load source. Error: %w", err)
 	}
 	starlarkFilePath := filepath.Join(t.Env.GetEnvironmentContext(), t.StarConfig.StarFile)
-	t.StarGlobals, err = starlark.ExecFile(t.StarThread, starlarkFilePath, nil, t.StarGlobals)
+	err = t.runSandboxed(context.Background(), func(thread *starlark.Thread) (err error) {
+		t.StarGlobals, err = starlark.ExecFile(thread, starlarkFilePath, nil, t.StarGlobals)
+		return err
+	})
 	if err != nil {
 		if t.StarConfig.StarFile == "" {
 			err = fmt.Errorf("no starlark file specified. Error: %w", err)
@@ -154,6 +187,9 @@ func (t *Starlark) Init(tc transformertypes.Transformer, env *environment.Enviro
 	if err := t.loadFunctions(); err != nil {
 		return fmt.Errorf("failed to load the required functions. Error: %w", err)
 	}
+	if err := t.askRegisteredQuestions(); err != nil {
+		return fmt.Errorf("failed to ask the questions of the transformer %s . Error: %w", tc.Name, err)
+	}
 	return nil
 }
 
@@ -164,7 +200,12 @@ func (t *Starlark) GetConfig() (transformertypes.Transformer, *environment.Envir
 
 // DirectoryDetect runs detect in each sub directory
 func (t *Starlark) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
-	return t.executeDetect(t.detectFn, dir)
+	return t.DirectoryDetectWithContext(context.Background(), dir)
+}
+
+// DirectoryDetectWithContext runs detect in each sub directory and stops the script when the context is cancelled
+func (t *Starlark) DirectoryDetectWithContext(ctx context.Context, dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return t.executeDetect(ctx, t.detectFn, dir)
 }
 
 // Transform transforms the artifacts
@@ -172,6 +213,25 @@ func (t *Starlark) Transform(
 	newArtifacts []transformertypes.Artifact,
 	alreadySeenArtifacts []transformertypes.Artifact,
 ) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	return t.TransformWithContext(context.Background(), newArtifacts, alreadySeenArtifacts)
+}
+
+// TransformWithContext transforms the artifacts and stops the script when the context is cancelled
+func (t *Starlark) TransformWithContext(
+	ctx context.Context,
+	newArtifacts []transformertypes.Artifact,
+	alreadySeenArtifacts []transformertypes.Artifact,
+) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	t.artifacts = append(append([]transformertypes.Artifact{}, newArtifacts...), alreadySeenArtifacts...)
+	t.resources = nil
+	defer func() { t.artifacts, t.resources = nil, nil }()
+	if len(t.StarConfig.ResourceSelectors) != 0 {
+		t.resources = getSelectedResources(t.StarConfig.ResourceSelectors, newArtifacts)
+		if len(t.resources) == 0 {
+			logrus.Debugf("Skipping the transformer %s since none of the resources in the artifacts match its selectors", t.Config.Name)
+			return nil, nil, nil
+		}
+	}
 	naObj, err := common.GetMapInterfaceFromObj(newArtifacts)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to convert new artifacts to map[string]interface{} . Error: %w", err)
@@ -188,7 +248,11 @@ func (t *Starlark) Transform(
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to marshal already seen artifacts %+v to starlark value. Error: %w", alreadySeenArtifacts, err)
 	}
-	val, err := starlark.Call(t.StarThread, t.transformFn, starlark.Tuple{starNewArtifacts, starOldArtifacts}, nil)
+	var val starlark.Value
+	err = t.runSandboxed(ctx, func(thread *starlark.Thread) (err error) {
+		val, err = starlark.Call(thread, t.transformFn, starlark.Tuple{starNewArtifacts, starOldArtifacts}, nil)
+		return err
+	})
 	if err != nil {
 		switch err := err.(type) {
 		case *starlark.EvalError:
@@ -208,7 +272,7 @@ func (t *Starlark) Transform(
 	return transformOutput.PathMappings, transformOutput.CreatedArtifacts, nil
 }
 
-func (t *Starlark) executeDetect(fn *starlark.Function, dir string) (services map[string][]transformertypes.Artifact, err error) {
+func (t *Starlark) executeDetect(ctx context.Context, fn *starlark.Function, dir string) (services map[string][]transformertypes.Artifact, err error) {
 	if fn == nil {
 		return nil, nil
 	}
@@ -217,7 +281,11 @@ func (t *Starlark) executeDetect(fn *starlark.Function, dir string) (services ma
 		logrus.Errorf("Unable to convert %s to starlark value : %s", dir, err)
 		return nil, err
 	}
-	val, err := starlark.Call(t.StarThread, fn, starlark.Tuple{starDir}, nil)
+	var val starlark.Value
+	err = t.runSandboxed(ctx, func(thread *starlark.Thread) (err error) {
+		val, err = starlark.Call(thread, fn, starlark.Tuple{starDir}, nil)
+		return err
+	})
 	if err != nil {
 		logrus.Errorf("Unable to execute starlark function : %s", err)
 		return nil, err
@@ -237,7 +305,7 @@ func (t *Starlark) executeDetect(fn *starlark.Function, dir string) (services ma
 }
 
 func (t *Starlark) getStarlarkQuery() *starlark.Builtin {
-	return starlark.NewBuiltin(qaFnName, func(_ *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	return starlark.NewBuiltin(qaFnName, func(thread *starlark.Thread, _ *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
 		argDictValue := &starlark.Dict{}
 		var validation string
 		if err := starlark.UnpackPositionalArgs(qaFnName, args, kwargs, 1, &argDictValue, &validation); err != nil {
@@ -247,90 +315,150 @@ func (t *Starlark) getStarlarkQuery() *starlark.Builtin {
 		if err != nil {
 			return starlark.None, fmt.Errorf("failed to unmarshal the argument provided to '%s'. Expected a single dict argument. Error: %q", qaFnName, err)
 		}
-		prob := qatypes.Problem{}
-		if err := common.GetObjFromInterface(argI, &prob); err != nil {
-			return starlark.None, fmt.Errorf("failed to get the qa problem of type %T from the object of type %T and value %+v . Error: %w", prob, argI, argI, err)
-		}
-		// key
-		if prob.ID == "" {
-			return starlark.None, fmt.Errorf("the key 'id' is missing from the question object %+v", argI)
-		}
-		if !strings.HasPrefix(prob.ID, common.BaseKey) {
-			prob.ID = common.JoinQASubKeys(common.BaseKey, prob.ID)
-		}
-		// type
-		if prob.Type == "" {
-			prob.Type = qatypes.InputSolutionFormType
+		prob, err := t.getProblem(getThreadContext(thread), argI, validation)
+		if err != nil {
+			return starlark.None, err
 		}
-		if validation != "" {
-			validationFn, ok := t.StarGlobals[validation]
-			if !ok {
-				return starlark.None, fmt.Errorf("provided validation function not found : %s", validation)
-			}
-			fn, ok := validationFn.(*starlark.Function)
-			if !ok {
-				return starlark.None, fmt.Errorf("%s is not a function", validationFn)
-			}
-			prob.Validator = func(ans interface{}) error {
-				answer, err := starutil.Marshal(ans)
-				if err != nil {
-					return fmt.Errorf("unable to convert %s to starlark value : %s", ans, err)
-				}
-				val, err := starlark.Call(t.StarThread, fn, starlark.Tuple{answer}, nil)
-				if err != nil {
-					return fmt.Errorf("unable to execute the starlark function: Error : %s", err)
-				}
-				value, err := starutil.Unmarshal(val)
-				if err != nil {
-					return fmt.Errorf("unable to unmarshal starlark function result : %s", err)
-				}
-				// if empty string is returned then we assume validation is successful
-				if value.(string) != "" {
-					return fmt.Errorf("validation failed : %s", value.(string))
-				}
-				return nil
-			}
+		// the questions registered by the script were already asked along with the other questions
+		if answer, ok := t.registeredAnswers[prob.ID]; ok {
+			return marshalAnswer(answer)
 		}
-
 		resolved, err := qaengine.FetchAnswer(prob)
 		if err != nil {
-			logrus.Fatalf("failed to ask the question. Error: %q", err)
+			return starlark.None, fmt.Errorf("failed to ask the question. Error: %w", err)
 		}
+		return marshalAnswer(resolved.Answer)
+	})
+}
 
-		var answerValue starlark.Value
-		if ansList, ok := resolved.Answer.([]string); ok {
-			var result []interface{}
-			for _, ans := range ansList {
-				result = append(result, ans)
+// getProblem returns the question described by the object, the validation is the name of a starlark function
+// which is stopped when the context is cancelled
+func (t *Starlark) getProblem(ctx context.Context, argI interface{}, validation string) (qatypes.Problem, error) {
+	prob := qatypes.Problem{}
+	if err := common.GetObjFromInterface(argI, &prob); err != nil {
+		return prob, fmt.Errorf("failed to get the qa problem of type %T from the object of type %T and value %+v . Error: %w", prob, argI, argI, err)
+	}
+	// key
+	if prob.ID == "" {
+		return prob, fmt.Errorf("the key 'id' is missing from the question object %+v", argI)
+	}
+	if !strings.HasPrefix(prob.ID, common.BaseKey) {
+		prob.ID = common.JoinQASubKeys(common.BaseKey, prob.ID)
+	}
+	// type
+	if prob.Type == "" {
+		prob.Type = qatypes.InputSolutionFormType
+	}
+	if validation != "" {
+		validationFn, ok := t.StarGlobals[validation]
+		if !ok {
+			return prob, fmt.Errorf("provided validation function not found : %s", validation)
+		}
+		fn, ok := validationFn.(*starlark.Function)
+		if !ok {
+			return prob, fmt.Errorf("%s is not a function", validationFn)
+		}
+		prob.Validator = func(ans interface{}) error {
+			answer, err := starutil.Marshal(ans)
+			if err != nil {
+				return fmt.Errorf("unable to convert %s to starlark value : %s", ans, err)
 			}
-			answerValue, err = starutil.Marshal(result)
+			var val starlark.Value
+			err = t.runSandboxed(ctx, func(thread *starlark.Thread) (err error) {
+				val, err = starlark.Call(thread, fn, starlark.Tuple{answer}, nil)
+				return err
+			})
 			if err != nil {
-				return starlark.None, fmt.Errorf("failed to marshal the answer %+v of type %T into a starlark value. Error: %q", resolved.Answer, resolved.Answer, err)
+				return fmt.Errorf("unable to execute the starlark function: Error : %s", err)
 			}
-		} else {
-			answerValue, err = starutil.Marshal(resolved.Answer)
+			value, err := starutil.Unmarshal(val)
 			if err != nil {
-				return starlark.None, fmt.Errorf("failed to marshal the answer %+v of type %T into a starlark value. Error: %q", resolved.Answer, resolved.Answer, err)
+				return fmt.Errorf("unable to unmarshal starlark function result : %s", err)
 			}
+			// if empty string is returned then we assume validation is successful
+			if value.(string) != "" {
+				return fmt.Errorf("validation failed : %s", value.(string))
+			}
+			return nil
+		}
+	}
+	return prob, nil
+}
+
+// marshalAnswer converts the answer of a question into a starlark value
+func marshalAnswer(answer interface{}) (starlark.Value, error) {
+	var answerValue starlark.Value
+	var err error
+	if ansList, ok := answer.([]string); ok {
+		var result []interface{}
+		for _, ans := range ansList {
+			result = append(result, ans)
 		}
-		return answerValue, err
+		answerValue, err = starutil.Marshal(result)
+	} else {
+		answerValue, err = starutil.Marshal(answer)
+	}
+	if err != nil {
+		return starlark.None, fmt.Errorf("failed to marshal the answer %+v of type %T into a starlark value. Error: %q", answer, answer, err)
+	}
+	return answerValue, nil
+}
+
+// askRegisteredQuestions asks the questions returned by the questions function of the script,
+// so that they are asked along with the other questions and can be answered by the config files, instead of in the middle of the transformation
+func (t *Starlark) askRegisteredQuestions() error {
+	t.registeredAnswers = map[string]interface{}{}
+	if t.questionsFn == nil {
+		return nil
+	}
+	var val starlark.Value
+	err := t.runSandboxed(context.Background(), func(thread *starlark.Thread) (err error) {
+		val, err = starlark.Call(thread, t.questionsFn, nil, nil)
+		return err
 	})
+	if err != nil {
+		return fmt.Errorf("failed to call the starlark function '%s' . Error: %w", t.questionsFn.String(), err)
+	}
+	valI, err := starutil.Unmarshal(val)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal the starlark value back to Golang value. Error: %w", err)
+	}
+	questions := []map[string]interface{}{}
+	if err := common.GetObjFromInterface(valI, &questions); err != nil {
+		return fmt.Errorf("the function '%s' should return a list of question objects. Error: %w", t.questionsFn.String(), err)
+	}
+	for _, question := range questions {
+		prob, err := t.getProblem(context.Background(), question, cast.ToString(question[questionValidationKey]))
+		if err != nil {
+			return fmt.Errorf("invalid question returned by the function '%s' . Error: %w", t.questionsFn.String(), err)
+		}
+		resolved, err := qaengine.FetchAnswer(prob)
+		if err != nil {
+			return fmt.Errorf("failed to ask the question with the id %s . Error: %w", prob.ID, err)
+		}
+		t.registeredAnswers[prob.ID] = resolved.Answer
+	}
+	return nil
 }
 
 func (t *Starlark) setDefaultGlobals() {
 	t.StarGlobals = starlark.StringDict{}
-	t.addStarlibModules()
-	t.addFSModules()
+	t.addStarlibModules(t.sandbox.allowNetwork)
+	t.addFSModules(t.sandbox.allowFSWrite)
 	t.addAppModules()
 	t.addCryptoModules()
 	t.addArchiveModules()
+	t.addContextModules()
 }
 
-func (t *Starlark) addStarlibModules() {
+func (t *Starlark) addStarlibModules(allowNetwork bool) {
 	t.addModules("encoding/json")
 	t.addModules("math")
 	t.addModules("time")
-	t.addModules("xlsx")
+	if allowNetwork {
+		// xlsx can fetch the spreadsheets from urls
+		t.addModules("xlsx")
+	}
 	t.addModules("html")
 	t.addModules("bsoup")
 	t.addModules("zipfile")
@@ -342,8 +470,8 @@ func (t *Starlark) addStarlibModules() {
 	t.addModules("hash")
 }
 
-func (t *Starlark) addFSModules() {
-	t.StarGlobals["fs"] = &starlarkstruct.Module{
+func (t *Starlark) addFSModules(allowWrite bool) {
+	fsModule := &starlarkstruct.Module{
 		Name: "fs",
 		Members: starlark.StringDict{
 			fsExistsFnName:               t.getStarlarkFSExists(),
@@ -352,13 +480,16 @@ func (t *Starlark) addFSModules() {
 			fsIsDirFnName:                t.getStarlarkFSIsDir(),
 			fsGetFilesWithPatternFnName:  t.getStarlarkFSGetFilesWithPattern(),
 			fsPathJoinFnName:             t.getStarlarkFSPathJoin(),
-			fsWriteFnName:                t.getStarlarkFSWrite(),
 			fsGetYamIsWithTypeMetaFnName: t.getStarlarkFSGetYamlsWithTypeMeta(),
 			fsPathBaseFnName:             t.getStarlarkFSPathBase(),
 			fsPathRelFnName:              t.getStarlarkFSPathRel(),
 			fsFindXmlPathFnName:          t.getStarlarkFindXmlPath(),
 		},
 	}
+	if allowWrite {
+		fsModule.Members[fsWriteFnName] = t.getStarlarkFSWrite()
+	}
+	t.StarGlobals["fs"] = fsModule
 }
 
 func (t *Starlark) addCryptoModules() {
@@ -381,6 +512,21 @@ func (t *Starlark) addArchiveModules() {
 	}
 }
 
+func (t *Starlark) addContextModules() {
+	t.StarGlobals["context"] = &starlarkstruct.Module{
+		Name: "context",
+		Members: starlark.StringDict{
+			ctxProjectFnName:   t.getStarlarkContextProject(),
+			ctxSourceDirFnName: t.getStarlarkContextSourceDir(),
+			ctxServicesFnName:  t.getStarlarkContextServices(),
+			ctxClusterFnName:   t.getStarlarkContextCluster(),
+			ctxIRFnName:        t.getStarlarkContextIR(),
+			ctxAnswersFnName:   t.getStarlarkContextAnswers(),
+			ctxResourcesFnName: t.getStarlarkContextResources(),
+		},
+	}
+}
+
 func (t *Starlark) addAppModules() {
 	t.StarGlobals[types.AppNameShort] = &starlarkstruct.Module{
 		Name: types.AppNameShort,
@@ -408,6 +554,9 @@ func (t *Starlark) loadFunctions() error {
 	if err := t.loadTransformFn(); err != nil {
 		return fmt.Errorf("failed to load transform function. Error: %w", err)
 	}
+	if err := t.loadQuestionsFn(); err != nil {
+		return fmt.Errorf("failed to load questions function. Error: %w", err)
+	}
 	return nil
 }
 
@@ -427,6 +576,22 @@ func (t *Starlark) loadDetectFn() (err error) {
 	return nil
 }
 
+func (t *Starlark) loadQuestionsFn() (err error) {
+	if !t.StarGlobals.Has(questionsFnName) {
+		return nil
+	}
+	questionsFn := t.StarGlobals[questionsFnName]
+	fn, ok := questionsFn.(*starlark.Function)
+	if !ok {
+		return fmt.Errorf("%s is not a function", questionsFn)
+	}
+	if fn.NumParams() != 0 {
+		return fmt.Errorf("%s does not have the required number of paramters. It has %d, expected %d", questionsFn, fn.NumParams(), 0)
+	}
+	t.questionsFn = fn
+	return nil
+}
+
 func (t *Starlark) loadTransformFn() (err error) {
 	if !t.StarGlobals.Has(transformFnName) {
 		return fmt.Errorf("no %s function found", transformFnName)
@@ -738,3 +903,121 @@ func (t *Starlark) getStarlarkArchTarStr() *starlark.Builtin {
 		return starlark.String(common.CreateTarArchiveNoCompressionStringWrapper(srcDir)), nil
 	})
 }
+
+func (t *Starlark) getStarlarkContextProject() *starlark.Builtin {
+	return starlark.NewBuiltin(ctxProjectFnName, func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackPositionalArgs(ctxProjectFnName, args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		return starlark.String(t.Env.ProjectName), nil
+	})
+}
+
+func (t *Starlark) getStarlarkContextSourceDir() *starlark.Builtin {
+	return starlark.NewBuiltin(ctxSourceDirFnName, func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackPositionalArgs(ctxSourceDirFnName, args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		return starlark.String(t.Env.GetEnvironmentSource()), nil
+	})
+}
+
+// getStarlarkContextServices returns the name, the artifact type and the paths of each service in the artifacts being transformed
+func (t *Starlark) getStarlarkContextServices() *starlark.Builtin {
+	return starlark.NewBuiltin(ctxServicesFnName, func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackPositionalArgs(ctxServicesFnName, args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		services := []interface{}{}
+		for _, a := range t.artifacts {
+			serviceConfig := artifacts.ServiceConfig{}
+			if err := a.GetConfig(artifacts.ServiceConfigType, &serviceConfig); err != nil {
+				continue
+			}
+			paths, err := common.GetMapInterfaceFromObj(a.Paths)
+			if err != nil {
+				return starlark.None, fmt.Errorf("failed to convert the paths of the service %s to map[string]interface{} . Error: %w", serviceConfig.ServiceName, err)
+			}
+			services = append(services, map[string]interface{}{
+				"name":  serviceConfig.ServiceName,
+				"type":  string(a.Type),
+				"paths": paths,
+			})
+		}
+		return marshalReadOnly(services)
+	})
+}
+
+func (t *Starlark) getStarlarkContextCluster() *starlark.Builtin {
+	return starlark.NewBuiltin(ctxClusterFnName, func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackPositionalArgs(ctxClusterFnName, args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		return t.getArtifactConfig(kubernetes.ClusterMetadata)
+	})
+}
+
+func (t *Starlark) getStarlarkContextIR() *starlark.Builtin {
+	return starlark.NewBuiltin(ctxIRFnName, func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackPositionalArgs(ctxIRFnName, args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		return t.getArtifactConfig(irtypes.IRConfigType)
+	})
+}
+
+func (t *Starlark) getStarlarkContextAnswers() *starlark.Builtin {
+	return starlark.NewBuiltin(ctxAnswersFnName, func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackPositionalArgs(ctxAnswersFnName, args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		answers, err := common.GetMapInterfaceFromObj(qaengine.GetAnswers())
+		if err != nil {
+			return starlark.None, fmt.Errorf("failed to convert the answers to map[string]interface{} . Error: %w", err)
+		}
+		return marshalReadOnly(answers)
+	})
+}
+
+// getStarlarkContextResources returns the resources matching the resource selectors in the config of the transformer
+func (t *Starlark) getStarlarkContextResources() *starlark.Builtin {
+	return starlark.NewBuiltin(ctxResourcesFnName, func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if err := starlark.UnpackPositionalArgs(ctxResourcesFnName, args, kwargs, 0); err != nil {
+			return nil, err
+		}
+		resources := []interface{}{}
+		for _, resource := range t.resources {
+			resources = append(resources, map[string]interface{}{
+				"path":     resource.Path,
+				"resource": resource.Resource,
+			})
+		}
+		return marshalReadOnly(resources)
+	})
+}
+
+// getArtifactConfig returns the first config of the type in the artifacts being transformed, or None if there is none
+func (t *Starlark) getArtifactConfig(configType transformertypes.ConfigType) (starlark.Value, error) {
+	for _, a := range t.artifacts {
+		config, ok := a.Configs[configType]
+		if !ok {
+			continue
+		}
+		configObj, err := common.GetMapInterfaceFromObj(config)
+		if err != nil {
+			return starlark.None, fmt.Errorf("failed to convert the config of type %s to map[string]interface{} . Error: %w", configType, err)
+		}
+		return marshalReadOnly(configObj)
+	}
+	return starlark.None, nil
+}
+
+// marshalReadOnly converts the value into a frozen starlark value, so that the scripts can not modify the context
+func marshalReadOnly(value interface{}) (starlark.Value, error) {
+	starValue, err := starutil.Marshal(value)
+	if err != nil {
+		return starlark.None, fmt.Errorf("failed to marshal the value %+v into a starlark value. Error: %w", value, err)
+	}
+	starValue.Freeze()
+	return starValue, nil
+}
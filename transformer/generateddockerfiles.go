@@ -0,0 +1,121 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+)
+
+// dockerfileFromRegex matches the FROM instructions of a Dockerfile, capturing the base image and the stage name
+var dockerfileFromRegex = regexp.MustCompile(`(?i)^(\s*FROM\s+(?:--\S+\s+)*)(\S+)(\s+AS\s+(\S+))?\s*$`)
+
+// rewriteGeneratedDockerfiles makes the Dockerfiles generated from the templates pull their base images from the mirrors
+// and declare the proxies of the builds in each stage. The Dockerfiles which are already rewritten are left unchanged.
+func rewriteGeneratedDockerfiles(templatesPath, destPath string) error {
+	dockerfilePaths := []string{}
+	if err := filepath.WalkDir(templatesPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasPrefix(d.Name(), common.DefaultDockerfileName) {
+			return nil
+		}
+		relPath, err := filepath.Rel(templatesPath, path)
+		if err != nil {
+			return nil
+		}
+		dockerfilePath := filepath.Join(destPath, relPath)
+		if _, err := os.Stat(dockerfilePath); err == nil {
+			dockerfilePaths = append(dockerfilePaths, dockerfilePath)
+		}
+		return nil
+	}); err != nil {
+		return fmt.Errorf("failed to look for the Dockerfile templates in the directory %s . Error: %w", templatesPath, err)
+	}
+	if len(dockerfilePaths) == 0 {
+		return nil
+	}
+	mirrors := commonqa.BaseImageMirrors()
+	proxyArgs := []string{}
+	for proxyArg := range commonqa.BuildProxies() {
+		proxyArgs = append(proxyArgs, proxyArg)
+	}
+	sort.Strings(proxyArgs)
+	if len(mirrors) == 0 && len(proxyArgs) == 0 {
+		return nil
+	}
+	for _, dockerfilePath := range dockerfilePaths {
+		fi, err := os.Stat(dockerfilePath)
+		if err != nil {
+			return fmt.Errorf("failed to stat the Dockerfile at path %s . Error: %w", dockerfilePath, err)
+		}
+		contents, err := os.ReadFile(dockerfilePath)
+		if err != nil {
+			return fmt.Errorf("failed to read the Dockerfile at path %s . Error: %w", dockerfilePath, err)
+		}
+		rewrittenContents := rewriteDockerfile(string(contents), mirrors, proxyArgs)
+		if rewrittenContents == string(contents) {
+			continue
+		}
+		if err := os.WriteFile(dockerfilePath, []byte(rewrittenContents), fi.Mode()); err != nil {
+			return fmt.Errorf("failed to write the Dockerfile at path %s . Error: %w", dockerfilePath, err)
+		}
+		logrus.Debugf("Rewrote the base images and the proxies of the generated Dockerfile %s", dockerfilePath)
+	}
+	return nil
+}
+
+// rewriteDockerfile replaces the base images of the Dockerfile with their mirrors and declares the proxy args after each FROM
+func rewriteDockerfile(contents string, mirrors map[string]string, proxyArgs []string) string {
+	lines := strings.Split(contents, "\n")
+	rewrittenLines := []string{}
+	stageNames := map[string]bool{}
+	for i, line := range lines {
+		match := dockerfileFromRegex.FindStringSubmatch(line)
+		if match == nil {
+			rewrittenLines = append(rewrittenLines, line)
+			continue
+		}
+		image := match[2]
+		// the previous stages, the scratch image and the images given by build args are not pulled from a registry
+		if !stageNames[strings.ToLower(image)] && !strings.EqualFold(image, "scratch") && !strings.Contains(image, "$") {
+			image = common.MirrorImage(image, mirrors)
+		}
+		if match[4] != "" {
+			stageNames[strings.ToLower(match[4])] = true
+		}
+		rewrittenLines = append(rewrittenLines, match[1]+image+match[3])
+		nextLines := lines[i+1:]
+		if len(nextLines) > len(proxyArgs) {
+			nextLines = nextLines[:len(proxyArgs)]
+		}
+		for _, proxyArg := range proxyArgs {
+			proxyArgLine := "ARG " + proxyArg
+			if !common.IsPresent(nextLines, proxyArgLine) {
+				rewrittenLines = append(rewrittenLines, proxyArgLine)
+			}
+		}
+	}
+	return strings.Join(rewrittenLines, "\n")
+}
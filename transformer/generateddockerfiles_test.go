@@ -0,0 +1,108 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+)
+
+func TestRewriteDockerfile(t *testing.T) {
+	mirrors := map[string]string{"docker.io": "mirror.example.com/dockerhub"}
+	proxyArgs := []string{"HTTPS_PROXY", "HTTP_PROXY"}
+	dockerfile := `FROM golang:1.18 AS builder
+RUN go build -o /app .
+
+FROM --platform=linux/amd64 builder AS test
+RUN go test ./...
+
+FROM ${BASE_IMAGE}
+FROM scratch
+COPY --from=builder /app /app
+`
+	want := `FROM mirror.example.com/dockerhub/library/golang:1.18 AS builder
+ARG HTTPS_PROXY
+ARG HTTP_PROXY
+RUN go build -o /app .
+
+FROM --platform=linux/amd64 builder AS test
+ARG HTTPS_PROXY
+ARG HTTP_PROXY
+RUN go test ./...
+
+FROM ${BASE_IMAGE}
+ARG HTTPS_PROXY
+ARG HTTP_PROXY
+FROM scratch
+ARG HTTPS_PROXY
+ARG HTTP_PROXY
+COPY --from=builder /app /app
+`
+	got := rewriteDockerfile(dockerfile, mirrors, proxyArgs)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected Dockerfile. Diff (-want +got):\n%s", diff)
+	}
+	if rewritten := rewriteDockerfile(got, mirrors, proxyArgs); rewritten != got {
+		t.Fatalf("expected the rewritten Dockerfile to be left unchanged. Diff (-want +got):\n%s", cmp.Diff(got, rewritten))
+	}
+}
+
+func TestRewriteGeneratedDockerfiles(t *testing.T) {
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{
+		common.ConfigBuildProxyEnabledKey + `=false`,
+		common.ConfigBaseImageMirrorsKey + `="docker.io=mirror.example.com/dockerhub"`,
+	}, nil, nil, false)
+
+	templatesPath, destPath := t.TempDir(), t.TempDir()
+	for _, relPath := range []string{common.DefaultDockerfileName, "Dockerfile.build", "README.md"} {
+		if err := os.WriteFile(filepath.Join(templatesPath, relPath), []byte("FROM {{ .Image }}\n"), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the template %s . Error: %q", relPath, err)
+		}
+	}
+	// the rendered Dockerfile.build was not copied to the destination
+	for relPath, contents := range map[string]string{common.DefaultDockerfileName: "FROM node:18\n", "README.md": "FROM node:18\n"} {
+		if err := os.WriteFile(filepath.Join(destPath, relPath), []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", relPath, err)
+		}
+	}
+	if err := rewriteGeneratedDockerfiles(templatesPath, destPath); err != nil {
+		t.Fatalf("failed to rewrite the generated Dockerfiles. Error: %q", err)
+	}
+	for relPath, want := range map[string]string{
+		common.DefaultDockerfileName: "FROM mirror.example.com/dockerhub/library/node:18\n",
+		"README.md":                  "FROM node:18\n",
+	} {
+		contents, err := os.ReadFile(filepath.Join(destPath, relPath))
+		if err != nil {
+			t.Fatalf("failed to read the file %s . Error: %q", relPath, err)
+		}
+		if string(contents) != want {
+			t.Fatalf("unexpected contents of the file %s . Diff (-want +got):\n%s", relPath, cmp.Diff(want, string(contents)))
+		}
+	}
+	if _, err := os.Stat(filepath.Join(destPath, "Dockerfile.build")); !os.IsNotExist(err) {
+		t.Fatalf("expected the Dockerfile which was not generated not to be created. Error: %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultIRExportOutputPath = "ir"
+)
+
+// IRExporter implements Transformer interface.
+// It writes the IR to a versioned file, so that it can be archived or transformed later using the IRImporter.
+type IRExporter struct {
+	Config   transformertypes.Transformer
+	Env      *environment.Environment
+	IRConfig *IRExporterYamlConfig
+}
+
+// IRExporterYamlConfig stores the IR exporter related information
+type IRExporterYamlConfig struct {
+	OutputPath string `yaml:"outputPath"`
+}
+
+// Init initializes the transformer
+func (t *IRExporter) Init(tc transformertypes.Transformer, env *environment.Environment) error {
+	t.Config = tc
+	t.Env = env
+	t.IRConfig = &IRExporterYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.IRConfig); err != nil {
+		return fmt.Errorf("unable to load the config for the IRExporter transformer. Actual: %+v . Error: %q", t.Config.Spec.Config, err)
+	}
+	if t.IRConfig.OutputPath == "" {
+		t.IRConfig.OutputPath = defaultIRExportOutputPath
+	}
+	return nil
+}
+
+// GetConfig returns the config of the transformer
+func (t *IRExporter) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect does nothing since the transformer only consumes IR
+func (t *IRExporter) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform writes the IR of each artifact to a file
+func (t *IRExporter) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	pathMappings := []transformertypes.PathMapping{}
+	for _, newArtifact := range newArtifacts {
+		if newArtifact.Type != irtypes.IRArtifactType {
+			continue
+		}
+		var ir irtypes.IR
+		if err := newArtifact.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		if !qaengine.FetchBoolAnswer(common.ConfigExportIRKey, "Do you want to export the intermediate representation (IR)?", []string{"The exported IR can be transformed later, possibly on another machine, by running move2kube with the IR file as the source."}, false, nil) {
+			return nil, nil, nil
+		}
+		ir.Name = newArtifact.Name
+		fileName := common.MakeFileNameCompliant(newArtifact.Name) + ".yaml"
+		tempPath := filepath.Join(t.Env.TempPath, t.IRConfig.OutputPath, fileName)
+		if err := irtypes.WriteIRFile(tempPath, irtypes.NewEnhancedIRFromIR(ir)); err != nil {
+			logrus.Errorf("failed to export the IR %s . Error: %q", ir.Name, err)
+			continue
+		}
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:     transformertypes.DefaultPathMappingType,
+			SrcPath:  tempPath,
+			DestPath: filepath.Join(t.IRConfig.OutputPath, fileName),
+		})
+	}
+	return pathMappings, nil, nil
+}
@@ -0,0 +1,93 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+)
+
+// IRImporter implements Transformer interface.
+// It loads the IR files exported by the IRExporter, so that the transformation can continue from the exported IR.
+type IRImporter struct {
+	Config transformertypes.Transformer
+	Env    *environment.Environment
+}
+
+// Init initializes the transformer
+func (t *IRImporter) Init(tc transformertypes.Transformer, env *environment.Environment) error {
+	t.Config = tc
+	t.Env = env
+	return nil
+}
+
+// GetConfig returns the config of the transformer
+func (t *IRImporter) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect detects the exported IR files
+func (t *IRImporter) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	filePaths, err := common.GetFilesByExtInCurrDir(dir, []string{".yaml", ".yml", ".json"})
+	if err != nil {
+		logrus.Errorf("Unable to fetch yaml and json files at path %q Error: %q", dir, err)
+		return nil, err
+	}
+	services = map[string][]transformertypes.Artifact{}
+	for _, filePath := range filePaths {
+		ir, err := irtypes.ReadIRFile(filePath)
+		if err != nil {
+			logrus.Debugf("the file at path %s is not an IR file : %s", filePath, err)
+			continue
+		}
+		serviceName := common.MakeStringK8sServiceNameCompliant(ir.Name)
+		if serviceName == "" {
+			serviceName = t.Env.GetProjectName()
+		}
+		services[serviceName] = append(services[serviceName], transformertypes.Artifact{
+			Paths: map[transformertypes.PathType][]string{
+				artifacts.IRFilePathType:     {filePath},
+				artifacts.ServiceDirPathType: {dir},
+			},
+		})
+	}
+	return services, nil
+}
+
+// Transform loads the IR files
+func (t *IRImporter) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	createdArtifacts := []transformertypes.Artifact{}
+	for _, newArtifact := range newArtifacts {
+		for _, irFilePath := range newArtifact.Paths[artifacts.IRFilePathType] {
+			ir, err := irtypes.ReadIRFile(irFilePath)
+			if err != nil {
+				logrus.Errorf("failed to import the IR file at path %s . Error: %q", irFilePath, err)
+				continue
+			}
+			createdArtifacts = append(createdArtifacts, transformertypes.Artifact{
+				Name:    t.Env.GetProjectName(),
+				Type:    irtypes.IRArtifactType,
+				Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir.IR},
+			})
+		}
+	}
+	return nil, createdArtifacts, nil
+}
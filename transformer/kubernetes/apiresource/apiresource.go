@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"reflect"
 
+	jsonpatch "github.com/evanphx/json-patch"
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
 	"github.com/konveyor/move2kube/types"
@@ -28,6 +29,7 @@ import (
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
@@ -59,6 +61,7 @@ func (o *APIResource) convertIRToObjects(ir irtypes.EnhancedIR, targetCluster co
 	for _, obj := range objs {
 		if !o.loadResource(obj, objs, ir, targetCluster) {
 			logrus.Errorf("Object created seems to be of an incompatible type : %+v [Supported Types: %+v]", obj.GetObjectKind(), o.getSupportedKinds())
+			recordIgnoredObject(obj, ConversionStatusDropped, fmt.Sprintf("the object could not be converted to any of the kinds %v supported by the target cluster", o.getClusterSupportedKinds(targetCluster)))
 		}
 	}
 	return o.cachedobjs
@@ -159,6 +162,10 @@ func (o *APIResource) merge(obj1, obj2 runtime.Object) (runtime.Object, bool) {
 }
 
 func (*APIResource) getObjectID(obj runtime.Object) string {
+	if cr, ok := obj.(*unstructured.Unstructured); ok {
+		// the custom resources of different kinds, like a Rollout and its AnalysisTemplate, can share a name
+		return cr.GroupVersionKind().GroupKind().String() + "/" + cr.GetNamespace() + "/" + cr.GetName()
+	}
 	k8sObjValue := reflect.ValueOf(obj).Elem()
 	objMeta, ok := k8sObjValue.FieldByName("ObjectMeta").Interface().(metav1.ObjectMeta)
 	if !ok {
@@ -190,6 +197,11 @@ func (o *APIResource) deepMerge(x, y runtime.Object) (runtime.Object, error) {
 	if xGVK.Kind != yGVK.Kind {
 		logrus.Errorf("Attempting to merge to different kinds : %s & %s", xGVK.Kind, yGVK.Kind)
 	}
+	if xcr, ok := x.(*unstructured.Unstructured); ok {
+		if ycr, ok := y.(*unstructured.Unstructured); ok {
+			return mergeCustomResources(xcr, ycr)
+		}
+	}
 	newx, err := k8sschema.ConvertToVersion(x, yGVK.GroupVersion())
 	if err != nil {
 		logrus.Errorf("Unable to convert version : %s. Will try to merge two different versions", err)
@@ -221,3 +233,24 @@ func (o *APIResource) deepMerge(x, y runtime.Object) (runtime.Object, error) {
 	}
 	return obj, err
 }
+
+// mergeCustomResources merges the custom resources using a json merge patch, since they have no schema for a strategic merge
+func mergeCustomResources(x, y *unstructured.Unstructured) (runtime.Object, error) {
+	xJSON, err := x.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the first object %s to json. Error: %w", x.GetName(), err)
+	}
+	yJSON, err := y.MarshalJSON()
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal the second object %s to json. Error: %w", y.GetName(), err)
+	}
+	mergedJSON, err := jsonpatch.MergePatch(xJSON, yJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge the objects \n%s\n and \n%s\n Error: %w", xJSON, yJSON, err)
+	}
+	merged := &unstructured.Unstructured{}
+	if err := merged.UnmarshalJSON(mergedJSON); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal the merged object %s . Error: %w", y.GetName(), err)
+	}
+	return merged, nil
+}
@@ -0,0 +1,218 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema/fixer"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// rolloutKind defines the Argo Rollouts Rollout Kind
+	rolloutKind = "Rollout"
+	// analysisTemplateKind defines the Argo Rollouts AnalysisTemplate Kind
+	analysisTemplateKind   = "AnalysisTemplate"
+	argoRolloutsAPIVersion = "argoproj.io/v1alpha1"
+	// successRateAnalysisTemplateName is the name of the analysis template shared by all the rollouts
+	successRateAnalysisTemplateName = "success-rate"
+	successRateServiceNameArg       = "service-name"
+	canaryServiceSuffix             = "-canary"
+	previewServiceSuffix            = "-preview"
+)
+
+// getCanarySteps returns the steps which shift the traffic to the new version in increments, while the analysis runs in the background
+func getCanarySteps() []interface{} {
+	steps := []interface{}{}
+	for _, weight := range []int64{20, 50, 80} {
+		steps = append(steps,
+			map[string]interface{}{"setWeight": weight},
+			map[string]interface{}{"pause": map[string]interface{}{"duration": "2m"}},
+		)
+	}
+	return steps
+}
+
+// getProgressiveDeliveryStrategy returns the progressive delivery strategy for the service, or an empty string if it uses a plain Deployment.
+// Only long running services with ports are rolled out progressively, since the analysis uses the metrics of their requests.
+func getProgressiveDeliveryStrategy(service irtypes.Service) string {
+	if service.Daemon || service.OnlyIngress || len(service.ServiceToPodPortForwardings) == 0 ||
+		service.RestartPolicy == core.RestartPolicyNever || service.RestartPolicy == core.RestartPolicyOnFailure {
+		return ""
+	}
+	strategy := commonqa.ProgressiveDeliveryStrategy()
+	if strategy == commonqa.NoProgressiveDelivery {
+		return ""
+	}
+	return strategy
+}
+
+// isArgoRolloutsStrategy returns true if the workloads are replaced by Argo Rollouts for the strategy
+func isArgoRolloutsStrategy(strategy string) bool {
+	return strategy == commonqa.ArgoRolloutsCanaryProgressiveDelivery || strategy == commonqa.ArgoRolloutsBlueGreenProgressiveDelivery
+}
+
+// getRolloutServiceName returns the name of the additional service which selects the pods of the new version during a rollout
+func getRolloutServiceName(serviceName, strategy string) string {
+	if strategy == commonqa.ArgoRolloutsBlueGreenProgressiveDelivery {
+		return serviceName + previewServiceSuffix
+	}
+	return serviceName + canaryServiceSuffix
+}
+
+// createRollout creates an Argo Rollout with the same pod template as the deployment of the service
+func (d *Deployment) createRollout(service irtypes.Service, strategy string, ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) (*unstructured.Unstructured, error) {
+	// the rollout is not fixed later, since it is a custom resource
+	deployment := fixer.Fix(d.createDeployment(service, targetCluster.Spec))
+	v1Deployment, err := k8sschema.ConvertToVersion(deployment, appsv1.SchemeGroupVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the deployment %s to %s . Error: %w", service.Name, appsv1.SchemeGroupVersion, err)
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(v1Deployment)
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert the deployment %s to unstructured. Error: %w", service.Name, err)
+	}
+	rollout := &unstructured.Unstructured{Object: content}
+	rollout.SetAPIVersion(argoRolloutsAPIVersion)
+	rollout.SetKind(rolloutKind)
+	unstructured.RemoveNestedField(rollout.Object, "status")
+	rolloutServiceName := getRolloutServiceName(service.Name, strategy)
+	// the canary is analysed through its own service, the blue-green version after it starts receiving the requests
+	analysedServiceName := rolloutServiceName
+	if strategy == commonqa.ArgoRolloutsBlueGreenProgressiveDelivery {
+		analysedServiceName = service.Name
+	}
+	analysis := map[string]interface{}{
+		"templates": []interface{}{map[string]interface{}{"templateName": successRateAnalysisTemplateName}},
+		"args":      []interface{}{map[string]interface{}{"name": successRateServiceNameArg, "value": analysedServiceName}},
+	}
+	var rolloutStrategy map[string]interface{}
+	if strategy == commonqa.ArgoRolloutsBlueGreenProgressiveDelivery {
+		// the new version is rolled back if the analysis fails after the promotion
+		rolloutStrategy = map[string]interface{}{"blueGreen": map[string]interface{}{
+			"activeService":         service.Name,
+			"previewService":        rolloutServiceName,
+			"autoPromotionEnabled":  true,
+			"postPromotionAnalysis": analysis,
+		}}
+	} else {
+		analysis["startingStep"] = int64(1)
+		canary := map[string]interface{}{
+			"stableService": service.Name,
+			"canaryService": rolloutServiceName,
+			"analysis":      analysis,
+			"steps":         getCanarySteps(),
+		}
+		if trafficRouting := d.getCanaryTrafficRouting(service, ir, targetCluster); trafficRouting != nil {
+			canary["trafficRouting"] = trafficRouting
+		}
+		rolloutStrategy = map[string]interface{}{"canary": canary}
+	}
+	if err := unstructured.SetNestedField(rollout.Object, rolloutStrategy, "spec", "strategy"); err != nil {
+		return nil, fmt.Errorf("failed to set the strategy of the rollout %s . Error: %w", service.Name, err)
+	}
+	return rollout, nil
+}
+
+// getCanaryTrafficRouting returns the traffic routing which splits the requests between the versions by weight.
+// Without it, the requests are split by the number of pods of each version.
+func (d *Deployment) getCanaryTrafficRouting(service irtypes.Service, ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) map[string]interface{} {
+	if commonqa.ServiceMesh() == commonqa.LinkerdServiceMesh {
+		return map[string]interface{}{"smi": map[string]interface{}{}}
+	}
+	if !isExposedThroughNginxIngress(service, targetCluster) {
+		return nil
+	}
+	// the ingress created for all the services is copied by Argo Rollouts to send a share of the requests to the canary service
+	return map[string]interface{}{"nginx": map[string]interface{}{"stableIngress": ir.Name}}
+}
+
+// isExposedThroughNginxIngress returns true if the service is exposed using the ingress created for all the services,
+// and the ingress is served by the NGINX ingress controller
+func isExposedThroughNginxIngress(service irtypes.Service, targetCluster collecttypes.ClusterMetadata) bool {
+	s := &Service{}
+	_, _, relPaths, _ := s.getExposeInfo(service)
+	exposed := false
+	for _, relPath := range relPaths {
+		if relPath != "" {
+			exposed = true
+			break
+		}
+	}
+	if !exposed {
+		return false
+	}
+	exposeKinds := []string{}
+	for _, kind := range []string{common.IngressKind, routeKind} {
+		if len(targetCluster.Spec.GetSupportedVersions(kind)) > 0 {
+			exposeKinds = append(exposeKinds, kind)
+		}
+	}
+	exposeKinds = s.filterExposeKinds(exposeKinds, targetCluster)
+	if common.IsPresent(exposeKinds, routeKind) || !common.IsPresent(exposeKinds, common.IngressKind) {
+		return false
+	}
+	controllerName, _ := getIngressControllerPreset(targetCluster)
+	return controllerName == nginxIngressControllerPreset
+}
+
+// createSuccessRateAnalysisTemplate creates the analysis template which fails a rollout if the success rate of the requests
+// to the service drops below the threshold. The metrics are queried from Prometheus, using the service mesh metrics if available.
+func (d *Deployment) createSuccessRateAnalysisTemplate() *unstructured.Unstructured {
+	var query string
+	switch commonqa.ServiceMesh() {
+	case commonqa.IstioServiceMesh:
+		query = `sum(irate(istio_requests_total{reporter="source",destination_service_name="{{args.service-name}}",response_code!~"5.*"}[2m])) / ` +
+			`sum(irate(istio_requests_total{reporter="source",destination_service_name="{{args.service-name}}"}[2m]))`
+	case commonqa.LinkerdServiceMesh:
+		query = `sum(rate(response_total{direction="outbound",dst_service="{{args.service-name}}",classification="success"}[2m])) / ` +
+			`sum(rate(response_total{direction="outbound",dst_service="{{args.service-name}}"}[2m]))`
+	default:
+		query = `sum(rate(nginx_ingress_controller_requests{service="{{args.service-name}}",status!~"5.*"}[2m])) / ` +
+			`sum(rate(nginx_ingress_controller_requests{service="{{args.service-name}}"}[2m]))`
+	}
+	minSuccessRate := float64(commonqa.ProgressiveDeliveryMinSuccessRate()) / 100
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": argoRolloutsAPIVersion,
+		"kind":       analysisTemplateKind,
+		"metadata":   map[string]interface{}{"name": successRateAnalysisTemplateName},
+		"spec": map[string]interface{}{
+			"args": []interface{}{map[string]interface{}{"name": successRateServiceNameArg}},
+			"metrics": []interface{}{map[string]interface{}{
+				"name":     "success-rate",
+				"interval": "1m",
+				"count":    int64(5),
+				// no requests during the interval is not a failure
+				"successCondition": fmt.Sprintf("len(result) == 0 || isNaN(result[0]) || result[0] >= %g", minSuccessRate),
+				"failureLimit":     int64(2),
+				"provider": map[string]interface{}{"prometheus": map[string]interface{}{
+					"address": commonqa.ProgressiveDeliveryPrometheusAddress(),
+					"query":   query,
+				}},
+			}},
+		},
+	}}
+}
@@ -0,0 +1,208 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+// setupQAConfig answers the questions using the given config strings and the defaults
+func setupQAConfig(t *testing.T, configs ...string) {
+	t.Helper()
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", configs, nil, nil, false)
+}
+
+func setupProgressiveDelivery(t *testing.T, strategy string) {
+	t.Helper()
+	setupQAConfig(t, common.ConfigProgressiveDeliveryStrategyKey+`="`+strategy+`"`)
+}
+
+func newRolloutTestIR(t *testing.T) irtypes.EnhancedIR {
+	t.Helper()
+	ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+	api := irtypes.NewServiceWithName("api")
+	api.Containers = []core.Container{{Name: "api", Image: "api:latest"}}
+	if err := api.AddPortForwarding(networking.ServiceBackendPort{Number: 8080}, networking.ServiceBackendPort{Number: 8080}, ""); err != nil {
+		t.Fatalf("failed to add the port forwarding. Error: %q", err)
+	}
+	ir.Services["api"] = api
+	worker := irtypes.NewServiceWithName("worker")
+	worker.Containers = []core.Container{{Name: "worker", Image: "worker:latest"}}
+	ir.Services["worker"] = worker
+	return ir
+}
+
+func newRolloutTestCluster() collecttypes.ClusterMetadata {
+	cluster := collecttypes.NewClusterMetadata("test")
+	cluster.Spec.APIKindVersionMap = map[string][]string{
+		common.DeploymentKind: {"apps/v1"},
+		common.ServiceKind:    {"v1"},
+	}
+	return cluster
+}
+
+func getObjectsByKind(objs []runtime.Object) map[string][]runtime.Object {
+	objsByKind := map[string][]runtime.Object{}
+	for _, obj := range objs {
+		kind := obj.GetObjectKind().GroupVersionKind().Kind
+		if _, ok := obj.(*core.Service); ok {
+			kind = common.ServiceKind
+		}
+		objsByKind[kind] = append(objsByKind[kind], obj)
+	}
+	return objsByKind
+}
+
+func TestGetProgressiveDeliveryStrategy(t *testing.T) {
+	setupProgressiveDelivery(t, commonqa.ArgoRolloutsCanaryProgressiveDelivery)
+	ir := newRolloutTestIR(t)
+	if got := getProgressiveDeliveryStrategy(ir.Services["api"]); got != commonqa.ArgoRolloutsCanaryProgressiveDelivery {
+		t.Fatalf("got the strategy %q for the service with ports, want %q", got, commonqa.ArgoRolloutsCanaryProgressiveDelivery)
+	}
+	if got := getProgressiveDeliveryStrategy(ir.Services["worker"]); got != "" {
+		t.Fatalf("got the strategy %q for the service without ports, want a Deployment", got)
+	}
+	job := ir.Services["api"]
+	job.RestartPolicy = core.RestartPolicyOnFailure
+	if got := getProgressiveDeliveryStrategy(job); got != "" {
+		t.Fatalf("got the strategy %q for the job, want none", got)
+	}
+}
+
+func TestCreateRollouts(t *testing.T) {
+	testCases := []struct {
+		name               string
+		strategy           string
+		rolloutServiceName string
+		wantStrategy       map[string]interface{}
+	}{
+		{
+			name:               "canary",
+			strategy:           commonqa.ArgoRolloutsCanaryProgressiveDelivery,
+			rolloutServiceName: "api-canary",
+			wantStrategy:       map[string]interface{}{"stableService": "api", "canaryService": "api-canary"},
+		},
+		{
+			name:               "blue-green",
+			strategy:           commonqa.ArgoRolloutsBlueGreenProgressiveDelivery,
+			rolloutServiceName: "api-preview",
+			wantStrategy:       map[string]interface{}{"activeService": "api", "previewService": "api-preview", "autoPromotionEnabled": true},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			setupProgressiveDelivery(t, testCase.strategy)
+			ir := newRolloutTestIR(t)
+			cluster := newRolloutTestCluster()
+			d := &Deployment{}
+			objsByKind := getObjectsByKind(d.createNewResources(ir, d.getSupportedKinds(), cluster))
+			if len(objsByKind[rolloutKind]) != 1 || len(objsByKind[analysisTemplateKind]) != 1 {
+				t.Fatalf("expected a rollout and an analysis template. Actual: %+v", objsByKind)
+			}
+			if len(objsByKind[common.DeploymentKind]) != 1 {
+				t.Fatalf("expected a deployment for the service without ports. Actual: %+v", objsByKind[common.DeploymentKind])
+			}
+			rollout := objsByKind[rolloutKind][0].(*unstructured.Unstructured)
+			if rollout.GetAPIVersion() != argoRolloutsAPIVersion || rollout.GetName() != "api" {
+				t.Fatalf("got the rollout %s %s , want %s api", rollout.GetAPIVersion(), rollout.GetName(), argoRolloutsAPIVersion)
+			}
+			if _, ok := rollout.Object["status"]; ok {
+				t.Fatalf("expected the status of the deployment to be removed from the rollout. Actual: %+v", rollout.Object["status"])
+			}
+			containers, _, _ := unstructured.NestedSlice(rollout.Object, "spec", "template", "spec", "containers")
+			if len(containers) != 1 || containers[0].(map[string]interface{})["image"] != "api:latest" {
+				t.Fatalf("expected the pod template of the deployment in the rollout. Actual: %+v", containers)
+			}
+			strategyName := "canary"
+			if testCase.strategy == commonqa.ArgoRolloutsBlueGreenProgressiveDelivery {
+				strategyName = "blueGreen"
+			}
+			strategy, _, _ := unstructured.NestedMap(rollout.Object, "spec", "strategy", strategyName)
+			for key, want := range testCase.wantStrategy {
+				if strategy[key] != want {
+					t.Fatalf("got %s %+v in the %s strategy, want %+v . Strategy: %+v", key, strategy[key], strategyName, want, strategy)
+				}
+			}
+
+			s := &Service{}
+			services := map[string]bool{}
+			for _, obj := range s.createNewResources(ir, []string{common.ServiceKind}, cluster) {
+				if svc, ok := obj.(*core.Service); ok {
+					services[svc.Name] = true
+				}
+			}
+			if !services["api"] || !services[testCase.rolloutServiceName] || services["worker-canary"] || services["worker-preview"] {
+				t.Fatalf("expected the services api and %s . Actual: %+v", testCase.rolloutServiceName, services)
+			}
+		})
+	}
+}
+
+func TestCreateSuccessRateAnalysisTemplate(t *testing.T) {
+	setupProgressiveDelivery(t, commonqa.ArgoRolloutsCanaryProgressiveDelivery)
+	qaengine.SetupConfigFile("", []string{common.ConfigProgressiveDeliverySuccessRateKey + "=95"}, nil, nil, false)
+	template := (&Deployment{}).createSuccessRateAnalysisTemplate()
+	metrics, _, _ := unstructured.NestedSlice(template.Object, "spec", "metrics")
+	if len(metrics) != 1 {
+		t.Fatalf("expected a single metric. Actual: %+v", metrics)
+	}
+	want := "len(result) == 0 || isNaN(result[0]) || result[0] >= 0.95"
+	if got := metrics[0].(map[string]interface{})["successCondition"]; got != want {
+		t.Fatalf("got the success condition %q , want %q", got, want)
+	}
+}
+
+func TestLoadResourceKeepsCustomResourcesOfDifferentKinds(t *testing.T) {
+	newCR := func(kind, name string) *unstructured.Unstructured {
+		cr := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		cr.SetAPIVersion(argoRolloutsAPIVersion)
+		cr.SetKind(kind)
+		cr.SetName(name)
+		return cr
+	}
+	o := &APIResource{IAPIResource: &Deployment{}}
+	if o.shareSameID(newCR(rolloutKind, "api"), newCR(analysisTemplateKind, "api")) {
+		t.Fatalf("expected the rollout and the analysis template with the same name to have different ids")
+	}
+	if !o.shareSameID(newCR(rolloutKind, "api"), newCR(rolloutKind, "api")) {
+		t.Fatalf("expected the rollouts with the same name to have the same id")
+	}
+	cluster := newRolloutTestCluster()
+	ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+	objs := []runtime.Object{newCR(rolloutKind, "api"), newCR(analysisTemplateKind, "api"), newCR(rolloutKind, "api")}
+	for _, obj := range objs {
+		if !o.loadResource(obj, objs, ir, cluster) {
+			t.Fatalf("failed to load the resource %+v", obj)
+		}
+	}
+	if len(o.cachedobjs) != 2 {
+		t.Fatalf("expected the duplicate rollout to be merged and the analysis template to be kept. Actual: %+v", o.cachedobjs)
+	}
+}
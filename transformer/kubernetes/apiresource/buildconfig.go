@@ -19,6 +19,7 @@ package apiresource
 import (
 	"fmt"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
@@ -89,6 +90,12 @@ func (bc *BuildConfig) createNewResource(irBuildConfig irtypes.BuildConfig, ir i
 		APIVersion: okdbuildv1.SchemeGroupVersion.String(),
 	}
 	buildConfig.ObjectMeta.Name = irBuildConfig.Name
+	if irBuildConfig.ContainerBuild.Target != "" {
+		// the docker strategy of the BuildConfig always builds the last stage of the Dockerfile
+		buildConfig.ObjectMeta.Annotations = map[string]string{
+			common.TODOAnnotation + "buildtarget": fmt.Sprintf("The BuildConfig builds the last stage of the Dockerfile. Make %s the last stage of the Dockerfile to build it.", irBuildConfig.ContainerBuild.Target),
+		}
+	}
 	buildConfig.Spec.Source = bc.getBuildSource(irBuildConfig, ir)
 	buildConfig.Spec.Strategy = bc.getBuildStrategy(irBuildConfig, ir)
 	buildConfig.Spec.Output.To = &corev1.ObjectReference{
@@ -128,7 +135,7 @@ func (*BuildConfig) getBuildSource(irBuildConfig irtypes.BuildConfig, ir irtypes
 	src := okdbuildv1.BuildSource{}
 	src.Type = okdbuildv1.BuildSourceGit
 	src.Git = &okdbuildv1.GitBuildSource{URI: gitRepoURL, Ref: branchName}
-	src.ContextDir = contextPath
+	src.ContextDir = common.GetUnixPath(contextPath)
 	src.SourceSecret = &corev1.LocalObjectReference{Name: irBuildConfig.SourceSecretName}
 	return src
 }
@@ -153,7 +160,15 @@ func (*BuildConfig) getBuildStrategy(irBuildConfig irtypes.BuildConfig, ir irtyp
 	}
 	strategy := okdbuildv1.BuildStrategy{}
 	strategy.Type = okdbuildv1.DockerBuildStrategyType
-	strategy.DockerStrategy = &okdbuildv1.DockerBuildStrategy{DockerfilePath: dockerfilePath}
+	strategy.DockerStrategy = &okdbuildv1.DockerBuildStrategy{DockerfilePath: common.GetUnixPath(dockerfilePath)}
+	buildArgs := []string{}
+	for buildArg := range irBuildConfig.ContainerBuild.BuildArgs {
+		buildArgs = append(buildArgs, buildArg)
+	}
+	sort.Strings(buildArgs)
+	for _, buildArg := range buildArgs {
+		strategy.DockerStrategy.BuildArgs = append(strategy.DockerStrategy.BuildArgs, corev1.EnvVar{Name: buildArg, Value: irBuildConfig.ContainerBuild.BuildArgs[buildArg]})
+	}
 	return strategy
 }
 
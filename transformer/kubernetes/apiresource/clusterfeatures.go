@@ -0,0 +1,176 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	ingressFeature    = "Ingress"
+	routeFeature      = "OpenShift Routes"
+	monitoringFeature = "Prometheus monitoring"
+	// prometheusOperatorGroup is the group of the custom resources of the Prometheus operator
+	prometheusOperatorGroup = "monitoring.coreos.com"
+)
+
+// prometheusOperatorKinds are the kinds of the monitoring resources which need the Prometheus operator
+var prometheusOperatorKinds = []string{"ServiceMonitor", "PodMonitor", "PrometheusRule"}
+
+// ClusterFeatureDecision records whether an optional feature is generated for the target cluster and why
+type ClusterFeatureDecision struct {
+	Cluster string `yaml:"cluster" json:"cluster"`
+	Feature string `yaml:"feature" json:"feature"`
+	Enabled bool   `yaml:"enabled" json:"enabled"`
+	Reason  string `yaml:"reason" json:"reason"`
+}
+
+var (
+	// clusterFeatureDecisions are keyed by the cluster and the feature, since the same cluster is used by several transformers
+	clusterFeatureDecisions      = map[string]ClusterFeatureDecision{}
+	clusterFeatureDecisionsMutex sync.Mutex
+)
+
+// ResetClusterFeatureDecisions clears the decisions made during a previous transformation
+func ResetClusterFeatureDecisions() {
+	clusterFeatureDecisionsMutex.Lock()
+	defer clusterFeatureDecisionsMutex.Unlock()
+	clusterFeatureDecisions = map[string]ClusterFeatureDecision{}
+}
+
+// GetClusterFeatureDecisions returns the decisions made during the transformation, sorted by cluster and feature
+func GetClusterFeatureDecisions() []ClusterFeatureDecision {
+	clusterFeatureDecisionsMutex.Lock()
+	defer clusterFeatureDecisionsMutex.Unlock()
+	decisions := []ClusterFeatureDecision{}
+	for _, decision := range clusterFeatureDecisions {
+		decisions = append(decisions, decision)
+	}
+	sort.Slice(decisions, func(i, j int) bool {
+		if decisions[i].Cluster != decisions[j].Cluster {
+			return decisions[i].Cluster < decisions[j].Cluster
+		}
+		return decisions[i].Feature < decisions[j].Feature
+	})
+	return decisions
+}
+
+// recordClusterFeatureDecision records the decision, logging it the first time it is made for the cluster
+func recordClusterFeatureDecision(decision ClusterFeatureDecision) {
+	clusterFeatureDecisionsMutex.Lock()
+	defer clusterFeatureDecisionsMutex.Unlock()
+	key := decision.Cluster + "/" + decision.Feature
+	if _, ok := clusterFeatureDecisions[key]; !ok {
+		state := "enabled"
+		if !decision.Enabled {
+			state = "disabled"
+		}
+		logrus.Infof("%s is %s for the cluster %s: %s", decision.Feature, state, decision.Cluster, decision.Reason)
+	}
+	clusterFeatureDecisions[key] = decision
+}
+
+// isCollectedCluster returns true if the metadata was collected from a live cluster, instead of being a preset.
+// The features are only disabled for the collected clusters, since the presets do not list all the installed controllers.
+func isCollectedCluster(targetCluster collecttypes.ClusterMetadata) bool {
+	return targetCluster.Spec.ServerVersion != ""
+}
+
+// isKindSupported returns true if the cluster serves the kind, either as a built in kind or through a custom resource definition
+func isKindSupported(targetCluster collecttypes.ClusterMetadata, group, kind string) bool {
+	return len(targetCluster.Spec.GetSupportedVersions(kind)) > 0 || targetCluster.Spec.GetCustomResourceDefinition(group, kind) != nil
+}
+
+// gateClusterFeatures decides which optional features are generated for the target cluster and returns the cluster metadata
+// without the kinds of the disabled features, so that the resources are converted to the kinds which the cluster can run.
+func gateClusterFeatures(targetCluster collecttypes.ClusterMetadata) collecttypes.ClusterMetadata {
+	collected := isCollectedCluster(targetCluster)
+	removedKinds := []string{}
+	decide := func(feature string, enabled bool, reason string) {
+		recordClusterFeatureDecision(ClusterFeatureDecision{Cluster: targetCluster.Name, Feature: feature, Enabled: enabled, Reason: reason})
+	}
+	routesSupported := len(targetCluster.Spec.GetSupportedVersions(routeKind)) > 0
+	if routesSupported {
+		decide(routeFeature, true, "the cluster supports OpenShift Routes, they are preferred over Ingress to expose the services")
+	} else {
+		decide(routeFeature, false, "the cluster does not support OpenShift Routes")
+	}
+	switch {
+	case len(targetCluster.Spec.GetSupportedVersions(common.IngressKind)) == 0:
+		decide(ingressFeature, false, "the cluster does not support Ingress")
+	case collected && len(targetCluster.Spec.IngressClasses) == 0 && targetCluster.Spec.IngressController == "":
+		removedKinds = append(removedKinds, common.IngressKind)
+		if routesSupported {
+			decide(ingressFeature, false, "no ingress class was found in the cluster, the services are exposed using Routes")
+		} else {
+			decide(ingressFeature, false, "no ingress class was found in the cluster, use the LoadBalancer or NodePort service types to expose the services")
+		}
+	case len(targetCluster.Spec.IngressClasses) != 0:
+		decide(ingressFeature, true, fmt.Sprintf("the cluster has the ingress classes %s", strings.Join(targetCluster.Spec.IngressClasses, ", ")))
+	default:
+		decide(ingressFeature, true, "the cluster supports Ingress")
+	}
+	monitoringSupported := false
+	for _, kind := range prometheusOperatorKinds {
+		monitoringSupported = monitoringSupported || isKindSupported(targetCluster, prometheusOperatorGroup, kind)
+	}
+	switch {
+	case monitoringSupported:
+		decide(monitoringFeature, true, "the custom resources of the Prometheus operator are installed in the cluster")
+	case collected:
+		removedKinds = append(removedKinds, prometheusOperatorKinds...)
+		decide(monitoringFeature, false, fmt.Sprintf("the custom resources of the Prometheus operator are not installed in the cluster, so the %s objects are not written", strings.Join(prometheusOperatorKinds, ", ")))
+	default:
+		decide(monitoringFeature, true, "the cluster metadata was not collected from the cluster, so the monitoring resources are kept as is")
+	}
+	if len(removedKinds) == 0 {
+		return targetCluster
+	}
+	apiKindVersionMap := map[string][]string{}
+	for kind, groupVersions := range targetCluster.Spec.APIKindVersionMap {
+		if !common.IsPresent(removedKinds, kind) {
+			apiKindVersionMap[kind] = groupVersions
+		}
+	}
+	targetCluster.Spec.APIKindVersionMap = apiKindVersionMap
+	return targetCluster
+}
+
+// dropGatedObjects removes the objects of the features which are disabled for the target cluster
+func dropGatedObjects(objs []runtime.Object, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	if !isCollectedCluster(targetCluster) {
+		return objs
+	}
+	filteredObjs := []runtime.Object{}
+	for _, obj := range objs {
+		gvk := obj.GetObjectKind().GroupVersionKind()
+		if gvk.Group == prometheusOperatorGroup && common.IsPresent(prometheusOperatorKinds, gvk.Kind) && !isKindSupported(targetCluster, gvk.Group, gvk.Kind) {
+			recordIgnoredObject(obj, ConversionStatusDropped, "the custom resources of the Prometheus operator are not installed in the target cluster")
+			continue
+		}
+		filteredObjs = append(filteredObjs, obj)
+	}
+	return filteredObjs
+}
@@ -0,0 +1,95 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestGateClusterFeatures(t *testing.T) {
+	defer ResetClusterFeatureDecisions()
+	newCluster := func(name, serverVersion string, ingressClasses []string) collecttypes.ClusterMetadata {
+		cluster := collecttypes.NewClusterMetadata(name)
+		cluster.Spec.ServerVersion = serverVersion
+		cluster.Spec.IngressClasses = ingressClasses
+		cluster.Spec.APIKindVersionMap = map[string][]string{
+			common.ServiceKind: {"v1"},
+			common.IngressKind: {"networking.k8s.io/v1"},
+		}
+		return cluster
+	}
+	testcases := []struct {
+		name        string
+		cluster     collecttypes.ClusterMetadata
+		wantIngress bool
+	}{
+		{name: "preset cluster", cluster: newCluster("preset", "", nil), wantIngress: true},
+		{name: "collected cluster with an ingress class", cluster: newCluster("withclass", "v1.29.0", []string{"nginx"}), wantIngress: true},
+		{name: "collected cluster without ingress classes", cluster: newCluster("withoutclass", "v1.29.0", nil), wantIngress: false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			gatedCluster := gateClusterFeatures(tc.cluster)
+			if gotIngress := gatedCluster.Spec.GetSupportedVersions(common.IngressKind) != nil; gotIngress != tc.wantIngress {
+				t.Fatalf("want ingress supported %v, got %v", tc.wantIngress, gotIngress)
+			}
+			if tc.cluster.Spec.GetSupportedVersions(common.IngressKind) == nil {
+				t.Fatalf("the original cluster metadata was modified")
+			}
+			found := false
+			for _, decision := range GetClusterFeatureDecisions() {
+				if decision.Cluster == tc.cluster.Name && decision.Feature == ingressFeature {
+					found = true
+					if decision.Enabled != tc.wantIngress {
+						t.Fatalf("want the ingress decision to be %v, got %+v", tc.wantIngress, decision)
+					}
+				}
+			}
+			if !found {
+				t.Fatalf("the ingress decision was not recorded for the cluster %s", tc.cluster.Name)
+			}
+		})
+	}
+}
+
+func TestDropGatedObjects(t *testing.T) {
+	defer ResetIgnoredObjects()
+	serviceMonitor := &unstructured.Unstructured{}
+	serviceMonitor.SetAPIVersion(prometheusOperatorGroup + "/v1")
+	serviceMonitor.SetKind("ServiceMonitor")
+	serviceMonitor.SetName("web")
+	objs := []runtime.Object{createService("web", nil), serviceMonitor}
+
+	preset := collecttypes.NewClusterMetadata("preset")
+	if got := dropGatedObjects(objs, preset); len(got) != 2 {
+		t.Fatalf("expected the objects to be kept for a preset cluster. Actual objects %+v", got)
+	}
+	collected := collecttypes.NewClusterMetadata("collected")
+	collected.Spec.ServerVersion = "v1.29.0"
+	if got := dropGatedObjects(objs, collected); len(got) != 1 {
+		t.Fatalf("expected the ServiceMonitor to be dropped. Actual objects %+v", got)
+	}
+	collected.Spec.CustomResourceDefinitions = []collecttypes.CustomResourceDefinition{{Name: "servicemonitors." + prometheusOperatorGroup, Group: prometheusOperatorGroup, Kind: "ServiceMonitor"}}
+	if got := dropGatedObjects(objs, collected); len(got) != 2 {
+		t.Fatalf("expected the ServiceMonitor to be kept when its CRD is installed. Actual objects %+v", got)
+	}
+}
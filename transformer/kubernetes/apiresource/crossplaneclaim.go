@@ -0,0 +1,389 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	compositeResourceDefinitionKind = "CompositeResourceDefinition"
+	crossplaneAPIVersion            = "apiextensions.crossplane.io/v1"
+	crossplaneClaimVersion          = "v1alpha1"
+	// crossplaneProviderLabel is matched by the compositions of the platform for each cloud provider
+	crossplaneProviderLabel    = "provider"
+	crossplaneConnectionSuffix = "-connection"
+	// sqlCloudResource is a database whose engine is found from the url or the port
+	sqlCloudResource       = "sql"
+	postgresCloudResource  = "postgres"
+	mysqlCloudResource     = "mysql"
+	sqlServerCloudResource = "sqlserver"
+	redisCloudResource     = "redis"
+	bucketCloudResource    = "bucket"
+)
+
+// cloudResourceType is the claim of a kind of managed cloud resource
+type cloudResourceType struct {
+	kind                 string
+	plural               string
+	parameters           map[string]interface{}
+	connectionSecretKeys []string
+}
+
+// cloudResourceDetector detects a managed cloud resource from the host names and urls in the configuration of the services
+type cloudResourceDetector struct {
+	// regex matches the address of the resource. The first group is the name of the resource and the optional second group is the region.
+	regex        *regexp.Regexp
+	resourceType string
+	provider     string
+}
+
+// cloudResource is a managed cloud resource used by the services
+type cloudResource struct {
+	name         string
+	resourceType string
+	provider     string
+	region       string
+	serviceNames []string
+}
+
+var (
+	cloudResourceTypes = map[string]cloudResourceType{
+		postgresCloudResource:  {kind: "PostgreSQLInstance", plural: "postgresqlinstances", parameters: map[string]interface{}{"storageGB": int64(20)}, connectionSecretKeys: []string{"username", "password", "endpoint", "port"}},
+		mysqlCloudResource:     {kind: "MySQLInstance", plural: "mysqlinstances", parameters: map[string]interface{}{"storageGB": int64(20)}, connectionSecretKeys: []string{"username", "password", "endpoint", "port"}},
+		sqlServerCloudResource: {kind: "SQLServerInstance", plural: "sqlserverinstances", parameters: map[string]interface{}{"storageGB": int64(20)}, connectionSecretKeys: []string{"username", "password", "endpoint", "port"}},
+		redisCloudResource:     {kind: "RedisCache", plural: "rediscaches", parameters: map[string]interface{}{"memoryGB": int64(1)}, connectionSecretKeys: []string{"password", "endpoint", "port"}},
+		bucketCloudResource:    {kind: "Bucket", plural: "buckets", parameters: map[string]interface{}{}, connectionSecretKeys: []string{"bucketName", "endpoint"}},
+	}
+	// cloudResourceDetectors are checked in order and sqlCloudResource is refined using the engine of the database
+	cloudResourceDetectors = []cloudResourceDetector{
+		{regex: regexp.MustCompile(`([a-z0-9-]+)\.[a-z0-9]+\.([a-z0-9-]+)\.rds\.amazonaws\.com`), resourceType: sqlCloudResource, provider: "aws"},
+		{regex: regexp.MustCompile(`([a-z0-9-]+)(?:\.[a-z0-9-]+)*\.cache\.amazonaws\.com`), resourceType: redisCloudResource, provider: "aws"},
+		{regex: regexp.MustCompile(`s3://([a-z0-9-]+)`), resourceType: bucketCloudResource, provider: "aws"},
+		{regex: regexp.MustCompile(`([a-z0-9-]+)\.s3(?:[.-]([a-z0-9-]+))?\.amazonaws\.com`), resourceType: bucketCloudResource, provider: "aws"},
+		{regex: regexp.MustCompile(`([a-z0-9-]+)\.postgres\.database\.azure\.com`), resourceType: postgresCloudResource, provider: "azure"},
+		{regex: regexp.MustCompile(`([a-z0-9-]+)\.mysql\.database\.azure\.com`), resourceType: mysqlCloudResource, provider: "azure"},
+		{regex: regexp.MustCompile(`([a-z0-9-]+)\.database\.windows\.net`), resourceType: sqlServerCloudResource, provider: "azure"},
+		{regex: regexp.MustCompile(`([a-z0-9-]+)\.redis\.cache\.windows\.net`), resourceType: redisCloudResource, provider: "azure"},
+		{regex: regexp.MustCompile(`([a-z0-9]+)\.blob\.core\.windows\.net`), resourceType: bucketCloudResource, provider: "azure"},
+		{regex: regexp.MustCompile(`gs://([a-z0-9_-]+)`), resourceType: bucketCloudResource, provider: "gcp"},
+	}
+	// vcapServiceResourceTypes maps the names of the service broker offerings to the types of the resources
+	vcapServiceResourceTypes = []struct {
+		offerings    []string
+		resourceType string
+	}{
+		{offerings: []string{"postgres", "elephantsql"}, resourceType: postgresCloudResource},
+		{offerings: []string{"mysql", "cleardb", "mariadb"}, resourceType: mysqlCloudResource},
+		{offerings: []string{"mssql", "sqlserver"}, resourceType: sqlServerCloudResource},
+		{offerings: []string{"redis"}, resourceType: redisCloudResource},
+		{offerings: []string{"s3", "bucket", "objectstorage"}, resourceType: bucketCloudResource},
+	}
+)
+
+// CrossplaneClaim handles the Crossplane claims of the managed cloud resources used by the services
+type CrossplaneClaim struct {
+}
+
+// getSupportedKinds returns all kinds supported by the class
+func (c *CrossplaneClaim) getSupportedKinds() []string {
+	kinds := []string{compositeResourceDefinitionKind}
+	for _, resourceType := range cloudResourceTypes {
+		kinds = append(kinds, resourceType.kind)
+	}
+	return kinds
+}
+
+// createNewResources converts ir to runtime objects
+func (c *CrossplaneClaim) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	// Since Crossplane is an extension, the supported kinds of the cluster are ignored, like for the ArgoCD resources
+	resources := detectCloudResources(ir)
+	if len(resources) == 0 {
+		return nil
+	}
+	detected := []string{}
+	for _, resource := range resources {
+		detected = append(detected, fmt.Sprintf("%s %s used by %s", cloudResourceTypes[resource.resourceType].kind, resource.name, strings.Join(resource.serviceNames, ", ")))
+	}
+	if !qaengine.FetchBoolAnswer(
+		common.ConfigCrossplaneClaimsKey,
+		"Generate Crossplane claims for the managed cloud resources used by the services?",
+		append([]string{"The services use the following cloud resources, which can be declared alongside the workloads:"}, detected...),
+		false,
+		nil,
+	) {
+		return nil
+	}
+	group := qaengine.FetchStringAnswer(
+		common.ConfigCrossplaneAPIGroupKey,
+		"Provide the API group of the composite resources defined by the platform :",
+		[]string{"The CompositeResourceDefinitions are generated as stubs. The platform has to provide the Compositions creating the cloud resources."},
+		"platform.example.org",
+		qatypes.NewHostnameValidator(),
+	)
+	objs := []runtime.Object{}
+	usedTypes := []string{}
+	for _, resource := range resources {
+		usedTypes = common.AppendIfNotPresent(usedTypes, resource.resourceType)
+		objs = append(objs, c.createClaim(resource, group))
+	}
+	sort.Strings(usedTypes)
+	for _, resourceType := range usedTypes {
+		objs = append(objs, c.createCompositeResourceDefinition(cloudResourceTypes[resourceType], group))
+	}
+	return objs
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (c *CrossplaneClaim) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsPresent(c.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
+
+// createClaim creates the namespaced claim of the resource, which writes the connection details to a secret
+func (c *CrossplaneClaim) createClaim(resource cloudResource, group string) *unstructured.Unstructured {
+	resourceType := cloudResourceTypes[resource.resourceType]
+	name := common.MakeStringK8sServiceNameCompliant(resource.name)
+	parameters := map[string]interface{}{}
+	for key, value := range resourceType.parameters {
+		parameters[key] = value
+	}
+	if resource.region != "" {
+		parameters["region"] = resource.region
+	}
+	spec := map[string]interface{}{
+		"parameters":                 parameters,
+		"writeConnectionSecretToRef": map[string]interface{}{"name": name + crossplaneConnectionSuffix},
+	}
+	if resource.provider != "" {
+		spec["compositionSelector"] = map[string]interface{}{"matchLabels": map[string]interface{}{crossplaneProviderLabel: resource.provider}}
+	}
+	composition := fmt.Sprintf("Provide a Composition for the %s", resourceType.kind)
+	if resource.provider != "" {
+		composition += fmt.Sprintf(" labelled %s: %s", crossplaneProviderLabel, resource.provider)
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": group + "/" + crossplaneClaimVersion,
+		"kind":       resourceType.kind,
+		"metadata": map[string]interface{}{
+			"name": name,
+			"annotations": map[string]interface{}{
+				common.TODOAnnotation + "composition": composition,
+				common.TODOAnnotation + "connection":  fmt.Sprintf("Point the configuration of the services %s to the secret %s", strings.Join(resource.serviceNames, ", "), name+crossplaneConnectionSuffix),
+			},
+		},
+		"spec": spec,
+	}}
+}
+
+// createCompositeResourceDefinition creates the stub of the definition of the composite resource and its claim
+func (c *CrossplaneClaim) createCompositeResourceDefinition(resourceType cloudResourceType, group string) *unstructured.Unstructured {
+	parameterProperties := map[string]interface{}{"region": map[string]interface{}{"type": "string"}}
+	for key := range resourceType.parameters {
+		parameterProperties[key] = map[string]interface{}{"type": "integer"}
+	}
+	connectionSecretKeys := []interface{}{}
+	for _, key := range resourceType.connectionSecretKeys {
+		connectionSecretKeys = append(connectionSecretKeys, key)
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": crossplaneAPIVersion,
+		"kind":       compositeResourceDefinitionKind,
+		"metadata":   map[string]interface{}{"name": "x" + resourceType.plural + "." + group},
+		"spec": map[string]interface{}{
+			"group":                group,
+			"names":                map[string]interface{}{"kind": "X" + resourceType.kind, "plural": "x" + resourceType.plural},
+			"claimNames":           map[string]interface{}{"kind": resourceType.kind, "plural": resourceType.plural},
+			"connectionSecretKeys": connectionSecretKeys,
+			"versions": []interface{}{map[string]interface{}{
+				"name":          crossplaneClaimVersion,
+				"served":        true,
+				"referenceable": true,
+				"schema": map[string]interface{}{"openAPIV3Schema": map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{"spec": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{"parameters": map[string]interface{}{
+							"type":       "object",
+							"properties": parameterProperties,
+						}},
+					}},
+				}},
+			}},
+		},
+	}}
+}
+
+// detectCloudResources finds the managed cloud resources in the environment variables of the services, including the ones from config maps and secrets
+func detectCloudResources(ir irtypes.EnhancedIR) []cloudResource {
+	contents := map[string]map[string][]byte{}
+	for _, storage := range ir.Storages {
+		if storage.StorageType == irtypes.ConfigMapKind || storage.StorageType == irtypes.SecretKind {
+			contents[storage.Name] = storage.Content
+		}
+	}
+	detected := map[string]cloudResource{}
+	serviceNames := []string{}
+	for serviceName := range ir.Services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		for _, container := range service.Containers {
+			env := map[string]string{}
+			for _, envFrom := range container.EnvFrom {
+				name := ""
+				if envFrom.ConfigMapRef != nil {
+					name = envFrom.ConfigMapRef.Name
+				} else if envFrom.SecretRef != nil {
+					name = envFrom.SecretRef.Name
+				}
+				for key, value := range contents[name] {
+					env[envFrom.Prefix+key] = string(value)
+				}
+			}
+			for _, envVar := range container.Env {
+				if envVar.ValueFrom == nil {
+					env[envVar.Name] = envVar.Value
+				} else if ref := envVar.ValueFrom.ConfigMapKeyRef; ref != nil {
+					env[envVar.Name] = string(contents[ref.Name][ref.Key])
+				} else if ref := envVar.ValueFrom.SecretKeyRef; ref != nil {
+					env[envVar.Name] = string(contents[ref.Name][ref.Key])
+				}
+			}
+			for name, value := range env {
+				var resources []cloudResource
+				if name == common.VcapServiceEnvName {
+					resources = getCloudResourcesFromVcapServices(value)
+				} else {
+					resources = getCloudResourcesFromValue(name, value)
+				}
+				for _, resource := range resources {
+					key := resource.resourceType + "/" + resource.name
+					if existing, ok := detected[key]; ok {
+						resource = existing
+					} else {
+						logrus.Debugf("Detected the %s %s used by the service %s", resource.resourceType, resource.name, service.Name)
+					}
+					resource.serviceNames = common.AppendIfNotPresent(resource.serviceNames, service.Name)
+					detected[key] = resource
+				}
+			}
+		}
+	}
+	keys := []string{}
+	for key := range detected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	resources := []cloudResource{}
+	for _, key := range keys {
+		resources = append(resources, detected[key])
+	}
+	return resources
+}
+
+// getCloudResourcesFromValue detects the cloud resources from the host names and urls in the value of an environment variable
+func getCloudResourcesFromValue(name, value string) []cloudResource {
+	resources := []cloudResource{}
+	lowerValue := strings.ToLower(value)
+	for _, detector := range cloudResourceDetectors {
+		for _, match := range detector.regex.FindAllStringSubmatch(lowerValue, -1) {
+			resource := cloudResource{name: match[1], resourceType: detector.resourceType, provider: detector.provider}
+			if len(match) > 2 {
+				resource.region = match[2]
+			}
+			if resource.resourceType == sqlCloudResource {
+				resource.resourceType = getSQLEngine(name, lowerValue)
+			}
+			resources = append(resources, resource)
+		}
+	}
+	return resources
+}
+
+// getSQLEngine returns the engine of a database from the scheme or the port in its url, defaulting to postgres
+func getSQLEngine(name, value string) string {
+	value = strings.ToLower(name) + " " + value
+	switch {
+	case strings.Contains(value, "mysql") || strings.Contains(value, "mariadb") || strings.Contains(value, ":3306"):
+		return mysqlCloudResource
+	case strings.Contains(value, "sqlserver") || strings.Contains(value, "mssql") || strings.Contains(value, ":1433"):
+		return sqlServerCloudResource
+	default:
+		return postgresCloudResource
+	}
+}
+
+// getCloudResourcesFromVcapServices detects the cloud resources bound to the Cloud Foundry application using a service broker
+func getCloudResourcesFromVcapServices(vcapServices string) []cloudResource {
+	serviceInstanceMap := map[string][]artifacts.VCAPService{}
+	if err := json.Unmarshal([]byte(vcapServices), &serviceInstanceMap); err != nil {
+		logrus.Debugf("Unable to parse the %s environment variable. Error: %q", common.VcapServiceEnvName, err)
+		return nil
+	}
+	resources := []cloudResource{}
+	for offering, serviceInstances := range serviceInstanceMap {
+		lowerOffering := strings.ToLower(offering)
+		resourceType := ""
+		for _, vcapServiceResourceType := range vcapServiceResourceTypes {
+			for _, name := range vcapServiceResourceType.offerings {
+				if strings.Contains(lowerOffering, name) {
+					resourceType = vcapServiceResourceType.resourceType
+					break
+				}
+			}
+			if resourceType != "" {
+				break
+			}
+		}
+		if resourceType == "" {
+			continue
+		}
+		provider := ""
+		if strings.Contains(lowerOffering, "aws") {
+			provider = "aws"
+		} else if strings.Contains(lowerOffering, "azure") {
+			provider = "azure"
+		} else if strings.Contains(lowerOffering, "google") || strings.Contains(lowerOffering, "gcp") {
+			provider = "gcp"
+		}
+		for _, serviceInstance := range serviceInstances {
+			if serviceInstance.ServiceName != "" {
+				resources = append(resources, cloudResource{name: serviceInstance.ServiceName, resourceType: resourceType, provider: provider})
+			}
+		}
+	}
+	return resources
+}
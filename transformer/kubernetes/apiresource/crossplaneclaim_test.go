@@ -0,0 +1,151 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestGetCloudResourcesFromValue(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value string
+		want  []cloudResource
+	}{
+		{name: "DATABASE_URL", value: "postgres://user@orders.c9akciq32.us-east-1.rds.amazonaws.com:5432/orders", want: []cloudResource{
+			{name: "orders", resourceType: postgresCloudResource, provider: "aws", region: "us-east-1"},
+		}},
+		{name: "DB_HOST", value: "Inventory.abc123.eu-west-2.rds.amazonaws.com:3306", want: []cloudResource{
+			{name: "inventory", resourceType: mysqlCloudResource, provider: "aws", region: "eu-west-2"},
+		}},
+		{name: "MSSQL_HOST", value: "billing.xyz.us-west-1.rds.amazonaws.com", want: []cloudResource{
+			{name: "billing", resourceType: sqlServerCloudResource, provider: "aws", region: "us-west-1"},
+		}},
+		{name: "BUCKET", value: "s3://invoices", want: []cloudResource{
+			{name: "invoices", resourceType: bucketCloudResource, provider: "aws"},
+		}},
+		{name: "BUCKET_URL", value: "https://media.s3.eu-central-1.amazonaws.com/", want: []cloudResource{
+			{name: "media", resourceType: bucketCloudResource, provider: "aws", region: "eu-central-1"},
+		}},
+		{name: "REDIS_HOST", value: "sessions.redis.cache.windows.net:6380", want: []cloudResource{
+			{name: "sessions", resourceType: redisCloudResource, provider: "azure"},
+		}},
+		{name: "SQL", value: "Server=tcp:crm.database.windows.net,1433", want: []cloudResource{
+			{name: "crm", resourceType: sqlServerCloudResource, provider: "azure"},
+		}},
+		{name: "ASSETS", value: "gs://static_assets", want: []cloudResource{
+			{name: "static_assets", resourceType: bucketCloudResource, provider: "gcp"},
+		}},
+		{name: "HOST", value: "db.internal:5432", want: []cloudResource{}},
+	}
+	for _, testCase := range testCases {
+		if got := getCloudResourcesFromValue(testCase.name, testCase.value); !cmp.Equal(got, testCase.want, cmp.AllowUnexported(cloudResource{})) {
+			t.Errorf("getCloudResourcesFromValue(%q, %q) = %+v, want %+v", testCase.name, testCase.value, got, testCase.want)
+		}
+	}
+}
+
+func TestGetCloudResourcesFromVcapServices(t *testing.T) {
+	vcapServices := `{
+		"aws-rds-postgres": [{"name": "orders-db"}],
+		"p.redis": [{"name": "cache"}, {"name": ""}],
+		"user-provided": [{"name": "logs"}]
+	}`
+	got := getCloudResourcesFromVcapServices(vcapServices)
+	want := map[string]cloudResource{
+		"orders-db": {name: "orders-db", resourceType: postgresCloudResource, provider: "aws"},
+		"cache":     {name: "cache", resourceType: redisCloudResource},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected the resources %+v , got %+v", want, got)
+	}
+	for _, resource := range got {
+		if !cmp.Equal(resource, want[resource.name], cmp.AllowUnexported(cloudResource{})) {
+			t.Fatalf("expected the resource %+v , got %+v", want[resource.name], resource)
+		}
+	}
+	if got := getCloudResourcesFromVcapServices("not json"); len(got) != 0 {
+		t.Fatalf("expected no resources for an invalid %s , got %+v", common.VcapServiceEnvName, got)
+	}
+}
+
+func TestCrossplaneClaimCreateNewResources(t *testing.T) {
+	newIR := func() irtypes.EnhancedIR {
+		ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+		ir.Storages = []irtypes.Storage{{Name: "api-secret", StorageType: irtypes.SecretKind, Content: map[string][]byte{
+			"DATABASE_URL": []byte("postgres://orders.c9akciq32.us-east-1.rds.amazonaws.com:5432/orders"),
+		}}}
+		api := irtypes.NewServiceWithName("api")
+		api.Containers = []core.Container{{
+			Name:    "api",
+			EnvFrom: []core.EnvFromSource{{SecretRef: &core.SecretEnvSource{LocalObjectReference: core.LocalObjectReference{Name: "api-secret"}}}},
+		}}
+		ir.Services["api"] = api
+		worker := irtypes.NewServiceWithName("worker")
+		worker.Containers = []core.Container{{Name: "worker", Env: []core.EnvVar{
+			{Name: "DATABASE_URL", Value: "postgres://orders.c9akciq32.us-east-1.rds.amazonaws.com:5432/orders"},
+			{Name: "UPLOADS", Value: "s3://uploads"},
+		}}}
+		ir.Services["worker"] = worker
+		return ir
+	}
+	cluster := collecttypes.NewClusterMetadata("")
+	t.Run("the claims are not generated by default", func(t *testing.T) {
+		setupQAConfig(t)
+		if objs := new(CrossplaneClaim).createNewResources(newIR(), nil, cluster); len(objs) != 0 {
+			t.Fatalf("expected no resources, got %+v", objs)
+		}
+	})
+	t.Run("the claims and the definitions are generated", func(t *testing.T) {
+		setupQAConfig(t, common.ConfigCrossplaneClaimsKey+"=true", common.ConfigCrossplaneAPIGroupKey+`="infra.acme.io"`)
+		objs := new(CrossplaneClaim).createNewResources(newIR(), nil, cluster)
+		kinds := []string{}
+		for _, obj := range objs {
+			kinds = append(kinds, obj.GetObjectKind().GroupVersionKind().Kind)
+		}
+		wantKinds := []string{"Bucket", "PostgreSQLInstance", compositeResourceDefinitionKind, compositeResourceDefinitionKind}
+		if diff := cmp.Diff(wantKinds, kinds); diff != "" {
+			t.Fatalf("got the wrong kinds. Diff (-want +got):\n%s", diff)
+		}
+		postgres := objs[1].(*unstructured.Unstructured)
+		if postgres.GetAPIVersion() != "infra.acme.io/"+crossplaneClaimVersion || postgres.GetName() != "orders" {
+			t.Fatalf("expected the claim orders in the API group, got %s %s", postgres.GetAPIVersion(), postgres.GetName())
+		}
+		if region, _, _ := unstructured.NestedString(postgres.Object, "spec", "parameters", "region"); region != "us-east-1" {
+			t.Fatalf("expected the region us-east-1, got %q", region)
+		}
+		if provider, _, _ := unstructured.NestedString(postgres.Object, "spec", "compositionSelector", "matchLabels", crossplaneProviderLabel); provider != "aws" {
+			t.Fatalf("expected the composition of the provider aws, got %q", provider)
+		}
+		if secret, _, _ := unstructured.NestedString(postgres.Object, "spec", "writeConnectionSecretToRef", "name"); secret != "orders-connection" {
+			t.Fatalf("expected the connection secret orders-connection, got %q", secret)
+		}
+		if todo := postgres.GetAnnotations()[common.TODOAnnotation+"connection"]; todo != "Point the configuration of the services api, worker to the secret orders-connection" {
+			t.Fatalf("expected the services using the database in the annotation, got %q", todo)
+		}
+		if name := objs[2].(*unstructured.Unstructured).GetName(); name != "xbuckets.infra.acme.io" {
+			t.Fatalf("expected the definition of the buckets first, got %q", name)
+		}
+	})
+}
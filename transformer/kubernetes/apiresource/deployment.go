@@ -17,17 +17,23 @@
 package apiresource
 
 import (
+	"encoding/json"
+	"fmt"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
 	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
 	okdappsv1 "github.com/openshift/api/apps/v1"
 	"github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	apps "k8s.io/kubernetes/pkg/apis/apps"
 	batch "k8s.io/kubernetes/pkg/apis/batch"
 	core "k8s.io/kubernetes/pkg/apis/core"
@@ -46,6 +52,10 @@ const (
 	replicationControllerKind string = "ReplicationController"
 	// daemonSetKind defines DaemonSet Kind
 	daemonSetKind string = "DaemonSet"
+	// cronJobKind defines CronJob Kind
+	cronJobKind string = "CronJob"
+	// imageTriggersAnnotation is used by OpenShift to update the images of the workloads when their ImageStream tags change
+	imageTriggersAnnotation = "image.openshift.io/triggers"
 )
 
 // Deployment handles all objects like a Deployment
@@ -54,12 +64,15 @@ type Deployment struct {
 
 // getSupportedKinds returns kinds supported by the deployment
 func (d *Deployment) getSupportedKinds() []string {
-	return []string{podKind, jobKind, common.DeploymentKind, deploymentConfigKind, replicationControllerKind}
+	return []string{podKind, jobKind, cronJobKind, common.DeploymentKind, common.StatefulSetKind, deploymentConfigKind, replicationControllerKind, rolloutKind, analysisTemplateKind, flaggerCanaryKind}
 }
 
 // createNewResources converts ir to runtime object
 func (d *Deployment) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
 	objs := []runtime.Object{}
+	supportedKinds = d.filterWorkloadKinds(supportedKinds, targetCluster)
+	rolloutCreated := false
+	flaggerCanaryCreated := false
 	for _, service := range ir.Services {
 		var obj runtime.Object
 		if service.Daemon {
@@ -67,6 +80,8 @@ func (d *Deployment) createNewResources(ir irtypes.EnhancedIR, supportedKinds []
 				logrus.Errorf("Creating Daemonset even though not supported by target cluster.")
 			}
 			obj = d.createDaemonSet(service, targetCluster.Spec)
+		} else if service.Schedule != "" && common.IsPresent(supportedKinds, cronJobKind) {
+			obj = d.createCronJob(service, targetCluster.Spec)
 		} else if service.RestartPolicy == core.RestartPolicyNever || service.RestartPolicy == core.RestartPolicyOnFailure {
 			if common.IsPresent(supportedKinds, jobKind) {
 				obj = d.createJob(service, targetCluster.Spec)
@@ -76,8 +91,22 @@ func (d *Deployment) createNewResources(ir irtypes.EnhancedIR, supportedKinds []
 			pod := d.createPod(service, targetCluster.Spec)
 			pod.Spec.RestartPolicy = core.RestartPolicyOnFailure
 			obj = pod
+		} else if isStatefulSetService(service, targetCluster) {
+			obj = d.createStatefulSet(service, ir, targetCluster.Spec)
 		} else if common.IsPresent(supportedKinds, common.DeploymentKind) {
 			obj = d.createDeployment(service, targetCluster.Spec)
+			if strategy := getProgressiveDeliveryStrategy(service); isArgoRolloutsStrategy(strategy) {
+				rollout, err := d.createRollout(service, strategy, ir, targetCluster)
+				if err != nil {
+					logrus.Errorf("Failed to create the rollout for the service %s . Creating a Deployment instead. Error: %q", service.Name, err)
+				} else {
+					obj = rollout
+					rolloutCreated = true
+				}
+			} else if strategy == commonqa.FlaggerCanaryProgressiveDelivery {
+				objs = append(objs, d.createFlaggerCanary(service, ir, targetCluster))
+				flaggerCanaryCreated = true
+			}
 		} else if common.IsPresent(supportedKinds, deploymentConfigKind) {
 			obj = d.createDeploymentConfig(service, targetCluster.Spec)
 		} else if common.IsPresent(supportedKinds, replicationControllerKind) {
@@ -92,15 +121,33 @@ func (d *Deployment) createNewResources(ir irtypes.EnhancedIR, supportedKinds []
 			objs = append(objs, obj)
 		}
 	}
+	if rolloutCreated {
+		objs = append(objs, d.createSuccessRateAnalysisTemplate())
+		logrus.Infof("The services are rolled out using Argo Rollouts. The Argo Rollouts controller needs to be installed in the cluster.")
+	}
+	if flaggerCanaryCreated {
+		logrus.Infof("The deployments are rolled out using Flagger canaries. Flagger needs to be installed in the cluster.")
+	}
 	return objs
 }
 
 // convertToClusterSupportedKinds converts objects to kind supported by the cluster
 func (d *Deployment) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if cr, ok := obj.(*unstructured.Unstructured); ok {
+		// the Argo Rollouts resources are passed through as is
+		return []runtime.Object{cr}, true
+	}
 	lobj, _ := k8sschema.ConvertToLiasonScheme(obj)
+	supportedKinds = d.filterWorkloadKinds(supportedKinds, targetCluster)
 	if d1, ok := lobj.(*apps.DaemonSet); ok {
 		return []runtime.Object{d1}, true
 	}
+	if d1, ok := lobj.(*apps.StatefulSet); ok && common.IsPresent(supportedKinds, common.StatefulSetKind) {
+		return []runtime.Object{d1}, true
+	}
+	if d1, ok := lobj.(*batch.CronJob); ok && common.IsPresent(supportedKinds, cronJobKind) {
+		return []runtime.Object{d1}, true
+	}
 	if d1, ok := lobj.(*core.Pod); ok && (d1.Spec.RestartPolicy == core.RestartPolicyOnFailure || d1.Spec.RestartPolicy == core.RestartPolicyNever) {
 		if common.IsPresent(supportedKinds, jobKind) {
 			return []runtime.Object{d.podToJob(*d1, targetCluster.Spec)}, true
@@ -162,6 +209,20 @@ func (d *Deployment) convertToClusterSupportedKinds(obj runtime.Object, supporte
 	return nil, false
 }
 
+// filterWorkloadKinds removes Deployment from the supported kinds if the user prefers DeploymentConfig on clusters supporting both
+func (d *Deployment) filterWorkloadKinds(supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []string {
+	if !common.IsPresent(supportedKinds, common.DeploymentKind) || !common.IsPresent(supportedKinds, deploymentConfigKind) {
+		return supportedKinds
+	}
+	quesKey := common.JoinQASubKeys(getClusterQaID(targetCluster), common.ConfigWorkloadKindKeySuffix)
+	desc := "Select the kind of the workloads to generate"
+	hints := []string{"The target cluster supports both Deployments and DeploymentConfigs. DeploymentConfigs are redeployed when their ImageStreams are updated."}
+	if qaengine.FetchSelectAnswer(quesKey, desc, hints, common.DeploymentKind, []string{common.DeploymentKind, deploymentConfigKind}, nil) == common.DeploymentKind {
+		return supportedKinds
+	}
+	return common.Filter(supportedKinds, func(kind string) bool { return kind != common.DeploymentKind })
+}
+
 // Create section
 
 func (d *Deployment) createDeployment(service irtypes.Service, cluster collecttypes.ClusterMetadataSpec) *apps.Deployment {
@@ -174,7 +235,10 @@ func (d *Deployment) createDeployment(service irtypes.Service, cluster collectty
 	podSpec = irtypes.PodSpec(d.convertVolumesKindsByPolicy(core.PodSpec(podSpec), cluster))
 	podSpec.RestartPolicy = core.RestartPolicyAlways
 	logrus.Debugf("Created deployment for %s", service.Name)
-	return d.toDeployment(meta, core.PodSpec(podSpec), int32(service.Replicas), cluster)
+	deployment := d.toDeployment(meta, core.PodSpec(podSpec), int32(service.Replicas), cluster)
+	deployment.Spec.Strategy = getDeploymentStrategy(service.RolloutStrategy)
+	deployment.Spec.RevisionHistoryLimit = service.RolloutStrategy.RevisionHistoryLimit
+	return deployment
 }
 
 func (d *Deployment) createDeploymentConfig(service irtypes.Service, cluster collecttypes.ClusterMetadataSpec) *okdappsv1.DeploymentConfig {
@@ -187,7 +251,10 @@ func (d *Deployment) createDeploymentConfig(service irtypes.Service, cluster col
 	podSpec = irtypes.PodSpec(d.convertVolumesKindsByPolicy(core.PodSpec(podSpec), cluster))
 	podSpec.RestartPolicy = core.RestartPolicyAlways
 	logrus.Debugf("Created DeploymentConfig for %s", service.Name)
-	return d.toDeploymentConfig(meta, core.PodSpec(podSpec), int32(service.Replicas), cluster)
+	dc := d.toDeploymentConfig(meta, core.PodSpec(podSpec), int32(service.Replicas), cluster)
+	dc.Spec.Strategy = getDeploymentConfigStrategy(service.RolloutStrategy)
+	dc.Spec.RevisionHistoryLimit = service.RolloutStrategy.RevisionHistoryLimit
+	return dc
 }
 
 // createReplicationController initializes Kubernetes ReplicationController object
@@ -236,11 +303,116 @@ func (d *Deployment) createDaemonSet(service irtypes.Service, cluster collecttyp
 				ObjectMeta: meta,
 				Spec:       core.PodSpec(podSpec),
 			},
+			RevisionHistoryLimit: service.RolloutStrategy.RevisionHistoryLimit,
 		},
 	}
 	return &pod
 }
 
+func (d *Deployment) createStatefulSet(service irtypes.Service, ir irtypes.EnhancedIR, cluster collecttypes.ClusterMetadataSpec) *apps.StatefulSet {
+	podSpec := service.PodSpec
+	podSpec = irtypes.PodSpec(d.convertVolumesKindsByPolicy(core.PodSpec(podSpec), cluster))
+	podSpec.RestartPolicy = core.RestartPolicyAlways
+	volumeClaimTemplates := []core.PersistentVolumeClaim{}
+	volumes := []core.Volume{}
+	for _, volume := range podSpec.Volumes {
+		if claimSpec, ok := getPVCSpec(volume, ir.Storages); ok && !isSharedClaim(volume.PersistentVolumeClaim.ClaimName, ir) {
+			// each pod gets its own claim, the replicas of the databases cannot share a ReadWriteOnce volume.
+			// The claims shared with the other services are mounted as is, since the services exchange data through them.
+			volumeClaimTemplates = append(volumeClaimTemplates, core.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: volume.Name},
+				Spec:       claimSpec,
+			})
+			continue
+		}
+		volumes = append(volumes, volume)
+	}
+	podSpec.Volumes = volumes
+	meta := metav1.ObjectMeta{
+		Name:        service.Name,
+		Labels:      getPodLabels(service.Name, service.Networks),
+		Annotations: getAnnotations(service),
+	}
+	logrus.Debugf("Created statefulset for %s", service.Name)
+	return &apps.StatefulSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       common.StatefulSetKind,
+			APIVersion: apps.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: meta,
+		Spec: apps.StatefulSetSpec{
+			Replicas: int32(service.Replicas),
+			Selector: &metav1.LabelSelector{
+				MatchLabels: getServiceLabels(service.Name),
+			},
+			// the headless service gives the pods the stable DNS names <pod>.<serviceName>.<namespace>.svc
+			ServiceName: getHeadlessServiceName(service.Name),
+			Template: core.PodTemplateSpec{
+				ObjectMeta: meta,
+				Spec:       core.PodSpec(podSpec),
+			},
+			VolumeClaimTemplates: volumeClaimTemplates,
+			RevisionHistoryLimit: service.RolloutStrategy.RevisionHistoryLimit,
+		},
+	}
+}
+
+// isStatefulSetService returns true if the service is deployed as a StatefulSet in the target cluster
+func isStatefulSetService(service irtypes.Service, targetCluster collecttypes.ClusterMetadata) bool {
+	return service.Stateful && !service.Daemon && service.RestartPolicy != core.RestartPolicyNever && service.RestartPolicy != core.RestartPolicyOnFailure && targetCluster.Spec.GetSupportedVersions(common.StatefulSetKind) != nil
+}
+
+// getDeploymentStrategy returns the strategy of the Deployment, the defaults of the cluster are used when the strategy is not set
+func getDeploymentStrategy(rolloutStrategy irtypes.RolloutStrategy) apps.DeploymentStrategy {
+	switch rolloutStrategy.Type {
+	case irtypes.RecreateRolloutStrategy:
+		return apps.DeploymentStrategy{Type: apps.RecreateDeploymentStrategyType}
+	case irtypes.RollingUpdateRolloutStrategy:
+		rollingUpdate := &apps.RollingUpdateDeployment{}
+		if rolloutStrategy.MaxSurge != "" {
+			rollingUpdate.MaxSurge = intstr.Parse(rolloutStrategy.MaxSurge)
+		}
+		if rolloutStrategy.MaxUnavailable != "" {
+			rollingUpdate.MaxUnavailable = intstr.Parse(rolloutStrategy.MaxUnavailable)
+		}
+		return apps.DeploymentStrategy{Type: apps.RollingUpdateDeploymentStrategyType, RollingUpdate: rollingUpdate}
+	}
+	return apps.DeploymentStrategy{}
+}
+
+// getDeploymentConfigStrategy returns the strategy of the DeploymentConfig, which calls the rolling update Rolling
+func getDeploymentConfigStrategy(rolloutStrategy irtypes.RolloutStrategy) okdappsv1.DeploymentStrategy {
+	switch rolloutStrategy.Type {
+	case irtypes.RecreateRolloutStrategy:
+		return okdappsv1.DeploymentStrategy{Type: okdappsv1.DeploymentStrategyTypeRecreate}
+	case irtypes.RollingUpdateRolloutStrategy:
+		rollingParams := &okdappsv1.RollingDeploymentStrategyParams{}
+		if rolloutStrategy.MaxSurge != "" {
+			maxSurge := intstr.Parse(rolloutStrategy.MaxSurge)
+			rollingParams.MaxSurge = &maxSurge
+		}
+		if rolloutStrategy.MaxUnavailable != "" {
+			maxUnavailable := intstr.Parse(rolloutStrategy.MaxUnavailable)
+			rollingParams.MaxUnavailable = &maxUnavailable
+		}
+		return okdappsv1.DeploymentStrategy{Type: okdappsv1.DeploymentStrategyTypeRolling, RollingParams: rollingParams}
+	}
+	return okdappsv1.DeploymentStrategy{}
+}
+
+// getPVCSpec returns the spec of the persistent volume claim used by the volume
+func getPVCSpec(volume core.Volume, storages []irtypes.Storage) (core.PersistentVolumeClaimSpec, bool) {
+	if volume.PersistentVolumeClaim == nil {
+		return core.PersistentVolumeClaimSpec{}, false
+	}
+	for _, storage := range storages {
+		if storage.StorageType == irtypes.PVCKind && storage.Name == volume.PersistentVolumeClaim.ClaimName {
+			return storage.PersistentVolumeClaimSpec, true
+		}
+	}
+	return core.PersistentVolumeClaimSpec{}, false
+}
+
 func (d *Deployment) createJob(service irtypes.Service, cluster collecttypes.ClusterMetadataSpec) *batch.Job {
 	podspec := service.PodSpec
 	podspec = irtypes.PodSpec(d.convertVolumesKindsByPolicy(core.PodSpec(podspec), cluster))
@@ -266,6 +438,24 @@ func (d *Deployment) createJob(service irtypes.Service, cluster collecttypes.Clu
 	return &pod
 }
 
+func (d *Deployment) createCronJob(service irtypes.Service, cluster collecttypes.ClusterMetadataSpec) *batch.CronJob {
+	job := d.createJob(service, cluster)
+	return &batch.CronJob{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       cronJobKind,
+			APIVersion: batch.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: job.ObjectMeta,
+		Spec: batch.CronJobSpec{
+			Schedule: service.Schedule,
+			JobTemplate: batch.JobTemplateSpec{
+				ObjectMeta: job.ObjectMeta,
+				Spec:       job.Spec,
+			},
+		},
+	}
+}
+
 // Conversions section
 
 func (d *Deployment) toDeploymentConfig(meta metav1.ObjectMeta, podspec core.PodSpec, replicas int32, cluster collecttypes.ClusterMetadataSpec) *okdappsv1.DeploymentConfig {
@@ -325,9 +515,44 @@ func (d *Deployment) toDeployment(meta metav1.ObjectMeta, podspec core.PodSpec,
 			},
 		},
 	}
+	if len(cluster.GetSupportedVersions(imageStreamKind)) > 0 {
+		// redeploy when the ImageStreams are updated, like the DeploymentConfig triggers, so that the BuildConfigs work for both kinds
+		dc.ObjectMeta.Annotations = d.getImageStreamTriggersAnnotation(meta.Annotations, podspec)
+	}
 	return dc
 }
 
+// getImageStreamTriggersAnnotation adds the annotation which makes OpenShift update the container images when the ImageStream tags change
+func (d *Deployment) getImageStreamTriggersAnnotation(annotations map[string]string, podspec core.PodSpec) map[string]string {
+	type imageTriggerFrom struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+	}
+	type imageTrigger struct {
+		From      imageTriggerFrom `json:"from"`
+		FieldPath string           `json:"fieldPath"`
+	}
+	triggers := []imageTrigger{}
+	for _, container := range podspec.Containers {
+		imageStreamName, imageStreamTag := new(ImageStream).GetImageStreamNameAndTag(container.Image)
+		triggers = append(triggers, imageTrigger{
+			From:      imageTriggerFrom{Kind: "ImageStreamTag", Name: imageStreamName + ":" + imageStreamTag},
+			FieldPath: fmt.Sprintf(`spec.template.spec.containers[?(@.name=="%s")].image`, container.Name),
+		})
+	}
+	newAnnotations := map[string]string{}
+	for k, v := range annotations {
+		newAnnotations[k] = v
+	}
+	data, err := json.Marshal(triggers)
+	if err != nil {
+		logrus.Errorf("failed to marshal the image triggers to json. Error: %q", err)
+		return newAnnotations
+	}
+	newAnnotations[imageTriggersAnnotation] = string(data)
+	return newAnnotations
+}
+
 // toReplicationController initializes Kubernetes ReplicationController object
 func (d *Deployment) toReplicationController(meta metav1.ObjectMeta, podspec core.PodSpec, replicas int32, cluster collecttypes.ClusterMetadataSpec) *core.ReplicationController {
 	podspec = d.convertVolumesKindsByPolicy(podspec, cluster)
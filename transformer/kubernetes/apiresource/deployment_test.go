@@ -0,0 +1,258 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	okdappsv1 "github.com/openshift/api/apps/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	apps "k8s.io/kubernetes/pkg/apis/apps"
+	batch "k8s.io/kubernetes/pkg/apis/batch"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestCreateNewResourcesCreatesCronJobs(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+	report := irtypes.NewServiceWithName("report")
+	report.Containers = []core.Container{{Name: "report", Image: "report:latest"}}
+	report.RestartPolicy = core.RestartPolicyOnFailure
+	report.Schedule = "0 2 * * *"
+	ir.Services["report"] = report
+	d := &Deployment{}
+	objs := d.createNewResources(ir, d.getSupportedKinds(), collecttypes.NewClusterMetadata("test"))
+	if len(objs) != 1 {
+		t.Fatalf("expected a single object for the scheduled service. Actual: %+v", objs)
+	}
+	cronJob, ok := objs[0].(*batch.CronJob)
+	if !ok {
+		t.Fatalf("expected a cronjob for the scheduled service. Actual: %+v", objs[0])
+	}
+	if cronJob.Spec.Schedule != report.Schedule {
+		t.Fatalf("expected the schedule %q , got %q", report.Schedule, cronJob.Spec.Schedule)
+	}
+	if cronJob.Spec.JobTemplate.Spec.Template.Spec.RestartPolicy != core.RestartPolicyOnFailure || len(cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers) != 1 {
+		t.Fatalf("expected the pod of the service in the job template. Actual: %+v", cronJob.Spec.JobTemplate.Spec.Template.Spec)
+	}
+	objs = d.createNewResources(ir, []string{jobKind, podKind}, collecttypes.NewClusterMetadata("test"))
+	if len(objs) != 1 {
+		t.Fatalf("expected a single object for the scheduled service. Actual: %+v", objs)
+	}
+	if _, ok := objs[0].(*batch.CronJob); ok {
+		t.Fatalf("expected no cronjob when the cluster does not support them")
+	}
+}
+
+func TestCreateNewResourcesCreatesStatefulSets(t *testing.T) {
+	setupQAConfig(t)
+	ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+	ir.Name = "myapp"
+	db := irtypes.NewServiceWithName("db")
+	db.Containers = []core.Container{{Name: "db", Image: "postgres:14"}}
+	db.Replicas = 3
+	db.Stateful = true
+	db.Volumes = []core.Volume{
+		{Name: "data", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "db-data"}}},
+		{Name: "config", VolumeSource: core.VolumeSource{ConfigMap: &core.ConfigMapVolumeSource{LocalObjectReference: core.LocalObjectReference{Name: "db-config"}}}},
+	}
+	if err := db.AddPortForwarding(networking.ServiceBackendPort{Number: 5432}, networking.ServiceBackendPort{Number: 5432}, ""); err != nil {
+		t.Fatalf("failed to add the port forwarding. Error: %q", err)
+	}
+	ir.Services["db"] = db
+	claimSpec := core.PersistentVolumeClaimSpec{AccessModes: []core.PersistentVolumeAccessMode{core.ReadWriteOnce}}
+	ir.Storages = []irtypes.Storage{{Name: "db-data", StorageType: irtypes.PVCKind, PersistentVolumeClaimSpec: claimSpec}}
+	cluster := newRolloutTestCluster()
+	cluster.Spec.APIKindVersionMap[common.StatefulSetKind] = []string{"apps/v1"}
+	cluster.Spec.APIKindVersionMap[string(irtypes.PVCKind)] = []string{"v1"}
+
+	d := &Deployment{}
+	objs := d.createNewResources(ir, d.getSupportedKinds(), cluster)
+	if len(objs) != 1 {
+		t.Fatalf("expected a single object for the stateful service. Actual: %+v", objs)
+	}
+	statefulSet, ok := objs[0].(*apps.StatefulSet)
+	if !ok {
+		t.Fatalf("expected a statefulset for the stateful service. Actual: %+v", objs[0])
+	}
+	if statefulSet.Spec.ServiceName != "db-headless" || statefulSet.Spec.Replicas != 3 {
+		t.Fatalf("expected 3 replicas governed by the headless service db-headless. Actual: %+v", statefulSet.Spec)
+	}
+	if len(statefulSet.Spec.VolumeClaimTemplates) != 1 || statefulSet.Spec.VolumeClaimTemplates[0].Name != "data" || !cmp.Equal(statefulSet.Spec.VolumeClaimTemplates[0].Spec, claimSpec) {
+		t.Fatalf("expected the claim of the volume data to become a volume claim template. Actual: %+v", statefulSet.Spec.VolumeClaimTemplates)
+	}
+	if volumes := statefulSet.Spec.Template.Spec.Volumes; len(volumes) != 1 || volumes[0].Name != "config" {
+		t.Fatalf("expected only the config map volume in the pod. Actual: %+v", volumes)
+	}
+
+	svcObjs := getObjectsByKind((&Service{}).createNewResources(ir, []string{common.ServiceKind}, cluster))
+	services := map[string]*core.Service{}
+	for _, obj := range svcObjs[common.ServiceKind] {
+		svc := obj.(*core.Service)
+		services[svc.Name] = svc
+	}
+	headless, ok := services["db-headless"]
+	if !ok || len(services) != 2 {
+		t.Fatalf("expected the service db and the headless service db-headless. Actual: %+v", services)
+	}
+	if headless.Spec.ClusterIP != core.ClusterIPNone || !headless.Spec.PublishNotReadyAddresses || headless.Spec.Type != core.ServiceTypeClusterIP {
+		t.Fatalf("expected a headless service publishing the not ready addresses. Actual: %+v", headless.Spec)
+	}
+	if !cmp.Equal(headless.Spec.Selector, services["db"].Spec.Selector) {
+		t.Fatalf("expected the headless service to select the pods of the service. Differences: %s", cmp.Diff(services["db"].Spec.Selector, headless.Spec.Selector))
+	}
+
+	if objs := (&Storage{}).createNewResources(ir, []string{string(irtypes.PVCKind)}, cluster); len(objs) != 0 {
+		t.Fatalf("expected no claim for the volume claimed only by the statefulset. Actual: %+v", objs)
+	}
+	worker := irtypes.NewServiceWithName("worker")
+	worker.Containers = []core.Container{{Name: "worker", Image: "worker:latest"}}
+	worker.Volumes = db.Volumes[:1]
+	ir.Services["worker"] = worker
+	if objs := (&Storage{}).createNewResources(ir, []string{string(irtypes.PVCKind)}, cluster); len(objs) != 1 {
+		t.Fatalf("expected the claim shared with the deployment. Actual: %+v", objs)
+	}
+}
+
+func TestCreateNewResourcesChoosesTheWorkloadKindOnOpenShift(t *testing.T) {
+	newIR := func() irtypes.EnhancedIR {
+		ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+		ir.Name = "myproject"
+		api := irtypes.NewServiceWithName("api")
+		api.Containers = []core.Container{{Name: "api", Image: "api:latest"}}
+		ir.Services["api"] = api
+		return ir
+	}
+	cluster := collecttypes.NewClusterMetadata("openshift")
+	cluster.Spec.APIKindVersionMap = map[string][]string{
+		common.DeploymentKind: {"apps/v1"},
+		deploymentConfigKind:  {"apps.openshift.io/v1"},
+		imageStreamKind:       {"image.openshift.io/v1"},
+	}
+	d := &Deployment{}
+
+	t.Run("deployments with the image triggers by default", func(t *testing.T) {
+		setupQAConfig(t)
+		objs := d.createNewResources(newIR(), d.getSupportedKinds(), cluster)
+		if len(objs) != 1 {
+			t.Fatalf("expected a single object. Actual: %+v", objs)
+		}
+		deployment, ok := objs[0].(*apps.Deployment)
+		if !ok {
+			t.Fatalf("expected a deployment. Actual: %T", objs[0])
+		}
+		want := `[{"from":{"kind":"ImageStreamTag","name":"api-latest:latest"},"fieldPath":"spec.template.spec.containers[?(@.name==\"api\")].image"}]`
+		if got := deployment.Annotations[imageTriggersAnnotation]; got != want {
+			t.Fatalf("got the image triggers %s , want %s", got, want)
+		}
+	})
+	t.Run("deployment configs", func(t *testing.T) {
+		setupQAConfig(t, common.JoinQASubKeys(getClusterQaID(cluster), common.ConfigWorkloadKindKeySuffix)+`="`+deploymentConfigKind+`"`)
+		objs := d.createNewResources(newIR(), d.getSupportedKinds(), cluster)
+		if len(objs) != 1 {
+			t.Fatalf("expected a single object. Actual: %+v", objs)
+		}
+		deploymentConfig, ok := objs[0].(*okdappsv1.DeploymentConfig)
+		if !ok {
+			t.Fatalf("expected a deployment config. Actual: %T", objs[0])
+		}
+		if _, ok := deploymentConfig.Annotations[imageTriggersAnnotation]; ok {
+			t.Fatalf("expected the deployment config to use its own triggers instead of the annotation. Actual: %+v", deploymentConfig.Annotations)
+		}
+	})
+	t.Run("no image triggers without image streams", func(t *testing.T) {
+		setupQAConfig(t)
+		objs := d.createNewResources(newIR(), d.getSupportedKinds(), newRolloutTestCluster())
+		deployment, ok := objs[0].(*apps.Deployment)
+		if !ok {
+			t.Fatalf("expected a deployment. Actual: %T", objs[0])
+		}
+		if _, ok := deployment.Annotations[imageTriggersAnnotation]; ok {
+			t.Fatalf("expected no image triggers without image streams. Actual: %+v", deployment.Annotations)
+		}
+	})
+}
+
+func TestCreateNewResourcesSetsTheRolloutStrategy(t *testing.T) {
+	setupQAConfig(t)
+	limit := int32(3)
+	ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+	api := irtypes.NewServiceWithName("api")
+	api.Containers = []core.Container{{Name: "api", Image: "api:latest"}}
+	api.RolloutStrategy = irtypes.RolloutStrategy{Type: irtypes.RollingUpdateRolloutStrategy, MaxSurge: "1", MaxUnavailable: "25%", RevisionHistoryLimit: &limit}
+	ir.Services["api"] = api
+	d := &Deployment{}
+	objs := d.createNewResources(ir, d.getSupportedKinds(), collecttypes.NewClusterMetadata("test"))
+	if len(objs) != 1 {
+		t.Fatalf("expected a single object for the service. Actual: %+v", objs)
+	}
+	deployment, ok := objs[0].(*apps.Deployment)
+	if !ok {
+		t.Fatalf("expected a deployment for the service. Actual: %+v", objs[0])
+	}
+	wantStrategy := apps.DeploymentStrategy{Type: apps.RollingUpdateDeploymentStrategyType, RollingUpdate: &apps.RollingUpdateDeployment{MaxSurge: intstr.FromInt(1), MaxUnavailable: intstr.FromString("25%")}}
+	if diff := cmp.Diff(wantStrategy, deployment.Spec.Strategy); diff != "" {
+		t.Errorf("the strategy of the deployment is not correct. Diff (-want +got):\n%s", diff)
+	}
+	if deployment.Spec.RevisionHistoryLimit == nil || *deployment.Spec.RevisionHistoryLimit != limit {
+		t.Errorf("expected the revision history limit %d , got %v", limit, deployment.Spec.RevisionHistoryLimit)
+	}
+}
+
+func TestGetDeploymentStrategy(t *testing.T) {
+	testCases := []struct {
+		strategy irtypes.RolloutStrategy
+		want     apps.DeploymentStrategy
+		wantDC   okdappsv1.DeploymentStrategy
+	}{
+		{strategy: irtypes.RolloutStrategy{}},
+		{
+			strategy: irtypes.RolloutStrategy{Type: irtypes.RecreateRolloutStrategy},
+			want:     apps.DeploymentStrategy{Type: apps.RecreateDeploymentStrategyType},
+			wantDC:   okdappsv1.DeploymentStrategy{Type: okdappsv1.DeploymentStrategyTypeRecreate},
+		},
+		{
+			strategy: irtypes.RolloutStrategy{Type: irtypes.RollingUpdateRolloutStrategy},
+			want:     apps.DeploymentStrategy{Type: apps.RollingUpdateDeploymentStrategyType, RollingUpdate: &apps.RollingUpdateDeployment{}},
+			wantDC:   okdappsv1.DeploymentStrategy{Type: okdappsv1.DeploymentStrategyTypeRolling, RollingParams: &okdappsv1.RollingDeploymentStrategyParams{}},
+		},
+		{
+			strategy: irtypes.RolloutStrategy{Type: irtypes.RollingUpdateRolloutStrategy, MaxSurge: "0", MaxUnavailable: "50%"},
+			want:     apps.DeploymentStrategy{Type: apps.RollingUpdateDeploymentStrategyType, RollingUpdate: &apps.RollingUpdateDeployment{MaxSurge: intstr.FromInt(0), MaxUnavailable: intstr.FromString("50%")}},
+			wantDC: okdappsv1.DeploymentStrategy{Type: okdappsv1.DeploymentStrategyTypeRolling, RollingParams: &okdappsv1.RollingDeploymentStrategyParams{
+				MaxSurge:       &[]intstr.IntOrString{intstr.FromInt(0)}[0],
+				MaxUnavailable: &[]intstr.IntOrString{intstr.FromString("50%")}[0],
+			}},
+		},
+	}
+	for _, testCase := range testCases {
+		if diff := cmp.Diff(testCase.want, getDeploymentStrategy(testCase.strategy)); diff != "" {
+			t.Errorf("getDeploymentStrategy(%+v) is not correct. Diff (-want +got):\n%s", testCase.strategy, diff)
+		}
+		if diff := cmp.Diff(testCase.wantDC, getDeploymentConfigStrategy(testCase.strategy)); diff != "" {
+			t.Errorf("getDeploymentConfigStrategy(%+v) is not correct. Diff (-want +got):\n%s", testCase.strategy, diff)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// flaggerCanaryKind defines the Flagger Canary Kind
+	flaggerCanaryKind = "Canary"
+	flaggerAPIVersion = "flagger.app/v1beta1"
+	// kubernetesFlaggerProvider is used when the traffic cannot be split between the versions
+	kubernetesFlaggerProvider = "kubernetes"
+)
+
+// createFlaggerCanary creates a Flagger Canary which rolls out new versions of the deployment of the service
+func (d *Deployment) createFlaggerCanary(service irtypes.Service, ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) *unstructured.Unstructured {
+	forwarding := service.ServiceToPodPortForwardings[0]
+	canaryService := map[string]interface{}{"port": int64(forwarding.ServicePort.Number)}
+	if forwarding.PodPort.Name != "" {
+		canaryService["targetPort"] = forwarding.PodPort.Name
+	} else if forwarding.PodPort.Number != 0 {
+		canaryService["targetPort"] = int64(forwarding.PodPort.Number)
+	}
+	spec := map[string]interface{}{
+		"targetRef": map[string]interface{}{
+			"apiVersion": appsv1.SchemeGroupVersion.String(),
+			"kind":       common.DeploymentKind,
+			"name":       service.Name,
+		},
+		"progressDeadlineSeconds": int64(600),
+		"service":                 canaryService,
+	}
+	provider := kubernetesFlaggerProvider
+	switch commonqa.ServiceMesh() {
+	case commonqa.IstioServiceMesh:
+		provider = "istio"
+	case commonqa.LinkerdServiceMesh:
+		provider = "linkerd"
+	default:
+		if isExposedThroughNginxIngress(service, targetCluster) {
+			provider = nginxIngressControllerPreset
+			spec["ingressRef"] = map[string]interface{}{
+				"apiVersion": networkingv1.SchemeGroupVersion.String(),
+				"kind":       common.IngressKind,
+				"name":       ir.Name,
+			}
+		}
+	}
+	spec["provider"] = provider
+	// the analysis fails after the checks fail the threshold number of times
+	analysis := map[string]interface{}{
+		"interval":  "1m",
+		"threshold": int64(5),
+	}
+	if provider == kubernetesFlaggerProvider {
+		// the new version is tested for a number of iterations before all the traffic is switched to it
+		logrus.Debugf("The requests to the service %s cannot be split between the versions. Using a blue-green Flagger canary.", service.Name)
+		analysis["iterations"] = int64(10)
+	} else {
+		analysis["maxWeight"] = int64(50)
+		analysis["stepWeight"] = int64(10)
+		analysis["metrics"] = []interface{}{
+			map[string]interface{}{
+				"name":           "request-success-rate",
+				"thresholdRange": map[string]interface{}{"min": int64(commonqa.ProgressiveDeliveryMinSuccessRate())},
+				"interval":       "1m",
+			},
+			map[string]interface{}{
+				"name":           "request-duration",
+				"thresholdRange": map[string]interface{}{"max": int64(commonqa.ProgressiveDeliveryMaxRequestDuration())},
+				"interval":       "1m",
+			},
+		}
+	}
+	spec["analysis"] = analysis
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": flaggerAPIVersion,
+		"kind":       flaggerCanaryKind,
+		"metadata":   map[string]interface{}{"name": service.Name},
+		"spec":       spec,
+	}}
+}
@@ -0,0 +1,99 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestCreateFlaggerCanaries(t *testing.T) {
+	testCases := []struct {
+		name         string
+		serviceMesh  string
+		wantProvider string
+	}{
+		{name: "without a service mesh", serviceMesh: commonqa.NoServiceMesh, wantProvider: kubernetesFlaggerProvider},
+		{name: "istio", serviceMesh: commonqa.IstioServiceMesh, wantProvider: "istio"},
+		{name: "linkerd", serviceMesh: commonqa.LinkerdServiceMesh, wantProvider: "linkerd"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			setupProgressiveDelivery(t, commonqa.FlaggerCanaryProgressiveDelivery)
+			qaengine.SetupConfigFile("", []string{
+				common.ConfigServiceMeshKey + `="` + testCase.serviceMesh + `"`,
+				common.ConfigProgressiveDeliverySuccessRateKey + "=95",
+				common.ConfigProgressiveDeliveryRequestDurationKey + "=250",
+			}, nil, nil, false)
+			ir := newRolloutTestIR(t)
+			d := &Deployment{}
+			objsByKind := getObjectsByKind(d.createNewResources(ir, d.getSupportedKinds(), newRolloutTestCluster()))
+			if len(objsByKind[rolloutKind]) != 0 || len(objsByKind[analysisTemplateKind]) != 0 {
+				t.Fatalf("expected no Argo Rollouts resources. Actual: %+v", objsByKind)
+			}
+			if len(objsByKind[common.DeploymentKind]) != 2 {
+				t.Fatalf("expected the deployments to be kept. Actual: %+v", objsByKind[common.DeploymentKind])
+			}
+			if len(objsByKind[flaggerCanaryKind]) != 1 {
+				t.Fatalf("expected a canary for the service with ports only. Actual: %+v", objsByKind[flaggerCanaryKind])
+			}
+			canary := objsByKind[flaggerCanaryKind][0].(*unstructured.Unstructured)
+			if canary.GetAPIVersion() != flaggerAPIVersion || canary.GetName() != "api" {
+				t.Fatalf("got the canary %s %s , want %s api", canary.GetAPIVersion(), canary.GetName(), flaggerAPIVersion)
+			}
+			targetRef, _, _ := unstructured.NestedStringMap(canary.Object, "spec", "targetRef")
+			if targetRef["kind"] != common.DeploymentKind || targetRef["name"] != "api" || targetRef["apiVersion"] != "apps/v1" {
+				t.Fatalf("expected the canary to target the api deployment. Actual: %+v", targetRef)
+			}
+			if port, _, _ := unstructured.NestedInt64(canary.Object, "spec", "service", "port"); port != 8080 {
+				t.Fatalf("got the service port %d , want 8080", port)
+			}
+			if provider, _, _ := unstructured.NestedString(canary.Object, "spec", "provider"); provider != testCase.wantProvider {
+				t.Fatalf("got the provider %q , want %q", provider, testCase.wantProvider)
+			}
+			if _, ok, _ := unstructured.NestedFieldNoCopy(canary.Object, "spec", "ingressRef"); ok {
+				t.Fatalf("expected no ingress reference for the service which is not exposed")
+			}
+			analysis, _, _ := unstructured.NestedMap(canary.Object, "spec", "analysis")
+			if testCase.wantProvider == kubernetesFlaggerProvider {
+				if analysis["iterations"] != int64(10) || analysis["metrics"] != nil || analysis["stepWeight"] != nil {
+					t.Fatalf("expected a blue-green analysis without traffic splitting. Actual: %+v", analysis)
+				}
+				return
+			}
+			if analysis["maxWeight"] != int64(50) || analysis["stepWeight"] != int64(10) {
+				t.Fatalf("expected the traffic to be shifted gradually. Actual: %+v", analysis)
+			}
+			metrics, _, _ := unstructured.NestedSlice(canary.Object, "spec", "analysis", "metrics")
+			if len(metrics) != 2 {
+				t.Fatalf("expected the success rate and the request duration metrics. Actual: %+v", metrics)
+			}
+			successRate := metrics[0].(map[string]interface{})
+			if successRate["name"] != "request-success-rate" || successRate["thresholdRange"].(map[string]interface{})["min"] != int64(95) {
+				t.Fatalf("expected the configured minimum success rate. Actual: %+v", successRate)
+			}
+			duration := metrics[1].(map[string]interface{})
+			if duration["name"] != "request-duration" || duration["thresholdRange"].(map[string]interface{})["max"] != int64(250) {
+				t.Fatalf("expected the configured maximum request duration. Actual: %+v", duration)
+			}
+		})
+	}
+}
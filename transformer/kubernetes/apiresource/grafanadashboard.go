@@ -0,0 +1,234 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// grafanaDashboardLabel makes the Grafana sidecar load the dashboards in the config map
+	grafanaDashboardLabel   = "grafana_dashboard"
+	grafanaDashboardSuffix  = "-grafana-dashboard"
+	grafanaDatasourceVar    = "${datasource}"
+	grafanaPanelWidth       = 8
+	grafanaPanelHeight      = 8
+	grafanaPanelsPerService = 3
+)
+
+// redQueries has the PromQL queries for the rate, the errors and the duration of the requests to a service
+type redQueries struct {
+	rate     string
+	errors   string
+	duration string
+	// durationUnit is the Grafana unit of the duration query
+	durationUnit string
+}
+
+type grafanaDashboard struct {
+	Title         string            `json:"title"`
+	UID           string            `json:"uid"`
+	Tags          []string          `json:"tags"`
+	SchemaVersion int               `json:"schemaVersion"`
+	Refresh       string            `json:"refresh"`
+	Time          map[string]string `json:"time"`
+	Templating    grafanaTemplating `json:"templating"`
+	Panels        []grafanaPanel    `json:"panels"`
+}
+
+type grafanaTemplating struct {
+	List []grafanaVariable `json:"list"`
+}
+
+type grafanaVariable struct {
+	Name  string `json:"name"`
+	Label string `json:"label"`
+	Type  string `json:"type"`
+	Query string `json:"query"`
+}
+
+type grafanaPanel struct {
+	ID          int                    `json:"id"`
+	Title       string                 `json:"title"`
+	Type        string                 `json:"type"`
+	Datasource  map[string]string      `json:"datasource,omitempty"`
+	GridPos     map[string]int         `json:"gridPos"`
+	FieldConfig map[string]interface{} `json:"fieldConfig,omitempty"`
+	Targets     []grafanaTarget        `json:"targets,omitempty"`
+}
+
+type grafanaTarget struct {
+	RefID        string `json:"refId"`
+	Expr         string `json:"expr"`
+	LegendFormat string `json:"legendFormat"`
+}
+
+// GrafanaDashboard creates a config map with a starter Grafana dashboard showing the request rate, errors and duration
+// of the services of the application. The dashboard is loaded by the Grafana sidecar which watches for labelled config maps.
+type GrafanaDashboard struct {
+}
+
+// getSupportedKinds returns kinds supported by the grafana dashboard
+func (g *GrafanaDashboard) getSupportedKinds() []string {
+	return []string{string(irtypes.ConfigMapKind)}
+}
+
+// createNewResources converts ir to runtime objects
+func (g *GrafanaDashboard) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	objs := []runtime.Object{}
+	serviceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if !service.OnlyIngress && len(service.ServiceToPodPortForwardings) > 0 {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	if len(serviceNames) == 0 {
+		return objs
+	}
+	if !qaengine.FetchBoolAnswer(
+		common.ConfigGrafanaDashboardKey,
+		"Generate a starter Grafana dashboard for the request rate, errors and duration of the services?",
+		[]string{
+			"The dashboard is stored in a config map which is loaded by the Grafana dashboard sidecar",
+			"The metrics are taken from the service mesh, or from the NGINX ingress controller if there is no service mesh",
+		},
+		false,
+		nil,
+	) {
+		return objs
+	}
+	sort.Strings(serviceNames)
+	dashboard := getGrafanaDashboard(ir.Name, serviceNames, commonqa.ServiceMesh())
+	content, err := json.MarshalIndent(dashboard, "", "  ")
+	if err != nil {
+		logrus.Errorf("Failed to marshal the Grafana dashboard to json. Error: %q", err)
+		return objs
+	}
+	name := common.MakeStringK8sServiceNameCompliant(ir.Name + grafanaDashboardSuffix)
+	objs = append(objs, &core.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       string(irtypes.ConfigMapKind),
+			APIVersion: core.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{grafanaDashboardLabel: "1"},
+		},
+		Data: map[string]string{name + ".json": string(content)},
+	})
+	return objs
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (g *GrafanaDashboard) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsPresent(g.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
+
+// getGrafanaDashboard returns a dashboard with a row of panels for each service
+func getGrafanaDashboard(appName string, serviceNames []string, serviceMesh string) grafanaDashboard {
+	dashboard := grafanaDashboard{
+		Title:         appName,
+		UID:           common.MakeStringK8sServiceNameCompliant(appName),
+		Tags:          []string{types.AppName},
+		SchemaVersion: 36,
+		Refresh:       "30s",
+		Time:          map[string]string{"from": "now-1h", "to": "now"},
+		Templating: grafanaTemplating{List: []grafanaVariable{{
+			Name:  "datasource",
+			Label: "Data source",
+			Type:  "datasource",
+			Query: "prometheus",
+		}}},
+		Panels: []grafanaPanel{},
+	}
+	datasource := map[string]string{"type": "prometheus", "uid": grafanaDatasourceVar}
+	for i, serviceName := range serviceNames {
+		queries := getREDQueries(serviceName, serviceMesh)
+		y := i * (grafanaPanelHeight + 1)
+		id := i * (grafanaPanelsPerService + 1)
+		dashboard.Panels = append(dashboard.Panels,
+			grafanaPanel{
+				ID:      id + 1,
+				Title:   serviceName,
+				Type:    "row",
+				GridPos: map[string]int{"h": 1, "w": grafanaPanelWidth * grafanaPanelsPerService, "x": 0, "y": y},
+			},
+			getGrafanaTimeSeriesPanel(id+2, "Request rate", "reqps", queries.rate, datasource, 0, y+1),
+			getGrafanaTimeSeriesPanel(id+3, "Error rate", "reqps", queries.errors, datasource, grafanaPanelWidth, y+1),
+			getGrafanaTimeSeriesPanel(id+4, "Request duration (p99)", queries.durationUnit, queries.duration, datasource, 2*grafanaPanelWidth, y+1),
+		)
+	}
+	return dashboard
+}
+
+func getGrafanaTimeSeriesPanel(id int, title, unit, expr string, datasource map[string]string, x, y int) grafanaPanel {
+	return grafanaPanel{
+		ID:          id,
+		Title:       title,
+		Type:        "timeseries",
+		Datasource:  datasource,
+		GridPos:     map[string]int{"h": grafanaPanelHeight, "w": grafanaPanelWidth, "x": x, "y": y},
+		FieldConfig: map[string]interface{}{"defaults": map[string]interface{}{"unit": unit}, "overrides": []interface{}{}},
+		Targets:     []grafanaTarget{{RefID: "A", Expr: expr, LegendFormat: title}},
+	}
+}
+
+// getREDQueries returns the queries for the metrics collected by the service mesh or the NGINX ingress controller
+func getREDQueries(serviceName, serviceMesh string) redQueries {
+	switch serviceMesh {
+	case commonqa.IstioServiceMesh:
+		selector := fmt.Sprintf(`reporter="destination",destination_service_name="%s"`, serviceName)
+		return redQueries{
+			rate:         fmt.Sprintf(`sum(rate(istio_requests_total{%s}[5m]))`, selector),
+			errors:       fmt.Sprintf(`sum(rate(istio_requests_total{%s,response_code=~"5.."}[5m]))`, selector),
+			duration:     fmt.Sprintf(`histogram_quantile(0.99, sum(rate(istio_request_duration_milliseconds_bucket{%s}[5m])) by (le))`, selector),
+			durationUnit: "ms",
+		}
+	case commonqa.LinkerdServiceMesh:
+		selector := fmt.Sprintf(`direction="inbound",deployment="%s"`, serviceName)
+		return redQueries{
+			rate:         fmt.Sprintf(`sum(rate(request_total{%s}[5m]))`, selector),
+			errors:       fmt.Sprintf(`sum(rate(response_total{%s,classification="failure"}[5m]))`, selector),
+			duration:     fmt.Sprintf(`histogram_quantile(0.99, sum(rate(response_latency_ms_bucket{%s}[5m])) by (le))`, selector),
+			durationUnit: "ms",
+		}
+	default:
+		selector := fmt.Sprintf(`service="%s"`, serviceName)
+		return redQueries{
+			rate:         fmt.Sprintf(`sum(rate(nginx_ingress_controller_requests{%s}[5m]))`, selector),
+			errors:       fmt.Sprintf(`sum(rate(nginx_ingress_controller_requests{%s,status=~"5.."}[5m]))`, selector),
+			duration:     fmt.Sprintf(`histogram_quantile(0.99, sum(rate(nginx_ingress_controller_request_duration_seconds_bucket{%s}[5m])) by (le))`, selector),
+			durationUnit: "s",
+		}
+	}
+}
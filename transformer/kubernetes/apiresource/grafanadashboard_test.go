@@ -0,0 +1,98 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestCreateGrafanaDashboard(t *testing.T) {
+	t.Run("the dashboard is not generated by default", func(t *testing.T) {
+		setupQAConfig(t)
+		g := &GrafanaDashboard{}
+		if objs := g.createNewResources(newRolloutTestIR(t), g.getSupportedKinds(), newRolloutTestCluster()); len(objs) != 0 {
+			t.Fatalf("expected no config maps. Actual: %+v", objs)
+		}
+	})
+
+	testCases := []struct {
+		name         string
+		serviceMesh  string
+		wantRate     string
+		durationUnit string
+	}{
+		{name: "nginx ingress", serviceMesh: commonqa.NoServiceMesh, wantRate: `sum(rate(nginx_ingress_controller_requests{service="api"}[5m]))`, durationUnit: "s"},
+		{name: "istio", serviceMesh: commonqa.IstioServiceMesh, wantRate: `sum(rate(istio_requests_total{reporter="destination",destination_service_name="api"}[5m]))`, durationUnit: "ms"},
+		{name: "linkerd", serviceMesh: commonqa.LinkerdServiceMesh, wantRate: `sum(rate(request_total{direction="inbound",deployment="api"}[5m]))`, durationUnit: "ms"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			setupQAConfig(t, common.ConfigGrafanaDashboardKey+"=true", common.ConfigServiceMeshKey+`="`+testCase.serviceMesh+`"`)
+			ir := newRolloutTestIR(t)
+			ir.Name = "myapp"
+			g := &GrafanaDashboard{}
+			objs := g.createNewResources(ir, g.getSupportedKinds(), newRolloutTestCluster())
+			if len(objs) != 1 {
+				t.Fatalf("expected a single config map. Actual: %+v", objs)
+			}
+			configMap := objs[0].(*core.ConfigMap)
+			if configMap.Name != "myapp-grafana-dashboard" || configMap.Labels[grafanaDashboardLabel] != "1" {
+				t.Fatalf("expected the config map myapp-grafana-dashboard with the sidecar label. Actual: %s %+v", configMap.Name, configMap.Labels)
+			}
+			content, ok := configMap.Data["myapp-grafana-dashboard.json"]
+			if !ok {
+				t.Fatalf("expected the dashboard in the config map. Actual: %+v", configMap.Data)
+			}
+			dashboard := grafanaDashboard{}
+			if err := json.Unmarshal([]byte(content), &dashboard); err != nil {
+				t.Fatalf("failed to parse the dashboard. Error: %q", err)
+			}
+			if dashboard.Title != "myapp" || dashboard.UID != "myapp" {
+				t.Fatalf("got the dashboard %q with the uid %q , want myapp", dashboard.Title, dashboard.UID)
+			}
+			// only the service with ports gets a row of panels
+			if len(dashboard.Panels) != grafanaPanelsPerService+1 {
+				t.Fatalf("expected a row and %d panels. Actual: %+v", grafanaPanelsPerService, dashboard.Panels)
+			}
+			if row := dashboard.Panels[0]; row.Type != "row" || row.Title != "api" {
+				t.Fatalf("expected a row for the api service. Actual: %+v", row)
+			}
+			rate := dashboard.Panels[1]
+			if len(rate.Targets) != 1 || rate.Targets[0].Expr != testCase.wantRate {
+				t.Fatalf("got the request rate targets %+v , want the query %s", rate.Targets, testCase.wantRate)
+			}
+			if rate.Datasource["uid"] != grafanaDatasourceVar {
+				t.Fatalf("expected the panels to use the datasource variable. Actual: %+v", rate.Datasource)
+			}
+			errors := dashboard.Panels[2]
+			if len(errors.Targets) != 1 || !strings.Contains(errors.Targets[0].Expr, `"api"`) {
+				t.Fatalf("expected the error rate of the api service. Actual: %+v", errors.Targets)
+			}
+			duration := dashboard.Panels[3]
+			unit := duration.FieldConfig["defaults"].(map[string]interface{})["unit"]
+			if unit != testCase.durationUnit || !strings.HasPrefix(duration.Targets[0].Expr, "histogram_quantile(0.99,") {
+				t.Fatalf("expected the p99 request duration in %s . Actual: %+v", testCase.durationUnit, duration)
+			}
+		})
+	}
+}
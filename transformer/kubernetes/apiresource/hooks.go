@@ -0,0 +1,114 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// PreTransformHook can mutate the IR before it is converted to objects
+type PreTransformHook func(ir *irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) error
+
+// PostTransformHook can inspect and modify the objects created from the IR before they are converted to the target cluster versions
+type PostTransformHook func(ir irtypes.EnhancedIR, objs []runtime.Object, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, error)
+
+type namedPreTransformHook struct {
+	name string
+	hook PreTransformHook
+}
+
+type namedPostTransformHook struct {
+	name string
+	hook PostTransformHook
+}
+
+var (
+	hooksMutex         sync.RWMutex
+	preTransformHooks  []namedPreTransformHook
+	postTransformHooks []namedPostTransformHook
+)
+
+// RegisterPreTransformHook registers a hook that runs every time an IR is converted to objects.
+// Hooks run in the order in which they are registered.
+func RegisterPreTransformHook(name string, hook PreTransformHook) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	preTransformHooks = append(preTransformHooks, namedPreTransformHook{name: name, hook: hook})
+}
+
+// RegisterPostTransformHook registers a hook that runs every time objects are created from an IR.
+// Hooks run in the order in which they are registered.
+func RegisterPostTransformHook(name string, hook PostTransformHook) {
+	hooksMutex.Lock()
+	defer hooksMutex.Unlock()
+	postTransformHooks = append(postTransformHooks, namedPostTransformHook{name: name, hook: hook})
+}
+
+// runPreTransformHooks returns a copy of the IR modified by the hooks. The original IR is left untouched
+// since it is shared with the other transformers.
+func runPreTransformHooks(ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) (irtypes.EnhancedIR, error) {
+	hooksMutex.RLock()
+	defer hooksMutex.RUnlock()
+	if len(preTransformHooks) == 0 {
+		return ir, nil
+	}
+	irCopy, err := copyIR(ir)
+	if err != nil {
+		return ir, err
+	}
+	for _, h := range preTransformHooks {
+		logrus.Debugf("running the pre transform hook %s", h.name)
+		if err := h.hook(&irCopy, targetCluster); err != nil {
+			return ir, fmt.Errorf("the pre transform hook %s failed. Error: %w", h.name, err)
+		}
+	}
+	return irCopy, nil
+}
+
+func runPostTransformHooks(ir irtypes.EnhancedIR, objs []runtime.Object, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, error) {
+	hooksMutex.RLock()
+	defer hooksMutex.RUnlock()
+	for _, h := range postTransformHooks {
+		logrus.Debugf("running the post transform hook %s", h.name)
+		newObjs, err := h.hook(ir, objs, targetCluster)
+		if err != nil {
+			return objs, fmt.Errorf("the post transform hook %s failed. Error: %w", h.name, err)
+		}
+		objs = newObjs
+	}
+	return objs, nil
+}
+
+// copyIR deep copies the IR. The IR contains kubernetes internal types which only round trip through json.
+func copyIR(ir irtypes.EnhancedIR) (irtypes.EnhancedIR, error) {
+	data, err := json.Marshal(ir)
+	if err != nil {
+		return ir, fmt.Errorf("failed to marshal the IR to json. Error: %w", err)
+	}
+	irCopy := irtypes.EnhancedIR{}
+	if err := json.Unmarshal(data, &irCopy); err != nil {
+		return ir, fmt.Errorf("failed to unmarshal the IR from json. Error: %w", err)
+	}
+	return irCopy, nil
+}
@@ -0,0 +1,60 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestTransformHooks(t *testing.T) {
+	defer func() {
+		preTransformHooks = nil
+		postTransformHooks = nil
+	}()
+	RegisterPreTransformHook("replicas", func(ir *irtypes.EnhancedIR, _ collecttypes.ClusterMetadata) error {
+		svc := ir.Services["svc1"]
+		svc.Replicas = 5
+		ir.Services["svc1"] = svc
+		return nil
+	})
+	RegisterPostTransformHook("drop", func(_ irtypes.EnhancedIR, objs []runtime.Object, _ collecttypes.ClusterMetadata) ([]runtime.Object, error) {
+		return objs[1:], nil
+	})
+	ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+	ir.Services["svc1"] = irtypes.NewServiceWithName("svc1")
+	newIR, err := runPreTransformHooks(ir, collecttypes.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to run the pre transform hooks. Error: %q", err)
+	}
+	if newIR.Services["svc1"].Replicas != 5 {
+		t.Fatalf("the pre transform hook did not modify the IR. Expected replicas 5 Actual %d", newIR.Services["svc1"].Replicas)
+	}
+	if ir.Services["svc1"].Replicas != 0 {
+		t.Fatalf("the pre transform hook modified the original IR")
+	}
+	objs, err := runPostTransformHooks(newIR, []runtime.Object{createService("svc1", nil), createService("svc2", nil)}, collecttypes.ClusterMetadata{})
+	if err != nil {
+		t.Fatalf("failed to run the post transform hooks. Error: %q", err)
+	}
+	if len(objs) != 1 {
+		t.Fatalf("expected the post transform hook to drop an object. Actual objects %+v", objs)
+	}
+}
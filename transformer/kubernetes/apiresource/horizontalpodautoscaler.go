@@ -0,0 +1,236 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"math"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	okdappsv1 "github.com/openshift/api/apps/v1"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	autoscaling "k8s.io/kubernetes/pkg/apis/autoscaling"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// horizontalPodAutoscalerKind defines HorizontalPodAutoscaler Kind
+	horizontalPodAutoscalerKind = "HorizontalPodAutoscaler"
+	cpuUtilizationMetric        = "CPU utilization"
+	requestsPerSecondMetric     = "Requests per second"
+	queueDepthMetric            = "Queue depth"
+)
+
+// HorizontalPodAutoscaler handles the autoscaling of the workloads
+type HorizontalPodAutoscaler struct {
+}
+
+// getSupportedKinds returns kinds supported by the horizontal pod autoscaler
+func (h *HorizontalPodAutoscaler) getSupportedKinds() []string {
+	return []string{horizontalPodAutoscalerKind}
+}
+
+// createNewResources converts ir to runtime objects
+func (h *HorizontalPodAutoscaler) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	objs := []runtime.Object{}
+	scaleTargetRefs := map[string]autoscaling.CrossVersionObjectReference{}
+	serviceNames := []string{}
+	for _, service := range ir.Services {
+		if scaleTargetRef, ok := h.getScaleTargetRef(service, targetCluster); ok {
+			scaleTargetRefs[service.Name] = scaleTargetRef
+			serviceNames = append(serviceNames, service.Name)
+		}
+	}
+	if len(serviceNames) == 0 {
+		return objs
+	}
+	sort.Strings(serviceNames)
+	selectedServiceNames := qaengine.FetchMultiSelectAnswer(
+		common.ConfigAutoscalingServicesKey,
+		"Select the services which should be horizontally autoscaled :",
+		[]string{"The number of replicas of the selected services is scaled on the CPU utilization or on custom and external metrics"},
+		[]string{},
+		serviceNames,
+		nil,
+	)
+	if len(selectedServiceNames) == 0 {
+		return objs
+	}
+	if !common.IsPresent(supportedKinds, horizontalPodAutoscalerKind) {
+		logrus.Errorf("Creating HorizontalPodAutoscalers even though not supported by target cluster.")
+	}
+	for _, serviceName := range selectedServiceNames {
+		scaleTargetRef, ok := scaleTargetRefs[serviceName]
+		if !ok {
+			continue
+		}
+		objs = append(objs, h.createHorizontalPodAutoscaler(ir.Services[serviceName], scaleTargetRef))
+	}
+	return objs
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (h *HorizontalPodAutoscaler) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsPresent(h.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
+
+// getScaleTargetRef returns the reference to the scalable workload created for the service, in the same way as the Deployment api resource
+func (h *HorizontalPodAutoscaler) getScaleTargetRef(service irtypes.Service, targetCluster collecttypes.ClusterMetadata) (autoscaling.CrossVersionObjectReference, bool) {
+	if service.OnlyIngress || service.Daemon || service.RestartPolicy == core.RestartPolicyNever || service.RestartPolicy == core.RestartPolicyOnFailure {
+		return autoscaling.CrossVersionObjectReference{}, false
+	}
+	if isStatefulSetService(service, targetCluster) {
+		return autoscaling.CrossVersionObjectReference{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: common.StatefulSetKind, Name: service.Name}, true
+	}
+	d := &Deployment{}
+	kinds := []string{}
+	for _, kind := range []string{common.DeploymentKind, deploymentConfigKind, replicationControllerKind} {
+		if len(targetCluster.Spec.GetSupportedVersions(kind)) > 0 {
+			kinds = append(kinds, kind)
+		}
+	}
+	kinds = d.filterWorkloadKinds(kinds, targetCluster)
+	if common.IsPresent(kinds, common.DeploymentKind) || len(kinds) == 0 {
+		if isArgoRolloutsStrategy(getProgressiveDeliveryStrategy(service)) {
+			return autoscaling.CrossVersionObjectReference{APIVersion: argoRolloutsAPIVersion, Kind: rolloutKind, Name: service.Name}, true
+		}
+		return autoscaling.CrossVersionObjectReference{APIVersion: appsv1.SchemeGroupVersion.String(), Kind: common.DeploymentKind, Name: service.Name}, true
+	}
+	if common.IsPresent(kinds, deploymentConfigKind) {
+		return autoscaling.CrossVersionObjectReference{APIVersion: okdappsv1.SchemeGroupVersion.String(), Kind: deploymentConfigKind, Name: service.Name}, true
+	}
+	return autoscaling.CrossVersionObjectReference{APIVersion: corev1.SchemeGroupVersion.String(), Kind: replicationControllerKind, Name: service.Name}, true
+}
+
+// createHorizontalPodAutoscaler creates the horizontal pod autoscaler for the service using the metrics selected by the user
+func (h *HorizontalPodAutoscaler) createHorizontalPodAutoscaler(service irtypes.Service, scaleTargetRef autoscaling.CrossVersionObjectReference) *autoscaling.HorizontalPodAutoscaler {
+	minReplicas := int32(service.Replicas)
+	if minReplicas < 1 {
+		minReplicas = 1
+	}
+	serviceKey := `"` + service.Name + `"`
+	defMaxReplicas := int32(10)
+	if minReplicas*2 > defMaxReplicas {
+		defMaxReplicas = minReplicas * 2
+	}
+	maxReplicas := cast.ToInt32(qaengine.FetchStringAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, serviceKey, common.ConfigAutoscalingMaxReplicasKeySuffix),
+		"Provide the maximum number of replicas for the service "+service.Name+" :",
+		[]string{"The minimum number of replicas is the current number of replicas of the service"},
+		cast.ToString(defMaxReplicas),
+		qatypes.NewRangeValidator(int(minReplicas), math.MaxInt32),
+	))
+	metricNames := qaengine.FetchMultiSelectAnswer(
+		common.JoinQASubKeys(common.ConfigServicesKey, serviceKey, common.ConfigAutoscalingMetricsKeySuffix),
+		"Select the metrics the service "+service.Name+" should be scaled on :",
+		[]string{
+			"Requests per second: a per pod metric served by the Prometheus adapter, or another custom metrics API server",
+			"Queue depth: an external metric, like the number of messages waiting in a queue",
+		},
+		[]string{cpuUtilizationMetric},
+		[]string{cpuUtilizationMetric, requestsPerSecondMetric, queueDepthMetric},
+		nil,
+	)
+	metrics := []autoscaling.MetricSpec{}
+	for _, metricName := range metricNames {
+		switch metricName {
+		case cpuUtilizationMetric:
+			utilization := cast.ToInt32(qaengine.FetchStringAnswer(
+				common.JoinQASubKeys(common.ConfigServicesKey, serviceKey, common.ConfigAutoscalingCPUUtilizationKeySuffix),
+				"Provide the target average CPU utilization percentage for the service "+service.Name+" :",
+				[]string{"The utilization is relative to the CPU requests of the containers"},
+				"70",
+				qatypes.NewRangeValidator(1, 100),
+			))
+			metrics = append(metrics, autoscaling.MetricSpec{
+				Type: autoscaling.ResourceMetricSourceType,
+				Resource: &autoscaling.ResourceMetricSource{
+					Name:   core.ResourceCPU,
+					Target: autoscaling.MetricTarget{Type: autoscaling.UtilizationMetricType, AverageUtilization: &utilization},
+				},
+			})
+		case requestsPerSecondMetric:
+			name := qaengine.FetchStringAnswer(
+				common.JoinQASubKeys(common.ConfigServicesKey, serviceKey, common.ConfigAutoscalingRequestsMetricKeySuffix),
+				"Provide the name of the requests per second metric of the pods of the service "+service.Name+" :",
+				[]string{"The metric has to be exposed through the custom metrics API, for example by a Prometheus adapter rule"},
+				"http_requests_per_second",
+				nil,
+			)
+			target := h.getMetricTarget(common.JoinQASubKeys(common.ConfigServicesKey, serviceKey, common.ConfigAutoscalingRequestsTargetKeySuffix),
+				"Provide the target requests per second per pod for the service "+service.Name+" :", "100")
+			metrics = append(metrics, autoscaling.MetricSpec{
+				Type: autoscaling.PodsMetricSourceType,
+				Pods: &autoscaling.PodsMetricSource{
+					Metric: autoscaling.MetricIdentifier{Name: name},
+					Target: autoscaling.MetricTarget{Type: autoscaling.AverageValueMetricType, AverageValue: &target},
+				},
+			})
+		case queueDepthMetric:
+			name := qaengine.FetchStringAnswer(
+				common.JoinQASubKeys(common.ConfigServicesKey, serviceKey, common.ConfigAutoscalingQueueMetricKeySuffix),
+				"Provide the name of the queue depth metric for the service "+service.Name+" :",
+				[]string{"The metric has to be exposed through the external metrics API, for example by a Prometheus adapter rule"},
+				"queue_messages_ready",
+				nil,
+			)
+			target := h.getMetricTarget(common.JoinQASubKeys(common.ConfigServicesKey, serviceKey, common.ConfigAutoscalingQueueTargetKeySuffix),
+				"Provide the target number of queued messages per pod for the service "+service.Name+" :", "30")
+			metrics = append(metrics, autoscaling.MetricSpec{
+				Type: autoscaling.ExternalMetricSourceType,
+				External: &autoscaling.ExternalMetricSource{
+					Metric: autoscaling.MetricIdentifier{Name: name},
+					Target: autoscaling.MetricTarget{Type: autoscaling.AverageValueMetricType, AverageValue: &target},
+				},
+			})
+		}
+	}
+	return &autoscaling.HorizontalPodAutoscaler{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       horizontalPodAutoscalerKind,
+			APIVersion: autoscaling.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   service.Name,
+			Labels: getServiceLabels(service.Name),
+		},
+		Spec: autoscaling.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: scaleTargetRef,
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    maxReplicas,
+			Metrics:        metrics,
+		},
+	}
+}
+
+// getMetricTarget returns the target average value of a metric
+func (h *HorizontalPodAutoscaler) getMetricTarget(quesKey, desc, def string) resource.Quantity {
+	return *resource.NewQuantity(cast.ToInt64(qaengine.FetchStringAnswer(quesKey, desc, []string{"The number of replicas is scaled to keep the average at the target"}, def, qatypes.NewRangeValidator(1, math.MaxInt32))), resource.DecimalSI)
+}
@@ -0,0 +1,138 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	autoscaling "k8s.io/kubernetes/pkg/apis/autoscaling"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func newAutoscalingTestIR(t *testing.T) irtypes.EnhancedIR {
+	t.Helper()
+	ir := newRolloutTestIR(t)
+	api := ir.Services["api"]
+	api.Replicas = 2
+	ir.Services["api"] = api
+	job := irtypes.NewServiceWithName("job")
+	job.Containers = []core.Container{{Name: "job", Image: "job:latest"}}
+	job.RestartPolicy = core.RestartPolicyOnFailure
+	ir.Services["job"] = job
+	return ir
+}
+
+func TestCreateHorizontalPodAutoscalers(t *testing.T) {
+	t.Run("no services are autoscaled by default", func(t *testing.T) {
+		setupQAConfig(t)
+		h := &HorizontalPodAutoscaler{}
+		if objs := h.createNewResources(newAutoscalingTestIR(t), h.getSupportedKinds(), newRolloutTestCluster()); len(objs) != 0 {
+			t.Fatalf("expected no horizontal pod autoscalers. Actual: %+v", objs)
+		}
+	})
+
+	t.Run("the selected services are scaled on the cpu utilization by default", func(t *testing.T) {
+		setupQAConfig(t, common.ConfigAutoscalingServicesKey+`=["api","job","worker"]`)
+		h := &HorizontalPodAutoscaler{}
+		objs := h.createNewResources(newAutoscalingTestIR(t), h.getSupportedKinds(), newRolloutTestCluster())
+		hpas := map[string]*autoscaling.HorizontalPodAutoscaler{}
+		for _, obj := range objs {
+			hpa := obj.(*autoscaling.HorizontalPodAutoscaler)
+			hpas[hpa.Name] = hpa
+		}
+		if len(hpas) != 2 || hpas["api"] == nil || hpas["worker"] == nil {
+			t.Fatalf("expected horizontal pod autoscalers for the api and the worker, but not for the job. Actual: %+v", hpas)
+		}
+		api := hpas["api"]
+		if api.Spec.ScaleTargetRef != (autoscaling.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: common.DeploymentKind, Name: "api"}) {
+			t.Fatalf("expected the api deployment as the scale target. Actual: %+v", api.Spec.ScaleTargetRef)
+		}
+		if *api.Spec.MinReplicas != 2 || api.Spec.MaxReplicas != 10 {
+			t.Fatalf("got the replicas %d to %d , want 2 to 10", *api.Spec.MinReplicas, api.Spec.MaxReplicas)
+		}
+		if len(api.Spec.Metrics) != 1 || api.Spec.Metrics[0].Resource == nil || *api.Spec.Metrics[0].Resource.Target.AverageUtilization != 70 {
+			t.Fatalf("expected a 70%% cpu utilization target. Actual: %+v", api.Spec.Metrics)
+		}
+	})
+
+	t.Run("the custom and external metrics are configured", func(t *testing.T) {
+		serviceKey := common.JoinQASubKeys(common.ConfigServicesKey, `"api"`)
+		setupQAConfig(t,
+			common.ConfigAutoscalingServicesKey+`=["api"]`,
+			common.JoinQASubKeys(serviceKey, common.ConfigAutoscalingMaxReplicasKeySuffix)+`="6"`,
+			common.JoinQASubKeys(serviceKey, common.ConfigAutoscalingMetricsKeySuffix)+`=["Requests per second","Queue depth"]`,
+			common.JoinQASubKeys(serviceKey, common.ConfigAutoscalingRequestsMetricKeySuffix)+`="http_requests"`,
+			common.JoinQASubKeys(serviceKey, common.ConfigAutoscalingRequestsTargetKeySuffix)+`="50"`,
+			common.JoinQASubKeys(serviceKey, common.ConfigAutoscalingQueueMetricKeySuffix)+`="orders_ready"`,
+			common.JoinQASubKeys(serviceKey, common.ConfigAutoscalingQueueTargetKeySuffix)+`="20"`,
+		)
+		h := &HorizontalPodAutoscaler{}
+		objs := h.createNewResources(newAutoscalingTestIR(t), h.getSupportedKinds(), newRolloutTestCluster())
+		if len(objs) != 1 {
+			t.Fatalf("expected a single horizontal pod autoscaler. Actual: %+v", objs)
+		}
+		hpa := objs[0].(*autoscaling.HorizontalPodAutoscaler)
+		if hpa.Spec.MaxReplicas != 6 || len(hpa.Spec.Metrics) != 2 {
+			t.Fatalf("expected 6 replicas at most and 2 metrics. Actual: %+v", hpa.Spec)
+		}
+		pods := hpa.Spec.Metrics[0]
+		if pods.Type != autoscaling.PodsMetricSourceType || pods.Pods.Metric.Name != "http_requests" || pods.Pods.Target.AverageValue.Value() != 50 {
+			t.Fatalf("expected the requests per second pods metric. Actual: %+v", pods)
+		}
+		external := hpa.Spec.Metrics[1]
+		if external.Type != autoscaling.ExternalMetricSourceType || external.External.Metric.Name != "orders_ready" || external.External.Target.AverageValue.Value() != 20 {
+			t.Fatalf("expected the queue depth external metric. Actual: %+v", external)
+		}
+
+		cluster := newRolloutTestCluster()
+		cluster.Spec.APIKindVersionMap[horizontalPodAutoscalerKind] = []string{autoscalingv1.SchemeGroupVersion.String(), autoscalingv2.SchemeGroupVersion.String()}
+		converted, err := k8sschema.ConvertToSupportedVersion(hpa, cluster.Spec, false)
+		if err != nil {
+			t.Fatalf("failed to convert the horizontal pod autoscaler. Error: %q", err)
+		}
+		if _, ok := converted.(*autoscalingv2.HorizontalPodAutoscaler); !ok {
+			t.Fatalf("expected the horizontal pod autoscaler with custom metrics to be converted to autoscaling/v2. Actual: %T", converted)
+		}
+	})
+
+	t.Run("the cpu utilization can use autoscaling/v1", func(t *testing.T) {
+		setupQAConfig(t, common.ConfigAutoscalingServicesKey+`=["worker"]`)
+		h := &HorizontalPodAutoscaler{}
+		objs := h.createNewResources(newAutoscalingTestIR(t), h.getSupportedKinds(), newRolloutTestCluster())
+		if len(objs) != 1 {
+			t.Fatalf("expected a single horizontal pod autoscaler. Actual: %+v", objs)
+		}
+		cluster := newRolloutTestCluster()
+		cluster.Spec.APIKindVersionMap[horizontalPodAutoscalerKind] = []string{autoscalingv1.SchemeGroupVersion.String(), autoscalingv2.SchemeGroupVersion.String()}
+		converted, err := k8sschema.ConvertToSupportedVersion(objs[0], cluster.Spec, false)
+		if err != nil {
+			t.Fatalf("failed to convert the horizontal pod autoscaler. Error: %q", err)
+		}
+		hpa, ok := converted.(*autoscalingv1.HorizontalPodAutoscaler)
+		if !ok {
+			t.Fatalf("expected the horizontal pod autoscaler with the cpu utilization to be converted to autoscaling/v1. Actual: %T", converted)
+		}
+		if hpa.Spec.TargetCPUUtilizationPercentage == nil || *hpa.Spec.TargetCPUUtilizationPercentage != 70 {
+			t.Fatalf("expected a 70%% cpu utilization target. Actual: %+v", hpa.Spec)
+		}
+	})
+}
@@ -0,0 +1,67 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+var (
+	// ignoredObjects stores the objects which were dropped or written in a version not supported by the target cluster
+	ignoredObjects      = []ConversionReportEntry{}
+	ignoredObjectsMutex sync.Mutex
+)
+
+// ResetIgnoredObjects clears the objects recorded during a previous transformation
+func ResetIgnoredObjects() {
+	ignoredObjectsMutex.Lock()
+	defer ignoredObjectsMutex.Unlock()
+	ignoredObjects = []ConversionReportEntry{}
+}
+
+// GetIgnoredObjects returns the objects which were ignored during the transformation, sorted by kind and name
+func GetIgnoredObjects() []ConversionReportEntry {
+	ignoredObjectsMutex.Lock()
+	defer ignoredObjectsMutex.Unlock()
+	entries := append([]ConversionReportEntry{}, ignoredObjects...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Kind != entries[j].Kind {
+			return entries[i].Kind < entries[j].Kind
+		}
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+// recordIgnoredObject records the object along with the reason it was ignored
+func recordIgnoredObject(obj runtime.Object, status ConversionStatus, reason string) {
+	info := getConversionInfo(obj)
+	logrus.Warnf("The %s %s (%s) was ignored: %s", info.OriginalKind, info.Name, info.OriginalAPIVersion, reason)
+	ignoredObjectsMutex.Lock()
+	defer ignoredObjectsMutex.Unlock()
+	ignoredObjects = append(ignoredObjects, ConversionReportEntry{
+		Name:       info.Name,
+		Kind:       info.OriginalKind,
+		APIVersion: info.OriginalAPIVersion,
+		Status:     status,
+		Reason:     reason,
+	})
+}
@@ -0,0 +1,173 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+)
+
+const (
+	// noIngressControllerPreset is the preset that leaves the ingress class and annotations untouched
+	noIngressControllerPreset = "none"
+	// nginxIngressControllerPreset is the preset of the NGINX ingress controller
+	nginxIngressControllerPreset = "nginx"
+)
+
+// ingressControllerPreset stores the ingress class and the controller specific annotations for an ingress controller
+type ingressControllerPreset struct {
+	IngressClassName string
+	// Annotations that are always set on the ingress
+	Annotations map[string]string
+	// TLSAnnotations are set only when the ingress has a TLS secret
+	TLSAnnotations map[string]string
+	// GRPCAnnotations replace the annotations of the backend protocol on the ingress of the gRPC services
+	GRPCAnnotations map[string]string
+	// SessionAffinityAnnotations route the requests of a client to the same pod with a cookie, when a service relies on sticky sessions
+	SessionAffinityAnnotations map[string]string
+}
+
+var ingressControllerPresets = map[string]ingressControllerPreset{
+	"aws-alb": {
+		IngressClassName: "alb",
+		Annotations: map[string]string{
+			"alb.ingress.kubernetes.io/scheme":           "internet-facing",
+			"alb.ingress.kubernetes.io/target-type":      "ip",
+			"alb.ingress.kubernetes.io/backend-protocol": "HTTP",
+			"alb.ingress.kubernetes.io/healthcheck-path": "/",
+		},
+		TLSAnnotations: map[string]string{
+			"alb.ingress.kubernetes.io/listen-ports": `[{"HTTP": 80}, {"HTTPS": 443}]`,
+			"alb.ingress.kubernetes.io/ssl-redirect": "443",
+		},
+		GRPCAnnotations: map[string]string{
+			"alb.ingress.kubernetes.io/backend-protocol-version": "GRPC",
+			"alb.ingress.kubernetes.io/healthcheck-path":         "/grpc.health.v1.Health/Check",
+			"alb.ingress.kubernetes.io/success-codes":            "0",
+		},
+		SessionAffinityAnnotations: map[string]string{
+			"alb.ingress.kubernetes.io/target-group-attributes": "stickiness.enabled=true,stickiness.type=lb_cookie",
+		},
+	},
+	"gce": {
+		// GCE ingress controller only honors the legacy annotation and rejects ingresses that also set the class field
+		Annotations: map[string]string{
+			"kubernetes.io/ingress.class": "gce",
+		},
+		TLSAnnotations: map[string]string{
+			"kubernetes.io/ingress.allow-http": "false",
+		},
+	},
+	nginxIngressControllerPreset: {
+		IngressClassName: "nginx",
+		Annotations: map[string]string{
+			"nginx.ingress.kubernetes.io/backend-protocol": "HTTP",
+		},
+		TLSAnnotations: map[string]string{
+			"nginx.ingress.kubernetes.io/ssl-redirect": "true",
+		},
+		GRPCAnnotations: map[string]string{
+			"nginx.ingress.kubernetes.io/backend-protocol": "GRPC",
+		},
+		SessionAffinityAnnotations: map[string]string{
+			"nginx.ingress.kubernetes.io/affinity":            "cookie",
+			"nginx.ingress.kubernetes.io/affinity-mode":       "persistent",
+			"nginx.ingress.kubernetes.io/session-cookie-name": "route",
+		},
+	},
+	"traefik": {
+		IngressClassName: "traefik",
+		Annotations: map[string]string{
+			"traefik.ingress.kubernetes.io/router.entrypoints": "web",
+		},
+		TLSAnnotations: map[string]string{
+			"traefik.ingress.kubernetes.io/router.entrypoints": "websecure",
+			"traefik.ingress.kubernetes.io/router.tls":         "true",
+		},
+	},
+	"haproxy": {
+		IngressClassName: "haproxy",
+		Annotations: map[string]string{
+			"haproxy.org/check":      "true",
+			"haproxy.org/check-http": "/",
+		},
+		TLSAnnotations: map[string]string{
+			"haproxy.org/ssl-redirect": "true",
+		},
+		GRPCAnnotations: map[string]string{
+			"haproxy.org/server-proto": "h2",
+			"haproxy.org/check-http":   "",
+		},
+		SessionAffinityAnnotations: map[string]string{
+			"haproxy.org/cookie-persistence": "route",
+		},
+	},
+}
+
+// getIngressControllerPresetNames returns the sorted list of preset names with the "none" option first
+func getIngressControllerPresetNames() []string {
+	names := []string{}
+	for name := range ingressControllerPresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return append([]string{noIngressControllerPreset}, names...)
+}
+
+// getIngressControllerPreset returns the name and the preset of the ingress controller used in the target cluster
+func getIngressControllerPreset(targetCluster collecttypes.ClusterMetadata) (string, ingressControllerPreset) {
+	quesKey := common.JoinQASubKeys(getClusterQaID(targetCluster), common.ConfigIngressControllerKeySuffix)
+	desc := "Select the ingress controller used in the cluster"
+	hints := []string{"The preset sets the ingress class name and the controller specific annotations"}
+	def := noIngressControllerPreset
+	if _, ok := ingressControllerPresets[targetCluster.Spec.IngressController]; ok {
+		def = targetCluster.Spec.IngressController
+	}
+	name := qaengine.FetchSelectAnswer(quesKey, desc, hints, def, getIngressControllerPresetNames(), nil)
+	return name, ingressControllerPresets[name]
+}
+
+// getAnnotations returns the annotations to be set on the ingress for this preset
+func (p ingressControllerPreset) getAnnotations(tlsEnabled bool) map[string]string {
+	annotations := map[string]string{}
+	for k, v := range p.Annotations {
+		annotations[k] = v
+	}
+	if tlsEnabled {
+		for k, v := range p.TLSAnnotations {
+			annotations[k] = v
+		}
+	}
+	return annotations
+}
+
+// getGRPCAnnotations returns the annotations to be set on the ingress of the gRPC services for this preset.
+// The annotations with empty values are removed, like the http health checks which gRPC servers do not answer.
+func (p ingressControllerPreset) getGRPCAnnotations(tlsEnabled bool) map[string]string {
+	annotations := p.getAnnotations(tlsEnabled)
+	for k, v := range p.GRPCAnnotations {
+		if v == "" {
+			delete(annotations, k)
+			continue
+		}
+		annotations[k] = v
+	}
+	return annotations
+}
@@ -0,0 +1,242 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+const (
+	namespaceKind     = "Namespace"
+	resourceQuotaKind = "ResourceQuota"
+	// resourceQuotaHeadroom leaves room for the surge pods of rolling updates
+	resourceQuotaHeadroom = 2
+	defaultDenyPolicyName = "default-deny-ingress"
+	sameNamespacePolicy   = "allow-same-namespace"
+	exposedPolicySuffix   = "-allow-external"
+)
+
+// Namespace handles the namespace the application is deployed to
+type Namespace struct {
+}
+
+// getSupportedKinds returns all kinds supported by the class
+func (n *Namespace) getSupportedKinds() []string {
+	return []string{namespaceKind, resourceQuotaKind, networkPolicyKind}
+}
+
+// createNewResources converts ir to runtime objects
+func (n *Namespace) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	if len(ir.Services) == 0 {
+		return nil
+	}
+	if !qaengine.FetchBoolAnswer(
+		common.ConfigNamespaceCreateKey,
+		"Generate a namespace for the application?",
+		[]string{"The namespace is not set in the other kubernetes yamls. Apply them using kubectl apply -n <namespace>"},
+		false,
+		nil,
+	) {
+		return nil
+	}
+	name := qaengine.FetchStringAnswer(
+		common.ConfigNamespaceNameKey,
+		"Provide the name of the namespace :",
+		nil,
+		common.MakeStringDNSNameCompliantWithoutDots(ir.Name),
+		qatypes.NewDNSLabelValidator(),
+	)
+	if !common.IsPresent(supportedKinds, namespaceKind) {
+		logrus.Errorf("Creating Namespace even though not supported by target cluster.")
+	}
+	objs := []runtime.Object{n.createNamespace(name, ir.Name)}
+	if qaengine.FetchBoolAnswer(
+		common.ConfigNamespaceResourceQuotaKey,
+		"Generate a resource quota for the namespace?",
+		[]string{"The quota is twice the cpu and memory of the replicas of the services, so rolling updates can surge. Increase it for autoscaled services."},
+		false,
+		nil,
+	) {
+		objs = append(objs, n.createResourceQuota(name, ir))
+	}
+	if qaengine.FetchBoolAnswer(
+		common.ConfigNamespaceDefaultDenyKey,
+		"Generate network policies which deny the traffic from outside the namespace by default?",
+		[]string{"The pods of the services exposed outside the cluster still accept traffic from everywhere. Traffic from monitoring in other namespaces needs an additional policy."},
+		true,
+		nil,
+	) {
+		if !common.IsPresent(supportedKinds, networkPolicyKind) {
+			logrus.Errorf("Creating NetworkPolicies even though not supported by target cluster.")
+		}
+		objs = append(objs, n.createDefaultNetworkPolicies(name, ir)...)
+	}
+	return objs
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (n *Namespace) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsPresent(n.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
+
+// createNamespace creates the namespace with the standard labels of the application
+func (n *Namespace) createNamespace(name, appName string) *core.Namespace {
+	labels := map[string]string{
+		"app.kubernetes.io/part-of":    appName,
+		"app.kubernetes.io/managed-by": types.AppName,
+	}
+	if commonqa.ServiceMesh() == commonqa.IstioServiceMesh {
+		labels["istio-injection"] = "enabled"
+	}
+	return &core.Namespace{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       namespaceKind,
+			APIVersion: core.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: labels,
+		},
+	}
+}
+
+// createResourceQuota creates a quota sized on the resources of the replicas of the services
+func (n *Namespace) createResourceQuota(namespace string, ir irtypes.EnhancedIR) *core.ResourceQuota {
+	var cpuRequests, memoryRequests, cpuLimits, memoryLimits, pods int64
+	for _, service := range ir.Services {
+		if service.OnlyIngress {
+			continue
+		}
+		replicas := int64(service.Replicas)
+		if replicas < 1 {
+			replicas = 1
+		}
+		pods += replicas
+		for _, container := range service.Containers {
+			cpuRequest := getContainerResource(container.Resources.Requests, core.ResourceCPU, "requests", common.DefaultContainerCPURequest)
+			memoryRequest := getContainerResource(container.Resources.Requests, core.ResourceMemory, "requests", common.DefaultContainerMemoryRequest)
+			cpuLimit := getContainerResource(container.Resources.Limits, core.ResourceCPU, "limits", common.DefaultContainerCPULimit)
+			memoryLimit := getContainerResource(container.Resources.Limits, core.ResourceMemory, "limits", common.DefaultContainerMemoryLimit)
+			cpuRequests += replicas * cpuRequest.MilliValue()
+			memoryRequests += replicas * memoryRequest.Value()
+			cpuLimits += replicas * cpuLimit.MilliValue()
+			memoryLimits += replicas * memoryLimit.Value()
+		}
+	}
+	return &core.ResourceQuota{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       resourceQuotaKind,
+			APIVersion: core.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      namespace,
+			Namespace: namespace,
+		},
+		Spec: core.ResourceQuotaSpec{
+			Hard: core.ResourceList{
+				core.ResourceRequestsCPU:    *resource.NewMilliQuantity(cpuRequests*resourceQuotaHeadroom, resource.DecimalSI),
+				core.ResourceRequestsMemory: *resource.NewQuantity(memoryRequests*resourceQuotaHeadroom, resource.BinarySI),
+				core.ResourceLimitsCPU:      *resource.NewMilliQuantity(cpuLimits*resourceQuotaHeadroom, resource.DecimalSI),
+				core.ResourceLimitsMemory:   *resource.NewQuantity(memoryLimits*resourceQuotaHeadroom, resource.BinarySI),
+				core.ResourcePods:           *resource.NewQuantity(pods*resourceQuotaHeadroom, resource.DecimalSI),
+			},
+		},
+	}
+}
+
+// getContainerResource returns the resource of the container, or the default used for the containers which don't specify one
+func getContainerResource(resources core.ResourceList, name core.ResourceName, resourceType, def string) resource.Quantity {
+	if quantity, ok := resources[name]; ok {
+		return quantity
+	}
+	return commonqa.ContainerResourceQuantity(resourceType, string(name), def)
+}
+
+// createDefaultNetworkPolicies denies the ingress traffic from outside the namespace, except to the pods of the exposed services
+func (n *Namespace) createDefaultNetworkPolicies(namespace string, ir irtypes.EnhancedIR) []runtime.Object {
+	objs := []runtime.Object{
+		n.createNetworkPolicy(defaultDenyPolicyName, namespace, metav1.LabelSelector{}, nil),
+		n.createNetworkPolicy(sameNamespacePolicy, namespace, metav1.LabelSelector{}, []networking.NetworkPolicyIngressRule{{
+			From: []networking.NetworkPolicyPeer{{PodSelector: &metav1.LabelSelector{}}},
+		}}),
+	}
+	serviceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if !service.OnlyIngress && isExposedOutsideNamespace(service) {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	sort.Strings(serviceNames)
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		logrus.Debugf("Allowing the traffic from outside the namespace to the exposed service %s", service.Name)
+		objs = append(objs, n.createNetworkPolicy(service.Name+exposedPolicySuffix, namespace,
+			metav1.LabelSelector{MatchLabels: getServiceLabels(service.Name)},
+			[]networking.NetworkPolicyIngressRule{{}},
+		))
+	}
+	return objs
+}
+
+func (n *Namespace) createNetworkPolicy(name, namespace string, podSelector metav1.LabelSelector, ingress []networking.NetworkPolicyIngressRule) *networking.NetworkPolicy {
+	return &networking.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       networkPolicyKind,
+			APIVersion: networking.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: networking.NetworkPolicySpec{
+			PodSelector: podSelector,
+			Ingress:     ingress,
+			PolicyTypes: []networking.PolicyType{networking.PolicyTypeIngress},
+		},
+	}
+}
+
+// isExposedOutsideNamespace returns true if the service is reachable through an ingress, a route or a node port
+func isExposedOutsideNamespace(service irtypes.Service) bool {
+	_, _, relPaths, serviceType := (&Service{}).getExposeInfo(service)
+	if serviceType == core.ServiceTypeLoadBalancer || serviceType == core.ServiceTypeNodePort {
+		return true
+	}
+	for _, relPath := range relPaths {
+		if relPath != "" {
+			return true
+		}
+	}
+	return false
+}
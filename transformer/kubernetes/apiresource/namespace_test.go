@@ -0,0 +1,128 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestCreateNamespace(t *testing.T) {
+	t.Run("the namespace is not generated by default", func(t *testing.T) {
+		setupQAConfig(t)
+		n := &Namespace{}
+		if objs := n.createNewResources(newRolloutTestIR(t), n.getSupportedKinds(), newRolloutTestCluster()); len(objs) != 0 {
+			t.Fatalf("expected no namespace. Actual: %+v", objs)
+		}
+	})
+
+	t.Run("the namespace with a quota and the default deny network policies", func(t *testing.T) {
+		setupQAConfig(t,
+			common.ConfigNamespaceCreateKey+"=true",
+			common.ConfigNamespaceResourceQuotaKey+"=true",
+			common.ConfigServiceMeshKey+`="`+commonqa.IstioServiceMesh+`"`,
+		)
+		ir := newRolloutTestIR(t)
+		ir.Name = "My App"
+		resources := core.ResourceRequirements{
+			Requests: core.ResourceList{core.ResourceCPU: resource.MustParse("100m"), core.ResourceMemory: resource.MustParse("128Mi")},
+			Limits:   core.ResourceList{core.ResourceCPU: resource.MustParse("500m"), core.ResourceMemory: resource.MustParse("256Mi")},
+		}
+		api := ir.Services["api"]
+		api.Replicas = 2
+		api.Containers[0].Resources = resources
+		api.ServiceToPodPortForwardings[0].ServiceType = core.ServiceTypeLoadBalancer
+		ir.Services["api"] = api
+		worker := ir.Services["worker"]
+		worker.Containers[0].Resources = resources
+		ir.Services["worker"] = worker
+
+		n := &Namespace{}
+		objsByKind := getObjectsByKind(n.createNewResources(ir, n.getSupportedKinds(), newRolloutTestCluster()))
+		if len(objsByKind[namespaceKind]) != 1 {
+			t.Fatalf("expected a single namespace. Actual: %+v", objsByKind)
+		}
+		namespace := objsByKind[namespaceKind][0].(*core.Namespace)
+		if namespace.Name != "my-app" || namespace.Labels["app.kubernetes.io/part-of"] != "My App" || namespace.Labels["istio-injection"] != "enabled" {
+			t.Fatalf("expected the namespace my-app with the labels of the application and the service mesh. Actual: %s %+v", namespace.Name, namespace.Labels)
+		}
+
+		if len(objsByKind[resourceQuotaKind]) != 1 {
+			t.Fatalf("expected a single resource quota. Actual: %+v", objsByKind[resourceQuotaKind])
+		}
+		quota := objsByKind[resourceQuotaKind][0].(*core.ResourceQuota)
+		if quota.Namespace != "my-app" {
+			t.Fatalf("got the namespace %q for the resource quota, want my-app", quota.Namespace)
+		}
+		// 3 pods, twice for the surge of the rolling updates
+		for name, want := range map[core.ResourceName]string{
+			core.ResourceRequestsCPU:    "600m",
+			core.ResourceRequestsMemory: "768Mi",
+			core.ResourceLimitsCPU:      "3",
+			core.ResourceLimitsMemory:   "1536Mi",
+			core.ResourcePods:           "6",
+		} {
+			got := quota.Spec.Hard[name]
+			if got.Cmp(resource.MustParse(want)) != 0 {
+				t.Errorf("got %s for %s in the resource quota, want %s", got.String(), name, want)
+			}
+		}
+
+		policies := map[string]*networking.NetworkPolicy{}
+		for _, obj := range objsByKind[networkPolicyKind] {
+			policy := obj.(*networking.NetworkPolicy)
+			policies[policy.Name] = policy
+		}
+		if len(policies) != 3 {
+			t.Fatalf("expected the default deny, same namespace and exposed api policies. Actual: %+v", policies)
+		}
+		if deny := policies[defaultDenyPolicyName]; deny == nil || len(deny.Spec.Ingress) != 0 || len(deny.Spec.PodSelector.MatchLabels) != 0 {
+			t.Fatalf("expected a policy denying the ingress traffic to all the pods. Actual: %+v", deny)
+		}
+		if same := policies[sameNamespacePolicy]; same == nil || len(same.Spec.Ingress) != 1 || same.Spec.Ingress[0].From[0].PodSelector == nil {
+			t.Fatalf("expected a policy allowing the traffic from the same namespace. Actual: %+v", same)
+		}
+		exposed := policies["api"+exposedPolicySuffix]
+		if exposed == nil || exposed.Namespace != "my-app" || !cmp.Equal(exposed.Spec.PodSelector.MatchLabels, getServiceLabels("api")) {
+			t.Fatalf("expected a policy allowing all the traffic to the pods of the exposed api service. Actual: %+v", exposed)
+		}
+		if len(exposed.Spec.Ingress) != 1 || len(exposed.Spec.Ingress[0].From) != 0 {
+			t.Fatalf("expected the exposed api service to accept the traffic from everywhere. Actual: %+v", exposed.Spec.Ingress)
+		}
+	})
+
+	t.Run("the default deny network policies can be skipped", func(t *testing.T) {
+		setupQAConfig(t,
+			common.ConfigNamespaceCreateKey+"=true",
+			common.ConfigNamespaceNameKey+`="custom"`,
+			common.ConfigNamespaceDefaultDenyKey+"=false",
+		)
+		ir := newRolloutTestIR(t)
+		ir.Name = "myapp"
+		n := &Namespace{}
+		objs := n.createNewResources(ir, n.getSupportedKinds(), newRolloutTestCluster())
+		if len(objs) != 1 || objs[0].(*core.Namespace).Name != "custom" {
+			t.Fatalf("expected only the namespace named custom. Actual: %+v", objs)
+		}
+	})
+}
@@ -18,13 +18,17 @@ package apiresource
 
 import (
 	"fmt"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/konveyor/move2kube/common"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/sirupsen/logrus"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
@@ -35,6 +39,13 @@ const (
 	gitRepoURLPlaceholder     = "<TODO: insert git repo url>"
 	contextPathPlaceholder    = "<TODO: insert path to the directory containing Dockerfile>"
 	dockerfilePathPlaceholder = "<TODO: insert path to the Dockerfile>"
+	// kanikoCacheDir is the directory of the workspace the layers are cached in, when the workspace is kept between the runs
+	kanikoCacheDir = "/workspace/source/.kaniko-cache"
+	cosignImage    = "gcr.io/projectsigstore/cosign:v2.2.4"
+	// cosignWorkspaceName is the workspace of the signing task holding the key
+	cosignWorkspaceName    = "cosign"
+	cosignKeyFilename      = "cosign.key"
+	cosignPasswordFilename = "cosign.password"
 )
 
 // Pipeline handles all objects like a Tekton pipeline.
@@ -71,11 +82,33 @@ func (*Pipeline) createNewResource(irpipeline irtypes.Pipeline, ir irtypes.Enhan
 	pipeline.Spec.Workspaces = []v1beta1.PipelineWorkspaceDeclaration{
 		{Name: irpipeline.WorkspaceName, Description: "This workspace will receive the cloned git repo and be passed to the kaniko task for building the image."},
 	}
+	if irpipeline.ArtifactBucket != "" {
+		pipeline.Spec.Workspaces = append(pipeline.Spec.Workspaces, v1beta1.PipelineWorkspaceDeclaration{
+			Name:        irpipeline.ArtifactBucketWorkspaceName,
+			Description: "This workspace holds the credentials of the bucket the workspace of the run is uploaded to.",
+		})
+	}
+	if irpipeline.SigningWorkspaceName != "" {
+		pipeline.Spec.Workspaces = append(pipeline.Spec.Workspaces, v1beta1.PipelineWorkspaceDeclaration{
+			Name:        irpipeline.SigningWorkspaceName,
+			Description: "This workspace holds the cosign key the pushed images are signed with.",
+		})
+	}
 	tasks := []v1beta1.PipelineTask{}
 	firstTask := true
 	prevTaskName := ""
 	containerIndex := 0
+	// the tasks run one after the other, so the base images are built before the images built from them
+	baseImages := map[string][]string{}
 	for imageName, container := range ir.ContainerImages {
+		baseImages[imageName] = nil
+		for _, dockerfilePath := range container.Build.Artifacts[irtypes.DockerfileContainerBuildArtifactTypeValue] {
+			baseImages[imageName] = append(baseImages[imageName], common.GetDockerfileBaseImages(dockerfilePath)...)
+		}
+	}
+	imageNames, _ := common.GetImageBuildOrder(baseImages)
+	for _, imageName := range imageNames {
+		container := ir.ContainerImages[imageName]
 		if container.Build.ContainerBuildType == "" {
 			continue
 		}
@@ -105,6 +138,12 @@ func (*Pipeline) createNewResource(irpipeline irtypes.Pipeline, ir irtypes.Enhan
 					{Name: "deleteExisting", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "true"}},
 				},
 			}
+			// The workspace is kept between the runs, so each repo is cloned into its own directory next to the cache.
+			subDir := ""
+			if irpipeline.CacheType == irtypes.PVCPipelineCache {
+				subDir = fmt.Sprintf("repo-%d", containerIndex)
+				cloneTask.Params = append(cloneTask.Params, v1beta1.Param{Name: "subdirectory", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: subDir}})
+			}
 			if !firstTask {
 				cloneTask.RunAfter = []string{prevTaskName}
 			}
@@ -135,6 +174,10 @@ func (*Pipeline) createNewResource(irpipeline irtypes.Pipeline, ir irtypes.Enhan
 					// We can't figure out the context from the source. So assume the context is the directory containing the dockerfile.
 					contextPath = relContextPath
 				}
+				if subDir != "" {
+					dockerfilePath = path.Join(subDir, common.GetUnixPath(dockerfilePath))
+					contextPath = path.Join(subDir, common.GetUnixPath(contextPath))
+				}
 			}
 
 			buildPushTaskName := fmt.Sprintf("build-push-%d", containerIndex)
@@ -147,13 +190,21 @@ func (*Pipeline) createNewResource(irpipeline irtypes.Pipeline, ir irtypes.Enhan
 				},
 				Params: []v1beta1.Param{
 					{Name: "IMAGE", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "$(params.image-registry-url)/" + imageName}},
-					{Name: "DOCKERFILE", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: dockerfilePath}},
-					{Name: "CONTEXT", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: contextPath}},
+					{Name: "DOCKERFILE", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: common.GetUnixPath(dockerfilePath)}},
+					{Name: "CONTEXT", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: common.GetUnixPath(contextPath)}},
 				},
 			}
+			if extraArgs := append(getKanikoExtraArgs(container.Build), getKanikoCacheArgs(irpipeline)...); len(extraArgs) != 0 {
+				buildPushTask.Params = append(buildPushTask.Params, v1beta1.Param{Name: "EXTRA_ARGS", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: extraArgs}})
+			}
 			tasks = append(tasks, cloneTask, buildPushTask)
 			firstTask = false
 			prevTaskName = buildPushTaskName
+			if irpipeline.SignImages {
+				signTask := getSignTask(irpipeline, fmt.Sprintf("sign-%d", containerIndex), buildPushTaskName)
+				tasks = append(tasks, signTask)
+				prevTaskName = signTask.Name
+			}
 		} else if container.Build.ContainerBuildType == irtypes.S2IContainerBuildTypeValue {
 			// TODO: Implement support for S2I
 			logrus.Debugf("S2I not yet supported for Tekton")
@@ -165,9 +216,111 @@ func (*Pipeline) createNewResource(irpipeline irtypes.Pipeline, ir irtypes.Enhan
 		}
 	}
 	pipeline.Spec.Tasks = tasks
+	if uploadTask, ok := getArtifactUploadTask(irpipeline); ok {
+		pipeline.Spec.Finally = []v1beta1.PipelineTask{uploadTask}
+	}
 	return pipeline
 }
 
+// getSignTask returns the task which signs the image pushed by the build task with cosign
+func getSignTask(irpipeline irtypes.Pipeline, name, buildPushTaskName string) v1beta1.PipelineTask {
+	args := []string{"sign", "--yes"}
+	step := v1beta1.Step{Container: corev1.Container{Name: "sign", Image: cosignImage}}
+	task := v1beta1.PipelineTask{
+		Name:     name,
+		RunAfter: []string{buildPushTaskName},
+		Params: []v1beta1.Param{{
+			Name:  "IMAGE",
+			Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: fmt.Sprintf("$(tasks.%s.results.IMAGE_URL)@$(tasks.%s.results.IMAGE_DIGEST)", buildPushTaskName, buildPushTaskName)},
+		}},
+	}
+	taskSpec := v1beta1.TaskSpec{
+		Params: []v1beta1.ParamSpec{{Name: "IMAGE", Description: "The digest of the image to sign.", Type: v1beta1.ParamTypeString}},
+	}
+	if irpipeline.SigningWorkspaceName != "" {
+		args = append(args, "--key", "$(workspaces."+cosignWorkspaceName+".path)/"+cosignKeyFilename)
+		step.Env = []corev1.EnvVar{{
+			Name: "COSIGN_PASSWORD",
+			ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: irpipeline.SigningSecretName},
+				Key:                  cosignPasswordFilename,
+			}},
+		}}
+		taskSpec.Workspaces = []v1beta1.WorkspaceDeclaration{{Name: cosignWorkspaceName, ReadOnly: true}}
+		task.Workspaces = []v1beta1.WorkspacePipelineTaskBinding{{Name: cosignWorkspaceName, Workspace: irpipeline.SigningWorkspaceName}}
+	} else if irpipeline.SigningKeyRef != "" {
+		args = append(args, "--key", irpipeline.SigningKeyRef)
+	}
+	step.Args = append(args, "$(params.IMAGE)")
+	taskSpec.Steps = []v1beta1.Step{step}
+	task.TaskSpec = &v1beta1.EmbeddedTask{TaskSpec: taskSpec}
+	return task
+}
+
+// getKanikoCacheArgs returns the arguments making kaniko reuse the layers built by the previous runs
+func getKanikoCacheArgs(irpipeline irtypes.Pipeline) []string {
+	switch irpipeline.CacheType {
+	case irtypes.RegistryPipelineCache:
+		if irpipeline.CacheRepo != "" {
+			return []string{"--cache=true", "--cache-repo=" + irpipeline.CacheRepo}
+		}
+	case irtypes.PVCPipelineCache:
+		return []string{"--cache=true", "--cache-repo=oci:" + kanikoCacheDir}
+	}
+	return nil
+}
+
+// getArtifactUploadTask returns the task which uploads the workspace of the run to the artifact bucket after all the other tasks
+func getArtifactUploadTask(irpipeline irtypes.Pipeline) (v1beta1.PipelineTask, bool) {
+	if irpipeline.ArtifactBucket == "" {
+		return v1beta1.PipelineTask{}, false
+	}
+	location := strings.TrimSuffix(irpipeline.ArtifactBucket, "/") + "/$(context.pipelineRun.name)"
+	switch {
+	case strings.HasPrefix(irpipeline.ArtifactBucket, "gs://"):
+		return v1beta1.PipelineTask{
+			Name:    "upload-artifacts",
+			TaskRef: &v1beta1.TaskRef{Name: "gcs-upload"},
+			Workspaces: []v1beta1.WorkspacePipelineTaskBinding{
+				{Name: "source", Workspace: irpipeline.WorkspaceName},
+				{Name: "credentials", Workspace: irpipeline.ArtifactBucketWorkspaceName},
+			},
+			Params: []v1beta1.Param{
+				{Name: "path", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "."}},
+				{Name: "location", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: location}},
+			},
+		}, true
+	case strings.HasPrefix(irpipeline.ArtifactBucket, "s3://"):
+		return v1beta1.PipelineTask{
+			Name:    "upload-artifacts",
+			TaskRef: &v1beta1.TaskRef{Name: "aws-cli"},
+			Workspaces: []v1beta1.WorkspacePipelineTaskBinding{
+				{Name: "source", Workspace: irpipeline.WorkspaceName},
+				{Name: "secrets", Workspace: irpipeline.ArtifactBucketWorkspaceName},
+			},
+			Params: []v1beta1.Param{
+				{Name: "SCRIPT", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeString, StringVal: "aws s3 sync . " + location + " $@"}},
+				{Name: "ARGS", Value: v1beta1.ArrayOrString{Type: v1beta1.ParamTypeArray, ArrayVal: []string{"--no-progress"}}},
+			},
+		}, true
+	}
+	logrus.Warnf("Only the gs:// and s3:// artifact buckets are supported. Not uploading the artifacts of the pipeline %s to the bucket %s", irpipeline.Name, irpipeline.ArtifactBucket)
+	return v1beta1.PipelineTask{}, false
+}
+
+// getKanikoExtraArgs returns the arguments passing the build args and the target stage of the image to kaniko
+func getKanikoExtraArgs(build irtypes.ContainerBuild) []string {
+	extraArgs := []string{}
+	for buildArg, value := range build.BuildArgs {
+		extraArgs = append(extraArgs, "--build-arg="+buildArg+"="+value)
+	}
+	sort.Strings(extraArgs)
+	if build.Target != "" {
+		extraArgs = append(extraArgs, "--target="+build.Target)
+	}
+	return extraArgs
+}
+
 // convertToClusterSupportedKinds converts the object to supported types if possible.
 func (p *Pipeline) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
 	if common.IsPresent(p.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
@@ -0,0 +1,141 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+func TestGetKanikoExtraArgs(t *testing.T) {
+	testCases := []struct {
+		name  string
+		build irtypes.ContainerBuild
+		want  []string
+	}{
+		{name: "no build args and target", want: []string{}},
+		{name: "the build args are sorted", build: irtypes.ContainerBuild{BuildArgs: map[string]string{"VERSION": "1.2", "PROFILE": "prod"}}, want: []string{"--build-arg=PROFILE=prod", "--build-arg=VERSION=1.2"}},
+		{name: "the target is last", build: irtypes.ContainerBuild{BuildArgs: map[string]string{"VERSION": "1.2"}, Target: "runtime"}, want: []string{"--build-arg=VERSION=1.2", "--target=runtime"}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := getKanikoExtraArgs(testCase.build); !cmp.Equal(got, testCase.want) {
+				t.Errorf("getKanikoExtraArgs(%+v) = %q, want %q", testCase.build, got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestGetKanikoCacheArgs(t *testing.T) {
+	testCases := []struct {
+		name     string
+		pipeline irtypes.Pipeline
+		want     []string
+	}{
+		{name: "no cache", pipeline: irtypes.Pipeline{CacheType: irtypes.NoPipelineCache}, want: nil},
+		{name: "registry cache", pipeline: irtypes.Pipeline{CacheType: irtypes.RegistryPipelineCache, CacheRepo: "quay.io/myteam/cache"}, want: []string{"--cache=true", "--cache-repo=quay.io/myteam/cache"}},
+		{name: "registry cache without a repo", pipeline: irtypes.Pipeline{CacheType: irtypes.RegistryPipelineCache}, want: nil},
+		{name: "pvc cache", pipeline: irtypes.Pipeline{CacheType: irtypes.PVCPipelineCache}, want: []string{"--cache=true", "--cache-repo=oci:" + kanikoCacheDir}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := getKanikoCacheArgs(testCase.pipeline); !cmp.Equal(got, testCase.want) {
+				t.Errorf("getKanikoCacheArgs(%+v) = %q, want %q", testCase.pipeline, got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestGetArtifactUploadTask(t *testing.T) {
+	pipeline := irtypes.Pipeline{Name: "myproject", WorkspaceName: "shared-data", ArtifactBucketWorkspaceName: "myproject-artifact-bucket"}
+	if _, ok := getArtifactUploadTask(pipeline); ok {
+		t.Fatalf("expected no upload task without an artifact bucket")
+	}
+	pipeline.ArtifactBucket = "azure://my-bucket"
+	if _, ok := getArtifactUploadTask(pipeline); ok {
+		t.Fatalf("expected no upload task for an unsupported artifact bucket")
+	}
+
+	pipeline.ArtifactBucket = "gs://my-bucket/builds/"
+	task, ok := getArtifactUploadTask(pipeline)
+	if !ok || task.TaskRef == nil || task.TaskRef.Name != "gcs-upload" {
+		t.Fatalf("expected the gcs upload task. Actual: %+v", task)
+	}
+	if location := task.Params[1].Value.StringVal; location != "gs://my-bucket/builds/$(context.pipelineRun.name)" {
+		t.Fatalf("got the location %q , want the directory of the run in the bucket", location)
+	}
+	if want := []v1beta1.WorkspacePipelineTaskBinding{{Name: "source", Workspace: "shared-data"}, {Name: "credentials", Workspace: "myproject-artifact-bucket"}}; !cmp.Equal(task.Workspaces, want) {
+		t.Fatalf("unexpected workspaces of the task. Differences:\n%s", cmp.Diff(want, task.Workspaces))
+	}
+
+	pipeline.ArtifactBucket = "s3://my-bucket/builds"
+	task, ok = getArtifactUploadTask(pipeline)
+	if !ok || task.TaskRef == nil || task.TaskRef.Name != "aws-cli" {
+		t.Fatalf("expected the aws cli task. Actual: %+v", task)
+	}
+	if script := task.Params[0].Value.StringVal; script != "aws s3 sync . s3://my-bucket/builds/$(context.pipelineRun.name) $@" {
+		t.Fatalf("got the script %q", script)
+	}
+}
+
+func TestGetSignTask(t *testing.T) {
+	testCases := []struct {
+		name          string
+		pipeline      irtypes.Pipeline
+		wantArgs      []string
+		wantWorkspace bool
+	}{
+		{name: "keyless", pipeline: irtypes.Pipeline{SignImages: true}, wantArgs: []string{"sign", "--yes", "$(params.IMAGE)"}},
+		{name: "the key in a kms", pipeline: irtypes.Pipeline{SignImages: true, SigningKeyRef: "awskms:///alias/signing"}, wantArgs: []string{"sign", "--yes", "--key", "awskms:///alias/signing", "$(params.IMAGE)"}},
+		{
+			name:          "the key in the secret of the pipeline",
+			pipeline:      irtypes.Pipeline{SignImages: true, SigningKeyRef: "cosign.key", SigningWorkspaceName: "myproject-cosign", SigningSecretName: "myproject-cosign"},
+			wantArgs:      []string{"sign", "--yes", "--key", "$(workspaces." + cosignWorkspaceName + ".path)/" + cosignKeyFilename, "$(params.IMAGE)"},
+			wantWorkspace: true,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			task := getSignTask(testCase.pipeline, "sign-0", "build-push-0")
+			if !cmp.Equal(task.RunAfter, []string{"build-push-0"}) {
+				t.Fatalf("expected the task to run after the image is pushed. Actual: %+v", task.RunAfter)
+			}
+			if image := task.Params[0].Value.StringVal; image != "$(tasks.build-push-0.results.IMAGE_URL)@$(tasks.build-push-0.results.IMAGE_DIGEST)" {
+				t.Fatalf("expected the digest of the pushed image to be signed. Actual: %q", image)
+			}
+			step := task.TaskSpec.Steps[0]
+			if !cmp.Equal(step.Args, testCase.wantArgs) {
+				t.Fatalf("unexpected args of the signing step. Differences:\n%s", cmp.Diff(testCase.wantArgs, step.Args))
+			}
+			if !testCase.wantWorkspace {
+				if len(task.Workspaces) != 0 || len(step.Env) != 0 {
+					t.Fatalf("expected no workspace and no password. Actual: %+v", task)
+				}
+				return
+			}
+			if len(task.Workspaces) != 1 || task.Workspaces[0].Workspace != "myproject-cosign" {
+				t.Fatalf("expected the workspace of the key. Actual: %+v", task.Workspaces)
+			}
+			if len(step.Env) != 1 || step.Env[0].ValueFrom.SecretKeyRef.Name != "myproject-cosign" || step.Env[0].ValueFrom.SecretKeyRef.Key != cosignPasswordFilename {
+				t.Fatalf("expected the password of the key from the secret. Actual: %+v", step.Env)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	scheduling "k8s.io/kubernetes/pkg/apis/scheduling"
+)
+
+const (
+	priorityClassKind = "PriorityClass"
+	// interruptionTolerantPriority is below the default priority 0 of the pods without a priority class
+	interruptionTolerantPriority int32 = -100
+)
+
+// PriorityClass handles the priority classes of the workloads
+type PriorityClass struct {
+}
+
+// getSupportedKinds returns all kinds supported by the class
+func (p *PriorityClass) getSupportedKinds() []string {
+	return []string{priorityClassKind}
+}
+
+// createNewResources converts ir to runtime objects
+func (p *PriorityClass) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	for _, service := range ir.Services {
+		if service.PriorityClassName != common.InterruptionTolerantPriorityClassName {
+			continue
+		}
+		if !common.IsPresent(supportedKinds, priorityClassKind) {
+			logrus.Errorf("Creating PriorityClass even though not supported by target cluster.")
+		}
+		return []runtime.Object{p.createInterruptionTolerantPriorityClass()}
+	}
+	return nil
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (p *PriorityClass) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsPresent(p.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
+
+// createInterruptionTolerantPriorityClass creates the low priority class of the workloads scheduled on spot and preemptible nodes
+func (p *PriorityClass) createInterruptionTolerantPriorityClass() *scheduling.PriorityClass {
+	preemptionPolicy := core.PreemptNever
+	return &scheduling.PriorityClass{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       priorityClassKind,
+			APIVersion: scheduling.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name: common.InterruptionTolerantPriorityClassName,
+		},
+		Value:            interruptionTolerantPriority,
+		GlobalDefault:    false,
+		PreemptionPolicy: &preemptionPolicy,
+		Description:      "Workloads which tolerate interruptions. They are preempted by the other workloads when the spot nodes are reclaimed and they fall back to the regular nodes.",
+	}
+}
@@ -0,0 +1,49 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	scheduling "k8s.io/kubernetes/pkg/apis/scheduling"
+)
+
+func TestCreateInterruptionTolerantPriorityClass(t *testing.T) {
+	ir := newRolloutTestIR(t)
+	p := &PriorityClass{}
+	if objs := p.createNewResources(ir, p.getSupportedKinds(), newRolloutTestCluster()); len(objs) != 0 {
+		t.Fatalf("expected no priority class without interruption tolerant services. Actual: %+v", objs)
+	}
+	for _, name := range []string{"api", "worker"} {
+		service := ir.Services[name]
+		service.PriorityClassName = common.InterruptionTolerantPriorityClassName
+		ir.Services[name] = service
+	}
+	objs := p.createNewResources(ir, p.getSupportedKinds(), newRolloutTestCluster())
+	if len(objs) != 1 {
+		t.Fatalf("expected a single priority class for all the interruption tolerant services. Actual: %+v", objs)
+	}
+	priorityClass := objs[0].(*scheduling.PriorityClass)
+	if priorityClass.Name != common.InterruptionTolerantPriorityClassName || priorityClass.Value >= 0 || priorityClass.GlobalDefault {
+		t.Fatalf("expected a priority class below the default priority. Actual: %+v", priorityClass)
+	}
+	if priorityClass.PreemptionPolicy == nil || *priorityClass.PreemptionPolicy != core.PreemptNever {
+		t.Fatalf("expected the interruption tolerant workloads to never preempt the others. Actual: %+v", priorityClass.PreemptionPolicy)
+	}
+}
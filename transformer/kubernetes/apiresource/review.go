@@ -0,0 +1,70 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// reviewObjects lists the generated objects of the transformer and removes the ones which were deselected
+func reviewObjects(transformerName string, objs []runtime.Object) []runtime.Object {
+	if !common.ReviewResources || len(objs) == 0 {
+		return objs
+	}
+	labels := []string{}
+	for _, obj := range objs {
+		labels = common.AppendIfNotPresent(labels, getReviewLabel(obj))
+	}
+	sort.Strings(labels)
+	selectedLabels := qaengine.FetchMultiSelectAnswer(
+		fmt.Sprintf(common.ConfigReviewResourcesKey, `"`+transformerName+`"`),
+		fmt.Sprintf("Select the resources generated by the %s transformer which should be written to the output :", transformerName),
+		[]string{"The resources are listed as <kind>/<name>. Deselect all the resources of a service, a kind or a pipeline to exclude it."},
+		labels,
+		labels,
+		nil,
+	)
+	if len(selectedLabels) == len(labels) {
+		return objs
+	}
+	selectedObjs := []runtime.Object{}
+	for _, obj := range objs {
+		label := getReviewLabel(obj)
+		if !common.IsPresent(selectedLabels, label) {
+			logrus.Infof("Excluding the resource %s generated by the %s transformer", label, transformerName)
+			continue
+		}
+		selectedObjs = append(selectedObjs, obj)
+	}
+	return selectedObjs
+}
+
+// getReviewLabel returns the kind and the name of the object
+func getReviewLabel(obj runtime.Object) string {
+	name := ""
+	if metaObj, err := meta.Accessor(obj); err == nil {
+		name = metaObj.GetName()
+	}
+	return obj.GetObjectKind().GroupVersionKind().Kind + "/" + name
+}
@@ -0,0 +1,75 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestReviewObjects(t *testing.T) {
+	newObjs := func() []runtime.Object {
+		return []runtime.Object{
+			createService("api", []v1.ServicePort{}),
+			&v1.ConfigMap{TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: "api"}},
+			createService("web", []v1.ServicePort{}),
+		}
+	}
+	getLabels := func(objs []runtime.Object) []string {
+		labels := []string{}
+		for _, obj := range objs {
+			labels = append(labels, getReviewLabel(obj))
+		}
+		return labels
+	}
+	reviewResources := common.ReviewResources
+	t.Cleanup(func() { common.ReviewResources = reviewResources })
+	reviewKey := fmt.Sprintf(common.ConfigReviewResourcesKey, `"Kubernetes"`)
+
+	t.Run("the objects are not reviewed by default", func(t *testing.T) {
+		common.ReviewResources = false
+		setupQAConfig(t, reviewKey+`=["Service/web"]`)
+		if got := getLabels(reviewObjects("Kubernetes", newObjs())); len(got) != 3 {
+			t.Fatalf("expected all the objects without the review, got %v", got)
+		}
+	})
+	t.Run("all the objects are selected by default", func(t *testing.T) {
+		common.ReviewResources = true
+		setupQAConfig(t)
+		want := []string{"Service/api", "ConfigMap/api", "Service/web"}
+		if diff := cmp.Diff(want, getLabels(reviewObjects("Kubernetes", newObjs()))); diff != "" {
+			t.Fatalf("got the wrong objects. Diff (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("the deselected objects are excluded", func(t *testing.T) {
+		common.ReviewResources = true
+		setupQAConfig(t, reviewKey+`=["ConfigMap/api", "Service/web"]`)
+		want := []string{"ConfigMap/api", "Service/web"}
+		if diff := cmp.Diff(want, getLabels(reviewObjects("Kubernetes", newObjs()))); diff != "" {
+			t.Fatalf("got the wrong objects. Diff (-want +got):\n%s", diff)
+		}
+		if got := getLabels(reviewObjects("Tekton", newObjs())); len(got) != 3 {
+			t.Fatalf("expected the review of the other transformer not to be affected, got %v", got)
+		}
+	})
+}
@@ -18,6 +18,7 @@ package apiresource
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/konveyor/move2kube/common"
@@ -37,6 +38,11 @@ import (
 
 const (
 	routeKind = "Route"
+	// grpcIngressSuffix is the suffix of the ingress of the gRPC services, when there are other services exposed too
+	grpcIngressSuffix = "-grpc"
+	grpcAppProtocol   = "grpc"
+	// headlessServiceSuffix is the suffix of the headless services governing the StatefulSets
+	headlessServiceSuffix = "-headless"
 )
 
 // Service handles all objects related to a service
@@ -51,6 +57,7 @@ func (d *Service) getSupportedKinds() []string {
 // createNewResources converts IR to runtime objects
 func (d *Service) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
 	objs := []runtime.Object{}
+	supportedKinds = d.filterExposeKinds(supportedKinds, targetCluster)
 	ingressEnabled := false
 	for _, service := range ir.Services {
 		exposeobjectcreated := false
@@ -79,12 +86,21 @@ func (d *Service) createNewResources(ir irtypes.EnhancedIR, supportedKinds []str
 		}
 		obj := d.createService(service)
 		objs = append(objs, obj)
+		if strategy := getProgressiveDeliveryStrategy(service); isArgoRolloutsStrategy(strategy) {
+			objs = append(objs, d.createRolloutService(obj, getRolloutServiceName(service.Name, strategy)))
+		}
+		if isStatefulSetService(service, targetCluster) {
+			objs = append(objs, d.createHeadlessService(obj, getHeadlessServiceName(service.Name)))
+		}
+	}
+
+	for _, externalService := range ir.ExternalServices {
+		objs = append(objs, d.createExternalNameService(externalService))
 	}
 
 	// Create one ingress for all services
 	if ingressEnabled {
-		obj := d.createIngress(ir, targetCluster)
-		if obj != nil {
+		for _, obj := range d.createIngress(ir, targetCluster) {
 			objs = append(objs, obj)
 		}
 	}
@@ -95,12 +111,13 @@ func (d *Service) createNewResources(ir irtypes.EnhancedIR, supportedKinds []str
 // convertToClusterSupportedKinds converts kinds to cluster supported kinds
 func (d *Service) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
 	lobj, _ := k8sschema.ConvertToLiasonScheme(obj)
+	supportedKinds = d.filterExposeKinds(supportedKinds, targetCluster)
 	if common.IsPresent(supportedKinds, routeKind) {
 		if _, ok := obj.(*okdroutev1.Route); ok {
 			return []runtime.Object{obj}, true
 		}
 		if ingress, ok := lobj.(*networking.Ingress); ok {
-			return d.ingressToRoute(*ingress), true
+			return d.ingressToRoute(*ingress, targetCluster), true
 		}
 		if _, ok := lobj.(*core.Service); ok {
 			return []runtime.Object{obj}, true
@@ -133,11 +150,83 @@ func (d *Service) convertToClusterSupportedKinds(obj runtime.Object, supportedKi
 	return nil, false
 }
 
-func (d *Service) ingressToRoute(ingress networking.Ingress) []runtime.Object {
+// filterExposeKinds removes Route from the supported kinds if the user prefers Ingress on clusters supporting both
+func (d *Service) filterExposeKinds(supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []string {
+	if !common.IsPresent(supportedKinds, routeKind) || !common.IsPresent(supportedKinds, common.IngressKind) {
+		return supportedKinds
+	}
+	quesKey := common.JoinQASubKeys(getClusterQaID(targetCluster), common.ConfigIngressUseRouteKeySuffix)
+	desc := "Do you want to generate OpenShift Routes instead of Ingress?"
+	hints := []string{"The target cluster supports both Routes and Ingress"}
+	if qaengine.FetchBoolAnswer(quesKey, desc, hints, true, nil) {
+		return supportedKinds
+	}
+	return common.Filter(supportedKinds, func(kind string) bool { return kind != routeKind })
+}
+
+// getRouteTLSConfig returns the TLS configuration for the routes as chosen by the user
+func (d *Service) getRouteTLSConfig(targetCluster collecttypes.ClusterMetadata) *okdroutev1.TLSConfig {
+	qaId := getClusterQaID(targetCluster)
+	const noTLSTermination = "none"
+	quesKey := common.JoinQASubKeys(qaId, common.ConfigRouteTLSTerminationKeySuffix)
+	desc := "Select the TLS termination type for the routes"
+	hints := []string{
+		"edge: TLS is terminated at the router",
+		"passthrough: encrypted traffic is sent straight to the pod",
+		"reencrypt: TLS is terminated at the router and re-encrypted to the pod",
+	}
+	options := []string{
+		noTLSTermination,
+		string(okdroutev1.TLSTerminationEdge),
+		string(okdroutev1.TLSTerminationPassthrough),
+		string(okdroutev1.TLSTerminationReencrypt),
+	}
+	termination := qaengine.FetchSelectAnswer(quesKey, desc, hints, noTLSTermination, options, nil)
+	if termination == noTLSTermination {
+		return nil
+	}
+	tlsConfig := &okdroutev1.TLSConfig{
+		Termination:                   okdroutev1.TLSTerminationType(termination),
+		InsecureEdgeTerminationPolicy: okdroutev1.InsecureEdgeTerminationPolicyRedirect,
+	}
+	if tlsConfig.Termination == okdroutev1.TLSTerminationReencrypt {
+		quesKey := common.JoinQASubKeys(qaId, common.ConfigRouteDestinationCACertKeySuffix)
+		desc := "Provide the CA certificate used to validate the certificate served by the pods"
+		hints := []string{"Leave empty to use the service serving certificate CA of the cluster"}
+		tlsConfig.DestinationCACertificate = qaengine.FetchMultilineInputAnswer(quesKey, desc, hints, "", nil)
+	}
+	return tlsConfig
+}
+
+// setRouteTLSConfig sets the TLS configuration on the route
+func setRouteTLSConfig(route *okdroutev1.Route, tlsConfig *okdroutev1.TLSConfig) {
+	if tlsConfig == nil {
+		return
+	}
+	route.Spec.TLS = tlsConfig.DeepCopy()
+	if tlsConfig.Termination == okdroutev1.TLSTerminationPassthrough {
+		// passthrough routes can not do path based routing since the router can't see the request
+		if route.Spec.Path != "" && route.Spec.Path != "/" {
+			logrus.Warnf("Dropping the path %s from the route %s since passthrough routes don't support path based routing", route.Spec.Path, route.Name)
+		}
+		route.Spec.Path = ""
+	}
+}
+
+func getClusterQaID(targetCluster collecttypes.ClusterMetadata) string {
+	qaLabel := collecttypes.DefaultClusterSpecificQaLabel
+	if _, ok := targetCluster.Labels[collecttypes.ClusterQaLabelKey]; ok {
+		qaLabel = targetCluster.Labels[collecttypes.ClusterQaLabelKey]
+	}
+	return common.JoinQASubKeys(common.ConfigTargetKey, `"`+qaLabel+`"`)
+}
+
+func (d *Service) ingressToRoute(ingress networking.Ingress, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
 	weight := int32(1)                                    //Hard-coded to 1 to avoid Helm v3 errors
 	ingressArray := []okdroutev1.RouteIngress{{Host: ""}} //Hard-coded to empty string to avoid Helm v3 errors
 
 	objs := []runtime.Object{}
+	tlsConfig := d.getRouteTLSConfig(targetCluster)
 
 	for _, ingressspec := range ingress.Spec.Rules {
 		for _, path := range ingressspec.IngressRuleValue.HTTP.Paths {
@@ -164,6 +253,7 @@ func (d *Service) ingressToRoute(ingress networking.Ingress) []runtime.Object {
 				},
 				Status: okdroutev1.RouteStatus{Ingress: ingressArray},
 			}
+			setRouteTLSConfig(route, tlsConfig)
 			objs = append(objs, route)
 		}
 	}
@@ -271,16 +361,34 @@ func (d *Service) ingressToService(ingress networking.Ingress) []runtime.Object
 func (d *Service) createRoutes(service irtypes.Service, ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) [](*okdroutev1.Route) {
 	routes := [](*okdroutev1.Route){}
 	servicePorts, hostPrefixes, relPaths, _ := d.getExposeInfo(service)
+	tlsConfig := d.getRouteTLSConfig(targetCluster)
 	for i, servicePort := range servicePorts {
 		if relPaths[i] == "" {
 			continue
 		}
 		route := d.createRoute(ir.Name, service, servicePort, hostPrefixes[i], relPaths[i], ir, targetCluster)
+		setRouteTLSConfig(route, tlsConfig)
 		routes = append(routes, route)
+		for _, relPath := range getAdditionalRelPaths(service, servicePort) {
+			route := d.createRoute(ir.Name, service, servicePort, hostPrefixes[i], relPath, ir, targetCluster)
+			route.Name = fmt.Sprintf("%s-%d", service.Name, len(routes))
+			setRouteTLSConfig(route, tlsConfig)
+			routes = append(routes, route)
+		}
 	}
 	return routes
 }
 
+// getAdditionalRelPaths returns the other paths routed to the service port
+func getAdditionalRelPaths(service irtypes.Service, servicePort core.ServicePort) []string {
+	for _, forwarding := range service.ServiceToPodPortForwardings {
+		if forwarding.ServicePort.Number == servicePort.Port && forwarding.Protocol == servicePort.Protocol {
+			return forwarding.AdditionalServiceRelPaths
+		}
+	}
+	return nil
+}
+
 //TODO: Remove these two sections after helm v3 issue is fixed
 //[https://github.com/openshift/origin/issues/24060]
 //[https://bugzilla.redhat.com/show_bug.cgi?id=1773682]
@@ -294,10 +402,7 @@ func (d *Service) createRoute(irName string, service irtypes.Service, port core.
 	if host == "" {
 		host = commonqa.IngressHost(d.getHostName(irName), targetCluster.Labels[collecttypes.ClusterQaLabelKey])
 	}
-	ph := host
-	if hostprefix != "" {
-		ph = hostprefix + "." + ph
-	}
+	ph := getPrefixedHost(hostprefix, host)
 	route := &okdroutev1.Route{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       routeKind,
@@ -324,11 +429,14 @@ func (d *Service) createRoute(irName string, service irtypes.Service, port core.
 	return route
 }
 
-// createIngress creates a single ingress for all services
-func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) *networking.Ingress {
+// createIngress creates a single ingress for all services.
+// The gRPC services get a separate ingress, since the backend protocol annotations apply to all the paths of an ingress.
+func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) []*networking.Ingress {
 	pathType := networking.PathTypePrefix
 
-	hostHTTPIngressPaths := map[string][]networking.HTTPIngressPath{} //[hostprefix]
+	hostHTTPIngressPaths := map[string][]networking.HTTPIngressPath{}     //[hostprefix]
+	grpcHostHTTPIngressPaths := map[string][]networking.HTTPIngressPath{} //[hostprefix]
+	sessionAffinity := false
 	for _, service := range ir.Services {
 		backendServiceName := service.BackendServiceName
 		if service.BackendServiceName == "" {
@@ -354,10 +462,21 @@ func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttype
 					},
 				},
 			}
-			hostHTTPIngressPaths[hostPrefixes[i]] = append(hostHTTPIngressPaths[hostPrefixes[i]], httpIngressPath)
+			paths := hostHTTPIngressPaths
+			if isGRPCPort(servicePort) {
+				paths = grpcHostHTTPIngressPaths
+			} else if service.SessionAffinity {
+				sessionAffinity = true
+			}
+			paths[hostPrefixes[i]] = append(paths[hostPrefixes[i]], httpIngressPath)
+			for _, relPath := range getAdditionalRelPaths(service, servicePort) {
+				additionalHTTPIngressPath := *httpIngressPath.DeepCopy()
+				additionalHTTPIngressPath.Path = relPath
+				paths[hostPrefixes[i]] = append(paths[hostPrefixes[i]], additionalHTTPIngressPath)
+			}
 		}
 	}
-	if len(hostHTTPIngressPaths) == 0 {
+	if len(hostHTTPIngressPaths) == 0 && len(grpcHostHTTPIngressPaths) == 0 {
 		return nil
 	}
 	qaLabel := collecttypes.DefaultClusterSpecificQaLabel
@@ -365,14 +484,14 @@ func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttype
 		qaLabel = targetCluster.Labels[collecttypes.ClusterQaLabelKey]
 	}
 	// QALabel prefix for cluster
-	qaId := common.JoinQASubKeys(common.ConfigTargetKey, `"`+qaLabel+`"`)
+	qaId := getClusterQaID(targetCluster)
+	// Choose the ingress controller preset
+	_, preset := getIngressControllerPreset(targetCluster)
 	// Set the default ingressClass value
 	quesKeyClass := common.JoinQASubKeys(qaId, common.ConfigIngressClassNameKeySuffix)
 	descClass := "Provide the Ingress class name for ingress"
-	ingressClassName := qaengine.FetchStringAnswer(quesKeyClass, descClass, []string{"Leave empty to use the cluster default"}, "", nil)
+	ingressClassName := qaengine.FetchStringAnswer(quesKeyClass, descClass, []string{"Leave empty to use the cluster default"}, preset.IngressClassName, nil)
 
-	// Configure the rule with the above fan-out paths
-	rules := []networking.IngressRule{}
 	host := targetCluster.Spec.Host
 	secretName := ""
 	defaultSecretName := ""
@@ -382,11 +501,35 @@ func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttype
 	quesKeyTLS := common.JoinQASubKeys(qaId, common.ConfigIngressTLSKeySuffix)
 	descTLS := "Provide the TLS secret for ingress"
 	secretName = qaengine.FetchStringAnswer(quesKeyTLS, descTLS, []string{"Leave empty to use http"}, defaultSecretName, nil)
-	for hostprefix, httpIngressPaths := range hostHTTPIngressPaths {
-		ph := host
-		if hostprefix != "" {
-			ph = hostprefix + "." + ph
+
+	ingresses := []*networking.Ingress{}
+	if len(hostHTTPIngressPaths) > 0 {
+		annotations := preset.getAnnotations(secretName != "")
+		if sessionAffinity {
+			// the affinity annotations apply to all the paths of the ingress, which does not break the services without sessions
+			annotations = common.MergeStringMaps(annotations, preset.SessionAffinityAnnotations)
+		}
+		ingresses = append(ingresses, d.newIngress(ir.Name, host, secretName, ingressClassName, hostHTTPIngressPaths, annotations))
+	}
+	if len(grpcHostHTTPIngressPaths) > 0 {
+		ingressName := ir.Name
+		if len(hostHTTPIngressPaths) > 0 {
+			ingressName += grpcIngressSuffix
+		}
+		if secretName == "" {
+			logrus.Warnf("The ingress %s of the gRPC services has no TLS secret. Most ingress controllers accept HTTP/2 requests only over TLS.", ingressName)
 		}
+		ingresses = append(ingresses, d.newIngress(ingressName, host, secretName, ingressClassName, grpcHostHTTPIngressPaths, preset.getGRPCAnnotations(secretName != "")))
+	}
+	return ingresses
+}
+
+// newIngress creates an ingress with the fan-out paths of the hosts
+func (d *Service) newIngress(ingressName, host, secretName, ingressClassName string, hostHTTPIngressPaths map[string][]networking.HTTPIngressPath, annotations map[string]string) *networking.Ingress {
+	// Configure the rule with the above fan-out paths
+	rules := []networking.IngressRule{}
+	for hostprefix, httpIngressPaths := range hostHTTPIngressPaths {
+		ph := getPrefixedHost(hostprefix, host)
 		rules = append(rules, networking.IngressRule{
 			Host: ph,
 			IngressRuleValue: networking.IngressRuleValue{
@@ -404,7 +547,6 @@ func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttype
 		}}
 	}
 
-	ingressName := ir.Name
 	ingress := networking.Ingress{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       common.IngressKind,
@@ -419,6 +561,9 @@ func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttype
 			TLS:   tls,
 		},
 	}
+	if len(annotations) > 0 {
+		ingress.ObjectMeta.Annotations = annotations
+	}
 	if ingressClassName != "" {
 		ingress.Spec.IngressClassName = &ingressClassName
 	}
@@ -426,6 +571,45 @@ func (d *Service) createIngress(ir irtypes.EnhancedIR, targetCluster collecttype
 	return &ingress
 }
 
+// GetIngressHosts returns the hosts of the ingress for all services, with the host prefixes of the services
+func GetIngressHosts(ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) []string {
+	d := &Service{}
+	hostPrefixes := []string{}
+	for _, service := range ir.Services {
+		_, prefixes, relPaths, _ := d.getExposeInfo(service)
+		for i, hostPrefix := range prefixes {
+			if relPaths[i] != "" {
+				hostPrefixes = common.AppendIfNotPresent(hostPrefixes, hostPrefix)
+			}
+		}
+	}
+	if len(hostPrefixes) == 0 {
+		return nil
+	}
+	host := targetCluster.Spec.Host
+	if host == "" {
+		qaLabel := collecttypes.DefaultClusterSpecificQaLabel
+		if _, ok := targetCluster.Labels[collecttypes.ClusterQaLabelKey]; ok {
+			qaLabel = targetCluster.Labels[collecttypes.ClusterQaLabelKey]
+		}
+		host = commonqa.IngressHost(d.getHostName(ir.Name), qaLabel)
+	}
+	hosts := []string{}
+	for _, hostPrefix := range hostPrefixes {
+		hosts = append(hosts, getPrefixedHost(hostPrefix, host))
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+// getPrefixedHost returns the host of the service with its host prefix. The prefix replaces the wildcard label of a wildcard host.
+func getPrefixedHost(hostPrefix, host string) string {
+	if hostPrefix == "" {
+		return host
+	}
+	return hostPrefix + "." + strings.TrimPrefix(host, "*.")
+}
+
 // createService creates a service
 func (d *Service) createService(service irtypes.Service) *core.Service {
 	ports, _, _, serviceType := d.getExposeInfo(service)
@@ -437,7 +621,7 @@ func (d *Service) createService(service irtypes.Service) *core.Service {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        service.Name,
 			Labels:      getServiceLabels(service.Name),
-			Annotations: getAnnotations(service),
+			Annotations: common.MergeStringMaps(getAnnotations(service), service.ServiceAnnotations),
 		},
 		Spec: core.ServiceSpec{
 			Type:     serviceType,
@@ -448,19 +632,83 @@ func (d *Service) createService(service irtypes.Service) *core.Service {
 	if len(ports) == 0 {
 		svc.Spec.ClusterIP = "None"
 	}
+	// the TODOs of the source constructs which could not be mapped are on the workload of the service, so they are reported only once
+	for key := range service.Annotations {
+		if strings.HasPrefix(key, common.TODOAnnotation) {
+			delete(svc.ObjectMeta.Annotations, key)
+		}
+	}
+	if service.SessionAffinity {
+		// the requests through the ingress are kept on the same pod by the cookie affinity of the ingress controller instead
+		svc.Spec.SessionAffinity = core.ServiceAffinityClientIP
+		svc.ObjectMeta.Annotations[common.TODOAnnotation+"sessionaffinity"] = "The service relies on sticky sessions, which are lost when its pods are restarted or scaled down. Store the sessions outside the pods, like in a cache or a database, to make it stateless."
+	}
 	return svc
 }
 
+// createRolloutService creates the internal service which Argo Rollouts points to the pods of the new version during a rollout
+func (d *Service) createRolloutService(svc *core.Service, name string) *core.Service {
+	rolloutSvc := svc.DeepCopy()
+	rolloutSvc.ObjectMeta.Name = name
+	rolloutSvc.Spec.Type = core.ServiceTypeClusterIP
+	for i := range rolloutSvc.Spec.Ports {
+		rolloutSvc.Spec.Ports[i].NodePort = 0
+	}
+	return rolloutSvc
+}
+
+// createExternalNameService creates the service which resolves to the host name of a dependency outside the cluster
+func (d *Service) createExternalNameService(externalService irtypes.ExternalService) *core.Service {
+	return &core.Service{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       common.ServiceKind,
+			APIVersion: core.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   externalService.Name,
+			Labels: getServiceLabels(externalService.Name),
+		},
+		Spec: core.ServiceSpec{
+			Type:         core.ServiceTypeExternalName,
+			ExternalName: externalService.ExternalName,
+		},
+	}
+}
+
+// createHeadlessService creates the service which gives the pods of a StatefulSet their stable DNS names
+func (d *Service) createHeadlessService(svc *core.Service, name string) *core.Service {
+	headlessSvc := svc.DeepCopy()
+	headlessSvc.ObjectMeta.Name = name
+	headlessSvc.Spec.Type = core.ServiceTypeClusterIP
+	headlessSvc.Spec.ClusterIP = core.ClusterIPNone
+	// the peers of the clustered workloads need to find each other before they are ready
+	headlessSvc.Spec.PublishNotReadyAddresses = true
+	for i := range headlessSvc.Spec.Ports {
+		headlessSvc.Spec.Ports[i].NodePort = 0
+	}
+	return headlessSvc
+}
+
+// getHeadlessServiceName returns the name of the headless service of the StatefulSet of the service
+func getHeadlessServiceName(serviceName string) string {
+	return serviceName + headlessServiceSuffix
+}
+
 // GetServicePorts configure the container service ports.
 func (d *Service) getExposeInfo(service irtypes.Service) (servicePorts []core.ServicePort, hostPrefixes []string, relPaths []string, serviceType core.ServiceType) {
 	servicePorts = []core.ServicePort{}
 	relPaths = []string{}
 	hostPrefixes = []string{}
 	serviceType = core.ServiceTypeClusterIP
+	serviceMesh := commonqa.ServiceMesh()
 	for _, forwarding := range service.ServiceToPodPortForwardings {
 		servicePortName := forwarding.ServicePort.Name
 		if servicePortName == "" {
 			servicePortName = fmt.Sprintf("port-%d", forwarding.ServicePort.Number)
+			if protocol := forwarding.GetProtocol(); protocol != core.ProtocolTCP {
+				// the same port number can be used for TCP too, like for DNS
+				servicePortName += "-" + strings.ToLower(string(protocol))
+			}
 		}
 		targetPort := intstr.IntOrString{Type: intstr.String, StrVal: forwarding.PodPort.Name}
 		if forwarding.PodPort.Name == "" {
@@ -471,6 +719,15 @@ func (d *Service) getExposeInfo(service irtypes.Service) (servicePorts []core.Se
 			Name:       servicePortName,
 			Port:       forwarding.ServicePort.Number,
 			TargetPort: targetPort,
+			Protocol:   forwarding.Protocol,
+		}
+		if forwarding.ServiceType == core.ServiceTypeNodePort {
+			// the clients of the published port can keep using it on the nodes
+			servicePort.NodePort = forwarding.GetNodePort()
+		}
+		// gRPC needs HTTP/2 even without a service mesh, so the load balancers and the ingress controllers use it for the backends
+		if appProtocol := getAppProtocol(servicePortName, forwarding.ServicePort.Number); forwarding.GetProtocol() == core.ProtocolTCP && (serviceMesh != commonqa.NoServiceMesh || appProtocol == grpcAppProtocol) {
+			servicePort.AppProtocol = &appProtocol
 		}
 		switch forwarding.ServiceType {
 		case core.ServiceTypeLoadBalancer:
@@ -488,6 +745,10 @@ func (d *Service) getExposeInfo(service irtypes.Service) (servicePorts []core.Se
 		}
 		hostPrefix := ""
 		relPath := forwarding.ServiceRelPath
+		if forwarding.GetProtocol() != core.ProtocolTCP {
+			// only http can be routed by the ingress and the routes
+			relPath = ""
+		}
 		if relPath != "" && !strings.HasPrefix(relPath, `/`) {
 			parts := []string{relPath}
 			if strings.Contains(relPath, `/`) {
@@ -506,6 +767,30 @@ func (d *Service) getExposeInfo(service irtypes.Service) (servicePorts []core.Se
 	return servicePorts, hostPrefixes, relPaths, serviceType
 }
 
+// isGRPCPort returns true if the service port serves gRPC
+func isGRPCPort(servicePort core.ServicePort) bool {
+	return servicePort.AppProtocol != nil && *servicePort.AppProtocol == grpcAppProtocol
+}
+
+// getAppProtocol guesses the application protocol of the port from its name and number, so that the service mesh does not have to detect it
+func getAppProtocol(portName string, port int32) string {
+	portName = strings.ToLower(portName)
+	for _, protocol := range []string{"grpc", "http2", "https", "http"} {
+		if strings.HasPrefix(portName, protocol) {
+			return protocol
+		}
+	}
+	switch port {
+	case 443, 8443:
+		return "https"
+	case 80, 3000, 5000, 8000, 8080, 8081, 9080:
+		return "http"
+	case 50051:
+		return "grpc"
+	}
+	return "tcp"
+}
+
 func (d *Service) getHostName(irName string) string {
-	return irName + ".com"
+	return common.MakeStringDNSSubdomainNameCompliant(irName) + ".com"
 }
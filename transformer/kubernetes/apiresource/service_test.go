@@ -0,0 +1,520 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	okdroutev1 "github.com/openshift/api/route/v1"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestGetPrefixedHost(t *testing.T) {
+	testCases := []struct {
+		hostPrefix string
+		host       string
+		want       string
+	}{
+		{hostPrefix: "", host: "myproject.example.com", want: "myproject.example.com"},
+		{hostPrefix: "api", host: "myproject.example.com", want: "api.myproject.example.com"},
+		{hostPrefix: "", host: "*.apps.example.com", want: "*.apps.example.com"},
+		{hostPrefix: "api", host: "*.apps.example.com", want: "api.apps.example.com"},
+	}
+	for _, testCase := range testCases {
+		if got := getPrefixedHost(testCase.hostPrefix, testCase.host); got != testCase.want {
+			t.Errorf("getPrefixedHost(%q, %q) = %q, want %q", testCase.hostPrefix, testCase.host, got, testCase.want)
+		}
+	}
+}
+
+func TestCreateServiceLeavesOutTheTODOs(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	service := irtypes.NewServiceWithName("web")
+	service.Annotations = map[string]string{
+		"team":                                "payments",
+		common.TODOAnnotation + "compose.dns": "The compose option dns (8.8.8.8) of the service web was not mapped.",
+	}
+	svc := (&Service{}).createService(service)
+	if want := map[string]string{"team": "payments"}; !cmp.Equal(svc.Annotations, want) {
+		t.Fatalf("the annotations of the service are different. Difference:\n%s", cmp.Diff(want, svc.Annotations))
+	}
+	if len(service.Annotations) != 2 {
+		t.Fatalf("expected the TODOs to be kept on the IR service for the workload, got %+v", service.Annotations)
+	}
+}
+
+func TestCreateIngressWithThePresets(t *testing.T) {
+	testCases := []struct {
+		name              string
+		preset            string
+		clusterController string
+		tlsSecret         string
+		wantClassName     string
+		wantAnnotations   map[string]string
+	}{
+		{name: "no preset by default", wantAnnotations: map[string]string{}},
+		{name: "the controller of the cluster by default", clusterController: nginxIngressControllerPreset, wantClassName: "nginx", wantAnnotations: map[string]string{
+			"nginx.ingress.kubernetes.io/backend-protocol": "HTTP",
+		}},
+		{name: "none", preset: noIngressControllerPreset, clusterController: nginxIngressControllerPreset, wantAnnotations: map[string]string{}},
+		{name: "aws-alb", preset: "aws-alb", wantClassName: "alb", wantAnnotations: map[string]string{
+			"alb.ingress.kubernetes.io/scheme":           "internet-facing",
+			"alb.ingress.kubernetes.io/target-type":      "ip",
+			"alb.ingress.kubernetes.io/backend-protocol": "HTTP",
+			"alb.ingress.kubernetes.io/healthcheck-path": "/",
+		}},
+		{name: "aws-alb with tls", preset: "aws-alb", tlsSecret: "web-tls", wantClassName: "alb", wantAnnotations: map[string]string{
+			"alb.ingress.kubernetes.io/scheme":           "internet-facing",
+			"alb.ingress.kubernetes.io/target-type":      "ip",
+			"alb.ingress.kubernetes.io/backend-protocol": "HTTP",
+			"alb.ingress.kubernetes.io/healthcheck-path": "/",
+			"alb.ingress.kubernetes.io/listen-ports":     `[{"HTTP": 80}, {"HTTPS": 443}]`,
+			"alb.ingress.kubernetes.io/ssl-redirect":     "443",
+		}},
+		{name: "gce", preset: "gce", wantAnnotations: map[string]string{
+			"kubernetes.io/ingress.class": "gce",
+		}},
+		{name: "gce with tls", preset: "gce", tlsSecret: "web-tls", wantAnnotations: map[string]string{
+			"kubernetes.io/ingress.class":      "gce",
+			"kubernetes.io/ingress.allow-http": "false",
+		}},
+		{name: "nginx with tls", preset: nginxIngressControllerPreset, tlsSecret: "web-tls", wantClassName: "nginx", wantAnnotations: map[string]string{
+			"nginx.ingress.kubernetes.io/backend-protocol": "HTTP",
+			"nginx.ingress.kubernetes.io/ssl-redirect":     "true",
+		}},
+		{name: "traefik", preset: "traefik", wantClassName: "traefik", wantAnnotations: map[string]string{
+			"traefik.ingress.kubernetes.io/router.entrypoints": "web",
+		}},
+		{name: "traefik with tls", preset: "traefik", tlsSecret: "web-tls", wantClassName: "traefik", wantAnnotations: map[string]string{
+			"traefik.ingress.kubernetes.io/router.entrypoints": "websecure",
+			"traefik.ingress.kubernetes.io/router.tls":         "true",
+		}},
+		{name: "haproxy", preset: "haproxy", wantClassName: "haproxy", wantAnnotations: map[string]string{
+			"haproxy.org/check":      "true",
+			"haproxy.org/check-http": "/",
+		}},
+		{name: "haproxy with tls", preset: "haproxy", tlsSecret: "web-tls", wantClassName: "haproxy", wantAnnotations: map[string]string{
+			"haproxy.org/check":        "true",
+			"haproxy.org/check-http":   "/",
+			"haproxy.org/ssl-redirect": "true",
+		}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			targetCluster := collecttypes.NewClusterMetadata("")
+			targetCluster.Spec.Host = "myproject.example.com"
+			targetCluster.Spec.IngressController = testCase.clusterController
+			qaID := getClusterQaID(targetCluster)
+			configs := []string{common.JoinQASubKeys(qaID, common.ConfigIngressTLSKeySuffix) + `="` + testCase.tlsSecret + `"`}
+			if testCase.preset != "" {
+				configs = append(configs, common.JoinQASubKeys(qaID, common.ConfigIngressControllerKeySuffix)+`="`+testCase.preset+`"`)
+			}
+			qaengine.Reset()
+			defer qaengine.Reset()
+			qaengine.AddEngine(qaengine.NewDefaultEngine())
+			qaengine.SetupConfigFile("", configs, nil, nil, false)
+
+			ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+			ir.Name = "myproject"
+			service := irtypes.NewServiceWithName("web")
+			service.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{{
+				ServicePort:    networking.ServiceBackendPort{Number: 8080},
+				PodPort:        networking.ServiceBackendPort{Number: 8080},
+				ServiceRelPath: "/web",
+				ServiceType:    core.ServiceTypeClusterIP,
+			}}
+			ir.Services[service.Name] = service
+
+			ingresses := (&Service{}).createIngress(ir, targetCluster)
+			if len(ingresses) != 1 {
+				t.Fatalf("expected a single ingress, got %d ingresses", len(ingresses))
+			}
+			ingress := ingresses[0]
+			className := ""
+			if ingress.Spec.IngressClassName != nil {
+				className = *ingress.Spec.IngressClassName
+			}
+			if className != testCase.wantClassName {
+				t.Fatalf("got the ingress class name %q , want %q", className, testCase.wantClassName)
+			}
+			annotations := ingress.Annotations
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			if !cmp.Equal(annotations, testCase.wantAnnotations) {
+				t.Fatalf("the annotations of the ingress are different. Difference:\n%s", cmp.Diff(testCase.wantAnnotations, annotations))
+			}
+			if hasTLS := len(ingress.Spec.TLS) != 0; hasTLS != (testCase.tlsSecret != "") {
+				t.Fatalf("got the tls %+v for the tls secret %q", ingress.Spec.TLS, testCase.tlsSecret)
+			}
+		})
+	}
+}
+
+func TestCreateIngressForGRPCServices(t *testing.T) {
+	targetCluster := collecttypes.NewClusterMetadata("")
+	targetCluster.Spec.Host = "myproject.example.com"
+	qaID := getClusterQaID(targetCluster)
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{
+		common.JoinQASubKeys(qaID, common.ConfigIngressTLSKeySuffix) + `="myproject-tls"`,
+		common.JoinQASubKeys(qaID, common.ConfigIngressControllerKeySuffix) + `="` + nginxIngressControllerPreset + `"`,
+	}, nil, nil, false)
+
+	ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+	ir.Name = "myproject"
+	web := irtypes.NewServiceWithName("web")
+	web.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{{
+		ServicePort:    networking.ServiceBackendPort{Number: 8080},
+		PodPort:        networking.ServiceBackendPort{Number: 8080},
+		ServiceRelPath: "/web",
+		ServiceType:    core.ServiceTypeClusterIP,
+	}}
+	ir.Services[web.Name] = web
+	greeter := irtypes.NewServiceWithName("greeter")
+	greeter.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{{
+		ServicePort:    networking.ServiceBackendPort{Name: "grpc", Number: 9090},
+		PodPort:        networking.ServiceBackendPort{Name: "h2c", Number: 9090},
+		ServiceRelPath: "greeter-grpc",
+		ServiceType:    core.ServiceTypeClusterIP,
+	}}
+	ir.Services[greeter.Name] = greeter
+
+	svc := (&Service{}).createService(greeter)
+	if len(svc.Spec.Ports) != 1 || svc.Spec.Ports[0].AppProtocol == nil || *svc.Spec.Ports[0].AppProtocol != grpcAppProtocol {
+		t.Fatalf("expected the grpc app protocol on the service port without a service mesh. Actual: %+v", svc.Spec.Ports)
+	}
+
+	ingresses := map[string]*networking.Ingress{}
+	for _, ingress := range (&Service{}).createIngress(ir, targetCluster) {
+		ingresses[ingress.Name] = ingress
+	}
+	if len(ingresses) != 2 || ingresses["myproject"] == nil || ingresses["myproject"+grpcIngressSuffix] == nil {
+		t.Fatalf("expected an ingress for the http services and another for the gRPC services. Actual: %+v", ingresses)
+	}
+	if protocol := ingresses["myproject"].Annotations["nginx.ingress.kubernetes.io/backend-protocol"]; protocol != "HTTP" {
+		t.Fatalf("got the backend protocol %q for the http services, want HTTP", protocol)
+	}
+	grpcIngress := ingresses["myproject"+grpcIngressSuffix]
+	if protocol := grpcIngress.Annotations["nginx.ingress.kubernetes.io/backend-protocol"]; protocol != "GRPC" {
+		t.Fatalf("got the backend protocol %q for the gRPC services, want GRPC", protocol)
+	}
+	if len(grpcIngress.Spec.Rules) != 1 || grpcIngress.Spec.Rules[0].Host != "greeter-grpc.myproject.example.com" {
+		t.Fatalf("expected the gRPC service on its own subdomain. Actual: %+v", grpcIngress.Spec.Rules)
+	}
+	paths := grpcIngress.Spec.Rules[0].HTTP.Paths
+	if len(paths) != 1 || paths[0].Path != "/" || paths[0].Backend.Service.Name != "greeter" {
+		t.Fatalf("expected all the paths of the subdomain to be routed to the greeter service. Actual: %+v", paths)
+	}
+	if len(grpcIngress.Spec.TLS) != 1 || grpcIngress.Spec.TLS[0].SecretName != "myproject-tls" {
+		t.Fatalf("expected the tls secret on the gRPC ingress. Actual: %+v", grpcIngress.Spec.TLS)
+	}
+}
+
+func TestGRPCAnnotationsOfThePresets(t *testing.T) {
+	haproxy := ingressControllerPresets["haproxy"]
+	annotations := haproxy.getGRPCAnnotations(false)
+	if _, ok := annotations["haproxy.org/check-http"]; ok {
+		t.Fatalf("expected the http health check to be removed for gRPC. Actual: %+v", annotations)
+	}
+	if annotations["haproxy.org/server-proto"] != "h2" || annotations["haproxy.org/check"] != "true" {
+		t.Fatalf("expected HTTP/2 to the backends and the other annotations to be kept. Actual: %+v", annotations)
+	}
+	alb := ingressControllerPresets["aws-alb"]
+	if annotations := alb.getGRPCAnnotations(false); annotations["alb.ingress.kubernetes.io/backend-protocol-version"] != "GRPC" {
+		t.Fatalf("expected the GRPC backend protocol version. Actual: %+v", annotations)
+	}
+}
+
+func TestCreateServiceWithTheProtocolsOfThePorts(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	service := irtypes.NewServiceWithName("dns")
+	for _, protocol := range []core.Protocol{core.ProtocolTCP, core.ProtocolUDP} {
+		port := networking.ServiceBackendPort{Number: 53}
+		if err := service.AddPortForwardingWithProtocol(port, port, "", protocol); err != nil {
+			t.Fatalf("failed to add the port forwarding. Error: %q", err)
+		}
+	}
+	for i := range service.ServiceToPodPortForwardings {
+		service.ServiceToPodPortForwardings[i].ServiceType = core.ServiceTypeClusterIP
+		service.ServiceToPodPortForwardings[i].ServiceRelPath = "/dns"
+	}
+	svc := (&Service{}).createService(service)
+	ports := map[string]core.Protocol{}
+	for _, port := range svc.Spec.Ports {
+		ports[port.Name] = port.Protocol
+		if port.AppProtocol != nil {
+			t.Fatalf("expected no app protocol without a service mesh. Actual: %q", *port.AppProtocol)
+		}
+	}
+	if want := map[string]core.Protocol{"port-53": "", "port-53-udp": core.ProtocolUDP}; !cmp.Equal(ports, want) {
+		t.Fatalf("unexpected service ports. Differences: %s", cmp.Diff(want, ports))
+	}
+	_, _, relPaths, _ := (&Service{}).getExposeInfo(service)
+	if want := []string{"/dns", ""}; !cmp.Equal(relPaths, want) {
+		t.Fatalf("expected only the tcp port to be exposed on the ingress. Differences: %s", cmp.Diff(want, relPaths))
+	}
+}
+
+func TestCreateExternalNameServices(t *testing.T) {
+	setupQAConfig(t)
+	ir := newRolloutTestIR(t)
+	ir.Name = "myapp"
+	ir.ExternalServices = map[string]irtypes.ExternalService{"orders": {Name: "orders", ExternalName: "orders.db.example.com"}}
+	services := map[string]*core.Service{}
+	for _, obj := range getObjectsByKind((&Service{}).createNewResources(ir, []string{common.ServiceKind}, newRolloutTestCluster()))[common.ServiceKind] {
+		svc := obj.(*core.Service)
+		services[svc.Name] = svc
+	}
+	externalService, ok := services["orders"]
+	if !ok {
+		t.Fatalf("expected the ExternalName service orders. Actual: %+v", services)
+	}
+	if externalService.Spec.Type != core.ServiceTypeExternalName || externalService.Spec.ExternalName != "orders.db.example.com" || len(externalService.Spec.Ports) != 0 {
+		t.Fatalf("expected an ExternalName service resolving to orders.db.example.com. Actual: %+v", externalService.Spec)
+	}
+	if !cmp.Equal(externalService.Labels, getServiceLabels("orders")) {
+		t.Fatalf("unexpected labels. Differences: %s", cmp.Diff(getServiceLabels("orders"), externalService.Labels))
+	}
+}
+
+func TestCreateServiceKeepsThePublishedNodePorts(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	service := irtypes.NewServiceWithName("web")
+	service.ServiceAnnotations = map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"}
+	for _, hostPort := range []int32{30080, 8443} {
+		port := networking.ServiceBackendPort{Number: hostPort}
+		if err := service.AddPublishedPortForwarding(port, port, core.ProtocolTCP, hostPort); err != nil {
+			t.Fatalf("failed to add the port forwarding. Error: %q", err)
+		}
+	}
+	for i := range service.ServiceToPodPortForwardings {
+		service.ServiceToPodPortForwardings[i].ServiceType = core.ServiceTypeNodePort
+	}
+	svc := (&Service{}).createService(service)
+	if svc.Spec.Type != core.ServiceTypeNodePort {
+		t.Fatalf("expected a NodePort service. Actual: %s", svc.Spec.Type)
+	}
+	nodePorts := map[int32]int32{}
+	for _, port := range svc.Spec.Ports {
+		nodePorts[port.Port] = port.NodePort
+	}
+	if want := map[int32]int32{30080: 30080, 8443: 0}; !cmp.Equal(nodePorts, want) {
+		t.Fatalf("expected only the port published in the node port range to be kept. Differences: %s", cmp.Diff(want, nodePorts))
+	}
+	if svc.Annotations["service.beta.kubernetes.io/aws-load-balancer-internal"] != "true" {
+		t.Fatalf("expected the annotations of the service to be set. Actual: %+v", svc.Annotations)
+	}
+}
+
+func TestCreateRoutesOnOpenShift(t *testing.T) {
+	newIR := func() irtypes.EnhancedIR {
+		ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+		ir.Name = "myproject"
+		service := irtypes.NewServiceWithName("web")
+		service.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{{
+			ServicePort:    networking.ServiceBackendPort{Number: 8080},
+			PodPort:        networking.ServiceBackendPort{Number: 8080},
+			ServiceRelPath: "/web",
+			ServiceType:    core.ServiceTypeClusterIP,
+		}}
+		ir.Services[service.Name] = service
+		return ir
+	}
+	targetCluster := collecttypes.NewClusterMetadata("")
+	targetCluster.Spec.Host = "apps.example.com"
+	qaID := getClusterQaID(targetCluster)
+	testCases := []struct {
+		name    string
+		configs []string
+		wantTLS *okdroutev1.TLSConfig
+		path    string
+	}{
+		{name: "no tls termination by default", path: "/web"},
+		{name: "edge", configs: []string{common.JoinQASubKeys(qaID, common.ConfigRouteTLSTerminationKeySuffix) + `="edge"`}, path: "/web", wantTLS: &okdroutev1.TLSConfig{
+			Termination:                   okdroutev1.TLSTerminationEdge,
+			InsecureEdgeTerminationPolicy: okdroutev1.InsecureEdgeTerminationPolicyRedirect,
+		}},
+		{name: "passthrough drops the path", configs: []string{common.JoinQASubKeys(qaID, common.ConfigRouteTLSTerminationKeySuffix) + `="passthrough"`}, wantTLS: &okdroutev1.TLSConfig{
+			Termination:                   okdroutev1.TLSTerminationPassthrough,
+			InsecureEdgeTerminationPolicy: okdroutev1.InsecureEdgeTerminationPolicyRedirect,
+		}},
+		{name: "reencrypt with the destination ca certificate", configs: []string{
+			common.JoinQASubKeys(qaID, common.ConfigRouteTLSTerminationKeySuffix) + `="reencrypt"`,
+			common.JoinQASubKeys(qaID, common.ConfigRouteDestinationCACertKeySuffix) + `="ca certificate"`,
+		}, path: "/web", wantTLS: &okdroutev1.TLSConfig{
+			Termination:                   okdroutev1.TLSTerminationReencrypt,
+			InsecureEdgeTerminationPolicy: okdroutev1.InsecureEdgeTerminationPolicyRedirect,
+			DestinationCACertificate:      "ca certificate",
+		}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			setupQAConfig(t, testCase.configs...)
+			objs := (&Service{}).createNewResources(newIR(), (&Service{}).getSupportedKinds(), targetCluster)
+			routes := []*okdroutev1.Route{}
+			for _, obj := range objs {
+				if _, ok := obj.(*networking.Ingress); ok {
+					t.Fatalf("expected no ingress when creating the routes, got %+v", obj)
+				}
+				if route, ok := obj.(*okdroutev1.Route); ok {
+					routes = append(routes, route)
+				}
+			}
+			if len(routes) != 1 {
+				t.Fatalf("expected a single route, got %d routes", len(routes))
+			}
+			if !cmp.Equal(routes[0].Spec.TLS, testCase.wantTLS) {
+				t.Fatalf("the tls of the route is different. Difference:\n%s", cmp.Diff(testCase.wantTLS, routes[0].Spec.TLS))
+			}
+			if routes[0].Spec.Path != testCase.path {
+				t.Fatalf("got the path %q , want %q", routes[0].Spec.Path, testCase.path)
+			}
+		})
+	}
+	t.Run("ingress instead of routes", func(t *testing.T) {
+		setupQAConfig(t, common.JoinQASubKeys(qaID, common.ConfigIngressUseRouteKeySuffix)+"=false")
+		objs := (&Service{}).createNewResources(newIR(), (&Service{}).getSupportedKinds(), targetCluster)
+		ingresses := 0
+		for _, obj := range objs {
+			if _, ok := obj.(*okdroutev1.Route); ok {
+				t.Fatalf("expected no route when creating the ingress, got %+v", obj)
+			}
+			if _, ok := obj.(*networking.Ingress); ok {
+				ingresses++
+			}
+		}
+		if ingresses != 1 {
+			t.Fatalf("expected a single ingress, got %d ingresses", ingresses)
+		}
+	})
+}
+
+func TestGetAppProtocol(t *testing.T) {
+	testCases := []struct {
+		portName string
+		port     int32
+		want     string
+	}{
+		{portName: "GRPC-api", port: 9000, want: "grpc"},
+		{portName: "http2-web", port: 9000, want: "http2"},
+		{portName: "https", port: 9000, want: "https"},
+		{portName: "http-metrics", port: 9000, want: "http"},
+		{portName: "port-8443", port: 8443, want: "https"},
+		{portName: "port-8080", port: 8080, want: "http"},
+		{portName: "port-50051", port: 50051, want: "grpc"},
+		{portName: "port-5432", port: 5432, want: "tcp"},
+	}
+	for _, testCase := range testCases {
+		if got := getAppProtocol(testCase.portName, testCase.port); got != testCase.want {
+			t.Errorf("getAppProtocol(%q, %d) = %q, want %q", testCase.portName, testCase.port, got, testCase.want)
+		}
+	}
+}
+
+func TestCreateServiceWithTheAppProtocolsOfTheServiceMesh(t *testing.T) {
+	setupQAConfig(t, common.ConfigServiceMeshKey+`="`+commonqa.LinkerdServiceMesh+`"`)
+	service := irtypes.NewServiceWithName("api")
+	for _, port := range []networking.ServiceBackendPort{{Number: 8080}, {Number: 5432}} {
+		if err := service.AddPortForwarding(port, port, ""); err != nil {
+			t.Fatalf("failed to add the port forwarding. Error: %q", err)
+		}
+	}
+	for i := range service.ServiceToPodPortForwardings {
+		service.ServiceToPodPortForwardings[i].ServiceType = core.ServiceTypeClusterIP
+	}
+	svc := (&Service{}).createService(service)
+	appProtocols := map[int32]string{}
+	for _, port := range svc.Spec.Ports {
+		if port.AppProtocol == nil {
+			t.Fatalf("expected the app protocol to be set with a service mesh. Actual: %+v", port)
+		}
+		appProtocols[port.Port] = *port.AppProtocol
+	}
+	if want := map[int32]string{8080: "http", 5432: "tcp"}; !cmp.Equal(appProtocols, want) {
+		t.Fatalf("the app protocols are different. Difference:\n%s", cmp.Diff(want, appProtocols))
+	}
+}
+
+func TestCreateServiceAndIngressWithSessionAffinity(t *testing.T) {
+	targetCluster := collecttypes.NewClusterMetadata("")
+	targetCluster.Spec.Host = "myproject.example.com"
+	qaID := getClusterQaID(targetCluster)
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{
+		common.JoinQASubKeys(qaID, common.ConfigIngressTLSKeySuffix) + `=""`,
+		common.JoinQASubKeys(qaID, common.ConfigIngressControllerKeySuffix) + `="` + nginxIngressControllerPreset + `"`,
+	}, nil, nil, false)
+
+	ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+	ir.Name = "myproject"
+	for _, name := range []string{"cart", "catalog"} {
+		service := irtypes.NewServiceWithName(name)
+		service.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{{
+			ServicePort:    networking.ServiceBackendPort{Number: 8080},
+			PodPort:        networking.ServiceBackendPort{Number: 8080},
+			ServiceRelPath: "/" + name,
+			ServiceType:    core.ServiceTypeClusterIP,
+		}}
+		ir.Services[name] = service
+	}
+	cart := ir.Services["cart"]
+	cart.SessionAffinity = true
+	ir.Services["cart"] = cart
+
+	svc := (&Service{}).createService(cart)
+	if svc.Spec.SessionAffinity != core.ServiceAffinityClientIP {
+		t.Fatalf("got the session affinity %q , want %q", svc.Spec.SessionAffinity, core.ServiceAffinityClientIP)
+	}
+	if _, ok := svc.Annotations[common.TODOAnnotation+"sessionaffinity"]; !ok {
+		t.Fatalf("expected a TODO to make the service stateless. Actual annotations: %+v", svc.Annotations)
+	}
+	if svc := (&Service{}).createService(ir.Services["catalog"]); svc.Spec.SessionAffinity != "" || len(svc.Annotations) != 0 {
+		t.Fatalf("expected no session affinity for the service without sessions. Actual: %+v", svc)
+	}
+
+	ingresses := (&Service{}).createIngress(ir, targetCluster)
+	if len(ingresses) != 1 {
+		t.Fatalf("expected a single ingress, got %d ingresses", len(ingresses))
+	}
+	want := map[string]string{
+		"nginx.ingress.kubernetes.io/backend-protocol":    "HTTP",
+		"nginx.ingress.kubernetes.io/affinity":            "cookie",
+		"nginx.ingress.kubernetes.io/affinity-mode":       "persistent",
+		"nginx.ingress.kubernetes.io/session-cookie-name": "route",
+	}
+	if !cmp.Equal(ingresses[0].Annotations, want) {
+		t.Fatalf("the annotations of the ingress are different. Difference:\n%s", cmp.Diff(want, ingresses[0].Annotations))
+	}
+}
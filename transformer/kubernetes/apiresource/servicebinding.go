@@ -0,0 +1,114 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// serviceBindingKind defines the ServiceBinding Kind of the servicebinding.io specification
+	serviceBindingKind       = "ServiceBinding"
+	serviceBindingAPIVersion = "servicebinding.io/v1"
+)
+
+// ServiceBinding handles the bindings of the services to their backing services
+type ServiceBinding struct {
+}
+
+// getSupportedKinds returns all kinds supported by the class
+func (s *ServiceBinding) getSupportedKinds() []string {
+	return []string{serviceBindingKind}
+}
+
+// createNewResources converts ir to runtime objects
+func (s *ServiceBinding) createNewResources(ir irtypes.EnhancedIR, supportedKinds []string, targetCluster collecttypes.ClusterMetadata) []runtime.Object {
+	// Since the bindings are projected by a controller, the supported kinds of the cluster are ignored, like for the Crossplane claims
+	serviceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if len(service.ServiceBindings) != 0 {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	sort.Strings(serviceNames)
+	objs := []runtime.Object{}
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		workload, ok := s.getWorkloadRef(service, targetCluster)
+		if !ok {
+			logrus.Warnf("Ignoring the service bindings of the service %s, since it is not deployed as a long running workload", serviceName)
+			continue
+		}
+		for _, binding := range service.ServiceBindings {
+			objs = append(objs, s.createServiceBinding(service, binding, workload))
+		}
+	}
+	return objs
+}
+
+// convertToClusterSupportedKinds converts kinds to cluster supported kinds
+func (s *ServiceBinding) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
+	if common.IsPresent(s.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
+		return []runtime.Object{obj}, true
+	}
+	return nil, false
+}
+
+// getWorkloadRef returns the reference to the workload created for the service, in the same way as the Deployment api resource
+func (s *ServiceBinding) getWorkloadRef(service irtypes.Service, targetCluster collecttypes.ClusterMetadata) (map[string]interface{}, bool) {
+	if service.Daemon {
+		return map[string]interface{}{"apiVersion": appsv1.SchemeGroupVersion.String(), "kind": daemonSetKind, "name": service.Name}, true
+	}
+	ref, ok := (&HorizontalPodAutoscaler{}).getScaleTargetRef(service, targetCluster)
+	if !ok {
+		return nil, false
+	}
+	return map[string]interface{}{"apiVersion": ref.APIVersion, "kind": ref.Kind, "name": ref.Name}, true
+}
+
+// createServiceBinding creates the binding which projects the secret of the backing service into the workload
+func (s *ServiceBinding) createServiceBinding(service irtypes.Service, binding irtypes.ServiceBinding, workload map[string]interface{}) *unstructured.Unstructured {
+	spec := map[string]interface{}{
+		"name":     binding.Name,
+		"service":  map[string]interface{}{"apiVersion": corev1.SchemeGroupVersion.String(), "kind": string(irtypes.SecretKind), "name": binding.SecretName},
+		"workload": workload,
+	}
+	if binding.Type != "" {
+		spec["type"] = binding.Type
+	}
+	if binding.Provider != "" {
+		spec["provider"] = binding.Provider
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": serviceBindingAPIVersion,
+		"kind":       serviceBindingKind,
+		"metadata": map[string]interface{}{
+			"name":   common.MakeStringK8sServiceNameCompliant(service.Name + "-" + binding.Name),
+			"labels": map[string]interface{}{selector: service.Name},
+		},
+		"spec": spec,
+	}}
+}
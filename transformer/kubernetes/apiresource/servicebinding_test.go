@@ -0,0 +1,84 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestCreateServiceBindings(t *testing.T) {
+	setupQAConfig(t)
+	ir := newRolloutTestIR(t)
+	api := ir.Services["api"]
+	api.ServiceBindings = []irtypes.ServiceBinding{
+		{Name: "orders-db", SecretName: "api-orders-db-binding", Type: "postgresql", Provider: "elephantsql"},
+		{Name: "cache", SecretName: "api-cache-binding"},
+	}
+	ir.Services["api"] = api
+	agent := irtypes.NewServiceWithName("agent")
+	agent.Containers = []core.Container{{Name: "agent", Image: "agent:latest"}}
+	agent.Daemon = true
+	agent.ServiceBindings = []irtypes.ServiceBinding{{Name: "logs", SecretName: "agent-logs-binding", Type: "syslog"}}
+	ir.Services["agent"] = agent
+	job := irtypes.NewServiceWithName("job")
+	job.Containers = []core.Container{{Name: "job", Image: "job:latest"}}
+	job.RestartPolicy = core.RestartPolicyOnFailure
+	job.ServiceBindings = []irtypes.ServiceBinding{{Name: "queue", SecretName: "job-queue-binding", Type: "rabbitmq"}}
+	ir.Services["job"] = job
+
+	s := &ServiceBinding{}
+	objs := s.createNewResources(ir, s.getSupportedKinds(), newRolloutTestCluster())
+	got := map[string]map[string]interface{}{}
+	for _, obj := range objs {
+		binding := obj.(*unstructured.Unstructured)
+		if binding.GetAPIVersion() != serviceBindingAPIVersion || binding.GetKind() != serviceBindingKind {
+			t.Fatalf("expected a %s of %s , got %s of %s", serviceBindingKind, serviceBindingAPIVersion, binding.GetKind(), binding.GetAPIVersion())
+		}
+		if _, ok := s.convertToClusterSupportedKinds(obj, nil, nil, ir, newRolloutTestCluster()); !ok {
+			t.Fatalf("expected the ServiceBinding %s to be kept for any cluster", binding.GetName())
+		}
+		got[binding.GetName()] = binding.Object["spec"].(map[string]interface{})
+	}
+	want := map[string]map[string]interface{}{
+		"agent-logs": {
+			"name":     "logs",
+			"type":     "syslog",
+			"service":  map[string]interface{}{"apiVersion": "v1", "kind": "Secret", "name": "agent-logs-binding"},
+			"workload": map[string]interface{}{"apiVersion": "apps/v1", "kind": daemonSetKind, "name": "agent"},
+		},
+		"api-orders-db": {
+			"name":     "orders-db",
+			"type":     "postgresql",
+			"provider": "elephantsql",
+			"service":  map[string]interface{}{"apiVersion": "v1", "kind": "Secret", "name": "api-orders-db-binding"},
+			"workload": map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "api"},
+		},
+		"api-cache": {
+			"name":     "cache",
+			"service":  map[string]interface{}{"apiVersion": "v1", "kind": "Secret", "name": "api-cache-binding"},
+			"workload": map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "api"},
+		},
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("expected the bindings of only the long running workloads. Diff (-want +got):\n%s", diff)
+	}
+}
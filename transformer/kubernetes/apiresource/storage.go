@@ -45,13 +45,44 @@ func (s *Storage) createNewResources(ir irtypes.EnhancedIR, supportedKinds []str
 		if stObj.StorageType == irtypes.SecretKind || stObj.StorageType == irtypes.PullSecretKind {
 			objs = append(objs, s.createSecret(stObj))
 		}
-		if stObj.StorageType == irtypes.PVCKind {
+		if stObj.StorageType == irtypes.PVCKind && (isSharedClaim(stObj.Name, ir) || !isClaimedByStatefulSetsOnly(stObj.Name, ir, targetCluster)) {
 			objs = append(objs, s.createPVC(stObj))
 		}
 	}
 	return objs
 }
 
+// isSharedClaim returns true if the claim is mounted by the pods of several services, in which case the StatefulSets mount it instead of getting a claim per pod
+func isSharedClaim(claimName string, ir irtypes.EnhancedIR) bool {
+	claimants := 0
+	for _, service := range ir.Services {
+		for _, volume := range service.Volumes {
+			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == claimName {
+				claimants++
+				break
+			}
+		}
+	}
+	return claimants > 1
+}
+
+// isClaimedByStatefulSetsOnly returns true if the claim is used only by StatefulSets, which create the claims from their templates
+func isClaimedByStatefulSetsOnly(claimName string, ir irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) bool {
+	claimed := false
+	for _, service := range ir.Services {
+		for _, volume := range service.Volumes {
+			if volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ClaimName != claimName {
+				continue
+			}
+			if !isStatefulSetService(service, targetCluster) {
+				return false
+			}
+			claimed = true
+		}
+	}
+	return claimed
+}
+
 // convertToClusterSupportedKinds converts kinds to cluster supported kinds
 func (s *Storage) convertToClusterSupportedKinds(obj runtime.Object, supportedKinds []string, otherobjs []runtime.Object, _ irtypes.EnhancedIR, targetCluster collecttypes.ClusterMetadata) ([]runtime.Object, bool) {
 	if common.IsPresent(s.getSupportedKinds(), obj.GetObjectKind().GroupVersionKind().Kind) {
@@ -68,21 +68,35 @@ func (*TriggerTemplate) createNewResource(tt irtypes.TriggerTemplate, ir irtypes
 	}
 
 	pipelineRun.ObjectMeta = metav1.ObjectMeta{Name: tt.PipelineRunName}
+	workspace := v1beta1.WorkspaceBinding{Name: tt.WorkspaceName}
+	if tt.CacheClaimName != "" {
+		workspace.PersistentVolumeClaim = &corev1.PersistentVolumeClaimVolumeSource{ClaimName: tt.CacheClaimName}
+	} else {
+		workspace.VolumeClaimTemplate = &corev1.PersistentVolumeClaim{
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: &tt.StorageClassName,
+				AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+				Resources:        corev1.ResourceRequirements{Requests: corev1.ResourceList{"storage": resource.MustParse("1Gi")}},
+			},
+		}
+	}
+	workspaces := []v1beta1.WorkspaceBinding{workspace}
+	if tt.ArtifactBucketSecretName != "" {
+		workspaces = append(workspaces, v1beta1.WorkspaceBinding{
+			Name:   tt.ArtifactBucketWorkspaceName,
+			Secret: &corev1.SecretVolumeSource{SecretName: tt.ArtifactBucketSecretName},
+		})
+	}
+	if tt.SigningSecretName != "" {
+		workspaces = append(workspaces, v1beta1.WorkspaceBinding{
+			Name:   tt.SigningWorkspaceName,
+			Secret: &corev1.SecretVolumeSource{SecretName: tt.SigningSecretName},
+		})
+	}
 	pipelineRun.Spec = v1beta1.PipelineRunSpec{
 		PipelineRef:        &v1beta1.PipelineRef{Name: tt.PipelineName},
 		ServiceAccountName: tt.ServiceAccountName,
-		Workspaces: []v1beta1.WorkspaceBinding{
-			{
-				Name: tt.WorkspaceName,
-				VolumeClaimTemplate: &corev1.PersistentVolumeClaim{
-					Spec: corev1.PersistentVolumeClaimSpec{
-						StorageClassName: &tt.StorageClassName,
-						AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
-						Resources:        corev1.ResourceRequirements{Requests: corev1.ResourceList{"storage": resource.MustParse("1Gi")}},
-					},
-				},
-			},
-		},
+		Workspaces:         workspaces,
 		Params: []v1beta1.Param{
 			{Name: "image-registry-url", Value: v1beta1.ArrayOrString{Type: "string", StringVal: registryURL + "/" + registryNamespace}},
 		},
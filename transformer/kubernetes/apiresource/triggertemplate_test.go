@@ -0,0 +1,70 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+func TestTriggerTemplateWorkspaces(t *testing.T) {
+	setupQAConfig(t, common.ConfigImageRegistryURLKey+`="quay.io"`, common.ConfigImageRegistryNamespaceKey+`="myteam"`)
+	ir := irtypes.NewEnhancedIRFromIR(irtypes.NewIR())
+	getWorkspaces := func(t *testing.T, tt irtypes.TriggerTemplate) []v1beta1.WorkspaceBinding {
+		t.Helper()
+		triggerTemplate := (&TriggerTemplate{}).createNewResource(tt, ir)
+		pipelineRun, ok := triggerTemplate.Spec.ResourceTemplates[0].RawExtension.Object.(*v1beta1.PipelineRun)
+		if !ok {
+			t.Fatalf("expected the trigger template to create a pipeline run. Actual: %+v", triggerTemplate.Spec.ResourceTemplates)
+		}
+		return pipelineRun.Spec.Workspaces
+	}
+
+	t.Run("a new claim for each run", func(t *testing.T) {
+		workspaces := getWorkspaces(t, irtypes.TriggerTemplate{Name: "myproject", WorkspaceName: "shared-data"})
+		if len(workspaces) != 1 || workspaces[0].VolumeClaimTemplate == nil || workspaces[0].PersistentVolumeClaim != nil {
+			t.Fatalf("expected the workspace to be a new claim for each run. Actual: %+v", workspaces)
+		}
+	})
+
+	t.Run("the cache claim shared by the runs, the bucket credentials and the cosign key", func(t *testing.T) {
+		workspaces := getWorkspaces(t, irtypes.TriggerTemplate{
+			Name:                        "myproject",
+			WorkspaceName:               "shared-data",
+			CacheClaimName:              "myproject-build-cache",
+			ArtifactBucketWorkspaceName: "myproject-artifact-bucket",
+			ArtifactBucketSecretName:    "myproject-artifact-bucket",
+			SigningWorkspaceName:        "myproject-cosign",
+			SigningSecretName:           "myproject-cosign",
+		})
+		if len(workspaces) != 3 {
+			t.Fatalf("expected the workspace, the bucket credentials and the cosign key. Actual: %+v", workspaces)
+		}
+		if workspaces[0].PersistentVolumeClaim == nil || workspaces[0].PersistentVolumeClaim.ClaimName != "myproject-build-cache" || workspaces[0].VolumeClaimTemplate != nil {
+			t.Fatalf("expected the workspace to be bound to the cache claim. Actual: %+v", workspaces[0])
+		}
+		if workspaces[1].Name != "myproject-artifact-bucket" || workspaces[1].Secret == nil || workspaces[1].Secret.SecretName != "myproject-artifact-bucket" {
+			t.Fatalf("expected the bucket credentials to be bound from the secret. Actual: %+v", workspaces[1])
+		}
+		if workspaces[2].Name != "myproject-cosign" || workspaces[2].Secret == nil || workspaces[2].Secret.SecretName != "myproject-cosign" {
+			t.Fatalf("expected the cosign key to be bound from the secret. Actual: %+v", workspaces[2])
+		}
+	})
+}
@@ -17,6 +17,8 @@
 package apiresource
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -26,15 +28,19 @@ import (
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
 	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema/fixer"
+	"github.com/konveyor/move2kube/types"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // TransformIRAndPersist transforms IR to yamls and writes to filesystem
 func TransformIRAndPersist(
+	transformerName string,
 	ir irtypes.EnhancedIR,
 	outputPath string,
 	apiResources []IAPIResource,
@@ -43,11 +49,22 @@ func TransformIRAndPersist(
 ) (files []string, err error) {
 	logrus.Trace("TransformIRAndPersist start")
 	defer logrus.Trace("TransformIRAndPersist end")
+	targetCluster = gateClusterFeatures(targetCluster)
+	ir, err = runPreTransformHooks(ir, targetCluster)
+	if err != nil {
+		return nil, err
+	}
 	targetObjs := []runtime.Object{}
 	for _, apiResource := range apiResources {
 		newObjs := (&APIResource{IAPIResource: apiResource}).convertIRToObjects(ir, targetCluster)
 		targetObjs = append(targetObjs, newObjs...)
 	}
+	targetObjs, err = runPostTransformHooks(ir, targetObjs, targetCluster)
+	if err != nil {
+		return nil, err
+	}
+	targetObjs = dropGatedObjects(targetObjs, targetCluster)
+	targetObjs = reviewObjects(transformerName, targetObjs)
 	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
 		return nil, fmt.Errorf("failed to create the deploy directory at path '%s' . Error: %w", outputPath, err)
 	}
@@ -63,10 +80,51 @@ func TransformIRAndPersist(
 	return filesWritten, nil
 }
 
+// ConversionStatus stores the result of converting an object to the target cluster
+type ConversionStatus string
+
+const (
+	// ConversionStatusUnchanged means the object was written with the same kind and version
+	ConversionStatusUnchanged ConversionStatus = "Unchanged"
+	// ConversionStatusConverted means the object was converted to a different kind or version
+	ConversionStatusConverted ConversionStatus = "Converted"
+	// ConversionStatusAdded means the object was generated during the conversion
+	ConversionStatusAdded ConversionStatus = "Added"
+	// ConversionStatusDropped means the object could not be written for the target cluster
+	ConversionStatusDropped ConversionStatus = "Dropped"
+	// ConversionStatusUnsupported means the object was written as is, in a kind or version which the target cluster does not support
+	ConversionStatusUnsupported ConversionStatus = "Unsupported"
+)
+
+// ConversionReportEntry stores the details of the conversion of a single object
+type ConversionReportEntry struct {
+	Name               string           `yaml:"name"`
+	OriginalKind       string           `yaml:"originalKind,omitempty"`
+	OriginalAPIVersion string           `yaml:"originalAPIVersion,omitempty"`
+	Kind               string           `yaml:"kind,omitempty"`
+	APIVersion         string           `yaml:"apiVersion,omitempty"`
+	File               string           `yaml:"file,omitempty"`
+	Status             ConversionStatus `yaml:"status"`
+	Reason             string           `yaml:"reason,omitempty"`
+}
+
 // TransformObjsAndPersist transforms versions of yamls in current directory and writes to filesystem
 func TransformObjsAndPersist(inputPath, outputPath string, apis []IAPIResource, targetCluster collecttypes.ClusterMetadata, setDefaultValuesInYamls bool) (files []string, err error) {
+	files, _, err = TransformObjsAndPersistWithReport(inputPath, outputPath, apis, targetCluster, setDefaultValuesInYamls)
+	return files, err
+}
+
+// TransformObjsAndPersistWithReport transforms versions of yamls in current directory, writes to filesystem
+// and returns a report of how each object was converted
+func TransformObjsAndPersistWithReport(inputPath, outputPath string, apis []IAPIResource, targetCluster collecttypes.ClusterMetadata, setDefaultValuesInYamls bool) (files []string, report []ConversionReportEntry, err error) {
+	targetCluster = gateClusterFeatures(targetCluster)
 	targetObjs := []runtime.Object{}
-	if pendingObjs := k8sschema.GetKubernetesObjsInDir(inputPath); len(pendingObjs) != 0 {
+	inputObjs := k8sschema.GetKubernetesObjsAndCustomResourcesInDir(inputPath)
+	originalInfos := []ConversionReportEntry{}
+	for _, obj := range inputObjs {
+		originalInfos = append(originalInfos, getConversionInfo(obj))
+	}
+	if pendingObjs := inputObjs; len(pendingObjs) != 0 {
 		for _, apiResource := range apis {
 			var newObjs []runtime.Object
 			newObjs, pendingObjs = (&APIResource{IAPIResource: apiResource}).convertObjectsToSupportedVersion(pendingObjs, targetCluster)
@@ -74,58 +132,203 @@ func TransformObjsAndPersist(inputPath, outputPath string, apis []IAPIResource,
 		}
 		targetObjs = append(targetObjs, pendingObjs...)
 	}
+	targetObjs = dropGatedObjects(targetObjs, targetCluster)
+	// the input objects are not needed anymore, once they are converted into the target objects
+	inputObjs = nil
 	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
 		logrus.Errorf("failed to create deploy directory at path '%s' . Error: %q", outputPath, err)
 	}
 	logrus.Debugf("Total %d services to be serialized.", len(targetObjs))
 	convertedObjs, err := convertVersion(targetObjs, targetCluster.Spec, setDefaultValuesInYamls)
 	if err != nil {
-		logrus.Errorf("Failed to fix, convert and transform the objects. Error: %q", err)
+		return nil, nil, fmt.Errorf("failed to fix, convert and transform the objects. Error: %w", err)
 	}
 	filesWritten, err := writeObjects(outputPath, convertedObjs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to write the transformed objects to the directory at path '%s' . Error: %w", outputPath, err)
+		return nil, nil, fmt.Errorf("failed to write the transformed objects to the directory at path '%s' . Error: %w", outputPath, err)
 	}
-	return filesWritten, nil
+	return filesWritten, getConversionReport(originalInfos, convertedObjs, filesWritten, outputPath), nil
 }
 
-// writeObjects writes the runtime objects to yaml files
+// getConversionReport matches the converted objects with the original objects using their names
+func getConversionReport(originalInfos []ConversionReportEntry, convertedObjs []runtime.Object, filesWritten []string, outputPath string) []ConversionReportEntry {
+	report := []ConversionReportEntry{}
+	used := make([]bool, len(originalInfos))
+	findOriginal := func(info ConversionReportEntry) int {
+		for i, orig := range originalInfos {
+			if !used[i] && orig.Name == info.Name && orig.OriginalKind == info.OriginalKind {
+				return i
+			}
+		}
+		for i, orig := range originalInfos {
+			if !used[i] && orig.Name == info.Name {
+				return i
+			}
+		}
+		return -1
+	}
+	for _, obj := range convertedObjs {
+		info := getConversionInfo(obj)
+		entry := ConversionReportEntry{Name: info.Name, Kind: info.OriginalKind, APIVersion: info.OriginalAPIVersion, Status: ConversionStatusAdded}
+		yamlPath := filepath.Join(outputPath, getFilename(obj))
+		if !common.IsPresent(filesWritten, yamlPath) {
+			entry.Status = ConversionStatusDropped
+			entry.Reason = fmt.Sprintf("the object could not be written to the file %s", getFilename(obj))
+		} else {
+			entry.File = getFilename(obj)
+		}
+		if i := findOriginal(info); i != -1 {
+			used[i] = true
+			entry.OriginalKind = originalInfos[i].OriginalKind
+			entry.OriginalAPIVersion = originalInfos[i].OriginalAPIVersion
+			if entry.Status != ConversionStatusDropped {
+				entry.Status = ConversionStatusUnchanged
+				if entry.OriginalKind != entry.Kind || entry.OriginalAPIVersion != entry.APIVersion {
+					entry.Status = ConversionStatusConverted
+				}
+			}
+		}
+		report = append(report, entry)
+	}
+	for i, orig := range originalInfos {
+		if !used[i] {
+			orig.Status = ConversionStatusDropped
+			orig.Reason = "the object was removed during the conversion to the kinds supported by the target cluster"
+			report = append(report, orig)
+		}
+	}
+	return report
+}
+
+// getConversionInfo returns the name, kind and api version of the object
+func getConversionInfo(obj runtime.Object) ConversionReportEntry {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	info := ConversionReportEntry{OriginalKind: gvk.Kind, OriginalAPIVersion: gvk.GroupVersion().String()}
+	if metaObj, err := meta.Accessor(obj); err == nil {
+		info.Name = metaObj.GetName()
+	}
+	return info
+}
+
+// writeObjects writes the runtime objects to yaml files, using a bounded worker pool
 func writeObjects(outputPath string, objs []runtime.Object) ([]string, error) {
 	if err := os.MkdirAll(outputPath, common.DefaultDirectoryPermission); err != nil {
 		return nil, fmt.Errorf("failed to create the output directory at path '%s' . Error: %w", outputPath, err)
 	}
-	filesWritten := []string{}
-	for _, obj := range objs {
-		objYamlBytes, err := common.MarshalObjToYaml(obj)
-		if err != nil {
-			logrus.Errorf("failed to marshal the runtime. Object to yaml. Object: %+v Error: %q", obj, err)
+	// The last object with a file name is written, like when the files were written one after the other
+	lastObjWithFilename := map[string]int{}
+	for i, obj := range objs {
+		lastObjWithFilename[getFilename(obj)] = i
+	}
+	written := make([]string, len(objs))
+	pool := common.NewWorkerPool(common.FileWriteWorkers)
+	for i, obj := range objs {
+		if lastObjWithFilename[getFilename(obj)] != i {
+			recordIgnoredObject(obj, ConversionStatusDropped, fmt.Sprintf("another object is written to the same file %s", getFilename(obj)))
 			continue
 		}
-		yamlPath := filepath.Join(outputPath, getFilename(obj))
-		if err := os.WriteFile(yamlPath, objYamlBytes, common.DefaultFilePermission); err != nil {
-			logrus.Errorf("failed to write the yaml to file at path '%s' . Error: %q", yamlPath, err)
-			continue
+		i, obj := i, obj
+		pool.Submit(func() {
+			setOwnershipAnnotation(obj)
+			yamlPath := filepath.Join(outputPath, getFilename(obj))
+			if err := writeObject(yamlPath, obj); err != nil {
+				logrus.Errorf("failed to write the yaml to file at path '%s' . Error: %q", yamlPath, err)
+				recordIgnoredObject(obj, ConversionStatusDropped, fmt.Sprintf("failed to write the file %s: %s", getFilename(obj), err))
+				return
+			}
+			written[i] = yamlPath
+		})
+	}
+	pool.Wait()
+	filesWritten := []string{}
+	for _, yamlPath := range written {
+		if yamlPath != "" {
+			filesWritten = append(filesWritten, yamlPath)
 		}
-		filesWritten = append(filesWritten, yamlPath)
 	}
 	return filesWritten, nil
 }
 
+// writeObject streams the yaml of the object into the file, without keeping the whole yaml in memory.
+// A partially written file is removed, so that it is not mistaken for a valid resource.
+func writeObject(yamlPath string, obj runtime.Object) error {
+	f, err := os.OpenFile(yamlPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, common.DefaultFilePermission)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	err = common.WriteObjToYaml(w, obj)
+	if err == nil {
+		err = w.Flush()
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		if removeErr := os.Remove(yamlPath); removeErr != nil {
+			logrus.Debugf("failed to remove the partially written file at path '%s' . Error: %q", yamlPath, removeErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// setOwnershipAnnotation marks the object as generated, so that it is modified when transforming into an existing output directory
+func setOwnershipAnnotation(obj runtime.Object) {
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		logrus.Debugf("failed to get the metadata of the object %+v to annotate it. Error: %q", obj, err)
+		return
+	}
+	annotations := objMeta.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[common.OwnershipAnnotation] = types.AppName
+	objMeta.SetAnnotations(annotations)
+}
+
+// convertVersion converts the objects in place, so that the original objects can be freed as soon as they are converted
 func convertVersion(objs []runtime.Object, clusterSpec collecttypes.ClusterMetadataSpec, setDefaultValuesInYamls bool) ([]runtime.Object, error) {
-	newobjs := []runtime.Object{}
-	for _, obj := range objs {
+	for i, obj := range objs {
 		fixedobj := fixer.Fix(obj)
 		newobj, err := k8sschema.ConvertToSupportedVersion(fixedobj, clusterSpec, setDefaultValuesInYamls)
+		invalidErr := &k8sschema.InvalidCustomResourceError{}
+		if errors.As(err, &invalidErr) {
+			return nil, err
+		}
 		if err != nil {
 			logrus.Errorf("failed to convert to supported version. Writing as is. Error: %q", err)
 			newobj = obj
 		}
-		newobjs = append(newobjs, newobj)
+		if reason := getUnsupportedReason(newobj, clusterSpec); reason != "" {
+			recordIgnoredObject(newobj, ConversionStatusUnsupported, reason)
+		}
+		objs[i] = newobj
 	}
-	return newobjs, nil
+	return objs, nil
+}
+
+// getUnsupportedReason returns why the target cluster does not support the kind or the version of the object, if it does not
+func getUnsupportedReason(obj runtime.Object, clusterSpec collecttypes.ClusterMetadataSpec) string {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	versions := clusterSpec.GetSupportedVersions(gvk.Kind)
+	if len(versions) == 0 {
+		return fmt.Sprintf("the kind %s is not supported by the target cluster. It was written as is", gvk.Kind)
+	}
+	if !common.IsPresent(versions, gvk.GroupVersion().String()) {
+		if objMeta, err := meta.Accessor(obj); err == nil && objMeta.GetAnnotations()[common.SkipConversionAnnotation] != "" {
+			return fmt.Sprintf("the object was written as %s because of the annotation %s, the target cluster only supports the versions %s", gvk.GroupVersion(), common.SkipConversionAnnotation, strings.Join(versions, ", "))
+		}
+		return fmt.Sprintf("the object could not be converted to any of the versions %s supported by the target cluster. It was written as %s", strings.Join(versions, ", "), gvk.GroupVersion())
+	}
+	return ""
 }
 
 func getFilename(obj runtime.Object) string {
+	if cr, ok := obj.(*unstructured.Unstructured); ok {
+		return fmt.Sprintf("%s-%s.yaml", cr.GetName(), strings.ToLower(cr.GetKind()))
+	}
 	val := reflect.ValueOf(obj).Elem()
 	typeMeta := val.FieldByName("TypeMeta").Interface().(metav1.TypeMeta)
 	objectMeta := val.FieldByName("ObjectMeta").Interface().(metav1.ObjectMeta)
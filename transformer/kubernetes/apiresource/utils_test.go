@@ -0,0 +1,166 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package apiresource
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func TestGetConversionReport(t *testing.T) {
+	outputPath := "/output"
+	originalInfos := []ConversionReportEntry{
+		{Name: "svc1", OriginalKind: "Service", OriginalAPIVersion: "v1"},
+		{Name: "svc2", OriginalKind: "Service", OriginalAPIVersion: "v1beta1"},
+		{Name: "svc3", OriginalKind: "Service", OriginalAPIVersion: "v1"},
+	}
+	svc1 := createService("svc1", []v1.ServicePort{})
+	svc2 := createService("svc2", []v1.ServicePort{})
+	svc4 := createService("svc4", []v1.ServicePort{})
+	filesWritten := []string{
+		filepath.Join(outputPath, getFilename(svc1)),
+		filepath.Join(outputPath, getFilename(svc2)),
+		filepath.Join(outputPath, getFilename(svc4)),
+	}
+	want := []ConversionReportEntry{
+		{Name: "svc1", OriginalKind: "Service", OriginalAPIVersion: "v1", Kind: "Service", APIVersion: "v1", File: "svc1-service.yaml", Status: ConversionStatusUnchanged},
+		{Name: "svc2", OriginalKind: "Service", OriginalAPIVersion: "v1beta1", Kind: "Service", APIVersion: "v1", File: "svc2-service.yaml", Status: ConversionStatusConverted},
+		{Name: "svc4", Kind: "Service", APIVersion: "v1", File: "svc4-service.yaml", Status: ConversionStatusAdded},
+		{Name: "svc3", OriginalKind: "Service", OriginalAPIVersion: "v1", Status: ConversionStatusDropped, Reason: "the object was removed during the conversion to the kinds supported by the target cluster"},
+	}
+	got := getConversionReport(originalInfos, []runtime.Object{svc1, svc2, svc4}, filesWritten, outputPath)
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("The conversion report is incorrect. Difference:\n%s", diff)
+	}
+}
+
+func TestGetUnsupportedReasonOfTheSkippedConversion(t *testing.T) {
+	clusterSpec := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{"CronJob": {"batch/v1"}}}
+	cronJob := &batchv1beta1.CronJob{TypeMeta: metav1.TypeMeta{Kind: "CronJob", APIVersion: "batch/v1beta1"}, ObjectMeta: metav1.ObjectMeta{Name: "backup"}}
+	if reason := getUnsupportedReason(cronJob, clusterSpec); !strings.Contains(reason, "could not be converted") {
+		t.Fatalf("expected the conversion to be reported as failed. Actual: %s", reason)
+	}
+	cronJob.Annotations = map[string]string{common.SkipConversionAnnotation: "true"}
+	if reason := getUnsupportedReason(cronJob, clusterSpec); !strings.Contains(reason, "because of the annotation "+common.SkipConversionAnnotation) {
+		t.Fatalf("expected the annotation to be reported as the reason. Actual: %s", reason)
+	}
+	cronJob.APIVersion = "batch/v1"
+	if reason := getUnsupportedReason(cronJob, clusterSpec); reason != "" {
+		t.Fatalf("expected no reason for the supported version. Actual: %s", reason)
+	}
+}
+
+func TestWriteObjectsWritesTheLastObjectWithAFileName(t *testing.T) {
+	outputPath := filepath.Join(t.TempDir(), "yamls")
+	first := createService("api", []v1.ServicePort{{Name: "first", Port: 80}})
+	last := createService("api", []v1.ServicePort{{Name: "last", Port: 8080}})
+	objs := []runtime.Object{createService("web", []v1.ServicePort{}), first, createService("db", []v1.ServicePort{}), last}
+	filesWritten, err := writeObjects(outputPath, objs)
+	if err != nil {
+		t.Fatalf("failed to write the objects. Error: %q", err)
+	}
+	want := []string{
+		filepath.Join(outputPath, "web-service.yaml"),
+		filepath.Join(outputPath, "db-service.yaml"),
+		filepath.Join(outputPath, "api-service.yaml"),
+	}
+	if diff := cmp.Diff(want, filesWritten); diff != "" {
+		t.Fatalf("expected the files in the order of the objects. Difference:\n%s", diff)
+	}
+	service := v1.Service{}
+	if err := common.ReadYaml(filepath.Join(outputPath, "api-service.yaml"), &service); err != nil {
+		t.Fatalf("failed to read the written service. Error: %q", err)
+	}
+	if len(service.Spec.Ports) != 1 || service.Spec.Ports[0].Name != "last" {
+		t.Fatalf("expected the last object with the file name to be written, got the ports %+v", service.Spec.Ports)
+	}
+	// The object metadata is inlined, so it is read as a map
+	serviceMap := map[string]interface{}{}
+	if err := common.ReadYaml(filepath.Join(outputPath, "api-service.yaml"), &serviceMap); err != nil {
+		t.Fatalf("failed to read the written service. Error: %q", err)
+	}
+	annotations, _, err := unstructured.NestedStringMap(serviceMap, "metadata", "annotations")
+	if err != nil {
+		t.Fatalf("failed to get the annotations of the written service. Error: %q", err)
+	}
+	if annotations[common.OwnershipAnnotation] != types.AppName {
+		t.Fatalf("expected the written object to be annotated as generated, got the annotations %+v", annotations)
+	}
+}
+
+// unencodableObject is an object which fails to be marshalled
+type unencodableObject struct {
+	metav1.TypeMeta
+}
+
+func (o *unencodableObject) DeepCopyObject() runtime.Object {
+	return &unencodableObject{TypeMeta: o.TypeMeta}
+}
+
+func (o *unencodableObject) MarshalJSON() ([]byte, error) {
+	return nil, errors.New("the object cannot be marshalled")
+}
+
+func TestWriteObjectRemovesThePartiallyWrittenFile(t *testing.T) {
+	yamlPath := filepath.Join(t.TempDir(), "web-service.yaml")
+	if err := writeObject(yamlPath, createService("web", []v1.ServicePort{{Name: "http", Port: 80}})); err != nil {
+		t.Fatalf("failed to write the object. Error: %q", err)
+	}
+	service := v1.Service{}
+	if err := common.ReadYaml(yamlPath, &service); err != nil || len(service.Spec.Ports) != 1 {
+		t.Fatalf("expected the service to be written. Ports: %+v Error: %v", service.Spec.Ports, err)
+	}
+	// the object fails to be converted after the file is created
+	obj := &unencodableObject{TypeMeta: metav1.TypeMeta{Kind: "Service", APIVersion: "v1"}}
+	if err := writeObject(yamlPath, obj); err == nil {
+		t.Fatalf("expected an error for the object which cannot be encoded")
+	}
+	if _, err := os.Stat(yamlPath); !os.IsNotExist(err) {
+		t.Fatalf("expected the partially written file to be removed. Error: %v", err)
+	}
+}
+
+func TestConvertVersionConvertsTheObjectsInPlace(t *testing.T) {
+	clusterMetadata := collecttypes.NewClusterMetadata("")
+	clusterMetadata.Spec.APIKindVersionMap = map[string][]string{"Service": {"v1"}}
+	objs := []runtime.Object{createService("web", nil), createService("db", nil)}
+	converted, err := convertVersion(objs, clusterMetadata.Spec, false)
+	if err != nil {
+		t.Fatalf("failed to convert the objects. Error: %q", err)
+	}
+	if len(converted) != len(objs) {
+		t.Fatalf("got %d converted objects, want %d", len(converted), len(objs))
+	}
+	for i := range objs {
+		if objs[i] != converted[i] {
+			t.Fatalf("expected the object %d to be replaced by the converted object in the same slice", i)
+		}
+	}
+}
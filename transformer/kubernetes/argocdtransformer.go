@@ -113,7 +113,7 @@ func (t *ArgoCD) Transform(newArtifacts []transformertypes.Artifact, alreadySeen
 		tempDest := filepath.Join(t.Env.TempPath, deployCICDDir)
 		logrus.Debugf("Generating ArgoCD yamls for CI/CD")
 		enhancedIR := t.setupEnhancedIR(ir, t.Env.GetProjectName())
-		files, err := apiresource.TransformIRAndPersist(enhancedIR, tempDest, resources, clusterConfig, t.ArgoCDConfig.SetDefaultValuesInYamls)
+		files, err := apiresource.TransformIRAndPersist(t.Config.Name, enhancedIR, tempDest, resources, clusterConfig, t.ArgoCDConfig.SetDefaultValuesInYamls)
 		if err != nil {
 			logrus.Errorf("failed to transform and persist IR. Error: %q", err)
 			continue
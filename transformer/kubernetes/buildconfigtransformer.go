@@ -126,6 +126,7 @@ func (t *BuildConfig) Transform(newArtifacts []transformertypes.Artifact, alread
 		logrus.Infof("Generating Buildconfig pipeline for CI/CD")
 		enhancedIR := t.setupEnhancedIR(ir, t.Env.GetProjectName())
 		filePaths, err := apiresource.TransformIRAndPersist(
+			t.Config.Name,
 			enhancedIR,
 			tempDest,
 			apiResources,
@@ -212,6 +213,7 @@ func (t *BuildConfig) setupEnhancedIR(oldir irtypes.IR, planName string) irtypes
 				ImageStreamTag:    imageStreamTag,
 				SourceSecretName:  gitSecretName,
 				WebhookSecretName: webhookSecretName,
+				ContainerBuild:    irtypes.ContainerBuild{BuildArgs: irContainer.Build.BuildArgs, Target: irContainer.Build.Target},
 			})
 
 			webHookURL := t.getWebHookURL(buildConfigName, string(webhookSecret.Content["WebHookSecretKey"]), "generic")
@@ -242,6 +244,7 @@ func (t *BuildConfig) setupEnhancedIR(oldir irtypes.IR, planName string) irtypes
 				ImageStreamTag:    imageStreamTag,
 				SourceSecretName:  gitSecretName,
 				WebhookSecretName: webhookSecretName,
+				ContainerBuild:    irtypes.ContainerBuild{BuildArgs: irContainer.Build.BuildArgs, Target: irContainer.Build.Target},
 			})
 
 			webHookURL := t.getWebHookURL(buildConfigName, string(webhookSecret.Content["WebHookSecretKey"]), t.getWebHookType(gitHostName))
@@ -0,0 +1,174 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
+	"github.com/konveyor/move2kube/transformer/kubernetes/irpreprocessor"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultCarvelOutputPath = common.DeployDir + string(os.PathSeparator) + "carvel"
+	// carvelBundleConfigDir is the directory of the manifests inside the imgpkg bundle
+	carvelBundleConfigDir = "bundle" + string(os.PathSeparator) + "config"
+)
+
+// Carvel implements Transformer interface
+type Carvel struct {
+	Config       transformertypes.Transformer
+	Env          *environment.Environment
+	CarvelConfig *CarvelYamlConfig
+}
+
+// CarvelYamlConfig stores the transformer specific configuration
+type CarvelYamlConfig struct {
+	OutputPath              string `yaml:"outputPath"`
+	IngressName             string `yaml:"ingressName"`
+	SetDefaultValuesInYamls bool   `yaml:"setDefaultValuesInYamls"`
+}
+
+// CarvelTemplateConfig represents template config used by the Package and App CRs
+type CarvelTemplateConfig struct {
+	AppName            string
+	PackageName        string
+	Version            string
+	BundleImage        string
+	ServiceAccountName string
+}
+
+// Init Initializes the transformer
+func (t *Carvel) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.CarvelConfig = &CarvelYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.CarvelConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.CarvelConfig, err)
+		return err
+	}
+	if t.CarvelConfig.OutputPath == "" {
+		t.CarvelConfig.OutputPath = defaultCarvelOutputPath
+	}
+	if !t.CarvelConfig.SetDefaultValuesInYamls {
+		t.CarvelConfig.SetDefaultValuesInYamls = setDefaultValuesInYamls
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *Carvel) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in each sub directory
+func (t *Carvel) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform generates an imgpkg bundle of the kubernetes yamls, along with the Package and App CRs which deploy it using kapp-controller
+func (t *Carvel) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	pathMappings := []transformertypes.PathMapping{}
+	for _, newArtifact := range newArtifacts {
+		if newArtifact.Type != irtypes.IRArtifactType {
+			continue
+		}
+		var ir irtypes.IR
+		if err := newArtifact.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		var clusterConfig collecttypes.ClusterMetadata
+		if err := newArtifact.GetConfig(ClusterMetadata, &clusterConfig); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", clusterConfig, err)
+			continue
+		}
+		ingressName, err := common.GetStringFromTemplate(t.CarvelConfig.IngressName, map[string]string{
+			common.ProjectNameTemplatizedStringKey:  t.Env.ProjectName,
+			common.ArtifactNameTemplatizedStringKey: newArtifact.Name,
+		})
+		if err != nil || ingressName == "" {
+			logrus.Debugf("Unable to compute the Ingress name. Defaulting to the artifact name. Error: %q", err)
+			ingressName = newArtifact.Name
+		}
+		ir.Name = ingressName
+		preprocessedIR, err := irpreprocessor.Preprocess(ir)
+		if err != nil {
+			logrus.Errorf("Unable to prepreocess IR : %s", err)
+		} else {
+			ir = preprocessedIR
+		}
+		tempDest := filepath.Join(t.Env.TempPath, "carvel-bundle-"+common.GetRandomString())
+		files, err := apiresource.TransformIRAndPersist(t.Config.Name, newEnhancedIRWithServiceAccounts(ir), tempDest, getAPIResources(), clusterConfig, t.CarvelConfig.SetDefaultValuesInYamls)
+		if err != nil {
+			logrus.Errorf("failed to transform and persist IR. Error: %q", err)
+			continue
+		}
+		if len(files) == 0 {
+			continue
+		}
+		logrus.Debugf("Carvel bundle has %d kubernetes yamls", len(files))
+		appName := common.MakeStringK8sServiceNameCompliant(t.Env.ProjectName)
+		tc := CarvelTemplateConfig{
+			AppName: appName,
+			PackageName: qaengine.FetchStringAnswer(
+				common.ConfigCarvelPackageNameKey,
+				"Provide the name of the Carvel package :",
+				[]string{"The name of a package has to be fully qualified, like a domain name which you own"},
+				appName+".example.com",
+				qatypes.NewHostnameValidator(),
+			),
+			Version: qaengine.FetchStringAnswer(
+				common.ConfigCarvelPackageVersionKey,
+				"Provide the version of the Carvel package :",
+				[]string{"The version has to follow semantic versioning, like 1.0.0"},
+				"1.0.0",
+				nil,
+			),
+			ServiceAccountName: qaengine.FetchStringAnswer(
+				common.ConfigCarvelServiceAccountKey,
+				"Provide the service account used by kapp-controller to deploy the application :",
+				[]string{"The service account has to be created in the namespace of the App and PackageInstall, with the permissions to create the resources of the application"},
+				appName+"-installer",
+				qatypes.NewDNSLabelValidator(),
+			),
+		}
+		tc.BundleImage = fmt.Sprintf("%s/%s/%s-bundle", commonqa.ImageRegistry(), commonqa.ImageRegistryNamespace(), appName)
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:           transformertypes.TemplatePathMappingType,
+			SrcPath:        filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir),
+			DestPath:       t.CarvelConfig.OutputPath,
+			TemplateConfig: tc,
+		}, transformertypes.PathMapping{
+			Type:     transformertypes.DefaultPathMappingType,
+			SrcPath:  tempDest,
+			DestPath: filepath.Join(t.CarvelConfig.OutputPath, carvelBundleConfigDir),
+		})
+	}
+	return pathMappings, nil, nil
+}
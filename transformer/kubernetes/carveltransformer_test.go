@@ -0,0 +1,133 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"sigs.k8s.io/yaml"
+)
+
+func TestCarvelTransform(t *testing.T) {
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{
+		common.ConfigImageRegistryURLKey + `="quay.io"`,
+		common.ConfigImageRegistryNamespaceKey + `="myteam"`,
+		common.ConfigCarvelPackageNameKey + `="shop.example.org"`,
+		common.ConfigCarvelPackageVersionKey + `="2.1.0"`,
+	}, nil, nil, false)
+	tc := transformertypes.Transformer{}
+	tc.Spec.TemplatesDir = "templates"
+	env := &environment.Environment{EnvInfo: environment.EnvInfo{
+		ProjectName: "Shop_App",
+		TempPath:    t.TempDir(),
+		Context:     filepath.Join("..", "..", "assets", "built-in", "transformers", "kubernetes", "carvel"),
+	}}
+	transformer := &Carvel{}
+	if err := transformer.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	ir := irtypes.NewIR()
+	api := irtypes.NewServiceWithName("api")
+	api.Containers = []core.Container{{Name: "api", Image: "api:latest"}}
+	ir.Services["api"] = api
+	cluster := collecttypes.NewClusterMetadata("kind")
+	cluster.Spec.APIKindVersionMap = map[string][]string{common.DeploymentKind: {"apps/v1"}}
+	pathMappings, _, err := transformer.Transform([]transformertypes.Artifact{{
+		Name: "shop",
+		Type: irtypes.IRArtifactType,
+		Configs: map[transformertypes.ConfigType]interface{}{
+			irtypes.IRConfigType: ir,
+			ClusterMetadata:      cluster,
+		},
+	}}, nil)
+	if err != nil {
+		t.Fatalf("failed to transform. Error: %q", err)
+	}
+	if len(pathMappings) != 2 {
+		t.Fatalf("expected the templates and the bundle. Actual: %+v", pathMappings)
+	}
+	if pathMappings[0].Type != transformertypes.TemplatePathMappingType || pathMappings[0].DestPath != defaultCarvelOutputPath {
+		t.Fatalf("expected the templates in the carvel directory. Actual: %+v", pathMappings[0])
+	}
+	if pathMappings[1].DestPath != filepath.Join(defaultCarvelOutputPath, "bundle", "config") {
+		t.Fatalf("expected the yamls in the config directory of the bundle. Actual: %+v", pathMappings[1])
+	}
+	entries, err := os.ReadDir(pathMappings[1].SrcPath)
+	if err != nil {
+		t.Fatalf("failed to read the yamls of the bundle. Error: %q", err)
+	}
+	deploymentFound := false
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), "-deployment.yaml") {
+			deploymentFound = true
+		}
+	}
+	if !deploymentFound {
+		t.Fatalf("expected a deployment in the bundle. Actual: %+v", entries)
+	}
+	templateConfig := pathMappings[0].TemplateConfig.(CarvelTemplateConfig)
+	want := CarvelTemplateConfig{
+		AppName:            "shop-app",
+		PackageName:        "shop.example.org",
+		Version:            "2.1.0",
+		BundleImage:        "quay.io/myteam/shop-app-bundle",
+		ServiceAccountName: "shop-app-installer",
+	}
+	if !cmp.Equal(templateConfig, want) {
+		t.Fatalf("the template config is different. Difference:\n%s", cmp.Diff(want, templateConfig))
+	}
+	fetchedImages := map[string]string{
+		"package.yaml": "spec.template.spec.fetch",
+		"app.yaml":     "spec.fetch",
+	}
+	for templateName, fetchPath := range fetchedImages {
+		tpl, err := os.ReadFile(filepath.Join(pathMappings[0].SrcPath, templateName))
+		if err != nil {
+			t.Fatalf("failed to read the template %s . Error: %q", templateName, err)
+		}
+		rendered, err := common.GetStringFromTemplate(string(tpl), templateConfig)
+		if err != nil {
+			t.Fatalf("failed to render the template %s . Error: %q", templateName, err)
+		}
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal([]byte(rendered), &obj); err != nil {
+			t.Fatalf("the rendered %s is not valid yaml. Error: %q\n%s", templateName, err, rendered)
+		}
+		var fetch interface{} = obj
+		for _, key := range strings.Split(fetchPath, ".") {
+			fetch = fetch.(map[string]interface{})[key]
+		}
+		image := fetch.([]interface{})[0].(map[string]interface{})["imgpkgBundle"].(map[string]interface{})["image"]
+		if image != "quay.io/myteam/shop-app-bundle:2.1.0" {
+			t.Fatalf("expected the %s to fetch the bundle of the version, got %v", templateName, image)
+		}
+	}
+}
@@ -0,0 +1,364 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
+	"github.com/konveyor/move2kube/transformer/kubernetes/irpreprocessor"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultCdk8sOutputPath = common.DeployDir + string(os.PathSeparator) + "cdk8s"
+
+	cdk8sTypeScriptLanguage = "typescript"
+	cdk8sGoLanguage         = "go"
+
+	cdk8sGoModTemplate = `module %s
+
+go 1.18
+
+require (
+	github.com/aws/constructs-go/constructs/v10 v10.1.270
+	github.com/aws/jsii-runtime-go v1.80.0
+	github.com/cdk8s-team/cdk8s-core-go/cdk8s/v2 v2.7.57
+)
+`
+)
+
+// Cdk8s implements Transformer interface
+type Cdk8s struct {
+	Config      transformertypes.Transformer
+	Env         *environment.Environment
+	Cdk8sConfig *Cdk8sYamlConfig
+}
+
+// Cdk8sYamlConfig stores the transformer specific configuration
+type Cdk8sYamlConfig struct {
+	OutputPath              string `yaml:"outputPath"`
+	IngressName             string `yaml:"ingressName"`
+	SetDefaultValuesInYamls bool   `yaml:"setDefaultValuesInYamls"`
+}
+
+// Cdk8sTemplateConfig represents template config used by the cdk8s project files
+type Cdk8sTemplateConfig struct {
+	AppName string
+}
+
+// cdk8sAPIObject is a kubernetes resource constructed by the cdk8s chart
+type cdk8sAPIObject struct {
+	id  string
+	obj map[string]interface{}
+}
+
+// Init Initializes the transformer
+func (t *Cdk8s) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.Cdk8sConfig = &Cdk8sYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.Cdk8sConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.Cdk8sConfig, err)
+		return err
+	}
+	if t.Cdk8sConfig.OutputPath == "" {
+		t.Cdk8sConfig.OutputPath = defaultCdk8sOutputPath
+	}
+	if !t.Cdk8sConfig.SetDefaultValuesInYamls {
+		t.Cdk8sConfig.SetDefaultValuesInYamls = setDefaultValuesInYamls
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *Cdk8s) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in each sub directory
+func (t *Cdk8s) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform generates a cdk8s project whose chart constructs the same resources as the kubernetes yamls
+func (t *Cdk8s) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	pathMappings := []transformertypes.PathMapping{}
+	for _, newArtifact := range newArtifacts {
+		if newArtifact.Type != irtypes.IRArtifactType {
+			continue
+		}
+		var ir irtypes.IR
+		if err := newArtifact.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		var clusterConfig collecttypes.ClusterMetadata
+		if err := newArtifact.GetConfig(ClusterMetadata, &clusterConfig); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", clusterConfig, err)
+			continue
+		}
+		ingressName, err := common.GetStringFromTemplate(t.Cdk8sConfig.IngressName, map[string]string{
+			common.ProjectNameTemplatizedStringKey:  t.Env.ProjectName,
+			common.ArtifactNameTemplatizedStringKey: newArtifact.Name,
+		})
+		if err != nil || ingressName == "" {
+			logrus.Debugf("Unable to compute the Ingress name. Defaulting to the artifact name. Error: %q", err)
+			ingressName = newArtifact.Name
+		}
+		ir.Name = ingressName
+		preprocessedIR, err := irpreprocessor.Preprocess(ir)
+		if err != nil {
+			logrus.Errorf("Unable to prepreocess IR : %s", err)
+		} else {
+			ir = preprocessedIR
+		}
+		tempYamls := filepath.Join(t.Env.TempPath, "cdk8s-yamls-"+common.GetRandomString())
+		files, err := apiresource.TransformIRAndPersist(t.Config.Name, newEnhancedIRWithServiceAccounts(ir), tempYamls, getAPIResources(), clusterConfig, t.Cdk8sConfig.SetDefaultValuesInYamls)
+		if err != nil {
+			logrus.Errorf("failed to transform and persist IR. Error: %q", err)
+			continue
+		}
+		objs := getCdk8sAPIObjects(files)
+		if len(objs) == 0 {
+			continue
+		}
+		language := qaengine.FetchSelectAnswer(
+			common.ConfigCdk8sLanguageKey,
+			"Select the language of the cdk8s code :",
+			[]string{"The chart constructs the same resources as the kubernetes yamls"},
+			cdk8sTypeScriptLanguage,
+			[]string{cdk8sTypeScriptLanguage, cdk8sGoLanguage},
+			nil,
+		)
+		appName := common.MakeStringK8sServiceNameCompliant(t.Env.ProjectName)
+		tempDest := filepath.Join(t.Env.TempPath, "cdk8s-"+common.GetRandomString())
+		if err := os.MkdirAll(tempDest, common.DefaultDirectoryPermission); err != nil {
+			logrus.Errorf("failed to create the directory at path %s . Error: %q", tempDest, err)
+			continue
+		}
+		if language == cdk8sGoLanguage {
+			err = writeCdk8sGoProject(tempDest, appName, objs)
+		} else {
+			err = os.WriteFile(filepath.Join(tempDest, "main.ts"), []byte(getCdk8sTypeScript(appName, objs)), common.DefaultFilePermission)
+		}
+		if err != nil {
+			logrus.Errorf("failed to generate the cdk8s code. Error: %q", err)
+			continue
+		}
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:           transformertypes.TemplatePathMappingType,
+			SrcPath:        filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir, language),
+			DestPath:       t.Cdk8sConfig.OutputPath,
+			TemplateConfig: Cdk8sTemplateConfig{AppName: appName},
+		}, transformertypes.PathMapping{
+			Type:     transformertypes.DefaultPathMappingType,
+			SrcPath:  tempDest,
+			DestPath: t.Cdk8sConfig.OutputPath,
+		})
+	}
+	return pathMappings, nil, nil
+}
+
+// getCdk8sAPIObjects reads the kubernetes yamls in the order of the file names and assigns the construct ids
+func getCdk8sAPIObjects(files []string) []cdk8sAPIObject {
+	sort.Strings(files)
+	objs := []cdk8sAPIObject{}
+	ids := map[string]bool{}
+	for _, file := range files {
+		obj := map[string]interface{}{}
+		if err := common.ReadYaml(file, &obj); err != nil {
+			logrus.Errorf("Unable to read the kubernetes yaml at path %s . Error: %q", file, err)
+			continue
+		}
+		kind, _ := obj["kind"].(string)
+		name := ""
+		if metadata, ok := obj["metadata"].(map[string]interface{}); ok {
+			name, _ = metadata["name"].(string)
+		}
+		id := strings.ToLower(kind) + "-" + name
+		for i := 2; ids[id]; i++ {
+			id = fmt.Sprintf("%s-%s-%d", strings.ToLower(kind), name, i)
+		}
+		ids[id] = true
+		objs = append(objs, cdk8sAPIObject{id: id, obj: obj})
+	}
+	return objs
+}
+
+// getCdk8sChartName returns the name of the chart class for the application
+func getCdk8sChartName(appName string) string {
+	chartName := ""
+	for _, part := range strings.FieldsFunc(appName, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) }) {
+		chartName += strings.ToUpper(part[:1]) + part[1:]
+	}
+	if chartName == "" || unicode.IsDigit(rune(chartName[0])) {
+		chartName = "App" + chartName
+	}
+	return chartName + "Chart"
+}
+
+// getCdk8sTypeScript returns the TypeScript code of the chart, passing the resources to the ApiObject constructs as object literals
+func getCdk8sTypeScript(appName string, objs []cdk8sAPIObject) string {
+	chartName := getCdk8sChartName(appName)
+	code := "import { Construct } from 'constructs';\n" +
+		"import { App, ApiObject, Chart, ChartProps } from 'cdk8s';\n\n" +
+		"export class " + chartName + " extends Chart {\n" +
+		"  constructor(scope: Construct, id: string, props: ChartProps = {}) {\n" +
+		"    super(scope, id, props);\n"
+	for _, obj := range objs {
+		literal, err := json.MarshalIndent(obj.obj, "    ", "  ")
+		if err != nil {
+			logrus.Errorf("Unable to convert the resource %s to TypeScript. Error: %q", obj.id, err)
+			continue
+		}
+		code += fmt.Sprintf("\n    new ApiObject(this, %s, %s);\n", strconv.Quote(obj.id), literal)
+	}
+	code += "  }\n}\n\n" +
+		"const app = new App();\n" +
+		"new " + chartName + "(app, " + strconv.Quote(appName) + ");\n" +
+		"app.synth();\n"
+	return code
+}
+
+// writeCdk8sGoProject writes the go module of the chart, which constructs the resources using ApiObject and json patches
+func writeCdk8sGoProject(dir, appName string, objs []cdk8sAPIObject) error {
+	chartName := getCdk8sChartName(appName)
+	code := "package main\n\n" +
+		"import (\n" +
+		"\t\"github.com/aws/constructs-go/constructs/v10\"\n" +
+		"\t\"github.com/aws/jsii-runtime-go\"\n" +
+		"\t\"github.com/cdk8s-team/cdk8s-core-go/cdk8s/v2\"\n" +
+		")\n\n" +
+		"// New" + chartName + " constructs the resources of the application\n" +
+		"func New" + chartName + "(scope constructs.Construct, id string, props *cdk8s.ChartProps) cdk8s.Chart {\n" +
+		"chart := cdk8s.NewChart(scope, jsii.String(id), props)\n"
+	objsCode := ""
+	for _, obj := range objs {
+		objCode, patched := getCdk8sGoAPIObject(obj)
+		if patched && !strings.Contains(code, "var apiObject") {
+			code += "var apiObject cdk8s.ApiObject\n"
+		}
+		objsCode += "\n" + objCode
+	}
+	code += objsCode + "return chart\n}\n\n" +
+		"func main() {\n" +
+		"app := cdk8s.NewApp(nil)\n" +
+		"New" + chartName + "(app, " + strconv.Quote(appName) + ", nil)\n" +
+		"app.Synth()\n" +
+		"}\n"
+	formatted, err := format.Source([]byte(code))
+	if err != nil {
+		return fmt.Errorf("failed to format the go code of the chart. Error: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), formatted, common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the go code of the chart. Error: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(fmt.Sprintf(cdk8sGoModTemplate, appName)), common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the go.mod of the chart. Error: %w", err)
+	}
+	return nil
+}
+
+// getCdk8sGoAPIObject returns the go code constructing the resource, and whether the ApiObject is patched after its construction.
+// The ApiObjectProps only have the type and the metadata, so the rest of the fields are added as json patches.
+func getCdk8sGoAPIObject(obj cdk8sAPIObject) (string, bool) {
+	apiVersion, _ := obj.obj["apiVersion"].(string)
+	kind, _ := obj.obj["kind"].(string)
+	metadata, _ := obj.obj["metadata"].(map[string]interface{})
+	name, _ := metadata["name"].(string)
+	metadataProps := "Name: jsii.String(" + strconv.Quote(name) + ")"
+	if namespace, ok := metadata["namespace"].(string); ok {
+		metadataProps += ", Namespace: jsii.String(" + strconv.Quote(namespace) + ")"
+	}
+	construct := fmt.Sprintf("cdk8s.NewApiObject(chart, jsii.String(%s), &cdk8s.ApiObjectProps{\nApiVersion: jsii.String(%s),\nKind: jsii.String(%s),\nMetadata: &cdk8s.ApiObjectMetadata{%s},\n})\n",
+		strconv.Quote(obj.id), strconv.Quote(apiVersion), strconv.Quote(kind), metadataProps)
+	code := ""
+	for _, field := range []string{"labels", "annotations"} {
+		values, _ := metadata[field].(map[string]interface{})
+		for _, key := range getSortedKeys(values) {
+			value := fmt.Sprintf("%v", values[key])
+			if field == "labels" {
+				code += fmt.Sprintf("apiObject.Metadata().AddLabel(jsii.String(%s), jsii.String(%s))\n", strconv.Quote(key), strconv.Quote(value))
+			} else {
+				code += fmt.Sprintf("apiObject.Metadata().AddAnnotation(jsii.String(%s), jsii.String(%s))\n", strconv.Quote(key), strconv.Quote(value))
+			}
+		}
+	}
+	for _, key := range getSortedKeys(metadata) {
+		if common.IsPresent([]string{"name", "namespace", "labels", "annotations"}, key) || metadata[key] == nil {
+			continue
+		}
+		code += fmt.Sprintf("apiObject.AddJsonPatch(cdk8s.JsonPatch_Add(jsii.String(%s), %s))\n", strconv.Quote("/metadata/"+key), getGoLiteral(metadata[key]))
+	}
+	for _, key := range getSortedKeys(obj.obj) {
+		if common.IsPresent([]string{"apiVersion", "kind", "metadata"}, key) || obj.obj[key] == nil {
+			continue
+		}
+		code += fmt.Sprintf("apiObject.AddJsonPatch(cdk8s.JsonPatch_Add(jsii.String(%s), %s))\n", strconv.Quote("/"+key), getGoLiteral(obj.obj[key]))
+	}
+	if code == "" {
+		return construct, false
+	}
+	return "apiObject = " + construct + code, true
+}
+
+// getGoLiteral returns the go composite literal of a value decoded from yaml
+func getGoLiteral(value interface{}) string {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		literal := "map[string]interface{}{\n"
+		for _, key := range getSortedKeys(value) {
+			literal += strconv.Quote(key) + ": " + getGoLiteral(value[key]) + ",\n"
+		}
+		return literal + "}"
+	case []interface{}:
+		literal := "[]interface{}{\n"
+		for _, item := range value {
+			literal += getGoLiteral(item) + ",\n"
+		}
+		return literal + "}"
+	case string:
+		return strconv.Quote(value)
+	case nil:
+		return "nil"
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+func getSortedKeys(m map[string]interface{}) []string {
+	keys := []string{}
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
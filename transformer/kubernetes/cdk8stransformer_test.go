@@ -0,0 +1,196 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func newCdk8sTestAPIObjects() []cdk8sAPIObject {
+	return []cdk8sAPIObject{
+		{id: "deployment-api", obj: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata": map[string]interface{}{
+				"name":              "api",
+				"labels":            map[string]interface{}{"app": "api"},
+				"annotations":       map[string]interface{}{"replicas": 2},
+				"creationTimestamp": nil,
+			},
+			"spec": map[string]interface{}{
+				"replicas": 2,
+				"template": map[string]interface{}{"spec": map[string]interface{}{
+					"containers": []interface{}{map[string]interface{}{"name": "api", "image": "api:latest"}},
+				}},
+			},
+		}},
+		{id: "configmap-api", obj: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata":   map[string]interface{}{"name": "api", "namespace": "shop"},
+		}},
+	}
+}
+
+func TestGetCdk8sChartName(t *testing.T) {
+	for appName, want := range map[string]string{
+		"shop":        "ShopChart",
+		"my-shop-app": "MyShopAppChart",
+		"2048":        "App2048Chart",
+		"---":         "AppChart",
+	} {
+		if got := getCdk8sChartName(appName); got != want {
+			t.Errorf("getCdk8sChartName(%q) = %q, want %q", appName, got, want)
+		}
+	}
+}
+
+func TestGetCdk8sAPIObjects(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{}
+	for fileName, contents := range map[string]string{
+		"b-service.yaml":    "apiVersion: v1\nkind: Service\nmetadata:\n  name: api\n",
+		"a-deployment.yaml": "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: api\n",
+		"c-service.yaml":    "apiVersion: v1\nkind: Service\nmetadata:\n  name: api\n",
+	} {
+		file := filepath.Join(dir, fileName)
+		if err := os.WriteFile(file, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the yaml %s . Error: %q", file, err)
+		}
+		files = append(files, file)
+	}
+	ids := []string{}
+	for _, obj := range getCdk8sAPIObjects(files) {
+		ids = append(ids, obj.id)
+	}
+	if want := []string{"deployment-api", "service-api", "service-api-2"}; !cmp.Equal(ids, want) {
+		t.Fatalf("the construct ids are different. Difference:\n%s", cmp.Diff(want, ids))
+	}
+}
+
+func TestGetCdk8sTypeScript(t *testing.T) {
+	code := getCdk8sTypeScript("my-shop", newCdk8sTestAPIObjects())
+	for _, line := range []string{
+		"export class MyShopChart extends Chart {",
+		`    new ApiObject(this, "deployment-api", {`,
+		`      "kind": "Deployment",`,
+		`    new ApiObject(this, "configmap-api", {`,
+		`new MyShopChart(app, "my-shop");`,
+	} {
+		if !strings.Contains(code, line+"\n") {
+			t.Fatalf("expected the line %q in the code:\n%s", line, code)
+		}
+	}
+}
+
+func TestWriteCdk8sGoProject(t *testing.T) {
+	dir := t.TempDir()
+	if err := writeCdk8sGoProject(dir, "my-shop", newCdk8sTestAPIObjects()); err != nil {
+		t.Fatalf("failed to write the go project. Error: %q", err)
+	}
+	code, err := os.ReadFile(filepath.Join(dir, "main.go"))
+	if err != nil {
+		t.Fatalf("failed to read the go code. Error: %q", err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "main.go", code, 0); err != nil {
+		t.Fatalf("the go code is invalid. Error: %q\n%s", err, code)
+	}
+	for _, line := range []string{
+		"func NewMyShopChart(scope constructs.Construct, id string, props *cdk8s.ChartProps) cdk8s.Chart {",
+		"\tvar apiObject cdk8s.ApiObject",
+		`	apiObject.Metadata().AddLabel(jsii.String("app"), jsii.String("api"))`,
+		`	apiObject.Metadata().AddAnnotation(jsii.String("replicas"), jsii.String("2"))`,
+		`	apiObject.AddJsonPatch(cdk8s.JsonPatch_Add(jsii.String("/spec"), map[string]interface{}{`,
+		`		Metadata:   &cdk8s.ApiObjectMetadata{Name: jsii.String("api"), Namespace: jsii.String("shop")},`,
+	} {
+		if !strings.Contains(string(code), line+"\n") {
+			t.Fatalf("expected the line %q in the code:\n%s", line, code)
+		}
+	}
+	if strings.Contains(string(code), "creationTimestamp") {
+		t.Fatalf("expected the null fields to be skipped:\n%s", code)
+	}
+	if strings.Count(string(code), "var apiObject") != 1 {
+		t.Fatalf("expected the apiObject to be declared once:\n%s", code)
+	}
+	goMod, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil || !strings.HasPrefix(string(goMod), "module my-shop\n") {
+		t.Fatalf("expected the go.mod of the module my-shop. Actual: %s Error: %v", goMod, err)
+	}
+}
+
+func TestCdk8sTransform(t *testing.T) {
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{common.ConfigCdk8sLanguageKey + `="go"`}, nil, nil, false)
+	tc := transformertypes.Transformer{}
+	tc.Spec.TemplatesDir = "templates"
+	env := &environment.Environment{EnvInfo: environment.EnvInfo{
+		ProjectName: "shop",
+		TempPath:    t.TempDir(),
+		Context:     filepath.Join("..", "..", "assets", "built-in", "transformers", "kubernetes", "cdk8s"),
+	}}
+	transformer := &Cdk8s{}
+	if err := transformer.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	ir := irtypes.NewIR()
+	api := irtypes.NewServiceWithName("api")
+	api.Containers = []core.Container{{Name: "api", Image: "api:latest"}}
+	ir.Services["api"] = api
+	cluster := collecttypes.NewClusterMetadata("")
+	cluster.Spec.APIKindVersionMap = map[string][]string{common.DeploymentKind: {"apps/v1"}}
+	pathMappings, _, err := transformer.Transform([]transformertypes.Artifact{{
+		Name: "shop",
+		Type: irtypes.IRArtifactType,
+		Configs: map[transformertypes.ConfigType]interface{}{
+			irtypes.IRConfigType: ir,
+			ClusterMetadata:      cluster,
+		},
+	}}, nil)
+	if err != nil {
+		t.Fatalf("failed to transform. Error: %q", err)
+	}
+	if len(pathMappings) != 2 {
+		t.Fatalf("expected the templates and the code of the project. Actual: %+v", pathMappings)
+	}
+	if pathMappings[0].SrcPath != filepath.Join(env.Context, "templates", "go") || pathMappings[0].DestPath != defaultCdk8sOutputPath {
+		t.Fatalf("expected the templates of the go project. Actual: %+v", pathMappings[0])
+	}
+	code, err := os.ReadFile(filepath.Join(pathMappings[1].SrcPath, "main.go"))
+	if err != nil {
+		t.Fatalf("failed to read the go code. Error: %q", err)
+	}
+	if !strings.Contains(string(code), `cdk8s.NewApiObject(chart, jsii.String("deployment-api"), &cdk8s.ApiObjectProps{`) {
+		t.Fatalf("expected the deployment in the chart:\n%s", code)
+	}
+}
@@ -18,6 +18,7 @@ package kubernetes
 
 import (
 	"fmt"
+	"sort"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
@@ -31,6 +32,8 @@ import (
 const (
 	// clusterTypeKey is the key for QA ID
 	clusterTypeKey = "clustertype"
+	// additionalClusterTypesKey is the key for QA ID of the other clusters the application is deployed to
+	additionalClusterTypesKey = "additionalclustertypes"
 	// defaultStorageClassName defines the default storage class to be used
 	defaultStorageClassName = "default"
 	// defaultQALabel defines the default QA label to be use
@@ -39,6 +42,8 @@ const (
 	defaultClusterType = "Kubernetes"
 	// ClusterMetadata config stores cluster configuration of selected cluster
 	ClusterMetadata transformertypes.ConfigType = "ClusterMetadata"
+	// AdditionalClusterMetadatas config stores cluster configurations of the other selected clusters
+	AdditionalClusterMetadatas transformertypes.ConfigType = "AdditionalClusterMetadatas"
 )
 
 // ClusterSelectorTransformer implements Transformer interface
@@ -47,6 +52,8 @@ type ClusterSelectorTransformer struct {
 	Env      *environment.Environment
 	Clusters map[string]collecttypes.ClusterMetadata
 	CSConfig *ClusterSelectorConfig
+	// collectedClusters stores the names of the clusters collected from the live cluster using move2kube collect
+	collectedClusters []string
 }
 
 // ClusterSelectorConfig represents the configuration of the cluster selector
@@ -76,6 +83,7 @@ func (t *ClusterSelectorTransformer) Init(tc transformertypes.Transformer, e *en
 		}
 		t.Clusters[cm.Name] = cm
 	}
+	t.loadCollectedClusters()
 	err = common.GetObjFromInterface(t.Config.Spec.Config, t.CSConfig)
 	if err != nil {
 		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.CSConfig, err)
@@ -109,7 +117,9 @@ func (t *ClusterSelectorTransformer) Transform(newArtifacts []transformertypes.A
 		return nil, nil, err
 	}
 	def := defaultClusterType
-	if !common.IsPresent(clusterTypeList, def) {
+	if len(t.collectedClusters) > 0 {
+		def = t.collectedClusters[0]
+	} else if !common.IsPresent(clusterTypeList, def) {
 		def = clusterTypeList[0]
 	}
 	clusterType := qaengine.FetchSelectAnswer(
@@ -118,6 +128,7 @@ func (t *ClusterSelectorTransformer) Transform(newArtifacts []transformertypes.A
 		[]string{"Choose the cluster type you would like to target"}, def, clusterTypeList,
 		nil,
 	)
+	additionalClusters := t.getAdditionalClusters(clusterType, clusterTypeList)
 	for ai := range newArtifacts {
 		if newArtifacts[ai].Configs == nil {
 			newArtifacts[ai].Configs = make(map[transformertypes.ConfigType]interface{})
@@ -129,10 +140,77 @@ func (t *ClusterSelectorTransformer) Transform(newArtifacts []transformertypes.A
 		cluster.Labels[collecttypes.ClusterQaLabelKey] = t.CSConfig.ClusterQaLabel
 		t.Clusters[clusterType] = cluster
 		newArtifacts[ai].Configs[ClusterMetadata] = t.Clusters[clusterType]
+		if len(additionalClusters) > 0 {
+			newArtifacts[ai].Configs[AdditionalClusterMetadatas] = additionalClusters
+		}
 	}
 	return nil, newArtifacts, nil
 }
 
+// getAdditionalClusters returns the metadata of the other clusters the kubernetes yamls should be generated for
+func (t *ClusterSelectorTransformer) getAdditionalClusters(clusterType string, clusterTypeList []string) map[string]collecttypes.ClusterMetadata {
+	otherClusterTypes := []string{}
+	for _, c := range clusterTypeList {
+		if c != clusterType {
+			otherClusterTypes = append(otherClusterTypes, c)
+		}
+	}
+	if len(otherClusterTypes) == 0 {
+		return nil
+	}
+	sort.Strings(otherClusterTypes)
+	additionalClusterTypes := qaengine.FetchMultiSelectAnswer(
+		common.JoinQASubKeys(common.ConfigTargetKey, `"`+t.CSConfig.ClusterQaLabel+`"`, additionalClusterTypesKey),
+		"Choose the other cluster types to generate kubernetes yamls for:",
+		[]string{"The kubernetes yamls for each of the other clusters are generated in a separate directory, using the versions supported by that cluster"},
+		[]string{},
+		otherClusterTypes,
+		nil,
+	)
+	additionalClusters := map[string]collecttypes.ClusterMetadata{}
+	for _, c := range additionalClusterTypes {
+		cluster, ok := t.Clusters[c]
+		if !ok {
+			logrus.Errorf("Unable to find the cluster type %s. Ignoring.", c)
+			continue
+		}
+		if cluster.Labels == nil {
+			cluster.Labels = make(map[string]string)
+		}
+		// the cluster specific questions like the ingress host are asked again for each of the other clusters
+		cluster.Labels[collecttypes.ClusterQaLabelKey] = c
+		additionalClusters[c] = cluster
+	}
+	return additionalClusters
+}
+
+// loadCollectedClusters loads the cluster metadata collected from live clusters and present in the source directory.
+// The collected metadata overrides the built-in cluster metadata with the same name.
+func (t *ClusterSelectorTransformer) loadCollectedClusters() {
+	if t.Env.Source == "" {
+		return
+	}
+	filePaths, err := common.GetYamlsWithTypeMeta(t.Env.Source, string(collecttypes.ClusterMetadataKind))
+	if err != nil {
+		logrus.Debugf("Failed to fetch the collected cluster metadata yamls at path %q Error: %q", t.Env.Source, err)
+		return
+	}
+	sort.Strings(filePaths)
+	for _, filePath := range filePaths {
+		cm, err := t.GetClusterMetadata(filePath)
+		if err != nil {
+			continue
+		}
+		if common.IsPresent(t.collectedClusters, cm.Name) {
+			logrus.Errorf("Two collected cluster configs with same name : %s", cm.Name)
+			continue
+		}
+		logrus.Debugf("Using the collected cluster metadata %s at path %q", cm.Name, filePath)
+		t.Clusters[cm.Name] = cm
+		t.collectedClusters = append(t.collectedClusters, cm.Name)
+	}
+}
+
 // GetClusterMetadata returns the Cluster Metadata
 func (t *ClusterSelectorTransformer) GetClusterMetadata(path string) (collecttypes.ClusterMetadata, error) {
 	cm := collecttypes.ClusterMetadata{}
@@ -0,0 +1,119 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+func TestClusterSelectorAdditionalClusters(t *testing.T) {
+	newClusterSelector := func() *ClusterSelectorTransformer {
+		return &ClusterSelectorTransformer{
+			CSConfig: &ClusterSelectorConfig{ClusterQaLabel: defaultQALabel},
+			Clusters: map[string]collecttypes.ClusterMetadata{
+				"Kubernetes": collecttypes.NewClusterMetadata("Kubernetes"),
+				"Openshift":  collecttypes.NewClusterMetadata("Openshift"),
+				"GKE":        collecttypes.NewClusterMetadata("GKE"),
+			},
+		}
+	}
+	setup := func(t *testing.T, configs ...string) {
+		t.Helper()
+		qaengine.Reset()
+		t.Cleanup(qaengine.Reset)
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", configs, nil, nil, false)
+	}
+	additionalClusterTypesQAKey := common.JoinQASubKeys(common.ConfigTargetKey, `"`+defaultQALabel+`"`, additionalClusterTypesKey)
+
+	t.Run("only the selected cluster by default", func(t *testing.T) {
+		setup(t)
+		_, artifacts, err := newClusterSelector().Transform([]transformertypes.Artifact{{Name: "ir"}}, nil)
+		if err != nil {
+			t.Fatalf("failed to select the cluster. Error: %q", err)
+		}
+		cluster, ok := artifacts[0].Configs[ClusterMetadata].(collecttypes.ClusterMetadata)
+		if !ok || cluster.Name != defaultClusterType || cluster.Labels[collecttypes.ClusterQaLabelKey] != defaultQALabel {
+			t.Fatalf("expected the default cluster with the default QA label. Actual: %+v", artifacts[0].Configs[ClusterMetadata])
+		}
+		if _, ok := artifacts[0].Configs[AdditionalClusterMetadatas]; ok {
+			t.Fatalf("expected no additional clusters. Actual: %+v", artifacts[0].Configs[AdditionalClusterMetadatas])
+		}
+	})
+
+	t.Run("the additional clusters get their own QA label", func(t *testing.T) {
+		setup(t, additionalClusterTypesQAKey+`=["Openshift","GKE","Kubernetes"]`)
+		_, artifacts, err := newClusterSelector().Transform([]transformertypes.Artifact{{Name: "ir"}}, nil)
+		if err != nil {
+			t.Fatalf("failed to select the clusters. Error: %q", err)
+		}
+		additionalClusters, ok := artifacts[0].Configs[AdditionalClusterMetadatas].(map[string]collecttypes.ClusterMetadata)
+		if !ok || len(additionalClusters) != 2 {
+			t.Fatalf("expected the GKE and Openshift clusters, without the selected cluster. Actual: %+v", artifacts[0].Configs[AdditionalClusterMetadatas])
+		}
+		for _, clusterType := range []string{"GKE", "Openshift"} {
+			if label := additionalClusters[clusterType].Labels[collecttypes.ClusterQaLabelKey]; label != clusterType {
+				t.Fatalf("got the QA label %q for the cluster %s , want its name", label, clusterType)
+			}
+		}
+		if cluster := artifacts[0].Configs[ClusterMetadata].(collecttypes.ClusterMetadata); cluster.Labels[collecttypes.ClusterQaLabelKey] != defaultQALabel {
+			t.Fatalf("expected the selected cluster to keep the default QA label. Actual: %+v", cluster.Labels)
+		}
+	})
+}
+
+func TestBuiltInClusterPresets(t *testing.T) {
+	clustersDir := filepath.Join("..", "..", "assets", "built-in", "transformers", "kubernetes", "clusterselector", "clusters")
+	testCases := []struct {
+		file              string
+		name              string
+		ingressController string
+		exposeKind        string
+	}{
+		{file: "aws-eks-1.23.yaml", name: "AWS-EKS-1.23", ingressController: "aws-alb", exposeKind: common.IngressKind},
+		{file: "azure-aks-1.23.yaml", name: "Azure-AKS-1.23", ingressController: "nginx", exposeKind: common.IngressKind},
+		{file: "gcp-gke-1.23.yaml", name: "GCP-GKE-1.23", ingressController: "gce", exposeKind: common.IngressKind},
+		{file: "k3s.yaml", name: "K3s", ingressController: "traefik", exposeKind: common.IngressKind},
+		{file: "openshift-4.10.yaml", name: "Openshift-4.10", exposeKind: "Route"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			cluster, err := (&ClusterSelectorTransformer{}).GetClusterMetadata(filepath.Join(clustersDir, testCase.file))
+			if err != nil {
+				t.Fatalf("failed to read the cluster preset %s . Error: %q", testCase.file, err)
+			}
+			if cluster.Name != testCase.name || cluster.Spec.ServerVersion != "v1.23" || cluster.Spec.IngressController != testCase.ingressController {
+				t.Fatalf("got the cluster %s with the server version %q and the ingress controller %q , want %s with v1.23 and %q",
+					cluster.Name, cluster.Spec.ServerVersion, cluster.Spec.IngressController, testCase.name, testCase.ingressController)
+			}
+			for _, kind := range []string{common.DeploymentKind, common.ServiceKind, testCase.exposeKind} {
+				if len(cluster.Spec.APIKindVersionMap[kind]) == 0 {
+					t.Fatalf("expected the cluster preset %s to support the kind %s", testCase.file, kind)
+				}
+			}
+			if len(cluster.Spec.StorageClasses) == 0 {
+				t.Fatalf("expected the cluster preset %s to have storage classes", testCase.file)
+			}
+		})
+	}
+}
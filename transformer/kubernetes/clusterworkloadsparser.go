@@ -0,0 +1,264 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	apps "k8s.io/kubernetes/pkg/apis/apps"
+	batch "k8s.io/kubernetes/pkg/apis/batch"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+// ClusterWorkloadsParser implements Transformer interface.
+// It converts the workloads collected from a cluster into IR, so that they can be re-platformed to another cluster.
+type ClusterWorkloadsParser struct {
+	Config transformertypes.Transformer
+	Env    *environment.Environment
+}
+
+// Init Initializes the transformer
+func (t *ClusterWorkloadsParser) Init(tc transformertypes.Transformer, env *environment.Environment) error {
+	t.Config = tc
+	t.Env = env
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *ClusterWorkloadsParser) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect detects the workloads collected using move2kube collect
+func (t *ClusterWorkloadsParser) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	filePaths, err := common.GetFilesByExtInCurrDir(dir, []string{".yaml", ".yml"})
+	if err != nil {
+		logrus.Errorf("Unable to fetch yaml files at path %q Error: %q", dir, err)
+		return nil, err
+	}
+	services = map[string][]transformertypes.Artifact{}
+	for _, filePath := range filePaths {
+		wm := collecttypes.WorkloadsMetadata{}
+		if err := common.ReadMove2KubeYaml(filePath, &wm); err != nil || wm.Kind != string(collecttypes.WorkloadsMetadataKind) {
+			continue
+		}
+		serviceName := common.MakeStringK8sServiceNameCompliant(wm.Name)
+		services[serviceName] = append(services[serviceName], transformertypes.Artifact{
+			Paths: map[transformertypes.PathType][]string{
+				artifacts.WorkloadsMetadataPathType: {filePath},
+				artifacts.KubernetesYamlsPathType:   {dir},
+				artifacts.ServiceDirPathType:        {dir},
+			},
+		})
+	}
+	return services, nil
+}
+
+// Transform transforms the artifacts
+func (t *ClusterWorkloadsParser) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	createdArtifacts := []transformertypes.Artifact{}
+	for _, newArtifact := range newArtifacts {
+		if len(newArtifact.Paths[artifacts.WorkloadsMetadataPathType]) == 0 {
+			continue
+		}
+		wm := collecttypes.WorkloadsMetadata{}
+		if err := common.ReadMove2KubeYaml(newArtifact.Paths[artifacts.WorkloadsMetadataPathType][0], &wm); err != nil {
+			logrus.Errorf("Failed to read the workloads metadata at path %q Error: %q", newArtifact.Paths[artifacts.WorkloadsMetadataPathType][0], err)
+			continue
+		}
+		ir := t.getIRFromWorkloads(filepath.Dir(newArtifact.Paths[artifacts.WorkloadsMetadataPathType][0]))
+		if len(ir.Services) == 0 {
+			logrus.Warnf("No workloads found in %s %s", wm.Spec.Source, wm.Name)
+			continue
+		}
+		createdArtifacts = append(createdArtifacts, transformertypes.Artifact{
+			Name:    t.Env.GetProjectName(),
+			Type:    irtypes.IRArtifactType,
+			Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir},
+		})
+	}
+	return nil, createdArtifacts, nil
+}
+
+// getIRFromWorkloads converts the kubernetes objects in the directory into IR
+func (t *ClusterWorkloadsParser) getIRFromWorkloads(dir string) irtypes.IR {
+	ir := irtypes.NewIR()
+	ir.Name = t.Env.GetProjectName()
+	podLabels := map[string]map[string]string{}
+	k8sServices := []*core.Service{}
+	ingresses := []*networking.Ingress{}
+	for _, obj := range k8sschema.GetKubernetesObjsInDir(dir) {
+		lobj, err := k8sschema.ConvertToLiasonScheme(obj)
+		if err != nil {
+			logrus.Debugf("Ignoring the object of kind %s : %s", obj.GetObjectKind().GroupVersionKind().Kind, err)
+			continue
+		}
+		switch o := lobj.(type) {
+		case *apps.Deployment:
+			ir.AddService(t.newServiceFromPodSpec(o.Name, o.Spec.Template.Spec, int(o.Spec.Replicas)))
+			podLabels[o.Name] = o.Spec.Template.Labels
+		case *apps.StatefulSet:
+			service := t.newServiceFromPodSpec(o.Name, o.Spec.Template.Spec, int(o.Spec.Replicas))
+			for _, claim := range o.Spec.VolumeClaimTemplates {
+				storageName := o.Name + "-" + claim.Name
+				ir.AddStorage(irtypes.Storage{Name: storageName, StorageType: irtypes.PVCKind, PersistentVolumeClaimSpec: claim.Spec})
+				service.AddVolume(core.Volume{Name: claim.Name, VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: storageName}}})
+			}
+			ir.AddService(service)
+			podLabels[o.Name] = o.Spec.Template.Labels
+		case *apps.DaemonSet:
+			service := t.newServiceFromPodSpec(o.Name, o.Spec.Template.Spec, 1)
+			service.Daemon = true
+			ir.AddService(service)
+			podLabels[o.Name] = o.Spec.Template.Labels
+		case *batch.Job:
+			ir.AddService(t.newServiceFromPodSpec(o.Name, getJobPodSpec(o.Spec.Template.Spec), 1))
+			podLabels[o.Name] = o.Spec.Template.Labels
+		case *batch.CronJob:
+			service := t.newServiceFromPodSpec(o.Name, getJobPodSpec(o.Spec.JobTemplate.Spec.Template.Spec), 1)
+			service.Schedule = o.Spec.Schedule
+			ir.AddService(service)
+			podLabels[o.Name] = o.Spec.JobTemplate.Spec.Template.Labels
+		case *core.Service:
+			k8sServices = append(k8sServices, o)
+		case *networking.Ingress:
+			ingresses = append(ingresses, o)
+		case *core.ConfigMap:
+			content := map[string][]byte{}
+			for k, v := range o.Data {
+				content[k] = []byte(v)
+			}
+			for k, v := range o.BinaryData {
+				content[k] = v
+			}
+			ir.AddStorage(irtypes.Storage{Name: o.Name, StorageType: irtypes.ConfigMapKind, Content: content})
+		case *core.Secret:
+			ir.AddStorage(irtypes.Storage{Name: o.Name, StorageType: irtypes.SecretKind, SecretType: o.Type, Content: o.Data})
+		case *core.PersistentVolumeClaim:
+			ir.AddStorage(irtypes.Storage{Name: o.Name, StorageType: irtypes.PVCKind, PersistentVolumeClaimSpec: o.Spec})
+		default:
+			logrus.Debugf("Ignoring the unsupported workload kind %s", obj.GetObjectKind().GroupVersionKind().Kind)
+		}
+	}
+	// map the kubernetes services to the workloads they select
+	backendServices := map[string]string{}
+	serviceNames := []string{}
+	for serviceName := range podLabels {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	for _, k8sService := range k8sServices {
+		if len(k8sService.Spec.Selector) == 0 {
+			continue
+		}
+		selector := labels.SelectorFromSet(k8sService.Spec.Selector)
+		for _, serviceName := range serviceNames {
+			if !selector.Matches(labels.Set(podLabels[serviceName])) {
+				continue
+			}
+			service := ir.Services[serviceName]
+			for _, port := range k8sService.Spec.Ports {
+				podPort := t.getPodPort(service, port.TargetPort, port.Port)
+				if err := service.AddPortForwarding(networking.ServiceBackendPort{Name: port.Name, Number: port.Port}, podPort, ""); err != nil {
+					logrus.Warnf("Failed to add the port forwarding for port %d of service %s . Error: %q", port.Port, k8sService.Name, err)
+					continue
+				}
+				service.ServiceToPodPortForwardings[len(service.ServiceToPodPortForwardings)-1].ServiceType = k8sService.Spec.Type
+				// the clients reaching the workload on a fixed node port are asked about like the ports published on the hosts
+				service.ServiceToPodPortForwardings[len(service.ServiceToPodPortForwardings)-1].HostPort = port.NodePort
+			}
+			ir.Services[serviceName] = service
+			backendServices[k8sService.Name] = serviceName
+			break
+		}
+	}
+	// expose the paths used by the ingresses on the services
+	for _, ingress := range ingresses {
+		for _, rule := range ingress.Spec.Rules {
+			if rule.HTTP == nil {
+				continue
+			}
+			for _, path := range rule.HTTP.Paths {
+				if path.Backend.Service == nil {
+					continue
+				}
+				serviceName, ok := backendServices[path.Backend.Service.Name]
+				if !ok {
+					continue
+				}
+				service := ir.Services[serviceName]
+				for i, pf := range service.ServiceToPodPortForwardings {
+					if pf.ServicePort.Number == path.Backend.Service.Port.Number || (pf.ServicePort.Name != "" && pf.ServicePort.Name == path.Backend.Service.Port.Name) {
+						relPath := path.Path
+						if relPath == "" {
+							relPath = "/"
+						}
+						service.ServiceToPodPortForwardings[i].ServiceRelPath = relPath
+					}
+				}
+				ir.Services[serviceName] = service
+			}
+		}
+	}
+	return ir
+}
+
+// getJobPodSpec returns the pod spec of the job with a restart policy that keeps it a job
+func getJobPodSpec(podSpec core.PodSpec) core.PodSpec {
+	if podSpec.RestartPolicy == core.RestartPolicyAlways || podSpec.RestartPolicy == "" {
+		podSpec.RestartPolicy = core.RestartPolicyOnFailure
+	}
+	return podSpec
+}
+
+func (t *ClusterWorkloadsParser) newServiceFromPodSpec(name string, podSpec core.PodSpec, replicas int) irtypes.Service {
+	service := irtypes.NewServiceWithName(name)
+	service.PodSpec = irtypes.PodSpec(podSpec)
+	service.Replicas = replicas
+	return service
+}
+
+// getPodPort resolves the target port of the kubernetes service to a container port
+func (t *ClusterWorkloadsParser) getPodPort(service irtypes.Service, targetPort intstr.IntOrString, servicePort int32) networking.ServiceBackendPort {
+	if targetPort.Type == intstr.Int {
+		if targetPort.IntVal == 0 {
+			return networking.ServiceBackendPort{Number: servicePort}
+		}
+		return networking.ServiceBackendPort{Number: targetPort.IntVal}
+	}
+	for _, container := range service.Containers {
+		for _, port := range container.Ports {
+			if port.Name == targetPort.StrVal {
+				return networking.ServiceBackendPort{Name: port.Name, Number: port.ContainerPort}
+			}
+		}
+	}
+	logrus.Warnf("Unable to find the container port named %s in service %s . Using the service port %d", targetPort.StrVal, service.Name, servicePort)
+	return networking.ServiceBackendPort{Number: servicePort}
+}
@@ -0,0 +1,116 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/environment"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestGetIRFromWorkloads(t *testing.T) {
+	manifests := map[string]string{
+		"cronjob-report.yaml": `apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: report
+spec:
+  schedule: "0 2 * * *"
+  jobTemplate:
+    spec:
+      template:
+        metadata:
+          labels: {app: report}
+        spec:
+          containers:
+          - name: report
+            image: quay.io/example/report:v1
+`,
+		"deployment-api.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+spec:
+  replicas: 2
+  selector:
+    matchLabels: {app: web}
+  template:
+    metadata:
+      labels: {app: web, tier: api}
+    spec:
+      containers:
+      - name: api
+        image: quay.io/example/api:v1
+        ports:
+        - containerPort: 8080
+`,
+		"deployment-web.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  selector:
+    matchLabels: {app: web}
+  template:
+    metadata:
+      labels: {app: web, tier: web}
+    spec:
+      containers:
+      - name: web
+        image: quay.io/example/web:v1
+`,
+		"service-web.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector: {app: web}
+  ports:
+  - port: 80
+    targetPort: 8080
+`,
+	}
+	dir := t.TempDir()
+	for fileName, manifest := range manifests {
+		if err := os.WriteFile(filepath.Join(dir, fileName), []byte(manifest), 0644); err != nil {
+			t.Fatalf("failed to write the manifest %s . Error: %q", fileName, err)
+		}
+	}
+	parser := &ClusterWorkloadsParser{Env: &environment.Environment{EnvInfo: environment.EnvInfo{ProjectName: "myproject"}}}
+	ir := parser.getIRFromWorkloads(dir)
+
+	report, ok := ir.Services["report"]
+	if !ok {
+		t.Fatalf("expected the cronjob to be converted to a service, got the services %+v", ir.Services)
+	}
+	if report.Schedule != "0 2 * * *" {
+		t.Fatalf("expected the schedule of the cronjob to be kept, got %q", report.Schedule)
+	}
+	if report.RestartPolicy != core.RestartPolicyOnFailure {
+		t.Fatalf("expected the cronjob to keep a job restart policy, got %q", report.RestartPolicy)
+	}
+	// both the deployments match the selector, the service is mapped to the first one by name every time
+	for i := 0; i < 10; i++ {
+		ir := parser.getIRFromWorkloads(dir)
+		if len(ir.Services["api"].ServiceToPodPortForwardings) != 1 || len(ir.Services["web"].ServiceToPodPortForwardings) != 0 {
+			t.Fatalf("expected the kubernetes service to be mapped to the deployment api, got the port forwardings %+v and %+v", ir.Services["api"].ServiceToPodPortForwardings, ir.Services["web"].ServiceToPodPortForwardings)
+		}
+	}
+}
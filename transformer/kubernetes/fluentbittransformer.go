@@ -0,0 +1,186 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+)
+
+const (
+	defaultFluentBitOutputPath = common.DeployDir + string(os.PathSeparator) + "logging"
+	fluentBitImage             = "cr.fluentbit.io/fluent/fluent-bit:2.1"
+	fluentBitDaemonSetMode     = "Fluent Bit DaemonSet"
+	loggingOperatorMode        = "Logging operator"
+)
+
+// fluentBitOutputs maps the names of the log destinations to the output plugin and the default host and port
+var fluentBitOutputs = map[string]struct {
+	plugin string
+	host   string
+	port   int
+}{
+	"Elasticsearch": {plugin: "es", host: "elasticsearch.logging.svc", port: 9200},
+	"Loki":          {plugin: "loki", host: "loki.logging.svc", port: 3100},
+	"Fluentd":       {plugin: "forward", host: "fluentd.logging.svc", port: 24224},
+}
+
+// FluentBit implements Transformer interface
+type FluentBit struct {
+	Config          transformertypes.Transformer
+	Env             *environment.Environment
+	FluentBitConfig *FluentBitConfig
+}
+
+// FluentBitConfig stores the transformer specific configuration
+type FluentBitConfig struct {
+	OutputPath string `yaml:"outputPath"`
+}
+
+// FluentBitTemplateConfig represents template config used by the log shipping manifests
+type FluentBitTemplateConfig struct {
+	AppName      string
+	Namespace    string
+	Image        string
+	ServiceNames []string
+	Output       string
+	OutputName   string
+	OutputHost   string
+	OutputPort   int
+}
+
+// Init Initializes the transformer
+func (t *FluentBit) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.FluentBitConfig = &FluentBitConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.FluentBitConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.FluentBitConfig, err)
+		return err
+	}
+	if t.FluentBitConfig.OutputPath == "" {
+		t.FluentBitConfig.OutputPath = defaultFluentBitOutputPath
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *FluentBit) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in each sub directory
+func (t *FluentBit) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform generates the manifests which ship the logs of the services, replacing the log streaming of Cloud Foundry
+func (t *FluentBit) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	serviceNames := []string{}
+	for _, a := range newArtifacts {
+		if a.Type != irtypes.IRArtifactType {
+			continue
+		}
+		var ir irtypes.IR
+		if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		for serviceName, service := range ir.Services {
+			if !service.OnlyIngress {
+				serviceNames = common.AppendIfNotPresent(serviceNames, common.MakeStringK8sServiceNameCompliant(serviceName))
+			}
+		}
+	}
+	if len(serviceNames) == 0 {
+		return nil, nil, nil
+	}
+	sort.Strings(serviceNames)
+	mode := qaengine.FetchSelectAnswer(
+		common.ConfigFluentBitModeKey,
+		"Select how the logs of the services should be shipped :",
+		[]string{
+			"Fluent Bit DaemonSet: a Fluent Bit DaemonSet which collects the logs of the containers on each node",
+			"Logging operator: Flow and Output resources for a Logging operator already installed in the cluster",
+		},
+		fluentBitDaemonSetMode,
+		[]string{fluentBitDaemonSetMode, loggingOperatorMode},
+		nil,
+	)
+	outputNames := []string{}
+	for outputName := range fluentBitOutputs {
+		outputNames = append(outputNames, outputName)
+	}
+	sort.Strings(outputNames)
+	outputName := qaengine.FetchSelectAnswer(
+		common.ConfigFluentBitOutputKey,
+		"Select where the logs should be shipped to :",
+		[]string{"The logs of the containers are enriched with the metadata of the pods"},
+		outputNames[0],
+		outputNames,
+		nil,
+	)
+	output := fluentBitOutputs[outputName]
+	tc := FluentBitTemplateConfig{
+		AppName:      common.MakeStringK8sServiceNameCompliant(t.Env.ProjectName),
+		Image:        fluentBitImage,
+		ServiceNames: serviceNames,
+		Output:       output.plugin,
+		OutputName:   outputName,
+		OutputHost: qaengine.FetchStringAnswer(
+			common.ConfigFluentBitOutputHostKey,
+			"Provide the host of "+outputName+" :",
+			[]string{"The host has to be reachable from the cluster"},
+			output.host,
+			qatypes.NewHostnameValidator(),
+		),
+		OutputPort: cast.ToInt(qaengine.FetchStringAnswer(
+			common.ConfigFluentBitOutputPortKey,
+			"Provide the port of "+outputName+" :",
+			nil,
+			cast.ToString(output.port),
+			qatypes.NewPortValidator(),
+		)),
+	}
+	templateFile := "logging-operator.yaml"
+	if mode == fluentBitDaemonSetMode {
+		templateFile = "fluent-bit.yaml"
+		tc.Namespace = qaengine.FetchStringAnswer(
+			common.ConfigFluentBitNamespaceKey,
+			"Provide the namespace where Fluent Bit should be deployed :",
+			[]string{"The namespace is created if it does not exist"},
+			"logging",
+			nil,
+		)
+	}
+	return []transformertypes.PathMapping{{
+		Type:           transformertypes.TemplatePathMappingType,
+		SrcPath:        filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir, templateFile),
+		DestPath:       filepath.Join(t.FluentBitConfig.OutputPath, templateFile),
+		TemplateConfig: tc,
+	}}, nil, nil
+}
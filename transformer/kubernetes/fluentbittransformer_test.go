@@ -0,0 +1,158 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"sigs.k8s.io/yaml"
+)
+
+func newFluentBitTestTransformer(t *testing.T) *FluentBit {
+	t.Helper()
+	tc := transformertypes.Transformer{}
+	tc.Spec.TemplatesDir = "templates"
+	fb := &FluentBit{}
+	env := &environment.Environment{EnvInfo: environment.EnvInfo{ProjectName: "myproject", Context: filepath.Join("..", "..", "assets", "built-in", "transformers", "kubernetes", "fluentbit")}}
+	if err := fb.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	return fb
+}
+
+func newFluentBitTestArtifact() transformertypes.Artifact {
+	ir := irtypes.NewIR()
+	ir.Services["api"] = irtypes.NewServiceWithName("api")
+	ir.Services["Web_App"] = irtypes.NewServiceWithName("Web_App")
+	ingress := irtypes.NewServiceWithName("ingress")
+	ingress.OnlyIngress = true
+	ir.Services["ingress"] = ingress
+	return transformertypes.Artifact{
+		Name:    "myproject",
+		Type:    irtypes.IRArtifactType,
+		Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir},
+	}
+}
+
+// renderFluentBitManifests renders the template of the path mapping and returns the kinds of the manifests
+func renderFluentBitManifests(t *testing.T, pathMapping transformertypes.PathMapping) []string {
+	t.Helper()
+	tpl, err := os.ReadFile(pathMapping.SrcPath)
+	if err != nil {
+		t.Fatalf("failed to read the template %s . Error: %q", pathMapping.SrcPath, err)
+	}
+	rendered, err := common.GetStringFromTemplate(string(tpl), pathMapping.TemplateConfig)
+	if err != nil {
+		t.Fatalf("failed to render the template %s . Error: %q", pathMapping.SrcPath, err)
+	}
+	kinds := []string{}
+	for _, doc := range strings.Split(rendered, "\n---\n") {
+		obj := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			t.Fatalf("failed to parse the rendered manifest. Error: %q\n%s", err, doc)
+		}
+		kinds = append(kinds, obj["kind"].(string))
+	}
+	return kinds
+}
+
+func TestFluentBitTransform(t *testing.T) {
+	t.Run("the daemon set is generated by default", func(t *testing.T) {
+		qaengine.Reset()
+		defer qaengine.Reset()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		fb := newFluentBitTestTransformer(t)
+		pathMappings, _, err := fb.Transform([]transformertypes.Artifact{newFluentBitTestArtifact()}, nil)
+		if err != nil {
+			t.Fatalf("failed to transform. Error: %q", err)
+		}
+		if len(pathMappings) != 1 {
+			t.Fatalf("expected a single path mapping. Actual: %+v", pathMappings)
+		}
+		pathMapping := pathMappings[0]
+		if pathMapping.Type != transformertypes.TemplatePathMappingType || pathMapping.DestPath != filepath.Join(defaultFluentBitOutputPath, "fluent-bit.yaml") {
+			t.Fatalf("expected the fluent-bit.yaml template in %s . Actual: %+v", defaultFluentBitOutputPath, pathMapping)
+		}
+		tc := pathMapping.TemplateConfig.(FluentBitTemplateConfig)
+		want := FluentBitTemplateConfig{
+			AppName:      "myproject",
+			Namespace:    "logging",
+			Image:        fluentBitImage,
+			ServiceNames: []string{"api", "web-app"},
+			Output:       "es",
+			OutputName:   "Elasticsearch",
+			OutputHost:   "elasticsearch.logging.svc",
+			OutputPort:   9200,
+		}
+		if !cmp.Equal(tc, want) {
+			t.Fatalf("the template config is different. Differences: %s", cmp.Diff(want, tc))
+		}
+		kinds := renderFluentBitManifests(t, pathMapping)
+		for _, kind := range []string{"Namespace", "ConfigMap", "DaemonSet"} {
+			if !common.IsPresent(kinds, kind) {
+				t.Fatalf("expected a %s in the manifests. Actual: %v", kind, kinds)
+			}
+		}
+	})
+
+	t.Run("the logging operator resources are generated for the selected output", func(t *testing.T) {
+		qaengine.Reset()
+		defer qaengine.Reset()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{
+			common.ConfigFluentBitModeKey + `="` + loggingOperatorMode + `"`,
+			common.ConfigFluentBitOutputKey + `="Loki"`,
+			common.ConfigFluentBitOutputHostKey + `="loki.example.com"`,
+			common.ConfigFluentBitOutputPortKey + `="3101"`,
+		}, nil, nil, false)
+		fb := newFluentBitTestTransformer(t)
+		pathMappings, _, err := fb.Transform([]transformertypes.Artifact{newFluentBitTestArtifact()}, nil)
+		if err != nil {
+			t.Fatalf("failed to transform. Error: %q", err)
+		}
+		if len(pathMappings) != 1 || filepath.Base(pathMappings[0].SrcPath) != "logging-operator.yaml" {
+			t.Fatalf("expected the logging-operator.yaml template. Actual: %+v", pathMappings)
+		}
+		tc := pathMappings[0].TemplateConfig.(FluentBitTemplateConfig)
+		if tc.Output != "loki" || tc.OutputHost != "loki.example.com" || tc.OutputPort != 3101 || tc.Namespace != "" {
+			t.Fatalf("expected the configured Loki output without a namespace. Actual: %+v", tc)
+		}
+		if kinds := renderFluentBitManifests(t, pathMappings[0]); !cmp.Equal(kinds, []string{"Output", "Flow"}) {
+			t.Fatalf("expected an Output and a Flow. Actual: %v", kinds)
+		}
+	})
+
+	t.Run("nothing is generated without services", func(t *testing.T) {
+		qaengine.Reset()
+		defer qaengine.Reset()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		fb := newFluentBitTestTransformer(t)
+		pathMappings, _, err := fb.Transform([]transformertypes.Artifact{{Type: irtypes.IRArtifactType, Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: irtypes.NewIR()}}}, nil)
+		if err != nil || len(pathMappings) != 0 {
+			t.Fatalf("expected no path mappings. Actual: %+v Error: %v", pathMappings, err)
+		}
+	})
+}
@@ -0,0 +1,159 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	noConfigRollout       = "None"
+	checksumConfigRollout = "Checksum"
+	reloaderConfigRollout = "Reloader"
+	// configChecksumAnnotation changes whenever the referenced config maps and secrets change, so the pods are replaced
+	configChecksumAnnotation = "checksum/config"
+	// reloaderAutoAnnotation makes the Stakater Reloader controller restart the pods when the referenced config maps and secrets change
+	reloaderAutoAnnotation = "reloader.stakater.com/auto"
+)
+
+// configRolloutPreprocessor annotates the workloads so that changes to the config maps and secrets they use trigger a rollout,
+// similar to restaging a Cloud Foundry app after changing its environment
+type configRolloutPreprocessor struct {
+}
+
+func (p configRolloutPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	storages := map[string]irtypes.Storage{}
+	for _, storage := range ir.Storages {
+		if storage.StorageType == irtypes.ConfigMapKind || storage.StorageType == irtypes.SecretKind {
+			storages[storage.Name] = storage
+		}
+	}
+	serviceStorages := map[string][]string{}
+	for serviceName, service := range ir.Services {
+		names := []string{}
+		for _, name := range getReferencedStorageNames(core.PodSpec(service.PodSpec)) {
+			if _, ok := storages[name]; ok {
+				names = append(names, name)
+			}
+		}
+		if len(names) > 0 {
+			serviceStorages[serviceName] = names
+		}
+	}
+	if len(serviceStorages) == 0 {
+		return ir, nil
+	}
+	rollout := qaengine.FetchSelectAnswer(
+		common.ConfigConfigRolloutKey,
+		"Select how changes to the config maps and secrets should roll out the workloads using them :",
+		[]string{
+			"Checksum: The pod templates are annotated with a checksum of the config maps and secrets, so applying changed manifests restarts the pods",
+			"Reloader: The workloads are annotated for the Stakater Reloader controller, which must be installed in the cluster",
+		},
+		noConfigRollout,
+		[]string{noConfigRollout, checksumConfigRollout, reloaderConfigRollout},
+		nil,
+	)
+	if rollout == noConfigRollout {
+		return ir, nil
+	}
+	for serviceName, names := range serviceStorages {
+		service := ir.Services[serviceName]
+		if service.Annotations == nil {
+			service.Annotations = map[string]string{}
+		}
+		switch rollout {
+		case checksumConfigRollout:
+			service.Annotations[configChecksumAnnotation] = getStoragesChecksum(storages, names)
+		case reloaderConfigRollout:
+			service.Annotations[reloaderAutoAnnotation] = "true"
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getReferencedStorageNames returns the sorted names of the config maps and secrets used in the environment variables and volumes of the pod
+func getReferencedStorageNames(podSpec core.PodSpec) []string {
+	names := []string{}
+	for _, container := range append(podSpec.InitContainers, podSpec.Containers...) {
+		for _, envFrom := range container.EnvFrom {
+			if envFrom.ConfigMapRef != nil {
+				names = common.AppendIfNotPresent(names, envFrom.ConfigMapRef.Name)
+			}
+			if envFrom.SecretRef != nil {
+				names = common.AppendIfNotPresent(names, envFrom.SecretRef.Name)
+			}
+		}
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if env.ValueFrom.ConfigMapKeyRef != nil {
+				names = common.AppendIfNotPresent(names, env.ValueFrom.ConfigMapKeyRef.Name)
+			}
+			if env.ValueFrom.SecretKeyRef != nil {
+				names = common.AppendIfNotPresent(names, env.ValueFrom.SecretKeyRef.Name)
+			}
+		}
+	}
+	for _, volume := range podSpec.Volumes {
+		if volume.ConfigMap != nil {
+			names = common.AppendIfNotPresent(names, volume.ConfigMap.Name)
+		}
+		if volume.Secret != nil {
+			names = common.AppendIfNotPresent(names, volume.Secret.SecretName)
+		}
+		if volume.Projected != nil {
+			for _, source := range volume.Projected.Sources {
+				if source.ConfigMap != nil {
+					names = common.AppendIfNotPresent(names, source.ConfigMap.Name)
+				}
+				if source.Secret != nil {
+					names = common.AppendIfNotPresent(names, source.Secret.Name)
+				}
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// getStoragesChecksum returns the sha256 checksum of the contents of the storages, in the order of their names and keys
+func getStoragesChecksum(storages map[string]irtypes.Storage, names []string) string {
+	hash := sha256.New()
+	for _, name := range names {
+		storage := storages[name]
+		keys := []string{}
+		for key := range storage.Content {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		fmt.Fprintf(hash, "%s/%s\n", storage.StorageType, name)
+		for _, key := range keys {
+			fmt.Fprintf(hash, "%s=%x\n", key, storage.Content[key])
+		}
+	}
+	return fmt.Sprintf("%x", hash.Sum(nil))
+}
@@ -0,0 +1,114 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestConfigRolloutPreprocessor(t *testing.T) {
+	newIR := func(dbPassword string) irtypes.IR {
+		ir := irtypes.NewIR()
+		ir.Name = "app"
+		ir.Storages = []irtypes.Storage{
+			{Name: "app-config", StorageType: irtypes.ConfigMapKind, Content: map[string][]byte{"LOG_LEVEL": []byte("info")}},
+			{Name: "db", StorageType: irtypes.SecretKind, Content: map[string][]byte{"PASSWORD": []byte(dbPassword)}},
+			{Name: "data", StorageType: irtypes.PVCKind},
+		}
+		api := irtypes.NewServiceWithName("api")
+		api.Containers = []core.Container{{
+			Name:    "api",
+			EnvFrom: []core.EnvFromSource{{ConfigMapRef: &core.ConfigMapEnvSource{LocalObjectReference: core.LocalObjectReference{Name: "app-config"}}}},
+			Env: []core.EnvVar{{Name: "DB_PASSWORD", ValueFrom: &core.EnvVarSource{SecretKeyRef: &core.SecretKeySelector{
+				LocalObjectReference: core.LocalObjectReference{Name: "db"}, Key: "PASSWORD",
+			}}}},
+		}}
+		ir.Services["api"] = api
+		worker := irtypes.NewServiceWithName("worker")
+		worker.Containers = []core.Container{{Name: "worker"}}
+		worker.Volumes = []core.Volume{{Name: "data", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}}}
+		ir.Services["worker"] = worker
+		return ir
+	}
+	preprocess := func(t *testing.T, rollout string, dbPassword string) irtypes.IR {
+		t.Helper()
+		qaengine.Reset()
+		t.Cleanup(qaengine.Reset)
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{common.ConfigConfigRolloutKey + `="` + rollout + `"`}, nil, nil, false)
+		ir, err := configRolloutPreprocessor{}.preprocess(newIR(dbPassword))
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if len(ir.Services["worker"].Annotations) != 0 {
+			t.Fatalf("expected no annotations on the service without config maps and secrets, got %+v", ir.Services["worker"].Annotations)
+		}
+		return ir
+	}
+	t.Run("none", func(t *testing.T) {
+		ir := preprocess(t, noConfigRollout, "secret")
+		if len(ir.Services["api"].Annotations) != 0 {
+			t.Fatalf("expected no annotations, got %+v", ir.Services["api"].Annotations)
+		}
+	})
+	t.Run("reloader", func(t *testing.T) {
+		ir := preprocess(t, reloaderConfigRollout, "secret")
+		if diff := cmp.Diff(map[string]string{reloaderAutoAnnotation: "true"}, ir.Services["api"].Annotations); diff != "" {
+			t.Fatalf("got the wrong annotations. Diff (-want +got):\n%s", diff)
+		}
+	})
+	t.Run("checksum", func(t *testing.T) {
+		checksum := preprocess(t, checksumConfigRollout, "secret").Services["api"].Annotations[configChecksumAnnotation]
+		if len(checksum) != 64 {
+			t.Fatalf("expected a sha256 checksum, got %q", checksum)
+		}
+		if got := preprocess(t, checksumConfigRollout, "secret").Services["api"].Annotations[configChecksumAnnotation]; got != checksum {
+			t.Fatalf("expected the checksum %q of the same config to be stable, got %q", checksum, got)
+		}
+		if got := preprocess(t, checksumConfigRollout, "changed").Services["api"].Annotations[configChecksumAnnotation]; got == checksum {
+			t.Fatalf("expected the checksum to change when the secret changes, got %q", got)
+		}
+	})
+}
+
+func TestGetReferencedStorageNames(t *testing.T) {
+	podSpec := core.PodSpec{
+		InitContainers: []core.Container{{EnvFrom: []core.EnvFromSource{{SecretRef: &core.SecretEnvSource{LocalObjectReference: core.LocalObjectReference{Name: "init-secret"}}}}}},
+		Containers: []core.Container{{Env: []core.EnvVar{
+			{Name: "PLAIN", Value: "value"},
+			{Name: "CONFIG", ValueFrom: &core.EnvVarSource{ConfigMapKeyRef: &core.ConfigMapKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "env-config"}}}},
+		}}},
+		Volumes: []core.Volume{
+			{Name: "config", VolumeSource: core.VolumeSource{ConfigMap: &core.ConfigMapVolumeSource{LocalObjectReference: core.LocalObjectReference{Name: "env-config"}}}},
+			{Name: "certs", VolumeSource: core.VolumeSource{Secret: &core.SecretVolumeSource{SecretName: "certs"}}},
+			{Name: "projected", VolumeSource: core.VolumeSource{Projected: &core.ProjectedVolumeSource{Sources: []core.VolumeProjection{
+				{ConfigMap: &core.ConfigMapProjection{LocalObjectReference: core.LocalObjectReference{Name: "projected-config"}}},
+				{Secret: &core.SecretProjection{LocalObjectReference: core.LocalObjectReference{Name: "certs"}}},
+			}}}},
+		},
+	}
+	want := []string{"certs", "env-config", "init-secret", "projected-config"}
+	if diff := cmp.Diff(want, getReferencedStorageNames(podSpec)); diff != "" {
+		t.Fatalf("got the wrong storage names. Diff (-want +got):\n%s", diff)
+	}
+}
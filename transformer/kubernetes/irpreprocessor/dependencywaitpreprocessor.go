@@ -0,0 +1,114 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	dependencyWaitImage           = "busybox:1.36"
+	dependencyWaitContainerPrefix = "wait-for-"
+	dependencyWaitIntervalSeconds = 2
+)
+
+// dependencyWaitPreprocessor adds init containers which wait for the dependencies of a service to be reachable,
+// so that the services of a migrated application do not crash loop while the services they depend on start
+type dependencyWaitPreprocessor struct {
+}
+
+func (p dependencyWaitPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	serviceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if len(service.DependsOn) > 0 {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	if len(serviceNames) == 0 {
+		return ir, nil
+	}
+	if !qaengine.FetchBoolAnswer(
+		common.ConfigDependencyWaitKey,
+		"Add init containers which wait for the services each service depends on to be reachable?",
+		[]string{"The dependencies are taken from depends_on and links in docker compose files and the service bindings of Cloud Foundry apps"},
+		false,
+		nil,
+	) {
+		return ir, nil
+	}
+	sort.Strings(serviceNames)
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		for _, dependency := range service.DependsOn {
+			port, ok := getDependencyPort(ir, dependency)
+			if !ok {
+				logrus.Debugf("not waiting for the dependency %s of the service %s since it is not a service with a port", dependency, serviceName)
+				continue
+			}
+			name := common.MakeStringK8sServiceNameCompliant(dependencyWaitContainerPrefix + dependency)
+			if hasInitContainer(service, name) {
+				continue
+			}
+			service.InitContainers = append(service.InitContainers, core.Container{
+				Name:  name,
+				Image: dependencyWaitImage,
+				Command: []string{"sh", "-c", fmt.Sprintf(
+					"until nc -z -w %d %s %d; do echo waiting for %s; sleep %d; done",
+					dependencyWaitIntervalSeconds, dependency, port, dependency, dependencyWaitIntervalSeconds,
+				)},
+				// the wait only needs a fraction of the default resources of the containers
+				Resources: core.ResourceRequirements{
+					Requests: core.ResourceList{core.ResourceCPU: resource.MustParse("10m"), core.ResourceMemory: resource.MustParse("16Mi")},
+					Limits:   core.ResourceList{core.ResourceCPU: resource.MustParse("100m"), core.ResourceMemory: resource.MustParse("32Mi")},
+				},
+			})
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getDependencyPort returns the first port of the k8s service created for the dependency
+func getDependencyPort(ir irtypes.IR, dependency string) (int32, bool) {
+	service, ok := ir.Services[dependency]
+	if !ok || service.OnlyIngress {
+		return 0, false
+	}
+	for _, forwarding := range service.ServiceToPodPortForwardings {
+		if forwarding.ServicePort.Number != 0 {
+			return forwarding.ServicePort.Number, true
+		}
+	}
+	return 0, false
+}
+
+func hasInitContainer(service irtypes.Service, name string) bool {
+	for _, container := range service.InitContainers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestDependencyWaitPreprocessor(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+
+	ir := irtypes.NewIR()
+	api := irtypes.NewServiceWithName("api")
+	api.DependsOn = []string{"db", "cache", "external"}
+	ir.Services["api"] = api
+	db := irtypes.NewServiceWithName("db")
+	if err := db.AddPortForwarding(networking.ServiceBackendPort{Number: 5432}, networking.ServiceBackendPort{Number: 5432}, ""); err != nil {
+		t.Fatalf("failed to add the port forwarding. Error: %q", err)
+	}
+	ir.Services["db"] = db
+	// the cache has no port, so it cannot be waited for
+	ir.Services["cache"] = irtypes.NewServiceWithName("cache")
+
+	notEnabled, err := dependencyWaitPreprocessor{}.preprocess(ir)
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	if len(notEnabled.Services["api"].InitContainers) != 0 {
+		t.Fatalf("expected no init containers unless they are enabled. Actual: %+v", notEnabled.Services["api"].InitContainers)
+	}
+
+	qaengine.SetupConfigFile("", []string{common.ConfigDependencyWaitKey + "=true"}, nil, nil, false)
+	actual, err := dependencyWaitPreprocessor{}.preprocess(ir)
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	initContainers := actual.Services["api"].InitContainers
+	if len(initContainers) != 1 || initContainers[0].Name != "wait-for-db" {
+		t.Fatalf("expected a single init container waiting for db. Actual: %+v", initContainers)
+	}
+	want := []string{"sh", "-c", "until nc -z -w 2 db 5432; do echo waiting for db; sleep 2; done"}
+	if !cmp.Equal(initContainers[0].Command, want) {
+		t.Fatalf("the command of the init container did not match. Differences:\n%s", cmp.Diff(want, initContainers[0].Command))
+	}
+	if len(actual.Services["db"].InitContainers) != 0 {
+		t.Fatalf("expected no init containers for the service without dependencies. Actual: %+v", actual.Services["db"].InitContainers)
+	}
+
+	again, err := dependencyWaitPreprocessor{}.preprocess(actual)
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR again. Error: %q", err)
+	}
+	if len(again.Services["api"].InitContainers) != 1 {
+		t.Fatalf("expected the init containers to not be added again. Actual: %+v", again.Services["api"].InitContainers)
+	}
+}
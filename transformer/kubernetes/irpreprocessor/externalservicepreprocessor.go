@@ -0,0 +1,180 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// urlHostRegex matches the host of the urls and the connection strings, like jdbc:postgresql://user@db.example.com:5432/orders
+	urlHostRegex = regexp.MustCompile(`[a-zA-Z][a-zA-Z0-9+.-]*://(?:[^@/\s]*@)?([a-zA-Z0-9.-]+)`)
+	// hostNameRegex matches the fully qualified host names. The IP addresses and the single label names of the compose services are ignored.
+	hostNameRegex = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*\.[a-z]([a-z0-9-]*[a-z0-9])?$`)
+	// hostEnvSuffixes are the suffixes of the environment variables which contain a host name, with an optional port
+	hostEnvSuffixes = []string{"HOST", "HOSTNAME", "SERVER", "ADDR", "ADDRESS", "ENDPOINT"}
+	// clusterHostSuffixes are the suffixes of the host names which already resolve inside the cluster
+	clusterHostSuffixes = []string{".svc", ".svc.cluster.local", ".cluster.local"}
+)
+
+// externalServicePreprocessor creates ExternalName services for the dependencies which are not migrated, like the managed databases
+type externalServicePreprocessor struct {
+}
+
+func (p externalServicePreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	hosts := []string{}
+	for _, service := range ir.Services {
+		for _, container := range service.Containers {
+			for _, env := range container.Env {
+				for _, host := range getEnvHosts(env.Name, env.Value) {
+					hosts = common.AppendIfNotPresent(hosts, host)
+				}
+			}
+		}
+	}
+	if len(hosts) == 0 {
+		return ir, nil
+	}
+	sort.Strings(hosts)
+	selectedHosts := qaengine.FetchMultiSelectAnswer(
+		common.ConfigExternalServicesHostsKey,
+		"Select the external dependencies which are not migrated and should be reached through ExternalName services :",
+		[]string{"The host names were found in the environment variables of the services. The ExternalName services give them in-cluster DNS names."},
+		hosts,
+		hosts,
+		nil,
+	)
+	if len(selectedHosts) == 0 {
+		return ir, nil
+	}
+	if ir.ExternalServices == nil {
+		ir.ExternalServices = map[string]irtypes.ExternalService{}
+	}
+	hostServiceNames := map[string]string{}
+	for _, host := range selectedHosts {
+		defaultName := common.MakeStringK8sServiceNameCompliant(strings.Split(host, ".")[0])
+		_, usedByService := ir.Services[defaultName]
+		if externalService, ok := ir.ExternalServices[defaultName]; usedByService || (ok && externalService.ExternalName != host) {
+			defaultName = common.MakeStringK8sServiceNameCompliant(host)
+		}
+		name := qaengine.FetchStringAnswer(
+			common.JoinQASubKeys(common.ConfigExternalServicesKey, `"`+host+`"`, common.ConfigExternalServiceNameKeySuffix),
+			"Provide the name of the ExternalName service for the dependency "+host+" :",
+			[]string{"The applications in the cluster can reach the dependency using this name"},
+			defaultName,
+			qatypes.NewDNSLabelValidator(),
+		)
+		if _, ok := ir.Services[name]; ok {
+			logrus.Errorf("The name %s of the ExternalName service for the dependency %s is used by a service. Skipping the dependency.", name, host)
+			continue
+		}
+		ir.ExternalServices[name] = irtypes.ExternalService{Name: name, ExternalName: host}
+		hostServiceNames[host] = name
+	}
+	if len(hostServiceNames) == 0 || !qaengine.FetchBoolAnswer(
+		common.ConfigExternalServicesRewriteEnvKey,
+		"Replace the host names of the external dependencies in the environment variables with the names of the ExternalName services?",
+		[]string{"The HTTPS urls keep the original host names, since the TLS certificates of the dependencies are issued for them"},
+		true,
+		nil,
+	) {
+		return ir, nil
+	}
+	for serviceName, service := range ir.Services {
+		for _, container := range service.Containers {
+			for i, env := range container.Env {
+				for host, name := range hostServiceNames {
+					container.Env[i].Value = replaceHost(container.Env[i].Value, host, name)
+				}
+				if container.Env[i].Value != env.Value {
+					logrus.Debugf("Replaced the host names in the environment variable %s of the service %s", env.Name, serviceName)
+				}
+			}
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getEnvHosts returns the host names of the dependencies outside the cluster in the value of the environment variable
+func getEnvHosts(envName, envValue string) []string {
+	if envValue == "" || strings.Contains(envValue, "$") {
+		return nil
+	}
+	candidates := []string{}
+	for _, match := range urlHostRegex.FindAllStringSubmatch(envValue, -1) {
+		candidates = append(candidates, match[1])
+	}
+	if len(candidates) == 0 {
+		for _, suffix := range hostEnvSuffixes {
+			if strings.HasSuffix(strings.ToUpper(envName), suffix) {
+				candidates = append(candidates, strings.Split(envValue, ":")[0])
+				break
+			}
+		}
+	}
+	hosts := []string{}
+	for _, candidate := range candidates {
+		host := strings.ToLower(strings.TrimSuffix(candidate, "."))
+		if !hostNameRegex.MatchString(host) || isClusterHost(host) {
+			continue
+		}
+		hosts = common.AppendIfNotPresent(hosts, host)
+	}
+	return hosts
+}
+
+// isClusterHost returns true if the host name already resolves inside the cluster
+func isClusterHost(host string) bool {
+	for _, suffix := range clusterHostSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceHost replaces the host name in the value, without touching the longer host names containing it, the schemes of the urls and the HTTPS urls
+func replaceHost(value, host, name string) string {
+	if strings.Contains(strings.ToLower(value), "https://"+host) {
+		return value
+	}
+	hostRegex := regexp.MustCompile(`(?i)(^|[^a-z0-9.-])` + regexp.QuoteMeta(host) + `($|[^a-z0-9.-])`)
+	replaced := strings.Builder{}
+	last := 0
+	for _, match := range hostRegex.FindAllStringSubmatchIndex(value, -1) {
+		// the host is between the characters before and after it
+		hostStart, hostEnd := match[3], match[4]
+		if strings.HasPrefix(value[hostEnd:], "://") {
+			// a service named like the scheme, as in postgres://db:5432
+			continue
+		}
+		replaced.WriteString(value[last:hostStart])
+		replaced.WriteString(name)
+		last = hostEnd
+	}
+	replaced.WriteString(value[last:])
+	return replaced.String()
+}
@@ -0,0 +1,154 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestExternalServicePreprocessor(t *testing.T) {
+	newIR := func() irtypes.IR {
+		ir := irtypes.NewIR()
+		api := irtypes.NewServiceWithName("api")
+		api.Containers = []core.Container{{Name: "api", Image: "api:latest", Env: []core.EnvVar{
+			{Name: "DATABASE_URL", Value: "jdbc:postgresql://user@orders.db.example.com:5432/orders"},
+			{Name: "PAYMENTS_URL", Value: "https://payments.example.com/v1"},
+			{Name: "CACHE_HOST", Value: "cache.example.com:6379"},
+			{Name: "WORKER_HOST", Value: "worker"},
+			{Name: "AUTH_HOST", Value: "auth.default.svc.cluster.local"},
+		}}}
+		ir.Services["api"] = api
+		cache := irtypes.NewServiceWithName("cache")
+		cache.Containers = []core.Container{{Name: "cache", Image: "cache:latest", Env: []core.EnvVar{{Name: "UPSTREAM", Value: "tcp://orders.db.example.com:5432"}}}}
+		ir.Services["cache"] = cache
+		return ir
+	}
+	setup := func(t *testing.T, configs ...string) {
+		t.Helper()
+		qaengine.Reset()
+		t.Cleanup(qaengine.Reset)
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", configs, nil, nil, false)
+	}
+
+	t.Run("the hosts of the environment variables get ExternalName services", func(t *testing.T) {
+		setup(t)
+		actual, err := externalServicePreprocessor{}.preprocess(newIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		want := map[string]irtypes.ExternalService{
+			"orders":            {Name: "orders", ExternalName: "orders.db.example.com"},
+			"payments":          {Name: "payments", ExternalName: "payments.example.com"},
+			"cache-example-com": {Name: "cache-example-com", ExternalName: "cache.example.com"},
+		}
+		if !cmp.Equal(actual.ExternalServices, want) {
+			t.Fatalf("the external services differ. Differences:\n%s", cmp.Diff(want, actual.ExternalServices))
+		}
+		wantEnv := []core.EnvVar{
+			{Name: "DATABASE_URL", Value: "jdbc:postgresql://user@orders:5432/orders"},
+			{Name: "PAYMENTS_URL", Value: "https://payments.example.com/v1"},
+			{Name: "CACHE_HOST", Value: "cache-example-com:6379"},
+			{Name: "WORKER_HOST", Value: "worker"},
+			{Name: "AUTH_HOST", Value: "auth.default.svc.cluster.local"},
+		}
+		if env := actual.Services["api"].Containers[0].Env; !cmp.Equal(env, wantEnv) {
+			t.Fatalf("the environment variables differ. Differences:\n%s", cmp.Diff(wantEnv, env))
+		}
+		if env := actual.Services["cache"].Containers[0].Env[0].Value; env != "tcp://orders:5432" {
+			t.Fatalf("expected the host to be replaced in all the services. Actual: %s", env)
+		}
+	})
+
+	t.Run("only the selected hosts are kept and the environment variables can be left as is", func(t *testing.T) {
+		setup(t,
+			common.ConfigExternalServicesHostsKey+`=["orders.db.example.com"]`,
+			common.JoinQASubKeys(common.ConfigExternalServicesKey, `"orders.db.example.com"`, common.ConfigExternalServiceNameKeySuffix)+`="orders-db"`,
+			common.ConfigExternalServicesRewriteEnvKey+`=false`,
+		)
+		actual, err := externalServicePreprocessor{}.preprocess(newIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		want := map[string]irtypes.ExternalService{"orders-db": {Name: "orders-db", ExternalName: "orders.db.example.com"}}
+		if !cmp.Equal(actual.ExternalServices, want) {
+			t.Fatalf("the external services differ. Differences:\n%s", cmp.Diff(want, actual.ExternalServices))
+		}
+		if env := actual.Services["api"].Containers[0].Env[0].Value; env != "jdbc:postgresql://user@orders.db.example.com:5432/orders" {
+			t.Fatalf("expected the environment variables to be unchanged. Actual: %s", env)
+		}
+	})
+
+	t.Run("a name used by a service is skipped", func(t *testing.T) {
+		setup(t,
+			common.ConfigExternalServicesHostsKey+`=["orders.db.example.com"]`,
+			common.JoinQASubKeys(common.ConfigExternalServicesKey, `"orders.db.example.com"`, common.ConfigExternalServiceNameKeySuffix)+`="api"`,
+		)
+		actual, err := externalServicePreprocessor{}.preprocess(newIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if len(actual.ExternalServices) != 0 {
+			t.Fatalf("expected no external services. Actual: %+v", actual.ExternalServices)
+		}
+		if env := actual.Services["api"].Containers[0].Env[0].Value; env != "jdbc:postgresql://user@orders.db.example.com:5432/orders" {
+			t.Fatalf("expected the environment variables to be unchanged. Actual: %s", env)
+		}
+	})
+}
+
+func TestGetEnvHosts(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{name: "DATABASE_URL", value: "mongodb://a.example.com:27017,b.example.com:27017/db", want: []string{"a.example.com"}},
+		{name: "REDIS_HOST", value: "Redis.Example.com.:6379", want: []string{"redis.example.com"}},
+		{name: "REDIS_HOST", value: "10.0.0.5", want: []string{}},
+		{name: "DB_HOST", value: "${DB_HOST}", want: nil},
+		{name: "DB_NAME", value: "orders.example.com", want: []string{}},
+		{name: "SERVICE_URL", value: "http://orders.prod.svc:8080", want: []string{}},
+	}
+	for _, testCase := range testCases {
+		if got := getEnvHosts(testCase.name, testCase.value); !cmp.Equal(got, testCase.want) {
+			t.Errorf("the hosts of %s=%s differ. Differences:\n%s", testCase.name, testCase.value, cmp.Diff(testCase.want, got))
+		}
+	}
+}
+
+func TestReplaceHost(t *testing.T) {
+	testCases := map[string]string{
+		"db.example.com:5432":              "orders:5432",
+		"postgres://db.example.com/orders": "postgres://orders/orders",
+		"https://db.example.com/orders":    "https://db.example.com/orders",
+		"replica.db.example.com:5432":      "replica.db.example.com:5432",
+		"db.example.community:5432":        "db.example.community:5432",
+		"db.example.com://db.example.com":  "db.example.com://orders",
+	}
+	for value, want := range testCases {
+		if got := replaceHost(value, "db.example.com", "orders"); got != want {
+			t.Errorf("got %s for %s , want %s", got, value, want)
+		}
+	}
+}
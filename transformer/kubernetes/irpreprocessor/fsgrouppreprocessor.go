@@ -0,0 +1,149 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/spf13/cast"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// fsGroupPreprocessor sets the group owning the volumes of the services running as a non root user, so that they can read and write their data
+type fsGroupPreprocessor struct {
+}
+
+func (p fsGroupPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	for serviceName, service := range ir.Services {
+		if service.SecurityContext != nil && service.SecurityContext.FSGroup != nil {
+			continue
+		}
+		hasClaims, ownedVolumes, otherVolumes := getMountedVolumeKinds(service)
+		if !ownedVolumes && !otherVolumes {
+			continue
+		}
+		uid, gid, ok := getNonRootUser(ir, service)
+		if !ok {
+			continue
+		}
+		if gid <= 0 {
+			gid = uid
+		}
+		rangeValidator := qatypes.NewRangeValidator(0, math.MaxInt32)
+		fsGroupStr := strings.TrimSpace(qaengine.FetchStringAnswer(
+			common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigFSGroupKeySuffix),
+			fmt.Sprintf("Enter the group which should own the volumes mounted by the service %s :", serviceName),
+			[]string{
+				fmt.Sprintf("The service runs as the user %d, which can not read or write the volumes owned by root", uid),
+				"Leave it empty to keep the ownership of the volumes as is",
+			},
+			cast.ToString(gid),
+			func(ans interface{}) error {
+				if strings.TrimSpace(cast.ToString(ans)) == "" {
+					return nil
+				}
+				return rangeValidator(ans)
+			},
+		))
+		if fsGroupStr == "" {
+			continue
+		}
+		fsGroup := cast.ToInt64(fsGroupStr)
+		if service.SecurityContext == nil {
+			service.SecurityContext = &core.PodSecurityContext{}
+		}
+		if ownedVolumes {
+			service.SecurityContext.FSGroup = &fsGroup
+			if hasClaims {
+				// changing the ownership of all the files on every start is slow for the large volumes
+				changePolicy := core.FSGroupChangeOnRootMismatch
+				service.SecurityContext.FSGroupChangePolicy = &changePolicy
+			}
+		}
+		// the ownership of the host paths and the nfs shares is not changed by the kubelet, the group has to be given to the processes
+		if otherVolumes && !common.IsPresent(service.SecurityContext.SupplementalGroups, fsGroup) {
+			service.SecurityContext.SupplementalGroups = append(service.SecurityContext.SupplementalGroups, fsGroup)
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getMountedVolumeKinds returns whether the volumes mounted by the containers include claims,
+// volumes whose ownership is changed by the kubelet to the fsGroup and volumes whose ownership is not changed
+func getMountedVolumeKinds(service irtypes.Service) (hasClaims bool, ownedVolumes bool, otherVolumes bool) {
+	mountedVolumes := map[string]bool{}
+	for _, container := range append(append([]core.Container{}, service.InitContainers...), service.Containers...) {
+		for _, volumeMount := range container.VolumeMounts {
+			mountedVolumes[volumeMount.Name] = true
+		}
+	}
+	for _, volume := range service.Volumes {
+		if !mountedVolumes[volume.Name] {
+			continue
+		}
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			hasClaims = true
+			ownedVolumes = true
+		case volume.Secret != nil, volume.ConfigMap != nil, volume.Projected != nil:
+			ownedVolumes = true
+		case volume.HostPath != nil, volume.NFS != nil:
+			otherVolumes = true
+		}
+	}
+	return hasClaims, ownedVolumes, otherVolumes
+}
+
+// getNonRootUser returns the user and the group, if known, the containers of the service run as.
+// The user set in the security context wins over the user of the image.
+func getNonRootUser(ir irtypes.IR, service irtypes.Service) (uid int64, gid int64, ok bool) {
+	for _, container := range service.Containers {
+		var containerUID, containerGID int64 = -1, -1
+		if service.SecurityContext != nil {
+			if service.SecurityContext.RunAsUser != nil {
+				containerUID = *service.SecurityContext.RunAsUser
+			}
+			if service.SecurityContext.RunAsGroup != nil {
+				containerGID = *service.SecurityContext.RunAsGroup
+			}
+		}
+		if container.SecurityContext != nil {
+			if container.SecurityContext.RunAsUser != nil {
+				containerUID = *container.SecurityContext.RunAsUser
+			}
+			if container.SecurityContext.RunAsGroup != nil {
+				containerGID = *container.SecurityContext.RunAsGroup
+			}
+		}
+		if containerUID < 0 {
+			if image, found := ir.ContainerImages[container.Image]; found {
+				containerUID = int64(image.UserID)
+			}
+		}
+		if containerUID > 0 && !ok {
+			uid, gid, ok = containerUID, containerGID, true
+		}
+	}
+	return uid, gid, ok
+}
@@ -0,0 +1,134 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func getFSGroupTestIR() irtypes.IR {
+	ir := irtypes.NewIR()
+	image := irtypes.NewContainer()
+	image.UserID = 1001
+	ir.ContainerImages["app:latest"] = image
+	rootImage := irtypes.NewContainer()
+	rootImage.UserID = 0
+	ir.ContainerImages["root:latest"] = rootImage
+	newService := func(name, image string, volumes ...core.Volume) irtypes.Service {
+		service := irtypes.NewServiceWithName(name)
+		service.Volumes = volumes
+		container := core.Container{Name: name, Image: image}
+		for _, volume := range volumes {
+			container.VolumeMounts = append(container.VolumeMounts, core.VolumeMount{Name: volume.Name, MountPath: "/" + volume.Name})
+		}
+		service.Containers = []core.Container{container}
+		return service
+	}
+	claim := core.Volume{Name: "data", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}}
+	secret := core.Volume{Name: "certs", VolumeSource: core.VolumeSource{Secret: &core.SecretVolumeSource{SecretName: "certs"}}}
+	hostPath := core.Volume{Name: "logs", VolumeSource: core.VolumeSource{HostPath: &core.HostPathVolumeSource{Path: "/var/log"}}}
+	ir.Services["db"] = newService("db", "app:latest", claim)
+	ir.Services["web"] = newService("web", "app:latest", secret, hostPath)
+	ir.Services["root"] = newService("root", "root:latest", claim)
+	ir.Services["novolumes"] = newService("novolumes", "app:latest")
+	unmounted := newService("unmounted", "app:latest")
+	unmounted.Volumes = []core.Volume{claim}
+	ir.Services["unmounted"] = unmounted
+	// the user and the group in the security context win over the user of the image
+	worker := newService("worker", "root:latest", hostPath)
+	uid, gid := int64(2000), int64(3000)
+	worker.SecurityContext = &core.PodSecurityContext{RunAsUser: &uid, RunAsGroup: &gid, SupplementalGroups: []int64{3000}}
+	ir.Services["worker"] = worker
+	return ir
+}
+
+func TestFSGroupPreprocessor(t *testing.T) {
+	setup := func(t *testing.T, configs ...string) {
+		t.Helper()
+		qaengine.Reset()
+		t.Cleanup(qaengine.Reset)
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", configs, nil, nil, false)
+	}
+	int64Ptr := func(value int64) *int64 { return &value }
+	onRootMismatch := core.FSGroupChangeOnRootMismatch
+
+	t.Run("the group of the user owns the volumes by default", func(t *testing.T) {
+		setup(t)
+		ir, err := fsGroupPreprocessor{}.preprocess(getFSGroupTestIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		want := map[string]*core.PodSecurityContext{
+			"db":  {FSGroup: int64Ptr(1001), FSGroupChangePolicy: &onRootMismatch},
+			"web": {FSGroup: int64Ptr(1001), SupplementalGroups: []int64{1001}},
+			"worker": {
+				RunAsUser:          int64Ptr(2000),
+				RunAsGroup:         int64Ptr(3000),
+				SupplementalGroups: []int64{3000},
+			},
+			"root":      nil,
+			"novolumes": nil,
+			"unmounted": nil,
+		}
+		got := map[string]*core.PodSecurityContext{}
+		for name, service := range ir.Services {
+			got[name] = service.SecurityContext
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("the security contexts are not correct. Diff (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("the group can be changed or left out", func(t *testing.T) {
+		setup(t,
+			common.JoinQASubKeys(common.ConfigServicesKey, `"db"`, common.ConfigFSGroupKeySuffix)+`="5000"`,
+			common.JoinQASubKeys(common.ConfigServicesKey, `"web"`, common.ConfigFSGroupKeySuffix)+`=""`,
+		)
+		ir, err := fsGroupPreprocessor{}.preprocess(getFSGroupTestIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if securityContext := ir.Services["db"].SecurityContext; securityContext == nil || securityContext.FSGroup == nil || *securityContext.FSGroup != 5000 {
+			t.Errorf("expected the fsGroup 5000 for the db, got %+v", securityContext)
+		}
+		if securityContext := ir.Services["web"].SecurityContext; securityContext != nil {
+			t.Errorf("expected the ownership of the volumes of web to be kept as is, got %+v", securityContext)
+		}
+	})
+
+	t.Run("the fsGroup set in the source is kept", func(t *testing.T) {
+		setup(t)
+		ir := getFSGroupTestIR()
+		db := ir.Services["db"]
+		db.SecurityContext = &core.PodSecurityContext{FSGroup: int64Ptr(42)}
+		ir.Services["db"] = db
+		ir, err := fsGroupPreprocessor{}.preprocess(ir)
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if diff := cmp.Diff(&core.PodSecurityContext{FSGroup: int64Ptr(42)}, ir.Services["db"].SecurityContext); diff != "" {
+			t.Errorf("the security context of the db was changed. Diff (-want +got):\n%s", diff)
+		}
+	})
+}
@@ -0,0 +1,182 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	grpcPortNamePrefix = "grpc"
+	// h2cPortName is the name of the container ports serving HTTP/2 over cleartext, used by knative and the service meshes
+	h2cPortName = "h2c"
+)
+
+var (
+	// grpcFrameworkMarkers are the gRPC libraries in the dependency files of the commonly used languages
+	grpcFrameworkMarkers = []string{"io.grpc", "grpc-spring-boot-starter", "grpc-server-spring-boot-starter", "google.golang.org/grpc", "@grpc/grpc-js", "grpcio", "Grpc.AspNetCore", "grpc-tools"}
+	// grpcPorts are the default ports of the gRPC servers and frameworks
+	grpcPorts            = []int32{50051, 6565, 9090}
+	grpcDependencyFiles  = []string{"pom.xml", "build.gradle", "build.gradle.kts", "package.json", "go.mod", "requirements.txt", "Pipfile", "pyproject.toml"}
+	grpcDependencyExts   = []string{".csproj"}
+	grpcSkippedDirs      = []string{".git", "node_modules", "vendor", "target", "build", "bin", "obj"}
+	grpcBuildContextUses = map[string]bool{}
+)
+
+// grpcPreprocessor names the ports of the gRPC services, so that the services, the ingress and the service meshes use HTTP/2 to reach them
+type grpcPreprocessor struct {
+}
+
+func (p grpcPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	options := []string{}
+	detected := []string{}
+	for serviceName, service := range ir.Services {
+		usesGRPC := serviceUsesGRPC(service, ir.ContainerImages)
+		for _, forwarding := range service.ServiceToPodPortForwardings {
+			if forwarding.ServicePort.Number == 0 || forwarding.GetProtocol() != core.ProtocolTCP {
+				continue
+			}
+			option := fmt.Sprintf("%s:%d", serviceName, forwarding.ServicePort.Number)
+			options = append(options, option)
+			// The other conventional ports are common for the metrics and the admin endpoints too, so they are used only along with the libraries
+			if strings.HasPrefix(strings.ToLower(forwarding.ServicePort.Name), grpcPortNamePrefix) ||
+				(common.IsPresent(grpcPorts, forwarding.ServicePort.Number) && (usesGRPC || forwarding.ServicePort.Number == grpcPorts[0])) ||
+				(usesGRPC && len(service.ServiceToPodPortForwardings) == 1) {
+				detected = append(detected, option)
+			}
+		}
+	}
+	if len(detected) == 0 {
+		return ir, nil
+	}
+	sort.Strings(options)
+	sort.Strings(detected)
+	selected := qaengine.FetchMultiSelectAnswer(
+		common.ConfigGRPCPortsKey,
+		"Select the ports of the services serving gRPC :",
+		[]string{"gRPC was detected from the proto files, the gRPC libraries and the port conventions. The ports are named for HTTP/2 so the ingress and the service mesh can route gRPC traffic."},
+		detected,
+		options,
+		nil,
+	)
+	for serviceName, service := range ir.Services {
+		grpcForwardings := 0
+		for _, forwarding := range service.ServiceToPodPortForwardings {
+			if forwarding.GetProtocol() == core.ProtocolTCP && common.IsPresent(selected, fmt.Sprintf("%s:%d", serviceName, forwarding.ServicePort.Number)) {
+				grpcForwardings++
+			}
+		}
+		for i, forwarding := range service.ServiceToPodPortForwardings {
+			if forwarding.GetProtocol() != core.ProtocolTCP || !common.IsPresent(selected, fmt.Sprintf("%s:%d", serviceName, forwarding.ServicePort.Number)) {
+				continue
+			}
+			servicePortName, containerPortName := grpcPortNamePrefix, h2cPortName
+			if grpcForwardings > 1 {
+				servicePortName = fmt.Sprintf("%s-%d", grpcPortNamePrefix, forwarding.ServicePort.Number)
+				containerPortName = fmt.Sprintf("%s-%d", h2cPortName, forwarding.PodPort.Number)
+			}
+			if !strings.HasPrefix(strings.ToLower(forwarding.ServicePort.Name), grpcPortNamePrefix) {
+				logrus.Debugf("Naming the port %d of the service %s as %s for gRPC", forwarding.ServicePort.Number, serviceName, servicePortName)
+				service.ServiceToPodPortForwardings[i].ServicePort.Name = servicePortName
+			}
+			if forwarding.PodPort.Number == 0 {
+				continue
+			}
+			for _, container := range service.Containers {
+				for j, port := range container.Ports {
+					if port.ContainerPort == forwarding.PodPort.Number && getContainerPortProtocol(port) == core.ProtocolTCP && port.Name == "" {
+						container.Ports[j].Name = containerPortName
+					}
+				}
+			}
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// serviceUsesGRPC returns true if the build context of a container of the service has proto files or depends on a gRPC library
+func serviceUsesGRPC(service irtypes.Service, containerImages map[string]irtypes.ContainerImage) bool {
+	for _, container := range service.Containers {
+		for imageName, containerImage := range containerImages {
+			if containerImage.Build.ContextPath == "" || (container.Image != imageName && !strings.HasSuffix(container.Image, "/"+imageName)) {
+				continue
+			}
+			if buildContextUsesGRPC(containerImage.Build.ContextPath) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// buildContextUsesGRPC walks the build context for proto files and gRPC libraries in the dependency files.
+// The results are cached, since the preprocessors run for each of the transformers.
+func buildContextUsesGRPC(dir string) bool {
+	if usesGRPC, ok := grpcBuildContextUses[dir]; ok {
+		return usesGRPC
+	}
+	usesGRPC := false
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || usesGRPC {
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && common.IsPresent(grpcSkippedDirs, d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(d.Name()) == ".proto" {
+			logrus.Debugf("Found the proto file %s", path)
+			usesGRPC = true
+			return nil
+		}
+		if !common.IsPresent(grpcDependencyFiles, d.Name()) && !common.IsPresent(grpcDependencyExts, filepath.Ext(d.Name())) {
+			return nil
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			logrus.Debugf("Unable to read the file at path %s . Error: %q", path, err)
+			return nil
+		}
+		for _, marker := range grpcFrameworkMarkers {
+			if strings.Contains(string(contents), marker) {
+				logrus.Debugf("Found the gRPC library %s in the file %s", marker, path)
+				usesGRPC = true
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Debugf("Unable to detect the use of gRPC in the directory %s . Error: %q", dir, err)
+	}
+	grpcBuildContextUses[dir] = usesGRPC
+	return usesGRPC
+}
@@ -0,0 +1,151 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func newGRPCTestService(t *testing.T, name string, ports ...int32) irtypes.Service {
+	t.Helper()
+	service := irtypes.NewServiceWithName(name)
+	container := core.Container{Name: name, Image: name + ":latest"}
+	for _, port := range ports {
+		if err := service.AddPortForwarding(networking.ServiceBackendPort{Number: port}, networking.ServiceBackendPort{Number: port}, ""); err != nil {
+			t.Fatalf("failed to add the port forwarding. Error: %q", err)
+		}
+		container.Ports = append(container.Ports, core.ContainerPort{ContainerPort: port})
+	}
+	service.Containers = []core.Container{container}
+	return service
+}
+
+func getPortNames(service irtypes.Service) (servicePortNames, containerPortNames []string) {
+	for _, forwarding := range service.ServiceToPodPortForwardings {
+		servicePortNames = append(servicePortNames, forwarding.ServicePort.Name)
+	}
+	for _, port := range service.Containers[0].Ports {
+		containerPortNames = append(containerPortNames, port.Name)
+	}
+	return servicePortNames, containerPortNames
+}
+
+func TestGRPCPreprocessor(t *testing.T) {
+	setup := func(t *testing.T, configs ...string) {
+		t.Helper()
+		qaengine.Reset()
+		t.Cleanup(qaengine.Reset)
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", configs, nil, nil, false)
+	}
+
+	t.Run("the ports are detected from the conventions and the build context", func(t *testing.T) {
+		setup(t)
+		contextPath := t.TempDir()
+		if err := os.WriteFile(filepath.Join(contextPath, "go.mod"), []byte("module orders\n\nrequire google.golang.org/grpc v1.50.0\n"), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the go.mod file. Error: %q", err)
+		}
+		ir := irtypes.NewIR()
+		ir.Services["web"] = newGRPCTestService(t, "web", 8080, 9090)
+		ir.Services["greeter"] = newGRPCTestService(t, "greeter", 50051)
+		ir.Services["orders"] = newGRPCTestService(t, "orders", 9090, 8081)
+		ir.ContainerImages["orders:latest"] = irtypes.ContainerImage{Build: irtypes.ContainerBuild{ContextPath: contextPath}}
+
+		actual, err := grpcPreprocessor{}.preprocess(ir)
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		for name, want := range map[string][2][]string{
+			// the port 9090 is also used for the metrics, so it is gRPC only along with the libraries
+			"web":     {{"", ""}, {"", ""}},
+			"greeter": {{grpcPortNamePrefix}, {h2cPortName}},
+			"orders":  {{grpcPortNamePrefix, ""}, {h2cPortName, ""}},
+		} {
+			servicePortNames, containerPortNames := getPortNames(actual.Services[name])
+			if !cmp.Equal(servicePortNames, want[0]) || !cmp.Equal(containerPortNames, want[1]) {
+				t.Errorf("got the service ports %q and the container ports %q for the service %s , want %q and %q", servicePortNames, containerPortNames, name, want[0], want[1])
+			}
+		}
+	})
+
+	t.Run("the selected ports of a service are numbered", func(t *testing.T) {
+		setup(t, common.ConfigGRPCPortsKey+`=["api:50051","api:6565"]`)
+		ir := irtypes.NewIR()
+		ir.Services["api"] = newGRPCTestService(t, "api", 50051, 6565, 8080)
+		actual, err := grpcPreprocessor{}.preprocess(ir)
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		servicePortNames, containerPortNames := getPortNames(actual.Services["api"])
+		if want := []string{"grpc-50051", "grpc-6565", ""}; !cmp.Equal(servicePortNames, want) {
+			t.Fatalf("unexpected service port names. Differences: %s", cmp.Diff(want, servicePortNames))
+		}
+		if want := []string{"h2c-50051", "h2c-6565", ""}; !cmp.Equal(containerPortNames, want) {
+			t.Fatalf("unexpected container port names. Differences: %s", cmp.Diff(want, containerPortNames))
+		}
+	})
+
+	t.Run("the services without gRPC are unchanged", func(t *testing.T) {
+		setup(t)
+		ir := irtypes.NewIR()
+		ir.Services["web"] = newGRPCTestService(t, "web", 8080)
+		want := irtypes.NewIR()
+		want.Services["web"] = newGRPCTestService(t, "web", 8080)
+		actual, err := grpcPreprocessor{}.preprocess(ir)
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if !cmp.Equal(actual, want) {
+			t.Fatalf("expected the IR to be unchanged. Differences: %s", cmp.Diff(want, actual))
+		}
+	})
+}
+
+func TestBuildContextUsesGRPC(t *testing.T) {
+	withProto := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(withProto, "api"), common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the directory. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(withProto, "api", "greeter.proto"), []byte(`syntax = "proto3";`), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the proto file. Error: %q", err)
+	}
+	vendored := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(vendored, "node_modules", "lib"), common.DefaultDirectoryPermission); err != nil {
+		t.Fatalf("failed to create the directory. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(vendored, "node_modules", "lib", "status.proto"), []byte(`syntax = "proto3";`), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the proto file. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(vendored, "package.json"), []byte(`{"dependencies": {"express": "^4.18.0"}}`), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the package.json file. Error: %q", err)
+	}
+	if !buildContextUsesGRPC(withProto) {
+		t.Fatalf("expected the proto file to be detected")
+	}
+	if buildContextUsesGRPC(vendored) {
+		t.Fatalf("expected the proto files of the dependencies to be skipped")
+	}
+}
@@ -0,0 +1,243 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	noHealthProbe      = "none"
+	execHealthProbe    = "exec"
+	sidecarHealthProbe = "sidecar"
+
+	healthSidecarImage           = "busybox:1.36"
+	healthSidecarContainerSuffix = "-health"
+	healthSidecarPath            = "/cgi-bin/healthz"
+	healthSidecarScriptEnv       = "HEALTH_CHECK_SCRIPT"
+	defaultHealthSidecarPort     = 8086
+	// healthProbeInitialDelaySeconds gives the services which start slowly the time to listen, before they are restarted by the liveness probe
+	healthProbeInitialDelaySeconds = 30
+)
+
+// HealthProbeScaffold is a probe added to a service which had no probes, until the service gets a proper health endpoint
+type HealthProbeScaffold struct {
+	Service   string `yaml:"service" json:"service"`
+	Container string `yaml:"container" json:"container"`
+	// Probe is the kind of probe added, none when no probe was added
+	Probe          string `yaml:"probe" json:"probe"`
+	Recommendation string `yaml:"recommendation" json:"recommendation"`
+}
+
+var (
+	// healthProbeScaffolds are keyed by the service and the container, since the preprocessors run for each of the transformers
+	healthProbeScaffolds      = map[string]HealthProbeScaffold{}
+	healthProbeScaffoldsMutex sync.Mutex
+)
+
+// ResetHealthProbeScaffolds clears the probes added during a previous transformation
+func ResetHealthProbeScaffolds() {
+	healthProbeScaffoldsMutex.Lock()
+	defer healthProbeScaffoldsMutex.Unlock()
+	healthProbeScaffolds = map[string]HealthProbeScaffold{}
+}
+
+// GetHealthProbeScaffolds returns the services which had no probes, sorted by the service and the container
+func GetHealthProbeScaffolds() []HealthProbeScaffold {
+	healthProbeScaffoldsMutex.Lock()
+	defer healthProbeScaffoldsMutex.Unlock()
+	scaffolds := []HealthProbeScaffold{}
+	for _, scaffold := range healthProbeScaffolds {
+		scaffolds = append(scaffolds, scaffold)
+	}
+	sort.Slice(scaffolds, func(i, j int) bool {
+		if scaffolds[i].Service != scaffolds[j].Service {
+			return scaffolds[i].Service < scaffolds[j].Service
+		}
+		return scaffolds[i].Container < scaffolds[j].Container
+	})
+	return scaffolds
+}
+
+func recordHealthProbeScaffold(scaffold HealthProbeScaffold) {
+	healthProbeScaffoldsMutex.Lock()
+	defer healthProbeScaffoldsMutex.Unlock()
+	key := scaffold.Service + "/" + scaffold.Container
+	if _, ok := healthProbeScaffolds[key]; !ok && scaffold.Probe != noHealthProbe {
+		logrus.Infof("Added the %s probes to the container %s of the service %s . %s", scaffold.Probe, scaffold.Container, scaffold.Service, scaffold.Recommendation)
+	}
+	healthProbeScaffolds[key] = scaffold
+}
+
+// healthProbePreprocessor adds probes to the services which have none, either an exec probe checking that the port is listening
+// or a tiny sidecar serving a health endpoint, so that the probes can be enabled without changing the services
+type healthProbePreprocessor struct {
+}
+
+func (p healthProbePreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	serviceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if service.OnlyIngress || service.RestartPolicy == core.RestartPolicyNever || service.RestartPolicy == core.RestartPolicyOnFailure {
+			continue
+		}
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		containerIndex, port, ok := getContainerWithoutProbes(service)
+		if !ok {
+			continue
+		}
+		container := service.Containers[containerIndex]
+		serviceKey := `"` + serviceName + `"`
+		probeType := qaengine.FetchSelectAnswer(
+			common.JoinQASubKeys(common.ConfigServicesKey, serviceKey, common.ConfigHealthProbeTypeKeySuffix),
+			fmt.Sprintf("The service %s has no health endpoint. Select the probes to add until it has one :", serviceName),
+			[]string{
+				fmt.Sprintf("exec : run a command in the container %s checking that the port %d is listening, the image needs a shell", container.Name, port),
+				fmt.Sprintf("sidecar : add a tiny container serving a health endpoint, which checks that the port %d accepts connections", port),
+				"none : do not add the probes, the pods are not restarted when the service hangs",
+			},
+			noHealthProbe,
+			[]string{noHealthProbe, execHealthProbe, sidecarHealthProbe},
+			nil,
+		)
+		scaffold := HealthProbeScaffold{
+			Service:        serviceName,
+			Container:      container.Name,
+			Probe:          probeType,
+			Recommendation: fmt.Sprintf("Add an HTTP health endpoint, like /healthz, to the service %s and use it in httpGet liveness and readiness probes on the port %d", serviceName, port),
+		}
+		var handler core.ProbeHandler
+		switch probeType {
+		case execHealthProbe:
+			command := strings.TrimSpace(qaengine.FetchStringAnswer(
+				common.JoinQASubKeys(common.ConfigServicesKey, serviceKey, common.ConfigHealthProbeCommandKeySuffix),
+				fmt.Sprintf("Enter the shell command checking the health of the container %s of the service %s :", container.Name, serviceName),
+				[]string{"The container is healthy when the command exits with 0. By default it checks that the port is listening."},
+				getListeningPortCommand(port),
+				nil,
+			))
+			if command == "" {
+				scaffold.Probe = noHealthProbe
+				break
+			}
+			handler = core.ProbeHandler{Exec: &core.ExecAction{Command: []string{"sh", "-c", command}}}
+		case sidecarHealthProbe:
+			sidecarPort := getFreeContainerPort(service, defaultHealthSidecarPort)
+			service.Containers = append(service.Containers, getHealthSidecar(serviceName, port, sidecarPort))
+			handler = core.ProbeHandler{HTTPGet: &core.HTTPGetAction{Path: healthSidecarPath, Port: intstr.FromInt(int(sidecarPort)), Scheme: core.URISchemeHTTP}}
+		}
+		recordHealthProbeScaffold(scaffold)
+		if scaffold.Probe == noHealthProbe {
+			continue
+		}
+		container.ReadinessProbe = &core.Probe{ProbeHandler: handler, PeriodSeconds: 10, TimeoutSeconds: 1, FailureThreshold: 3, SuccessThreshold: 1}
+		container.LivenessProbe = &core.Probe{ProbeHandler: handler, InitialDelaySeconds: healthProbeInitialDelaySeconds, PeriodSeconds: 10, TimeoutSeconds: 1, FailureThreshold: 3, SuccessThreshold: 1}
+		service.Containers[containerIndex] = container
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getContainerWithoutProbes returns the first container listening on a port, if none of the containers have probes
+func getContainerWithoutProbes(service irtypes.Service) (int, int32, bool) {
+	for _, container := range service.Containers {
+		if container.LivenessProbe != nil || container.ReadinessProbe != nil || container.StartupProbe != nil {
+			return 0, 0, false
+		}
+	}
+	for i, container := range service.Containers {
+		for _, port := range container.Ports {
+			if port.ContainerPort != 0 && getContainerPortProtocol(port) == core.ProtocolTCP {
+				return i, port.ContainerPort, true
+			}
+		}
+	}
+	if len(service.Containers) == 0 {
+		return 0, 0, false
+	}
+	for _, forwarding := range service.ServiceToPodPortForwardings {
+		if forwarding.PodPort.Number != 0 && (forwarding.Protocol == "" || forwarding.Protocol == core.ProtocolTCP) {
+			return 0, forwarding.PodPort.Number, true
+		}
+	}
+	return 0, 0, false
+}
+
+// getListeningPortCommand returns a command checking that the port is listening, using only the proc filesystem since the images might not have netstat or nc
+func getListeningPortCommand(port int32) string {
+	// the local address is followed by the remote address and the state, which is 0A for the listening sockets
+	return fmt.Sprintf("grep -qiE ':%04X [0-9A-F]+:[0-9A-F]{4} 0A' /proc/net/tcp /proc/net/tcp6", port)
+}
+
+// getFreeContainerPort returns the port, or the next port which is not used by the containers of the service
+func getFreeContainerPort(service irtypes.Service, port int32) int32 {
+	usedPorts := map[int32]bool{}
+	for _, container := range service.Containers {
+		for _, containerPort := range container.Ports {
+			usedPorts[containerPort.ContainerPort] = true
+		}
+	}
+	for _, forwarding := range service.ServiceToPodPortForwardings {
+		usedPorts[forwarding.PodPort.Number] = true
+	}
+	for usedPorts[port] {
+		port++
+	}
+	return port
+}
+
+// getHealthSidecar returns a container serving a health endpoint, which is healthy while the port of the service accepts connections
+func getHealthSidecar(serviceName string, port, sidecarPort int32) core.Container {
+	script := strings.Join([]string{
+		"#!/bin/sh",
+		fmt.Sprintf("if nc -z -w 1 127.0.0.1 %d; then", port),
+		`  printf 'HTTP/1.0 200 OK\r\n\r\nok\n'`,
+		"else",
+		`  printf 'HTTP/1.0 503 Service Unavailable\r\n\r\nunavailable\n'`,
+		"fi",
+	}, "\n")
+	scriptPath := "/tmp/health" + healthSidecarPath
+	return core.Container{
+		Name:  common.MakeStringK8sServiceNameCompliant(serviceName + healthSidecarContainerSuffix),
+		Image: healthSidecarImage,
+		Command: []string{"sh", "-c", fmt.Sprintf(
+			`mkdir -p /tmp/health/cgi-bin && echo "$%s" > %s && chmod +x %s && exec httpd -f -p %d -h /tmp/health`,
+			healthSidecarScriptEnv, scriptPath, scriptPath, sidecarPort,
+		)},
+		Env:   []core.EnvVar{{Name: healthSidecarScriptEnv, Value: script}},
+		Ports: []core.ContainerPort{{Name: "health", ContainerPort: sidecarPort, Protocol: core.ProtocolTCP}},
+		// the health checks only need a fraction of the default resources of the containers
+		Resources: core.ResourceRequirements{
+			Requests: core.ResourceList{core.ResourceCPU: resource.MustParse("10m"), core.ResourceMemory: resource.MustParse("16Mi")},
+			Limits:   core.ResourceList{core.ResourceCPU: resource.MustParse("100m"), core.ResourceMemory: resource.MustParse("32Mi")},
+		},
+	}
+}
@@ -0,0 +1,172 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func getHealthProbeTestIR() irtypes.IR {
+	ir := irtypes.NewIR()
+	api := irtypes.NewServiceWithName("api")
+	api.Containers = []core.Container{{Name: "api", Image: "api:latest", Ports: []core.ContainerPort{{ContainerPort: 53, Protocol: core.ProtocolUDP}, {ContainerPort: 8080}}}}
+	ir.Services["api"] = api
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{{Name: "web", Image: "web:latest", Ports: []core.ContainerPort{{ContainerPort: defaultHealthSidecarPort}}}}
+	web.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{{PodPort: networking.ServiceBackendPort{Number: defaultHealthSidecarPort + 1}}}
+	ir.Services["web"] = web
+	probed := irtypes.NewServiceWithName("probed")
+	probed.Containers = []core.Container{
+		{Name: "probed", Image: "probed:latest", Ports: []core.ContainerPort{{ContainerPort: 8080}}},
+		{Name: "other", Image: "other:latest", LivenessProbe: &core.Probe{}},
+	}
+	ir.Services["probed"] = probed
+	job := irtypes.NewServiceWithName("job")
+	job.Containers = []core.Container{{Name: "job", Image: "job:latest", Ports: []core.ContainerPort{{ContainerPort: 8080}}}}
+	job.RestartPolicy = core.RestartPolicyOnFailure
+	ir.Services["job"] = job
+	return ir
+}
+
+func TestHealthProbePreprocessor(t *testing.T) {
+	setup := func(t *testing.T, configs ...string) {
+		t.Helper()
+		qaengine.Reset()
+		t.Cleanup(qaengine.Reset)
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", configs, nil, nil, false)
+		ResetHealthProbeScaffolds()
+		t.Cleanup(ResetHealthProbeScaffolds)
+	}
+	typeKey := func(serviceName string) string {
+		return common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigHealthProbeTypeKeySuffix)
+	}
+	commandKey := func(serviceName string) string {
+		return common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, common.ConfigHealthProbeCommandKeySuffix)
+	}
+
+	t.Run("no probes are added by default, but the services are reported", func(t *testing.T) {
+		setup(t)
+		before := getHealthProbeTestIR()
+		ir, err := healthProbePreprocessor{}.preprocess(getHealthProbeTestIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if diff := cmp.Diff(before, ir); diff != "" {
+			t.Fatalf("expected the IR to be unchanged. Diff (-want +got):\n%s", diff)
+		}
+		got := []string{}
+		for _, scaffold := range GetHealthProbeScaffolds() {
+			got = append(got, scaffold.Service+"/"+scaffold.Container+"/"+scaffold.Probe)
+		}
+		if diff := cmp.Diff([]string{"api/api/none", "web/web/none"}, got); diff != "" {
+			t.Fatalf("expected only the long running services without probes to be reported. Diff (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("the exec and the sidecar probes are added", func(t *testing.T) {
+		setup(t, typeKey("api")+`="`+execHealthProbe+`"`, typeKey("web")+`="`+sidecarHealthProbe+`"`)
+		ir, err := healthProbePreprocessor{}.preprocess(getHealthProbeTestIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		api := ir.Services["api"].Containers[0]
+		wantExec := &core.ExecAction{Command: []string{"sh", "-c", getListeningPortCommand(8080)}}
+		if api.ReadinessProbe == nil || api.LivenessProbe == nil || !cmp.Equal(api.ReadinessProbe.Exec, wantExec) || !cmp.Equal(api.LivenessProbe.Exec, wantExec) {
+			t.Fatalf("expected the exec probes checking the port 8080 , got the readiness probe %+v and the liveness probe %+v", api.ReadinessProbe, api.LivenessProbe)
+		}
+		if api.LivenessProbe.InitialDelaySeconds != healthProbeInitialDelaySeconds || api.ReadinessProbe.InitialDelaySeconds != 0 {
+			t.Fatalf("expected only the liveness probe to wait for the service to start, got %+v and %+v", api.LivenessProbe, api.ReadinessProbe)
+		}
+
+		web := ir.Services["web"]
+		if len(web.Containers) != 2 {
+			t.Fatalf("expected the health sidecar to be added, got the containers %+v", web.Containers)
+		}
+		// the ports used by the service are skipped
+		sidecarPort := int32(defaultHealthSidecarPort + 2)
+		if diff := cmp.Diff(getHealthSidecar("web", defaultHealthSidecarPort, sidecarPort), web.Containers[1]); diff != "" {
+			t.Fatalf("the health sidecar is not correct. Diff (-want +got):\n%s", diff)
+		}
+		wantHTTPGet := &core.HTTPGetAction{Path: healthSidecarPath, Port: intstr.FromInt(int(sidecarPort)), Scheme: core.URISchemeHTTP}
+		if probe := web.Containers[0].LivenessProbe; probe == nil || !cmp.Equal(probe.HTTPGet, wantHTTPGet) {
+			t.Fatalf("expected the probe of the web container to use the sidecar, got %+v", probe)
+		}
+	})
+
+	t.Run("an empty command adds no probes", func(t *testing.T) {
+		setup(t, typeKey("api")+`="`+execHealthProbe+`"`, commandKey("api")+`=""`)
+		ir, err := healthProbePreprocessor{}.preprocess(getHealthProbeTestIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if api := ir.Services["api"].Containers[0]; api.LivenessProbe != nil || api.ReadinessProbe != nil {
+			t.Fatalf("expected no probes, got %+v", api)
+		}
+		if scaffolds := GetHealthProbeScaffolds(); len(scaffolds) == 0 || scaffolds[0].Probe != noHealthProbe {
+			t.Fatalf("expected the api to be reported without probes, got %+v", scaffolds)
+		}
+	})
+}
+
+func TestGetContainerWithoutProbes(t *testing.T) {
+	service := irtypes.NewServiceWithName("svc")
+	if _, _, ok := getContainerWithoutProbes(service); ok {
+		t.Errorf("expected no container for a service without containers")
+	}
+	service.Containers = []core.Container{{Name: "sidecar"}, {Name: "svc"}}
+	service.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{
+		{PodPort: networking.ServiceBackendPort{Number: 9090}, Protocol: core.ProtocolUDP},
+		{PodPort: networking.ServiceBackendPort{Number: 8080}},
+	}
+	if index, port, ok := getContainerWithoutProbes(service); !ok || index != 0 || port != 8080 {
+		t.Errorf("expected the first container and the tcp port of the service, got %d, %d and %t", index, port, ok)
+	}
+	service.Containers[1].Ports = []core.ContainerPort{{ContainerPort: 3000}}
+	if index, port, ok := getContainerWithoutProbes(service); !ok || index != 1 || port != 3000 {
+		t.Errorf("expected the container listening on a port, got %d, %d and %t", index, port, ok)
+	}
+}
+
+func TestGetListeningPortCommand(t *testing.T) {
+	pattern := regexp.MustCompile(`(?i)` + regexp.MustCompile(`^grep -qiE '(.*)' `).FindStringSubmatch(getListeningPortCommand(8080))[1])
+	for line, want := range map[string]bool{
+		// the local address 0.0.0.0:8080 in the listening state
+		"   0: 00000000:1F90 00000000:0000 0A 00000000:00000000 00:00000000 00000000":          true,
+		"   1: 00000000000000000000000000000000:1F90 00000000000000000000000000000000:0000 0A": true,
+		// a connection from the port 8080 is not listening
+		"   2: 0100007F:1F90 0100007F:D431 01 00000000:00000000": false,
+		"   3: 00000000:1F91 00000000:0000 0A":                   false,
+	} {
+		if got := pattern.MatchString(line); got != want {
+			t.Errorf("the command %s matched the line %q : %t, want %t", getListeningPortCommand(8080), line, got, want)
+		}
+	}
+	want := "grep -qiE ':0050 [0-9A-F]+:[0-9A-F]{4} 0A' /proc/net/tcp /proc/net/tcp6"
+	if got := getListeningPortCommand(80); got != want {
+		t.Errorf("getListeningPortCommand(80) = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+)
+
+// imageBuildPreprocessor sets the build args and the target stage of the images built from Dockerfiles,
+// so that the pipelines build them the same way as the build scripts
+type imageBuildPreprocessor struct {
+}
+
+func (p imageBuildPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	imageNames := []string{}
+	for imageName, containerImage := range ir.ContainerImages {
+		if containerImage.Build.ContainerBuildType == irtypes.DockerfileContainerBuildType {
+			imageNames = append(imageNames, imageName)
+		}
+	}
+	sort.Strings(imageNames)
+	for _, imageName := range imageNames {
+		containerImage := ir.ContainerImages[imageName]
+		declaredBuildArgs, stages := []string{}, []string{}
+		if dockerfilePaths := containerImage.Build.Artifacts[irtypes.DockerfileContainerBuildArtifactTypeValue]; len(dockerfilePaths) != 0 {
+			declaredBuildArgs, stages = common.GetDockerfileArgsAndStages(dockerfilePaths[0])
+		}
+		containerImage.Build.BuildArgs = commonqa.ImageBuildArgs(imageName, declaredBuildArgs, containerImage.Build.BuildArgs)
+		containerImage.Build.Target = commonqa.ImageBuildTarget(imageName, stages, containerImage.Build.Target)
+		ir.ContainerImages[imageName] = containerImage
+	}
+	return ir, nil
+}
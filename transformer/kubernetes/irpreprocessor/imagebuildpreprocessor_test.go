@@ -0,0 +1,70 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+)
+
+func TestImageBuildPreprocessor(t *testing.T) {
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{
+		common.JoinQASubKeys(common.ConfigImageBuildKey, `"web"`, common.ConfigImageBuildTargetKeySegment) + `="runtime"`,
+	}, nil, nil, false)
+
+	dockerfilePath := filepath.Join(t.TempDir(), "Dockerfile")
+	dockerfile := "FROM golang:1.18 AS builder\nARG VERSION\nFROM alpine AS runtime\n"
+	if err := os.WriteFile(dockerfilePath, []byte(dockerfile), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the Dockerfile. Error: %q", err)
+	}
+	ir := irtypes.NewIR()
+	web := irtypes.NewContainer()
+	web.Build = irtypes.ContainerBuild{
+		ContainerBuildType: irtypes.DockerfileContainerBuildType,
+		Artifacts:          map[irtypes.ContainerBuildArtifactTypeValue][]string{irtypes.DockerfileContainerBuildArtifactTypeValue: {dockerfilePath}},
+		BuildArgs:          map[string]string{"VERSION": "1.2"},
+	}
+	ir.ContainerImages["web"] = web
+	prebuilt := irtypes.NewContainer()
+	prebuilt.Build = irtypes.ContainerBuild{BuildArgs: map[string]string{"VERSION": "1.2"}, Target: "builder"}
+	ir.ContainerImages["prebuilt"] = prebuilt
+
+	actual, err := imageBuildPreprocessor{}.preprocess(ir)
+	if err != nil {
+		t.Fatalf("failed to preprocess the IR. Error: %q", err)
+	}
+	build := actual.ContainerImages["web"].Build
+	if want := map[string]string{"VERSION": "1.2"}; !cmp.Equal(build.BuildArgs, want) {
+		t.Fatalf("unexpected build args. Differences:\n%s", cmp.Diff(want, build.BuildArgs))
+	}
+	if build.Target != "runtime" {
+		t.Fatalf("got the target %q , want %q", build.Target, "runtime")
+	}
+	// the images which are not built with a Dockerfile are left as they are
+	if build := actual.ContainerImages["prebuilt"].Build; build.Target != "builder" || len(build.BuildArgs) != 1 {
+		t.Fatalf("expected the image which is not built with a Dockerfile to be unchanged. Actual: %+v", build)
+	}
+}
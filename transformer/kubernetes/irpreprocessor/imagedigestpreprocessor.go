@@ -0,0 +1,129 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	imageDigestLookupTimeout = 30 * time.Second
+)
+
+// imageDigests caches the digests of the images during a transformation, since the preprocessors run for each of the transformers.
+// The images which could not be looked up are cached with an empty digest.
+var imageDigests = map[string]string{}
+
+// ResetImageDigests clears the digests looked up during a previous transformation, since the tags could point to other images now
+func ResetImageDigests() {
+	imageDigests = map[string]string{}
+}
+
+// imageDigestPreprocessor pins the images of the containers to the digests their tags currently point to,
+// for the supply chain policies which do not allow mutable tags
+type imageDigestPreprocessor struct {
+}
+
+func (p imageDigestPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	images := []string{}
+	for _, service := range ir.Services {
+		for _, container := range append(service.InitContainers, service.Containers...) {
+			if container.Image != "" && !strings.Contains(container.Image, "@") && !isBuiltImage(container.Image, ir.ContainerImages) {
+				images = common.AppendIfNotPresent(images, container.Image)
+			}
+		}
+	}
+	if len(images) == 0 {
+		return ir, nil
+	}
+	if !qaengine.FetchBoolAnswer(
+		common.ConfigImageDigestsKey,
+		"Pin the images of the containers to their digests?",
+		[]string{"The tags are resolved to digests by looking them up in the image registries. The images built for the services are not pinned since they are not pushed yet."},
+		false,
+		nil,
+	) {
+		return ir, nil
+	}
+	digests := map[string]string{}
+	for _, image := range images {
+		digest, ok := imageDigests[image]
+		if !ok {
+			var err error
+			digest, err = getImageDigest(image)
+			if err != nil {
+				logrus.Warnf("Unable to pin the image %s to its digest. Keeping the tag. Error: %q", image, err)
+			} else {
+				logrus.Debugf("Pinning the image %s to the digest %s", image, digest)
+			}
+			imageDigests[image] = digest
+		}
+		if digest != "" {
+			digests[image] = image + "@" + digest
+		}
+	}
+	for serviceName, service := range ir.Services {
+		for i, container := range service.InitContainers {
+			if pinned, ok := digests[container.Image]; ok {
+				service.InitContainers[i].Image = pinned
+			}
+		}
+		for i, container := range service.Containers {
+			if pinned, ok := digests[container.Image]; ok {
+				service.Containers[i].Image = pinned
+			}
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getImageDigest looks up the digest of the image in its registry, using the credentials of the docker config file
+func getImageDigest(image string) (string, error) {
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse the image name. Error: %w", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), imageDigestLookupTimeout)
+	defer cancel()
+	desc, err := remote.Head(ref, remote.WithAuthFromKeychain(authn.DefaultKeychain), remote.WithContext(ctx))
+	if err != nil {
+		return "", fmt.Errorf("failed to look up the image in the registry %s . Error: %w", ref.Context().RegistryStr(), err)
+	}
+	return desc.Digest.String(), nil
+}
+
+// isBuiltImage returns true if the image is built for one of the services, even if it has been prefixed with the image registry
+func isBuiltImage(image string, containerImages map[string]irtypes.ContainerImage) bool {
+	for imageName := range containerImages {
+		if image == imageName || strings.HasSuffix(image, "/"+imageName) {
+			return true
+		}
+	}
+	return false
+}
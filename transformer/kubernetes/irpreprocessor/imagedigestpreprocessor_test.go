@@ -0,0 +1,129 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/random"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// pushRandomImage pushes a new random image with the tag to the registry and returns its digest
+func pushRandomImage(t *testing.T, image string) string {
+	t.Helper()
+	ref, err := name.ParseReference(image)
+	if err != nil {
+		t.Fatalf("failed to parse the image name %s . Error: %q", image, err)
+	}
+	img, err := random.Image(64, 1)
+	if err != nil {
+		t.Fatalf("failed to create a random image. Error: %q", err)
+	}
+	if err := remote.Write(ref, img); err != nil {
+		t.Fatalf("failed to push the image %s . Error: %q", image, err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		t.Fatalf("failed to get the digest of the image. Error: %q", err)
+	}
+	return digest.String()
+}
+
+func TestImageDigestPreprocessor(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{common.ConfigImageDigestsKey + "=true"}, nil, nil, false)
+	ResetImageDigests()
+	defer ResetImageDigests()
+
+	var manifestRequests int32
+	registryHandler := registry.New()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/manifests/") && r.Method == http.MethodHead {
+			atomic.AddInt32(&manifestRequests, 1)
+		}
+		registryHandler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	registryHost := strings.TrimPrefix(server.URL, "http://")
+	image := registryHost + "/team/api:1.0"
+	missingImage := registryHost + "/team/missing:1.0"
+	builtImage := "web:latest"
+
+	newIR := func() irtypes.IR {
+		ir := irtypes.NewIR()
+		ir.ContainerImages[builtImage] = irtypes.ContainerImage{}
+		service := irtypes.NewServiceWithName("api")
+		service.InitContainers = []core.Container{{Name: "init", Image: image}}
+		service.Containers = []core.Container{{Name: "api", Image: image}, {Name: "missing", Image: missingImage}, {Name: "web", Image: builtImage}}
+		ir.Services["api"] = service
+		return ir
+	}
+	getImages := func(ir irtypes.IR) []string {
+		service := ir.Services["api"]
+		return []string{service.InitContainers[0].Image, service.Containers[0].Image, service.Containers[1].Image, service.Containers[2].Image}
+	}
+
+	firstDigest := pushRandomImage(t, image)
+	t.Run("the tags are resolved to the digests", func(t *testing.T) {
+		ir, err := imageDigestPreprocessor{}.preprocess(newIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		want := []string{image + "@" + firstDigest, image + "@" + firstDigest, missingImage, builtImage}
+		if got := getImages(ir); strings.Join(got, ",") != strings.Join(want, ",") {
+			t.Fatalf("got the images %+v , want %+v", got, want)
+		}
+	})
+	t.Run("the digests are cached during the transformation", func(t *testing.T) {
+		requestsBefore := atomic.LoadInt32(&manifestRequests)
+		pushRandomImage(t, image)
+		ir, err := imageDigestPreprocessor{}.preprocess(newIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if got := getImages(ir)[1]; got != image+"@"+firstDigest {
+			t.Fatalf("got the image %s , want the cached digest %s", got, firstDigest)
+		}
+		if requests := atomic.LoadInt32(&manifestRequests); requests != requestsBefore {
+			t.Fatalf("expected the cached images and the failed lookups not to be looked up again, got %d more requests", requests-requestsBefore)
+		}
+	})
+	t.Run("the digests are looked up again after the reset", func(t *testing.T) {
+		ResetImageDigests()
+		movedDigest := pushRandomImage(t, image)
+		ir, err := imageDigestPreprocessor{}.preprocess(newIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if got := getImages(ir)[1]; got != image+"@"+movedDigest {
+			t.Fatalf("got the image %s , want the image pinned to the digest %s the tag moved to", got, movedDigest)
+		}
+	})
+}
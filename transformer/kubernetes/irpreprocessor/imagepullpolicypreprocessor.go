@@ -17,18 +17,38 @@
 package irpreprocessor
 
 import (
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	core "k8s.io/kubernetes/pkg/apis/core"
 )
 
-// imagePullPolicyOptimizer sets the pull policy to be always
+// imagePullPolicyOptimizer sets the pull policy of all the containers, which is always by default
 type imagePullPolicyPreprocessor struct {
 }
 
 func (ep imagePullPolicyPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	hasContainers := false
+	for _, scObj := range ir.Services {
+		if len(scObj.Containers) > 0 {
+			hasContainers = true
+			break
+		}
+	}
+	if !hasContainers {
+		return ir, nil
+	}
+	pullPolicy := core.PullPolicy(qaengine.FetchSelectAnswer(
+		common.ConfigImagePullPolicyKey,
+		"Select the image pull policy of the containers :",
+		[]string{"IfNotPresent is enough when the images are pinned to their digests"},
+		string(core.PullAlways),
+		[]string{string(core.PullAlways), string(core.PullIfNotPresent), string(core.PullNever)},
+		nil,
+	))
 	for k, scObj := range ir.Services {
 		for i := range scObj.Containers {
-			scObj.Containers[i].ImagePullPolicy = core.PullAlways
+			scObj.Containers[i].ImagePullPolicy = pullPolicy
 		}
 		ir.Services[k] = scObj
 	}
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/qaengine"
 	irtypes "github.com/konveyor/move2kube/types/ir"
 	"github.com/sirupsen/logrus"
 	core "k8s.io/kubernetes/pkg/apis/core"
@@ -27,6 +28,7 @@ import (
 
 func TestImagePullPolicyOptimizer(t *testing.T) {
 	logrus.SetLevel(logrus.DebugLevel)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
 
 	t.Run("IR with no services", func(t *testing.T) {
 		// Setup
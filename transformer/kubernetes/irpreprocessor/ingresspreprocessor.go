@@ -23,6 +23,7 @@ import (
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/qaengine"
 	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
 	core "k8s.io/kubernetes/pkg/apis/core"
 )
@@ -34,31 +35,67 @@ type ingressPreprocessor struct {
 func (opt *ingressPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
 	for serviceName, service := range ir.Services {
 		tempService := ir.Services[serviceName]
+		// The APIs described by the specs in the sources are routed to the first port of the service
+		apiBasePaths := getServiceAPIBasePaths(service, ir.ContainerImages)
+		exposure := getPublishedServiceExposure(serviceName, &tempService)
 		for portForwardingIdx, portForwarding := range service.ServiceToPodPortForwardings {
 			if portForwarding.ServicePort.Number == 0 {
 				continue
 			}
 			if portForwarding.ServiceRelPath == "" {
 				portForwarding.ServiceRelPath = "/" + serviceName
+				if strings.HasPrefix(portForwarding.ServicePort.Name, grpcPortNamePrefix) {
+					// gRPC requests have the paths of the methods, like /package.Service/Method, so the service is exposed on a subdomain
+					portForwarding.ServiceRelPath = serviceName + "-" + grpcPortNamePrefix
+				} else if len(apiBasePaths) > 0 {
+					portForwarding.ServiceRelPath = strings.Join(apiBasePaths, ",")
+					apiBasePaths = nil
+				}
 			}
 			noneServiceType := "Don't create service"
 			portKeyPart := common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, `"`+cast.ToString(portForwarding.ServicePort.Number)+`"`)
 			options := []string{common.IngressKind, string(core.ServiceTypeLoadBalancer), string(core.ServiceTypeNodePort), string(core.ServiceTypeClusterIP), noneServiceType}
 			desc := fmt.Sprintf("What kind of service/ingress should be created for the service %s's %d port?", serviceName, portForwarding.ServicePort.Number)
 			hints := []string{"Choose " + common.IngressKind + " if you want a ingress/route resource to be created"}
+			def := common.IngressKind
+			if protocol := portForwarding.GetProtocol(); protocol != core.ProtocolTCP {
+				// ingresses and routes only carry http, so the other protocols can only be exposed by the services
+				portPart := cast.ToString(portForwarding.ServicePort.Number) + "/" + strings.ToLower(string(protocol))
+				portKeyPart = common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, `"`+portPart+`"`)
+				options = options[1:]
+				desc = fmt.Sprintf("What kind of service should be created for the service %s's %s port?", serviceName, portPart)
+				hints = []string{"LoadBalancer services support " + string(protocol) + " on most clouds"}
+				def = string(core.ServiceTypeClusterIP)
+			}
 			quesKey := common.JoinQASubKeys(portKeyPart, "servicetype")
-			portForwarding.ServiceType = core.ServiceType(qaengine.FetchSelectAnswer(quesKey, desc, hints, common.IngressKind, options, nil))
+			if exposure != "" && portForwarding.HostPort != 0 && (exposure != common.IngressKind || portForwarding.GetProtocol() == core.ProtocolTCP) {
+				// the ports published on the hosts are exposed the way selected for the whole service
+				portForwarding.ServiceType = core.ServiceType(exposure)
+				if exposure == string(core.ServiceTypeNodePort) && portForwarding.GetNodePort() == 0 {
+					logrus.Warnf("The port %d published by the service %s is outside the node port range of the clusters, so the cluster picks the node port of the service port %d", portForwarding.HostPort, serviceName, portForwarding.ServicePort.Number)
+				}
+			} else {
+				portForwarding.ServiceType = core.ServiceType(qaengine.FetchSelectAnswer(quesKey, desc, hints, def, options, nil))
+			}
 			if string(portForwarding.ServiceType) == noneServiceType {
 				portForwarding.ServiceType = ""
 			}
 			if string(portForwarding.ServiceType) == common.IngressKind {
 				desc := fmt.Sprintf("Specify the ingress path to expose the service %s's %d port on?", serviceName, portForwarding.ServicePort.Number)
-				hints := []string{"Leave out leading / to use first part as subdomain"}
+				hints := []string{"Leave out leading / to use first part as subdomain", "Separate multiple paths with commas, like the base paths of the APIs found in the specs"}
 				quesKey := common.JoinQASubKeys(portKeyPart, "urlpath")
-				portForwarding.ServiceRelPath = strings.TrimSpace(qaengine.FetchStringAnswer(quesKey, desc, hints, portForwarding.ServiceRelPath, nil))
+				relPaths := strings.Split(qaengine.FetchStringAnswer(quesKey, desc, hints, portForwarding.ServiceRelPath, nil), ",")
+				portForwarding.ServiceRelPath = strings.TrimSpace(relPaths[0])
+				portForwarding.AdditionalServiceRelPaths = nil
+				for _, relPath := range relPaths[1:] {
+					if relPath = strings.TrimSpace(relPath); relPath != "" {
+						portForwarding.AdditionalServiceRelPaths = append(portForwarding.AdditionalServiceRelPaths, "/"+strings.TrimPrefix(relPath, "/"))
+					}
+				}
 				portForwarding.ServiceType = core.ServiceTypeClusterIP
 			} else {
 				portForwarding.ServiceRelPath = ""
+				portForwarding.AdditionalServiceRelPaths = nil
 			}
 			tempService.ServiceToPodPortForwardings[portForwardingIdx] = portForwarding
 		}
@@ -66,3 +103,93 @@ func (opt *ingressPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
 	}
 	return ir, nil
 }
+
+// getPublishedServiceExposure asks how the service should be exposed, when the source published its ports on the hosts,
+// and sets the annotations of the load balancer of the cloud. It returns an empty string when no port was published.
+func getPublishedServiceExposure(serviceName string, service *irtypes.Service) string {
+	publishedPorts := []string{}
+	for _, forwarding := range service.ServiceToPodPortForwardings {
+		if forwarding.HostPort != 0 && forwarding.ServicePort.Number != 0 {
+			publishedPorts = append(publishedPorts, cast.ToString(forwarding.HostPort))
+		}
+	}
+	if len(publishedPorts) == 0 {
+		return ""
+	}
+	serviceKeyPart := common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`)
+	exposure := qaengine.FetchSelectAnswer(
+		common.JoinQASubKeys(serviceKeyPart, "publishedports", "exposure"),
+		fmt.Sprintf("The service %s publishes the ports %s on the hosts. How should the service be exposed?", serviceName, strings.Join(publishedPorts, ", ")),
+		[]string{
+			common.IngressKind + " : a ClusterIP service with an ingress/route for the http ports",
+			string(core.ServiceTypeNodePort) + " : the published ports are kept as the node ports, when they are in the node port range",
+			string(core.ServiceTypeLoadBalancer) + " : a load balancer of the cloud in front of the service",
+		},
+		common.IngressKind,
+		[]string{common.IngressKind, string(core.ServiceTypeNodePort), string(core.ServiceTypeLoadBalancer)},
+		nil,
+	)
+	if exposure != string(core.ServiceTypeLoadBalancer) {
+		return exposure
+	}
+	cloud := getLoadBalancerCloud()
+	if cloud == noLoadBalancerCloud {
+		return exposure
+	}
+	internal := qaengine.FetchBoolAnswer(
+		common.JoinQASubKeys(serviceKeyPart, "loadbalancer", "internal"),
+		fmt.Sprintf("Should the load balancer of the service %s only be reachable from the private network?", serviceName),
+		[]string{"An internet facing load balancer is created otherwise"},
+		false,
+		nil,
+	)
+	annotations := loadBalancerAnnotations[cloud][internal]
+	if len(annotations) == 0 {
+		return exposure
+	}
+	if service.ServiceAnnotations == nil {
+		service.ServiceAnnotations = map[string]string{}
+	}
+	for key, value := range annotations {
+		service.ServiceAnnotations[key] = value
+	}
+	return exposure
+}
+
+const (
+	noLoadBalancerCloud    = "none"
+	awsLoadBalancerCloud   = "aws"
+	azureLoadBalancerCloud = "azure"
+	gcpLoadBalancerCloud   = "gcp"
+	ibmLoadBalancerCloud   = "ibm"
+)
+
+// loadBalancerAnnotations are the annotations of the LoadBalancer services of each cloud, for the internal and the internet facing load balancers
+var loadBalancerAnnotations = map[string]map[bool]map[string]string{
+	awsLoadBalancerCloud: {
+		true:  {"service.beta.kubernetes.io/aws-load-balancer-internal": "true"},
+		false: {"service.beta.kubernetes.io/aws-load-balancer-scheme": "internet-facing"},
+	},
+	azureLoadBalancerCloud: {
+		true: {"service.beta.kubernetes.io/azure-load-balancer-internal": "true"},
+	},
+	gcpLoadBalancerCloud: {
+		true: {"networking.gke.io/load-balancer-type": "Internal"},
+	},
+	ibmLoadBalancerCloud: {
+		true:  {"service.kubernetes.io/ibm-load-balancer-cloud-provider-ip-type": "private"},
+		false: {"service.kubernetes.io/ibm-load-balancer-cloud-provider-ip-type": "public"},
+	},
+}
+
+// getLoadBalancerCloud asks which cloud the LoadBalancer services are created in, so that its annotations can be set
+func getLoadBalancerCloud() string {
+	return qaengine.FetchSelectAnswer(
+		common.ConfigLoadBalancerCloudKey,
+		"Select the cloud the load balancers of the services are created in :",
+		[]string{"The annotations of the load balancers of the cloud are set on the LoadBalancer services"},
+		noLoadBalancerCloud,
+		[]string{noLoadBalancerCloud, awsLoadBalancerCloud, azureLoadBalancerCloud, gcpLoadBalancerCloud, ibmLoadBalancerCloud},
+		nil,
+	)
+}
@@ -0,0 +1,126 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestIngressPreprocessorExposesThePublishedPorts(t *testing.T) {
+	newIR := func(t *testing.T) irtypes.IR {
+		t.Helper()
+		ir := irtypes.NewIR()
+		web := irtypes.NewServiceWithName("web")
+		for _, port := range []struct {
+			number   int32
+			protocol core.Protocol
+			hostPort int32
+		}{{number: 8080, protocol: core.ProtocolTCP, hostPort: 30080}, {number: 8443, protocol: core.ProtocolTCP, hostPort: 443}, {number: 5353, protocol: core.ProtocolUDP, hostPort: 5353}} {
+			if err := web.AddPublishedPortForwarding(networking.ServiceBackendPort{Number: port.number}, networking.ServiceBackendPort{Number: port.number}, port.protocol, port.hostPort); err != nil {
+				t.Fatalf("failed to add the published port forwarding. Error: %q", err)
+			}
+		}
+		if err := web.AddPortForwarding(networking.ServiceBackendPort{Number: 9090}, networking.ServiceBackendPort{Number: 9090}, ""); err != nil {
+			t.Fatalf("failed to add the port forwarding. Error: %q", err)
+		}
+		ir.Services["web"] = web
+		return ir
+	}
+	setup := func(t *testing.T, configs ...string) {
+		t.Helper()
+		qaengine.Reset()
+		t.Cleanup(qaengine.Reset)
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", configs, nil, nil, false)
+	}
+	exposureKey := common.JoinQASubKeys(common.ConfigServicesKey, `"web"`, "publishedports", "exposure")
+	getServiceTypes := func(service irtypes.Service) []string {
+		serviceTypes := []string{}
+		for _, forwarding := range service.ServiceToPodPortForwardings {
+			serviceTypes = append(serviceTypes, string(forwarding.ServiceType)+" "+forwarding.ServiceRelPath)
+		}
+		return serviceTypes
+	}
+
+	t.Run("the published ports are exposed on an ingress by default", func(t *testing.T) {
+		setup(t)
+		actual, err := (&ingressPreprocessor{}).preprocess(newIR(t))
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		// the udp port can not be exposed on an ingress, so its own question is asked
+		want := []string{"ClusterIP /web", "ClusterIP /web", "ClusterIP ", "ClusterIP /web"}
+		if got := getServiceTypes(actual.Services["web"]); !cmp.Equal(got, want) {
+			t.Fatalf("the service types differ. Differences:\n%s", cmp.Diff(want, got))
+		}
+	})
+
+	t.Run("the published ports are kept as the node ports", func(t *testing.T) {
+		setup(t, exposureKey+`="NodePort"`)
+		actual, err := (&ingressPreprocessor{}).preprocess(newIR(t))
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		web := actual.Services["web"]
+		want := []string{"NodePort ", "NodePort ", "NodePort ", "ClusterIP /web"}
+		if got := getServiceTypes(web); !cmp.Equal(got, want) {
+			t.Fatalf("the service types differ. Differences:\n%s", cmp.Diff(want, got))
+		}
+		if nodePort, outOfRange := web.ServiceToPodPortForwardings[0].GetNodePort(), web.ServiceToPodPortForwardings[1].GetNodePort(); nodePort != 30080 || outOfRange != 0 {
+			t.Fatalf("expected only the port published in the node port range to be kept. Actual: %d and %d", nodePort, outOfRange)
+		}
+	})
+
+	t.Run("the load balancer gets the annotations of the cloud", func(t *testing.T) {
+		setup(t,
+			exposureKey+`="LoadBalancer"`,
+			common.ConfigLoadBalancerCloudKey+`="aws"`,
+			common.JoinQASubKeys(common.ConfigServicesKey, `"web"`, "loadbalancer", "internal")+`=true`,
+		)
+		actual, err := (&ingressPreprocessor{}).preprocess(newIR(t))
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		web := actual.Services["web"]
+		want := []string{"LoadBalancer ", "LoadBalancer ", "LoadBalancer ", "ClusterIP /web"}
+		if got := getServiceTypes(web); !cmp.Equal(got, want) {
+			t.Fatalf("the service types differ. Differences:\n%s", cmp.Diff(want, got))
+		}
+		wantAnnotations := map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"}
+		if !cmp.Equal(web.ServiceAnnotations, wantAnnotations) {
+			t.Fatalf("the annotations of the service differ. Differences:\n%s", cmp.Diff(wantAnnotations, web.ServiceAnnotations))
+		}
+	})
+
+	t.Run("the clouds without annotations for the internet facing load balancers", func(t *testing.T) {
+		setup(t, exposureKey+`="LoadBalancer"`, common.ConfigLoadBalancerCloudKey+`="gcp"`)
+		actual, err := (&ingressPreprocessor{}).preprocess(newIR(t))
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if annotations := actual.Services["web"].ServiceAnnotations; len(annotations) != 0 {
+			t.Fatalf("expected no annotations. Actual: %+v", annotations)
+		}
+	})
+}
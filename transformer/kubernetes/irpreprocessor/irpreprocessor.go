@@ -28,7 +28,7 @@ type irpreprocessor interface {
 
 // getIRPreprocessors returns optimizers
 func getIRPreprocessors() []irpreprocessor {
-	var l = []irpreprocessor{new(mergePreprocessor), new(normalizeCharacterPreprocessor), new(ingressPreprocessor), new(replicaPreprocessor), new(imagePullPolicyPreprocessor), new(registryPreProcessor)}
+	var l = []irpreprocessor{new(mergePreprocessor), new(normalizeCharacterPreprocessor), new(externalServicePreprocessor), new(serviceDiscoveryPreprocessor), new(grpcPreprocessor), new(ingressPreprocessor), new(portConflictPreprocessor), new(sessionAffinityPreprocessor), new(replicaPreprocessor), new(lifecycleHooksPreprocessor), new(healthProbePreprocessor), new(statefulSetPreprocessor), new(sharedVolumePreprocessor), new(rolloutStrategyPreprocessor), new(imagePullPolicyPreprocessor), new(serviceMeshPreprocessor), new(imageBuildPreprocessor), new(registryPreProcessor), new(dependencyWaitPreprocessor), new(configRolloutPreprocessor), new(spotSchedulingPreprocessor), new(secretsPreprocessor), new(fsGroupPreprocessor), new(imageDigestPreprocessor)}
 	return l
 }
 
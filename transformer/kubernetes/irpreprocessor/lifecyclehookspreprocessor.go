@@ -0,0 +1,204 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	preStopDelayHook     = "PreStop delay"
+	preStopCommandHook   = "PreStop command"
+	postStartCommandHook = "PostStart command"
+
+	defaultPreStopDelaySeconds = 5
+	// defaultTerminationGracePeriodSeconds is the termination grace period kubernetes uses when it is not set
+	defaultTerminationGracePeriodSeconds = 30
+	// minShutdownSeconds is the time left to the containers to exit after the preStop hooks, before they are killed
+	minShutdownSeconds      = 10
+	maxLifecycleHookSeconds = 3600
+)
+
+// lifecycleHooksPreprocessor adds the preStop and postStart hooks to the containers and sets the termination grace period,
+// so that the pods drain their connections instead of being killed abruptly during the rollouts
+type lifecycleHooksPreprocessor struct {
+}
+
+func (p lifecycleHooksPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	serviceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if !service.OnlyIngress && len(service.Containers) != 0 {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	sort.Strings(serviceNames)
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		keyPart := common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, "lifecyclehooks")
+		existingPreStopCommand := getExecCommand(service.Containers[0].Lifecycle, true)
+		existingPostStartCommand := getExecCommand(service.Containers[0].Lifecycle, false)
+		defaultHooks := []string{}
+		if service.PreStopDelaySeconds > 0 {
+			defaultHooks = append(defaultHooks, preStopDelayHook)
+		}
+		if existingPreStopCommand != "" {
+			defaultHooks = append(defaultHooks, preStopCommandHook)
+		}
+		if existingPostStartCommand != "" {
+			defaultHooks = append(defaultHooks, postStartCommandHook)
+		}
+		hooks := qaengine.FetchMultiSelectAnswer(
+			common.JoinQASubKeys(keyPart, "hooks"),
+			fmt.Sprintf("Select the lifecycle hooks for the containers of the service %s :", serviceName),
+			[]string{"PreStop delay: wait before stopping, so that the load balancers stop sending new requests and the in flight requests complete"},
+			defaultHooks,
+			[]string{preStopDelayHook, preStopCommandHook, postStartCommandHook},
+			nil,
+		)
+		delaySeconds := int32(0)
+		preStopCommand, postStartCommand := "", ""
+		for _, hook := range hooks {
+			switch hook {
+			case preStopDelayHook:
+				defaultDelaySeconds := service.PreStopDelaySeconds
+				if defaultDelaySeconds <= 0 {
+					defaultDelaySeconds = defaultPreStopDelaySeconds
+				}
+				delaySeconds = cast.ToInt32(qaengine.FetchStringAnswer(
+					common.JoinQASubKeys(keyPart, "prestopdelay"),
+					fmt.Sprintf("Enter the number of seconds the containers of the service %s should wait before stopping :", serviceName),
+					nil,
+					cast.ToString(defaultDelaySeconds),
+					qatypes.NewRangeValidator(1, maxLifecycleHookSeconds),
+				))
+			case preStopCommandHook:
+				preStopCommand = qaengine.FetchStringAnswer(
+					common.JoinQASubKeys(keyPart, "prestopcommand"),
+					fmt.Sprintf("Enter the command to run in the containers of the service %s before they are stopped :", serviceName),
+					[]string{"The command is run with /bin/sh -c, after the preStop delay"},
+					existingPreStopCommand,
+					nil,
+				)
+			case postStartCommandHook:
+				postStartCommand = qaengine.FetchStringAnswer(
+					common.JoinQASubKeys(keyPart, "poststartcommand"),
+					fmt.Sprintf("Enter the command to run in the containers of the service %s after they are started :", serviceName),
+					[]string{"The command is run with /bin/sh -c. The container is restarted if it fails."},
+					existingPostStartCommand,
+					nil,
+				)
+			}
+		}
+		service.PreStopDelaySeconds = delaySeconds
+		preStopHandler := getPreStopHandler(delaySeconds, preStopCommand)
+		postStartHandler := getShellHandler(postStartCommand)
+		for i, container := range service.Containers {
+			lifecycle := core.Lifecycle{}
+			if container.Lifecycle != nil {
+				lifecycle = *container.Lifecycle
+			}
+			// only the exec hooks are configured through the questions, the other hooks, like the http ones, are kept
+			if lifecycle.PreStop == nil || lifecycle.PreStop.Exec != nil {
+				lifecycle.PreStop = preStopHandler
+			}
+			if lifecycle.PostStart == nil || lifecycle.PostStart.Exec != nil {
+				lifecycle.PostStart = postStartHandler
+			}
+			container.Lifecycle = nil
+			if lifecycle.PreStop != nil || lifecycle.PostStart != nil {
+				container.Lifecycle = &lifecycle
+			}
+			service.Containers[i] = container
+		}
+		if delaySeconds > 0 || preStopCommand != "" {
+			defaultGracePeriodSeconds := int64(defaultTerminationGracePeriodSeconds)
+			if service.TerminationGracePeriodSeconds != nil {
+				defaultGracePeriodSeconds = *service.TerminationGracePeriodSeconds
+			}
+			if minGracePeriodSeconds := int64(delaySeconds) + minShutdownSeconds; defaultGracePeriodSeconds < minGracePeriodSeconds {
+				defaultGracePeriodSeconds = minGracePeriodSeconds
+			}
+			gracePeriodSeconds := cast.ToInt64(qaengine.FetchStringAnswer(
+				common.JoinQASubKeys(keyPart, "terminationgraceperiod"),
+				fmt.Sprintf("Enter the termination grace period in seconds of the service %s :", serviceName),
+				[]string{"The containers are killed if they have not exited when it ends, including the time spent in the preStop hook"},
+				cast.ToString(defaultGracePeriodSeconds),
+				qatypes.NewRangeValidator(int(delaySeconds)+1, maxLifecycleHookSeconds+minShutdownSeconds),
+			))
+			service.TerminationGracePeriodSeconds = &gracePeriodSeconds
+			logrus.Debugf("Added the preStop hook to the service %s with the termination grace period of %d seconds", serviceName, gracePeriodSeconds)
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// getPreStopHandler returns the handler which waits for the delay and then runs the command
+func getPreStopHandler(delaySeconds int32, command string) *core.LifecycleHandler {
+	commands := []string{}
+	if delaySeconds > 0 {
+		commands = append(commands, fmt.Sprintf("sleep %d", delaySeconds))
+	}
+	if command != "" {
+		commands = append(commands, command)
+	}
+	return getShellHandler(strings.Join(commands, " && "))
+}
+
+// getShellHandler returns the handler which runs the command in a shell
+func getShellHandler(command string) *core.LifecycleHandler {
+	if command == "" {
+		return nil
+	}
+	return &core.LifecycleHandler{Exec: &core.ExecAction{Command: []string{"/bin/sh", "-c", command}}}
+}
+
+// getExecCommand returns the command of the exec preStop or postStart hook, without the shell and the preStop delay
+func getExecCommand(lifecycle *core.Lifecycle, preStop bool) string {
+	if lifecycle == nil {
+		return ""
+	}
+	handler := lifecycle.PostStart
+	if preStop {
+		handler = lifecycle.PreStop
+	}
+	if handler == nil || handler.Exec == nil {
+		return ""
+	}
+	command := handler.Exec.Command
+	if len(command) == 3 && strings.HasSuffix(command[0], "sh") && command[1] == "-c" {
+		commands := strings.SplitN(command[2], " && ", 2)
+		if preStop && strings.HasPrefix(commands[0], "sleep ") {
+			if len(commands) == 1 {
+				return ""
+			}
+			return commands[1]
+		}
+		return command[2]
+	}
+	return strings.Join(command, " ")
+}
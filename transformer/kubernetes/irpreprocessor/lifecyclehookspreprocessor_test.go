@@ -0,0 +1,156 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const lifecycleHooksTestKey = common.ConfigServicesKey + common.Delim + `"web"` + common.Delim + "lifecyclehooks"
+
+func getLifecycleHooksTestIR(preStopDelaySeconds int32, containers ...core.Container) irtypes.IR {
+	ir := irtypes.NewIR()
+	service := irtypes.NewServiceWithName("web")
+	service.PreStopDelaySeconds = preStopDelaySeconds
+	service.Containers = containers
+	ir.Services["web"] = service
+	return ir
+}
+
+func getShellLifecycleHandler(command string) *core.LifecycleHandler {
+	return &core.LifecycleHandler{Exec: &core.ExecAction{Command: []string{"/bin/sh", "-c", command}}}
+}
+
+func TestLifecycleHooksPreprocessor(t *testing.T) {
+	setup := func(t *testing.T, configs ...string) {
+		t.Helper()
+		qaengine.Reset()
+		t.Cleanup(qaengine.Reset)
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", configs, nil, nil, false)
+	}
+
+	t.Run("no hooks are added to the services without a preStop delay", func(t *testing.T) {
+		setup(t)
+		actual, err := lifecycleHooksPreprocessor{}.preprocess(getLifecycleHooksTestIR(0, core.Container{Name: "web"}))
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if want := getLifecycleHooksTestIR(0, core.Container{Name: "web"}); !cmp.Equal(actual, want) {
+			t.Fatalf("expected the IR to be unchanged. Differences: %s", cmp.Diff(want, actual))
+		}
+	})
+
+	t.Run("the preStop delay of the service is added to all the containers", func(t *testing.T) {
+		setup(t)
+		actual, err := lifecycleHooksPreprocessor{}.preprocess(getLifecycleHooksTestIR(5, core.Container{Name: "web"}, core.Container{Name: "sidecar"}))
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		service := actual.Services["web"]
+		want := &core.Lifecycle{PreStop: getShellLifecycleHandler("sleep 5")}
+		for _, container := range service.Containers {
+			if !cmp.Equal(container.Lifecycle, want) {
+				t.Fatalf("unexpected lifecycle of the container %s . Differences: %s", container.Name, cmp.Diff(want, container.Lifecycle))
+			}
+		}
+		if service.TerminationGracePeriodSeconds == nil || *service.TerminationGracePeriodSeconds != defaultTerminationGracePeriodSeconds {
+			t.Fatalf("expected the default termination grace period of %d seconds. Actual: %v", defaultTerminationGracePeriodSeconds, service.TerminationGracePeriodSeconds)
+		}
+	})
+
+	t.Run("the configured hooks and the termination grace period", func(t *testing.T) {
+		setup(t,
+			lifecycleHooksTestKey+common.Delim+`hooks=["PreStop delay","PreStop command","PostStart command"]`,
+			lifecycleHooksTestKey+common.Delim+`prestopdelay="20"`,
+			lifecycleHooksTestKey+common.Delim+`prestopcommand="nginx -s quit"`,
+			lifecycleHooksTestKey+common.Delim+`poststartcommand="echo started"`,
+		)
+		httpPreStop := &core.LifecycleHandler{HTTPGet: &core.HTTPGetAction{Path: "/drain", Port: intstr.FromInt(8080)}}
+		ir := getLifecycleHooksTestIR(0, core.Container{Name: "web"}, core.Container{Name: "sidecar", Lifecycle: &core.Lifecycle{PreStop: httpPreStop}})
+		service := ir.Services["web"]
+		gracePeriodSeconds := int64(15)
+		service.TerminationGracePeriodSeconds = &gracePeriodSeconds
+		ir.Services["web"] = service
+		actual, err := lifecycleHooksPreprocessor{}.preprocess(ir)
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		service = actual.Services["web"]
+		postStart := getShellLifecycleHandler("echo started")
+		want := []*core.Lifecycle{
+			{PreStop: getShellLifecycleHandler("sleep 20 && nginx -s quit"), PostStart: postStart},
+			{PreStop: httpPreStop, PostStart: postStart},
+		}
+		for i, container := range service.Containers {
+			if !cmp.Equal(container.Lifecycle, want[i]) {
+				t.Fatalf("unexpected lifecycle of the container %s . Differences: %s", container.Name, cmp.Diff(want[i], container.Lifecycle))
+			}
+		}
+		if service.PreStopDelaySeconds != 20 {
+			t.Fatalf("got the preStop delay %d , want 20", service.PreStopDelaySeconds)
+		}
+		// the grace period is raised to leave the containers the time to exit after the delay
+		if service.TerminationGracePeriodSeconds == nil || *service.TerminationGracePeriodSeconds != 30 {
+			t.Fatalf("expected the termination grace period of 30 seconds. Actual: %v", service.TerminationGracePeriodSeconds)
+		}
+	})
+
+	t.Run("the existing exec hooks are removed when they are not selected", func(t *testing.T) {
+		setup(t, lifecycleHooksTestKey+common.Delim+`hooks=[]`)
+		lifecycle := &core.Lifecycle{PreStop: getShellLifecycleHandler("sleep 5 && cleanup"), PostStart: getShellLifecycleHandler("warmup")}
+		actual, err := lifecycleHooksPreprocessor{}.preprocess(getLifecycleHooksTestIR(5, core.Container{Name: "web", Lifecycle: lifecycle}))
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		service := actual.Services["web"]
+		if service.Containers[0].Lifecycle != nil || service.PreStopDelaySeconds != 0 {
+			t.Fatalf("expected the hooks to be removed. Actual lifecycle: %+v preStop delay: %d", service.Containers[0].Lifecycle, service.PreStopDelaySeconds)
+		}
+	})
+}
+
+func TestGetExecCommand(t *testing.T) {
+	testCases := []struct {
+		lifecycle *core.Lifecycle
+		preStop   bool
+		want      string
+	}{
+		{lifecycle: nil, preStop: true, want: ""},
+		{lifecycle: &core.Lifecycle{PreStop: getShellLifecycleHandler("sleep 5")}, preStop: true, want: ""},
+		{lifecycle: &core.Lifecycle{PreStop: getShellLifecycleHandler("sleep 5 && cleanup && exit")}, preStop: true, want: "cleanup && exit"},
+		{lifecycle: &core.Lifecycle{PreStop: getShellLifecycleHandler("cleanup")}, preStop: true, want: "cleanup"},
+		{lifecycle: &core.Lifecycle{PostStart: getShellLifecycleHandler("sleep 5 && warmup")}, preStop: false, want: "sleep 5 && warmup"},
+		{lifecycle: &core.Lifecycle{PostStart: &core.LifecycleHandler{Exec: &core.ExecAction{Command: []string{"warmup", "--fast"}}}}, preStop: false, want: "warmup --fast"},
+		{lifecycle: &core.Lifecycle{PreStop: &core.LifecycleHandler{HTTPGet: &core.HTTPGetAction{Path: "/drain"}}}, preStop: true, want: ""},
+	}
+	for i, testCase := range testCases {
+		t.Run(fmt.Sprint(i), func(t *testing.T) {
+			if got := getExecCommand(testCase.lifecycle, testCase.preStop); got != testCase.want {
+				t.Errorf("getExecCommand(%+v, %t) = %q, want %q", testCase.lifecycle, testCase.preStop, got, testCase.want)
+			}
+		})
+	}
+}
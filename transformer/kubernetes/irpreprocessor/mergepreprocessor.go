@@ -33,11 +33,15 @@ func (opt *mergePreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
 		pfs := service.ServiceToPodPortForwardings
 		service.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{}
 		for _, pf := range pfs {
-			service.AddPortForwarding(pf.ServicePort, pf.PodPort, pf.ServiceRelPath)
+			if err := service.AddPortForwardingWithProtocol(pf.ServicePort, pf.PodPort, pf.ServiceRelPath, pf.GetProtocol()); err == nil {
+				service.ServiceToPodPortForwardings[len(service.ServiceToPodPortForwardings)-1].HostPort = pf.HostPort
+			}
 		}
 		for _, c := range service.Containers {
 			for _, p := range c.Ports {
-				service.AddPortForwarding(networking.ServiceBackendPort{Number: p.ContainerPort}, networking.ServiceBackendPort{Number: p.ContainerPort}, "")
+				if err := service.AddPortForwardingWithProtocol(networking.ServiceBackendPort{Number: p.ContainerPort}, networking.ServiceBackendPort{Number: p.ContainerPort}, "", getContainerPortProtocol(p)); err == nil {
+					service.ServiceToPodPortForwardings[len(service.ServiceToPodPortForwardings)-1].HostPort = p.HostPort
+				}
 			}
 		}
 		tolerations := service.Tolerations
@@ -76,7 +80,7 @@ func (opt *mergePreprocessor) mergeContainers(sContainers []core.Container) []co
 			for _, ccp := range coreContainer.Ports {
 				found := false
 				for _, cp := range uniquePorts {
-					if ccp.ContainerPort == cp.ContainerPort {
+					if ccp.ContainerPort == cp.ContainerPort && getContainerPortProtocol(ccp) == getContainerPortProtocol(cp) {
 						found = true
 						break
 					}
@@ -105,7 +109,7 @@ func (opt *mergePreprocessor) mergeContainers(sContainers []core.Container) []co
 		for _, ccp := range coreContainer.Ports {
 			found := false
 			for _, cp := range uniquePorts {
-				if ccp.ContainerPort == cp.ContainerPort {
+				if ccp.ContainerPort == cp.ContainerPort && getContainerPortProtocol(ccp) == getContainerPortProtocol(cp) {
 					found = true
 					break
 				}
@@ -138,3 +142,11 @@ func (opt *mergePreprocessor) mergeContainers(sContainers []core.Container) []co
 	}
 	return sContainers
 }
+
+// getContainerPortProtocol returns the transport protocol of the container port, TCP when not set
+func getContainerPortProtocol(port core.ContainerPort) core.Protocol {
+	if port.Protocol == "" {
+		return core.ProtocolTCP
+	}
+	return port.Protocol
+}
@@ -0,0 +1,133 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"io/fs"
+	"net/url"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+)
+
+// openAPISpec has the fields of Swagger 2.0 and OpenAPI 3 specs which contain the base path of the API
+type openAPISpec struct {
+	Swagger  string `yaml:"swagger"`
+	OpenAPI  string `yaml:"openapi"`
+	BasePath string `yaml:"basePath"`
+	Servers  []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+}
+
+var (
+	openAPISpecPrefixes = []string{"openapi", "swagger"}
+	openAPISpecExts     = []string{".yaml", ".yml", ".json"}
+	openAPISkippedDirs  = []string{".git", "node_modules", "vendor", "target", "build", "bin", "obj"}
+	// openAPIBasePaths caches the base paths of the APIs found in the build contexts, since the preprocessors run for each of the transformers
+	openAPIBasePaths = map[string][]string{}
+)
+
+// getServiceAPIBasePaths returns the base paths of the OpenAPI and Swagger specs in the build contexts of the containers of the service
+func getServiceAPIBasePaths(service irtypes.Service, containerImages map[string]irtypes.ContainerImage) []string {
+	basePaths := []string{}
+	for _, container := range service.Containers {
+		for imageName, containerImage := range containerImages {
+			if containerImage.Build.ContextPath == "" || (container.Image != imageName && !strings.HasSuffix(container.Image, "/"+imageName)) {
+				continue
+			}
+			for _, basePath := range getAPIBasePaths(containerImage.Build.ContextPath) {
+				basePaths = common.AppendIfNotPresent(basePaths, basePath)
+			}
+		}
+	}
+	return basePaths
+}
+
+// getAPIBasePaths returns the sorted base paths of the specs in the directory, ignoring the specs served at the root
+func getAPIBasePaths(dir string) []string {
+	if basePaths, ok := openAPIBasePaths[dir]; ok {
+		return basePaths
+	}
+	basePaths := []string{}
+	err := filepath.WalkDir(dir, func(filePath string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if filePath != dir && common.IsPresent(openAPISkippedDirs, d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isOpenAPISpecFile(d.Name()) {
+			return nil
+		}
+		spec := openAPISpec{}
+		if err := common.ReadYaml(filePath, &spec); err != nil || (spec.Swagger == "" && spec.OpenAPI == "") {
+			return nil
+		}
+		paths := []string{spec.BasePath}
+		for _, server := range spec.Servers {
+			paths = append(paths, getServerPath(server.URL))
+		}
+		for _, basePath := range paths {
+			basePath = strings.TrimSuffix(path.Clean("/"+basePath), "/")
+			if basePath == "" || strings.Contains(basePath, "{") {
+				continue
+			}
+			logrus.Debugf("Found the API base path %s in the spec at path %s", basePath, filePath)
+			basePaths = common.AppendIfNotPresent(basePaths, basePath)
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Debugf("Unable to find the API specs in the directory %s . Error: %q", dir, err)
+	}
+	sort.Strings(basePaths)
+	openAPIBasePaths[dir] = basePaths
+	return basePaths
+}
+
+// isOpenAPISpecFile returns true if the file name looks like an OpenAPI or Swagger spec, like openapi.yaml or swagger-v2.json
+func isOpenAPISpecFile(fileName string) bool {
+	fileName = strings.ToLower(fileName)
+	if !common.IsPresent(openAPISpecExts, filepath.Ext(fileName)) {
+		return false
+	}
+	for _, prefix := range openAPISpecPrefixes {
+		if strings.HasPrefix(fileName, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// getServerPath returns the path of the url of an OpenAPI 3 server, which can be relative to the host serving the spec
+func getServerPath(serverURL string) string {
+	u, err := url.Parse(serverURL)
+	if err != nil {
+		logrus.Debugf("Unable to parse the server url %s of the API spec. Error: %q", serverURL, err)
+		return ""
+	}
+	return u.Path
+}
@@ -0,0 +1,68 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestGetServiceAPIBasePaths(t *testing.T) {
+	writeFile := func(t *testing.T, path, contents string) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create the directory of %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("Failed to write the file %s . Error: %q", path, err)
+		}
+	}
+
+	t.Run("service with OpenAPI and Swagger specs", func(t *testing.T) {
+		// Setup
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, "openapi.yaml"), "openapi: 3.0.0\nservers:\n  - url: https://api.example.com/api/orders/\n  - url: /api/v2/orders\n  - url: https://{region}.example.com/{basePath}\n  - url: /\n")
+		writeFile(t, filepath.Join(dir, "docs", "swagger.json"), `{"swagger": "2.0", "basePath": "/legacy"}`)
+		writeFile(t, filepath.Join(dir, "node_modules", "lib", "openapi.yaml"), "openapi: 3.0.0\nservers:\n  - url: /ignored\n")
+		writeFile(t, filepath.Join(dir, "openapi-generator.yaml"), "generatorName: go\n")
+		containerImages := map[string]irtypes.ContainerImage{"orders:latest": {Build: irtypes.ContainerBuild{ContextPath: dir}}}
+		service := irtypes.Service{Name: "orders"}
+		service.Containers = []core.Container{{Name: "orders", Image: "quay.io/myproject/orders:latest"}}
+		want := []string{"/api/orders", "/api/v2/orders", "/legacy"}
+
+		// Test
+		actual := getServiceAPIBasePaths(service, containerImages)
+		if !cmp.Equal(actual, want) {
+			t.Fatalf("Failed to get the base paths of the APIs properly. Differences:\n%s", cmp.Diff(want, actual))
+		}
+	})
+
+	t.Run("service without a build context", func(t *testing.T) {
+		// Setup
+		service := irtypes.Service{Name: "db"}
+		service.Containers = []core.Container{{Name: "db", Image: "postgres:13"}}
+
+		// Test
+		if actual := getServiceAPIBasePaths(service, map[string]irtypes.ContainerImage{}); len(actual) != 0 {
+			t.Fatalf("Expected no base paths. Actual: %v", actual)
+		}
+	})
+}
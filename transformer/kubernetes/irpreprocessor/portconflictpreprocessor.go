@@ -0,0 +1,209 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	remapConflictOption  = "Remap"
+	rejectConflictOption = "Reject"
+)
+
+// portConflictPreprocessor detects the ports of a service, and the ingress host and path routes, used more than once and resolves them by remapping or rejecting them
+type portConflictPreprocessor struct {
+}
+
+func (opt *portConflictPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	serviceNames := []string{}
+	for serviceName := range ir.Services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	// usedRoutes stores the service and the port using each ingress host and path
+	usedRoutes := map[string]string{}
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		usedPorts := map[string]bool{}
+		// allPorts stores all the ports of the service, so that the conflicting ports are not remapped to the ports of the later forwardings by default
+		allPorts := map[string]bool{}
+		for _, forwarding := range service.ServiceToPodPortForwardings {
+			allPorts[getPortKey(forwarding.ServicePort.Number, forwarding.GetProtocol())] = true
+		}
+		forwardings := []irtypes.ServiceToPodPortForwarding{}
+		for _, forwarding := range service.ServiceToPodPortForwardings {
+			if forwarding.ServicePort.Number == 0 {
+				forwardings = append(forwardings, forwarding)
+				continue
+			}
+			portKeyPart := common.JoinQASubKeys(common.ConfigServicesKey, `"`+serviceName+`"`, `"`+cast.ToString(forwarding.ServicePort.Number)+`"`)
+			if !resolvePortConflict(serviceName, portKeyPart, &forwarding, usedPorts, allPorts) {
+				continue
+			}
+			usedPorts[getPortKey(forwarding.ServicePort.Number, forwarding.GetProtocol())] = true
+			if forwarding.ServiceRelPath != "" && forwarding.GetProtocol() == core.ProtocolTCP {
+				resolveRouteConflicts(serviceName, portKeyPart, &forwarding, usedRoutes)
+			}
+			forwardings = append(forwardings, forwarding)
+		}
+		service.ServiceToPodPortForwardings = forwardings
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// resolvePortConflict remaps the port, if another port of the service has the same number and protocol. It returns false if the port is rejected.
+func resolvePortConflict(serviceName, portKeyPart string, forwarding *irtypes.ServiceToPodPortForwarding, usedPorts, allPorts map[string]bool) bool {
+	protocol := forwarding.GetProtocol()
+	if !usedPorts[getPortKey(forwarding.ServicePort.Number, protocol)] {
+		return true
+	}
+	desc := fmt.Sprintf("The port %d/%s is used more than once by the service %s. Remap it to a different port or reject it?", forwarding.ServicePort.Number, protocol, serviceName)
+	hints := []string{"A service can not have two ports with the same number and protocol"}
+	if qaengine.FetchSelectAnswer(common.JoinQASubKeys(portKeyPart, "portconflict"), desc, hints, remapConflictOption, []string{remapConflictOption, rejectConflictOption}, nil) == rejectConflictOption {
+		logrus.Warnf("Rejected the conflicting port %d/%s of the service %s", forwarding.ServicePort.Number, protocol, serviceName)
+		return false
+	}
+	defaultPort := forwarding.ServicePort.Number + 1
+	for usedPorts[getPortKey(defaultPort, protocol)] || allPorts[getPortKey(defaultPort, protocol)] {
+		defaultPort++
+	}
+	notUsedValidator := func(ans interface{}) error {
+		if usedPorts[getPortKey(cast.ToInt32(ans), protocol)] {
+			return fmt.Errorf("the port %v/%s is already used by the service %s", ans, protocol, serviceName)
+		}
+		return nil
+	}
+	port := qaengine.FetchStringAnswer(
+		common.JoinQASubKeys(portKeyPart, "remappedport"),
+		fmt.Sprintf("Enter the port to remap the conflicting port %d/%s of the service %s to:", forwarding.ServicePort.Number, protocol, serviceName),
+		[]string{"The container port stays the same"},
+		cast.ToString(defaultPort),
+		qatypes.CombineValidators(qatypes.NewPortValidator(), notUsedValidator),
+	)
+	logrus.Infof("Remapped the conflicting port %d/%s of the service %s to %s", forwarding.ServicePort.Number, protocol, serviceName, port)
+	forwarding.ServicePort.Number = cast.ToInt32(port)
+	if forwarding.ServicePort.Name != "" {
+		forwarding.ServicePort.Name += "-" + port
+	}
+	return true
+}
+
+// resolveRouteConflicts remaps or drops the ingress paths of the port which are already routed to another port
+func resolveRouteConflicts(serviceName, portKeyPart string, forwarding *irtypes.ServiceToPodPortForwarding, usedRoutes map[string]string) {
+	owner := serviceName + ":" + cast.ToString(forwarding.ServicePort.Number)
+	host, _ := getRouteHostAndPath(forwarding.ServiceRelPath)
+	relPaths := append([]string{forwarding.ServiceRelPath}, forwarding.AdditionalServiceRelPaths...)
+	resolvedRelPaths := []string{}
+	for i, relPath := range relPaths {
+		if i != 0 && host != "" {
+			// the additional paths are routed on the host of the main path, like the main path itself
+			relPath = host + relPath
+		}
+		if conflictingOwner, ok := usedRoutes[getRouteKey(relPath)]; ok && conflictingOwner != owner {
+			relPath = resolveRouteConflict(serviceName, portKeyPart, relPath, conflictingOwner, usedRoutes)
+			if relPath == "" {
+				continue
+			}
+		}
+		usedRoutes[getRouteKey(relPath)] = owner
+		resolvedRelPaths = append(resolvedRelPaths, relPath)
+	}
+	if len(resolvedRelPaths) == 0 {
+		logrus.Warnf("Not exposing the port %d of the service %s through the ingress, since all its paths conflict with other services", forwarding.ServicePort.Number, serviceName)
+		forwarding.ServiceRelPath = ""
+		forwarding.AdditionalServiceRelPaths = nil
+		return
+	}
+	forwarding.ServiceRelPath = resolvedRelPaths[0]
+	forwarding.AdditionalServiceRelPaths = nil
+	for _, relPath := range resolvedRelPaths[1:] {
+		_, path := getRouteHostAndPath(relPath)
+		forwarding.AdditionalServiceRelPaths = append(forwarding.AdditionalServiceRelPaths, path)
+	}
+}
+
+// resolveRouteConflict asks whether to remap or reject the ingress path and returns the remapped path, or an empty string if it is rejected
+func resolveRouteConflict(serviceName, portKeyPart, relPath, conflictingOwner string, usedRoutes map[string]string) string {
+	host, path := getRouteHostAndPath(relPath)
+	route := path
+	if host != "" {
+		route = host + " " + path
+	}
+	desc := fmt.Sprintf("The ingress path %s of the service %s is already routed to %s. Remap it to a different path or reject it?", route, serviceName, conflictingOwner)
+	hints := []string{"The ingress routes the requests for a host and path to a single service"}
+	if qaengine.FetchSelectAnswer(common.JoinQASubKeys(portKeyPart, `"`+relPath+`"`, "urlpathconflict"), desc, hints, remapConflictOption, []string{remapConflictOption, rejectConflictOption}, nil) == rejectConflictOption {
+		logrus.Warnf("Rejected the ingress path %s of the service %s, since it is already routed to %s", route, serviceName, conflictingOwner)
+		return ""
+	}
+	defaultRelPath := host + "/" + serviceName + strings.TrimSuffix(path, "/")
+	for i := 2; usedRoutes[getRouteKey(defaultRelPath)] != ""; i++ {
+		defaultRelPath = host + "/" + serviceName + cast.ToString(i) + strings.TrimSuffix(path, "/")
+	}
+	notUsedValidator := func(ans interface{}) error {
+		if conflictingOwner, ok := usedRoutes[getRouteKey(cast.ToString(ans))]; ok {
+			return fmt.Errorf("the path %v is already routed to %s", ans, conflictingOwner)
+		}
+		return nil
+	}
+	remappedRelPath := qaengine.FetchStringAnswer(
+		common.JoinQASubKeys(portKeyPart, `"`+relPath+`"`, "remappedurlpath"),
+		fmt.Sprintf("Enter the ingress path to remap the conflicting path %s of the service %s to:", route, serviceName),
+		[]string{"Leave out leading / to use first part as subdomain"},
+		defaultRelPath,
+		notUsedValidator,
+	)
+	logrus.Infof("Remapped the conflicting ingress path %s of the service %s to %s", route, serviceName, remappedRelPath)
+	return remappedRelPath
+}
+
+// getRouteHostAndPath splits the ingress path into the subdomain and the path, like the services do when creating the ingress
+func getRouteHostAndPath(relPath string) (string, string) {
+	if strings.HasPrefix(relPath, "/") {
+		return "", relPath
+	}
+	parts := strings.SplitN(relPath, "/", 2)
+	if len(parts) == 1 {
+		return parts[0], "/"
+	}
+	return parts[0], "/" + parts[1]
+}
+
+// getRouteKey normalizes the ingress path so that the paths differing only by a trailing slash conflict
+func getRouteKey(relPath string) string {
+	host, path := getRouteHostAndPath(relPath)
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return host + path
+}
+
+// getPortKey returns the key identifying the port of a service
+func getPortKey(port int32, protocol core.Protocol) string {
+	return cast.ToString(port) + "/" + string(protocol)
+}
@@ -0,0 +1,82 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestPortConflictPreprocessor(t *testing.T) {
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+
+	getForwarding := func(port int32, relPath string, additionalRelPaths ...string) irtypes.ServiceToPodPortForwarding {
+		return irtypes.ServiceToPodPortForwarding{
+			ServicePort:               networking.ServiceBackendPort{Number: port},
+			PodPort:                   networking.ServiceBackendPort{Number: port},
+			ServiceRelPath:            relPath,
+			AdditionalServiceRelPaths: additionalRelPaths,
+			ServiceType:               core.ServiceTypeClusterIP,
+		}
+	}
+
+	t.Run("remap the ingress paths routed to another service", func(t *testing.T) {
+		ir := irtypes.NewIR()
+		ir.Services["svc1"] = irtypes.Service{Name: "svc1", ServiceToPodPortForwardings: []irtypes.ServiceToPodPortForwarding{getForwarding(8080, "/api", "/docs")}}
+		ir.Services["svc2"] = irtypes.Service{Name: "svc2", ServiceToPodPortForwardings: []irtypes.ServiceToPodPortForwarding{getForwarding(8080, "/api/", "/svc2")}}
+		ir.Services["svc3"] = irtypes.Service{Name: "svc3", ServiceToPodPortForwardings: []irtypes.ServiceToPodPortForwarding{getForwarding(8080, "svc3/api")}}
+		want := []irtypes.ServiceToPodPortForwarding{getForwarding(8080, "/svc2/api", "/svc2")}
+
+		actual, err := (&portConflictPreprocessor{}).preprocess(ir)
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if !cmp.Equal(actual.Services["svc2"].ServiceToPodPortForwardings, want) {
+			t.Fatalf("the conflicting ingress path was not remapped. Differences:\n%s", cmp.Diff(want, actual.Services["svc2"].ServiceToPodPortForwardings))
+		}
+		if got := actual.Services["svc3"].ServiceToPodPortForwardings[0].ServiceRelPath; got != "svc3/api" {
+			t.Fatalf("the path on a different host should not conflict. Expected: svc3/api Actual: %s", got)
+		}
+	})
+
+	t.Run("remap the ports used more than once by a service", func(t *testing.T) {
+		ir := irtypes.NewIR()
+		ir.Services["svc1"] = irtypes.Service{Name: "svc1", ServiceToPodPortForwardings: []irtypes.ServiceToPodPortForwarding{
+			getForwarding(8080, ""),
+			getForwarding(8080, ""),
+			getForwarding(8081, ""),
+			{ServicePort: networking.ServiceBackendPort{Number: 8080}, PodPort: networking.ServiceBackendPort{Number: 53}, Protocol: core.ProtocolUDP},
+		}}
+
+		actual, err := (&portConflictPreprocessor{}).preprocess(ir)
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		ports := []int32{}
+		for _, forwarding := range actual.Services["svc1"].ServiceToPodPortForwardings {
+			ports = append(ports, forwarding.ServicePort.Number)
+		}
+		if want := []int32{8080, 8082, 8081, 8080}; !cmp.Equal(ports, want) {
+			t.Fatalf("the conflicting port was not remapped. Expected: %v Actual: %v", want, ports)
+		}
+	})
+}
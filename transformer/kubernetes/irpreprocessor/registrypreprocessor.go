@@ -70,8 +70,9 @@ func (p registryPreProcessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
 
 				// if it's a pre-existing image then find the registry where the image exists
 
+				// the images of the Artifact Registries have both the project and the repository in their paths
 				parts := strings.Split(container.Image, "/")
-				if len(parts) == 3 {
+				if len(parts) >= 3 {
 					usedRegistries = append(usedRegistries, parts[0])
 				}
 			}
@@ -152,11 +153,13 @@ func (p registryPreProcessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
 		case usernamePasswordLogin:
 			createPullSecret = true
 			qaUsernameKey := fmt.Sprintf(common.ConfigImageRegistryUserNameKey, `"`+registry+`"`)
-			regAuth.Username = qaengine.FetchStringAnswer(qaUsernameKey, fmt.Sprintf("[%s] Enter the username to login into the registry : ", registry), nil, "iamapikey", nil)
+			defaultUsername, usernameHints := commonqa.GetImageRegistryInfo(registry).GetDefaultUsername()
+			regAuth.Username = qaengine.FetchStringAnswer(qaUsernameKey, fmt.Sprintf("[%s] Enter the username to login into the registry : ", registry), usernameHints, defaultUsername, nil)
 			qaPasswordKey := fmt.Sprintf(common.ConfigImageRegistryPasswordKey, `"`+registry+`"`)
 			regAuth.Password = qaengine.FetchPasswordAnswer(qaPasswordKey, fmt.Sprintf("[%s] Enter the password to login into the registry : ", registry), nil, nil)
 		case dockerConfigLogin:
 			createPullSecret = true
+			common.AddSecretValues(regAuth.Password, regAuth.Auth, regAuth.IdentityToken, regAuth.RegistryToken)
 			logrus.Debugf("using the credentials from the docker config.json file")
 		}
 		if createPullSecret {
@@ -191,7 +194,7 @@ func (p registryPreProcessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
 				service.Containers[i] = container
 			}
 			parts := strings.Split(container.Image, "/")
-			if len(parts) != 3 {
+			if len(parts) < 3 {
 				continue
 			}
 			reg := parts[0]
@@ -17,10 +17,17 @@
 package irpreprocessor
 
 import (
+	"fmt"
+	"math"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
 	irtypes "github.com/konveyor/move2kube/types/ir"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
 	"github.com/konveyor/move2kube/types/qaengine/commonqa"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
+	core "k8s.io/kubernetes/pkg/apis/core"
 )
 
 // replicaOptimizer sets the minimum number of replicas
@@ -42,8 +49,23 @@ func (ep replicaPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
 		if scObj.Replicas < replicaCount {
 			scObj.Replicas = replicaCount
 		}
+		if isReplicatedService(scObj) {
+			// the replicas found in the source, like the instances of the Cloud Foundry apps, are the default
+			scObj.Replicas = cast.ToInt(qaengine.FetchStringAnswer(
+				common.JoinQASubKeys(common.ConfigServicesKey, `"`+k+`"`, common.ConfigReplicasKeySuffix),
+				fmt.Sprintf("Enter the number of replicas of the service %s :", k),
+				[]string{"The number of replicas in the source, or the minimum number of replicas, is used by default"},
+				cast.ToString(scObj.Replicas),
+				qatypes.NewRangeValidator(0, math.MaxInt32),
+			))
+		}
 		ir.Services[k] = scObj
 	}
 
 	return ir, nil
 }
+
+// isReplicatedService returns true if the service is deployed as a workload with a number of replicas
+func isReplicatedService(service irtypes.Service) bool {
+	return !service.OnlyIngress && !service.Daemon && service.RestartPolicy != core.RestartPolicyNever && service.RestartPolicy != core.RestartPolicyOnFailure
+}
@@ -0,0 +1,93 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestReplicaPreprocessor(t *testing.T) {
+	getIR := func() irtypes.IR {
+		ir := irtypes.NewIR()
+		api := irtypes.NewServiceWithName("api")
+		api.Replicas = 4
+		ir.Services["api"] = api
+		ir.Services["web"] = irtypes.NewServiceWithName("web")
+		agent := irtypes.NewServiceWithName("agent")
+		agent.Daemon = true
+		ir.Services["agent"] = agent
+		return ir
+	}
+	getReplicas := func(ir irtypes.IR) map[string]int {
+		replicas := map[string]int{}
+		for name, service := range ir.Services {
+			replicas[name] = service.Replicas
+		}
+		return replicas
+	}
+
+	t.Run("the replicas in the source or the minimum replicas are the defaults", func(t *testing.T) {
+		setupRolloutStrategyQA(t)
+		ir, err := replicaPreprocessor{}.preprocess(getIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if diff := cmp.Diff(map[string]int{"api": 4, "web": minReplicas, "agent": minReplicas}, getReplicas(ir)); diff != "" {
+			t.Fatalf("the replicas are not correct. Diff (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("the replicas of each service can be set", func(t *testing.T) {
+		setupRolloutStrategyQA(t,
+			common.ConfigMinReplicasKey+`="3"`,
+			common.JoinQASubKeys(common.ConfigServicesKey, `"web"`, common.ConfigReplicasKeySuffix)+`="1"`,
+			common.JoinQASubKeys(common.ConfigServicesKey, `"agent"`, common.ConfigReplicasKeySuffix)+`="5"`,
+		)
+		ir, err := replicaPreprocessor{}.preprocess(getIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		// the daemons run one pod on each node, so their replicas are not asked for
+		if diff := cmp.Diff(map[string]int{"api": 4, "web": 1, "agent": 3}, getReplicas(ir)); diff != "" {
+			t.Fatalf("the replicas are not correct. Diff (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestIsReplicatedService(t *testing.T) {
+	service := irtypes.NewServiceWithName("svc")
+	if !isReplicatedService(service) {
+		t.Errorf("expected a long running service to be replicated")
+	}
+	for _, change := range []func(*irtypes.Service){
+		func(s *irtypes.Service) { s.OnlyIngress = true },
+		func(s *irtypes.Service) { s.Daemon = true },
+		func(s *irtypes.Service) { s.RestartPolicy = core.RestartPolicyNever },
+		func(s *irtypes.Service) { s.RestartPolicy = core.RestartPolicyOnFailure },
+	} {
+		s := irtypes.NewServiceWithName("svc")
+		change(&s)
+		if isReplicatedService(s) {
+			t.Errorf("expected the service %+v to not be replicated", s)
+		}
+	}
+}
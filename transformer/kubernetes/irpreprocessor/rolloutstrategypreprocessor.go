@@ -0,0 +1,141 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/spf13/cast"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// defaultMaxSurge, defaultMaxUnavailable and defaultRevisionHistoryLimit are the defaults of the Deployments
+	defaultMaxSurge             = "25%"
+	defaultMaxUnavailable       = "25%"
+	defaultRevisionHistoryLimit = 10
+)
+
+// rolloutStrategyPreprocessor sets how the pods of each service are replaced when it is updated, using the hints from the source as the defaults
+type rolloutStrategyPreprocessor struct {
+}
+
+func (p rolloutStrategyPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	for serviceName, service := range ir.Services {
+		if service.OnlyIngress || service.RestartPolicy == core.RestartPolicyNever || service.RestartPolicy == core.RestartPolicyOnFailure {
+			continue
+		}
+		serviceKey := `"` + serviceName + `"`
+		strategy := service.RolloutStrategy
+		// the StatefulSets and the DaemonSets always replace the pods one by one
+		if !service.Stateful && !service.Daemon {
+			defaultType := strategy.Type
+			if defaultType == "" {
+				defaultType = irtypes.RollingUpdateRolloutStrategy
+				if usesReadWriteOnceClaim(ir, service) {
+					defaultType = irtypes.RecreateRolloutStrategy
+				}
+			}
+			strategy.Type = irtypes.RolloutStrategyType(qaengine.FetchSelectAnswer(
+				common.JoinQASubKeys(common.ConfigServicesKey, serviceKey, common.ConfigRolloutStrategyKeySuffix),
+				fmt.Sprintf("Select how the pods of the service %s should be replaced when it is updated :", serviceName),
+				[]string{
+					"RollingUpdate : the new pods are started before the old pods are stopped, so the service stays available",
+					"Recreate : all the old pods are stopped before the new pods are started, like the services using a ReadWriteOnce volume need",
+				},
+				string(defaultType),
+				[]string{string(irtypes.RollingUpdateRolloutStrategy), string(irtypes.RecreateRolloutStrategy)},
+				nil,
+			))
+			if strategy.Type == irtypes.RollingUpdateRolloutStrategy {
+				strategy.MaxSurge = fetchIntOrPercentAnswer(
+					common.JoinQASubKeys(common.ConfigServicesKey, serviceKey, common.ConfigRolloutMaxSurgeKeySuffix),
+					fmt.Sprintf("Enter the number or percentage of pods of the service %s which can be created above the replicas during a rolling update :", serviceName),
+					getOrDefault(strategy.MaxSurge, defaultMaxSurge),
+				)
+				strategy.MaxUnavailable = fetchIntOrPercentAnswer(
+					common.JoinQASubKeys(common.ConfigServicesKey, serviceKey, common.ConfigRolloutMaxUnavailableKeySuffix),
+					fmt.Sprintf("Enter the number or percentage of pods of the service %s which can be unavailable during a rolling update :", serviceName),
+					getOrDefault(strategy.MaxUnavailable, defaultMaxUnavailable),
+				)
+				if isZeroIntOrPercent(strategy.MaxSurge) && isZeroIntOrPercent(strategy.MaxUnavailable) {
+					strategy.MaxUnavailable = "1"
+				}
+			} else {
+				strategy.MaxSurge = ""
+				strategy.MaxUnavailable = ""
+			}
+		}
+		defaultLimit := int32(defaultRevisionHistoryLimit)
+		if strategy.RevisionHistoryLimit != nil {
+			defaultLimit = *strategy.RevisionHistoryLimit
+		}
+		limit := cast.ToInt32(qaengine.FetchStringAnswer(
+			common.JoinQASubKeys(common.ConfigServicesKey, serviceKey, common.ConfigRolloutRevisionHistoryLimitKeySuffix),
+			fmt.Sprintf("Enter the number of old revisions of the service %s which should be kept to roll back to :", serviceName),
+			[]string{"Each revision is kept in the cluster as a ReplicaSet or a ControllerRevision"},
+			cast.ToString(defaultLimit),
+			qatypes.NewRangeValidator(0, math.MaxInt32),
+		))
+		strategy.RevisionHistoryLimit = &limit
+		service.RolloutStrategy = strategy
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// fetchIntOrPercentAnswer asks for a number or a percentage of pods
+func fetchIntOrPercentAnswer(quesKey, desc, def string) string {
+	return strings.TrimSpace(qaengine.FetchStringAnswer(quesKey, desc, []string{"Ex : 1 or 25%"}, def, qatypes.NewIntOrPercentValidator()))
+}
+
+// getOrDefault returns the value, or the default when it is empty
+func getOrDefault(value, def string) string {
+	if value == "" {
+		return def
+	}
+	return value
+}
+
+// isZeroIntOrPercent returns true for 0 and 0%, the max surge and the max unavailable can not both be zero
+func isZeroIntOrPercent(value string) bool {
+	return cast.ToInt(strings.TrimSuffix(value, "%")) == 0
+}
+
+// usesReadWriteOnceClaim returns true if the service mounts a claim which can be mounted by the pods on only one node
+func usesReadWriteOnceClaim(ir irtypes.IR, service irtypes.Service) bool {
+	for _, volume := range service.Volumes {
+		if volume.PersistentVolumeClaim == nil || volume.PersistentVolumeClaim.ReadOnly {
+			continue
+		}
+		for _, storage := range ir.Storages {
+			if storage.Name != volume.PersistentVolumeClaim.ClaimName || storage.StorageType != irtypes.PVCKind {
+				continue
+			}
+			if len(storage.AccessModes) == 0 || common.IsPresent(storage.AccessModes, core.ReadWriteOnce) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func setupRolloutStrategyQA(t *testing.T, configs ...string) {
+	t.Helper()
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", configs, nil, nil, false)
+}
+
+func getRolloutStrategyTestIR() irtypes.IR {
+	ir := irtypes.NewIR()
+	ir.Services["api"] = irtypes.NewServiceWithName("api")
+	data := irtypes.NewServiceWithName("data")
+	data.Volumes = []core.Volume{{Name: "data", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}}}
+	ir.Services["data"] = data
+	ir.Storages = []irtypes.Storage{{Name: "data", StorageType: irtypes.PVCKind}}
+	swarm := irtypes.NewServiceWithName("swarm")
+	swarm.RolloutStrategy = irtypes.RolloutStrategy{Type: irtypes.RollingUpdateRolloutStrategy, MaxSurge: "0", MaxUnavailable: "2"}
+	ir.Services["swarm"] = swarm
+	db := irtypes.NewServiceWithName("db")
+	db.Stateful = true
+	ir.Services["db"] = db
+	job := irtypes.NewServiceWithName("job")
+	job.RestartPolicy = core.RestartPolicyOnFailure
+	ir.Services["job"] = job
+	return ir
+}
+
+func TestRolloutStrategyPreprocessor(t *testing.T) {
+	limit := func(limit int32) *int32 { return &limit }
+
+	t.Run("the hints from the source are the defaults", func(t *testing.T) {
+		setupRolloutStrategyQA(t)
+		ir, err := rolloutStrategyPreprocessor{}.preprocess(getRolloutStrategyTestIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		want := map[string]irtypes.RolloutStrategy{
+			"api":   {Type: irtypes.RollingUpdateRolloutStrategy, MaxSurge: defaultMaxSurge, MaxUnavailable: defaultMaxUnavailable, RevisionHistoryLimit: limit(defaultRevisionHistoryLimit)},
+			"data":  {Type: irtypes.RecreateRolloutStrategy, RevisionHistoryLimit: limit(defaultRevisionHistoryLimit)},
+			"swarm": {Type: irtypes.RollingUpdateRolloutStrategy, MaxSurge: "0", MaxUnavailable: "2", RevisionHistoryLimit: limit(defaultRevisionHistoryLimit)},
+			"db":    {RevisionHistoryLimit: limit(defaultRevisionHistoryLimit)},
+			"job":   {},
+		}
+		got := map[string]irtypes.RolloutStrategy{}
+		for name, service := range ir.Services {
+			got[name] = service.RolloutStrategy
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("the rollout strategies are not correct. Diff (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("the answers override the hints", func(t *testing.T) {
+		apiKey := common.JoinQASubKeys(common.ConfigServicesKey, `"api"`)
+		dataKey := common.JoinQASubKeys(common.ConfigServicesKey, `"data"`)
+		setupRolloutStrategyQA(t,
+			common.JoinQASubKeys(apiKey, common.ConfigRolloutMaxSurgeKeySuffix)+`="0%"`,
+			common.JoinQASubKeys(apiKey, common.ConfigRolloutMaxUnavailableKeySuffix)+`="0"`,
+			common.JoinQASubKeys(apiKey, common.ConfigRolloutRevisionHistoryLimitKeySuffix)+`="3"`,
+			common.JoinQASubKeys(dataKey, common.ConfigRolloutStrategyKeySuffix)+`="RollingUpdate"`,
+			common.JoinQASubKeys(dataKey, common.ConfigRolloutMaxSurgeKeySuffix)+`="1"`,
+		)
+		ir, err := rolloutStrategyPreprocessor{}.preprocess(getRolloutStrategyTestIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		// the max surge and the max unavailable can not both be zero
+		wantAPI := irtypes.RolloutStrategy{Type: irtypes.RollingUpdateRolloutStrategy, MaxSurge: "0%", MaxUnavailable: "1", RevisionHistoryLimit: limit(3)}
+		if diff := cmp.Diff(wantAPI, ir.Services["api"].RolloutStrategy); diff != "" {
+			t.Errorf("the rollout strategy of the api is not correct. Diff (-want +got):\n%s", diff)
+		}
+		wantData := irtypes.RolloutStrategy{Type: irtypes.RollingUpdateRolloutStrategy, MaxSurge: "1", MaxUnavailable: defaultMaxUnavailable, RevisionHistoryLimit: limit(defaultRevisionHistoryLimit)}
+		if diff := cmp.Diff(wantData, ir.Services["data"].RolloutStrategy); diff != "" {
+			t.Errorf("the rollout strategy of the data service is not correct. Diff (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestUsesReadWriteOnceClaim(t *testing.T) {
+	ir := getRolloutStrategyTestIR()
+	claimVolume := func(readOnly bool) []core.Volume {
+		return []core.Volume{{Name: "data", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data", ReadOnly: readOnly}}}}
+	}
+	testCases := []struct {
+		accessModes []core.PersistentVolumeAccessMode
+		readOnly    bool
+		want        bool
+	}{
+		{want: true},
+		{accessModes: []core.PersistentVolumeAccessMode{core.ReadWriteOnce}, want: true},
+		{accessModes: []core.PersistentVolumeAccessMode{core.ReadWriteMany}, want: false},
+		{readOnly: true, want: false},
+	}
+	for _, tc := range testCases {
+		ir.Storages[0].AccessModes = tc.accessModes
+		service := irtypes.NewServiceWithName("data")
+		service.Volumes = claimVolume(tc.readOnly)
+		if got := usesReadWriteOnceClaim(ir, service); got != tc.want {
+			t.Errorf("usesReadWriteOnceClaim() with the access modes %v and read only %t = %t, want %t", tc.accessModes, tc.readOnly, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"github.com/konveyor/move2kube/common"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+)
+
+// secretsPreprocessor registers the values of the secrets and credential environment variables so that they are masked in the logs and reports
+type secretsPreprocessor struct {
+}
+
+func (p secretsPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	for _, storage := range ir.Storages {
+		if storage.StorageType != irtypes.SecretKind && storage.StorageType != irtypes.PullSecretKind {
+			continue
+		}
+		for _, value := range storage.Content {
+			common.AddSecretValues(string(value))
+		}
+	}
+	for _, service := range ir.Services {
+		for _, container := range append(service.InitContainers, service.Containers...) {
+			for _, env := range container.Env {
+				if env.Value != "" && common.IsSecretName(env.Name) {
+					common.AddSecretValues(env.Value)
+				}
+			}
+		}
+	}
+	return ir, nil
+}
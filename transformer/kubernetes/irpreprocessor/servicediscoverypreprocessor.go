@@ -0,0 +1,214 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// HostSubstitution is a host name or an IP address of a service which was replaced with the name of its k8s service
+type HostSubstitution struct {
+	// Service is the service whose environment variable was rewritten, empty for the config maps
+	Service string `yaml:"service,omitempty" json:"service,omitempty"`
+	// ConfigMap is the config map whose file was rewritten, empty for the environment variables
+	ConfigMap string `yaml:"configMap,omitempty" json:"configMap,omitempty"`
+	// Key is the name of the environment variable or the key of the file in the config map
+	Key     string `yaml:"key" json:"key"`
+	Host    string `yaml:"host" json:"host"`
+	DNSName string `yaml:"dnsName" json:"dnsName"`
+}
+
+var (
+	// hostSubstitutions are keyed by the location and the host, since the preprocessors run for each of the transformers
+	hostSubstitutions      = map[string]HostSubstitution{}
+	hostSubstitutionsMutex sync.Mutex
+)
+
+// ResetHostSubstitutions clears the substitutions made during a previous transformation
+func ResetHostSubstitutions() {
+	hostSubstitutionsMutex.Lock()
+	defer hostSubstitutionsMutex.Unlock()
+	hostSubstitutions = map[string]HostSubstitution{}
+}
+
+// GetHostSubstitutions returns the substitutions made during the transformation, sorted by their location
+func GetHostSubstitutions() []HostSubstitution {
+	hostSubstitutionsMutex.Lock()
+	defer hostSubstitutionsMutex.Unlock()
+	substitutions := []HostSubstitution{}
+	for _, substitution := range hostSubstitutions {
+		substitutions = append(substitutions, substitution)
+	}
+	sort.Slice(substitutions, func(i, j int) bool {
+		si, sj := substitutions[i], substitutions[j]
+		if si.Service+si.ConfigMap != sj.Service+sj.ConfigMap {
+			return si.Service+si.ConfigMap < sj.Service+sj.ConfigMap
+		}
+		if si.Key != sj.Key {
+			return si.Key < sj.Key
+		}
+		return si.Host < sj.Host
+	})
+	return substitutions
+}
+
+func recordHostSubstitution(substitution HostSubstitution) {
+	hostSubstitutionsMutex.Lock()
+	defer hostSubstitutionsMutex.Unlock()
+	key := strings.Join([]string{substitution.Service, substitution.ConfigMap, substitution.Key, substitution.Host}, "/")
+	if _, ok := hostSubstitutions[key]; !ok {
+		logrus.Infof("Replaced the host %s with %s in %s", substitution.Host, substitution.DNSName, getHostSubstitutionLocation(substitution))
+	}
+	hostSubstitutions[key] = substitution
+}
+
+// getHostSubstitutionLocation describes where the substitution was made
+func getHostSubstitutionLocation(substitution HostSubstitution) string {
+	if substitution.ConfigMap != "" {
+		return fmt.Sprintf("the file %s of the config map %s", substitution.Key, substitution.ConfigMap)
+	}
+	return fmt.Sprintf("the environment variable %s of the service %s", substitution.Key, substitution.Service)
+}
+
+// serviceDiscoveryPreprocessor replaces the other host names and IP addresses of the services, like the container names and the static IP addresses,
+// in the environment variables and the config files with the names of the k8s services, since those are the names which resolve in the cluster
+type serviceDiscoveryPreprocessor struct {
+}
+
+func (p serviceDiscoveryPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	aliasServiceNames := getAliasServiceNames(ir)
+	if len(aliasServiceNames) == 0 {
+		return ir, nil
+	}
+	// only the aliases which are used are asked about
+	usedAliases := []string{}
+	mapHostValues(ir, func(_ HostSubstitution, value string) string {
+		for alias := range aliasServiceNames {
+			if !common.IsPresent(usedAliases, alias) && replaceHost(value, alias, aliasServiceNames[alias]) != value {
+				usedAliases = append(usedAliases, alias)
+			}
+		}
+		return value
+	})
+	if len(usedAliases) == 0 {
+		return ir, nil
+	}
+	sort.Strings(usedAliases)
+	hints := []string{"The environment variables and the config files reach these services using names which do not resolve in the cluster. The selected ones are replaced with the names of the k8s services:"}
+	for _, alias := range usedAliases {
+		hints = append(hints, alias+" -> "+aliasServiceNames[alias])
+	}
+	selectedAliases := qaengine.FetchMultiSelectAnswer(
+		common.ConfigServiceDiscoveryAliasesKey,
+		"Select the host names and IP addresses of the services which should be replaced with the in-cluster DNS names of their services :",
+		hints,
+		usedAliases,
+		usedAliases,
+		nil,
+	)
+	if len(selectedAliases) == 0 {
+		return ir, nil
+	}
+	mapHostValues(ir, func(location HostSubstitution, value string) string {
+		for _, alias := range selectedAliases {
+			serviceName, ok := aliasServiceNames[alias]
+			if !ok {
+				continue
+			}
+			if newValue := replaceHost(value, alias, serviceName); newValue != value {
+				value = newValue
+				location.Host = alias
+				location.DNSName = serviceName
+				recordHostSubstitution(location)
+			}
+		}
+		return value
+	})
+	return ir, nil
+}
+
+// getAliasServiceNames returns the names of the k8s services of the aliases of the services.
+// The names of the services already resolve and the aliases shared by several services are left out.
+func getAliasServiceNames(ir irtypes.IR) map[string]string {
+	aliasServiceNames := map[string]string{}
+	sharedAliases := map[string]bool{}
+	for serviceName, service := range ir.Services {
+		aliases := append([]string{}, service.Aliases...)
+		if service.Hostname != "" {
+			aliases = append(aliases, service.Hostname)
+		}
+		for _, container := range service.Containers {
+			aliases = append(aliases, container.Name)
+		}
+		for _, alias := range aliases {
+			alias = strings.ToLower(alias)
+			if _, ok := ir.Services[alias]; ok {
+				continue
+			}
+			if otherServiceName, ok := aliasServiceNames[alias]; ok && otherServiceName != serviceName {
+				sharedAliases[alias] = true
+				continue
+			}
+			aliasServiceNames[alias] = serviceName
+		}
+	}
+	for alias := range sharedAliases {
+		logrus.Debugf("The alias %s is used by several services. It is not replaced.", alias)
+		delete(aliasServiceNames, alias)
+	}
+	return aliasServiceNames
+}
+
+// mapHostValues replaces each of the environment variables and the config files which can contain host names with the value returned by the function
+func mapHostValues(ir irtypes.IR, f func(location HostSubstitution, value string) string) {
+	for serviceName, service := range ir.Services {
+		for _, containers := range [][]core.Container{service.InitContainers, service.Containers} {
+			for _, container := range containers {
+				for i, env := range container.Env {
+					if isHostValue(env.Value) {
+						container.Env[i].Value = f(HostSubstitution{Service: serviceName, Key: env.Name}, env.Value)
+					}
+				}
+			}
+		}
+	}
+	for _, storage := range ir.Storages {
+		if storage.StorageType != irtypes.ConfigMapKind {
+			continue
+		}
+		for key, content := range storage.Content {
+			if isHostValue(string(content)) {
+				storage.Content[key] = []byte(f(HostSubstitution{ConfigMap: storage.Name, Key: key}, string(content)))
+			}
+		}
+	}
+}
+
+// isHostValue returns true if the value is text which can contain host names
+func isHostValue(value string) bool {
+	return value != "" && utf8.ValidString(value)
+}
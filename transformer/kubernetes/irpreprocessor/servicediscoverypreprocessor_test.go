@@ -0,0 +1,133 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestServiceDiscoveryPreprocessor(t *testing.T) {
+	newIR := func() irtypes.IR {
+		ir := irtypes.NewIR()
+		db := irtypes.NewServiceWithName("db")
+		db.Aliases = []string{"my-postgres", "172.16.238.10", "shared"}
+		db.Containers = []core.Container{{Name: "postgres", Image: "postgres"}}
+		ir.Services["db"] = db
+		cache := irtypes.NewServiceWithName("cache")
+		cache.Aliases = []string{"shared", "web"}
+		cache.Containers = []core.Container{{Name: "cache", Image: "redis"}}
+		ir.Services["cache"] = cache
+		web := irtypes.NewServiceWithName("web")
+		web.Containers = []core.Container{{Name: "web", Image: "web", Env: []core.EnvVar{
+			{Name: "DATABASE_URL", Value: "postgres://my-postgres:5432/orders"},
+			{Name: "DB_REPLICA", Value: "172.16.238.10"},
+			{Name: "SHARED_HOST", Value: "shared"},
+			{Name: "ADMIN_URL", Value: "https://my-postgres/admin"},
+		}}}
+		web.InitContainers = []core.Container{{Name: "wait", Image: "busybox", Env: []core.EnvVar{{Name: "WAIT_FOR", Value: "postgres:5432"}}}}
+		ir.Services["web"] = web
+		ir.Storages = []irtypes.Storage{
+			{Name: "web-config", StorageType: irtypes.ConfigMapKind, Content: map[string][]byte{"app.properties": []byte("db.host=my-postgres\ndb.port=5432\n")}},
+			{Name: "web-secret", StorageType: irtypes.SecretKind, Content: map[string][]byte{"password": []byte("my-postgres")}},
+		}
+		return ir
+	}
+	setup := func(t *testing.T, configs ...string) {
+		t.Helper()
+		qaengine.Reset()
+		t.Cleanup(qaengine.Reset)
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", configs, nil, nil, false)
+		ResetHostSubstitutions()
+		t.Cleanup(ResetHostSubstitutions)
+	}
+	getEnv := func(containers []core.Container) []string {
+		values := []string{}
+		for _, env := range containers[0].Env {
+			values = append(values, env.Value)
+		}
+		return values
+	}
+
+	t.Run("the used aliases are replaced with the names of the services", func(t *testing.T) {
+		setup(t)
+		actual, err := serviceDiscoveryPreprocessor{}.preprocess(newIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		// the alias shared by several services and the https urls are left as is
+		want := []string{"postgres://db:5432/orders", "db", "shared", "https://my-postgres/admin"}
+		if got := getEnv(actual.Services["web"].Containers); !cmp.Equal(got, want) {
+			t.Fatalf("the environment variables differ. Differences:\n%s", cmp.Diff(want, got))
+		}
+		if got := getEnv(actual.Services["web"].InitContainers); !cmp.Equal(got, []string{"db:5432"}) {
+			t.Fatalf("expected the container name to be replaced in the init containers. Actual: %v", got)
+		}
+		if got := string(actual.Storages[0].Content["app.properties"]); got != "db.host=db\ndb.port=5432\n" {
+			t.Fatalf("expected the alias to be replaced in the config map. Actual: %q", got)
+		}
+		if got := string(actual.Storages[1].Content["password"]); got != "my-postgres" {
+			t.Fatalf("expected the secrets to be left as is. Actual: %q", got)
+		}
+		wantSubstitutions := []HostSubstitution{
+			{Service: "web", Key: "DATABASE_URL", Host: "my-postgres", DNSName: "db"},
+			{Service: "web", Key: "DB_REPLICA", Host: "172.16.238.10", DNSName: "db"},
+			{Service: "web", Key: "WAIT_FOR", Host: "postgres", DNSName: "db"},
+			{ConfigMap: "web-config", Key: "app.properties", Host: "my-postgres", DNSName: "db"},
+		}
+		if got := GetHostSubstitutions(); !cmp.Equal(got, wantSubstitutions) {
+			t.Fatalf("the host substitutions differ. Differences:\n%s", cmp.Diff(wantSubstitutions, got))
+		}
+	})
+
+	t.Run("only the selected aliases are replaced", func(t *testing.T) {
+		setup(t, common.ConfigServiceDiscoveryAliasesKey+`=["172.16.238.10"]`)
+		actual, err := serviceDiscoveryPreprocessor{}.preprocess(newIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		want := []string{"postgres://my-postgres:5432/orders", "db", "shared", "https://my-postgres/admin"}
+		if got := getEnv(actual.Services["web"].Containers); !cmp.Equal(got, want) {
+			t.Fatalf("the environment variables differ. Differences:\n%s", cmp.Diff(want, got))
+		}
+		if got := GetHostSubstitutions(); len(got) != 1 || got[0].Host != "172.16.238.10" {
+			t.Fatalf("expected only the substitution of the selected alias. Actual: %+v", got)
+		}
+	})
+}
+
+func TestGetAliasServiceNames(t *testing.T) {
+	ir := irtypes.NewIR()
+	api := irtypes.NewServiceWithName("api")
+	api.Hostname = "API-Server"
+	api.Aliases = []string{"backend", "worker"}
+	api.Containers = []core.Container{{Name: "api"}}
+	ir.Services["api"] = api
+	worker := irtypes.NewServiceWithName("worker")
+	worker.Aliases = []string{"backend"}
+	ir.Services["worker"] = worker
+	want := map[string]string{"api-server": "api"}
+	if got := getAliasServiceNames(ir); !cmp.Equal(got, want) {
+		t.Fatalf("the aliases differ. Differences:\n%s", cmp.Diff(want, got))
+	}
+}
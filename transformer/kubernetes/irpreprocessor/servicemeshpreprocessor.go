@@ -0,0 +1,53 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+)
+
+// serviceMeshPreprocessor annotates the services for automatic sidecar injection by the service mesh
+type serviceMeshPreprocessor struct {
+}
+
+const (
+	linkerdInjectAnnotation = "linkerd.io/inject"
+	istioInjectAnnotation   = "sidecar.istio.io/inject"
+)
+
+func (p serviceMeshPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	key, value := "", ""
+	switch commonqa.ServiceMesh() {
+	case commonqa.LinkerdServiceMesh:
+		key, value = linkerdInjectAnnotation, "enabled"
+	case commonqa.IstioServiceMesh:
+		key, value = istioInjectAnnotation, "true"
+	default:
+		return ir, nil
+	}
+	for name, service := range ir.Services {
+		if service.Annotations == nil {
+			service.Annotations = map[string]string{}
+		}
+		if _, ok := service.Annotations[key]; !ok {
+			service.Annotations[key] = value
+		}
+		ir.Services[name] = service
+	}
+	return ir, nil
+}
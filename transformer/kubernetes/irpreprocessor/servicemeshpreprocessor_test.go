@@ -0,0 +1,74 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+)
+
+func TestServiceMeshPreprocessor(t *testing.T) {
+	newIR := func() irtypes.IR {
+		ir := irtypes.NewIR()
+		ir.Services["api"] = irtypes.NewServiceWithName("api")
+		web := irtypes.NewServiceWithName("web")
+		web.Annotations = map[string]string{linkerdInjectAnnotation: "disabled", istioInjectAnnotation: "false"}
+		ir.Services["web"] = web
+		return ir
+	}
+	testCases := []struct {
+		serviceMesh string
+		want        map[string]map[string]string
+	}{
+		{serviceMesh: "None", want: map[string]map[string]string{
+			"web": {linkerdInjectAnnotation: "disabled", istioInjectAnnotation: "false"},
+		}},
+		{serviceMesh: "Linkerd", want: map[string]map[string]string{
+			"api": {linkerdInjectAnnotation: "enabled"},
+			"web": {linkerdInjectAnnotation: "disabled", istioInjectAnnotation: "false"},
+		}},
+		{serviceMesh: "Istio", want: map[string]map[string]string{
+			"api": {istioInjectAnnotation: "true"},
+			"web": {linkerdInjectAnnotation: "disabled", istioInjectAnnotation: "false"},
+		}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.serviceMesh, func(t *testing.T) {
+			qaengine.Reset()
+			t.Cleanup(qaengine.Reset)
+			qaengine.AddEngine(qaengine.NewDefaultEngine())
+			qaengine.SetupConfigFile("", []string{common.ConfigServiceMeshKey + `="` + testCase.serviceMesh + `"`}, nil, nil, false)
+			ir, err := serviceMeshPreprocessor{}.preprocess(newIR())
+			if err != nil {
+				t.Fatalf("failed to preprocess the IR. Error: %q", err)
+			}
+			got := map[string]map[string]string{}
+			for name, service := range ir.Services {
+				if len(service.Annotations) != 0 {
+					got[name] = service.Annotations
+				}
+			}
+			if !cmp.Equal(got, testCase.want) {
+				t.Fatalf("the annotations of the services are different. Difference:\n%s", cmp.Diff(testCase.want, got))
+			}
+		})
+	}
+}
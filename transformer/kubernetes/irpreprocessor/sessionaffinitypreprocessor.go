@@ -0,0 +1,73 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+)
+
+// sessionAffinityPreprocessor confirms the services which rely on sticky sessions, so that the requests of a client keep reaching the same pod
+type sessionAffinityPreprocessor struct {
+}
+
+func (p sessionAffinityPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	serviceNames := []string{}
+	detectedServiceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if service.OnlyIngress || len(service.ServiceToPodPortForwardings) == 0 {
+			continue
+		}
+		serviceNames = append(serviceNames, serviceName)
+		if service.SessionAffinity {
+			detectedServiceNames = append(detectedServiceNames, serviceName)
+		}
+	}
+	if len(serviceNames) == 0 {
+		return ir, nil
+	}
+	sort.Strings(serviceNames)
+	sort.Strings(detectedServiceNames)
+	selectedServiceNames := qaengine.FetchMultiSelectAnswer(
+		common.ConfigSessionAffinityServicesKey,
+		"Select the services which rely on sticky sessions :",
+		[]string{
+			"The services storing the sessions in memory, like the ones using JSESSIONID, are selected by default",
+			"The requests of a client are routed to the same pod, but the sessions are still lost when the pods are restarted or scaled down",
+		},
+		detectedServiceNames,
+		serviceNames,
+		nil,
+	)
+	selected := map[string]bool{}
+	for _, serviceName := range selectedServiceNames {
+		selected[serviceName] = true
+	}
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		service.SessionAffinity = selected[serviceName]
+		if service.SessionAffinity {
+			logrus.Debugf("Routing the requests of a client to the same pod of the service %s", serviceName)
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
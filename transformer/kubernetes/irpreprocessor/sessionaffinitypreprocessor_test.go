@@ -0,0 +1,88 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func getSessionAffinityTestIR() irtypes.IR {
+	ir := irtypes.NewIR()
+	for _, name := range []string{"cart", "catalog"} {
+		service := irtypes.NewServiceWithName(name)
+		service.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{{
+			ServicePort: networking.ServiceBackendPort{Number: 8080},
+			PodPort:     networking.ServiceBackendPort{Number: 8080},
+		}}
+		ir.Services[name] = service
+	}
+	cart := ir.Services["cart"]
+	cart.SessionAffinity = true
+	ir.Services["cart"] = cart
+	worker := irtypes.NewServiceWithName("worker")
+	worker.SessionAffinity = true
+	ir.Services["worker"] = worker
+	return ir
+}
+
+func TestSessionAffinityPreprocessor(t *testing.T) {
+	setup := func(t *testing.T, configs ...string) {
+		t.Helper()
+		qaengine.Reset()
+		t.Cleanup(qaengine.Reset)
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", configs, nil, nil, false)
+	}
+	getSessionAffinities := func(ir irtypes.IR) map[string]bool {
+		sessionAffinities := map[string]bool{}
+		for name, service := range ir.Services {
+			sessionAffinities[name] = service.SessionAffinity
+		}
+		return sessionAffinities
+	}
+
+	t.Run("the detected services are selected by default", func(t *testing.T) {
+		setup(t)
+		actual, err := sessionAffinityPreprocessor{}.preprocess(getSessionAffinityTestIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		// the services without ports are not asked about, so they are left as they are
+		want := map[string]bool{"cart": true, "catalog": false, "worker": true}
+		if got := getSessionAffinities(actual); !cmp.Equal(got, want) {
+			t.Fatalf("unexpected session affinities. Differences: %s", cmp.Diff(want, got))
+		}
+	})
+
+	t.Run("the selected services", func(t *testing.T) {
+		setup(t, common.ConfigSessionAffinityServicesKey+`=["catalog"]`)
+		actual, err := sessionAffinityPreprocessor{}.preprocess(getSessionAffinityTestIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		want := map[string]bool{"cart": false, "catalog": true, "worker": true}
+		if got := getSessionAffinities(actual); !cmp.Equal(got, want) {
+			t.Fatalf("unexpected session affinities. Differences: %s", cmp.Diff(want, got))
+		}
+	})
+}
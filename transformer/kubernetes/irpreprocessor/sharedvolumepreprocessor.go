@@ -0,0 +1,149 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// readWriteManySharing mounts the same ReadWriteMany claim in the pods of all the services
+	readWriteManySharing = "readwritemany"
+	// objectStorageSharing gives each service its own claim, the data has to be exchanged through an object storage instead
+	objectStorageSharing = "objectstorage"
+)
+
+// sharedVolumePreprocessor translates the volume claims which several services share to exchange data
+type sharedVolumePreprocessor struct {
+}
+
+func (p sharedVolumePreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	claimServices := getClaimServices(ir)
+	claimNames := []string{}
+	for claimName, serviceNames := range claimServices {
+		if len(serviceNames) > 1 {
+			claimNames = append(claimNames, claimName)
+		}
+	}
+	sort.Strings(claimNames)
+	for _, claimName := range claimNames {
+		serviceNames := claimServices[claimName]
+		storageIndex := -1
+		for i, storage := range ir.Storages {
+			if storage.StorageType == irtypes.PVCKind && storage.Name == claimName {
+				storageIndex = i
+				break
+			}
+		}
+		if storageIndex == -1 {
+			continue
+		}
+		if accessModes := ir.Storages[storageIndex].AccessModes; len(accessModes) == 1 && accessModes[0] == core.ReadOnlyMany {
+			logrus.Debugf("The volume claim %s is shared read only by the services %s", claimName, strings.Join(serviceNames, ", "))
+			continue
+		}
+		quotedClaimName := `"` + claimName + `"`
+		sharing := qaengine.FetchSelectAnswer(
+			fmt.Sprintf(common.ConfigStoragesSharingKey, quotedClaimName),
+			fmt.Sprintf("The volume %s is shared by the services %s. How should it be translated?", claimName, strings.Join(serviceNames, ", ")),
+			[]string{
+				"A ReadWriteOnce claim can only be mounted by the pods of a single node, so the services would fail to start on the other nodes.",
+				readWriteManySharing + " : one ReadWriteMany claim mounted by all the services, which needs a storage class supporting it like NFS, CephFS, Azure Files, EFS or Filestore",
+				objectStorageSharing + " : a claim per service, the services have to exchange the data through an object storage like S3 instead",
+			},
+			readWriteManySharing,
+			[]string{readWriteManySharing, objectStorageSharing},
+			nil,
+		)
+		if sharing == objectStorageSharing {
+			logrus.Warnf("The services %s get their own copy of the volume %s. Change them to exchange the data through an object storage", strings.Join(serviceNames, ", "), claimName)
+			ir = splitSharedClaim(ir, storageIndex, serviceNames)
+			continue
+		}
+		storage := ir.Storages[storageIndex]
+		storage.AccessModes = []core.PersistentVolumeAccessMode{core.ReadWriteMany}
+		if _, ok := storage.Resources.Requests[core.ResourceStorage]; !ok {
+			storage.Resources.Requests = core.ResourceList{core.ResourceStorage: common.DefaultPVCSize}
+		}
+		storageClassName := strings.TrimSpace(qaengine.FetchStringAnswer(
+			fmt.Sprintf(common.ConfigStoragesStorageClassKey, quotedClaimName),
+			fmt.Sprintf("Enter the storage class of the ReadWriteMany volume claim %s :", claimName),
+			[]string{"The storage class should support the ReadWriteMany access mode. Leave it empty to use the default storage class of the cluster."},
+			"",
+			nil,
+		))
+		if storageClassName != "" {
+			storage.StorageClassName = &storageClassName
+		}
+		logrus.Infof("The volume %s shared by the services %s is translated to a ReadWriteMany claim", claimName, strings.Join(serviceNames, ", "))
+		ir.Storages[storageIndex] = storage
+	}
+	return ir, nil
+}
+
+// getClaimServices returns the sorted names of the services mounting each volume claim
+func getClaimServices(ir irtypes.IR) map[string][]string {
+	claimServices := map[string][]string{}
+	for serviceName, service := range ir.Services {
+		if service.OnlyIngress {
+			continue
+		}
+		for _, volume := range service.Volumes {
+			if volume.PersistentVolumeClaim != nil {
+				claimName := volume.PersistentVolumeClaim.ClaimName
+				claimServices[claimName] = common.AppendIfNotPresent(claimServices[claimName], serviceName)
+			}
+		}
+	}
+	for _, serviceNames := range claimServices {
+		sort.Strings(serviceNames)
+	}
+	return claimServices
+}
+
+// splitSharedClaim replaces the shared claim with a copy of it for each of the services
+func splitSharedClaim(ir irtypes.IR, storageIndex int, serviceNames []string) irtypes.IR {
+	sharedStorage := ir.Storages[storageIndex]
+	ir.Storages = append(ir.Storages[:storageIndex], ir.Storages[storageIndex+1:]...)
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		claimName := common.NormalizeForMetadataName(sharedStorage.Name + "-" + serviceName)
+		volumes := []core.Volume{}
+		for _, volume := range service.Volumes {
+			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == sharedStorage.Name {
+				claim := *volume.PersistentVolumeClaim
+				claim.ClaimName = claimName
+				volume.PersistentVolumeClaim = &claim
+			}
+			volumes = append(volumes, volume)
+		}
+		service.Volumes = volumes
+		ir.Services[serviceName] = service
+		storage := sharedStorage
+		storage.Name = claimName
+		ir.Storages = append(ir.Storages, storage)
+	}
+	return ir
+}
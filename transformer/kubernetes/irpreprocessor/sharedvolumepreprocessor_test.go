@@ -0,0 +1,100 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestSharedVolumePreprocessor(t *testing.T) {
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+
+	newIR := func(accessModes ...core.PersistentVolumeAccessMode) irtypes.IR {
+		ir := irtypes.NewIR()
+		for _, serviceName := range []string{"api", "worker", "web"} {
+			service := irtypes.NewServiceWithName(serviceName)
+			if serviceName != "web" {
+				service.AddVolume(core.Volume{Name: "uploads", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "uploads"}}})
+			}
+			service.AddVolume(core.Volume{Name: "data", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data-" + serviceName}}})
+			ir.Services[serviceName] = service
+			ir.AddStorage(irtypes.Storage{Name: "data-" + serviceName, StorageType: irtypes.PVCKind})
+		}
+		ir.AddStorage(irtypes.Storage{Name: "uploads", StorageType: irtypes.PVCKind, PersistentVolumeClaimSpec: core.PersistentVolumeClaimSpec{AccessModes: accessModes}})
+		return ir
+	}
+	getStorage := func(ir irtypes.IR, name string) (irtypes.Storage, bool) {
+		for _, storage := range ir.Storages {
+			if storage.Name == name {
+				return storage, true
+			}
+		}
+		return irtypes.Storage{}, false
+	}
+
+	t.Run("translate the shared claim to a ReadWriteMany claim", func(t *testing.T) {
+		actual, err := (&sharedVolumePreprocessor{}).preprocess(newIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		storage, ok := getStorage(actual, "uploads")
+		if !ok {
+			t.Fatalf("the shared claim was removed")
+		}
+		if len(storage.AccessModes) != 1 || storage.AccessModes[0] != core.ReadWriteMany {
+			t.Fatalf("expected the shared claim to be ReadWriteMany. Actual: %v", storage.AccessModes)
+		}
+		if size := storage.Resources.Requests[core.ResourceStorage]; size.Cmp(common.DefaultPVCSize) != 0 {
+			t.Fatalf("expected the shared claim to request the default size %s. Actual: %s", common.DefaultPVCSize.String(), size.String())
+		}
+		if storage, _ := getStorage(actual, "data-api"); len(storage.AccessModes) != 0 {
+			t.Fatalf("the claim of a single service should not be changed. Actual: %v", storage.AccessModes)
+		}
+	})
+
+	t.Run("keep the claims shared read only", func(t *testing.T) {
+		actual, err := (&sharedVolumePreprocessor{}).preprocess(newIR(core.ReadOnlyMany))
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if storage, _ := getStorage(actual, "uploads"); len(storage.AccessModes) != 1 || storage.AccessModes[0] != core.ReadOnlyMany {
+			t.Fatalf("expected the read only claim to be kept. Actual: %v", storage.AccessModes)
+		}
+	})
+
+	t.Run("split the shared claim into a claim per service", func(t *testing.T) {
+		ir := newIR()
+		actual := splitSharedClaim(ir, len(ir.Storages)-1, getClaimServices(ir)["uploads"])
+		if _, ok := getStorage(actual, "uploads"); ok {
+			t.Fatalf("the shared claim should be removed")
+		}
+		for _, serviceName := range []string{"api", "worker"} {
+			claimName := "uploads-" + serviceName
+			if _, ok := getStorage(actual, claimName); !ok {
+				t.Fatalf("the claim %s was not created", claimName)
+			}
+			if got := getClaimServices(actual)[claimName]; len(got) != 1 || got[0] != serviceName {
+				t.Fatalf("expected the claim %s to be mounted by the service %s only. Actual: %v", claimName, serviceName, got)
+			}
+		}
+	})
+}
@@ -0,0 +1,200 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	customSpotNodePool            = "Custom"
+	defaultCustomSpotNodeSelector = "node.kubernetes.io/lifecycle=spot"
+)
+
+// spotNodePool stores the label and the optional taint of the spot nodes of a cloud provider
+type spotNodePool struct {
+	nodeSelector string
+	taint        string
+}
+
+// spotNodePools are the spot and preemptible nodes of the commonly used managed clusters
+var spotNodePools = map[string]spotNodePool{
+	"AKS spot node pools": {nodeSelector: "kubernetes.azure.com/scalesetpriority=spot", taint: "kubernetes.azure.com/scalesetpriority=spot:NoSchedule"},
+	"EKS spot instances":  {nodeSelector: "eks.amazonaws.com/capacityType=SPOT"},
+	"GKE spot VMs":        {nodeSelector: "cloud.google.com/gke-spot=true", taint: "cloud.google.com/gke-spot=true:NoSchedule"},
+	"Karpenter spot":      {nodeSelector: "karpenter.sh/capacity-type=spot"},
+}
+
+// spotSchedulingPreprocessor schedules the workloads which tolerate their nodes being reclaimed on the cheaper spot and preemptible nodes
+type spotSchedulingPreprocessor struct {
+}
+
+func (p spotSchedulingPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	serviceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if !service.OnlyIngress {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	if len(serviceNames) == 0 {
+		return ir, nil
+	}
+	sort.Strings(serviceNames)
+	selectedServiceNames := qaengine.FetchMultiSelectAnswer(
+		common.ConfigSpotSchedulingServicesKey,
+		"Select the services which tolerate interruptions and can run on spot or preemptible nodes :",
+		[]string{"The pods of the selected services are scheduled on the spot nodes and may be evicted when the nodes are reclaimed"},
+		[]string{},
+		serviceNames,
+		nil,
+	)
+	if len(selectedServiceNames) == 0 {
+		return ir, nil
+	}
+	nodePoolNames := []string{}
+	for nodePoolName := range spotNodePools {
+		nodePoolNames = append(nodePoolNames, nodePoolName)
+	}
+	sort.Strings(nodePoolNames)
+	nodePoolName := qaengine.FetchSelectAnswer(
+		common.ConfigSpotSchedulingNodePoolKey,
+		"Select the kind of spot nodes in the target cluster :",
+		[]string{"Custom: provide the label and the taint of the spot nodes"},
+		customSpotNodePool,
+		append(nodePoolNames, customSpotNodePool),
+		nil,
+	)
+	nodePool, ok := spotNodePools[nodePoolName]
+	if !ok {
+		nodePool = spotNodePool{
+			nodeSelector: qaengine.FetchStringAnswer(
+				common.ConfigSpotSchedulingNodeSelectorKey,
+				"Provide the label of the spot nodes :",
+				[]string{"Format: key=value"},
+				defaultCustomSpotNodeSelector,
+				validateSpotNodeSelector,
+			),
+			taint: qaengine.FetchStringAnswer(
+				common.ConfigSpotSchedulingTaintKey,
+				"Provide the taint of the spot nodes :",
+				[]string{"Format: key=value:effect", "Leave empty if the spot nodes are not tainted"},
+				"",
+				validateSpotTaint,
+			),
+		}
+	}
+	nodeSelectorKey, nodeSelectorValue, err := parseSpotNodeSelector(nodePool.nodeSelector)
+	if err != nil {
+		return ir, err
+	}
+	var toleration *core.Toleration
+	if nodePool.taint != "" {
+		t, err := parseSpotTaint(nodePool.taint)
+		if err != nil {
+			return ir, err
+		}
+		toleration = &t
+	}
+	for _, serviceName := range selectedServiceNames {
+		service, ok := ir.Services[serviceName]
+		if !ok {
+			continue
+		}
+		logrus.Debugf("Scheduling the service %s on the spot nodes with the label %s", serviceName, nodePool.nodeSelector)
+		if service.NodeSelector == nil {
+			service.NodeSelector = map[string]string{}
+		}
+		service.NodeSelector[nodeSelectorKey] = nodeSelectorValue
+		if toleration != nil && !hasToleration(service, *toleration) {
+			service.Tolerations = append(service.Tolerations, *toleration)
+		}
+		if service.PriorityClassName == "" {
+			// the other workloads preempt the interruption tolerant ones when they fall back to the regular nodes
+			service.PriorityClassName = common.InterruptionTolerantPriorityClassName
+		}
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+func validateSpotNodeSelector(ans interface{}) error {
+	_, _, err := parseSpotNodeSelector(fmt.Sprintf("%v", ans))
+	return err
+}
+
+func validateSpotTaint(ans interface{}) error {
+	taint := fmt.Sprintf("%v", ans)
+	if taint == "" {
+		return nil
+	}
+	_, err := parseSpotTaint(taint)
+	return err
+}
+
+// parseSpotNodeSelector parses a node label of the form key=value
+func parseSpotNodeSelector(nodeSelector string) (string, string, error) {
+	parts := strings.SplitN(strings.TrimSpace(nodeSelector), "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("the node label '%s' is not of the form key=value", nodeSelector)
+	}
+	return parts[0], parts[1], nil
+}
+
+// parseSpotTaint parses a node taint of the form key=value:effect into the toleration for it
+func parseSpotTaint(taint string) (core.Toleration, error) {
+	parts := strings.SplitN(strings.TrimSpace(taint), ":", 2)
+	if len(parts) != 2 {
+		return core.Toleration{}, fmt.Errorf("the taint '%s' is not of the form key=value:effect", taint)
+	}
+	effect := parts[1]
+	switch core.TaintEffect(effect) {
+	case core.TaintEffectNoSchedule, core.TaintEffectPreferNoSchedule, core.TaintEffectNoExecute:
+	default:
+		return core.Toleration{}, fmt.Errorf("the effect '%s' of the taint '%s' is not one of NoSchedule, PreferNoSchedule or NoExecute", effect, taint)
+	}
+	keyValue := strings.SplitN(parts[0], "=", 2)
+	key, value := keyValue[0], ""
+	if len(keyValue) == 2 {
+		value = keyValue[1]
+	}
+	if key == "" {
+		return core.Toleration{}, fmt.Errorf("the taint '%s' does not have a key", taint)
+	}
+	toleration := core.Toleration{Key: key, Operator: core.TolerationOpExists, Effect: core.TaintEffect(effect)}
+	if value != "" {
+		toleration.Operator = core.TolerationOpEqual
+		toleration.Value = value
+	}
+	return toleration, nil
+}
+
+func hasToleration(service irtypes.Service, toleration core.Toleration) bool {
+	for _, t := range service.Tolerations {
+		if t.MatchToleration(&toleration) {
+			return true
+		}
+	}
+	return false
+}
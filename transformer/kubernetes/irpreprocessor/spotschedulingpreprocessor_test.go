@@ -0,0 +1,148 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func getSpotSchedulingTestIR() irtypes.IR {
+	ir := irtypes.NewIR()
+	for _, name := range []string{"batch", "web"} {
+		service := irtypes.NewServiceWithName(name)
+		service.Containers = []core.Container{{Name: name, Image: name + ":latest"}}
+		ir.Services[name] = service
+	}
+	return ir
+}
+
+func TestSpotSchedulingPreprocessor(t *testing.T) {
+	setup := func(t *testing.T, configs ...string) {
+		t.Helper()
+		qaengine.Reset()
+		t.Cleanup(qaengine.Reset)
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", configs, nil, nil, false)
+	}
+
+	t.Run("no services are scheduled on spot nodes by default", func(t *testing.T) {
+		setup(t)
+		actual, err := spotSchedulingPreprocessor{}.preprocess(getSpotSchedulingTestIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		if want := getSpotSchedulingTestIR(); !cmp.Equal(actual, want) {
+			t.Fatalf("expected the IR to be unchanged. Differences: %s", cmp.Diff(want, actual))
+		}
+	})
+
+	t.Run("the selected services tolerate the taint of the node pool", func(t *testing.T) {
+		setup(t, common.ConfigSpotSchedulingServicesKey+`=["batch"]`, common.ConfigSpotSchedulingNodePoolKey+`="GKE spot VMs"`)
+		ir := getSpotSchedulingTestIR()
+		batch := ir.Services["batch"]
+		batch.Tolerations = []core.Toleration{{Key: "cloud.google.com/gke-spot", Operator: core.TolerationOpEqual, Value: "true", Effect: core.TaintEffectNoSchedule}}
+		ir.Services["batch"] = batch
+		actual, err := spotSchedulingPreprocessor{}.preprocess(ir)
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		batch = actual.Services["batch"]
+		if want := map[string]string{"cloud.google.com/gke-spot": "true"}; !cmp.Equal(batch.NodeSelector, want) {
+			t.Fatalf("unexpected node selector. Differences: %s", cmp.Diff(want, batch.NodeSelector))
+		}
+		if len(batch.Tolerations) != 1 {
+			t.Fatalf("expected the existing toleration not to be added again. Actual: %+v", batch.Tolerations)
+		}
+		if batch.PriorityClassName != common.InterruptionTolerantPriorityClassName {
+			t.Fatalf("got the priority class %q , want %q", batch.PriorityClassName, common.InterruptionTolerantPriorityClassName)
+		}
+		if web := actual.Services["web"]; web.NodeSelector != nil || web.Tolerations != nil || web.PriorityClassName != "" {
+			t.Fatalf("expected the service which is not selected to be unchanged. Actual: %+v", web)
+		}
+	})
+
+	t.Run("the custom node pool", func(t *testing.T) {
+		setup(t,
+			common.ConfigSpotSchedulingServicesKey+`=["batch","web"]`,
+			common.ConfigSpotSchedulingNodePoolKey+`="`+customSpotNodePool+`"`,
+			common.ConfigSpotSchedulingNodeSelectorKey+`="pool=cheap"`,
+			common.ConfigSpotSchedulingTaintKey+`="spot:NoExecute"`,
+		)
+		ir := getSpotSchedulingTestIR()
+		web := ir.Services["web"]
+		web.PriorityClassName = "critical"
+		web.NodeSelector = map[string]string{"zone": "a"}
+		ir.Services["web"] = web
+		actual, err := spotSchedulingPreprocessor{}.preprocess(ir)
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		wantToleration := []core.Toleration{{Key: "spot", Operator: core.TolerationOpExists, Effect: core.TaintEffectNoExecute}}
+		for name, wantNodeSelector := range map[string]map[string]string{
+			"batch": {"pool": "cheap"},
+			"web":   {"zone": "a", "pool": "cheap"},
+		} {
+			service := actual.Services[name]
+			if !cmp.Equal(service.NodeSelector, wantNodeSelector) || !cmp.Equal(service.Tolerations, wantToleration) {
+				t.Fatalf("unexpected scheduling of the service %s . Node selector: %+v Tolerations: %+v", name, service.NodeSelector, service.Tolerations)
+			}
+		}
+		if actual.Services["web"].PriorityClassName != "critical" {
+			t.Fatalf("expected the existing priority class to be kept. Actual: %q", actual.Services["web"].PriorityClassName)
+		}
+	})
+}
+
+func TestParseSpotTaint(t *testing.T) {
+	testCases := []struct {
+		taint   string
+		want    core.Toleration
+		wantErr bool
+	}{
+		{taint: "key=value:NoSchedule", want: core.Toleration{Key: "key", Operator: core.TolerationOpEqual, Value: "value", Effect: core.TaintEffectNoSchedule}},
+		{taint: "key:PreferNoSchedule", want: core.Toleration{Key: "key", Operator: core.TolerationOpExists, Effect: core.TaintEffectPreferNoSchedule}},
+		{taint: "key=value", wantErr: true},
+		{taint: "key=value:Sometimes", wantErr: true},
+		{taint: "=value:NoSchedule", wantErr: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.taint, func(t *testing.T) {
+			got, err := parseSpotTaint(testCase.taint)
+			if testCase.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error for the taint %q . Actual toleration: %+v", testCase.taint, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to parse the taint %q . Error: %q", testCase.taint, err)
+			}
+			if !cmp.Equal(got, testCase.want) {
+				t.Fatalf("unexpected toleration. Differences: %s", cmp.Diff(testCase.want, got))
+			}
+		})
+	}
+	if err := validateSpotNodeSelector("novalue"); err == nil {
+		t.Fatalf("expected an error for the node label without a value")
+	}
+}
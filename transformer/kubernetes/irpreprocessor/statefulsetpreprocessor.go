@@ -0,0 +1,93 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// statefulImages are the images of the clustered and the stateful workloads, which need stable network identities
+var statefulImages = []string{"kafka", "zookeeper", "postgres", "mysql", "mariadb", "mongo", "redis", "elasticsearch", "opensearch", "cassandra", "etcd", "rabbitmq", "consul", "cockroach", "nats", "solr", "couchdb", "neo4j"}
+
+// statefulSetPreprocessor selects the services which are deployed as StatefulSets
+type statefulSetPreprocessor struct {
+}
+
+func (p statefulSetPreprocessor) preprocess(ir irtypes.IR) (irtypes.IR, error) {
+	serviceNames := []string{}
+	detected := []string{}
+	for serviceName, service := range ir.Services {
+		if service.OnlyIngress || service.Daemon || service.RestartPolicy == core.RestartPolicyNever || service.RestartPolicy == core.RestartPolicyOnFailure {
+			continue
+		}
+		serviceNames = append(serviceNames, serviceName)
+		if service.Stateful || isStatefulService(service) {
+			detected = append(detected, serviceName)
+		}
+	}
+	if len(serviceNames) == 0 {
+		return ir, nil
+	}
+	sort.Strings(serviceNames)
+	sort.Strings(detected)
+	selectedServiceNames := qaengine.FetchMultiSelectAnswer(
+		common.ConfigStatefulSetServicesKey,
+		"Select the services which should be deployed as StatefulSets :",
+		[]string{"The pods of the StatefulSets get stable names and DNS entries through a headless service, which the clustered workloads like Kafka and the databases use to find their peers"},
+		detected,
+		serviceNames,
+		nil,
+	)
+	for serviceName, service := range ir.Services {
+		stateful := common.IsPresent(selectedServiceNames, serviceName)
+		if stateful != service.Stateful {
+			logrus.Debugf("Setting the service %s to be deployed as a StatefulSet to %t", serviceName, stateful)
+		}
+		service.Stateful = stateful
+		ir.Services[serviceName] = service
+	}
+	return ir, nil
+}
+
+// isStatefulService returns true if the service persists its data in a volume claim or uses a known stateful image
+func isStatefulService(service irtypes.Service) bool {
+	for _, volume := range service.Volumes {
+		if volume.PersistentVolumeClaim != nil {
+			return true
+		}
+	}
+	for _, container := range service.Containers {
+		image := container.Image
+		image = image[strings.LastIndex(image, "/")+1:]
+		if i := strings.Index(image, ":"); i != -1 {
+			image = image[:i]
+		}
+		for _, statefulImage := range statefulImages {
+			if image == statefulImage || strings.HasSuffix(image, "-"+statefulImage) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package irpreprocessor
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestStatefulSetPreprocessor(t *testing.T) {
+	newIR := func() irtypes.IR {
+		ir := irtypes.NewIR()
+		for name, image := range map[string]string{"web": "web:latest", "kafka": "docker.io/bitnami/kafka:3.3", "db": "my-postgres:14", "worker": "worker:latest", "migrate": "migrate:latest"} {
+			service := irtypes.NewServiceWithName(name)
+			service.Containers = []core.Container{{Name: name, Image: image}}
+			ir.Services[name] = service
+		}
+		worker := ir.Services["worker"]
+		worker.Volumes = []core.Volume{{Name: "data", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}}}
+		ir.Services["worker"] = worker
+		migrate := ir.Services["migrate"]
+		migrate.RestartPolicy = core.RestartPolicyOnFailure
+		migrate.Stateful = true
+		ir.Services["migrate"] = migrate
+		return ir
+	}
+	getStatefulServices := func(ir irtypes.IR) []string {
+		statefulServices := []string{}
+		for _, name := range []string{"db", "kafka", "migrate", "web", "worker"} {
+			if ir.Services[name].Stateful {
+				statefulServices = append(statefulServices, name)
+			}
+		}
+		return statefulServices
+	}
+
+	t.Run("the stateful images and the services with volume claims are detected", func(t *testing.T) {
+		qaengine.Reset()
+		defer qaengine.Reset()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		actual, err := statefulSetPreprocessor{}.preprocess(newIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		want := []string{"db", "kafka", "worker"}
+		if got := getStatefulServices(actual); !cmp.Equal(got, want) {
+			t.Fatalf("the stateful services differ. Differences:\n%s", cmp.Diff(want, got))
+		}
+	})
+
+	t.Run("the selected services are deployed as StatefulSets", func(t *testing.T) {
+		qaengine.Reset()
+		defer qaengine.Reset()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{common.ConfigStatefulSetServicesKey + `=["web"]`}, nil, nil, false)
+		actual, err := statefulSetPreprocessor{}.preprocess(newIR())
+		if err != nil {
+			t.Fatalf("failed to preprocess the IR. Error: %q", err)
+		}
+		want := []string{"web"}
+		if got := getStatefulServices(actual); !cmp.Equal(got, want) {
+			t.Fatalf("the stateful services differ. Differences:\n%s", cmp.Diff(want, got))
+		}
+	})
+}
+
+func TestIsStatefulService(t *testing.T) {
+	testCases := map[string]bool{
+		"kafka":                     true,
+		"quay.io/strimzi/kafka:0.3": true,
+		"bitnami-redis:7":           true,
+		"mongo-express:latest":      false,
+		"registry:5000/web:1.0":     false,
+	}
+	for image, want := range testCases {
+		service := irtypes.NewServiceWithName("svc")
+		service.Containers = []core.Container{{Name: "svc", Image: image}}
+		if got := isStatefulService(service); got != want {
+			t.Errorf("got %t for the image %s , want %t", got, image, want)
+		}
+	}
+}
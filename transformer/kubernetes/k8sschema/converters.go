@@ -18,10 +18,17 @@ package k8sschema
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
+	"strings"
 
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	autoscaling "k8s.io/kubernetes/pkg/apis/autoscaling"
 	core "k8s.io/kubernetes/pkg/apis/core"
 	knativev1 "knative.dev/serving/pkg/apis/serving/v1"
 
@@ -32,6 +39,12 @@ import (
 
 // ConvertToSupportedVersion converts obj to a supported Version
 func ConvertToSupportedVersion(obj runtime.Object, clusterSpec collecttypes.ClusterMetadataSpec, setDefaultValuesInYamls bool) (runtime.Object, error) {
+	if newobj, ok := convertToAnnotatedVersion(obj); ok {
+		return newobj, nil
+	}
+	if cr, ok := obj.(*unstructured.Unstructured); ok {
+		return passThroughCustomResource(cr, clusterSpec)
+	}
 	newobj, err := convertToSupportedVersion(obj, clusterSpec, setDefaultValuesInYamls)
 	if err != nil {
 		logrus.Debugf("Unable to transform object to a supported version : %s.", err)
@@ -49,6 +62,47 @@ func ConvertToSupportedVersion(obj runtime.Object, clusterSpec collecttypes.Clus
 	return newobj, nil
 }
 
+// convertToAnnotatedVersion keeps the object in its version, or converts it to the version, set in the skip conversion annotation.
+// It returns false when the object is not annotated or the annotation can not be honoured, so that the object is converted as usual.
+func convertToAnnotatedVersion(obj runtime.Object) (runtime.Object, bool) {
+	metaObj, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, false
+	}
+	value := strings.TrimSpace(metaObj.GetAnnotations()[common.SkipConversionAnnotation])
+	if value == "" || value == "false" {
+		return nil, false
+	}
+	objgvk := obj.GetObjectKind().GroupVersionKind()
+	if value == common.AnnotationLabelValue {
+		if objgvk.Version == runtime.APIVersionInternal {
+			logrus.Warnf("The %s %s is generated by move2kube and has no apiVersion to keep. Ignoring the annotation %s . Set it to the apiVersion instead.", objgvk.Kind, metaObj.GetName(), common.SkipConversionAnnotation)
+			return nil, false
+		}
+		logrus.Debugf("Keeping the %s %s in the version %s since it has the annotation %s", objgvk.Kind, metaObj.GetName(), objgvk.GroupVersion(), common.SkipConversionAnnotation)
+		return obj, true
+	}
+	gv, err := schema.ParseGroupVersion(value)
+	if err != nil {
+		logrus.Warnf("The value %s of the annotation %s of the %s %s is neither true nor an apiVersion. Ignoring it. Error: %q", value, common.SkipConversionAnnotation, objgvk.Kind, metaObj.GetName(), err)
+		return nil, false
+	}
+	if gv == objgvk.GroupVersion() {
+		return obj, true
+	}
+	if _, ok := obj.(*unstructured.Unstructured); ok {
+		logrus.Warnf("The custom resource %s %s can not be converted to the version %s set in the annotation %s . Ignoring it.", objgvk.Kind, metaObj.GetName(), gv, common.SkipConversionAnnotation)
+		return nil, false
+	}
+	newobj, err := ConvertToVersion(obj, gv)
+	if err != nil {
+		logrus.Warnf("failed to convert the %s %s to the version %s set in the annotation %s . Ignoring it. Error: %q", objgvk.Kind, metaObj.GetName(), gv, common.SkipConversionAnnotation, err)
+		return nil, false
+	}
+	logrus.Debugf("Converted the %s %s to the version %s set in the annotation %s", objgvk.Kind, metaObj.GetName(), gv, common.SkipConversionAnnotation)
+	return newobj, true
+}
+
 // ConvertToSupportedVersion converts obj to a supported Version
 func convertToSupportedVersion(obj runtime.Object, clusterSpec collecttypes.ClusterMetadataSpec, setDefaultValuesInYamls bool) (newobj runtime.Object, err error) {
 	objgvk := obj.GetObjectKind().GroupVersionKind()
@@ -63,6 +117,10 @@ func convertToSupportedVersion(obj runtime.Object, clusterSpec collecttypes.Clus
 	if kind == common.ServiceKind && objgv.Group == knativev1.SchemeGroupVersion.Group {
 		return obj, nil
 	}
+	// when the cluster only supports older versions which can not store some of the fields, the first of them is used
+	var lossyobj runtime.Object
+	var lossygv schema.GroupVersion
+	var droppedFields []string
 	for _, v := range versions {
 		gv, err := schema.ParseGroupVersion(v)
 		if err != nil {
@@ -72,22 +130,168 @@ func convertToSupportedVersion(obj runtime.Object, clusterSpec collecttypes.Clus
 		if kind == common.ServiceKind && gv.Group == knativev1.SchemeGroupVersion.Group {
 			continue
 		}
+		if gv == autoscalingv1.SchemeGroupVersion && hasNonCPUMetrics(obj) {
+			// autoscaling/v1 only supports the CPU utilization, the other metrics would be moved to an annotation
+			continue
+		}
 		newobj, err := ConvertToVersion(obj, gv)
 		if err != nil {
 			logrus.Debugf("Unable to convert : %s", err)
 			continue
 		}
+		if gv != objgv {
+			if fields := getDroppedFields(obj, newobj, objgv); len(fields) != 0 {
+				logrus.Debugf("Converting %s to %s drops the fields %v", objgvk, gv, fields)
+				if lossyobj == nil {
+					lossyobj, lossygv, droppedFields = newobj, gv, fields
+				}
+				continue
+			}
+		}
 		if setDefaultValuesInYamls {
 			scheme.Default(newobj)
 		}
 		return newobj, err
 	}
+	if lossyobj != nil {
+		logrus.Warnf("The %s %s was converted to %s, the version supported by the target cluster, which does not support the fields %s", kind, getName(obj), lossygv, strings.Join(droppedFields, ", "))
+		if metaObj, err := meta.Accessor(lossyobj); err == nil {
+			annotations := metaObj.GetAnnotations()
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[common.TODOAnnotation+"downgrade"] = fmt.Sprintf("The fields %s of %s are not supported by %s and were dropped. Add them again when the cluster is upgraded.", strings.Join(droppedFields, ", "), objgv, lossygv)
+			metaObj.SetAnnotations(annotations)
+		}
+		if setDefaultValuesInYamls {
+			scheme.Default(lossyobj)
+		}
+		return lossyobj, nil
+	}
 	if setDefaultValuesInYamls {
 		scheme.Default(obj)
 	}
 	return obj, fmt.Errorf("unable to convert to a supported version : %+v", obj.GetObjectKind())
 }
 
+// getDroppedFields returns the fields of the object which are lost when it is converted to the older version and back to its original version
+func getDroppedFields(obj, newobj runtime.Object, objgv schema.GroupVersion) []string {
+	if objgv.Version == runtime.APIVersionInternal {
+		// the internal types can have nil quantity pointers which can not be converted to unstructured, so they are compared in an external version
+		gv, ok := getLosslessVersion(obj, objgv.Group)
+		if !ok {
+			return nil
+		}
+		objgv = gv
+	}
+	original, err := scheme.ConvertToVersion(obj, objgv)
+	if err != nil {
+		logrus.Debugf("Unable to convert the original object to %s : %s", objgv, err)
+		return nil
+	}
+	roundTripped, err := ConvertToVersion(newobj, objgv)
+	if err != nil {
+		logrus.Debugf("Unable to convert the object back to %s : %s", objgv, err)
+		return nil
+	}
+	originalMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(original)
+	if err != nil {
+		return nil
+	}
+	roundTrippedMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(roundTripped)
+	if err != nil {
+		return nil
+	}
+	delete(originalMap, "status")
+	fields := getMissingFields(originalMap, roundTrippedMap, "")
+	sort.Strings(fields)
+	return fields
+}
+
+// getLosslessVersion returns the first version of the group which stores all the fields of the internal object,
+// without moving some of them to annotations. The preferred version is returned when there is none.
+func getLosslessVersion(obj runtime.Object, group string) (schema.GroupVersion, bool) {
+	versions := scheme.PrioritizedVersionsForGroup(group)
+	if len(versions) == 0 {
+		return schema.GroupVersion{}, false
+	}
+	annotationsCount := -1
+	if metaObj, err := meta.Accessor(obj); err == nil {
+		annotationsCount = len(metaObj.GetAnnotations())
+	}
+	for _, gv := range versions {
+		newobj, err := scheme.ConvertToVersion(obj, gv)
+		if err != nil {
+			continue
+		}
+		if metaObj, err := meta.Accessor(newobj); err == nil && len(metaObj.GetAnnotations()) == annotationsCount {
+			return gv, true
+		}
+	}
+	return versions[0], true
+}
+
+// getMissingFields returns the paths of the fields in the original map which are missing or different in the other map
+func getMissingFields(original, other map[string]interface{}, prefix string) []string {
+	fields := []string{}
+	for k, v := range original {
+		path := prefix + k
+		otherV, ok := other[k]
+		if !ok {
+			if !isEmptyValue(v) {
+				fields = append(fields, path)
+			}
+			continue
+		}
+		vMap, ok1 := v.(map[string]interface{})
+		otherVMap, ok2 := otherV.(map[string]interface{})
+		if ok1 && ok2 {
+			fields = append(fields, getMissingFields(vMap, otherVMap, path+".")...)
+			continue
+		}
+		if !reflect.DeepEqual(v, otherV) {
+			fields = append(fields, path)
+		}
+	}
+	return fields
+}
+
+// isEmptyValue returns true if the value is nil, an empty map or an empty slice
+func isEmptyValue(v interface{}) bool {
+	switch tv := v.(type) {
+	case nil:
+		return true
+	case map[string]interface{}:
+		return len(tv) == 0
+	case []interface{}:
+		return len(tv) == 0
+	}
+	return false
+}
+
+// getName returns the name of the object
+func getName(obj runtime.Object) string {
+	if metaObj, err := meta.Accessor(obj); err == nil {
+		return metaObj.GetName()
+	}
+	return ""
+}
+
+// hasNonCPUMetrics returns true if the object is a horizontal pod autoscaler which scales on metrics other than the CPU utilization
+func hasNonCPUMetrics(obj runtime.Object) bool {
+	hpa, ok := obj.(*autoscaling.HorizontalPodAutoscaler)
+	if !ok {
+		return false
+	}
+	for _, metric := range hpa.Spec.Metrics {
+		if metric.Type != autoscaling.ResourceMetricSourceType || metric.Resource == nil ||
+			metric.Resource.Name != core.ResourceCPU || metric.Resource.Target.Type != autoscaling.UtilizationMetricType {
+			return true
+		}
+	}
+	return false
+}
+
 // ConvertToPreferredVersion converts obj to a preferred Version
 func ConvertToPreferredVersion(obj runtime.Object, clusterSpec collecttypes.ClusterMetadataSpec, setDefaultValuesInYamls bool) (newobj runtime.Object, err error) {
 	objgvk := obj.GetObjectKind().GroupVersionKind()
@@ -0,0 +1,203 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	autoscaling "k8s.io/kubernetes/pkg/apis/autoscaling"
+	batch "k8s.io/kubernetes/pkg/apis/batch"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func newTestHorizontalPodAutoscaler() *autoscaling.HorizontalPodAutoscaler {
+	minReplicas := int32(1)
+	utilization := int32(70)
+	window := int32(300)
+	return &autoscaling.HorizontalPodAutoscaler{
+		TypeMeta:   metav1.TypeMeta{Kind: "HorizontalPodAutoscaler", APIVersion: autoscaling.SchemeGroupVersion.String()},
+		ObjectMeta: metav1.ObjectMeta{Name: "api"},
+		Spec: autoscaling.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscaling.CrossVersionObjectReference{APIVersion: "apps/v1", Kind: common.DeploymentKind, Name: "api"},
+			MinReplicas:    &minReplicas,
+			MaxReplicas:    10,
+			Metrics: []autoscaling.MetricSpec{{
+				Type: autoscaling.ResourceMetricSourceType,
+				Resource: &autoscaling.ResourceMetricSource{
+					Name:   core.ResourceCPU,
+					Target: autoscaling.MetricTarget{Type: autoscaling.UtilizationMetricType, AverageUtilization: &utilization},
+				},
+			}},
+			Behavior: &autoscaling.HorizontalPodAutoscalerBehavior{
+				ScaleDown: &autoscaling.HPAScalingRules{StabilizationWindowSeconds: &window},
+			},
+		},
+	}
+}
+
+func TestConvertToSupportedVersionDowngrades(t *testing.T) {
+	t.Run("the object is downgraded to the only supported version", func(t *testing.T) {
+		cronJob := &batch.CronJob{
+			TypeMeta:   metav1.TypeMeta{Kind: "CronJob", APIVersion: batch.SchemeGroupVersion.String()},
+			ObjectMeta: metav1.ObjectMeta{Name: "backup"},
+			Spec:       batch.CronJobSpec{Schedule: "0 * * * *"},
+		}
+		clusterSpec := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{"CronJob": {batchv1beta1.SchemeGroupVersion.String()}}}
+		converted, err := ConvertToSupportedVersion(cronJob, clusterSpec, false)
+		if err != nil {
+			t.Fatalf("failed to convert the cron job. Error: %q", err)
+		}
+		downgraded, ok := converted.(*batchv1beta1.CronJob)
+		if !ok {
+			t.Fatalf("expected the cron job to be converted to %s . Actual: %T", batchv1beta1.SchemeGroupVersion, converted)
+		}
+		if downgraded.Spec.Schedule != "0 * * * *" {
+			t.Fatalf("expected the schedule to be kept. Actual: %q", downgraded.Spec.Schedule)
+		}
+		if len(downgraded.Annotations) != 0 {
+			t.Fatalf("expected no annotations for a lossless downgrade. Actual: %+v", downgraded.Annotations)
+		}
+	})
+
+	t.Run("the fields without a counterpart in the older version are kept in annotations", func(t *testing.T) {
+		clusterSpec := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{
+			"HorizontalPodAutoscaler": {autoscalingv2beta1.SchemeGroupVersion.String(), autoscalingv2beta2.SchemeGroupVersion.String()},
+		}}
+		converted, err := ConvertToSupportedVersion(newTestHorizontalPodAutoscaler(), clusterSpec, false)
+		if err != nil {
+			t.Fatalf("failed to convert the horizontal pod autoscaler. Error: %q", err)
+		}
+		hpa, ok := converted.(*autoscalingv2beta1.HorizontalPodAutoscaler)
+		if !ok {
+			t.Fatalf("expected the horizontal pod autoscaler to be converted to %s . Actual: %T", autoscalingv2beta1.SchemeGroupVersion, converted)
+		}
+		if len(hpa.Spec.Metrics) != 1 || hpa.Spec.Metrics[0].Resource == nil || *hpa.Spec.Metrics[0].Resource.TargetAverageUtilization != 70 {
+			t.Fatalf("expected the cpu utilization metric to be kept. Actual: %+v", hpa.Spec.Metrics)
+		}
+		if _, ok := hpa.Annotations[common.TODOAnnotation+"downgrade"]; ok {
+			t.Fatalf("expected no dropped fields since the behavior round trips through an annotation. Actual annotations: %+v", hpa.Annotations)
+		}
+	})
+}
+
+func TestGetDroppedFields(t *testing.T) {
+	// the internal metric targets have nil quantity pointers
+	hpa := newTestHorizontalPodAutoscaler()
+	converted, err := ConvertToVersion(hpa, autoscalingv2beta2.SchemeGroupVersion)
+	if err != nil {
+		t.Fatalf("failed to convert the horizontal pod autoscaler. Error: %q", err)
+	}
+	if fields := getDroppedFields(hpa, converted, autoscaling.SchemeGroupVersion); len(fields) != 0 {
+		t.Fatalf("expected no dropped fields. Actual: %v", fields)
+	}
+	lossy := converted.(*autoscalingv2beta2.HorizontalPodAutoscaler).DeepCopy()
+	lossy.Spec.Behavior = nil
+	lossy.Spec.MaxReplicas = 5
+	if fields := getDroppedFields(hpa, lossy, autoscaling.SchemeGroupVersion); !reflect.DeepEqual(fields, []string{"spec.behavior", "spec.maxReplicas"}) {
+		t.Fatalf("expected the behavior and the changed maximum number of replicas. Actual: %v", fields)
+	}
+}
+
+func TestConvertToSupportedVersionHonoursTheSkipConversionAnnotation(t *testing.T) {
+	clusterSpec := collecttypes.ClusterMetadataSpec{APIKindVersionMap: map[string][]string{"CronJob": {batchv1.SchemeGroupVersion.String()}}}
+	newCronJob := func(apiVersion, skipConversion string) *batchv1beta1.CronJob {
+		return &batchv1beta1.CronJob{
+			TypeMeta:   metav1.TypeMeta{Kind: "CronJob", APIVersion: apiVersion},
+			ObjectMeta: metav1.ObjectMeta{Name: "backup", Annotations: map[string]string{common.SkipConversionAnnotation: skipConversion}},
+			Spec:       batchv1beta1.CronJobSpec{Schedule: "0 * * * *"},
+		}
+	}
+	testCases := []struct {
+		name           string
+		obj            runtime.Object
+		wantAPIVersion string
+	}{
+		{name: "the version is kept", obj: newCronJob("batch/v1beta1", "true"), wantAPIVersion: "batch/v1beta1"},
+		{name: "the annotation set to false is ignored", obj: newCronJob("batch/v1beta1", "false"), wantAPIVersion: "batch/v1"},
+		{name: "an invalid version is ignored", obj: newCronJob("batch/v1beta1", "batch/v1/cronjob"), wantAPIVersion: "batch/v1"},
+		{
+			name: "the generated object is converted to the annotated version",
+			obj: &batch.CronJob{
+				TypeMeta:   metav1.TypeMeta{Kind: "CronJob", APIVersion: batch.SchemeGroupVersion.String()},
+				ObjectMeta: metav1.ObjectMeta{Name: "backup", Annotations: map[string]string{common.SkipConversionAnnotation: "batch/v1beta1"}},
+				Spec:       batch.CronJobSpec{Schedule: "0 * * * *"},
+			},
+			wantAPIVersion: "batch/v1beta1",
+		},
+		{
+			name: "the generated object has no version to keep",
+			obj: &batch.CronJob{
+				TypeMeta:   metav1.TypeMeta{Kind: "CronJob", APIVersion: batch.SchemeGroupVersion.String()},
+				ObjectMeta: metav1.ObjectMeta{Name: "backup", Annotations: map[string]string{common.SkipConversionAnnotation: "true"}},
+				Spec:       batch.CronJobSpec{Schedule: "0 * * * *"},
+			},
+			wantAPIVersion: "batch/v1",
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			converted, err := ConvertToSupportedVersion(testCase.obj, clusterSpec, false)
+			if err != nil {
+				t.Fatalf("failed to convert the cron job. Error: %q", err)
+			}
+			if got := converted.GetObjectKind().GroupVersionKind().GroupVersion().String(); got != testCase.wantAPIVersion {
+				t.Fatalf("got the apiVersion %s , want %s", got, testCase.wantAPIVersion)
+			}
+		})
+	}
+}
+
+func TestGetMissingFields(t *testing.T) {
+	original := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "api", "labels": map[string]interface{}{}},
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"paused":   nil,
+			"ports":    []interface{}{int64(80), int64(443)},
+			"selector": map[string]interface{}{"app": "api", "tier": "web"},
+			"strategy": map[string]interface{}{"type": "RollingUpdate"},
+			"volumes":  []interface{}{},
+		},
+	}
+	other := map[string]interface{}{
+		"metadata": map[string]interface{}{"name": "api"},
+		"spec": map[string]interface{}{
+			"replicas": int64(3),
+			"ports":    []interface{}{int64(80)},
+			"selector": map[string]interface{}{"app": "api"},
+			"strategy": "RollingUpdate",
+		},
+	}
+	fields := getMissingFields(original, other, "")
+	sort.Strings(fields)
+	if want := []string{"spec.ports", "spec.selector.tier", "spec.strategy"}; !reflect.DeepEqual(fields, want) {
+		t.Fatalf("expected the missing and changed fields %v , without the empty ones. Actual: %v", want, fields)
+	}
+	if fields := getMissingFields(original, original, ""); len(fields) != 0 {
+		t.Fatalf("expected no missing fields when comparing the object with itself. Actual: %v", fields)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	apiextensions "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiservervalidation "k8s.io/apiextensions-apiserver/pkg/apiserver/validation"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// offCustomResourceValidation passes the custom resources through without validating them
+	offCustomResourceValidation = "off"
+	// warnCustomResourceValidation logs the custom resources which are invalid for the target cluster
+	warnCustomResourceValidation = "warn"
+	// strictCustomResourceValidation fails the conversion of the custom resources which are invalid for the target cluster
+	strictCustomResourceValidation = "strict"
+)
+
+var (
+	// customResourceValidation is asked once per transformation, since it applies to all the custom resources
+	customResourceValidation     string
+	customResourceValidationOnce sync.Once
+)
+
+// InvalidCustomResourceError is returned for the custom resources which are invalid for the target cluster, when the validation is strict
+type InvalidCustomResourceError struct {
+	Name string
+	Kind string
+	Err  error
+}
+
+func (e *InvalidCustomResourceError) Error() string {
+	return fmt.Sprintf("the custom resource %s of kind %s is invalid for the target cluster. Error: %q", e.Name, e.Kind, e.Err)
+}
+
+func (e *InvalidCustomResourceError) Unwrap() error {
+	return e.Err
+}
+
+// ResetCustomResourceValidation forgets the validation chosen during a previous transformation
+func ResetCustomResourceValidation() {
+	customResourceValidation, customResourceValidationOnce = "", sync.Once{}
+}
+
+func getCustomResourceValidation() string {
+	customResourceValidationOnce.Do(func() {
+		customResourceValidation = qaengine.FetchSelectAnswer(
+			common.ConfigTargetCustomResourceValidationKey,
+			"How should the custom resources be validated against the CRDs collected from the target cluster?",
+			[]string{
+				offCustomResourceValidation + ": pass the custom resources through without validating them",
+				warnCustomResourceValidation + ": log the custom resources which do not match the schemas of the CRDs",
+				strictCustomResourceValidation + ": fail for the custom resources which do not match the schemas of the CRDs",
+			},
+			offCustomResourceValidation,
+			[]string{offCustomResourceValidation, warnCustomResourceValidation, strictCustomResourceValidation},
+			nil,
+		)
+	})
+	return customResourceValidation
+}
+
+// decodeCustomResource decodes a yaml of a kind unknown to move2kube into an unstructured object
+func decodeCustomResource(data []byte) (runtime.Object, error) {
+	// NOTE: This roundabout method is required to avoid yaml.v3 unmarshalling timestamps into time.Time
+	var objI interface{}
+	if err := yaml.Unmarshal(data, &objI); err != nil {
+		return nil, err
+	}
+	objJSONBytes, err := json.Marshal(objI)
+	if err != nil {
+		return nil, err
+	}
+	cr := &unstructured.Unstructured{}
+	if err := cr.UnmarshalJSON(objJSONBytes); err != nil {
+		return nil, err
+	}
+	return cr, nil
+}
+
+// passThroughCustomResource returns the custom resource untouched.
+// If the validation is enabled, the custom resource is checked against the CRDs installed in the target cluster.
+func passThroughCustomResource(cr *unstructured.Unstructured, clusterSpec collecttypes.ClusterMetadataSpec) (*unstructured.Unstructured, error) {
+	validation := getCustomResourceValidation()
+	if validation == offCustomResourceValidation {
+		return cr, nil
+	}
+	gvk := cr.GroupVersionKind()
+	crd := clusterSpec.GetCustomResourceDefinition(gvk.Group, gvk.Kind)
+	if crd == nil {
+		if len(clusterSpec.GetSupportedVersions(gvk.Kind)) == 0 {
+			logrus.Warnf("The custom resource %s of kind %s is not supported by the target cluster. Passing it through as is.", cr.GetName(), gvk.String())
+		}
+		return cr, nil
+	}
+	if !common.IsPresent(crd.Versions, gvk.Version) {
+		logrus.Warnf("The version %s of the custom resource %s of kind %s is not served by the target cluster. Served versions: %+v", gvk.Version, cr.GetName(), gvk.Kind, crd.Versions)
+		return cr, nil
+	}
+	if err := ValidateCustomResource(cr, *crd); err != nil {
+		if validation == strictCustomResourceValidation {
+			return cr, &InvalidCustomResourceError{Name: cr.GetName(), Kind: gvk.String(), Err: err}
+		}
+		logrus.Warnf("The custom resource %s of kind %s is invalid for the target cluster. Error: %q", cr.GetName(), gvk.String(), err)
+	}
+	return cr, nil
+}
+
+// ValidateCustomResource validates the custom resource against the schema collected from the target cluster.
+// Validation is skipped if no schema was collected for the version of the custom resource.
+func ValidateCustomResource(cr *unstructured.Unstructured, crd collecttypes.CustomResourceDefinition) error {
+	schemaI, ok := crd.Schemas[cr.GroupVersionKind().Version]
+	if !ok || schemaI == nil {
+		logrus.Debugf("No schema found for the custom resource definition %s version %s . Skipping validation.", crd.Name, cr.GroupVersionKind().Version)
+		return nil
+	}
+	schemaJSONBytes, err := json.Marshal(schemaI)
+	if err != nil {
+		return fmt.Errorf("failed to marshal the schema of the custom resource definition %s . Error: %w", crd.Name, err)
+	}
+	v1Props := apiextensionsv1.JSONSchemaProps{}
+	if err := json.Unmarshal(schemaJSONBytes, &v1Props); err != nil {
+		return fmt.Errorf("failed to parse the schema of the custom resource definition %s . Error: %w", crd.Name, err)
+	}
+	props := apiextensions.JSONSchemaProps{}
+	if err := apiextensionsv1.Convert_v1_JSONSchemaProps_To_apiextensions_JSONSchemaProps(&v1Props, &props, nil); err != nil {
+		return fmt.Errorf("failed to convert the schema of the custom resource definition %s . Error: %w", crd.Name, err)
+	}
+	validator, _, err := apiservervalidation.NewSchemaValidator(&apiextensions.CustomResourceValidation{OpenAPIV3Schema: &props})
+	if err != nil {
+		return fmt.Errorf("failed to create a validator for the custom resource definition %s . Error: %w", crd.Name, err)
+	}
+	if errs := apiservervalidation.ValidateCustomResource(nil, cr.UnstructuredContent(), validator); len(errs) > 0 {
+		return errs.ToAggregate()
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestPassThroughCustomResource(t *testing.T) {
+	clusterSpec := collecttypes.ClusterMetadataSpec{
+		CustomResourceDefinitions: []collecttypes.CustomResourceDefinition{{
+			Name:     "widgets.example.com",
+			Group:    "example.com",
+			Kind:     "Widget",
+			Versions: []string{"v1"},
+			Schemas: map[string]interface{}{"v1": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"spec": map[string]interface{}{
+						"type":       "object",
+						"required":   []interface{}{"size"},
+						"properties": map[string]interface{}{"size": map[string]interface{}{"type": "integer"}},
+					},
+				},
+			}},
+		}},
+	}
+	newWidget := func(size interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "example.com/v1",
+			"kind":       "Widget",
+			"metadata":   map[string]interface{}{"name": "mywidget"},
+			"spec":       map[string]interface{}{"size": size},
+		}}
+	}
+	testCases := []struct {
+		name       string
+		validation string
+		size       interface{}
+		wantErr    bool
+	}{
+		{name: "valid custom resource with strict validation", validation: strictCustomResourceValidation, size: int64(3)},
+		{name: "invalid custom resource with strict validation", validation: strictCustomResourceValidation, size: "three", wantErr: true},
+		{name: "invalid custom resource with the warnings", validation: warnCustomResourceValidation, size: "three"},
+		{name: "invalid custom resource without validation", validation: offCustomResourceValidation, size: "three"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			qaengine.Reset()
+			defer qaengine.Reset()
+			qaengine.AddEngine(qaengine.NewDefaultEngine())
+			qaengine.SetupConfigFile("", []string{common.ConfigTargetCustomResourceValidationKey + `="` + testCase.validation + `"`}, nil, nil, false)
+			ResetCustomResourceValidation()
+			defer ResetCustomResourceValidation()
+
+			cr := newWidget(testCase.size)
+			obj, err := ConvertToSupportedVersion(cr, clusterSpec, false)
+			invalidErr := &InvalidCustomResourceError{}
+			if testCase.wantErr != errors.As(err, &invalidErr) {
+				t.Fatalf("got the error %v , want an invalid custom resource error: %t", err, testCase.wantErr)
+			}
+			if obj != cr {
+				t.Fatalf("expected the custom resource to be passed through untouched, got %+v", obj)
+			}
+		})
+	}
+}
+
+func TestCustomResourceValidationDefaultsToOff(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	ResetCustomResourceValidation()
+	defer ResetCustomResourceValidation()
+	if validation := getCustomResourceValidation(); validation != offCustomResourceValidation {
+		t.Fatalf("got the validation %s by default, want %s", validation, offCustomResourceValidation)
+	}
+}
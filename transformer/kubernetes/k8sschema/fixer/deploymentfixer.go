@@ -29,11 +29,11 @@ import (
 type deploymentFixer struct {
 }
 
-func (f deploymentFixer) getGroupVersionKind() schema.GroupVersionKind {
+func (f deploymentFixer) GetGroupVersionKind() schema.GroupVersionKind {
 	return apps.SchemeGroupVersion.WithKind(common.DeploymentKind)
 }
 
-func (f deploymentFixer) fix(obj runtime.Object) (runtime.Object, error) {
+func (f deploymentFixer) Fix(obj runtime.Object) (runtime.Object, error) {
 	d, ok := obj.(*apps.Deployment)
 	if !ok {
 		return obj, fmt.Errorf("non Matching type. Expected Deployment : Got %T", obj)
@@ -17,35 +17,165 @@
 package fixer
 
 import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
 	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
 	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	apps "k8s.io/kubernetes/pkg/apis/apps"
+	batch "k8s.io/kubernetes/pkg/apis/batch"
+	core "k8s.io/kubernetes/pkg/apis/core"
 )
 
-// fixer can be used to fix K8s resources
-type fixer interface {
-	getGroupVersionKind() schema.GroupVersionKind
-	fix(obj runtime.Object) (runtime.Object, error)
+// Fixer can be used to fix K8s resources
+type Fixer interface {
+	GetGroupVersionKind() schema.GroupVersionKind
+	Fix(obj runtime.Object) (runtime.Object, error)
 }
 
+const (
+	// deploymentFixerName is the name of the fixer that sets the selector of deployments
+	deploymentFixerName = "deployment"
+	// ingressFixerName is the name of the fixer that sets the path type of ingresses
+	ingressFixerName = "ingress"
+	// resourcesFixerNamePrefix is the prefix of the names of the fixers that set the default container resources
+	resourcesFixerNamePrefix = "resources-"
+	// allFixers in the skip fixers annotation skips all the fixers
+	allFixers = "*"
+)
+
 var (
-	fixers = []fixer{deploymentFixer{}, ingressFixer{}}
+	fixers      = map[string]Fixer{deploymentFixerName: deploymentFixer{}, ingressFixerName: ingressFixer{}}
+	fixersOrder = []string{deploymentFixerName, ingressFixerName}
+	// enabledFixers is the ordered list of the names of the fixers selected by the user. It is computed once, until the next Reset.
+	enabledFixers     []string
+	enabledFixersOnce sync.Once
+	// customFixers are the names of the fixers loaded from the customizations, which are removed by Reset
+	customFixers []string
 )
 
+func init() {
+	for _, gvk := range []schema.GroupVersionKind{
+		apps.SchemeGroupVersion.WithKind(common.DeploymentKind),
+		apps.SchemeGroupVersion.WithKind(common.StatefulSetKind),
+		apps.SchemeGroupVersion.WithKind(common.DaemonSetKind),
+		batch.SchemeGroupVersion.WithKind(common.JobKind),
+		core.SchemeGroupVersion.WithKind(common.PodKind),
+	} {
+		name := resourcesFixerNamePrefix + strings.ToLower(gvk.Kind)
+		if err := RegisterFixer(name, resourcesFixer{gvk: gvk}); err != nil {
+			logrus.Errorf("failed to register the fixer %s . Error: %q", name, err)
+		}
+	}
+}
+
+// RegisterFixer registers a new fixer. Registered fixers run after the built-in fixers, in the order they were registered.
+func RegisterFixer(name string, f Fixer) error {
+	if _, ok := fixers[name]; ok {
+		return fmt.Errorf("couldn't register fixer '%s' because a fixer with that name already exists", name)
+	}
+	fixers[name] = f
+	fixersOrder = append(fixersOrder, name)
+	return nil
+}
+
+// Reset removes the fixers loaded from the customizations and forgets the fixers selected by the user, the default resources and the usage metrics,
+// so that the next run loads the customizations and the usage metrics again and asks for the fixers and the resources
+func Reset() {
+	resetResources()
+	for _, name := range customFixers {
+		delete(fixers, name)
+		fixersOrder = common.Filter(fixersOrder, func(n string) bool { return n != name })
+	}
+	customFixers = nil
+	enabledFixers, enabledFixersOnce = nil, sync.Once{}
+}
+
+// getEnabledFixers returns the fixers selected by the user, in the order they were specified
+func getEnabledFixers() []string {
+	enabledFixersOnce.Do(func() {
+		names := qaengine.FetchMultiSelectAnswer(
+			common.ConfigFixersEnabledKey,
+			"Select the fixers to run on the kubernetes resources:",
+			[]string{"The fixers run in the order they are specified."},
+			fixersOrder,
+			fixersOrder,
+			nil,
+		)
+		for _, name := range names {
+			if _, ok := fixers[name]; !ok {
+				logrus.Errorf("failed to find the fixer with the name '%s' . Valid fixers are: %+v", name, fixersOrder)
+				continue
+			}
+			enabledFixers = append(enabledFixers, name)
+		}
+	})
+	return enabledFixers
+}
+
+// getSkippedFixers returns the names of the fixers which the annotation of the object opts it out of
+func getSkippedFixers(obj runtime.Object) map[string]bool {
+	skippedFixers := map[string]bool{}
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return skippedFixers
+	}
+	value, ok := objMeta.GetAnnotations()[common.SkipFixersAnnotation]
+	if !ok {
+		return skippedFixers
+	}
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if _, ok := fixers[name]; !ok && name != allFixers {
+			logrus.Warnf("The fixer %s in the annotation %s of %s does not exist. Valid fixers are: %+v", name, common.SkipFixersAnnotation, objMeta.GetName(), fixersOrder)
+			continue
+		}
+		skippedFixers[name] = true
+	}
+	return skippedFixers
+}
+
+// getName returns the name of the object
+func getName(obj runtime.Object) string {
+	if objMeta, err := meta.Accessor(obj); err == nil {
+		return objMeta.GetName()
+	}
+	return ""
+}
+
 // Fix fixes kubernetes objects
 func Fix(obj runtime.Object) runtime.Object {
+	if _, ok := obj.(*unstructured.Unstructured); ok {
+		// custom resources are passed through untouched
+		return obj
+	}
 	objgv := obj.GetObjectKind().GroupVersionKind().GroupVersion()
-	for _, fixer := range fixers {
-		fgvk := fixer.getGroupVersionKind()
+	skippedFixers := getSkippedFixers(obj)
+	for _, name := range getEnabledFixers() {
+		fixer := fixers[name]
+		fgvk := fixer.GetGroupVersionKind()
 		if fgvk.Kind == obj.GetObjectKind().GroupVersionKind().Kind {
+			if skippedFixers[name] || skippedFixers[allFixers] {
+				logrus.Debugf("Skipping the fixer %s on the %s %s since it is listed in the annotation %s", name, fgvk.Kind, getName(obj), common.SkipFixersAnnotation)
+				continue
+			}
 			logrus.Debugf("Running fixer %T", fixer)
 			newobj, err := k8sschema.ConvertToVersion(obj, fgvk.GroupVersion())
 			if err != nil {
 				logrus.Errorf("Unable to convert to %s for fixer %T", fgvk, fixer)
 				continue
 			}
-			newobj, err = fixer.fix(newobj)
+			newobj, err = fixer.Fix(newobj)
 			if err != nil {
 				logrus.Errorf("Unable to fix %s using fixer %T : %s", fgvk, fixer, err)
 				continue
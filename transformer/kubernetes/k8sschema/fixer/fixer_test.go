@@ -0,0 +1,71 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package fixer
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFixSkipsTheFixersOfTheAnnotation(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	Reset()
+	defer Reset()
+	newDeployment := func(skipFixers string) *appsv1.Deployment {
+		deployment := &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: common.DeploymentKind},
+			ObjectMeta: metav1.ObjectMeta{Name: "api"},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "api"}},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "api", Image: "api:latest"}}},
+			}},
+		}
+		if skipFixers != "" {
+			deployment.Annotations = map[string]string{common.SkipFixersAnnotation: skipFixers}
+		}
+		return deployment
+	}
+	testCases := []struct {
+		skipFixers    string
+		wantSelector  bool
+		wantResources bool
+	}{
+		{skipFixers: "", wantSelector: true, wantResources: true},
+		{skipFixers: deploymentFixerName, wantSelector: false, wantResources: true},
+		{skipFixers: "unknown, " + resourcesFixerNamePrefix + "deployment", wantSelector: true, wantResources: false},
+		{skipFixers: allFixers, wantSelector: false, wantResources: false},
+	}
+	for _, testCase := range testCases {
+		fixed, ok := Fix(newDeployment(testCase.skipFixers)).(*appsv1.Deployment)
+		if !ok {
+			t.Fatalf("expected the fixed object to be a deployment")
+		}
+		if hasSelector := fixed.Spec.Selector != nil; hasSelector != testCase.wantSelector {
+			t.Errorf("got the selector %+v with the annotation %q , want the selector to be set %t", fixed.Spec.Selector, testCase.skipFixers, testCase.wantSelector)
+		}
+		if hasResources := len(fixed.Spec.Template.Spec.Containers[0].Resources.Requests) != 0; hasResources != testCase.wantResources {
+			t.Errorf("got the resources %+v with the annotation %q , want the resources to be set %t", fixed.Spec.Template.Spec.Containers[0].Resources, testCase.skipFixers, testCase.wantResources)
+		}
+	}
+}
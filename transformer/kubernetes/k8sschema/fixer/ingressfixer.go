@@ -28,11 +28,11 @@ import (
 type ingressFixer struct {
 }
 
-func (f ingressFixer) getGroupVersionKind() schema.GroupVersionKind {
+func (f ingressFixer) GetGroupVersionKind() schema.GroupVersionKind {
 	return networking.SchemeGroupVersion.WithKind(common.IngressKind)
 }
 
-func (f ingressFixer) fix(obj runtime.Object) (runtime.Object, error) {
+func (f ingressFixer) Fix(obj runtime.Object) (runtime.Object, error) {
 	ptf := networking.PathTypePrefix
 	i, ok := obj.(*networking.Ingress)
 	if !ok {
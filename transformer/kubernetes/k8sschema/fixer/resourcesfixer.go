@@ -0,0 +1,228 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package fixer
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	apps "k8s.io/kubernetes/pkg/apis/apps"
+	batch "k8s.io/kubernetes/pkg/apis/batch"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	// observedCPULimitFactor and observedMemoryLimitFactor give the limits headroom over the observed usage, used as the requests
+	observedCPULimitFactor    = 2.0
+	observedMemoryLimitFactor = 1.5
+	minObservedCPURequest     = "10m"
+	minObservedMemoryRequest  = "32Mi"
+)
+
+var (
+	defaultResources     core.ResourceRequirements
+	defaultResourcesOnce sync.Once
+	// usageMetrics stores the usage of the containers of the workloads collected from the cluster they are migrated from, keyed by workload and container name
+	usageMetrics = map[string]map[string]collecttypes.ContainerUsage{}
+)
+
+// resetResources forgets the default resources and the usage metrics of the previous run
+func resetResources() {
+	defaultResources, defaultResourcesOnce = core.ResourceRequirements{}, sync.Once{}
+	usageMetrics = map[string]map[string]collecttypes.ContainerUsage{}
+}
+
+// resourcesFixer makes sure that every container of a workload has cpu and memory requests and limits.
+// Values that already exist (for example from the cloud foundry manifest or the compose deploy.resources section) are kept.
+type resourcesFixer struct {
+	gvk schema.GroupVersionKind
+}
+
+func (f resourcesFixer) GetGroupVersionKind() schema.GroupVersionKind {
+	return f.gvk
+}
+
+func (f resourcesFixer) Fix(obj runtime.Object) (runtime.Object, error) {
+	var podSpec *core.PodSpec
+	switch o := obj.(type) {
+	case *apps.Deployment:
+		podSpec = &o.Spec.Template.Spec
+	case *apps.StatefulSet:
+		podSpec = &o.Spec.Template.Spec
+	case *apps.DaemonSet:
+		podSpec = &o.Spec.Template.Spec
+	case *batch.Job:
+		podSpec = &o.Spec.Template.Spec
+	case *core.Pod:
+		podSpec = &o.Spec
+	default:
+		return obj, fmt.Errorf("non Matching type. Expected a workload with a pod spec : Got %T", obj)
+	}
+	defaults := getDefaultResources()
+	workloadName := ""
+	if metaObj, ok := obj.(metav1.Object); ok {
+		workloadName = metaObj.GetName()
+	}
+	for i := range podSpec.InitContainers {
+		setDefaultResources(&podSpec.InitContainers[i].Resources, defaults)
+	}
+	for i, container := range podSpec.Containers {
+		if usage, ok := usageMetrics[workloadName][container.Name]; ok {
+			setDefaultResources(&podSpec.Containers[i].Resources, getObservedResources(workloadName, usage, defaults))
+			continue
+		}
+		setDefaultResources(&podSpec.Containers[i].Resources, defaults)
+	}
+	return obj, nil
+}
+
+// LoadUsageMetrics loads the usage metrics collected from the clusters, which are used as the defaults of the requests and limits
+func LoadUsageMetrics(sourceDir string) {
+	if sourceDir == "" {
+		return
+	}
+	filePaths, err := common.GetYamlsWithTypeMeta(sourceDir, string(collecttypes.UsageMetricsKind))
+	if err != nil {
+		logrus.Debugf("Failed to fetch the collected usage metrics yamls at path %q Error: %q", sourceDir, err)
+		return
+	}
+	for _, filePath := range filePaths {
+		metrics := collecttypes.UsageMetrics{}
+		if err := common.ReadMove2KubeYaml(filePath, &metrics); err != nil || metrics.Kind != string(collecttypes.UsageMetricsKind) {
+			logrus.Debugf("The file at path %q is not a valid usage metrics file. Error: %q", filePath, err)
+			continue
+		}
+		logrus.Debugf("Using the usage metrics collected from the %s of the cluster %s at path %q", metrics.Spec.Source, metrics.Spec.Cluster, filePath)
+		for _, workload := range metrics.Spec.Workloads {
+			if _, ok := usageMetrics[workload.Name]; !ok {
+				usageMetrics[workload.Name] = map[string]collecttypes.ContainerUsage{}
+			}
+			for _, container := range workload.Containers {
+				usageMetrics[workload.Name][container.Name] = getMaxContainerUsage(usageMetrics[workload.Name][container.Name], container)
+			}
+		}
+	}
+}
+
+// GetContainerMemoryLimit returns the memory limit the container of the workload gets in the generated manifests
+func GetContainerMemoryLimit(workloadName, containerName string) resource.Quantity {
+	defaults := getDefaultResources()
+	if usage, ok := usageMetrics[workloadName][containerName]; ok {
+		return getObservedResources(workloadName, usage, defaults).Limits[core.ResourceMemory]
+	}
+	return defaults.Limits[core.ResourceMemory]
+}
+
+// getMaxContainerUsage returns the higher usage of the container, when the workload runs in several namespaces or clusters
+func getMaxContainerUsage(x, y collecttypes.ContainerUsage) collecttypes.ContainerUsage {
+	max := func(a, b string) string {
+		qa, erra := resource.ParseQuantity(a)
+		qb, errb := resource.ParseQuantity(b)
+		if erra != nil || (errb == nil && qb.Cmp(qa) > 0) {
+			return b
+		}
+		return a
+	}
+	return collecttypes.ContainerUsage{Name: y.Name, CPU: max(x.CPU, y.CPU), Memory: max(x.Memory, y.Memory)}
+}
+
+// getObservedResources asks the user for the requests and limits of the container, proposing the observed usage as the requests
+func getObservedResources(workloadName string, usage collecttypes.ContainerUsage, defaults core.ResourceRequirements) core.ResourceRequirements {
+	resources := core.ResourceRequirements{Requests: core.ResourceList{}, Limits: core.ResourceList{}}
+	for _, r := range []struct {
+		name        core.ResourceName
+		observed    string
+		min         string
+		limitFactor float64
+	}{
+		{name: core.ResourceCPU, observed: usage.CPU, min: minObservedCPURequest, limitFactor: observedCPULimitFactor},
+		{name: core.ResourceMemory, observed: usage.Memory, min: minObservedMemoryRequest, limitFactor: observedMemoryLimitFactor},
+	} {
+		observed, err := resource.ParseQuantity(r.observed)
+		if err != nil {
+			resources.Requests[r.name] = defaults.Requests[r.name]
+			resources.Limits[r.name] = defaults.Limits[r.name]
+			continue
+		}
+		request := resource.MustParse(r.min)
+		if observed.Cmp(request) > 0 {
+			request = observed
+		}
+		limit := resource.NewMilliQuantity(int64(math.Ceil(float64(request.MilliValue())*r.limitFactor)), request.Format)
+		if r.name == core.ResourceMemory {
+			limit = resource.NewQuantity(int64(math.Ceil(float64(request.Value())*r.limitFactor/(1024*1024)))*1024*1024, resource.BinarySI)
+		}
+		resources.Requests[r.name] = commonqa.ObservedContainerResourceQuantity(workloadName, usage.Name, "requests", string(r.name), observed.String(), request.String())
+		resources.Limits[r.name] = commonqa.ObservedContainerResourceQuantity(workloadName, usage.Name, "limits", string(r.name), observed.String(), limit.String())
+	}
+	return resources
+}
+
+// setDefaultResources fills in the missing cpu and memory requests and limits.
+// A missing request never exceeds the existing limit and a missing limit is never lower than the existing request.
+func setDefaultResources(resources *core.ResourceRequirements, defaults core.ResourceRequirements) {
+	if resources.Requests == nil {
+		resources.Requests = core.ResourceList{}
+	}
+	if resources.Limits == nil {
+		resources.Limits = core.ResourceList{}
+	}
+	for _, name := range []core.ResourceName{core.ResourceCPU, core.ResourceMemory} {
+		request, requestOk := resources.Requests[name]
+		limit, limitOk := resources.Limits[name]
+		if !requestOk {
+			request = defaults.Requests[name]
+			if limitOk && request.Cmp(limit) > 0 {
+				request = limit
+			}
+			resources.Requests[name] = request.DeepCopy()
+		}
+		if !limitOk {
+			limit = defaults.Limits[name]
+			if request.Cmp(limit) > 0 {
+				limit = request
+			}
+			resources.Limits[name] = limit.DeepCopy()
+		}
+	}
+}
+
+// getDefaultResources asks the user for the default requests and limits. It is computed once, until the next Reset.
+func getDefaultResources() core.ResourceRequirements {
+	defaultResourcesOnce.Do(func() {
+		defaultResources = core.ResourceRequirements{
+			Requests: core.ResourceList{
+				core.ResourceCPU:    commonqa.ContainerResourceQuantity("requests", "cpu", common.DefaultContainerCPURequest),
+				core.ResourceMemory: commonqa.ContainerResourceQuantity("requests", "memory", common.DefaultContainerMemoryRequest),
+			},
+			Limits: core.ResourceList{
+				core.ResourceCPU:    commonqa.ContainerResourceQuantity("limits", "cpu", common.DefaultContainerCPULimit),
+				core.ResourceMemory: commonqa.ContainerResourceQuantity("limits", "memory", common.DefaultContainerMemoryLimit),
+			},
+		}
+	})
+	return defaultResources
+}
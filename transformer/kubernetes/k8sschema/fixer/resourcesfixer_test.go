@@ -0,0 +1,135 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package fixer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestSetDefaultResources(t *testing.T) {
+	defaults := core.ResourceRequirements{
+		Requests: core.ResourceList{core.ResourceCPU: resource.MustParse("100m"), core.ResourceMemory: resource.MustParse("128Mi")},
+		Limits:   core.ResourceList{core.ResourceCPU: resource.MustParse("500m"), core.ResourceMemory: resource.MustParse("512Mi")},
+	}
+	t.Run("container without any resources gets the defaults", func(t *testing.T) {
+		resources := core.ResourceRequirements{}
+		setDefaultResources(&resources, defaults)
+		assertQuantity(t, resources.Requests, core.ResourceCPU, "100m")
+		assertQuantity(t, resources.Requests, core.ResourceMemory, "128Mi")
+		assertQuantity(t, resources.Limits, core.ResourceCPU, "500m")
+		assertQuantity(t, resources.Limits, core.ResourceMemory, "512Mi")
+	})
+	t.Run("existing values are kept and missing values stay consistent with them", func(t *testing.T) {
+		resources := core.ResourceRequirements{
+			Requests: core.ResourceList{core.ResourceCPU: resource.MustParse("2")},
+			Limits:   core.ResourceList{core.ResourceMemory: resource.MustParse("64Mi")},
+		}
+		setDefaultResources(&resources, defaults)
+		assertQuantity(t, resources.Requests, core.ResourceCPU, "2")
+		assertQuantity(t, resources.Limits, core.ResourceCPU, "2")
+		assertQuantity(t, resources.Requests, core.ResourceMemory, "64Mi")
+		assertQuantity(t, resources.Limits, core.ResourceMemory, "64Mi")
+	})
+}
+
+func assertQuantity(t *testing.T, list core.ResourceList, name core.ResourceName, expected string) {
+	t.Helper()
+	actual, ok := list[name]
+	if !ok {
+		t.Fatalf("expected %s to be set to %s. Actual: %+v", name, expected, list)
+	}
+	if actual.Cmp(resource.MustParse(expected)) != 0 {
+		t.Fatalf("expected %s to be %s. Actual: %s", name, expected, actual.String())
+	}
+}
+
+func TestResetForgetsTheResourcesOfThePreviousRun(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	Reset()
+	defer Reset()
+	writeUsageMetrics := func(memory string) string {
+		dir := t.TempDir()
+		metrics := "apiVersion: move2kube.konveyor.io/v1alpha1\nkind: UsageMetrics\nmetadata:\n  name: usage\nspec:\n  source: MetricsServer\n  workloads:\n  - name: api\n    namespace: default\n    containers:\n    - name: api\n      memory: " + memory + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "usage.yaml"), []byte(metrics), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the usage metrics. Error: %q", err)
+		}
+		return dir
+	}
+
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{common.JoinQASubKeys(common.ConfigFixersResourcesKey, "limits", "memory") + `="1Gi"`}, nil, nil, false)
+	LoadUsageMetrics(writeUsageMetrics("1Gi"))
+	assertQuantity(t, core.ResourceList{core.ResourceMemory: GetContainerMemoryLimit("api", "api")}, core.ResourceMemory, "1536Mi")
+	assertQuantity(t, core.ResourceList{core.ResourceMemory: GetContainerMemoryLimit("web", "web")}, core.ResourceMemory, "1Gi")
+
+	Reset()
+	qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{common.JoinQASubKeys(common.ConfigFixersResourcesKey, "limits", "memory") + `="2Gi"`}, nil, nil, false)
+	LoadUsageMetrics(writeUsageMetrics("100Mi"))
+	assertQuantity(t, core.ResourceList{core.ResourceMemory: GetContainerMemoryLimit("api", "api")}, core.ResourceMemory, "150Mi")
+	assertQuantity(t, core.ResourceList{core.ResourceMemory: GetContainerMemoryLimit("web", "web")}, core.ResourceMemory, "2Gi")
+}
+
+func TestGetObservedResources(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{common.JoinQASubKeys(common.ConfigServicesKey, `"api"`, "containers", `"api"`, common.ConfigContainerResourcesKeySegment, "limits", "cpu") + `="1"`}, nil, nil, false)
+	defaults := core.ResourceRequirements{
+		Requests: core.ResourceList{core.ResourceCPU: resource.MustParse("100m"), core.ResourceMemory: resource.MustParse("128Mi")},
+		Limits:   core.ResourceList{core.ResourceCPU: resource.MustParse("500m"), core.ResourceMemory: resource.MustParse("512Mi")},
+	}
+
+	resources := getObservedResources("api", collecttypes.ContainerUsage{Name: "api", CPU: "300m", Memory: "100Mi"}, defaults)
+	assertQuantity(t, resources.Requests, core.ResourceCPU, "300m")
+	assertQuantity(t, resources.Limits, core.ResourceCPU, "1")
+	assertQuantity(t, resources.Requests, core.ResourceMemory, "100Mi")
+	assertQuantity(t, resources.Limits, core.ResourceMemory, "150Mi")
+
+	resources = getObservedResources("worker", collecttypes.ContainerUsage{Name: "worker", CPU: "1m"}, defaults)
+	assertQuantity(t, resources.Requests, core.ResourceCPU, minObservedCPURequest)
+	assertQuantity(t, resources.Limits, core.ResourceCPU, "20m")
+	assertQuantity(t, resources.Requests, core.ResourceMemory, "128Mi")
+	assertQuantity(t, resources.Limits, core.ResourceMemory, "512Mi")
+}
+
+func TestLoadUsageMetricsKeepsTheHigherUsage(t *testing.T) {
+	Reset()
+	defer Reset()
+	dir := t.TempDir()
+	for i, usage := range []string{"cpu: 200m\n      memory: 1Gi", "cpu: 500m\n      memory: 256Mi"} {
+		metrics := "apiVersion: move2kube.konveyor.io/v1alpha1\nkind: UsageMetrics\nmetadata:\n  name: usage\nspec:\n  source: Prometheus\n  workloads:\n  - name: api\n    namespace: ns" + string(rune('a'+i)) + "\n    containers:\n    - name: api\n      " + usage + "\n"
+		if err := os.WriteFile(filepath.Join(dir, "usage"+string(rune('a'+i))+".yaml"), []byte(metrics), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the usage metrics. Error: %q", err)
+		}
+	}
+	LoadUsageMetrics(dir)
+	want := collecttypes.ContainerUsage{Name: "api", CPU: "500m", Memory: "1Gi"}
+	if got := usageMetrics["api"]["api"]; got != want {
+		t.Fatalf("expected the usage %+v , got %+v", want, got)
+	}
+}
@@ -0,0 +1,157 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package fixer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types"
+	starutil "github.com/qri-io/starlib/util"
+	"github.com/sirupsen/logrus"
+	"go.starlark.net/starlark"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// FixerKind defines the kind of the yamls which add custom fixers
+	FixerKind types.Kind = "Fixer"
+	// starlarkFixerFnName is the name of the function in the star file which fixes the object
+	starlarkFixerFnName = "fix"
+	// starlarkFixerMaxExecutionSteps limits the steps of a single call of the fix function, so that a loop does not hang the run
+	starlarkFixerMaxExecutionSteps = 10000000
+)
+
+// FixerConfig defines the yaml of a custom fixer in the customizations
+type FixerConfig struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty"`
+	Spec             FixerSpec `yaml:"spec,omitempty"`
+}
+
+// FixerSpec stores the kind fixed by a custom fixer and the star file that fixes it
+type FixerSpec struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	// StarFile is the path of the star file relative to the fixer yaml. It has to define the function fix(obj) which returns the fixed object
+	StarFile string `yaml:"starFile"`
+}
+
+// starlarkFixer runs the fix function of a star file on the objects
+type starlarkFixer struct {
+	name  string
+	gvk   schema.GroupVersionKind
+	fixFn *starlark.Function
+}
+
+// LoadCustomFixers registers the fixers defined by the Fixer yamls in the directory, like the customizations
+func LoadCustomFixers(dir string) {
+	if _, err := os.Stat(dir); err != nil {
+		return
+	}
+	yamlPaths, err := common.GetFilesByExt(dir, []string{".yml", ".yaml"})
+	if err != nil {
+		logrus.Errorf("failed to look for the fixers in the directory %s . Error: %q", dir, err)
+		return
+	}
+	for _, yamlPath := range yamlPaths {
+		fc := FixerConfig{}
+		if err := common.ReadMove2KubeYaml(yamlPath, &fc); err != nil || fc.Kind != string(FixerKind) {
+			continue
+		}
+		f, err := newStarlarkFixer(fc, filepath.Dir(yamlPath))
+		if err != nil {
+			logrus.Errorf("failed to load the fixer at path %s . Error: %q", yamlPath, err)
+			continue
+		}
+		if err := RegisterFixer(fc.Name, f); err != nil {
+			logrus.Errorf("failed to register the fixer at path %s . Error: %q", yamlPath, err)
+			continue
+		}
+		customFixers = append(customFixers, fc.Name)
+	}
+}
+
+func newStarlarkFixer(fc FixerConfig, dir string) (*starlarkFixer, error) {
+	if fc.Name == "" || fc.Spec.Kind == "" || fc.Spec.StarFile == "" {
+		return nil, fmt.Errorf("the name, the kind and the star file of the fixer are required")
+	}
+	gv, err := schema.ParseGroupVersion(fc.Spec.APIVersion)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the api version %s . Error: %w", fc.Spec.APIVersion, err)
+	}
+	starFile := fc.Spec.StarFile
+	if !filepath.IsAbs(starFile) {
+		starFile = filepath.Join(dir, starFile)
+	}
+	src, err := os.ReadFile(starFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the star file %s . Error: %w", starFile, err)
+	}
+	// the star file only gets the built-in functions of the language, so it cannot read or write files or reach the network
+	globals, err := starlark.ExecFile(newStarlarkFixerThread(fc.Name), starFile, src, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load the star file %s . Error: %w", starFile, err)
+	}
+	fixFn, ok := globals[starlarkFixerFnName].(*starlark.Function)
+	if !ok {
+		return nil, fmt.Errorf("the star file %s does not define the function %s", starFile, starlarkFixerFnName)
+	}
+	return &starlarkFixer{name: fc.Name, gvk: gv.WithKind(fc.Spec.Kind), fixFn: fixFn}, nil
+}
+
+func newStarlarkFixerThread(name string) *starlark.Thread {
+	thread := &starlark.Thread{Name: name}
+	thread.SetMaxExecutionSteps(starlarkFixerMaxExecutionSteps)
+	return thread
+}
+
+func (f *starlarkFixer) GetGroupVersionKind() schema.GroupVersionKind {
+	return f.gvk
+}
+
+func (f *starlarkFixer) Fix(obj runtime.Object) (runtime.Object, error) {
+	objMap, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return obj, fmt.Errorf("failed to convert the object to a map. Error: %w", err)
+	}
+	starObj, err := starutil.Marshal(objMap)
+	if err != nil {
+		return obj, fmt.Errorf("failed to convert the object to a starlark value. Error: %w", err)
+	}
+	starFixedObj, err := starlark.Call(newStarlarkFixerThread(f.name), f.fixFn, starlark.Tuple{starObj}, nil)
+	if err != nil {
+		return obj, fmt.Errorf("failed to run the function %s of the fixer %s . Error: %w", starlarkFixerFnName, f.name, err)
+	}
+	fixedObj, err := starutil.Unmarshal(starFixedObj)
+	if err != nil {
+		return obj, fmt.Errorf("failed to convert the fixed object from starlark. Error: %w", err)
+	}
+	fixedObjMap, ok := fixedObj.(map[string]interface{})
+	if !ok {
+		return obj, fmt.Errorf("the function %s of the fixer %s returned %T instead of the object", starlarkFixerFnName, f.name, fixedObj)
+	}
+	newObj := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(runtime.Object)
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(fixedObjMap, newObj); err != nil {
+		return obj, fmt.Errorf("failed to convert the fixed object back to a %s . Error: %w", f.gvk.Kind, err)
+	}
+	return newObj, nil
+}
@@ -0,0 +1,129 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package fixer
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	testFixerYaml = `apiVersion: move2kube.konveyor.io/v1alpha1
+kind: Fixer
+metadata:
+  name: team-label
+spec:
+  apiVersion: apps/v1
+  kind: Deployment
+  starFile: teamlabel.star
+`
+	testFixerStar = `
+def fix(obj):
+    obj["metadata"]["labels"]["team"] = "payments"
+    obj["spec"]["revisionHistoryLimit"] = 3
+    return obj
+`
+)
+
+func writeTestFixer(t *testing.T, star string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fixer.yaml"), []byte(testFixerYaml), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the fixer yaml. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "teamlabel.star"), []byte(star), common.DefaultFilePermission); err != nil {
+		t.Fatalf("failed to write the star file. Error: %q", err)
+	}
+	return dir
+}
+
+func TestLoadCustomFixers(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	Reset()
+	LoadCustomFixers(writeTestFixer(t, testFixerStar))
+	defer Reset()
+	if !common.IsPresent(getEnabledFixers(), "team-label") {
+		t.Fatalf("expected the custom fixer to be enabled by default, got %+v", enabledFixers)
+	}
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: common.DeploymentKind},
+		ObjectMeta: metav1.ObjectMeta{Name: "api", Labels: map[string]string{"app": "api"}},
+	}
+	fixed, ok := Fix(deployment).(*appsv1.Deployment)
+	if !ok {
+		t.Fatalf("expected the fixed object to be a deployment")
+	}
+	if fixed.Labels["team"] != "payments" || fixed.Labels["app"] != "api" {
+		t.Fatalf("expected the label to be added by the custom fixer, got %+v", fixed.Labels)
+	}
+	if fixed.Spec.RevisionHistoryLimit == nil || *fixed.Spec.RevisionHistoryLimit != 3 {
+		t.Fatalf("expected the revision history limit to be set by the custom fixer, got %+v", fixed.Spec.RevisionHistoryLimit)
+	}
+}
+
+func TestResetReloadsTheCustomFixers(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	Reset()
+	defer Reset()
+	newDeployment := func() *appsv1.Deployment {
+		return &appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: common.DeploymentKind},
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Labels: map[string]string{"app": "api"}},
+		}
+	}
+	LoadCustomFixers(writeTestFixer(t, testFixerStar))
+	if fixed := Fix(newDeployment()).(*appsv1.Deployment); fixed.Labels["team"] != "payments" {
+		t.Fatalf("expected the label to be added by the custom fixer, got %+v", fixed.Labels)
+	}
+	Reset()
+	if common.IsPresent(fixersOrder, "team-label") {
+		t.Fatalf("expected the custom fixer to be removed by the reset, got %+v", fixersOrder)
+	}
+	LoadCustomFixers(writeTestFixer(t, strings.Replace(testFixerStar, "payments", "billing", 1)))
+	if fixed := Fix(newDeployment()).(*appsv1.Deployment); fixed.Labels["team"] != "billing" {
+		t.Fatalf("expected the label to be added by the changed custom fixer, got %+v", fixed.Labels)
+	}
+}
+
+func TestStarlarkFixerIsLimited(t *testing.T) {
+	dir := writeTestFixer(t, "def fix(obj):\n    for i in range(100000000):\n        pass\n    return obj\n")
+	fc := FixerConfig{}
+	if err := common.ReadMove2KubeYaml(filepath.Join(dir, "fixer.yaml"), &fc); err != nil {
+		t.Fatalf("failed to read the fixer yaml. Error: %q", err)
+	}
+	f, err := newStarlarkFixer(fc, dir)
+	if err != nil {
+		t.Fatalf("failed to load the fixer. Error: %q", err)
+	}
+	if _, err := f.Fix(&appsv1.Deployment{TypeMeta: metav1.TypeMeta{APIVersion: "apps/v1", Kind: common.DeploymentKind}}); err == nil || !strings.Contains(err.Error(), "too many steps") {
+		t.Fatalf("expected the fixer to be stopped after too many steps, got the error %v", err)
+	}
+	if _, err := newStarlarkFixer(FixerConfig{Spec: FixerSpec{APIVersion: "apps/v1", Kind: common.DeploymentKind, StarFile: "teamlabel.star"}}, dir); err == nil {
+		t.Fatalf("expected an error for the fixer without a name")
+	}
+}
@@ -0,0 +1,107 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// kustomizeGroupName is the group of the kustomization files, which are not deployed to the cluster
+	kustomizeGroupName = "kustomize.config.k8s.io"
+)
+
+// kustomizationFilenames are the names kustomize looks for in a directory, in order of preference
+var kustomizationFilenames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// kustomization stores the fields of a kustomization file which refer to other manifests
+type kustomization struct {
+	Resources             []string      `yaml:"resources,omitempty"`
+	Bases                 []string      `yaml:"bases,omitempty"`
+	Components            []string      `yaml:"components,omitempty"`
+	Patches               []interface{} `yaml:"patches,omitempty"`
+	PatchesStrategicMerge []interface{} `yaml:"patchesStrategicMerge,omitempty"`
+	PatchesJSON6902       []interface{} `yaml:"patchesJson6902,omitempty"`
+}
+
+// getKustomizationPath returns the path of the kustomization file in the directory, or an empty string if there is none
+func getKustomizationPath(dir string) string {
+	for _, filename := range kustomizationFilenames {
+		path := filepath.Join(dir, filename)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// getKustomizationObjs returns the kubernetes objects of the resources referred to by the kustomization file,
+// following the nested kustomizations. The patches and generators are not applied.
+func getKustomizationObjs(kustomizationPath string, includeCustomResources bool, visited map[string]bool) []runtime.Object {
+	if visited[kustomizationPath] {
+		logrus.Warnf("The kustomization %s was already followed. Ignoring the repeated reference.", kustomizationPath)
+		return nil
+	}
+	visited[kustomizationPath] = true
+	data, err := os.ReadFile(kustomizationPath)
+	if err != nil {
+		logrus.Errorf("Failed to read the kustomization file at path %s . Error: %q", kustomizationPath, err)
+		return nil
+	}
+	k := kustomization{}
+	if err := yaml.Unmarshal(data, &k); err != nil {
+		logrus.Errorf("Failed to parse the kustomization file at path %s . Error: %q", kustomizationPath, err)
+		return nil
+	}
+	if len(k.Patches)+len(k.PatchesStrategicMerge)+len(k.PatchesJSON6902) != 0 {
+		logrus.Warnf("The patches in the kustomization %s are not applied. Only the resources it refers to are transformed.", kustomizationPath)
+	}
+	dir := filepath.Dir(kustomizationPath)
+	objs := []runtime.Object{}
+	for _, ref := range append(append(k.Resources, k.Bases...), k.Components...) {
+		if strings.Contains(ref, "://") || strings.HasPrefix(ref, "github.com/") {
+			logrus.Warnf("Ignoring the remote resource %s in the kustomization %s", ref, kustomizationPath)
+			continue
+		}
+		path := ref
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			logrus.Warnf("Ignoring the resource %s in the kustomization %s . Error: %q", ref, kustomizationPath, err)
+			continue
+		}
+		if !info.IsDir() {
+			objs = append(objs, getKubernetesObjsInFile(path, includeCustomResources)...)
+			continue
+		}
+		nestedKustomizationPath := getKustomizationPath(path)
+		if nestedKustomizationPath == "" {
+			logrus.Warnf("Ignoring the directory %s in the kustomization %s since it does not have a kustomization file", ref, kustomizationPath)
+			continue
+		}
+		objs = append(objs, getKustomizationObjs(nestedKustomizationPath, includeCustomResources, visited)...)
+	}
+	return objs
+}
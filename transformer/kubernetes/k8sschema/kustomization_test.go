@@ -0,0 +1,92 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"k8s.io/apimachinery/pkg/api/meta"
+)
+
+func TestGetKubernetesObjsInDirFollowsTheKustomizations(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"kustomization.yaml": `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- deployment.yaml
+- ../base
+- missing.yaml
+- https://github.com/example/app//config
+patchesStrategicMerge:
+- patch.yaml
+`,
+		"deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+`,
+		"patch.yaml":        "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: patched\n",
+		"unreferenced.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: unreferenced\n",
+		"../base/kustomization.yml": `resources:
+- configmap.yaml
+- ../overlay
+`,
+		"../base/configmap.yaml": "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n",
+	}
+	overlayDir := filepath.Join(dir, "overlay")
+	baseDir := filepath.Join(dir, "base")
+	for relPath, contents := range files {
+		path := filepath.Join(overlayDir, relPath)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory of the file %s . Error: %q", relPath, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", relPath, err)
+		}
+	}
+	if got := getKustomizationPath(baseDir); got != filepath.Join(baseDir, "kustomization.yml") {
+		t.Fatalf("got the kustomization path %q , want the kustomization.yml of the base", got)
+	}
+	if got := getKustomizationPath(dir); got != "" {
+		t.Fatalf("expected no kustomization in the directory %s . Actual: %q", dir, got)
+	}
+
+	// the base refers back to the overlay, which is not followed again
+	names := []string{}
+	for _, obj := range GetKubernetesObjsInDir(overlayDir) {
+		objMeta, err := meta.Accessor(obj)
+		if err != nil {
+			t.Fatalf("failed to get the metadata of the object %+v . Error: %q", obj, err)
+		}
+		names = append(names, obj.GetObjectKind().GroupVersionKind().Kind+"/"+objMeta.GetName())
+	}
+	sort.Strings(names)
+	if want := []string{"ConfigMap/settings", "Deployment/web", "Service/web"}; !cmp.Equal(names, want) {
+		t.Fatalf("unexpected objects. Differences:\n%s", cmp.Diff(want, names))
+	}
+}
@@ -19,8 +19,10 @@ package k8sschema
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/konveyor/move2kube/common"
@@ -149,48 +151,123 @@ func GetK8sResourcesWithPaths(k8sResourcesPath string, currDirOnly bool) (map[st
 	return k8sResources, nil
 }
 
-// GetK8sResourcesFromYaml decodes k8s resources from yaml
+// GetK8sResourcesFromYaml decodes k8s resources from yaml.
+// The yaml may contain multiple documents and the items of the List kinds are returned as separate resources.
 func GetK8sResourcesFromYaml(k8sYaml string) ([]K8sResourceT, error) {
-	// TODO: split yaml file into multiple resources
+	decoder := yaml.NewDecoder(strings.NewReader(k8sYaml))
+	k8sResources := []K8sResourceT{}
+	for {
+		// NOTE: This roundabout method is required to avoid yaml.v3 unmarshalling timestamps into time.Time
+		// Decoding into an interface also resolves the anchors and aliases, and drops the comments.
+		var resourceI interface{}
+		if err := decoder.Decode(&resourceI); err != nil {
+			if err == io.EOF {
+				break
+			}
+			logrus.Errorf("Failed to unmarshal k8s yaml. Error: %q", err)
+			return nil, err
+		}
+		if resourceI == nil {
+			// empty document, for example a leading --- or a document with only comments
+			continue
+		}
+		resourceJSONBytes, err := json.Marshal(resourceI)
+		if err != nil {
+			logrus.Errorf("Failed to marshal the k8s resource into json. K8s resource:\n+%v\nError: %q", resourceI, err)
+			return nil, err
+		}
+		var k8sResource K8sResourceT
+		if err := json.Unmarshal(resourceJSONBytes, &k8sResource); err != nil {
+			return nil, err
+		}
+		if _, ok := k8sResource["kind"]; !ok {
+			logrus.Debugf("Ignoring the yaml document %+v since it does not have a kind", k8sResource)
+			continue
+		}
+		k8sResources = append(k8sResources, expandListResource(k8sResource)...)
+	}
+	if len(k8sResources) == 0 {
+		return nil, fmt.Errorf("no k8s resources found in the yaml")
+	}
+	return k8sResources, nil
+}
 
-	// NOTE: This roundabout method is required to avoid yaml.v3 unmarshalling timestamps into time.Time
-	var resourceI interface{}
-	if err := yaml.Unmarshal([]byte(k8sYaml), &resourceI); err != nil {
-		logrus.Errorf("Failed to unmarshal k8s yaml. Error: %q", err)
-		return nil, err
+// expandListResource returns the items of a List kind (v1 List, ConfigMapList, etc.) or the resource itself for other kinds
+func expandListResource(k8sResource K8sResourceT) []K8sResourceT {
+	kind, _ := k8sResource["kind"].(string)
+	itemsI, ok := k8sResource["items"].([]interface{})
+	if !strings.HasSuffix(kind, "List") || !ok {
+		return []K8sResourceT{k8sResource}
 	}
-	resourceJSONBytes, err := json.Marshal(resourceI)
-	if err != nil {
-		logrus.Errorf("Failed to marshal the k8s resource into json. K8s resource:\n+%v\nError: %q", resourceI, err)
-		return nil, err
+	k8sResources := []K8sResourceT{}
+	for _, itemI := range itemsI {
+		item, ok := itemI.(map[string]interface{})
+		if !ok {
+			logrus.Debugf("Ignoring the item %+v of the %s since it is not an object", itemI, kind)
+			continue
+		}
+		k8sResources = append(k8sResources, expandListResource(item)...)
 	}
-	var k8sResource K8sResourceT
-	err = json.Unmarshal(resourceJSONBytes, &k8sResource)
-	return []K8sResourceT{k8sResource}, err
+	return k8sResources
 }
 
 // GetKubernetesObjsInDir returns returns all kubernetes objects in a dir
 func GetKubernetesObjsInDir(dir string) []runtime.Object {
-	objs := []runtime.Object{}
-	codecs := serializer.NewCodecFactory(GetSchema())
+	return getKubernetesObjsInDir(dir, false)
+}
+
+// GetKubernetesObjsAndCustomResourcesInDir returns all kubernetes objects in a dir.
+// Objects of kinds not known to move2kube are returned as unstructured custom resources.
+func GetKubernetesObjsAndCustomResourcesInDir(dir string) []runtime.Object {
+	return getKubernetesObjsInDir(dir, true)
+}
+
+func getKubernetesObjsInDir(dir string, includeCustomResources bool) []runtime.Object {
+	if kustomizationPath := getKustomizationPath(dir); kustomizationPath != "" {
+		return getKustomizationObjs(kustomizationPath, includeCustomResources, map[string]bool{})
+	}
 	filePaths, err := common.GetFilesByExtInCurrDir(dir, []string{".yml", ".yaml"})
 	if err != nil {
 		logrus.Errorf("Unable to fetch yaml files at path %q Error: %q", dir, err)
 		return nil
 	}
+	objs := []runtime.Object{}
 	for _, filePath := range filePaths {
-		data, err := os.ReadFile(filePath)
+		objs = append(objs, getKubernetesObjsInFile(filePath, includeCustomResources)...)
+	}
+	return objs
+}
+
+// getKubernetesObjsInFile decodes each of the documents in the yaml file into a kubernetes object
+func getKubernetesObjsInFile(filePath string, includeCustomResources bool) []runtime.Object {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		logrus.Debugf("Failed to read the yaml file at path %q Error: %q", filePath, err)
+		return nil
+	}
+	k8sResources, err := GetK8sResourcesFromYaml(string(data))
+	if err != nil {
+		logrus.Debugf("Failed to decode the file at path %q as a k8s file. Error: %q", filePath, err)
+		return nil
+	}
+	codecs := serializer.NewCodecFactory(GetSchema())
+	objs := []runtime.Object{}
+	for _, k8sResource := range k8sResources {
+		resourceJSONBytes, err := json.Marshal(k8sResource)
 		if err != nil {
-			logrus.Debugf("Failed to read the yaml file at path %q Error: %q", filePath, err)
+			logrus.Debugf("Failed to marshal a resource in the file at path %q Error: %q", filePath, err)
 			continue
 		}
-		obj, _, err := codecs.UniversalDeserializer().Decode(data, nil, nil)
+		obj, _, err := codecs.UniversalDeserializer().Decode(resourceJSONBytes, nil, nil)
+		if err != nil && includeCustomResources && runtime.IsNotRegisteredError(err) {
+			obj, err = decodeCustomResource(resourceJSONBytes)
+		}
 		if err != nil {
-			logrus.Debugf("Failed to decode the file at path %q as a k8s file. Error: %q", filePath, err)
+			logrus.Debugf("Failed to decode a resource in the file at path %q as a k8s resource. Error: %q", filePath, err)
 			continue
 		}
 		objGroupName := obj.GetObjectKind().GroupVersionKind().Group
-		if objGroupName == types.GroupName {
+		if objGroupName == types.GroupName || objGroupName == kustomizeGroupName {
 			continue
 		}
 		objs = append(objs, obj)
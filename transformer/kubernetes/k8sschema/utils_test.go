@@ -0,0 +1,76 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package k8sschema
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetK8sResourcesFromYaml(t *testing.T) {
+	k8sYaml := `---
+# the configuration of the app
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+  labels: &labels
+    app: web
+data:
+  created: 2021-01-01T00:00:00Z
+---
+# only a comment
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Service
+  metadata:
+    name: web
+    labels: *labels
+- apiVersion: v1
+  kind: ConfigMapList
+  items:
+  - apiVersion: v1
+    kind: ConfigMap
+    metadata:
+      name: nested
+- not an object
+---
+not: a resource
+`
+	want := []K8sResourceT{
+		{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "settings", "labels": map[string]interface{}{"app": "web"}}, "data": map[string]interface{}{"created": "2021-01-01T00:00:00Z"}},
+		{"apiVersion": "v1", "kind": "Service", "metadata": map[string]interface{}{"name": "web", "labels": map[string]interface{}{"app": "web"}}},
+		{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]interface{}{"name": "nested"}},
+	}
+	got, err := GetK8sResourcesFromYaml(k8sYaml)
+	if err != nil {
+		t.Fatalf("failed to decode the resources. Error: %q", err)
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected resources. Diff (-want +got):\n%s", diff)
+	}
+
+	for _, invalidYaml := range []string{"# only comments\n", "not: a resource\n", "kind: [unclosed\n"} {
+		if resources, err := GetK8sResourcesFromYaml(invalidYaml); err == nil {
+			t.Errorf("GetK8sResourcesFromYaml(%q) = %+v, want an error", invalidYaml, resources)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// kubernetesTransformerEnabled and knativeTransformerEnabled are set when the transformers are initialized and cleared by ResetEnabledTransformers.
+// The services are split between them only when both are selected, otherwise each of them deploys all the services.
+var (
+	kubernetesTransformerEnabled bool
+	knativeTransformerEnabled    bool
+)
+
+// ResetEnabledTransformers forgets which of the Kubernetes and Knative transformers were initialized, before the transformers are initialized again
+func ResetEnabledTransformers() {
+	kubernetesTransformerEnabled = false
+	knativeTransformerEnabled = false
+}
+
+// filterKnativeServices keeps in the IR only the services deployed as Knative Services, or only the other ones.
+// The storages and the other resources shared by the services are kept in both.
+func filterKnativeServices(ir irtypes.IR, knative bool) irtypes.IR {
+	if !kubernetesTransformerEnabled || !knativeTransformerEnabled {
+		return ir
+	}
+	selected := getKnativeServices(ir)
+	services := map[string]irtypes.Service{}
+	for serviceName, service := range ir.Services {
+		if selected[serviceName] == knative {
+			services[serviceName] = service
+		}
+	}
+	ir.Services = services
+	return ir
+}
+
+// getKnativeServices asks which services are deployed as Knative Services. None are selected by default,
+// so that the services stay Deployments unless the user opts in, and the request driven ones are suggested in the hint.
+func getKnativeServices(ir irtypes.IR) map[string]bool {
+	serviceNames := []string{}
+	requestDrivenServiceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if service.OnlyIngress || len(service.Containers) == 0 {
+			continue
+		}
+		serviceNames = append(serviceNames, serviceName)
+		if isRequestDriven(service) {
+			requestDrivenServiceNames = append(requestDrivenServiceNames, serviceName)
+		}
+	}
+	selected := map[string]bool{}
+	if len(serviceNames) == 0 {
+		return selected
+	}
+	sort.Strings(serviceNames)
+	sort.Strings(requestDrivenServiceNames)
+	hints := []string{"The other services are deployed as Deployments, with Services and Ingresses"}
+	if len(requestDrivenServiceNames) != 0 {
+		hints = append(hints, "The stateless services serving requests on a single port suit Knative, since it scales them with the requests, down to zero: "+strings.Join(requestDrivenServiceNames, ", "))
+	}
+	selectedServiceNames := qaengine.FetchMultiSelectAnswer(
+		common.ConfigKnativeServicesKey,
+		"Select the services which should be deployed as Knative Services :",
+		hints,
+		[]string{},
+		serviceNames,
+		nil,
+	)
+	for _, serviceName := range selectedServiceNames {
+		selected[serviceName] = true
+		logrus.Debugf("Deploying the service %s as a Knative Service", serviceName)
+	}
+	return selected
+}
+
+// isRequestDriven returns true for the services which only serve requests, on a single port, and keep no state,
+// since Knative scales the pods with the requests and routes them to one port of the container
+func isRequestDriven(service irtypes.Service) bool {
+	if service.Stateful || service.Daemon || len(service.ServiceToPodPortForwardings) == 0 {
+		return false
+	}
+	podPorts := map[int32]bool{}
+	for _, forwarding := range service.ServiceToPodPortForwardings {
+		if forwarding.GetProtocol() != core.ProtocolTCP {
+			return false
+		}
+		podPorts[forwarding.PodPort.Number] = true
+	}
+	if len(podPorts) != 1 {
+		return false
+	}
+	for _, volume := range service.Volumes {
+		if volume.PersistentVolumeClaim != nil || volume.HostPath != nil {
+			return false
+		}
+	}
+	return true
+}
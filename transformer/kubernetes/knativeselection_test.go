@@ -0,0 +1,106 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestFilterKnativeServices(t *testing.T) {
+	newIR := func() irtypes.IR {
+		ir := irtypes.NewIR()
+		for _, serviceName := range []string{"api", "web"} {
+			service := irtypes.NewServiceWithName(serviceName)
+			service.Containers = []core.Container{{Name: serviceName, Image: serviceName + ":latest"}}
+			service.AddPortForwarding(networking.ServiceBackendPort{Number: 80}, networking.ServiceBackendPort{Number: 8080}, "")
+			ir.Services[serviceName] = service
+		}
+		return ir
+	}
+	getServiceNames := func(ir irtypes.IR) []string {
+		serviceNames := []string{}
+		for serviceName := range ir.Services {
+			serviceNames = append(serviceNames, serviceName)
+		}
+		sort.Strings(serviceNames)
+		return serviceNames
+	}
+	testCases := []struct {
+		name        string
+		knative     bool
+		kubernetes  bool
+		configs     []string
+		wantKnative []string
+		wantOthers  []string
+	}{
+		{
+			name:        "no services are deployed as Knative Services by default",
+			knative:     true,
+			kubernetes:  true,
+			wantKnative: []string{},
+			wantOthers:  []string{"api", "web"},
+		},
+		{
+			name:        "the selected services are split out",
+			knative:     true,
+			kubernetes:  true,
+			configs:     []string{common.ConfigKnativeServicesKey + `=["web"]`},
+			wantKnative: []string{"web"},
+			wantOthers:  []string{"api"},
+		},
+		{
+			name:        "the services are not split when only the Kubernetes transformer is enabled",
+			kubernetes:  true,
+			configs:     []string{common.ConfigKnativeServicesKey + `=["web"]`},
+			wantKnative: []string{"api", "web"},
+			wantOthers:  []string{"api", "web"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			qaengine.Reset()
+			defer qaengine.Reset()
+			qaengine.AddEngine(qaengine.NewDefaultEngine())
+			qaengine.SetupConfigFile("", testCase.configs, nil, nil, false)
+			ResetEnabledTransformers()
+			defer ResetEnabledTransformers()
+			kubernetesTransformerEnabled, knativeTransformerEnabled = testCase.kubernetes, testCase.knative
+			if got := getServiceNames(filterKnativeServices(newIR(), true)); !cmp.Equal(got, testCase.wantKnative) {
+				t.Fatalf("the Knative Services are different. Difference:\n%s", cmp.Diff(testCase.wantKnative, got))
+			}
+			if got := getServiceNames(filterKnativeServices(newIR(), false)); !cmp.Equal(got, testCase.wantOthers) {
+				t.Fatalf("the other services are different. Difference:\n%s", cmp.Diff(testCase.wantOthers, got))
+			}
+		})
+	}
+}
+
+func TestResetEnabledTransformers(t *testing.T) {
+	kubernetesTransformerEnabled, knativeTransformerEnabled = true, true
+	ResetEnabledTransformers()
+	if kubernetesTransformerEnabled || knativeTransformerEnabled {
+		t.Fatalf("expected the transformers to be forgotten after the reset")
+	}
+}
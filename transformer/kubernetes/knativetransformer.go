@@ -52,6 +52,7 @@ type KnativeYamlConfig struct {
 func (t *Knative) Init(tc transformertypes.Transformer, env *environment.Environment) error {
 	t.Config = tc
 	t.Env = env
+	knativeTransformerEnabled = true
 	t.KnativeConfig = &KnativeYamlConfig{}
 	err := common.GetObjFromInterface(t.Config.Spec.Config, t.KnativeConfig)
 	if err != nil {
@@ -104,12 +105,13 @@ func (t *Knative) Transform(newArtifacts []transformertypes.Artifact, alreadySee
 		} else {
 			ir = preprocessedIR
 		}
+		ir = filterKnativeServices(ir, true)
 		deployKnativeDir := t.KnativeConfig.OutputPath
 		tempDest := filepath.Join(t.Env.TempPath, deployKnativeDir)
 		logrus.Debugf("Starting Kubernetes transform")
 		logrus.Debugf("Total services to be transformed : %d", len(ir.Services))
 		apis := []apiresource.IAPIResource{&apiresource.KnativeService{}}
-		files, err := apiresource.TransformIRAndPersist(irtypes.NewEnhancedIRFromIR(ir), tempDest, apis, clusterConfig, t.KnativeConfig.SetDefaultValuesInYamls)
+		files, err := apiresource.TransformIRAndPersist(t.Config.Name, irtypes.NewEnhancedIRFromIR(ir), tempDest, apis, clusterConfig, t.KnativeConfig.SetDefaultValuesInYamls)
 		if err != nil {
 			logrus.Errorf("Unable to transform and persist IR : %s", err)
 			return nil, nil, err
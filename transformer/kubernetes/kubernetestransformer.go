@@ -20,6 +20,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
@@ -50,6 +52,10 @@ type KubernetesYamlConfig struct {
 	IngressName             string `yaml:"ingressName"`
 	OutputPath              string `yaml:"outputPath"`
 	SetDefaultValuesInYamls bool   `yaml:"setDefaultValuesInYamls"`
+	// ReadmesPath is the directory the READMEs of the services are written to. They are not generated when it is empty.
+	ReadmesPath string `yaml:"readmesPath"`
+	// SmokeTestsPath is the directory the smoke test Jobs of the exposed services and the verify script are written to. They are not generated when it is empty.
+	SmokeTestsPath string `yaml:"smokeTestsPath"`
 }
 
 // KubernetesPathTemplateConfig implements Kubernetes template config interface
@@ -62,6 +68,7 @@ type KubernetesPathTemplateConfig struct {
 func (t *Kubernetes) Init(tc transformertypes.Transformer, e *environment.Environment) error {
 	t.Config = tc
 	t.Env = e
+	kubernetesTransformerEnabled = true
 	t.KubernetesConfig = &KubernetesYamlConfig{}
 	err := common.GetObjFromInterface(t.Config.Spec.Config, t.KubernetesConfig)
 	if err != nil {
@@ -134,17 +141,11 @@ func (t *Kubernetes) Transform(newArtifacts []transformertypes.Artifact, already
 		} else {
 			ir = preprocessedIR
 		}
+		ir = filterKnativeServices(ir, false)
 		tempDest := filepath.Join(t.Env.TempPath, "k8s-yamls-"+common.GetRandomString())
 		logrus.Debugf("Starting Kubernetes transform")
 		logrus.Debugf("Total services to be transformed: %d", len(ir.Services))
-		apis := []apiresource.IAPIResource{
-			new(apiresource.Deployment),
-			new(apiresource.Storage),
-			new(apiresource.Service),
-			new(apiresource.ImageStream),
-			new(apiresource.NetworkPolicy),
-		}
-		files, err := apiresource.TransformIRAndPersist(irtypes.NewEnhancedIRFromIR(ir), tempDest, apis, clusterConfig, t.KubernetesConfig.SetDefaultValuesInYamls)
+		files, err := apiresource.TransformIRAndPersist(t.Config.Name, newEnhancedIRWithServiceAccounts(ir), tempDest, getAPIResources(), clusterConfig, t.KubernetesConfig.SetDefaultValuesInYamls)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to transform and persist the IR. Error: %w", err)
 		}
@@ -164,6 +165,26 @@ func (t *Kubernetes) Transform(newArtifacts []transformertypes.Artifact, already
 			SrcPath:  tempDest,
 			DestPath: outputPath,
 		})
+		pathMappings = append(pathMappings, t.getManifestsLayoutPathMappings(tempDest, outputPath)...)
+		pathMappings = append(pathMappings, t.getServiceReadmePathMappings(ir, tempDest)...)
+		pathMappings = append(pathMappings, t.getSmokeTestPathMappings(ir, tempDest)...)
+		additionalClusters := map[string]collecttypes.ClusterMetadata{}
+		if err := newArtifact.GetConfig(AdditionalClusterMetadatas, &additionalClusters); err == nil {
+			additionalClusterTypes := []string{}
+			for clusterType := range additionalClusters {
+				additionalClusterTypes = append(additionalClusterTypes, clusterType)
+			}
+			sort.Strings(additionalClusterTypes)
+			for _, clusterType := range additionalClusterTypes {
+				additionalCluster := additionalClusters[clusterType]
+				additionalPathMappings, err := t.transformForAdditionalCluster(ir, additionalCluster, serviceFsPath)
+				if err != nil {
+					logrus.Errorf("failed to generate the kubernetes yamls for the cluster %s . Error: %q", additionalCluster.Name, err)
+					continue
+				}
+				pathMappings = append(pathMappings, additionalPathMappings...)
+			}
+		}
 		createdArtifact := transformertypes.Artifact{
 			Name: t.Config.Name,
 			Type: artifacts.KubernetesYamlsArtifactType,
@@ -186,3 +207,46 @@ func (t *Kubernetes) Transform(newArtifacts []transformertypes.Artifact, already
 	}
 	return pathMappings, createdArtifacts, nil
 }
+
+// transformForAdditionalCluster generates the kubernetes yamls for one of the other target clusters, in a directory next to the yamls of the selected cluster
+func (t *Kubernetes) transformForAdditionalCluster(ir irtypes.IR, cluster collecttypes.ClusterMetadata, serviceFsPath string) ([]transformertypes.PathMapping, error) {
+	logrus.Infof("Generating the kubernetes yamls for the cluster %s", cluster.Name)
+	tempDest := filepath.Join(t.Env.TempPath, "k8s-yamls-"+common.GetRandomString())
+	files, err := apiresource.TransformIRAndPersist(t.Config.Name, newEnhancedIRWithServiceAccounts(ir), tempDest, getAPIResources(), cluster, t.KubernetesConfig.SetDefaultValuesInYamls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform and persist the IR. Error: %w", err)
+	}
+	logrus.Debugf("Total transformed objects for the cluster %s : %d", cluster.Name, len(files))
+	outputPathKey := outputPathTemplateName + common.GetRandomString()
+	outputPath := fmt.Sprintf("{{ .%s }}", outputPathKey)
+	pathMappings := []transformertypes.PathMapping{{
+		Type:           transformertypes.PathTemplatePathMappingType,
+		SrcPath:        t.KubernetesConfig.OutputPath + "-" + strings.ToLower(common.MakeStringK8sServiceNameCompliant(cluster.Name)),
+		TemplateConfig: KubernetesPathTemplateConfig{PathTemplateName: outputPathKey, ServiceFsPath: serviceFsPath},
+	}, {
+		Type:     transformertypes.DefaultPathMappingType,
+		SrcPath:  tempDest,
+		DestPath: outputPath,
+	}}
+	return append(pathMappings, t.getManifestsLayoutPathMappings(tempDest, outputPath)...), nil
+}
+
+// getAPIResources returns the api resources used to generate the kubernetes yamls
+func getAPIResources() []apiresource.IAPIResource {
+	return []apiresource.IAPIResource{
+		new(apiresource.Deployment),
+		new(apiresource.Storage),
+		new(apiresource.Service),
+		new(apiresource.ImageStream),
+		new(apiresource.NetworkPolicy),
+		new(apiresource.HorizontalPodAutoscaler),
+		new(apiresource.GrafanaDashboard),
+		new(apiresource.PriorityClass),
+		new(apiresource.Namespace),
+		new(apiresource.ServiceAccount),
+		new(apiresource.Role),
+		new(apiresource.RoleBinding),
+		new(apiresource.CrossplaneClaim),
+		new(apiresource.ServiceBinding),
+	}
+}
@@ -0,0 +1,78 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestTransformForAdditionalCluster(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	k := &Kubernetes{
+		Env:              &environment.Environment{EnvInfo: environment.EnvInfo{ProjectName: "myproject", TempPath: t.TempDir()}},
+		KubernetesConfig: &KubernetesYamlConfig{OutputPath: filepath.Join(common.DeployDir, "yamls")},
+	}
+	ir := irtypes.NewIR()
+	ir.Name = "myproject"
+	api := irtypes.NewServiceWithName("api")
+	api.Containers = []core.Container{{Name: "api", Image: "api:latest"}}
+	ir.Services["api"] = api
+	cluster := collecttypes.NewClusterMetadata("Old Cluster")
+	cluster.Spec.APIKindVersionMap = map[string][]string{common.DeploymentKind: {"apps/v1"}}
+
+	pathMappings, err := k.transformForAdditionalCluster(ir, cluster, "services/api")
+	if err != nil {
+		t.Fatalf("failed to generate the yamls for the additional cluster. Error: %q", err)
+	}
+	if len(pathMappings) != 2 {
+		t.Fatalf("expected the output path template and the yamls. Actual: %+v", pathMappings)
+	}
+	pathTemplate := pathMappings[0]
+	if pathTemplate.Type != transformertypes.PathTemplatePathMappingType || pathTemplate.SrcPath != filepath.Join(common.DeployDir, "yamls")+"-old-cluster" {
+		t.Fatalf("expected the yamls of the cluster in a directory named after it. Actual: %+v", pathTemplate)
+	}
+	templateConfig := pathTemplate.TemplateConfig.(KubernetesPathTemplateConfig)
+	if templateConfig.ServiceFsPath != "services/api" || pathMappings[1].DestPath != "{{ ."+templateConfig.PathTemplateName+" }}" {
+		t.Fatalf("expected the yamls to be copied to the templated output path. Actual: %+v %+v", templateConfig, pathMappings[1])
+	}
+	entries, err := os.ReadDir(pathMappings[1].SrcPath)
+	if err != nil {
+		t.Fatalf("failed to read the generated yamls. Error: %q", err)
+	}
+	deploymentFound := false
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), "-deployment.yaml") {
+			deploymentFound = true
+		}
+	}
+	if !deploymentFound {
+		t.Fatalf("expected a deployment in the generated yamls. Actual: %+v", entries)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/konveyor/move2kube/environment"
 	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
 	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/konveyor/move2kube/types"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
 	"github.com/konveyor/move2kube/types/transformer/artifacts"
@@ -33,8 +34,26 @@ import (
 
 const (
 	defaultKVCOutputPath = "{{ $rel := Rel .YamlsPath }}source/{{ $rel }}{{ if ne $rel \".\" }}/..{{end}}/{{ FilePathBase .YamlsPath }}-versionchanged/"
+	// conversionReportsDir is the directory in the output where the conversion reports are written
+	conversionReportsDir = "conversionreports"
+	// ConversionReportKind defines the kind of the conversion report file
+	ConversionReportKind types.Kind = "ConversionReport"
 )
 
+// ConversionReport stores the details of the conversion of a directory of kubernetes yamls to the target cluster
+type ConversionReport struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty"`
+	Spec             ConversionReportSpec `yaml:"spec,omitempty"`
+}
+
+// ConversionReportSpec stores the conversion results of each sub directory
+type ConversionReportSpec struct {
+	TargetCluster       string                                         `yaml:"targetCluster"`
+	TargetServerVersion string                                         `yaml:"targetServerVersion,omitempty"`
+	Directories         map[string][]apiresource.ConversionReportEntry `yaml:"directories"`
+}
+
 // KubernetesVersionChanger implements Transformer interface
 type KubernetesVersionChanger struct {
 	Config    transformertypes.Transformer
@@ -110,6 +129,14 @@ func (t *KubernetesVersionChanger) Transform(newArtifacts []transformertypes.Art
 			logrus.Errorf("Unable to load config for Transformer into %T : %s", sConfig, err)
 		}
 		tempDest := filepath.Join(t.Env.TempPath, "k8s-yamls-versionchanged-"+common.GetRandomString())
+		report := ConversionReport{
+			TypeMeta: types.TypeMeta{Kind: string(ConversionReportKind), APIVersion: types.SchemeGroupVersion.String()},
+			Spec: ConversionReportSpec{
+				TargetCluster:       clusterConfig.Name,
+				TargetServerVersion: clusterConfig.Spec.ServerVersion,
+				Directories:         map[string][]apiresource.ConversionReportEntry{},
+			},
+		}
 		err := filepath.WalkDir(yamlsPath, func(path string, info os.DirEntry, err error) error {
 			if err != nil && path == yamlsPath {
 				// if walk for root search path return gets error
@@ -127,11 +154,12 @@ func (t *KubernetesVersionChanger) Transform(newArtifacts []transformertypes.Art
 					return nil
 				}
 				if objs := k8sschema.GetKubernetesObjsInDir(path); len(objs) != 0 {
-					_, err := apiresource.TransformObjsAndPersist(path, filepath.Join(tempDest, relInputPath), apis, clusterConfig, t.KVCConfig.SetDefaultValuesInYamls)
+					_, entries, err := apiresource.TransformObjsAndPersistWithReport(path, filepath.Join(tempDest, relInputPath), apis, clusterConfig, t.KVCConfig.SetDefaultValuesInYamls)
 					if err != nil {
 						logrus.Errorf("Unable to transform objs at %s : %s", path, err)
 						return nil
 					}
+					report.Spec.Directories[relInputPath] = entries
 				}
 			}
 			return nil
@@ -151,6 +179,21 @@ func (t *KubernetesVersionChanger) Transform(newArtifacts []transformertypes.Art
 			SrcPath:  tempDest,
 			DestPath: outputPath,
 		})
+		reportName := sConfig.ServiceName
+		if reportName == "" {
+			reportName = filepath.Base(yamlsPath)
+		}
+		report.Name = reportName
+		reportPath := filepath.Join(t.Env.TempPath, "k8s-yamls-conversionreport-"+common.GetRandomString()+".yaml")
+		if err := common.WriteYaml(reportPath, report); err != nil {
+			logrus.Errorf("Unable to write the conversion report for the yamls at %s : %s", yamlsPath, err)
+		} else {
+			pathMappings = append(pathMappings, transformertypes.PathMapping{
+				Type:     transformertypes.DefaultPathMappingType,
+				SrcPath:  reportPath,
+				DestPath: filepath.Join(conversionReportsDir, common.MakeFileNameCompliant(reportName)+".yaml"),
+			})
+		}
 		na := transformertypes.Artifact{
 			Name: sConfig.ServiceName,
 			Type: artifacts.KubernetesYamlsInSourceArtifactType,
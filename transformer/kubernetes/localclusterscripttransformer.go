@@ -0,0 +1,179 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"path/filepath"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/transformer/kubernetes/irpreprocessor"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultLocalClusterScriptOutputPath = common.ScriptsDir
+	// minLocalPort is the smallest local port used for port forwarding, since lower ports need root privileges
+	minLocalPort = 1024
+)
+
+// LocalClusterScript implements Transformer interface
+type LocalClusterScript struct {
+	Config                   transformertypes.Transformer
+	Env                      *environment.Environment
+	LocalClusterScriptConfig *LocalClusterScriptConfig
+}
+
+// LocalClusterScriptConfig stores the transformer specific configuration
+type LocalClusterScriptConfig struct {
+	OutputPath string `yaml:"outputPath"`
+	DeployPath string `yaml:"deployPath"`
+}
+
+// LocalClusterScriptTemplateConfig represents template config used by the local cluster script
+type LocalClusterScriptTemplateConfig struct {
+	ClusterName       string
+	RegistryURL       string
+	RegistryNamespace string
+	DeployPath        string
+	Images            []string
+	PortForwards      []LocalClusterPortForward
+}
+
+// LocalClusterPortForward stores a service port to be forwarded to a local port
+type LocalClusterPortForward struct {
+	ServiceName string
+	ServicePort int32
+	LocalPort   int32
+}
+
+// Init Initializes the transformer
+func (t *LocalClusterScript) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.LocalClusterScriptConfig = &LocalClusterScriptConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.LocalClusterScriptConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.LocalClusterScriptConfig, err)
+		return err
+	}
+	if t.LocalClusterScriptConfig.OutputPath == "" {
+		t.LocalClusterScriptConfig.OutputPath = defaultLocalClusterScriptOutputPath
+	}
+	if t.LocalClusterScriptConfig.DeployPath == "" {
+		t.LocalClusterScriptConfig.DeployPath = defaultK8sYamlsOutputPath
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *LocalClusterScript) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in each sub directory
+func (t *LocalClusterScript) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform transforms the artifacts
+func (t *LocalClusterScript) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	tc := LocalClusterScriptTemplateConfig{
+		ClusterName: common.MakeStringK8sServiceNameCompliant(t.Env.ProjectName),
+		DeployPath:  common.GetUnixPath(t.LocalClusterScriptConfig.DeployPath),
+	}
+	services := map[string]irtypes.Service{}
+	for _, a := range append(alreadySeenArtifacts, newArtifacts...) {
+		switch a.Type {
+		case artifacts.NewImagesArtifactType:
+			images := artifacts.NewImages{}
+			if err := a.GetConfig(artifacts.NewImagesConfigType, &images); err != nil {
+				logrus.Errorf("Unable to read Image config : %s", err)
+				continue
+			}
+			tc.Images = common.MergeSlices(tc.Images, images.ImageNames)
+		case irtypes.IRArtifactType:
+			var ir irtypes.IR
+			if err := a.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+				logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+				continue
+			}
+			preprocessedIR, err := irpreprocessor.Preprocess(ir)
+			if err != nil {
+				logrus.Errorf("Unable to prepreocess IR : %s", err)
+			} else {
+				ir = preprocessedIR
+			}
+			for _, service := range ir.Services {
+				services[service.Name] = service
+			}
+		}
+	}
+	if len(services) == 0 {
+		return nil, nil, nil
+	}
+	tc.PortForwards = getLocalClusterPortForwards(services)
+	if len(tc.Images) > 0 {
+		tc.RegistryURL = commonqa.ImageRegistry()
+		tc.RegistryNamespace = commonqa.ImageRegistryNamespace()
+	}
+	return []transformertypes.PathMapping{{
+		Type:           transformertypes.TemplatePathMappingType,
+		SrcPath:        filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir),
+		DestPath:       t.LocalClusterScriptConfig.OutputPath,
+		TemplateConfig: tc,
+	}}, nil, nil
+}
+
+// getLocalClusterPortForwards returns the ports of the services sorted by service name.
+// The service port is used as the local port unless it is privileged or already used.
+func getLocalClusterPortForwards(services map[string]irtypes.Service) []LocalClusterPortForward {
+	serviceNames := []string{}
+	for serviceName := range services {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	portForwards := []LocalClusterPortForward{}
+	usedPorts := map[int32]bool{}
+	var nextFreePort int32 = 8080
+	for _, serviceName := range serviceNames {
+		service := services[serviceName]
+		if service.OnlyIngress {
+			continue
+		}
+		for _, forwarding := range service.ServiceToPodPortForwardings {
+			servicePort := forwarding.ServicePort.Number
+			if servicePort == 0 {
+				continue
+			}
+			localPort := servicePort
+			if localPort < minLocalPort || usedPorts[localPort] {
+				for usedPorts[nextFreePort] {
+					nextFreePort++
+				}
+				localPort = nextFreePort
+			}
+			usedPorts[localPort] = true
+			portForwards = append(portForwards, LocalClusterPortForward{ServiceName: serviceName, ServicePort: servicePort, LocalPort: localPort})
+		}
+	}
+	return portForwards
+}
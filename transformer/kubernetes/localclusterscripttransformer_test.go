@@ -0,0 +1,145 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func newLocalClusterTestService(name string, ports ...int32) irtypes.Service {
+	service := irtypes.NewServiceWithName(name)
+	service.Containers = []core.Container{{Name: name, Image: name + ":latest"}}
+	for _, port := range ports {
+		service.ServiceToPodPortForwardings = append(service.ServiceToPodPortForwardings, irtypes.ServiceToPodPortForwarding{
+			ServicePort: networking.ServiceBackendPort{Number: port},
+			PodPort:     networking.ServiceBackendPort{Number: port},
+			ServiceType: core.ServiceTypeClusterIP,
+		})
+	}
+	return service
+}
+
+func TestGetLocalClusterPortForwards(t *testing.T) {
+	ingress := newLocalClusterTestService("ingress", 443)
+	ingress.OnlyIngress = true
+	services := map[string]irtypes.Service{
+		"web":     newLocalClusterTestService("web", 80, 8080),
+		"api":     newLocalClusterTestService("api", 8080, 9090),
+		"worker":  newLocalClusterTestService("worker"),
+		"ingress": ingress,
+	}
+	want := []LocalClusterPortForward{
+		{ServiceName: "api", ServicePort: 8080, LocalPort: 8080},
+		{ServiceName: "api", ServicePort: 9090, LocalPort: 9090},
+		{ServiceName: "web", ServicePort: 80, LocalPort: 8081},
+		{ServiceName: "web", ServicePort: 8080, LocalPort: 8082},
+	}
+	if got := getLocalClusterPortForwards(services); !cmp.Equal(got, want) {
+		t.Fatalf("the port forwards are different. Difference:\n%s", cmp.Diff(want, got))
+	}
+}
+
+func TestLocalClusterScriptTransform(t *testing.T) {
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{
+		common.ConfigImageRegistryURLKey + `="quay.io"`,
+		common.ConfigImageRegistryNamespaceKey + `="myteam"`,
+	}, nil, nil, false)
+	tc := transformertypes.Transformer{}
+	tc.Spec.TemplatesDir = "templates"
+	env := &environment.Environment{EnvInfo: environment.EnvInfo{ProjectName: "My_Project", Context: filepath.Join("..", "..", "assets", "built-in", "transformers", "kubernetes", "localclusterscript")}}
+	transformer := &LocalClusterScript{}
+	if err := transformer.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	pathMappings, _, err := transformer.Transform([]transformertypes.Artifact{{
+		Name:    "images",
+		Type:    artifacts.NewImagesArtifactType,
+		Configs: map[transformertypes.ConfigType]interface{}{artifacts.NewImagesConfigType: artifacts.NewImages{ImageNames: []string{"api"}}},
+	}}, nil)
+	if err != nil || len(pathMappings) != 0 {
+		t.Fatalf("expected no script without services. Path mappings: %+v Error: %v", pathMappings, err)
+	}
+
+	ir := irtypes.NewIR()
+	ir.Name = "myproject"
+	ir.Services["api"] = newLocalClusterTestService("api", 8080)
+	pathMappings, _, err = transformer.Transform([]transformertypes.Artifact{
+		{Name: "myproject", Type: irtypes.IRArtifactType, Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir}},
+	}, []transformertypes.Artifact{{
+		Name:    "images",
+		Type:    artifacts.NewImagesArtifactType,
+		Configs: map[transformertypes.ConfigType]interface{}{artifacts.NewImagesConfigType: artifacts.NewImages{ImageNames: []string{"api"}}},
+	}})
+	if err != nil {
+		t.Fatalf("failed to transform. Error: %q", err)
+	}
+	if len(pathMappings) != 1 || pathMappings[0].Type != transformertypes.TemplatePathMappingType || pathMappings[0].DestPath != common.ScriptsDir {
+		t.Fatalf("expected the template of the script in the scripts directory. Actual: %+v", pathMappings)
+	}
+	templateConfig := pathMappings[0].TemplateConfig.(LocalClusterScriptTemplateConfig)
+	want := LocalClusterScriptTemplateConfig{
+		ClusterName:       "my-project",
+		RegistryURL:       "quay.io",
+		RegistryNamespace: "myteam",
+		DeployPath:        "deploy/yamls",
+		Images:            []string{"api"},
+		PortForwards:      []LocalClusterPortForward{{ServiceName: "api", ServicePort: 8080, LocalPort: 8080}},
+	}
+	if !cmp.Equal(templateConfig, want) {
+		t.Fatalf("the template config is different. Difference:\n%s", cmp.Diff(want, templateConfig))
+	}
+	tpl, err := os.ReadFile(filepath.Join(pathMappings[0].SrcPath, "deploy-local.sh"))
+	if err != nil {
+		t.Fatalf("failed to read the template. Error: %q", err)
+	}
+	script, err := common.GetStringFromTemplate(string(tpl), templateConfig)
+	if err != nil {
+		t.Fatalf("failed to render the template. Error: %q", err)
+	}
+	for _, line := range []string{
+		"CLUSTER_NAME=my-project",
+		"DEPLOY_DIR=deploy/yamls",
+		"kind load docker-image ${REGISTRY_URL}/${REGISTRY_NAMESPACE}/api --name \"${CLUSTER_NAME}\"",
+		"kubectl port-forward service/api 8080:8080 > /dev/null &",
+	} {
+		if !strings.Contains(script, line+"\n") {
+			t.Fatalf("expected the line %q in the script:\n%s", line, script)
+		}
+	}
+	if bash, err := exec.LookPath("bash"); err == nil {
+		if output, err := exec.Command(bash, "-n", "-c", script).CombinedOutput(); err != nil {
+			t.Fatalf("the script has syntax errors. Error: %q\n%s", err, output)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// flatManifestsLayout writes all the yamls to the same directory
+	flatManifestsLayout = "flat"
+	// kindManifestsLayout writes the yamls to a directory per kind, along with the order the directories should be applied in
+	kindManifestsLayout = "bykind"
+	// scopeManifestsLayout writes the cluster scoped yamls and the namespaced yamls to separate directories, each laid out by kind with its own apply script,
+	// since the cluster scoped resources are usually applied by the platform team with more permissions
+	scopeManifestsLayout = "byscope"
+	// clusterScopedManifestsDir and namespacedManifestsDir are the directories of the byscope layout
+	clusterScopedManifestsDir = "cluster-scoped"
+	namespacedManifestsDir    = "namespaced"
+	// applyOrderFile lists the directories of the kinds in the order they should be applied in
+	applyOrderFile          = "apply-order.txt"
+	applyScriptTemplateFile = "apply.sh"
+	otherKindsGroup         = "others"
+)
+
+// manifestApplyGroups are the kinds in the order they should be applied in, so that the resources exist before the resources which use them.
+// The kinds which are not listed are applied at the end.
+var manifestApplyGroups = []struct {
+	name  string
+	kinds []string
+}{
+	{name: "namespaces", kinds: []string{"Namespace"}},
+	{name: "crds", kinds: []string{"CustomResourceDefinition"}},
+	{name: "rbac", kinds: []string{"ServiceAccount", "Role", "ClusterRole", "RoleBinding", "ClusterRoleBinding", "PodSecurityPolicy"}},
+	{name: "config", kinds: []string{"ResourceQuota", "LimitRange", "PriorityClass", "StorageClass", "ConfigMap", "Secret", "PersistentVolume", "PersistentVolumeClaim"}},
+	{name: "workloads", kinds: []string{"ImageStream", "BuildConfig", common.PodKind, "ReplicaSet", "ReplicationController", "DeploymentConfig", common.DeploymentKind, common.StatefulSetKind, common.DaemonSetKind, common.JobKind, "CronJob", "HorizontalPodAutoscaler", "PodDisruptionBudget"}},
+	{name: "networking", kinds: []string{common.ServiceKind, common.IngressKind, "Route", "NetworkPolicy"}},
+}
+
+// clusterScopedKinds are the kinds of the resources which do not belong to a namespace
+var clusterScopedKinds = []string{
+	"Namespace", "CustomResourceDefinition", "ClusterRole", "ClusterRoleBinding", "PodSecurityPolicy", "SecurityContextConstraints",
+	"PriorityClass", "StorageClass", "PersistentVolume", "IngressClass", "RuntimeClass", "ClusterIssuer",
+	"MutatingWebhookConfiguration", "ValidatingWebhookConfiguration", "APIService",
+}
+
+// ApplyScriptTemplateConfig stores the data used to fill the script which applies the yamls in order
+type ApplyScriptTemplateConfig struct {
+	ApplyOrderFile string
+	// ClusterScoped is true for the script applying the cluster scoped yamls of the byscope layout
+	ClusterScoped bool
+}
+
+// getManifestsLayout asks how the generated kubernetes yamls should be laid out
+func getManifestsLayout() string {
+	return qaengine.FetchSelectAnswer(
+		common.ConfigManifestsLayoutKey,
+		"Select the layout of the generated kubernetes yamls :",
+		[]string{
+			"flat : all the yamls are written to the same directory",
+			"bykind : the yamls are written to a directory per kind, along with " + applyOrderFile + " and " + applyScriptTemplateFile + " to apply the directories in order",
+			"byscope : the cluster scoped yamls, like the CRDs, the ClusterRoles, the Namespaces and the StorageClasses, and the namespaced yamls are written to the " +
+				clusterScopedManifestsDir + " and " + namespacedManifestsDir + " directories, each laid out by kind with its own " + applyScriptTemplateFile,
+		},
+		flatManifestsLayout,
+		[]string{flatManifestsLayout, kindManifestsLayout, scopeManifestsLayout},
+		nil,
+	)
+}
+
+// getKindApplyOrder returns the group of the kind and its position in the group
+func getKindApplyOrder(kind string) (int, int) {
+	for i, group := range manifestApplyGroups {
+		for j, groupKind := range group.kinds {
+			if groupKind == kind {
+				return i, j
+			}
+		}
+	}
+	return len(manifestApplyGroups), 0
+}
+
+// groupManifestsByKind moves each yaml in the directory into a sub directory named after its kind
+// and writes the order the sub directories should be applied in
+func groupManifestsByKind(yamlsPath string) error {
+	resources, err := k8sschema.GetK8sResourcesWithPaths(yamlsPath, true)
+	if err != nil {
+		return fmt.Errorf("failed to read the yamls in the directory %s . Error: %w", yamlsPath, err)
+	}
+	kindDirs := map[string]string{}
+	for relPath, fileResources := range resources {
+		if len(fileResources) == 0 {
+			continue
+		}
+		kind, _, _, err := k8sschema.GetInfoFromK8sResource(fileResources[0])
+		if err != nil {
+			logrus.Debugf("Leaving the file %s as is since its kind could not be found. Error: %q", relPath, err)
+			continue
+		}
+		kindDir := strings.ToLower(kind)
+		kindDirs[kind] = kindDir
+		if err := os.MkdirAll(filepath.Join(yamlsPath, kindDir), common.DefaultDirectoryPermission); err != nil {
+			return fmt.Errorf("failed to create the directory for the kind %s . Error: %w", kind, err)
+		}
+		if err := os.Rename(filepath.Join(yamlsPath, relPath), filepath.Join(yamlsPath, kindDir, filepath.Base(relPath))); err != nil {
+			return fmt.Errorf("failed to move the file %s to the directory of the kind %s . Error: %w", relPath, kind, err)
+		}
+	}
+	kinds := []string{}
+	for kind := range kindDirs {
+		kinds = append(kinds, kind)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		gi, oi := getKindApplyOrder(kinds[i])
+		gj, oj := getKindApplyOrder(kinds[j])
+		if gi != gj {
+			return gi < gj
+		}
+		if oi != oj {
+			return oi < oj
+		}
+		return kinds[i] < kinds[j]
+	})
+	applyOrder := "# The directories of the kinds in the order they should be applied in. Use " + applyScriptTemplateFile + " to apply them.\n"
+	lastGroup := -1
+	for _, kind := range kinds {
+		if group, _ := getKindApplyOrder(kind); group != lastGroup {
+			lastGroup = group
+			groupName := otherKindsGroup
+			if group < len(manifestApplyGroups) {
+				groupName = manifestApplyGroups[group].name
+			}
+			applyOrder += "# " + groupName + "\n"
+		}
+		applyOrder += kindDirs[kind] + "\n"
+	}
+	if err := os.WriteFile(filepath.Join(yamlsPath, applyOrderFile), []byte(applyOrder), common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the apply order of the yamls. Error: %w", err)
+	}
+	return nil
+}
+
+// groupManifestsByScope moves each yaml in the directory into the directory of the cluster scoped yamls or the directory of the namespaced yamls
+// and lays out each of them by kind. It returns the directories which have yamls.
+func groupManifestsByScope(yamlsPath string) ([]string, error) {
+	resources, err := k8sschema.GetK8sResourcesWithPaths(yamlsPath, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the yamls in the directory %s . Error: %w", yamlsPath, err)
+	}
+	scopeDirs := []string{}
+	for relPath, fileResources := range resources {
+		if len(fileResources) == 0 {
+			continue
+		}
+		kind, _, _, err := k8sschema.GetInfoFromK8sResource(fileResources[0])
+		if err != nil {
+			logrus.Debugf("Leaving the file %s as is since its kind could not be found. Error: %q", relPath, err)
+			continue
+		}
+		scopeDir := namespacedManifestsDir
+		if common.IsPresent(clusterScopedKinds, kind) {
+			scopeDir = clusterScopedManifestsDir
+		}
+		scopeDirs = common.AppendIfNotPresent(scopeDirs, scopeDir)
+		if err := os.MkdirAll(filepath.Join(yamlsPath, scopeDir), common.DefaultDirectoryPermission); err != nil {
+			return nil, fmt.Errorf("failed to create the directory %s . Error: %w", scopeDir, err)
+		}
+		if err := os.Rename(filepath.Join(yamlsPath, relPath), filepath.Join(yamlsPath, scopeDir, filepath.Base(relPath))); err != nil {
+			return nil, fmt.Errorf("failed to move the file %s to the directory %s . Error: %w", relPath, scopeDir, err)
+		}
+	}
+	// the cluster scoped resources, like the namespaces and the CRDs, have to exist before the namespaced resources
+	sort.Strings(scopeDirs)
+	for _, scopeDir := range scopeDirs {
+		if err := groupManifestsByKind(filepath.Join(yamlsPath, scopeDir)); err != nil {
+			return nil, err
+		}
+	}
+	return scopeDirs, nil
+}
+
+// getManifestsLayoutPathMappings lays out the yamls written to the directory as selected
+// and returns the path mappings of the scripts which apply them in order
+func (t *Kubernetes) getManifestsLayoutPathMappings(yamlsPath, outputPath string) []transformertypes.PathMapping {
+	applyScriptPath := filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir, applyScriptTemplateFile)
+	switch getManifestsLayout() {
+	case kindManifestsLayout:
+		if err := groupManifestsByKind(yamlsPath); err != nil {
+			logrus.Errorf("failed to group the yamls in %s by kind. Error: %q", yamlsPath, err)
+			return nil
+		}
+		return []transformertypes.PathMapping{{
+			Type:           transformertypes.TemplatePathMappingType,
+			SrcPath:        applyScriptPath,
+			DestPath:       filepath.Join(outputPath, applyScriptTemplateFile),
+			TemplateConfig: ApplyScriptTemplateConfig{ApplyOrderFile: applyOrderFile},
+		}}
+	case scopeManifestsLayout:
+		scopeDirs, err := groupManifestsByScope(yamlsPath)
+		if err != nil {
+			logrus.Errorf("failed to group the yamls in %s by scope. Error: %q", yamlsPath, err)
+			return nil
+		}
+		pathMappings := []transformertypes.PathMapping{}
+		for _, scopeDir := range scopeDirs {
+			pathMappings = append(pathMappings, transformertypes.PathMapping{
+				Type:           transformertypes.TemplatePathMappingType,
+				SrcPath:        applyScriptPath,
+				DestPath:       filepath.Join(outputPath, scopeDir, applyScriptTemplateFile),
+				TemplateConfig: ApplyScriptTemplateConfig{ApplyOrderFile: applyOrderFile, ClusterScoped: scopeDir == clusterScopedManifestsDir},
+			})
+		}
+		return pathMappings
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+// writeLayoutTestManifests writes a yaml of each kind to the directory
+func writeLayoutTestManifests(t *testing.T, yamlsPath string) {
+	t.Helper()
+	manifests := map[string]string{
+		"api-service.yaml":        "apiVersion: v1\nkind: Service\nmetadata:\n  name: api\n",
+		"api-deployment.yaml":     "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: api\n",
+		"myproject-ns.yaml":       "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: myproject\n",
+		"widgets-crd.yaml":        "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: widgets.example.com\n",
+		"api-configmap.yaml":      "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: api\n",
+		"reader-clusterrole.yaml": "apiVersion: rbac.authorization.k8s.io/v1\nkind: ClusterRole\nmetadata:\n  name: reader\n",
+		"my-widget.yaml":          "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: my-widget\n",
+	}
+	for name, manifest := range manifests {
+		if err := os.WriteFile(filepath.Join(yamlsPath, name), []byte(manifest), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the yaml %s . Error: %q", name, err)
+		}
+	}
+}
+
+// readLines returns the lines of the file
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read the file %s . Error: %q", path, err)
+	}
+	return strings.Split(strings.TrimSpace(string(contents)), "\n")
+}
+
+func TestGroupManifestsByKind(t *testing.T) {
+	yamlsPath := t.TempDir()
+	writeLayoutTestManifests(t, yamlsPath)
+	if err := groupManifestsByKind(yamlsPath); err != nil {
+		t.Fatalf("failed to group the yamls by kind. Error: %q", err)
+	}
+	want := []string{
+		"# The directories of the kinds in the order they should be applied in. Use apply.sh to apply them.",
+		"# namespaces", "namespace",
+		"# crds", "customresourcedefinition",
+		"# rbac", "clusterrole",
+		"# config", "configmap",
+		"# workloads", "deployment",
+		"# networking", "service",
+		"# others", "widget",
+	}
+	if got := readLines(t, filepath.Join(yamlsPath, applyOrderFile)); !cmp.Equal(got, want) {
+		t.Fatalf("the apply order differs. Differences:\n%s", cmp.Diff(want, got))
+	}
+	for _, path := range []string{"service/api-service.yaml", "deployment/api-deployment.yaml", "widget/my-widget.yaml"} {
+		if _, err := os.Stat(filepath.Join(yamlsPath, path)); err != nil {
+			t.Fatalf("expected the yaml to be moved to %s . Error: %q", path, err)
+		}
+	}
+}
+
+func TestGroupManifestsByScope(t *testing.T) {
+	yamlsPath := t.TempDir()
+	writeLayoutTestManifests(t, yamlsPath)
+	scopeDirs, err := groupManifestsByScope(yamlsPath)
+	if err != nil {
+		t.Fatalf("failed to group the yamls by scope. Error: %q", err)
+	}
+	if want := []string{clusterScopedManifestsDir, namespacedManifestsDir}; !cmp.Equal(scopeDirs, want) {
+		t.Fatalf("the scope directories differ. Differences:\n%s", cmp.Diff(want, scopeDirs))
+	}
+	for scopeDir, want := range map[string][]string{
+		clusterScopedManifestsDir: {"namespace", "customresourcedefinition", "clusterrole"},
+		namespacedManifestsDir:    {"configmap", "deployment", "service", "widget"},
+	} {
+		got := []string{}
+		for _, line := range readLines(t, filepath.Join(yamlsPath, scopeDir, applyOrderFile)) {
+			if !strings.HasPrefix(line, "#") {
+				got = append(got, line)
+			}
+		}
+		if !cmp.Equal(got, want) {
+			t.Fatalf("the apply order of %s differs. Differences:\n%s", scopeDir, cmp.Diff(want, got))
+		}
+	}
+}
+
+func TestGetManifestsLayoutPathMappings(t *testing.T) {
+	newTransformer := func() *Kubernetes {
+		tc := transformertypes.Transformer{}
+		tc.Spec.TemplatesDir = "templates"
+		return &Kubernetes{Config: tc, Env: &environment.Environment{EnvInfo: environment.EnvInfo{Context: filepath.Join("..", "..", "assets", "built-in", "transformers", "kubernetes", "kubernetes")}}}
+	}
+	setup := func(t *testing.T, layout string) {
+		t.Helper()
+		qaengine.Reset()
+		t.Cleanup(qaengine.Reset)
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", []string{common.ConfigManifestsLayoutKey + `="` + layout + `"`}, nil, nil, false)
+	}
+
+	t.Run("the flat layout leaves the yamls as is", func(t *testing.T) {
+		setup(t, flatManifestsLayout)
+		yamlsPath := t.TempDir()
+		writeLayoutTestManifests(t, yamlsPath)
+		if pathMappings := newTransformer().getManifestsLayoutPathMappings(yamlsPath, "deploy/yamls"); len(pathMappings) != 0 {
+			t.Fatalf("expected no apply scripts. Actual: %+v", pathMappings)
+		}
+		if _, err := os.Stat(filepath.Join(yamlsPath, "api-service.yaml")); err != nil {
+			t.Fatalf("expected the yamls to be left as is. Error: %q", err)
+		}
+	})
+
+	t.Run("the byscope layout gets an apply script per scope", func(t *testing.T) {
+		setup(t, scopeManifestsLayout)
+		yamlsPath := t.TempDir()
+		writeLayoutTestManifests(t, yamlsPath)
+		pathMappings := newTransformer().getManifestsLayoutPathMappings(yamlsPath, "deploy/yamls")
+		destPaths := []string{}
+		for _, pathMapping := range pathMappings {
+			destPaths = append(destPaths, pathMapping.DestPath)
+			if want := (ApplyScriptTemplateConfig{ApplyOrderFile: applyOrderFile, ClusterScoped: strings.Contains(pathMapping.DestPath, clusterScopedManifestsDir)}); pathMapping.TemplateConfig != want {
+				t.Fatalf("got the template config %+v for %s , want %+v", pathMapping.TemplateConfig, pathMapping.DestPath, want)
+			}
+		}
+		want := []string{filepath.Join("deploy", "yamls", clusterScopedManifestsDir, applyScriptTemplateFile), filepath.Join("deploy", "yamls", namespacedManifestsDir, applyScriptTemplateFile)}
+		if !cmp.Equal(destPaths, want) {
+			t.Fatalf("the apply scripts differ. Differences:\n%s", cmp.Diff(want, destPaths))
+		}
+	})
+
+	t.Run("the apply script of the bykind layout applies the directories in order", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("the apply script is a bash script")
+		}
+		if _, err := exec.LookPath("bash"); err != nil {
+			t.Skip("bash is not available")
+		}
+		setup(t, kindManifestsLayout)
+		yamlsPath := t.TempDir()
+		writeLayoutTestManifests(t, yamlsPath)
+		pathMappings := newTransformer().getManifestsLayoutPathMappings(yamlsPath, "deploy/yamls")
+		if len(pathMappings) != 1 || pathMappings[0].DestPath != filepath.Join("deploy", "yamls", applyScriptTemplateFile) {
+			t.Fatalf("expected a single apply script for the yamls. Actual: %+v", pathMappings)
+		}
+		template, err := os.ReadFile(pathMappings[0].SrcPath)
+		if err != nil {
+			t.Fatalf("failed to read the template of the apply script. Error: %q", err)
+		}
+		script, err := common.GetStringFromTemplate(string(template), pathMappings[0].TemplateConfig)
+		if err != nil {
+			t.Fatalf("failed to fill the template of the apply script. Error: %q", err)
+		}
+		scriptPath := filepath.Join(yamlsPath, applyScriptTemplateFile)
+		if err := os.WriteFile(scriptPath, []byte(script), 0755); err != nil {
+			t.Fatalf("failed to write the apply script. Error: %q", err)
+		}
+		// a fake kubectl which records the commands it is invoked with
+		binPath := t.TempDir()
+		logPath := filepath.Join(binPath, "kubectl.log")
+		if err := os.WriteFile(filepath.Join(binPath, "kubectl"), []byte("#!/bin/sh\necho \"$@\" >> '"+logPath+"'\n"), 0755); err != nil {
+			t.Fatalf("failed to write the fake kubectl. Error: %q", err)
+		}
+		cmd := exec.Command("bash", scriptPath, "--dry-run=server")
+		cmd.Env = append(os.Environ(), "PATH="+binPath+string(os.PathListSeparator)+os.Getenv("PATH"))
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("failed to run the apply script. Output: %s Error: %q", output, err)
+		}
+		want := []string{
+			"apply -f namespace --dry-run=server",
+			"apply -f customresourcedefinition --dry-run=server",
+			"wait --for condition=established --timeout=60s -f customresourcedefinition",
+			"apply -f clusterrole --dry-run=server",
+			"apply -f configmap --dry-run=server",
+			"apply -f deployment --dry-run=server",
+			"apply -f service --dry-run=server",
+			"apply -f widget --dry-run=server",
+		}
+		if got := readLines(t, logPath); !cmp.Equal(got, want) {
+			t.Fatalf("the kubectl commands differ. Differences:\n%s", cmp.Diff(want, got))
+		}
+	})
+}
+
+func TestGroupManifestsByScopeWithOnlyNamespacedYamls(t *testing.T) {
+	yamlsPath := t.TempDir()
+	files := map[string]string{
+		"api-service.yaml": "apiVersion: v1\nkind: Service\nmetadata:\n  name: api\n",
+		"notes.yaml":       "just: some notes\n",
+	}
+	for name, contents := range files {
+		if err := os.WriteFile(filepath.Join(yamlsPath, name), []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the yaml %s . Error: %q", name, err)
+		}
+	}
+	scopeDirs, err := groupManifestsByScope(yamlsPath)
+	if err != nil {
+		t.Fatalf("failed to group the yamls by scope. Error: %q", err)
+	}
+	if want := []string{namespacedManifestsDir}; !cmp.Equal(scopeDirs, want) {
+		t.Fatalf("the scope directories differ. Differences:\n%s", cmp.Diff(want, scopeDirs))
+	}
+	if _, err := os.Stat(filepath.Join(yamlsPath, clusterScopedManifestsDir)); !os.IsNotExist(err) {
+		t.Fatalf("expected no directory for the cluster scoped yamls when there are none")
+	}
+	for _, path := range []string{filepath.Join(namespacedManifestsDir, "service", "api-service.yaml"), "notes.yaml"} {
+		if _, err := os.Stat(filepath.Join(yamlsPath, path)); err != nil {
+			t.Fatalf("expected the file at %s . Error: %q", path, err)
+		}
+	}
+}
+
+func TestApplyScriptOfTheClusterScopedYamls(t *testing.T) {
+	templatePath := filepath.Join("..", "..", "assets", "built-in", "transformers", "kubernetes", "kubernetes", "templates", applyScriptTemplateFile)
+	template, err := os.ReadFile(templatePath)
+	if err != nil {
+		t.Fatalf("failed to read the template of the apply script. Error: %q", err)
+	}
+	for _, clusterScoped := range []bool{true, false} {
+		script, err := common.GetStringFromTemplate(string(template), ApplyScriptTemplateConfig{ApplyOrderFile: applyOrderFile, ClusterScoped: clusterScoped})
+		if err != nil {
+			t.Fatalf("failed to fill the template of the apply script. Error: %q", err)
+		}
+		if got := strings.Contains(script, "permissions of a cluster administrator"); got != clusterScoped {
+			t.Errorf("expected the script for the cluster scoped yamls %t to mention the cluster administrator: %t. Script:\n%s", clusterScoped, got, script)
+		}
+		// the cluster scoped resources do not belong to a namespace
+		if got := strings.Contains(script, "--namespace"); got == clusterScoped {
+			t.Errorf("expected the script for the cluster scoped yamls %t to have the namespace example: %t. Script:\n%s", clusterScoped, got, script)
+		}
+	}
+}
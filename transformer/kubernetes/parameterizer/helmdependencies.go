@@ -0,0 +1,467 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package parameterizer
+
+import (
+	"encoding/base64"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/deepcopy"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	bitnamiChartsRepository = "https://charts.bitnami.com/bitnami"
+	secretKind              = "Secret"
+	configMapKind           = "ConfigMap"
+	serviceKind             = "Service"
+	pvcKind                 = "PersistentVolumeClaim"
+)
+
+// workloadKinds are the kinds whose pod template is checked for the images of the backing services
+var workloadKinds = []string{"Deployment", "StatefulSet", "DeploymentConfig", "ReplicaSet", "ReplicationController"}
+
+// helmDependencyChart is a well known chart which can replace the manifests of a backing service
+type helmDependencyChart struct {
+	name    string
+	version string
+	// images are the names of the images, without the registry and the tag, which are replaced by the chart
+	images []string
+	// component is the value of the app.kubernetes.io/component label on the pods of the chart
+	component string
+	// getValues returns the values of the chart and the passwords, by the keys the chart expects in the existing secret,
+	// derived from the environment variables of the backing service
+	getValues func(env map[string]string) (HelmValuesT, map[string]string)
+}
+
+// helmDependency is a backing service which is deployed using a dependency chart
+type helmDependency struct {
+	serviceName string
+	chart       helmDependencyChart
+	values      HelmValuesT
+	// secretData stores the passwords of the chart, which are written to a Secret instead of the values
+	secretData map[string]string
+	// podLabels are the labels of the pods of the workload being replaced, used to find its Services
+	podLabels map[string]string
+	pvcNames  []string
+}
+
+var helmDependencyCharts = []helmDependencyChart{
+	{
+		name:      "postgresql",
+		version:   "12.x.x",
+		images:    []string{"postgres", "postgresql"},
+		component: "primary",
+		getValues: func(env map[string]string) (HelmValuesT, map[string]string) {
+			values, secretData := HelmValuesT{}, map[string]string{}
+			setHelmValue(values, "auth.database", getFirstEnvValue(env, "POSTGRES_DB", "POSTGRESQL_DATABASE"))
+			user := getFirstEnvValue(env, "POSTGRES_USER", "POSTGRESQL_USERNAME")
+			password := getFirstEnvValue(env, "POSTGRES_PASSWORD", "POSTGRESQL_PASSWORD")
+			if password == "" {
+				return values, secretData
+			}
+			secretData["postgres-password"] = password
+			if user != "" && user != "postgres" {
+				setHelmValue(values, "auth.username", user)
+				secretData["password"] = password
+			}
+			return values, secretData
+		},
+	},
+	{
+		name:      "mysql",
+		version:   "9.x.x",
+		images:    []string{"mysql", "mysql-server"},
+		component: "primary",
+		getValues: func(env map[string]string) (HelmValuesT, map[string]string) {
+			values, secretData := HelmValuesT{}, map[string]string{}
+			setHelmValue(values, "auth.username", env["MYSQL_USER"])
+			setHelmValue(values, "auth.database", env["MYSQL_DATABASE"])
+			// the chart requires the root password in the existing secret
+			if env["MYSQL_ROOT_PASSWORD"] == "" {
+				return values, secretData
+			}
+			secretData["mysql-root-password"] = env["MYSQL_ROOT_PASSWORD"]
+			setSecretData(secretData, "mysql-password", env["MYSQL_PASSWORD"])
+			return values, secretData
+		},
+	},
+	{
+		name:      "mariadb",
+		version:   "11.x.x",
+		images:    []string{"mariadb"},
+		component: "primary",
+		getValues: func(env map[string]string) (HelmValuesT, map[string]string) {
+			values, secretData := HelmValuesT{}, map[string]string{}
+			setHelmValue(values, "auth.username", getFirstEnvValue(env, "MARIADB_USER", "MYSQL_USER"))
+			setHelmValue(values, "auth.database", getFirstEnvValue(env, "MARIADB_DATABASE", "MYSQL_DATABASE"))
+			rootPassword := getFirstEnvValue(env, "MARIADB_ROOT_PASSWORD", "MYSQL_ROOT_PASSWORD")
+			if rootPassword == "" {
+				return values, secretData
+			}
+			secretData["mariadb-root-password"] = rootPassword
+			setSecretData(secretData, "mariadb-password", getFirstEnvValue(env, "MARIADB_PASSWORD", "MYSQL_PASSWORD"))
+			return values, secretData
+		},
+	},
+	{
+		name:      "mongodb",
+		version:   "13.x.x",
+		images:    []string{"mongo", "mongodb"},
+		component: "mongodb",
+		getValues: func(env map[string]string) (HelmValuesT, map[string]string) {
+			values, secretData := HelmValuesT{}, map[string]string{}
+			password := getFirstEnvValue(env, "MONGO_INITDB_ROOT_PASSWORD", "MONGODB_ROOT_PASSWORD")
+			if password == "" {
+				// the mongo image does not enable authentication unless a root password is given
+				setHelmValue(values, "auth.enabled", false)
+				return values, secretData
+			}
+			setHelmValue(values, "auth.rootUser", getFirstEnvValue(env, "MONGO_INITDB_ROOT_USERNAME", "MONGODB_ROOT_USER"))
+			secretData["mongodb-root-password"] = password
+			return values, secretData
+		},
+	},
+	{
+		name:      "redis",
+		version:   "17.x.x",
+		images:    []string{"redis"},
+		component: "master",
+		getValues: func(env map[string]string) (HelmValuesT, map[string]string) {
+			values, secretData := HelmValuesT{"architecture": "standalone"}, map[string]string{}
+			password := env["REDIS_PASSWORD"]
+			if password == "" {
+				// the redis image does not enable authentication unless a password is given
+				setHelmValue(values, "auth.enabled", false)
+				return values, secretData
+			}
+			setHelmValue(values, "auth.existingSecretPasswordKey", "redis-password")
+			secretData["redis-password"] = password
+			return values, secretData
+		},
+	},
+}
+
+// getHelmDependencies finds the backing services among the workloads and asks which of them should be deployed using dependency charts
+func getHelmDependencies(pathedKs map[string][]k8sschema.K8sResourceT) []helmDependency {
+	secrets := map[string]map[string]string{}
+	configMaps := map[string]map[string]string{}
+	for _, ks := range pathedKs {
+		for _, k := range ks {
+			kind, apiVersion, name, err := k8sschema.GetInfoFromK8sResource(k)
+			if err != nil || apiVersion != "v1" {
+				continue
+			}
+			switch kind {
+			case secretKind:
+				secret := corev1.Secret{}
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(k, &secret); err != nil {
+					logrus.Debugf("failed to parse the secret %s . Error: %q", name, err)
+					continue
+				}
+				data := map[string]string{}
+				for key, value := range secret.Data {
+					data[key] = string(value)
+				}
+				for key, value := range secret.StringData {
+					data[key] = value
+				}
+				secrets[name] = data
+			case configMapKind:
+				configMap := corev1.ConfigMap{}
+				if err := runtime.DefaultUnstructuredConverter.FromUnstructured(k, &configMap); err != nil {
+					logrus.Debugf("failed to parse the config map %s . Error: %q", name, err)
+					continue
+				}
+				configMaps[name] = configMap.Data
+			}
+		}
+	}
+	detected := map[string]helmDependency{}
+	for _, ks := range pathedKs {
+		for _, k := range ks {
+			kind, _, name, err := k8sschema.GetInfoFromK8sResource(k)
+			if err != nil || !common.IsPresent(workloadKinds, kind) {
+				continue
+			}
+			spec, ok := k["spec"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			templateI, ok := spec["template"].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			template := corev1.PodTemplateSpec{}
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(templateI, &template); err != nil {
+				logrus.Debugf("failed to parse the pod template of the %s %s . Error: %q", kind, name, err)
+				continue
+			}
+			// backing services which share the pod with other containers cannot be replaced
+			if len(template.Spec.Containers) != 1 {
+				continue
+			}
+			container := template.Spec.Containers[0]
+			chart, ok := getHelmDependencyChart(container.Image)
+			if !ok {
+				continue
+			}
+			dependency := helmDependency{
+				serviceName: name,
+				chart:       chart,
+				podLabels:   template.Labels,
+			}
+			dependency.values, dependency.secretData = chart.getValues(getContainerEnv(container, secrets, configMaps))
+			if len(dependency.secretData) != 0 {
+				setHelmValue(dependency.values, "auth.existingSecret", getHelmDependencySecretName(name))
+			} else {
+				logrus.Debugf("The passwords of the backing service %s are generated by the %s chart", name, chart.name)
+			}
+			for _, volume := range template.Spec.Volumes {
+				if volume.PersistentVolumeClaim != nil {
+					dependency.pvcNames = append(dependency.pvcNames, volume.PersistentVolumeClaim.ClaimName)
+				}
+			}
+			// the selector labels of the chart use the name override, since the name of an aliased chart varies across Helm versions
+			dependency.values["nameOverride"] = name
+			detected[name] = dependency
+		}
+	}
+	if len(detected) == 0 {
+		return nil
+	}
+	serviceNames := []string{}
+	for serviceName := range detected {
+		serviceNames = append(serviceNames, serviceName)
+	}
+	sort.Strings(serviceNames)
+	selectedServiceNames := qaengine.FetchMultiSelectAnswer(
+		common.ConfigHelmDependencyChartsKey,
+		"Select the backing services which should be deployed using well known Helm charts as dependencies of the generated Helm chart :",
+		[]string{"The values of the charts are derived from the environment variables of the services. The other services are deployed using the generated manifests."},
+		[]string{},
+		serviceNames,
+		nil,
+	)
+	dependencies := []helmDependency{}
+	for _, serviceName := range serviceNames {
+		if common.IsPresent(selectedServiceNames, serviceName) {
+			dependencies = append(dependencies, detected[serviceName])
+		}
+	}
+	return dependencies
+}
+
+// replaceHelmDependencyResources removes the workloads and volume claims of the backing services deployed using dependency charts.
+// The Services are retained, with their selectors pointing to the pods of the charts, so that the host names used by the other services do not change.
+func replaceHelmDependencyResources(pathedKs map[string][]k8sschema.K8sResourceT, dependencies []helmDependency) map[string][]k8sschema.K8sResourceT {
+	if len(dependencies) == 0 {
+		return pathedKs
+	}
+	newPathedKs := map[string][]k8sschema.K8sResourceT{}
+	for kPath, ks := range pathedKs {
+		newKs := []k8sschema.K8sResourceT{}
+		for _, k := range ks {
+			kind, apiVersion, name, err := k8sschema.GetInfoFromK8sResource(k)
+			if err != nil {
+				newKs = append(newKs, k)
+				continue
+			}
+			replaced := false
+			for _, dependency := range dependencies {
+				if common.IsPresent(workloadKinds, kind) && name == dependency.serviceName {
+					replaced = true
+					break
+				}
+				if kind == pvcKind && common.IsPresent(dependency.pvcNames, name) {
+					replaced = true
+					break
+				}
+				if kind == serviceKind && apiVersion == "v1" && selectsPods(k, dependency.podLabels) {
+					k = deepcopy.DeepCopy(k).(k8sschema.K8sResourceT)
+					selector := map[string]interface{}{
+						"app.kubernetes.io/name":      dependency.serviceName,
+						"app.kubernetes.io/instance":  "{{ .Release.Name }}",
+						"app.kubernetes.io/component": dependency.chart.component,
+					}
+					if err := set("spec.selector", selector, k); err != nil {
+						logrus.Errorf("failed to point the service %s to the pods of the %s chart. Error: %q", name, dependency.chart.name, err)
+					}
+					break
+				}
+			}
+			if !replaced {
+				newKs = append(newKs, k)
+			}
+		}
+		if len(newKs) > 0 {
+			newPathedKs[kPath] = newKs
+		}
+	}
+	for _, dependency := range dependencies {
+		if len(dependency.secretData) == 0 {
+			continue
+		}
+		secretName := getHelmDependencySecretName(dependency.serviceName)
+		newPathedKs[secretName+"-secret.yaml"] = []k8sschema.K8sResourceT{getHelmDependencySecret(secretName, dependency.secretData)}
+	}
+	return newPathedKs
+}
+
+// getHelmDependencySecretName returns the name of the Secret with the passwords of the dependency chart
+func getHelmDependencySecretName(serviceName string) string {
+	return serviceName + "-auth"
+}
+
+// getHelmDependencySecret returns the Secret used as the existing secret of the dependency chart,
+// so that the passwords derived from the backing service are not written to the values files
+func getHelmDependencySecret(name string, secretData map[string]string) k8sschema.K8sResourceT {
+	data := map[string]interface{}{}
+	for key, value := range secretData {
+		data[key] = base64.StdEncoding.EncodeToString([]byte(value))
+	}
+	return k8sschema.K8sResourceT{
+		"apiVersion": "v1",
+		"kind":       secretKind,
+		"metadata":   map[string]interface{}{"name": name},
+		"type":       string(corev1.SecretTypeOpaque),
+		"data":       data,
+	}
+}
+
+// addHelmDependencies adds the dependency charts to the Chart.yaml and their values to the values of each environment
+func addHelmDependencies(helmChartYaml map[string]interface{}, namedValues map[string]HelmValuesT, envs []string, dependencies []helmDependency) {
+	if len(dependencies) == 0 {
+		return
+	}
+	chartDependencies := []map[string]interface{}{}
+	for _, dependency := range dependencies {
+		chartDependencies = append(chartDependencies, map[string]interface{}{
+			"name":       dependency.chart.name,
+			"version":    dependency.chart.version,
+			"repository": bitnamiChartsRepository,
+			"alias":      dependency.serviceName,
+		})
+		for _, env := range envs {
+			if _, ok := namedValues[env]; !ok {
+				namedValues[env] = HelmValuesT{}
+			}
+			namedValues[env][dependency.serviceName] = deepcopy.DeepCopy(dependency.values)
+		}
+	}
+	helmChartYaml["dependencies"] = chartDependencies
+	logrus.Infof("The Helm chart has dependency charts. Run 'helm dependency update' on the chart before installing it.")
+}
+
+func getHelmDependencyChart(image string) (helmDependencyChart, bool) {
+	name := image
+	if idx := strings.Index(name, "@"); idx != -1 {
+		name = name[:idx]
+	}
+	name = name[strings.LastIndex(name, "/")+1:]
+	if idx := strings.Index(name, ":"); idx != -1 {
+		name = name[:idx]
+	}
+	for _, chart := range helmDependencyCharts {
+		if common.IsPresent(chart.images, name) {
+			return chart, true
+		}
+	}
+	return helmDependencyChart{}, false
+}
+
+// getContainerEnv returns the values of the environment variables of the container, resolving the references to secrets and config maps
+func getContainerEnv(container corev1.Container, secrets, configMaps map[string]map[string]string) map[string]string {
+	env := map[string]string{}
+	for _, envFrom := range container.EnvFrom {
+		data := map[string]string{}
+		if envFrom.SecretRef != nil {
+			data = secrets[envFrom.SecretRef.Name]
+		} else if envFrom.ConfigMapRef != nil {
+			data = configMaps[envFrom.ConfigMapRef.Name]
+		}
+		for key, value := range data {
+			env[envFrom.Prefix+key] = value
+		}
+	}
+	for _, envVar := range container.Env {
+		if envVar.ValueFrom == nil {
+			env[envVar.Name] = envVar.Value
+			continue
+		}
+		if ref := envVar.ValueFrom.SecretKeyRef; ref != nil {
+			if value, ok := secrets[ref.Name][ref.Key]; ok {
+				env[envVar.Name] = value
+			}
+		} else if ref := envVar.ValueFrom.ConfigMapKeyRef; ref != nil {
+			if value, ok := configMaps[ref.Name][ref.Key]; ok {
+				env[envVar.Name] = value
+			}
+		}
+	}
+	return env
+}
+
+// selectsPods returns true if the selector of the service matches the pod labels
+func selectsPods(service k8sschema.K8sResourceT, podLabels map[string]string) bool {
+	spec, ok := service["spec"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	selector, ok := spec["selector"].(map[string]interface{})
+	if !ok || len(selector) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if podValue, ok := podLabels[key]; !ok || podValue != value {
+			return false
+		}
+	}
+	return true
+}
+
+func getFirstEnvValue(env map[string]string, names ...string) string {
+	for _, name := range names {
+		if value := env[name]; value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// setSecretData sets the password at the key, skipping empty passwords
+func setSecretData(secretData map[string]string, key, password string) {
+	if password != "" {
+		secretData[key] = password
+	}
+}
+
+// setHelmValue sets the value at the key, skipping empty strings so that the defaults of the chart are used
+func setHelmValue(values HelmValuesT, key string, value interface{}) {
+	if s, ok := value.(string); ok && s == "" {
+		return
+	}
+	if err := setCreatingNew(key, value, values); err != nil {
+		logrus.Errorf("failed to set the Helm value %s . Error: %q", key, err)
+	}
+}
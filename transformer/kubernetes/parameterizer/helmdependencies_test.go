@@ -0,0 +1,125 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package parameterizer
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"sigs.k8s.io/yaml"
+)
+
+const helmDependenciesTestResources = `
+- apiVersion: v1
+  kind: Secret
+  metadata: {name: db-credentials}
+  data:
+    password: ` + "czNjcjN0" + `
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata: {name: db}
+  spec:
+    template:
+      metadata:
+        labels: {app: db}
+      spec:
+        containers:
+        - name: db
+          image: docker.io/library/postgres:14
+          env:
+          - {name: POSTGRES_USER, value: app}
+          - {name: POSTGRES_DB, value: orders}
+          - name: POSTGRES_PASSWORD
+            valueFrom:
+              secretKeyRef: {name: db-credentials, key: password}
+- apiVersion: apps/v1
+  kind: Deployment
+  metadata: {name: cache}
+  spec:
+    template:
+      metadata:
+        labels: {app: cache}
+      spec:
+        containers:
+        - name: cache
+          image: redis:7
+`
+
+func getAuthValue(values HelmValuesT, key string) (interface{}, bool) {
+	auth, ok := values["auth"].(map[string]interface{})
+	if !ok {
+		return nil, false
+	}
+	value, ok := auth[key]
+	return value, ok
+}
+
+func TestHelmDependenciesKeepThePasswordsOutOfTheValues(t *testing.T) {
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{common.ConfigHelmDependencyChartsKey + `=["cache", "db"]`}, nil, nil, false)
+	ks := []k8sschema.K8sResourceT{}
+	if err := yaml.Unmarshal([]byte(helmDependenciesTestResources), &ks); err != nil {
+		t.Fatalf("failed to parse the resources. Error: %q", err)
+	}
+	pathedKs := map[string][]k8sschema.K8sResourceT{"resources.yaml": ks}
+	dependencies := getHelmDependencies(pathedKs)
+	if len(dependencies) != 2 {
+		t.Fatalf("expected the dependencies for the cache and the database, got %+v", dependencies)
+	}
+	namedValues := map[string]HelmValuesT{}
+	addHelmDependencies(map[string]interface{}{}, namedValues, []string{"dev"}, dependencies)
+	valuesYaml, err := yaml.Marshal(namedValues)
+	if err != nil {
+		t.Fatalf("failed to marshal the values. Error: %q", err)
+	}
+	if strings.Contains(string(valuesYaml), "s3cr3t") {
+		t.Fatalf("expected the password not to be in the values, got:\n%s", valuesYaml)
+	}
+	db := namedValues["dev"]["db"].(HelmValuesT)
+	if v, _ := getAuthValue(db, "existingSecret"); v != "db-auth" {
+		t.Fatalf("expected the chart to use the secret db-auth, got the values %+v", db)
+	}
+	if v, _ := getAuthValue(db, "username"); v != "app" {
+		t.Fatalf("expected the user of the database in the values, got the values %+v", db)
+	}
+	cache := namedValues["dev"]["cache"].(HelmValuesT)
+	if v, ok := getAuthValue(cache, "existingSecret"); ok {
+		t.Fatalf("expected no secret for the cache without a password, got %v", v)
+	}
+
+	newPathedKs := replaceHelmDependencyResources(pathedKs, dependencies)
+	secrets := newPathedKs["db-auth-secret.yaml"]
+	if len(secrets) != 1 {
+		t.Fatalf("expected the secret with the passwords of the database, got the files %+v", newPathedKs)
+	}
+	data := secrets[0]["data"].(map[string]interface{})
+	for _, key := range []string{"postgres-password", "password"} {
+		value, _ := base64.StdEncoding.DecodeString(data[key].(string))
+		if string(value) != "s3cr3t" {
+			t.Fatalf("expected the password in the key %s of the secret, got %+v", key, data)
+		}
+	}
+	if _, ok := newPathedKs["cache-auth-secret.yaml"]; ok {
+		t.Fatalf("expected no secret for the cache without a password")
+	}
+}
@@ -0,0 +1,299 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package parameterizer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/common/deepcopy"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	kustomizeComponentsDir       = "components"
+	kustomizeComponentKind       = "Component"
+	kustomizeComponentAPIVersion = "kustomize.config.k8s.io/v1alpha1"
+	monitoringComponent          = "monitoring"
+	meshComponent                = "mesh"
+	debugComponent               = "debug"
+	debugContainerName           = "debug"
+	debugContainerImage          = "nicolaka/netshoot:latest"
+	grafanaDashboardLabel        = "grafana_dashboard"
+)
+
+var (
+	// monitoringKinds are the kinds of the monitoring resources which are moved from the base to the monitoring component
+	monitoringKinds = []string{"ServiceMonitor", "PodMonitor", "PrometheusRule"}
+	// meshAnnotations are the sidecar injection annotations which are moved from the base to the mesh component
+	meshAnnotations = []string{"sidecar.istio.io/inject", "linkerd.io/inject"}
+	// componentWorkloadKinds are the kinds whose pod templates are patched by the components
+	componentWorkloadKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController", "DeploymentConfig"}
+	// strategicMergeWorkloadKinds are the workload kinds whose containers can be added using strategic merge patches.
+	// The patches of the other kinds are json merge patches, which replace the lists.
+	strategicMergeWorkloadKinds = []string{"Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "ReplicationController"}
+)
+
+// kustomizeComponent is an optional capability which the environments opt into, instead of baking it into the base
+type kustomizeComponent struct {
+	name string
+	// resources are keyed by the path of the file relative to the component
+	resources map[string][]k8sschema.K8sResourceT
+	// patches are the strategic merge patches keyed by the filename
+	patches map[string]k8sschema.K8sResourceT
+}
+
+// getKustomizeComponents moves the optional capabilities out of the resources into kustomize components.
+// The resources of the base and the components which are not empty are returned.
+func getKustomizeComponents(pathedKs map[string][]k8sschema.K8sResourceT) (map[string][]k8sschema.K8sResourceT, []kustomizeComponent) {
+	monitoring := kustomizeComponent{name: monitoringComponent, resources: map[string][]k8sschema.K8sResourceT{}, patches: map[string]k8sschema.K8sResourceT{}}
+	mesh := kustomizeComponent{name: meshComponent, resources: map[string][]k8sschema.K8sResourceT{}, patches: map[string]k8sschema.K8sResourceT{}}
+	debug := kustomizeComponent{name: debugComponent, resources: map[string][]k8sschema.K8sResourceT{}, patches: map[string]k8sschema.K8sResourceT{}}
+	baseKs := map[string][]k8sschema.K8sResourceT{}
+	for kPath, ks := range pathedKs {
+		for _, k := range ks {
+			kind, apiVersion, name, err := k8sschema.GetInfoFromK8sResource(k)
+			if err != nil {
+				baseKs[kPath] = append(baseKs[kPath], k)
+				continue
+			}
+			if isMonitoringResource(k, kind) {
+				logrus.Debugf("Moving the %s %s to the %s kustomize component", kind, name, monitoringComponent)
+				monitoring.resources[kPath] = append(monitoring.resources[kPath], k)
+				continue
+			}
+			if !common.IsPresent(componentWorkloadKinds, kind) {
+				baseKs[kPath] = append(baseKs[kPath], k)
+				continue
+			}
+			k = deepcopy.DeepCopy(k).(k8sschema.K8sResourceT)
+			patchFilename := strings.ToLower(common.MakeFileNameCompliant(fmt.Sprintf("%s-%s.yaml", kind, name)))
+			if annotations := removeMeshAnnotations(k); len(annotations) > 0 {
+				mesh.patches[patchFilename] = newWorkloadPatch(kind, apiVersion, name, map[string]interface{}{"metadata": map[string]interface{}{"annotations": annotations}})
+			}
+			if port, ok := getFirstContainerPort(k); ok {
+				monitoring.patches[patchFilename] = newWorkloadPatch(kind, apiVersion, name, map[string]interface{}{"metadata": map[string]interface{}{"annotations": map[string]interface{}{
+					"prometheus.io/scrape": "true",
+					"prometheus.io/port":   fmt.Sprintf("%v", port),
+				}}})
+			}
+			if common.IsPresent(strategicMergeWorkloadKinds, kind) {
+				debug.patches[patchFilename] = newWorkloadPatch(kind, apiVersion, name, map[string]interface{}{"spec": map[string]interface{}{
+					"shareProcessNamespace": true,
+					"containers": []interface{}{map[string]interface{}{
+						"name":    debugContainerName,
+						"image":   debugContainerImage,
+						"command": []interface{}{"sleep", "infinity"},
+						"stdin":   true,
+						"tty":     true,
+					}},
+				}})
+			} else {
+				logrus.Debugf("Not adding the debug sidecar to the %s %s since its containers cannot be patched", kind, name)
+			}
+			baseKs[kPath] = append(baseKs[kPath], k)
+		}
+	}
+	components := []kustomizeComponent{}
+	for _, component := range []kustomizeComponent{monitoring, mesh, debug} {
+		if len(component.resources) > 0 || len(component.patches) > 0 {
+			components = append(components, component)
+		}
+	}
+	return baseKs, components
+}
+
+// getEnvKustomizeComponents asks which of the components each environment opts into
+func getEnvKustomizeComponents(envs []string, components []kustomizeComponent) map[string][]string {
+	envComponents := map[string][]string{}
+	if len(components) == 0 {
+		return envComponents
+	}
+	componentNames := []string{}
+	for _, component := range components {
+		componentNames = append(componentNames, component.name)
+	}
+	for _, env := range envs {
+		def := []string{}
+		for _, componentName := range componentNames {
+			// the debug sidecars have access to the processes of the pod, so they are only enabled for development by default
+			if componentName != debugComponent || env == "dev" {
+				def = append(def, componentName)
+			}
+		}
+		envComponents[env] = qaengine.FetchMultiSelectAnswer(
+			common.JoinQASubKeys(common.ConfigKustomizeComponentsKey, `"`+env+`"`),
+			fmt.Sprintf("Select the kustomize components for the %s environment :", env),
+			[]string{"The components are optional capabilities which are not part of the base. The components which are not selected can be added to the overlay later."},
+			def,
+			componentNames,
+			nil,
+		)
+	}
+	return envComponents
+}
+
+// writeKustomizeComponents writes each component in its own directory, returning the files written
+func writeKustomizeComponents(kustDir string, components []kustomizeComponent) []string {
+	filesWritten := []string{}
+	for _, component := range components {
+		componentDir := filepath.Join(kustDir, kustomizeComponentsDir, component.name)
+		if err := os.MkdirAll(componentDir, common.DefaultDirectoryPermission); err != nil {
+			logrus.Errorf("Unable to create the directory for the kustomize component %s (%s) : %s", component.name, componentDir, err)
+			continue
+		}
+		kPaths := []string{}
+		for kPath, ks := range component.resources {
+			finalKPath := filepath.Join(componentDir, kPath)
+			for _, k := range ks {
+				if err := writeResourceAppendToFile(k, finalKPath); err != nil {
+					logrus.Errorf("Unable to append the resource to the file (%s) of the kustomize component %s : %s", finalKPath, component.name, err)
+					continue
+				}
+			}
+			kPaths = append(kPaths, kPath)
+			filesWritten = append(filesWritten, finalKPath)
+		}
+		sort.Strings(kPaths)
+		patchFilenames := []string{}
+		for patchFilename := range component.patches {
+			patchFilenames = append(patchFilenames, patchFilename)
+		}
+		sort.Strings(patchFilenames)
+		patches := []map[string]string{}
+		for _, patchFilename := range patchFilenames {
+			finalKPath := filepath.Join(componentDir, patchFilename)
+			if err := common.WriteYaml(finalKPath, component.patches[patchFilename]); err != nil {
+				logrus.Errorf("Unable to write the patch file (%s) of the kustomize component %s : %s", finalKPath, component.name, err)
+				continue
+			}
+			patches = append(patches, map[string]string{"path": patchFilename})
+			filesWritten = append(filesWritten, finalKPath)
+		}
+		kustomization := map[string]interface{}{"apiVersion": kustomizeComponentAPIVersion, "kind": kustomizeComponentKind}
+		if len(kPaths) > 0 {
+			kustomization["resources"] = kPaths
+		}
+		if len(patches) > 0 {
+			kustomization["patches"] = patches
+		}
+		finalKPath := filepath.Join(componentDir, "kustomization.yaml")
+		if err := common.WriteYaml(finalKPath, kustomization); err != nil {
+			logrus.Errorf("Unable to write %s : %s", finalKPath, err)
+			continue
+		}
+		filesWritten = append(filesWritten, finalKPath)
+	}
+	return filesWritten
+}
+
+// isMonitoringResource returns true for the prometheus operator resources and the config maps of the Grafana dashboards
+func isMonitoringResource(k k8sschema.K8sResourceT, kind string) bool {
+	if common.IsPresent(monitoringKinds, kind) {
+		return true
+	}
+	if kind != configMapKind {
+		return false
+	}
+	labels, ok, _ := unstructured.NestedFieldNoCopy(k, "metadata", "labels")
+	if !ok {
+		return false
+	}
+	labelsMap, ok := labels.(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, ok = labelsMap[grafanaDashboardLabel]
+	return ok
+}
+
+// removeMeshAnnotations removes the sidecar injection annotations from the pod template of the workload and returns them
+func removeMeshAnnotations(k k8sschema.K8sResourceT) map[string]interface{} {
+	removed := map[string]interface{}{}
+	annotationsI, ok, _ := unstructured.NestedFieldNoCopy(k, "spec", "template", "metadata", "annotations")
+	if !ok {
+		return removed
+	}
+	annotations, ok := annotationsI.(map[string]interface{})
+	if !ok {
+		return removed
+	}
+	for _, annotation := range meshAnnotations {
+		if value, ok := annotations[annotation]; ok {
+			removed[annotation] = value
+			delete(annotations, annotation)
+		}
+	}
+	if len(annotations) == 0 {
+		unstructured.RemoveNestedField(k, "spec", "template", "metadata", "annotations")
+	}
+	// the annotations of the pods are also set on the workloads
+	if metadataAnnotationsI, ok, _ := unstructured.NestedFieldNoCopy(k, "metadata", "annotations"); ok {
+		if metadataAnnotations, ok := metadataAnnotationsI.(map[string]interface{}); ok {
+			for annotation := range removed {
+				delete(metadataAnnotations, annotation)
+			}
+			if len(metadataAnnotations) == 0 {
+				unstructured.RemoveNestedField(k, "metadata", "annotations")
+			}
+		}
+	}
+	return removed
+}
+
+// getFirstContainerPort returns the first port of the containers of the workload
+func getFirstContainerPort(k k8sschema.K8sResourceT) (interface{}, bool) {
+	containersI, ok, _ := unstructured.NestedFieldNoCopy(k, "spec", "template", "spec", "containers")
+	if !ok {
+		return nil, false
+	}
+	containers, ok := containersI.([]interface{})
+	if !ok {
+		return nil, false
+	}
+	for _, containerI := range containers {
+		container, ok := containerI.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		ports, ok := container["ports"].([]interface{})
+		if !ok || len(ports) == 0 {
+			continue
+		}
+		if port, ok := ports[0].(map[string]interface{}); ok && port["containerPort"] != nil {
+			return port["containerPort"], true
+		}
+	}
+	return nil, false
+}
+
+// newWorkloadPatch creates a strategic merge patch of the pod template of the workload
+func newWorkloadPatch(kind, apiVersion, name string, template map[string]interface{}) k8sschema.K8sResourceT {
+	return k8sschema.K8sResourceT{
+		"apiVersion": apiVersion,
+		"kind":       kind,
+		"metadata":   map[string]interface{}{"name": name},
+		"spec":       map[string]interface{}{"template": template},
+	}
+}
@@ -90,7 +90,8 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 		if err := os.MkdirAll(helmTemplatesDir, common.DefaultDirectoryPermission); err != nil {
 			logrus.Errorf("Unable to create directory for helm : %s", err)
 		} else {
-			for kPath, ks := range pathedKs {
+			helmDependencies := getHelmDependencies(pathedKs)
+			for kPath, ks := range replaceHelmDependencyResources(pathedKs, helmDependencies) {
 				for _, k := range ks {
 					k = deepcopy.DeepCopy(k).(k8sschema.K8sResourceT)
 					if err := parameterize(TargetHelm, packSpecConfig.Envs, k, ps, namedValues, nil, nil); err != nil {
@@ -105,6 +106,14 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 					filesWritten = append(filesWritten, finalKPath)
 				}
 			}
+			helmChartYaml := map[string]interface{}{
+				"apiVersion":  "v2",
+				"name":        helmChartName,
+				"version":     "0.1.0",
+				"description": "A Helm Chart generated by Move2Kube for " + helmChartName,
+				"keywords":    []string{helmChartName},
+			}
+			addHelmDependencies(helmChartYaml, namedValues, packSpecConfig.Envs, helmDependencies)
 			for env, values := range namedValues {
 				finalKPath := filepath.Join(helmChartDir, "values-"+env+".yaml")
 				if shouldGenerateDefaultEnv && env == parameterizerDefaultEnvironment {
@@ -116,13 +125,6 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 				}
 				filesWritten = append(filesWritten, finalKPath)
 			}
-			helmChartYaml := map[string]interface{}{
-				"apiVersion":  "v2",
-				"name":        helmChartName,
-				"version":     "0.1.0",
-				"description": "A Helm Chart generated by Move2Kube for " + helmChartName,
-				"keywords":    []string{helmChartName},
-			}
 			finalKPath := filepath.Join(helmChartDir, "Chart.yaml")
 			if err := common.WriteYaml(finalKPath, helmChartYaml); err != nil {
 				logrus.Errorf("Unable to write %s : %s", finalKPath, err)
@@ -140,7 +142,8 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 		} else {
 			kustPatches := map[string]map[PatchMetadataT][]PatchT{}
 			kPaths := []string{}
-			for kPath, ks := range pathedKs {
+			baseKs, components := getKustomizeComponents(pathedKs)
+			for kPath, ks := range baseKs {
 				for _, k := range ks {
 					// base
 					finalKPath := filepath.Join(baseDir, kPath)
@@ -187,8 +190,14 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 				}
 				filesWritten = append(filesWritten, finalKPath)
 			}
+			filesWritten = append(filesWritten, writeKustomizeComponents(kustDir, components)...)
+			envComponents := getEnvKustomizeComponents(packSpecConfig.Envs, components)
 			// create a overlay for each env
-			for env, kMetaPatches := range kustPatches {
+			for _, env := range packSpecConfig.Envs {
+				kMetaPatches := kustPatches[env]
+				if len(kMetaPatches) == 0 && len(envComponents[env]) == 0 {
+					continue
+				}
 				envDir := filepath.Join(kustDir, "overlays", env)
 				if err := os.MkdirAll(envDir, common.DefaultDirectoryPermission); err != nil {
 					logrus.Errorf("Unable to create overlay dir for env %s (%s) : %s", env, envDir, err)
@@ -205,6 +214,13 @@ func Parameterize(srcDir, outDir string, packSpecConfig ParameterizerConfigT, ps
 					filesWritten = append(filesWritten, finalKPath)
 				}
 				kustomization := map[string]interface{}{"resources": []string{"../../base"}, "patches": metas}
+				if len(envComponents[env]) > 0 {
+					componentPaths := []string{}
+					for _, componentName := range envComponents[env] {
+						componentPaths = append(componentPaths, "../../"+kustomizeComponentsDir+"/"+componentName)
+					}
+					kustomization["components"] = componentPaths
+				}
 				finalKPath := filepath.Join(envDir, "kustomization.yaml")
 				if err := common.WriteYaml(finalKPath, kustomization); err != nil {
 					logrus.Errorf("Unable to write file %s : %s", finalKPath, err)
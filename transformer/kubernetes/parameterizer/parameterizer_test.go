@@ -23,12 +23,14 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/qaengine"
 	"github.com/konveyor/move2kube/transformer/kubernetes/parameterizer"
 	"github.com/sirupsen/logrus"
 )
 
 func TestGettingAndParameterizingResources(t *testing.T) {
 	logrus.SetLevel(logrus.TraceLevel)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
 	relBaseDir := "testdata"
 	baseDir, err := filepath.Abs(relBaseDir)
 	if err != nil {
@@ -57,8 +59,8 @@ func TestGettingAndParameterizingResources(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Failed to apply all the parameterizations. Error: %q", err)
 	}
-	if len(filesWritten) != 26 {
-		t.Fatalf("Expected %d files to be written. Actual: %d filesWritten: %+v", 26, len(filesWritten), filesWritten)
+	if len(filesWritten) != 32 {
+		t.Fatalf("Expected %d files to be written. Actual: %d filesWritten: %+v", 32, len(filesWritten), filesWritten)
 	}
 	wantDataDir := filepath.Join(baseDir, "want")
 	for _, fileWritten := range filesWritten {
@@ -301,7 +301,7 @@ func writeResourceAppendToFile(k8sResource k8sschema.K8sResourceT, outputPath st
 		return err
 	}
 	if err := os.MkdirAll(filepath.Dir(outputPath), common.DefaultDirectoryPermission); err != nil {
-		logrus.Fatalf("Failed to create the output directory at path %s Error: %q", filepath.Dir(outputPath), err)
+		return fmt.Errorf("failed to create the output directory at path %s . Error: %w", filepath.Dir(outputPath), err)
 	}
 	// If the file doesn't exist, create it, or append to the file
 	f, err := os.OpenFile(outputPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, common.DefaultFilePermission)
@@ -326,7 +326,7 @@ func writeResourceStripQuotesAndAppendToFile(k8sResource k8sschema.K8sResourceT,
 	}
 	strippedYamlBytes := stripHelmQuotesRegex.ReplaceAll(yamlBytes, []byte("$1"))
 	if err := os.MkdirAll(filepath.Dir(outputPath), common.DefaultDirectoryPermission); err != nil {
-		logrus.Fatalf("Failed to create the output directory at path %s Error: %q", filepath.Dir(outputPath), err)
+		return fmt.Errorf("failed to create the output directory at path %s . Error: %w", filepath.Dir(outputPath), err)
 	}
 	// If the file doesn't exist, create it, or append to the file
 	f, err := os.OpenFile(outputPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, common.DefaultFilePermission)
@@ -0,0 +1,181 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/sirupsen/logrus"
+)
+
+// apiAccessDetector detects the use of a library which accesses the kubernetes API from the dependencies and the sources of a service
+type apiAccessDetector struct {
+	name    string
+	markers []string
+	rules   []irtypes.PolicyRule
+}
+
+var (
+	readVerbs  = []string{"get", "list", "watch"}
+	writeVerbs = []string{"get", "list", "watch", "create", "update", "patch"}
+	// apiAccessDetectors are checked in order and the rules of all the detected libraries are combined
+	apiAccessDetectors = []apiAccessDetector{{
+		name:    "service discovery",
+		markers: []string{"spring-cloud-starter-kubernetes", "spring-cloud-kubernetes", "kubernetes-client", "k8s.io/client-go", "@kubernetes/client-node", "KubernetesClient"},
+		rules: []irtypes.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"services", "endpoints", "pods"}, Verbs: readVerbs},
+			{APIGroups: []string{"discovery.k8s.io"}, Resources: []string{"endpointslices"}, Verbs: readVerbs},
+		},
+	}, {
+		name:    "config maps as configuration",
+		markers: []string{"kubernetes-config", "kubernetes-fabric8-config", "kubernetes-client-config"},
+		rules: []irtypes.PolicyRule{
+			{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: readVerbs},
+		},
+	}, {
+		name:    "leader election",
+		markers: []string{"leaderelection", "kubernetes-leader", "kubernetes-fabric8-leader"},
+		rules: []irtypes.PolicyRule{
+			{APIGroups: []string{"coordination.k8s.io"}, Resources: []string{"leases"}, Verbs: writeVerbs},
+			{APIGroups: []string{""}, Resources: []string{"configmaps"}, Verbs: writeVerbs},
+		},
+	}}
+	// apiAccessDependencyFiles are the files which list the dependencies of a service
+	apiAccessDependencyFiles = []string{"pom.xml", "build.gradle", "build.gradle.kts", "package.json", "go.mod"}
+	// apiAccessSourceExts are the extensions of the sources which are checked for the leader election imports
+	apiAccessSourceExts  = []string{".go", ".java", ".csproj"}
+	apiAccessSkippedDirs = []string{".git", "node_modules", "vendor", "target", "build", "bin", "obj"}
+)
+
+// newEnhancedIRWithServiceAccounts creates a service account for each service, with a role for the kubernetes API access detected in its sources
+func newEnhancedIRWithServiceAccounts(ir irtypes.IR) irtypes.EnhancedIR {
+	enhancedIR := irtypes.NewEnhancedIRFromIR(ir)
+	serviceNames := []string{}
+	for serviceName, service := range enhancedIR.Services {
+		if service.OnlyIngress || (service.ServiceAccountName != "" && service.ServiceAccountName != "default") {
+			continue
+		}
+		serviceNames = append(serviceNames, serviceName)
+	}
+	if len(serviceNames) == 0 || !qaengine.FetchBoolAnswer(
+		common.ConfigServiceAccountsKey,
+		"Create a service account for each service?",
+		[]string{"The pods of the services no longer use the default service account. Roles are created for the kubernetes API access detected in the dependencies of the services."},
+		true,
+		nil,
+	) {
+		return enhancedIR
+	}
+	sort.Strings(serviceNames)
+	for _, serviceName := range serviceNames {
+		service := enhancedIR.Services[serviceName]
+		service.ServiceAccountName = service.Name
+		enhancedIR.Services[serviceName] = service
+		enhancedIR.ServiceAccounts = append(enhancedIR.ServiceAccounts, irtypes.ServiceAccount{Name: service.Name})
+		rules := getAPIAccessRules(service, enhancedIR.ContainerImages)
+		if len(rules) == 0 {
+			continue
+		}
+		enhancedIR.Roles = append(enhancedIR.Roles, irtypes.Role{Name: service.Name, PolicyRules: rules})
+		enhancedIR.RoleBindings = append(enhancedIR.RoleBindings, irtypes.RoleBinding{Name: service.Name, RoleName: service.Name, ServiceAccountName: service.Name})
+	}
+	return enhancedIR
+}
+
+// getAPIAccessRules returns the rules of the libraries accessing the kubernetes API used by the containers of the service
+func getAPIAccessRules(service irtypes.Service, containerImages map[string]irtypes.ContainerImage) []irtypes.PolicyRule {
+	rules := []irtypes.PolicyRule{}
+	detected := map[string]bool{}
+	for _, container := range service.Containers {
+		contextPath := getBuildContextPath(container.Image, containerImages)
+		if contextPath == "" {
+			continue
+		}
+		for _, detector := range detectAPIAccess(contextPath) {
+			if detected[detector.name] {
+				continue
+			}
+			detected[detector.name] = true
+			logrus.Infof("Detected %s using the kubernetes API in the service %s. Creating a role for it.", detector.name, service.Name)
+			rules = append(rules, detector.rules...)
+		}
+	}
+	return rules
+}
+
+// getBuildContextPath returns the build context of the image, which might have been prefixed with the image registry by the preprocessors
+func getBuildContextPath(image string, containerImages map[string]irtypes.ContainerImage) string {
+	for imageName, containerImage := range containerImages {
+		if image == imageName || strings.HasSuffix(image, "/"+imageName) {
+			return containerImage.Build.ContextPath
+		}
+	}
+	return ""
+}
+
+// detectAPIAccess returns the detectors whose markers are present in the dependency files and sources of the directory
+func detectAPIAccess(dir string) []apiAccessDetector {
+	detected := map[string]bool{}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != dir && common.IsPresent(apiAccessSkippedDirs, d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !common.IsPresent(apiAccessDependencyFiles, d.Name()) && !common.IsPresent(apiAccessSourceExts, filepath.Ext(d.Name())) {
+			return nil
+		}
+		contents, err := os.ReadFile(path)
+		if err != nil {
+			logrus.Debugf("Unable to read the file at path %s . Error: %q", path, err)
+			return nil
+		}
+		for _, detector := range apiAccessDetectors {
+			if detected[detector.name] {
+				continue
+			}
+			for _, marker := range detector.markers {
+				if strings.Contains(string(contents), marker) {
+					detected[detector.name] = true
+					break
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Debugf("Unable to detect the kubernetes API access in the directory %s . Error: %q", dir, err)
+	}
+	detectors := []apiAccessDetector{}
+	for _, detector := range apiAccessDetectors {
+		if detected[detector.name] {
+			detectors = append(detectors, detector)
+		}
+	}
+	return detectors
+}
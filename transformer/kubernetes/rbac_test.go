@@ -0,0 +1,94 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestNewEnhancedIRWithServiceAccounts(t *testing.T) {
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+
+	apiDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(apiDir, "go.mod"), []byte("module api\n\nrequire k8s.io/client-go v0.23.1\n"), 0644); err != nil {
+		t.Fatalf("failed to write the go.mod file. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(apiDir, "main.go"), []byte("package main\n\nimport _ \"k8s.io/client-go/tools/leaderelection\"\n"), 0644); err != nil {
+		t.Fatalf("failed to write the main.go file. Error: %q", err)
+	}
+	webDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(webDir, "package.json"), []byte(`{"dependencies": {"express": "^4.18.0"}}`), 0644); err != nil {
+		t.Fatalf("failed to write the package.json file. Error: %q", err)
+	}
+	// the dependencies of the dependencies are not the dependencies of the service
+	if err := os.MkdirAll(filepath.Join(webDir, "node_modules", "dep"), 0755); err != nil {
+		t.Fatalf("failed to create the node_modules directory. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(webDir, "node_modules", "dep", "package.json"), []byte(`{"dependencies": {"@kubernetes/client-node": "^0.18.0"}}`), 0644); err != nil {
+		t.Fatalf("failed to write the package.json file of the dependency. Error: %q", err)
+	}
+
+	ir := irtypes.NewIR()
+	ir.ContainerImages["api:latest"] = irtypes.ContainerImage{Build: irtypes.ContainerBuild{ContextPath: apiDir}}
+	ir.ContainerImages["web:latest"] = irtypes.ContainerImage{Build: irtypes.ContainerBuild{ContextPath: webDir}}
+	api := irtypes.NewServiceWithName("api")
+	api.Containers = []core.Container{{Name: "api", Image: "api:latest"}}
+	ir.Services["api"] = api
+	web := irtypes.NewServiceWithName("web")
+	web.Containers = []core.Container{{Name: "web", Image: "web:latest"}}
+	ir.Services["web"] = web
+	worker := irtypes.NewServiceWithName("worker")
+	worker.ServiceAccountName = "existing"
+	ir.Services["worker"] = worker
+
+	actual := newEnhancedIRWithServiceAccounts(ir)
+
+	if len(actual.ServiceAccounts) != 2 || actual.ServiceAccounts[0].Name != "api" || actual.ServiceAccounts[1].Name != "web" {
+		t.Fatalf("expected service accounts for the services api and web. Actual: %+v", actual.ServiceAccounts)
+	}
+	for serviceName, serviceAccountName := range map[string]string{"api": "api", "web": "web", "worker": "existing"} {
+		if actual.Services[serviceName].ServiceAccountName != serviceAccountName {
+			t.Fatalf("expected the service %s to use the service account %s. Actual: %s", serviceName, serviceAccountName, actual.Services[serviceName].ServiceAccountName)
+		}
+	}
+	if len(actual.Roles) != 1 || actual.Roles[0].Name != "api" {
+		t.Fatalf("expected a role only for the service api which uses the kubernetes API. Actual: %+v", actual.Roles)
+	}
+	hasLeases := false
+	for _, rule := range actual.Roles[0].PolicyRules {
+		for _, resource := range rule.Resources {
+			if resource == "leases" {
+				hasLeases = true
+			}
+		}
+	}
+	if !hasLeases {
+		t.Fatalf("expected the role of the service api to allow leader election using leases. Actual: %+v", actual.Roles[0].PolicyRules)
+	}
+	if len(actual.RoleBindings) != 1 || actual.RoleBindings[0].ServiceAccountName != "api" || actual.RoleBindings[0].RoleName != "api" {
+		t.Fatalf("expected a role binding of the role api to the service account api. Actual: %+v", actual.RoleBindings)
+	}
+	if ir.Services["api"].ServiceAccountName != "" {
+		t.Fatalf("expected the original IR to not be modified. Actual: %+v", ir.Services["api"])
+	}
+}
@@ -0,0 +1,271 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/konveyor/move2kube/types"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	serviceReadmeTemplateFile = "README.md"
+	// serviceLabel is the label which the generated objects of a service are selected with
+	serviceLabel = types.GroupName + "/service"
+)
+
+// ServiceReadmeTemplateConfig stores the details of a service which are shown in its README
+type ServiceReadmeTemplateConfig struct {
+	ProjectName  string
+	ServiceName  string
+	Images       []ServiceReadmeImage
+	BuildsImages bool
+	Manifests    []ServiceReadmeManifest
+	Secrets      []ServiceReadmeSecret
+}
+
+// ServiceReadmeImage stores how an image of the service is built
+type ServiceReadmeImage struct {
+	Name string
+	// New is true for the images which are built from the sources
+	New        bool
+	BuildType  string
+	Dockerfile string
+	Context    string
+	BuildArgs  []string
+	Target     string
+}
+
+// ServiceReadmeManifest stores a manifest which deploys the service
+type ServiceReadmeManifest struct {
+	Path string
+	Kind string
+	Name string
+}
+
+// ServiceReadmeSecret stores a secret which the service uses
+type ServiceReadmeSecret struct {
+	Name string
+	Keys []string
+	// Manifest is the path of the generated secret, empty when the secret has to be created before deploying
+	Manifest string
+}
+
+// getServiceReadmePathMappings returns the path mappings of the READMEs of the services, assembled from the IR and the manifests written to the yamls path
+func (t *Kubernetes) getServiceReadmePathMappings(ir irtypes.IR, yamlsPath string) []transformertypes.PathMapping {
+	if t.KubernetesConfig.ReadmesPath == "" {
+		return nil
+	}
+	resources, err := k8sschema.GetK8sResourcesWithPaths(yamlsPath, false)
+	if err != nil {
+		logrus.Errorf("failed to read the manifests in %s to generate the READMEs of the services. Error: %q", yamlsPath, err)
+		return nil
+	}
+	manifestsPath := ""
+	if !strings.Contains(t.KubernetesConfig.OutputPath, "{{") {
+		manifestsPath = t.KubernetesConfig.OutputPath
+	}
+	manifests := []ServiceReadmeManifest{}
+	labels := map[string]map[string]interface{}{}
+	for relPath, fileResources := range resources {
+		for _, resource := range fileResources {
+			kind, _, name, err := k8sschema.GetInfoFromK8sResource(resource)
+			if err != nil {
+				logrus.Debugf("Ignoring the resource in the file %s for the READMEs of the services. Error: %q", relPath, err)
+				continue
+			}
+			manifest := ServiceReadmeManifest{Path: filepath.ToSlash(filepath.Join(manifestsPath, relPath)), Kind: kind, Name: name}
+			manifests = append(manifests, manifest)
+			if metadata, ok := resource["metadata"].(map[string]interface{}); ok {
+				if resourceLabels, ok := metadata["labels"].(map[string]interface{}); ok {
+					labels[manifest.Path] = resourceLabels
+				}
+			}
+		}
+	}
+	sort.Slice(manifests, func(i, j int) bool { return manifests[i].Path < manifests[j].Path })
+	secretManifests := map[string]string{}
+	for _, manifest := range manifests {
+		if manifest.Kind == "Secret" {
+			secretManifests[manifest.Name] = manifest.Path
+		}
+	}
+	serviceNames := []string{}
+	for serviceName, service := range ir.Services {
+		if !service.OnlyIngress {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	sort.Strings(serviceNames)
+	pathMappings := []transformertypes.PathMapping{}
+	for _, serviceName := range serviceNames {
+		service := ir.Services[serviceName]
+		secrets := getServiceSecrets(service, secretManifests)
+		config := ServiceReadmeTemplateConfig{
+			ProjectName: t.Env.ProjectName,
+			ServiceName: serviceName,
+			Images:      t.getServiceReadmeImages(ir, service),
+			Manifests:   []ServiceReadmeManifest{},
+			Secrets:     secrets,
+		}
+		for _, image := range config.Images {
+			config.BuildsImages = config.BuildsImages || image.New
+		}
+		usedNames := map[string]bool{serviceName: true}
+		for _, secret := range secrets {
+			usedNames[secret.Name] = true
+		}
+		for _, volume := range service.Volumes {
+			if volume.ConfigMap != nil {
+				usedNames[volume.ConfigMap.Name] = true
+			}
+			if volume.PersistentVolumeClaim != nil {
+				usedNames[volume.PersistentVolumeClaim.ClaimName] = true
+			}
+		}
+		for _, manifest := range manifests {
+			if usedNames[manifest.Name] || labels[manifest.Path][serviceLabel] == serviceName {
+				config.Manifests = append(config.Manifests, manifest)
+			}
+		}
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:           transformertypes.TemplatePathMappingType,
+			SrcPath:        filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir, serviceReadmeTemplateFile),
+			DestPath:       filepath.Join(t.KubernetesConfig.ReadmesPath, serviceName, serviceReadmeTemplateFile),
+			TemplateConfig: config,
+		})
+	}
+	return pathMappings
+}
+
+// getServiceReadmeImages returns the images of the containers of the service, along with how they are built
+func (t *Kubernetes) getServiceReadmeImages(ir irtypes.IR, service irtypes.Service) []ServiceReadmeImage {
+	images := []ServiceReadmeImage{}
+	seen := map[string]bool{}
+	for _, containers := range [][]core.Container{service.InitContainers, service.Containers} {
+		for _, container := range containers {
+			if container.Image == "" || seen[container.Image] {
+				continue
+			}
+			seen[container.Image] = true
+			image := ServiceReadmeImage{Name: container.Image}
+			if containerImage, ok := getContainerImage(ir, container.Image); ok {
+				build := containerImage.Build
+				image.New = true
+				image.BuildType = string(build.ContainerBuildType)
+				if build.ContextPath != "" {
+					image.Context = t.getOutputRelPath(build.ContextPath)
+				}
+				if dockerfiles := build.Artifacts[irtypes.DockerfileContainerBuildArtifactTypeValue]; len(dockerfiles) > 0 {
+					image.Dockerfile = t.getOutputRelPath(dockerfiles[0])
+					if image.Context == "" {
+						image.Context = filepath.ToSlash(filepath.Dir(image.Dockerfile))
+					}
+				}
+				for buildArg, value := range build.BuildArgs {
+					image.BuildArgs = append(image.BuildArgs, buildArg+"="+value)
+				}
+				sort.Strings(image.BuildArgs)
+				image.Target = build.Target
+			}
+			images = append(images, image)
+		}
+	}
+	return images
+}
+
+// getContainerImage returns the image which is built, including when the name of the registry was prepended to it
+func getContainerImage(ir irtypes.IR, image string) (irtypes.ContainerImage, bool) {
+	for imageName, containerImage := range ir.ContainerImages {
+		if image == imageName || strings.HasSuffix(image, "/"+imageName) {
+			return containerImage, true
+		}
+	}
+	return irtypes.ContainerImage{}, false
+}
+
+// getOutputRelPath returns the path the source or generated file is at, relative to the root of the output directory
+func (t *Kubernetes) getOutputRelPath(path string) string {
+	if common.IsParent(path, t.Env.GetEnvironmentSource()) {
+		if relPath, err := filepath.Rel(t.Env.GetEnvironmentSource(), path); err == nil {
+			return filepath.ToSlash(filepath.Join(common.DefaultSourceDir, relPath))
+		}
+	}
+	if common.IsParent(path, t.Env.GetEnvironmentOutput()) {
+		if relPath, err := filepath.Rel(t.Env.GetEnvironmentOutput(), path); err == nil {
+			return filepath.ToSlash(relPath)
+		}
+	}
+	return filepath.ToSlash(path)
+}
+
+// getServiceSecrets returns the secrets referenced by the pods of the service, along with the keys they use
+func getServiceSecrets(service irtypes.Service, secretManifests map[string]string) []ServiceReadmeSecret {
+	secretKeys := map[string]map[string]bool{}
+	addSecret := func(name, key string) {
+		if name == "" {
+			return
+		}
+		if secretKeys[name] == nil {
+			secretKeys[name] = map[string]bool{}
+		}
+		if key != "" {
+			secretKeys[name][key] = true
+		}
+	}
+	for _, containers := range [][]core.Container{service.InitContainers, service.Containers} {
+		for _, container := range containers {
+			for _, env := range container.Env {
+				if env.ValueFrom != nil && env.ValueFrom.SecretKeyRef != nil {
+					addSecret(env.ValueFrom.SecretKeyRef.Name, env.ValueFrom.SecretKeyRef.Key)
+				}
+			}
+			for _, envFrom := range container.EnvFrom {
+				if envFrom.SecretRef != nil {
+					addSecret(envFrom.SecretRef.Name, "")
+				}
+			}
+		}
+	}
+	for _, volume := range service.Volumes {
+		if volume.Secret != nil {
+			addSecret(volume.Secret.SecretName, "")
+		}
+	}
+	for _, pullSecret := range service.ImagePullSecrets {
+		addSecret(pullSecret.Name, "")
+	}
+	secrets := []ServiceReadmeSecret{}
+	for name, keys := range secretKeys {
+		secret := ServiceReadmeSecret{Name: name, Keys: []string{}, Manifest: secretManifests[name]}
+		for key := range keys {
+			secret.Keys = append(secret.Keys, key)
+		}
+		sort.Strings(secret.Keys)
+		secrets = append(secrets, secret)
+	}
+	sort.Slice(secrets, func(i, j int) bool { return secrets[i].Name < secrets[j].Name })
+	return secrets
+}
@@ -0,0 +1,152 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestGetServiceSecrets(t *testing.T) {
+	service := irtypes.NewServiceWithName("api")
+	service.InitContainers = []core.Container{{EnvFrom: []core.EnvFromSource{{SecretRef: &core.SecretEnvSource{LocalObjectReference: core.LocalObjectReference{Name: "api-env"}}}}}}
+	service.Containers = []core.Container{{Env: []core.EnvVar{
+		{Name: "DB_PASSWORD", ValueFrom: &core.EnvVarSource{SecretKeyRef: &core.SecretKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "api-db"}, Key: "password"}}},
+		{Name: "DB_USER", ValueFrom: &core.EnvVarSource{SecretKeyRef: &core.SecretKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "api-db"}, Key: "user"}}},
+		{Name: "PORT", Value: "8080"},
+	}}}
+	service.Volumes = []core.Volume{{Name: "tls", VolumeSource: core.VolumeSource{Secret: &core.SecretVolumeSource{SecretName: "api-tls"}}}}
+	service.ImagePullSecrets = []core.LocalObjectReference{{Name: "regcred"}}
+	want := []ServiceReadmeSecret{
+		{Name: "api-db", Keys: []string{"password", "user"}, Manifest: "deploy/yamls/api-db-secret.yaml"},
+		{Name: "api-env", Keys: []string{}},
+		{Name: "api-tls", Keys: []string{}},
+		{Name: "regcred", Keys: []string{}},
+	}
+	got := getServiceSecrets(service, map[string]string{"api-db": "deploy/yamls/api-db-secret.yaml"})
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("unexpected secrets. Diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestGetServiceReadmePathMappings(t *testing.T) {
+	sourcePath, yamlsPath := t.TempDir(), t.TempDir()
+	writeFile := func(path, contents string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory of the file %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	writeFile(filepath.Join(sourcePath, "api", "Dockerfile"), "FROM golang:1.18\n")
+	writeFile(filepath.Join(yamlsPath, "api-deployment.yaml"), "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: api\n")
+	writeFile(filepath.Join(yamlsPath, "api-db-secret.yaml"), "apiVersion: v1\nkind: Secret\nmetadata:\n  name: api-db\n")
+	writeFile(filepath.Join(yamlsPath, "settings-configmap.yaml"), "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: settings\n  labels:\n    "+serviceLabel+": api\n")
+	writeFile(filepath.Join(yamlsPath, "web-deployment.yaml"), "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n")
+
+	envInfo := environment.EnvInfo{ProjectName: "myproject", Source: sourcePath, Context: filepath.Join("..", "..", "assets", "built-in", "transformers", "kubernetes", "kubernetes")}
+	local, err := environment.NewLocal(envInfo, nil)
+	if err != nil {
+		t.Fatalf("failed to create the local environment. Error: %q", err)
+	}
+	k := &Kubernetes{
+		Env:              &environment.Environment{EnvInfo: envInfo, Env: local},
+		KubernetesConfig: &KubernetesYamlConfig{OutputPath: filepath.Join(common.DeployDir, "yamls"), ReadmesPath: "readmes"},
+	}
+	k.Config.Spec.TemplatesDir = "templates"
+
+	ir := irtypes.NewIR()
+	ir.Name = "myproject"
+	apiImage := irtypes.NewContainer()
+	apiImage.Build = irtypes.ContainerBuild{
+		ContainerBuildType: irtypes.DockerfileContainerBuildType,
+		ContextPath:        filepath.Join(sourcePath, "api"),
+		Artifacts:          map[irtypes.ContainerBuildArtifactTypeValue][]string{irtypes.DockerfileContainerBuildArtifactTypeValue: {filepath.Join(sourcePath, "api", "Dockerfile")}},
+		BuildArgs:          map[string]string{"VERSION": "1.2"},
+	}
+	ir.ContainerImages["api:latest"] = apiImage
+	api := irtypes.NewServiceWithName("api")
+	api.InitContainers = []core.Container{{Name: "wait", Image: "busybox"}}
+	api.Containers = []core.Container{{Name: "api", Image: "quay.io/myteam/api:latest", Env: []core.EnvVar{
+		{Name: "DB_PASSWORD", ValueFrom: &core.EnvVarSource{SecretKeyRef: &core.SecretKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "api-db"}, Key: "password"}}},
+	}}}
+	api.ImagePullSecrets = []core.LocalObjectReference{{Name: "regcred"}}
+	ir.Services["api"] = api
+	ir.Services["gateway"] = irtypes.Service{Name: "gateway", OnlyIngress: true}
+
+	pathMappings := k.getServiceReadmePathMappings(ir, yamlsPath)
+	if len(pathMappings) != 1 {
+		t.Fatalf("expected a README only for the service which is not only an ingress. Actual: %+v", pathMappings)
+	}
+	if want := filepath.Join("readmes", "api", serviceReadmeTemplateFile); pathMappings[0].DestPath != want {
+		t.Fatalf("got the README path %q , want %q", pathMappings[0].DestPath, want)
+	}
+	config, ok := pathMappings[0].TemplateConfig.(ServiceReadmeTemplateConfig)
+	if !ok {
+		t.Fatalf("expected the template config of the README. Actual: %T", pathMappings[0].TemplateConfig)
+	}
+	want := ServiceReadmeTemplateConfig{
+		ProjectName: "myproject",
+		ServiceName: "api",
+		Images: []ServiceReadmeImage{
+			{Name: "busybox"},
+			{Name: "quay.io/myteam/api:latest", New: true, BuildType: string(irtypes.DockerfileContainerBuildType), Dockerfile: common.DefaultSourceDir + "/api/Dockerfile", Context: common.DefaultSourceDir + "/api", BuildArgs: []string{"VERSION=1.2"}},
+		},
+		BuildsImages: true,
+		Manifests: []ServiceReadmeManifest{
+			{Path: "deploy/yamls/api-db-secret.yaml", Kind: "Secret", Name: "api-db"},
+			{Path: "deploy/yamls/api-deployment.yaml", Kind: "Deployment", Name: "api"},
+			{Path: "deploy/yamls/settings-configmap.yaml", Kind: "ConfigMap", Name: "settings"},
+		},
+		Secrets: []ServiceReadmeSecret{
+			{Name: "api-db", Keys: []string{"password"}, Manifest: "deploy/yamls/api-db-secret.yaml"},
+			{Name: "regcred", Keys: []string{}},
+		},
+	}
+	if diff := cmp.Diff(want, config); diff != "" {
+		t.Fatalf("unexpected README config. Diff (-want +got):\n%s", diff)
+	}
+
+	template, err := os.ReadFile(pathMappings[0].SrcPath)
+	if err != nil {
+		t.Fatalf("failed to read the README template. Error: %q", err)
+	}
+	readme, err := common.GetStringFromTemplate(string(template), config)
+	if err != nil {
+		t.Fatalf("failed to render the README. Error: %q", err)
+	}
+	for _, wantLine := range []string{
+		"docker build -f " + common.DefaultSourceDir + "/api/Dockerfile --build-arg 'VERSION=1.2' -t quay.io/myteam/api:latest " + common.DefaultSourceDir + "/api",
+		"- `busybox` is pulled from its registry",
+		"kubectl apply -f deploy/yamls/api-deployment.yaml",
+		"- `regcred`: not generated. Create it in the namespace of the service before deploying.",
+	} {
+		if !strings.Contains(readme, wantLine) {
+			t.Fatalf("expected the README to contain the line %q . Actual:\n%s", wantLine, readme)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/konveyor/move2kube/types"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	verifyScriptTemplateFile = "verify.sh"
+	smokeTestImage           = "curlimages/curl:8.5.0"
+	// smokeTestImageUID is the uid of the curl_user of the image, which has to be numeric for the kubelet to verify that it is not root
+	smokeTestImageUID = 100
+	smokeTestSuffix   = "-smoke-test"
+	// smokeTestLabel is the label of the smoke test Jobs, so that they can be listed and deleted together
+	smokeTestLabel = types.GroupName + "/smoketest"
+	// smokeTestRetries and smokeTestRetryDelaySeconds give the services behind the ingress controllers and the load balancers the time to be reachable
+	smokeTestRetries           = 12
+	smokeTestRetryDelaySeconds = 5
+	// healthyStatusLimit is the status code the health endpoints have to respond below, while any response
+	// from the other paths shows that the service came up, since they might need authentication or not exist
+	healthyStatusLimit   = 400
+	respondedStatusLimit = 500
+)
+
+// rolloutKinds are the kinds of the workloads whose rollouts are waited for by kubectl rollout status
+var rolloutKinds = []string{common.DeploymentKind, common.StatefulSetKind, common.DaemonSetKind}
+
+// VerifyScriptTemplateConfig stores the data used to fill the script which waits for the rollouts and runs the smoke tests
+type VerifyScriptTemplateConfig struct {
+	// Workloads are the kind and name of the workloads, like deployment/web
+	Workloads []string
+	Jobs      []VerifyScriptJob
+}
+
+// VerifyScriptJob is a smoke test Job run by the verify script
+type VerifyScriptJob struct {
+	Name string
+	File string
+}
+
+// smokeTestCheck is a url checked by a smoke test, which has to respond with a status code below the limit
+type smokeTestCheck struct {
+	url         string
+	statusLimit int
+}
+
+// getSmokeTestPathMappings returns the path mappings of the smoke test Jobs of the services exposed by the manifests written to the yamls path,
+// along with the script which waits for the rollouts and runs them
+func (t *Kubernetes) getSmokeTestPathMappings(ir irtypes.IR, yamlsPath string) []transformertypes.PathMapping {
+	if t.KubernetesConfig.SmokeTestsPath == "" {
+		return nil
+	}
+	resources, err := k8sschema.GetK8sResourcesWithPaths(yamlsPath, false)
+	if err != nil {
+		logrus.Errorf("failed to read the manifests in %s to generate the smoke tests. Error: %q", yamlsPath, err)
+		return nil
+	}
+	workloads := []string{}
+	externalChecks := map[string][]smokeTestCheck{}
+	exposedServices := map[string]bool{}
+	for _, fileResources := range resources {
+		for _, resource := range fileResources {
+			kind, _, name, err := k8sschema.GetInfoFromK8sResource(resource)
+			if err != nil {
+				continue
+			}
+			switch {
+			case common.IsPresent(rolloutKinds, kind):
+				workloads = append(workloads, strings.ToLower(kind)+"/"+name)
+			case kind == common.ServiceKind:
+				spec, _ := resource["spec"].(map[string]interface{})
+				if serviceType, _ := spec["type"].(string); serviceType == string(core.ServiceTypeLoadBalancer) || serviceType == string(core.ServiceTypeNodePort) {
+					exposedServices[name] = true
+				}
+			case kind == common.IngressKind:
+				for serviceName, checks := range getIngressSmokeTestChecks(resource) {
+					exposedServices[serviceName] = true
+					externalChecks[serviceName] = append(externalChecks[serviceName], checks...)
+				}
+			case kind == "Route":
+				if serviceName, check, ok := getRouteSmokeTestCheck(resource); ok {
+					exposedServices[serviceName] = true
+					externalChecks[serviceName] = append(externalChecks[serviceName], check)
+				}
+			}
+		}
+	}
+	serviceNames := []string{}
+	for serviceName := range exposedServices {
+		if service, ok := ir.Services[serviceName]; ok && !service.OnlyIngress && len(service.ServiceToPodPortForwardings) > 0 {
+			serviceNames = append(serviceNames, serviceName)
+		}
+	}
+	if len(serviceNames) == 0 {
+		return nil
+	}
+	if !qaengine.FetchBoolAnswer(
+		common.ConfigSmokeTestsKey,
+		"Generate the smoke test Jobs which check that the exposed services respond after the deployment?",
+		[]string{
+			"A Job is generated for each service exposed by an Ingress, a Route or a load balancer, which requests its health endpoint and its external url",
+			"The " + verifyScriptTemplateFile + " script waits for the rollouts and runs the Jobs",
+		},
+		false,
+		nil,
+	) {
+		return nil
+	}
+	sort.Strings(serviceNames)
+	sort.Strings(workloads)
+	tempDest := filepath.Join(t.Env.TempPath, "smoke-tests-"+common.GetRandomString())
+	if err := os.MkdirAll(tempDest, common.DefaultDirectoryPermission); err != nil {
+		logrus.Errorf("failed to create the directory for the smoke tests at path %s . Error: %q", tempDest, err)
+		return nil
+	}
+	config := VerifyScriptTemplateConfig{Workloads: workloads, Jobs: []VerifyScriptJob{}}
+	for _, serviceName := range serviceNames {
+		checks := append([]smokeTestCheck{getServiceSmokeTestCheck(ir.Services[serviceName])}, externalChecks[serviceName]...)
+		job := getSmokeTestJob(serviceName, checks)
+		fileName := job.Name + "-job.yaml"
+		if err := writeSmokeTestJob(filepath.Join(tempDest, fileName), job); err != nil {
+			logrus.Errorf("failed to write the smoke test Job of the service %s . Error: %q", serviceName, err)
+			continue
+		}
+		config.Jobs = append(config.Jobs, VerifyScriptJob{Name: job.Name, File: fileName})
+	}
+	if len(config.Jobs) == 0 {
+		return nil
+	}
+	return []transformertypes.PathMapping{{
+		Type:     transformertypes.DefaultPathMappingType,
+		SrcPath:  tempDest,
+		DestPath: t.KubernetesConfig.SmokeTestsPath,
+	}, {
+		Type:           transformertypes.TemplatePathMappingType,
+		SrcPath:        filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir, verifyScriptTemplateFile),
+		DestPath:       filepath.Join(t.KubernetesConfig.SmokeTestsPath, verifyScriptTemplateFile),
+		TemplateConfig: config,
+	}}
+}
+
+// getServiceSmokeTestCheck returns the url of the health endpoint of the service, found in the http readiness or liveness probes,
+// or the root path of the first port when the service has no health endpoint
+func getServiceSmokeTestCheck(service irtypes.Service) smokeTestCheck {
+	forwarding := service.ServiceToPodPortForwardings[0]
+	check := smokeTestCheck{url: fmt.Sprintf("http://%s:%d/", service.Name, forwarding.ServicePort.Number), statusLimit: respondedStatusLimit}
+	for _, container := range service.Containers {
+		for _, probe := range []*core.Probe{container.ReadinessProbe, container.LivenessProbe} {
+			if probe == nil || probe.HTTPGet == nil || probe.HTTPGet.Scheme == core.URISchemeHTTPS {
+				continue
+			}
+			for _, forwarding := range service.ServiceToPodPortForwardings {
+				if !isProbePort(probe.HTTPGet.Port, container, forwarding.PodPort.Number) {
+					continue
+				}
+				path := probe.HTTPGet.Path
+				if !strings.HasPrefix(path, "/") {
+					path = "/" + path
+				}
+				return smokeTestCheck{url: fmt.Sprintf("http://%s:%d%s", service.Name, forwarding.ServicePort.Number, path), statusLimit: healthyStatusLimit}
+			}
+		}
+	}
+	return check
+}
+
+// isProbePort checks whether the port of the probe, given as a number or as the name of a container port, is the pod port
+func isProbePort(port intstr.IntOrString, container core.Container, podPort int32) bool {
+	if port.Type == intstr.Int {
+		return port.IntVal == podPort
+	}
+	for _, containerPort := range container.Ports {
+		if containerPort.Name == port.StrVal {
+			return containerPort.ContainerPort == podPort
+		}
+	}
+	return false
+}
+
+// getIngressSmokeTestChecks returns the urls of the paths of the ingress, grouped by the backend service. The rules without a host are skipped.
+func getIngressSmokeTestChecks(ingress k8sschema.K8sResourceT) map[string][]smokeTestCheck {
+	checks := map[string][]smokeTestCheck{}
+	spec, _ := ingress["spec"].(map[string]interface{})
+	tlsHosts := map[string]bool{}
+	tlsList, _ := spec["tls"].([]interface{})
+	for _, tlsI := range tlsList {
+		tls, _ := tlsI.(map[string]interface{})
+		hosts, _ := tls["hosts"].([]interface{})
+		for _, host := range hosts {
+			if host, ok := host.(string); ok {
+				tlsHosts[host] = true
+			}
+		}
+	}
+	rules, _ := spec["rules"].([]interface{})
+	for _, ruleI := range rules {
+		rule, _ := ruleI.(map[string]interface{})
+		host, _ := rule["host"].(string)
+		if host == "" || strings.Contains(host, "*") {
+			continue
+		}
+		scheme := "http"
+		if tlsHosts[host] {
+			scheme = "https"
+		}
+		http, _ := rule["http"].(map[string]interface{})
+		paths, _ := http["paths"].([]interface{})
+		for _, pathI := range paths {
+			path, _ := pathI.(map[string]interface{})
+			backend, _ := path["backend"].(map[string]interface{})
+			serviceName := ""
+			if service, ok := backend["service"].(map[string]interface{}); ok {
+				serviceName, _ = service["name"].(string)
+			} else {
+				// the extensions/v1beta1 and networking.k8s.io/v1beta1 ingresses
+				serviceName, _ = backend["serviceName"].(string)
+			}
+			if serviceName == "" {
+				continue
+			}
+			urlPath, _ := path["path"].(string)
+			if !strings.HasPrefix(urlPath, "/") {
+				urlPath = "/" + urlPath
+			}
+			checks[serviceName] = append(checks[serviceName], smokeTestCheck{url: scheme + "://" + host + urlPath, statusLimit: respondedStatusLimit})
+		}
+	}
+	return checks
+}
+
+// getRouteSmokeTestCheck returns the url of the route along with its service
+func getRouteSmokeTestCheck(route k8sschema.K8sResourceT) (string, smokeTestCheck, bool) {
+	spec, _ := route["spec"].(map[string]interface{})
+	host, _ := spec["host"].(string)
+	to, _ := spec["to"].(map[string]interface{})
+	serviceName, _ := to["name"].(string)
+	if host == "" || serviceName == "" {
+		return "", smokeTestCheck{}, false
+	}
+	scheme := "http"
+	if _, ok := spec["tls"].(map[string]interface{}); ok {
+		scheme = "https"
+	}
+	urlPath, _ := spec["path"].(string)
+	if !strings.HasPrefix(urlPath, "/") {
+		urlPath = "/" + urlPath
+	}
+	return serviceName, smokeTestCheck{url: scheme + "://" + host + urlPath, statusLimit: respondedStatusLimit}, true
+}
+
+// getSmokeTestJob returns a Job which requests the urls, failing when any of them does not respond with a status code below its limit
+// The Job is created in the batch/v1 version, which is supported by all the clusters.
+func getSmokeTestJob(serviceName string, checks []smokeTestCheck) *batchv1.Job {
+	lines := []string{
+		"set -e",
+		"check() {",
+		fmt.Sprintf(`  code=$(curl -sS -k -o /dev/null -w '%%{http_code}' --max-time 10 --retry %d --retry-delay %d --retry-all-errors "$1") || code=000`, smokeTestRetries, smokeTestRetryDelaySeconds),
+		`  echo "$1 responded with the status code ${code}"`,
+		`  [ "${code}" -ge 200 ] && [ "${code}" -lt "$2" ]`,
+		"}",
+	}
+	for _, check := range checks {
+		lines = append(lines, fmt.Sprintf("check '%s' %d", check.url, check.statusLimit))
+	}
+	name := common.MakeStringK8sServiceNameCompliant(serviceName + smokeTestSuffix)
+	labels := map[string]string{serviceLabel: serviceName, smokeTestLabel: "true"}
+	var backoffLimit int32 = 0
+	var activeDeadlineSeconds int64 = 600
+	runAsNonRoot := true
+	var runAsUser int64 = smokeTestImageUID
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       common.JobKind,
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Spec: batchv1.JobSpec{
+			// the verify script runs the Job again after deleting it, instead of the Job retrying the failed checks
+			BackoffLimit:          &backoffLimit,
+			ActiveDeadlineSeconds: &activeDeadlineSeconds,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{{
+						Name:    "smoke-test",
+						Image:   smokeTestImage,
+						Command: []string{"sh", "-c", strings.Join(lines, "\n")},
+						SecurityContext: &corev1.SecurityContext{
+							RunAsNonRoot: &runAsNonRoot,
+							RunAsUser:    &runAsUser,
+						},
+						Resources: corev1.ResourceRequirements{
+							Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("10m"), corev1.ResourceMemory: resource.MustParse("16Mi")},
+							Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("64Mi")},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// writeSmokeTestJob writes the Job to the yaml file
+func writeSmokeTestJob(yamlPath string, obj runtime.Object) error {
+	f, err := os.OpenFile(yamlPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, common.DefaultFilePermission)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	w := bufio.NewWriter(f)
+	if err := common.WriteObjToYaml(w, obj); err != nil {
+		return err
+	}
+	return w.Flush()
+}
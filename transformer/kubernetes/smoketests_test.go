@@ -0,0 +1,210 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	"k8s.io/kubernetes/pkg/apis/networking"
+	"sigs.k8s.io/yaml"
+)
+
+func TestGetIngressSmokeTestChecks(t *testing.T) {
+	testCases := []struct {
+		name    string
+		ingress string
+		want    map[string][]smokeTestCheck
+	}{
+		{
+			name: "networking.k8s.io/v1 with tls",
+			ingress: `
+spec:
+  tls:
+  - hosts: [secure.example.com]
+  rules:
+  - host: secure.example.com
+    http:
+      paths:
+      - path: /api
+        backend: {service: {name: api, port: {number: 8080}}}
+  - host: plain.example.com
+    http:
+      paths:
+      - path: ""
+        backend: {service: {name: web, port: {number: 80}}}
+`,
+			want: map[string][]smokeTestCheck{
+				"api": {{url: "https://secure.example.com/api", statusLimit: respondedStatusLimit}},
+				"web": {{url: "http://plain.example.com/", statusLimit: respondedStatusLimit}},
+			},
+		},
+		{
+			name: "v1beta1 backends",
+			ingress: `
+spec:
+  rules:
+  - host: old.example.com
+    http:
+      paths:
+      - path: /
+        backend: {serviceName: legacy, servicePort: 80}
+`,
+			want: map[string][]smokeTestCheck{"legacy": {{url: "http://old.example.com/", statusLimit: respondedStatusLimit}}},
+		},
+		{
+			name: "rules without a host and wildcard hosts are skipped",
+			ingress: `
+spec:
+  rules:
+  - http:
+      paths:
+      - path: /
+        backend: {service: {name: nohost}}
+  - host: "*.example.com"
+    http:
+      paths:
+      - path: /
+        backend: {service: {name: wildcard}}
+  - host: resource.example.com
+    http:
+      paths:
+      - path: /
+        backend: {resource: {kind: StorageBucket, name: assets}}
+`,
+			want: map[string][]smokeTestCheck{},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ingress := k8sschema.K8sResourceT{}
+			if err := yaml.Unmarshal([]byte(testCase.ingress), &ingress); err != nil {
+				t.Fatalf("failed to unmarshal the ingress. Error: %q", err)
+			}
+			got := getIngressSmokeTestChecks(ingress)
+			if !cmp.Equal(got, testCase.want, cmp.AllowUnexported(smokeTestCheck{})) {
+				t.Fatalf("the checks are different. Difference:\n%s", cmp.Diff(testCase.want, got, cmp.AllowUnexported(smokeTestCheck{})))
+			}
+		})
+	}
+}
+
+func TestGetRouteSmokeTestCheck(t *testing.T) {
+	testCases := []struct {
+		name        string
+		route       string
+		wantService string
+		wantCheck   smokeTestCheck
+		wantOk      bool
+	}{
+		{
+			name:        "edge terminated route with a path",
+			route:       `{"spec": {"host": "web.apps.example.com", "path": "/app", "to": {"kind": "Service", "name": "web"}, "tls": {"termination": "edge"}}}`,
+			wantService: "web",
+			wantCheck:   smokeTestCheck{url: "https://web.apps.example.com/app", statusLimit: respondedStatusLimit},
+			wantOk:      true,
+		},
+		{
+			name:        "plain route",
+			route:       `{"spec": {"host": "web.apps.example.com", "to": {"kind": "Service", "name": "web"}}}`,
+			wantService: "web",
+			wantCheck:   smokeTestCheck{url: "http://web.apps.example.com/", statusLimit: respondedStatusLimit},
+			wantOk:      true,
+		},
+		{
+			name:  "route without a host",
+			route: `{"spec": {"to": {"kind": "Service", "name": "web"}}}`,
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			route := k8sschema.K8sResourceT{}
+			if err := yaml.Unmarshal([]byte(testCase.route), &route); err != nil {
+				t.Fatalf("failed to unmarshal the route. Error: %q", err)
+			}
+			service, check, ok := getRouteSmokeTestCheck(route)
+			if ok != testCase.wantOk || service != testCase.wantService || check != testCase.wantCheck {
+				t.Fatalf("got (%s, %+v, %t), want (%s, %+v, %t)", service, check, ok, testCase.wantService, testCase.wantCheck, testCase.wantOk)
+			}
+		})
+	}
+}
+
+func TestGetServiceSmokeTestCheck(t *testing.T) {
+	newService := func(containerPorts []core.ContainerPort, readinessProbe, livenessProbe *core.Probe) irtypes.Service {
+		service := irtypes.NewServiceWithName("api")
+		service.AddPortForwarding(networking.ServiceBackendPort{Number: 80}, networking.ServiceBackendPort{Number: 8080}, "")
+		service.Containers = []core.Container{{Name: "api", Ports: containerPorts, ReadinessProbe: readinessProbe, LivenessProbe: livenessProbe}}
+		return service
+	}
+	httpProbe := func(path string, port intstr.IntOrString, scheme core.URIScheme) *core.Probe {
+		return &core.Probe{ProbeHandler: core.ProbeHandler{HTTPGet: &core.HTTPGetAction{Path: path, Port: port, Scheme: scheme}}}
+	}
+	testCases := []struct {
+		name    string
+		service irtypes.Service
+		want    smokeTestCheck
+	}{
+		{
+			name:    "no probes",
+			service: newService(nil, nil, nil),
+			want:    smokeTestCheck{url: "http://api:80/", statusLimit: respondedStatusLimit},
+		},
+		{
+			name:    "readiness probe on the pod port",
+			service: newService(nil, httpProbe("/healthz", intstr.FromInt(8080), core.URISchemeHTTP), nil),
+			want:    smokeTestCheck{url: "http://api:80/healthz", statusLimit: healthyStatusLimit},
+		},
+		{
+			name:    "liveness probe on a named port without a leading slash",
+			service: newService([]core.ContainerPort{{Name: "http", ContainerPort: 8080}}, nil, httpProbe("live", intstr.FromString("http"), "")),
+			want:    smokeTestCheck{url: "http://api:80/live", statusLimit: healthyStatusLimit},
+		},
+		{
+			name:    "probe on a port which is not exposed",
+			service: newService(nil, httpProbe("/metrics-health", intstr.FromInt(9090), core.URISchemeHTTP), nil),
+			want:    smokeTestCheck{url: "http://api:80/", statusLimit: respondedStatusLimit},
+		},
+		{
+			name:    "https probes are skipped",
+			service: newService(nil, httpProbe("/healthz", intstr.FromInt(8080), core.URISchemeHTTPS), nil),
+			want:    smokeTestCheck{url: "http://api:80/", statusLimit: respondedStatusLimit},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := getServiceSmokeTestCheck(testCase.service); got != testCase.want {
+				t.Fatalf("got the check %+v , want %+v", got, testCase.want)
+			}
+		})
+	}
+}
+
+func TestGetSmokeTestJobRunsAsNumericUser(t *testing.T) {
+	job := getSmokeTestJob("api", []smokeTestCheck{{url: "http://api:80/", statusLimit: respondedStatusLimit}})
+	securityContext := job.Spec.Template.Spec.Containers[0].SecurityContext
+	if securityContext == nil || securityContext.RunAsUser == nil || *securityContext.RunAsUser != smokeTestImageUID {
+		t.Fatalf("expected the smoke test to run as the numeric user %d of the image, got %+v", smokeTestImageUID, securityContext)
+	}
+	if securityContext.RunAsNonRoot == nil || !*securityContext.RunAsNonRoot {
+		t.Fatalf("expected the smoke test to run as non root, got %+v", securityContext)
+	}
+}
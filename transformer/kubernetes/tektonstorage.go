@@ -0,0 +1,162 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+const (
+	baseBuildCacheName           = "build-cache"
+	baseArtifactBucketName       = "artifact-bucket"
+	baseCosignSecretName         = "cosign"
+	defaultBuildCacheSize        = "5Gi"
+	artifactBucketKeyPlaceholder = "<TODO: insert the credentials of your artifact bucket>"
+	gcsServiceAccountKeyFilename = "service_account.json"
+	awsCredentialsFilename       = "credentials"
+	awsConfigFilename            = "config"
+	cosignKeyPlaceholder         = "<TODO: insert the private key generated by cosign generate-key-pair>"
+	cosignPasswordPlaceholder    = "<TODO: insert the password of the cosign key>"
+	awsConfigPlaceholder         = "[default]\nregion = <TODO: insert the region of your artifact bucket>\n"
+)
+
+// setupPipelineCache configures the cache of the image builds of the pipeline and the trigger template which runs it
+func setupPipelineCache(pipeline *irtypes.Pipeline, triggerTemplate *irtypes.TriggerTemplate, cacheName string) []irtypes.Storage {
+	cacheType := irtypes.PipelineCacheType(qaengine.FetchSelectAnswer(
+		common.ConfigTektonCacheTypeKey,
+		"Select where the layers of the images built by the Tekton pipeline should be cached :",
+		[]string{
+			"none : every run builds the images from scratch",
+			"pvc : the cloned repos and the layers are kept in a persistent volume claim shared by the runs",
+			"registry : the layers are pushed to a repository of the image registry",
+		},
+		string(irtypes.NoPipelineCache),
+		[]string{string(irtypes.NoPipelineCache), string(irtypes.PVCPipelineCache), string(irtypes.RegistryPipelineCache)},
+		nil,
+	))
+	pipeline.CacheType = cacheType
+	switch cacheType {
+	case irtypes.RegistryPipelineCache:
+		defaultCacheRepo := commonqa.ImageRegistry() + "/" + commonqa.ImageRegistryNamespace() + "/" + cacheName
+		pipeline.CacheRepo = qaengine.FetchStringAnswer(
+			common.ConfigTektonCacheRepoKey,
+			"Enter the repository the layers of the images should be cached in :",
+			[]string{"The service account of the pipeline should be able to push to this repository"},
+			defaultCacheRepo,
+			nil,
+		)
+	case irtypes.PVCPipelineCache:
+		size := qaengine.FetchStringAnswer(
+			common.ConfigTektonCacheSizeKey,
+			"Enter the size of the persistent volume claim the builds should be cached in :",
+			[]string{"Ex : 10Gi"},
+			defaultBuildCacheSize,
+			func(answer interface{}) error {
+				if _, err := resource.ParseQuantity(fmt.Sprintf("%v", answer)); err != nil {
+					return fmt.Errorf("the size is not a valid quantity. Error: %w", err)
+				}
+				return nil
+			},
+		)
+		quantity, err := resource.ParseQuantity(size)
+		if err != nil {
+			logrus.Warnf("failed to parse the size %s of the build cache. Using the default %s instead. Error: %q", size, defaultBuildCacheSize, err)
+			quantity = resource.MustParse(defaultBuildCacheSize)
+		}
+		// The runs share the claim, so the same directory is reused by each run and the layers are kept in it.
+		triggerTemplate.CacheClaimName = cacheName
+		return []irtypes.Storage{{
+			StorageType: irtypes.PVCKind,
+			Name:        cacheName,
+			PersistentVolumeClaimSpec: core.PersistentVolumeClaimSpec{
+				StorageClassName: &triggerTemplate.StorageClassName,
+				AccessModes:      []core.PersistentVolumeAccessMode{core.ReadWriteOnce},
+				Resources:        core.ResourceRequirements{Requests: core.ResourceList{core.ResourceStorage: quantity}},
+			},
+		}}
+	}
+	return nil
+}
+
+// setupPipelineArtifactBucket configures the bucket the workspace of each run is uploaded to, along with the secret holding its credentials
+func setupPipelineArtifactBucket(pipeline *irtypes.Pipeline, triggerTemplate *irtypes.TriggerTemplate, bucketName string) []irtypes.Storage {
+	bucket := strings.TrimSpace(qaengine.FetchStringAnswer(
+		common.ConfigTektonArtifactBucketKey,
+		"Enter the url of the bucket the artifacts of the Tekton pipeline runs should be uploaded to :",
+		[]string{
+			"Ex : gs://my-bucket/builds or s3://my-bucket/builds",
+			"The workspace of each run is uploaded to a directory named after the run. Leave it empty to discard the artifacts.",
+		},
+		"",
+		nil,
+	))
+	if bucket == "" {
+		return nil
+	}
+	content := map[string][]byte{}
+	switch {
+	case strings.HasPrefix(bucket, "gs://"):
+		content[gcsServiceAccountKeyFilename] = []byte(artifactBucketKeyPlaceholder)
+	case strings.HasPrefix(bucket, "s3://"):
+		content[awsCredentialsFilename] = []byte(artifactBucketKeyPlaceholder)
+		content[awsConfigFilename] = []byte(awsConfigPlaceholder)
+	default:
+		logrus.Warnf("Only the gs:// and s3:// artifact buckets are supported. Ignoring the bucket %s", bucket)
+		return nil
+	}
+	pipeline.ArtifactBucket = bucket
+	pipeline.ArtifactBucketWorkspaceName = bucketName
+	triggerTemplate.ArtifactBucketWorkspaceName = bucketName
+	triggerTemplate.ArtifactBucketSecretName = bucketName
+	return []irtypes.Storage{{
+		StorageType: irtypes.SecretKind,
+		Name:        bucketName,
+		SecretType:  core.SecretTypeOpaque,
+		Content:     content,
+	}}
+}
+
+// setupPipelineSigning configures the signing of the images pushed by the pipeline.
+// The keys which are not in a KMS or in a Kubernetes secret are read from a secret created for the pipeline.
+func setupPipelineSigning(pipeline *irtypes.Pipeline, triggerTemplate *irtypes.TriggerTemplate, secretName string) []irtypes.Storage {
+	pipeline.SignImages, pipeline.SigningKeyRef = commonqa.ImageSigning()
+	if !pipeline.SignImages || pipeline.SigningKeyRef == "" || strings.Contains(pipeline.SigningKeyRef, "://") {
+		return nil
+	}
+	pipeline.SigningWorkspaceName = secretName
+	pipeline.SigningSecretName = secretName
+	triggerTemplate.SigningWorkspaceName = secretName
+	triggerTemplate.SigningSecretName = secretName
+	return []irtypes.Storage{{
+		StorageType: irtypes.SecretKind,
+		Name:        secretName,
+		SecretType:  core.SecretTypeOpaque,
+		Content: map[string][]byte{
+			"cosign.key":      []byte(cosignKeyPlaceholder),
+			"cosign.password": []byte(cosignPasswordPlaceholder),
+		},
+	}}
+}
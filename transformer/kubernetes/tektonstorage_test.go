@@ -0,0 +1,165 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func setupTektonStorageQA(t *testing.T, configs ...string) {
+	t.Helper()
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", configs, nil, nil, false)
+}
+
+func TestSetupPipelineCache(t *testing.T) {
+	t.Run("no cache by default", func(t *testing.T) {
+		setupTektonStorageQA(t)
+		pipeline, triggerTemplate := irtypes.Pipeline{}, irtypes.TriggerTemplate{}
+		if storages := setupPipelineCache(&pipeline, &triggerTemplate, "myproject-build-cache"); len(storages) != 0 {
+			t.Fatalf("expected no storages. Actual: %+v", storages)
+		}
+		if pipeline.CacheType != irtypes.NoPipelineCache || triggerTemplate.CacheClaimName != "" {
+			t.Fatalf("expected no cache. Pipeline: %+v Trigger template: %+v", pipeline, triggerTemplate)
+		}
+	})
+
+	t.Run("the registry cache in the image registry by default", func(t *testing.T) {
+		setupTektonStorageQA(t,
+			common.ConfigTektonCacheTypeKey+`="registry"`,
+			common.ConfigImageRegistryURLKey+`="quay.io"`,
+			common.ConfigImageRegistryNamespaceKey+`="myteam"`,
+		)
+		pipeline, triggerTemplate := irtypes.Pipeline{}, irtypes.TriggerTemplate{}
+		if storages := setupPipelineCache(&pipeline, &triggerTemplate, "myproject-build-cache"); len(storages) != 0 {
+			t.Fatalf("expected no storages for the registry cache. Actual: %+v", storages)
+		}
+		if pipeline.CacheType != irtypes.RegistryPipelineCache || pipeline.CacheRepo != "quay.io/myteam/myproject-build-cache" {
+			t.Fatalf("got the cache type %q and the cache repo %q", pipeline.CacheType, pipeline.CacheRepo)
+		}
+	})
+
+	t.Run("the pvc cache shared by the runs", func(t *testing.T) {
+		setupTektonStorageQA(t, common.ConfigTektonCacheTypeKey+`="pvc"`, common.ConfigTektonCacheSizeKey+`="20Gi"`)
+		pipeline, triggerTemplate := irtypes.Pipeline{}, irtypes.TriggerTemplate{StorageClassName: "standard"}
+		storages := setupPipelineCache(&pipeline, &triggerTemplate, "myproject-build-cache")
+		if len(storages) != 1 || storages[0].StorageType != irtypes.PVCKind || storages[0].Name != "myproject-build-cache" {
+			t.Fatalf("expected the persistent volume claim of the cache. Actual: %+v", storages)
+		}
+		spec := storages[0].PersistentVolumeClaimSpec
+		if size := spec.Resources.Requests[core.ResourceStorage]; size.String() != "20Gi" {
+			t.Fatalf("got the size %s , want 20Gi", size.String())
+		}
+		if spec.StorageClassName == nil || *spec.StorageClassName != "standard" {
+			t.Fatalf("expected the storage class of the trigger template. Actual: %v", spec.StorageClassName)
+		}
+		if pipeline.CacheType != irtypes.PVCPipelineCache || triggerTemplate.CacheClaimName != "myproject-build-cache" {
+			t.Fatalf("expected the runs to share the claim. Pipeline: %+v Trigger template: %+v", pipeline, triggerTemplate)
+		}
+	})
+}
+
+func TestSetupPipelineArtifactBucket(t *testing.T) {
+	testCases := []struct {
+		name     string
+		bucket   string
+		wantKeys []string
+	}{
+		{name: "no bucket", bucket: ""},
+		{name: "gcs", bucket: "gs://my-bucket/builds", wantKeys: []string{gcsServiceAccountKeyFilename}},
+		{name: "s3", bucket: "s3://my-bucket/builds", wantKeys: []string{awsConfigFilename, awsCredentialsFilename}},
+		{name: "unsupported", bucket: "azure://my-bucket/builds"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			setupTektonStorageQA(t, common.ConfigTektonArtifactBucketKey+`="`+testCase.bucket+`"`)
+			pipeline, triggerTemplate := irtypes.Pipeline{}, irtypes.TriggerTemplate{}
+			storages := setupPipelineArtifactBucket(&pipeline, &triggerTemplate, "myproject-artifact-bucket")
+			if len(testCase.wantKeys) == 0 {
+				if len(storages) != 0 || pipeline.ArtifactBucket != "" || triggerTemplate.ArtifactBucketSecretName != "" {
+					t.Fatalf("expected no artifact bucket. Storages: %+v Pipeline: %+v", storages, pipeline)
+				}
+				return
+			}
+			if len(storages) != 1 || storages[0].StorageType != irtypes.SecretKind || storages[0].Name != "myproject-artifact-bucket" {
+				t.Fatalf("expected the secret of the bucket credentials. Actual: %+v", storages)
+			}
+			if len(storages[0].Content) != len(testCase.wantKeys) {
+				t.Fatalf("got the keys %+v , want %+v", storages[0].Content, testCase.wantKeys)
+			}
+			for _, key := range testCase.wantKeys {
+				if _, ok := storages[0].Content[key]; !ok {
+					t.Fatalf("expected the key %s in the secret. Actual: %+v", key, storages[0].Content)
+				}
+			}
+			if pipeline.ArtifactBucket != testCase.bucket || pipeline.ArtifactBucketWorkspaceName != "myproject-artifact-bucket" {
+				t.Fatalf("unexpected artifact bucket of the pipeline. Actual: %+v", pipeline)
+			}
+			if triggerTemplate.ArtifactBucketSecretName != "myproject-artifact-bucket" || triggerTemplate.ArtifactBucketWorkspaceName != "myproject-artifact-bucket" {
+				t.Fatalf("expected the trigger template to bind the secret to the workspace. Actual: %+v", triggerTemplate)
+			}
+		})
+	}
+}
+
+func TestSetupPipelineSigning(t *testing.T) {
+	testCases := []struct {
+		name       string
+		configs    []string
+		wantSign   bool
+		wantSecret bool
+	}{
+		{name: "not signed"},
+		{name: "keyless", configs: []string{common.ConfigImageSigningTypeKey + `="Keyless"`}, wantSign: true},
+		{name: "the key in a kms", configs: []string{common.ConfigImageSigningTypeKey + `="Key"`, common.ConfigImageSigningKeyRefKey + `="awskms:///alias/signing"`}, wantSign: true},
+		{name: "the key in a secret created for the pipeline", configs: []string{common.ConfigImageSigningTypeKey + `="Key"`, common.ConfigImageSigningKeyRefKey + `="cosign.key"`}, wantSign: true, wantSecret: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			setupTektonStorageQA(t, testCase.configs...)
+			pipeline, triggerTemplate := irtypes.Pipeline{}, irtypes.TriggerTemplate{}
+			storages := setupPipelineSigning(&pipeline, &triggerTemplate, "myproject-cosign")
+			if pipeline.SignImages != testCase.wantSign {
+				t.Fatalf("got the signing %t , want %t", pipeline.SignImages, testCase.wantSign)
+			}
+			if !testCase.wantSecret {
+				if len(storages) != 0 || pipeline.SigningSecretName != "" || triggerTemplate.SigningSecretName != "" {
+					t.Fatalf("expected no secret for the key. Storages: %+v Pipeline: %+v", storages, pipeline)
+				}
+				return
+			}
+			if len(storages) != 1 || storages[0].StorageType != irtypes.SecretKind || storages[0].Name != "myproject-cosign" {
+				t.Fatalf("expected the secret of the cosign key. Actual: %+v", storages)
+			}
+			for _, key := range []string{"cosign.key", "cosign.password"} {
+				if _, ok := storages[0].Content[key]; !ok {
+					t.Fatalf("expected the key %s in the secret. Actual: %+v", key, storages[0].Content)
+				}
+			}
+			if pipeline.SigningWorkspaceName != "myproject-cosign" || triggerTemplate.SigningSecretName != "myproject-cosign" || triggerTemplate.SigningWorkspaceName != "myproject-cosign" {
+				t.Fatalf("expected the trigger template to bind the secret to the workspace of the pipeline. Pipeline: %+v Trigger template: %+v", pipeline, triggerTemplate)
+			}
+		})
+	}
+}
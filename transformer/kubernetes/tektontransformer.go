@@ -31,6 +31,7 @@ import (
 	"github.com/konveyor/move2kube/transformer/kubernetes/irpreprocessor"
 	collecttypes "github.com/konveyor/move2kube/types/collection"
 	irtypes "github.com/konveyor/move2kube/types/ir"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
 	"github.com/konveyor/move2kube/types/transformer/artifacts"
 	"github.com/sirupsen/logrus"
@@ -143,7 +144,7 @@ func (t *Tekton) Transform(newArtifacts []transformertypes.Artifact, alreadySeen
 		tempDest := filepath.Join(t.Env.TempPath, deployCICDDir)
 		logrus.Debugf("Generating Tekton pipeline for CI/CD")
 		enhancedIR := t.setupEnhancedIR(ir, t.Env.GetProjectName())
-		files, err := apiresource.TransformIRAndPersist(enhancedIR, tempDest, resources, clusterConfig, t.TektonConfig.SetDefaultValuesInYamls)
+		files, err := apiresource.TransformIRAndPersist(t.Config.Name, enhancedIR, tempDest, resources, clusterConfig, t.TektonConfig.SetDefaultValuesInYamls)
 		if err != nil {
 			logrus.Errorf("Unable to transform and persist IR : %s", err)
 			return nil, nil, err
@@ -206,18 +207,23 @@ func (t *Tekton) setupEnhancedIR(oldir irtypes.IR, name string) irtypes.Enhanced
 		TriggerTemplateName: triggerTemplateName,
 	}}
 	res.TriggerBindings = []irtypes.TriggerBinding{{Name: triggerBindingName}}
-	res.TriggerTemplates = []irtypes.TriggerTemplate{{
+	triggerTemplate := irtypes.TriggerTemplate{
 		Name:               triggerTemplateName,
 		PipelineName:       pipelineName,
 		PipelineRunName:    pipelineName + "-$(uid)", // appends a random string to the name to make it unique
 		ServiceAccountName: clonePushServiceAccountName,
 		WorkspaceName:      workspaceName,
 		StorageClassName:   defaultStorageClassName,
-	}}
-	res.Pipelines = []irtypes.Pipeline{{
+	}
+	pipeline := irtypes.Pipeline{
 		Name:          pipelineName,
 		WorkspaceName: workspaceName,
-	}}
+	}
+	ir.Storages = append(ir.Storages, setupPipelineCache(&pipeline, &triggerTemplate, p(baseBuildCacheName))...)
+	ir.Storages = append(ir.Storages, setupPipelineArtifactBucket(&pipeline, &triggerTemplate, p(baseArtifactBucketName))...)
+	ir.Storages = append(ir.Storages, setupPipelineSigning(&pipeline, &triggerTemplate, p(baseCosignSecretName))...)
+	res.TriggerTemplates = []irtypes.TriggerTemplate{triggerTemplate}
+	res.Pipelines = []irtypes.Pipeline{pipeline}
 	ir.TektonResources = res
 	var port int32 = common.DefaultServicePort
 	ir.Services = map[string]irtypes.Service{gitEventIngressName: {
@@ -251,13 +257,16 @@ func (t *Tekton) setupEnhancedIR(oldir irtypes.IR, name string) irtypes.Enhanced
 	})
 
 	dockerConfigJSON := dockerConfigJSONPlaceholder
+	registryURL := commonqa.ImageRegistry()
+	if commonqa.GetImageRegistryInfo(registryURL).Provider == commonqa.ECRRegistryProvider {
+		logrus.Warnf("The passwords of the ECR registry %s expire after 12 hours. Refresh the secret %s with the output of aws ecr get-login-password before the pipeline runs, or give the pipeline an IAM role which can push the images", registryURL, registrySecretName)
+	}
 	imageRegistrySecret := irtypes.Storage{
 		StorageType: irtypes.SecretKind,
 		Name:        registrySecretName,
 		SecretType:  core.SecretTypeDockerConfigJSON,
-		// TODO: not sure if this annotation is specific to docker hub
-		// TOFIX: Fix registry url
-		Annotations: map[string]string{"tekton.dev/docker-0": ""},
+		// the credentials are used by tekton for the registry in the annotation
+		Annotations: map[string]string{"tekton.dev/docker-0": "https://" + registryURL},
 		Content:     map[string][]byte{core.DockerConfigJSONKey: []byte(dockerConfigJSON)},
 	}
 
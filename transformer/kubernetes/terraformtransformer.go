@@ -0,0 +1,231 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
+	"github.com/konveyor/move2kube/transformer/kubernetes/irpreprocessor"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	qatypes "github.com/konveyor/move2kube/types/qaengine"
+	"github.com/konveyor/move2kube/types/qaengine/commonqa"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultTerraformOutputPath = common.DeployDir + string(os.PathSeparator) + "terraform"
+
+	terraformAWSProvider    = "aws"
+	terraformGoogleProvider = "google"
+	terraformAzureProvider  = "azurerm"
+
+	terraformRoute53DNSProvider     = "Route53"
+	terraformAzureDNSProvider       = "Azure DNS"
+	terraformCloudDNSProvider       = "Cloud DNS"
+	terraformNoDNSProvider          = "None"
+	terraformArtifactRegistrySuffix = "-docker.pkg.dev"
+)
+
+var (
+	ecrRegistryRegex = regexp.MustCompile(`^\d+\.dkr\.ecr\.([a-z0-9-]+)\.amazonaws\.com$`)
+	acrRegistryRegex = regexp.MustCompile(`^([a-z0-9]+)\.azurecr\.io$`)
+)
+
+// Terraform implements Transformer interface
+type Terraform struct {
+	Config          transformertypes.Transformer
+	Env             *environment.Environment
+	TerraformConfig *TerraformYamlConfig
+}
+
+// TerraformYamlConfig stores the transformer specific configuration
+type TerraformYamlConfig struct {
+	OutputPath  string `yaml:"outputPath"`
+	IngressName string `yaml:"ingressName"`
+}
+
+// TerraformTemplateConfig represents template config used by the Terraform module
+type TerraformTemplateConfig struct {
+	Namespace        string
+	RegistryProvider string
+	RegistryRegion   string
+	RegistryName     string
+	Repositories     []string
+	DNSProvider      string
+	IngressHosts     []string
+}
+
+// Init Initializes the transformer
+func (t *Terraform) Init(tc transformertypes.Transformer, env *environment.Environment) (err error) {
+	t.Config = tc
+	t.Env = env
+	t.TerraformConfig = &TerraformYamlConfig{}
+	if err := common.GetObjFromInterface(t.Config.Spec.Config, t.TerraformConfig); err != nil {
+		logrus.Errorf("unable to load config for Transformer %+v into %T : %s", t.Config.Spec.Config, t.TerraformConfig, err)
+		return err
+	}
+	if t.TerraformConfig.OutputPath == "" {
+		t.TerraformConfig.OutputPath = defaultTerraformOutputPath
+	}
+	return nil
+}
+
+// GetConfig returns the transformer config
+func (t *Terraform) GetConfig() (transformertypes.Transformer, *environment.Environment) {
+	return t.Config, t.Env
+}
+
+// DirectoryDetect runs detect in each sub directory
+func (t *Terraform) DirectoryDetect(dir string) (services map[string][]transformertypes.Artifact, err error) {
+	return nil, nil
+}
+
+// Transform generates a Terraform module for the image repositories, the namespace and the DNS records the application depends on
+func (t *Terraform) Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error) {
+	pathMappings := []transformertypes.PathMapping{}
+	for _, newArtifact := range newArtifacts {
+		if newArtifact.Type != irtypes.IRArtifactType {
+			continue
+		}
+		var ir irtypes.IR
+		if err := newArtifact.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", ir, err)
+			continue
+		}
+		var clusterConfig collecttypes.ClusterMetadata
+		if err := newArtifact.GetConfig(ClusterMetadata, &clusterConfig); err != nil {
+			logrus.Errorf("unable to load config for Transformer into %T : %s", clusterConfig, err)
+			continue
+		}
+		ingressName, err := common.GetStringFromTemplate(t.TerraformConfig.IngressName, map[string]string{
+			common.ProjectNameTemplatizedStringKey:  t.Env.ProjectName,
+			common.ArtifactNameTemplatizedStringKey: newArtifact.Name,
+		})
+		if err != nil || ingressName == "" {
+			logrus.Debugf("Unable to compute the Ingress name. Defaulting to the artifact name. Error: %q", err)
+			ingressName = newArtifact.Name
+		}
+		ir.Name = ingressName
+		preprocessedIR, err := irpreprocessor.Preprocess(ir)
+		if err != nil {
+			logrus.Errorf("Unable to prepreocess IR : %s", err)
+		} else {
+			ir = preprocessedIR
+		}
+		tc := TerraformTemplateConfig{
+			Namespace: qaengine.FetchStringAnswer(
+				common.ConfigNamespaceNameKey,
+				"Provide the name of the namespace :",
+				nil,
+				common.MakeStringDNSNameCompliantWithoutDots(ir.Name),
+				qatypes.NewDNSLabelValidator(),
+			),
+			IngressHosts: apiresource.GetIngressHosts(irtypes.NewEnhancedIRFromIR(ir), clusterConfig),
+		}
+		t.setRegistryConfig(&tc, ir)
+		if len(tc.IngressHosts) > 0 {
+			tc.DNSProvider = qaengine.FetchSelectAnswer(
+				common.ConfigTerraformDNSProviderKey,
+				"Select the provider of the DNS zone for the ingress hosts :",
+				[]string{"The records point the ingress hosts to the address of the ingress controller load balancer"},
+				getDefaultTerraformDNSProvider(tc.RegistryProvider),
+				[]string{terraformRoute53DNSProvider, terraformAzureDNSProvider, terraformCloudDNSProvider, terraformNoDNSProvider},
+				nil,
+			)
+		}
+		if tc.DNSProvider == terraformNoDNSProvider {
+			tc.DNSProvider = ""
+		}
+		pathMappings = append(pathMappings, transformertypes.PathMapping{
+			Type:           transformertypes.TemplatePathMappingType,
+			SrcPath:        filepath.Join(t.Env.Context, t.Config.Spec.TemplatesDir),
+			DestPath:       t.TerraformConfig.OutputPath,
+			TemplateConfig: tc,
+		})
+	}
+	return pathMappings, nil, nil
+}
+
+// setRegistryConfig sets the provider and the repositories of the images built for the services, based on the image registry
+func (t *Terraform) setRegistryConfig(tc *TerraformTemplateConfig, ir irtypes.IR) {
+	registry := commonqa.ImageRegistry()
+	namespace := commonqa.ImageRegistryNamespace()
+	repositories := []string{}
+	for imageName := range ir.ContainerImages {
+		repository := getImageRepositoryName(imageName)
+		if repository == "" {
+			continue
+		}
+		repositories = common.AppendIfNotPresent(repositories, namespace+"/"+repository)
+	}
+	sort.Strings(repositories)
+	if matches := ecrRegistryRegex.FindStringSubmatch(registry); matches != nil {
+		tc.RegistryProvider = terraformAWSProvider
+		tc.RegistryRegion = matches[1]
+		tc.Repositories = repositories
+		return
+	}
+	if strings.HasSuffix(registry, terraformArtifactRegistrySuffix) {
+		// Artifact Registry repositories contain the images, the registry namespace is <project>/<repository>
+		tc.RegistryProvider = terraformGoogleProvider
+		tc.RegistryRegion = strings.TrimSuffix(registry, terraformArtifactRegistrySuffix)
+		tc.RegistryName = namespace[strings.LastIndex(namespace, "/")+1:]
+		return
+	}
+	if matches := acrRegistryRegex.FindStringSubmatch(registry); matches != nil {
+		// Azure Container Registry creates the repositories on push
+		tc.RegistryProvider = terraformAzureProvider
+		tc.RegistryName = matches[1]
+		return
+	}
+	logrus.Infof("The image registry %s is not supported by the Terraform module. Create the image repositories manually.", registry)
+}
+
+// getImageRepositoryName returns the name of the image without the registry, the namespace and the tag
+func getImageRepositoryName(image string) string {
+	if i := strings.Index(image, "@"); i != -1 {
+		image = image[:i]
+	}
+	image = image[strings.LastIndex(image, "/")+1:]
+	if i := strings.Index(image, ":"); i != -1 {
+		image = image[:i]
+	}
+	return image
+}
+
+// getDefaultTerraformDNSProvider returns the DNS provider of the cloud hosting the image registry
+func getDefaultTerraformDNSProvider(registryProvider string) string {
+	switch registryProvider {
+	case terraformAWSProvider:
+		return terraformRoute53DNSProvider
+	case terraformAzureProvider:
+		return terraformAzureDNSProvider
+	case terraformGoogleProvider:
+		return terraformCloudDNSProvider
+	}
+	return terraformNoDNSProvider
+}
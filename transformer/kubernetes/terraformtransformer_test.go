@@ -0,0 +1,168 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package kubernetes
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/environment"
+	"github.com/konveyor/move2kube/qaengine"
+	collecttypes "github.com/konveyor/move2kube/types/collection"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestGetImageRepositoryName(t *testing.T) {
+	for image, want := range map[string]string{
+		"api":                               "api",
+		"api:v1":                            "api",
+		"quay.io/myteam/api:latest":         "api",
+		"localhost:5000/api":                "api",
+		"quay.io/myteam/api@sha256:0123abc": "api",
+	} {
+		if got := getImageRepositoryName(image); got != want {
+			t.Errorf("getImageRepositoryName(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
+
+func TestTerraformSetRegistryConfig(t *testing.T) {
+	ir := irtypes.NewIR()
+	ir.ContainerImages["quay.io/old/api:v1"] = irtypes.ContainerImage{}
+	ir.ContainerImages["web"] = irtypes.ContainerImage{}
+	testCases := []struct {
+		registry  string
+		namespace string
+		want      TerraformTemplateConfig
+	}{
+		{registry: "123456789012.dkr.ecr.eu-west-1.amazonaws.com", namespace: "shop", want: TerraformTemplateConfig{
+			RegistryProvider: terraformAWSProvider, RegistryRegion: "eu-west-1", Repositories: []string{"shop/api", "shop/web"},
+		}},
+		{registry: "europe-west1-docker.pkg.dev", namespace: "my-project/shop", want: TerraformTemplateConfig{
+			RegistryProvider: terraformGoogleProvider, RegistryRegion: "europe-west1", RegistryName: "shop",
+		}},
+		{registry: "shopregistry.azurecr.io", namespace: "shop", want: TerraformTemplateConfig{
+			RegistryProvider: terraformAzureProvider, RegistryName: "shopregistry",
+		}},
+		{registry: "quay.io", namespace: "shop", want: TerraformTemplateConfig{}},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.registry, func(t *testing.T) {
+			qaengine.Reset()
+			t.Cleanup(qaengine.Reset)
+			qaengine.AddEngine(qaengine.NewDefaultEngine())
+			qaengine.SetupConfigFile("", []string{
+				common.ConfigImageRegistryURLKey + `="` + testCase.registry + `"`,
+				common.ConfigImageRegistryNamespaceKey + `="` + testCase.namespace + `"`,
+			}, nil, nil, false)
+			tc := TerraformTemplateConfig{}
+			(&Terraform{}).setRegistryConfig(&tc, ir)
+			if !cmp.Equal(tc, testCase.want) {
+				t.Fatalf("the template config is different. Difference:\n%s", cmp.Diff(testCase.want, tc))
+			}
+		})
+	}
+}
+
+func TestTerraformTransform(t *testing.T) {
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", []string{
+		common.ConfigImageRegistryURLKey + `="123456789012.dkr.ecr.us-east-1.amazonaws.com"`,
+		common.ConfigImageRegistryNamespaceKey + `="shop"`,
+	}, nil, nil, false)
+	tc := transformertypes.Transformer{}
+	tc.Spec.TemplatesDir = "templates"
+	env := &environment.Environment{EnvInfo: environment.EnvInfo{
+		ProjectName: "shop",
+		Context:     filepath.Join("..", "..", "assets", "built-in", "transformers", "kubernetes", "terraform"),
+	}}
+	transformer := &Terraform{}
+	if err := transformer.Init(tc, env); err != nil {
+		t.Fatalf("failed to initialize the transformer. Error: %q", err)
+	}
+	ir := irtypes.NewIR()
+	ir.ContainerImages["api:latest"] = irtypes.ContainerImage{}
+	for name, relPath := range map[string]string{"api": "/api", "admin": "admin/"} {
+		service := irtypes.NewServiceWithName(name)
+		service.Containers = []core.Container{{Name: name, Image: name + ":latest"}}
+		service.ServiceToPodPortForwardings = []irtypes.ServiceToPodPortForwarding{{
+			ServicePort:    networking.ServiceBackendPort{Number: 8080},
+			PodPort:        networking.ServiceBackendPort{Number: 8080},
+			ServiceRelPath: relPath,
+			ServiceType:    core.ServiceTypeClusterIP,
+		}}
+		ir.Services[name] = service
+	}
+	cluster := collecttypes.NewClusterMetadata("")
+	cluster.Spec.Host = "apps.example.com"
+	pathMappings, _, err := transformer.Transform([]transformertypes.Artifact{{
+		Name: "shop",
+		Type: irtypes.IRArtifactType,
+		Configs: map[transformertypes.ConfigType]interface{}{
+			irtypes.IRConfigType: ir,
+			ClusterMetadata:      cluster,
+		},
+	}}, nil)
+	if err != nil {
+		t.Fatalf("failed to transform. Error: %q", err)
+	}
+	if len(pathMappings) != 1 || pathMappings[0].DestPath != defaultTerraformOutputPath {
+		t.Fatalf("expected the module in the terraform directory. Actual: %+v", pathMappings)
+	}
+	templateConfig := pathMappings[0].TemplateConfig.(TerraformTemplateConfig)
+	want := TerraformTemplateConfig{
+		Namespace:        "shop",
+		RegistryProvider: terraformAWSProvider,
+		RegistryRegion:   "us-east-1",
+		Repositories:     []string{"shop/api"},
+		DNSProvider:      terraformRoute53DNSProvider,
+		IngressHosts:     []string{"admin.apps.example.com", "apps.example.com"},
+	}
+	if !cmp.Equal(templateConfig, want) {
+		t.Fatalf("the template config is different. Difference:\n%s", cmp.Diff(want, templateConfig))
+	}
+	tpl, err := os.ReadFile(filepath.Join(pathMappings[0].SrcPath, "main.tf"))
+	if err != nil {
+		t.Fatalf("failed to read the template. Error: %q", err)
+	}
+	mainTf, err := common.GetStringFromTemplate(string(tpl), templateConfig)
+	if err != nil {
+		t.Fatalf("failed to render the template. Error: %q", err)
+	}
+	for _, line := range []string{
+		`  image_repositories = ["shop/api"]`,
+		`  ingress_hosts      = ["admin.apps.example.com", "apps.example.com"]`,
+		`resource "aws_ecr_repository" "images" {`,
+		`resource "aws_route53_record" "ingress" {`,
+	} {
+		if !strings.Contains(mainTf, line+"\n") {
+			t.Fatalf("expected the line %q in the module:\n%s", line, mainTf)
+		}
+	}
+	if strings.Contains(mainTf, "azurerm") || strings.Contains(mainTf, "google") {
+		t.Fatalf("expected only the AWS resources in the module:\n%s", mainTf)
+	}
+}
@@ -0,0 +1,145 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+// OutputManifestFile is the name of the file listing all the generated files
+const OutputManifestFile = types.AppNameShort + "-manifest.json"
+
+// OutputManifest lists all the files in the output directory
+type OutputManifest struct {
+	Files []OutputManifestEntry `yaml:"files" json:"files"`
+}
+
+// OutputManifestEntry stores the details of a generated file
+type OutputManifestEntry struct {
+	// Path is relative to the output directory and always uses forward slashes
+	Path        string `yaml:"path" json:"path"`
+	Size        int64  `yaml:"size" json:"size"`
+	SHA256      string `yaml:"sha256" json:"sha256"`
+	Transformer string `yaml:"transformer,omitempty" json:"transformer,omitempty"`
+	// HandAuthored is true for the files, in the output directory, which were not generated and were kept as is
+	HandAuthored bool `yaml:"handAuthored,omitempty" json:"handAuthored,omitempty"`
+}
+
+type pathMappingProducer struct {
+	destPath    string
+	transformer string
+}
+
+// pathMappingProducers stores the transformer which created each path mapping, in the order they were created
+var pathMappingProducers = []pathMappingProducer{}
+
+func recordPathMappingProducers(pathMappings []transformertypes.PathMapping, transformerName string) {
+	for _, pm := range pathMappings {
+		if strings.EqualFold(string(pm.Type), string(transformertypes.DeletePathMappingType)) ||
+			strings.EqualFold(string(pm.Type), string(transformertypes.PathTemplatePathMappingType)) ||
+			filepath.IsAbs(pm.DestPath) {
+			continue
+		}
+		pathMappingProducers = append(pathMappingProducers, pathMappingProducer{destPath: filepath.Clean(pm.DestPath), transformer: transformerName})
+	}
+}
+
+// getFileProducer returns the transformer whose path mapping has the longest destination path containing the file.
+// When multiple path mappings have the same destination, the last one wins since it overwrites the others.
+func getFileProducer(relPath string) string {
+	producer := ""
+	longest := -1
+	for _, p := range pathMappingProducers {
+		if p.destPath != "." && relPath != p.destPath && !strings.HasPrefix(relPath, p.destPath+string(os.PathSeparator)) {
+			continue
+		}
+		length := len(p.destPath)
+		if p.destPath == "." {
+			length = 0
+		}
+		if length >= longest {
+			longest = length
+			producer = p.transformer
+		}
+	}
+	return producer
+}
+
+// writeOutputManifest writes a manifest of all the files in the output directory
+func writeOutputManifest(outputPath string) error {
+	manifest := OutputManifest{Files: []OutputManifestEntry{}}
+	manifestPath := filepath.Join(outputPath, OutputManifestFile)
+	err := filepath.WalkDir(outputPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path == manifestPath {
+			return nil
+		}
+		relPath, err := filepath.Rel(outputPath, path)
+		if err != nil {
+			return fmt.Errorf("failed to make the path %s relative to the output directory %s . Error: %w", path, outputPath, err)
+		}
+		size, checksum, err := getFileSizeAndChecksum(path)
+		if err != nil {
+			return err
+		}
+		entry := OutputManifestEntry{Path: filepath.ToSlash(relPath), Size: size, SHA256: checksum}
+		if keptHandAuthoredFiles[relPath] {
+			entry.HandAuthored = true
+		} else if relPath != TransformationReportJSONFile && relPath != TransformationReportMarkdownFile {
+			entry.Transformer = getFileProducer(relPath)
+		}
+		manifest.Files = append(manifest.Files, entry)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to walk the output directory %s . Error: %w", outputPath, err)
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the output manifest to json. Error: %w", err)
+	}
+	if err := os.WriteFile(manifestPath, data, common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the output manifest to the file at path %s . Error: %w", manifestPath, err)
+	}
+	return nil
+}
+
+func getFileSizeAndChecksum(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to open the file at path %s . Error: %w", path, err)
+	}
+	defer f.Close()
+	hash := sha256.New()
+	size, err := io.Copy(hash, f)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to read the file at path %s . Error: %w", path, err)
+	}
+	return size, fmt.Sprintf("%x", hash.Sum(nil)), nil
+}
@@ -0,0 +1,84 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+func TestWriteOutputManifest(t *testing.T) {
+	pathMappingProducers = []pathMappingProducer{}
+	t.Cleanup(func() { pathMappingProducers = []pathMappingProducer{} })
+	recordPathMappingProducers([]transformertypes.PathMapping{
+		{Type: transformertypes.DefaultPathMappingType, DestPath: "deploy"},
+		{Type: transformertypes.DeletePathMappingType, DestPath: "deploy/yamls"},
+		{Type: transformertypes.DefaultPathMappingType, DestPath: "/tmp/outside"},
+	}, "Kubernetes")
+	recordPathMappingProducers([]transformertypes.PathMapping{{Type: transformertypes.TemplatePathMappingType, DestPath: "deploy/cicd"}}, "Tekton")
+	recordPathMappingProducers([]transformertypes.PathMapping{{Type: transformertypes.DefaultPathMappingType, DestPath: "deploy/cicd"}}, "ArgoCD")
+	recordPathMappingProducers([]transformertypes.PathMapping{{Type: transformertypes.DefaultPathMappingType, DestPath: "."}}, "ReadMeGenerator")
+
+	outputPath := t.TempDir()
+	files := map[string]string{
+		filepath.Join("deploy", "yamls", "api-deployment.yaml"): "kind: Deployment\n",
+		filepath.Join("deploy", "cicd", "pipeline.yaml"):        "kind: Pipeline\n",
+		"Readme.md":                  "# myproject\n",
+		TransformationReportJSONFile: "{}",
+		OutputManifestFile:           "stale",
+	}
+	for path, contents := range files {
+		path = filepath.Join(outputPath, path)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory of %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	if err := writeOutputManifest(outputPath); err != nil {
+		t.Fatalf("failed to write the output manifest. Error: %q", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outputPath, OutputManifestFile))
+	if err != nil {
+		t.Fatalf("failed to read the output manifest. Error: %q", err)
+	}
+	manifest := OutputManifest{}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		t.Fatalf("failed to parse the output manifest. Error: %q", err)
+	}
+	newEntry := func(path, contents, transformer string) OutputManifestEntry {
+		return OutputManifestEntry{Path: path, Size: int64(len(contents)), SHA256: fmt.Sprintf("%x", sha256.Sum256([]byte(contents))), Transformer: transformer}
+	}
+	want := []OutputManifestEntry{
+		newEntry("Readme.md", "# myproject\n", "ReadMeGenerator"),
+		newEntry("deploy/cicd/pipeline.yaml", "kind: Pipeline\n", "ArgoCD"),
+		newEntry("deploy/yamls/api-deployment.yaml", "kind: Deployment\n", "Kubernetes"),
+		newEntry(TransformationReportJSONFile, "{}", ""),
+	}
+	if !cmp.Equal(manifest.Files, want) {
+		t.Fatalf("the output manifest is different. Difference:\n%s", cmp.Diff(want, manifest.Files))
+	}
+}
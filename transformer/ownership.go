@@ -0,0 +1,157 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
+)
+
+// IsHandAuthoredFile returns true if the file is a yaml file containing kubernetes resources without the ownership annotation
+func IsHandAuthoredFile(path string) bool {
+	if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+		return false
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return isHandAuthored(data)
+}
+
+// isHandAuthored returns true if any of the kubernetes resources in the yaml is missing the ownership annotation
+func isHandAuthored(data []byte) bool {
+	docs, err := common.SplitYAML(data)
+	if err != nil {
+		return false
+	}
+	for _, doc := range docs {
+		resource := map[string]interface{}{}
+		if err := yaml.Unmarshal(doc, &resource); err != nil || resource["apiVersion"] == nil || resource["kind"] == nil {
+			continue
+		}
+		annotations := cast.ToStringMapString(cast.ToStringMap(resource["metadata"])["annotations"])
+		if _, ok := annotations[common.OwnershipAnnotation]; !ok {
+			return true
+		}
+	}
+	return false
+}
+
+// keptHandAuthoredFiles stores the paths, relative to the output directory, of the hand authored files kept during the transformation
+var keptHandAuthoredFiles = map[string]bool{}
+
+// GetHandAuthoredFiles returns the contents of the hand authored files in the directory, keyed by their paths relative to the directory.
+// The generated files listed in the output manifest of a previous transformation are skipped, unless they were modified since.
+func GetHandAuthoredFiles(dir string) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return files, nil
+	}
+	generatedFiles := map[string]string{}
+	if data, err := os.ReadFile(filepath.Join(dir, OutputManifestFile)); err == nil {
+		manifest := OutputManifest{}
+		if err := json.Unmarshal(data, &manifest); err != nil {
+			logrus.Warnf("failed to unmarshal the output manifest in the directory %s . Error: %q", dir, err)
+		}
+		for _, entry := range manifest.Files {
+			if !entry.HandAuthored {
+				generatedFiles[filepath.FromSlash(entry.Path)] = entry.SHA256
+			}
+		}
+	}
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !IsHandAuthoredFile(path) {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		if checksum, ok := generatedFiles[relPath]; ok {
+			if _, currentChecksum, err := getFileSizeAndChecksum(path); err == nil && currentChecksum == checksum {
+				return nil
+			}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[relPath] = data
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to look for the hand authored files in the directory %s . Error: %w", dir, err)
+	}
+	return files, nil
+}
+
+// SeedHandAuthoredFiles copies the hand authored files from the output directory into the directory the transformation is run in instead
+func SeedHandAuthoredFiles(outputPath, dir string) error {
+	files, err := GetHandAuthoredFiles(outputPath)
+	if err != nil {
+		return err
+	}
+	_, err = RestoreHandAuthoredFiles(dir, files)
+	return err
+}
+
+// RestoreHandAuthoredFiles writes back the hand authored files, which were removed or replaced by the generated files, and returns the paths of the files which were kept.
+// A file is not restored when the file now at its path is not generated either, like a copy of a source file.
+func RestoreHandAuthoredFiles(dir string, files map[string][]byte) ([]string, error) {
+	kept := []string{}
+	relPaths := []string{}
+	for relPath := range files {
+		relPaths = append(relPaths, relPath)
+	}
+	sort.Strings(relPaths)
+	for _, relPath := range relPaths {
+		path := filepath.Join(dir, relPath)
+		if data, err := os.ReadFile(path); err == nil {
+			if bytes.Equal(data, files[relPath]) {
+				kept = append(kept, relPath)
+				continue
+			}
+			if isHandAuthored(data) {
+				continue
+			}
+			logrus.Warnf("Keeping the hand authored file %s instead of the generated one. Add the annotation %s to its resources to let it be replaced.", path, common.OwnershipAnnotation)
+		} else {
+			logrus.Infof("Keeping the hand authored file %s", path)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			return kept, fmt.Errorf("failed to create the directory %s . Error: %w", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, files[relPath], common.DefaultFilePermission); err != nil {
+			return kept, fmt.Errorf("failed to restore the hand authored file %s . Error: %w", path, err)
+		}
+		kept = append(kept, relPath)
+	}
+	return kept, nil
+}
@@ -0,0 +1,141 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+)
+
+const (
+	generatedDeployment   = "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: api\n  annotations:\n    " + common.OwnershipAnnotation + ": move2kube\n"
+	handAuthoredConfigMap = "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: extra\n"
+)
+
+func writeOwnershipTestFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for path, contents := range files {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory of %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+}
+
+func TestIsHandAuthored(t *testing.T) {
+	testCases := []struct {
+		name     string
+		contents string
+		want     bool
+	}{
+		{name: "generated resource", contents: generatedDeployment, want: false},
+		{name: "resource without the annotation", contents: handAuthoredConfigMap, want: true},
+		{name: "generated and hand authored resources", contents: generatedDeployment + "---\n" + handAuthoredConfigMap, want: true},
+		{name: "not a kubernetes resource", contents: "name: myproject\nversion: 1\n", want: false},
+		{name: "empty", contents: "", want: false},
+	}
+	for _, testCase := range testCases {
+		if got := isHandAuthored([]byte(testCase.contents)); got != testCase.want {
+			t.Errorf("isHandAuthored for the %s = %t, want %t", testCase.name, got, testCase.want)
+		}
+	}
+}
+
+func TestGetHandAuthoredFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeOwnershipTestFiles(t, dir, map[string]string{
+		"deploy/yamls/api-deployment.yaml": generatedDeployment,
+		"deploy/yamls/extra.yaml":          handAuthoredConfigMap,
+		"deploy/yamls/unchanged.yaml":      handAuthoredConfigMap,
+		"deploy/yamls/modified.yml":        handAuthoredConfigMap + "data:\n  key: value\n",
+		"deploy/notes.txt":                 handAuthoredConfigMap,
+	})
+	manifest := OutputManifest{Files: []OutputManifestEntry{
+		{Path: "deploy/yamls/unchanged.yaml", SHA256: getOwnershipTestChecksum(t, filepath.Join(dir, "deploy", "yamls", "unchanged.yaml"))},
+		{Path: "deploy/yamls/modified.yml", SHA256: getOwnershipTestChecksum(t, filepath.Join(dir, "deploy", "yamls", "unchanged.yaml"))},
+		{Path: "deploy/yamls/extra.yaml", SHA256: getOwnershipTestChecksum(t, filepath.Join(dir, "deploy", "yamls", "extra.yaml")), HandAuthored: true},
+	}}
+	data, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("failed to marshal the output manifest. Error: %q", err)
+	}
+	writeOwnershipTestFiles(t, dir, map[string]string{OutputManifestFile: string(data)})
+
+	files, err := GetHandAuthoredFiles(dir)
+	if err != nil {
+		t.Fatalf("failed to get the hand authored files. Error: %q", err)
+	}
+	want := map[string][]byte{
+		filepath.Join("deploy", "yamls", "extra.yaml"):   []byte(handAuthoredConfigMap),
+		filepath.Join("deploy", "yamls", "modified.yml"): []byte(handAuthoredConfigMap + "data:\n  key: value\n"),
+	}
+	if diff := cmp.Diff(want, files); diff != "" {
+		t.Fatalf("got the wrong hand authored files. Diff (-want +got):\n%s", diff)
+	}
+	if files, err := GetHandAuthoredFiles(filepath.Join(dir, "missing")); err != nil || len(files) != 0 {
+		t.Fatalf("expected no hand authored files in a missing directory. Files: %+v Error: %v", files, err)
+	}
+}
+
+func getOwnershipTestChecksum(t *testing.T, path string) string {
+	t.Helper()
+	_, checksum, err := getFileSizeAndChecksum(path)
+	if err != nil {
+		t.Fatalf("failed to get the checksum of %s . Error: %q", path, err)
+	}
+	return checksum
+}
+
+func TestRestoreHandAuthoredFiles(t *testing.T) {
+	dir := t.TempDir()
+	otherHandAuthored := "apiVersion: v1\nkind: Secret\nmetadata:\n  name: copied\n"
+	writeOwnershipTestFiles(t, dir, map[string]string{
+		"same.yaml":     handAuthoredConfigMap,
+		"replaced.yaml": generatedDeployment,
+		"source.yaml":   otherHandAuthored,
+	})
+	files := map[string][]byte{
+		"same.yaml":                          []byte(handAuthoredConfigMap),
+		"replaced.yaml":                      []byte(handAuthoredConfigMap),
+		"source.yaml":                        []byte(handAuthoredConfigMap),
+		filepath.Join("sub", "removed.yaml"): []byte(handAuthoredConfigMap),
+	}
+	kept, err := RestoreHandAuthoredFiles(dir, files)
+	if err != nil {
+		t.Fatalf("failed to restore the hand authored files. Error: %q", err)
+	}
+	if diff := cmp.Diff([]string{"replaced.yaml", "same.yaml", filepath.Join("sub", "removed.yaml")}, kept); diff != "" {
+		t.Fatalf("got the wrong kept files. Diff (-want +got):\n%s", diff)
+	}
+	for relPath, want := range map[string]string{
+		"replaced.yaml":                      handAuthoredConfigMap,
+		filepath.Join("sub", "removed.yaml"): handAuthoredConfigMap,
+		"source.yaml":                        otherHandAuthored,
+	} {
+		if data, err := os.ReadFile(filepath.Join(dir, relPath)); err != nil || string(data) != want {
+			t.Errorf("got the contents %q for the file %s , want %q . Error: %v", data, relPath, want, err)
+		}
+	}
+}
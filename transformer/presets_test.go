@@ -0,0 +1,71 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRetargetPresetSelectsTheLabelledCustomizations(t *testing.T) {
+	preset := struct {
+		Move2Kube struct {
+			TransformerSelector string `yaml:"transformerselector"`
+		} `yaml:"move2kube"`
+	}{}
+	presetBytes, err := os.ReadFile(filepath.Join("..", "assets", "built-in", "presets", "retarget-kubernetes-yamls.yaml"))
+	if err != nil {
+		t.Fatalf("failed to read the preset. Error: %q", err)
+	}
+	if err := yaml.Unmarshal(presetBytes, &preset); err != nil {
+		t.Fatalf("failed to parse the preset. Error: %q", err)
+	}
+	selector, err := common.ConvertStringSelectorsToSelectors(preset.Move2Kube.TransformerSelector)
+	if err != nil {
+		t.Fatalf("failed to parse the selector of the preset. Error: %q", err)
+	}
+	customizationsDir := t.TempDir()
+	newStarlarkTransformer := func(name, labels string) string {
+		path := filepath.Join(customizationsDir, name+".yaml")
+		transformerYaml := "apiVersion: move2kube.konveyor.io/v1alpha1\nkind: Transformer\nmetadata:\n  name: " + name + "\n  labels: " + labels + "\nspec:\n  class: Starlark\n"
+		if err := os.WriteFile(path, []byte(transformerYaml), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the transformer %s . Error: %q", name, err)
+		}
+		return path
+	}
+	transformerYamlPaths := map[string]string{
+		"KubernetesVersionChanger": filepath.Join("..", "assets", "built-in", "transformers", "kubernetes", "kubernetesversionchanger", "transformer.yaml"),
+		"Kubernetes":               filepath.Join("..", "assets", "built-in", "transformers", "kubernetes", "kubernetes", "transformer.yaml"),
+		"labelled":                 newStarlarkTransformer("labelled", "{move2kube.konveyor.io/task: retarget}"),
+		"unlabelled":               newStarlarkTransformer("unlabelled", "{}"),
+	}
+	got := getFilteredTransformers(transformerYamlPaths, selector, true)
+	for _, name := range []string{"KubernetesVersionChanger", "labelled"} {
+		if _, ok := got[name]; !ok {
+			t.Fatalf("expected the preset to select the transformer %s , got %+v", name, got)
+		}
+	}
+	for _, name := range []string{"Kubernetes", "unlabelled"} {
+		if _, ok := got[name]; ok {
+			t.Fatalf("expected the preset not to select the transformer %s", name)
+		}
+	}
+}
@@ -17,11 +17,18 @@
 package transformer
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
+	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/filesystem"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types"
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
 	"github.com/sirupsen/logrus"
 )
@@ -35,6 +42,202 @@ func getpair(a, b string) pair {
 	return pair{A: a, B: b}
 }
 
+// sourcesStage stores the copies of the source path mappings, laid out like the output directory.
+// The sources are copied only once into the stage, instead of once per transformer and iteration.
+// The stage is kept for the next transformation into the same output directory, so that only the changed sources are copied again.
+type sourcesStage struct {
+	dir string
+	// previousDir stores the sources staged by the previous transformation, until they are moved back into the stage
+	previousDir string
+	persistent  bool
+	staged      map[pair]bool
+	// syncedDests stores the destination paths which were synced with a source path, the other source paths are merged into them
+	syncedDests map[string]bool
+}
+
+// sourcesCacheMaxAge is the age after which the sources kept for the other source and output directories are removed from the cache
+const sourcesCacheMaxAge = 30 * 24 * time.Hour
+
+var stage = sourcesStage{staged: map[pair]bool{}, syncedDests: map[string]bool{}}
+
+// resetStage removes the staged sources of the previous transformation, except the ones kept for the next transformation
+func resetStage() {
+	if stage.previousDir != "" {
+		os.RemoveAll(stage.previousDir)
+	}
+	if stage.dir != "" && !stage.persistent {
+		os.RemoveAll(stage.dir)
+	}
+	stage = sourcesStage{staged: map[pair]bool{}, syncedDests: map[string]bool{}}
+}
+
+// initStage sets up the stage of the transformation of the source directory into the output directory.
+// If the user chooses to, the sources staged by the previous transformation of the same source directory into the same output directory are kept aside to be reused.
+func initStage(sourcePath, outputPath string) {
+	resetStage()
+	if !qaengine.FetchBoolAnswer(
+		common.ConfigSourceCacheKey,
+		"Do you want to keep a copy of the sources in the cache directory, so that the next transformation copies only the changed files?",
+		[]string{"The copy is reused only when the same source directory is transformed into the same output directory again."},
+		false,
+		nil,
+	) {
+		return
+	}
+	dir, err := getSourcesCacheDir(sourcePath, outputPath)
+	if err != nil {
+		logrus.Debugf("The staged sources will not be kept for the next transformation. Error: %q", err)
+		return
+	}
+	previousDir := dir + ".previous"
+	if err := os.RemoveAll(previousDir); err != nil {
+		logrus.Debugf("failed to remove the directory %s . Error: %q", previousDir, err)
+		return
+	}
+	if _, err := os.Stat(dir); err == nil {
+		if err := os.Rename(dir, previousDir); err != nil {
+			logrus.Debugf("failed to keep aside the previously staged sources in %s . Error: %q", dir, err)
+			if err := os.RemoveAll(dir); err != nil {
+				return
+			}
+		} else {
+			stage.previousDir = previousDir
+		}
+	}
+	if err := os.MkdirAll(dir, common.DefaultDirectoryPermission); err != nil {
+		logrus.Debugf("failed to create the directory %s for the staged sources. Error: %q", dir, err)
+		return
+	}
+	stage.dir = dir
+	stage.persistent = true
+	pruneSourcesCache(filepath.Dir(dir), dir)
+}
+
+// getSourcesCacheDir returns the directory the sources of the transformations of the source directory into the output directory are staged in
+func getSourcesCacheDir(sourcePath, outputPath string) (string, error) {
+	absSourcePath, err := filepath.Abs(sourcePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to make the source path %s absolute. Error: %w", sourcePath, err)
+	}
+	absOutputPath, err := filepath.Abs(outputPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to make the output path %s absolute. Error: %w", outputPath, err)
+	}
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get the cache directory. Error: %w", err)
+	}
+	return filepath.Join(cacheDir, types.AppName, "sources", common.GetSHA256Hash(absSourcePath + "\n" + absOutputPath)[:16]), nil
+}
+
+// pruneSourcesCache removes the sources kept for the other transformations, which have not been used for sourcesCacheMaxAge
+func pruneSourcesCache(cacheDir, currentDir string) {
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		logrus.Debugf("failed to read the sources cache directory %s . Error: %q", cacheDir, err)
+		return
+	}
+	for _, entry := range entries {
+		path := filepath.Join(cacheDir, entry.Name())
+		if path == currentDir || path == currentDir+".previous" {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil || time.Since(info.ModTime()) < sourcesCacheMaxAge {
+			continue
+		}
+		if err := os.RemoveAll(path); err != nil {
+			logrus.Debugf("failed to remove the stale sources at %s from the cache. Error: %q", path, err)
+		}
+	}
+}
+
+// getStageDir returns the directory of the staged sources, creating it if required
+func getStageDir() (string, error) {
+	if stage.dir != "" {
+		return stage.dir, nil
+	}
+	dir, err := os.MkdirTemp(common.TempPath, "sources")
+	if err != nil {
+		return "", fmt.Errorf("failed to create the directory for the staged sources. Error: %w", err)
+	}
+	stage.dir = dir
+	return dir, nil
+}
+
+// stageSources copies the source path into the stage. When the destination path is staged for the first time,
+// it replaces the sources staged by the previous transformation, copying only the files which have changed.
+func stageSources(srcPath, destPath, stagedPath string) error {
+	excluded := getSourceExcluder(srcPath)
+	symlinks := getSourceSymlinkPolicy()
+	if stage.syncedDests[destPath] {
+		return filesystem.MergeExcluding(srcPath, stagedPath, true, excluded, symlinks)
+	}
+	stage.syncedDests[destPath] = true
+	if stage.previousDir != "" {
+		previousPath := filepath.Join(stage.previousDir, destPath)
+		if _, err := os.Lstat(stagedPath); os.IsNotExist(err) {
+			if _, err := os.Lstat(previousPath); err == nil {
+				if err := os.MkdirAll(filepath.Dir(stagedPath), common.DefaultDirectoryPermission); err != nil {
+					return fmt.Errorf("failed to create the directory %s . Error: %w", filepath.Dir(stagedPath), err)
+				}
+				if err := os.Rename(previousPath, stagedPath); err != nil {
+					logrus.Debugf("failed to reuse the previously staged sources in %s . Error: %q", previousPath, err)
+				}
+			}
+		}
+	}
+	return filesystem.Sync(srcPath, stagedPath, excluded, symlinks)
+}
+
+// getSourceSymlinkPolicy returns how the symbolic links are copied into the source directory
+func getSourceSymlinkPolicy() filesystem.SymlinkPolicy {
+	return filesystem.SymlinkPolicy(qaengine.FetchSelectAnswer(
+		common.ConfigSourceSymlinksKey,
+		"Select how the symbolic links should be copied into the source directory :",
+		[]string{
+			"preserve: copy the links which point inside the source directory as links, and the files the other ones point to, since the builds cannot access them",
+			"follow: copy the files all the links point to",
+			"skip: leave out the links",
+		},
+		string(filesystem.PreserveSymlinks),
+		[]string{string(filesystem.PreserveSymlinks), string(filesystem.FollowSymlinks), string(filesystem.SkipSymlinks)},
+		nil,
+	))
+}
+
+// resetOutputToStagedSources replaces the contents of the output directory with the staged sources,
+// skipping the files which have not changed since the previous iteration
+func resetOutputToStagedSources(outputPath string) error {
+	stageDir, err := getStageDir()
+	if err != nil {
+		return err
+	}
+	return filesystem.Replicate(stageDir, outputPath)
+}
+
+// writeFailures stores the errors of the destination paths which could not be written to, by the destination path
+var writeFailures = map[string]string{}
+
+// GetWriteFailures returns the destination paths which could not be written to during the transformation, along with the errors
+func GetWriteFailures() map[string]string {
+	return writeFailures
+}
+
+// recordWriteResult records whether the destination path could be written to.
+// The error is returned if the output cannot be written to anymore, like when the disk is full, so that the transformation stops early.
+func recordWriteResult(destPath string, err error) error {
+	if err == nil {
+		delete(writeFailures, destPath)
+		return nil
+	}
+	writeFailures[destPath] = err.Error()
+	if errors.Is(err, syscall.ENOSPC) || errors.Is(err, syscall.ENAMETOOLONG) || errors.Is(err, os.ErrPermission) {
+		return fmt.Errorf("failed to write to the path %s . Error: %w", destPath, err)
+	}
+	return nil
+}
+
 func processPathMappings(pms []transformertypes.PathMapping, sourcePath, outputPath string) error {
 	copiedSourceDests := map[pair]bool{}
 	for _, pm := range pms {
@@ -45,8 +248,27 @@ func processPathMappings(pms []transformertypes.PathMapping, sourcePath, outputP
 		if !filepath.IsAbs(pm.SrcPath) {
 			srcPath = filepath.Join(sourcePath, pm.SrcPath)
 		}
+		stageDir, err := getStageDir()
+		if err != nil {
+			return err
+		}
+		stagedPath := filepath.Join(stageDir, pm.DestPath)
 		destPath := filepath.Join(outputPath, pm.DestPath)
-		if err := filesystem.Merge(srcPath, destPath, true); err != nil {
+		if !stage.staged[getpair(srcPath, pm.DestPath)] {
+			if err := stageSources(srcPath, pm.DestPath, stagedPath); err != nil {
+				logrus.Errorf("failed to copy the source path %s the the destination path %s for the path mapping %+v . Error: %q", srcPath, stagedPath, pm, err)
+				if err := recordWriteResult(destPath, err); err != nil {
+					return err
+				}
+				continue
+			}
+			stage.staged[getpair(srcPath, pm.DestPath)] = true
+		}
+		err = filesystem.Merge(stagedPath, destPath, true)
+		if err := recordWriteResult(destPath, err); err != nil {
+			return err
+		}
+		if err != nil {
 			logrus.Errorf("failed to copy the source path %s the the destination path %s for the path mapping %+v . Error: %q", srcPath, destPath, pm, err)
 			continue
 		}
@@ -58,32 +280,43 @@ func processPathMappings(pms []transformertypes.PathMapping, sourcePath, outputP
 		if !filepath.IsAbs(pm.DestPath) {
 			destPath = filepath.Join(outputPath, pm.DestPath)
 		}
+		var err error
 		switch strings.ToLower(string(pm.Type)) {
 		case strings.ToLower(string(transformertypes.SourcePathMappingType)): // skip sources
+			continue
 		case strings.ToLower(string(transformertypes.DeletePathMappingType)): // skip deletes
+			continue
 		case strings.ToLower(string(transformertypes.ModifiedSourcePathMappingType)):
-			if err := filesystem.Merge(pm.SrcPath, destPath, false); err != nil {
+			if err = filesystem.Merge(pm.SrcPath, destPath, false); err != nil {
 				logrus.Errorf("Error while copying sourcepath for %+v . Error: %q", pm, err)
 			}
 		case strings.ToLower(string(transformertypes.TemplatePathMappingType)):
-			if err := filesystem.TemplateCopy(pm.SrcPath, destPath,
+			if err = filesystem.TemplateCopy(pm.SrcPath, destPath,
 				filesystem.AddOnConfig{Config: pm.TemplateConfig}); err != nil {
 				logrus.Errorf("Error while copying sourcepath for %+v . Error: %q", pm, err)
+			} else if err := rewriteGeneratedDockerfiles(pm.SrcPath, destPath); err != nil {
+				logrus.Errorf("failed to rewrite the Dockerfiles generated for %+v . Error: %q", pm, err)
 			}
 		case strings.ToLower(string(transformertypes.SpecialTemplatePathMappingType)):
-			if err := filesystem.TemplateCopy(pm.SrcPath, destPath,
+			if err = filesystem.TemplateCopy(pm.SrcPath, destPath,
 				filesystem.AddOnConfig{OpeningDelimiter: filesystem.SpecialOpeningDelimiter,
 					ClosingDelimiter: filesystem.SpecialClosingDelimiter,
 					Config:           pm.TemplateConfig}); err != nil {
 				logrus.Errorf("Error while copying sourcepath for %+v . Error: %q", pm, err)
+			} else if err := rewriteGeneratedDockerfiles(pm.SrcPath, destPath); err != nil {
+				logrus.Errorf("failed to rewrite the Dockerfiles generated for %+v . Error: %q", pm, err)
 			}
 		default:
-			if !copiedDefaultDests[getpair(pm.SrcPath, pm.DestPath)] {
-				if err := filesystem.Merge(pm.SrcPath, destPath, false); err != nil {
-					logrus.Errorf("Error while copying sourcepath for %+v . Error: %q", pm, err)
-				}
-				copiedDefaultDests[getpair(pm.SrcPath, pm.DestPath)] = true
+			if copiedDefaultDests[getpair(pm.SrcPath, pm.DestPath)] {
+				continue
+			}
+			if err = filesystem.Merge(pm.SrcPath, destPath, false); err != nil {
+				logrus.Errorf("Error while copying sourcepath for %+v . Error: %q", pm, err)
 			}
+			copiedDefaultDests[getpair(pm.SrcPath, pm.DestPath)] = true
+		}
+		if err := recordWriteResult(destPath, err); err != nil {
+			return err
 		}
 	}
 
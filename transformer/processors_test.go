@@ -0,0 +1,166 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/filesystem"
+	"github.com/konveyor/move2kube/qaengine"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+)
+
+func TestMergeReturnsTheWriteFailures(t *testing.T) {
+	sourcePath := t.TempDir()
+	destPath := t.TempDir()
+	for _, path := range []string{"top.txt", "sub/nested.txt", "sub/ok.txt"} {
+		path = filepath.Join(sourcePath, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create the source directory. Error: %q", err)
+		}
+		if err := os.WriteFile(path, []byte("contents"), 0644); err != nil {
+			t.Fatalf("failed to write the source file. Error: %q", err)
+		}
+	}
+	// the files can not be written since there are directories at their destination paths
+	for _, path := range []string{"top.txt/keep", "sub/nested.txt/keep"} {
+		if err := os.MkdirAll(filepath.Join(destPath, filepath.FromSlash(path)), 0755); err != nil {
+			t.Fatalf("failed to create the destination directory. Error: %q", err)
+		}
+	}
+
+	err := filesystem.Merge(sourcePath, destPath, false)
+	if err == nil {
+		t.Fatalf("expected the merge to return the errors of the files which could not be written")
+	}
+	for _, path := range []string{"top.txt", filepath.Join("sub", "nested.txt")} {
+		if !strings.Contains(err.Error(), filepath.Join(destPath, path)) {
+			t.Fatalf("expected the error of the path %s to be returned, got %q", path, err)
+		}
+	}
+	if !errors.Is(err, syscall.EISDIR) {
+		t.Fatalf("expected the cause of the failure to be kept, so that the errors which stop the transformation are detected. Error: %q", err)
+	}
+	if _, err := os.Stat(filepath.Join(destPath, "sub", "ok.txt")); err != nil {
+		t.Fatalf("expected the other files to be written. Error: %q", err)
+	}
+
+	writeFailures = map[string]string{}
+	if err := recordWriteResult(destPath, err); err != nil {
+		t.Fatalf("expected the transformation to continue after the files could not be written. Error: %q", err)
+	}
+	if GetWriteFailures()[destPath] == "" {
+		t.Fatalf("expected the write failure of the path %s to be recorded, got %+v", destPath, GetWriteFailures())
+	}
+	if err := recordWriteResult(destPath, nil); err != nil || len(GetWriteFailures()) != 0 {
+		t.Fatalf("expected the write failure to be cleared once the path is written, got %+v . Error: %v", GetWriteFailures(), err)
+	}
+}
+
+func TestInitStageKeepsTheSourcesOnlyWhenOptedIn(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	sourcePath, otherSourcePath, outputPath := t.TempDir(), t.TempDir(), t.TempDir()
+	setup := func(configs ...string) {
+		qaengine.Reset()
+		qaengine.AddEngine(qaengine.NewDefaultEngine())
+		qaengine.SetupConfigFile("", configs, nil, nil, false)
+	}
+	defer qaengine.Reset()
+	defer resetStage()
+
+	setup()
+	initStage(sourcePath, outputPath)
+	if stage.persistent || stage.dir != "" {
+		t.Fatalf("expected the sources not to be kept by default, got the stage %+v", stage)
+	}
+
+	setup(common.ConfigSourceCacheKey + "=true")
+	initStage(sourcePath, outputPath)
+	if !stage.persistent {
+		t.Fatalf("expected the sources to be kept when opted in")
+	}
+	dir := stage.dir
+	initStage(otherSourcePath, outputPath)
+	if stage.dir == dir {
+		t.Fatalf("expected the sources of the other source directory to be kept in another directory than %s", dir)
+	}
+	otherDir := stage.dir
+
+	// the sources which have not been used for a long time are removed
+	oldTime := time.Now().Add(-2 * sourcesCacheMaxAge)
+	if err := os.Chtimes(otherDir, oldTime, oldTime); err != nil {
+		t.Fatalf("failed to change the time of the directory %s . Error: %q", otherDir, err)
+	}
+	initStage(sourcePath, outputPath)
+	if stage.dir != dir {
+		t.Fatalf("expected the sources to be kept in the same directory %s , got %s", dir, stage.dir)
+	}
+	if _, err := os.Stat(otherDir); !os.IsNotExist(err) {
+		t.Fatalf("expected the stale sources in %s to be removed", otherDir)
+	}
+}
+
+func TestProcessPathMappingsCopiesTheSourcesOnce(t *testing.T) {
+	tempPath := common.TempPath
+	common.TempPath = t.TempDir()
+	defer func() { common.TempPath = tempPath }()
+	qaengine.Reset()
+	defer qaengine.Reset()
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	defer resetStage()
+	sourcePath, outputPath := t.TempDir(), t.TempDir()
+	if err := os.WriteFile(filepath.Join(sourcePath, "app.txt"), []byte("original"), 0644); err != nil {
+		t.Fatalf("failed to write the source file. Error: %q", err)
+	}
+	initStage(sourcePath, outputPath)
+	pms := []transformertypes.PathMapping{{Type: transformertypes.SourcePathMappingType, DestPath: common.DefaultSourceDir}}
+	if err := processPathMappings(pms, sourcePath, outputPath); err != nil {
+		t.Fatalf("failed to process the path mappings. Error: %q", err)
+	}
+	outputFile := filepath.Join(outputPath, common.DefaultSourceDir, "app.txt")
+	if contents, err := os.ReadFile(outputFile); err != nil || string(contents) != "original" {
+		t.Fatalf("expected the source to be copied to the output. Contents: %q Error: %v", contents, err)
+	}
+
+	// the next iteration starts from the staged sources, without copying the source directory again
+	if err := os.WriteFile(filepath.Join(outputPath, common.DefaultSourceDir, "generated.txt"), []byte("generated"), 0644); err != nil {
+		t.Fatalf("failed to write the generated file. Error: %q", err)
+	}
+	if err := os.WriteFile(filepath.Join(sourcePath, "app.txt"), []byte("changed"), 0644); err != nil {
+		t.Fatalf("failed to change the source file. Error: %q", err)
+	}
+	if err := resetOutputToStagedSources(outputPath); err != nil {
+		t.Fatalf("failed to reset the output to the staged sources. Error: %q", err)
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, common.DefaultSourceDir, "generated.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected the files generated in the previous iteration to be removed. Error: %v", err)
+	}
+	if err := processPathMappings(pms, sourcePath, outputPath); err != nil {
+		t.Fatalf("failed to process the path mappings. Error: %q", err)
+	}
+	if contents, err := os.ReadFile(outputFile); err != nil || string(contents) != "original" {
+		t.Fatalf("expected the staged copy of the source to be used. Contents: %q Error: %v", contents, err)
+	}
+}
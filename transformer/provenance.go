@@ -0,0 +1,224 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/konveyor/move2kube/types"
+	"github.com/konveyor/move2kube/types/info"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ProvenanceFile is the name of the in-toto statement describing how the output files were generated
+	ProvenanceFile = types.AppNameShort + "-provenance.json"
+	// inTotoStatementType and slsaProvenancePredicateType are the types of the statement and the SLSA provenance predicate
+	inTotoStatementType         = "https://in-toto.io/Statement/v0.1"
+	slsaProvenancePredicateType = "https://slsa.dev/provenance/v0.2"
+	provenanceBuildType         = "https://" + types.GroupName + "/transform@v1"
+)
+
+// Provenance is an in-toto statement with a SLSA provenance predicate
+type Provenance struct {
+	Type          string              `json:"_type"`
+	Subject       []ProvenanceSubject `json:"subject"`
+	PredicateType string              `json:"predicateType"`
+	Predicate     ProvenancePredicate `json:"predicate"`
+}
+
+// ProvenanceSubject is an output file along with its digest
+type ProvenanceSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// ProvenancePredicate describes the tool, the inputs and the configuration used for the transformation
+type ProvenancePredicate struct {
+	Builder     ProvenanceBuilder     `json:"builder"`
+	BuildType   string                `json:"buildType"`
+	Invocation  ProvenanceInvocation  `json:"invocation"`
+	BuildConfig ProvenanceBuildConfig `json:"buildConfig"`
+	Metadata    ProvenanceMetadata    `json:"metadata"`
+	Materials   []ProvenanceMaterial  `json:"materials"`
+}
+
+// ProvenanceBuilder identifies the version of the tool
+type ProvenanceBuilder struct {
+	ID      string           `json:"id"`
+	Version info.VersionInfo `json:"version"`
+}
+
+// ProvenanceInvocation stores the digest of the answers to the questions
+type ProvenanceInvocation struct {
+	Parameters ProvenanceParameters `json:"parameters"`
+}
+
+// ProvenanceParameters stores the parameters of the transformation
+type ProvenanceParameters struct {
+	ProjectName string `json:"projectName"`
+	// QAAnswersSHA256 is the digest of the answers given during the transformation, excluding the passwords
+	QAAnswersSHA256 string `json:"qaAnswersSha256"`
+}
+
+// ProvenanceBuildConfig stores the transformers which generated each of the output files
+type ProvenanceBuildConfig struct {
+	Transformers []string                 `json:"transformers"`
+	Files        []ProvenanceFileProducer `json:"files"`
+}
+
+// ProvenanceFileProducer is an output file and the transformer which generated it
+type ProvenanceFileProducer struct {
+	Path        string `json:"path"`
+	Transformer string `json:"transformer,omitempty"`
+}
+
+// ProvenanceMetadata stores the timestamps of the transformation
+type ProvenanceMetadata struct {
+	BuildStartedOn  time.Time `json:"buildStartedOn"`
+	BuildFinishedOn time.Time `json:"buildFinishedOn"`
+	Reproducible    bool      `json:"reproducible"`
+}
+
+// ProvenanceMaterial is an input file, either a source file or a file of a custom transformer
+type ProvenanceMaterial struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+// writeProvenance writes the provenance of the files listed in the output manifest
+func writeProvenance(outputPath, sourceDir string, startedOn time.Time) error {
+	subjects := []ProvenanceSubject{}
+	files := []ProvenanceFileProducer{}
+	manifestPath := filepath.Join(outputPath, OutputManifestFile)
+	manifestData, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to read the output manifest at path %s . Error: %w", manifestPath, err)
+	}
+	manifest := OutputManifest{}
+	if err := json.Unmarshal(manifestData, &manifest); err != nil {
+		return fmt.Errorf("failed to unmarshal the output manifest at path %s . Error: %w", manifestPath, err)
+	}
+	transformers := []string{}
+	for _, entry := range manifest.Files {
+		subjects = append(subjects, ProvenanceSubject{Name: entry.Path, Digest: map[string]string{"sha256": entry.SHA256}})
+		files = append(files, ProvenanceFileProducer{Path: entry.Path, Transformer: entry.Transformer})
+		if entry.Transformer != "" {
+			transformers = common.AppendIfNotPresent(transformers, entry.Transformer)
+		}
+	}
+	if _, manifestChecksum, err := getFileSizeAndChecksum(manifestPath); err == nil {
+		subjects = append(subjects, ProvenanceSubject{Name: OutputManifestFile, Digest: map[string]string{"sha256": manifestChecksum}})
+	}
+	sort.Strings(transformers)
+	materials := []ProvenanceMaterial{}
+	if sourceDir != "" {
+		materials = append(materials, getProvenanceMaterials(sourceDir, "source")...)
+	}
+	materials = append(materials, getCustomTransformerMaterials()...)
+	provenance := Provenance{
+		Type:          inTotoStatementType,
+		Subject:       subjects,
+		PredicateType: slsaProvenancePredicateType,
+		Predicate: ProvenancePredicate{
+			Builder:    ProvenanceBuilder{ID: "https://" + types.GroupName + "/" + types.AppName + "@" + info.GetVersion(), Version: info.GetVersionInfo()},
+			BuildType:  provenanceBuildType,
+			Invocation: ProvenanceInvocation{Parameters: ProvenanceParameters{ProjectName: common.ProjectName, QAAnswersSHA256: qaengine.GetAnswersDigest()}},
+			BuildConfig: ProvenanceBuildConfig{
+				Transformers: transformers,
+				Files:        files,
+			},
+			Metadata:  ProvenanceMetadata{BuildStartedOn: startedOn.UTC(), BuildFinishedOn: time.Now().UTC()},
+			Materials: materials,
+		},
+	}
+	data, err := json.MarshalIndent(provenance, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the provenance to json. Error: %w", err)
+	}
+	provenancePath := filepath.Join(outputPath, ProvenanceFile)
+	if err := os.WriteFile(provenancePath, data, common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the provenance to the file at path %s . Error: %w", provenancePath, err)
+	}
+	return nil
+}
+
+// getProvenanceMaterials returns the digests of the files in the directory, skipping the hidden directories like .git
+func getProvenanceMaterials(dir, uriPrefix string) []ProvenanceMaterial {
+	materials := []ProvenanceMaterial{}
+	err := filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path == dir {
+				return nil
+			}
+			for _, dirRegExp := range common.DefaultIgnoreDirRegexps {
+				if dirRegExp.MatchString(d.Name()) {
+					return filepath.SkipDir
+				}
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		_, checksum, err := getFileSizeAndChecksum(path)
+		if err != nil {
+			logrus.Debugf("Skipping the file %s in the provenance. Error: %q", path, err)
+			return nil
+		}
+		materials = append(materials, ProvenanceMaterial{URI: uriPrefix + ":" + filepath.ToSlash(relPath), Digest: map[string]string{"sha256": checksum}})
+		return nil
+	})
+	if err != nil {
+		logrus.Errorf("failed to get the digests of the files in the directory %s for the provenance. Error: %q", dir, err)
+	}
+	return materials
+}
+
+// getCustomTransformerMaterials returns the digests of the files of the custom transformers which were used, like the Starlark and ytt scripts
+func getCustomTransformerMaterials() []ProvenanceMaterial {
+	customAssetsPath := filepath.Join(common.AssetsPath, "custom")
+	transformerNames := []string{}
+	for name := range transformerMap {
+		transformerNames = append(transformerNames, name)
+	}
+	sort.Strings(transformerNames)
+	materials := []ProvenanceMaterial{}
+	for _, name := range transformerNames {
+		tconfig, _ := transformerMap[name].GetConfig()
+		transformerDir := filepath.Dir(tconfig.Spec.TransformerYamlPath)
+		if !common.IsParent(transformerDir, customAssetsPath) {
+			continue
+		}
+		materials = append(materials, getProvenanceMaterials(transformerDir, "transformer:"+name)...)
+	}
+	return materials
+}
@@ -0,0 +1,110 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+)
+
+func TestWriteProvenance(t *testing.T) {
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	transformers := transformerMap
+	transformerMap = map[string]Transformer{}
+	t.Cleanup(func() { transformerMap = transformers })
+	sha256Of := func(contents string) string { return fmt.Sprintf("%x", sha256.Sum256([]byte(contents))) }
+	writeFiles := func(dir string, files map[string]string) {
+		for path, contents := range files {
+			path = filepath.Join(dir, filepath.FromSlash(path))
+			if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+				t.Fatalf("failed to create the directory of %s . Error: %q", path, err)
+			}
+			if err := os.WriteFile(path, []byte(contents), common.DefaultFilePermission); err != nil {
+				t.Fatalf("failed to write the file %s . Error: %q", path, err)
+			}
+		}
+	}
+	outputPath, sourceDir := t.TempDir(), t.TempDir()
+	writeFiles(sourceDir, map[string]string{
+		"app/main.go":    "package main\n",
+		"manifest.yml":   "applications: []\n",
+		".git/HEAD":      "ref: refs/heads/main\n",
+		"app/.cache/obj": "cached",
+	})
+	manifest := OutputManifest{Files: []OutputManifestEntry{
+		{Path: "deploy/yamls/api-deployment.yaml", SHA256: sha256Of("kind: Deployment\n"), Transformer: "Kubernetes"},
+		{Path: "deploy/cicd/pipeline.yaml", SHA256: sha256Of("kind: Pipeline\n"), Transformer: "Tekton"},
+		{Path: "deploy/yamls/api-service.yaml", SHA256: sha256Of("kind: Service\n"), Transformer: "Kubernetes"},
+		{Path: TransformationReportJSONFile, SHA256: sha256Of("{}")},
+	}}
+	manifestData, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("failed to marshal the output manifest. Error: %q", err)
+	}
+	writeFiles(outputPath, map[string]string{OutputManifestFile: string(manifestData)})
+
+	startedOn := time.Now().Add(-time.Minute)
+	if err := writeProvenance(outputPath, sourceDir, startedOn); err != nil {
+		t.Fatalf("failed to write the provenance. Error: %q", err)
+	}
+	data, err := os.ReadFile(filepath.Join(outputPath, ProvenanceFile))
+	if err != nil {
+		t.Fatalf("failed to read the provenance. Error: %q", err)
+	}
+	provenance := Provenance{}
+	if err := json.Unmarshal(data, &provenance); err != nil {
+		t.Fatalf("failed to parse the provenance. Error: %q", err)
+	}
+	if provenance.Type != inTotoStatementType || provenance.PredicateType != slsaProvenancePredicateType {
+		t.Fatalf("expected an in-toto statement with a SLSA provenance, got the types %s %s", provenance.Type, provenance.PredicateType)
+	}
+	wantSubjects := []ProvenanceSubject{}
+	for _, entry := range manifest.Files {
+		wantSubjects = append(wantSubjects, ProvenanceSubject{Name: entry.Path, Digest: map[string]string{"sha256": entry.SHA256}})
+	}
+	wantSubjects = append(wantSubjects, ProvenanceSubject{Name: OutputManifestFile, Digest: map[string]string{"sha256": sha256Of(string(manifestData))}})
+	if diff := cmp.Diff(wantSubjects, provenance.Subject); diff != "" {
+		t.Fatalf("the subjects are different. Difference:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"Kubernetes", "Tekton"}, provenance.Predicate.BuildConfig.Transformers); diff != "" {
+		t.Fatalf("the transformers are different. Difference:\n%s", diff)
+	}
+	wantMaterials := []ProvenanceMaterial{
+		{URI: "source:app/main.go", Digest: map[string]string{"sha256": sha256Of("package main\n")}},
+		{URI: "source:manifest.yml", Digest: map[string]string{"sha256": sha256Of("applications: []\n")}},
+	}
+	if diff := cmp.Diff(wantMaterials, provenance.Predicate.Materials); diff != "" {
+		t.Fatalf("expected the source files without the hidden directories as the materials. Difference:\n%s", diff)
+	}
+	if provenance.Predicate.Invocation.Parameters.QAAnswersSHA256 != qaengine.GetAnswersDigest() {
+		t.Fatalf("expected the digest of the answers %s , got %s", qaengine.GetAnswersDigest(), provenance.Predicate.Invocation.Parameters.QAAnswersSHA256)
+	}
+	metadata := provenance.Predicate.Metadata
+	if !metadata.BuildStartedOn.Equal(startedOn) || metadata.BuildFinishedOn.Before(startedOn) {
+		t.Fatalf("expected the transformation to start at %s and finish after it, got %+v", startedOn, metadata)
+	}
+}
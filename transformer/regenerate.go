@@ -0,0 +1,211 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
+	"github.com/konveyor/move2kube/transformer/kubernetes/irpreprocessor"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	graphtypes "github.com/konveyor/move2kube/types/graph"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
+)
+
+// GetExportedIRFilePaths returns the paths of the IR files exported by the IRExporter into the output directory
+func GetExportedIRFilePaths(outputPath string) ([]string, error) {
+	irDir := filepath.Join(outputPath, defaultIRExportOutputPath)
+	filePaths, err := common.GetFilesByExtInCurrDir(irDir, []string{".yaml", ".yml", ".json"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the exported IR files in the directory %s . Error: %w", irDir, err)
+	}
+	return filePaths, nil
+}
+
+// Regenerate runs only the transformer on the IR and rewrites the files it generated in the output directory.
+// The services are not transformed again and the sources are not copied again, the other files in the output directory are kept as is.
+func Regenerate(ctx context.Context, transformerName string, ir irtypes.IR, sourceDir, outputPath string) error {
+	t, err := GetTransformerByName(transformerName)
+	if err != nil {
+		return fmt.Errorf("failed to find the transformer %s in the initialized transformers. Error: %w", transformerName, err)
+	}
+	startedOn := time.Now()
+	tConfig, env := t.GetConfig()
+	if cs, ok := tConfig.Spec.ConsumedArtifacts[irtypes.IRArtifactType]; !ok || cs.Disabled {
+		return fmt.Errorf("the transformer %s can not be regenerated, since it does not consume the %s artifacts", transformerName, irtypes.IRArtifactType)
+	}
+	transformationFailures = []ReportFailure{}
+	apiresource.ResetIgnoredObjects()
+	apiresource.ResetClusterFeatureDecisions()
+	irpreprocessor.ResetImageDigests()
+	k8sschema.ResetCustomResourceValidation()
+	defer resetStage()
+	keptHandAuthoredFiles = map[string]bool{}
+	pathMappingProducers = []pathMappingProducer{}
+	writeFailures = map[string]string{}
+	previousManifest := readOutputManifest(outputPath)
+	modifiedFiles := removePreviousOutput(outputPath, transformerName, previousManifest)
+	graph := graphtypes.NewGraph()
+	startVertexId := graph.AddVertex("start", 1, nil)
+	irArtifact := transformertypes.Artifact{
+		Name:    ir.Name,
+		Type:    irtypes.IRArtifactType,
+		Configs: map[transformertypes.ConfigType]interface{}{irtypes.IRConfigType: ir, graphtypes.GraphSourceVertexKey: startVertexId},
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("the regeneration was stopped. Error: %w", err)
+	}
+	logrus.Infof("Regenerating the output of the transformer %s", transformerName)
+	allArtifacts := []transformertypes.Artifact{irArtifact}
+	// the transformers it depends on, like the cluster selector, add the configs it needs to the IR
+	dependencyPathMappings, _, dependencyUpdatedArtifacts := transform(ctx, allArtifacts, allArtifacts, dependency, tConfig.Spec.DependencySelector, graph, 1)
+	if len(dependencyPathMappings) != 0 {
+		logrus.Debugf("Skipping the %d path mappings created by the dependencies of the transformer %s , since only its output is regenerated", len(dependencyPathMappings), transformerName)
+	}
+	artifactsToConsume, _ := getArtifactsToProcess(dependencyUpdatedArtifacts, allArtifacts, tConfig, consume)
+	pathMappings, newArtifacts, err := runSingleTransform(ctx, artifactsToConsume, allArtifacts, t, tConfig, env, graph, 1)
+	if err != nil {
+		recordTransformationFailure(transformerName, 1, err)
+		return &TransformationFailedError{Failures: transformationFailures}
+	}
+	generatedPathMappings := []transformertypes.PathMapping{}
+	for _, pathMapping := range pathMappings {
+		if strings.EqualFold(string(pathMapping.Type), string(transformertypes.SourcePathMappingType)) {
+			logrus.Debugf("Skipping the copy of the source %s , since the sources are not copied again during the regeneration", pathMapping.SrcPath)
+			continue
+		}
+		generatedPathMappings = append(generatedPathMappings, pathMapping)
+	}
+	if err := processPathMappings(generatedPathMappings, sourceDir, outputPath); err != nil {
+		return fmt.Errorf("failed to process the path mappings: %+v . Error: %q", generatedPathMappings, err)
+	}
+	restoreModifiedFiles(outputPath, modifiedFiles)
+	if len(newArtifacts) != 0 {
+		logrus.Infof("The %d artifacts created by the transformer %s are not transformed further, since only its output is regenerated", len(newArtifacts), transformerName)
+	}
+	if err := encryptSecretManifests(outputPath); err != nil {
+		logrus.Errorf("failed to encrypt the Secret manifests. Error: %q", err)
+	}
+	// the provenance describes the previous output, it is written again after the output manifest
+	if err := os.Remove(filepath.Join(outputPath, ProvenanceFile)); err != nil && !os.IsNotExist(err) {
+		logrus.Errorf("failed to remove the previous provenance. Error: %q", err)
+	}
+	if err := updateOutputManifest(outputPath, previousManifest, modifiedFiles); err != nil {
+		logrus.Errorf("failed to update the output manifest. Error: %q", err)
+	} else if err := writeProvenance(outputPath, sourceDir, startedOn); err != nil {
+		logrus.Errorf("failed to write the provenance. Error: %q", err)
+	}
+	return nil
+}
+
+// readOutputManifest reads the output manifest written by the previous transformation into the output directory
+func readOutputManifest(outputPath string) OutputManifest {
+	manifest := OutputManifest{}
+	data, err := os.ReadFile(filepath.Join(outputPath, OutputManifestFile))
+	if err != nil {
+		logrus.Warnf("The output manifest was not found in the output directory %s . The files generated previously by the transformer can not be removed.", outputPath)
+		return manifest
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		logrus.Warnf("failed to unmarshal the output manifest in the directory %s . Error: %q", outputPath, err)
+	}
+	return manifest
+}
+
+// removePreviousOutput removes the files generated by the transformer in the previous transformation, so that the files it no longer generates do not remain.
+// The files modified since then are kept and their contents are returned, so that they can be restored after the regeneration.
+func removePreviousOutput(outputPath, transformerName string, manifest OutputManifest) map[string][]byte {
+	modifiedFiles := map[string][]byte{}
+	for _, entry := range manifest.Files {
+		if entry.Transformer != transformerName || entry.HandAuthored {
+			continue
+		}
+		path := filepath.Join(outputPath, filepath.FromSlash(entry.Path))
+		_, checksum, err := getFileSizeAndChecksum(path)
+		if err != nil {
+			continue
+		}
+		if checksum != entry.SHA256 {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				logrus.Errorf("failed to read the modified file %s . Error: %q", entry.Path, err)
+				continue
+			}
+			logrus.Warnf("Keeping the file %s , since it was modified after it was generated by the transformer %s", entry.Path, transformerName)
+			modifiedFiles[entry.Path] = data
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			logrus.Errorf("failed to remove the file %s generated previously by the transformer %s . Error: %q", entry.Path, transformerName, err)
+		}
+	}
+	return modifiedFiles
+}
+
+// restoreModifiedFiles writes back the modified files overwritten by the regeneration
+func restoreModifiedFiles(outputPath string, modifiedFiles map[string][]byte) {
+	for relPath, data := range modifiedFiles {
+		path := filepath.Join(outputPath, filepath.FromSlash(relPath))
+		if err := os.WriteFile(path, data, common.DefaultFilePermission); err != nil {
+			logrus.Errorf("failed to restore the modified file %s . Error: %q", relPath, err)
+		}
+	}
+}
+
+// updateOutputManifest writes the output manifest again, keeping the producers of the files which did not change.
+// The entries of the modified files are kept as is, so that they are still found to be modified by the next regeneration.
+func updateOutputManifest(outputPath string, previousManifest OutputManifest, modifiedFiles map[string][]byte) error {
+	if err := writeOutputManifest(outputPath); err != nil {
+		return err
+	}
+	manifest := readOutputManifest(outputPath)
+	previousEntries := map[string]OutputManifestEntry{}
+	for _, entry := range previousManifest.Files {
+		previousEntries[entry.Path] = entry
+	}
+	for i, entry := range manifest.Files {
+		previousEntry, ok := previousEntries[entry.Path]
+		if _, modified := modifiedFiles[entry.Path]; ok && modified {
+			manifest.Files[i] = previousEntry
+			continue
+		}
+		// the path mappings of the transformer can contain the files of the other transformers
+		if !ok || (entry.Transformer != "" && entry.SHA256 != previousEntry.SHA256) {
+			continue
+		}
+		manifest.Files[i].Transformer = previousEntry.Transformer
+		manifest.Files[i].HandAuthored = previousEntry.HandAuthored
+	}
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the output manifest to json. Error: %w", err)
+	}
+	manifestPath := filepath.Join(outputPath, OutputManifestFile)
+	if err := os.WriteFile(manifestPath, data, common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the output manifest to the file at path %s . Error: %w", manifestPath, err)
+	}
+	return nil
+}
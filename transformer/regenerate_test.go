@@ -0,0 +1,85 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRegenerateOutputFiles(t *testing.T) {
+	outputPath := t.TempDir()
+	writeFile := func(relPath, contents string) {
+		path := filepath.Join(outputPath, filepath.FromSlash(relPath))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create the directory. Error: %q", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write the file. Error: %q", err)
+		}
+	}
+	writeFile("deploy/cicd/tekton/pipeline.yaml", "kind: Pipeline")
+	writeFile("deploy/cicd/tekton/ingress.yaml", "kind: Ingress")
+	writeFile("deploy/yamls/web-deployment.yaml", "kind: Deployment")
+	keptHandAuthoredFiles = map[string]bool{}
+	pathMappingProducers = []pathMappingProducer{}
+	if err := writeOutputManifest(outputPath); err != nil {
+		t.Fatalf("failed to write the output manifest. Error: %q", err)
+	}
+	manifest := readOutputManifest(outputPath)
+	for i, entry := range manifest.Files {
+		if entry.Path == "deploy/yamls/web-deployment.yaml" {
+			manifest.Files[i].Transformer = "Kubernetes"
+		} else {
+			manifest.Files[i].Transformer = "Tekton"
+		}
+	}
+
+	// the user modified one of the files generated by Tekton
+	writeFile("deploy/cicd/tekton/ingress.yaml", "kind: Ingress\n# edited")
+	modifiedFiles := removePreviousOutput(outputPath, "Tekton", manifest)
+	if _, err := os.Stat(filepath.Join(outputPath, "deploy", "cicd", "tekton", "pipeline.yaml")); !os.IsNotExist(err) {
+		t.Fatalf("expected the unmodified file generated by Tekton to be removed")
+	}
+	if _, err := os.Stat(filepath.Join(outputPath, "deploy", "yamls", "web-deployment.yaml")); err != nil {
+		t.Fatalf("expected the file generated by Kubernetes to be kept. Error: %q", err)
+	}
+	if len(modifiedFiles) != 1 || string(modifiedFiles["deploy/cicd/tekton/ingress.yaml"]) != "kind: Ingress\n# edited" {
+		t.Fatalf("expected only the modified file to be returned, got %+v", modifiedFiles)
+	}
+
+	// the regeneration writes the files again
+	writeFile("deploy/cicd/tekton/pipeline.yaml", "kind: Pipeline")
+	writeFile("deploy/cicd/tekton/ingress.yaml", "kind: Ingress")
+	restoreModifiedFiles(outputPath, modifiedFiles)
+	if data, err := os.ReadFile(filepath.Join(outputPath, "deploy", "cicd", "tekton", "ingress.yaml")); err != nil || string(data) != "kind: Ingress\n# edited" {
+		t.Fatalf("expected the modified file to be restored, got %q. Error: %v", string(data), err)
+	}
+	if err := updateOutputManifest(outputPath, manifest, modifiedFiles); err != nil {
+		t.Fatalf("failed to update the output manifest. Error: %q", err)
+	}
+	previousEntries := map[string]OutputManifestEntry{}
+	for _, entry := range manifest.Files {
+		previousEntries[entry.Path] = entry
+	}
+	for _, entry := range readOutputManifest(outputPath).Files {
+		if entry != previousEntries[entry.Path] {
+			t.Fatalf("expected the entry of the file %s to be kept, want %+v, got %+v", entry.Path, previousEntries[entry.Path], entry)
+		}
+	}
+}
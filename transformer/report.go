@@ -0,0 +1,550 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/transformer/dockerfile"
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
+	"github.com/konveyor/move2kube/transformer/kubernetes/irpreprocessor"
+	"github.com/konveyor/move2kube/types"
+	irtypes "github.com/konveyor/move2kube/types/ir"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/konveyor/move2kube/types/transformer/artifacts"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// TransformationReportJSONFile is the name of the machine readable transformation report
+	TransformationReportJSONFile = types.AppNameShort + "-report.json"
+	// TransformationReportMarkdownFile is the name of the human readable transformation report
+	TransformationReportMarkdownFile = types.AppNameShort + "-report.md"
+)
+
+// TransformationReport summarizes the transformation
+type TransformationReport struct {
+	Services        []ReportService  `yaml:"services" json:"services"`
+	BuiltImages     []string         `yaml:"builtImages" json:"builtImages"`
+	ReusedImages    []string         `yaml:"reusedImages" json:"reusedImages"`
+	ResourcesByKind map[string]int   `yaml:"resourcesByKind" json:"resourcesByKind"`
+	Skipped         []string         `yaml:"skipped" json:"skipped"`
+	ManualSteps     []ReportTODOItem `yaml:"manualSteps" json:"manualSteps"`
+	// StableDNSNames are the DNS names of the pods of the StatefulSets, which the clustered workloads use to find their peers
+	StableDNSNames []ReportStableDNSName `yaml:"stableDNSNames" json:"stableDNSNames"`
+	// HostSubstitutions are the host names and IP addresses of the services which were replaced with the in-cluster DNS names of their services
+	HostSubstitutions []irpreprocessor.HostSubstitution `yaml:"hostSubstitutions" json:"hostSubstitutions"`
+	// HealthProbes are the services which had no probes and the probes added to them until they have a health endpoint
+	HealthProbes []irpreprocessor.HealthProbeScaffold `yaml:"healthProbes" json:"healthProbes"`
+	// ImageBuildOrder is the order the new images are built in, since some of them are the base images of others
+	ImageBuildOrder []dockerfile.ImageBuildDependency `yaml:"imageBuildOrder" json:"imageBuildOrder"`
+	// DockerfileFindings are the issues found in the Dockerfiles present in the source directory
+	DockerfileFindings []artifacts.DockerfileLintFinding `yaml:"dockerfileFindings" json:"dockerfileFindings"`
+	// ClusterFeatures are the optional features which were generated or not, depending on the capabilities of the target clusters
+	ClusterFeatures []apiresource.ClusterFeatureDecision `yaml:"clusterFeatures" json:"clusterFeatures"`
+	Failures        []ReportFailure                      `yaml:"failures" json:"failures"`
+	Warnings        []string                             `yaml:"warnings" json:"warnings"`
+	Errors          []string                             `yaml:"errors" json:"errors"`
+}
+
+// ReportFailure is a transformation that failed. The transformation continues with the other transformers.
+type ReportFailure struct {
+	Transformer string `yaml:"transformer" json:"transformer"`
+	Iteration   int    `yaml:"iteration" json:"iteration"`
+	Error       string `yaml:"error" json:"error"`
+}
+
+// TransformationFailedError is returned by Transform when some of the transformations failed.
+// The output of the other transformations and the report are still written.
+type TransformationFailedError struct {
+	Failures []ReportFailure
+}
+
+// Error returns the error message
+func (e *TransformationFailedError) Error() string {
+	names := []string{}
+	for _, failure := range e.Failures {
+		names = common.AppendIfNotPresent(names, failure.Transformer)
+	}
+	return fmt.Sprintf("%d transformations failed. Failed transformers: %s", len(e.Failures), strings.Join(names, ", "))
+}
+
+// transformationFailures stores the failed transformations of the current run
+var transformationFailures = []ReportFailure{}
+
+func recordTransformationFailure(transformerName string, iteration int, err error) {
+	transformationFailures = append(transformationFailures, ReportFailure{Transformer: transformerName, Iteration: iteration, Error: err.Error()})
+}
+
+// ReportService stores the transformation option used for a service
+type ReportService struct {
+	Name        string `yaml:"name" json:"name"`
+	Transformer string `yaml:"transformer" json:"transformer"`
+	// OriginalName is the name of the service in the source, when it had to be changed to be a valid kubernetes name
+	OriginalName string `yaml:"originalName,omitempty" json:"originalName,omitempty"`
+}
+
+// ReportTODOItem is a manual step added as a TODO annotation to a generated resource
+type ReportTODOItem struct {
+	File     string `yaml:"file" json:"file"`
+	Kind     string `yaml:"kind" json:"kind"`
+	Name     string `yaml:"name" json:"name"`
+	Category string `yaml:"category" json:"category"`
+	Message  string `yaml:"message" json:"message"`
+}
+
+// ReportStableDNSName has the DNS names of the pods of a StatefulSet, resolved through its headless service
+type ReportStableDNSName struct {
+	StatefulSet string   `yaml:"statefulSet" json:"statefulSet"`
+	Service     string   `yaml:"service" json:"service"`
+	PodDNSNames []string `yaml:"podDNSNames" json:"podDNSNames"`
+}
+
+// reportHook collects the warnings and errors logged during the transformation
+type reportHook struct {
+	mutex    sync.Mutex
+	warnings []string
+	errors   []string
+	skipped  []string
+}
+
+var currentReportHook *reportHook
+
+// StartTransformationReport starts collecting the warnings and errors for the transformation report.
+// The ones collected during a previous transformation are dropped.
+func StartTransformationReport() {
+	StopTransformationReport()
+	currentReportHook = &reportHook{}
+	logrus.AddHook(currentReportHook)
+}
+
+// StopTransformationReport stops collecting the warnings and errors, once the transformation report has been written
+func StopTransformationReport() {
+	if currentReportHook == nil {
+		return
+	}
+	hooks := logrus.LevelHooks{}
+	for level, levelHooks := range logrus.StandardLogger().Hooks {
+		for _, hook := range levelHooks {
+			if hook != currentReportHook {
+				hooks[level] = append(hooks[level], hook)
+			}
+		}
+	}
+	logrus.StandardLogger().ReplaceHooks(hooks)
+	currentReportHook = nil
+}
+
+// AddSkippedToReport records an item that was skipped during the transformation
+func AddSkippedToReport(item string) {
+	if currentReportHook == nil {
+		return
+	}
+	currentReportHook.mutex.Lock()
+	defer currentReportHook.mutex.Unlock()
+	currentReportHook.skipped = append(currentReportHook.skipped, item)
+}
+
+// Fire records the log message
+func (h *reportHook) Fire(entry *logrus.Entry) error {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if entry.Level == logrus.WarnLevel {
+		h.warnings = common.AppendIfNotPresent(h.warnings, entry.Message)
+	} else {
+		h.errors = common.AppendIfNotPresent(h.errors, entry.Message)
+	}
+	return nil
+}
+
+// Levels returns the levels on which the report hook gets called
+func (*reportHook) Levels() []logrus.Level {
+	return []logrus.Level{
+		logrus.ErrorLevel,
+		logrus.WarnLevel,
+	}
+}
+
+func getTransformationReport(planArtifacts []plantypes.PlanArtifact, allArtifacts []transformertypes.Artifact, outputPath string) TransformationReport {
+	report := TransformationReport{
+		Services:           []ReportService{},
+		BuiltImages:        []string{},
+		ReusedImages:       []string{},
+		ResourcesByKind:    map[string]int{},
+		Skipped:            []string{},
+		ManualSteps:        []ReportTODOItem{},
+		StableDNSNames:     []ReportStableDNSName{},
+		DockerfileFindings: []artifacts.DockerfileLintFinding{},
+		HostSubstitutions:  irpreprocessor.GetHostSubstitutions(),
+		HealthProbes:       irpreprocessor.GetHealthProbeScaffolds(),
+		ImageBuildOrder:    dockerfile.GetImageBuildDependencies(),
+		ClusterFeatures:    apiresource.GetClusterFeatureDecisions(),
+		Failures:           append([]ReportFailure{}, transformationFailures...),
+		Warnings:           []string{},
+		Errors:             []string{},
+	}
+	for _, planArtifact := range planArtifacts {
+		report.Services = append(report.Services, ReportService{Name: planArtifact.ServiceName, Transformer: string(planArtifact.TransformerName), OriginalName: planArtifact.OriginalServiceName})
+	}
+	sort.Slice(report.Services, func(i, j int) bool { return report.Services[i].Name < report.Services[j].Name })
+	usedImages := []string{}
+	seenDockerfileFindings := map[artifacts.DockerfileLintFinding]bool{}
+	for _, artifact := range allArtifacts {
+		switch artifact.Type {
+		case artifacts.DockerfileArtifactType:
+			lint := artifacts.DockerfileLint{}
+			if err := artifact.GetConfig(artifacts.DockerfileLintConfigType, &lint); err != nil {
+				continue
+			}
+			for _, finding := range lint.Findings {
+				if !seenDockerfileFindings[finding] {
+					seenDockerfileFindings[finding] = true
+					report.DockerfileFindings = append(report.DockerfileFindings, finding)
+				}
+			}
+		case artifacts.NewImagesArtifactType:
+			newImages := artifacts.NewImages{}
+			if err := artifact.GetConfig(artifacts.NewImagesConfigType, &newImages); err != nil {
+				logrus.Debugf("failed to get the new images from the artifact %s . Error: %q", artifact.Name, err)
+				continue
+			}
+			report.BuiltImages = common.AppendIfNotPresent(report.BuiltImages, newImages.ImageNames...)
+		case irtypes.IRArtifactType:
+			ir := irtypes.IR{}
+			if err := artifact.GetConfig(irtypes.IRConfigType, &ir); err != nil {
+				logrus.Debugf("failed to get the IR from the artifact %s . Error: %q", artifact.Name, err)
+				continue
+			}
+			for _, service := range ir.Services {
+				for _, container := range service.Containers {
+					usedImages = common.AppendIfNotPresent(usedImages, container.Image)
+				}
+			}
+		}
+	}
+	for _, image := range usedImages {
+		if !common.IsPresent(report.BuiltImages, image) {
+			report.ReusedImages = append(report.ReusedImages, image)
+		}
+	}
+	sort.Slice(report.DockerfileFindings, func(i, j int) bool {
+		if report.DockerfileFindings[i].File != report.DockerfileFindings[j].File {
+			return report.DockerfileFindings[i].File < report.DockerfileFindings[j].File
+		}
+		return report.DockerfileFindings[i].Line < report.DockerfileFindings[j].Line
+	})
+	sort.Strings(report.BuiltImages)
+	sort.Strings(report.ReusedImages)
+	addGeneratedResourcesToReport(&report, outputPath)
+	if currentReportHook != nil {
+		currentReportHook.mutex.Lock()
+		report.Warnings = append(report.Warnings, currentReportHook.warnings...)
+		report.Errors = append(report.Errors, currentReportHook.errors...)
+		report.Skipped = append(report.Skipped, currentReportHook.skipped...)
+		currentReportHook.mutex.Unlock()
+	}
+	redactReport(&report)
+	return report
+}
+
+// redactReport masks the secret values in the report.
+// The messages are redacted again since some secrets may have been found after the messages were logged.
+func redactReport(report *TransformationReport) {
+	redactSlice := func(items []string) {
+		for i, item := range items {
+			items[i] = common.RedactSecrets(item)
+		}
+	}
+	redactSlice(report.Skipped)
+	redactSlice(report.Warnings)
+	redactSlice(report.Errors)
+	for i := range report.ManualSteps {
+		report.ManualSteps[i].Message = common.RedactSecrets(report.ManualSteps[i].Message)
+	}
+	for i := range report.DockerfileFindings {
+		report.DockerfileFindings[i].Message = common.RedactSecrets(report.DockerfileFindings[i].Message)
+	}
+	for i := range report.Failures {
+		report.Failures[i].Error = common.RedactSecrets(report.Failures[i].Error)
+	}
+}
+
+// addGeneratedResourcesToReport counts the kubernetes resources in the output directory and collects their TODO annotations.
+// The copy of the source directory is ignored.
+func addGeneratedResourcesToReport(report *TransformationReport, outputPath string) {
+	sourceDir := filepath.Join(outputPath, common.DefaultSourceDir)
+	err := filepath.WalkDir(outputPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path == sourceDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			logrus.Debugf("failed to open the file at path %s . Error: %q", path, err)
+			return nil
+		}
+		defer f.Close()
+		relPath, err := filepath.Rel(outputPath, path)
+		if err != nil {
+			relPath = path
+		}
+		decoder := yaml.NewDecoder(f)
+		for {
+			obj := struct {
+				Kind     string `yaml:"kind"`
+				Metadata struct {
+					Name        string            `yaml:"name"`
+					Namespace   string            `yaml:"namespace"`
+					Annotations map[string]string `yaml:"annotations"`
+				} `yaml:"metadata"`
+				Spec struct {
+					ServiceName string `yaml:"serviceName"`
+					Replicas    *int   `yaml:"replicas"`
+				} `yaml:"spec"`
+			}{}
+			if err := decoder.Decode(&obj); err != nil {
+				if !errors.Is(err, io.EOF) {
+					logrus.Debugf("failed to decode the yaml file at path %s . Error: %q", path, err)
+				}
+				break
+			}
+			if obj.Kind == "" {
+				continue
+			}
+			report.ResourcesByKind[obj.Kind]++
+			if obj.Kind == common.StatefulSetKind && obj.Spec.ServiceName != "" {
+				addStableDNSNamesToReport(report, obj.Metadata.Name, obj.Spec.ServiceName, obj.Metadata.Namespace, obj.Spec.Replicas)
+			}
+			for k, v := range obj.Metadata.Annotations {
+				if !strings.HasPrefix(k, common.TODOAnnotation) {
+					continue
+				}
+				report.ManualSteps = append(report.ManualSteps, ReportTODOItem{
+					File:     relPath,
+					Kind:     obj.Kind,
+					Name:     obj.Metadata.Name,
+					Category: strings.TrimPrefix(k, common.TODOAnnotation),
+					Message:  v,
+				})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Debugf("failed to walk the output directory %s . Error: %q", outputPath, err)
+	}
+	sort.Slice(report.ManualSteps, func(i, j int) bool {
+		if report.ManualSteps[i].File != report.ManualSteps[j].File {
+			return report.ManualSteps[i].File < report.ManualSteps[j].File
+		}
+		return report.ManualSteps[i].Category < report.ManualSteps[j].Category
+	})
+}
+
+// addStableDNSNamesToReport adds the DNS names of the pods of the StatefulSet, once for all the output formats of the same StatefulSet
+func addStableDNSNamesToReport(report *TransformationReport, statefulSetName, serviceName, namespace string, replicas *int) {
+	for _, stableDNSName := range report.StableDNSNames {
+		if stableDNSName.StatefulSet == statefulSetName && stableDNSName.Service == serviceName {
+			return
+		}
+	}
+	if namespace == "" {
+		namespace = "<namespace>"
+	}
+	numPods := 1
+	if replicas != nil {
+		numPods = *replicas
+	}
+	podDNSNames := []string{}
+	for i := 0; i < numPods; i++ {
+		podDNSNames = append(podDNSNames, fmt.Sprintf("%s-%d.%s.%s.svc.cluster.local", statefulSetName, i, serviceName, namespace))
+	}
+	report.StableDNSNames = append(report.StableDNSNames, ReportStableDNSName{StatefulSet: statefulSetName, Service: serviceName, PodDNSNames: podDNSNames})
+	sort.Slice(report.StableDNSNames, func(i, j int) bool {
+		return report.StableDNSNames[i].StatefulSet < report.StableDNSNames[j].StatefulSet
+	})
+}
+
+// writeTransformationReport writes the report as json and markdown to the output directory
+func writeTransformationReport(report TransformationReport, outputPath string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the transformation report to json. Error: %w", err)
+	}
+	jsonPath := filepath.Join(outputPath, TransformationReportJSONFile)
+	if err := os.WriteFile(jsonPath, data, common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the transformation report to the file at path %s . Error: %w", jsonPath, err)
+	}
+	mdPath := filepath.Join(outputPath, TransformationReportMarkdownFile)
+	if err := os.WriteFile(mdPath, []byte(getTransformationReportMarkdown(report)), common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the transformation report to the file at path %s . Error: %w", mdPath, err)
+	}
+	return nil
+}
+
+func getTransformationReportMarkdown(report TransformationReport) string {
+	sb := strings.Builder{}
+	sb.WriteString("# Transformation Report\n\n")
+	sb.WriteString("## Services\n\n")
+	if len(report.Services) == 0 {
+		sb.WriteString("No services were transformed.\n\n")
+	} else {
+		sb.WriteString("| Service | Transformer |\n| --- | --- |\n")
+		for _, service := range report.Services {
+			sb.WriteString(fmt.Sprintf("| %s | %s |\n", service.Name, service.Transformer))
+		}
+		sb.WriteString("\n")
+	}
+	renamedServices := []ReportService{}
+	for _, service := range report.Services {
+		if service.OriginalName != "" {
+			renamedServices = append(renamedServices, service)
+		}
+	}
+	if len(renamedServices) != 0 {
+		sb.WriteString("## Renamed Services\n\n")
+		sb.WriteString("These services were renamed to make them valid kubernetes names.\n\n")
+		sb.WriteString("| Original Name | Service |\n| --- | --- |\n")
+		for _, service := range renamedServices {
+			sb.WriteString(fmt.Sprintf("| %s | %s |\n", service.OriginalName, service.Name))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("## Container Images\n\n")
+	writeMarkdownList(&sb, "### Built", report.BuiltImages)
+	writeMarkdownList(&sb, "### Reused", report.ReusedImages)
+	sb.WriteString("## Generated Resources\n\n")
+	if len(report.ResourcesByKind) == 0 {
+		sb.WriteString("No resources were generated.\n\n")
+	} else {
+		kinds := []string{}
+		for kind := range report.ResourcesByKind {
+			kinds = append(kinds, kind)
+		}
+		sort.Strings(kinds)
+		sb.WriteString("| Kind | Count |\n| --- | --- |\n")
+		for _, kind := range kinds {
+			sb.WriteString(fmt.Sprintf("| %s | %d |\n", kind, report.ResourcesByKind[kind]))
+		}
+		sb.WriteString("\n")
+	}
+	if len(report.ManualSteps) != 0 {
+		sb.WriteString("## Manual Steps\n\n")
+		for _, item := range report.ManualSteps {
+			sb.WriteString(fmt.Sprintf("- %s %s (%s) [%s]: %s\n", item.Kind, item.Name, item.File, item.Category, item.Message))
+		}
+		sb.WriteString("\n")
+	}
+	if len(report.StableDNSNames) != 0 {
+		sb.WriteString("## Stable DNS Names\n\n")
+		sb.WriteString("The pods of the StatefulSets are reachable at these names through their headless services. Use them to configure the peers of the clustered workloads.\n\n")
+		sb.WriteString("| StatefulSet | Service | Pod DNS Names |\n| --- | --- | --- |\n")
+		for _, stableDNSName := range report.StableDNSNames {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", stableDNSName.StatefulSet, stableDNSName.Service, strings.Join(stableDNSName.PodDNSNames, "<br>")))
+		}
+		sb.WriteString("\n")
+	}
+	if len(report.HostSubstitutions) != 0 {
+		sb.WriteString("## Host Substitutions\n\n")
+		sb.WriteString("These host names and IP addresses of the services do not resolve in the cluster and were replaced with the names of their services.\n\n")
+		sb.WriteString("| Service | Config Map | Key | Host | DNS Name |\n| --- | --- | --- | --- | --- |\n")
+		for _, substitution := range report.HostSubstitutions {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", substitution.Service, substitution.ConfigMap, substitution.Key, substitution.Host, substitution.DNSName))
+		}
+		sb.WriteString("\n")
+	}
+	if len(report.HealthProbes) != 0 {
+		sb.WriteString("## Health Probes\n\n")
+		sb.WriteString("These services have no health endpoint. The probes added to them only check that their ports accept connections.\n\n")
+		sb.WriteString("| Service | Container | Probe | Recommendation |\n| --- | --- | --- | --- |\n")
+		for _, scaffold := range report.HealthProbes {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", scaffold.Service, scaffold.Container, scaffold.Probe, scaffold.Recommendation))
+		}
+		sb.WriteString("\n")
+	}
+	dependentImages := false
+	for _, dependency := range report.ImageBuildOrder {
+		dependentImages = dependentImages || len(dependency.BuiltAfter) != 0
+	}
+	if dependentImages {
+		sb.WriteString("## Image Build Order\n\n")
+		sb.WriteString("Some of the new images are built from the others, so the build scripts and the pipelines build them in this order.\n\n")
+		sb.WriteString("| Image | Built After |\n| --- | --- |\n")
+		for _, dependency := range report.ImageBuildOrder {
+			sb.WriteString(fmt.Sprintf("| %s | %s |\n", dependency.Image, strings.Join(dependency.BuiltAfter, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+	if len(report.DockerfileFindings) != 0 {
+		sb.WriteString("## Dockerfile Findings\n\n")
+		sb.WriteString("| File | Line | Rule | Message | Fixed |\n| --- | --- | --- | --- | --- |\n")
+		for _, finding := range report.DockerfileFindings {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %s | %s | %t |\n", finding.File, finding.Line, finding.Rule, strings.ReplaceAll(finding.Message, "|", "\\|"), finding.Fixed))
+		}
+		sb.WriteString("\n")
+	}
+	if len(report.ClusterFeatures) != 0 {
+		sb.WriteString("## Cluster Features\n\n")
+		sb.WriteString("| Cluster | Feature | Enabled | Reason |\n| --- | --- | --- | --- |\n")
+		for _, decision := range report.ClusterFeatures {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %t | %s |\n", decision.Cluster, decision.Feature, decision.Enabled, decision.Reason))
+		}
+		sb.WriteString("\n")
+	}
+	if len(report.Failures) != 0 {
+		sb.WriteString("## Failures\n\n")
+		sb.WriteString("| Transformer | Iteration | Error |\n| --- | --- | --- |\n")
+		for _, failure := range report.Failures {
+			sb.WriteString(fmt.Sprintf("| %s | %d | %s |\n", failure.Transformer, failure.Iteration, strings.ReplaceAll(strings.ReplaceAll(failure.Error, "\n", " "), "|", "\\|")))
+		}
+		sb.WriteString("\n")
+	}
+	writeMarkdownList(&sb, "## Skipped", report.Skipped)
+	writeMarkdownList(&sb, "## Warnings", report.Warnings)
+	writeMarkdownList(&sb, "## Errors", report.Errors)
+	return sb.String()
+}
+
+func writeMarkdownList(sb *strings.Builder, heading string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	sb.WriteString(heading + "\n\n")
+	for _, item := range items {
+		sb.WriteString("- " + strings.ReplaceAll(item, "\n", " ") + "\n")
+	}
+	sb.WriteString("\n")
+}
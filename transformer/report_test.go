@@ -0,0 +1,182 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/transformer/dockerfile"
+	"github.com/konveyor/move2kube/transformer/kubernetes/irpreprocessor"
+	"github.com/sirupsen/logrus"
+)
+
+func TestTransformationReportIsPerRun(t *testing.T) {
+	countReportHooks := func() int {
+		count := 0
+		for _, hook := range logrus.StandardLogger().Hooks[logrus.WarnLevel] {
+			if _, ok := hook.(*reportHook); ok {
+				count++
+			}
+		}
+		return count
+	}
+	defer StopTransformationReport()
+
+	StartTransformationReport()
+	logrus.Warn("warning of the first run")
+	AddSkippedToReport("skipped in the first run")
+	StopTransformationReport()
+	logrus.Warn("warning after the first run")
+	if countReportHooks() != 0 {
+		t.Fatalf("expected the report hook to be removed when the run ends")
+	}
+
+	StartTransformationReport()
+	StartTransformationReport()
+	logrus.Warn("warning of the second run")
+	if got := countReportHooks(); got != 1 {
+		t.Fatalf("expected only the report hook of the second run to be registered, found %d", got)
+	}
+	report := getTransformationReport(nil, nil, t.TempDir())
+	if len(report.Warnings) != 1 || report.Warnings[0] != "warning of the second run" {
+		t.Fatalf("expected only the warnings of the second run in the report, got %+v", report.Warnings)
+	}
+	if len(report.Skipped) != 0 {
+		t.Fatalf("expected the skipped items of the first run to be dropped, got %+v", report.Skipped)
+	}
+}
+
+func TestStableDNSNamesInReport(t *testing.T) {
+	outputPath := t.TempDir()
+	statefulSet := "apiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n  name: kafka\nspec:\n  serviceName: kafka-headless\n  replicas: 2\n"
+	others := "apiVersion: apps/v1\nkind: StatefulSet\nmetadata:\n  name: db\n  namespace: prod\nspec:\n  serviceName: db-headless\n---\napiVersion: v1\nkind: Service\nmetadata:\n  name: kafka-headless\n"
+	for path, content := range map[string]string{
+		filepath.Join("deploy", "yamls", "kafka-statefulset.yaml"):                 statefulSet,
+		filepath.Join("deploy", "yamls-parameterized", "kafka-statefulset.yaml"):   statefulSet,
+		filepath.Join("deploy", "yamls", "db.yaml"):                                others,
+		filepath.Join(common.DefaultSourceDir, "deploy", "other-statefulset.yaml"): "kind: StatefulSet\nmetadata:\n  name: other\nspec:\n  serviceName: other\n",
+	} {
+		path = filepath.Join(outputPath, path)
+		if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+			t.Fatalf("failed to create the directory for %s . Error: %q", path, err)
+		}
+		if err := os.WriteFile(path, []byte(content), common.DefaultFilePermission); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", path, err)
+		}
+	}
+	report := getTransformationReport(nil, nil, outputPath)
+	want := []ReportStableDNSName{
+		{StatefulSet: "db", Service: "db-headless", PodDNSNames: []string{"db-0.db-headless.prod.svc.cluster.local"}},
+		{StatefulSet: "kafka", Service: "kafka-headless", PodDNSNames: []string{"kafka-0.kafka-headless.<namespace>.svc.cluster.local", "kafka-1.kafka-headless.<namespace>.svc.cluster.local"}},
+	}
+	if !cmp.Equal(report.StableDNSNames, want) {
+		t.Fatalf("the stable DNS names differ. Differences:\n%s", cmp.Diff(want, report.StableDNSNames))
+	}
+	markdown := getTransformationReportMarkdown(report)
+	if !strings.Contains(markdown, "| kafka | kafka-headless | kafka-0.kafka-headless.<namespace>.svc.cluster.local<br>kafka-1.kafka-headless.<namespace>.svc.cluster.local |") {
+		t.Fatalf("expected the stable DNS names of kafka in the markdown report. Actual:\n%s", markdown)
+	}
+}
+
+func TestHostSubstitutionsInReport(t *testing.T) {
+	report := TransformationReport{HostSubstitutions: []irpreprocessor.HostSubstitution{
+		{Service: "web", Key: "DATABASE_URL", Host: "my-postgres", DNSName: "db"},
+		{ConfigMap: "web-config", Key: "app.properties", Host: "172.16.238.10", DNSName: "db"},
+	}}
+	markdown := getTransformationReportMarkdown(report)
+	for _, row := range []string{"| web |  | DATABASE_URL | my-postgres | db |", "|  | web-config | app.properties | 172.16.238.10 | db |"} {
+		if !strings.Contains(markdown, row) {
+			t.Fatalf("expected the row %q in the markdown report. Actual:\n%s", row, markdown)
+		}
+	}
+	if markdown := getTransformationReportMarkdown(TransformationReport{}); strings.Contains(markdown, "Host Substitutions") {
+		t.Fatalf("expected no host substitutions section without any substitutions. Actual:\n%s", markdown)
+	}
+}
+
+func TestTransformationFailuresInReport(t *testing.T) {
+	transformationFailures = []ReportFailure{}
+	t.Cleanup(func() { transformationFailures = []ReportFailure{} })
+	recordTransformationFailure("Kubernetes", 2, errors.New("failed to write the yamls"))
+	recordTransformationFailure("Kubernetes", 3, errors.New("failed | again\nwith details"))
+	recordTransformationFailure("Tekton", 3, errors.New("failed to create the pipeline"))
+
+	err := &TransformationFailedError{Failures: transformationFailures}
+	if want := "3 transformations failed. Failed transformers: Kubernetes, Tekton"; err.Error() != want {
+		t.Fatalf("got the error %q , want %q", err.Error(), want)
+	}
+	report := getTransformationReport(nil, nil, t.TempDir())
+	want := []ReportFailure{
+		{Transformer: "Kubernetes", Iteration: 2, Error: "failed to write the yamls"},
+		{Transformer: "Kubernetes", Iteration: 3, Error: "failed | again\nwith details"},
+		{Transformer: "Tekton", Iteration: 3, Error: "failed to create the pipeline"},
+	}
+	if !cmp.Equal(report.Failures, want) {
+		t.Fatalf("the failures in the report are different. Difference:\n%s", cmp.Diff(want, report.Failures))
+	}
+	transformationFailures[0].Error = "changed after the report"
+	if report.Failures[0].Error != "failed to write the yamls" {
+		t.Fatalf("expected the report to keep a copy of the failures, got %+v", report.Failures)
+	}
+	markdown := getTransformationReportMarkdown(report)
+	if !strings.Contains(markdown, "## Failures\n\n| Transformer | Iteration | Error |\n| --- | --- | --- |\n| Kubernetes | 2 | failed to write the yamls |\n| Kubernetes | 3 | failed \\| again with details |\n| Tekton | 3 | failed to create the pipeline |\n") {
+		t.Fatalf("expected the failures table in the markdown report, got:\n%s", markdown)
+	}
+	transformationFailures = []ReportFailure{}
+	if markdown := getTransformationReportMarkdown(getTransformationReport(nil, nil, t.TempDir())); strings.Contains(markdown, "## Failures") {
+		t.Fatalf("expected no failures section without failures, got:\n%s", markdown)
+	}
+}
+
+func TestImageBuildOrderInReport(t *testing.T) {
+	report := TransformationReport{ImageBuildOrder: []dockerfile.ImageBuildDependency{
+		{Image: "base", BuiltAfter: []string{}},
+		{Image: "api", BuiltAfter: []string{"base", "runtime"}},
+	}}
+	markdown := getTransformationReportMarkdown(report)
+	for _, row := range []string{"## Image Build Order", "| base |  |", "| api | base, runtime |"} {
+		if !strings.Contains(markdown, row) {
+			t.Fatalf("expected the row %q in the markdown report. Actual:\n%s", row, markdown)
+		}
+	}
+	report.ImageBuildOrder = []dockerfile.ImageBuildDependency{{Image: "api", BuiltAfter: []string{}}, {Image: "web", BuiltAfter: []string{}}}
+	if markdown := getTransformationReportMarkdown(report); strings.Contains(markdown, "Image Build Order") {
+		t.Fatalf("expected no build order section when the images do not depend on each other. Actual:\n%s", markdown)
+	}
+}
+
+func TestHealthProbesInReport(t *testing.T) {
+	report := TransformationReport{HealthProbes: []irpreprocessor.HealthProbeScaffold{
+		{Service: "api", Container: "api", Probe: "exec", Recommendation: "Add an HTTP health endpoint"},
+		{Service: "web", Container: "web", Probe: "none", Recommendation: "Add an HTTP health endpoint"},
+	}}
+	markdown := getTransformationReportMarkdown(report)
+	for _, row := range []string{"## Health Probes", "| api | api | exec | Add an HTTP health endpoint |", "| web | web | none | Add an HTTP health endpoint |"} {
+		if !strings.Contains(markdown, row) {
+			t.Fatalf("expected the row %q in the markdown report. Actual:\n%s", row, markdown)
+		}
+	}
+	if markdown := getTransformationReportMarkdown(TransformationReport{}); strings.Contains(markdown, "Health Probes") {
+		t.Fatalf("expected no health probes section without any services lacking probes. Actual:\n%s", markdown)
+	}
+}
@@ -0,0 +1,103 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// maxServiceNameLength is the maximum length of a DNS-1035 label, which the names of the kubernetes services must be
+	maxServiceNameLength = 63
+	// serviceNameSuffixLength is the number of characters of the hash of the original name, used to tell apart the names which collide
+	serviceNameSuffixLength = 5
+)
+
+var (
+	invalidServiceNameCharsRegex = regexp.MustCompile(`[^a-z0-9-]+`)
+	repeatedHyphensRegex         = regexp.MustCompile(`-+`)
+)
+
+// SanitizeServiceNames makes the names of the services valid DNS-1035 labels, which can be used for the kubernetes services and the workloads.
+// The names which collide after the sanitization, or which had to be truncated, get a suffix derived from the original name, so that they are the same in every run.
+// The names which are already valid are kept as is. The original names are stored in the plan artifacts so that they can be reported.
+func SanitizeServiceNames(services map[string][]plantypes.PlanArtifact) map[string][]plantypes.PlanArtifact {
+	validNames := []string{}
+	invalidNames := []string{}
+	for name := range services {
+		if sanitizeServiceName(name) == name {
+			validNames = append(validNames, name)
+		} else {
+			invalidNames = append(invalidNames, name)
+		}
+	}
+	sort.Strings(invalidNames)
+	sanitizedServices := map[string][]plantypes.PlanArtifact{}
+	for _, name := range validNames {
+		sanitizedServices[name] = services[name]
+	}
+	for _, name := range invalidNames {
+		sanitizedName := sanitizeServiceName(name)
+		for suffixLength := serviceNameSuffixLength; ; suffixLength++ {
+			if _, ok := sanitizedServices[sanitizedName]; !ok {
+				break
+			}
+			sanitizedName = addServiceNameSuffix(sanitizeServiceName(name), common.GetSHA256Hash(name)[:suffixLength])
+		}
+		logrus.Infof("Renaming the service %s to %s to make it a valid kubernetes name", name, sanitizedName)
+		planArtifacts := []plantypes.PlanArtifact{}
+		for _, planArtifact := range services[name] {
+			if planArtifact.OriginalServiceName == "" {
+				planArtifact.OriginalServiceName = name
+			}
+			planArtifact.ServiceName = sanitizedName
+			planArtifacts = append(planArtifacts, planArtifact)
+		}
+		sanitizedServices[sanitizedName] = planArtifacts
+	}
+	return sanitizedServices
+}
+
+// sanitizeServiceName converts the name into a DNS-1035 label, truncating the long names with a suffix derived from the name
+func sanitizeServiceName(name string) string {
+	sanitizedName := invalidServiceNameCharsRegex.ReplaceAllLiteralString(strings.ToLower(name), "-")
+	sanitizedName = strings.Trim(repeatedHyphensRegex.ReplaceAllLiteralString(sanitizedName, "-"), "-")
+	if sanitizedName == "" {
+		sanitizedName = "service"
+	}
+	if sanitizedName[0] < 'a' || sanitizedName[0] > 'z' {
+		sanitizedName = "svc-" + sanitizedName
+	}
+	if len(sanitizedName) > maxServiceNameLength {
+		sanitizedName = addServiceNameSuffix(sanitizedName, common.GetSHA256Hash(name)[:serviceNameSuffixLength])
+	}
+	return sanitizedName
+}
+
+// addServiceNameSuffix appends the suffix to the name, truncating the name so that the result is not longer than a DNS-1035 label
+func addServiceNameSuffix(name, suffix string) string {
+	if maxLength := maxServiceNameLength - len(suffix) - 1; len(name) > maxLength {
+		name = strings.TrimSuffix(name[:maxLength], "-")
+	}
+	return name + "-" + suffix
+}
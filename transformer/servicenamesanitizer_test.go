@@ -0,0 +1,95 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	plantypes "github.com/konveyor/move2kube/types/plan"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func TestSanitizeServiceNames(t *testing.T) {
+	longName := strings.Repeat("a", 70)
+	testCases := []struct {
+		name  string
+		names []string
+		// want is the sanitized name mapped to the original name, which is empty for the names kept as is
+		want map[string]string
+	}{
+		{
+			name:  "valid names are kept as is",
+			names: []string{"api", "web-1"},
+			want:  map[string]string{"api": "", "web-1": ""},
+		},
+		{
+			name:  "invalid characters are replaced with hyphens",
+			names: []string{"My_App", "-front..end-"},
+			want:  map[string]string{"my-app": "My_App", "front-end": "-front..end-"},
+		},
+		{
+			name:  "names not starting with a letter get a prefix",
+			names: []string{"1st", "___"},
+			want:  map[string]string{"svc-1st": "1st", "service": "___"},
+		},
+		{
+			name:  "over-long names are truncated with a suffix derived from the name",
+			names: []string{longName},
+			want:  map[string]string{strings.Repeat("a", 57) + "-" + common.GetSHA256Hash(longName)[:5]: longName},
+		},
+		{
+			name:  "a name colliding with a valid name gets a suffix",
+			names: []string{"api", "API"},
+			want:  map[string]string{"api": "", "api-" + common.GetSHA256Hash("API")[:5]: "API"},
+		},
+		{
+			name:  "names colliding with each other get a suffix in the sorted order",
+			names: []string{"web.app", "Web_App"},
+			want:  map[string]string{"web-app": "Web_App", "web-app-" + common.GetSHA256Hash("web.app")[:5]: "web.app"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			services := map[string][]plantypes.PlanArtifact{}
+			for _, name := range testCase.names {
+				services[name] = []plantypes.PlanArtifact{{ServiceName: name}}
+			}
+			sanitizedServices := SanitizeServiceNames(services)
+			got := map[string]string{}
+			for name, planArtifacts := range sanitizedServices {
+				if errs := validation.IsDNS1035Label(name); len(errs) != 0 {
+					t.Errorf("the name %s is not a DNS-1035 label. Errors: %+v", name, errs)
+				}
+				for _, planArtifact := range planArtifacts {
+					if planArtifact.ServiceName != name {
+						t.Errorf("the plan artifact of the service %s has the service name %s", name, planArtifact.ServiceName)
+					}
+					got[name] = planArtifact.OriginalServiceName
+				}
+			}
+			if !reflect.DeepEqual(got, testCase.want) {
+				t.Fatalf("got the services %+v , want %+v", got, testCase.want)
+			}
+			if again := SanitizeServiceNames(sanitizedServices); !reflect.DeepEqual(again, sanitizedServices) {
+				t.Fatalf("sanitizing the names again changed them from %+v to %+v", sanitizedServices, again)
+			}
+		})
+	}
+}
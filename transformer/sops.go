@@ -0,0 +1,222 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	sopsCommand    = "sops"
+	sopsConfigFile = ".sops.yaml"
+	helmChartFile  = "Chart.yaml"
+	// sopsEncryptedRegex makes SOPS encrypt only the values in the Secrets, so that the rest of the manifest stays readable
+	sopsEncryptedRegex = "^(data|stringData)$"
+)
+
+var (
+	secretKindRegex   = regexp.MustCompile(`(?m)^kind:\s*["']?Secret["']?\s*$`)
+	sopsMetadataRegex = regexp.MustCompile(`(?m)^sops:`)
+)
+
+// sopsKeyType is a type of key supported by SOPS
+type sopsKeyType struct {
+	name          string
+	flag          string
+	setRecipients func(rule *sopsCreationRule, recipients string)
+}
+
+var sopsKeyTypes = []sopsKeyType{
+	{name: "age", flag: "--age", setRecipients: func(rule *sopsCreationRule, recipients string) { rule.Age = recipients }},
+	{name: "PGP", flag: "--pgp", setRecipients: func(rule *sopsCreationRule, recipients string) { rule.PGP = recipients }},
+	{name: "AWS KMS", flag: "--kms", setRecipients: func(rule *sopsCreationRule, recipients string) { rule.KMS = recipients }},
+	{name: "GCP KMS", flag: "--gcp-kms", setRecipients: func(rule *sopsCreationRule, recipients string) { rule.GCPKMS = recipients }},
+	{name: "Azure Key Vault", flag: "--azure-kv", setRecipients: func(rule *sopsCreationRule, recipients string) { rule.AzureKeyVault = recipients }},
+}
+
+// sopsConfig is the SOPS configuration file written to the output directory
+type sopsConfig struct {
+	CreationRules []sopsCreationRule `yaml:"creation_rules"`
+}
+
+// sopsCreationRule stores the keys for which SOPS encrypts the files
+type sopsCreationRule struct {
+	EncryptedRegex string `yaml:"encrypted_regex"`
+	Age            string `yaml:"age,omitempty"`
+	PGP            string `yaml:"pgp,omitempty"`
+	KMS            string `yaml:"kms,omitempty"`
+	GCPKMS         string `yaml:"gcp_kms,omitempty"`
+	AzureKeyVault  string `yaml:"azure_keyvault,omitempty"`
+}
+
+// encryptSecretManifests encrypts the Secret manifests in the output directory using SOPS, if the user chooses to.
+// A SOPS configuration file is written so that the Secrets can be edited and encrypted again later.
+func encryptSecretManifests(outputPath string) error {
+	if !qaengine.FetchBoolAnswer(common.ConfigSOPSEnabledKey, "Do you want to encrypt the generated Secret manifests using SOPS?", []string{"The encrypted Secrets can be committed to version control and decrypted using sops or the SOPS plugins of kustomize and Argo CD."}, false, nil) {
+		return nil
+	}
+	keyTypeNames := []string{}
+	for _, keyType := range sopsKeyTypes {
+		keyTypeNames = append(keyTypeNames, keyType.name)
+	}
+	keyTypeName := qaengine.FetchSelectAnswer(common.ConfigSOPSKeyTypeKey, "Select the type of the keys used to encrypt the Secrets :", nil, sopsKeyTypes[0].name, keyTypeNames, nil)
+	keyType := sopsKeyTypes[0]
+	for _, kt := range sopsKeyTypes {
+		if kt.name == keyTypeName {
+			keyType = kt
+		}
+	}
+	recipients := []string{}
+	for _, recipient := range strings.Split(qaengine.FetchStringAnswer(common.ConfigSOPSRecipientsKey, fmt.Sprintf("Enter the comma separated %s recipients :", keyType.name), []string{"For age these are the public keys, for PGP the key fingerprints and for the KMS types the key ARNs or resource IDs."}, "", nil), ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+	secretManifests := getSecretManifests(outputPath)
+	if len(recipients) == 0 {
+		return removeUnencryptedSecretManifests(secretManifests, fmt.Errorf("no SOPS recipients were given"))
+	}
+	joinedRecipients := strings.Join(recipients, ",")
+	rule := sopsCreationRule{EncryptedRegex: sopsEncryptedRegex}
+	keyType.setRecipients(&rule, joinedRecipients)
+	if err := common.WriteYaml(filepath.Join(outputPath, sopsConfigFile), sopsConfig{CreationRules: []sopsCreationRule{rule}}); err != nil {
+		return removeUnencryptedSecretManifests(secretManifests, fmt.Errorf("failed to write the SOPS configuration file. Error: %w", err))
+	}
+	if len(secretManifests) == 0 {
+		return nil
+	}
+	sopsPath, err := exec.LookPath(sopsCommand)
+	if err != nil {
+		return removeUnencryptedSecretManifests(secretManifests, fmt.Errorf("the %s command was not found. Error: %w", sopsCommand, err))
+	}
+	failedSecretManifests := []string{}
+	inHelmChart := false
+	for _, secretManifest := range secretManifests {
+		cmd := exec.Command(sopsPath, "--encrypt", "--in-place", "--encrypted-regex", sopsEncryptedRegex, keyType.flag, joinedRecipients, secretManifest)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			logrus.Errorf("failed to encrypt the Secret manifest at path %s using %s . Output: %s Error: %q", secretManifest, sopsCommand, string(output), err)
+			failedSecretManifests = append(failedSecretManifests, secretManifest)
+			continue
+		}
+		if isHelmChartTemplate(outputPath, secretManifest) {
+			inHelmChart = true
+		}
+	}
+	if len(failedSecretManifests) != 0 {
+		return removeUnencryptedSecretManifests(failedSecretManifests, fmt.Errorf("failed to encrypt %d of the %d Secret manifests using %s", len(failedSecretManifests), len(secretManifests), sopsCommand))
+	}
+	logrus.Infof("Encrypted %d Secret manifests using %s", len(secretManifests), sopsCommand)
+	if inHelmChart {
+		logrus.Infof("Helm cannot decrypt the Secrets in the templates of the Helm charts. Decrypt them using %s, or a plugin like helm-secrets, before installing the charts.", sopsCommand)
+	}
+	return nil
+}
+
+// removeUnencryptedSecretManifests removes the Secret manifests which could not be encrypted, so that the Secrets are not left in plain text in the output
+func removeUnencryptedSecretManifests(secretManifests []string, cause error) error {
+	if len(secretManifests) == 0 {
+		return cause
+	}
+	for _, secretManifest := range secretManifests {
+		if err := os.Remove(secretManifest); err != nil {
+			logrus.Errorf("failed to remove the unencrypted Secret manifest at path %s . Error: %q", secretManifest, err)
+		}
+	}
+	return fmt.Errorf("removed the %d unencrypted Secret manifests %+v from the output. Error: %w", len(secretManifests), secretManifests, cause)
+}
+
+// isHelmChartTemplate returns true if the file is in the templates of a Helm chart in the output directory
+func isHelmChartTemplate(outputPath, path string) bool {
+	for dir := filepath.Dir(path); dir != outputPath && dir != filepath.Dir(dir); dir = filepath.Dir(dir) {
+		if _, err := os.Stat(filepath.Join(dir, helmChartFile)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// getSecretManifests returns the yaml files in the output directory, including the templates of the Helm charts,
+// which only contain unencrypted Secrets. The copy of the source directory is ignored.
+func getSecretManifests(outputPath string) []string {
+	secretManifests := []string{}
+	sourceDir := filepath.Join(outputPath, common.DefaultSourceDir)
+	err := filepath.WalkDir(outputPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path == sourceDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		if isUnencryptedSecretManifest(path) {
+			secretManifests = append(secretManifests, path)
+		}
+		return nil
+	})
+	if err != nil {
+		logrus.Debugf("failed to walk the output directory %s . Error: %q", outputPath, err)
+	}
+	return secretManifests
+}
+
+// isUnencryptedSecretManifest returns true if the yaml file only contains Secrets which are not encrypted by SOPS
+func isUnencryptedSecretManifest(path string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		logrus.Debugf("failed to read the file at path %s . Error: %q", path, err)
+		return false
+	}
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	numSecrets := 0
+	for {
+		obj := struct {
+			Kind string      `yaml:"kind"`
+			Sops interface{} `yaml:"sops"`
+		}{}
+		if err := decoder.Decode(&obj); err != nil {
+			if !errors.Is(err, io.EOF) {
+				// the templates of the Helm charts are not always valid yaml
+				logrus.Debugf("failed to decode the yaml file at path %s . Error: %q", path, err)
+				return secretKindRegex.Match(content) && !sopsMetadataRegex.Match(content)
+			}
+			break
+		}
+		if obj.Kind != "Secret" || obj.Sops != nil {
+			return false
+		}
+		numSecrets++
+	}
+	return numSecrets > 0
+}
@@ -0,0 +1,137 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+)
+
+const testSecretManifest = `apiVersion: v1
+kind: Secret
+metadata:
+  name: db
+data:
+  password: cGFzc3dvcmQ=
+`
+
+func TestEncryptSecretManifests(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake sops command is a shell script")
+	}
+	// the fake sops command appends the SOPS metadata to the file, like sops --encrypt --in-place
+	encryptingSops := "#!/bin/sh\nfor last; do :; done\nprintf 'sops:\\n  mac: fake\\n' >> \"$last\"\n"
+	failingSops := "#!/bin/sh\necho 'no key could encrypt the data' >&2\nexit 1\n"
+	testCases := []struct {
+		name       string
+		sops       string
+		recipients string
+		wantErr    bool
+	}{
+		{name: "encrypts the Secrets", sops: encryptingSops, recipients: "age1testrecipient"},
+		{name: "no recipients", sops: encryptingSops, wantErr: true},
+		{name: "sops is not installed", recipients: "age1testrecipient", wantErr: true},
+		{name: "sops fails", sops: failingSops, recipients: "age1testrecipient", wantErr: true},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			binDir := t.TempDir()
+			if testCase.sops != "" {
+				if err := os.WriteFile(filepath.Join(binDir, sopsCommand), []byte(testCase.sops), 0755); err != nil {
+					t.Fatalf("failed to write the fake sops command. Error: %q", err)
+				}
+			}
+			t.Setenv("PATH", binDir)
+			qaengine.Reset()
+			defer qaengine.Reset()
+			qaengine.AddEngine(qaengine.NewDefaultEngine())
+			qaengine.SetupConfigFile("", []string{
+				common.ConfigSOPSEnabledKey + "=true",
+				common.ConfigSOPSKeyTypeKey + `="age"`,
+				common.ConfigSOPSRecipientsKey + `="` + testCase.recipients + `"`,
+			}, nil, nil, false)
+
+			outputPath := t.TempDir()
+			secretManifests := []string{
+				filepath.Join(outputPath, "deploy", "yamls", "db-secret.yaml"),
+				filepath.Join(outputPath, "deploy", "helm-chart", "myproject", "templates", "db-secret.yaml"),
+			}
+			if err := os.MkdirAll(filepath.Join(outputPath, "deploy", "yamls"), 0755); err != nil {
+				t.Fatalf("failed to create the directory. Error: %q", err)
+			}
+			if err := os.MkdirAll(filepath.Join(outputPath, "deploy", "helm-chart", "myproject", "templates"), 0755); err != nil {
+				t.Fatalf("failed to create the directory. Error: %q", err)
+			}
+			if err := os.WriteFile(filepath.Join(outputPath, "deploy", "helm-chart", "myproject", helmChartFile), []byte("name: myproject\n"), 0644); err != nil {
+				t.Fatalf("failed to write the chart. Error: %q", err)
+			}
+			for _, secretManifest := range secretManifests {
+				if err := os.WriteFile(secretManifest, []byte(testSecretManifest), 0644); err != nil {
+					t.Fatalf("failed to write the Secret manifest. Error: %q", err)
+				}
+			}
+			deploymentPath := filepath.Join(outputPath, "deploy", "yamls", "db-deployment.yaml")
+			if err := os.WriteFile(deploymentPath, []byte("kind: Deployment\n"), 0644); err != nil {
+				t.Fatalf("failed to write the deployment. Error: %q", err)
+			}
+
+			err := encryptSecretManifests(outputPath)
+			if testCase.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error")
+				}
+				for _, secretManifest := range secretManifests {
+					if _, err := os.Stat(secretManifest); !os.IsNotExist(err) {
+						t.Fatalf("expected the unencrypted Secret manifest at path %s to be removed", secretManifest)
+					}
+				}
+			} else {
+				if err != nil {
+					t.Fatalf("failed to encrypt the Secret manifests. Error: %q", err)
+				}
+				for _, secretManifest := range secretManifests {
+					content, err := os.ReadFile(secretManifest)
+					if err != nil {
+						t.Fatalf("failed to read the Secret manifest. Error: %q", err)
+					}
+					if !strings.Contains(string(content), "sops:") {
+						t.Fatalf("expected the Secret manifest at path %s to be encrypted, got:\n%s", secretManifest, string(content))
+					}
+				}
+				sopsConfigContent, err := os.ReadFile(filepath.Join(outputPath, sopsConfigFile))
+				if err != nil {
+					t.Fatalf("failed to read the SOPS configuration file. Error: %q", err)
+				}
+				if !strings.Contains(string(sopsConfigContent), "age1testrecipient") {
+					t.Fatalf("expected the recipient in the SOPS configuration file, got:\n%s", string(sopsConfigContent))
+				}
+				if len(getSecretManifests(outputPath)) != 0 {
+					t.Fatalf("expected no unencrypted Secret manifests to be left")
+				}
+			}
+			if _, err := os.Stat(deploymentPath); err != nil {
+				t.Fatalf("expected the other manifests to be kept. Error: %q", err)
+			}
+		})
+	}
+}
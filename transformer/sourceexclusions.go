@@ -0,0 +1,150 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultSourceExcludes are the paths which are not needed to build the images and are not copied into the source directory by default
+var defaultSourceExcludes = []string{".git/", "node_modules/"}
+
+// sourceExcludePattern is a pattern of the paths which are not copied into the source directory.
+// The patterns without a slash match the names of the files and directories at any depth,
+// the other ones match the paths relative to the directory of the pattern.
+type sourceExcludePattern struct {
+	baseDir  string
+	pattern  string
+	dirOnly  bool
+	anyDepth bool
+}
+
+func newSourceExcludePattern(baseDir, line string) (sourceExcludePattern, bool) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return sourceExcludePattern{}, false
+	}
+	p := sourceExcludePattern{baseDir: baseDir}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	p.anyDepth = !strings.Contains(line, "/")
+	p.pattern = strings.TrimPrefix(line, "/")
+	if p.pattern == "" {
+		return sourceExcludePattern{}, false
+	}
+	if _, err := filepath.Match(p.pattern, ""); err != nil {
+		logrus.Warnf("Ignoring the invalid source exclusion pattern '%s' . Error: %q", line, err)
+		return sourceExcludePattern{}, false
+	}
+	return p, true
+}
+
+func (p sourceExcludePattern) matches(path string, isDir bool) bool {
+	if p.dirOnly && !isDir {
+		return false
+	}
+	relPath, err := filepath.Rel(p.baseDir, path)
+	if err != nil || relPath == "." || strings.HasPrefix(relPath, "..") {
+		return false
+	}
+	if p.anyDepth {
+		matched, _ := filepath.Match(p.pattern, filepath.Base(path))
+		return matched
+	}
+	matched, _ := filepath.Match(p.pattern, filepath.ToSlash(relPath))
+	return matched
+}
+
+// getSourceExcluder returns the function which tells whether a path is excluded from the copy of the source path.
+// The patterns are the ones configured, along with the ones in the .m2kcopyignore files of the source path.
+func getSourceExcluder(srcPath string) func(path string, isDir bool) bool {
+	excludes := qaengine.FetchMultilineInputAnswer(
+		common.ConfigSourceExcludesKey,
+		"Enter the patterns of the paths which should not be copied into the source directory :",
+		[]string{
+			"Enter one pattern per line. A pattern without a slash matches the names at any depth, like node_modules/ , the other ones match the paths relative to the source directory, like build/*.log . A trailing slash matches only the directories.",
+			"The patterns in the " + common.CopyIgnoreFilename + " files are excluded too.",
+		},
+		strings.Join(defaultSourceExcludes, "\n"),
+		nil,
+	)
+	patterns := []sourceExcludePattern{}
+	for _, line := range strings.Split(excludes, "\n") {
+		if p, ok := newSourceExcludePattern(srcPath, line); ok {
+			patterns = append(patterns, p)
+		}
+	}
+	isExcluded := func(path string, isDir bool) bool {
+		for _, p := range patterns {
+			if p.matches(path, isDir) {
+				return true
+			}
+		}
+		return false
+	}
+	if err := filepath.WalkDir(srcPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if isExcluded(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || d.Name() != common.CopyIgnoreFilename {
+			return nil
+		}
+		ignorePatterns, err := readSourceExcludePatterns(path)
+		if err != nil {
+			logrus.Warnf("failed to read the %s file at path %s . Error: %q", common.CopyIgnoreFilename, path, err)
+			return nil
+		}
+		patterns = append(patterns, ignorePatterns...)
+		return nil
+	}); err != nil {
+		logrus.Warnf("failed to look for the %s files in the source path %s . Error: %q", common.CopyIgnoreFilename, srcPath, err)
+	}
+	return isExcluded
+}
+
+// readSourceExcludePatterns reads the patterns of an ignore file, relative to the directory of the file
+func readSourceExcludePatterns(ignoreFilePath string) ([]sourceExcludePattern, error) {
+	file, err := os.Open(ignoreFilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	patterns := []sourceExcludePattern{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if p, ok := newSourceExcludePattern(filepath.Dir(ignoreFilePath), scanner.Text()); ok {
+			patterns = append(patterns, p)
+		}
+	}
+	return patterns, scanner.Err()
+}
@@ -0,0 +1,121 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package transformer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/filesystem"
+)
+
+func TestSourceExcludePattern(t *testing.T) {
+	baseDir := filepath.Join("/src", "app")
+	testcases := []struct {
+		name    string
+		pattern string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		{name: "name at the root", pattern: "node_modules/", path: "node_modules", isDir: true, want: true},
+		{name: "name at any depth", pattern: "node_modules/", path: "web/node_modules", isDir: true, want: true},
+		{name: "directory pattern does not match files", pattern: "node_modules/", path: "node_modules", isDir: false, want: false},
+		{name: "glob on the name", pattern: "*.log", path: "logs/server.log", want: true},
+		{name: "relative path", pattern: "build/*.log", path: "build/server.log", want: true},
+		{name: "relative path only matches from the base directory", pattern: "build/*.log", path: "web/build/server.log", want: false},
+		{name: "leading slash", pattern: "/dist/", path: "dist", isDir: true, want: true},
+		{name: "no match", pattern: "target/", path: "src/main", isDir: true, want: false},
+	}
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			p, ok := newSourceExcludePattern(baseDir, tc.pattern)
+			if !ok {
+				t.Fatalf("failed to parse the pattern %s", tc.pattern)
+			}
+			if got := p.matches(filepath.Join(baseDir, tc.path), tc.isDir); got != tc.want {
+				t.Fatalf("pattern %s on path %s: want %v, got %v", tc.pattern, tc.path, tc.want, got)
+			}
+		})
+	}
+	for _, line := range []string{"", "   ", "# a comment", "/", "[invalid"} {
+		if _, ok := newSourceExcludePattern(baseDir, line); ok {
+			t.Fatalf("expected the line '%s' to be ignored", line)
+		}
+	}
+}
+
+func TestMergeExcludingSourcePatterns(t *testing.T) {
+	srcPath := t.TempDir()
+	for path, contents := range map[string]string{
+		".git/HEAD":                           "ref: refs/heads/main",
+		"package.json":                        "{}",
+		"node_modules/express/index.js":       "",
+		"web/" + common.CopyIgnoreFilename:    "dist/\n# generated\n*.tmp\n",
+		"web/dist/bundle.js":                  "",
+		"web/src/dist.js":                     "",
+		"web/src/cache.tmp":                   "",
+		"api/dist/app.jar":                    "",
+		"api/" + common.DefaultDockerfileName: "FROM scratch",
+	} {
+		path = filepath.Join(srcPath, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create the directory. Error: %q", err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+			t.Fatalf("failed to write the file. Error: %q", err)
+		}
+	}
+	patterns := []sourceExcludePattern{}
+	for _, line := range defaultSourceExcludes {
+		p, _ := newSourceExcludePattern(srcPath, line)
+		patterns = append(patterns, p)
+	}
+	ignorePatterns, err := readSourceExcludePatterns(filepath.Join(srcPath, "web", common.CopyIgnoreFilename))
+	if err != nil {
+		t.Fatalf("failed to read the ignore file. Error: %q", err)
+	}
+	patterns = append(patterns, ignorePatterns...)
+	destPath := filepath.Join(t.TempDir(), "source")
+	if err := filesystem.MergeExcluding(srcPath, destPath, false, func(path string, isDir bool) bool {
+		for _, p := range patterns {
+			if p.matches(path, isDir) {
+				return true
+			}
+		}
+		return false
+	}, filesystem.PreserveSymlinks); err != nil {
+		t.Fatalf("failed to copy the source. Error: %q", err)
+	}
+	for path, wantCopied := range map[string]bool{
+		".git":                                false,
+		"node_modules":                        false,
+		"web/dist":                            false,
+		"web/src/cache.tmp":                   false,
+		"package.json":                        true,
+		"web/src/dist.js":                     true,
+		"api/dist/app.jar":                    true,
+		"api/" + common.DefaultDockerfileName: true,
+	} {
+		_, err := os.Stat(filepath.Join(destPath, path))
+		if copied := err == nil; copied != wantCopied {
+			t.Errorf("path %s: want copied %v, got %v", path, wantCopied, copied)
+		}
+	}
+}
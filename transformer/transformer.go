@@ -17,6 +17,7 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -26,6 +27,7 @@ import (
 	"runtime"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/konveyor/move2kube/common"
 	"github.com/konveyor/move2kube/environment"
@@ -40,6 +42,10 @@ import (
 	"github.com/konveyor/move2kube/transformer/dockerfilegenerator/windows"
 	"github.com/konveyor/move2kube/transformer/external"
 	"github.com/konveyor/move2kube/transformer/kubernetes"
+	"github.com/konveyor/move2kube/transformer/kubernetes/apiresource"
+	"github.com/konveyor/move2kube/transformer/kubernetes/irpreprocessor"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema"
+	"github.com/konveyor/move2kube/transformer/kubernetes/k8sschema/fixer"
 	"github.com/konveyor/move2kube/types"
 	environmenttypes "github.com/konveyor/move2kube/types/environment"
 	graphtypes "github.com/konveyor/move2kube/types/graph"
@@ -61,6 +67,14 @@ type Transformer interface {
 	Transform(newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error)
 }
 
+// ContextTransformer is implemented by the transformers which run commands or scripts, so that they can be stopped
+// when the planning or the transformation is cancelled, instead of only being checked for it between the transformers
+type ContextTransformer interface {
+	Transformer
+	DirectoryDetectWithContext(ctx context.Context, dir string) (services map[string][]transformertypes.Artifact, err error)
+	TransformWithContext(ctx context.Context, newArtifacts []transformertypes.Artifact, alreadySeenArtifacts []transformertypes.Artifact) ([]transformertypes.PathMapping, []transformertypes.Artifact, error)
+}
+
 type processType int
 
 const (
@@ -82,6 +96,8 @@ var (
 	transformers                 = []Transformer{}
 	invokedByDefaultTransformers = []Transformer{}
 	transformerMap               = map[string]Transformer{}
+	// servicesToTransform tracks whether the artifacts of each service in the plan have been processed
+	servicesToTransform = map[string]bool{}
 )
 
 func init() {
@@ -129,7 +145,17 @@ func init() {
 		new(kubernetes.BuildConfig),
 		new(kubernetes.Parameterizer),
 		new(kubernetes.KubernetesVersionChanger),
+		new(kubernetes.ClusterWorkloadsParser),
 		new(kubernetes.OperatorTransformer),
+		new(kubernetes.LocalClusterScript),
+		new(kubernetes.FluentBit),
+		new(kubernetes.Carvel),
+		new(kubernetes.Terraform),
+		new(kubernetes.Cdk8s),
+
+		new(IRExporter),
+		new(IRImporter),
+		new(DevConfigGenerator),
 
 		new(ReadMeGenerator),
 	}
@@ -200,6 +226,8 @@ func InitTransformers(transformerYamlPaths map[string]string, selector labels.Se
 			selector = selector.Add(reqs...)
 		}
 	}
+	fixer.LoadUsageMetrics(sourcePath)
+	fixer.LoadCustomFixers(filepath.Join(common.AssetsPath, "custom"))
 	transformerConfigs := getFilteredTransformers(transformerYamlPaths, selector, logError)
 	deselectedTransformers := map[string]string{}
 	for transformerName, transformerPath := range transformerYamlPaths {
@@ -300,6 +328,30 @@ func Destroy() {
 	}
 }
 
+// Reset removes the transformers initialized for a previous run, so that they are initialized again in the next run
+func Reset() {
+	initialized = false
+	transformers = []Transformer{}
+	invokedByDefaultTransformers = []Transformer{}
+	transformerMap = map[string]Transformer{}
+	servicesToTransform = map[string]bool{}
+	kubernetes.ResetEnabledTransformers()
+	fixer.Reset()
+}
+
+// SetProject changes the name of the project and the output directory the initialized transformers generate the output for
+func SetProject(projectName, outputPath string) {
+	common.ProjectName = projectName
+	for _, t := range transformers {
+		_, env := t.GetConfig()
+		for _, e := range append([]*environment.Environment{env}, env.Children...) {
+			e.ProjectName = projectName
+			e.Output = outputPath
+			e.CurrEnvOutputBasePath = ""
+		}
+	}
+}
+
 // GetInitializedTransformers returns the list of initialized transformers
 func GetInitializedTransformers() []Transformer {
 	return transformers
@@ -329,24 +381,31 @@ func GetInitializedTransformersF(filters labels.Selector) []Transformer {
 	return filteredTransformers
 }
 
-// GetServices returns the list of services detected in a directory
-func GetServices(prjName string, dir string) (map[string][]plantypes.PlanArtifact, error) {
+// GetServices returns the list of services detected in a directory.
+// Planning stops with an error when the context is cancelled.
+func GetServices(ctx context.Context, prjName string, dir string) (map[string][]plantypes.PlanArtifact, error) {
 	planServices := map[string][]plantypes.PlanArtifact{}
 	logrus.Infoln("Planning started on the base directory")
 	logrus.Debugf("Transformers: %+v", transformers)
+	baseDetectTransformers := []Transformer{}
 	for _, transformer := range transformers {
+		if config, _ := transformer.GetConfig(); config.Spec.DirectoryDetect.Levels == 1 {
+			baseDetectTransformers = append(baseDetectTransformers, transformer)
+		}
+	}
+	for i, transformer := range baseDetectTransformers {
+		if err := ctx.Err(); err != nil {
+			return planServices, fmt.Errorf("planning was stopped. Error: %w", err)
+		}
 		config, env := transformer.GetConfig()
 		if err := env.Reset(); err != nil {
 			logrus.Errorf("failed to reset the environment for the transformer %s . Error: %q", config.Name, err)
 			continue
 		}
-		if config.Spec.DirectoryDetect.Levels != 1 {
-			continue
-		}
-		logrus.Infof("[%s] Planning", config.Name)
-		newServices, err := transformer.DirectoryDetect(env.Encode(dir).(string))
+		logrus.WithField(common.LogFieldTransformer, config.Name).Infof("[%s] Planning", config.Name)
+		newServices, err := directoryDetect(ctx, transformer, env.Encode(dir).(string))
 		if err != nil {
-			logrus.Errorf("[%s] failed to look for services in the directory '%s' . Error: %q", config.Name, dir, err)
+			logrus.WithFields(logrus.Fields{common.LogFieldTransformer: config.Name, common.LogFieldFile: dir}).Errorf("[%s] failed to look for services in the directory '%s' . Error: %q", config.Name, dir, err)
 			continue
 		}
 		newPlanServices := getPlanArtifactsFromArtifacts(*env.Decode(&newServices).(*map[string][]transformertypes.Artifact), config)
@@ -355,12 +414,16 @@ func GetServices(prjName string, dir string) (map[string][]plantypes.PlanArtifac
 			logrus.Infof(getNamedAndUnNamedServicesLogMessage(newPlanServices))
 		}
 		common.PlanProgressNumBaseDetectTransformers++
-		logrus.Infof("[%s] Done", config.Name)
+		common.ReportProgress(common.ProgressEvent{Stage: common.PlanProgressStage, Type: common.TransformerFinishedProgressEvent, Name: config.Name, Current: i + 1, Total: len(baseDetectTransformers)})
+		logrus.WithField(common.LogFieldTransformer, config.Name).Infof("[%s] Done", config.Name)
 	}
 	logrus.Infof("[Base Directory] %s", getNamedAndUnNamedServicesLogMessage(planServices))
 	logrus.Infoln("Planning finished on the base directory")
 	logrus.Infoln("Planning started on its sub directories")
-	nservices, err := walkForServices(dir, planServices)
+	nservices, err := walkForServices(ctx, dir, planServices)
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return planServices, fmt.Errorf("planning was stopped. Error: %w", ctxErr)
+	}
 	if err != nil {
 		logrus.Errorf("Transformation planning - Directory Walk failed : %s", err)
 	} else {
@@ -368,12 +431,12 @@ func GetServices(prjName string, dir string) (map[string][]plantypes.PlanArtifac
 		logrus.Infoln("Planning finished on its sub directories")
 	}
 	logrus.Infof("[Directory Walk] %s", getNamedAndUnNamedServicesLogMessage(planServices))
-	planServices = nameServices(prjName, planServices)
+	planServices = SanitizeServiceNames(nameServices(prjName, planServices))
 	logrus.Infof("[Named Services] Identified %d named services", len(planServices))
 	return planServices, nil
 }
 
-func walkForServices(inputPath string, bservices map[string][]plantypes.PlanArtifact) (map[string][]plantypes.PlanArtifact, error) {
+func walkForServices(ctx context.Context, inputPath string, bservices map[string][]plantypes.PlanArtifact) (map[string][]plantypes.PlanArtifact, error) {
 	services := bservices
 	ignoreDirectories, ignoreContents := getIgnorePaths(inputPath)
 	knownServiceDirPaths := []string{}
@@ -383,6 +446,9 @@ func walkForServices(inputPath string, bservices map[string][]plantypes.PlanArti
 			logrus.Warnf("Skipping path %q due to error. Error: %q", path, err)
 			return nil
 		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
 		if !info.IsDir() {
 			return nil
 		}
@@ -414,7 +480,7 @@ func walkForServices(inputPath string, bservices map[string][]plantypes.PlanArti
 			if config.Spec.DirectoryDetect.Levels == 1 || config.Spec.DirectoryDetect.Levels == 0 {
 				continue
 			}
-			newServicesToArtifacts, err := transformer.DirectoryDetect(env.Encode(path).(string))
+			newServicesToArtifacts, err := directoryDetect(ctx, transformer, env.Encode(path).(string))
 			if err != nil {
 				logrus.Warnf("[%s] directory detect failed. Error: %q", config.Name, err)
 				continue
@@ -473,21 +539,45 @@ func summarizePathMappings(pathMappings []transformertypes.PathMapping) string {
 	return strings.Join(paths, "\n")
 }
 
-// Transform transforms as per the plan
-func Transform(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath string) error {
+// Transform transforms as per the plan.
+// When the context is cancelled, the transformer that is running is allowed to finish and then an error is returned.
+func Transform(ctx context.Context, planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath string) error {
 	var allArtifacts []transformertypes.Artifact
 	newArtifactsToProcess := []transformertypes.Artifact{}
 	pathMappings := []transformertypes.PathMapping{}
 	defaultNewArtifactsToProcess := []transformertypes.Artifact{}
 	iteration := 1
+	transformationFailures = []ReportFailure{}
+	startedOn := time.Now()
+	apiresource.ResetIgnoredObjects()
+	apiresource.ResetClusterFeatureDecisions()
+	irpreprocessor.ResetHostSubstitutions()
+	irpreprocessor.ResetHealthProbeScaffolds()
+	irpreprocessor.ResetImageDigests()
+	k8sschema.ResetCustomResourceValidation()
+	dockerfile.ResetImageBuildDependencies()
+	initStage(sourceDir, outputPath)
+	defer resetStage()
+	keptHandAuthoredFiles = map[string]bool{}
+	pathMappingProducers = []pathMappingProducer{}
+	servicesToTransform = map[string]bool{}
+	writeFailures = map[string]string{}
+	handAuthoredFiles, err := GetHandAuthoredFiles(outputPath)
+	if err != nil {
+		return err
+	}
 	// transform default transformers
 	graph := graphtypes.NewGraph()
 	startVertexId := graph.AddVertex("start", iteration, nil)
 	for _, invokedByDefaultTransformer := range invokedByDefaultTransformers {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("the transformation was stopped. Error: %w", err)
+		}
 		tDefaultConfig, defaultEnv := invokedByDefaultTransformer.GetConfig()
-		newPathMappings, defaultArtifacts, err := runSingleTransform(nil, nil, invokedByDefaultTransformer, tDefaultConfig, defaultEnv, graph, iteration)
+		newPathMappings, defaultArtifacts, err := runSingleTransform(ctx, nil, nil, invokedByDefaultTransformer, tDefaultConfig, defaultEnv, graph, iteration)
 		if err != nil {
-			logrus.Errorf("failed to transform using the transformer %s. Error: %q", tDefaultConfig.Name, err)
+			logrus.WithField(common.LogFieldTransformer, tDefaultConfig.Name).Errorf("failed to transform using the transformer %s. Error: %q", tDefaultConfig.Name, err)
+			recordTransformationFailure(tDefaultConfig.Name, iteration, err)
 		}
 		defaultNewArtifactsToProcess = append(defaultNewArtifactsToProcess, defaultArtifacts...)
 		pathMappings = append(pathMappings, newPathMappings...)
@@ -509,6 +599,7 @@ func Transform(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath str
 		}
 		planArtifact.Configs[artifacts.ServiceConfigType] = serviceConfig
 		newArtifactsToProcess = append(newArtifactsToProcess, planArtifact.Artifact)
+		servicesToTransform[planArtifact.ServiceName] = false
 	}
 
 	// logging
@@ -522,14 +613,18 @@ func Transform(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath str
 	for {
 		iteration++
 		logrus.Infof("Iteration %d - %d artifacts to process", iteration, len(newArtifactsToProcess))
-		newPathMappings, newArtifacts, _ := transform(newArtifactsToProcess, allArtifacts, consume, nil, graph, iteration)
+		newPathMappings, newArtifacts, _ := transform(ctx, newArtifactsToProcess, allArtifacts, consume, nil, graph, iteration)
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("the transformation was stopped in iteration %d . Error: %w", iteration, err)
+		}
 		pathMappings = append(pathMappings, newPathMappings...)
-		if err := os.RemoveAll(outputPath); err != nil {
-			return fmt.Errorf("failed to remove the output directory %s . Error: %q", outputPath, err)
+		if err := resetOutputToStagedSources(outputPath); err != nil {
+			return fmt.Errorf("failed to reset the output directory %s . Error: %q", outputPath, err)
 		}
 		if err := processPathMappings(pathMappings, sourceDir, outputPath); err != nil {
 			return fmt.Errorf("failed to process the path mappings: %+v . Error: %q", pathMappings, err)
 		}
+		reportFilesWritten(outputPath, iteration)
 		if len(newArtifacts) == 0 {
 			break
 		}
@@ -538,8 +633,30 @@ func Transform(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath str
 		newArtifactsToProcess = newArtifacts
 	}
 
+	if err := encryptSecretManifests(outputPath); err != nil {
+		logrus.Errorf("failed to encrypt the Secret manifests. Error: %q", err)
+	}
+	kept, err := RestoreHandAuthoredFiles(outputPath, handAuthoredFiles)
+	if err != nil {
+		logrus.Errorf("failed to keep the hand authored files in the output directory %s . Error: %q", outputPath, err)
+	}
+	for _, relPath := range kept {
+		keptHandAuthoredFiles[relPath] = true
+	}
+	if err := writeTransformationReport(getTransformationReport(planArtifacts, allArtifacts, outputPath), outputPath); err != nil {
+		logrus.Errorf("failed to write the transformation report. Error: %q", err)
+	}
+	if err := writeConversionsReport(outputPath); err != nil {
+		logrus.Errorf("failed to write the conversions report. Error: %q", err)
+	}
+	if err := writeOutputManifest(outputPath); err != nil {
+		logrus.Errorf("failed to write the output manifest. Error: %q", err)
+	} else if err := writeProvenance(outputPath, sourceDir, startedOn); err != nil {
+		logrus.Errorf("failed to write the provenance. Error: %q", err)
+	}
+
 	// logging
-	{
+	if !common.DryRun {
 		graphFilePath := graphtypes.GraphFileName
 		graphFile, err := os.Create(graphFilePath)
 		if err != nil {
@@ -554,16 +671,22 @@ func Transform(planArtifacts []plantypes.PlanArtifact, sourceDir, outputPath str
 	}
 	// logging
 
+	if len(transformationFailures) != 0 {
+		return &TransformationFailedError{Failures: transformationFailures}
+	}
 	return nil
 }
 
-func transform(newArtifactsToProcess, allArtifacts []transformertypes.Artifact, pt processType, depSel labels.Selector, graph *graphtypes.Graph, iteration int) (pathMappings []transformertypes.PathMapping, newArtifactsCreated, updatedArtifacts []transformertypes.Artifact) {
+func transform(ctx context.Context, newArtifactsToProcess, allArtifacts []transformertypes.Artifact, pt processType, depSel labels.Selector, graph *graphtypes.Graph, iteration int) (pathMappings []transformertypes.PathMapping, newArtifactsCreated, updatedArtifacts []transformertypes.Artifact) {
 	logrus.Trace("transform start")
 	defer logrus.Trace("transform end")
 	if pt == dependency && (depSel == nil || depSel.String() == "") {
 		return nil, nil, newArtifactsToProcess
 	}
 	for _, transformer := range transformers {
+		if ctx.Err() != nil {
+			break
+		}
 		tConfig, env := transformer.GetConfig()
 		if pt == dependency && !depSel.Matches(labels.Set(tConfig.Labels)) {
 			continue
@@ -576,7 +699,7 @@ func transform(newArtifactsToProcess, allArtifacts []transformertypes.Artifact,
 		logrus.Debugf("Transformer %s will be processing %d artifacts in %d mode", tConfig.Name, len(artifactsToProcess), pt)
 
 		// Dependency processing
-		dependencyCreatedNewPathMappings, dependencyCreatedNewArtifacts, dependencyUpdatedArtifacts := transform(artifactsToProcess, allArtifacts, dependency, tConfig.Spec.DependencySelector, graph, iteration)
+		dependencyCreatedNewPathMappings, dependencyCreatedNewArtifacts, dependencyUpdatedArtifacts := transform(ctx, artifactsToProcess, allArtifacts, dependency, tConfig.Spec.DependencySelector, graph, iteration)
 		pathMappings = append(pathMappings, dependencyCreatedNewPathMappings...)
 		// Dependency processing
 
@@ -585,12 +708,13 @@ func transform(newArtifactsToProcess, allArtifacts []transformertypes.Artifact,
 			logrus.Errorf("Artifacts to not consume: %d. This should have been 0.", len(artifactsToNotConsume))
 		}
 
-		logrus.Infof("Transformer %s processing %d artifacts", tConfig.Name, len(artifactsToConsume))
+		logrus.WithField(common.LogFieldTransformer, tConfig.Name).Infof("Transformer %s processing %d artifacts", tConfig.Name, len(artifactsToConsume))
 
-		producedNewPathMappings, producedNewArtifacts, err := runSingleTransform(artifactsToConsume, allArtifacts, transformer, tConfig, env, graph, iteration)
+		producedNewPathMappings, producedNewArtifacts, err := runSingleTransform(ctx, artifactsToConsume, allArtifacts, transformer, tConfig, env, graph, iteration)
 		if err != nil {
-			logrus.Errorf("failed to run a single transformation using the transformer %+v on the artifacts: %+v", tConfig, artifactsToConsume)
-			logrus.Error(err.Error())
+			logrus.WithField(common.LogFieldTransformer, tConfig.Name).Errorf("failed to run a single transformation using the transformer %+v on the artifacts: %+v", tConfig, artifactsToConsume)
+			logrus.WithField(common.LogFieldTransformer, tConfig.Name).Error(err.Error())
+			recordTransformationFailure(tConfig.Name, iteration, err)
 			continue
 		}
 		pathMappings = append(pathMappings, producedNewPathMappings...)
@@ -609,7 +733,7 @@ func transform(newArtifactsToProcess, allArtifacts []transformertypes.Artifact,
 			}
 		}
 
-		passedThroughPathMappings, passedThroughNewArtifactsCreated, passedThroughUpdatedArtifacts := transform(artifactsToPassThrough, allArtifacts, passthrough, nil, graph, iteration)
+		passedThroughPathMappings, passedThroughNewArtifactsCreated, passedThroughUpdatedArtifacts := transform(ctx, artifactsToPassThrough, allArtifacts, passthrough, nil, graph, iteration)
 
 		pathMappings = append(pathMappings, passedThroughPathMappings...)
 		newArtifactsCreated = append(newArtifactsCreated, passedThroughNewArtifactsCreated...)
@@ -622,7 +746,10 @@ func transform(newArtifactsToProcess, allArtifacts []transformertypes.Artifact,
 			newArtifactsToProcess = append(newArtifactsToProcess, passedThroughUpdatedArtifacts...)
 			newArtifactsToProcess = append(newArtifactsToProcess, artifactsAlreadyPassedThrough...)
 		}
-		logrus.Infof("Transformer %s Done", tConfig.Name)
+		if pt == consume {
+			common.ReportProgress(common.ProgressEvent{Stage: common.TransformProgressStage, Type: common.TransformerFinishedProgressEvent, Name: tConfig.Name, Message: fmt.Sprintf("processed %d artifacts in iteration %d", len(artifactsToConsume), iteration)})
+		}
+		logrus.WithField(common.LogFieldTransformer, tConfig.Name).Infof("Transformer %s Done", tConfig.Name)
 	}
 	if pt == passthrough || pt == dependency {
 		logrus.Debugf("Created %d pathMappings, %d artifacts, %d updated artifacts from transform while passing through/dependency.", len(pathMappings), len(newArtifactsCreated), len(newArtifactsToProcess))
@@ -632,16 +759,27 @@ func transform(newArtifactsToProcess, allArtifacts []transformertypes.Artifact,
 	return pathMappings, newArtifactsCreated, nil
 }
 
-func runSingleTransform(artifactsToProcess, allArtifacts []transformertypes.Artifact, transformer Transformer, tconfig transformertypes.Transformer, env *environment.Environment, graph *graphtypes.Graph, iteration int) (newPathMappings []transformertypes.PathMapping, newArtifacts []transformertypes.Artifact, err error) {
+// directoryDetect runs the directory detect of the transformer with the context, when the transformer supports it
+func directoryDetect(ctx context.Context, transformer Transformer, dir string) (map[string][]transformertypes.Artifact, error) {
+	if contextTransformer, ok := transformer.(ContextTransformer); ok {
+		return contextTransformer.DirectoryDetectWithContext(ctx, dir)
+	}
+	return transformer.DirectoryDetect(dir)
+}
+
+func runSingleTransform(ctx context.Context, artifactsToProcess, allArtifacts []transformertypes.Artifact, transformer Transformer, tconfig transformertypes.Transformer, env *environment.Environment, graph *graphtypes.Graph, iteration int) (newPathMappings []transformertypes.PathMapping, newArtifacts []transformertypes.Artifact, err error) {
 	logrus.Trace("runSingleTransform start")
 	defer logrus.Trace("runSingleTransform end")
 	if err := env.Reset(); err != nil {
 		return nil, nil, fmt.Errorf("failed to reset the environment: %+v Error: %q", env, err)
 	}
-	newPathMappings, newArtifacts, err = transformer.Transform(
-		*env.Encode(&artifactsToProcess).(*[]transformertypes.Artifact),
-		*env.Encode(&allArtifacts).(*[]transformertypes.Artifact),
-	)
+	encodedArtifactsToProcess := *env.Encode(&artifactsToProcess).(*[]transformertypes.Artifact)
+	encodedAllArtifacts := *env.Encode(&allArtifacts).(*[]transformertypes.Artifact)
+	if contextTransformer, ok := transformer.(ContextTransformer); ok {
+		newPathMappings, newArtifacts, err = contextTransformer.TransformWithContext(ctx, encodedArtifactsToProcess, encodedAllArtifacts)
+	} else {
+		newPathMappings, newArtifacts, err = transformer.Transform(encodedArtifactsToProcess, encodedAllArtifacts)
+	}
 	// logging
 	{
 		vertexName := fmt.Sprintf("iteration: %d\nclass: %s\nname: %s", iteration, tconfig.Spec.Class, tconfig.Name)
@@ -709,9 +847,47 @@ func runSingleTransform(artifactsToProcess, allArtifacts []transformertypes.Arti
 	}
 	newArtifacts = *env.DownloadAndDecode(&newArtifacts, false).(*[]transformertypes.Artifact)
 	newArtifacts = postProcessArtifacts(newArtifacts, tconfig)
+	reportServicesTransformed(artifactsToProcess)
+	recordPathMappingProducers(newPathMappings, tconfig.Name)
 	return newPathMappings, newArtifacts, nil
 }
 
+// reportServicesTransformed reports the services in the plan whose artifacts were processed for the first time
+func reportServicesTransformed(artifactsProcessed []transformertypes.Artifact) {
+	for _, artifact := range artifactsProcessed {
+		serviceConfig := artifacts.ServiceConfig{}
+		if err := artifact.GetConfig(artifacts.ServiceConfigType, &serviceConfig); err != nil {
+			continue
+		}
+		if done, ok := servicesToTransform[serviceConfig.ServiceName]; !ok || done {
+			continue
+		}
+		servicesToTransform[serviceConfig.ServiceName] = true
+		numTransformed := 0
+		for _, done := range servicesToTransform {
+			if done {
+				numTransformed++
+			}
+		}
+		common.ReportProgress(common.ProgressEvent{Stage: common.TransformProgressStage, Type: common.ServiceTransformedProgressEvent, Name: serviceConfig.ServiceName, Current: numTransformed, Total: len(servicesToTransform)})
+	}
+}
+
+// reportFilesWritten reports the number of files in the output directory
+func reportFilesWritten(outputPath string, iteration int) {
+	numFiles := 0
+	if err := filepath.WalkDir(outputPath, func(_ string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			numFiles++
+		}
+		return nil
+	}); err != nil {
+		logrus.Debugf("failed to count the files in the output directory %s . Error: %q", outputPath, err)
+		return
+	}
+	common.ReportProgress(common.ProgressEvent{Stage: common.TransformProgressStage, Type: common.FilesWrittenProgressEvent, Name: outputPath, Current: numFiles, Message: fmt.Sprintf("%d files in the output directory after iteration %d", numFiles, iteration)})
+}
+
 func getArtifactsToProcess(newArtifactsToProcess, allArtifacts []transformertypes.Artifact, tConfig transformertypes.Transformer, pt processType) ([]transformertypes.Artifact, []transformertypes.Artifact) {
 	artifactsToProcess := []transformertypes.Artifact{}
 	artifactsToNotProcess := []transformertypes.Artifact{}
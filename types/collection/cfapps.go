@@ -41,6 +41,17 @@ type CfApps struct {
 type CfApp struct {
 	Application cfclient.App    `yaml:"application"`
 	Environment cfclient.AppEnv `yaml:"environment"`
+	Routes      []CfRoute       `yaml:"routes,omitempty"`
+}
+
+// CfRoute defines a route of a CfApp, along with the route service bound to it
+type CfRoute struct {
+	Route  cfclient.Route `yaml:"route"`
+	Domain string         `yaml:"domain,omitempty"`
+	// RouteService is the name of the service instance processing the requests of the route before they reach the app
+	RouteService string `yaml:"routeService,omitempty"`
+	// RouteServiceURL is the url of the route service, when it is a user provided service instance
+	RouteServiceURL string `yaml:"routeServiceURL,omitempty"`
 }
 
 // CfAppsSpec stores the data
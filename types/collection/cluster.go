@@ -75,9 +75,25 @@ func (c *ClusterMetadata) isEmpty() bool {
 
 // ClusterMetadataSpec stores the data
 type ClusterMetadataSpec struct {
-	StorageClasses    []string            `yaml:"storageClasses"`
-	APIKindVersionMap map[string][]string `yaml:"apiKindVersionMap"` //[kubernetes kind]["gv1", "gv2",...,"gvn"] prioritized group-version
-	Host              string              `yaml:"host,omitempty"`    // Optional field, either collected with move2kube collect or by asking the user.
+	StorageClasses            []string                   `yaml:"storageClasses"`
+	APIKindVersionMap         map[string][]string        `yaml:"apiKindVersionMap"`                   //[kubernetes kind]["gv1", "gv2",...,"gvn"] prioritized group-version
+	Host                      string                     `yaml:"host,omitempty"`                      // Optional field, either collected with move2kube collect or by asking the user.
+	ServerVersion             string                     `yaml:"serverVersion,omitempty"`             // Optional field, collected from the cluster API server
+	DefaultStorageClass       string                     `yaml:"defaultStorageClass,omitempty"`       // Optional field, the storage class annotated as default in the cluster
+	IngressController         string                     `yaml:"ingressController,omitempty"`         // Optional field, the ingress controller preset used by default for the cluster
+	IngressClasses            []string                   `yaml:"ingressClasses,omitempty"`            // Optional field, the ingress classes collected from the cluster
+	CustomResourceDefinitions []CustomResourceDefinition `yaml:"customResourceDefinitions,omitempty"` // Optional field, the CRDs installed in the cluster
+}
+
+// CustomResourceDefinition stores the details of a custom resource definition installed in the cluster
+type CustomResourceDefinition struct {
+	Name     string   `yaml:"name"`
+	Group    string   `yaml:"group"`
+	Kind     string   `yaml:"kind"`
+	Scope    string   `yaml:"scope,omitempty"`
+	Versions []string `yaml:"versions"`
+	// Schemas stores the openAPIV3Schema of each served version, used to validate the custom resources
+	Schemas map[string]interface{} `yaml:"schemas,omitempty"`
 }
 
 // Merge helps merge clustermetadata
@@ -102,9 +118,41 @@ func (c *ClusterMetadataSpec) Merge(newc ClusterMetadataSpec) bool {
 	}
 	c.APIKindVersionMap = apiversionkindmap
 	c.Host = newc.Host
+	if newc.ServerVersion != "" {
+		c.ServerVersion = newc.ServerVersion
+	}
+	if newc.IngressController != "" {
+		c.IngressController = newc.IngressController
+	}
+	if len(newc.IngressClasses) != 0 {
+		c.IngressClasses = newc.IngressClasses
+	}
+	if common.IsPresent(c.StorageClasses, newc.DefaultStorageClass) {
+		c.DefaultStorageClass = newc.DefaultStorageClass
+	} else if !common.IsPresent(c.StorageClasses, c.DefaultStorageClass) {
+		c.DefaultStorageClass = ""
+	}
+	// Allow only intersection of custom resource definitions
+	var crds []CustomResourceDefinition
+	for _, crd := range c.CustomResourceDefinitions {
+		if newc.GetCustomResourceDefinition(crd.Group, crd.Kind) != nil {
+			crds = append(crds, crd)
+		}
+	}
+	c.CustomResourceDefinitions = crds
 	return true
 }
 
+// GetCustomResourceDefinition returns the custom resource definition for the group and kind if it is installed in the cluster
+func (c *ClusterMetadataSpec) GetCustomResourceDefinition(group, kind string) *CustomResourceDefinition {
+	for i, crd := range c.CustomResourceDefinitions {
+		if crd.Group == group && crd.Kind == kind {
+			return &c.CustomResourceDefinitions[i]
+		}
+	}
+	return nil
+}
+
 // GetSupportedVersions returns all the group version supported for the kind in this cluster
 func (c *ClusterMetadataSpec) GetSupportedVersions(kind string) []string {
 	if gvList, ok := c.APIKindVersionMap[kind]; ok {
@@ -102,6 +102,19 @@ func TestMerge(t *testing.T) {
 			t.Fatalf("Failed to merge ClusterMetadata properly. Difference:\n%s:", cmp.Diff(want, cmeta1))
 		}
 	})
+
+	t.Run("merging the ingress controller keeps the old one when the new one is empty", func(t *testing.T) {
+		cmeta1 := collection.NewClusterMetadata("")
+		cmeta1.Spec.IngressController = "nginx"
+		cmeta2 := collection.NewClusterMetadata("")
+		if merged := cmeta1.Merge(cmeta2); !merged || cmeta1.Spec.IngressController != "nginx" {
+			t.Fatalf("expected the ingress controller nginx to be kept. Actual: %q", cmeta1.Spec.IngressController)
+		}
+		cmeta2.Spec.IngressController = "traefik"
+		if merged := cmeta1.Merge(cmeta2); !merged || cmeta1.Spec.IngressController != "traefik" {
+			t.Fatalf("expected the ingress controller traefik to be merged. Actual: %q", cmeta1.Spec.IngressController)
+		}
+	})
 }
 
 func TestGetSupportedVersions(t *testing.T) {
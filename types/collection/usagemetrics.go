@@ -0,0 +1,77 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collection
+
+import (
+	"github.com/konveyor/move2kube/types"
+)
+
+// UsageMetricsKind defines the kind of the usage metrics file
+const UsageMetricsKind types.Kind = "UsageMetrics"
+
+// UsageMetricsSourceType defines where the usage metrics were collected from
+type UsageMetricsSourceType string
+
+const (
+	// PrometheusUsageMetricsSourceType represents the percentiles computed by Prometheus over the collection window
+	PrometheusUsageMetricsSourceType UsageMetricsSourceType = "Prometheus"
+	// MetricsServerUsageMetricsSourceType represents the current usage reported by the metrics-server
+	MetricsServerUsageMetricsSourceType UsageMetricsSourceType = "MetricsServer"
+)
+
+// UsageMetrics stores the observed cpu and memory usage of the workloads in a cluster
+type UsageMetrics struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty"`
+	Spec             UsageMetricsSpec `yaml:"spec,omitempty"`
+}
+
+// UsageMetricsSpec stores the usage of the workloads
+type UsageMetricsSpec struct {
+	Source    UsageMetricsSourceType `yaml:"source"`
+	Cluster   string                 `yaml:"cluster,omitempty"`
+	Window    string                 `yaml:"window,omitempty"` // Duration over which the percentiles are computed
+	Workloads []WorkloadUsage        `yaml:"workloads"`
+}
+
+// WorkloadUsage stores the usage of the containers of a workload, the maximum over its pods
+type WorkloadUsage struct {
+	Name       string           `yaml:"name"`
+	Namespace  string           `yaml:"namespace"`
+	Kind       string           `yaml:"kind,omitempty"`
+	Containers []ContainerUsage `yaml:"containers"`
+}
+
+// ContainerUsage stores the 95th percentile of the cpu and memory usage of a container, as kubernetes quantities
+type ContainerUsage struct {
+	Name   string `yaml:"name"`
+	CPU    string `yaml:"cpu,omitempty"`
+	Memory string `yaml:"memory,omitempty"`
+}
+
+// NewUsageMetrics creates a new instance of UsageMetrics
+func NewUsageMetrics(name string) UsageMetrics {
+	return UsageMetrics{
+		TypeMeta: types.TypeMeta{
+			Kind:       string(UsageMetricsKind),
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: types.ObjectMeta{
+			Name: name,
+		},
+	}
+}
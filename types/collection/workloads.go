@@ -0,0 +1,67 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package collection
+
+import (
+	"github.com/konveyor/move2kube/types"
+)
+
+// WorkloadsMetadataKind defines the kind of the workloads metadata file
+const WorkloadsMetadataKind types.Kind = "WorkloadsMetadata"
+
+// WorkloadsSourceType defines where the workloads were collected from
+type WorkloadsSourceType string
+
+const (
+	// HelmReleaseWorkloadsSourceType represents workloads collected from a deployed helm release
+	HelmReleaseWorkloadsSourceType WorkloadsSourceType = "HelmRelease"
+	// ClusterWorkloadsSourceType represents workloads collected directly from a namespace in the cluster
+	ClusterWorkloadsSourceType WorkloadsSourceType = "Cluster"
+)
+
+// WorkloadsMetadata stores the metadata of the workloads collected from a cluster
+type WorkloadsMetadata struct {
+	types.TypeMeta   `yaml:",inline"`
+	types.ObjectMeta `yaml:"metadata,omitempty"`
+	Spec             WorkloadsMetadataSpec `yaml:"spec,omitempty"`
+}
+
+// WorkloadsMetadataSpec stores the details of the collected workloads
+type WorkloadsMetadataSpec struct {
+	Source       WorkloadsSourceType `yaml:"source"`
+	Cluster      string              `yaml:"cluster,omitempty"`
+	Namespace    string              `yaml:"namespace"`
+	ReleaseName  string              `yaml:"releaseName,omitempty"`
+	Revision     int                 `yaml:"revision,omitempty"`
+	ChartName    string              `yaml:"chartName,omitempty"`
+	ChartVersion string              `yaml:"chartVersion,omitempty"`
+	AppVersion   string              `yaml:"appVersion,omitempty"`
+	Manifests    []string            `yaml:"manifests"` // Paths of the manifests relative to the metadata file
+}
+
+// NewWorkloadsMetadata creates a new instance of WorkloadsMetadata
+func NewWorkloadsMetadata(name string) WorkloadsMetadata {
+	return WorkloadsMetadata{
+		TypeMeta: types.TypeMeta{
+			Kind:       string(WorkloadsMetadataKind),
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: types.ObjectMeta{
+			Name: name,
+		},
+	}
+}
@@ -59,6 +59,14 @@ type IR struct {
 	ContainerImages map[string]ContainerImage // [imageName]
 	Services        map[string]Service
 	Storages        []Storage
+	// ExternalServices are the dependencies which are not migrated, reached through ExternalName services
+	ExternalServices map[string]ExternalService
+}
+
+// ExternalService is a dependency outside the cluster, like a managed database, with an in-cluster DNS name
+type ExternalService struct {
+	Name         string
+	ExternalName string // Host name of the dependency
 }
 
 // PodSpec is type alias for core.PodSpec
@@ -101,10 +109,45 @@ type Service struct {
 	Annotations                 map[string]string
 	Labels                      map[string]string
 	ServiceToPodPortForwardings []ServiceToPodPortForwarding
+	ServiceAnnotations          map[string]string // Annotations set only on the k8s service, like the annotations of the load balancers of the clouds
 	Replicas                    int
+	RolloutStrategy             RolloutStrategy  // How the pods are replaced when the service is updated
+	ServiceBindings             []ServiceBinding // Bindings of the backing services, projected into the pods by a servicebinding.io controller
 	Networks                    []string
+	DependsOn                   []string // Names of the services which should be reachable before this service starts
+	Aliases                     []string // Other host names and IP addresses the service is reached with in the source, like the container names, the network aliases and the static IP addresses
+	Schedule                    string   // Cron schedule of the service, gets converted to CronJob
 	OnlyIngress                 bool
-	Daemon                      bool //Gets converted to DaemonSet
+	Daemon                      bool  //Gets converted to DaemonSet
+	Stateful                    bool  //Gets converted to StatefulSet with a headless service
+	SessionAffinity             bool  // The requests of a client should reach the same pod, like the apps using sticky sessions need
+	PreStopDelaySeconds         int32 // Seconds the containers wait before stopping, so that the endpoint is removed from the load balancers and the connections drain
+}
+
+// RolloutStrategyType is the way the pods of a service are replaced
+type RolloutStrategyType string
+
+const (
+	// RollingUpdateRolloutStrategy replaces the pods a few at a time
+	RollingUpdateRolloutStrategy RolloutStrategyType = "RollingUpdate"
+	// RecreateRolloutStrategy stops all the old pods before starting the new ones
+	RecreateRolloutStrategy RolloutStrategyType = "Recreate"
+)
+
+// RolloutStrategy stores how the pods of a service are replaced, the defaults of the workload kinds are used for the empty fields
+type RolloutStrategy struct {
+	Type                 RolloutStrategyType
+	MaxSurge             string // Number or percentage of pods created above the replicas during a rolling update
+	MaxUnavailable       string // Number or percentage of pods which can be unavailable during a rolling update
+	RevisionHistoryLimit *int32
+}
+
+// ServiceBinding binds the service to a backing service, whose credentials are in a secret with the layout of the servicebinding.io specification
+type ServiceBinding struct {
+	Name       string
+	SecretName string
+	Type       string
+	Provider   string
 }
 
 // ServiceToPodPortForwarding forwards a k8s service port to a k8s pod port
@@ -112,7 +155,35 @@ type ServiceToPodPortForwarding struct {
 	ServicePort    networking.ServiceBackendPort
 	PodPort        networking.ServiceBackendPort
 	ServiceRelPath string
-	ServiceType    core.ServiceType
+	// AdditionalServiceRelPaths are the other paths routed to the port, like the base paths of the APIs served on it
+	AdditionalServiceRelPaths []string
+	ServiceType               core.ServiceType
+	// Protocol is the transport protocol of the port, TCP when empty
+	Protocol core.Protocol
+	// HostPort is the port the source published the port on, on the hosts. It is zero when the port was not published.
+	HostPort int32
+}
+
+const (
+	// minNodePort and maxNodePort are the bounds of the default node port range of the clusters
+	minNodePort = 30000
+	maxNodePort = 32767
+)
+
+// GetNodePort returns the host port, when it can be kept as the node port of a NodePort service, and zero otherwise
+func (forwarding ServiceToPodPortForwarding) GetNodePort() int32 {
+	if forwarding.HostPort < minNodePort || forwarding.HostPort > maxNodePort {
+		return 0
+	}
+	return forwarding.HostPort
+}
+
+// GetProtocol returns the transport protocol of the port forwarding
+func (forwarding ServiceToPodPortForwarding) GetProtocol() core.Protocol {
+	if forwarding.Protocol == "" {
+		return core.ProtocolTCP
+	}
+	return forwarding.Protocol
 }
 
 // ContainerBuildTypeValue stores the container build type
@@ -134,6 +205,8 @@ type ContainerBuild struct {
 	ContainerBuildType ContainerBuildTypeValue                      `yaml:"-"`
 	ContextPath        string                                       `yaml:"-"`
 	Artifacts          map[ContainerBuildArtifactTypeValue][]string `yaml:"-"` //[artifacttype]value
+	BuildArgs          map[string]string                            `yaml:"-"` // Optional field to store the build args passed when building the Dockerfile
+	Target             string                                       `yaml:"-"` // Optional field to store the stage of the multi-stage Dockerfile to build
 }
 
 // StorageKindType defines storage type kind
@@ -198,6 +271,8 @@ func (service *Service) merge(nService Service) {
 	if nService.BackendServiceName != "" {
 		service.BackendServiceName = nService.BackendServiceName
 	}
+	// The strategic merge uses only the port number as the key of the container ports, which overwrites the ports using the same number with another protocol
+	containerPorts := getUniqueContainerPorts(service.PodSpec, nService.PodSpec)
 	svcPodSpec := core.PodSpec(service.PodSpec)
 	podSpecJSON, err1 := json.Marshal(k8sschema.ConvertToV1PodSpec(&svcPodSpec))
 	if err1 != nil {
@@ -220,39 +295,100 @@ func (service *Service) merge(nService Service) {
 			} else {
 				svcPodSpec := corev1.PodSpec(podSpec)
 				service.PodSpec = PodSpec(k8sschema.ConvertToPodSpec(&svcPodSpec))
+				for i, container := range service.Containers {
+					if ports, ok := containerPorts[container.Name]; ok {
+						service.Containers[i].Ports = ports
+					}
+				}
 			}
 		}
 	}
 	service.Annotations = common.MergeStringMaps(service.Annotations, nService.Annotations)
+	service.ServiceAnnotations = common.MergeStringMaps(service.ServiceAnnotations, nService.ServiceAnnotations)
 	service.Labels = common.MergeStringMaps(service.Labels, nService.Labels)
 	if nService.Replicas != 0 {
 		service.Replicas = nService.Replicas
 	}
 	service.Networks = common.MergeSlices(service.Networks, nService.Networks)
+	service.DependsOn = common.MergeSlices(service.DependsOn, nService.DependsOn)
+	service.Aliases = common.MergeSlices(service.Aliases, nService.Aliases)
+	if nService.Schedule != "" {
+		service.Schedule = nService.Schedule
+	}
 	service.OnlyIngress = service.OnlyIngress && nService.OnlyIngress
 	service.Daemon = service.Daemon && nService.Daemon
+	service.Stateful = service.Stateful || nService.Stateful
+	service.SessionAffinity = service.SessionAffinity || nService.SessionAffinity
+	if nService.PreStopDelaySeconds > service.PreStopDelaySeconds {
+		service.PreStopDelaySeconds = nService.PreStopDelaySeconds
+	}
 	for _, pf := range nService.ServiceToPodPortForwardings {
-		service.AddPortForwarding(pf.ServicePort, pf.PodPort, pf.ServiceRelPath)
+		if err := service.AddPortForwardingWithProtocol(pf.ServicePort, pf.PodPort, pf.ServiceRelPath, pf.GetProtocol()); err == nil {
+			service.ServiceToPodPortForwardings[len(service.ServiceToPodPortForwardings)-1].HostPort = pf.HostPort
+		}
 	}
 }
 
+// getUniqueContainerPorts returns the ports of the containers of the pod specs, unique by the port number and the protocol
+func getUniqueContainerPorts(podSpecs ...PodSpec) map[string][]core.ContainerPort {
+	containerPorts := map[string][]core.ContainerPort{}
+	for _, podSpec := range podSpecs {
+		for _, container := range podSpec.Containers {
+			for _, port := range container.Ports {
+				found := false
+				for _, cp := range containerPorts[container.Name] {
+					if cp.ContainerPort == port.ContainerPort && cp.Protocol == port.Protocol {
+						found = true
+						break
+					}
+				}
+				if !found {
+					containerPorts[container.Name] = append(containerPorts[container.Name], port)
+				}
+			}
+		}
+	}
+	return containerPorts
+}
+
 // AddPortForwarding adds a new port forwarding to the service.
 func (service *Service) AddPortForwarding(servicePort networking.ServiceBackendPort, podPort networking.ServiceBackendPort, relPath string) error {
+	return service.AddPortForwardingWithProtocol(servicePort, podPort, relPath, core.ProtocolTCP)
+}
+
+// AddPublishedPortForwarding adds a new port forwarding for a port which the source published on the hosts
+func (service *Service) AddPublishedPortForwarding(servicePort networking.ServiceBackendPort, podPort networking.ServiceBackendPort, protocol core.Protocol, hostPort int32) error {
+	if err := service.AddPortForwardingWithProtocol(servicePort, podPort, "", protocol); err != nil {
+		return err
+	}
+	service.ServiceToPodPortForwardings[len(service.ServiceToPodPortForwardings)-1].HostPort = hostPort
+	return nil
+}
+
+// AddPortForwardingWithProtocol adds a new port forwarding for the transport protocol, like UDP for DNS.
+// The same port number can be forwarded for each of the protocols.
+func (service *Service) AddPortForwardingWithProtocol(servicePort networking.ServiceBackendPort, podPort networking.ServiceBackendPort, relPath string, protocol core.Protocol) error {
 	if podPort.Number == 0 || servicePort.Number == 0 {
 		return fmt.Errorf("PodPort or ServicePort can not be 0")
 	}
+	newForwarding := ServiceToPodPortForwarding{ServicePort: servicePort, PodPort: podPort, ServiceRelPath: relPath}
+	if protocol != core.ProtocolTCP {
+		newForwarding.Protocol = protocol
+	}
 	for _, forwarding := range service.ServiceToPodPortForwardings {
 		if servicePort.Name != "" && forwarding.ServicePort.Name == servicePort.Name {
 			err := fmt.Errorf("the port name %s on %s service is already in use. Not adding the new forwarding", servicePort.Name, service.Name)
 			return err
 		}
-		if forwarding.ServicePort.Number == servicePort.Number {
+		if forwarding.ServicePort.Number == servicePort.Number && forwarding.GetProtocol() == newForwarding.GetProtocol() {
 			err := fmt.Errorf("the port number %d on %s service is already in use. Not adding the new forwarding", servicePort.Number, service.Name)
 			return err
 		}
 	}
-	newForwarding := ServiceToPodPortForwarding{ServicePort: servicePort, PodPort: podPort, ServiceRelPath: relPath}
 	for _, pf := range service.ServiceToPodPortForwardings {
+		if pf.GetProtocol() != newForwarding.GetProtocol() {
+			continue
+		}
 		if pf.PodPort == newForwarding.PodPort || pf.ServicePort == newForwarding.ServicePort {
 			return fmt.Errorf("mapping exists for port %v:%v in service %s. Ignoring", pf.PodPort, pf.ServicePort, service.Name)
 		}
@@ -319,6 +455,17 @@ func (c *ContainerBuild) Merge(newc ContainerBuild) bool {
 	if c.ContextPath == "" {
 		c.ContextPath = newc.ContextPath
 	}
+	if c.Target == "" {
+		c.Target = newc.Target
+	}
+	for buildArg, value := range newc.BuildArgs {
+		if c.BuildArgs == nil {
+			c.BuildArgs = map[string]string{}
+		}
+		if _, ok := c.BuildArgs[buildArg]; !ok {
+			c.BuildArgs[buildArg] = value
+		}
+	}
 	return true
 }
 
@@ -338,6 +485,7 @@ func NewIR() IR {
 	ir.ContainerImages = map[string]ContainerImage{}
 	ir.Services = map[string]Service{}
 	ir.Storages = []Storage{}
+	ir.ExternalServices = map[string]ExternalService{}
 	return ir
 }
 
@@ -361,6 +509,12 @@ func (ir *IR) Merge(newirC interface{}) bool {
 	for _, newst := range newirptr.Storages {
 		ir.AddStorage(newst)
 	}
+	for name, externalService := range newirptr.ExternalServices {
+		if ir.ExternalServices == nil {
+			ir.ExternalServices = map[string]ExternalService{}
+		}
+		ir.ExternalServices[name] = externalService
+	}
 	return true
 }
 
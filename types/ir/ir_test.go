@@ -0,0 +1,129 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package ir
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestAddPortForwardingWithProtocol(t *testing.T) {
+	service := NewServiceWithName("dns")
+	port := networking.ServiceBackendPort{Number: 53}
+	if err := service.AddPortForwarding(port, port, ""); err != nil {
+		t.Fatalf("failed to add the tcp port forwarding. Error: %q", err)
+	}
+	if err := service.AddPortForwardingWithProtocol(port, port, "", core.ProtocolUDP); err != nil {
+		t.Fatalf("failed to add the udp port forwarding for the same port number. Error: %q", err)
+	}
+	if err := service.AddPortForwardingWithProtocol(port, port, "", core.ProtocolUDP); err == nil {
+		t.Fatalf("expected an error when adding the udp port forwarding again")
+	}
+	if err := service.AddPortForwardingWithProtocol(port, port, "", core.ProtocolTCP); err == nil {
+		t.Fatalf("expected an error when adding the tcp port forwarding again")
+	}
+	sctpPort := networking.ServiceBackendPort{Number: 3868}
+	if err := service.AddPublishedPortForwarding(sctpPort, sctpPort, core.ProtocolSCTP, 3868); err != nil {
+		t.Fatalf("failed to add the published sctp port forwarding. Error: %q", err)
+	}
+	protocols := []core.Protocol{}
+	for _, forwarding := range service.ServiceToPodPortForwardings {
+		protocols = append(protocols, forwarding.GetProtocol())
+	}
+	if want := []core.Protocol{core.ProtocolTCP, core.ProtocolUDP, core.ProtocolSCTP}; !cmp.Equal(protocols, want) {
+		t.Fatalf("unexpected protocols of the port forwardings. Differences: %s", cmp.Diff(want, protocols))
+	}
+	if service.ServiceToPodPortForwardings[0].Protocol != "" {
+		t.Fatalf("expected the tcp protocol to be left empty. Actual: %q", service.ServiceToPodPortForwardings[0].Protocol)
+	}
+	if service.ServiceToPodPortForwardings[2].HostPort != 3868 {
+		t.Fatalf("expected the published port to be set. Actual: %+v", service.ServiceToPodPortForwardings[2])
+	}
+}
+
+func TestAddServiceKeepsTheContainerPortsOfEachProtocol(t *testing.T) {
+	newDNSService := func(protocol core.Protocol) Service {
+		service := NewServiceWithName("dns")
+		service.Containers = []core.Container{{Name: "dns", Image: "coredns:latest", Ports: []core.ContainerPort{{ContainerPort: 53, Protocol: protocol}}}}
+		port := networking.ServiceBackendPort{Number: 53}
+		if err := service.AddPortForwardingWithProtocol(port, port, "", protocol); err != nil {
+			t.Fatalf("failed to add the port forwarding. Error: %q", err)
+		}
+		return service
+	}
+	ir := NewIR()
+	ir.AddService(newDNSService(core.ProtocolTCP))
+	ir.AddService(newDNSService(core.ProtocolUDP))
+	service := ir.Services["dns"]
+	if want := []core.ContainerPort{{ContainerPort: 53, Protocol: core.ProtocolTCP}, {ContainerPort: 53, Protocol: core.ProtocolUDP}}; !cmp.Equal(service.Containers[0].Ports, want) {
+		t.Fatalf("unexpected container ports after the merge. Differences: %s", cmp.Diff(want, service.Containers[0].Ports))
+	}
+	if len(service.ServiceToPodPortForwardings) != 2 || service.ServiceToPodPortForwardings[1].GetProtocol() != core.ProtocolUDP {
+		t.Fatalf("expected the tcp and the udp port forwardings. Actual: %+v", service.ServiceToPodPortForwardings)
+	}
+}
+
+func TestMergeKeepsTheExternalServices(t *testing.T) {
+	ir := NewIR()
+	ir.ExternalServices = nil
+	newIR := NewIR()
+	newIR.ExternalServices["orders"] = ExternalService{Name: "orders", ExternalName: "orders.db.example.com"}
+	if !ir.Merge(newIR) {
+		t.Fatalf("failed to merge the IRs")
+	}
+	other := NewIR()
+	other.ExternalServices["cache"] = ExternalService{Name: "cache", ExternalName: "cache.example.com"}
+	ir.Merge(other)
+	want := map[string]ExternalService{
+		"orders": {Name: "orders", ExternalName: "orders.db.example.com"},
+		"cache":  {Name: "cache", ExternalName: "cache.example.com"},
+	}
+	if !cmp.Equal(ir.ExternalServices, want) {
+		t.Fatalf("the external services differ. Differences:\n%s", cmp.Diff(want, ir.ExternalServices))
+	}
+}
+
+func TestGetNodePort(t *testing.T) {
+	for hostPort, want := range map[int32]int32{0: 0, 8080: 0, 30000: 30000, 32767: 32767, 32768: 0} {
+		if got := (ServiceToPodPortForwarding{HostPort: hostPort}).GetNodePort(); got != want {
+			t.Errorf("got the node port %d for the host port %d , want %d", got, hostPort, want)
+		}
+	}
+}
+
+func TestMergeKeepsTheHostPortsAndTheServiceAnnotations(t *testing.T) {
+	service := NewServiceWithName("web")
+	other := NewServiceWithName("web")
+	other.ServiceAnnotations = map[string]string{"key": "value"}
+	port := networking.ServiceBackendPort{Number: 8080}
+	if err := other.AddPublishedPortForwarding(port, port, core.ProtocolTCP, 30080); err != nil {
+		t.Fatalf("failed to add the port forwarding. Error: %q", err)
+	}
+	ir := NewIR()
+	ir.AddService(service)
+	ir.AddService(other)
+	merged := ir.Services["web"]
+	if len(merged.ServiceToPodPortForwardings) != 1 || merged.ServiceToPodPortForwardings[0].HostPort != 30080 {
+		t.Fatalf("expected the host port to be kept. Actual: %+v", merged.ServiceToPodPortForwardings)
+	}
+	if merged.ServiceAnnotations["key"] != "value" {
+		t.Fatalf("expected the annotations of the service to be kept. Actual: %+v", merged.ServiceAnnotations)
+	}
+}
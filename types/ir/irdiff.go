@@ -0,0 +1,299 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package ir
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+// IRChangeType is the type of change between two IRs
+type IRChangeType string
+
+const (
+	// AddedIRChangeType means the field exists only in the new IR
+	AddedIRChangeType IRChangeType = "Added"
+	// RemovedIRChangeType means the field exists only in the old IR
+	RemovedIRChangeType IRChangeType = "Removed"
+	// ModifiedIRChangeType means the field has different values in the two IRs
+	ModifiedIRChangeType IRChangeType = "Modified"
+)
+
+// IRChange stores a single difference between two IRs
+type IRChange struct {
+	// Path is the dot separated path of the changed field. Example: services.web.containers.web.env.PORT
+	Path string       `yaml:"path" json:"path"`
+	Type IRChangeType `yaml:"type" json:"type"`
+	Old  string       `yaml:"old,omitempty" json:"old,omitempty"`
+	New  string       `yaml:"new,omitempty" json:"new,omitempty"`
+}
+
+// DiffIRs returns the differences in services, containers, ports, environment variables and storages between two IRs
+func DiffIRs(oldIR, newIR IR) []IRChange {
+	changes := []IRChange{}
+	for _, name := range getSortedKeys(oldIR.Services, newIR.Services) {
+		path := common.JoinQASubKeys("services", name)
+		oldService, oldOk := oldIR.Services[name]
+		newService, newOk := newIR.Services[name]
+		if !newOk {
+			changes = append(changes, IRChange{Path: path, Type: RemovedIRChangeType})
+			continue
+		}
+		if !oldOk {
+			changes = append(changes, IRChange{Path: path, Type: AddedIRChangeType})
+			continue
+		}
+		changes = append(changes, diffServices(path, oldService, newService)...)
+	}
+	oldStorages := map[string]Storage{}
+	for _, storage := range oldIR.Storages {
+		oldStorages[storage.Name] = storage
+	}
+	newStorages := map[string]Storage{}
+	for _, storage := range newIR.Storages {
+		newStorages[storage.Name] = storage
+	}
+	for _, name := range getSortedKeys(oldStorages, newStorages) {
+		path := common.JoinQASubKeys("storages", name)
+		oldStorage, oldOk := oldStorages[name]
+		newStorage, newOk := newStorages[name]
+		if !newOk {
+			changes = append(changes, IRChange{Path: path, Type: RemovedIRChangeType})
+			continue
+		}
+		if !oldOk {
+			changes = append(changes, IRChange{Path: path, Type: AddedIRChangeType})
+			continue
+		}
+		changes = appendIfModified(changes, common.JoinQASubKeys(path, "type"), string(oldStorage.StorageType), string(newStorage.StorageType))
+		for _, key := range getSortedKeys(oldStorage.Content, newStorage.Content) {
+			// the content may contain secrets, so the values are not included in the diff
+			oldContent, oldOk := oldStorage.Content[key]
+			newContent, newOk := newStorage.Content[key]
+			contentPath := common.JoinQASubKeys(path, "content", key)
+			if !newOk {
+				changes = append(changes, IRChange{Path: contentPath, Type: RemovedIRChangeType})
+			} else if !oldOk {
+				changes = append(changes, IRChange{Path: contentPath, Type: AddedIRChangeType})
+			} else if string(oldContent) != string(newContent) {
+				changes = append(changes, IRChange{Path: contentPath, Type: ModifiedIRChangeType})
+			}
+		}
+		changes = appendIfModified(changes, common.JoinQASubKeys(path, "size"), getStorageSize(oldStorage), getStorageSize(newStorage))
+	}
+	return changes
+}
+
+func diffServices(path string, oldService, newService Service) []IRChange {
+	changes := []IRChange{}
+	changes = appendIfModified(changes, common.JoinQASubKeys(path, "replicas"), fmt.Sprint(oldService.Replicas), fmt.Sprint(newService.Replicas))
+	changes = appendIfModified(changes, common.JoinQASubKeys(path, "daemon"), fmt.Sprint(oldService.Daemon), fmt.Sprint(newService.Daemon))
+	changes = appendIfModified(changes, common.JoinQASubKeys(path, "stateful"), fmt.Sprint(oldService.Stateful), fmt.Sprint(newService.Stateful))
+	changes = append(changes, diffStringSets(common.JoinQASubKeys(path, "ports"), getServicePorts(oldService), getServicePorts(newService))...)
+	oldContainers := map[string]core.Container{}
+	for _, container := range oldService.Containers {
+		oldContainers[container.Name] = container
+	}
+	newContainers := map[string]core.Container{}
+	for _, container := range newService.Containers {
+		newContainers[container.Name] = container
+	}
+	for _, name := range getSortedKeys(oldContainers, newContainers) {
+		containerPath := common.JoinQASubKeys(path, "containers", name)
+		oldContainer, oldOk := oldContainers[name]
+		newContainer, newOk := newContainers[name]
+		if !newOk {
+			changes = append(changes, IRChange{Path: containerPath, Type: RemovedIRChangeType})
+			continue
+		}
+		if !oldOk {
+			changes = append(changes, IRChange{Path: containerPath, Type: AddedIRChangeType})
+			continue
+		}
+		changes = appendIfModified(changes, common.JoinQASubKeys(containerPath, "image"), oldContainer.Image, newContainer.Image)
+		changes = appendIfModified(changes, common.JoinQASubKeys(containerPath, "command"), strings.Join(oldContainer.Command, " "), strings.Join(newContainer.Command, " "))
+		changes = appendIfModified(changes, common.JoinQASubKeys(containerPath, "args"), strings.Join(oldContainer.Args, " "), strings.Join(newContainer.Args, " "))
+		changes = append(changes, diffStringSets(common.JoinQASubKeys(containerPath, "ports"), getContainerPorts(oldContainer), getContainerPorts(newContainer))...)
+		oldEnv := getEnvMap(oldContainer)
+		newEnv := getEnvMap(newContainer)
+		for _, envName := range getSortedKeys(oldEnv, newEnv) {
+			envPath := common.JoinQASubKeys(containerPath, "env", envName)
+			oldValue, oldOk := oldEnv[envName]
+			newValue, newOk := newEnv[envName]
+			if !newOk {
+				changes = append(changes, IRChange{Path: envPath, Type: RemovedIRChangeType, Old: oldValue})
+			} else if !oldOk {
+				changes = append(changes, IRChange{Path: envPath, Type: AddedIRChangeType, New: newValue})
+			} else {
+				changes = appendIfModified(changes, envPath, oldValue, newValue)
+			}
+		}
+		for _, resourceName := range []core.ResourceName{core.ResourceCPU, core.ResourceMemory} {
+			changes = appendIfModified(changes, common.JoinQASubKeys(containerPath, "resources", "requests", string(resourceName)), getQuantity(oldContainer.Resources.Requests, resourceName), getQuantity(newContainer.Resources.Requests, resourceName))
+			changes = appendIfModified(changes, common.JoinQASubKeys(containerPath, "resources", "limits", string(resourceName)), getQuantity(oldContainer.Resources.Limits, resourceName), getQuantity(newContainer.Resources.Limits, resourceName))
+		}
+	}
+	oldVolumes := map[string]string{}
+	for _, volume := range oldService.Volumes {
+		oldVolumes[volume.Name] = getVolumeSource(volume)
+	}
+	newVolumes := map[string]string{}
+	for _, volume := range newService.Volumes {
+		newVolumes[volume.Name] = getVolumeSource(volume)
+	}
+	for _, name := range getSortedKeys(oldVolumes, newVolumes) {
+		volumePath := common.JoinQASubKeys(path, "volumes", name)
+		oldVolume, oldOk := oldVolumes[name]
+		newVolume, newOk := newVolumes[name]
+		if !newOk {
+			changes = append(changes, IRChange{Path: volumePath, Type: RemovedIRChangeType, Old: oldVolume})
+		} else if !oldOk {
+			changes = append(changes, IRChange{Path: volumePath, Type: AddedIRChangeType, New: newVolume})
+		} else {
+			changes = appendIfModified(changes, volumePath, oldVolume, newVolume)
+		}
+	}
+	return changes
+}
+
+func appendIfModified(changes []IRChange, path, oldValue, newValue string) []IRChange {
+	if oldValue == newValue {
+		return changes
+	}
+	return append(changes, IRChange{Path: path, Type: ModifiedIRChangeType, Old: oldValue, New: newValue})
+}
+
+func diffStringSets(path string, oldValues, newValues []string) []IRChange {
+	changes := []IRChange{}
+	for _, v := range oldValues {
+		if !common.IsPresent(newValues, v) {
+			changes = append(changes, IRChange{Path: path, Type: RemovedIRChangeType, Old: v})
+		}
+	}
+	for _, v := range newValues {
+		if !common.IsPresent(oldValues, v) {
+			changes = append(changes, IRChange{Path: path, Type: AddedIRChangeType, New: v})
+		}
+	}
+	return changes
+}
+
+// getSortedKeys returns the sorted union of the keys of the two maps
+func getSortedKeys(maps ...interface{}) []string {
+	keys := []string{}
+	for _, m := range maps {
+		for _, k := range reflect.ValueOf(m).MapKeys() {
+			keys = common.AppendIfNotPresent(keys, k.String())
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func getServicePorts(service Service) []string {
+	ports := []string{}
+	for _, forwarding := range service.ServiceToPodPortForwardings {
+		port := fmt.Sprintf("%s->%s", getBackendPort(forwarding.ServicePort.Name, forwarding.ServicePort.Number), getBackendPort(forwarding.PodPort.Name, forwarding.PodPort.Number))
+		if forwarding.Protocol != "" {
+			port += "/" + string(forwarding.Protocol)
+		}
+		if forwarding.ServiceRelPath != "" {
+			port += " " + forwarding.ServiceRelPath
+		}
+		if forwarding.ServiceType != "" {
+			port += " " + string(forwarding.ServiceType)
+		}
+		ports = append(ports, port)
+	}
+	sort.Strings(ports)
+	return ports
+}
+
+func getBackendPort(name string, number int32) string {
+	if name != "" {
+		return name
+	}
+	return fmt.Sprint(number)
+}
+
+func getContainerPorts(container core.Container) []string {
+	ports := []string{}
+	for _, port := range container.Ports {
+		p := fmt.Sprint(port.ContainerPort)
+		if port.Protocol != "" {
+			p += "/" + string(port.Protocol)
+		}
+		ports = append(ports, p)
+	}
+	sort.Strings(ports)
+	return ports
+}
+
+func getEnvMap(container core.Container) map[string]string {
+	env := map[string]string{}
+	for _, e := range container.Env {
+		if e.ValueFrom == nil {
+			env[e.Name] = e.Value
+			continue
+		}
+		switch {
+		case e.ValueFrom.SecretKeyRef != nil:
+			env[e.Name] = fmt.Sprintf("secret:%s/%s", e.ValueFrom.SecretKeyRef.Name, e.ValueFrom.SecretKeyRef.Key)
+		case e.ValueFrom.ConfigMapKeyRef != nil:
+			env[e.Name] = fmt.Sprintf("configmap:%s/%s", e.ValueFrom.ConfigMapKeyRef.Name, e.ValueFrom.ConfigMapKeyRef.Key)
+		case e.ValueFrom.FieldRef != nil:
+			env[e.Name] = "field:" + e.ValueFrom.FieldRef.FieldPath
+		default:
+			env[e.Name] = "valueFrom"
+		}
+	}
+	return env
+}
+
+func getQuantity(list core.ResourceList, name core.ResourceName) string {
+	quantity, ok := list[name]
+	if !ok {
+		return ""
+	}
+	return quantity.String()
+}
+
+func getStorageSize(storage Storage) string {
+	return getQuantity(storage.Resources.Requests, core.ResourceStorage)
+}
+
+func getVolumeSource(volume core.Volume) string {
+	switch {
+	case volume.PersistentVolumeClaim != nil:
+		return "pvc:" + volume.PersistentVolumeClaim.ClaimName
+	case volume.ConfigMap != nil:
+		return "configmap:" + volume.ConfigMap.Name
+	case volume.Secret != nil:
+		return "secret:" + volume.Secret.SecretName
+	case volume.EmptyDir != nil:
+		return "emptydir"
+	case volume.HostPath != nil:
+		return "hostpath:" + volume.HostPath.Path
+	default:
+		return "other"
+	}
+}
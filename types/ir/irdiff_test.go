@@ -0,0 +1,93 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package ir
+
+import (
+	"reflect"
+	"testing"
+
+	core "k8s.io/kubernetes/pkg/apis/core"
+)
+
+func TestDiffIRs(t *testing.T) {
+	newService := func(name string, replicas int, image string, env map[string]string, ports ...int32) Service {
+		service := NewServiceWithName(name)
+		service.Replicas = replicas
+		container := core.Container{Name: name, Image: image}
+		for envName, value := range env {
+			container.Env = append(container.Env, core.EnvVar{Name: envName, Value: value})
+		}
+		for _, port := range ports {
+			container.Ports = append(container.Ports, core.ContainerPort{ContainerPort: port})
+		}
+		service.Containers = []core.Container{container}
+		return service
+	}
+	newIR := func(services ...Service) IR {
+		ir := NewIR()
+		for _, service := range services {
+			ir.Services[service.Name] = service
+		}
+		return ir
+	}
+	api := newService("api", 1, "api:1", map[string]string{"A": "1", "B": "2"}, 8080)
+	testCases := []struct {
+		name string
+		old  IR
+		new  IR
+		want []IRChange
+	}{
+		{
+			name: "unchanged services",
+			old:  newIR(api),
+			new:  newIR(api),
+			want: []IRChange{},
+		},
+		{
+			name: "added service",
+			old:  newIR(api),
+			new:  newIR(api, newService("web", 1, "web:1", nil)),
+			want: []IRChange{{Path: "services.web", Type: AddedIRChangeType}},
+		},
+		{
+			name: "removed service",
+			old:  newIR(api, newService("web", 1, "web:1", nil)),
+			new:  newIR(api),
+			want: []IRChange{{Path: "services.web", Type: RemovedIRChangeType}},
+		},
+		{
+			name: "changed service",
+			old:  newIR(api),
+			new:  newIR(newService("api", 2, "api:2", map[string]string{"A": "3", "C": "4"}, 8080, 9090)),
+			want: []IRChange{
+				{Path: "services.api.replicas", Type: ModifiedIRChangeType, Old: "1", New: "2"},
+				{Path: "services.api.containers.api.image", Type: ModifiedIRChangeType, Old: "api:1", New: "api:2"},
+				{Path: "services.api.containers.api.ports", Type: AddedIRChangeType, New: "9090"},
+				{Path: "services.api.containers.api.env.A", Type: ModifiedIRChangeType, Old: "1", New: "3"},
+				{Path: "services.api.containers.api.env.B", Type: RemovedIRChangeType, Old: "2"},
+				{Path: "services.api.containers.api.env.C", Type: AddedIRChangeType, New: "4"},
+			},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			if got := DiffIRs(testCase.old, testCase.new); !reflect.DeepEqual(got, testCase.want) {
+				t.Fatalf("got the changes %+v , want %+v", got, testCase.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package ir
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types"
+	"sigs.k8s.io/yaml"
+)
+
+// IRFileKind defines the kind of the exported IR file
+const IRFileKind types.Kind = "IR"
+
+// IRFile stores an exported IR along with the version of the format.
+// The IR contains kubernetes internal types which only round trip through json,
+// so yaml files are converted to and from json.
+type IRFile struct {
+	types.TypeMeta `json:",inline"`
+	ObjectMeta     types.ObjectMeta `json:"metadata,omitempty"`
+	Spec           EnhancedIR       `json:"spec"`
+}
+
+// NewIRFile creates a new instance of IRFile
+func NewIRFile(ir EnhancedIR) IRFile {
+	return IRFile{
+		TypeMeta: types.TypeMeta{
+			Kind:       string(IRFileKind),
+			APIVersion: types.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: types.ObjectMeta{
+			Name: ir.Name,
+		},
+		Spec: ir,
+	}
+}
+
+// WriteIRFile writes the IR to a file. The file is written as json if the path has the .json extension, else as yaml.
+func WriteIRFile(path string, ir EnhancedIR) error {
+	data, err := json.Marshal(NewIRFile(ir))
+	if err != nil {
+		return fmt.Errorf("failed to marshal the IR to json. Error: %w", err)
+	}
+	if !strings.EqualFold(filepath.Ext(path), ".json") {
+		if data, err = yaml.JSONToYAML(data); err != nil {
+			return fmt.Errorf("failed to convert the IR from json to yaml. Error: %w", err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), common.DefaultDirectoryPermission); err != nil {
+		return fmt.Errorf("failed to create the directory for the IR file at path %s . Error: %w", path, err)
+	}
+	if err := os.WriteFile(path, data, common.DefaultFilePermission); err != nil {
+		return fmt.Errorf("failed to write the IR file at path %s . Error: %w", path, err)
+	}
+	return nil
+}
+
+// ReadIRFile reads an IR file written by WriteIRFile. Both json and yaml files are supported.
+func ReadIRFile(path string) (EnhancedIR, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return EnhancedIR{}, fmt.Errorf("failed to read the IR file at path %s . Error: %w", path, err)
+	}
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return EnhancedIR{}, fmt.Errorf("failed to convert the file at path %s to json. Error: %w", path, err)
+	}
+	irFile := IRFile{}
+	if err := json.Unmarshal(jsonData, &irFile); err != nil {
+		return EnhancedIR{}, fmt.Errorf("failed to unmarshal the file at path %s as an IR file. Error: %w", path, err)
+	}
+	if irFile.Kind != string(IRFileKind) {
+		return EnhancedIR{}, fmt.Errorf("the file at path %s is not an IR file. Expected kind %s Actual kind %s", path, IRFileKind, irFile.Kind)
+	}
+	if irFile.APIVersion != types.SchemeGroupVersion.String() {
+		return EnhancedIR{}, fmt.Errorf("the IR file at path %s has an unsupported version. Expected %s Actual %s", path, types.SchemeGroupVersion.String(), irFile.APIVersion)
+	}
+	ir := irFile.Spec
+	if ir.ContainerImages == nil {
+		ir.ContainerImages = map[string]ContainerImage{}
+	}
+	if ir.Services == nil {
+		ir.Services = map[string]Service{}
+	}
+	return ir, nil
+}
@@ -0,0 +1,90 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package ir
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/konveyor/move2kube/types"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	core "k8s.io/kubernetes/pkg/apis/core"
+	networking "k8s.io/kubernetes/pkg/apis/networking"
+)
+
+func TestIRFileRoundTrip(t *testing.T) {
+	ir := NewEnhancedIRFromIR(NewIR())
+	ir.Name = "myproject"
+	ir.ContainerImages["api:latest"] = ContainerImage{ExposedPorts: []int32{8080}}
+	service := NewServiceWithName("api")
+	service.Replicas = 2
+	service.ServiceToPodPortForwardings = []ServiceToPodPortForwarding{{ServicePort: networking.ServiceBackendPort{Number: 80}, PodPort: networking.ServiceBackendPort{Number: 8080}, ServiceRelPath: "/api"}}
+	service.Containers = []core.Container{{
+		Name:  "api",
+		Image: "api:latest",
+		Ports: []core.ContainerPort{{ContainerPort: 8080, Protocol: core.ProtocolTCP}},
+		Env:   []core.EnvVar{{Name: "PORT", Value: "8080"}, {Name: "PASSWORD", ValueFrom: &core.EnvVarSource{SecretKeyRef: &core.SecretKeySelector{LocalObjectReference: core.LocalObjectReference{Name: "api"}, Key: "password"}}}},
+		Resources: core.ResourceRequirements{
+			Requests: core.ResourceList{core.ResourceCPU: resource.MustParse("100m"), core.ResourceMemory: resource.MustParse("128Mi")},
+			Limits:   core.ResourceList{core.ResourceMemory: resource.MustParse("256Mi")},
+		},
+		VolumeMounts: []core.VolumeMount{{Name: "data", MountPath: "/data"}},
+	}}
+	service.Volumes = []core.Volume{{Name: "data", VolumeSource: core.VolumeSource{PersistentVolumeClaim: &core.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}}}
+	ir.Services[service.Name] = service
+	ir.Storages = []Storage{
+		{Name: "data", StorageType: PVCKind, PersistentVolumeClaimSpec: core.PersistentVolumeClaimSpec{
+			AccessModes: []core.PersistentVolumeAccessMode{core.ReadWriteOnce},
+			Resources:   core.ResourceRequirements{Requests: core.ResourceList{core.ResourceStorage: resource.MustParse("1Gi")}},
+		}},
+		{Name: "api", StorageType: SecretKind, Content: map[string][]byte{"password": []byte("secret\n")}},
+	}
+
+	for _, fileName := range []string{"ir.yaml", "ir.json"} {
+		t.Run(fileName, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "out", fileName)
+			if err := WriteIRFile(path, ir); err != nil {
+				t.Fatalf("failed to write the IR file. Error: %q", err)
+			}
+			readIR, err := ReadIRFile(path)
+			if err != nil {
+				t.Fatalf("failed to read the IR file. Error: %q", err)
+			}
+			if !equality.Semantic.DeepEqual(readIR, ir) {
+				t.Fatalf("the IR changed after the round trip. Got:\n%+v\nWant:\n%+v", readIR, ir)
+			}
+		})
+	}
+}
+
+func TestReadIRFileRejectsOtherFiles(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"plan.yaml":    "apiVersion: " + types.SchemeGroupVersion.String() + "\nkind: Plan\n",
+		"version.yaml": "apiVersion: move2kube.konveyor.io/v0\nkind: IR\n",
+	} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatalf("failed to write the file %s . Error: %q", name, err)
+		}
+		if _, err := ReadIRFile(path); err == nil {
+			t.Fatalf("expected an error reading the file %s", name)
+		}
+	}
+}
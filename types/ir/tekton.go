@@ -45,10 +45,40 @@ type TriggerTemplate struct {
 	ServiceAccountName string
 	WorkspaceName      string
 	StorageClassName   string
+	// CacheClaimName is the persistent volume claim the workspace is bound to, instead of a new claim per run
+	CacheClaimName              string
+	ArtifactBucketWorkspaceName string
+	ArtifactBucketSecretName    string
+	SigningWorkspaceName        string
+	SigningSecretName           string
 }
 
 // Pipeline holds the details about the clone build push pipeline resource
 type Pipeline struct {
 	Name          string
 	WorkspaceName string
+	CacheType     PipelineCacheType
+	// CacheRepo is the repository the layers are pushed to when the cache is in the image registry
+	CacheRepo string
+	// ArtifactBucket is the url of the bucket the workspace is uploaded to at the end of each run, like gs://bucket or s3://bucket
+	ArtifactBucket              string
+	ArtifactBucketWorkspaceName string
+	// SignImages is true when the pushed images are signed with cosign, with the key of SigningKeyRef or keyless when it is empty
+	SignImages    bool
+	SigningKeyRef string
+	// SigningWorkspaceName is the workspace holding the key, when the key is in a secret created for the pipeline
+	SigningWorkspaceName string
+	SigningSecretName    string
 }
+
+// PipelineCacheType is the type of the cache of the image builds of a pipeline
+type PipelineCacheType string
+
+const (
+	// NoPipelineCache builds the images from scratch in every run
+	NoPipelineCache PipelineCacheType = "none"
+	// PVCPipelineCache keeps the cloned repos and the layers in a persistent volume claim shared by the runs
+	PVCPipelineCache PipelineCacheType = "pvc"
+	// RegistryPipelineCache pushes the layers to a repository of the image registry
+	RegistryPipelineCache PipelineCacheType = "registry"
+)
@@ -48,8 +48,10 @@ type Spec struct {
 
 // PlanArtifact stores the artifact with the transformerName
 type PlanArtifact struct {
-	ServiceName               string `yaml:"-"`
-	TransformerName           string `yaml:"transformerName"`
+	ServiceName     string `yaml:"-"`
+	TransformerName string `yaml:"transformerName"`
+	// OriginalServiceName is the name of the service before it was made a valid kubernetes name
+	OriginalServiceName       string `yaml:"originalServiceName,omitempty"`
 	transformertypes.Artifact `yaml:",inline"`
 }
 
@@ -18,7 +18,10 @@ package commonqa
 
 import (
 	"fmt"
+	"math"
 	"net/url"
+	"os"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -28,6 +31,7 @@ import (
 	qatypes "github.com/konveyor/move2kube/types/qaengine"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/cast"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 // ImageRegistry returns Image Registry URL
@@ -74,27 +78,301 @@ func ImageRegistry() string {
 
 // ImageRegistryNamespace returns Image Registry Namespace
 func ImageRegistryNamespace() string {
-	return qaengine.FetchStringAnswer(common.ConfigImageRegistryNamespaceKey, "Enter the namespace where the new images should be pushed : ", []string{"Ex : " + common.ProjectName}, common.ProjectName, nil)
+	hints := []string{"Ex : " + common.ProjectName}
+	defaultNamespace := common.ProjectName
+	var validator func(interface{}) error
+	// the registries of the cloud providers have their own naming
+	switch GetImageRegistryInfo(ImageRegistry()).Provider {
+	case ECRRegistryProvider:
+		hints = append(hints, "The images are pushed to the repositories <namespace>/<image>, which are created by the push scripts if they do not exist")
+	case GCRRegistryProvider:
+		hints = []string{"Enter the ID of the Google Cloud project, the images are pushed to gcr.io/<project>/<image>"}
+	case ArtifactRegistryProvider:
+		hints = []string{"Enter the ID of the Google Cloud project and the name of the repository, the images are pushed to <location>-docker.pkg.dev/<project>/<repository>/<image>"}
+		defaultNamespace = common.ProjectName + "/" + common.ProjectName
+		validator = func(ans interface{}) error {
+			parts := strings.Split(cast.ToString(ans), "/")
+			if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+				return fmt.Errorf("the namespace of an Artifact Registry should be of the form <project>/<repository>")
+			}
+			return nil
+		}
+	}
+	return qaengine.FetchStringAnswer(common.ConfigImageRegistryNamespaceKey, "Enter the namespace where the new images should be pushed : ", hints, defaultNamespace, validator)
 }
 
 // IngressHost returns Ingress host
 func IngressHost(defaulthost string, clusterQaLabel string) string {
 	key := common.JoinQASubKeys(common.ConfigTargetKey, `"`+clusterQaLabel+`"`, common.ConfigIngressHostKeySuffix)
-	return qaengine.FetchStringAnswer(key, "Provide the ingress host domain", []string{"Ingress host domain is part of service URL"}, defaulthost, nil)
+	return qaengine.FetchStringAnswer(key, "Provide the ingress host domain", []string{"Ingress host domain is part of service URL", "Wildcard domains like *.apps.example.com are accepted, the host prefixes of the services replace the wildcard"}, defaulthost, qatypes.NewIngressHostValidator())
 }
 
 // MinimumReplicaCount returns minimum replica count
 func MinimumReplicaCount(defaultminreplicas string) string {
-	return qaengine.FetchStringAnswer(common.ConfigMinReplicasKey, "Provide the minimum number of replicas each service should have", []string{"If the value is 0 pods won't be started by default"}, defaultminreplicas, func(replicaCount interface{}) error {
-		replicaCountI, err := cast.ToIntE(replicaCount)
-		if err != nil {
-			return err
+	return qaengine.FetchStringAnswer(common.ConfigMinReplicasKey, "Provide the minimum number of replicas each service should have", []string{"If the value is 0 pods won't be started by default"}, defaultminreplicas, qatypes.NewRangeValidator(0, math.MaxInt32))
+}
+
+const (
+	// NoServiceMesh means that the target cluster does not have a service mesh
+	NoServiceMesh = "None"
+	// LinkerdServiceMesh is the Linkerd service mesh
+	LinkerdServiceMesh = "Linkerd"
+	// IstioServiceMesh is the Istio service mesh
+	IstioServiceMesh = "Istio"
+)
+
+// ServiceMesh returns the service mesh installed in the target cluster
+func ServiceMesh() string {
+	return qaengine.FetchSelectAnswer(
+		common.ConfigServiceMeshKey,
+		"Select the service mesh installed in the target cluster :",
+		[]string{"The workloads will be annotated for automatic sidecar injection and the service ports will have an app protocol"},
+		NoServiceMesh,
+		[]string{NoServiceMesh, LinkerdServiceMesh, IstioServiceMesh},
+		nil,
+	)
+}
+
+const (
+	// NoProgressiveDelivery means that new versions of the workloads are rolled out using the default rolling update
+	NoProgressiveDelivery = "None"
+	// ArgoRolloutsCanaryProgressiveDelivery shifts the traffic gradually to new versions using Argo Rollouts
+	ArgoRolloutsCanaryProgressiveDelivery = "Argo Rollouts canary"
+	// ArgoRolloutsBlueGreenProgressiveDelivery switches the traffic to new versions after they are ready using Argo Rollouts
+	ArgoRolloutsBlueGreenProgressiveDelivery = "Argo Rollouts blue-green"
+	// FlaggerCanaryProgressiveDelivery shifts the traffic gradually to new versions of the deployments using Flagger
+	FlaggerCanaryProgressiveDelivery = "Flagger canary"
+)
+
+// ProgressiveDeliveryStrategy returns the strategy used to roll out new versions of the workloads
+func ProgressiveDeliveryStrategy() string {
+	return qaengine.FetchSelectAnswer(
+		common.ConfigProgressiveDeliveryStrategyKey,
+		"Select the progressive delivery strategy used to roll out new versions of the services :",
+		[]string{"The controller of the selected strategy must be installed in the target cluster"},
+		NoProgressiveDelivery,
+		[]string{NoProgressiveDelivery, ArgoRolloutsCanaryProgressiveDelivery, ArgoRolloutsBlueGreenProgressiveDelivery, FlaggerCanaryProgressiveDelivery},
+		nil,
+	)
+}
+
+// ProgressiveDeliveryPrometheusAddress returns the address of the Prometheus server which has the request metrics of the services
+func ProgressiveDeliveryPrometheusAddress() string {
+	return qaengine.FetchStringAnswer(
+		common.ConfigProgressiveDeliveryPrometheusKey,
+		"Provide the address of the Prometheus server used to analyse the new versions :",
+		[]string{"The success rate of the requests to a new version is queried from this server"},
+		"http://prometheus.monitoring.svc.cluster.local:9090",
+		nil,
+	)
+}
+
+// ProgressiveDeliveryMinSuccessRate returns the minimum percentage of successful requests for a new version to be promoted
+func ProgressiveDeliveryMinSuccessRate() int {
+	return cast.ToInt(qaengine.FetchStringAnswer(
+		common.ConfigProgressiveDeliverySuccessRateKey,
+		"Provide the minimum percentage of successful requests for a new version to be promoted :",
+		[]string{"New versions with a lower success rate are rolled back"},
+		"99",
+		qatypes.NewRangeValidator(0, 100),
+	))
+}
+
+// ProgressiveDeliveryMaxRequestDuration returns the maximum request duration in milliseconds for a new version to be promoted
+func ProgressiveDeliveryMaxRequestDuration() int {
+	return cast.ToInt(qaengine.FetchStringAnswer(
+		common.ConfigProgressiveDeliveryRequestDurationKey,
+		"Provide the maximum 99th percentile request duration in milliseconds for a new version to be promoted :",
+		[]string{"New versions with slower requests are rolled back"},
+		"500",
+		qatypes.NewRangeValidator(1, math.MaxInt32),
+	))
+}
+
+const (
+	// UBIBaseImageFamily is the family of the Red Hat Universal Base Images
+	UBIBaseImageFamily = "UBI"
+	// AlpineBaseImageFamily is the family of the Alpine Linux based images
+	AlpineBaseImageFamily = "Alpine"
+	// DistrolessBaseImageFamily is the family of the distroless images which only contain the runtime
+	DistrolessBaseImageFamily = "Distroless"
+)
+
+// TimeZone returns the time zone of the containers which used the time zone of the host
+func TimeZone() string {
+	defaultTimeZone := "UTC"
+	if !common.IgnoreEnvironment {
+		if tz := os.Getenv("TZ"); tz != "" {
+			defaultTimeZone = strings.TrimPrefix(tz, ":")
+		} else if localtime, err := os.Readlink("/etc/localtime"); err == nil && strings.Contains(localtime, "zoneinfo/") {
+			defaultTimeZone = localtime[strings.LastIndex(localtime, "zoneinfo/")+len("zoneinfo/"):]
 		}
-		if replicaCountI < 0 {
-			return fmt.Errorf("replica count should be a positive number")
+	}
+	return qaengine.FetchStringAnswer(
+		common.ConfigTimeZoneKey,
+		"Enter the time zone of the containers which used the time zone of the host :",
+		[]string{"The time zone of the nodes can not be mounted in the pods, so it is set using the TZ environment variable. Ex : Europe/Paris"},
+		defaultTimeZone,
+		nil,
+	)
+}
+
+// BaseImageFamily returns the preferred family of the base images used in the generated Dockerfiles
+func BaseImageFamily() string {
+	return qaengine.FetchSelectAnswer(
+		common.ConfigBaseImageFamilyKey,
+		"Select the preferred family of base images for the generated Dockerfiles :",
+		[]string{"Runtimes which do not have an image in the selected family will use their default base image"},
+		UBIBaseImageFamily,
+		[]string{UBIBaseImageFamily, AlpineBaseImageFamily, DistrolessBaseImageFamily},
+		nil,
+	)
+}
+
+// BaseImage returns the image of the preferred base image family for the runtime.
+// The familyImages is the allowlist of base image families supported by the runtime.
+// If the preferred family is not in it, a warning is logged and the default image is returned.
+func BaseImage(runtimeName string, familyImages map[string]string, defaultImage string) string {
+	family := BaseImageFamily()
+	if image, ok := familyImages[family]; ok {
+		return image
+	}
+	logrus.Warnf("The %s runtime does not support the %s base image family. Using the base image %s instead.", runtimeName, family, defaultImage)
+	return defaultImage
+}
+
+// buildProxyArgs are the predefined build args of the proxies, which the builds use without the Dockerfiles declaring them
+var buildProxyArgs = []string{"HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"}
+
+// BuildProxies returns the HTTP proxies the image builds go through, by the name of their build arg.
+// The proxies set in the environment are used as the defaults.
+func BuildProxies() map[string]string {
+	defaultProxies := map[string]string{}
+	if !common.IgnoreEnvironment {
+		for _, buildProxyArg := range buildProxyArgs {
+			if value := os.Getenv(buildProxyArg); value != "" {
+				defaultProxies[buildProxyArg] = value
+			} else if value := os.Getenv(strings.ToLower(buildProxyArg)); value != "" {
+				defaultProxies[buildProxyArg] = value
+			}
 		}
-		return nil
-	})
+	}
+	proxies := map[string]string{}
+	if !qaengine.FetchBoolAnswer(
+		common.ConfigBuildProxyEnabledKey,
+		"Do the image builds need to go through an HTTP proxy?",
+		[]string{"The proxies are passed as build args to the build scripts and the pipelines"},
+		len(defaultProxies) != 0,
+		nil,
+	) {
+		return proxies
+	}
+	for _, buildProxyArg := range buildProxyArgs {
+		proxy := qaengine.FetchStringAnswer(
+			common.JoinQASubKeys(common.ConfigBuildProxyKey, strings.ToLower(strings.ReplaceAll(buildProxyArg, "_", ""))),
+			fmt.Sprintf("Enter the %s of the image builds :", buildProxyArg),
+			[]string{"Leave it empty to not set it"},
+			defaultProxies[buildProxyArg],
+			nil,
+		)
+		if proxy != "" {
+			proxies[buildProxyArg] = proxy
+		}
+	}
+	return proxies
+}
+
+// BaseImageMirrors returns the mirrors which the base images of the generated Dockerfiles are pulled from, by the registry they mirror
+func BaseImageMirrors() map[string]string {
+	answer := qaengine.FetchMultilineInputAnswer(
+		common.ConfigBaseImageMirrorsKey,
+		"Enter the mirrors of the registries to pull the base images of the generated Dockerfiles from :",
+		[]string{"Enter one registry=mirror per line, like docker.io=mirror.example.com/dockerhub . Leave it empty to pull the base images from their registries."},
+		"",
+		nil,
+	)
+	mirrors := map[string]string{}
+	for _, line := range strings.Split(answer, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		registryAndMirror := strings.SplitN(line, "=", 2)
+		for i := range registryAndMirror {
+			registryAndMirror[i] = strings.TrimSpace(registryAndMirror[i])
+		}
+		if len(registryAndMirror) != 2 || registryAndMirror[0] == "" || registryAndMirror[1] == "" {
+			logrus.Warnf("Ignoring the base image mirror '%s' . Expected registry=mirror", line)
+			continue
+		}
+		mirrors[registryAndMirror[0]] = registryAndMirror[1]
+	}
+	return mirrors
+}
+
+// ImageBuildArgs returns the build args to pass when building the image with its Dockerfile, along with the proxies of the builds.
+// The build args are asked only if the Dockerfile declares some or if some are already given.
+func ImageBuildArgs(imageName string, declaredBuildArgs []string, defaultBuildArgs map[string]string) map[string]string {
+	buildArgs := map[string]string{}
+	// the proxies are passed to all the builds, so they are not asked for each image
+	declaredBuildArgs = common.Filter(declaredBuildArgs, func(buildArg string) bool { return !common.IsPresent(buildProxyArgs, buildArg) })
+	defaultLines := []string{}
+	for buildArg, value := range defaultBuildArgs {
+		if !common.IsPresent(buildProxyArgs, buildArg) {
+			defaultLines = append(defaultLines, buildArg+"="+value)
+		}
+	}
+	sort.Strings(defaultLines)
+	if len(declaredBuildArgs) != 0 || len(defaultLines) != 0 {
+		hints := []string{"Enter one NAME=value per line. Leave it empty to use the defaults of the Dockerfile."}
+		if len(declaredBuildArgs) != 0 {
+			hints = append(hints, "The Dockerfile declares the build args: "+strings.Join(declaredBuildArgs, ", "))
+		}
+		answer := qaengine.FetchMultilineInputAnswer(
+			common.JoinQASubKeys(common.ConfigImageBuildKey, `"`+imageName+`"`, common.ConfigImageBuildArgsKeySegment),
+			fmt.Sprintf("Enter the build args to pass when building the image %s :", imageName),
+			hints,
+			strings.Join(defaultLines, "\n"),
+			nil,
+		)
+		for _, line := range strings.Split(answer, "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" {
+				continue
+			}
+			nameAndValue := strings.SplitN(line, "=", 2)
+			if len(nameAndValue) != 2 || nameAndValue[0] == "" {
+				logrus.Warnf("Ignoring the build arg '%s' of the image %s . Expected NAME=value", line, imageName)
+				continue
+			}
+			buildArgs[nameAndValue[0]] = nameAndValue[1]
+		}
+	}
+	for buildProxyArg, proxy := range BuildProxies() {
+		if _, ok := buildArgs[buildProxyArg]; !ok {
+			buildArgs[buildProxyArg] = proxy
+		}
+	}
+	return buildArgs
+}
+
+// ImageBuildTarget returns the stage of the multi-stage Dockerfile to build for the image. The last stage is built if it is empty.
+// It is asked only if the Dockerfile has several named stages or if a target is already given.
+func ImageBuildTarget(imageName string, stages []string, defaultTarget string) string {
+	if len(stages) < 2 && defaultTarget == "" {
+		return defaultTarget
+	}
+	target := qaengine.FetchStringAnswer(
+		common.JoinQASubKeys(common.ConfigImageBuildKey, `"`+imageName+`"`, common.ConfigImageBuildTargetKeySegment),
+		fmt.Sprintf("Enter the stage of the Dockerfile to build for the image %s :", imageName),
+		[]string{fmt.Sprintf("The stages of the Dockerfile are: %s . Leave it empty to build the last stage.", strings.Join(stages, ", "))},
+		defaultTarget,
+		nil,
+	)
+	if target != "" && !common.IsPresent(stages, target) {
+		logrus.Warnf("The target %s of the image %s is not one of the stages of its Dockerfile: %+v", target, imageName, stages)
+	}
+	return target
 }
 
 // GetPortsForService returns ports used by a service
@@ -109,7 +387,7 @@ func GetPortsForService(detectedPorts []int32, qaSubKey string) []int32 {
 		quesKey := common.JoinQASubKeys(common.ConfigServicesKey, qaSubKey, common.ConfigPortsForServiceKeySegment)
 		desc := fmt.Sprintf("Select ports to be exposed for the service '%s' :", qaSubKey)
 		hints := []string{"Select 'Other' if you want to add more ports"}
-		selectedPortsStr = qaengine.FetchMultiSelectAnswer(quesKey, desc, hints, detectedPortsStr, allDetectedPortsStr, nil)
+		selectedPortsStr = qaengine.FetchMultiSelectAnswer(quesKey, desc, hints, detectedPortsStr, allDetectedPortsStr, qatypes.NewPortValidator())
 	}
 	for _, portStr := range selectedPortsStr {
 		portStr = strings.TrimSpace(portStr)
@@ -138,7 +416,7 @@ func GetPortForService(detectedPorts []int32, qaSubKey string) int32 {
 		detectedPortStrs = append(detectedPortStrs, cast.ToString(common.DefaultServicePort))
 	}
 	detectedPortStrs = append(detectedPortStrs, qatypes.OtherAnswer)
-	selectedPortStr := qaengine.FetchSelectAnswer(quesKey, desc, hints, detectedPortStrs[0], detectedPortStrs, nil)
+	selectedPortStr := qaengine.FetchSelectAnswer(quesKey, desc, hints, detectedPortStrs[0], detectedPortStrs, qatypes.NewPortValidator())
 	selectedPortStr = strings.TrimSpace(selectedPortStr)
 	selectedPort, err := strconv.ParseInt(selectedPortStr, 10, 32)
 	if err != nil {
@@ -151,3 +429,143 @@ func GetPortForService(detectedPorts []int32, qaSubKey string) int32 {
 	}
 	return int32(selectedPort)
 }
+
+// ScriptHookSnippets stores the snippets to run before and after the main loop of the generated scripts
+type ScriptHookSnippets struct {
+	PreHook         string
+	PostHook        string
+	PreHookWindows  string
+	PostHookWindows string
+}
+
+// GetScriptHooks returns the shell and the batch snippets to run before and after the main loop of the generated script.
+// An answer can either be the snippet itself or the path to a file containing the snippet.
+func GetScriptHooks(scriptName string) ScriptHookSnippets {
+	if !qaengine.FetchBoolAnswer(
+		common.ConfigScriptsHooksEnabledKey,
+		"Do you want to add hooks to the generated build and push scripts?",
+		[]string{"Hooks are snippets that run before and after the images are built or pushed. Example: login, gating, notifications"},
+		false,
+		nil,
+	) {
+		return ScriptHookSnippets{}
+	}
+	fetchHook := func(key, when, script, language string) string {
+		hook := qaengine.FetchMultilineInputAnswer(
+			key,
+			fmt.Sprintf("Enter the snippet to run %s the main loop of the %s script:", when, script),
+			[]string{fmt.Sprintf("Enter a %s snippet or the path to a file containing the snippet. Leave it empty to not add a hook.", language)},
+			"",
+			nil,
+		)
+		hook = strings.TrimSpace(hook)
+		if hook == "" || strings.Contains(hook, "\n") {
+			return hook
+		}
+		if data, err := os.ReadFile(hook); err == nil {
+			return strings.TrimSpace(string(data))
+		}
+		return hook
+	}
+	shellScript, batchScript := scriptName+".sh", scriptName+".bat"
+	return ScriptHookSnippets{
+		PreHook:         fetchHook(common.JoinQASubKeys(common.ConfigScriptsKey, scriptName, common.ConfigScriptPreHookKeySuffix), "before", shellScript, "shell"),
+		PostHook:        fetchHook(common.JoinQASubKeys(common.ConfigScriptsKey, scriptName, common.ConfigScriptPostHookKeySuffix), "after", shellScript, "shell"),
+		PreHookWindows:  fetchHook(common.JoinQASubKeys(common.ConfigScriptsKey, scriptName, common.ConfigScriptWindowsHookKeySegment, common.ConfigScriptPreHookKeySuffix), "before", batchScript, "batch"),
+		PostHookWindows: fetchHook(common.JoinQASubKeys(common.ConfigScriptsKey, scriptName, common.ConfigScriptWindowsHookKeySegment, common.ConfigScriptPostHookKeySuffix), "after", batchScript, "batch"),
+	}
+}
+
+const (
+	// NoImageSigning means that the pushed images are not signed
+	NoImageSigning = "None"
+	// KeyImageSigning signs the pushed images with a cosign key
+	KeyImageSigning = "Key"
+	// KeylessImageSigning signs the pushed images with a short lived certificate issued for the OIDC identity of the signer
+	KeylessImageSigning = "Keyless"
+	// defaultImageSigningKeyRef is the private key generated by cosign generate-key-pair
+	defaultImageSigningKeyRef = "cosign.key"
+)
+
+// ImageSigning returns whether the pushed images are signed with cosign, along with the reference to the key.
+// The key reference is empty when the images are signed keyless.
+func ImageSigning() (sign bool, keyRef string) {
+	signing := qaengine.FetchSelectAnswer(
+		common.ConfigImageSigningTypeKey,
+		"Select how the pushed images should be signed with cosign :",
+		[]string{"The generated scripts and pipelines sign each image after pushing it. cosign must be installed to run the scripts."},
+		NoImageSigning,
+		[]string{NoImageSigning, KeyImageSigning, KeylessImageSigning},
+		nil,
+	)
+	switch signing {
+	case KeyImageSigning:
+		keyRef = qaengine.FetchStringAnswer(
+			common.ConfigImageSigningKeyRefKey,
+			"Enter the reference to the cosign key the images should be signed with :",
+			[]string{
+				"Ex : cosign.key , awskms:///arn:aws:kms:us-east-1:123456789012:key/my-key , k8s://my-namespace/my-secret",
+				"The password of the key is read from the COSIGN_PASSWORD environment variable",
+			},
+			defaultImageSigningKeyRef,
+			nil,
+		)
+		if keyRef = strings.TrimSpace(keyRef); keyRef == "" {
+			keyRef = defaultImageSigningKeyRef
+		}
+		return true, keyRef
+	case KeylessImageSigning:
+		return true, ""
+	}
+	return false, ""
+}
+
+// ContainerResourceQuantity returns the default request or limit of the resource for containers that don't specify one
+func ContainerResourceQuantity(resourceType, resourceName, def string) resource.Quantity {
+	quesKey := common.JoinQASubKeys(common.ConfigFixersResourcesKey, resourceType, resourceName)
+	ans := qaengine.FetchStringAnswer(
+		quesKey,
+		fmt.Sprintf("Provide the default %s %s for containers that don't specify one:", resourceName, resourceType),
+		[]string{"Use kubernetes quantity notation. Example: 250m for cpu, 256Mi for memory"},
+		def,
+		func(ans interface{}) error {
+			s, ok := ans.(string)
+			if !ok {
+				return fmt.Errorf("expected a string. Actual value is %+v of type %T", ans, ans)
+			}
+			_, err := resource.ParseQuantity(s)
+			return err
+		},
+	)
+	quantity, err := resource.ParseQuantity(ans)
+	if err != nil {
+		logrus.Errorf("failed to parse the %s %s '%s' . Using the default value %s instead. Error: %q", resourceName, resourceType, ans, def, err)
+		return resource.MustParse(def)
+	}
+	return quantity
+}
+
+// ObservedContainerResourceQuantity returns the request or limit of the resource for a container whose usage was observed in the cluster it is migrated from
+func ObservedContainerResourceQuantity(workloadName, containerName, resourceType, resourceName, observed, def string) resource.Quantity {
+	quesKey := common.JoinQASubKeys(common.ConfigServicesKey, `"`+workloadName+`"`, "containers", `"`+containerName+`"`, common.ConfigContainerResourcesKeySegment, resourceType, resourceName)
+	ans := qaengine.FetchStringAnswer(
+		quesKey,
+		fmt.Sprintf("Provide the %s %s for the container %s of %s :", resourceName, resourceType, containerName, workloadName),
+		[]string{fmt.Sprintf("The observed 95th percentile of the %s usage is %s", resourceName, observed), "Use kubernetes quantity notation. Example: 250m for cpu, 256Mi for memory"},
+		def,
+		func(ans interface{}) error {
+			s, ok := ans.(string)
+			if !ok {
+				return fmt.Errorf("expected a string. Actual value is %+v of type %T", ans, ans)
+			}
+			_, err := resource.ParseQuantity(s)
+			return err
+		},
+	)
+	quantity, err := resource.ParseQuantity(ans)
+	if err != nil {
+		logrus.Errorf("failed to parse the %s %s '%s' . Using the default value %s instead. Error: %q", resourceName, resourceType, ans, def, err)
+		return resource.MustParse(def)
+	}
+	return quantity
+}
@@ -0,0 +1,234 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package commonqa
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/qaengine"
+)
+
+func setupQA(t *testing.T, configs ...string) {
+	t.Helper()
+	qaengine.Reset()
+	t.Cleanup(qaengine.Reset)
+	qaengine.AddEngine(qaengine.NewDefaultEngine())
+	qaengine.SetupConfigFile("", configs, nil, nil, false)
+}
+
+func TestTimeZone(t *testing.T) {
+	t.Run("the time zone of the environment is the default", func(t *testing.T) {
+		setupQA(t)
+		t.Setenv("TZ", ":Europe/Paris")
+		if got := TimeZone(); got != "Europe/Paris" {
+			t.Fatalf("expected the time zone of the environment, got %q", got)
+		}
+	})
+	t.Run("the environment is ignored", func(t *testing.T) {
+		setupQA(t)
+		t.Setenv("TZ", "Europe/Paris")
+		ignoreEnvironment := common.IgnoreEnvironment
+		common.IgnoreEnvironment = true
+		t.Cleanup(func() { common.IgnoreEnvironment = ignoreEnvironment })
+		if got := TimeZone(); got != "UTC" {
+			t.Fatalf("expected the time zone UTC when ignoring the environment, got %q", got)
+		}
+	})
+	t.Run("the answer is used", func(t *testing.T) {
+		setupQA(t, common.ConfigTimeZoneKey+`="Asia/Tokyo"`)
+		t.Setenv("TZ", "Europe/Paris")
+		if got := TimeZone(); got != "Asia/Tokyo" {
+			t.Fatalf("expected the configured time zone, got %q", got)
+		}
+	})
+}
+
+func TestBaseImage(t *testing.T) {
+	familyImages := map[string]string{
+		UBIBaseImageFamily:    "registry.access.redhat.com/ubi8/python-39",
+		AlpineBaseImageFamily: "python:3.9-alpine",
+	}
+	testCases := []struct {
+		family string
+		want   string
+	}{
+		{family: "", want: "registry.access.redhat.com/ubi8/python-39"},
+		{family: AlpineBaseImageFamily, want: "python:3.9-alpine"},
+		{family: DistrolessBaseImageFamily, want: "python:3.9"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.family, func(t *testing.T) {
+			configs := []string{}
+			if testCase.family != "" {
+				configs = append(configs, common.ConfigBaseImageFamilyKey+`="`+testCase.family+`"`)
+			}
+			setupQA(t, configs...)
+			if got := BaseImage("Python", familyImages, "python:3.9"); got != testCase.want {
+				t.Fatalf("got the base image %q for the family %q , want %q", got, testCase.family, testCase.want)
+			}
+		})
+	}
+}
+
+func TestImageBuildArgs(t *testing.T) {
+	key := common.JoinQASubKeys(common.ConfigImageBuildKey, `"web"`, common.ConfigImageBuildArgsKeySegment)
+	testCases := []struct {
+		name              string
+		configs           []string
+		declaredBuildArgs []string
+		defaultBuildArgs  map[string]string
+		want              map[string]string
+	}{
+		{name: "not asked without build args", defaultBuildArgs: map[string]string{}, want: map[string]string{}},
+		{name: "the given build args by default", defaultBuildArgs: map[string]string{"VERSION": "1.2"}, want: map[string]string{"VERSION": "1.2"}},
+		{name: "the declared build args without a value", declaredBuildArgs: []string{"VERSION"}, want: map[string]string{}},
+		{
+			name:              "the configured build args",
+			configs:           []string{key + "=\"VERSION=2.0\n\n  PROFILE=prod=eu \nINVALID\""},
+			declaredBuildArgs: []string{"VERSION", "PROFILE"},
+			defaultBuildArgs:  map[string]string{"VERSION": "1.2"},
+			want:              map[string]string{"VERSION": "2.0", "PROFILE": "prod=eu"},
+		},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			clearProxyEnv(t)
+			setupQA(t, testCase.configs...)
+			got := ImageBuildArgs("web", testCase.declaredBuildArgs, testCase.defaultBuildArgs)
+			if diff := cmp.Diff(testCase.want, got); diff != "" {
+				t.Fatalf("unexpected build args. Diff (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestImageBuildTarget(t *testing.T) {
+	key := common.JoinQASubKeys(common.ConfigImageBuildKey, `"web"`, common.ConfigImageBuildTargetKeySegment)
+	testCases := []struct {
+		name          string
+		configs       []string
+		stages        []string
+		defaultTarget string
+		want          string
+	}{
+		{name: "not asked for a single stage", stages: []string{"builder"}, want: ""},
+		{name: "the last stage by default", stages: []string{"builder", "runtime"}, want: ""},
+		{name: "the given target by default", stages: []string{"builder", "runtime"}, defaultTarget: "builder", want: "builder"},
+		{name: "the configured target", configs: []string{key + `="runtime"`}, stages: []string{"builder", "runtime"}, defaultTarget: "builder", want: "runtime"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			setupQA(t, testCase.configs...)
+			if got := ImageBuildTarget("web", testCase.stages, testCase.defaultTarget); got != testCase.want {
+				t.Errorf("ImageBuildTarget(%q, %q, %q) = %q, want %q", "web", testCase.stages, testCase.defaultTarget, got, testCase.want)
+			}
+		})
+	}
+}
+
+// clearProxyEnv unsets the proxies of the environment, which are the defaults of the build proxies
+func clearProxyEnv(t *testing.T) {
+	t.Helper()
+	for _, buildProxyArg := range buildProxyArgs {
+		t.Setenv(buildProxyArg, "")
+		t.Setenv(strings.ToLower(buildProxyArg), "")
+	}
+}
+
+func TestBuildProxies(t *testing.T) {
+	t.Run("no proxies by default", func(t *testing.T) {
+		clearProxyEnv(t)
+		setupQA(t)
+		if got := BuildProxies(); len(got) != 0 {
+			t.Fatalf("expected no proxies. Actual: %+v", got)
+		}
+	})
+
+	t.Run("the proxies of the environment by default", func(t *testing.T) {
+		clearProxyEnv(t)
+		t.Setenv("HTTPS_PROXY", "http://proxy.example.com:3128")
+		t.Setenv("no_proxy", "localhost,.svc")
+		setupQA(t)
+		want := map[string]string{"HTTPS_PROXY": "http://proxy.example.com:3128", "NO_PROXY": "localhost,.svc"}
+		if diff := cmp.Diff(want, BuildProxies()); diff != "" {
+			t.Fatalf("unexpected proxies. Diff (-want +got):\n%s", diff)
+		}
+	})
+
+	t.Run("the proxies are disabled", func(t *testing.T) {
+		clearProxyEnv(t)
+		t.Setenv("HTTPS_PROXY", "http://proxy.example.com:3128")
+		setupQA(t, common.ConfigBuildProxyEnabledKey+`=false`)
+		if got := BuildProxies(); len(got) != 0 {
+			t.Fatalf("expected no proxies. Actual: %+v", got)
+		}
+	})
+
+	t.Run("the configured proxies", func(t *testing.T) {
+		clearProxyEnv(t)
+		setupQA(t,
+			common.ConfigBuildProxyEnabledKey+`=true`,
+			common.JoinQASubKeys(common.ConfigBuildProxyKey, "httpproxy")+`="http://proxy.example.com:3128"`,
+			common.JoinQASubKeys(common.ConfigBuildProxyKey, "noproxy")+`=""`,
+		)
+		want := map[string]string{"HTTP_PROXY": "http://proxy.example.com:3128"}
+		if diff := cmp.Diff(want, BuildProxies()); diff != "" {
+			t.Fatalf("unexpected proxies. Diff (-want +got):\n%s", diff)
+		}
+		// the proxies are passed to all the builds, along with the build args of the image
+		got := ImageBuildArgs("web", []string{"VERSION", "HTTP_PROXY"}, map[string]string{"VERSION": "1.2"})
+		want = map[string]string{"VERSION": "1.2", "HTTP_PROXY": "http://proxy.example.com:3128"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("unexpected build args. Diff (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func TestBaseImageMirrors(t *testing.T) {
+	setupQA(t, common.ConfigBaseImageMirrorsKey+"=\"docker.io=mirror.example.com/dockerhub\n quay.io = mirror.example.com/quay \nregistry.example.com=\n\"")
+	want := map[string]string{"docker.io": "mirror.example.com/dockerhub", "quay.io": "mirror.example.com/quay"}
+	if diff := cmp.Diff(want, BaseImageMirrors()); diff != "" {
+		t.Fatalf("unexpected mirrors. Diff (-want +got):\n%s", diff)
+	}
+}
+
+func TestImageSigning(t *testing.T) {
+	testCases := []struct {
+		name       string
+		configs    []string
+		wantSign   bool
+		wantKeyRef string
+	}{
+		{name: "not signed by default"},
+		{name: "keyless", configs: []string{common.ConfigImageSigningTypeKey + `="` + KeylessImageSigning + `"`}, wantSign: true},
+		{name: "the default key", configs: []string{common.ConfigImageSigningTypeKey + `="` + KeyImageSigning + `"`}, wantSign: true, wantKeyRef: defaultImageSigningKeyRef},
+		{name: "an empty key is the default key", configs: []string{common.ConfigImageSigningTypeKey + `="` + KeyImageSigning + `"`, common.ConfigImageSigningKeyRefKey + `=" "`}, wantSign: true, wantKeyRef: defaultImageSigningKeyRef},
+		{name: "the key in a kms", configs: []string{common.ConfigImageSigningTypeKey + `="` + KeyImageSigning + `"`, common.ConfigImageSigningKeyRefKey + `="gcpkms://projects/p/locations/global/keyRings/r/cryptoKeys/k"`}, wantSign: true, wantKeyRef: "gcpkms://projects/p/locations/global/keyRings/r/cryptoKeys/k"},
+	}
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			setupQA(t, testCase.configs...)
+			if sign, keyRef := ImageSigning(); sign != testCase.wantSign || keyRef != testCase.wantKeyRef {
+				t.Errorf("ImageSigning() = (%t, %q), want (%t, %q)", sign, keyRef, testCase.wantSign, testCase.wantKeyRef)
+			}
+		})
+	}
+}
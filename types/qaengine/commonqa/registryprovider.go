@@ -0,0 +1,156 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package commonqa
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+)
+
+// RegistryProvider is the cloud provider of an image registry, which has its own naming and authentication
+type RegistryProvider string
+
+const (
+	// GenericRegistryProvider is any other registry, like quay.io or docker.io
+	GenericRegistryProvider RegistryProvider = ""
+	// ECRRegistryProvider is the Amazon Elastic Container Registry
+	ECRRegistryProvider RegistryProvider = "ecr"
+	// GCRRegistryProvider is the Google Container Registry
+	GCRRegistryProvider RegistryProvider = "gcr"
+	// ArtifactRegistryProvider is the Google Artifact Registry
+	ArtifactRegistryProvider RegistryProvider = "artifactregistry"
+	// ACRRegistryProvider is the Azure Container Registry
+	ACRRegistryProvider RegistryProvider = "acr"
+)
+
+const (
+	// acrTokenUsername is the user name used to login with an access token of the Azure Container Registry
+	acrTokenUsername = "00000000-0000-0000-0000-000000000000"
+)
+
+var (
+	ecrRegistryRegex      = regexp.MustCompile(`^(\d{12})\.dkr\.ecr(-fips)?\.([a-z0-9-]+)\.amazonaws\.com(\.cn)?$`)
+	gcrRegistryRegex      = regexp.MustCompile(`^([a-z]+\.)?gcr\.io$`)
+	artifactRegistryRegex = regexp.MustCompile(`^([a-z0-9-]+)-docker\.pkg\.dev$`)
+	acrRegistryRegex      = regexp.MustCompile(`^([a-z0-9]+)\.azurecr\.(io|cn|us)$`)
+)
+
+// ImageRegistryInfo is the provider of an image registry and the details found in its URL
+type ImageRegistryInfo struct {
+	URL      string
+	Provider RegistryProvider
+	// Region is the AWS region of an ECR registry or the location of an Artifact Registry
+	Region string
+	// AccountID is the AWS account of an ECR registry
+	AccountID string
+	// Name is the name of an ACR registry
+	Name string
+}
+
+// RegistryScriptCommands are the commands the scripts run to push the images to the registry
+type RegistryScriptCommands struct {
+	// LoginCommand and LoginCommandWindows login into the registry, they are empty when the registry has no command line login
+	LoginCommand        string
+	LoginCommandWindows string
+	// CreateRepositoryCommands and CreateRepositoryCommandsWindows create the repository of each image, when the registry does not create them on push
+	CreateRepositoryCommands        map[string]string
+	CreateRepositoryCommandsWindows map[string]string
+}
+
+// GetImageRegistryInfo finds the provider of the registry from its URL
+func GetImageRegistryInfo(registryURL string) ImageRegistryInfo {
+	info := ImageRegistryInfo{URL: registryURL}
+	host := strings.ToLower(strings.TrimSpace(registryURL))
+	if idx := strings.Index(host, "/"); idx >= 0 {
+		host = host[:idx]
+	}
+	if matches := ecrRegistryRegex.FindStringSubmatch(host); matches != nil {
+		info.Provider, info.AccountID, info.Region = ECRRegistryProvider, matches[1], matches[3]
+	} else if gcrRegistryRegex.MatchString(host) {
+		info.Provider = GCRRegistryProvider
+	} else if matches := artifactRegistryRegex.FindStringSubmatch(host); matches != nil {
+		info.Provider, info.Region = ArtifactRegistryProvider, matches[1]
+	} else if matches := acrRegistryRegex.FindStringSubmatch(host); matches != nil {
+		info.Provider, info.Name = ACRRegistryProvider, matches[1]
+	}
+	return info
+}
+
+// GetLoginCommand returns the command which logs the container runtime into the registry using the command line tool of the provider
+func (info ImageRegistryInfo) GetLoginCommand(containerRuntime, registryURL string) string {
+	switch info.Provider {
+	case ECRRegistryProvider:
+		return fmt.Sprintf("aws ecr get-login-password --region %s | %s login --username AWS --password-stdin %s", info.Region, containerRuntime, registryURL)
+	case GCRRegistryProvider, ArtifactRegistryProvider:
+		return fmt.Sprintf("gcloud auth print-access-token | %s login --username oauth2accesstoken --password-stdin %s", containerRuntime, registryURL)
+	case ACRRegistryProvider:
+		return fmt.Sprintf("az acr login --name %s --expose-token --output tsv --query accessToken | %s login --username %s --password-stdin %s", info.Name, containerRuntime, acrTokenUsername, registryURL)
+	}
+	return ""
+}
+
+// GetCreateRepositoryCommand returns the command which creates the repository if it does not exist, since ECR does not create the repositories on push
+func (info ImageRegistryInfo) GetCreateRepositoryCommand(repository string, windows bool) string {
+	if info.Provider != ECRRegistryProvider {
+		return ""
+	}
+	nullDevice := "/dev/null"
+	if windows {
+		nullDevice = "NUL"
+	}
+	return fmt.Sprintf(
+		"aws ecr describe-repositories --region %s --repository-names %s >%s 2>&1 || aws ecr create-repository --region %s --repository-name %s >%s",
+		info.Region, repository, nullDevice, info.Region, repository, nullDevice,
+	)
+}
+
+// GetDefaultUsername returns the user name used to login into the registry with a long lived password
+func (info ImageRegistryInfo) GetDefaultUsername() (string, []string) {
+	switch info.Provider {
+	case ECRRegistryProvider:
+		return "AWS", []string{"The password is the output of aws ecr get-login-password, which expires after 12 hours"}
+	case GCRRegistryProvider, ArtifactRegistryProvider:
+		return "_json_key", []string{"The password is the JSON key of a service account which can pull the images"}
+	case ACRRegistryProvider:
+		return info.Name, []string{"Use the admin user of the registry, or the ID and the password of a service principal which can pull the images"}
+	}
+	return "iamapikey", nil
+}
+
+// GetRegistryScriptCommands returns the commands the scripts run to login into the registry and to create the repositories of the images.
+// The container runtime, the registry url and the registry namespace are the variables of the scripts, so that they can still be overridden.
+func GetRegistryScriptCommands(registryURL string, imageNames []string, containerRuntime, containerRuntimeWindows string) RegistryScriptCommands {
+	info := GetImageRegistryInfo(registryURL)
+	commands := RegistryScriptCommands{
+		LoginCommand:                    info.GetLoginCommand(containerRuntime, "${REGISTRY_URL}"),
+		LoginCommandWindows:             info.GetLoginCommand(containerRuntimeWindows, "%REGISTRY_URL%"),
+		CreateRepositoryCommands:        map[string]string{},
+		CreateRepositoryCommandsWindows: map[string]string{},
+	}
+	if info.Provider != ECRRegistryProvider {
+		return commands
+	}
+	for _, imageName := range imageNames {
+		repository, _ := common.GetImageNameAndTag(imageName)
+		commands.CreateRepositoryCommands[imageName] = info.GetCreateRepositoryCommand("${REGISTRY_NAMESPACE}/"+repository, false)
+		commands.CreateRepositoryCommandsWindows[imageName] = info.GetCreateRepositoryCommand("%REGISTRY_NAMESPACE%/"+repository, true)
+	}
+	return commands
+}
@@ -0,0 +1,80 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package commonqa
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestGetImageRegistryInfo(t *testing.T) {
+	testCases := []struct {
+		url  string
+		want ImageRegistryInfo
+	}{
+		{url: "quay.io", want: ImageRegistryInfo{URL: "quay.io", Provider: GenericRegistryProvider}},
+		{url: "123456789012.dkr.ecr.us-east-1.amazonaws.com", want: ImageRegistryInfo{URL: "123456789012.dkr.ecr.us-east-1.amazonaws.com", Provider: ECRRegistryProvider, Region: "us-east-1", AccountID: "123456789012"}},
+		{url: "123456789012.dkr.ecr-fips.us-gov-west-1.amazonaws.com", want: ImageRegistryInfo{URL: "123456789012.dkr.ecr-fips.us-gov-west-1.amazonaws.com", Provider: ECRRegistryProvider, Region: "us-gov-west-1", AccountID: "123456789012"}},
+		{url: "123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn/team", want: ImageRegistryInfo{URL: "123456789012.dkr.ecr.cn-north-1.amazonaws.com.cn/team", Provider: ECRRegistryProvider, Region: "cn-north-1", AccountID: "123456789012"}},
+		{url: "1234.dkr.ecr.us-east-1.amazonaws.com", want: ImageRegistryInfo{URL: "1234.dkr.ecr.us-east-1.amazonaws.com", Provider: GenericRegistryProvider}},
+		{url: "gcr.io", want: ImageRegistryInfo{URL: "gcr.io", Provider: GCRRegistryProvider}},
+		{url: "EU.GCR.IO/my-project", want: ImageRegistryInfo{URL: "EU.GCR.IO/my-project", Provider: GCRRegistryProvider}},
+		{url: "europe-west1-docker.pkg.dev", want: ImageRegistryInfo{URL: "europe-west1-docker.pkg.dev", Provider: ArtifactRegistryProvider, Region: "europe-west1"}},
+		{url: "myregistry.azurecr.io", want: ImageRegistryInfo{URL: "myregistry.azurecr.io", Provider: ACRRegistryProvider, Name: "myregistry"}},
+		{url: "myregistry.azurecr.cn", want: ImageRegistryInfo{URL: "myregistry.azurecr.cn", Provider: ACRRegistryProvider, Name: "myregistry"}},
+	}
+	for _, testCase := range testCases {
+		if got := GetImageRegistryInfo(testCase.url); got != testCase.want {
+			t.Errorf("GetImageRegistryInfo(%q) = %+v, want %+v", testCase.url, got, testCase.want)
+		}
+	}
+}
+
+func TestGetRegistryScriptCommands(t *testing.T) {
+	t.Run("ecr", func(t *testing.T) {
+		got := GetRegistryScriptCommands("123456789012.dkr.ecr.us-east-1.amazonaws.com", []string{"api:v1", "web"}, "docker", "podman")
+		want := RegistryScriptCommands{
+			LoginCommand:        "aws ecr get-login-password --region us-east-1 | docker login --username AWS --password-stdin ${REGISTRY_URL}",
+			LoginCommandWindows: "aws ecr get-login-password --region us-east-1 | podman login --username AWS --password-stdin %REGISTRY_URL%",
+			CreateRepositoryCommands: map[string]string{
+				"api:v1": "aws ecr describe-repositories --region us-east-1 --repository-names ${REGISTRY_NAMESPACE}/api >/dev/null 2>&1 || aws ecr create-repository --region us-east-1 --repository-name ${REGISTRY_NAMESPACE}/api >/dev/null",
+				"web":    "aws ecr describe-repositories --region us-east-1 --repository-names ${REGISTRY_NAMESPACE}/web >/dev/null 2>&1 || aws ecr create-repository --region us-east-1 --repository-name ${REGISTRY_NAMESPACE}/web >/dev/null",
+			},
+			CreateRepositoryCommandsWindows: map[string]string{
+				"api:v1": "aws ecr describe-repositories --region us-east-1 --repository-names %REGISTRY_NAMESPACE%/api >NUL 2>&1 || aws ecr create-repository --region us-east-1 --repository-name %REGISTRY_NAMESPACE%/api >NUL",
+				"web":    "aws ecr describe-repositories --region us-east-1 --repository-names %REGISTRY_NAMESPACE%/web >NUL 2>&1 || aws ecr create-repository --region us-east-1 --repository-name %REGISTRY_NAMESPACE%/web >NUL",
+			},
+		}
+		if !cmp.Equal(got, want) {
+			t.Fatalf("the commands are different. Difference:\n%s", cmp.Diff(want, got))
+		}
+	})
+	t.Run("acr", func(t *testing.T) {
+		got := GetRegistryScriptCommands("myregistry.azurecr.io", []string{"api:v1"}, "docker", "docker")
+		want := "az acr login --name myregistry --expose-token --output tsv --query accessToken | docker login --username " + acrTokenUsername + " --password-stdin ${REGISTRY_URL}"
+		if got.LoginCommand != want || len(got.CreateRepositoryCommands) != 0 {
+			t.Fatalf("got the commands %+v , want the login command %q and no create repository commands", got, want)
+		}
+	})
+	t.Run("generic", func(t *testing.T) {
+		got := GetRegistryScriptCommands("quay.io", []string{"api:v1"}, "docker", "docker")
+		if got.LoginCommand != "" || got.LoginCommandWindows != "" || len(got.CreateRepositoryCommands) != 0 {
+			t.Fatalf("expected no commands for a generic registry, got %+v", got)
+		}
+	})
+}
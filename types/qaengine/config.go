@@ -18,6 +18,7 @@ package qaengine
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"os"
 	"reflect"
@@ -90,6 +91,18 @@ func (c *Config) Load() (err error) {
 }
 
 func (c *Config) convertAnswer(p Problem, value interface{}) (Problem, error) {
+	// scalar values in the config files and strings are converted to the type expected by the problem.
+	// Example: a port given as 8080 is used as the string "8080" for an input problem.
+	switch p.Type {
+	case InputSolutionFormType, PasswordSolutionFormType, MultilineInputSolutionFormType, SelectSolutionFormType:
+		if v, err := cast.ToStringE(value); err == nil {
+			value = v
+		}
+	case ConfirmSolutionFormType:
+		if v, err := cast.ToBoolE(value); err == nil {
+			value = v
+		}
+	}
 	p.Answer = value
 	return p, nil
 }
@@ -297,6 +310,31 @@ func getPrinterAndEvaluator(buffer *bytes.Buffer) (yqlib.Printer, yqlib.StreamEv
 	return printer, evaluator
 }
 
+// GetConfigStringFromKeyValue converts a question ID and an answer of the form <question id>=<answer> into a config string.
+// The answer doesn't need to be quoted. Answers of the form [a,b] are treated as lists for multi select problems.
+// Example: move2kube.target."default".clustertype=Openshift gives move2kube.target."default".clustertype="Openshift"
+func GetConfigStringFromKeyValue(keyValue string) (string, error) {
+	idx := strings.Index(keyValue, "=")
+	if idx <= 0 {
+		return "", fmt.Errorf("expected the format <question id>=<answer> . Actual: %s", keyValue)
+	}
+	key := strings.TrimSpace(keyValue[:idx])
+	value := keyValue[idx+1:]
+	var answer interface{} = value
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		list := []string{}
+		if err := yaml.Unmarshal([]byte(value), &list); err == nil {
+			answer = list
+		}
+	}
+	// json is valid yq syntax for literals
+	answerJSON, err := json.Marshal(answer)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal the answer %+v to json. Error: %w", answer, err)
+	}
+	return key + "=" + string(answerJSON), nil
+}
+
 // GenerateYAMLFromExpression generates yaml string from yq syntax expression
 // Example: The expression .foo.bar="abc" gives:
 // foo:
@@ -0,0 +1,49 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import "testing"
+
+func TestGetConfigStringFromKeyValue(t *testing.T) {
+	testCases := []struct {
+		keyValue string
+		want     string
+		wantErr  bool
+	}{
+		{keyValue: `move2kube.target."default".clustertype=Kubernetes`, want: `move2kube.target."default".clustertype="Kubernetes"`},
+		{keyValue: `move2kube.minreplicas=2`, want: `move2kube.minreplicas="2"`},
+		{keyValue: `move2kube.transformers.types=[Kubernetes, Tekton]`, want: `move2kube.transformers.types=["Kubernetes","Tekton"]`},
+		{keyValue: `move2kube.target.imageregistry.url=a=b`, want: `move2kube.target.imageregistry.url="a=b"`},
+		{keyValue: `=Kubernetes`, wantErr: true},
+		{keyValue: `move2kube.target`, wantErr: true},
+	}
+	for _, testCase := range testCases {
+		got, err := GetConfigStringFromKeyValue(testCase.keyValue)
+		if testCase.wantErr {
+			if err == nil {
+				t.Fatalf("expected an error for %s . Actual: %s", testCase.keyValue, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("failed to convert %s . Error: %q", testCase.keyValue, err)
+		}
+		if got != testCase.want {
+			t.Fatalf("expected %s to be converted to %s . Actual: %s", testCase.keyValue, testCase.want, got)
+		}
+	}
+}
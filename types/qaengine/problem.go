@@ -47,6 +47,8 @@ const (
 )
 
 const (
+	// ValidatedAnswerHint is the hint of the GRPC problems whose answers are validated by a validator which can not be sent
+	ValidatedAnswerHint = "The answer is validated, an invalid answer is sent back with the reason in the validation error"
 	// OtherAnswer - Use as one of the answers, when there is a option to enter the answer in Select Question Type
 	OtherAnswer = "Other (specify custom option)"
 )
@@ -61,6 +63,8 @@ type Problem struct {
 	Default   interface{}             `yaml:"default,omitempty" json:"default,omitempty"`
 	Answer    interface{}             `yaml:"answer,omitempty" json:"answer,omitempty"`
 	Validator func(interface{}) error `yaml:"-" json:"-"`
+	// Pattern is the regex the answers have to match, when the validator was created from it
+	Pattern string `yaml:"-" json:"pattern,omitempty"`
 }
 
 // NewProblem creates a new problem object from a GRPC problem
@@ -79,24 +83,40 @@ func NewProblem(p *qagrpc.Problem) (prob Problem, err error) {
 		Default: defaults,
 	}
 	if p.Pattern != "" {
-		reg, err := regexp.Compile(p.Pattern)
+		validator, err := NewRegexValidator(p.Pattern)
 		if err != nil {
-			return pp, fmt.Errorf("not a valid regex pattern : Error : %s", err)
-		}
-		pp.Validator = func(ans interface{}) error {
-			a, ok := ans.(string)
-			if !ok {
-				return fmt.Errorf("expected input to be type String, got %T. Value : %+v", ans, ans)
-			}
-			if !reg.MatchString(a) {
-				return fmt.Errorf("pattern does not match : %s", a)
-			}
-			return nil
+			return pp, err
 		}
+		pp.Validator = validator
+		pp.Pattern = p.Pattern
 	}
 	return pp, nil
 }
 
+// NewGRPCProblem creates a new GRPC problem object from a problem.
+// The validators which are not regex patterns can not be sent, so a hint makes the clients expect the answers to be validated.
+func NewGRPCProblem(p Problem) (*qagrpc.Problem, error) {
+	gp := &qagrpc.Problem{
+		Id:          p.ID,
+		Type:        string(p.Type),
+		Description: p.Desc,
+		Hints:       p.Hints,
+		Options:     p.Options,
+		Pattern:     p.Pattern,
+	}
+	if p.Validator != nil && p.Pattern == "" {
+		gp.Hints = append(append([]string{}, p.Hints...), ValidatedAnswerHint)
+	}
+	if p.Default != nil {
+		defaults, err := InterfaceToArray(p.Default, p.Type)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert the defaults of the problem %s . Error: %w", p.ID, err)
+		}
+		gp.Default = defaults
+	}
+	return gp, nil
+}
+
 // InterfaceToArray converts the answer interface to array
 func InterfaceToArray(ansI interface{}, problemType SolutionFormType) (ans []string, err error) {
 	if ansI == nil {
@@ -128,7 +148,7 @@ func InterfaceToArray(ansI interface{}, problemType SolutionFormType) (ans []str
 
 // ArrayToInterface converts the answer array to interface
 func ArrayToInterface(ans []string, problemType SolutionFormType) (ansI interface{}, err error) {
-	if ansI == nil {
+	if ans == nil {
 		return nil, nil
 	}
 	switch problemType {
@@ -0,0 +1,67 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"testing"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/konveyor/move2kube/types/qaengine/qagrpc"
+)
+
+func TestNewGRPCProblem(t *testing.T) {
+	t.Run("defaults which can not be converted", func(t *testing.T) {
+		prob := Problem{ID: "move2kube.test.password", Type: PasswordSolutionFormType, Desc: "Password :", Default: 1234}
+		if gprob, err := NewGRPCProblem(prob); err == nil {
+			t.Fatalf("expected an error for the default which is not a string, got %+v", gprob)
+		}
+	})
+
+	t.Run("regex validators are sent as patterns", func(t *testing.T) {
+		prob, err := NewProblem(&qagrpc.Problem{Id: "move2kube.test.name", Type: string(InputSolutionFormType), Description: "Name :", Default: []string{"web"}, Pattern: "^[a-z]+$"})
+		if err != nil {
+			t.Fatalf("failed to create the problem. Error: %q", err)
+		}
+		if err := prob.Validator("Web"); err == nil {
+			t.Fatalf("expected the answer not matching the pattern to be rejected")
+		}
+		gprob, err := NewGRPCProblem(prob)
+		if err != nil {
+			t.Fatalf("failed to create the GRPC problem. Error: %q", err)
+		}
+		if gprob.Pattern != "^[a-z]+$" || len(gprob.Hints) != 0 || len(gprob.Default) != 1 || gprob.Default[0] != "web" {
+			t.Fatalf("expected the pattern and the default to be kept, got %+v", gprob)
+		}
+	})
+
+	t.Run("other validators are hinted", func(t *testing.T) {
+		prob, err := NewInputProblem("move2kube.test.port", "Port :", []string{"The port of the service"}, "8080", NewPortValidator())
+		if err != nil {
+			t.Fatalf("failed to create the problem. Error: %q", err)
+		}
+		gprob, err := NewGRPCProblem(prob)
+		if err != nil {
+			t.Fatalf("failed to create the GRPC problem. Error: %q", err)
+		}
+		if !common.IsPresent(gprob.Hints, ValidatedAnswerHint) || gprob.Hints[0] != "The port of the service" {
+			t.Fatalf("expected the hints to say that the answers are validated, got %+v", gprob.Hints)
+		}
+		if len(prob.Hints) != 1 {
+			t.Fatalf("expected the hints of the problem not to be changed, got %+v", prob.Hints)
+		}
+	})
+}
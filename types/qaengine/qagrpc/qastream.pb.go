@@ -0,0 +1,256 @@
+/*
+ *  Copyright IBM Corporation 2020, 2021, 2022
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// If this file is updated, protoc needs to be installed and the following command needs to be executed again in this directory
+// protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative qastream.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.28.1-devel
+// 	protoc        v3.19.1
+// source: qastream.proto
+
+package qagrpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Question struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Problem         *Problem `protobuf:"bytes,1,opt,name=problem,proto3" json:"problem,omitempty"`
+	ValidationError string   `protobuf:"bytes,2,opt,name=validationError,proto3" json:"validationError,omitempty"`
+}
+
+func (x *Question) Reset() {
+	*x = Question{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_qastream_proto_msgTypes[0]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Question) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Question) ProtoMessage() {}
+
+func (x *Question) ProtoReflect() protoreflect.Message {
+	mi := &file_qastream_proto_msgTypes[0]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Question.ProtoReflect.Descriptor instead.
+func (*Question) Descriptor() ([]byte, []int) {
+	return file_qastream_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Question) GetProblem() *Problem {
+	if x != nil {
+		return x.Problem
+	}
+	return nil
+}
+
+func (x *Question) GetValidationError() string {
+	if x != nil {
+		return x.ValidationError
+	}
+	return ""
+}
+
+type Solution struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	Id     string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Answer []string `protobuf:"bytes,2,rep,name=answer,proto3" json:"answer,omitempty"`
+}
+
+func (x *Solution) Reset() {
+	*x = Solution{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_qastream_proto_msgTypes[1]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *Solution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Solution) ProtoMessage() {}
+
+func (x *Solution) ProtoReflect() protoreflect.Message {
+	mi := &file_qastream_proto_msgTypes[1]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Solution.ProtoReflect.Descriptor instead.
+func (*Solution) Descriptor() ([]byte, []int) {
+	return file_qastream_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Solution) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Solution) GetAnswer() []string {
+	if x != nil {
+		return x.Answer
+	}
+	return nil
+}
+
+var File_qastream_proto protoreflect.FileDescriptor
+
+var file_qastream_proto_rawDesc = []byte{
+	0x0a, 0x0e, 0x71, 0x61, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
+	0x12, 0x06, 0x71, 0x61, 0x67, 0x72, 0x70, 0x63, 0x1a, 0x11, 0x66, 0x65, 0x74, 0x63, 0x68, 0x61,
+	0x6e, 0x73, 0x77, 0x65, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x5f, 0x0a, 0x08, 0x51,
+	0x75, 0x65, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x29, 0x0a, 0x07, 0x70, 0x72, 0x6f, 0x62, 0x6c,
+	0x65, 0x6d, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x71, 0x61, 0x67, 0x72, 0x70,
+	0x63, 0x2e, 0x50, 0x72, 0x6f, 0x62, 0x6c, 0x65, 0x6d, 0x52, 0x07, 0x70, 0x72, 0x6f, 0x62, 0x6c,
+	0x65, 0x6d, 0x12, 0x28, 0x0a, 0x0f, 0x76, 0x61, 0x6c, 0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x18, 0x02, 0x20, 0x01, 0x28, 0x09, 0x52, 0x0f, 0x76, 0x61, 0x6c,
+	0x69, 0x64, 0x61, 0x74, 0x69, 0x6f, 0x6e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x22, 0x32, 0x0a, 0x08,
+	0x53, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x12, 0x0e, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
+	0x20, 0x01, 0x28, 0x09, 0x52, 0x02, 0x69, 0x64, 0x12, 0x16, 0x0a, 0x06, 0x61, 0x6e, 0x73, 0x77,
+	0x65, 0x72, 0x18, 0x02, 0x20, 0x03, 0x28, 0x09, 0x52, 0x06, 0x61, 0x6e, 0x73, 0x77, 0x65, 0x72,
+	0x32, 0x3e, 0x0a, 0x08, 0x51, 0x41, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x32, 0x0a, 0x06,
+	0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x10, 0x2e, 0x71, 0x61, 0x67, 0x72, 0x70, 0x63, 0x2e,
+	0x53, 0x6f, 0x6c, 0x75, 0x74, 0x69, 0x6f, 0x6e, 0x1a, 0x10, 0x2e, 0x71, 0x61, 0x67, 0x72, 0x70,
+	0x63, 0x2e, 0x51, 0x75, 0x65, 0x73, 0x74, 0x69, 0x6f, 0x6e, 0x22, 0x00, 0x28, 0x01, 0x30, 0x01,
+	0x42, 0x35, 0x5a, 0x33, 0x67, 0x69, 0x74, 0x68, 0x75, 0x62, 0x2e, 0x63, 0x6f, 0x6d, 0x2f, 0x6b,
+	0x6f, 0x6e, 0x76, 0x65, 0x79, 0x6f, 0x72, 0x2f, 0x6d, 0x6f, 0x76, 0x65, 0x32, 0x6b, 0x75, 0x62,
+	0x65, 0x2f, 0x74, 0x79, 0x70, 0x65, 0x73, 0x2f, 0x71, 0x61, 0x65, 0x6e, 0x67, 0x69, 0x6e, 0x65,
+	0x2f, 0x71, 0x61, 0x67, 0x72, 0x70, 0x63, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+}
+
+var (
+	file_qastream_proto_rawDescOnce sync.Once
+	file_qastream_proto_rawDescData = file_qastream_proto_rawDesc
+)
+
+func file_qastream_proto_rawDescGZIP() []byte {
+	file_qastream_proto_rawDescOnce.Do(func() {
+		file_qastream_proto_rawDescData = protoimpl.X.CompressGZIP(file_qastream_proto_rawDescData)
+	})
+	return file_qastream_proto_rawDescData
+}
+
+var file_qastream_proto_msgTypes = make([]protoimpl.MessageInfo, 2)
+var file_qastream_proto_goTypes = []interface{}{
+	(*Question)(nil), // 0: qagrpc.Question
+	(*Solution)(nil), // 1: qagrpc.Solution
+	(*Problem)(nil),  // 2: qagrpc.Problem
+}
+var file_qastream_proto_depIdxs = []int32{
+	2, // 0: qagrpc.Question.problem:type_name -> qagrpc.Problem
+	1, // 1: qagrpc.QAStream.Stream:input_type -> qagrpc.Solution
+	0, // 2: qagrpc.QAStream.Stream:output_type -> qagrpc.Question
+	2, // [2:3] is the sub-list for method output_type
+	1, // [1:2] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	1, // [1:1] is the sub-list for extension extendee
+	0, // [0:1] is the sub-list for field type_name
+}
+
+func init() { file_qastream_proto_init() }
+func file_qastream_proto_init() {
+	if File_qastream_proto != nil {
+		return
+	}
+	file_fetchanswer_proto_init()
+	if !protoimpl.UnsafeEnabled {
+		file_qastream_proto_msgTypes[0].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Question); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_qastream_proto_msgTypes[1].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*Solution); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: file_qastream_proto_rawDesc,
+			NumEnums:      0,
+			NumMessages:   2,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_qastream_proto_goTypes,
+		DependencyIndexes: file_qastream_proto_depIdxs,
+		MessageInfos:      file_qastream_proto_msgTypes,
+	}.Build()
+	File_qastream_proto = out.File
+	file_qastream_proto_rawDesc = nil
+	file_qastream_proto_goTypes = nil
+	file_qastream_proto_depIdxs = nil
+}
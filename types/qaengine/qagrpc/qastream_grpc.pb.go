@@ -0,0 +1,157 @@
+/*
+ *  Copyright IBM Corporation 2020, 2021, 2022
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.2.0
+// - protoc             v3.19.1
+// source: qastream.proto
+
+package qagrpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+// QAStreamClient is the client API for QAStream service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type QAStreamClient interface {
+	// Stream sends the questions of a running transformation to the client and receives the solutions from the client.
+	// A question is resent with the validation error if its solution is invalid.
+	Stream(ctx context.Context, opts ...grpc.CallOption) (QAStream_StreamClient, error)
+}
+
+type qAStreamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewQAStreamClient(cc grpc.ClientConnInterface) QAStreamClient {
+	return &qAStreamClient{cc}
+}
+
+func (c *qAStreamClient) Stream(ctx context.Context, opts ...grpc.CallOption) (QAStream_StreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &QAStream_ServiceDesc.Streams[0], "/qagrpc.QAStream/Stream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &qAStreamStreamClient{stream}
+	return x, nil
+}
+
+type QAStream_StreamClient interface {
+	Send(*Solution) error
+	Recv() (*Question, error)
+	grpc.ClientStream
+}
+
+type qAStreamStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *qAStreamStreamClient) Send(m *Solution) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *qAStreamStreamClient) Recv() (*Question, error) {
+	m := new(Question)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// QAStreamServer is the server API for QAStream service.
+// All implementations must embed UnimplementedQAStreamServer
+// for forward compatibility
+type QAStreamServer interface {
+	// Stream sends the questions of a running transformation to the client and receives the solutions from the client.
+	// A question is resent with the validation error if its solution is invalid.
+	Stream(QAStream_StreamServer) error
+	mustEmbedUnimplementedQAStreamServer()
+}
+
+// UnimplementedQAStreamServer must be embedded to have forward compatible implementations.
+type UnimplementedQAStreamServer struct {
+}
+
+func (UnimplementedQAStreamServer) Stream(QAStream_StreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method Stream not implemented")
+}
+func (UnimplementedQAStreamServer) mustEmbedUnimplementedQAStreamServer() {}
+
+// UnsafeQAStreamServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to QAStreamServer will
+// result in compilation errors.
+type UnsafeQAStreamServer interface {
+	mustEmbedUnimplementedQAStreamServer()
+}
+
+func RegisterQAStreamServer(s grpc.ServiceRegistrar, srv QAStreamServer) {
+	s.RegisterService(&QAStream_ServiceDesc, srv)
+}
+
+func _QAStream_Stream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(QAStreamServer).Stream(&qAStreamStreamServer{stream})
+}
+
+type QAStream_StreamServer interface {
+	Send(*Question) error
+	Recv() (*Solution, error)
+	grpc.ServerStream
+}
+
+type qAStreamStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *qAStreamStreamServer) Send(m *Question) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *qAStreamStreamServer) Recv() (*Solution, error) {
+	m := new(Solution)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// QAStream_ServiceDesc is the grpc.ServiceDesc for QAStream service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var QAStream_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "qagrpc.QAStream",
+	HandlerType: (*QAStreamServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Stream",
+			Handler:       _QAStream_Stream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "qastream.proto",
+}
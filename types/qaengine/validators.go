@@ -0,0 +1,169 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/konveyor/move2kube/common"
+	"github.com/spf13/cast"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Validators return an error for invalid answers, which makes the QA engine ask the question again.
+// The answers of multi select problems are validated one at a time and the "Other" option is always accepted,
+// since the actual values for it are asked and validated separately.
+
+// NewRegexValidator returns a validator that accepts answers matching the regex pattern
+func NewRegexValidator(pattern string) (func(interface{}) error, error) {
+	reg, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("not a valid regex pattern : Error : %s", err)
+	}
+	return newValidator(func(ans string) error {
+		if !reg.MatchString(ans) {
+			return fmt.Errorf("the answer '%s' does not match the pattern %s", ans, pattern)
+		}
+		return nil
+	}), nil
+}
+
+// NewRangeValidator returns a validator that accepts integers between min and max (both inclusive)
+func NewRangeValidator(min, max int) func(interface{}) error {
+	return newValidator(func(ans string) error {
+		value, err := cast.ToIntE(strings.TrimSpace(ans))
+		if err != nil {
+			return fmt.Errorf("the answer '%s' is not a valid integer", ans)
+		}
+		if value < min || value > max {
+			return fmt.Errorf("the answer %d is outside the range %d to %d", value, min, max)
+		}
+		return nil
+	})
+}
+
+// NewIntOrPercentValidator returns a validator that accepts non negative integers and percentages, like the fields of the rolling updates
+func NewIntOrPercentValidator() func(interface{}) error {
+	return newValidator(func(ans string) error {
+		value, err := cast.ToIntE(strings.TrimSuffix(strings.TrimSpace(ans), "%"))
+		if err != nil || value < 0 {
+			return fmt.Errorf("the answer '%s' is not a number or a percentage", ans)
+		}
+		return nil
+	})
+}
+
+// NewEnumValidator returns a validator that accepts only the given values
+func NewEnumValidator(values []string) func(interface{}) error {
+	return newValidator(func(ans string) error {
+		if !common.IsPresent(values, ans) {
+			return fmt.Errorf("the answer '%s' is not one of %+v", ans, values)
+		}
+		return nil
+	})
+}
+
+// NewHostnameValidator returns a validator that accepts empty answers and valid DNS subdomains
+func NewHostnameValidator() func(interface{}) error {
+	return newValidator(func(ans string) error {
+		if ans == "" {
+			return nil
+		}
+		if errs := validation.IsDNS1123Subdomain(ans); len(errs) != 0 {
+			return fmt.Errorf("the answer '%s' is not a valid hostname : %s", ans, strings.Join(errs, ", "))
+		}
+		return nil
+	})
+}
+
+// NewIngressHostValidator returns a validator that accepts empty answers, valid DNS subdomains and wildcard DNS subdomains like *.example.com
+func NewIngressHostValidator() func(interface{}) error {
+	return newValidator(func(ans string) error {
+		if ans == "" {
+			return nil
+		}
+		errs := validation.IsDNS1123Subdomain(ans)
+		if strings.HasPrefix(ans, "*.") {
+			errs = validation.IsWildcardDNS1123Subdomain(ans)
+		}
+		if len(errs) != 0 {
+			return fmt.Errorf("the answer '%s' is not a valid hostname : %s", ans, strings.Join(errs, ", "))
+		}
+		return nil
+	})
+}
+
+// NewDNSLabelValidator returns a validator that accepts valid DNS labels, like the names of namespaces
+func NewDNSLabelValidator() func(interface{}) error {
+	return newValidator(func(ans string) error {
+		if errs := validation.IsDNS1123Label(ans); len(errs) != 0 {
+			return fmt.Errorf("the answer '%s' is not a valid name : %s", ans, strings.Join(errs, ", "))
+		}
+		return nil
+	})
+}
+
+// NewPortValidator returns a validator that accepts valid port numbers
+func NewPortValidator() func(interface{}) error {
+	return NewRangeValidator(1, 65535)
+}
+
+// CombineValidators returns a validator that accepts answers accepted by all the given validators
+func CombineValidators(validators ...func(interface{}) error) func(interface{}) error {
+	return func(ans interface{}) error {
+		for _, validator := range validators {
+			if validator == nil {
+				continue
+			}
+			if err := validator(ans); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func newValidator(validate func(string) error) func(interface{}) error {
+	return func(ans interface{}) error {
+		answers := []string{}
+		switch a := ans.(type) {
+		case []string:
+			answers = a
+		case []interface{}:
+			for _, v := range a {
+				answers = append(answers, cast.ToString(v))
+			}
+		default:
+			s, err := cast.ToStringE(ans)
+			if err != nil {
+				return fmt.Errorf("expected the answer to be a string. Actual value is %+v of type %T", ans, ans)
+			}
+			answers = append(answers, s)
+		}
+		for _, answer := range answers {
+			if answer == OtherAnswer {
+				continue
+			}
+			if err := validate(answer); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,86 @@
+/*
+ *  Copyright IBM Corporation 2021
+ *
+ *  Licensed under the Apache License, Version 2.0 (the "License");
+ *  you may not use this file except in compliance with the License.
+ *  You may obtain a copy of the License at
+ *
+ *        http://www.apache.org/licenses/LICENSE-2.0
+ *
+ *  Unless required by applicable law or agreed to in writing, software
+ *  distributed under the License is distributed on an "AS IS" BASIS,
+ *  WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ *  See the License for the specific language governing permissions and
+ *  limitations under the License.
+ */
+
+package qaengine
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHostValidators(t *testing.T) {
+	testCases := []struct {
+		host            string
+		wantHostname    bool
+		wantIngressHost bool
+	}{
+		{host: "", wantHostname: true, wantIngressHost: true},
+		{host: "myproject.example.com", wantHostname: true, wantIngressHost: true},
+		{host: "*.apps.example.com", wantHostname: false, wantIngressHost: true},
+		{host: "*", wantHostname: false, wantIngressHost: false},
+		{host: "apps.*.example.com", wantHostname: false, wantIngressHost: false},
+		{host: "*.Example.com", wantHostname: false, wantIngressHost: false},
+		{host: "https://example.com", wantHostname: false, wantIngressHost: false},
+	}
+	hostnameValidator := NewHostnameValidator()
+	ingressHostValidator := NewIngressHostValidator()
+	for _, testCase := range testCases {
+		if err := hostnameValidator(testCase.host); (err == nil) != testCase.wantHostname {
+			t.Errorf("the hostname validator returned the error %v for the host %q, want it to be accepted: %t", err, testCase.host, testCase.wantHostname)
+		}
+		if err := ingressHostValidator(testCase.host); (err == nil) != testCase.wantIngressHost {
+			t.Errorf("the ingress host validator returned the error %v for the host %q, want it to be accepted: %t", err, testCase.host, testCase.wantIngressHost)
+		}
+	}
+}
+
+func TestDNSLabelValidator(t *testing.T) {
+	validator := NewDNSLabelValidator()
+	for label, want := range map[string]bool{
+		"myproject":             true,
+		"my-project-2":          true,
+		"":                      false,
+		"MyProject":             false,
+		"my.project":            false,
+		"-myproject":            false,
+		"my_project":            false,
+		strings.Repeat("a", 63): true,
+		strings.Repeat("a", 64): false,
+	} {
+		if err := validator(label); (err == nil) != want {
+			t.Errorf("the dns label validator returned the error %v for the label %q, want it to be accepted: %t", err, label, want)
+		}
+	}
+}
+
+func TestIntOrPercentValidator(t *testing.T) {
+	validator := NewIntOrPercentValidator()
+	for answer, want := range map[string]bool{
+		"0":    true,
+		"1":    true,
+		"25%":  true,
+		" 3 ":  true,
+		"-1":   false,
+		"-10%": false,
+		"%":    false,
+		"abc":  false,
+		"1.5":  false,
+	} {
+		if err := validator(answer); (err == nil) != want {
+			t.Errorf("the int or percent validator returned the error %v for the answer %q, want it to be accepted: %t", err, answer, want)
+		}
+	}
+}
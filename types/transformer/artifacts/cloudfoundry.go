@@ -41,5 +41,9 @@ type CloudFoundryConfig struct {
 // VCAPService defines the VCAP service data from JSON
 type VCAPService struct {
 	ServiceName        string                 `json:"name"`
+	Label              string                 `json:"label"`
+	Tags               []string               `json:"tags"`
 	ServiceCredentials map[string]interface{} `json:"credentials"`
+	// SyslogDrainURL is set for the user provided services which drain the logs of the apps
+	SyslogDrainURL string `json:"syslog_drain_url"`
 }
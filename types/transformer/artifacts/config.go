@@ -38,6 +38,7 @@ func init() {
 		new(GradleConfig),
 		new(SpringBootConfig),
 		new(ContainerizationOptionsConfig),
+		new(DockerfileLint),
 		new(collecttypes.ClusterMetadata),
 	}
 	ConfigTypes = common.GetTypesMap(configObjs)
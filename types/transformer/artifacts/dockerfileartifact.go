@@ -18,6 +18,7 @@ package artifacts
 
 import (
 	transformertypes "github.com/konveyor/move2kube/types/transformer"
+	"github.com/sirupsen/logrus"
 )
 
 // DockerfileArtifactType represents the Dockerfile artifact type
@@ -34,4 +35,58 @@ const (
 const (
 	// DockerfileTemplateConfigConfigType stores the imagename for the dockerfile
 	DockerfileTemplateConfigConfigType transformertypes.ConfigType = "DockerfileTemplateConfig"
+	// DockerfileBuildConfigType stores the build args and the target stage used to build the dockerfile
+	DockerfileBuildConfigType transformertypes.ConfigType = "DockerfileBuild"
 )
+
+// DockerfileBuild stores the build args and the target stage used to build a Dockerfile
+type DockerfileBuild struct {
+	BuildArgs map[string]string `yaml:"buildArgs,omitempty" json:"buildArgs,omitempty"`
+	Target    string            `yaml:"target,omitempty" json:"target,omitempty"`
+}
+
+const (
+	// DockerfileLintConfigType stores the issues found in a Dockerfile present in the source directory
+	DockerfileLintConfigType transformertypes.ConfigType = "DockerfileLint"
+)
+
+// DockerfileLint stores the issues found in a Dockerfile
+type DockerfileLint struct {
+	Findings []DockerfileLintFinding `yaml:"findings" json:"findings"`
+}
+
+// DockerfileLintFinding is an issue found in a Dockerfile
+type DockerfileLintFinding struct {
+	// File is relative to the output directory
+	File    string `yaml:"file" json:"file"`
+	Line    int    `yaml:"line" json:"line"`
+	Rule    string `yaml:"rule" json:"rule"`
+	Message string `yaml:"message" json:"message"`
+	Fixed   bool   `yaml:"fixed" json:"fixed"`
+}
+
+// Merge implements the Config interface allowing artifacts to be merged
+func (dl *DockerfileLint) Merge(newdlobj interface{}) bool {
+	newdlptr, ok := newdlobj.(*DockerfileLint)
+	if !ok {
+		newdl, ok := newdlobj.(DockerfileLint)
+		if !ok {
+			logrus.Error("Unable to cast to DockerfileLint for merge")
+			return false
+		}
+		newdlptr = &newdl
+	}
+	for _, newFinding := range newdlptr.Findings {
+		found := false
+		for _, finding := range dl.Findings {
+			if finding == newFinding {
+				found = true
+				break
+			}
+		}
+		if !found {
+			dl.Findings = append(dl.Findings, newFinding)
+		}
+	}
+	return true
+}
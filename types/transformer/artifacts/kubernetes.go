@@ -32,4 +32,10 @@ const (
 
 	// KubernetesYamlsPathType is points to the kubernetes Yamls
 	KubernetesYamlsPathType transformertypes.PathType = "KubernetesYamls"
+
+	// WorkloadsMetadataPathType points to the metadata of the workloads collected from a cluster
+	WorkloadsMetadataPathType transformertypes.PathType = "WorkloadsMetadata"
+
+	// IRFilePathType points to an IR file exported by a previous run
+	IRFilePathType transformertypes.PathType = "IRFile"
 )